@@ -4,24 +4,43 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/octobees/leads-generator/api/internal/auth"
 	"github.com/octobees/leads-generator/api/internal/config"
 	"github.com/octobees/leads-generator/api/internal/database"
+	"github.com/octobees/leads-generator/api/internal/errreport"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+	"github.com/octobees/leads-generator/api/internal/geocode"
 	"github.com/octobees/leads-generator/api/internal/handler"
+	"github.com/octobees/leads-generator/api/internal/mailer"
 	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/outbound"
+	"github.com/octobees/leads-generator/api/internal/querycache"
 	"github.com/octobees/leads-generator/api/internal/repository"
 	"github.com/octobees/leads-generator/api/internal/router"
 	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+	"github.com/octobees/leads-generator/api/internal/storage"
+	"github.com/octobees/leads-generator/api/internal/telemetry"
+	"github.com/octobees/leads-generator/api/internal/workerpb"
 )
 
 func main() {
@@ -29,61 +48,622 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	for _, warning := range cfg.Warnings {
+		log.Printf("config: %s", warning)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("failed to configure tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	}()
+
+	var errorReporter errreport.Reporter = errreport.Nop{}
+	if cfg.ErrorReporting.Enabled() {
+		sentry, err := errreport.NewSentryReporter(cfg.ErrorReporting.DSN, cfg.ErrorReporting.Environment, nil)
+		if err != nil {
+			log.Fatalf("failed to configure error reporting: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := sentry.Flush(ctx); err != nil {
+				log.Printf("failed to flush error reports: %v", err)
+			}
+		}()
+		errorReporter = errreport.Sample(sentry, cfg.ErrorReporting.SampleRate)
+	}
+
+	readOnly := database.NewReadOnlyMonitor()
+	poolOpts := []database.ConnectOption{database.WithStatementCache(cfg.Pool.ExecMode, cfg.Pool.StatementCache)}
+	if cfg.Tracing.Enabled() {
+		poolOpts = append(poolOpts, database.WithTracer(otelpgx.NewTracer()))
+	}
+	if cfg.Pool.SlowQuery > 0 {
+		poolOpts = append(poolOpts, database.WithTracer(database.NewSlowQueryLogger(cfg.Pool.SlowQuery)))
+	}
+	pool, err := database.Connect(ctx, cfg.DatabaseURL, append(poolOpts, database.WithTracer(readOnly))...)
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
 	defer pool.Close()
+	if err := readOnly.Probe(ctx, pool); err != nil {
+		log.Printf("read-only probe failed: %v", err)
+	}
+
+	if err := database.CheckSchema(ctx, pool, cfg.SchemaCheck); err != nil {
+		log.Fatalf("schema verification failed: %v", err)
+	}
 
 	jwtManager := auth.NewJWTManager(cfg.JWTSecret, cfg.TokenTTL)
 
 	usersRepo := repository.NewPGXUsersRepository(pool)
-	companiesRepo := repository.NewPGXCompaniesRepository(pool)
+	queryTimeout := repository.WithQueryTimeout(cfg.Pool.QueryTimeout)
+	var contactCipher *fieldcrypt.Cipher
+	if cfg.Encryption.Enabled() {
+		if contactCipher, err = fieldcrypt.New(cfg.Encryption.Key, cfg.Encryption.PreviousKeys...); err != nil {
+			log.Fatalf("failed to configure enrichment encryption: %v", err)
+		}
+	}
+	enrichmentCipher := repository.WithEnrichmentCipher(contactCipher)
+	companyOpts := []repository.RepositoryOption{queryTimeout, enrichmentCipher}
+	if cfg.LeadsView {
+		companyOpts = append(companyOpts, repository.WithLeadsView())
+	}
+	companiesRepo := repository.NewPGXCompaniesRepository(pool, companyOpts...)
+	enrichmentJobsRepo := repository.NewPGXEnrichmentJobsRepository(pool)
+	enrichmentCacheRepo := repository.NewPGXEnrichmentCacheRepository(pool, enrichmentCipher)
+	chainsRepo := repository.NewPGXChainsRepository(pool)
+	companySizeRepo := repository.NewPGXCompanySizeRepository(pool)
+	outreachLanguageRepo := repository.NewPGXOutreachLanguageRepository(pool)
+	companyImagesRepo := repository.NewPGXCompanyImagesRepository(pool)
+	scoringProfilesRepo := repository.NewPGXScoringProfilesRepository(pool)
+	businessStatusRepo := repository.NewPGXBusinessStatusRepository(pool)
+	statsRepo := repository.NewPGXStatsRepository(pool, queryTimeout)
+	freshnessRepo := repository.NewPGXFreshnessRepository(pool, queryTimeout)
+	var replica *database.ReplicaRouter
+	if cfg.Replica.URL != "" {
+		replicaPool, err := database.Open(ctx, cfg.Replica.URL, poolOpts...)
+		if err != nil {
+			log.Fatalf("failed to configure read replica: %v", err)
+		}
+		defer replicaPool.Close()
+		// Reads stay on the primary until the replica answers a probe.
+		replica = database.NewReplicaRouter(pool, replicaPool, cfg.Replica.MaxLag)
+		if err := replica.Probe(ctx); err != nil {
+			log.Printf("replica probe failed: %v", err)
+		}
+		companiesRepo = repository.NewPGXCompaniesRepositoryWithReplica(pool, replica, companyOpts...)
+		statsRepo = repository.NewPGXStatsRepositoryWithReplica(replica, queryTimeout)
+		freshnessRepo = repository.NewPGXFreshnessRepositoryWithReplica(replica, queryTimeout)
+	}
+	ingestRunsRepo := repository.NewPGXIngestRunsRepository(pool)
+	warehouseRepo := repository.NewPGXWarehouseRepository(pool, enrichmentCipher)
+	changeEventsRepo := repository.NewPGXChangeEventsRepository(pool)
+	exportTemplatesRepo := repository.NewPGXExportTemplatesRepository(pool)
 
-	authService := service.NewAuthService(usersRepo, jwtManager)
-	userService := service.NewUserService(usersRepo)
-	companiesService := service.NewCompaniesService(companiesRepo)
+	var (
+		authOpts    []service.AuthServiceOption
+		accountOpts []service.AccountServiceOption
+	)
+	if cfg.Account.RefreshTTL > 0 {
+		sessionsRepo := repository.NewPGXUserSessionsRepository(pool)
+		authOpts = append(authOpts, service.WithRefreshTokens(sessionsRepo, cfg.Account.RefreshTTL))
+		accountOpts = append(accountOpts, service.WithAccountSessions(sessionsRepo))
+	}
+	if cfg.SMTP.Enabled() {
+		sender := mailer.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		accountOpts = append(accountOpts, service.WithEmailChanges(repository.NewPGXEmailChangesRepository(pool), sender, cfg.Account.EmailVerifyURL))
+	}
+	authService := service.NewAuthService(usersRepo, jwtManager, authOpts...)
+	accountService := service.NewAccountService(usersRepo, accountOpts...)
+	var redisClient *redis.Client
+	if cfg.Redis.URL != "" {
+		if redisClient, err = database.NewRedisClient(cfg.Redis.URL); err != nil {
+			log.Fatalf("failed to configure redis: %v", err)
+		}
+		defer redisClient.Close()
+	}
+	// Services publish their changes on one bus; with Redis it also carries them between replicas.
+	var busOpts []events.Option
+	if redisClient != nil && cfg.Redis.EventsChannel != "" {
+		busOpts = append(busOpts, events.WithRemote(events.NewRedisRemote(redisClient, cfg.Redis.EventsChannel)))
+	}
+	eventBus := events.NewBus(busOpts...)
+	userService := service.NewUserService(usersRepo, service.WithUserEvents(eventBus))
+	emailClassifier := service.NewEmailClassifier(nil)
+	// Payload URLs and worker calls go through one policy so internal addresses stay out of reach.
+	outboundPolicy := outbound.New(
+		outbound.WithAllow(cfg.Outbound.Allow...),
+		outbound.WithDeny(cfg.Outbound.Deny...),
+		outbound.WithMaxRedirects(cfg.Outbound.MaxRedirects),
+		outbound.WithMaxConnsPerHost(cfg.Outbound.MaxConnsPerHost),
+	)
+	// One MX cache serves every enrichment so common mail domains are resolved once per TTL.
+	dnsCache := service.NewDNSCache(cfg.Enrich.DNSCacheSize, cfg.Enrich.DNSPositiveTTL, cfg.Enrich.DNSNegativeTTL)
+	enrichProcessor := service.NewDataProcessor(cfg.Enrich.PhoneRegion,
+		service.WithEmailClassifier(emailClassifier),
+		service.WithCheckConcurrency(cfg.Enrich.CheckConcurrency),
+		service.WithCheckDeadline(cfg.Enrich.CheckTimeout),
+		service.WithCheckCacheTTL(cfg.Enrich.CheckCacheTTL),
+		service.WithDNSCache(dnsCache),
+		service.WithOutboundPolicy(outboundPolicy),
+	)
+	var rawStore service.BlobStore
+	if cfg.RawStore.Enabled() {
+		switch {
+		case cfg.RawStore.GCSBucket != "":
+			rawStore, err = storage.NewGCSStore(ctx, cfg.RawStore.GCSBucket)
+		case cfg.RawStore.S3Bucket != "":
+			rawStore, err = storage.NewS3Store(storage.S3Config{
+				Bucket:          cfg.RawStore.S3Bucket,
+				Region:          cfg.RawStore.S3Region,
+				Endpoint:        cfg.RawStore.S3Endpoint,
+				AccessKeyID:     cfg.RawStore.S3AccessKeyID,
+				SecretAccessKey: cfg.RawStore.S3SecretAccessKey,
+				SessionToken:    cfg.RawStore.S3SessionToken,
+			})
+		default:
+			rawStore, err = storage.NewLocalStore(cfg.RawStore.Dir)
+		}
+		if err != nil {
+			log.Fatalf("failed to configure raw payload store: %v", err)
+		}
+	}
+	// Listings and stats are cached until companies change or a scrape run finishes.
+	var queryCacheStore querycache.Store
+	switch cfg.QueryCache.Store {
+	case querycache.StoreMemory:
+		queryCacheStore = querycache.NewMemoryStore(cfg.QueryCache.Size)
+	case querycache.StoreRedis:
+		queryCacheStore = querycache.NewRedisStore(redisClient, cfg.QueryCache.RedisKey)
+	}
+	queryCache := querycache.New(queryCacheStore, cfg.QueryCache.Store, cfg.QueryCache.TTL)
+	// The do-not-contact list is matched against the decrypted contacts of the companies.
+	suppressionService := service.NewContactSuppressionService(repository.NewPGXContactSuppressionsRepository(pool), companiesRepo)
+	// Imported, scraped and prompted cities are normalised onto the location catalog.
+	var locationOpts []service.LocationServiceOption
+	if cfg.Locations.GeocoderURL != "" {
+		locationOpts = append(locationOpts, service.WithGeocoder(geocode.NewNominatimGeocoder(cfg.Locations.GeocoderURL, cfg.Locations.GeocoderUserAgent, nil)))
+	}
+	locationService := service.NewLocationService(repository.NewPGXLocationsRepository(pool), locationOpts...)
+	if err := locationService.Refresh(ctx); err != nil {
+		// Cities are stored as sent until the catalog loads, so this should not block startup.
+		log.Printf("failed to load locations: %v", err)
+	}
+	// Imported and scraped business types are normalised onto the category taxonomy.
+	categoryService := service.NewCategoryService(repository.NewPGXBusinessCategoriesRepository(pool))
+	if err := categoryService.Refresh(ctx); err != nil {
+		log.Printf("failed to load business categories: %v", err)
+	}
+	companiesService := service.NewCompaniesService(
+		companiesRepo,
+		service.WithEnrichmentCache(enrichmentCacheRepo, cfg.Enrich.CacheTTL),
+		service.WithSizeEstimation(companySizeRepo),
+		service.WithOutreachLanguageDetection(outreachLanguageRepo),
+		service.WithFacets(companiesRepo),
+		service.WithSuggestions(companiesRepo),
+		service.WithQueryPlans(companiesRepo),
+		service.WithRawPayloads(companiesRepo),
+		service.WithRawStore(rawStore, cfg.RawStore.Prefix),
+		service.WithScoringOptions(scoring.Options{SizeWeight: cfg.Scoring.SizeWeight}),
+		service.WithSearchScoreWeight(cfg.Scoring.SearchWeight),
+		service.WithActiveScoringProfile(scoringProfilesRepo),
+		service.WithEnrichmentValidation(enrichProcessor, cfg.Enrich.Validation),
+		service.WithCompanyCountries(companiesRepo),
+		service.WithExports(companiesRepo, map[string]int64{"user": cfg.Exports.UserRowCap, "admin": cfg.Exports.AdminRowCap}),
+		service.WithExportTemplates(exportTemplatesRepo),
+		service.WithNoWebsiteLeads(companiesRepo),
+		service.WithCompanyEvents(eventBus),
+		service.WithHistory(companiesRepo),
+		service.WithPlaceRefresh(companiesRepo),
+		service.WithTrending(companiesRepo),
+		service.WithAssignments(companiesRepo),
+		service.WithContactSuppressions(suppressionService),
+		service.WithCompanyImages(companyImagesRepo),
+		service.WithQueryCache(queryCache),
+		service.WithLocations(locationService),
+		service.WithCategories(categoryService),
+	)
+	chainService := service.NewChainService(chainsRepo)
+	businessStatusService := service.NewBusinessStatusService(businessStatusRepo)
+	statsService := service.NewStatsService(statsRepo, cfg.Stats.CacheTTL, service.WithStatsQueryCache(queryCache))
+	freshnessService := service.NewFreshnessService(freshnessRepo, cfg.Stats.CacheTTL, service.WithScrapeFreshFor(cfg.Stats.ScrapeFreshFor))
+	// The API only streams downloads and serves the manifest; partitions are written by apiadmin.
+	warehouseService := service.NewWarehouseService(warehouseRepo, nil, cfg.Warehouse.Prefix, cfg.Warehouse.RowsPerFile)
+	changeFeedService := service.NewChangeFeedService(changeEventsRepo)
+	companyChangesService := service.NewCompanyChangesService(companiesRepo)
+	scoringProfilesService := service.NewScoringProfilesService(scoringProfilesRepo, scoring.Options{SizeWeight: cfg.Scoring.SizeWeight})
+	enrichJobService := service.NewEnrichJobService(enrichmentJobsRepo, cfg.Enrich.DailyQuota)
 
 	authHandler := handler.NewAuthHandler(authService)
 	userAdminHandler := handler.NewUserAdminHandler(userService)
 	companiesHandler := handler.NewCompaniesHandler(companiesService)
 	adminUploadHandler := handler.NewAdminUploadHandler(companiesService)
-	enrichHandler := handler.NewEnrichHandler(companiesService)
-	workerClient := handler.NewWorkerClient(nil, cfg.WorkerBaseURL)
-	enrichJobHandler := handler.NewEnrichWorkerHandlerWithWorker(workerClient)
-	promptHandler := handler.NewPromptSearchHandler(workerClient, service.NewPromptService(cfg.PromptCountry))
+	var uploadArchive *service.UploadArchiveService
+	if cfg.Uploads.Enabled() {
+		var store service.UploadStore
+		if cfg.Uploads.Bucket != "" {
+			store, err = storage.NewGCSStore(ctx, cfg.Uploads.Bucket)
+		} else {
+			store, err = storage.NewLocalStore(cfg.Uploads.Dir)
+		}
+		if err != nil {
+			log.Fatalf("failed to configure upload archive: %v", err)
+		}
+		uploadArchive = service.NewUploadArchiveService(repository.NewPGXCSVUploadsRepository(pool), store, cfg.Uploads.Prefix, cfg.Uploads.Retention)
+		adminUploadHandler = handler.NewAdminUploadHandlerWithArchive(companiesService, uploadArchive)
+	}
+	importScratch, err := storage.NewLocalStore(cfg.Imports.Dir)
+	if err != nil {
+		log.Fatalf("failed to configure import directory: %v", err)
+	}
+	invitationService := service.NewInvitationService(repository.NewPGXUserInvitationsRepository(pool))
+	importJobService := service.NewImportJobService(repository.NewPGXImportJobsRepository(pool), companiesService, uploadArchive, importScratch, cfg.Imports.BatchSize, cfg.Imports.QueueSize,
+		service.WithUserImports(invitationService),
+	)
+	if err := importJobService.Resume(ctx); err != nil {
+		log.Printf("failed to resume import jobs: %v", err)
+	}
+	importJobsHandler := handler.NewImportJobsHandler(importJobService)
+	maintenanceService := service.NewMaintenanceService(
+		repository.NewPGXMaintenanceRepository(pool, enrichmentCipher),
+		service.WithSizing(companySizeRepo, scoring.Options{SizeWeight: cfg.Scoring.SizeWeight}),
+		service.WithScoringProfiles(scoringProfilesRepo),
+		service.WithPlaceAttributes(companiesRepo, rawStore),
+	)
+	scoreRecomputeService := service.NewScoreRecomputeJobService(repository.NewPGXScoreRecomputeJobsRepository(pool), maintenanceService, 0)
+	if err := scoreRecomputeService.Resume(ctx); err != nil {
+		log.Printf("failed to resume score recompute jobs: %v", err)
+	}
+	rawReprocessService := service.NewRawReprocessJobService(repository.NewPGXRawReprocessJobsRepository(pool), maintenanceService, 0)
+	if err := rawReprocessService.Resume(ctx); err != nil {
+		log.Printf("failed to resume raw reprocess jobs: %v", err)
+	}
+	var (
+		attachmentService  *service.AttachmentService
+		attachmentsHandler *handler.AttachmentsHandler
+	)
+	if cfg.Attachments.Enabled() {
+		files, err := storage.NewGCSStore(ctx, cfg.Attachments.Bucket)
+		if err != nil {
+			log.Fatalf("failed to configure attachment storage: %v", err)
+		}
+		signer, err := storage.NewURLSigner(cfg.Attachments.Bucket, cfg.Attachments.SignerKeyFile)
+		if err != nil {
+			log.Fatalf("failed to configure attachment url signer: %v", err)
+		}
+		attachmentService = service.NewAttachmentService(
+			repository.NewPGXCompanyAttachmentsRepository(pool),
+			storage.SignedGCSStore{GCSStore: files, URLSigner: signer},
+			cfg.Attachments.Prefix,
+			cfg.Attachments.MaxFileBytes,
+			cfg.Attachments.UserQuotaBytes,
+			cfg.Attachments.URLTTL,
+			service.WithOrgAttachmentQuota(cfg.Attachments.OrgQuotaBytes),
+		)
+		attachmentsHandler = handler.NewAttachmentsHandler(attachmentService)
+	}
+	deadLetterService := service.NewDeadLetterService(repository.NewPGXDeadLettersRepository(pool), companiesService)
+	enrichHandler := handler.NewEnrichHandlerWithDeadLetters(companiesService, deadLetterService)
+	workerOpts := []handler.WorkerClientOption{handler.WithDeadlineMargin(cfg.Workers.DeadlineMargin)}
+	if cfg.Workers.ScrapeTimeout > 0 {
+		workerOpts = append(workerOpts, handler.WithCallTimeout("/scrape", cfg.Workers.ScrapeTimeout))
+	}
+	if cfg.Workers.EnrichTimeout > 0 {
+		workerOpts = append(workerOpts, handler.WithCallTimeout("/enrich", cfg.Workers.EnrichTimeout))
+	}
+	// newWorker reaches a worker by the configured transport: JSON posts to baseURL, or calls to the
+	// Worker gRPC service at grpcAddr.
+	var workerConns []*grpc.ClientConn
+	newWorker := func(baseURL, grpcAddr string, timeout time.Duration) handler.Worker {
+		if cfg.Workers.Transport != config.WorkerTransportGRPC {
+			return handler.NewWorkerClientWithTimeout(nil, baseURL, outboundPolicy, timeout, workerOpts...)
+		}
+		conn, err := handler.DialWorkerGRPC(grpcAddr, cfg.Workers.GRPCTLS)
+		if err != nil {
+			log.Fatalf("failed to connect to worker %s: %v", grpcAddr, err)
+		}
+		workerConns = append(workerConns, conn)
+		return handler.NewGRPCWorkerClient(conn, timeout, workerOpts...)
+	}
+	workerClient := newWorker(cfg.WorkerBaseURL, cfg.Workers.GRPCAddr, cfg.Workers.Timeout)
+	if len(cfg.Workers.Routes) > 0 {
+		routes := make([]handler.WorkerRoute, 0, len(cfg.Workers.Routes))
+		for _, route := range cfg.Workers.Routes {
+			routes = append(routes, handler.WorkerRoute{
+				Name:      route.Name,
+				Worker:    newWorker(route.BaseURL, route.GRPCAddr, route.Timeout),
+				Countries: route.Countries,
+				Jobs:      route.Jobs,
+			})
+		}
+		workerClient = handler.NewWorkerRouter(workerClient, cfg.Workers.FailoverCooldown, routes...)
+	}
+	workerLatency := handler.NewWorkerLatencyRecorder(workerClient, handler.DefaultWorkerLatencySamples)
+	workerClient = workerLatency
+	callbackSigner := auth.NewCallbackSigner(cfg.JWTSecret, cfg.CallbackTTL)
+	enrichJobHandler := handler.NewEnrichWorkerHandlerWithJobs(workerClient, enrichJobService, companiesService, callbackSigner)
+	chainsHandler := handler.NewChainsHandler(chainService)
+	reportsHandler := handler.NewReportsHandler(businessStatusService)
+	statsHandler := handler.NewStatsHandler(statsService)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseService)
+	changesHandler := handler.NewChangesHandler(changeFeedService, companyChangesService)
+	promptService := service.NewPromptService(cfg.PromptCountry)
+	promptService.SetLocations(locationService)
+	promptAliasService := service.NewPromptAliasService(repository.NewPGXPromptAliasesRepository(pool), promptService)
+	if err := promptAliasService.Refresh(ctx); err != nil {
+		// The built-in aliases still work, so a missing table or slow database should not block startup.
+		log.Printf("failed to load prompt aliases: %v", err)
+	}
+	promptHandler := handler.NewPromptSearchHandlerWithCallbacks(workerClient, promptService, callbackSigner)
+	promptAliasHandler := handler.NewPromptAliasHandler(promptAliasService)
+	locationsHandler := handler.NewLocationsHandler(locationService)
+	categoriesHandler := handler.NewCategoriesHandler(categoryService)
+	ingestRunsService := service.NewIngestRunsService(
+		ingestRunsRepo,
+		service.WithIngestRunEvents(eventBus),
+		service.WithIngestLocations(locationService),
+		service.WithIngestCategories(categoryService),
+	)
+
+	campaignOpts := []service.CampaignServiceOption{service.WithCampaignSuppressions(suppressionService)}
+	if cfg.Campaigns.Enabled() {
+		var sender mailer.MessageSender
+		switch cfg.Campaigns.Provider {
+		case "sendgrid":
+			sender = mailer.NewSendGridSender(cfg.Campaigns.SendGridAPIKey, cfg.Campaigns.From, nil)
+		case "mailgun":
+			sender = mailer.NewMailgunSender(cfg.Campaigns.MailgunBaseURL, cfg.Campaigns.MailgunDomain, cfg.Campaigns.MailgunAPIKey, cfg.Campaigns.From, nil)
+		default:
+			sender = mailer.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.Campaigns.From)
+		}
+		campaignOpts = append(campaignOpts, service.WithCampaignSender(sender, cfg.Campaigns.PublicURL, cfg.Campaigns.BatchSize))
+	}
+	if cfg.Campaigns.SendGridWebhookKey != "" {
+		webhook, err := mailer.NewSendGridWebhook(cfg.Campaigns.SendGridWebhookKey)
+		if err != nil {
+			log.Fatalf("failed to configure sendgrid webhook: %v", err)
+		}
+		campaignOpts = append(campaignOpts, service.WithCampaignWebhook("sendgrid", webhook))
+	}
+	if cfg.Campaigns.MailgunSigningKey != "" {
+		campaignOpts = append(campaignOpts, service.WithCampaignWebhook("mailgun", mailer.NewMailgunWebhook(cfg.Campaigns.MailgunSigningKey)))
+	}
+	campaignService := service.NewCampaignService(repository.NewPGXCampaignsRepository(pool), companiesRepo, campaignOpts...)
+
+	exportScheduleOpts := []service.ExportScheduleServiceOption{
+		service.WithExportWebhooks(outboundPolicy.Client(cfg.ExportSchedules.WebhookTimeout)),
+	}
+	if cfg.SMTP.Enabled() {
+		sender := mailer.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		exportScheduleOpts = append(exportScheduleOpts, service.WithExportMailer(sender))
+	}
+	if cfg.ExportSchedules.Bucket != "" {
+		store, err := storage.NewGCSStore(ctx, cfg.ExportSchedules.Bucket)
+		if err != nil {
+			log.Fatalf("failed to configure export schedule storage: %v", err)
+		}
+		exportScheduleOpts = append(exportScheduleOpts, service.WithExportStore(store, cfg.ExportSchedules.Prefix))
+	}
+	exportScheduleService := service.NewExportScheduleService(repository.NewPGXExportSchedulesRepository(pool), companiesService, usersRepo, exportScheduleOpts...)
+
+	var leadReportOpts []service.LeadReportServiceOption
+	if cfg.LeadReports.MapsAPIKey != "" {
+		leadReportOpts = append(leadReportOpts, service.WithReportMaps(outboundPolicy.Client(cfg.LeadReports.MapTimeout), cfg.LeadReports.MapsAPIKey))
+	}
+	leadReportService := service.NewLeadReportService(companiesRepo, companiesService, leadReportOpts...)
+
+	overviewService := service.NewOverviewService(repository.NewPGXOverviewRepository(pool, queryTimeout),
+		service.WithOverviewWorkerLatency(workerLatency))
+	jobRetryService := service.NewJobRetryService(repository.NewPGXRetryableJobsRepository(pool, queryTimeout))
+	orphanService := service.NewOrphanReconcileService(repository.NewPGXOrphansRepository(pool))
+
+	healthChecks := []handler.HealthCheck{
+		{Name: "database", Check: pool.Ping},
+		{Name: "worker", Check: workerClient.Ping},
+	}
+	if redisClient != nil {
+		healthChecks = append(healthChecks, handler.HealthCheck{
+			Name:  "redis",
+			Check: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() },
+		})
+	}
+	healthHandler := handler.NewHealthHandlerWithReadOnly(readOnly.ReadOnly, healthChecks...)
 
 	// ⚡ scrapeHandler menggunakan ID-token client otomatis
-	scrapeHandler := handler.NewScrapeHandlerWithWorker(workerClient)
+	scrapeJobService := service.NewScrapeJobService(repository.NewPGXScrapeJobsRepository(pool), cfg.ScrapeCoalesceWindow,
+		service.WithScrapeJobEventsInterval(cfg.ScrapeEventsInterval), service.WithScrapeJobWakeups(eventBus))
+	scrapeHandler := handler.NewScrapeHandlerWithJobs(workerClient, callbackSigner, scrapeJobService)
 
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	// Per-IP rate limits key on the client address, so only trusted proxies may supply it.
+	trustOptions := make([]echo.TrustOption, 0, len(cfg.TrustedProxies))
+	for _, cidr := range cfg.TrustedProxies {
+		_, network, _ := net.ParseCIDR(cidr)
+		trustOptions = append(trustOptions, echo.TrustIPRange(network))
+	}
+	e.IPExtractor = echo.ExtractIPFromXFFHeader(trustOptions...)
 
 	e.Use(middlewarepkg.RequestID())
+	e.Use(middlewarepkg.Language())
+	e.Use(middlewarepkg.Tracing())
 	e.Use(middlewarepkg.Logging())
-	e.Use(echoMiddleware.Recover())
+	e.Use(middlewarepkg.ReportErrors(errorReporter))
+	e.Use(middlewarepkg.Recover())
+	// Login only reads users, so it keeps working while the database is read-only.
+	e.Use(middlewarepkg.ReadOnlyGuard(readOnly, database.TrackReadOnly, "/auth/login"))
+	e.Use(echoMiddleware.GzipWithConfig(echoMiddleware.GzipConfig{
+		MinLength: 1024,
+		// Parquet downloads are already compressed, and event streams must not wait for a full buffer.
+		Skipper: func(c echo.Context) bool {
+			return strings.HasPrefix(c.Path(), "/admin/exports/") || strings.HasSuffix(c.Path(), "/events")
+		},
+	}))
+	if len(cfg.CORS.AllowOrigins) > 0 {
+		e.Use(echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
+			AllowOrigins: cfg.CORS.AllowOrigins,
+			// Lets browser dashboards read the ETag they send back in If-None-Match.
+			ExposeHeaders: []string{"ETag"},
+		}))
+	}
 
 	router.Register(e, cfg, jwtManager, router.Handlers{
-		Auth:        authHandler,
-		Users:       userAdminHandler,
-		Companies:   companiesHandler,
-		AdminUpload: adminUploadHandler,
-		Scrape:      scrapeHandler,
-		Enrich:      enrichHandler,
-		EnrichJob:   enrichJobHandler,
-		Prompt:      promptHandler,
+		Auth:         authHandler,
+		Users:        userAdminHandler,
+		Companies:    companiesHandler,
+		AdminUpload:  adminUploadHandler,
+		Scrape:       scrapeHandler,
+		Enrich:       enrichHandler,
+		EnrichJob:    enrichJobHandler,
+		Prompt:       promptHandler,
+		Health:       healthHandler,
+		Chains:       chainsHandler,
+		Reports:      reportsHandler,
+		Stats:        statsHandler,
+		Warehouse:    warehouseHandler,
+		Changes:      changesHandler,
+		PromptAlias:  promptAliasHandler,
+		Locations:    locationsHandler,
+		Categories:   categoriesHandler,
+		Imports:      importJobsHandler,
+		Attachments:  attachmentsHandler,
+		DNSCache:     handler.NewDNSCacheHandler(dnsCache),
+		QueryCache:   handler.NewQueryCacheHandler(queryCache),
+		Scoring:      handler.NewScoringProfilesHandler(scoringProfilesService),
+		Freshness:    handler.NewFreshnessHandler(freshnessService),
+		Ingest:       handler.NewIngestRunsHandler(ingestRunsService),
+		Recompute:    handler.NewScoreRecomputeHandler(scoreRecomputeService),
+		Collisions:   handler.NewContactCollisionsHandler(service.NewContactCollisionService(repository.NewPGXContactCollisionsRepository(pool, enrichmentCipher))),
+		Invites:      handler.NewInvitationsHandler(invitationService),
+		Features:     handler.NewFeatureTogglesHandler(service.NewFeatureToggleService(repository.NewPGXFeatureTogglesRepository(pool))),
+		Account:      handler.NewAccountHandler(accountService),
+		Privacy:      handler.NewPrivacyHandler(service.NewPrivacyService(repository.NewPGXPrivacyRepository(pool, enrichmentCipher), rawStore)),
+		Campaigns:    handler.NewCampaignsHandler(campaignService),
+		Suppressions: handler.NewSuppressionsHandler(suppressionService),
+		Reprocess:    handler.NewRawReprocessHandler(rawReprocessService),
+		Overview:     handler.NewAdminOverviewHandler(overviewService),
+		Jobs:         handler.NewAdminJobsHandler(workerClient, callbackSigner, jobRetryService),
+		DeadLetters:  handler.NewDeadLettersHandler(deadLetterService),
+		Maintenance:  handler.NewAdminMaintenanceHandler(orphanService),
+		APIKeys:      handler.NewAPIKeysHandler(service.NewAPIKeyService(repository.NewPGXAPIKeysRepository(pool))),
+		PlaceRefresh: handler.NewPlaceRefreshHandler(workerClient, companiesService),
+		Schedules:    handler.NewExportSchedulesHandler(exportScheduleService),
+		LeadReports:  handler.NewLeadReportsHandler(leadReportService),
 	})
 
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go readOnly.Run(backgroundCtx, pool, cfg.ReadOnlyProbe)
+	go func() {
+		if err := eventBus.Run(backgroundCtx); err != nil {
+			log.Printf("event bus stopped: %v", err)
+		}
+	}()
+	go queryCache.Run(backgroundCtx, eventBus)
+	if replica != nil {
+		go replica.Run(backgroundCtx, cfg.Replica.Probe)
+	}
+	go importJobService.Run(backgroundCtx, cfg.Imports.Workers)
+	go scoreRecomputeService.Run(backgroundCtx)
+	go rawReprocessService.Run(backgroundCtx)
+	if cfg.Campaigns.Enabled() {
+		go campaignService.RunSender(backgroundCtx, cfg.Campaigns.SendInterval)
+	}
+	if rawStore != nil && cfg.RawStore.OffloadInterval > 0 {
+		go companiesService.RunRawOffload(backgroundCtx, cfg.RawStore.OffloadInterval, service.DefaultRawOffloadBatchSize)
+	}
+	if cfg.ReconcileInterval > 0 {
+		go orphanService.Run(backgroundCtx, cfg.ReconcileInterval)
+	}
+	if attachmentService != nil {
+		go attachmentService.Run(backgroundCtx, cfg.Attachments.URLTTL)
+	}
+	if cfg.Prompt.AliasRefresh > 0 {
+		go promptAliasService.Run(backgroundCtx, cfg.Prompt.AliasRefresh)
+	}
+	if cfg.Locations.Refresh > 0 {
+		go locationService.Run(backgroundCtx, cfg.Locations.Refresh)
+	}
+	if cfg.Categories.Refresh > 0 {
+		go categoryService.Run(backgroundCtx, cfg.Categories.Refresh)
+	}
+	if cfg.ExportSchedules.Interval > 0 {
+		go exportScheduleService.Run(backgroundCtx, cfg.ExportSchedules.Interval)
+	}
+	if cfg.Enrich.DisposableListURL != "" {
+		go emailClassifier.RunRefresh(backgroundCtx, cfg.Enrich.DisposableListURL, cfg.Enrich.DisposableRefresh)
+	}
+	if cfg.WebsiteChecks.Interval > 0 {
+		websiteChecks := service.NewWebsiteCheckService(
+			repository.NewPGXWebsiteCheckRepository(pool),
+			service.WithWebsiteCheckPolicy(outboundPolicy, cfg.WebsiteChecks.Timeout),
+			service.WithWebsiteCheckBatch(cfg.WebsiteChecks.BatchSize, cfg.WebsiteChecks.Concurrency),
+			service.WithWebsiteRecheck(cfg.WebsiteChecks.Recheck),
+		)
+		go websiteChecks.Run(backgroundCtx, cfg.WebsiteChecks.Interval)
+	}
+	if cfg.ChangeSink.Enabled() {
+		table, err := storage.NewBigQueryTable(ctx, cfg.ChangeSink.Project, cfg.ChangeSink.Dataset, cfg.ChangeSink.Table)
+		if err != nil {
+			log.Fatalf("failed to configure bigquery change sink: %v", err)
+		}
+		sink := "bigquery:" + cfg.ChangeSink.Project + "." + cfg.ChangeSink.Dataset + "." + cfg.ChangeSink.Table
+		changeSink := service.NewChangeSinkService(repository.NewPGXChangeSinksRepository(pool), sink, table, cfg.ChangeSink.BatchSize)
+		go changeSink.Run(backgroundCtx, eventBus, cfg.ChangeSink.Interval)
+	}
+	if cfg.CompanyImages.Enabled() && cfg.CompanyImages.Interval > 0 {
+		var store service.ImageStore
+		if cfg.CompanyImages.Bucket != "" {
+			store, err = storage.NewGCSStore(ctx, cfg.CompanyImages.Bucket)
+		} else {
+			store, err = storage.NewLocalStore(cfg.CompanyImages.Dir)
+		}
+		if err != nil {
+			log.Fatalf("failed to configure company image store: %v", err)
+		}
+		imageMirror := service.NewCompanyImageMirrorService(companyImagesRepo, store, cfg.CompanyImages.Prefix, cfg.CompanyImages.PublicURL,
+			service.WithImageMirrorPolicy(outboundPolicy, cfg.CompanyImages.Timeout),
+			service.WithImageMirrorLimits(cfg.CompanyImages.BatchSize, cfg.CompanyImages.MaxBytes),
+			service.WithPlacesPhotoKey(cfg.CompanyImages.PlacesAPIKey),
+		)
+		go imageMirror.Run(backgroundCtx, cfg.CompanyImages.Interval)
+	}
+
 	serverErr := make(chan error, 1)
 	go func() {
 		serverErr <- e.Start(":" + cfg.Port)
 	}()
 
+	// Workers on the gRPC transport may report enrichment results over the WorkerCallbacks service
+	// rather than POST /enrich-result.
+	var callbackServer *grpc.Server
+	if cfg.Workers.CallbackGRPCAddr != "" {
+		listener, err := net.Listen("tcp", cfg.Workers.CallbackGRPCAddr)
+		if err != nil {
+			log.Fatalf("failed to listen for worker callbacks: %v", err)
+		}
+		callbackServer = grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+		workerpb.RegisterWorkerCallbacksServer(callbackServer, handler.NewGRPCWorkerCallbacksWithDeadLetters(companiesService, callbackSigner, deadLetterService))
+		healthServer := health.NewServer()
+		healthServer.SetServingStatus(workerpb.WorkerCallbacks_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+		healthpb.RegisterHealthServer(callbackServer, healthServer)
+		go func() {
+			serverErr <- callbackServer.Serve(listener)
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -103,4 +683,10 @@ func main() {
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
+	if callbackServer != nil {
+		callbackServer.GracefulStop()
+	}
+	for _, conn := range workerConns {
+		conn.Close()
+	}
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/config"
+)
+
+func newCallbackTokenCmd() *cobra.Command {
+	var (
+		kind      string
+		jobID     string
+		companies []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "callback-token",
+		Short: "Issue a worker callback token for a scrape run or an enrichment job",
+		Long: "Issue the token the worker presents to /ingest/runs (--kind scrape) or /enrich-result\n" +
+			"(--kind enrich). Jobs queued through the API get theirs automatically; use this to replay\n" +
+			"or backfill by hand. A scrape token covers one ingest run, a new one unless --job is given.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch kind {
+			case auth.CallbackScrape:
+			case auth.CallbackEnrich:
+				if len(companies) == 0 {
+					return errors.New("--company is required for enrich tokens")
+				}
+			default:
+				return fmt.Errorf("--kind must be %s or %s", auth.CallbackScrape, auth.CallbackEnrich)
+			}
+			if jobID == "" {
+				jobID = uuid.NewString()
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			token, err := auth.NewCallbackSigner(cfg.JWTSecret, cfg.CallbackTTL).Issue(kind, jobID, companies...)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "job_id=%s\ntoken=%s\n", jobID, token)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kind, "kind", auth.CallbackScrape, "callback the token is for: scrape or enrich")
+	cmd.Flags().StringVar(&jobID, "job", "", "ingest run or enrichment job id (default: a new id)")
+	cmd.Flags().StringSliceVar(&companies, "company", nil, "company ids an enrich token covers (repeatable)")
+	return cmd
+}
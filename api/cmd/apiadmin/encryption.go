@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+
+	"github.com/octobees/leads-generator/api/internal/config"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// newEnrichmentCipher builds the cipher of ENRICHMENT_ENCRYPTION_KEY, or nil when it is unset.
+func newEnrichmentCipher(cfg *config.Config) (*fieldcrypt.Cipher, error) {
+	if !cfg.Encryption.Enabled() {
+		return nil, nil
+	}
+	return fieldcrypt.New(cfg.Encryption.Key, cfg.Encryption.PreviousKeys...)
+}
+
+func newEncryptEnrichmentsCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "encrypt-enrichments",
+		Short: "Encrypt the stored emails and phone numbers of every enrichment with the active key",
+		Long: "Encrypt the emails and phone numbers of enrichments, website contacts and the domain cache\n" +
+			"still stored in plaintext or with a key listed in $ENRICHMENT_ENCRYPTION_PREVIOUS_KEYS, then\n" +
+			"rebuild the contact search terms of enrichments with the active key. Run it after setting\n" +
+			"$ENRICHMENT_ENCRYPTION_KEY on the API, and after every rotation before dropping the previous\n" +
+			"key. It is safe to re-run.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				if !cfg.Encryption.Enabled() {
+					return errors.New("ENRICHMENT_ENCRYPTION_KEY or ENRICHMENT_ENCRYPTION_KEY_FILE is required for encrypt-enrichments")
+				}
+				cipher, err := newEnrichmentCipher(cfg)
+				if err != nil {
+					return err
+				}
+				maintenance := newMaintenanceServiceWithCipher(cfg, pool, cipher,
+					service.WithContactEncryption(repository.NewPGXEnrichmentContactsRepository(pool), cipher),
+				)
+				for _, table := range repository.ContactTables {
+					result, err := maintenance.EncryptEnrichmentContacts(ctx, table, batchSize)
+					if err != nil {
+						return fmt.Errorf("encrypt %s (%d rewritten before failure): %w", table.Name, result.Encrypted, err)
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "encrypted %d of %d rows of %s\n", result.Encrypted, result.Scanned, table.Name)
+					if result.Changed > 0 {
+						fmt.Fprintf(cmd.OutOrStdout(), "%d rows of %s changed while running; run again to cover them\n", result.Changed, table.Name)
+					}
+				}
+				indexed, err := maintenance.IndexEnrichmentContacts(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("index enrichment contacts (%d rewritten before failure): %w", indexed.Updated, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "rebuilt the contact search terms of %d enrichments\n", indexed.Updated)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of rows processed per page")
+	return cmd
+}
+
+func newIndexContactsCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "index-contacts",
+		Short: "Rebuild the terms q_contact searches enrichment emails, phone numbers and handles by",
+		Long: "Rebuild the contact search terms of every enrichment from its emails, phone numbers and\n" +
+			"social profiles, tokenising emails and phone numbers with $ENRICHMENT_ENCRYPTION_KEY when it\n" +
+			"is set. Run it once after migration 0044. It is safe to re-run.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				cipher, err := newEnrichmentCipher(cfg)
+				if err != nil {
+					return err
+				}
+				result, err := newMaintenanceServiceWithCipher(cfg, pool, cipher).IndexEnrichmentContacts(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("index enrichment contacts (%d rewritten before failure): %w", result.Updated, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "rebuilt the contact search terms of %d of %d enrichments\n", result.Updated, result.Scanned)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of enrichments processed per page")
+	return cmd
+}
@@ -0,0 +1,15 @@
+// Command apiadmin bundles one-off administration tasks such as bootstrapping the first admin
+// user and database maintenance. It reads the same environment as the API server.
+package main
+
+import (
+	"os"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPasswordFlagsResolve(t *testing.T) {
+	p := passwordFlags{password: "inline"}
+	if got, err := p.resolve(nil); err != nil || got != "inline" {
+		t.Fatalf("expected inline password, got %q, %v", got, err)
+	}
+
+	p = passwordFlags{stdin: true}
+	if got, err := p.resolve(strings.NewReader("from-stdin\nignored\n")); err != nil || got != "from-stdin" {
+		t.Fatalf("expected stdin password, got %q, %v", got, err)
+	}
+
+	p = passwordFlags{}
+	if _, err := p.resolve(nil); err == nil {
+		t.Fatalf("expected error when no password given")
+	}
+
+	p = passwordFlags{password: "inline", stdin: true}
+	if _, err := p.resolve(strings.NewReader("x\n")); err == nil {
+		t.Fatalf("expected error when both sources given")
+	}
+}
+
+func TestPurgeRunRequiresConfirmation(t *testing.T) {
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"purge-run", "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected confirmation error, got %v", err)
+	}
+}
+
+func TestCreateAdminRequiresEmail(t *testing.T) {
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"create-admin", "--password", "secret"})
+
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--email") {
+		t.Fatalf("expected missing email error, got %v", err)
+	}
+}
+
+func TestExportWarehouseRejectsBadDate(t *testing.T) {
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"export-warehouse", "--date", "02/06/2024"})
+
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--date") {
+		t.Fatalf("expected invalid date error, got %v", err)
+	}
+}
+
+func TestCallbackTokenRequiresCompanyForEnrich(t *testing.T) {
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"callback-token", "--kind", "enrich"})
+
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--company") {
+		t.Fatalf("expected missing company error, got %v", err)
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+
+	"github.com/octobees/leads-generator/api/internal/config"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/service/legalform"
+	"github.com/octobees/leads-generator/api/internal/service/outreach"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+	"github.com/octobees/leads-generator/api/internal/service/sizing"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+func newMaintenanceService(cfg *config.Config, pool *pgxpool.Pool, opts ...service.MaintenanceServiceOption) *service.MaintenanceService {
+	return newMaintenanceServiceWithCipher(cfg, pool, nil, opts...)
+}
+
+// newMaintenanceServiceWithCipher builds a maintenance service reading enrichments encrypted with cipher.
+func newMaintenanceServiceWithCipher(cfg *config.Config, pool *pgxpool.Pool, cipher *fieldcrypt.Cipher, opts ...service.MaintenanceServiceOption) *service.MaintenanceService {
+	return service.NewMaintenanceService(
+		repository.NewPGXMaintenanceRepository(pool, repository.WithEnrichmentCipher(cipher)),
+		append([]service.MaintenanceServiceOption{
+			service.WithSizing(repository.NewPGXCompanySizeRepository(pool), scoring.Options{SizeWeight: cfg.Scoring.SizeWeight}),
+			service.WithOutreachLanguages(repository.NewPGXOutreachLanguageRepository(pool)),
+			service.WithScoringProfiles(repository.NewPGXScoringProfilesRepository(pool)),
+			service.WithLegalForms(repository.NewPGXLegalFormRepository(pool)),
+			service.WithContactNormalization(repository.NewPGXContactNormalizationRepository(pool)),
+			service.WithPlaceAttributes(repository.NewPGXCompaniesRepository(pool), nil),
+			service.WithContactTerms(repository.NewPGXCompaniesRepository(pool, repository.WithEnrichmentCipher(cipher))),
+		}, opts...)...,
+	)
+}
+
+func newReindexSearchCmd(connect connectFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex-search",
+		Short: "Rebuild the company search indexes and refresh planner statistics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				maintenance := newMaintenanceService(cfg, pool)
+				if err := maintenance.ReindexSearch(ctx); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "company indexes rebuilt")
+				return nil
+			})
+		},
+	}
+}
+
+func newRebuildLeadsViewCmd(connect connectFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild-leads-view",
+		Short: "Recompute the denormalized company listing from the live tables",
+		Long: "Recompute every row of leads_view, which company listings and exports read. Triggers keep\n" +
+			"it current; rebuild it after writing companies or enrichments with triggers disabled.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				rows, err := newMaintenanceService(cfg, pool).RebuildLeadsView(ctx)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "leads view rebuilt with %d companies\n", rows)
+				return nil
+			})
+		},
+	}
+}
+
+func newRecomputeScoresCmd(connect connectFunc) *cobra.Command {
+	var (
+		batchSize int
+		staleOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recompute-scores",
+		Short: "Recalculate and store lead scores for every enriched company",
+		Long: "Recalculate and store lead scores with the active scoring profile. After changing or\n" +
+			"activating a profile, --stale-only rescores just the leads scored with another one.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				cipher, err := newEnrichmentCipher(cfg)
+				if err != nil {
+					return err
+				}
+				maintenance := newMaintenanceServiceWithCipher(cfg, pool, cipher)
+				recompute := maintenance.RecomputeScores
+				if staleOnly {
+					recompute = maintenance.RecomputeStaleScores
+				}
+				scored, err := recompute(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("recompute scores (%d stored before failure): %w", scored, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "recomputed %d lead scores\n", scored)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of enrichments processed per page")
+	cmd.Flags().BoolVar(&staleOnly, "stale-only", false, "only rescore leads not scored with the current revision of the active profile")
+	return cmd
+}
+
+func newRecomputeSizesCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "recompute-sizes",
+		Short: "Re-estimate the business size bucket of every company",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				counts, err := newMaintenanceService(cfg, pool).RecomputeSizes(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("recompute sizes: %w", err)
+				}
+				out := cmd.OutOrStdout()
+				for _, bucket := range sizing.Buckets {
+					fmt.Fprintf(out, "%-8s %d\n", bucket, counts[bucket])
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of companies processed per page")
+	return cmd
+}
+
+func newRecomputeOutreachLanguagesCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "recompute-outreach-languages",
+		Short: "Re-detect the preferred outreach language of every company",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				counts, err := newMaintenanceService(cfg, pool).RecomputeOutreachLanguages(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("recompute outreach languages: %w", err)
+				}
+				out := cmd.OutOrStdout()
+				for _, language := range append(outreach.Languages, outreach.Unknown) {
+					if counts[language] > 0 {
+						fmt.Fprintf(out, "%-8s %d\n", language, counts[language])
+					}
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of companies processed per page")
+	return cmd
+}
+
+func newRecomputeLegalFormsCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "recompute-legal-forms",
+		Short: "Extract the legal form (PT, CV...) and display name of every company again",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				counts, err := newMaintenanceService(cfg, pool).RecomputeLegalForms(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("recompute legal forms: %w", err)
+				}
+				out := cmd.OutOrStdout()
+				for _, form := range append(legalform.Forms, legalform.None, service.LegalFormConflict) {
+					if counts[form] > 0 {
+						fmt.Fprintf(out, "%-9s %d\n", form, counts[form])
+					}
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of companies processed per page")
+	return cmd
+}
+
+func newNormalizeContactsCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "normalize-contacts",
+		Short: "Rewrite every company phone in E.164 and website as a canonical https URL",
+		Long: "Rewrite the stored company phones in E.164, read in the region of the company country, and\n" +
+			"websites as https URLs with a lowercase host and no tracking parameters. Values that do not\n" +
+			"parse are left as they are.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				result, err := newMaintenanceService(cfg, pool).NormalizeContacts(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("normalize contacts (%d companies updated before failure): %w", result.Companies, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "normalized %d companies (%d phones, %d websites)\n", result.Companies, result.Phones, result.Websites)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of companies processed per page")
+	return cmd
+}
+
+func newExportWarehouseCmd(connect connectFunc) *cobra.Command {
+	var date string
+
+	cmd := &cobra.Command{
+		Use:   "export-warehouse",
+		Short: "Write a dated Parquet partition of companies, enrichments and scores to GCS",
+		Long: "Write a dated Parquet partition of companies, enrichments and scores to\n" +
+			"gs://$WAREHOUSE_GCS_BUCKET/$WAREHOUSE_GCS_PREFIX/companies/dt=YYYY-MM-DD/.\n" +
+			"Schedule it daily (for example as a Cloud Run job triggered by Cloud Scheduler).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			partition := time.Now().UTC()
+			if date != "" {
+				parsed, err := time.Parse(time.DateOnly, date)
+				if err != nil {
+					return fmt.Errorf("invalid --date %q (use YYYY-MM-DD)", date)
+				}
+				partition = parsed
+			}
+
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				if cfg.Warehouse.Bucket == "" {
+					return errors.New("WAREHOUSE_GCS_BUCKET is required for export-warehouse")
+				}
+				store, err := storage.NewGCSStore(ctx, cfg.Warehouse.Bucket)
+				if err != nil {
+					return err
+				}
+				cipher, err := newEnrichmentCipher(cfg)
+				if err != nil {
+					return err
+				}
+				warehouse := service.NewWarehouseService(repository.NewPGXWarehouseRepository(pool, repository.WithEnrichmentCipher(cipher)), store, cfg.Warehouse.Prefix, cfg.Warehouse.RowsPerFile)
+				export, err := warehouse.ExportPartition(ctx, partition)
+				if err != nil {
+					return fmt.Errorf("export warehouse partition: %w", err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "exported %d rows in %d files to %s\n", export.RowCount, len(export.Files), export.Location)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&date, "date", "", "partition date (YYYY-MM-DD, default today in UTC)")
+	return cmd
+}
+
+func newPurgeRunCmd(connect connectFunc) *cobra.Command {
+	var (
+		dryRun bool
+		yes    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "purge-run <scrape-run-id>",
+		Short: "Delete every company (and its enrichment) imported by a scrape run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID := args[0]
+			if _, err := uuid.Parse(runID); err != nil {
+				return fmt.Errorf("invalid scrape run id %q", runID)
+			}
+			if !dryRun && !yes {
+				return errors.New("purge-run deletes data; re-run with --yes to confirm or --dry-run to preview")
+			}
+
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				maintenance := newMaintenanceService(cfg, pool)
+				affected, err := maintenance.PurgeScrapeRun(ctx, runID, dryRun)
+				if err != nil {
+					return err
+				}
+				if dryRun {
+					fmt.Fprintf(cmd.OutOrStdout(), "scrape run %s has %d companies (dry run, nothing deleted)\n", runID, affected)
+					return nil
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "deleted %d companies from scrape run %s\n", affected, runID)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only report how many companies would be deleted")
+	cmd.Flags().BoolVar(&yes, "yes", false, "confirm the deletion")
+	return cmd
+}
+
+func newPurgeUploadsCmd(connect connectFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "purge-uploads",
+		Short: "Delete retained CSV uploads whose retention period has ended",
+		Long: "Delete retained CSV uploads older than $UPLOAD_RETENTION from the upload archive\n" +
+			"($UPLOAD_ARCHIVE_GCS_BUCKET or $UPLOAD_ARCHIVE_DIR) together with their records.\n" +
+			"Schedule it daily next to export-warehouse.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				if !cfg.Uploads.Enabled() {
+					return errors.New("UPLOAD_ARCHIVE_GCS_BUCKET or UPLOAD_ARCHIVE_DIR is required for purge-uploads")
+				}
+				var (
+					store service.UploadStore
+					err   error
+				)
+				if cfg.Uploads.Bucket != "" {
+					store, err = storage.NewGCSStore(ctx, cfg.Uploads.Bucket)
+				} else {
+					store, err = storage.NewLocalStore(cfg.Uploads.Dir)
+				}
+				if err != nil {
+					return err
+				}
+				archive := service.NewUploadArchiveService(repository.NewPGXCSVUploadsRepository(pool), store, cfg.Uploads.Prefix, cfg.Uploads.Retention)
+				purged, err := archive.PurgeExpired(ctx)
+				if err != nil {
+					return fmt.Errorf("purge uploads (%d deleted before failure): %w", purged, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "deleted %d expired uploads\n", purged)
+				return nil
+			})
+		},
+	}
+}
+
+func newOffloadRawCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "offload-raw",
+		Short: "Move inline raw scrape payloads to the raw payload store",
+		Long: "Move every raw Places payload still stored in Postgres to the raw payload store\n" +
+			"($RAW_STORE_GCS_BUCKET, $RAW_STORE_S3_BUCKET or $RAW_STORE_DIR), keeping only the object\n" +
+			"key and the fields SQL reads. Use it for the first migration or with RAW_OFFLOAD_INTERVAL=0.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				if !cfg.RawStore.Enabled() {
+					return errors.New("RAW_STORE_GCS_BUCKET, RAW_STORE_S3_BUCKET or RAW_STORE_DIR is required for offload-raw")
+				}
+				store, err := openRawStore(ctx, cfg)
+				if err != nil {
+					return err
+				}
+				companies := repository.NewPGXCompaniesRepository(pool)
+				svc := service.NewCompaniesService(companies, service.WithRawPayloads(companies), service.WithRawStore(store, cfg.RawStore.Prefix))
+				moved, err := svc.OffloadRawPayloads(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("offload raw payloads (%d moved before failure): %w", moved, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "moved %d raw payloads\n", moved)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", service.DefaultRawOffloadBatchSize, "number of payloads listed per page")
+	return cmd
+}
+
+func newReconcileOrphansCmd(connect connectFunc) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "reconcile-orphans",
+		Short: "Re-link or archive enrichment rows of companies that no longer exist",
+		Long: "Move enrichments, website contacts, lead scores and snapshots left pointing at a deleted\n" +
+			"company to the company scraped again under the same place id, and archive the rest to\n" +
+			"orphan_archive. Use it with RECONCILE_INTERVAL=0, e.g. after restoring a backup.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				report, err := service.NewOrphanReconcileService(repository.NewPGXOrphansRepository(pool)).Reconcile(ctx, dryRun)
+				if err != nil {
+					return err
+				}
+				out := cmd.OutOrStdout()
+				for _, table := range report.Tables {
+					fmt.Fprintf(out, "%s: %d orphaned, %d re-linked, %d archived\n", table.Table, table.Orphans, table.Relinked, table.Archived)
+				}
+				if dryRun {
+					fmt.Fprintln(out, "dry run, nothing changed")
+					return nil
+				}
+				fmt.Fprintf(out, "archived rows are kept in orphan_archive under run %s\n", report.RunID)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would change without changing it")
+	return cmd
+}
+
+// openRawStore opens the raw payload store configured in cfg.
+func openRawStore(ctx context.Context, cfg *config.Config) (service.BlobStore, error) {
+	switch {
+	case cfg.RawStore.GCSBucket != "":
+		return storage.NewGCSStore(ctx, cfg.RawStore.GCSBucket)
+	case cfg.RawStore.S3Bucket != "":
+		return storage.NewS3Store(storage.S3Config{
+			Bucket:          cfg.RawStore.S3Bucket,
+			Region:          cfg.RawStore.S3Region,
+			Endpoint:        cfg.RawStore.S3Endpoint,
+			AccessKeyID:     cfg.RawStore.S3AccessKeyID,
+			SecretAccessKey: cfg.RawStore.S3SecretAccessKey,
+			SessionToken:    cfg.RawStore.S3SessionToken,
+		})
+	default:
+		return storage.NewLocalStore(cfg.RawStore.Dir)
+	}
+}
+
+func newExtractPlaceAttributesCmd(connect connectFunc) *cobra.Command {
+	var batchSize int
+
+	cmd := &cobra.Command{
+		Use:   "extract-place-attributes",
+		Short: "Extract the opening hours, price level and categories of every company from its raw payload",
+		Long: "Extract the opening hours, price level and categories of every company from its raw place\n" +
+			"payload again, for companies written before the columns existed or by the worker. Offloaded\n" +
+			"payloads are read from the raw payload store when one is configured; otherwise those companies\n" +
+			"keep their attributes.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				var opts []service.MaintenanceServiceOption
+				if cfg.RawStore.Enabled() {
+					store, err := openRawStore(ctx, cfg)
+					if err != nil {
+						return err
+					}
+					opts = append(opts, service.WithPlaceAttributes(repository.NewPGXCompaniesRepository(pool), store))
+				}
+				result, err := newMaintenanceService(cfg, pool, opts...).ExtractPlaceAttributes(ctx, batchSize)
+				if err != nil {
+					return fmt.Errorf("extract place attributes (%d companies updated before failure): %w", result.Companies, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "extracted %d companies (%d opening hours, %d price levels, %d categories); %d offloaded skipped\n",
+					result.Companies, result.OpeningHours, result.PriceLevels, result.Categories, result.Offloaded)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 500, "number of companies processed per page")
+	return cmd
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+
+	"github.com/octobees/leads-generator/api/internal/config"
+	"github.com/octobees/leads-generator/api/internal/database"
+)
+
+func newRootCmd() *cobra.Command {
+	var timeout time.Duration
+
+	root := &cobra.Command{
+		Use:          "apiadmin",
+		Short:        "Administrative tasks for the leads generator API",
+		SilenceUsage: true,
+	}
+	root.PersistentFlags().DurationVar(&timeout, "timeout", 10*time.Minute, "maximum time a command may run")
+
+	connect := func(cmd *cobra.Command, fn func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		defer cancel()
+
+		pool, err := database.Connect(ctx, cfg.DatabaseURL, database.WithStatementCache(cfg.Pool.ExecMode, cfg.Pool.StatementCache))
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+		defer pool.Close()
+
+		return fn(ctx, cfg, pool)
+	}
+
+	root.AddCommand(
+		newCreateAdminCmd(connect),
+		newResetPasswordCmd(connect),
+		newReindexSearchCmd(connect),
+		newRebuildLeadsViewCmd(connect),
+		newRecomputeScoresCmd(connect),
+		newRecomputeSizesCmd(connect),
+		newRecomputeOutreachLanguagesCmd(connect),
+		newRecomputeLegalFormsCmd(connect),
+		newNormalizeContactsCmd(connect),
+		newExtractPlaceAttributesCmd(connect),
+		newExportWarehouseCmd(connect),
+		newPurgeRunCmd(connect),
+		newPurgeUploadsCmd(connect),
+		newOffloadRawCmd(connect),
+		newReconcileOrphansCmd(connect),
+		newEncryptEnrichmentsCmd(connect),
+		newIndexContactsCmd(connect),
+		newCallbackTokenCmd(),
+	)
+	return root
+}
+
+// connectFunc loads configuration and opens the database for the duration of a command.
+type connectFunc func(cmd *cobra.Command, fn func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error) error
+
+// passwordFlags lets commands accept a password inline or, preferably, from stdin.
+type passwordFlags struct {
+	password string
+	stdin    bool
+}
+
+func (p *passwordFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.password, "password", "", "password (visible in shell history; prefer --password-stdin)")
+	cmd.Flags().BoolVar(&p.stdin, "password-stdin", false, "read the password from the first line of stdin")
+}
+
+func (p *passwordFlags) resolve(in io.Reader) (string, error) {
+	if p.stdin && p.password != "" {
+		return "", errors.New("use either --password or --password-stdin, not both")
+	}
+	if !p.stdin {
+		if p.password == "" {
+			return "", errors.New("a password is required (--password or --password-stdin)")
+		}
+		return p.password, nil
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password read from stdin is empty")
+	}
+	return password, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+
+	"github.com/octobees/leads-generator/api/internal/config"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+func newCreateAdminCmd(connect connectFunc) *cobra.Command {
+	var (
+		email string
+		pw    passwordFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create a user with the admin role",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			email = strings.TrimSpace(email)
+			if email == "" {
+				return errors.New("--email is required")
+			}
+			password, err := pw.resolve(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				users := service.NewUserService(repository.NewPGXUsersRepository(pool))
+				user, err := users.CreateUser(ctx, dto.CreateUserRequest{Email: email, Password: password, Role: "admin"})
+				if err != nil {
+					if errors.Is(err, repository.ErrEmailDuplicate) {
+						return fmt.Errorf("user %s already exists; use reset-password to change its password", email)
+					}
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "created admin %s (%s)\n", user.Email, user.ID)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "e-mail address of the new admin")
+	pw.register(cmd)
+	return cmd
+}
+
+func newResetPasswordCmd(connect connectFunc) *cobra.Command {
+	var (
+		email string
+		pw    passwordFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reset-password",
+		Short: "Set a new password for an existing user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			email = strings.TrimSpace(email)
+			if email == "" {
+				return errors.New("--email is required")
+			}
+			password, err := pw.resolve(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			return connect(cmd, func(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool) error {
+				users := service.NewUserService(repository.NewPGXUsersRepository(pool))
+				if err := users.ResetPassword(ctx, email, password); err != nil {
+					if errors.Is(err, repository.ErrUserNotFound) {
+						return fmt.Errorf("user %s not found", email)
+					}
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "password updated for %s\n", email)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "e-mail address of the user")
+	pw.register(cmd)
+	return cmd
+}
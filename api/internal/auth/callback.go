@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Callback token kinds: the worker endpoint a token lets the worker call back.
+const (
+	CallbackEnrich = "enrich"
+	CallbackScrape = "scrape"
+)
+
+// DefaultCallbackTTL is how long a worker may take to call back when no lifetime is configured.
+const DefaultCallbackTTL = time.Hour
+
+// callbackAudience prefixes the audience of callback tokens; the kind completes it.
+const callbackAudience = "worker-callback:"
+
+// CallbackClaims scope a worker callback to one job and, for enrichment, the companies it covers.
+// The job id travels as the registered jti claim.
+type CallbackClaims struct {
+	jwt.RegisteredClaims
+	CompanyIDs []string `json:"company_ids,omitempty"`
+}
+
+// JobID returns the job the callback belongs to.
+func (c *CallbackClaims) JobID() string {
+	return c.ID
+}
+
+// CoversCompany reports whether the token was issued for companyID.
+func (c *CallbackClaims) CoversCompany(companyID string) bool {
+	companyID = strings.ToLower(strings.TrimSpace(companyID))
+	return companyID != "" && slices.Contains(c.CompanyIDs, companyID)
+}
+
+// CallbackSigner issues and verifies the short-lived tokens the API embeds in jobs it sends to the
+// worker. Its key is derived from the JWT secret, so callback tokens and access tokens are never
+// accepted in place of one another.
+type CallbackSigner struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCallbackSigner derives a signing key from secret; a non-positive ttl means DefaultCallbackTTL.
+func NewCallbackSigner(secret string, ttl time.Duration) *CallbackSigner {
+	if ttl <= 0 {
+		ttl = DefaultCallbackTTL
+	}
+	var key []byte
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("worker-callback"))
+		key = mac.Sum(nil)
+	}
+	return &CallbackSigner{key: key, ttl: ttl, now: time.Now}
+}
+
+// TTL reports the lifetime of issued callback tokens.
+func (s *CallbackSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue signs a token letting the worker call back the kind endpoint for jobID and companyIDs.
+func (s *CallbackSigner) Issue(kind, jobID string, companyIDs ...string) (string, error) {
+	if len(s.key) == 0 {
+		return "", errors.New("jwt secret must not be empty")
+	}
+	if kind == "" || jobID == "" {
+		return "", errors.New("callback kind and job id are required")
+	}
+	ids := make([]string, 0, len(companyIDs))
+	for _, id := range companyIDs {
+		ids = append(ids, strings.ToLower(strings.TrimSpace(id)))
+	}
+
+	now := s.now()
+	claims := &CallbackClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jobID,
+			Audience:  jwt.ClaimStrings{callbackAudience + kind},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		CompanyIDs: ids,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+}
+
+// Verify checks the token signature, expiry and kind, and returns its scope.
+func (s *CallbackSigner) Verify(token, kind string) (*CallbackClaims, error) {
+	parsed, err := jwt.ParseWithClaims(token, &CallbackClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.key, nil
+	}, jwt.WithAudience(callbackAudience+kind), jwt.WithExpirationRequired(), jwt.WithTimeFunc(s.now))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := parsed.Claims.(*CallbackClaims)
+	if !ok || !parsed.Valid || claims.ID == "" {
+		return nil, errors.New("invalid callback token claims")
+	}
+	return claims, nil
+}
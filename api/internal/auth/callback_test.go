@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallbackSigner_Scope(t *testing.T) {
+	signer := NewCallbackSigner("secret", time.Minute)
+	token, err := signer.Issue(CallbackEnrich, "job-1", "AAAAAAAA-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	claims, err := signer.Verify(token, CallbackEnrich)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.JobID() != "job-1" || !claims.CoversCompany("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa") || claims.CoversCompany("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb") {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	if _, err := signer.Verify(token, CallbackScrape); err == nil {
+		t.Fatal("expected an enrich token to be refused for scrape callbacks")
+	}
+	if _, err := NewCallbackSigner("other", time.Minute).Verify(token, CallbackEnrich); err == nil {
+		t.Fatal("expected a token signed with another secret to be refused")
+	}
+
+	signer.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := signer.Verify(token, CallbackEnrich); err == nil {
+		t.Fatal("expected an expired token to be refused")
+	}
+}
+
+func TestCallbackSigner_SeparateFromAccessTokens(t *testing.T) {
+	signer := NewCallbackSigner("secret", time.Minute)
+	manager := NewJWTManager("secret", time.Minute)
+
+	access, _ := manager.GenerateToken("user-1", "user@example.com", "admin")
+	if _, err := signer.Verify(access, CallbackEnrich); err == nil {
+		t.Fatal("expected an access token to be refused as a callback token")
+	}
+	callback, _ := signer.Issue(CallbackScrape, "run-1")
+	if _, err := manager.ParseToken(callback); err == nil {
+		t.Fatal("expected a callback token to be refused as an access token")
+	}
+}
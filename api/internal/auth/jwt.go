@@ -12,6 +12,8 @@ type Claims struct {
 	jwt.RegisteredClaims
 	Email string `json:"email"`
 	Role  string `json:"role"`
+	// SessionID names the refresh token session the access token was issued for, when there is one.
+	SessionID string `json:"sid,omitempty"`
 }
 
 // JWTManager handles issuing and verifying HMAC signed tokens.
@@ -30,6 +32,11 @@ func NewJWTManager(secret string, ttl time.Duration) *JWTManager {
 
 // GenerateToken creates a short-lived access token for the provided subject.
 func (m *JWTManager) GenerateToken(subject, email, role string) (string, error) {
+	return m.GenerateSessionToken(subject, email, role, "")
+}
+
+// GenerateSessionToken creates an access token tied to a refresh token session.
+func (m *JWTManager) GenerateSessionToken(subject, email, role, sessionID string) (string, error) {
 	if len(m.secret) == 0 {
 		return "", errors.New("jwt secret must not be empty")
 	}
@@ -40,8 +47,9 @@ func (m *JWTManager) GenerateToken(subject, email, role string) (string, error)
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-		Email: email,
-		Role:  role,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -53,6 +61,11 @@ func (m *JWTManager) GenerateToken(subject, email, role string) (string, error)
 	return signed, nil
 }
 
+// TTL reports the lifetime of issued access tokens.
+func (m *JWTManager) TTL() time.Duration {
+	return m.ttl
+}
+
 // ParseToken verifies the token signature and payload integrity.
 func (m *JWTManager) ParseToken(token string) (*Claims, error) {
 	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(t *jwt.Token) (interface{}, error) {
@@ -0,0 +1,47 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.Form.Get("secret") != "s3cret" || r.Form.Get("remoteip") != "203.0.113.7" {
+			t.Fatalf("unexpected form: %v", r.Form)
+		}
+		resp := siteVerifyResponse{Success: r.Form.Get("response") == "good"}
+		if !resp.Success {
+			resp.ErrorCodes = []string{"invalid-input-response"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	verifier := NewSiteVerifier(server.URL, "s3cret", server.Client())
+	if err := verifier.Verify(context.Background(), "good", "203.0.113.7"); err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+	if err := verifier.Verify(context.Background(), "bad", "203.0.113.7"); !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected ErrRejected, got %v", err)
+	}
+}
+
+func TestSiteVerifier_ProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewSiteVerifier(server.URL, "s3cret", server.Client()).Verify(context.Background(), "good", "")
+	if err == nil || errors.Is(err, ErrRejected) {
+		t.Fatalf("expected provider error distinct from rejection, got %v", err)
+	}
+}
@@ -1,51 +1,1759 @@
 package config
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// RateLimitConfig indicates how many requests are allowed within a given interval.
+// Supported deployment profiles.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+const (
+	devJWTSecret      = "dev-secret"
+	minJWTSecretBytes = 32
+	// defaultRateLimitBudget lets a user list 120 times a minute, or scrape 12 times, in bursts of
+	// twice that; admins get five times as much.
+	defaultRateLimitBudget = "120/min burst=240 cost:scrape=10 cost:prompt_search=10 cost:enrich=5 cost:export=5 role:admin=5"
+	// defaultRateLimitWorkers keeps the global worker call rate RATE_LIMIT_SCRAPE used to default to.
+	defaultRateLimitWorkers = "5/min"
+)
+
+// RateLimitConfig is a token budget: Requests tokens per Interval, spent one per request unless
+// Costs says otherwise.
 type RateLimitConfig struct {
 	Requests int
 	Interval time.Duration
+	// Burst is how many tokens a client may spend at once; zero means Requests.
+	Burst int
+	// Costs maps an operation onto the tokens it spends; other operations spend one.
+	Costs map[string]int
+	// Roles scales the rate and burst of callers in a role; other roles get the plain budget.
+	Roles map[string]float64
+}
+
+// BurstSize returns how many tokens a client may spend at once.
+func (c RateLimitConfig) BurstSize() int {
+	if c.Burst > 0 {
+		return c.Burst
+	}
+	return c.Requests
+}
+
+// Cost returns the tokens an operation spends.
+func (c RateLimitConfig) Cost(operation string) int {
+	if cost, ok := c.Costs[operation]; ok {
+		return cost
+	}
+	return 1
+}
+
+// Multiplier returns how much the budget of role is scaled by.
+func (c RateLimitConfig) Multiplier(role string) float64 {
+	if multiplier, ok := c.Roles[role]; ok {
+		return multiplier
+	}
+	return 1
+}
+
+// CaptchaConfig enables challenge verification on the auth endpoints; an empty Provider disables it.
+type CaptchaConfig struct {
+	// Provider is turnstile, hcaptcha or recaptcha.
+	Provider string
+	Secret   string
+}
+
+// Enabled reports whether auth requests must carry a solved challenge.
+func (c CaptchaConfig) Enabled() bool {
+	return c.Provider != ""
+}
+
+// RedisConfig holds the optional Redis connection settings.
+type RedisConfig struct {
+	URL string
+	// EventsChannel is the pub/sub channel replicas share their events on; empty keeps events
+	// within each replica.
+	EventsChannel string
+}
+
+// SMTPConfig holds outbound e-mail settings; Host empty means e-mail is disabled.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Enabled reports whether SMTP delivery has been configured.
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// CampaignsConfig selects the e-mail provider outreach campaigns are sent through.
+type CampaignsConfig struct {
+	// Provider is smtp, sendgrid or mailgun; empty leaves campaigns as drafts that cannot be sent.
+	Provider string
+	// From is the sender address of campaign messages.
+	From string
+	// PublicURL is the base URL recipients reach the API at, for the open pixel and unsubscribe links.
+	PublicURL string
+	// BatchSize is how many messages each pass of the send loop delivers, every SendInterval.
+	BatchSize    int
+	SendInterval time.Duration
+
+	SendGridAPIKey string
+	// SendGridWebhookKey is the verification key of the signed event webhook; empty refuses events.
+	SendGridWebhookKey string
+	MailgunAPIKey      string
+	MailgunDomain      string
+	MailgunBaseURL     string
+	// MailgunSigningKey verifies Mailgun webhooks; empty refuses events.
+	MailgunSigningKey string
+}
+
+// Enabled reports whether campaigns can be sent.
+func (c CampaignsConfig) Enabled() bool {
+	return c.Provider != ""
+}
+
+// WebsiteChecksConfig tunes the background checker of company website liveness.
+type WebsiteChecksConfig struct {
+	// Interval is how often a batch of websites is checked; zero disables the checker.
+	Interval time.Duration
+	// Recheck is how old a check may get before the website is checked again.
+	Recheck     time.Duration
+	BatchSize   int
+	Concurrency int
+	// Timeout bounds each website request, redirects included.
+	Timeout time.Duration
+}
+
+// CompanyImagesConfig mirrors company logos and Maps photos to object storage. Setting Bucket or
+// Dir enables it.
+type CompanyImagesConfig struct {
+	// Bucket is the GCS bucket keeping mirrored images.
+	Bucket string
+	// Dir is a local directory used instead of GCS, for development.
+	Dir    string
+	Prefix string
+	// PublicURL is where the bucket or directory is served from; image URLs are PublicURL followed
+	// by the object name.
+	PublicURL string
+	// PlacesAPIKey resolves Maps photo references; without it only logos are mirrored.
+	PlacesAPIKey string
+	// Interval is how often a batch of new images is mirrored; zero disables mirroring.
+	Interval  time.Duration
+	BatchSize int
+	// MaxBytes limits a single image and Timeout each download.
+	MaxBytes int64
+	Timeout  time.Duration
+}
+
+// Enabled reports whether company images are mirrored.
+func (c CompanyImagesConfig) Enabled() bool {
+	return c.Bucket != "" || c.Dir != ""
+}
+
+// OutboundConfig limits the HTTP requests the API makes to URLs taken from payloads, and to the
+// worker. Internal addresses are refused unless an Allow entry covers them.
+type OutboundConfig struct {
+	// Allow and Deny list hosts, IPs or CIDR ranges; a deny entry wins over an allow entry.
+	Allow []string
+	Deny  []string
+	// MaxRedirects caps the redirects followed per request; zero follows none.
+	MaxRedirects int
+	// MaxConnsPerHost bounds the connections open to one host at a time; zero removes the bound.
+	MaxConnsPerHost int
+}
+
+// WorkersConfig routes worker jobs between the default worker at WorkerBaseURL and extra
+// deployments.
+type WorkersConfig struct {
+	// Timeout bounds each call to the default worker; zero keeps the client default.
+	Timeout time.Duration
+	// ScrapeTimeout and EnrichTimeout bound /scrape and /enrich calls to any worker instead of
+	// its timeout; zero keeps the worker timeout.
+	ScrapeTimeout time.Duration
+	EnrichTimeout time.Duration
+	// DeadlineMargin ends worker calls this long before the deadline of the request, so the API
+	// can still answer once they give up.
+	DeadlineMargin time.Duration
+	// Routes are tried in order before the default worker; a job goes to the first healthy route
+	// matching it.
+	Routes []WorkerRoute
+	// FailoverCooldown is how long a worker that could not be reached is skipped.
+	FailoverCooldown time.Duration
+	// Transport is how jobs reach workers: http posts JSON to their base URL, grpc calls the
+	// Worker service at their GRPCAddr.
+	Transport string
+	// GRPCAddr is the host:port of the default worker's gRPC service; GRPCTLS dials it over TLS
+	// with ID tokens, as Cloud Run expects.
+	GRPCAddr string
+	GRPCTLS  bool
+	// CallbackGRPCAddr is the address the API serves the WorkerCallbacks gRPC service on; empty
+	// leaves it off.
+	CallbackGRPCAddr string
+}
+
+// Worker transports.
+const (
+	WorkerTransportHTTP = "http"
+	WorkerTransportGRPC = "grpc"
+)
+
+// WorkerRoute sends the jobs matching it to another worker deployment.
+type WorkerRoute struct {
+	Name    string
+	BaseURL string
+	// Countries restricts the route to scrapes of these countries, compared case-insensitively.
+	Countries []string
+	// Jobs restricts the route to these job types, scrape or enrich.
+	Jobs []string
+	// Timeout bounds each call to the worker; zero keeps the client default.
+	Timeout time.Duration
+	// GRPCAddr is the host:port of the worker's gRPC service, used with the grpc transport.
+	GRPCAddr string
+}
+
+// workerJobTypes are the job types routes may name.
+var workerJobTypes = []string{"scrape", "enrich", "lookup"}
+
+// CORSConfig lists the browser origins allowed to call the API.
+type CORSConfig struct {
+	AllowOrigins []string
+}
+
+// EnrichConfig holds enrichment accounting settings.
+type EnrichConfig struct {
+	// DailyQuota is the number of cost units a user may spend per rolling 24h; zero disables the quota.
+	DailyQuota int
+	// CacheTTL is how long a domain-level enrichment may be reused for other companies; zero disables reuse.
+	CacheTTL time.Duration
+	// Validation is strict, lenient or off and decides what happens to invalid worker values.
+	Validation string
+	// PhoneRegion is the ISO region assumed for phone numbers without a country code.
+	PhoneRegion string
+	// DisposableListURL adds a remote list of disposable email domains to the bundled one.
+	DisposableListURL string
+	// DisposableRefresh is how often the remote list is reloaded; zero loads it only at startup.
+	DisposableRefresh time.Duration
+	// CheckConcurrency bounds the MX lookups and URL checks running at once.
+	CheckConcurrency int
+	// CheckTimeout caps the time one payload may spend on those checks.
+	CheckTimeout time.Duration
+	// CheckCacheTTL is how long URL check results are reused; zero disables the cache.
+	CheckCacheTTL time.Duration
+	// DNSCacheSize is how many domains the shared MX lookup cache holds; zero disables it.
+	DNSCacheSize int
+	// DNSPositiveTTL and DNSNegativeTTL are how long domains with and without mail servers are cached.
+	DNSPositiveTTL time.Duration
+	DNSNegativeTTL time.Duration
+}
+
+// ScoringConfig tunes optional lead scoring factors.
+type ScoringConfig struct {
+	// SizeWeight is the maximum number of points the business size factor adds; zero disables it.
+	SizeWeight int
+	// SearchWeight is the share, from 0 to 1, of the lead score when searches rank by relevance.
+	SearchWeight float64
+}
+
+// StatsConfig tunes the dashboard stats endpoint.
+type StatsConfig struct {
+	// CacheTTL is how long computed stats are reused; zero recomputes on every request.
+	CacheTTL time.Duration
+	// ScrapeFreshFor is how long scraped companies count as fresh in scrape previews.
+	ScrapeFreshFor time.Duration
+}
+
+// QueryCacheConfig controls the cache of company listings and stats.
+type QueryCacheConfig struct {
+	// Store is off, memory for a per-replica LRU, or redis for one cache shared through REDIS_URL.
+	Store string
+	// TTL bounds how long a result is served when no invalidating event arrives.
+	TTL time.Duration
+	// Size is how many results the memory store holds.
+	Size int
+	// RedisKey is the hash the redis store keeps its results in.
+	RedisKey string
+}
+
+// WarehouseConfig controls the Parquet export to Google Cloud Storage.
+type WarehouseConfig struct {
+	// Bucket is the GCS bucket receiving partitions; empty disables scheduled exports.
+	Bucket      string
+	Prefix      string
+	RowsPerFile int
+}
+
+// ChangeSinkConfig streams the change feed into a BigQuery table. Setting Dataset enables it.
+type ChangeSinkConfig struct {
+	Project string
+	Dataset string
+	Table   string
+	// Interval is how often changes the event bus did not announce are looked for.
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Enabled reports whether changes are streamed to BigQuery.
+func (c ChangeSinkConfig) Enabled() bool {
+	return c.Dataset != ""
+}
+
+// UploadsConfig controls retention of admin CSV uploads. Setting Bucket or Dir enables it.
+type UploadsConfig struct {
+	// Bucket is the GCS bucket keeping retained uploads.
+	Bucket string
+	// Dir is a local directory used instead of GCS, for development.
+	Dir       string
+	Prefix    string
+	Retention time.Duration
+}
+
+// Enabled reports whether uploads are retained.
+func (u UploadsConfig) Enabled() bool {
+	return u.Bucket != "" || u.Dir != ""
+}
+
+// ImportsConfig tunes asynchronous admin import jobs.
+type ImportsConfig struct {
+	// Workers is how many import jobs are processed concurrently.
+	Workers int
+	// QueueSize bounds how many jobs may wait for a worker before uploads are refused.
+	QueueSize int
+	// BatchSize is how many rows are written per transaction.
+	BatchSize int
+	// Dir keeps files of pending jobs when upload retention is disabled.
+	Dir string
+}
+
+// AttachmentsConfig controls company attachments. Setting Bucket enables them.
+type AttachmentsConfig struct {
+	// Bucket is the GCS bucket keeping attachment files.
+	Bucket string
+	// SignerKeyFile is a service account JSON key used to sign upload and download URLs.
+	SignerKeyFile string
+	Prefix        string
+	// MaxFileBytes limits a single file, UserQuotaBytes the total stored per uploader and OrgQuotaBytes
+	// the total stored by the members of an invitation org; zero disables any of them.
+	MaxFileBytes   int64
+	UserQuotaBytes int64
+	OrgQuotaBytes  int64
+	// URLTTL is how long signed URLs stay valid.
+	URLTTL time.Duration
+}
+
+// Enabled reports whether attachments are available.
+func (a AttachmentsConfig) Enabled() bool {
+	return a.Bucket != ""
+}
+
+// RawStoreConfig moves raw scrape payloads out of Postgres. Setting GCSBucket, S3Bucket or Dir
+// enables it.
+type RawStoreConfig struct {
+	GCSBucket string
+	// S3Bucket, S3Region and S3Endpoint locate an S3 or S3 compatible bucket; the credentials come
+	// from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+	S3Bucket          string
+	S3Region          string
+	S3Endpoint        string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3SessionToken    string
+	// Dir is a local directory used instead of a bucket, for development.
+	Dir    string
+	Prefix string
+	// OffloadInterval is how often inline payloads are moved; zero leaves it to apiadmin offload-raw.
+	OffloadInterval time.Duration
+}
+
+// Enabled reports whether raw payloads are offloaded.
+func (r RawStoreConfig) Enabled() bool {
+	return r.GCSBucket != "" || r.S3Bucket != "" || r.Dir != ""
+}
+
+// ReplicaConfig routes heavy listing and export reads to a Postgres read replica. Setting URL
+// enables it.
+type ReplicaConfig struct {
+	URL string
+	// MaxLag is how far the replica may trail the primary before reads fall back to the primary;
+	// zero disables the lag check.
+	MaxLag time.Duration
+	// Probe is how often replica health and lag are checked.
+	Probe time.Duration
+}
+
+// TracingConfig exports OpenTelemetry traces of requests, queries and worker calls. Setting
+// Endpoint enables it; the exporter reads the other OTEL_EXPORTER_OTLP_* variables itself.
+type TracingConfig struct {
+	Endpoint    string
+	ServiceName string
+	// SampleRatio is the share of new traces recorded; traces started upstream follow the caller.
+	SampleRatio float64
+}
+
+// Enabled reports whether traces are exported.
+func (t TracingConfig) Enabled() bool {
+	return t.Endpoint != ""
+}
+
+// ErrorReportingConfig sends server errors to Sentry. Setting DSN enables it.
+type ErrorReportingConfig struct {
+	DSN         string
+	Environment string
+	// SampleRate is the share of errors reported.
+	SampleRate float64
+}
+
+// Enabled reports whether errors are reported.
+func (e ErrorReportingConfig) Enabled() bool {
+	return e.DSN != ""
+}
+
+// PoolConfig tunes how the API talks to Postgres.
+type PoolConfig struct {
+	// QueryTimeout bounds listing, search, facet, trend and stats queries; zero leaves them unbounded.
+	QueryTimeout time.Duration
+	// SlowQuery logs queries taking at least this long; zero disables the log.
+	SlowQuery time.Duration
+	// ExecMode overrides pgx's default_query_exec_mode; empty keeps the DSN setting.
+	ExecMode string
+	// StatementCache sizes the per-connection statement cache; zero keeps the pgx default.
+	StatementCache int
+}
+
+// queryExecModes lists the accepted DB_QUERY_EXEC_MODE values.
+var queryExecModes = map[string]bool{
+	"cache_statement": true,
+	"cache_describe":  true,
+	"describe_exec":   true,
+	"exec":            true,
+	"simple_protocol": true,
+}
+
+// AccountConfig tunes self-service account management.
+type AccountConfig struct {
+	// RefreshTTL is how long an unused refresh token lasts; zero disables refresh tokens and sessions.
+	RefreshTTL time.Duration
+	// EmailVerifyURL is the page that email change confirmations link to, with a token query
+	// parameter; unset mails the token itself.
+	EmailVerifyURL string
+}
+
+// EncryptionConfig holds the AES-256 keys encrypting enrichment emails and phone numbers at rest.
+type EncryptionConfig struct {
+	// Key encrypts new values; nil stores them in plaintext.
+	Key []byte
+	// PreviousKeys still decrypt values written before the key was rotated.
+	PreviousKeys [][]byte
+}
+
+// Enabled reports whether enrichment contacts are encrypted.
+func (c EncryptionConfig) Enabled() bool {
+	return c.Key != nil
+}
+
+// ExportsConfig caps how many companies a single CSV export may hold, per role; zero means unlimited.
+type ExportsConfig struct {
+	UserRowCap  int64
+	AdminRowCap int64
+}
+
+// PromptConfig tunes the prompt search parser.
+type PromptConfig struct {
+	// AliasRefresh is how often aliases are reloaded from the database; zero loads them only at startup.
+	AliasRefresh time.Duration
+}
+
+// LocationsConfig configures the location catalog city values are normalised against.
+type LocationsConfig struct {
+	// Refresh is how often the catalog is reloaded from the database; zero loads it only at startup.
+	Refresh time.Duration
+	// GeocoderURL is the search endpoint of a Nominatim compatible geocoder locating new locations;
+	// empty stores locations with the coordinates they are sent with.
+	GeocoderURL       string
+	GeocoderUserAgent string
+}
+
+// CategoriesConfig configures the business category taxonomy business types are normalised against.
+type CategoriesConfig struct {
+	// Refresh is how often the taxonomy is reloaded from the database; zero loads it only at startup.
+	Refresh time.Duration
+}
+
+// ExportSchedulesConfig configures scheduled exports and the destinations they deliver to. Email
+// deliveries go through the SMTP settings.
+type ExportSchedulesConfig struct {
+	// Interval is how often due schedules are looked for; zero leaves them to run only on request.
+	Interval time.Duration
+	// Bucket is the GCS bucket of gcs deliveries, stored under Prefix; empty refuses them.
+	Bucket string
+	Prefix string
+	// WebhookTimeout bounds posting an export to a webhook.
+	WebhookTimeout time.Duration
+}
+
+// LeadReportsConfig configures the PDF lead sheets of companies.
+type LeadReportsConfig struct {
+	// MapsAPIKey fetches the map thumbnails of lead sheets from the Maps Static API; without it the
+	// sheets show coordinates instead.
+	MapsAPIKey string
+	// MapTimeout bounds fetching a map thumbnail.
+	MapTimeout time.Duration
 }
 
 // Config aggregates application-wide configuration values.
 type Config struct {
-	DatabaseURL     string
-	JWTSecret       string
-	Port            string
-	WorkerBaseURL   string
-	PromptCountry   string
-	RateLimitScrape RateLimitConfig
+	Env           string
+	DatabaseURL   string
+	JWTSecret     string
+	Port          string
+	WorkerBaseURL string
+	Workers       WorkersConfig
+	PromptCountry string
+	SchemaCheck   string
+	// ReadOnlyProbe is how often the API checks whether the database accepts writes.
+	ReadOnlyProbe  time.Duration
+	Replica        ReplicaConfig
+	Pool           PoolConfig
+	Tracing        TracingConfig
+	ErrorReporting ErrorReportingConfig
+	// RateLimitBudget is the weighted budget each caller spends on listings, exports and worker
+	// jobs, by user when authenticated and by IP otherwise.
+	RateLimitBudget RateLimitConfig
+	// RateLimitWorkers is one budget all callers share on worker jobs, guarding the Google Places
+	// quota the worker spends on their behalf.
+	RateLimitWorkers RateLimitConfig
+	// ScrapeCoalesceWindow is how long a queued scrape absorbs identical requests; zero disables it.
+	ScrapeCoalesceWindow time.Duration
+	// ScrapeEventsInterval is how often scrape job event streams read the jobs they follow.
+	ScrapeEventsInterval time.Duration
+	// RateLimitAuth limits each client IP on /auth.
+	RateLimitAuth RateLimitConfig
+	// RateLimitAPIKey limits each API key on POST /companies/batch.
+	RateLimitAPIKey RateLimitConfig
 	TokenTTL        time.Duration
+	CallbackTTL     time.Duration
+	Account         AccountConfig
+	Redis           RedisConfig
+	SMTP            SMTPConfig
+	CORS            CORSConfig
+	Enrich          EnrichConfig
+	Scoring         ScoringConfig
+	Stats           StatsConfig
+	QueryCache      QueryCacheConfig
+	Warehouse       WarehouseConfig
+	ChangeSink      ChangeSinkConfig
+	Uploads         UploadsConfig
+	Imports         ImportsConfig
+	Prompt          PromptConfig
+	Locations       LocationsConfig
+	Categories      CategoriesConfig
+	ExportSchedules ExportSchedulesConfig
+	LeadReports     LeadReportsConfig
+	// TrustedProxies lists proxy CIDRs whose X-Forwarded-For entries are trusted to find client IPs.
+	TrustedProxies []string
+	Captcha        CaptchaConfig
+	Attachments    AttachmentsConfig
+	Exports        ExportsConfig
+	RawStore       RawStoreConfig
+	Outbound       OutboundConfig
+	Encryption     EncryptionConfig
+	Campaigns      CampaignsConfig
+	WebsiteChecks  WebsiteChecksConfig
+	CompanyImages  CompanyImagesConfig
+	// ReconcileInterval is how often enrichment rows of companies that no longer exist are re-linked
+	// or archived; zero leaves it to POST /admin/maintenance/reconcile and apiadmin reconcile-orphans.
+	ReconcileInterval time.Duration
+	// LeadsView sends company listings, counts and exports to the leads_view table instead of
+	// joining the live tables per query.
+	LeadsView bool
+	// Warnings lists settings Load accepted but that should be changed, such as deprecated variables;
+	// the caller logs them.
+	Warnings []string
+}
+
+// IsDevelopment reports whether the service runs with the development profile.
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
 }
 
-// Load reads configuration from environment variables and applies sane defaults.
+// Load reads configuration from environment variables, applies profile defaults and validates the result.
 func Load() (*Config, error) {
+	// An unset profile is production, so a deployment that forgets APP_ENV gets the strict
+	// validation instead of the development JWT secret.
+	env, err := parseEnv(getEnv("APP_ENV", EnvProduction))
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSchemaCheck := "warn"
+	if env == EnvProduction {
+		defaultSchemaCheck = "strict"
+	}
+	// Login limits would only get in the way of local testing.
+	defaultAuthLimit := "10/min"
+	if env == EnvDevelopment {
+		defaultAuthLimit = "off"
+	}
+
 	cfg := &Config{
+		Env:           env,
 		DatabaseURL:   os.Getenv("DATABASE_URL"),
-		JWTSecret:     getEnv("JWT_SECRET", "dev-secret"),
+		JWTSecret:     os.Getenv("JWT_SECRET"),
 		Port:          getEnv("PORT", "8080"),
 		WorkerBaseURL: getEnv("WORKER_BASE_URL", "http://worker:9000"),
 		PromptCountry: getEnv("PROMPT_DEFAULT_COUNTRY", "Indonesia"),
+		SchemaCheck:   strings.ToLower(getEnv("SCHEMA_CHECK", defaultSchemaCheck)),
 		TokenTTL:      parseDuration(getEnv("JWT_TTL", "24h")),
+		Redis: RedisConfig{
+			URL:           os.Getenv("REDIS_URL"),
+			EventsChannel: strings.TrimSpace(getEnv("REDIS_EVENTS_CHANNEL", "leadsgen:events")),
+		},
+		SMTP: SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		},
+		CORS: CORSConfig{
+			AllowOrigins: splitList(os.Getenv("CORS_ALLOW_ORIGINS")),
+		},
+		TrustedProxies: splitList(os.Getenv("TRUSTED_PROXIES")),
+		Captcha: CaptchaConfig{
+			Provider: strings.ToLower(strings.TrimSpace(os.Getenv("CAPTCHA_PROVIDER"))),
+			Secret:   os.Getenv("CAPTCHA_SECRET"),
+		},
+	}
+	if cfg.JWTSecret == "" && env == EnvDevelopment {
+		cfg.JWTSecret = devJWTSecret
+	}
+
+	if cfg.RateLimitBudget, err = parseOptionalRateLimit(getEnv("RATE_LIMIT_BUDGET", defaultRateLimitBudget)); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_BUDGET value: %w", err)
+	}
+	// RATE_LIMIT_SCRAPE was the global limit on worker calls RATE_LIMIT_WORKERS now sets, so it still
+	// applies until that is set. The per-IP RATE_LIMIT_PUBLIC is covered by the caller budget.
+	workersLimit := getEnv("RATE_LIMIT_WORKERS", defaultRateLimitWorkers)
+	if legacy := strings.TrimSpace(os.Getenv("RATE_LIMIT_SCRAPE")); legacy != "" {
+		if os.Getenv("RATE_LIMIT_WORKERS") == "" {
+			workersLimit = legacy
+			cfg.Warnings = append(cfg.Warnings, "RATE_LIMIT_SCRAPE is deprecated; rename it to RATE_LIMIT_WORKERS")
+		} else {
+			cfg.Warnings = append(cfg.Warnings, "RATE_LIMIT_SCRAPE is deprecated and ignored because RATE_LIMIT_WORKERS is set")
+		}
+	}
+	if os.Getenv("RATE_LIMIT_PUBLIC") != "" {
+		cfg.Warnings = append(cfg.Warnings, "RATE_LIMIT_PUBLIC is deprecated and ignored; public listings spend RATE_LIMIT_BUDGET")
+	}
+	if cfg.RateLimitWorkers, err = parseOptionalRateLimit(workersLimit); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_WORKERS value: %w", err)
+	}
+	if len(cfg.RateLimitWorkers.Roles) > 0 {
+		return nil, errors.New("invalid RATE_LIMIT_WORKERS value: role settings do not apply to a shared budget")
+	}
+	if cfg.ScrapeCoalesceWindow, err = time.ParseDuration(getEnv("SCRAPE_COALESCE_WINDOW", "6h")); err != nil {
+		return nil, fmt.Errorf("invalid SCRAPE_COALESCE_WINDOW value: %w", err)
+	}
+	if cfg.ScrapeEventsInterval, err = time.ParseDuration(getEnv("SCRAPE_EVENTS_INTERVAL", "2s")); err != nil {
+		return nil, fmt.Errorf("invalid SCRAPE_EVENTS_INTERVAL value: %w", err)
+	}
+
+	if cfg.RateLimitAuth, err = parseOptionalRateLimit(getEnv("RATE_LIMIT_AUTH", defaultAuthLimit)); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_AUTH value: %w", err)
+	}
+	if cfg.RateLimitAPIKey, err = parseOptionalRateLimit(getEnv("RATE_LIMIT_API_KEY", "60/min")); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_API_KEY value: %w", err)
+	}
+
+	port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_PORT value: %w", err)
+	}
+	cfg.SMTP.Port = port
+
+	quota, err := strconv.Atoi(getEnv("ENRICH_DAILY_QUOTA", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_DAILY_QUOTA value: %w", err)
+	}
+	cfg.Enrich.DailyQuota = quota
+	if cfg.Encryption, err = loadEncryption(); err != nil {
+		return nil, err
+	}
+	cfg.Outbound.Allow = splitList(os.Getenv("OUTBOUND_ALLOW"))
+	cfg.Outbound.Deny = splitList(os.Getenv("OUTBOUND_DENY"))
+	if cfg.Outbound.MaxRedirects, err = strconv.Atoi(getEnv("OUTBOUND_MAX_REDIRECTS", "3")); err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_MAX_REDIRECTS value: %w", err)
+	}
+	if cfg.Outbound.MaxConnsPerHost, err = strconv.Atoi(getEnv("OUTBOUND_MAX_CONNS_PER_HOST", "4")); err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_MAX_CONNS_PER_HOST value: %w", err)
+	}
+
+	cacheTTL, err := time.ParseDuration(getEnv("ENRICH_CACHE_TTL", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_CACHE_TTL value: %w", err)
+	}
+	cfg.Enrich.CacheTTL = cacheTTL
+	cfg.Enrich.Validation = strings.ToLower(getEnv("ENRICH_VALIDATION", "lenient"))
+	cfg.Enrich.PhoneRegion = strings.ToUpper(getEnv("ENRICH_PHONE_REGION", "ID"))
+	cfg.Enrich.DisposableListURL = strings.TrimSpace(os.Getenv("EMAIL_DISPOSABLE_LIST_URL"))
+	if cfg.Enrich.DisposableRefresh, err = time.ParseDuration(getEnv("EMAIL_DISPOSABLE_REFRESH", "24h")); err != nil {
+		return nil, fmt.Errorf("invalid EMAIL_DISPOSABLE_REFRESH value: %w", err)
+	}
+	if cfg.Enrich.CheckConcurrency, err = strconv.Atoi(getEnv("ENRICH_CHECK_CONCURRENCY", "16")); err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_CHECK_CONCURRENCY value: %w", err)
+	}
+	if cfg.Enrich.CheckTimeout, err = time.ParseDuration(getEnv("ENRICH_CHECK_TIMEOUT", "10s")); err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_CHECK_TIMEOUT value: %w", err)
+	}
+	if cfg.Enrich.CheckCacheTTL, err = time.ParseDuration(getEnv("ENRICH_CHECK_CACHE_TTL", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_CHECK_CACHE_TTL value: %w", err)
+	}
+	if cfg.Enrich.DNSCacheSize, err = strconv.Atoi(getEnv("ENRICH_DNS_CACHE_SIZE", "10000")); err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_DNS_CACHE_SIZE value: %w", err)
+	}
+	if cfg.Enrich.DNSPositiveTTL, err = time.ParseDuration(getEnv("ENRICH_DNS_POSITIVE_TTL", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_DNS_POSITIVE_TTL value: %w", err)
+	}
+	if cfg.Enrich.DNSNegativeTTL, err = time.ParseDuration(getEnv("ENRICH_DNS_NEGATIVE_TTL", "10m")); err != nil {
+		return nil, fmt.Errorf("invalid ENRICH_DNS_NEGATIVE_TTL value: %w", err)
+	}
+
+	sizeWeight, err := strconv.Atoi(getEnv("SCORE_SIZE_WEIGHT", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCORE_SIZE_WEIGHT value: %w", err)
+	}
+	cfg.Scoring.SizeWeight = sizeWeight
+	if cfg.Scoring.SearchWeight, err = strconv.ParseFloat(getEnv("SEARCH_SCORE_WEIGHT", "0.3"), 64); err != nil {
+		return nil, fmt.Errorf("invalid SEARCH_SCORE_WEIGHT value: %w", err)
+	}
+
+	statsTTL, err := time.ParseDuration(getEnv("STATS_CACHE_TTL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATS_CACHE_TTL value: %w", err)
+	}
+	cfg.Stats.CacheTTL = statsTTL
+	if cfg.Stats.ScrapeFreshFor, err = time.ParseDuration(getEnv("SCRAPE_FRESH_FOR", "168h")); err != nil {
+		return nil, fmt.Errorf("invalid SCRAPE_FRESH_FOR value: %w", err)
+	}
+	cfg.QueryCache.Store = strings.ToLower(strings.TrimSpace(getEnv("QUERY_CACHE", "off")))
+	cfg.QueryCache.RedisKey = strings.TrimSpace(getEnv("REDIS_QUERY_CACHE_KEY", "leadsgen:query-cache"))
+	if cfg.QueryCache.TTL, err = time.ParseDuration(getEnv("QUERY_CACHE_TTL", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid QUERY_CACHE_TTL value: %w", err)
+	}
+	if cfg.QueryCache.Size, err = strconv.Atoi(getEnv("QUERY_CACHE_SIZE", "1000")); err != nil {
+		return nil, fmt.Errorf("invalid QUERY_CACHE_SIZE value: %w", err)
+	}
+
+	rowsPerFile, err := strconv.Atoi(getEnv("WAREHOUSE_ROWS_PER_FILE", "250000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WAREHOUSE_ROWS_PER_FILE value: %w", err)
+	}
+	cfg.Warehouse = WarehouseConfig{
+		Bucket:      strings.TrimSpace(os.Getenv("WAREHOUSE_GCS_BUCKET")),
+		Prefix:      getEnv("WAREHOUSE_GCS_PREFIX", "warehouse"),
+		RowsPerFile: rowsPerFile,
+	}
+
+	cfg.ChangeSink = ChangeSinkConfig{
+		Project: strings.TrimSpace(getEnv("BIGQUERY_SINK_PROJECT", os.Getenv("GOOGLE_CLOUD_PROJECT"))),
+		Dataset: strings.TrimSpace(os.Getenv("BIGQUERY_SINK_DATASET")),
+		Table:   strings.TrimSpace(getEnv("BIGQUERY_SINK_TABLE", "change_events")),
+	}
+	if cfg.ChangeSink.Interval, err = time.ParseDuration(getEnv("BIGQUERY_SINK_INTERVAL", "1m")); err != nil {
+		return nil, fmt.Errorf("invalid BIGQUERY_SINK_INTERVAL value: %w", err)
+	}
+	if cfg.ChangeSink.BatchSize, err = strconv.Atoi(getEnv("BIGQUERY_SINK_BATCH_SIZE", "500")); err != nil {
+		return nil, fmt.Errorf("invalid BIGQUERY_SINK_BATCH_SIZE value: %w", err)
+	}
+
+	uploadRetention, err := time.ParseDuration(getEnv("UPLOAD_RETENTION", "2160h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPLOAD_RETENTION value: %w", err)
+	}
+	cfg.Uploads = UploadsConfig{
+		Bucket:    strings.TrimSpace(os.Getenv("UPLOAD_ARCHIVE_GCS_BUCKET")),
+		Dir:       strings.TrimSpace(os.Getenv("UPLOAD_ARCHIVE_DIR")),
+		Prefix:    getEnv("UPLOAD_ARCHIVE_PREFIX", "uploads"),
+		Retention: uploadRetention,
+	}
+
+	importWorkers, err := strconv.Atoi(getEnv("IMPORT_WORKERS", "1"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMPORT_WORKERS value: %w", err)
+	}
+	importQueue, err := strconv.Atoi(getEnv("IMPORT_QUEUE_SIZE", "20"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMPORT_QUEUE_SIZE value: %w", err)
+	}
+	importBatch, err := strconv.Atoi(getEnv("IMPORT_BATCH_SIZE", "500"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMPORT_BATCH_SIZE value: %w", err)
+	}
+	cfg.Imports = ImportsConfig{
+		Workers:   importWorkers,
+		QueueSize: importQueue,
+		BatchSize: importBatch,
+		Dir:       getEnv("IMPORT_DIR", filepath.Join(os.TempDir(), "leads-imports")),
+	}
+
+	rawOffload, err := time.ParseDuration(getEnv("RAW_OFFLOAD_INTERVAL", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RAW_OFFLOAD_INTERVAL value: %w", err)
+	}
+	cfg.RawStore = RawStoreConfig{
+		GCSBucket:         strings.TrimSpace(os.Getenv("RAW_STORE_GCS_BUCKET")),
+		S3Bucket:          strings.TrimSpace(os.Getenv("RAW_STORE_S3_BUCKET")),
+		S3Region:          strings.TrimSpace(os.Getenv("RAW_STORE_S3_REGION")),
+		S3Endpoint:        strings.TrimSpace(os.Getenv("RAW_STORE_S3_ENDPOINT")),
+		S3AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		S3SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Dir:               strings.TrimSpace(os.Getenv("RAW_STORE_DIR")),
+		Prefix:            getEnv("RAW_STORE_PREFIX", "raw"),
+		OffloadInterval:   rawOffload,
+	}
+
+	readOnlyProbe, err := time.ParseDuration(getEnv("DB_READONLY_PROBE_INTERVAL", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_READONLY_PROBE_INTERVAL value: %w", err)
+	}
+	cfg.ReadOnlyProbe = readOnlyProbe
+
+	replicaLag, err := time.ParseDuration(getEnv("DATABASE_REPLICA_MAX_LAG", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DATABASE_REPLICA_MAX_LAG value: %w", err)
+	}
+	replicaProbe, err := time.ParseDuration(getEnv("DATABASE_REPLICA_PROBE_INTERVAL", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DATABASE_REPLICA_PROBE_INTERVAL value: %w", err)
+	}
+	cfg.Replica = ReplicaConfig{
+		URL:    strings.TrimSpace(os.Getenv("DATABASE_REPLICA_URL")),
+		MaxLag: replicaLag,
+		Probe:  replicaProbe,
+	}
+
+	sampleRatio, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO value: %w", err)
+	}
+	cfg.Tracing = TracingConfig{
+		Endpoint:    strings.TrimSpace(getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))),
+		ServiceName: getEnv("OTEL_SERVICE_NAME", "leads-generator-api"),
+		SampleRatio: sampleRatio,
+	}
+
+	errorSampleRate, err := strconv.ParseFloat(getEnv("ERROR_REPORT_SAMPLE_RATE", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ERROR_REPORT_SAMPLE_RATE value: %w", err)
+	}
+	cfg.ErrorReporting = ErrorReportingConfig{
+		DSN:         strings.TrimSpace(os.Getenv("SENTRY_DSN")),
+		Environment: getEnv("SENTRY_ENVIRONMENT", cfg.Env),
+		SampleRate:  errorSampleRate,
+	}
+
+	if cfg.Workers, err = loadWorkers(); err != nil {
+		return nil, err
+	}
+
+	callbackTTL, err := time.ParseDuration(getEnv("WORKER_CALLBACK_TTL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WORKER_CALLBACK_TTL value: %w", err)
+	}
+	cfg.CallbackTTL = callbackTTL
+
+	refreshTTL, err := time.ParseDuration(getEnv("JWT_REFRESH_TTL", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TTL value: %w", err)
+	}
+	cfg.Account = AccountConfig{
+		RefreshTTL:     refreshTTL,
+		EmailVerifyURL: strings.TrimSpace(os.Getenv("EMAIL_VERIFY_URL")),
+	}
+
+	queryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT value: %w", err)
+	}
+	slowQuery, err := time.ParseDuration(getEnv("DB_SLOW_QUERY_THRESHOLD", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_SLOW_QUERY_THRESHOLD value: %w", err)
+	}
+	statementCache, err := strconv.Atoi(getEnv("DB_STATEMENT_CACHE_SIZE", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_STATEMENT_CACHE_SIZE value: %w", err)
+	}
+	cfg.Pool = PoolConfig{
+		QueryTimeout:   queryTimeout,
+		SlowQuery:      slowQuery,
+		ExecMode:       strings.ToLower(strings.TrimSpace(os.Getenv("DB_QUERY_EXEC_MODE"))),
+		StatementCache: statementCache,
+	}
+
+	attachmentMaxMB, err := strconv.ParseInt(getEnv("ATTACHMENTS_MAX_FILE_MB", "100"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENTS_MAX_FILE_MB value: %w", err)
+	}
+	attachmentQuotaMB, err := strconv.ParseInt(getEnv("ATTACHMENTS_USER_QUOTA_MB", "1024"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENTS_USER_QUOTA_MB value: %w", err)
+	}
+	attachmentOrgQuotaMB, err := strconv.ParseInt(getEnv("ATTACHMENTS_ORG_QUOTA_MB", "10240"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENTS_ORG_QUOTA_MB value: %w", err)
+	}
+	attachmentURLTTL, err := time.ParseDuration(getEnv("ATTACHMENTS_URL_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENTS_URL_TTL value: %w", err)
+	}
+	cfg.Attachments = AttachmentsConfig{
+		Bucket:         strings.TrimSpace(os.Getenv("ATTACHMENTS_GCS_BUCKET")),
+		SignerKeyFile:  getEnv("ATTACHMENTS_SIGNER_KEY_FILE", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")),
+		Prefix:         getEnv("ATTACHMENTS_PREFIX", "attachments"),
+		MaxFileBytes:   attachmentMaxMB << 20,
+		UserQuotaBytes: attachmentQuotaMB << 20,
+		OrgQuotaBytes:  attachmentOrgQuotaMB << 20,
+		URLTTL:         attachmentURLTTL,
+	}
+
+	exportUserCap, err := strconv.ParseInt(getEnv("EXPORT_ROW_CAP_USER", "1000"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_ROW_CAP_USER value: %w", err)
+	}
+	exportAdminCap, err := strconv.ParseInt(getEnv("EXPORT_ROW_CAP_ADMIN", "0"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_ROW_CAP_ADMIN value: %w", err)
+	}
+	cfg.Exports = ExportsConfig{UserRowCap: exportUserCap, AdminRowCap: exportAdminCap}
+
+	aliasRefresh, err := time.ParseDuration(getEnv("PROMPT_ALIAS_REFRESH", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROMPT_ALIAS_REFRESH value: %w", err)
 	}
+	cfg.Prompt.AliasRefresh = aliasRefresh
 
-	rl, err := parseRateLimit(getEnv("RATE_LIMIT_SCRAPE", "5/min"))
+	locationsRefresh, err := time.ParseDuration(getEnv("LOCATIONS_REFRESH", "5m"))
 	if err != nil {
-		return nil, fmt.Errorf("invalid RATE_LIMIT_SCRAPE value: %w", err)
+		return nil, fmt.Errorf("invalid LOCATIONS_REFRESH value: %w", err)
+	}
+	cfg.Locations = LocationsConfig{
+		Refresh:           locationsRefresh,
+		GeocoderURL:       strings.TrimSpace(getEnv("GEOCODER_URL", "")),
+		GeocoderUserAgent: getEnv("GEOCODER_USER_AGENT", "leads-generator"),
 	}
-	cfg.RateLimitScrape = rl
 
+	categoriesRefresh, err := time.ParseDuration(getEnv("CATEGORIES_REFRESH", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CATEGORIES_REFRESH value: %w", err)
+	}
+	cfg.Categories.Refresh = categoriesRefresh
+
+	exportSchedulesInterval, err := time.ParseDuration(getEnv("EXPORT_SCHEDULES_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_SCHEDULES_INTERVAL value: %w", err)
+	}
+	exportWebhookTimeout, err := time.ParseDuration(getEnv("EXPORT_SCHEDULES_WEBHOOK_TIMEOUT", "2m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_SCHEDULES_WEBHOOK_TIMEOUT value: %w", err)
+	}
+	cfg.ExportSchedules = ExportSchedulesConfig{
+		Interval:       exportSchedulesInterval,
+		Bucket:         strings.TrimSpace(getEnv("EXPORT_SCHEDULES_GCS_BUCKET", "")),
+		Prefix:         getEnv("EXPORT_SCHEDULES_GCS_PREFIX", "exports"),
+		WebhookTimeout: exportWebhookTimeout,
+	}
+
+	reportMapTimeout, err := time.ParseDuration(getEnv("LEAD_REPORTS_MAP_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEAD_REPORTS_MAP_TIMEOUT value: %w", err)
+	}
+	cfg.LeadReports = LeadReportsConfig{
+		MapsAPIKey: strings.TrimSpace(os.Getenv("LEAD_REPORTS_MAPS_API_KEY")),
+		MapTimeout: reportMapTimeout,
+	}
+
+	campaignBatch, err := strconv.Atoi(getEnv("CAMPAIGN_BATCH_SIZE", "50"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAMPAIGN_BATCH_SIZE value: %w", err)
+	}
+	campaignInterval, err := time.ParseDuration(getEnv("CAMPAIGN_SEND_INTERVAL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAMPAIGN_SEND_INTERVAL value: %w", err)
+	}
+	cfg.Campaigns = CampaignsConfig{
+		Provider:           strings.ToLower(strings.TrimSpace(os.Getenv("CAMPAIGN_PROVIDER"))),
+		From:               getEnv("CAMPAIGN_FROM", cfg.SMTP.From),
+		PublicURL:          strings.TrimRight(strings.TrimSpace(os.Getenv("CAMPAIGN_PUBLIC_URL")), "/"),
+		BatchSize:          campaignBatch,
+		SendInterval:       campaignInterval,
+		SendGridAPIKey:     os.Getenv("SENDGRID_API_KEY"),
+		SendGridWebhookKey: strings.TrimSpace(os.Getenv("SENDGRID_WEBHOOK_VERIFICATION_KEY")),
+		MailgunAPIKey:      os.Getenv("MAILGUN_API_KEY"),
+		MailgunDomain:      strings.TrimSpace(os.Getenv("MAILGUN_DOMAIN")),
+		MailgunBaseURL:     getEnv("MAILGUN_API_URL", "https://api.mailgun.net"),
+		MailgunSigningKey:  os.Getenv("MAILGUN_WEBHOOK_SIGNING_KEY"),
+	}
+
+	if cfg.WebsiteChecks, err = loadWebsiteChecks(); err != nil {
+		return nil, err
+	}
+	if cfg.CompanyImages, err = loadCompanyImages(); err != nil {
+		return nil, err
+	}
+	if cfg.ReconcileInterval, err = time.ParseDuration(getEnv("RECONCILE_INTERVAL", "6h")); err != nil {
+		return nil, fmt.Errorf("invalid RECONCILE_INTERVAL value: %w", err)
+	}
+	if cfg.LeadsView, err = strconv.ParseBool(getEnv("LEADS_VIEW", "true")); err != nil {
+		return nil, fmt.Errorf("invalid LEADS_VIEW value: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+func loadWebsiteChecks() (WebsiteChecksConfig, error) {
+	var (
+		checks WebsiteChecksConfig
+		err    error
+	)
+	if checks.Interval, err = time.ParseDuration(getEnv("WEBSITE_CHECK_INTERVAL", "15m")); err != nil {
+		return checks, fmt.Errorf("invalid WEBSITE_CHECK_INTERVAL value: %w", err)
+	}
+	if checks.Recheck, err = time.ParseDuration(getEnv("WEBSITE_CHECK_RECHECK", "168h")); err != nil {
+		return checks, fmt.Errorf("invalid WEBSITE_CHECK_RECHECK value: %w", err)
+	}
+	if checks.BatchSize, err = strconv.Atoi(getEnv("WEBSITE_CHECK_BATCH_SIZE", "100")); err != nil {
+		return checks, fmt.Errorf("invalid WEBSITE_CHECK_BATCH_SIZE value: %w", err)
+	}
+	if checks.Concurrency, err = strconv.Atoi(getEnv("WEBSITE_CHECK_CONCURRENCY", "8")); err != nil {
+		return checks, fmt.Errorf("invalid WEBSITE_CHECK_CONCURRENCY value: %w", err)
+	}
+	if checks.Timeout, err = time.ParseDuration(getEnv("WEBSITE_CHECK_TIMEOUT", "10s")); err != nil {
+		return checks, fmt.Errorf("invalid WEBSITE_CHECK_TIMEOUT value: %w", err)
+	}
+	return checks, nil
+}
+
+func loadCompanyImages() (CompanyImagesConfig, error) {
+	images := CompanyImagesConfig{
+		Bucket:       strings.TrimSpace(os.Getenv("COMPANY_IMAGES_GCS_BUCKET")),
+		Dir:          strings.TrimSpace(os.Getenv("COMPANY_IMAGES_DIR")),
+		Prefix:       getEnv("COMPANY_IMAGES_PREFIX", "company-images"),
+		PublicURL:    strings.TrimRight(strings.TrimSpace(os.Getenv("COMPANY_IMAGES_PUBLIC_URL")), "/"),
+		PlacesAPIKey: strings.TrimSpace(os.Getenv("COMPANY_IMAGES_PLACES_API_KEY")),
+	}
+	var err error
+	if images.Interval, err = time.ParseDuration(getEnv("COMPANY_IMAGES_INTERVAL", "10m")); err != nil {
+		return images, fmt.Errorf("invalid COMPANY_IMAGES_INTERVAL value: %w", err)
+	}
+	if images.BatchSize, err = strconv.Atoi(getEnv("COMPANY_IMAGES_BATCH_SIZE", "50")); err != nil {
+		return images, fmt.Errorf("invalid COMPANY_IMAGES_BATCH_SIZE value: %w", err)
+	}
+	if images.MaxBytes, err = strconv.ParseInt(getEnv("COMPANY_IMAGES_MAX_BYTES", "2097152"), 10, 64); err != nil {
+		return images, fmt.Errorf("invalid COMPANY_IMAGES_MAX_BYTES value: %w", err)
+	}
+	if images.Timeout, err = time.ParseDuration(getEnv("COMPANY_IMAGES_TIMEOUT", "15s")); err != nil {
+		return images, fmt.Errorf("invalid COMPANY_IMAGES_TIMEOUT value: %w", err)
+	}
+	return images, nil
+}
+
+// Validate checks every configuration section and reports all problems at once.
+// New settings should add their rules here so misconfiguration fails at boot.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.DatabaseURL == "" {
+		errs = append(errs, errors.New("DATABASE_URL is required"))
+	} else if err := validateURL(c.DatabaseURL, "postgres", "postgresql"); err != nil {
+		errs = append(errs, fmt.Errorf("invalid DATABASE_URL: %w", err))
+	}
+
+	if c.Replica.URL != "" {
+		if err := validateURL(c.Replica.URL, "postgres", "postgresql"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid DATABASE_REPLICA_URL: %w", err))
+		}
+	}
+	if c.Tracing.Enabled() {
+		if err := validateURL(c.Tracing.Endpoint, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_ENDPOINT: %w", err))
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("invalid TRACING_SAMPLE_RATIO value: %g (expected 0 to 1)", c.Tracing.SampleRatio))
+	}
+	if c.ErrorReporting.Enabled() {
+		if err := validateURL(c.ErrorReporting.DSN, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid SENTRY_DSN: %w", err))
+		}
+	}
+	if c.ErrorReporting.SampleRate < 0 || c.ErrorReporting.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("invalid ERROR_REPORT_SAMPLE_RATE value: %g (expected 0 to 1)", c.ErrorReporting.SampleRate))
+	}
+	if c.Replica.MaxLag < 0 {
+		errs = append(errs, fmt.Errorf("invalid DATABASE_REPLICA_MAX_LAG value: %s", c.Replica.MaxLag))
+	}
+	if c.Replica.Probe <= 0 {
+		errs = append(errs, fmt.Errorf("invalid DATABASE_REPLICA_PROBE_INTERVAL value: %s", c.Replica.Probe))
+	}
+	if c.Pool.QueryTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_QUERY_TIMEOUT value: %s", c.Pool.QueryTimeout))
+	}
+	if c.Pool.SlowQuery < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_SLOW_QUERY_THRESHOLD value: %s", c.Pool.SlowQuery))
+	}
+	if c.Pool.ExecMode != "" && !queryExecModes[c.Pool.ExecMode] {
+		errs = append(errs, fmt.Errorf("invalid DB_QUERY_EXEC_MODE value: %q", c.Pool.ExecMode))
+	}
+	if c.Pool.StatementCache < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_STATEMENT_CACHE_SIZE value: %d", c.Pool.StatementCache))
+	}
+	if c.ReadOnlyProbe <= 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_READONLY_PROBE_INTERVAL value: %s", c.ReadOnlyProbe))
+	}
+
+	if err := validateURL(c.WorkerBaseURL, "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("invalid WORKER_BASE_URL: %w", err))
+	}
+	if c.CallbackTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WORKER_CALLBACK_TTL value: %s", c.CallbackTTL))
+	}
+	errs = append(errs, c.Workers.validate()...)
+	if c.Account.RefreshTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid JWT_REFRESH_TTL value: %s", c.Account.RefreshTTL))
+	}
+	if c.Account.EmailVerifyURL != "" {
+		if err := validateURL(c.Account.EmailVerifyURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid EMAIL_VERIFY_URL: %w", err))
+		}
+	}
+
+	switch {
+	case c.JWTSecret == "":
+		errs = append(errs, errors.New("JWT_SECRET is required"))
+	case !c.IsDevelopment() && c.JWTSecret == devJWTSecret:
+		errs = append(errs, fmt.Errorf("JWT_SECRET must not use the development default in %s", c.Env))
+	case !c.IsDevelopment() && len(c.JWTSecret) < minJWTSecretBytes:
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d bytes in %s", minJWTSecretBytes, c.Env))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid PORT value: %q", c.Port))
+	}
+
+	switch c.SchemaCheck {
+	case "strict", "warn", "off":
+	default:
+		errs = append(errs, fmt.Errorf("invalid SCHEMA_CHECK value: %q (use strict, warn or off)", c.SchemaCheck))
+	}
+
+	if c.Redis.URL != "" {
+		if err := validateURL(c.Redis.URL, "redis", "rediss"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid REDIS_URL: %w", err))
+		}
+	}
+
+	if c.SMTP.Enabled() {
+		if c.SMTP.Port <= 0 || c.SMTP.Port > 65535 {
+			errs = append(errs, fmt.Errorf("invalid SMTP_PORT value: %d", c.SMTP.Port))
+		}
+		if c.SMTP.From == "" {
+			errs = append(errs, errors.New("SMTP_FROM is required when SMTP_HOST is set"))
+		}
+	}
+
+	if c.Enrich.DailyQuota < 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_DAILY_QUOTA value: %d", c.Enrich.DailyQuota))
+	}
+	if c.Enrich.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_CACHE_TTL value: %s", c.Enrich.CacheTTL))
+	}
+	switch c.Enrich.Validation {
+	case "strict", "lenient", "off":
+	default:
+		errs = append(errs, fmt.Errorf("invalid ENRICH_VALIDATION value: %q (use strict, lenient or off)", c.Enrich.Validation))
+	}
+	if len(c.Enrich.PhoneRegion) != 2 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_PHONE_REGION value: %q (use a two-letter region code)", c.Enrich.PhoneRegion))
+	}
+	if c.Enrich.DisposableListURL != "" {
+		if err := validateURL(c.Enrich.DisposableListURL, "https", "http"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid EMAIL_DISPOSABLE_LIST_URL: %w", err))
+		}
+	}
+	if c.Enrich.DisposableRefresh < 0 {
+		errs = append(errs, fmt.Errorf("invalid EMAIL_DISPOSABLE_REFRESH value: %s", c.Enrich.DisposableRefresh))
+	}
+	if c.Enrich.CheckConcurrency <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_CHECK_CONCURRENCY value: %d", c.Enrich.CheckConcurrency))
+	}
+	if c.Enrich.CheckTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_CHECK_TIMEOUT value: %s", c.Enrich.CheckTimeout))
+	}
+	if c.Enrich.CheckCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_CHECK_CACHE_TTL value: %s", c.Enrich.CheckCacheTTL))
+	}
+	if c.Enrich.DNSCacheSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_DNS_CACHE_SIZE value: %d", c.Enrich.DNSCacheSize))
+	}
+	if c.Enrich.DNSPositiveTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_DNS_POSITIVE_TTL value: %s", c.Enrich.DNSPositiveTTL))
+	}
+	if c.Enrich.DNSNegativeTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid ENRICH_DNS_NEGATIVE_TTL value: %s", c.Enrich.DNSNegativeTTL))
+	}
+	if c.Scoring.SizeWeight < 0 || c.Scoring.SizeWeight > 100 {
+		errs = append(errs, fmt.Errorf("invalid SCORE_SIZE_WEIGHT value: %d (use 0-100)", c.Scoring.SizeWeight))
+	}
+	if !(c.Scoring.SearchWeight >= 0 && c.Scoring.SearchWeight <= 1) {
+		errs = append(errs, fmt.Errorf("invalid SEARCH_SCORE_WEIGHT value: %g (use 0-1)", c.Scoring.SearchWeight))
+	}
+	if c.ScrapeCoalesceWindow < 0 {
+		errs = append(errs, fmt.Errorf("invalid SCRAPE_COALESCE_WINDOW value: %s", c.ScrapeCoalesceWindow))
+	}
+	if c.ScrapeEventsInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SCRAPE_EVENTS_INTERVAL value: %s", c.ScrapeEventsInterval))
+	}
+	if c.Stats.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid STATS_CACHE_TTL value: %s", c.Stats.CacheTTL))
+	}
+	if c.Stats.ScrapeFreshFor <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SCRAPE_FRESH_FOR value: %s", c.Stats.ScrapeFreshFor))
+	}
+	switch c.QueryCache.Store {
+	case "off":
+	case "memory", "redis":
+		if c.QueryCache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("invalid QUERY_CACHE_TTL value: %s", c.QueryCache.TTL))
+		}
+		if c.QueryCache.Store == "memory" && c.QueryCache.Size <= 0 {
+			errs = append(errs, fmt.Errorf("invalid QUERY_CACHE_SIZE value: %d", c.QueryCache.Size))
+		}
+		if c.QueryCache.Store == "redis" && (c.Redis.URL == "" || c.QueryCache.RedisKey == "") {
+			errs = append(errs, errors.New("QUERY_CACHE=redis requires REDIS_URL and REDIS_QUERY_CACHE_KEY"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid QUERY_CACHE value: %q (use off, memory or redis)", c.QueryCache.Store))
+	}
+	if c.Warehouse.RowsPerFile <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WAREHOUSE_ROWS_PER_FILE value: %d", c.Warehouse.RowsPerFile))
+	}
+	if strings.HasPrefix(c.Warehouse.Bucket, "gs://") || strings.Contains(c.Warehouse.Bucket, "/") {
+		errs = append(errs, fmt.Errorf("invalid WAREHOUSE_GCS_BUCKET value: %q (use the bare bucket name)", c.Warehouse.Bucket))
+	}
+	if c.ChangeSink.Enabled() && c.ChangeSink.Project == "" {
+		errs = append(errs, errors.New("BIGQUERY_SINK_PROJECT (or GOOGLE_CLOUD_PROJECT) is required when BIGQUERY_SINK_DATASET is set"))
+	}
+	if c.ChangeSink.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BIGQUERY_SINK_INTERVAL value: %s", c.ChangeSink.Interval))
+	}
+	// insertAll takes at most 50,000 rows a request and recommends 500.
+	if c.ChangeSink.BatchSize <= 0 || c.ChangeSink.BatchSize > 50000 {
+		errs = append(errs, fmt.Errorf("invalid BIGQUERY_SINK_BATCH_SIZE value: %d (must be between 1 and 50000)", c.ChangeSink.BatchSize))
+	}
+	if c.Uploads.Bucket != "" && c.Uploads.Dir != "" {
+		errs = append(errs, errors.New("set either UPLOAD_ARCHIVE_GCS_BUCKET or UPLOAD_ARCHIVE_DIR, not both"))
+	}
+	if strings.HasPrefix(c.Uploads.Bucket, "gs://") || strings.Contains(c.Uploads.Bucket, "/") {
+		errs = append(errs, fmt.Errorf("invalid UPLOAD_ARCHIVE_GCS_BUCKET value: %q (use the bare bucket name)", c.Uploads.Bucket))
+	}
+	if c.Uploads.Retention <= 0 {
+		errs = append(errs, fmt.Errorf("invalid UPLOAD_RETENTION value: %s", c.Uploads.Retention))
+	}
+	rawBackends := 0
+	for _, set := range []bool{c.RawStore.GCSBucket != "", c.RawStore.S3Bucket != "", c.RawStore.Dir != ""} {
+		if set {
+			rawBackends++
+		}
+	}
+	if rawBackends > 1 {
+		errs = append(errs, errors.New("set only one of RAW_STORE_GCS_BUCKET, RAW_STORE_S3_BUCKET or RAW_STORE_DIR"))
+	}
+	if strings.HasPrefix(c.RawStore.GCSBucket, "gs://") || strings.Contains(c.RawStore.GCSBucket, "/") {
+		errs = append(errs, fmt.Errorf("invalid RAW_STORE_GCS_BUCKET value: %q (use the bare bucket name)", c.RawStore.GCSBucket))
+	}
+	if c.RawStore.S3Bucket != "" && (c.RawStore.S3Region == "" || c.RawStore.S3AccessKeyID == "" || c.RawStore.S3SecretAccessKey == "") {
+		errs = append(errs, errors.New("RAW_STORE_S3_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when RAW_STORE_S3_BUCKET is set"))
+	}
+	if c.RawStore.OffloadInterval < 0 {
+		errs = append(errs, fmt.Errorf("invalid RAW_OFFLOAD_INTERVAL value: %s", c.RawStore.OffloadInterval))
+	}
+	if c.Imports.Workers <= 0 {
+		errs = append(errs, fmt.Errorf("invalid IMPORT_WORKERS value: %d", c.Imports.Workers))
+	}
+	if c.Imports.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid IMPORT_QUEUE_SIZE value: %d", c.Imports.QueueSize))
+	}
+	if c.Imports.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid IMPORT_BATCH_SIZE value: %d", c.Imports.BatchSize))
+	}
+	if strings.HasPrefix(c.Attachments.Bucket, "gs://") || strings.Contains(c.Attachments.Bucket, "/") {
+		errs = append(errs, fmt.Errorf("invalid ATTACHMENTS_GCS_BUCKET value: %q (use the bare bucket name)", c.Attachments.Bucket))
+	}
+	if c.Attachments.Enabled() && c.Attachments.SignerKeyFile == "" {
+		errs = append(errs, errors.New("ATTACHMENTS_SIGNER_KEY_FILE (or GOOGLE_APPLICATION_CREDENTIALS) is required when ATTACHMENTS_GCS_BUCKET is set"))
+	}
+	if c.Attachments.MaxFileBytes < 0 || c.Attachments.UserQuotaBytes < 0 || c.Attachments.OrgQuotaBytes < 0 {
+		errs = append(errs, errors.New("ATTACHMENTS_MAX_FILE_MB, ATTACHMENTS_USER_QUOTA_MB and ATTACHMENTS_ORG_QUOTA_MB must not be negative"))
+	}
+	if c.Attachments.URLTTL <= 0 || c.Attachments.URLTTL > 7*24*time.Hour {
+		errs = append(errs, fmt.Errorf("invalid ATTACHMENTS_URL_TTL value: %s (must be between 1s and 168h)", c.Attachments.URLTTL))
+	}
+	if c.Exports.UserRowCap < 0 || c.Exports.AdminRowCap < 0 {
+		errs = append(errs, errors.New("EXPORT_ROW_CAP_USER and EXPORT_ROW_CAP_ADMIN must not be negative"))
+	}
+	if c.Prompt.AliasRefresh < 0 {
+		errs = append(errs, fmt.Errorf("invalid PROMPT_ALIAS_REFRESH value: %s", c.Prompt.AliasRefresh))
+	}
+	if c.Locations.Refresh < 0 {
+		errs = append(errs, fmt.Errorf("invalid LOCATIONS_REFRESH value: %s", c.Locations.Refresh))
+	}
+	if c.Categories.Refresh < 0 {
+		errs = append(errs, fmt.Errorf("invalid CATEGORIES_REFRESH value: %s", c.Categories.Refresh))
+	}
+	if c.ExportSchedules.Interval < 0 {
+		errs = append(errs, fmt.Errorf("invalid EXPORT_SCHEDULES_INTERVAL value: %s", c.ExportSchedules.Interval))
+	}
+	if strings.Contains(c.ExportSchedules.Bucket, "/") {
+		errs = append(errs, fmt.Errorf("invalid EXPORT_SCHEDULES_GCS_BUCKET value: %q (use the bare bucket name)", c.ExportSchedules.Bucket))
+	}
+	if c.ExportSchedules.WebhookTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid EXPORT_SCHEDULES_WEBHOOK_TIMEOUT value: %s", c.ExportSchedules.WebhookTimeout))
+	}
+	if c.LeadReports.MapTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid LEAD_REPORTS_MAP_TIMEOUT value: %s", c.LeadReports.MapTimeout))
+	}
+
+	switch c.Campaigns.Provider {
+	case "":
+	case "smtp":
+		if !c.SMTP.Enabled() {
+			errs = append(errs, errors.New("SMTP_HOST is required when CAMPAIGN_PROVIDER is smtp"))
+		}
+	case "sendgrid":
+		if c.Campaigns.SendGridAPIKey == "" {
+			errs = append(errs, errors.New("SENDGRID_API_KEY is required when CAMPAIGN_PROVIDER is sendgrid"))
+		}
+	case "mailgun":
+		if c.Campaigns.MailgunAPIKey == "" || c.Campaigns.MailgunDomain == "" {
+			errs = append(errs, errors.New("MAILGUN_API_KEY and MAILGUN_DOMAIN are required when CAMPAIGN_PROVIDER is mailgun"))
+		}
+		if err := validateURL(c.Campaigns.MailgunBaseURL, "https", "http"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid MAILGUN_API_URL: %w", err))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid CAMPAIGN_PROVIDER value: %q (use smtp, sendgrid or mailgun)", c.Campaigns.Provider))
+	}
+	if c.Campaigns.Enabled() {
+		if c.Campaigns.From == "" {
+			errs = append(errs, errors.New("CAMPAIGN_FROM (or SMTP_FROM) is required when CAMPAIGN_PROVIDER is set"))
+		}
+		if c.Campaigns.PublicURL == "" {
+			errs = append(errs, errors.New("CAMPAIGN_PUBLIC_URL is required when CAMPAIGN_PROVIDER is set"))
+		} else if err := validateURL(c.Campaigns.PublicURL, "https", "http"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CAMPAIGN_PUBLIC_URL: %w", err))
+		}
+	}
+	if c.Campaigns.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid CAMPAIGN_BATCH_SIZE value: %d", c.Campaigns.BatchSize))
+	}
+	if c.Campaigns.SendInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid CAMPAIGN_SEND_INTERVAL value: %s", c.Campaigns.SendInterval))
+	}
+	if c.WebsiteChecks.Interval < 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBSITE_CHECK_INTERVAL value: %s", c.WebsiteChecks.Interval))
+	}
+	if c.WebsiteChecks.Recheck <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBSITE_CHECK_RECHECK value: %s", c.WebsiteChecks.Recheck))
+	}
+	if c.WebsiteChecks.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBSITE_CHECK_BATCH_SIZE value: %d", c.WebsiteChecks.BatchSize))
+	}
+	if c.WebsiteChecks.Concurrency <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBSITE_CHECK_CONCURRENCY value: %d", c.WebsiteChecks.Concurrency))
+	}
+	if c.WebsiteChecks.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBSITE_CHECK_TIMEOUT value: %s", c.WebsiteChecks.Timeout))
+	}
+	if c.CompanyImages.Bucket != "" && c.CompanyImages.Dir != "" {
+		errs = append(errs, errors.New("set either COMPANY_IMAGES_GCS_BUCKET or COMPANY_IMAGES_DIR, not both"))
+	}
+	if strings.HasPrefix(c.CompanyImages.Bucket, "gs://") || strings.Contains(c.CompanyImages.Bucket, "/") {
+		errs = append(errs, fmt.Errorf("invalid COMPANY_IMAGES_GCS_BUCKET value: %q (use the bare bucket name)", c.CompanyImages.Bucket))
+	}
+	if c.CompanyImages.Enabled() {
+		if c.CompanyImages.PublicURL == "" {
+			errs = append(errs, errors.New("COMPANY_IMAGES_PUBLIC_URL is required when COMPANY_IMAGES_GCS_BUCKET or COMPANY_IMAGES_DIR is set"))
+		} else if err := validateURL(c.CompanyImages.PublicURL, "https", "http"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid COMPANY_IMAGES_PUBLIC_URL: %w", err))
+		}
+	}
+	if c.CompanyImages.Interval < 0 {
+		errs = append(errs, fmt.Errorf("invalid COMPANY_IMAGES_INTERVAL value: %s", c.CompanyImages.Interval))
+	}
+	if c.CompanyImages.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid COMPANY_IMAGES_BATCH_SIZE value: %d", c.CompanyImages.BatchSize))
+	}
+	if c.CompanyImages.MaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("invalid COMPANY_IMAGES_MAX_BYTES value: %d", c.CompanyImages.MaxBytes))
+	}
+	if c.CompanyImages.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid COMPANY_IMAGES_TIMEOUT value: %s", c.CompanyImages.Timeout))
+	}
+	if c.ReconcileInterval < 0 {
+		errs = append(errs, fmt.Errorf("invalid RECONCILE_INTERVAL value: %s", c.ReconcileInterval))
+	}
+
+	for _, entry := range append(append([]string(nil), c.Outbound.Allow...), c.Outbound.Deny...) {
+		if !validOutboundEntry(entry) {
+			errs = append(errs, fmt.Errorf("invalid OUTBOUND_ALLOW or OUTBOUND_DENY entry %q: use a host, an IP or a CIDR range", entry))
+		}
+	}
+	if c.Outbound.MaxRedirects < 0 {
+		errs = append(errs, fmt.Errorf("invalid OUTBOUND_MAX_REDIRECTS value: %d", c.Outbound.MaxRedirects))
+	}
+	if c.Outbound.MaxConnsPerHost < 0 {
+		errs = append(errs, fmt.Errorf("invalid OUTBOUND_MAX_CONNS_PER_HOST value: %d", c.Outbound.MaxConnsPerHost))
+	}
+
+	for _, cidr := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: use CIDR notation", cidr))
+		}
+	}
+	switch c.Captcha.Provider {
+	case "":
+	case "turnstile", "hcaptcha", "recaptcha":
+		if c.Captcha.Secret == "" {
+			errs = append(errs, errors.New("CAPTCHA_SECRET is required when CAPTCHA_PROVIDER is set"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid CAPTCHA_PROVIDER value: %q (use turnstile, hcaptcha or recaptcha)", c.Captcha.Provider))
+	}
+
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin == "*" {
+			if c.Env == EnvProduction {
+				errs = append(errs, errors.New("CORS_ALLOW_ORIGINS must not contain * in production"))
+			}
+			continue
+		}
+		if err := validateURL(origin, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CORS origin %q: %w", origin, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func parseEnv(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "dev", "development", "local":
+		return EnvDevelopment, nil
+	case "staging", "stage":
+		return EnvStaging, nil
+	case "prod", "production":
+		return EnvProduction, nil
+	default:
+		return "", fmt.Errorf("invalid APP_ENV value: %q (use development, staging or production)", value)
+	}
+}
+
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", raw)
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported scheme %q (expected %s)", u.Scheme, strings.Join(schemes, " or "))
+}
+
+// loadEncryption reads ENRICHMENT_ENCRYPTION_KEY, or the file named by ENRICHMENT_ENCRYPTION_KEY_FILE
+// for keys mounted from a secret manager, and ENRICHMENT_ENCRYPTION_PREVIOUS_KEYS.
+func loadEncryption() (EncryptionConfig, error) {
+	var enc EncryptionConfig
+	raw := strings.TrimSpace(os.Getenv("ENRICHMENT_ENCRYPTION_KEY"))
+	if path := strings.TrimSpace(os.Getenv("ENRICHMENT_ENCRYPTION_KEY_FILE")); raw == "" && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return enc, fmt.Errorf("invalid ENRICHMENT_ENCRYPTION_KEY_FILE value: %w", err)
+		}
+		raw = strings.TrimSpace(string(data))
+	}
+	previous := splitList(os.Getenv("ENRICHMENT_ENCRYPTION_PREVIOUS_KEYS"))
+	if raw == "" {
+		if len(previous) > 0 {
+			return enc, errors.New("ENRICHMENT_ENCRYPTION_PREVIOUS_KEYS requires ENRICHMENT_ENCRYPTION_KEY")
+		}
+		return enc, nil
+	}
+	key, err := decodeEncryptionKey(raw)
+	if err != nil {
+		return enc, fmt.Errorf("invalid ENRICHMENT_ENCRYPTION_KEY value: %w", err)
+	}
+	enc.Key = key
+	for _, value := range previous {
+		key, err := decodeEncryptionKey(value)
+		if err != nil {
+			return enc, fmt.Errorf("invalid ENRICHMENT_ENCRYPTION_PREVIOUS_KEYS entry: %w", err)
+		}
+		enc.PreviousKeys = append(enc.PreviousKeys, key)
+	}
+	return enc, nil
+}
+
+// decodeEncryptionKey accepts a 32-byte key encoded as base64 or hex.
+func decodeEncryptionKey(value string) ([]byte, error) {
+	for _, decode := range []func(string) ([]byte, error){
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		hex.DecodeString,
+	} {
+		if key, err := decode(value); err == nil && len(key) == 32 {
+			return key, nil
+		}
+	}
+	return nil, errors.New("use 32 bytes encoded as base64 or hex (openssl rand -base64 32)")
+}
+
+// validOutboundEntry accepts a CIDR range, an IP address or a bare host name.
+func validOutboundEntry(entry string) bool {
+	if _, _, err := net.ParseCIDR(entry); err == nil {
+		return true
+	}
+	if net.ParseIP(entry) != nil {
+		return true
+	}
+	return !strings.ContainsAny(entry, "/:@ ") && strings.Trim(entry, ".") != ""
+}
+
+// loadWorkers reads WORKER_TIMEOUT, WORKER_FAILOVER_COOLDOWN, the WORKER_TRANSPORT settings and the
+// routes named in WORKER_ROUTES, each configured by WORKER_<NAME>_BASE_URL, _GRPC_ADDR, _COUNTRIES,
+// _JOBS and _TIMEOUT.
+func loadWorkers() (WorkersConfig, error) {
+	var workers WorkersConfig
+	var err error
+	if workers.Timeout, err = time.ParseDuration(getEnv("WORKER_TIMEOUT", "0s")); err != nil {
+		return workers, fmt.Errorf("invalid WORKER_TIMEOUT value: %w", err)
+	}
+	if workers.ScrapeTimeout, err = time.ParseDuration(getEnv("WORKER_SCRAPE_TIMEOUT", "0s")); err != nil {
+		return workers, fmt.Errorf("invalid WORKER_SCRAPE_TIMEOUT value: %w", err)
+	}
+	if workers.EnrichTimeout, err = time.ParseDuration(getEnv("WORKER_ENRICH_TIMEOUT", "0s")); err != nil {
+		return workers, fmt.Errorf("invalid WORKER_ENRICH_TIMEOUT value: %w", err)
+	}
+	if workers.DeadlineMargin, err = time.ParseDuration(getEnv("WORKER_DEADLINE_MARGIN", "250ms")); err != nil {
+		return workers, fmt.Errorf("invalid WORKER_DEADLINE_MARGIN value: %w", err)
+	}
+	if workers.FailoverCooldown, err = time.ParseDuration(getEnv("WORKER_FAILOVER_COOLDOWN", "30s")); err != nil {
+		return workers, fmt.Errorf("invalid WORKER_FAILOVER_COOLDOWN value: %w", err)
+	}
+	workers.Transport = strings.ToLower(strings.TrimSpace(getEnv("WORKER_TRANSPORT", WorkerTransportHTTP)))
+	workers.GRPCAddr = strings.TrimSpace(os.Getenv("WORKER_GRPC_ADDR"))
+	if workers.GRPCTLS, err = strconv.ParseBool(getEnv("WORKER_GRPC_TLS", "false")); err != nil {
+		return workers, fmt.Errorf("invalid WORKER_GRPC_TLS value: %w", err)
+	}
+	workers.CallbackGRPCAddr = strings.TrimSpace(os.Getenv("WORKER_CALLBACK_GRPC_ADDR"))
+	for _, name := range splitList(os.Getenv("WORKER_ROUTES")) {
+		name = strings.ToLower(name)
+		prefix := "WORKER_" + strings.ToUpper(name) + "_"
+		route := WorkerRoute{
+			Name:      name,
+			BaseURL:   strings.TrimSpace(os.Getenv(prefix + "BASE_URL")),
+			Countries: splitList(os.Getenv(prefix + "COUNTRIES")),
+			Jobs:      splitList(strings.ToLower(os.Getenv(prefix + "JOBS"))),
+			GRPCAddr:  strings.TrimSpace(os.Getenv(prefix + "GRPC_ADDR")),
+		}
+		if route.Timeout, err = time.ParseDuration(getEnv(prefix+"TIMEOUT", "0s")); err != nil {
+			return workers, fmt.Errorf("invalid %sTIMEOUT value: %w", prefix, err)
+		}
+		workers.Routes = append(workers.Routes, route)
+	}
+	return workers, nil
+}
+
+func (w WorkersConfig) validate() []error {
+	var errs []error
+	if w.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid WORKER_TIMEOUT value: %s", w.Timeout))
+	}
+	if w.ScrapeTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid WORKER_SCRAPE_TIMEOUT value: %s", w.ScrapeTimeout))
+	}
+	if w.EnrichTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid WORKER_ENRICH_TIMEOUT value: %s", w.EnrichTimeout))
+	}
+	if w.DeadlineMargin < 0 {
+		errs = append(errs, fmt.Errorf("invalid WORKER_DEADLINE_MARGIN value: %s", w.DeadlineMargin))
+	}
+	if w.FailoverCooldown < 0 {
+		errs = append(errs, fmt.Errorf("invalid WORKER_FAILOVER_COOLDOWN value: %s", w.FailoverCooldown))
+	}
+	grpcTransport := w.Transport == WorkerTransportGRPC
+	switch {
+	case w.Transport != WorkerTransportHTTP && !grpcTransport:
+		errs = append(errs, fmt.Errorf("invalid WORKER_TRANSPORT value %q (expected http or grpc)", w.Transport))
+	case grpcTransport && !validHostPort(w.GRPCAddr):
+		errs = append(errs, fmt.Errorf("invalid WORKER_GRPC_ADDR value %q (expected host:port)", w.GRPCAddr))
+	}
+	if w.CallbackGRPCAddr != "" && !validListenAddr(w.CallbackGRPCAddr) {
+		errs = append(errs, fmt.Errorf("invalid WORKER_CALLBACK_GRPC_ADDR value %q (expected [host]:port)", w.CallbackGRPCAddr))
+	}
+	seen := make(map[string]bool, len(w.Routes))
+	for _, route := range w.Routes {
+		prefix := "WORKER_" + strings.ToUpper(route.Name) + "_"
+		if !workerRouteName.MatchString(route.Name) || route.Name == "default" || seen[route.Name] {
+			errs = append(errs, fmt.Errorf("invalid WORKER_ROUTES entry %q (use unique names of letters, digits and underscores other than default)", route.Name))
+		}
+		seen[route.Name] = true
+		if grpcTransport {
+			if !validHostPort(route.GRPCAddr) {
+				errs = append(errs, fmt.Errorf("invalid %sGRPC_ADDR value %q (expected host:port)", prefix, route.GRPCAddr))
+			}
+		} else if err := validateURL(route.BaseURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %sBASE_URL: %w", prefix, err))
+		}
+		for _, job := range route.Jobs {
+			if !slices.Contains(workerJobTypes, job) {
+				errs = append(errs, fmt.Errorf("invalid %sJOBS entry %q (expected one of %s)", prefix, job, strings.Join(workerJobTypes, ", ")))
+			}
+		}
+		if route.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("invalid %sTIMEOUT value: %s", prefix, route.Timeout))
+		}
+	}
+	return errs
+}
+
+// validHostPort reports whether addr is a host and port a client can dial.
+func validHostPort(addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	return err == nil && host != "" && validPort(port)
+}
+
+// validListenAddr reports whether addr is an address to listen on, the host being optional.
+func validListenAddr(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	return err == nil && validPort(port)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// workerRouteName matches the names of worker routes, which become part of env variable names.
+var workerRouteName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// parseOptionalRateLimit accepts "off" (or an empty value) for a disabled limit.
+func parseOptionalRateLimit(value string) (RateLimitConfig, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "off":
+		return RateLimitConfig{}, nil
+	}
+	return parseRateLimit(value)
+}
+
+// parseRateLimit reads "<requests>/<interval>" optionally followed by space separated settings:
+// burst=<tokens>, cost:<operation>=<tokens> and role:<role>=<multiplier>, as in
+// "120/min burst=240 cost:scrape=10 role:admin=5".
 func parseRateLimit(value string) (RateLimitConfig, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return RateLimitConfig{}, fmt.Errorf("expected format <requests>/<interval>, got %q", value)
+	}
+	cfg, err := parseRateLimitRate(fields[0])
+	if err != nil {
+		return RateLimitConfig{}, err
+	}
+	for _, field := range fields[1:] {
+		name, raw, ok := strings.Cut(field, "=")
+		if !ok {
+			return RateLimitConfig{}, fmt.Errorf("expected <setting>=<value>, got %q", field)
+		}
+		switch kind, key, _ := strings.Cut(name, ":"); {
+		case name == "burst":
+			if cfg.Burst, err = strconv.Atoi(raw); err != nil || cfg.Burst <= 0 {
+				return RateLimitConfig{}, fmt.Errorf("invalid burst: %s", raw)
+			}
+		case kind == "cost" && key != "":
+			cost, err := strconv.Atoi(raw)
+			if err != nil || cost <= 0 {
+				return RateLimitConfig{}, fmt.Errorf("invalid cost of %s: %s", key, raw)
+			}
+			if cfg.Costs == nil {
+				cfg.Costs = make(map[string]int)
+			}
+			cfg.Costs[key] = cost
+		case kind == "role" && key != "":
+			multiplier, err := strconv.ParseFloat(raw, 64)
+			if err != nil || !(multiplier > 0) || math.IsInf(multiplier, 0) {
+				return RateLimitConfig{}, fmt.Errorf("invalid multiplier of role %s: %s", key, raw)
+			}
+			if cfg.Roles == nil {
+				cfg.Roles = make(map[string]float64)
+			}
+			cfg.Roles[key] = multiplier
+		default:
+			return RateLimitConfig{}, fmt.Errorf("unknown setting %q (use burst, cost:<operation> or role:<role>)", name)
+		}
+	}
+	// An operation costing more than a bucket holds could never run.
+	smallest := 1.0
+	for _, multiplier := range cfg.Roles {
+		smallest = math.Min(smallest, multiplier)
+	}
+	for operation, cost := range cfg.Costs {
+		if float64(cost) > math.Ceil(float64(cfg.BurstSize())*smallest) {
+			return RateLimitConfig{}, fmt.Errorf("cost of %s (%d) exceeds the burst", operation, cost)
+		}
+	}
+	return cfg, nil
+}
+
+func parseRateLimitRate(value string) (RateLimitConfig, error) {
 	parts := strings.Split(value, "/")
 	if len(parts) != 2 {
 		return RateLimitConfig{}, fmt.Errorf("expected format <requests>/<interval>, got %q", value)
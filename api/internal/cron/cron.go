@@ -0,0 +1,163 @@
+// Package cron parses standard five-field cron expressions and finds the times they fire at.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSpec is returned for expressions that are not valid five-field cron expressions.
+var ErrInvalidSpec = errors.New("invalid cron expression")
+
+// maxSearch bounds how far Next looks ahead, so expressions that never fire, such as 0 0 30 2 *,
+// end the search.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// macros are the shorthands accepted in place of the five fields.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Schedule is a parsed cron expression. Each field is a bit set of the values it allows.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record day fields starting with *: when both day fields are restricted
+	// a day matching either fires, as in Vixie cron.
+	domStar, dowStar bool
+}
+
+// Parse reads "minute hour day-of-month month day-of-week" with *, lists, ranges and /steps, or
+// one of @yearly, @monthly, @weekly, @daily and @hourly. Day of week 0 and 7 are both Sunday.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSpec, len(parts))
+	}
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	dow := sets[4]
+	if dow&(1<<7) != 0 {
+		dow = dow&^(1<<7) | 1
+	}
+	return &Schedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     dow,
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(part string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(part, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: bad step %q in %s", ErrInvalidSpec, stepPart, f.name)
+			}
+			step = n
+		}
+		if rangePart != "*" {
+			start, end, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(start, f); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(end, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%w: range %q in %s runs backwards", ErrInvalidSpec, rangePart, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func parseValue(raw string, f field) (int, error) {
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: %s must be between %d and %d, got %q", ErrInvalidSpec, f.name, f.min, f.max, raw)
+	}
+	return v, nil
+}
+
+// Next returns the first time after after the schedule fires, in the location of after, or the
+// zero time when it does not fire within five years. Times are matched on the wall clock, so a
+// time skipped by a daylight saving change does not fire.
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(maxSearch)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domStar || s.dowStar:
+		return dom && dow
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,51 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	from := time.Date(2026, 10, 15, 9, 30, 0, 0, jakarta) // a Thursday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 8 * * 1-5", time.Date(2026, 10, 16, 8, 0, 0, 0, jakarta)},
+		{"*/15 * * * *", time.Date(2026, 10, 15, 9, 45, 0, 0, jakarta)},
+		{"30 9 * * *", time.Date(2026, 10, 16, 9, 30, 0, 0, jakarta)},
+		{"0 6 1 * *", time.Date(2026, 11, 1, 6, 0, 0, 0, jakarta)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, jakarta)},
+		{"0 0 20 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, jakarta)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, jakarta)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, jakarta)},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", tt.spec, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Fatalf("Next(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+
+	never, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := never.Next(from); !got.IsZero() {
+		t.Fatalf("expected an impossible date never to fire, got %s", got)
+	}
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "0 0 * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := Parse(spec); !errors.Is(err, ErrInvalidSpec) {
+			t.Fatalf("expected %q to be rejected, got %v", spec, err)
+		}
+	}
+}
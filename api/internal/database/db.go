@@ -5,11 +5,66 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ConnectOption customises the pool configuration before the pool is created.
+type ConnectOption func(*pgxpool.Config)
+
+// WithTracer installs a query tracer on every pool connection, next to any tracer installed before.
+func WithTracer(tracer pgx.QueryTracer) ConnectOption {
+	return func(cfg *pgxpool.Config) {
+		if cfg.ConnConfig.Tracer != nil {
+			tracer = multitracer.New(cfg.ConnConfig.Tracer, tracer)
+		}
+		cfg.ConnConfig.Tracer = tracer
+	}
+}
+
+// queryExecModes maps the names accepted by DB_QUERY_EXEC_MODE, which match pgx's
+// default_query_exec_mode connection parameter.
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// WithStatementCache sets how queries are prepared and how many prepared statements or statement
+// descriptions each connection caches. An empty mode or a zero size keeps the pgx default.
+func WithStatementCache(mode string, size int) ConnectOption {
+	return func(cfg *pgxpool.Config) {
+		if execMode, ok := queryExecModes[mode]; ok {
+			cfg.ConnConfig.DefaultQueryExecMode = execMode
+		}
+		if size > 0 {
+			cfg.ConnConfig.StatementCacheCapacity = size
+			cfg.ConnConfig.DescriptionCacheCapacity = size
+		}
+	}
+}
+
 // Connect opens a PostgreSQL connection pool using pgx and verifies connectivity.
-func Connect(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+func Connect(ctx context.Context, dsn string, opts ...ConnectOption) (*pgxpool.Pool, error) {
+	pool, err := Open(ctx, dsn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+
+	return pool, nil
+}
+
+// Open creates a PostgreSQL connection pool without connecting, for servers such as read replicas
+// that may be down at startup.
+func Open(ctx context.Context, dsn string, opts ...ConnectOption) (*pgxpool.Pool, error) {
 	if dsn == "" {
 		return nil, fmt.Errorf("database DSN must not be empty")
 	}
@@ -23,16 +78,13 @@ func Connect(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	cfg.MaxConnLifetime = 1 * time.Hour
 	cfg.MaxConnIdleTime = 15 * time.Minute
 	cfg.HealthCheckPeriod = 30 * time.Second
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create pgx pool: %w", err)
 	}
-
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("ping database: %w", err)
-	}
-
 	return pool, nil
 }
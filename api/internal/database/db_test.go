@@ -14,3 +14,17 @@ func TestConnect_Validation(t *testing.T) {
 		t.Fatalf("expected error for invalid dsn")
 	}
 }
+
+func TestNewRedisClient_Validation(t *testing.T) {
+	if _, err := NewRedisClient(""); err == nil {
+		t.Fatalf("expected error for empty url")
+	}
+	if _, err := NewRedisClient("http://not-redis"); err == nil {
+		t.Fatalf("expected error for invalid scheme")
+	}
+	client, err := NewRedisClient("redis://localhost:6379/0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.Close()
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// readOnlySQLState is raised by PostgreSQL for writes inside a read-only transaction, which is
+// what every write hits on a standby that has not been promoted yet.
+const readOnlySQLState = "25006"
+
+// IsReadOnlyError reports whether err was caused by the database refusing a write.
+func IsReadOnlyError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == readOnlySQLState
+}
+
+// rowQuerier runs single-row queries; *pgxpool.Pool satisfies it.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type readOnlyHitKey struct{}
+
+// TrackReadOnly returns a context whose queries record read-only errors, and a function
+// reporting whether one of them hit such an error.
+func TrackReadOnly(ctx context.Context) (context.Context, func() bool) {
+	hit := new(atomic.Bool)
+	return context.WithValue(ctx, readOnlyHitKey{}, hit), hit.Load
+}
+
+// ReadOnlyMonitor tracks whether the database currently rejects writes. It is installed as the
+// pool's query tracer, so a read-only error from any repository flips it immediately, and Run
+// probes the server to notice when writes are accepted again.
+type ReadOnlyMonitor struct {
+	readOnly atomic.Bool
+}
+
+// NewReadOnlyMonitor creates a monitor that starts in read-write mode.
+func NewReadOnlyMonitor() *ReadOnlyMonitor {
+	return &ReadOnlyMonitor{}
+}
+
+// ReadOnly reports whether writes are currently rejected.
+func (m *ReadOnlyMonitor) ReadOnly() bool {
+	return m.readOnly.Load()
+}
+
+// Set records the current mode and logs transitions.
+func (m *ReadOnlyMonitor) Set(readOnly bool) {
+	if m.readOnly.Swap(readOnly) == readOnly {
+		return
+	}
+	if readOnly {
+		log.Printf("database is read-only; rejecting writes until it accepts them again")
+	} else {
+		log.Printf("database accepts writes again")
+	}
+}
+
+// Probe asks the server whether new transactions are read-only and updates the mode.
+func (m *ReadOnlyMonitor) Probe(ctx context.Context, db rowQuerier) error {
+	var setting string
+	if err := db.QueryRow(ctx, `SHOW transaction_read_only`).Scan(&setting); err != nil {
+		return fmt.Errorf("probe transaction_read_only: %w", err)
+	}
+	m.Set(setting == "on")
+	return nil
+}
+
+// Run probes the database every interval until ctx is cancelled. Failed probes leave the mode
+// unchanged; an unreachable database is reported by the readiness checks instead.
+func (m *ReadOnlyMonitor) Run(ctx context.Context, db rowQuerier, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			probeCtx, cancel := context.WithTimeout(ctx, interval)
+			if err := m.Probe(probeCtx, db); err != nil && ctx.Err() == nil {
+				log.Printf("read-only probe failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// observe flips the monitor and marks the request when err is a read-only error.
+func (m *ReadOnlyMonitor) observe(ctx context.Context, err error) {
+	if err == nil || !IsReadOnlyError(err) {
+		return
+	}
+	m.Set(true)
+	if hit, ok := ctx.Value(readOnlyHitKey{}).(*atomic.Bool); ok {
+		hit.Store(true)
+	}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (m *ReadOnlyMonitor) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (m *ReadOnlyMonitor) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	m.observe(ctx, data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (m *ReadOnlyMonitor) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return ctx
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (m *ReadOnlyMonitor) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	m.observe(ctx, data.Err)
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (m *ReadOnlyMonitor) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	m.observe(ctx, data.Err)
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type settingRow string
+
+func (r settingRow) Scan(dest ...any) error {
+	*dest[0].(*string) = string(r)
+	return nil
+}
+
+type settingQuerier string
+
+func (q settingQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return settingRow(q)
+}
+
+func TestIsReadOnlyError(t *testing.T) {
+	readOnlyErr := fmt.Errorf("insert user: %w", &pgconn.PgError{Code: "25006", Message: "cannot execute INSERT in a read-only transaction"})
+	if !IsReadOnlyError(readOnlyErr) {
+		t.Fatalf("expected wrapped 25006 to be read-only")
+	}
+	if IsReadOnlyError(&pgconn.PgError{Code: "23505"}) || IsReadOnlyError(errors.New("boom")) || IsReadOnlyError(nil) {
+		t.Fatalf("expected other errors not to be read-only")
+	}
+}
+
+func TestReadOnlyMonitor(t *testing.T) {
+	monitor := NewReadOnlyMonitor()
+	ctx, hit := TrackReadOnly(context.Background())
+
+	monitor.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+	if monitor.ReadOnly() || hit() {
+		t.Fatalf("expected unrelated errors to be ignored")
+	}
+
+	monitor.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "25006"}})
+	if !monitor.ReadOnly() || !hit() {
+		t.Fatalf("expected read-only error to flip the monitor and mark the request")
+	}
+
+	if err := monitor.Probe(context.Background(), settingQuerier("off")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if monitor.ReadOnly() {
+		t.Fatalf("expected probe to clear read-only mode")
+	}
+	if err := monitor.Probe(context.Background(), settingQuerier("on")); err != nil || !monitor.ReadOnly() {
+		t.Fatalf("expected probe to detect read-only mode (err=%v)", err)
+	}
+}
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisClient builds a Redis client from a redis:// or rediss:// URL.
+func NewRedisClient(rawURL string) (*redis.Client, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("redis URL must not be empty")
+	}
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	return redis.NewClient(opts), nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// replicaLagSQL measures how far the replica trails the primary. A replica that has replayed
+// everything it received is current even when the primary has been idle for a while.
+const replicaLagSQL = `
+	SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8
+`
+
+// Querier runs read-only queries; *pgxpool.Pool satisfies it.
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// ReplicaRouter sends read-only queries to a read replica while it is reachable and current, and
+// to the primary otherwise. It has no Exec or BeginTx, so writes cannot be routed to the replica.
+type ReplicaRouter struct {
+	primary Querier
+	replica Querier
+	maxLag  time.Duration
+	healthy atomic.Bool
+}
+
+// NewReplicaRouter creates a router that uses the primary until a probe finds the replica healthy.
+// A zero maxLag disables the lag check.
+func NewReplicaRouter(primary, replica Querier, maxLag time.Duration) *ReplicaRouter {
+	return &ReplicaRouter{primary: primary, replica: replica, maxLag: maxLag}
+}
+
+// Healthy reports whether reads currently go to the replica.
+func (r *ReplicaRouter) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// Set records whether the replica serves reads and logs transitions.
+func (r *ReplicaRouter) Set(healthy bool) {
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		log.Printf("read replica is healthy; routing reads to it")
+	} else {
+		log.Printf("read replica is unavailable; routing reads to the primary")
+	}
+}
+
+// Probe checks that the replica answers and trails the primary by at most maxLag.
+func (r *ReplicaRouter) Probe(ctx context.Context) error {
+	var lagSeconds float64
+	if err := r.replica.QueryRow(ctx, replicaLagSQL).Scan(&lagSeconds); err != nil {
+		r.Set(false)
+		return fmt.Errorf("probe replica: %w", err)
+	}
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	if r.maxLag > 0 && lag > r.maxLag {
+		r.Set(false)
+		return fmt.Errorf("replica lags %s behind the primary (max %s)", lag.Round(time.Second), r.maxLag)
+	}
+	r.Set(true)
+	return nil
+}
+
+// Run probes the replica every interval until ctx is cancelled.
+func (r *ReplicaRouter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			probeCtx, cancel := context.WithTimeout(ctx, interval)
+			if err := r.Probe(probeCtx); err != nil && ctx.Err() == nil {
+				log.Printf("replica probe failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// QueryRow runs a single-row query on the replica, retrying on the primary when the replica
+// cannot be reached.
+func (r *ReplicaRouter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if !r.Healthy() {
+		return r.primary.QueryRow(ctx, sql, args...)
+	}
+	return &fallbackRow{router: r, ctx: ctx, sql: sql, args: args, row: r.replica.QueryRow(ctx, sql, args...)}
+}
+
+// Query runs a query on the replica, retrying on the primary when the replica cannot be reached.
+// Failures while reading rows are returned as they are.
+func (r *ReplicaRouter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if r.Healthy() {
+		rows, err := r.replica.Query(ctx, sql, args...)
+		if !r.fallback(ctx, err) {
+			return rows, err
+		}
+	}
+	return r.primary.Query(ctx, sql, args...)
+}
+
+// fallback reports whether err means the replica is unreachable, in which case it is taken out of
+// rotation until the next successful probe.
+func (r *ReplicaRouter) fallback(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return false
+	}
+	log.Printf("read replica query failed, retrying on the primary: %v", err)
+	r.Set(false)
+	return true
+}
+
+// fallbackRow retries a replica row on the primary when scanning it reveals a connection failure.
+type fallbackRow struct {
+	router *ReplicaRouter
+	ctx    context.Context
+	sql    string
+	args   []any
+	row    pgx.Row
+}
+
+func (f *fallbackRow) Scan(dest ...any) error {
+	err := f.row.Scan(dest...)
+	if !f.router.fallback(f.ctx, err) {
+		return err
+	}
+	return f.router.primary.QueryRow(f.ctx, f.sql, f.args...).Scan(dest...)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+// fakeQuerier answers every query with lag seconds, or with err when set.
+type fakeQuerier struct {
+	lag     float64
+	err     error
+	queries int
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.queries++
+	return scanFunc(func(dest ...any) error {
+		if q.err != nil {
+			return q.err
+		}
+		*dest[0].(*float64) = q.lag
+		return nil
+	})
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries++
+	return nil, q.err
+}
+
+func TestReplicaRouter_Routing(t *testing.T) {
+	primary, replica := &fakeQuerier{}, &fakeQuerier{lag: 2}
+	router := NewReplicaRouter(primary, replica, 30*time.Second)
+	ctx := context.Background()
+	var lag float64
+
+	if err := router.QueryRow(ctx, "SELECT 1").Scan(&lag); err != nil || primary.queries != 1 || replica.queries != 0 {
+		t.Fatalf("expected reads on the primary before the first probe (err=%v)", err)
+	}
+	if err := router.Probe(ctx); err != nil || !router.Healthy() {
+		t.Fatalf("expected a current replica to be healthy (err=%v)", err)
+	}
+	if _, err := router.Query(ctx, "SELECT 1"); err != nil || replica.queries != 2 || primary.queries != 1 {
+		t.Fatalf("expected reads on the replica (err=%v)", err)
+	}
+
+	// Query errors reported by Postgres are the caller's problem, not the replica's.
+	replica.err = &pgconn.PgError{Code: "42P01"}
+	if _, err := router.Query(ctx, "SELECT 1"); err == nil || !router.Healthy() || primary.queries != 1 {
+		t.Fatalf("expected a SQL error to be returned as is (err=%v)", err)
+	}
+
+	replica.err = errors.New("connection refused")
+	if err := router.QueryRow(ctx, "SELECT 1").Scan(&lag); err != nil || router.Healthy() || primary.queries != 2 {
+		t.Fatalf("expected an unreachable replica to fall back to the primary (err=%v)", err)
+	}
+	if _, err := router.Query(ctx, "SELECT 1"); err != nil || primary.queries != 3 {
+		t.Fatalf("expected reads to stay on the primary (err=%v)", err)
+	}
+}
+
+func TestReplicaRouter_ProbeLag(t *testing.T) {
+	replica := &fakeQuerier{lag: 45}
+	router := NewReplicaRouter(&fakeQuerier{}, replica, 30*time.Second)
+
+	if err := router.Probe(context.Background()); err == nil || router.Healthy() {
+		t.Fatalf("expected a lagging replica to be taken out of rotation (err=%v)", err)
+	}
+	replica.lag = 0
+	if err := router.Probe(context.Background()); err != nil || !router.Healthy() {
+		t.Fatalf("expected a caught up replica to serve reads again (err=%v)", err)
+	}
+	replica.lag = 3600
+	if err := NewReplicaRouter(&fakeQuerier{}, replica, 0).Probe(context.Background()); err != nil {
+		t.Fatalf("expected a zero max lag to skip the lag check, got %v", err)
+	}
+}
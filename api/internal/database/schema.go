@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Schema check modes accepted by CheckSchema.
+const (
+	SchemaCheckStrict = "strict"
+	SchemaCheckWarn   = "warn"
+	SchemaCheckOff    = "off"
+)
+
+//go:embed schema_manifest.json
+var schemaManifestJSON []byte
+
+// SchemaManifest lists the tables, columns and indexes the API expects to exist.
+// Keep it in sync with db/migrations whenever a migration adds or drops objects.
+type SchemaManifest struct {
+	Tables map[string]TableSpec `json:"tables"`
+}
+
+// TableSpec declares the expected columns and indexes of a single table.
+type TableSpec struct {
+	Columns []string `json:"columns"`
+	Indexes []string `json:"indexes"`
+}
+
+// SchemaDiff reports objects declared in the manifest but absent from the database.
+type SchemaDiff struct {
+	MissingTables  []string `json:"missing_tables,omitempty"`
+	MissingColumns []string `json:"missing_columns,omitempty"`
+	MissingIndexes []string `json:"missing_indexes,omitempty"`
+}
+
+// Empty reports whether the live schema satisfies the manifest.
+func (d SchemaDiff) Empty() bool {
+	return len(d.MissingTables) == 0 && len(d.MissingColumns) == 0 && len(d.MissingIndexes) == 0
+}
+
+// String renders the diff as a single human readable line.
+func (d SchemaDiff) String() string {
+	parts := make([]string, 0, 3)
+	if len(d.MissingTables) > 0 {
+		parts = append(parts, "missing tables: "+strings.Join(d.MissingTables, ", "))
+	}
+	if len(d.MissingColumns) > 0 {
+		parts = append(parts, "missing columns: "+strings.Join(d.MissingColumns, ", "))
+	}
+	if len(d.MissingIndexes) > 0 {
+		parts = append(parts, "missing indexes: "+strings.Join(d.MissingIndexes, ", "))
+	}
+	if len(parts) == 0 {
+		return "schema up to date"
+	}
+	return strings.Join(parts, "; ")
+}
+
+type schemaQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// LoadSchemaManifest decodes the manifest embedded in the binary.
+func LoadSchemaManifest() (SchemaManifest, error) {
+	var manifest SchemaManifest
+	if err := json.Unmarshal(schemaManifestJSON, &manifest); err != nil {
+		return SchemaManifest{}, fmt.Errorf("decode schema manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// CheckSchema compares the live schema with the embedded manifest.
+// In strict mode any drift is returned as an error; in warn mode it is logged.
+func CheckSchema(ctx context.Context, db schemaQuerier, mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == SchemaCheckOff {
+		return nil
+	}
+
+	manifest, err := LoadSchemaManifest()
+	if err != nil {
+		return err
+	}
+	diff, err := VerifySchema(ctx, db, manifest)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+	if mode == SchemaCheckStrict {
+		return fmt.Errorf("schema drift detected: %s", diff)
+	}
+	log.Printf("WARNING schema drift detected: %s", diff)
+	return nil
+}
+
+// VerifySchema queries information_schema and pg_indexes and returns the diff against manifest.
+func VerifySchema(ctx context.Context, db schemaQuerier, manifest SchemaManifest) (SchemaDiff, error) {
+	tables := make([]string, 0, len(manifest.Tables))
+	for name := range manifest.Tables {
+		tables = append(tables, name)
+	}
+
+	columns := make(map[string]map[string]bool)
+	rows, err := db.Query(ctx, `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`, tables)
+	if err != nil {
+		return SchemaDiff{}, fmt.Errorf("query columns: %w", err)
+	}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			rows.Close()
+			return SchemaDiff{}, fmt.Errorf("scan column: %w", err)
+		}
+		if columns[table] == nil {
+			columns[table] = make(map[string]bool)
+		}
+		columns[table][column] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return SchemaDiff{}, fmt.Errorf("iterate columns: %w", err)
+	}
+
+	indexes := make(map[string]bool)
+	rows, err = db.Query(ctx, `
+		SELECT indexname
+		FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename = ANY($1)
+	`, tables)
+	if err != nil {
+		return SchemaDiff{}, fmt.Errorf("query indexes: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return SchemaDiff{}, fmt.Errorf("scan index: %w", err)
+		}
+		indexes[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return SchemaDiff{}, fmt.Errorf("iterate indexes: %w", err)
+	}
+
+	return diffSchema(manifest, columns, indexes), nil
+}
+
+func diffSchema(manifest SchemaManifest, columns map[string]map[string]bool, indexes map[string]bool) SchemaDiff {
+	var diff SchemaDiff
+	for table, spec := range manifest.Tables {
+		existing, ok := columns[table]
+		if !ok {
+			diff.MissingTables = append(diff.MissingTables, table)
+			continue
+		}
+		for _, column := range spec.Columns {
+			if !existing[column] {
+				diff.MissingColumns = append(diff.MissingColumns, table+"."+column)
+			}
+		}
+		for _, index := range spec.Indexes {
+			if !indexes[index] {
+				diff.MissingIndexes = append(diff.MissingIndexes, index)
+			}
+		}
+	}
+	sort.Strings(diff.MissingTables)
+	sort.Strings(diff.MissingColumns)
+	sort.Strings(diff.MissingIndexes)
+	return diff
+}
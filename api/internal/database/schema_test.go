@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type failingQuerier struct{}
+
+func (failingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestLoadSchemaManifest(t *testing.T) {
+	manifest, err := LoadSchemaManifest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	companies, ok := manifest.Tables["companies"]
+	if !ok {
+		t.Fatalf("expected companies table in manifest")
+	}
+	if len(companies.Columns) == 0 || len(companies.Indexes) == 0 {
+		t.Fatalf("expected columns and indexes declared, got %+v", companies)
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	manifest := SchemaManifest{Tables: map[string]TableSpec{
+		"companies": {Columns: []string{"id", "scraped_at"}, Indexes: []string{"idx_companies_city"}},
+		"users":     {Columns: []string{"id"}},
+	}}
+
+	diff := diffSchema(manifest,
+		map[string]map[string]bool{"companies": {"id": true}},
+		map[string]bool{},
+	)
+	if diff.Empty() {
+		t.Fatalf("expected drift to be detected")
+	}
+	if len(diff.MissingTables) != 1 || diff.MissingTables[0] != "users" {
+		t.Fatalf("unexpected missing tables: %+v", diff.MissingTables)
+	}
+	if len(diff.MissingColumns) != 1 || diff.MissingColumns[0] != "companies.scraped_at" {
+		t.Fatalf("unexpected missing columns: %+v", diff.MissingColumns)
+	}
+	if len(diff.MissingIndexes) != 1 || diff.MissingIndexes[0] != "idx_companies_city" {
+		t.Fatalf("unexpected missing indexes: %+v", diff.MissingIndexes)
+	}
+	if !strings.Contains(diff.String(), "companies.scraped_at") {
+		t.Fatalf("expected diff string to mention missing column, got %s", diff.String())
+	}
+
+	clean := diffSchema(manifest,
+		map[string]map[string]bool{"companies": {"id": true, "scraped_at": true}, "users": {"id": true}},
+		map[string]bool{"idx_companies_city": true},
+	)
+	if !clean.Empty() {
+		t.Fatalf("expected no drift, got %s", clean)
+	}
+}
+
+func TestCheckSchema_Modes(t *testing.T) {
+	if err := CheckSchema(context.Background(), failingQuerier{}, SchemaCheckOff); err != nil {
+		t.Fatalf("expected off mode to skip verification, got %v", err)
+	}
+	if err := CheckSchema(context.Background(), failingQuerier{}, SchemaCheckWarn); err == nil {
+		t.Fatalf("expected query failure to surface")
+	}
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxLoggedSQL bounds the statement text written to the log.
+const maxLoggedSQL = 500
+
+type slowQueryKey struct{}
+
+type slowQueryStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+// SlowQueryLogger is a pgx.QueryTracer logging queries that take longer than a threshold, with
+// their SQL and a summary of the bound arguments. Strings are summarised by length only, so
+// passwords, tokens and contact details stay out of the log.
+type SlowQueryLogger struct {
+	threshold time.Duration
+	logf      func(format string, args ...any)
+	now       func() time.Time
+}
+
+// NewSlowQueryLogger logs queries slower than threshold.
+func NewSlowQueryLogger(threshold time.Duration) *SlowQueryLogger {
+	return &SlowQueryLogger{threshold: threshold, logf: log.Printf, now: time.Now}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (l *SlowQueryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryKey{}, slowQueryStart{at: l.now(), sql: data.SQL, args: data.Args})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (l *SlowQueryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(slowQueryKey{}).(slowQueryStart)
+	if !ok {
+		return
+	}
+	elapsed := l.now().Sub(start.at)
+	if elapsed < l.threshold {
+		return
+	}
+	status := "ok"
+	if data.Err != nil {
+		status = data.Err.Error()
+	}
+	l.logf("slow query (%s, %s): %s args=[%s]", elapsed.Round(time.Millisecond), status, compactSQL(start.sql), summarizeArgs(start.args))
+}
+
+// compactSQL collapses whitespace and truncates long statements.
+func compactSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLoggedSQL {
+		sql = sql[:maxLoggedSQL] + "..."
+	}
+	return sql
+}
+
+// summarizeArgs describes bound arguments as $n=value for numbers, booleans, times and UUIDs, and
+// by type and length for everything else.
+func summarizeArgs(args []any) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = fmt.Sprintf("$%d=%s", i+1, summarizeArg(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func summarizeArg(arg any) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case bool, int, int16, int32, int64, float32, float64, uuid.UUID:
+		return fmt.Sprint(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case time.Duration:
+		return v.String()
+	case string:
+		return fmt.Sprintf("string(%d)", len(v))
+	case []byte:
+		return fmt.Sprintf("bytes(%d)", len(v))
+	}
+	value := reflect.ValueOf(arg)
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return "NULL"
+		}
+		return summarizeArg(value.Elem().Interface())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("%s(%d)", value.Type(), value.Len())
+	}
+	return value.Type().String()
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestSlowQueryLogger(t *testing.T) {
+	var logged []string
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := NewSlowQueryLogger(time.Second)
+	logger.logf = func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+	logger.now = func() time.Time { return clock }
+
+	run := func(elapsed time.Duration, sql string, args []any, err error) {
+		ctx := logger.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql, Args: args})
+		clock = clock.Add(elapsed)
+		logger.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+	}
+
+	run(200*time.Millisecond, "SELECT 1", nil, nil)
+	if len(logged) != 0 {
+		t.Fatalf("expected fast queries to be skipped, got %v", logged)
+	}
+
+	id := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	city := "Jakarta"
+	run(1500*time.Millisecond, "SELECT *\n\t\tFROM companies\n\t\tWHERE city = $1 AND id > $2 LIMIT $3", []any{&city, id, 50}, nil)
+	run(2*time.Second, "UPDATE users SET password_hash = $1", []any{"$2a$10$secret", nil}, errors.New("canceled"))
+	if len(logged) != 2 {
+		t.Fatalf("expected two slow queries, got %v", logged)
+	}
+	want := "slow query (1.5s, ok): SELECT * FROM companies WHERE city = $1 AND id > $2 LIMIT $3 args=[$1=string(7) $2=aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa $3=50]"
+	if logged[0] != want {
+		t.Fatalf("unexpected log line:\n%s\nwant\n%s", logged[0], want)
+	}
+	if strings.Contains(logged[1], "secret") || !strings.Contains(logged[1], "canceled") || !strings.Contains(logged[1], "$2=NULL") {
+		t.Fatalf("unexpected log line %s", logged[1])
+	}
+}
+
+func TestSummarizeArgs(t *testing.T) {
+	got := summarizeArgs([]any{[]string{"a", "b"}, true, []byte("{}"), time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)})
+	if got != "$1=[]string(2) $2=true $3=bytes(2) $4=2025-01-02T03:04:05Z" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
+
+func TestConnectOptions(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://localhost/leads")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	WithTracer(NewReadOnlyMonitor())(cfg)
+	WithTracer(NewSlowQueryLogger(time.Second))(cfg)
+	if _, ok := cfg.ConnConfig.Tracer.(*ReadOnlyMonitor); ok {
+		t.Fatal("expected the second tracer to be chained, not to replace the first")
+	}
+
+	WithStatementCache("cache_describe", 128)(cfg)
+	if cfg.ConnConfig.DefaultQueryExecMode != pgx.QueryExecModeCacheDescribe || cfg.ConnConfig.DescriptionCacheCapacity != 128 {
+		t.Fatalf("unexpected statement cache config: %v %d", cfg.ConnConfig.DefaultQueryExecMode, cfg.ConnConfig.DescriptionCacheCapacity)
+	}
+	WithStatementCache("", 0)(cfg)
+	if cfg.ConnConfig.DefaultQueryExecMode != pgx.QueryExecModeCacheDescribe {
+		t.Fatal("expected an empty mode to keep the configured one")
+	}
+}
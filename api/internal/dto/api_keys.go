@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/octobees/leads-generator/api/internal/entity"
+
+// CreateAPIKeyRequest is used by administrators to issue a key to a data provider.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreatedAPIKeyResponse carries a new key with its secret, which is shown this once.
+type CreatedAPIKeyResponse struct {
+	entity.APIKey
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// CreateAttachmentRequest announces a file the client is about to upload for a company.
+type CreateAttachmentRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
@@ -6,7 +6,20 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-// LoginResponse contains the issued access token.
+// LoginResponse contains the issued access token and, when refresh tokens are enabled, the refresh
+// token of the new session.
 type LoginResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RefreshRequest exchanges a refresh token for new tokens.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ClientInfo describes the client signing in, shown to users listing their sessions.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
 }
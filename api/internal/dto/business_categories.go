@@ -0,0 +1,10 @@
+package dto
+
+// BusinessCategoryRequest is the payload for creating or replacing a category of the business
+// taxonomy. Name defaults to the label in the form of Places types.
+type BusinessCategoryRequest struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label"`
+	ParentID *string  `json:"parent_id"`
+	Synonyms []string `json:"synonyms"`
+}
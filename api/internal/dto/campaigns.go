@@ -0,0 +1,10 @@
+package dto
+
+// CampaignRequest creates an outreach campaign. The companies it mails are those matching the
+// listing filter of the query; Subject and Body are templates using {{company}}, {{city}} and the
+// other campaign variables.
+type CampaignRequest struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
@@ -21,4 +21,102 @@ type ListFilter struct {
 	PerPage       int
 	Limit         int
 	WebsiteStatus string
+	IsChain       *bool
+	ChainID       *uuid.UUID
+	SizeBuckets   []string
+	// OutreachLanguages are preferred outreach languages; "unknown" also matches undetected leads.
+	OutreachLanguages []string
+	// LegalForms are legal forms such as PT or CV; "none" also matches names without one.
+	LegalForms []string
+	// WebsiteLiveness are website check statuses such as dead or parked; "unchecked" also matches
+	// websites not checked yet.
+	WebsiteLiveness []string
+	// Technologies are website technologies such as wordpress; "none" also matches websites
+	// enrichment analysed without detecting any.
+	Technologies []string
+	// OpenNow keeps companies open (true) or closed (false) at the moment of the query, by their
+	// opening hours in local time; companies without known hours match neither.
+	OpenNow *bool
+	// PriceLevels are price levels from 0 (free) to 4 (very expensive).
+	PriceLevels []int
+	// Categories are place types such as cafe; a company matches when it has any of them.
+	Categories []string
+	// QContact matches an enrichment email, phone number or social handle exactly, ignoring case,
+	// punctuation in phone numbers and the URL around a handle.
+	QContact string
+	// SharedContact keeps companies whose email or phone is (true) or is not (false) shared with
+	// unrelated companies.
+	SharedContact *bool
+	// AssignedTo keeps the companies assigned to that user; Unassigned keeps those nobody works.
+	AssignedTo *uuid.UUID
+	Unassigned bool
+	// BusinessStatus is one of operational, closed, closed_temporarily, closed_permanently or all.
+	BusinessStatus string
+	// ScoreWeight is the share, from 0 to 1, of the lead score in relevance sorting; the rest is
+	// text relevance to Q.
+	ScoreWeight *float64
+	// IncludeRaw selects the raw Places payload, which is left out of listings by default.
+	IncludeRaw bool
+	// IncludeEnrichmentSummary joins the email and phone counts, best social profile and lead
+	// score of each company, so dashboards need not fetch every enrichment.
+	IncludeEnrichmentSummary bool
+	// IncludeSuppressed keeps companies on the do-not-contact list in exports, which leave them
+	// out by default.
+	IncludeSuppressed bool
+
+	// MaxRating closes the rating band opened by MinRating.
+	MaxRating  *float64
+	MinReviews *int
+	// HasPhone and HasSocials keep companies with (true) or without (false) a phone number or
+	// a social profile found by enrichment.
+	HasPhone   *bool
+	HasSocials *bool
+}
+
+// TrendingFilter contains query parameters for GET /companies/trending.
+type TrendingFilter struct {
+	ListFilter
+	// WindowDays bounds how long ago the latest observation of a company may be.
+	WindowDays int
+	// Since is the start of the window, resolved by the service.
+	Since time.Time
+	// By is reviews or rating, the growth trending companies are ranked by.
+	By string
+	// MinReviewGain and MinRatingGain are the least growth between the last two observations.
+	MinReviewGain *int
+	MinRatingGain *float64
+}
+
+// PrivacyRequest names the people, by email, and the companies an erasure or export covers.
+type PrivacyRequest struct {
+	Emails     []string `json:"emails"`
+	CompanyIDs []string `json:"company_ids"`
+}
+
+// AssignCompanyRequest assigns a company to a user id, or to the caller with "me"; a null or empty
+// user_id returns the company to the pool.
+type AssignCompanyRequest struct {
+	UserID *string `json:"user_id"`
+}
+
+// BulkAssignRequest assigns every company matching a listing filter like AssignCompanyRequest.
+// OnlyUnassigned leaves companies already assigned to someone alone.
+type BulkAssignRequest struct {
+	UserID         *string `json:"user_id"`
+	OnlyUnassigned bool    `json:"only_unassigned"`
+}
+
+// CompanyBatchRecord is one company pushed to POST /companies/batch, with the columns of a CSV
+// import. Company and address are required and identify the company.
+type CompanyBatchRecord struct {
+	Company      string   `json:"company"`
+	Address      string   `json:"address"`
+	Phone        string   `json:"phone"`
+	Website      string   `json:"website"`
+	Rating       *float64 `json:"rating"`
+	Reviews      *int     `json:"reviews"`
+	TypeBusiness string   `json:"type_business"`
+	City         string   `json:"city"`
+	Country      string   `json:"country"`
+	Categories   []string `json:"categories"`
 }
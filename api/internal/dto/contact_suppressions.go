@@ -0,0 +1,9 @@
+package dto
+
+// SuppressionRequest puts an email address, a domain or a phone number on the do-not-contact list.
+// Kind is email, domain or phone; a domain may be given as a website URL or an email address.
+type SuppressionRequest struct {
+	Kind  string  `json:"kind"`
+	Value string  `json:"value"`
+	Note  *string `json:"note"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // EnrichResultRequest represents the payload sent by the worker after crawling a website.
 type EnrichResultRequest struct {
 	CompanyID      string              `json:"company_id"`
@@ -11,10 +13,32 @@ type EnrichResultRequest struct {
 	AboutSummary   *string             `json:"about_summary"`
 	Website        string              `json:"website"`
 	PagesCrawled   int                 `json:"pages_crawled"`
+	Depth          string              `json:"depth,omitempty"`
+	// EmployeeMentions is the largest headcount stated on the crawled pages.
+	EmployeeMentions *int `json:"employee_mentions,omitempty"`
+	// SocialFollowers holds follower counts per platform when a vendor reports them.
+	SocialFollowers map[string]int `json:"social_followers,omitempty"`
+	// WebsiteLanguage is the lang attribute of the website's html element, e.g. "id-ID".
+	WebsiteLanguage *string `json:"website_language,omitempty"`
+	// Technologies are the website technologies fingerprinted on the crawled pages, e.g.
+	// "wordpress". An empty list means none was detected; a missing one that none was looked for.
+	Technologies []string `json:"technologies,omitempty"`
+	// LogoURL is the absolute URL of the logo found on the website.
+	LogoURL *string `json:"logo_url,omitempty"`
+	// PhotoReference is a Google Maps photo reference, either a legacy photo_reference or a
+	// Places API (New) name such as "places/{place}/photos/{photo}".
+	PhotoReference *string `json:"photo_reference,omitempty"`
 }
 
 // EnrichJobRequest represents a request to trigger enrichment on the worker.
 type EnrichJobRequest struct {
-	CompanyID string `json:"company_id"`
-	Website   string `json:"website"`
+	CompanyID    string `json:"company_id"`
+	Website      string `json:"website"`
+	Depth        string `json:"depth,omitempty"`
+	ForceRefresh bool   `json:"force_refresh,omitempty"`
+}
+
+// UpdateDeadLetterRequest replaces the body of a dead letter with a fixed one.
+type UpdateDeadLetterRequest struct {
+	Body json.RawMessage `json:"body"`
 }
@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/octobees/leads-generator/api/internal/entity"
+
+// ExportTemplateRequest creates or replaces a saved export template. Empty settings use the export
+// defaults: comma delimiter, dot decimal mark, ISO dates and no BOM.
+type ExportTemplateRequest struct {
+	Name        string `json:"name"`
+	Delimiter   string `json:"delimiter"`
+	DecimalMark string `json:"decimal_mark"`
+	DateFormat  string `json:"date_format"`
+	BOM         bool   `json:"bom"`
+}
+
+// ExportLocaleQuery holds the locale params of an export request. Settings left empty, or BOM left
+// nil, come from Template when one is named and from the defaults otherwise.
+type ExportLocaleQuery struct {
+	Template    string
+	Delimiter   string
+	DecimalMark string
+	DateFormat  string
+	BOM         *bool
+}
+
+// ExportScheduleRequest creates or replaces an export schedule. Filter holds the listing filter of
+// the export; TemplateID names an export template of the caller setting its locale. Target is the
+// recipient address of email destinations, the object prefix of gcs and the URL of webhook ones.
+// Timezone defaults to UTC and Enabled to true.
+type ExportScheduleRequest struct {
+	Name        string                      `json:"name"`
+	Filter      entity.ExportScheduleFilter `json:"filter"`
+	TemplateID  *string                     `json:"template_id"`
+	Destination string                      `json:"destination"`
+	Target      string                      `json:"target"`
+	Cron        string                      `json:"cron"`
+	Timezone    string                      `json:"timezone"`
+	Enabled     *bool                       `json:"enabled"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "encoding/json"
+
+// IngestRunStartRequest opens a chunked ingest run. RunID is optional; a worker resuming a run
+// sends the id it was given so the start is idempotent.
+type IngestRunStartRequest struct {
+	RunID  string `json:"run_id,omitempty"`
+	Source string `json:"source"`
+	Query  string `json:"query"`
+}
+
+// IngestBatchRequest appends one numbered batch to an ingest run; sequences start at 0.
+type IngestBatchRequest struct {
+	Sequence *int         `json:"sequence"`
+	Items    []IngestItem `json:"items"`
+}
+
+// IngestItem is one place streamed by the worker, shaped like its CompanyCandidate.
+type IngestItem struct {
+	// Source is the provider the place was scraped from, Google Maps when empty.
+	Source string `json:"source,omitempty"`
+	// PlaceID is the id the source gives the place. For Google Maps it is taken from
+	// raw_snapshot.place_id when empty.
+	PlaceID      string          `json:"place_id,omitempty"`
+	Name         string          `json:"name"`
+	Address      *string         `json:"address,omitempty"`
+	Phone        *string         `json:"phone,omitempty"`
+	Website      *string         `json:"website,omitempty"`
+	Latitude     *float64        `json:"latitude,omitempty"`
+	Longitude    *float64        `json:"longitude,omitempty"`
+	Rating       *float64        `json:"rating,omitempty"`
+	ReviewCount  *int            `json:"review_count,omitempty"`
+	TypeBusiness *string         `json:"type_business,omitempty"`
+	City         *string         `json:"city,omitempty"`
+	Country      *string         `json:"country,omitempty"`
+	RawSnapshot  json.RawMessage `json:"raw_snapshot,omitempty"`
+}
+
+// IngestRunFinishRequest closes an ingest run once all TotalBatches batches were sent.
+type IngestRunFinishRequest struct {
+	TotalBatches *int `json:"total_batches"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/octobees/leads-generator/api/internal/entity"
+
+// LocationRequest is the payload for creating or replacing a location of the catalog. Coordinates
+// left out are filled in by the geocoder when one is configured.
+type LocationRequest struct {
+	City        string              `json:"city"`
+	Region      *string             `json:"region"`
+	Country     string              `json:"country"`
+	Aliases     []string            `json:"aliases"`
+	Latitude    *float64            `json:"latitude"`
+	Longitude   *float64            `json:"longitude"`
+	BoundingBox *entity.BoundingBox `json:"bounding_box"`
+}
@@ -18,3 +18,10 @@ type PromptSearchResponse struct {
 	Limit        int     `json:"limit,omitempty"`
 	RequireNoWebsite bool `json:"require_no_website"`
 }
+
+// PromptAliasRequest creates or replaces a prompt parser alias.
+type PromptAliasRequest struct {
+	Kind      string `json:"kind"`
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+}
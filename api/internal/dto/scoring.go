@@ -0,0 +1,17 @@
+package dto
+
+// ScoringProfileRequest creates or replaces a lead scoring profile. Omitted thresholds take the
+// defaults of 70 (hot) and 40 (warm); zero disables a tier.
+type ScoringProfileRequest struct {
+	Name          string         `json:"name"`
+	Weights       map[string]int `json:"weights"`
+	Inverted      []string       `json:"inverted"`
+	HotThreshold  *int           `json:"hot_threshold"`
+	WarmThreshold *int           `json:"warm_threshold"`
+}
+
+// ScoreRecomputeRequest is the optional payload of POST /admin/scores/recompute.
+type ScoreRecomputeRequest struct {
+	// StaleOnly limits the job to scores missing or computed with another scoring profile revision.
+	StaleOnly bool `json:"stale_only"`
+}
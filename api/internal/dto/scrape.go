@@ -1,10 +1,22 @@
 package dto
 
+import "time"
+
 // ScrapeRequest is the payload used by the scraping endpoint.
 type ScrapeRequest struct {
+	// Source is the provider to scrape, Google Maps when empty.
+	Source       string  `json:"source,omitempty"`
 	TypeBusiness string  `json:"type_business"`
 	Location     string  `json:"location,omitempty"`
 	MinRating    float64 `json:"min_rating,omitempty"`
 	City         string  `json:"city,omitempty"`
 	Country      string  `json:"country,omitempty"`
 }
+
+// FeatureToggleRequest switches a worker-dependent feature off or back on.
+type FeatureToggleRequest struct {
+	Disabled bool `json:"disabled"`
+	// Message and ETA are shown to callers while the feature is disabled.
+	Message string     `json:"message,omitempty"`
+	ETA     *time.Time `json:"eta,omitempty"`
+}
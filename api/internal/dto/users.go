@@ -1,5 +1,17 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// User listing sort columns.
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortEmail     = "email"
+)
+
 // RegisterRequest captures self-service registration payloads.
 type RegisterRequest struct {
 	Email    string `json:"email"`
@@ -20,9 +32,51 @@ type UpdateUserRequest struct {
 	Role     *string `json:"role,omitempty"`
 }
 
+// UserListFilter narrows and orders the admin users listing.
+type UserListFilter struct {
+	// Email matches users whose email contains it, ignoring case.
+	Email string
+	Role  string
+	// Sort is UserSortCreatedAt or UserSortEmail; ties are broken by id in the same direction.
+	Sort  string
+	Desc  bool
+	After *entity.UserCursor
+	Limit int
+}
+
 // UserResponse represents user data returned to clients.
 type UserResponse struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID        string     `json:"id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// ProfileResponse is the signed-in user's own account.
+type ProfileResponse struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	// PendingEmailChange is the new address waiting to be confirmed, if any.
+	PendingEmailChange *entity.EmailChange `json:"pending_email_change,omitempty"`
+}
+
+// UpdateProfileRequest changes the signed-in user's email or password. Both require the current
+// password; a new email only applies once confirmed from that address.
+type UpdateProfileRequest struct {
+	Email           *string `json:"email,omitempty"`
+	Password        *string `json:"password,omitempty"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+// VerifyEmailRequest confirms a pending email change.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// AcceptInvitationRequest is sent by invitees to create their account.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
 }
@@ -0,0 +1,48 @@
+package dto
+
+// WorkerScrapeRequest is the payload the API posts to the worker /scrape endpoint. The worker
+// streams the results to /ingest/runs under RunID, presenting CallbackToken as a bearer token.
+type WorkerScrapeRequest struct {
+	Source        string  `json:"source,omitempty"`
+	TypeBusiness  string  `json:"type_business"`
+	City          string  `json:"city"`
+	Country       string  `json:"country"`
+	MinRating     float64 `json:"min_rating,omitempty"`
+	RunID         string  `json:"run_id,omitempty"`
+	CallbackToken string  `json:"callback_token,omitempty"`
+}
+
+// WorkerEnrichRequest is the payload the API posts to the worker /enrich endpoint. The worker
+// presents CallbackToken as a bearer token when it posts the result to /enrich-result.
+type WorkerEnrichRequest struct {
+	CompanyID     string `json:"company_id"`
+	Website       string `json:"website"`
+	Depth         string `json:"depth"`
+	MaxPages      int    `json:"max_pages"`
+	JobID         string `json:"job_id,omitempty"`
+	CallbackToken string `json:"callback_token,omitempty"`
+}
+
+// WorkerPlaceLookupRequest is the payload the API posts to the worker /lookup endpoint to find the
+// place of a company again. Country only picks the worker route.
+type WorkerPlaceLookupRequest struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Country string `json:"country,omitempty"`
+}
+
+// WorkerPlaceLookupResult is the data of a /lookup answer; Found is false when no place matched.
+type WorkerPlaceLookupResult struct {
+	Found   bool     `json:"found"`
+	PlaceID string   `json:"place_id"`
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Rating  *float64 `json:"rating"`
+	Reviews *int     `json:"reviews"`
+}
+
+// WorkerResponse is the envelope every worker endpoint replies with; Error is set on failure.
+type WorkerResponse struct {
+	Data  map[string]any `json:"data"`
+	Error string         `json:"error"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScopeCompaniesWrite lets a key push companies through POST /companies/batch.
+const APIKeyScopeCompaniesWrite = "companies:write"
+
+// APIKeyScopes lists the scopes a key may be granted.
+var APIKeyScopes = []string{APIKeyScopeCompaniesWrite}
+
+// APIKey authenticates a data provider. Only a hash of the key is stored; Prefix is its start,
+// shown so administrators can tell keys apart.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BusinessCategory is a category of the business taxonomy. Business types and categories of
+// imported and scraped companies are normalised onto it when they match its name, label or one of
+// its Synonyms.
+type BusinessCategory struct {
+	ID uuid.UUID `json:"id"`
+	// Name is the category in the form of Places types, such as restaurant; Label is how it is shown.
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	// ParentID groups the category under a broader one; nil for top-level categories.
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	// Synonyms are other words for the category, lowercased.
+	Synonyms  []string  `json:"synonyms"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
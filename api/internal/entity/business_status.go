@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// ClosureArea summarises businesses in one city that recently switched to a closed status.
+type ClosureArea struct {
+	Country           string    `json:"country"`
+	City              string    `json:"city"`
+	NewlyClosed       int       `json:"newly_closed"`
+	ClosedPermanently int       `json:"closed_permanently"`
+	ClosedTemporarily int       `json:"closed_temporarily"`
+	LastClosedAt      time.Time `json:"last_closed_at"`
+}
+
+// ClosureReport lists newly closed businesses per area since a point in time.
+type ClosureReport struct {
+	Since time.Time     `json:"since"`
+	Total int           `json:"total"`
+	Areas []ClosureArea `json:"areas"`
+}
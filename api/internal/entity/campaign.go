@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Campaign statuses: a draft is edited freely, sending starts once its recipients are resolved,
+// and sent once none is left queued.
+const (
+	CampaignStatusDraft   = "draft"
+	CampaignStatusSending = "sending"
+	CampaignStatusSent    = "sent"
+)
+
+// Campaign recipient statuses.
+const (
+	RecipientStatusQueued       = "queued"
+	RecipientStatusSending      = "sending"
+	RecipientStatusSent         = "sent"
+	RecipientStatusOpened       = "opened"
+	RecipientStatusBounced      = "bounced"
+	RecipientStatusComplained   = "complained"
+	RecipientStatusUnsubscribed = "unsubscribed"
+	RecipientStatusFailed       = "failed"
+	RecipientStatusSuppressed   = "suppressed"
+)
+
+// Campaign is an outreach e-mail sent to the companies matching a listing filter. Query is the
+// listing query string it was created with; Counts holds its recipients per status.
+type Campaign struct {
+	ID         uuid.UUID        `json:"id"`
+	Name       string           `json:"name"`
+	Query      string           `json:"query"`
+	Filter     []byte           `json:"-"`
+	Subject    string           `json:"subject"`
+	Body       string           `json:"body"`
+	Status     string           `json:"status"`
+	Counts     map[string]int64 `json:"counts"`
+	CreatedBy  *uuid.UUID       `json:"created_by,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+// CampaignRecipient is a company mailed by a campaign, with the template variables resolved when
+// sending started and the delivery status reported since.
+type CampaignRecipient struct {
+	ID         uuid.UUID         `json:"id"`
+	CampaignID uuid.UUID         `json:"campaign_id"`
+	CompanyID  *uuid.UUID        `json:"company_id,omitempty"`
+	Email      string            `json:"email"`
+	Variables  map[string]string `json:"variables"`
+	Status     string            `json:"status"`
+	MessageID  *string           `json:"message_id,omitempty"`
+	Error      *string           `json:"error,omitempty"`
+	SentAt     *time.Time        `json:"sent_at,omitempty"`
+	OpenedAt   *time.Time        `json:"opened_at,omitempty"`
+	BouncedAt  *time.Time        `json:"bounced_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// CampaignDelivery is a recipient claimed for sending, with the template of its campaign.
+type CampaignDelivery struct {
+	Recipient CampaignRecipient
+	Subject   string
+	Body      string
+}
+
+// CampaignLead is a company a campaign may mail: its enrichment and website emails, enrichment
+// first, and the values of its template variables.
+type CampaignLead struct {
+	CompanyID uuid.UUID
+	Emails    []string
+	Variables map[string]string
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Change feed entity kinds and operations.
+const (
+	ChangeEntityCompany    = "company"
+	ChangeEntityEnrichment = "enrichment"
+
+	ChangeOperationCreate = "create"
+	ChangeOperationUpdate = "update"
+	ChangeOperationDelete = "delete"
+)
+
+// ChangeCursor is a position in the change feed: the writing transaction then the event sequence.
+type ChangeCursor struct {
+	TxID uint64
+	ID   int64
+}
+
+// ChangeEvent records one create/update/delete of a company or an enrichment. Data holds the row as
+// written (companies without raw) and is empty for deletes; enrichment events use the company id.
+type ChangeEvent struct {
+	Cursor     ChangeCursor    `json:"-"`
+	Entity     string          `json:"entity"`
+	EntityID   uuid.UUID       `json:"entity_id"`
+	Operation  string          `json:"operation"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
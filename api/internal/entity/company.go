@@ -9,22 +9,130 @@ import (
 
 // Company represents a business stored in the catalogue.
 type Company struct {
-	ID           uuid.UUID       `json:"id"`
-	PlaceID      *string         `json:"place_id,omitempty"`
-	ScrapeRunID  *uuid.UUID      `json:"scrape_run_id,omitempty"`
-	Company      string          `json:"company"`
-	Phone        *string         `json:"phone,omitempty"`
-	Website      *string         `json:"website,omitempty"`
-	Rating       *float64        `json:"rating,omitempty"`
-	Reviews      *int            `json:"reviews,omitempty"`
-	TypeBusiness *string         `json:"type_business,omitempty"`
-	Address      *string         `json:"address,omitempty"`
-	City         *string         `json:"city,omitempty"`
-	Country      *string         `json:"country,omitempty"`
-	Longitude    *float64        `json:"longitude,omitempty"`
-	Latitude     *float64        `json:"latitude,omitempty"`
-	Raw          json.RawMessage `json:"raw"`
-	ScrapedAt    *time.Time      `json:"scraped_at,omitempty"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	ID              uuid.UUID       `json:"id"`
+	PlaceID         *string         `json:"place_id,omitempty"`
+	ScrapeRunID     *uuid.UUID      `json:"scrape_run_id,omitempty"`
+	ChainID         *uuid.UUID      `json:"chain_id,omitempty"`
+	SizeBucket      *string         `json:"size_bucket,omitempty"`
+	BusinessStatus  *string         `json:"business_status,omitempty"`
+	StatusChangedAt *time.Time      `json:"status_changed_at,omitempty"`
+	Company         string          `json:"company"`
+	Phone           *string         `json:"phone,omitempty"`
+	Website         *string         `json:"website,omitempty"`
+	Rating          *float64        `json:"rating,omitempty"`
+	Reviews         *int            `json:"reviews,omitempty"`
+	TypeBusiness    *string         `json:"type_business,omitempty"`
+	Address         *string         `json:"address,omitempty"`
+	City            *string         `json:"city,omitempty"`
+	Country         *string         `json:"country,omitempty"`
+	Longitude       *float64        `json:"longitude,omitempty"`
+	Latitude        *float64        `json:"latitude,omitempty"`
+	Raw             json.RawMessage `json:"raw,omitempty"`
+	ScrapedAt       *time.Time      `json:"scraped_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	// PreferredOutreachLanguage is the ISO 639-1 language to contact the company in, detected from
+	// the signal named by OutreachLanguageSource.
+	PreferredOutreachLanguage *string `json:"preferred_outreach_language,omitempty"`
+	OutreachLanguageSource    *string `json:"outreach_language_source,omitempty"`
+
+	// LegalForm is the legal form carried by the name (PT, CV...); DisplayName is the name without
+	// it, which is also what companies without a place id are matched on.
+	LegalForm   *string `json:"legal_form,omitempty"`
+	DisplayName *string `json:"display_name,omitempty"`
+
+	// SharedContact flags companies whose email or phone number is shared with unrelated companies,
+	// as found by the last contact collision detection.
+	SharedContact bool `json:"shared_contact"`
+
+	// DoNotContact flags companies with an email, domain or phone number on the do-not-contact
+	// list. It is set when companies are listed, not stored.
+	DoNotContact bool `json:"do_not_contact"`
+
+	// AssignedTo is the user the company is assigned to as a lead, if any.
+	AssignedTo *uuid.UUID `json:"assigned_to,omitempty"`
+
+	// WebsiteStatus is the website liveness found by the last check at WebsiteCheckedAt;
+	// WebsiteRedirectsTo is where a redirected website leads.
+	WebsiteStatus      *string    `json:"website_status,omitempty"`
+	WebsiteRedirectsTo *string    `json:"website_redirects_to,omitempty"`
+	WebsiteCheckedAt   *time.Time `json:"website_checked_at,omitempty"`
+
+	// LogoURL is the logo found on the website, served from its mirrored copy once image mirroring
+	// stored one. PhotoURL is the mirrored copy of the Maps photo PhotoReference, since references
+	// only resolve with a Places API key.
+	LogoURL        *string `json:"logo_url,omitempty"`
+	PhotoURL       *string `json:"photo_url,omitempty"`
+	PhotoReference *string `json:"photo_reference,omitempty"`
+
+	// OpeningHours, UTCOffsetMinutes, PriceLevel and Categories are extracted from the raw place
+	// payload when the company is stored. PriceLevel runs from 0 (free) to 4 (very expensive).
+	OpeningHours     []OpeningPeriod `json:"opening_hours,omitempty"`
+	UTCOffsetMinutes *int            `json:"utc_offset_minutes,omitempty"`
+	PriceLevel       *int            `json:"price_level,omitempty"`
+	Categories       []string        `json:"categories,omitempty"`
+
+	// EnrichmentSummary is only loaded by listings asking for include=enrichment_summary.
+	EnrichmentSummary *EnrichmentSummary `json:"enrichment_summary,omitempty"`
+}
+
+// EnrichmentSummary condenses the enrichment and lead score of a company for listings. Enriched is
+// false for companies never enriched, which have no counts; Score is nil until one is computed.
+type EnrichmentSummary struct {
+	Enriched   bool    `json:"enriched"`
+	EmailCount int     `json:"email_count"`
+	PhoneCount int     `json:"phone_count"`
+	BestSocial *string `json:"best_social,omitempty"`
+	Score      *int    `json:"score,omitempty"`
+}
+
+// OpeningPeriod is a span of a day a place is open: Day runs from 0 (Sunday) to 6, Open and Close
+// are local "HH:MM" times, Close "24:00" at midnight. Periods crossing midnight are split at it.
+type OpeningPeriod struct {
+	Day   int    `json:"day"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// CompanyAssignment assigns a company to the sales rep working it as a lead.
+type CompanyAssignment struct {
+	CompanyID  uuid.UUID  `json:"company_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	AssignedBy *uuid.UUID `json:"assigned_by,omitempty"`
+	AssignedAt time.Time  `json:"assigned_at"`
+}
+
+// CompanyName is the stored name of a company, read when its legal form is extracted again.
+type CompanyName struct {
+	CompanyID uuid.UUID
+	Company   string
+}
+
+// PlaceAttributes are the opening hours, price level and categories extracted from a raw place
+// payload, stored on the company columns of the same name.
+type PlaceAttributes struct {
+	OpeningHours     []OpeningPeriod
+	UTCOffsetMinutes *int
+	PriceLevel       *int
+	Categories       []string
+}
+
+// CompanyPlacePayload is the raw place payload and business type of a company, read when place
+// attributes are extracted again. Offloaded payloads live in object storage under RawRef and
+// leave Raw empty.
+type CompanyPlacePayload struct {
+	CompanyID    uuid.UUID
+	TypeBusiness *string
+	Raw          json.RawMessage
+	RawRef       *string
+}
+
+// CompanyContactFields are the stored phone and website of a company with the country their
+// phone is read in, read when contact fields are normalized again.
+type CompanyContactFields struct {
+	CompanyID uuid.UUID
+	Phone     *string
+	Website   *string
+	Country   *string
 }
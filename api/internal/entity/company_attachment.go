@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Company attachment statuses.
+const (
+	// AttachmentStatusPending marks an attachment whose upload URL was issued but not confirmed.
+	AttachmentStatusPending = "pending"
+	AttachmentStatusReady   = "ready"
+)
+
+// CompanyAttachment is a file such as a proposal or call recording kept for a company.
+type CompanyAttachment struct {
+	ID          uuid.UUID  `json:"id"`
+	CompanyID   uuid.UUID  `json:"company_id"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	StorageKey  string     `json:"-"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UploadedAt  *time.Time `json:"uploaded_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanyChain groups company locations that share a website domain or normalised name across cities.
+type CompanyChain struct {
+	ID            uuid.UUID `json:"id"`
+	ChainKey      string    `json:"chain_key"`
+	Name          string    `json:"name"`
+	Domain        *string   `json:"domain,omitempty"`
+	LocationCount int       `json:"location_count"`
+	CityCount     int       `json:"city_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ChainCityCount reports how many chain locations exist in a city.
+type ChainCityCount struct {
+	City      string `json:"city"`
+	Locations int    `json:"locations"`
+}
+
+// ChainRollup aggregates metrics across every location of a chain.
+type ChainRollup struct {
+	Chain         CompanyChain     `json:"chain"`
+	AverageRating *float64         `json:"average_rating,omitempty"`
+	TotalReviews  int              `json:"total_reviews"`
+	Countries     []string         `json:"countries"`
+	Cities        []ChainCityCount `json:"cities"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanyChangeCursor is a watermark in the company sync feed: the change time, then the company id
+// to break ties.
+type CompanyChangeCursor struct {
+	ChangedAt time.Time
+	ID        uuid.UUID
+}
+
+// CompanyChange is the latest state of one company since a watermark. Company is set for creates
+// and updates and omitted for deletes.
+type CompanyChange struct {
+	Cursor    CompanyChangeCursor `json:"-"`
+	Operation string              `json:"operation"`
+	CompanyID uuid.UUID           `json:"company_id"`
+	ChangedAt time.Time           `json:"changed_at"`
+	Company   *Company            `json:"company,omitempty"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+// CompanyFacets counts the companies matching a listing filter per filterable dimension, so clients
+// can render filter sidebars next to the result page.
+type CompanyFacets struct {
+	Cities        []StatBucket `json:"city"`
+	BusinessTypes []StatBucket `json:"type_business"`
+	Categories    []StatBucket `json:"category"`
+	WebsiteStatus []StatBucket `json:"website_status"`
+	Ratings       []StatBucket `json:"rating"`
+}
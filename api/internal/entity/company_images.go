@@ -0,0 +1,13 @@
+package entity
+
+import "github.com/google/uuid"
+
+// CompanyImages are the logo and Maps photo of a company with the URLs of their mirrored copies,
+// read when images are mirrored to object storage.
+type CompanyImages struct {
+	CompanyID      uuid.UUID
+	LogoURL        *string
+	PhotoReference *string
+	LogoMirrorURL  *string
+	PhotoMirrorURL *string
+}
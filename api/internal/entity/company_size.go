@@ -0,0 +1,13 @@
+package entity
+
+import "github.com/google/uuid"
+
+// CompanySizeSignals gathers the indicators used to estimate how large a business is.
+type CompanySizeSignals struct {
+	CompanyID        uuid.UUID
+	Reviews          *int
+	Photos           *int
+	SocialFollowers  *int
+	EmployeeMentions *int
+	SizeBucket       *string
+}
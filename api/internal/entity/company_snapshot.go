@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sources of a company snapshot.
+const (
+	SnapshotSourceScrape  = "scrape"
+	SnapshotSourceRefresh = "refresh"
+)
+
+// CompanySnapshot is the rating and review count a scrape run observed for a company, or that a
+// place refresh found; ScrapeRunID is then the id of the refresh.
+type CompanySnapshot struct {
+	ScrapeRunID uuid.UUID `json:"scrape_run_id"`
+	Source      string    `json:"source"`
+	Rating      *float64  `json:"rating,omitempty"`
+	Reviews     *int      `json:"reviews,omitempty"`
+	ObservedAt  time.Time `json:"observed_at"`
+	// PlaceID and PreviousPlaceID are the place ids after and before a refresh.
+	PlaceID         *string `json:"place_id,omitempty"`
+	PreviousPlaceID *string `json:"previous_place_id,omitempty"`
+	// ReviewsDelta is the change in reviews since the previous observation with a count.
+	ReviewsDelta *int `json:"reviews_delta,omitempty"`
+}
+
+// CompanyHistory is the timeline of scrape observations of a company, oldest first.
+type CompanyHistory struct {
+	CompanyID uuid.UUID         `json:"company_id"`
+	Snapshots []CompanySnapshot `json:"snapshots"`
+	// ReviewGrowth is the change in reviews between the first and the last observation with a
+	// count, omitted until two such observations exist.
+	ReviewGrowth *int `json:"review_growth,omitempty"`
+}
+
+// PlaceRefresh is a company looked up again on Google Maps by name and address. Its rating and
+// reviews replace the stored ones, and so does PlaceID when Google retired the previous one.
+type PlaceRefresh struct {
+	ID              uuid.UUID `json:"id"`
+	CompanyID       uuid.UUID `json:"company_id"`
+	PlaceID         string    `json:"place_id"`
+	PreviousPlaceID *string   `json:"previous_place_id,omitempty"`
+	PlaceIDChanged  bool      `json:"place_id_changed"`
+	Rating          *float64  `json:"rating,omitempty"`
+	Reviews         *int      `json:"reviews,omitempty"`
+	RefreshedAt     time.Time `json:"refreshed_at"`
+}
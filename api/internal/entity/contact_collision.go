@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Contact collision kinds.
+const (
+	ContactKindEmail = "email"
+	ContactKindPhone = "phone"
+)
+
+// ContactCollision is an email address or phone number found on several unrelated companies,
+// usually an agency, a directory listing or a data error rather than the business itself.
+type ContactCollision struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	// CompanyCount counts the companies carrying the contact; OwnerCount counts a chain as one.
+	CompanyCount int         `json:"company_count"`
+	OwnerCount   int         `json:"owner_count"`
+	CompanyIDs   []uuid.UUID `json:"company_ids"`
+	DetectedAt   time.Time   `json:"detected_at"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of contact on the do-not-contact list.
+const (
+	SuppressionKindEmail  = "email"
+	SuppressionKindDomain = "domain"
+	SuppressionKindPhone  = "phone"
+)
+
+// Reasons a contact is on the do-not-contact list.
+const (
+	SuppressionUnsubscribe = "unsubscribe"
+	SuppressionBounce      = "bounce"
+	SuppressionComplaint   = "complaint"
+	SuppressionManual      = "manual"
+)
+
+// ContactSuppression is an email address, a domain or a phone number nobody may contact. A domain
+// covers its subdomains, the addresses under it and the websites on it; Value is normalised for
+// its kind. CampaignID is the campaign whose recipient unsubscribed, bounced or complained.
+type ContactSuppression struct {
+	ID         uuid.UUID  `json:"id"`
+	Kind       string     `json:"kind"`
+	Value      string     `json:"value"`
+	Reason     string     `json:"reason"`
+	Note       *string    `json:"note,omitempty"`
+	CampaignID *uuid.UUID `json:"campaign_id,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CompanyContacts gathers the phone number and website of a company with the emails and phone
+// numbers its enrichments found, as stored, to check them against the do-not-contact list.
+type CompanyContacts struct {
+	CompanyID uuid.UUID
+	Website   string
+	Emails    []string
+	Phones    []string
+}
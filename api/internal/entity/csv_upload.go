@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CSV upload statuses.
+const (
+	CSVUploadStatusPending   = "pending"
+	CSVUploadStatusCompleted = "completed"
+	CSVUploadStatusFailed    = "failed"
+)
+
+// CSVUpload records a retained copy of an admin CSV upload and the outcome of its import.
+type CSVUpload struct {
+	ID          uuid.UUID  `json:"id"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty"`
+	Filename    string     `json:"filename"`
+	SHA256      string     `json:"sha256"`
+	SizeBytes   int64      `json:"size_bytes"`
+	StorageKey  string     `json:"-"`
+	Mode        string     `json:"mode"`
+	Status      string     `json:"status"`
+	Inserted    int        `json:"inserted"`
+	Updated     int        `json:"updated"`
+	Skipped     int        `json:"skipped"`
+	Total       int        `json:"total"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
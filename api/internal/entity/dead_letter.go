@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetterEnrichResult is the kind of dead letters holding /enrich-result payloads.
+const DeadLetterEnrichResult = "enrich_result"
+
+// Dead letter statuses.
+const (
+	DeadLetterPending  = "pending"
+	DeadLetterReplayed = "replayed"
+)
+
+// DeadLetter is a worker callback the API could not store, kept as received so an operator can fix
+// and replay it.
+type DeadLetter struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
+	// Body is the payload as received; it may not be valid JSON.
+	Body   string `json:"body"`
+	Reason string `json:"reason"`
+	// Details holds the rejected values per field of a payload that failed validation.
+	Details map[string]string `json:"details,omitempty"`
+	// CompanyID and JobID are read from the payload and the callback token when they could be.
+	CompanyID *uuid.UUID `json:"company_id,omitempty"`
+	JobID     *uuid.UUID `json:"job_id,omitempty"`
+	Status    string     `json:"status"`
+	// Attempts counts the replays; LastError is why the latest one failed.
+	Attempts   int        `json:"attempts"`
+	LastError  *string    `json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEnrichment caches the latest enrichment result for a canonical website domain.
+type DomainEnrichment struct {
+	Domain          string              `json:"domain"`
+	SourceCompanyID *uuid.UUID          `json:"source_company_id,omitempty"`
+	Depth           string              `json:"depth"`
+	Emails          []string            `json:"emails"`
+	Phones          []string            `json:"phones"`
+	Socials         map[string][]string `json:"socials"`
+	Address         *string             `json:"address"`
+	ContactFormURL  *string             `json:"contact_form_url"`
+	AboutSummary    *string             `json:"about_summary"`
+	Metadata        map[string]any      `json:"metadata"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EnrichmentJob records an enrichment request forwarded to the worker for cost accounting.
+type EnrichmentJob struct {
+	ID          uuid.UUID  `json:"id"`
+	CompanyID   uuid.UUID  `json:"company_id"`
+	RequestedBy *uuid.UUID `json:"requested_by,omitempty"`
+	Website     string     `json:"website"`
+	Depth       string     `json:"depth"`
+	Cost        int        `json:"cost"`
+	Status      string     `json:"status"`
+	// Error is why the worker did not accept a failed job.
+	Error *string `json:"error,omitempty"`
+	// Retries counts how often a failed job was sent to the worker again.
+	Retries   int       `json:"retries"`
+	CreatedAt time.Time `json:"created_at"`
+}
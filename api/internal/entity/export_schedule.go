@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export schedule destinations.
+const (
+	ExportDestinationEmail   = "email"
+	ExportDestinationGCS     = "gcs"
+	ExportDestinationWebhook = "webhook"
+)
+
+// Export delivery statuses.
+const (
+	ExportDeliveryDelivered = "delivered"
+	ExportDeliveryFailed    = "failed"
+)
+
+// ExportScheduleFilter is the saved listing filter of a scheduled export, a subset of the filter
+// of GET /exports/companies; Categories are matched like the category param.
+type ExportScheduleFilter struct {
+	Q                 string   `json:"q,omitempty"`
+	TypeBusiness      string   `json:"type_business,omitempty"`
+	City              string   `json:"city,omitempty"`
+	Country           string   `json:"country,omitempty"`
+	Categories        []string `json:"categories,omitempty"`
+	MinRating         *float64 `json:"min_rating,omitempty"`
+	MaxRating         *float64 `json:"max_rating,omitempty"`
+	MinReviews        *int     `json:"min_reviews,omitempty"`
+	Website           string   `json:"website,omitempty"`
+	HasPhone          *bool    `json:"has_phone,omitempty"`
+	Sort              string   `json:"sort,omitempty"`
+	Limit             int      `json:"limit,omitempty"`
+	IncludeSuppressed bool     `json:"include_suppressed,omitempty"`
+}
+
+// ExportSchedule exports the companies matching a saved filter on a cron schedule and delivers the
+// file to its destination. Target is the recipient address of email deliveries, the object prefix
+// of gcs deliveries and the URL of webhook deliveries.
+type ExportSchedule struct {
+	ID          uuid.UUID            `json:"id"`
+	UserID      uuid.UUID            `json:"user_id"`
+	Name        string               `json:"name"`
+	Filter      ExportScheduleFilter `json:"filter"`
+	TemplateID  *uuid.UUID           `json:"template_id,omitempty"`
+	Destination string               `json:"destination"`
+	Target      string               `json:"target"`
+	Cron        string               `json:"cron"`
+	Timezone    string               `json:"timezone"`
+	Enabled     bool                 `json:"enabled"`
+	NextRunAt   time.Time            `json:"next_run_at"`
+	LastRunAt   *time.Time           `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// ExportDelivery records one run of an export schedule. Location is where the file went: the
+// object URI, the webhook URL or the recipient address.
+type ExportDelivery struct {
+	ID         uuid.UUID `json:"id"`
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	Status     string    `json:"status"`
+	RowCount   int64     `json:"row_count"`
+	FileName   string    `json:"file_name"`
+	Location   *string   `json:"location,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportTemplate is a named set of CSV export locale settings saved by a user.
+type ExportTemplate struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Delimiter   string    `json:"delimiter"`
+	DecimalMark string    `json:"decimal_mark"`
+	DateFormat  string    `json:"date_format"`
+	BOM         bool      `json:"bom"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Features that depend on the worker and can be switched off by admins.
+const (
+	FeatureScrape       = "scrape"
+	FeaturePromptSearch = "prompt_search"
+	FeatureEnrich       = "enrich"
+)
+
+// Features lists every feature that can be toggled.
+var Features = []string{FeatureScrape, FeaturePromptSearch, FeatureEnrich}
+
+// FeatureToggle records whether a feature is switched off and what callers are told meanwhile.
+type FeatureToggle struct {
+	Feature  string `json:"feature"`
+	Disabled bool   `json:"disabled"`
+	// Message explains the outage to callers; ETA is when the feature is expected back.
+	Message   string     `json:"message,omitempty"`
+	ETA       *time.Time `json:"eta,omitempty"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Import job statuses.
+const (
+	ImportJobStatusQueued    = "queued"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+)
+
+// Import job kinds.
+const (
+	ImportJobKindCompanies = "companies"
+	ImportJobKindUsers     = "users"
+)
+
+// Import row result statuses.
+const (
+	ImportRowInvited   = "invited"
+	ImportRowReinvited = "reinvited"
+	ImportRowSkipped   = "skipped"
+	ImportRowFailed    = "failed"
+)
+
+// ImportRowError describes a row an import job could not use.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportRowResult reports what a user import did with one row. Token is the invitation token to
+// hand to the invitee; it is only kept here, the invitation stores its hash.
+type ImportRowResult struct {
+	Row          int        `json:"row"`
+	Email        string     `json:"email"`
+	Status       string     `json:"status"`
+	Message      string     `json:"message,omitempty"`
+	InvitationID *uuid.UUID `json:"invitation_id,omitempty"`
+	Token        string     `json:"token,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// ImportJob tracks an asynchronous import from an uploaded CSV or XLSX file: companies, or user
+// invitations when Kind is ImportJobKindUsers.
+type ImportJob struct {
+	ID         uuid.UUID         `json:"id"`
+	Kind       string            `json:"kind"`
+	CreatedBy  *uuid.UUID        `json:"created_by,omitempty"`
+	UploadID   *uuid.UUID        `json:"upload_id,omitempty"`
+	Filename   string            `json:"filename"`
+	Mode       string            `json:"mode"`
+	Mapping    map[string]string `json:"mapping,omitempty"`
+	StorageKey string            `json:"-"`
+	Status     string            `json:"status"`
+	// SizeBytes and BytesRead let clients derive a progress percentage.
+	SizeBytes     int64 `json:"size_bytes"`
+	BytesRead     int64 `json:"bytes_read"`
+	ProcessedRows int   `json:"processed_rows"`
+	Inserted      int   `json:"inserted"`
+	Updated       int   `json:"updated"`
+	Skipped       int   `json:"skipped"`
+	// ErrorCount counts every rejected row; RowErrors keeps the first ones.
+	ErrorCount int              `json:"error_count"`
+	RowErrors  []ImportRowError `json:"row_errors"`
+	// Results lists every row of a user import.
+	Results    []ImportRowResult `json:"results,omitempty"`
+	Error      *string           `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
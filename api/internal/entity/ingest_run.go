@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ingest run statuses.
+const (
+	IngestRunOpen     = "open"
+	IngestRunFinished = "finished"
+)
+
+// IngestRun is a scrape the worker streams in numbered batches. Its ID is the scrape_run_id of the
+// companies it ingests.
+type IngestRun struct {
+	ID     uuid.UUID `json:"id"`
+	Source string    `json:"source"`
+	Query  string    `json:"query"`
+	Status string    `json:"status"`
+	// Batches and Items count what was applied; replayed batches are not counted twice.
+	Batches int `json:"batches"`
+	Items   int `json:"items"`
+	// NextSequence is the lowest sequence not received yet, where a resumed worker continues.
+	NextSequence int `json:"next_sequence"`
+	// TotalBatches is the batch count the worker reported when finishing the run.
+	TotalBatches *int       `json:"total_batches,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IngestBatch is one numbered chunk of an ingest run.
+type IngestBatch struct {
+	Sequence int `json:"sequence"`
+	// Checksum identifies the batch content, so a replay can be told from a conflicting batch.
+	Checksum string `json:"checksum"`
+	Items    int    `json:"items"`
+	// Duplicate reports that the batch had already been applied and was acknowledged again.
+	Duplicate bool `json:"duplicate"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// LeadReport is what the lead sheet of a company shows: the company, the contacts enrichment found
+// and its lead score. Enrichment and score fields are empty until the company is enriched and
+// scored.
+type LeadReport struct {
+	Company        Company
+	Emails         []string
+	Phones         []string
+	Socials        map[string][]string
+	ContactFormURL *string
+	AboutSummary   *string
+	EnrichedAt     *time.Time
+	Score          *int
+	ScoreBreakdown map[string]int
+	ScoredAt       *time.Time
+}
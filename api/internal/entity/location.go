@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location is a city of the location catalog. City values of imported and scraped companies are
+// normalised onto its City when they match it or one of its Aliases.
+type Location struct {
+	ID      uuid.UUID `json:"id"`
+	City    string    `json:"city"`
+	Region  *string   `json:"region,omitempty"`
+	Country string    `json:"country"`
+	// Aliases are other spellings of the city, lowercased.
+	Aliases []string `json:"aliases"`
+	// Latitude, Longitude and BoundingBox are found by the geocoder; nil until it located the city.
+	Latitude    *float64     `json:"latitude,omitempty"`
+	Longitude   *float64     `json:"longitude,omitempty"`
+	BoundingBox *BoundingBox `json:"bounding_box,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// BoundingBox is the area of a location, from its south-west to its north-east corner.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
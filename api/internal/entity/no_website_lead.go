@@ -0,0 +1,10 @@
+package entity
+
+// NoWebsiteLead is a company without a website, ranked for web design outreach.
+type NoWebsiteLead struct {
+	Company
+	// HasSocials reports whether enrichment found a social profile to reach the business through.
+	HasSocials bool `json:"has_socials"`
+	// OpportunityScore ranks the lead from 0 to 100 by reviews, rating, phone and socials.
+	OpportunityScore float64 `json:"opportunity_score"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrphanReport describes a reconciliation of the rows left pointing at companies that no longer
+// exist.
+type OrphanReport struct {
+	RunID uuid.UUID `json:"run_id"`
+	// DryRun reports that nothing was changed; the counts say what a run would have done.
+	DryRun     bool                `json:"dry_run"`
+	Tables     []OrphanTableReport `json:"tables"`
+	Relinked   int64               `json:"relinked"`
+	Archived   int64               `json:"archived"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+}
+
+// OrphanTableReport counts the orphaned rows of one table. Relinked rows were moved to the company
+// scraped again under the same place id; archived rows were copied to orphan_archive and deleted.
+type OrphanTableReport struct {
+	Table    string `json:"table"`
+	Orphans  int64  `json:"orphans"`
+	Relinked int64  `json:"relinked"`
+	Archived int64  `json:"archived"`
+}
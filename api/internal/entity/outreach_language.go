@@ -0,0 +1,13 @@
+package entity
+
+import "github.com/google/uuid"
+
+// OutreachLanguageSignals gathers what tells which language a company should be contacted in.
+type OutreachLanguageSignals struct {
+	CompanyID       uuid.UUID
+	WebsiteLanguage *string
+	AboutSummary    *string
+	City            *string
+	Country         *string
+	Language        *string
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job kinds of the admin overview.
+const (
+	JobKindScrape         = "scrape"
+	JobKindEnrichment     = "enrichment"
+	JobKindImport         = "import"
+	JobKindScoreRecompute = "score_recompute"
+	JobKindRawReprocess   = "raw_reprocess"
+)
+
+// JobStatusCounts counts the jobs of one kind created in the overview window by status. Finished
+// counts jobs that completed; enrichment jobs never report failures.
+type JobStatusCounts struct {
+	Kind     string `json:"kind"`
+	Queued   int64  `json:"queued"`
+	Running  int64  `json:"running"`
+	Failed   int64  `json:"failed"`
+	Finished int64  `json:"finished"`
+}
+
+// CityScrape is the latest scrape of a city whose ingest run finished.
+type CityScrape struct {
+	City         string    `json:"city"`
+	Country      string    `json:"country"`
+	TypeBusiness string    `json:"type_business"`
+	JobID        uuid.UUID `json:"job_id"`
+	Items        int       `json:"items"`
+	FinishedAt   time.Time `json:"finished_at"`
+}
+
+// ImportFailure is an import job that failed.
+type ImportFailure struct {
+	ID         uuid.UUID  `json:"id"`
+	Filename   string     `json:"filename"`
+	Error      *string    `json:"error,omitempty"`
+	ErrorCount int        `json:"error_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ImportFailures counts the failed imports of the overview window and lists the latest.
+type ImportFailures struct {
+	Total  int64           `json:"total"`
+	Recent []ImportFailure `json:"recent"`
+}
+
+// TableSize is the disk use of a table with its indexes and an estimate of its rows.
+type TableSize struct {
+	Table         string `json:"table"`
+	Bytes         int64  `json:"bytes"`
+	EstimatedRows int64  `json:"estimated_rows"`
+}
+
+// DatabaseSize is the disk use of the database and of its largest tables.
+type DatabaseSize struct {
+	Bytes  int64       `json:"bytes"`
+	Tables []TableSize `json:"tables"`
+}
+
+// WorkerLatency summarises the recent calls of one worker job type, as this API instance made them.
+type WorkerLatency struct {
+	Job string `json:"job"`
+	// Calls and Errors count every call since the instance started; percentiles cover the latest.
+	Calls   int64   `json:"calls"`
+	Errors  int64   `json:"errors"`
+	Samples int     `json:"samples"`
+	P50MS   float64 `json:"p50_ms"`
+	P90MS   float64 `json:"p90_ms"`
+	P99MS   float64 `json:"p99_ms"`
+}
+
+// AdminOverview gathers the figures of the ops dashboard.
+type AdminOverview struct {
+	WindowHours    int               `json:"window_hours"`
+	Jobs           []JobStatusCounts `json:"jobs"`
+	LastScrapes    []CityScrape      `json:"last_scrapes"`
+	ImportFailures ImportFailures    `json:"import_failures"`
+	WorkerLatency  []WorkerLatency   `json:"worker_latency"`
+	Database       DatabaseSize      `json:"database"`
+	GeneratedAt    time.Time         `json:"generated_at"`
+}
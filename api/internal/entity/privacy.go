@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the privacy audit trail.
+const (
+	PrivacyActionErase  = "erase"
+	PrivacyActionExport = "export"
+)
+
+// PrivacyRequest is an audit trail entry of an erasure or an export. Subjects are the SHA-256
+// hashes of the requested emails so the trail does not keep what was erased.
+type PrivacyRequest struct {
+	ID            uuid.UUID        `json:"id"`
+	Action        string           `json:"action"`
+	SubjectHashes []string         `json:"subject_hashes"`
+	CompanyIDs    []uuid.UUID      `json:"company_ids"`
+	Counts        map[string]int64 `json:"counts"`
+	RequestedBy   *uuid.UUID       `json:"requested_by,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// PrivacyExport is everything held about the requested emails and companies: the stored rows of
+// each table keyed by table name, and the offloaded raw payloads keyed by company id.
+type PrivacyExport struct {
+	Emails      []string                     `json:"emails"`
+	CompanyIDs  []uuid.UUID                  `json:"company_ids"`
+	Records     map[string][]json.RawMessage `json:"records"`
+	RawPayloads map[string]json.RawMessage   `json:"raw_payloads,omitempty"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Prompt alias kinds.
+const (
+	PromptAliasKindCity         = "city"
+	PromptAliasKindBusinessType = "business_type"
+)
+
+// PromptAlias maps a word or phrase found in search prompts onto a canonical city or business type.
+type PromptAlias struct {
+	ID        uuid.UUID `json:"id"`
+	Kind      string    `json:"kind"`
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
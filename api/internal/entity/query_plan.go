@@ -0,0 +1,20 @@
+package entity
+
+import "encoding/json"
+
+// QueryPlan is the plan Postgres chose for a query, as EXPLAIN reports it.
+type QueryPlan struct {
+	// SQL is the statement explained; Args are its positional arguments.
+	SQL  string `json:"sql"`
+	Args []any  `json:"args"`
+	// Analyzed reports that the query was run, so the plan carries actual rows and timings.
+	Analyzed bool `json:"analyzed"`
+	// Indexes are the indexes the plan reads; SeqScans the tables it reads in full.
+	Indexes     []string `json:"indexes"`
+	SeqScans    []string `json:"seq_scans"`
+	TotalCost   float64  `json:"total_cost"`
+	PlanningMS  *float64 `json:"planning_ms,omitempty"`
+	ExecutionMS *float64 `json:"execution_ms,omitempty"`
+	// Plan is the EXPLAIN (FORMAT JSON) output as returned.
+	Plan json.RawMessage `json:"plan"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Raw reprocess job statuses.
+const (
+	RawReprocessQueued    = "queued"
+	RawReprocessRunning   = "running"
+	RawReprocessCompleted = "completed"
+	RawReprocessFailed    = "failed"
+)
+
+// RawReprocessJob tracks a background extraction of place attributes from the stored raw payloads
+// of the companies matching a listing filter. Query is the listing query string the job was
+// created with; Filter is the parsed filter it runs with.
+type RawReprocessJob struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	Query     string     `json:"query"`
+	Filter    []byte     `json:"-"`
+	Status    string     `json:"status"`
+	// Total is the number of companies to reprocess counted when the job started; Processed grows
+	// per batch. Updated counts the companies whose attributes were stored, Skipped those whose
+	// offloaded payload could not be read.
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Updated   int `json:"updated"`
+	Skipped   int `json:"skipped"`
+	// ResumeAfter is the last company reprocessed, where the job continues after a restart.
+	ResumeAfter *uuid.UUID `json:"resume_after,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
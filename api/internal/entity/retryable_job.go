@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RetryableJob is a scrape or enrichment job an operator can send to the worker again once it
+// failed. It carries the request the job posted, so a retry needs none of the original parameters.
+type RetryableJob struct {
+	ID uuid.UUID `json:"id"`
+	// Kind is JobKindScrape or JobKindEnrichment.
+	Kind   string  `json:"kind"`
+	Status string  `json:"status"`
+	Error  *string `json:"error,omitempty"`
+	// Retries counts how often the job was sent to the worker again.
+	Retries     int                  `json:"retries"`
+	RequestedBy *uuid.UUID           `json:"requested_by,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Scrape      *RetryableScrape     `json:"scrape,omitempty"`
+	Enrichment  *RetryableEnrichment `json:"enrichment,omitempty"`
+}
+
+// RetryableScrape is the request of a scrape job.
+type RetryableScrape struct {
+	Source       string  `json:"source"`
+	TypeBusiness string  `json:"type_business"`
+	City         string  `json:"city"`
+	Country      string  `json:"country"`
+	MinRating    float64 `json:"min_rating"`
+}
+
+// RetryableEnrichment is the request of an enrichment job.
+type RetryableEnrichment struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Website   string    `json:"website"`
+	Depth     string    `json:"depth"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Score recompute job statuses.
+const (
+	ScoreRecomputeQueued    = "queued"
+	ScoreRecomputeRunning   = "running"
+	ScoreRecomputeCompleted = "completed"
+	ScoreRecomputeFailed    = "failed"
+)
+
+// ScoreRecomputeJob tracks a background recomputation of the stored lead scores.
+type ScoreRecomputeJob struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	// StaleOnly limits the job to scores missing or computed with another scoring profile revision.
+	StaleOnly bool   `json:"stale_only"`
+	Status    string `json:"status"`
+	// Total is the number of enrichments to score counted when the job started; Processed grows per batch.
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	// ResumeAfter is the last company scored, where the job continues after a restart.
+	ResumeAfter *uuid.UUID `json:"resume_after,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
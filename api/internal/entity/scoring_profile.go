@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScoringProfile is an admin defined set of lead scoring weights. At most one profile is active.
+type ScoringProfile struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Weights is the maximum number of points of each score category.
+	Weights map[string]int `json:"weights"`
+	// Inverted categories score the absence of their signals.
+	Inverted      []string `json:"inverted"`
+	HotThreshold  int      `json:"hot_threshold"`
+	WarmThreshold int      `json:"warm_threshold"`
+	Active        bool     `json:"active"`
+	// Revision grows on every update so scores computed with an older revision can be recomputed.
+	Revision  int       `json:"revision"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
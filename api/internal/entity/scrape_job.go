@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scrape job statuses. Only queued and failed are stored; running and finished are read from the
+// ingest run of the job.
+const (
+	ScrapeJobQueued   = "queued"
+	ScrapeJobRunning  = "running"
+	ScrapeJobFinished = "finished"
+	ScrapeJobFailed   = "failed"
+)
+
+// ScrapeSourceGoogleMaps is the source scrapes run against unless they name another one.
+const ScrapeSourceGoogleMaps = "google_maps"
+
+// ScrapeJob is a scrape sent to the worker, shared by every user who asked for it while it was
+// queued. Its ID is the ingest run the results are streamed to.
+type ScrapeJob struct {
+	ID           uuid.UUID `json:"id"`
+	Source       string    `json:"source"`
+	TypeBusiness string    `json:"type_business"`
+	City         string    `json:"city"`
+	Country      string    `json:"country"`
+	MinRating    float64   `json:"min_rating"`
+	// Fingerprint is the normalised request identical requests share.
+	Fingerprint string  `json:"-"`
+	Status      string  `json:"status"`
+	Error       *string `json:"error,omitempty"`
+	// Requesters counts the users who asked for the scrape.
+	Requesters int `json:"requesters"`
+	// Batches and Items count what the worker has streamed to the job's ingest run so far.
+	Batches    *int       `json:"batches,omitempty"`
+	Items      *int       `json:"items,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
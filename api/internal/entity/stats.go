@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatBucket counts companies sharing a dimension value such as a city or business type.
+type StatBucket struct {
+	Name      string `json:"name"`
+	Companies int64  `json:"companies"`
+}
+
+// ScrapeRunStats summarises the companies collected by one scrape run.
+type ScrapeRunStats struct {
+	ScrapeRunID    uuid.UUID  `json:"scrape_run_id"`
+	Companies      int64      `json:"companies"`
+	FirstScrapedAt *time.Time `json:"first_scraped_at,omitempty"`
+	LastScrapedAt  *time.Time `json:"last_scraped_at,omitempty"`
+}
+
+// CatalogueStats aggregates dashboard totals over the company catalogue.
+type CatalogueStats struct {
+	Companies        int64            `json:"companies"`
+	Enriched         int64            `json:"enriched_companies"`
+	WithoutWebsite   int64            `json:"companies_without_website"`
+	AverageRating    *float64         `json:"average_rating,omitempty"`
+	TopCities        []StatBucket     `json:"top_cities"`
+	TopBusinessTypes []StatBucket     `json:"top_business_types"`
+	ScrapeRuns       []ScrapeRunStats `json:"scrape_runs"`
+	GeneratedAt      time.Time        `json:"generated_at"`
+}
+
+// SegmentFreshness reports how current the companies of one city and business type are.
+type SegmentFreshness struct {
+	City            string     `json:"city"`
+	TypeBusiness    string     `json:"type_business"`
+	Companies       int64      `json:"companies"`
+	LastScrapeRunID *uuid.UUID `json:"last_scrape_run_id,omitempty"`
+	LastScrapedAt   *time.Time `json:"last_scraped_at,omitempty"`
+	// PendingEnrichments counts enrichment jobs of the window still waiting for a result.
+	PendingEnrichments int64 `json:"pending_enrichments"`
+	// AvgEnrichmentLagSeconds is the mean time from an enrichment request to its stored result.
+	AvgEnrichmentLagSeconds *float64 `json:"avg_enrichment_lag_seconds,omitempty"`
+}
+
+// DataFreshness summarises scrape recency, enrichment lag and queue backlog of the catalogue.
+type DataFreshness struct {
+	LastScrapedAt           *time.Time         `json:"last_scraped_at,omitempty"`
+	PendingEnrichments      int64              `json:"pending_enrichments"`
+	OldestPendingAt         *time.Time         `json:"oldest_pending_enrichment_at,omitempty"`
+	AvgEnrichmentLagSeconds *float64           `json:"avg_enrichment_lag_seconds,omitempty"`
+	QueuedImports           int64              `json:"queued_imports"`
+	WindowHours             int                `json:"window_hours"`
+	Segments                []SegmentFreshness `json:"segments"`
+	GeneratedAt             time.Time          `json:"generated_at"`
+}
+
+// Scrape preview recommendations.
+const (
+	ScrapeRecommendFresh   = "fresh"
+	ScrapeRecommendStale   = "stale"
+	ScrapeRecommendMissing = "missing"
+)
+
+// ScrapePreview tells how much of a requested scrape the catalogue already holds. Recommendation
+// is missing when no company matches, fresh when they were scraped recently enough that scraping
+// again would mostly fetch them twice, and stale otherwise.
+type ScrapePreview struct {
+	TypeBusiness    string     `json:"type_business"`
+	City            string     `json:"city"`
+	Country         string     `json:"country"`
+	Companies       int64      `json:"companies"`
+	LastScrapeRunID *uuid.UUID `json:"last_scrape_run_id,omitempty"`
+	LastScrapedAt   *time.Time `json:"last_scraped_at,omitempty"`
+	// FreshUntil is when the companies stop counting as fresh.
+	FreshUntil     *time.Time `json:"fresh_until,omitempty"`
+	Recommendation string     `json:"recommendation"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// TrendingCompany is a company with the growth between its last two scrape observations.
+type TrendingCompany struct {
+	Company
+	PreviousRating     *float64  `json:"previous_rating,omitempty"`
+	PreviousReviews    *int      `json:"previous_reviews,omitempty"`
+	PreviousObservedAt time.Time `json:"previous_observed_at"`
+	ObservedAt         time.Time `json:"observed_at"`
+	// ReviewsDelta and RatingDelta are omitted when either observation lacks the value.
+	ReviewsDelta *int     `json:"reviews_delta,omitempty"`
+	RatingDelta  *float64 `json:"rating_delta,omitempty"`
+}
@@ -15,3 +15,21 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+// UserSummary is the listing projection of a user. It has no password hash, so listings cannot
+// leak one past the repository.
+type UserSummary struct {
+	ID        uuid.UUID
+	Email     string
+	Role      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// UserCursor is a position in the users listing: the value of the sort column, then the user id to
+// break ties. Only the field of the listing's sort column is set.
+type UserCursor struct {
+	CreatedAt time.Time
+	Email     string
+	ID        uuid.UUID
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserInvitation lets the holder of its token create an account with the given role.
+type UserInvitation struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Role  string    `json:"role"`
+	// Org is the agency or team the invitee belongs to, as given by the administrator.
+	Org         *string    `json:"org,omitempty"`
+	InvitedBy   *uuid.UUID `json:"invited_by,omitempty"`
+	ImportJobID *uuid.UUID `json:"import_job_id,omitempty"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
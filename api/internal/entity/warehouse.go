@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WarehouseRow is one denormalised company record (company + enrichment + lead score) in a Parquet export.
+// Optional timestamps use the zero time for NULL because parquet-go only tags value types as timestamps.
+type WarehouseRow struct {
+	ID              string    `parquet:"id"`
+	PlaceID         *string   `parquet:"place_id,optional"`
+	Company         string    `parquet:"company"`
+	Phone           *string   `parquet:"phone,optional"`
+	Website         *string   `parquet:"website,optional"`
+	Rating          *float64  `parquet:"rating,optional"`
+	Reviews         *int64    `parquet:"reviews,optional"`
+	TypeBusiness    *string   `parquet:"type_business,optional"`
+	Address         *string   `parquet:"address,optional"`
+	City            *string   `parquet:"city,optional"`
+	Country         *string   `parquet:"country,optional"`
+	Longitude       *float64  `parquet:"longitude,optional"`
+	Latitude        *float64  `parquet:"latitude,optional"`
+	ScrapeRunID     *string   `parquet:"scrape_run_id,optional"`
+	ScrapedAt       time.Time `parquet:"scraped_at,optional,timestamp(microsecond)"`
+	CreatedAt       time.Time `parquet:"created_at,timestamp(microsecond)"`
+	UpdatedAt       time.Time `parquet:"updated_at,timestamp(microsecond)"`
+	ChainID         *string   `parquet:"chain_id,optional"`
+	SizeBucket      *string   `parquet:"size_bucket,optional"`
+	BusinessStatus  *string   `parquet:"business_status,optional"`
+	Emails          []string  `parquet:"emails,list"`
+	EnrichedPhones  []string  `parquet:"enriched_phones,list"`
+	Socials         *string   `parquet:"socials_json,optional"`
+	ContactFormURL  *string   `parquet:"contact_form_url,optional"`
+	AboutSummary    *string   `parquet:"about_summary,optional"`
+	EnrichedAt      time.Time `parquet:"enriched_at,optional,timestamp(microsecond)"`
+	LeadScore       *int64    `parquet:"lead_score,optional"`
+	ScoreBreakdown  *string   `parquet:"score_breakdown_json,optional"`
+	ScoreComputedAt time.Time `parquet:"score_computed_at,optional,timestamp(microsecond)"`
+}
+
+// WarehouseExport records a completed warehouse partition and the files it contains.
+type WarehouseExport struct {
+	ID            uuid.UUID `json:"id"`
+	PartitionDate time.Time `json:"partition_date"`
+	Location      string    `json:"location"`
+	Files         []string  `json:"files"`
+	RowCount      int64     `json:"row_count"`
+	StartedAt     time.Time `json:"started_at"`
+	CompletedAt   time.Time `json:"completed_at"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Website liveness statuses stored by the website checker.
+const (
+	WebsiteLive       = "live"
+	WebsiteDead       = "dead"
+	WebsiteParked     = "parked"
+	WebsiteRedirected = "redirected"
+)
+
+// CompanyWebsite is the website of a company due for a liveness check.
+type CompanyWebsite struct {
+	CompanyID uuid.UUID
+	Website   string
+}
+
+// WebsiteCheck is the outcome of a website liveness check. HTTPStatus is the status of the last
+// response, nil when none came; RedirectsTo is set for redirected websites.
+type WebsiteCheck struct {
+	Status      string
+	HTTPStatus  *int
+	RedirectsTo *string
+	CheckedAt   time.Time
+}
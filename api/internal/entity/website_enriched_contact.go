@@ -8,17 +8,21 @@ import (
 
 // WebsiteEnrichedContact stores normalized contact details for a company website.
 type WebsiteEnrichedContact struct {
-	ID             uuid.UUID `json:"id"`
-	CompanyID      uuid.UUID `json:"company_id"`
-	Emails         []string  `json:"emails"`
-	Phones         []string  `json:"phones"`
-	LinkedInURL    *string   `json:"linkedin_url,omitempty"`
-	FacebookURL    *string   `json:"facebook_url,omitempty"`
-	InstagramURL   *string   `json:"instagram_url,omitempty"`
-	YouTubeURL     *string   `json:"youtube_url,omitempty"`
-	TikTokURL      *string   `json:"tiktok_url,omitempty"`
-	Address        *string   `json:"address,omitempty"`
-	ContactFormURL *string   `json:"contact_form_url,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID           uuid.UUID `json:"id"`
+	CompanyID    uuid.UUID `json:"company_id"`
+	Emails       []string  `json:"emails"`
+	Phones       []string  `json:"phones"`
+	LinkedInURL  *string   `json:"linkedin_url,omitempty"`
+	FacebookURL  *string   `json:"facebook_url,omitempty"`
+	InstagramURL *string   `json:"instagram_url,omitempty"`
+	YouTubeURL   *string   `json:"youtube_url,omitempty"`
+	TikTokURL    *string   `json:"tiktok_url,omitempty"`
+	TwitterURL   *string   `json:"twitter_url,omitempty"`
+	WhatsAppURL  *string   `json:"whatsapp_url,omitempty"`
+	// SocialHandles maps each platform to the profile handle of its stored URL.
+	SocialHandles  map[string]string `json:"social_handles,omitempty"`
+	Address        *string           `json:"address,omitempty"`
+	ContactFormURL *string           `json:"contact_form_url,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
 }
@@ -0,0 +1,74 @@
+// Package errreport reports server errors, with their stack and request context, to an error
+// tracker such as Sentry.
+package errreport
+
+import (
+	"context"
+	"math/rand/v2"
+	"runtime"
+)
+
+// Event is a server error worth reporting.
+type Event struct {
+	// Err is the cause, when known; Message describes the failure otherwise.
+	Err     error
+	Message string
+	// Stack holds the frames where the error was raised, innermost first.
+	Stack []runtime.Frame
+	// Panic is set when the error was recovered from a panic.
+	Panic bool
+
+	RequestID string
+	UserID    string
+	Method    string
+	Route     string
+	Status    int
+}
+
+// Reporter sends events to an error tracker. Report must not block the request for long.
+type Reporter interface {
+	Report(ctx context.Context, event Event)
+}
+
+// Nop discards every event; it is used when no tracker is configured.
+type Nop struct{}
+
+// Report implements Reporter.
+func (Nop) Report(context.Context, Event) {}
+
+// Sample reports a share rate, between 0 and 1, of the events through r.
+func Sample(r Reporter, rate float64) Reporter {
+	switch {
+	case rate <= 0:
+		return Nop{}
+	case rate >= 1:
+		return r
+	}
+	return sampler{reporter: r, rate: rate}
+}
+
+type sampler struct {
+	reporter Reporter
+	rate     float64
+}
+
+func (s sampler) Report(ctx context.Context, event Event) {
+	if rand.Float64() < s.rate {
+		s.reporter.Report(ctx, event)
+	}
+}
+
+// Callers returns the stack of its caller, less skip more frames, innermost first.
+func Callers(skip int) []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			return stack
+		}
+	}
+}
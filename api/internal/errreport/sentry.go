@@ -0,0 +1,232 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// appModule marks the frames of this API as in-app in reported stacks.
+const appModule = "github.com/octobees/leads-generator/api/"
+
+// maxPendingReports bounds the events being sent at once; further events are dropped rather
+// than queued behind a slow tracker.
+const maxPendingReports = 8
+
+// SentryReporter sends events to Sentry through its envelope endpoint. Events are sent in the
+// background, so Report returns at once.
+type SentryReporter struct {
+	dsn         string
+	endpoint    string
+	publicKey   string
+	environment string
+	client      *http.Client
+
+	pending chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewSentryReporter builds a reporter for the project of dsn, tagging events with environment; a
+// nil client uses one with a timeout.
+func NewSentryReporter(dsn, environment string, client *http.Client) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid sentry dsn")
+	}
+	path := strings.Trim(u.Path, "/")
+	projectID := path[strings.LastIndex(path, "/")+1:]
+	if _, err := strconv.Atoi(projectID); err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn: missing project id")
+	}
+	prefix := strings.TrimSuffix(path, projectID)
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/" + prefix + "api/" + projectID + "/envelope/"}
+	return &SentryReporter{
+		dsn:         dsn,
+		endpoint:    endpoint.String(),
+		publicKey:   u.User.Username(),
+		environment: environment,
+		client:      client,
+		pending:     make(chan struct{}, maxPendingReports),
+	}, nil
+}
+
+// Report implements Reporter.
+func (s *SentryReporter) Report(ctx context.Context, event Event) {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+		log.Printf("request_id=%s error report dropped: too many reports in flight", event.RequestID)
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.pending }()
+		// The request is over by the time the report is sent, so it gets a context of its own.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := s.send(ctx, event); err != nil {
+			log.Printf("request_id=%s failed to report error: %v", event.RequestID, err)
+		}
+	}()
+}
+
+// Flush waits for the events being sent, or for ctx to end.
+func (s *SentryReporter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type sentryFrame struct {
+	Function string `json:"function,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryMechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+	Mechanism  *sentryMechanism  `json:"mechanism,omitempty"`
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        map[string]string `json:"user,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+}
+
+// sentryEventFor converts event to the Sentry event schema.
+func (s *SentryReporter) sentryEventFor(event Event, id string, now time.Time) sentryEvent {
+	out := sentryEvent{
+		EventID:     id,
+		Timestamp:   now.UTC().Format(time.RFC3339Nano),
+		Level:       "error",
+		Platform:    "go",
+		Environment: s.environment,
+		Tags:        map[string]string{"request_id": event.RequestID, "status": strconv.Itoa(event.Status)},
+	}
+	if event.Route != "" {
+		out.Transaction = event.Method + " " + event.Route
+		out.Tags["route"] = event.Route
+	}
+	if event.UserID != "" {
+		out.User = map[string]string{"id": event.UserID}
+	}
+
+	exception := sentryException{Type: "error", Value: event.Message}
+	if event.Err != nil {
+		exception.Type = fmt.Sprintf("%T", event.Err)
+		exception.Value = event.Err.Error()
+		out.Message = event.Message
+	}
+	if event.Panic {
+		exception.Mechanism = &sentryMechanism{Type: "panic", Handled: false}
+	}
+	if len(event.Stack) > 0 {
+		exception.Stacktrace = &sentryStacktrace{Frames: sentryFrames(event.Stack)}
+	}
+	out.Exception.Values = []sentryException{exception}
+	return out
+}
+
+// sentryFrames lists stack frames outermost first, as Sentry expects.
+func sentryFrames(stack []runtime.Frame) []sentryFrame {
+	frames := make([]sentryFrame, 0, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		frame := stack[i]
+		frames = append(frames, sentryFrame{
+			Function: frame.Function,
+			Filename: frame.File[strings.LastIndex(frame.File, "/")+1:],
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, appModule),
+		})
+	}
+	return frames
+}
+
+func (s *SentryReporter) send(ctx context.Context, event Event) error {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return fmt.Errorf("generate event id: %w", err)
+	}
+	id := hex.EncodeToString(raw[:])
+	now := time.Now()
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, part := range []any{
+		map[string]string{"event_id": id, "sent_at": now.UTC().Format(time.RFC3339Nano), "dsn": s.dsn},
+		map[string]string{"type": "event"},
+		s.sentryEventFor(event, id, now),
+	} {
+		if err := enc.Encode(part); err != nil {
+			return fmt.Errorf("encode sentry envelope: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("build sentry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=leads-generator-api/1.0, sentry_key="+s.publicKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send sentry event: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sentry: unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+var _ Reporter = (*SentryReporter)(nil)
@@ -0,0 +1,95 @@
+package errreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingReporter struct{ events []Event }
+
+func (r *recordingReporter) Report(ctx context.Context, event Event) {
+	r.events = append(r.events, event)
+}
+
+func TestSentryReporter_Report(t *testing.T) {
+	var path, auth string
+	var lines []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public-key@", 1) + "/sentry/42"
+	reporter, err := NewSentryReporter(dsn, "staging", server.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reporter.Report(context.Background(), Event{
+		Err:       errors.New("boom"),
+		Message:   "failed to list companies",
+		Stack:     Callers(0),
+		RequestID: "rid-1",
+		UserID:    "user-1",
+		Method:    http.MethodGet,
+		Route:     "/companies",
+		Status:    http.StatusInternalServerError,
+	})
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if path != "/sentry/api/42/envelope/" || !strings.Contains(auth, "sentry_key=public-key") {
+		t.Fatalf("unexpected request to %s with auth %q", path, auth)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected an envelope of three lines, got %q", lines)
+	}
+	var event sentryEvent
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	exception := event.Exception.Values[0]
+	if event.Environment != "staging" || event.Transaction != "GET /companies" || event.Tags["request_id"] != "rid-1" ||
+		event.User["id"] != "user-1" || event.Message != "failed to list companies" || exception.Value != "boom" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	frames := exception.Stacktrace.Frames
+	if last := frames[len(frames)-1]; !strings.HasSuffix(last.Function, "TestSentryReporter_Report") || !last.InApp {
+		t.Fatalf("expected the innermost frame last, got %+v", last)
+	}
+}
+
+func TestNewSentryReporter_InvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"https://sentry.example.com/42", "https://key@sentry.example.com/", "::"} {
+		if _, err := NewSentryReporter(dsn, "", nil); err == nil {
+			t.Fatalf("expected %q to be rejected", dsn)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	recorder := &recordingReporter{}
+	if _, ok := Sample(recorder, 0).(Nop); !ok {
+		t.Fatalf("expected a zero rate to drop every event")
+	}
+	if Sample(recorder, 1) != Reporter(recorder) {
+		t.Fatalf("expected a full rate to keep the reporter")
+	}
+	sampled := Sample(recorder, 0.5)
+	for range 1000 {
+		sampled.Report(context.Background(), Event{})
+	}
+	if n := len(recorder.events); n < 350 || n > 650 {
+		t.Fatalf("expected about half the events, got %d", n)
+	}
+}
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultBufferSize is the number of events a subscription holds before new ones are dropped.
+const DefaultBufferSize = 64
+
+// Publisher is what services publish events through.
+type Publisher interface {
+	Publish(ctx context.Context, payload Payload)
+}
+
+// Discard is a Publisher dropping every event, the default of services without a bus.
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, Payload) {}
+
+// Remote carries events between the buses of several replicas.
+type Remote interface {
+	// Send hands an encoded event to the other replicas.
+	Send(ctx context.Context, data []byte) error
+	// Receive calls deliver with every encoded event sent, its own included, until ctx is done.
+	Receive(ctx context.Context, deliver func(data []byte)) error
+}
+
+// Bus fans published events out to its subscriptions. Publishing never blocks: a subscription
+// whose buffer is full misses the event, so subscribers treat events as hints to re-read state
+// rather than as a log.
+type Bus struct {
+	mu      sync.RWMutex
+	subs    map[*Subscription]struct{}
+	buffer  int
+	remote  Remote
+	origin  string
+	now     func() time.Time
+	dropped atomic.Int64
+}
+
+// Option customises a Bus.
+type Option func(*Bus)
+
+// WithBufferSize sets the number of events each subscription buffers.
+func WithBufferSize(size int) Option {
+	return func(b *Bus) {
+		if size > 0 {
+			b.buffer = size
+		}
+	}
+}
+
+// WithRemote also sends published events through remote, and delivers the events of other
+// replicas once Run is started.
+func WithRemote(remote Remote) Option {
+	return func(b *Bus) {
+		b.remote = remote
+	}
+}
+
+// NewBus builds an event bus.
+func NewBus(opts ...Option) *Bus {
+	b := &Bus{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: DefaultBufferSize,
+		origin: uuid.NewString(),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Publish delivers payload to the matching subscriptions and sends it to the remote, if any.
+// Remote failures are logged: the local subscribers already have the event.
+func (b *Bus) Publish(ctx context.Context, payload Payload) {
+	event := Event{Type: payload.EventType(), At: b.now().UTC(), Payload: payload}
+	b.deliver(event)
+	if b.remote == nil {
+		return
+	}
+	data, err := encodeMessage(b.origin, event)
+	if err == nil {
+		err = b.remote.Send(context.WithoutCancel(ctx), data)
+	}
+	if err != nil {
+		log.Printf("events: send %s to remote: %v", event.Type, err)
+	}
+}
+
+// Run delivers the events other replicas send through the remote until ctx is done. Without a
+// remote it returns at once.
+func (b *Bus) Run(ctx context.Context) error {
+	if b.remote == nil {
+		return nil
+	}
+	return b.remote.Receive(ctx, func(data []byte) {
+		origin, event, err := decodeMessage(data)
+		if err != nil {
+			log.Printf("events: %v", err)
+			return
+		}
+		if origin != b.origin {
+			b.deliver(event)
+		}
+	})
+}
+
+// Subscribe returns a subscription to the events of types, or to every event without types. The
+// subscription must be closed once the subscriber is done.
+func (b *Bus) Subscribe(types ...string) *Subscription {
+	c := make(chan Event, b.buffer)
+	sub := &Subscription{C: c, c: c, bus: b}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Dropped returns the number of events subscriptions missed for being full.
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+func (b *Bus) deliver(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.c <- event:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Subscription receives the events of a Bus on C until it is closed.
+type Subscription struct {
+	// C receives the events; it is closed by Close.
+	C     <-chan Event
+	c     chan Event
+	types map[string]bool
+	bus   *Bus
+	once  sync.Once
+}
+
+// Close ends the subscription and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.c)
+	})
+}
+
+var _ Publisher = (*Bus)(nil)
@@ -0,0 +1,115 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryRemote hands sent events to every bus receiving from it, like a pub/sub channel.
+type memoryRemote struct {
+	messages chan []byte
+}
+
+func (r *memoryRemote) Send(_ context.Context, data []byte) error {
+	r.messages <- data
+	return nil
+}
+
+func (r *memoryRemote) Receive(ctx context.Context, deliver func(data []byte)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case data := <-r.messages:
+			deliver(data)
+		}
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event := <-sub.C:
+		return event
+	case <-time.After(time.Second):
+		t.Fatalf("expected an event")
+		return Event{}
+	}
+}
+
+func TestBus_SubscribeFiltersTypes(t *testing.T) {
+	bus := NewBus()
+	all := bus.Subscribe()
+	defer all.Close()
+	runs := bus.Subscribe(TypeIngestRunFinished)
+	defer runs.Close()
+
+	runID := uuid.New()
+	bus.Publish(context.Background(), EnrichmentSaved{CompanyID: uuid.New()})
+	bus.Publish(context.Background(), IngestRunFinished{RunID: runID, Batches: 3})
+
+	if event := receive(t, all); event.Type != TypeEnrichmentSaved {
+		t.Fatalf("expected the enrichment event first, got %+v", event)
+	}
+	receive(t, all)
+	event := receive(t, runs)
+	finished, ok := event.Payload.(IngestRunFinished)
+	if !ok || finished.RunID != runID || event.At.IsZero() {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	select {
+	case event := <-runs.C:
+		t.Fatalf("expected no other event, got %+v", event)
+	default:
+	}
+}
+
+func TestBus_DropsEventsForFullSubscriptions(t *testing.T) {
+	bus := NewBus(WithBufferSize(1))
+	sub := bus.Subscribe()
+	bus.Publish(context.Background(), UserChanged{UserID: uuid.New(), Change: UserCreated})
+	bus.Publish(context.Background(), UserChanged{UserID: uuid.New(), Change: UserDeleted})
+
+	if got := bus.Dropped(); got != 1 {
+		t.Fatalf("expected 1 dropped event, got %d", got)
+	}
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; !ok {
+		t.Fatalf("expected the buffered event before the channel closes")
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatalf("expected the channel closed")
+	}
+	bus.Publish(context.Background(), UserChanged{UserID: uuid.New(), Change: UserUpdated})
+}
+
+func TestBus_RemoteDeliversOtherReplicas(t *testing.T) {
+	remote := &memoryRemote{messages: make(chan []byte, 4)}
+	sender := NewBus(WithRemote(remote))
+	receiver := NewBus(WithRemote(remote))
+	sub := receiver.Subscribe(TypeCompaniesUpserted)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go receiver.Run(ctx)
+
+	sender.Publish(context.Background(), CompaniesUpserted{Source: SourceCSV, Companies: 3, Inserted: 2, Updated: 1})
+	upserted, ok := receive(t, sub).Payload.(CompaniesUpserted)
+	if !ok || upserted != (CompaniesUpserted{Source: SourceCSV, Companies: 3, Inserted: 2, Updated: 1}) {
+		t.Fatalf("unexpected payload %+v", upserted)
+	}
+
+	// The receiver's own events reach its subscribers once, not again through the remote.
+	receiver.Publish(context.Background(), CompaniesUpserted{Source: SourceAPI, Companies: 1})
+	receive(t, sub)
+	select {
+	case event := <-sub.C:
+		t.Fatalf("expected no echo of the replica's own event, got %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
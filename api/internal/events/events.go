@@ -0,0 +1,165 @@
+// Package events is the in-process notification hub of the API. Services publish typed events
+// when data changes, such as a company upsert or a finished ingest run, and subscribers like the
+// scrape event streams react to them instead of waiting for their next poll. With a Remote the
+// events also reach the other replicas.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types, as Payload.EventType reports them.
+const (
+	TypeCompaniesUpserted = "companies.upserted"
+	TypeEnrichmentSaved   = "enrichment.saved"
+	TypeIngestBatchStored = "ingest_run.batch_stored"
+	TypeIngestRunFinished = "ingest_run.finished"
+	TypeUserChanged       = "user.changed"
+)
+
+// User changes, as UserChanged.Change reports them.
+const (
+	UserCreated       = "created"
+	UserUpdated       = "updated"
+	UserDeleted       = "deleted"
+	UserPasswordReset = "password_reset"
+)
+
+// Payload is the typed body of an event.
+type Payload interface {
+	EventType() string
+}
+
+// Event is a published payload with the time it was published at.
+type Event struct {
+	Type    string
+	At      time.Time
+	Payload Payload
+}
+
+// Sources of upserted companies, as CompaniesUpserted.Source reports them.
+const (
+	SourceAPI    = "api"
+	SourceCSV    = "csv"
+	SourceIngest = "ingest"
+	// SourceBatch is a batch pushed by a data provider through POST /companies/batch.
+	SourceBatch = "batch"
+	// SourceRefresh is a company looked up again through POST /admin/companies/:id/refresh.
+	SourceRefresh = "refresh"
+)
+
+// CompaniesUpserted reports companies inserted or updated by the same write. Inserted and Updated
+// are only known for CSV imports and batches; the other sources report Companies alone.
+type CompaniesUpserted struct {
+	Source    string `json:"source"`
+	Companies int    `json:"companies"`
+	Inserted  int    `json:"inserted,omitempty"`
+	Updated   int    `json:"updated,omitempty"`
+}
+
+// EventType implements Payload.
+func (CompaniesUpserted) EventType() string { return TypeCompaniesUpserted }
+
+// EnrichmentSaved reports a stored enrichment result.
+type EnrichmentSaved struct {
+	CompanyID uuid.UUID `json:"company_id"`
+}
+
+// EventType implements Payload.
+func (EnrichmentSaved) EventType() string { return TypeEnrichmentSaved }
+
+// IngestBatchStored reports a batch applied to an ingest run; resent duplicates are not reported.
+type IngestBatchStored struct {
+	RunID    uuid.UUID `json:"run_id"`
+	Sequence int       `json:"sequence"`
+	Items    int       `json:"items"`
+}
+
+// EventType implements Payload.
+func (IngestBatchStored) EventType() string { return TypeIngestBatchStored }
+
+// IngestRunFinished reports an ingest run closed with all of its batches.
+type IngestRunFinished struct {
+	RunID   uuid.UUID `json:"run_id"`
+	Batches int       `json:"batches"`
+}
+
+// EventType implements Payload.
+func (IngestRunFinished) EventType() string { return TypeIngestRunFinished }
+
+// UserChanged reports a user created, updated, deleted or given a new password by an admin.
+type UserChanged struct {
+	UserID uuid.UUID `json:"user_id"`
+	Change string    `json:"change"`
+}
+
+// EventType implements Payload.
+func (UserChanged) EventType() string { return TypeUserChanged }
+
+// payloadTypes builds an empty payload of each type, for decoding events of other replicas.
+var payloadTypes = map[string]func() Payload{
+	TypeCompaniesUpserted: func() Payload { return &CompaniesUpserted{} },
+	TypeEnrichmentSaved:   func() Payload { return &EnrichmentSaved{} },
+	TypeIngestBatchStored: func() Payload { return &IngestBatchStored{} },
+	TypeIngestRunFinished: func() Payload { return &IngestRunFinished{} },
+	TypeUserChanged:       func() Payload { return &UserChanged{} },
+}
+
+// message is the wire form of an event sent to a Remote.
+type message struct {
+	Origin  string          `json:"origin"`
+	Type    string          `json:"type"`
+	At      time.Time       `json:"at"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func encodeMessage(origin string, event Event) ([]byte, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode event payload: %w", err)
+	}
+	encoded, err := json.Marshal(message{Origin: origin, Type: event.Type, At: event.At, Payload: payload})
+	if err != nil {
+		return nil, fmt.Errorf("encode event: %w", err)
+	}
+	return encoded, nil
+}
+
+// decodeMessage reads a message back into an event with a payload of the same value type it was
+// published with.
+func decodeMessage(data []byte) (string, Event, error) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return "", Event{}, fmt.Errorf("decode event: %w", err)
+	}
+	newPayload, ok := payloadTypes[msg.Type]
+	if !ok {
+		return "", Event{}, fmt.Errorf("decode event: unknown type %q", msg.Type)
+	}
+	payload := newPayload()
+	if err := json.Unmarshal(msg.Payload, payload); err != nil {
+		return "", Event{}, fmt.Errorf("decode %s payload: %w", msg.Type, err)
+	}
+	return msg.Origin, Event{Type: msg.Type, At: msg.At, Payload: dereference(payload)}, nil
+}
+
+func dereference(payload Payload) Payload {
+	switch p := payload.(type) {
+	case *CompaniesUpserted:
+		return *p
+	case *EnrichmentSaved:
+		return *p
+	case *IngestBatchStored:
+		return *p
+	case *IngestRunFinished:
+		return *p
+	case *UserChanged:
+		return *p
+	default:
+		return payload
+	}
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRemote carries events between replicas over a Redis pub/sub channel. Pub/sub does not
+// keep messages, so a replica only receives the events sent while it is subscribed.
+type RedisRemote struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisRemote builds a remote publishing to and subscribing on channel.
+func NewRedisRemote(client *redis.Client, channel string) *RedisRemote {
+	return &RedisRemote{client: client, channel: channel}
+}
+
+// Send publishes data on the channel.
+func (r *RedisRemote) Send(ctx context.Context, data []byte) error {
+	if err := r.client.Publish(ctx, r.channel, data).Err(); err != nil {
+		return fmt.Errorf("publish event: %w", err)
+	}
+	return nil
+}
+
+// Receive subscribes to the channel and calls deliver with each message until ctx is done. The
+// client reconnects on its own after connection failures.
+func (r *RedisRemote) Receive(ctx context.Context, deliver func(data []byte)) error {
+	sub := r.client.Subscribe(ctx, r.channel)
+	defer sub.Close()
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			deliver([]byte(msg.Payload))
+		}
+	}
+}
+
+var _ Remote = (*RedisRemote)(nil)
@@ -0,0 +1,196 @@
+// Package fieldcrypt encrypts single column values, such as the emails and phone numbers of an
+// enrichment, with AES-256-GCM so they are unreadable in the database, its backups and its dumps.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// prefix marks encrypted values; it is followed by the key id, a colon and the base64 encoded
+// nonce and ciphertext. Values without it are plaintext written before encryption was enabled.
+const prefix = "enc:v1:"
+
+// LikePattern matches encrypted values in SQL LIKE expressions.
+const LikePattern = prefix + "%"
+
+// tokenPrefix marks search tokens; it is followed by the key id, a colon and the base64 encoded
+// keyed hash.
+const tokenPrefix = "tok:v1:"
+
+// tokenContext derives the keys of search tokens from the encryption keys, so no key is used for
+// both.
+const tokenContext = "fieldcrypt search token"
+
+// KeySize is the length of an AES-256 key in bytes.
+const KeySize = 32
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted with a key the cipher does not hold.
+	ErrUnknownKey = errors.New("value encrypted with an unknown key")
+	// ErrNoCipher is returned when an encrypted value is read without a cipher.
+	ErrNoCipher = errors.New("value is encrypted but no encryption key is configured")
+)
+
+// Cipher encrypts values with its active key and decrypts values written with the active key or
+// one of the previous keys, so keys can be rotated without rewriting every row at once. A nil
+// Cipher stores values as they are.
+type Cipher struct {
+	activeID  string
+	keys      map[string]cipher.AEAD
+	tokenKeys map[string][]byte
+}
+
+// New builds a cipher encrypting with active and also decrypting with previous keys.
+func New(active []byte, previous ...[]byte) (*Cipher, error) {
+	c := &Cipher{keys: make(map[string]cipher.AEAD, 1+len(previous)), tokenKeys: make(map[string][]byte, 1+len(previous))}
+	for i, key := range append([][]byte{active}, previous...) {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := keyID(key)
+		if i == 0 {
+			c.activeID = id
+		}
+		c.keys[id] = aead
+		derive := hmac.New(sha256.New, key)
+		derive.Write([]byte(tokenContext))
+		c.tokenKeys[id] = derive.Sum(nil)
+	}
+	return c, nil
+}
+
+// keyID names a key in the values it encrypted without revealing it.
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// IsEncrypted reports whether value was written by a Cipher.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt seals value with the active key. Empty values are kept empty.
+func (c *Cipher) Encrypt(value string) (string, error) {
+	if c == nil || value == "" || IsEncrypted(value) {
+		return value, nil
+	}
+	aead := c.keys[c.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return prefix + c.activeID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value written by Encrypt; plaintext values are returned as they are.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", ErrNoCipher
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, found := c.keys[id]
+	if !found {
+		return "", fmt.Errorf("%w %s", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Current reports whether value is stored the way Encrypt would store it now: encrypted with the
+// active key, or plaintext when there is no cipher.
+func (c *Cipher) Current(value string) bool {
+	if c == nil || value == "" {
+		return !IsEncrypted(value)
+	}
+	return strings.HasPrefix(value, prefix+c.activeID+":")
+}
+
+// EncryptAll encrypts every value of values into a new slice.
+func (c *Cipher) EncryptAll(values []string) ([]string, error) {
+	return c.mapAll(values, c.Encrypt)
+}
+
+// DecryptAll decrypts every value of values into a new slice.
+func (c *Cipher) DecryptAll(values []string) ([]string, error) {
+	return c.mapAll(values, c.Decrypt)
+}
+
+func (c *Cipher) mapAll(values []string, fn func(string) (string, error)) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	out := make([]string, len(values))
+	for i, value := range values {
+		converted, err := fn(value)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = converted
+	}
+	return out, nil
+}
+
+// Token returns the search token of value under the active key: a keyed hash equal for equal
+// values, so encrypted values can be looked up without being readable. A nil Cipher returns value
+// itself.
+func (c *Cipher) Token(value string) string {
+	if c == nil {
+		return value
+	}
+	return c.token(c.activeID, value)
+}
+
+// Tokens returns the search tokens of value under every key, the active one first, to match
+// values tokenised before a key rotation.
+func (c *Cipher) Tokens(value string) []string {
+	if c == nil {
+		return []string{value}
+	}
+	tokens := []string{c.token(c.activeID, value)}
+	for _, id := range slices.Sorted(maps.Keys(c.tokenKeys)) {
+		if id != c.activeID {
+			tokens = append(tokens, c.token(id, value))
+		}
+	}
+	return tokens
+}
+
+func (c *Cipher) token(id, value string) string {
+	mac := hmac.New(sha256.New, c.tokenKeys[id])
+	mac.Write([]byte(value))
+	return tokenPrefix + id + ":" + base64.RawStdEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
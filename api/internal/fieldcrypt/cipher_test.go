@@ -0,0 +1,74 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	first, _ := c.Encrypt("info@example.com")
+	second, _ := c.Encrypt("info@example.com")
+	if !IsEncrypted(first) || first == second || strings.Contains(first, "example") {
+		t.Fatalf("expected distinct opaque ciphertexts, got %q and %q", first, second)
+	}
+	if plain, err := c.Decrypt(first); err != nil || plain != "info@example.com" {
+		t.Fatalf("expected the value back, got %q (%v)", plain, err)
+	}
+	if plain, err := c.Decrypt("+62 21 555"); err != nil || plain != "+62 21 555" {
+		t.Fatalf("expected plaintext to pass through, got %q (%v)", plain, err)
+	}
+	tampered := []byte(first)
+	tampered[len(tampered)-10] ^= 1
+	if _, err := c.Decrypt(string(tampered)); err == nil {
+		t.Fatal("expected a tampered value to be refused")
+	}
+	if _, err := (*Cipher)(nil).Decrypt(first); !errors.Is(err, ErrNoCipher) {
+		t.Fatalf("expected ErrNoCipher, got %v", err)
+	}
+	if _, err := New([]byte("short")); err == nil {
+		t.Fatal("expected a short key to be refused")
+	}
+}
+
+func TestCipher_Rotation(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, KeySize), bytes.Repeat([]byte{2}, KeySize)
+	old, _ := New(oldKey)
+	stored, _ := old.Encrypt("info@example.com")
+
+	rotated, _ := New(newKey, oldKey)
+	if plain, err := rotated.Decrypt(stored); err != nil || plain != "info@example.com" {
+		t.Fatalf("expected a previous key to decrypt, got %q (%v)", plain, err)
+	}
+	if rotated.Current(stored) || rotated.Current("plain") || !old.Current(stored) {
+		t.Fatal("expected only values of the active key to be current")
+	}
+
+	withoutOld, _ := New(newKey)
+	if _, err := withoutOld.Decrypt(stored); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestCipher_Tokens(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, KeySize), bytes.Repeat([]byte{2}, KeySize)
+	old, _ := New(oldKey)
+	rotated, _ := New(newKey, oldKey)
+
+	token := old.Token("email:info@example.com")
+	if token != old.Token("email:info@example.com") || strings.Contains(token, "example") || token == old.Token("email:sales@example.com") {
+		t.Fatalf("expected a stable opaque token, got %q", token)
+	}
+	tokens := rotated.Tokens("email:info@example.com")
+	if len(tokens) != 2 || tokens[0] != rotated.Token("email:info@example.com") || tokens[1] != token {
+		t.Fatalf("expected the active token then the previous one, got %v", tokens)
+	}
+	if got := (*Cipher)(nil).Tokens("phone:62215550100"); len(got) != 1 || got[0] != "phone:62215550100" {
+		t.Fatalf("expected plaintext tokens without a cipher, got %v", got)
+	}
+}
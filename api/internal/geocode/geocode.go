@@ -0,0 +1,111 @@
+// Package geocode locates cities through a geocoder speaking the Nominatim search API
+// (OpenStreetMap Nominatim, or a hosted one such as LocationIQ).
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound is returned when the geocoder knows no place by the given name.
+var ErrNotFound = errors.New("location not found")
+
+// Result is where a geocoded city lies: its centre and the box around it.
+type Result struct {
+	Latitude  float64
+	Longitude float64
+	South     float64
+	West      float64
+	North     float64
+	East      float64
+}
+
+// Geocoder locates a city of a country.
+type Geocoder interface {
+	Geocode(ctx context.Context, city, country string) (*Result, error)
+}
+
+// NominatimGeocoder queries the /search endpoint of a Nominatim compatible geocoder.
+type NominatimGeocoder struct {
+	endpoint  string
+	userAgent string
+	client    *http.Client
+}
+
+// NewNominatimGeocoder builds a geocoder calling endpoint, identified by userAgent as the Nominatim
+// usage policy requires; a nil client uses a client with a short timeout.
+func NewNominatimGeocoder(endpoint, userAgent string, client *http.Client) *NominatimGeocoder {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &NominatimGeocoder{endpoint: endpoint, userAgent: userAgent, client: client}
+}
+
+// nominatimPlace is a search result; Nominatim encodes coordinates as strings and the bounding box
+// as south, north, west, east.
+type nominatimPlace struct {
+	Lat         string   `json:"lat"`
+	Lon         string   `json:"lon"`
+	BoundingBox []string `json:"boundingbox"`
+}
+
+// Geocode returns the best match for city in country, or ErrNotFound when there is none.
+func (g *NominatimGeocoder) Geocode(ctx context.Context, city, country string) (*Result, error) {
+	query := url.Values{"city": {city}, "format": {"json"}, "limit": {"1"}}
+	if country != "" {
+		query.Set("country", country)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build geocode request: %w", err)
+	}
+	req.Header.Set("User-Agent", g.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("geocode %s: %w", city, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocode %s: unexpected status %d", city, resp.StatusCode)
+	}
+
+	var places []nominatimPlace
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return nil, fmt.Errorf("decode geocode response: %w", err)
+	}
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+	return places[0].result()
+}
+
+func (p nominatimPlace) result() (*Result, error) {
+	if len(p.BoundingBox) != 4 {
+		return nil, fmt.Errorf("decode geocode response: bounding box has %d values", len(p.BoundingBox))
+	}
+	values := append([]string{p.Lat, p.Lon}, p.BoundingBox...)
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("decode geocode response: %w", err)
+		}
+		parsed[i] = number
+	}
+	return &Result{
+		Latitude:  parsed[0],
+		Longitude: parsed[1],
+		South:     parsed[2],
+		North:     parsed[3],
+		West:      parsed[4],
+		East:      parsed[5],
+	}, nil
+}
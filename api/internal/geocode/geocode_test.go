@@ -0,0 +1,49 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNominatimGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "leads-test" || r.URL.Query().Get("country") != "Indonesia" {
+			t.Fatalf("unexpected request %s with agent %q", r.URL, r.Header.Get("User-Agent"))
+		}
+		if r.URL.Query().Get("city") != "Yogyakarta" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"lat":"-7.8013","lon":"110.3647","boundingbox":["-7.8375","-7.7590","110.3391","110.4021"]}]`))
+	}))
+	defer server.Close()
+
+	geocoder := NewNominatimGeocoder(server.URL, "leads-test", server.Client())
+	result, err := geocoder.Geocode(context.Background(), "Yogyakarta", "Indonesia")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Result{Latitude: -7.8013, Longitude: 110.3647, South: -7.8375, West: 110.3391, North: -7.7590, East: 110.4021}
+	if *result != want {
+		t.Fatalf("expected %+v, got %+v", want, *result)
+	}
+
+	if _, err := geocoder.Geocode(context.Background(), "Atlantis", "Indonesia"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestNominatimGeocoder_ProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewNominatimGeocoder(server.URL, "leads-test", server.Client()).Geocode(context.Background(), "Bandung", "Indonesia")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected provider error distinct from not found, got %v", err)
+	}
+}
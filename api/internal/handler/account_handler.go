@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// AccountHandler lets signed-in users manage their own account.
+type AccountHandler struct {
+	accounts *service.AccountService
+}
+
+// NewAccountHandler constructs a handler instance.
+func NewAccountHandler(accounts *service.AccountService) *AccountHandler {
+	return &AccountHandler{accounts: accounts}
+}
+
+// Get handles GET /me requests.
+func (h *AccountHandler) Get(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	profile, err := h.accounts.Profile(c.Request().Context(), userID)
+	if err != nil {
+		return accountError(c, err, "failed to load profile")
+	}
+	return Success(c, http.StatusOK, "profile retrieved", profile)
+}
+
+// Update handles PATCH /me requests. A new password applies at once and signs out the user's other
+// sessions; a new email applies once confirmed through POST /auth/email/verify.
+func (h *AccountHandler) Update(c echo.Context) error {
+	var req dto.UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	sessionID, _ := c.Get(middlewarepkg.ContextKeySessionID).(string)
+
+	profile, err := h.accounts.UpdateProfile(c.Request().Context(), userID, sessionID, req)
+	if err != nil {
+		return accountError(c, err, "failed to update profile")
+	}
+	message := "profile updated"
+	if profile.PendingEmailChange != nil && req.Email != nil {
+		message = "profile updated; confirm the new email address from the message sent to it"
+	}
+	return Success(c, http.StatusOK, message, profile)
+}
+
+// VerifyEmail handles POST /auth/email/verify requests, confirming a pending email change.
+func (h *AccountHandler) VerifyEmail(c echo.Context) error {
+	var req dto.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	user, err := h.accounts.VerifyEmail(c.Request().Context(), req.Token)
+	if err != nil {
+		return accountError(c, err, "unable to verify email")
+	}
+	return Success(c, http.StatusOK, "email updated", user)
+}
+
+// Sessions handles GET /me/sessions requests.
+func (h *AccountHandler) Sessions(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	sessionID, _ := c.Get(middlewarepkg.ContextKeySessionID).(string)
+	sessions, err := h.accounts.Sessions(c.Request().Context(), userID, sessionID)
+	if err != nil {
+		return accountError(c, err, "failed to list sessions")
+	}
+	return Success(c, http.StatusOK, "sessions retrieved", sessions)
+}
+
+// RevokeSession handles DELETE /me/sessions/:id requests.
+func (h *AccountHandler) RevokeSession(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	if err := h.accounts.RevokeSession(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return accountError(c, err, "failed to revoke session")
+	}
+	return Success(c, http.StatusOK, "session revoked", nil)
+}
+
+// accountError maps account service errors to responses, answering 500 with message otherwise.
+func accountError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		return Error(c, http.StatusNotFound, "user not found")
+	case errors.Is(err, repository.ErrSessionNotFound):
+		return Error(c, http.StatusNotFound, "session not found")
+	case errors.Is(err, service.ErrInvalidEmailChange):
+		return Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCurrentPasswordMismatch):
+		return Error(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrEmailDuplicate):
+		return Error(c, http.StatusConflict, "email already exists")
+	case errors.Is(err, service.ErrEmailChangesUnavailable), errors.Is(err, service.ErrSessionsUnavailable):
+		return Error(c, http.StatusNotImplemented, err.Error())
+	case errors.Is(err, service.ErrInvalidProfileUpdate):
+		return Error(c, http.StatusBadRequest, err.Error())
+	default:
+		return Error(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+func TestAccountHandler(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("current"), bcrypt.MinCost)
+	user := testsupport.NewUser().WithPasswordHash(string(hashed)).Build()
+	h := NewAccountHandler(service.NewAccountService(testsupport.NewMemoryUsersRepository(user)))
+
+	c, rec := testsupport.NewContext(http.MethodGet, "/me")
+	if err := h.Get(testsupport.WithUser(c, user)); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	var profile dto.ProfileResponse
+	testsupport.DecodeData(t, rec, &profile)
+	if profile.ID != user.ID.String() || profile.Email != user.Email || profile.PendingEmailChange != nil {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+
+	password := "rotated"
+	update := func(req dto.UpdateProfileRequest) int {
+		c, rec := testsupport.NewJSONContext(t, http.MethodPatch, "/me", req)
+		if err := h.Update(testsupport.WithUser(c, user)); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		return rec.Code
+	}
+	if code := update(dto.UpdateProfileRequest{Password: &password, CurrentPassword: "guess"}); code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a wrong current password, got %d", code)
+	}
+	if code := update(dto.UpdateProfileRequest{CurrentPassword: "current"}); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an empty update, got %d", code)
+	}
+	email := "new@example.com"
+	if code := update(dto.UpdateProfileRequest{Email: &email, CurrentPassword: "current"}); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without a mailer, got %d", code)
+	}
+
+	c, rec = testsupport.NewContext(http.MethodGet, "/me/sessions")
+	if err := h.Sessions(testsupport.WithUser(c, user)); err != nil {
+		t.Fatalf("sessions: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusNotImplemented)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// AdminJobsHandler lets administrators list failed scrape and enrichment jobs and send them to the
+// worker again.
+type AdminJobsHandler struct {
+	worker    WorkerPoster
+	callbacks *auth.CallbackSigner
+	jobs      *service.JobRetryService
+}
+
+// NewAdminJobsHandler wires a handler retrying jobs through worker; callbacks, when set, signs the
+// callback token of every retried job like the enqueue endpoints do.
+func NewAdminJobsHandler(worker WorkerPoster, callbacks *auth.CallbackSigner, jobs *service.JobRetryService) *AdminJobsHandler {
+	return &AdminJobsHandler{worker: worker, callbacks: callbacks, jobs: jobs}
+}
+
+// List handles GET /admin/jobs?status=failed requests, optionally narrowed with kind=scrape or
+// kind=enrichment.
+func (h *AdminJobsHandler) List(c echo.Context) error {
+	limit := parseIntDefault(c.QueryParam("limit"), 50)
+	offset := parseIntDefault(c.QueryParam("offset"), 0)
+	jobs, err := h.jobs.List(c.Request().Context(), c.QueryParam("status"), c.QueryParam("kind"), limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidJobFilter) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return queryError(c, err, "failed to list jobs")
+	}
+	return Success(c, http.StatusOK, "jobs retrieved", jobs)
+}
+
+// Retry handles POST /admin/jobs/:id/retry requests, posting the stored request of a failed job to
+// the worker again. A job the worker refuses again is marked failed with the new reason.
+func (h *AdminJobsHandler) Retry(c echo.Context) error {
+	ctx := c.Request().Context()
+	job, err := h.jobs.Requeue(ctx, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidJobID):
+			return Error(c, http.StatusBadRequest, "invalid job id")
+		case errors.Is(err, service.ErrJobNotFound):
+			return Error(c, http.StatusNotFound, "job not found")
+		case errors.Is(err, service.ErrJobNotFailed):
+			return Error(c, http.StatusConflict, err.Error())
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to requeue job")
+		}
+	}
+
+	var (
+		path    string
+		payload any
+	)
+	switch job.Kind {
+	case entity.JobKindScrape:
+		scrape := dto.WorkerScrapeRequest{
+			Source:       job.Scrape.Source,
+			TypeBusiness: job.Scrape.TypeBusiness,
+			City:         job.Scrape.City,
+			Country:      job.Scrape.Country,
+			MinRating:    job.Scrape.MinRating,
+		}
+		if err := assignScrapeRun(h.callbacks, &scrape, job.ID); err != nil {
+			h.failJob(c, job, errCallbackToken)
+			return Error(c, http.StatusInternalServerError, errCallbackToken)
+		}
+		path, payload = "/scrape", scrape
+	case entity.JobKindEnrichment:
+		profile, err := service.ResolveEnrichDepth(job.Enrichment.Depth)
+		if err != nil {
+			h.failJob(c, job, err.Error())
+			return Error(c, http.StatusUnprocessableEntity, err.Error())
+		}
+		enrich := dto.WorkerEnrichRequest{
+			CompanyID: job.Enrichment.CompanyID.String(),
+			Website:   job.Enrichment.Website,
+			Depth:     profile.Depth,
+			MaxPages:  profile.MaxPages,
+		}
+		if h.callbacks != nil {
+			token, err := h.callbacks.Issue(auth.CallbackEnrich, job.ID.String(), enrich.CompanyID)
+			if err != nil {
+				h.failJob(c, job, errCallbackToken)
+				return Error(c, http.StatusInternalServerError, errCallbackToken)
+			}
+			enrich.JobID = job.ID.String()
+			enrich.CallbackToken = token
+		}
+		path, payload = "/enrich", enrich
+	}
+
+	if _, err := h.worker.PostJSON(ctx, path, payload, middlewarepkg.RequestIDFromContext(c)); err != nil {
+		h.failJob(c, job, err.Error())
+		return workerError(c, err)
+	}
+	return Success(c, http.StatusOK, "job requeued", job)
+}
+
+// failJob marks a retried job failed again, so it can be retried once more.
+func (h *AdminJobsHandler) failJob(c echo.Context, job *entity.RetryableJob, reason string) {
+	if err := h.jobs.Fail(c.Request().Context(), job, reason); err != nil {
+		log.Printf("request_id=%s failed to mark %s job %s failed: %v", middlewarepkg.RequestIDFromContext(c), job.Kind, job.ID, err)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type retryableJobsRepoStub struct {
+	jobs   map[uuid.UUID]entity.RetryableJob
+	failed map[uuid.UUID]string
+}
+
+func (s *retryableJobsRepoStub) ListFailedJobs(ctx context.Context, kind string, limit, offset int) ([]entity.RetryableJob, error) {
+	jobs := make([]entity.RetryableJob, 0)
+	for _, job := range s.jobs {
+		if job.Status == service.JobStatusFailed && (kind == "" || job.Kind == kind) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *retryableJobsRepoStub) RequeueFailedJob(ctx context.Context, id uuid.UUID) (*entity.RetryableJob, error) {
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, repository.ErrRetryableJobNotFound
+	}
+	if job.Status != service.JobStatusFailed {
+		return nil, repository.ErrJobNotFailed
+	}
+	job.Status = "queued"
+	job.Error = nil
+	job.Retries++
+	s.jobs[id] = job
+	return &job, nil
+}
+
+func (s *retryableJobsRepoStub) FailRetryableJob(ctx context.Context, kind string, id uuid.UUID, reason string) error {
+	job := s.jobs[id]
+	job.Status = service.JobStatusFailed
+	job.Error = &reason
+	s.jobs[id] = job
+	s.failed[id] = reason
+	return nil
+}
+
+func TestAdminJobsHandler(t *testing.T) {
+	e := echo.New()
+	scrapeID, enrichID := uuid.New(), uuid.New()
+	repo := &retryableJobsRepoStub{
+		jobs: map[uuid.UUID]entity.RetryableJob{
+			scrapeID: {ID: scrapeID, Kind: entity.JobKindScrape, Status: service.JobStatusFailed,
+				Scrape: &entity.RetryableScrape{TypeBusiness: "cafe", City: "Bandung", Country: "ID", MinRating: 4}},
+			enrichID: {ID: enrichID, Kind: entity.JobKindEnrichment, Status: service.JobStatusFailed,
+				Enrichment: &entity.RetryableEnrichment{CompanyID: uuid.New(), Website: "https://example.com", Depth: "deep"}},
+		},
+		failed: map[uuid.UUID]string{},
+	}
+	worker := &workerStub{data: map[string]any{"status": "queued"}}
+	h := NewAdminJobsHandler(worker, auth.NewCallbackSigner("callback-secret", time.Hour), service.NewJobRetryService(repo))
+
+	list := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		if err := h.List(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/jobs?"+query, nil), rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+	if rec := list("status=queued"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unsupported status, got %d", rec.Code)
+	}
+	if rec := list("status=failed&kind=scrape"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), scrapeID.String()) || strings.Contains(rec.Body.String(), enrichID.String()) {
+		t.Fatalf("expected the failed scrape job, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	retry := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/admin/jobs/"+id+"/retry", nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		if err := h.Retry(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+	if rec := retry("nope"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid id, got %d", rec.Code)
+	}
+	if rec := retry(uuid.NewString()); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown job, got %d", rec.Code)
+	}
+
+	if rec := retry(scrapeID.String()); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"retries":1`) {
+		t.Fatalf("expected the requeued job, got %d: %s", rec.Code, rec.Body.String())
+	}
+	scrape, ok := worker.payload.(dto.WorkerScrapeRequest)
+	if worker.path != "/scrape" || !ok || scrape.City != "Bandung" || scrape.MinRating != 4 || scrape.RunID != scrapeID.String() || scrape.CallbackToken == "" {
+		t.Fatalf("expected the stored scrape re-posted to its run, got %s %+v", worker.path, worker.payload)
+	}
+	if rec := retry(scrapeID.String()); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a job retried already, got %d", rec.Code)
+	}
+
+	worker.err = errors.New("boom")
+	if rec := retry(enrichID.String()); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 when the worker refuses, got %d", rec.Code)
+	}
+	enrich, ok := worker.payload.(dto.WorkerEnrichRequest)
+	if worker.path != "/enrich" || !ok || enrich.MaxPages != 20 || enrich.JobID != enrichID.String() {
+		t.Fatalf("expected the stored enrichment re-posted, got %s %+v", worker.path, worker.payload)
+	}
+	if repo.failed[enrichID] != "boom" || repo.jobs[enrichID].Status != service.JobStatusFailed {
+		t.Fatalf("expected the job failed again with the reason, got %+v", repo.jobs[enrichID])
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// AdminMaintenanceHandler exposes database maintenance tasks to administrators.
+type AdminMaintenanceHandler struct {
+	orphans *service.OrphanReconcileService
+}
+
+// NewAdminMaintenanceHandler wires a handler backed by the orphan reconciliation service.
+func NewAdminMaintenanceHandler(orphans *service.OrphanReconcileService) *AdminMaintenanceHandler {
+	return &AdminMaintenanceHandler{orphans: orphans}
+}
+
+// Reconcile handles POST /admin/maintenance/reconcile requests. It re-links or archives the
+// enrichments, contacts, lead scores and snapshots of companies that no longer exist and answers
+// with a report per table; dry_run=true reports without changing anything.
+func (h *AdminMaintenanceHandler) Reconcile(c echo.Context) error {
+	dryRun := false
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			return Error(c, http.StatusBadRequest, "invalid dry_run flag")
+		}
+	}
+	report, err := h.orphans.Reconcile(c.Request().Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrReconcileInProgress) {
+			return Error(c, http.StatusConflict, err.Error())
+		}
+		log.Printf("request_id=%s failed to reconcile orphans: %v", middlewarepkg.RequestIDFromContext(c), err)
+		return Error(c, http.StatusInternalServerError, "failed to reconcile orphans")
+	}
+	return Success(c, http.StatusOK, "orphans reconciled", report)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type orphansRepoStub struct {
+	dryRun bool
+	err    error
+}
+
+func (s *orphansRepoStub) ReconcileOrphans(ctx context.Context, runID uuid.UUID, dryRun bool) ([]entity.OrphanTableReport, error) {
+	s.dryRun = dryRun
+	return []entity.OrphanTableReport{{Table: "company_enrichments", Orphans: 2, Relinked: 1, Archived: 1}}, s.err
+}
+
+func TestAdminMaintenanceHandler_Reconcile(t *testing.T) {
+	e := echo.New()
+	repo := &orphansRepoStub{}
+	h := NewAdminMaintenanceHandler(service.NewOrphanReconcileService(repo))
+
+	call := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		if err := h.Reconcile(e.NewContext(httptest.NewRequest(http.MethodPost, "/admin/maintenance/reconcile"+query, nil), rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := call("?dry_run=true")
+	if rec.Code != http.StatusOK || !repo.dryRun || !strings.Contains(rec.Body.String(), `"dry_run":true`) || !strings.Contains(rec.Body.String(), `"table":"company_enrichments"`) {
+		t.Fatalf("expected a dry run report, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(""); rec.Code != http.StatusOK || repo.dryRun {
+		t.Fatalf("expected a real run by default, got %d", rec.Code)
+	}
+	if rec := call("?dry_run=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid flag, got %d", rec.Code)
+	}
+	repo.err = repository.ErrReconcileInProgress
+	if rec := call(""); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while a run is in progress, got %d", rec.Code)
+	}
+}
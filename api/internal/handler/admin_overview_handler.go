@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// AdminOverviewHandler serves the one-call summary of the ops dashboard.
+type AdminOverviewHandler struct {
+	overview *service.OverviewService
+}
+
+// NewAdminOverviewHandler constructs a handler instance.
+func NewAdminOverviewHandler(overview *service.OverviewService) *AdminOverviewHandler {
+	return &AdminOverviewHandler{overview: overview}
+}
+
+// Get handles GET /admin/overview requests.
+func (h *AdminOverviewHandler) Get(c echo.Context) error {
+	overview, err := h.overview.Overview(c.Request().Context())
+	if err != nil {
+		return queryError(c, err, "failed to build overview")
+	}
+	return Success(c, http.StatusOK, "overview retrieved", overview)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type overviewRepoStub struct {
+	err error
+}
+
+func (s *overviewRepoStub) JobCounts(ctx context.Context, since time.Time) ([]entity.JobStatusCounts, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []entity.JobStatusCounts{{Kind: entity.JobKindScrape, Queued: 2, Failed: 1}}, nil
+}
+
+func (s *overviewRepoStub) LastCityScrapes(ctx context.Context, limit int) ([]entity.CityScrape, error) {
+	return []entity.CityScrape{{City: "Jakarta", Country: "ID", Items: 40}}, nil
+}
+
+func (s *overviewRepoStub) ImportFailures(ctx context.Context, since time.Time, limit int) (*entity.ImportFailures, error) {
+	return &entity.ImportFailures{Total: 1, Recent: []entity.ImportFailure{{Filename: "leads.csv"}}}, nil
+}
+
+func (s *overviewRepoStub) DatabaseSize(ctx context.Context, tables int) (*entity.DatabaseSize, error) {
+	return &entity.DatabaseSize{Bytes: 4096, Tables: []entity.TableSize{}}, nil
+}
+
+func TestAdminOverviewHandler_Get(t *testing.T) {
+	e := echo.New()
+	recorder := NewWorkerLatencyRecorder(&routedWorkerStub{}, 10)
+	recorder.PostJSON(context.Background(), "/enrich", nil, "")
+	handler := NewAdminOverviewHandler(service.NewOverviewService(&overviewRepoStub{}, service.WithOverviewWorkerLatency(recorder)))
+
+	rec := httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/overview", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body struct {
+		Data entity.AdminOverview `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Data.Jobs) != 1 || body.Data.ImportFailures.Total != 1 || body.Data.Database.Bytes != 4096 {
+		t.Fatalf("unexpected overview payload: %s", rec.Body.String())
+	}
+	if len(body.Data.WorkerLatency) != 1 || body.Data.WorkerLatency[0].Job != "enrich" || body.Data.WorkerLatency[0].Calls != 1 {
+		t.Fatalf("expected the recorded worker latency, got %+v", body.Data.WorkerLatency)
+	}
+
+	handler = NewAdminOverviewHandler(service.NewOverviewService(&overviewRepoStub{err: errors.New("boom")}))
+	rec = httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/overview", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+}
@@ -2,16 +2,22 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 	"github.com/octobees/leads-generator/api/internal/service"
 )
 
 // AdminUploadHandler handles CSV ingestion for administrators.
 type AdminUploadHandler struct {
 	companiesService *service.CompaniesService
+	archive          *service.UploadArchiveService
 }
 
 // NewAdminUploadHandler wires a handler backed by the companies service.
@@ -19,8 +25,35 @@ func NewAdminUploadHandler(companiesService *service.CompaniesService) *AdminUpl
 	return &AdminUploadHandler{companiesService: companiesService}
 }
 
-// UploadCSV handles POST /admin/upload-csv requests.
+// NewAdminUploadHandlerWithArchive also retains a copy of every uploaded file.
+func NewAdminUploadHandlerWithArchive(companiesService *service.CompaniesService, archive *service.UploadArchiveService) *AdminUploadHandler {
+	return &AdminUploadHandler{companiesService: companiesService, archive: archive}
+}
+
+// UploadCSV handles POST /admin/upload-csv requests. The file may be a CSV (comma, semicolon or
+// tab separated, with or without a UTF-8 BOM) or an XLSX workbook. The optional mode form or query value picks
+// overwrite (default), fill_missing or skip_existing for companies that already exist; mapping is
+// an optional JSON object renaming CSV columns onto the expected fields. With preview=true the
+// file is only parsed and the first rows plus detected issues are returned.
 func (h *AdminUploadHandler) UploadCSV(c echo.Context) error {
+	mode, err := service.ParseImportMode(c.FormValue("mode"))
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "invalid mode (use overwrite, fill_missing or skip_existing)")
+	}
+
+	mapping, err := service.ParseCSVColumnMapping(c.FormValue("mapping"))
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	preview := false
+	if raw := c.FormValue("preview"); raw != "" {
+		preview, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Error(c, http.StatusBadRequest, "invalid preview flag")
+		}
+	}
+
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		return Error(c, http.StatusBadRequest, "missing csv file")
@@ -32,7 +65,33 @@ func (h *AdminUploadHandler) UploadCSV(c echo.Context) error {
 	}
 	defer file.Close()
 
-	summary, err := h.companiesService.ImportCompaniesCSV(c.Request().Context(), file)
+	if preview {
+		result, err := h.companiesService.PreviewCompaniesCSV(file, mapping)
+		if err != nil {
+			return Error(c, http.StatusInternalServerError, "failed to preview csv")
+		}
+		return Success(c, http.StatusOK, "companies CSV preview", result)
+	}
+
+	ctx := c.Request().Context()
+	var upload *entity.CSVUpload
+	if h.archive != nil {
+		userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+		// Imports are only accepted once their file is retained, so every import stays auditable.
+		upload, err = h.archive.Archive(ctx, userID, fileHeader.Filename, mode, file)
+		if err != nil {
+			log.Printf("request_id=%s failed to retain upload: %v", middlewarepkg.RequestIDFromContext(c), err)
+			return Error(c, http.StatusInternalServerError, "failed to retain csv upload")
+		}
+	}
+
+	summary, err := h.companiesService.ImportCompaniesCSV(ctx, file, mode, mapping)
+	if upload != nil {
+		if completeErr := h.archive.Complete(ctx, upload, summary, err); completeErr != nil {
+			log.Printf("request_id=%s failed to record upload %s outcome: %v", middlewarepkg.RequestIDFromContext(c), upload.ID, completeErr)
+		}
+		summary.UploadID = upload.ID.String()
+	}
 	if err != nil {
 		var validationErr service.CSVValidationError
 		if errors.As(err, &validationErr) {
@@ -43,3 +102,58 @@ func (h *AdminUploadHandler) UploadCSV(c echo.Context) error {
 
 	return Success(c, http.StatusOK, "companies CSV processed", summary)
 }
+
+// ListUploads handles GET /admin/uploads requests.
+func (h *AdminUploadHandler) ListUploads(c echo.Context) error {
+	if h.archive == nil {
+		return Error(c, http.StatusNotImplemented, "upload retention is not enabled")
+	}
+	limit := parseIntDefault(c.QueryParam("limit"), 50)
+	offset := parseIntDefault(c.QueryParam("offset"), 0)
+	uploads, err := h.archive.List(c.Request().Context(), limit, offset)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list uploads")
+	}
+	return Success(c, http.StatusOK, "uploads retrieved", uploads)
+}
+
+// GetUpload handles GET /admin/uploads/:id requests.
+func (h *AdminUploadHandler) GetUpload(c echo.Context) error {
+	if h.archive == nil {
+		return Error(c, http.StatusNotImplemented, "upload retention is not enabled")
+	}
+	upload, err := h.archive.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return uploadError(c, err)
+	}
+	return Success(c, http.StatusOK, "upload retrieved", upload)
+}
+
+// DownloadUpload handles GET /admin/uploads/:id/download requests by streaming the retained file.
+func (h *AdminUploadHandler) DownloadUpload(c echo.Context) error {
+	if h.archive == nil {
+		return Error(c, http.StatusNotImplemented, "upload retention is not enabled")
+	}
+	upload, file, err := h.archive.Open(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return uploadError(c, err)
+	}
+	defer file.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", upload.Filename))
+	// Lets the reviewer check the file against the hash recorded at upload time.
+	res.Header().Set("X-Checksum-SHA256", upload.SHA256)
+	return c.Stream(http.StatusOK, service.UploadContentType(upload), file)
+}
+
+func uploadError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidUploadID):
+		return Error(c, http.StatusBadRequest, "invalid upload id")
+	case errors.Is(err, service.ErrUploadNotFound):
+		return Error(c, http.StatusNotFound, "upload not found")
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to load upload")
+	}
+}
@@ -3,29 +3,36 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 	"github.com/octobees/leads-generator/api/internal/repository"
 	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/storage"
 )
 
 type stubCompaniesRepository struct {
 	bulk func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error)
+	mode repository.ImportMode
 }
 
 func (s *stubCompaniesRepository) List(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
 	return nil, nil
 }
 
-func (s *stubCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+func (s *stubCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput, mode repository.ImportMode) (repository.BulkUpsertResult, error) {
+	s.mode = mode
 	if s.bulk != nil {
 		return s.bulk(ctx, records)
 	}
@@ -126,6 +133,29 @@ func TestAdminUploadHandler_UploadCSV(t *testing.T) {
 	}
 }
 
+func TestAdminUploadHandler_UploadCSV_Mode(t *testing.T) {
+	e := echo.New()
+
+	repo := &stubCompaniesRepository{}
+	req, rec := multipartRequest(t, "file", "test.csv", validCSV())
+	req.URL.RawQuery = "mode=skip-existing"
+	if err := newAdminUploadHandler(repo).UploadCSV(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || repo.mode != repository.ImportModeSkipExisting {
+		t.Fatalf("expected skip_existing import, got %d with mode %q", rec.Code, repo.mode)
+	}
+
+	req, rec = multipartRequest(t, "file", "test.csv", validCSV())
+	req.URL.RawQuery = "mode=merge"
+	if err := newAdminUploadHandler(&stubCompaniesRepository{}).UploadCSV(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown mode, got %d", rec.Code)
+	}
+}
+
 func multipartRequest(t *testing.T, field, filename, content string) (*http.Request, *httptest.ResponseRecorder) {
 	t.Helper()
 	body := &bytes.Buffer{}
@@ -145,6 +175,143 @@ func multipartRequest(t *testing.T, field, filename, content string) (*http.Requ
 	return req, rec
 }
 
+func TestAdminUploadHandler_UploadCSV_Preview(t *testing.T) {
+	e := echo.New()
+
+	repo := &stubCompaniesRepository{}
+	req, rec := multipartRequest(t, "file", "test.csv", "Name,Street,phone,website,rating,reviews,type_business,city,country\nAcme,Main St,,,4.5,10,store,Gotham,USA\n")
+	req.URL.RawQuery = url.Values{"preview": {"true"}, "mapping": {`{"Name":"company","Street":"address"}`}}.Encode()
+	if err := newAdminUploadHandler(repo).UploadCSV(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Data service.CSVPreview `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if payload.Data.Valid != 1 || len(payload.Data.Rows) != 1 || payload.Data.Rows[0].Company != "Acme" {
+		t.Fatalf("unexpected preview: %+v", payload.Data)
+	}
+	if repo.mode != "" {
+		t.Fatalf("expected preview not to import")
+	}
+
+	req, rec = multipartRequest(t, "file", "test.csv", validCSV())
+	req.URL.RawQuery = url.Values{"mapping": {`{"Name":"title"}`}}.Encode()
+	if err := newAdminUploadHandler(&stubCompaniesRepository{}).UploadCSV(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid mapping, got %d", rec.Code)
+	}
+}
+
 func validCSV() string {
 	return "company,address,phone,website,rating,reviews,type_business,city,country\nAcme,Main St,,,4.5,10,store,Gotham,USA\n"
 }
+
+type csvUploadsRepoStub struct {
+	uploads map[uuid.UUID]entity.CSVUpload
+}
+
+func (s *csvUploadsRepoStub) Create(ctx context.Context, upload *entity.CSVUpload) error {
+	s.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (s *csvUploadsRepoStub) Complete(ctx context.Context, upload *entity.CSVUpload) error {
+	s.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (s *csvUploadsRepoStub) List(ctx context.Context, limit, offset int) ([]entity.CSVUpload, error) {
+	uploads := make([]entity.CSVUpload, 0, len(s.uploads))
+	for _, upload := range s.uploads {
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+func (s *csvUploadsRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.CSVUpload, error) {
+	upload, ok := s.uploads[id]
+	if !ok {
+		return nil, repository.ErrCSVUploadNotFound
+	}
+	return &upload, nil
+}
+
+func (s *csvUploadsRepoStub) ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.CSVUpload, error) {
+	return nil, nil
+}
+
+func (s *csvUploadsRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(s.uploads, id)
+	return nil
+}
+
+func TestAdminUploadHandler_RetainsUploads(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo := &csvUploadsRepoStub{uploads: make(map[uuid.UUID]entity.CSVUpload)}
+	archive := service.NewUploadArchiveService(repo, store, "uploads", time.Hour)
+	handler := NewAdminUploadHandlerWithArchive(service.NewCompaniesService(&stubCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	}), archive)
+	e := echo.New()
+
+	req, rec := multipartRequest(t, "file", "leads.csv", validCSV())
+	c := e.NewContext(req, rec)
+	c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	if err := handler.UploadCSV(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Data service.UploadSummary `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	uploadID, err := uuid.Parse(payload.Data.UploadID)
+	if err != nil {
+		t.Fatalf("expected upload id in summary, got %+v", payload.Data)
+	}
+	stored := repo.uploads[uploadID]
+	if stored.Status != entity.CSVUploadStatusCompleted || stored.Inserted != 1 || stored.UploadedBy == nil || stored.Filename != "leads.csv" {
+		t.Fatalf("unexpected upload record: %+v", stored)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/uploads/"+uploadID.String()+"/download", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(uploadID.String())
+	if err := handler.DownloadUpload(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != validCSV() || rec.Header().Get("X-Checksum-SHA256") != stored.SHA256 {
+		t.Fatalf("unexpected download: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+}
+
+func TestAdminUploadHandler_UploadsDisabled(t *testing.T) {
+	handler := newAdminUploadHandler(&stubCompaniesRepository{})
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := handler.ListUploads(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/uploads", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", rec.Code)
+	}
+}
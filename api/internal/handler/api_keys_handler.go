@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// APIKeysHandler lets administrators issue and revoke the API keys data providers push companies
+// with.
+type APIKeysHandler struct {
+	keys *service.APIKeyService
+}
+
+// NewAPIKeysHandler wires a handler backed by the API key service.
+func NewAPIKeysHandler(keys *service.APIKeyService) *APIKeysHandler {
+	return &APIKeysHandler{keys: keys}
+}
+
+// Create handles POST /admin/api-keys requests. The response is the only time the secret is shown.
+func (h *APIKeysHandler) Create(c echo.Context) error {
+	var req dto.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	var createdBy *uuid.UUID
+	if userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string); userID != "" {
+		if id, err := uuid.Parse(userID); err == nil {
+			createdBy = &id
+		}
+	}
+
+	key, secret, err := h.keys.Create(c.Request().Context(), req.Name, req.Scopes, createdBy)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyName) || errors.Is(err, service.ErrInvalidAPIKeyScope) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to create api key")
+	}
+	return Success(c, http.StatusCreated, "api key created", dto.CreatedAPIKeyResponse{APIKey: *key, Secret: secret})
+}
+
+// List handles GET /admin/api-keys requests.
+func (h *APIKeysHandler) List(c echo.Context) error {
+	keys, err := h.keys.List(c.Request().Context())
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list api keys")
+	}
+	return Success(c, http.StatusOK, "api keys retrieved", keys)
+}
+
+// Revoke handles DELETE /admin/api-keys/:id requests. Revoking a revoked key succeeds.
+func (h *APIKeysHandler) Revoke(c echo.Context) error {
+	key, err := h.keys.Revoke(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKeyID):
+			return Error(c, http.StatusBadRequest, "invalid api key id")
+		case errors.Is(err, service.ErrAPIKeyNotFound):
+			return Error(c, http.StatusNotFound, "api key not found")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to revoke api key")
+		}
+	}
+	return Success(c, http.StatusOK, "api key revoked", key)
+}
+
+// Guard only lets through requests carrying an unrevoked API key granted scope.
+func (h *APIKeysHandler) Guard(scope string) echo.MiddlewareFunc {
+	return middlewarepkg.APIKey(h.keys, scope)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type apiKeysRepoStub struct {
+	keys []*entity.APIKey
+}
+
+func (s *apiKeysRepoStub) Create(ctx context.Context, key *entity.APIKey, keyHash string) error {
+	key.ID = uuid.New()
+	key.CreatedAt = time.Now()
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *apiKeysRepoStub) List(ctx context.Context) ([]entity.APIKey, error) {
+	keys := make([]entity.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (s *apiKeysRepoStub) GetActiveByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (s *apiKeysRepoStub) Revoke(ctx context.Context, id uuid.UUID) (*entity.APIKey, error) {
+	for _, key := range s.keys {
+		if key.ID == id {
+			now := time.Now()
+			key.RevokedAt = &now
+			return key, nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (s *apiKeysRepoStub) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func TestAPIKeysHandler(t *testing.T) {
+	repo := &apiKeysRepoStub{}
+	handler := NewAPIKeysHandler(service.NewAPIKeyService(repo))
+	admin := testsupport.NewUser().Admin().Build()
+
+	c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/api-keys", dto.CreateAPIKeyRequest{Name: "Acme data"})
+	if err := handler.Create(testsupport.WithUser(c, admin)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusCreated)
+	var created dto.CreatedAPIKeyResponse
+	testsupport.DecodeData(t, rec, &created)
+	if created.Secret == "" || created.Name != "Acme data" || created.CreatedBy == nil || *created.CreatedBy != admin.ID {
+		t.Fatalf("unexpected created key: %+v", created)
+	}
+
+	c, rec = testsupport.NewJSONContext(t, http.MethodPost, "/admin/api-keys", dto.CreateAPIKeyRequest{Name: "Acme data", Scopes: []string{"users:write"}})
+	if err := handler.Create(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusBadRequest)
+
+	c, rec = testsupport.NewContext(http.MethodGet, "/admin/api-keys")
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var keys []map[string]any
+	testsupport.DecodeData(t, rec, &keys)
+	if len(keys) != 1 || keys[0]["secret"] != nil {
+		t.Fatalf("expected the key listed without its secret, got %+v", keys)
+	}
+
+	revoke := func(id string) int {
+		c, rec := testsupport.NewContext(http.MethodDelete, "/admin/api-keys/"+id)
+		if err := handler.Revoke(testsupport.WithParams(c, "id", id)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code
+	}
+	if code := revoke(created.ID.String()); code != http.StatusOK || repo.keys[0].RevokedAt == nil {
+		t.Fatalf("expected the key revoked, got %d", code)
+	}
+	if code := revoke(uuid.NewString()); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown key, got %d", code)
+	}
+	if code := revoke("nope"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid id, got %d", code)
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// AttachmentsHandler exposes files attached to companies, such as proposals and call recordings.
+type AttachmentsHandler struct {
+	attachments *service.AttachmentService
+}
+
+// NewAttachmentsHandler constructs a handler instance.
+func NewAttachmentsHandler(attachments *service.AttachmentService) *AttachmentsHandler {
+	return &AttachmentsHandler{attachments: attachments}
+}
+
+// Create handles POST /companies/:id/attachments requests. It answers with a signed URL the client
+// uploads the file to, after which it calls POST /attachments/:id/complete.
+func (h *AttachmentsHandler) Create(c echo.Context) error {
+	var req dto.CreateAttachmentRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	upload, err := h.attachments.CreateUpload(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return h.error(c, err, "failed to create attachment")
+	}
+	return Success(c, http.StatusCreated, "attachment upload url created", upload)
+}
+
+// Complete handles POST /attachments/:id/complete requests from the uploader or an admin once the
+// file has been uploaded.
+func (h *AttachmentsHandler) Complete(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	attachment, err := h.attachments.Complete(c.Request().Context(), userID, role == "admin", c.Param("id"))
+	if err != nil {
+		return h.error(c, err, "failed to complete attachment")
+	}
+	return Success(c, http.StatusOK, "attachment uploaded", attachment)
+}
+
+// List handles GET /companies/:id/attachments requests.
+func (h *AttachmentsHandler) List(c echo.Context) error {
+	attachments, err := h.attachments.List(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.error(c, err, "failed to list attachments")
+	}
+	return Success(c, http.StatusOK, "attachments retrieved", attachments)
+}
+
+// Download handles GET /attachments/:id/download requests with a signed download URL.
+func (h *AttachmentsHandler) Download(c echo.Context) error {
+	download, err := h.attachments.Download(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.error(c, err, "failed to sign attachment download")
+	}
+	return Success(c, http.StatusOK, "attachment download url created", download)
+}
+
+// Delete handles DELETE /attachments/:id requests from the uploader or an admin.
+func (h *AttachmentsHandler) Delete(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	if err := h.attachments.Delete(c.Request().Context(), userID, role == "admin", c.Param("id")); err != nil {
+		return h.error(c, err, "failed to delete attachment")
+	}
+	return Success(c, http.StatusOK, "attachment deleted", nil)
+}
+
+// Usage handles GET /attachments/usage requests with the caller's storage use and quota.
+func (h *AttachmentsHandler) Usage(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	usage, err := h.attachments.Usage(c.Request().Context(), userID)
+	if err != nil {
+		return h.error(c, err, "failed to load attachment usage")
+	}
+	return Success(c, http.StatusOK, "attachment usage retrieved", usage)
+}
+
+func (h *AttachmentsHandler) error(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAttachment):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidCompanyID):
+		return Error(c, http.StatusBadRequest, "invalid company id")
+	case errors.Is(err, service.ErrInvalidAttachmentID):
+		return Error(c, http.StatusBadRequest, "invalid attachment id")
+	case errors.Is(err, service.ErrCompanyNotFound):
+		return Error(c, http.StatusNotFound, "company not found")
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		return Error(c, http.StatusNotFound, "attachment not found")
+	case errors.Is(err, service.ErrAttachmentForbidden):
+		return Error(c, http.StatusForbidden, "only the uploader or an admin can change this attachment")
+	case errors.Is(err, service.ErrAttachmentNotUploaded):
+		return Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrAttachmentTooLarge), errors.Is(err, service.ErrAttachmentQuotaExceeded):
+		return Error(c, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		log.Printf("request_id=%s %s: %v", middlewarepkg.RequestIDFromContext(c), fallback, err)
+		return Error(c, http.StatusInternalServerError, fallback)
+	}
+}
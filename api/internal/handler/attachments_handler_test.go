@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type attachmentsRepoStub struct {
+	attachments map[uuid.UUID]entity.CompanyAttachment
+}
+
+func (s *attachmentsRepoStub) Create(ctx context.Context, attachment *entity.CompanyAttachment) error {
+	s.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (s *attachmentsRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.CompanyAttachment, error) {
+	attachment, ok := s.attachments[id]
+	if !ok {
+		return nil, repository.ErrAttachmentNotFound
+	}
+	return &attachment, nil
+}
+
+func (s *attachmentsRepoStub) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]entity.CompanyAttachment, error) {
+	return nil, nil
+}
+
+func (s *attachmentsRepoStub) MarkReady(ctx context.Context, id uuid.UUID, sizeBytes int64, uploadedAt time.Time) error {
+	return nil
+}
+
+func (s *attachmentsRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(s.attachments, id)
+	return nil
+}
+
+func (s *attachmentsRepoStub) UsageByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (s *attachmentsRepoStub) UsageByOrg(ctx context.Context, userID uuid.UUID) (string, int64, error) {
+	return "", 0, nil
+}
+
+func (s *attachmentsRepoStub) ListExpiredPending(ctx context.Context, before time.Time, limit int) ([]entity.CompanyAttachment, error) {
+	return nil, nil
+}
+
+type attachmentStoreStub struct{}
+
+func (attachmentStoreStub) SignedURL(method, name string, headers map[string]string, ttl time.Duration) (string, error) {
+	return "https://storage.example/" + name, nil
+}
+
+func (attachmentStoreStub) Stat(ctx context.Context, name string) (storage.ObjectInfo, error) {
+	return storage.ObjectInfo{}, storage.ErrObjectNotFound
+}
+
+func (attachmentStoreStub) Delete(ctx context.Context, name string) error { return nil }
+
+func TestAttachmentsHandler(t *testing.T) {
+	attachments := service.NewAttachmentService(&attachmentsRepoStub{attachments: map[uuid.UUID]entity.CompanyAttachment{}}, attachmentStoreStub{}, "attachments", 0, 0, 0)
+	h := NewAttachmentsHandler(attachments)
+	e := echo.New()
+	uploader := uuid.NewString()
+
+	req := httptest.NewRequest(http.MethodPost, "/companies/x/attachments", strings.NewReader(`{"filename":"proposal.pdf","content_type":"application/pdf","size_bytes":1024}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(uuid.NewString())
+	c.Set(middlewarepkg.ContextKeyUserID, uploader)
+	if err := h.Create(c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data service.AttachmentTransfer `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Data.Method != http.MethodPut || created.Data.URL == "" || created.Data.Attachment == nil {
+		t.Fatalf("unexpected upload: %+v", created.Data)
+	}
+	id := created.Data.Attachment.ID.String()
+
+	call := func(method string, handle echo.HandlerFunc, userID, role string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(method, "/attachments/"+id, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set(middlewarepkg.ContextKeyUserID, userID)
+		c.Set(middlewarepkg.ContextKeyUserRole, role)
+		if err := handle(c); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		return rec
+	}
+
+	if rec := call(http.MethodPost, h.Complete, uuid.NewString(), "user"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 when another user confirms the upload, got %d", rec.Code)
+	}
+	if rec := call(http.MethodPost, h.Complete, uploader, "user"); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 before the file is uploaded, got %d", rec.Code)
+	}
+	if rec := call(http.MethodDelete, h.Delete, uuid.NewString(), "user"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another user, got %d", rec.Code)
+	}
+	if rec := call(http.MethodDelete, h.Delete, uploader, "user"); rec.Code != http.StatusOK {
+		t.Fatalf("expected uploader delete to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodGet, h.Download, uploader, "user"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", rec.Code)
+	}
+}
@@ -33,7 +33,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return Error(c, http.StatusBadRequest, "email and password are required")
 	}
 
-	token, err := h.authService.Register(c.Request().Context(), req.Email, req.Password)
+	tokens, err := h.authService.Register(c.Request().Context(), req.Email, req.Password, clientInfo(c))
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrEmailAlreadyExists):
@@ -43,7 +43,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		}
 	}
 
-	return Success(c, http.StatusCreated, "registration successful", dto.LoginResponse{AccessToken: token})
+	return Success(c, http.StatusCreated, "registration successful", tokens)
 }
 
 // Login handles POST /auth/login requests.
@@ -58,7 +58,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return Error(c, http.StatusBadRequest, "email and password are required")
 	}
 
-	token, err := h.authService.Login(c.Request().Context(), req.Email, req.Password)
+	tokens, err := h.authService.Login(c.Request().Context(), req.Email, req.Password, clientInfo(c))
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "invalid credentials") {
 			return Error(c, http.StatusUnauthorized, "invalid credentials")
@@ -66,5 +66,32 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return Error(c, http.StatusInternalServerError, "unable to authenticate")
 	}
 
-	return Success(c, http.StatusOK, "login successful", dto.LoginResponse{AccessToken: token})
+	return Success(c, http.StatusOK, "login successful", tokens)
+}
+
+// Refresh handles POST /auth/refresh requests, exchanging a refresh token for a new access token
+// and a new refresh token.
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req dto.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+
+	tokens, err := h.authService.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			return Error(c, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrRefreshTokensUnavailable):
+			return Error(c, http.StatusNotImplemented, "refresh tokens are not enabled")
+		default:
+			return Error(c, http.StatusInternalServerError, "unable to refresh tokens")
+		}
+	}
+	return Success(c, http.StatusOK, "tokens refreshed", tokens)
+}
+
+// clientInfo describes the caller for the session a login opens.
+func clientInfo(c echo.Context) dto.ClientInfo {
+	return dto.ClientInfo{UserAgent: c.Request().UserAgent(), IP: c.RealIP()}
 }
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
 	"github.com/octobees/leads-generator/api/internal/repository"
 	"github.com/octobees/leads-generator/api/internal/service"
@@ -42,10 +43,14 @@ func (s *stubUsersRepo) Create(ctx context.Context, email, passwordHash, role st
 	return nil, errors.New("not implemented")
 }
 
-func (s *stubUsersRepo) List(ctx context.Context) ([]entity.User, error) {
+func (s *stubUsersRepo) List(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (s *stubUsersRepo) Count(ctx context.Context, filter dto.UserListFilter) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
 func (s *stubUsersRepo) Update(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error) {
 	return nil, errors.New("not implemented")
 }
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"html"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// maxWebhookBody bounds the size of a provider webhook request.
+const maxWebhookBody = 1 << 20
+
+// trackingPixel is a transparent 1x1 GIF.
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// CampaignsHandler serves outreach campaigns and their public tracking links and provider
+// webhooks.
+type CampaignsHandler struct {
+	campaigns *service.CampaignService
+}
+
+// NewCampaignsHandler constructs a handler instance.
+func NewCampaignsHandler(campaigns *service.CampaignService) *CampaignsHandler {
+	return &CampaignsHandler{campaigns: campaigns}
+}
+
+// Create handles POST /admin/campaigns requests; the query holds the listing filter of the
+// companies to mail.
+func (h *CampaignsHandler) Create(c echo.Context) error {
+	filter, err := parseListFilter(c, false)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	var req dto.CampaignRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+
+	campaign, err := h.campaigns.Create(c.Request().Context(), filter, c.QueryString(), req, userID)
+	if err != nil {
+		return campaignError(c, err, "failed to create campaign")
+	}
+	return Success(c, http.StatusCreated, "campaign created", campaign)
+}
+
+// List handles GET /admin/campaigns requests.
+func (h *CampaignsHandler) List(c echo.Context) error {
+	campaigns, err := h.campaigns.List(
+		c.Request().Context(),
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list campaigns")
+	}
+	return Success(c, http.StatusOK, "campaigns retrieved", campaigns)
+}
+
+// Get handles GET /admin/campaigns/:id requests.
+func (h *CampaignsHandler) Get(c echo.Context) error {
+	campaign, err := h.campaigns.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return campaignError(c, err, "failed to get campaign")
+	}
+	return Success(c, http.StatusOK, "campaign retrieved", campaign)
+}
+
+// Recipients handles GET /admin/campaigns/:id/recipients requests.
+func (h *CampaignsHandler) Recipients(c echo.Context) error {
+	recipients, err := h.campaigns.Recipients(
+		c.Request().Context(),
+		c.Param("id"),
+		c.QueryParam("status"),
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		return campaignError(c, err, "failed to list campaign recipients")
+	}
+	return Success(c, http.StatusOK, "campaign recipients retrieved", recipients)
+}
+
+// Send handles POST /admin/campaigns/:id/send requests.
+func (h *CampaignsHandler) Send(c echo.Context) error {
+	campaign, err := h.campaigns.Send(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return campaignError(c, err, "failed to send campaign")
+	}
+	return Success(c, http.StatusAccepted, "campaign queued", campaign)
+}
+
+// Open handles GET /campaigns/open/:token requests from the tracking pixel. The pixel is served
+// whatever the token, so the link reveals nothing about it.
+func (h *CampaignsHandler) Open(c echo.Context) error {
+	if err := h.campaigns.TrackOpen(c.Request().Context(), c.Param("token")); err != nil && !errors.Is(err, service.ErrCampaignTokenNotFound) {
+		c.Logger().Errorf("failed to track campaign open: %v", err)
+	}
+	c.Response().Header().Set("Cache-Control", "no-store, max-age=0")
+	return c.Blob(http.StatusOK, "image/gif", trackingPixel)
+}
+
+// UnsubscribePage handles GET /campaigns/unsubscribe/:token requests with a confirmation form, so
+// link scanners that follow every URL do not unsubscribe recipients.
+func (h *CampaignsHandler) UnsubscribePage(c echo.Context) error {
+	return c.HTML(http.StatusOK, unsubscribePage(
+		"Unsubscribe",
+		`<form method="post"><p>Stop receiving these e-mails?</p><button type="submit">Unsubscribe</button></form>`,
+	))
+}
+
+// Unsubscribe handles POST /campaigns/unsubscribe/:token requests, from the confirmation form and
+// from the one-click List-Unsubscribe-Post header.
+func (h *CampaignsHandler) Unsubscribe(c echo.Context) error {
+	if err := h.campaigns.Unsubscribe(c.Request().Context(), c.Param("token")); err != nil {
+		if errors.Is(err, service.ErrCampaignTokenNotFound) {
+			return c.HTML(http.StatusNotFound, unsubscribePage("Link not found", "<p>This unsubscribe link is not valid.</p>"))
+		}
+		return c.HTML(http.StatusInternalServerError, unsubscribePage("Something went wrong", "<p>Please try again later.</p>"))
+	}
+	return c.HTML(http.StatusOK, unsubscribePage("Unsubscribed", "<p>You will not receive these e-mails anymore.</p>"))
+}
+
+// unsubscribePage renders a minimal page with an escaped title and a trusted body.
+func unsubscribePage(title, body string) string {
+	title = html.EscapeString(title)
+	return `<!doctype html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width">` +
+		`<title>` + title + `</title></head><body><h1>` + title + `</h1>` + body + `</body></html>`
+}
+
+// Webhook handles POST /campaigns/webhooks/:provider requests carrying delivery events.
+func (h *CampaignsHandler) Webhook(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBody))
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	applied, err := h.campaigns.HandleWebhook(c.Request().Context(), c.Param("provider"), c.Request().Header, body)
+	if err != nil {
+		return campaignError(c, err, "failed to record delivery events")
+	}
+	return Success(c, http.StatusOK, "delivery events recorded", map[string]int{"applied": applied})
+}
+
+// campaignError writes the response of a failed campaign request.
+func campaignError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCampaign), errors.Is(err, service.ErrInvalidCampaignID),
+		errors.Is(err, service.ErrCampaignFilterRequired), errors.Is(err, service.ErrCampaignNoRecipients),
+		errors.Is(err, service.ErrCampaignTooLarge), errors.Is(err, service.ErrInvalidWebhookPayload):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidWebhookSignature):
+		return Error(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrCampaignNotFound), errors.Is(err, service.ErrCampaignWebhookUnavailable):
+		return Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCampaignNotDraft):
+		return Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrCampaignSenderUnavailable):
+		return Error(c, http.StatusNotImplemented, "campaign sending is not enabled")
+	default:
+		return queryError(c, err, fallback)
+	}
+}
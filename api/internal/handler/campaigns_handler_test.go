@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type campaignsRepoStub struct {
+	created *entity.Campaign
+}
+
+func (s *campaignsRepoStub) CreateCampaign(ctx context.Context, campaign *entity.Campaign) error {
+	campaign.ID, campaign.Status = uuid.New(), entity.CampaignStatusDraft
+	s.created = campaign
+	return nil
+}
+
+func (s *campaignsRepoStub) GetCampaign(ctx context.Context, id uuid.UUID) (*entity.Campaign, error) {
+	return nil, service.ErrCampaignNotFound
+}
+
+func (s *campaignsRepoStub) ListCampaigns(ctx context.Context, limit, offset int) ([]entity.Campaign, error) {
+	return nil, nil
+}
+
+func (s *campaignsRepoStub) QueueCampaign(ctx context.Context, id uuid.UUID, recipients []entity.CampaignRecipient) error {
+	return nil
+}
+
+func (s *campaignsRepoStub) ListCampaignRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]entity.CampaignRecipient, error) {
+	return nil, nil
+}
+
+func (s *campaignsRepoStub) ClaimCampaignDeliveries(ctx context.Context, limit int) ([]entity.CampaignDelivery, error) {
+	return nil, nil
+}
+
+func (s *campaignsRepoStub) MarkRecipientSent(ctx context.Context, id uuid.UUID, tokenHash, messageID string) error {
+	return nil
+}
+
+func (s *campaignsRepoStub) MarkRecipientFailed(ctx context.Context, id uuid.UUID, message string) error {
+	return nil
+}
+
+func (s *campaignsRepoStub) FinishCampaigns(ctx context.Context) (int64, error) { return 0, nil }
+
+func (s *campaignsRepoStub) MarkRecipientOpened(ctx context.Context, tokenHash string) (bool, error) {
+	return false, nil
+}
+
+func (s *campaignsRepoStub) MarkRecipientUndeliverable(ctx context.Context, tokenHash, status, reason string) (bool, error) {
+	return false, nil
+}
+
+func (s *campaignsRepoStub) SuppressEmail(ctx context.Context, suppression *entity.ContactSuppression) error {
+	return nil
+}
+
+func TestCampaignsHandler_Create(t *testing.T) {
+	e := echo.New()
+	repo := &campaignsRepoStub{}
+	handler := NewCampaignsHandler(service.NewCampaignService(repo, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/campaigns?city=Bandung", strings.NewReader(`{"name":"Launch","subject":"Hi {{owner}}","body":"Hello"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	_ = handler.Create(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "{{owner}}") {
+		t.Fatalf("expected an unknown variable to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/campaigns?city=Bandung", strings.NewReader(`{"name":"Launch","subject":"Hi {{company}}","body":"Hello"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	_ = handler.Create(e.NewContext(req, rec))
+	if rec.Code != http.StatusCreated || repo.created == nil || repo.created.Query != "city=Bandung" {
+		t.Fatalf("expected the campaign created with its query, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCampaignsHandler_SendWithoutProvider(t *testing.T) {
+	e := echo.New()
+	handler := NewCampaignsHandler(service.NewCampaignService(&campaignsRepoStub{}, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/campaigns/x/send", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(uuid.NewString())
+	_ = handler.Send(ctx)
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCampaignsHandler_PublicLinks(t *testing.T) {
+	e := echo.New()
+	handler := NewCampaignsHandler(service.NewCampaignService(&campaignsRepoStub{}, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns/open/unknown", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("unknown")
+	_ = handler.Open(ctx)
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != "image/gif" || rec.Body.Len() != len(trackingPixel) {
+		t.Fatalf("expected the pixel for any token, got %d %q", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/campaigns/unsubscribe/unknown", nil)
+	rec = httptest.NewRecorder()
+	ctx = e.NewContext(req, rec)
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("unknown")
+	_ = handler.Unsubscribe(ctx)
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "not valid") {
+		t.Fatalf("expected an unknown unsubscribe link to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/campaigns/webhooks/sendgrid", strings.NewReader(`[]`))
+	rec = httptest.NewRecorder()
+	ctx = e.NewContext(req, rec)
+	ctx.SetParamNames("provider")
+	ctx.SetParamValues("sendgrid")
+	_ = handler.Webhook(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected an unconfigured webhook to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
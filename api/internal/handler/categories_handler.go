@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// CategoriesHandler exposes the business category taxonomy: listing for every user and management
+// for admins.
+type CategoriesHandler struct {
+	categories *service.CategoryService
+}
+
+// NewCategoriesHandler constructs a handler instance.
+func NewCategoriesHandler(categories *service.CategoryService) *CategoriesHandler {
+	return &CategoriesHandler{categories: categories}
+}
+
+// List handles GET /categories requests: q keeps the categories whose name, label or a synonym
+// starts with it.
+func (h *CategoriesHandler) List(c echo.Context) error {
+	return Success(c, http.StatusOK, "categories retrieved", h.categories.List(c.QueryParam("q")))
+}
+
+// Create handles POST /admin/categories requests.
+func (h *CategoriesHandler) Create(c echo.Context) error {
+	var req dto.BusinessCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	category, err := h.categories.Create(c.Request().Context(), req)
+	if err != nil {
+		return businessCategoryError(c, err)
+	}
+	return Success(c, http.StatusCreated, "category created", category)
+}
+
+// Update handles PUT /admin/categories/:id requests.
+func (h *CategoriesHandler) Update(c echo.Context) error {
+	var req dto.BusinessCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	category, err := h.categories.Update(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return businessCategoryError(c, err)
+	}
+	return Success(c, http.StatusOK, "category updated", category)
+}
+
+// Delete handles DELETE /admin/categories/:id requests.
+func (h *CategoriesHandler) Delete(c echo.Context) error {
+	if err := h.categories.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return businessCategoryError(c, err)
+	}
+	return Success(c, http.StatusOK, "category deleted", nil)
+}
+
+func businessCategoryError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidBusinessCategory):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidBusinessCategoryID):
+		return Error(c, http.StatusBadRequest, "invalid category id")
+	case errors.Is(err, service.ErrBusinessCategoryNotFound):
+		return Error(c, http.StatusNotFound, "category not found")
+	case errors.Is(err, service.ErrBusinessCategoryExists):
+		return Error(c, http.StatusConflict, "category already exists")
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to save category")
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type businessCategoriesRepoStub struct {
+	categories []entity.BusinessCategory
+}
+
+func (s *businessCategoriesRepoStub) List(ctx context.Context) ([]entity.BusinessCategory, error) {
+	return s.categories, nil
+}
+
+func (s *businessCategoriesRepoStub) Create(ctx context.Context, category *entity.BusinessCategory) error {
+	for _, existing := range s.categories {
+		if existing.Name == category.Name {
+			return repository.ErrBusinessCategoryDuplicate
+		}
+	}
+	category.ID = uuid.New()
+	s.categories = append(s.categories, *category)
+	return nil
+}
+
+func (s *businessCategoriesRepoStub) Update(ctx context.Context, category *entity.BusinessCategory) error {
+	return repository.ErrBusinessCategoryNotFound
+}
+
+func (s *businessCategoriesRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrBusinessCategoryNotFound
+}
+
+func TestCategoriesHandler_List(t *testing.T) {
+	categories := service.NewCategoryService(&businessCategoriesRepoStub{categories: []entity.BusinessCategory{
+		{ID: uuid.New(), Name: "restaurant", Label: "Restaurant", Synonyms: []string{"resto", "rumah makan"}},
+		{ID: uuid.New(), Name: "cafe", Label: "Cafe", Synonyms: []string{"kafe"}},
+	}})
+	if err := categories.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+	h := NewCategoriesHandler(categories)
+
+	c, rec := testsupport.NewContext(http.MethodGet, "/categories?q=rumah")
+	if err := h.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var got []entity.BusinessCategory
+	testsupport.DecodeData(t, rec, &got)
+	if len(got) != 1 || got[0].Name != "restaurant" {
+		t.Fatalf("unexpected categories: %+v", got)
+	}
+}
+
+func TestCategoriesHandler_Admin(t *testing.T) {
+	h := NewCategoriesHandler(service.NewCategoryService(&businessCategoriesRepoStub{}))
+
+	tests := []struct {
+		name     string
+		call     func() (*httptest.ResponseRecorder, error)
+		wantCode int
+	}{
+		{"created", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/categories", map[string]any{"label": "Bakery", "synonyms": []string{"toko roti"}})
+			return rec, h.Create(c)
+		}, http.StatusCreated},
+		{"duplicate", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/categories", map[string]any{"name": "bakery", "label": "Bakeries"})
+			return rec, h.Create(c)
+		}, http.StatusConflict},
+		{"missing label", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/categories", map[string]any{"name": "bakery"})
+			return rec, h.Create(c)
+		}, http.StatusBadRequest},
+		{"invalid id", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPut, "/admin/categories/nope", map[string]any{"label": "Bakery"})
+			return rec, h.Update(testsupport.WithParams(c, "id", "nope"))
+		}, http.StatusBadRequest},
+		{"unknown", func() (*httptest.ResponseRecorder, error) {
+			id := uuid.NewString()
+			c, rec := testsupport.NewContext(http.MethodDelete, "/admin/categories/"+id)
+			return rec, h.Delete(testsupport.WithParams(c, "id", id))
+		}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testsupport.AssertStatus(t, rec, tt.wantCode)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ChainsHandler exposes franchise/chain grouping endpoints.
+type ChainsHandler struct {
+	chains *service.ChainService
+}
+
+// NewChainsHandler constructs a handler instance.
+func NewChainsHandler(chains *service.ChainService) *ChainsHandler {
+	return &ChainsHandler{chains: chains}
+}
+
+// List handles GET /chains requests.
+func (h *ChainsHandler) List(c echo.Context) error {
+	chains, err := h.chains.ListChains(
+		c.Request().Context(),
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list chains")
+	}
+	return Success(c, http.StatusOK, "chains retrieved", chains)
+}
+
+// Rollup handles GET /chains/:id requests.
+func (h *ChainsHandler) Rollup(c echo.Context) error {
+	rollup, err := h.chains.GetChainRollup(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidChainID):
+			return Error(c, http.StatusBadRequest, "invalid chain id")
+		case errors.Is(err, service.ErrChainNotFound):
+			return Error(c, http.StatusNotFound, "chain not found")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to load chain")
+		}
+	}
+	return Success(c, http.StatusOK, "chain retrieved", rollup)
+}
+
+// Detect handles POST /admin/chains/detect requests.
+func (h *ChainsHandler) Detect(c echo.Context) error {
+	minCities := parseIntDefault(c.QueryParam("min_cities"), service.DefaultChainMinCities)
+	if minCities < 2 {
+		return Error(c, http.StatusBadRequest, "min_cities must be at least 2")
+	}
+
+	result, err := h.chains.DetectChains(c.Request().Context(), minCities)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to detect chains")
+	}
+	return Success(c, http.StatusOK, "chains detected", result)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type chainsRepoStub struct {
+	minCities int
+	rollupErr error
+}
+
+func (s *chainsRepoStub) DetectChains(ctx context.Context, minCities int, sharedHosts []string) (repository.ChainDetectionResult, error) {
+	s.minCities = minCities
+	return repository.ChainDetectionResult{Chains: 1}, nil
+}
+
+func (s *chainsRepoStub) ListChains(ctx context.Context, limit, offset int) ([]entity.CompanyChain, error) {
+	return []entity.CompanyChain{{Name: "Acme"}}, nil
+}
+
+func (s *chainsRepoStub) GetChainRollup(ctx context.Context, id uuid.UUID) (*entity.ChainRollup, error) {
+	if s.rollupErr != nil {
+		return nil, s.rollupErr
+	}
+	return &entity.ChainRollup{Chain: entity.CompanyChain{ID: id, Name: "Acme"}}, nil
+}
+
+func TestChainsHandler_Rollup(t *testing.T) {
+	e := echo.New()
+
+	cases := []struct {
+		name   string
+		id     string
+		repo   *chainsRepoStub
+		status int
+	}{
+		{name: "success", id: "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", repo: &chainsRepoStub{}, status: http.StatusOK},
+		{name: "invalid id", id: "nope", repo: &chainsRepoStub{}, status: http.StatusBadRequest},
+		{name: "not found", id: "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", repo: &chainsRepoStub{rollupErr: repository.ErrChainNotFound}, status: http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewChainsHandler(service.NewChainService(tc.repo))
+			req := httptest.NewRequest(http.MethodGet, "/chains/"+tc.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.id)
+
+			_ = handler.Rollup(c)
+			if rec.Code != tc.status {
+				t.Fatalf("expected %d, got %d", tc.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestChainsHandler_Detect(t *testing.T) {
+	e := echo.New()
+	repo := &chainsRepoStub{}
+	handler := NewChainsHandler(service.NewChainService(repo))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/chains/detect?min_cities=5", nil)
+	rec := httptest.NewRecorder()
+	_ = handler.Detect(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if repo.minCities != 5 {
+		t.Fatalf("expected min_cities forwarded, got %d", repo.minCities)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/chains/detect?min_cities=1", nil)
+	rec = httptest.NewRecorder()
+	_ = handler.Detect(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ChangesHandler exposes the incremental sync feeds.
+type ChangesHandler struct {
+	feed      *service.ChangeFeedService
+	companies *service.CompanyChangesService
+}
+
+// NewChangesHandler constructs a handler instance.
+func NewChangesHandler(feed *service.ChangeFeedService, companies *service.CompanyChangesService) *ChangesHandler {
+	return &ChangesHandler{feed: feed, companies: companies}
+}
+
+// List handles GET /changes requests.
+func (h *ChangesHandler) List(c echo.Context) error {
+	page, err := h.feed.Changes(
+		c.Request().Context(),
+		c.QueryParam("since_cursor"),
+		parseIntDefault(c.QueryParam("limit"), 0),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidChangeCursor) {
+			return Error(c, http.StatusBadRequest, "invalid since_cursor")
+		}
+		return Error(c, http.StatusInternalServerError, "failed to list changes")
+	}
+	return Success(c, http.StatusOK, "changes retrieved", page)
+}
+
+// Companies handles GET /companies/changes requests.
+func (h *ChangesHandler) Companies(c echo.Context) error {
+	page, err := h.companies.Changes(
+		c.Request().Context(),
+		c.QueryParam("since"),
+		parseIntDefault(c.QueryParam("limit"), 0),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidChangeCursor) {
+			return Error(c, http.StatusBadRequest, "invalid since cursor")
+		}
+		return Error(c, http.StatusInternalServerError, "failed to list company changes")
+	}
+	return Success(c, http.StatusOK, "company changes retrieved", page)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type changeEventsRepoStub struct{}
+
+func (s *changeEventsRepoStub) ListChangesAfter(ctx context.Context, after entity.ChangeCursor, limit int) ([]entity.ChangeEvent, error) {
+	return []entity.ChangeEvent{{
+		Cursor:    entity.ChangeCursor{TxID: after.TxID + 1, ID: after.ID + 1},
+		Entity:    entity.ChangeEntityEnrichment,
+		EntityID:  uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"),
+		Operation: entity.ChangeOperationCreate,
+		Data:      json.RawMessage(`{"emails":["info@example.com"]}`),
+	}}, nil
+}
+
+func TestChangesHandler_List(t *testing.T) {
+	e := echo.New()
+	handler := NewChangesHandler(service.NewChangeFeedService(&changeEventsRepoStub{}), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/changes?since_cursor=5.40", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Data service.ChangeFeedPage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if payload.Data.NextCursor != "6.41" || len(payload.Data.Events) != 1 || payload.Data.Events[0].Entity != "enrichment" {
+		t.Fatalf("unexpected page: %+v", payload.Data)
+	}
+}
+
+func TestChangesHandler_List_InvalidCursor(t *testing.T) {
+	e := echo.New()
+	handler := NewChangesHandler(service.NewChangeFeedService(&changeEventsRepoStub{}), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/changes?since_cursor=latest", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+type companyChangesRepoStub struct{}
+
+func (s *companyChangesRepoStub) ListCompanyChanges(ctx context.Context, after entity.CompanyChangeCursor, settle time.Duration, limit int) ([]entity.CompanyChange, error) {
+	id := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	changedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []entity.CompanyChange{{
+		Cursor:    entity.CompanyChangeCursor{ChangedAt: changedAt, ID: id},
+		Operation: entity.ChangeOperationDelete,
+		CompanyID: id,
+		ChangedAt: changedAt,
+	}}, nil
+}
+
+func TestChangesHandler_Companies(t *testing.T) {
+	e := echo.New()
+	handler := NewChangesHandler(nil, service.NewCompanyChangesService(&companyChangesRepoStub{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/companies/changes", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.Companies(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Data service.CompanyChangesPage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(payload.Data.Changes) != 1 || payload.Data.Changes[0].Operation != "delete" || payload.Data.NextCursor != "1714564800000000_aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa" {
+		t.Fatalf("unexpected page: %+v", payload.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies/changes?since=yesterday", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.Companies(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
@@ -1,6 +1,11 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,9 +15,22 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/service/legalform"
+	"github.com/octobees/leads-generator/api/internal/service/outreach"
+	"github.com/octobees/leads-generator/api/internal/service/placeattrs"
+	"github.com/octobees/leads-generator/api/internal/service/sizing"
+	"github.com/octobees/leads-generator/api/internal/service/techstack"
 )
 
+// companyFields are the names accepted by the fields param of company listings.
+var companyFields = jsonFieldNames(entity.Company{})
+
+// companyIncludes are the optional, heavier parts a listing loads through the include param.
+var companyIncludes = map[string]bool{"raw": true, "enrichment_summary": true}
+
 // CompaniesHandler exposes company catalogue endpoints.
 type CompaniesHandler struct {
 	service *service.CompaniesService
@@ -34,6 +52,254 @@ func (h *CompaniesHandler) ListAdmin(c echo.Context) error {
 }
 
 func (h *CompaniesHandler) listInternal(c echo.Context, latestOnly bool) error {
+	filter, err := parseListFilter(c, latestOnly)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	fields, err := parseFields("fields", c.QueryParam("fields"), companyFields)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	includes, err := parseFields("include", c.QueryParam("include"), companyIncludes)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	// Selecting raw or enrichment_summary through fields implies loading it.
+	filter.IncludeRaw = includes["raw"] || fields["raw"]
+	filter.IncludeEnrichmentSummary = includes["enrichment_summary"] || fields["enrichment_summary"]
+
+	withFacets := false
+	if facetsParam := strings.TrimSpace(c.QueryParam("facets")); facetsParam != "" {
+		parsed, err := strconv.ParseBool(facetsParam)
+		if err != nil {
+			return Error(c, http.StatusBadRequest, "invalid facets (use true or false)")
+		}
+		withFacets = parsed
+	}
+
+	ctx := c.Request().Context()
+	companies, err := h.service.ListCompanies(ctx, filter)
+	if err != nil {
+		return queryError(c, err, "failed to list companies")
+	}
+	data, err := selectFieldsEach(companies, fields)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to encode companies")
+	}
+	if !withFacets {
+		return SuccessWithETag(c, http.StatusOK, "companies retrieved", data)
+	}
+
+	facets, err := h.service.CompanyFacets(ctx, filter)
+	if err != nil {
+		if errors.Is(err, service.ErrFacetsUnavailable) {
+			return Error(c, http.StatusNotImplemented, "facets are not enabled")
+		}
+		return queryError(c, err, "failed to compute facets")
+	}
+	return SuccessWithETag(c, http.StatusOK, "companies retrieved", map[string]any{
+		"companies": data,
+		"facets":    facets,
+	})
+}
+
+// Explain handles GET /admin/companies/explain requests. It takes the filters of GET
+// /admin/companies and answers with the plan of the listing they run, naming the indexes it reads
+// and the tables it scans in full; analyze=true runs the listing to report actual rows and timings.
+func (h *CompaniesHandler) Explain(c echo.Context) error {
+	filter, err := parseListFilter(c, false)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	includes, err := parseFields("include", c.QueryParam("include"), companyIncludes)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	filter.IncludeRaw = includes["raw"]
+	filter.IncludeEnrichmentSummary = includes["enrichment_summary"]
+
+	analyze := false
+	if raw := c.QueryParam("analyze"); raw != "" {
+		if analyze, err = strconv.ParseBool(raw); err != nil {
+			return Error(c, http.StatusBadRequest, "invalid analyze flag")
+		}
+	}
+
+	plan, err := h.service.ExplainCompanies(c.Request().Context(), filter, analyze)
+	if err != nil {
+		if errors.Is(err, service.ErrQueryPlansUnavailable) {
+			return Error(c, http.StatusNotImplemented, "query plans are not enabled")
+		}
+		return queryError(c, err, "failed to explain companies listing")
+	}
+	return Success(c, http.StatusOK, "query plan retrieved", plan)
+}
+
+// Raw handles GET /companies/:id/raw requests, returning the full stored Places payload.
+func (h *CompaniesHandler) Raw(c echo.Context) error {
+	raw, err := h.service.CompanyRaw(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCompanyID):
+			return Error(c, http.StatusBadRequest, "invalid company id")
+		case errors.Is(err, service.ErrCompanyNotFound):
+			return Error(c, http.StatusNotFound, "company not found")
+		case errors.Is(err, service.ErrRawPayloadUnavailable), errors.Is(err, service.ErrRawStoreNotConfigured):
+			return Error(c, http.StatusNotImplemented, "raw payloads are not enabled")
+		case errors.Is(err, service.ErrRawPayloadMissing):
+			return Error(c, http.StatusNotFound, "raw payload not found")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to fetch raw payload")
+		}
+	}
+	return SuccessWithETag(c, http.StatusOK, "raw payload retrieved", raw)
+}
+
+// History handles GET /companies/:id/history requests with the rating and reviews observed by
+// every scrape run of the company.
+func (h *CompaniesHandler) History(c echo.Context) error {
+	history, err := h.service.CompanyHistory(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCompanyID):
+			return Error(c, http.StatusBadRequest, "invalid company id")
+		case errors.Is(err, service.ErrCompanyNotFound):
+			return Error(c, http.StatusNotFound, "company not found")
+		case errors.Is(err, service.ErrHistoryUnavailable):
+			return Error(c, http.StatusNotImplemented, "company history is not enabled")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to fetch company history")
+		}
+	}
+	return Success(c, http.StatusOK, "company history retrieved", history)
+}
+
+// Export handles GET /exports/companies requests by streaming the companies matching the listing
+// filter as CSV. Every row carries an exported_by stamp naming the caller, and the row count is
+// capped per role; admins export all data while other users only see the latest run. The template,
+// delimiter, decimal_mark, date_format and bom params pick the locale of the file. Companies on the
+// do-not-contact list are left out unless include_suppressed=true. Requests accepting
+// application/x-ndjson get the same rows as JSON Lines, one company object per line.
+func (h *CompaniesHandler) Export(c echo.Context) error {
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	filter, err := parseListFilter(c, role != "admin")
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	format := csvExport(h.service.WriteCompanyExportCSV)
+	if acceptsNDJSON(c) {
+		format = ndjsonExport(h.service.WriteCompanyExportNDJSON)
+	}
+	return h.streamExport(c, "companies", filter, h.service.PrepareCompanyExport, format)
+}
+
+// ndjsonContentType is the media type of JSON Lines exports.
+const ndjsonContentType = "application/x-ndjson"
+
+// exportFormat is how an export is streamed: its content type, file extension and writer.
+type exportFormat struct {
+	contentType string
+	extension   string
+	write       func(context.Context, *service.CompanyExport, service.ExportStamp, service.ExportLocale, io.Writer) error
+}
+
+// csvExport streams an export as CSV with write.
+func csvExport(write func(context.Context, *service.CompanyExport, service.ExportStamp, service.ExportLocale, io.Writer) error) exportFormat {
+	return exportFormat{contentType: "text/csv", extension: "csv", write: write}
+}
+
+// ndjsonExport streams an export as JSON Lines with write. The CSV locale does not apply to it.
+func ndjsonExport(write func(context.Context, *service.CompanyExport, service.ExportStamp, io.Writer) error) exportFormat {
+	return exportFormat{contentType: ndjsonContentType, extension: "ndjson", write: func(ctx context.Context, export *service.CompanyExport, stamp service.ExportStamp, _ service.ExportLocale, w io.Writer) error {
+		return write(ctx, export, stamp, w)
+	}}
+}
+
+// acceptsNDJSON reports whether the Accept header of the request asks for JSON Lines.
+func acceptsNDJSON(c echo.Context) bool {
+	for _, accept := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), ndjsonContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamExport checks an export of filter with prepare and streams it as an attachment named after
+// name in format, in the locale picked by the query.
+func (h *CompaniesHandler) streamExport(
+	c echo.Context,
+	name string,
+	filter dto.ListFilter,
+	prepare func(context.Context, dto.ListFilter, string) (*service.CompanyExport, error),
+	format exportFormat,
+) error {
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	localeQuery, err := parseExportLocaleQuery(c)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	if raw := strings.TrimSpace(c.QueryParam("include_suppressed")); raw != "" {
+		filter.IncludeSuppressed, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Error(c, http.StatusBadRequest, "invalid include_suppressed (use true or false)")
+		}
+	}
+
+	ctx := c.Request().Context()
+	locale, err := h.service.ResolveExportLocale(ctx, userID, localeQuery)
+	if err != nil {
+		return exportTemplateError(c, err, "failed to load export template")
+	}
+	export, err := prepare(ctx, filter, role)
+	if err != nil {
+		var capErr service.ExportCapError
+		switch {
+		case errors.As(err, &capErr):
+			return c.JSON(http.StatusUnprocessableEntity, APIResponse{
+				Status:  "error",
+				Message: capErr.Error(),
+				Data:    map[string]int64{"matched": capErr.Matched, "cap": capErr.Cap},
+			})
+		case errors.Is(err, service.ErrExportsUnavailable), errors.Is(err, service.ErrNoWebsiteLeadsUnavailable):
+			return Error(c, http.StatusNotImplemented, "exports are not enabled")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to prepare export")
+		}
+	}
+
+	stamp := newExportStamp(c)
+	filename := fmt.Sprintf("%s-%s.%s", name, stamp.At.Format("20060102"), format.extension)
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, format.contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure can only be logged.
+	if err := format.write(ctx, export, stamp, locale, res); err != nil {
+		log.Printf("request_id=%s %s export %s failed: %v", middlewarepkg.RequestIDFromContext(c), name, stamp.ID, err)
+	}
+	return nil
+}
+
+// newExportStamp stamps an export by the authenticated caller, announces it in the X-Export-ID
+// header and logs it so a leaked file can be matched to the request.
+func newExportStamp(c echo.Context) service.ExportStamp {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	email, _ := c.Get(middlewarepkg.ContextKeyUserEmail).(string)
+	stamp := service.NewExportStamp(userID, email, time.Now())
+	c.Response().Header().Set("X-Export-ID", stamp.ID.String())
+	log.Printf("request_id=%s company data exported by %s", middlewarepkg.RequestIDFromContext(c), stamp)
+	return stamp
+}
+
+// parseListFilter reads the company listing filter from query params. latestOnly restricts it to the
+// latest scrape run, as the public listing does. Errors are meant for a 400 response.
+func parseListFilter(c echo.Context, latestOnly bool) (dto.ListFilter, error) {
 	filter := dto.ListFilter{
 		Q:            strings.TrimSpace(c.QueryParam("q")),
 		TypeBusiness: strings.TrimSpace(c.QueryParam("type_business")),
@@ -52,6 +318,14 @@ func (h *CompaniesHandler) listInternal(c echo.Context, latestOnly bool) error {
 		}
 	}
 
+	if scoreWeightStr := strings.TrimSpace(c.QueryParam("score_weight")); scoreWeightStr != "" {
+		scoreWeight, err := strconv.ParseFloat(scoreWeightStr, 64)
+		if err != nil || !(scoreWeight >= 0 && scoreWeight <= 1) {
+			return filter, errors.New("invalid score_weight (use a number between 0 and 1)")
+		}
+		filter.ScoreWeight = &scoreWeight
+	}
+
 	if latestOnly {
 		filter.LatestRunOnly = true
 		if filter.Sort == "" {
@@ -62,25 +336,154 @@ func (h *CompaniesHandler) listInternal(c echo.Context, latestOnly bool) error {
 	if runIDParam := strings.TrimSpace(c.QueryParam("scrape_run_id")); runIDParam != "" {
 		parsed, err := uuid.Parse(runIDParam)
 		if err != nil {
-			return Error(c, http.StatusBadRequest, "invalid scrape_run_id")
+			return filter, errors.New("invalid scrape_run_id")
 		}
 		filter.ScrapeRunID = &parsed
 	}
 
+	if isChainParam := strings.TrimSpace(c.QueryParam("is_chain")); isChainParam != "" {
+		isChain, err := strconv.ParseBool(isChainParam)
+		if err != nil {
+			return filter, errors.New("invalid is_chain (use true or false)")
+		}
+		filter.IsChain = &isChain
+	}
+
+	if chainIDParam := strings.TrimSpace(c.QueryParam("chain_id")); chainIDParam != "" {
+		parsed, err := uuid.Parse(chainIDParam)
+		if err != nil {
+			return filter, errors.New("invalid chain_id")
+		}
+		filter.ChainID = &parsed
+	}
+
+	if sizeParam := strings.TrimSpace(c.QueryParam("size")); sizeParam != "" {
+		for _, raw := range strings.Split(sizeParam, ",") {
+			bucket, ok := sizing.NormalizeBucket(raw)
+			if !ok {
+				return filter, fmt.Errorf("invalid size %q (use %s)", strings.TrimSpace(raw), strings.Join(sizing.Buckets, ", "))
+			}
+			filter.SizeBuckets = append(filter.SizeBuckets, bucket)
+		}
+	}
+
+	if languageParam := strings.TrimSpace(c.QueryParam("outreach_language")); languageParam != "" {
+		for _, raw := range strings.Split(languageParam, ",") {
+			language, ok := outreach.NormalizeFilter(raw)
+			if !ok {
+				return filter, fmt.Errorf("invalid outreach_language %q (use %s or %s)", strings.TrimSpace(raw), strings.Join(outreach.Languages, ", "), outreach.Unknown)
+			}
+			filter.OutreachLanguages = append(filter.OutreachLanguages, language)
+		}
+	}
+
+	if legalFormParam := strings.TrimSpace(c.QueryParam("legal_form")); legalFormParam != "" {
+		for _, raw := range strings.Split(legalFormParam, ",") {
+			form, ok := legalform.NormalizeFilter(raw)
+			if !ok {
+				return filter, fmt.Errorf("invalid legal_form %q (use %s or %s)", strings.TrimSpace(raw), strings.Join(legalform.Forms, ", "), legalform.None)
+			}
+			filter.LegalForms = append(filter.LegalForms, form)
+		}
+	}
+
+	if techParam := strings.TrimSpace(c.QueryParam("tech")); techParam != "" {
+		for _, raw := range strings.Split(techParam, ",") {
+			technology, ok := techstack.NormalizeFilter(raw)
+			if !ok {
+				return filter, fmt.Errorf("invalid tech %q (use %s or %s)", strings.TrimSpace(raw), strings.Join(techstack.Technologies, ", "), techstack.None)
+			}
+			filter.Technologies = append(filter.Technologies, technology)
+		}
+	}
+
+	if openNowParam := strings.TrimSpace(c.QueryParam("open_now")); openNowParam != "" {
+		openNow, err := strconv.ParseBool(openNowParam)
+		if err != nil {
+			return filter, errors.New("invalid open_now (use true or false)")
+		}
+		filter.OpenNow = &openNow
+	}
+
+	if priceParam := strings.TrimSpace(c.QueryParam("price_level")); priceParam != "" {
+		for _, raw := range strings.Split(priceParam, ",") {
+			level, ok := placeattrs.ParsePriceLevel(raw)
+			if !ok {
+				return filter, fmt.Errorf("invalid price_level %q (use 0 to %d or $ to $$$$)", strings.TrimSpace(raw), placeattrs.MaxPriceLevel)
+			}
+			filter.PriceLevels = append(filter.PriceLevels, level)
+		}
+	}
+
+	if categoryParam := strings.TrimSpace(c.QueryParam("category")); categoryParam != "" {
+		for _, raw := range strings.Split(categoryParam, ",") {
+			if category := placeattrs.NormalizeCategory(raw); category != "" {
+				filter.Categories = append(filter.Categories, category)
+			}
+		}
+	}
+
+	// An email address is at most 320 characters; anything longer cannot match a contact.
+	if filter.QContact = strings.TrimSpace(c.QueryParam("q_contact")); len(filter.QContact) > 320 {
+		return filter, errors.New("invalid q_contact (use at most 320 characters)")
+	}
+
+	if websiteStatusParam := strings.TrimSpace(c.QueryParam("website_status")); websiteStatusParam != "" {
+		for _, raw := range strings.Split(websiteStatusParam, ",") {
+			status, ok := service.NormalizeWebsiteStatus(raw)
+			if !ok {
+				return filter, fmt.Errorf("invalid website_status %q (use live, dead, parked, redirected or unchecked)", strings.TrimSpace(raw))
+			}
+			filter.WebsiteLiveness = append(filter.WebsiteLiveness, status)
+		}
+	}
+
+	if sharedContactParam := strings.TrimSpace(c.QueryParam("shared_contact")); sharedContactParam != "" {
+		sharedContact, err := strconv.ParseBool(sharedContactParam)
+		if err != nil {
+			return filter, errors.New("invalid shared_contact (use true or false)")
+		}
+		filter.SharedContact = &sharedContact
+	}
+
+	if assignedParam := strings.TrimSpace(c.QueryParam("assigned_to")); assignedParam != "" {
+		// Assignments are internal, so only signed-in callers filter on them.
+		callerID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+		if callerID == "" {
+			return filter, errors.New("assigned_to needs an authenticated request")
+		}
+		switch {
+		case strings.EqualFold(assignedParam, "none"):
+			filter.Unassigned = true
+		case strings.EqualFold(assignedParam, service.AssignToCaller):
+			assignedParam = callerID
+			fallthrough
+		default:
+			parsed, err := uuid.Parse(assignedParam)
+			if err != nil {
+				return filter, errors.New("invalid assigned_to (use me, none or a user id)")
+			}
+			filter.AssignedTo = &parsed
+		}
+	}
+
+	if statusParam := strings.TrimSpace(c.QueryParam("status")); statusParam != "" {
+		status, ok := service.NormalizeBusinessStatus(statusParam)
+		if !ok {
+			return filter, errors.New("invalid status (use operational, closed, closed_temporarily, closed_permanently or all)")
+		}
+		filter.BusinessStatus = status
+	}
+
 	if updatedSinceStr := strings.TrimSpace(c.QueryParam("updated_since")); updatedSinceStr != "" {
 		parsed, err := time.Parse(time.RFC3339, updatedSinceStr)
 		if err != nil {
-			return Error(c, http.StatusBadRequest, "invalid updated_since (use RFC3339)")
+			return filter, errors.New("invalid updated_since (use RFC3339)")
 		}
 		filter.UpdatedSince = &parsed
 	}
 
-	companies, err := h.service.ListCompanies(c.Request().Context(), filter)
-	if err != nil {
-		return Error(c, http.StatusInternalServerError, "failed to list companies")
-	}
-
-	return Success(c, http.StatusOK, "companies retrieved", companies)
+	return filter, nil
 }
 
 func parseIntDefault(input string, fallback int) int {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,8 +14,11 @@ import (
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 	"github.com/octobees/leads-generator/api/internal/repository"
 	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/storage"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
 )
 
 type capturingCompaniesRepo struct {
@@ -30,7 +34,7 @@ func (c *capturingCompaniesRepo) List(ctx context.Context, filter dto.ListFilter
 	return []entity.Company{{Company: "Acme"}}, nil
 }
 
-func (c *capturingCompaniesRepo) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+func (c *capturingCompaniesRepo) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput, mode repository.ImportMode) (repository.BulkUpsertResult, error) {
 	return repository.BulkUpsertResult{}, nil
 }
 
@@ -193,6 +197,672 @@ func TestCompaniesHandler_List_InvalidScrapeRunID(t *testing.T) {
 	}
 }
 
+func TestCompaniesHandler_List_IsChainFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?is_chain=false", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastFilter.IsChain == nil || *repo.lastFilter.IsChain {
+		t.Fatalf("expected is_chain=false parsed, got %+v", repo.lastFilter.IsChain)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?is_chain=maybe", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid is_chain, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_SizeFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?size=Small,%20medium", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.lastFilter.SizeBuckets) != 2 || repo.lastFilter.SizeBuckets[0] != "small" || repo.lastFilter.SizeBuckets[1] != "medium" {
+		t.Fatalf("expected size buckets parsed, got %+v", repo.lastFilter.SizeBuckets)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?size=huge", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid size, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_OutreachLanguageFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?outreach_language=id-ID,%20Unknown", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.OutreachLanguages; len(got) != 2 || got[0] != "id" || got[1] != "unknown" {
+		t.Fatalf("expected outreach languages parsed, got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?outreach_language=klingon", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid outreach_language, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_LegalFormFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?legal_form=p.t.,%20None", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.LegalForms; len(got) != 2 || got[0] != "PT" || got[1] != "none" {
+		t.Fatalf("expected legal forms parsed, got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?legal_form=gmbh", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid legal_form, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_WebsiteStatusFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?website_status=Dead,%20parked", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.WebsiteLiveness; len(got) != 2 || got[0] != "dead" || got[1] != "parked" {
+		t.Fatalf("expected website statuses parsed, got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?website_status=offline", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid website_status, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_TechFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?tech=WordPress,%20gtm,none", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.Technologies; len(got) != 3 || got[0] != "wordpress" || got[1] != "google-tag-manager" || got[2] != "none" {
+		t.Fatalf("expected technologies parsed, got %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?tech=frontpage", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := handler.List(c); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid tech, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_PlaceAttributeFilters(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?open_now=true&price_level=1,$$&category=Coffee%20Shop,cafe", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter := repo.lastFilter
+	if filter.OpenNow == nil || !*filter.OpenNow {
+		t.Fatalf("expected open_now=true parsed, got %v", filter.OpenNow)
+	}
+	if got := filter.PriceLevels; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected price levels parsed, got %v", got)
+	}
+	if got := filter.Categories; len(got) != 2 || got[0] != "coffee_shop" || got[1] != "cafe" {
+		t.Fatalf("expected categories parsed, got %v", got)
+	}
+
+	for _, query := range []string{"open_now=soon", "price_level=5"} {
+		req = httptest.NewRequest(http.MethodGet, "/companies?"+query, nil)
+		rec = httptest.NewRecorder()
+		if err := handler.List(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("expected handler to write response")
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestCompaniesHandler_List_ContactSearch(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?q_contact=%20Sales@Kopi.co.id%20", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.QContact; got != "Sales@Kopi.co.id" {
+		t.Fatalf("expected q_contact parsed, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?q_contact="+strings.Repeat("a", 321), nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an overlong q_contact, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_SharedContactFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?shared_contact=false", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.SharedContact; got == nil || *got {
+		t.Fatalf("expected shared_contact=false parsed, got %v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?shared_contact=maybe", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid shared_contact, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_AssignedToFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+	callerID := uuid.New()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?assigned_to=me", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an anonymous assigned_to, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?assigned_to=me", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middlewarepkg.ContextKeyUserID, callerID.String())
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.lastFilter.AssignedTo; got == nil || *got != callerID {
+		t.Fatalf("expected assigned_to=me to resolve to the caller, got %v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?assigned_to=none", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(middlewarepkg.ContextKeyUserID, callerID.String())
+	if err := handler.List(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.lastFilter.Unassigned || repo.lastFilter.AssignedTo != nil {
+		t.Fatalf("expected assigned_to=none to keep unassigned companies, got %+v", repo.lastFilter)
+	}
+}
+
+func TestCompaniesHandler_List_ScoreWeight(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?q=kopi&sort=relevance&score_weight=0.6", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastFilter.Sort != "relevance" || repo.lastFilter.ScoreWeight == nil || *repo.lastFilter.ScoreWeight != 0.6 {
+		t.Fatalf("expected relevance sort with score_weight 0.6, got %q %v", repo.lastFilter.Sort, repo.lastFilter.ScoreWeight)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?q=kopi&score_weight=2", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid score_weight, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_StatusFilter(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastFilter.BusinessStatus != service.BusinessStatusOperational {
+		t.Fatalf("expected operational-only by default, got %q", repo.lastFilter.BusinessStatus)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?status=CLOSED_PERMANENTLY", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastFilter.BusinessStatus != service.BusinessStatusClosedPermanently {
+		t.Fatalf("expected closed_permanently filter, got %q", repo.lastFilter.BusinessStatus)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?status=open", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid status, got %d", rec.Code)
+	}
+}
+
+type stubFacetsRepo struct {
+	lastFilter dto.ListFilter
+}
+
+func (s *stubFacetsRepo) Facets(ctx context.Context, filter dto.ListFilter, limit int) (*entity.CompanyFacets, error) {
+	s.lastFilter = filter
+	return &entity.CompanyFacets{Cities: []entity.StatBucket{{Name: "Jakarta", Companies: 3}}}, nil
+}
+
+type stubPlansRepo struct {
+	lastFilter dto.ListFilter
+	analyze    bool
+}
+
+func (s *stubPlansRepo) ExplainList(ctx context.Context, filter dto.ListFilter, analyze bool) (*entity.QueryPlan, error) {
+	s.lastFilter, s.analyze = filter, analyze
+	return &entity.QueryPlan{SQL: "SELECT 1", Indexes: []string{"idx_leads_view_city_type_rating"}}, nil
+}
+
+func TestCompaniesHandler_Explain(t *testing.T) {
+	plans := &stubPlansRepo{}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}, service.WithQueryPlans(plans)))
+
+	e := echo.New()
+	explain := func(handler *CompaniesHandler, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		if err := handler.Explain(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/companies/explain?"+query, nil), rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := explain(handler, "city=Jakarta&type_business=cafe&website=missing&analyze=true")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "idx_leads_view_city_type_rating") {
+		t.Fatalf("expected the plan, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !plans.analyze || plans.lastFilter.City != "Jakarta" || plans.lastFilter.WebsiteStatus != "missing" || plans.lastFilter.LatestRunOnly || plans.lastFilter.PerPage != 20 {
+		t.Fatalf("expected the admin listing filter explained, got %+v (analyze=%v)", plans.lastFilter, plans.analyze)
+	}
+
+	if rec := explain(handler, "analyze=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid analyze flag, got %d", rec.Code)
+	}
+	if rec := explain(newCompaniesHandler(&capturingCompaniesRepo{}), ""); rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without a plans repository, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_Facets(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	facets := &stubFacetsRepo{}
+	handler := NewCompaniesHandler(service.NewCompaniesService(repo, service.WithFacets(facets)))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?city=Jakarta&facets=true", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if facets.lastFilter.City != "Jakarta" || facets.lastFilter.BusinessStatus != service.BusinessStatusOperational {
+		t.Fatalf("expected facets to share the listing filter, got %+v", facets.lastFilter)
+	}
+
+	var payload struct {
+		Data struct {
+			Companies []entity.Company     `json:"companies"`
+			Facets    entity.CompanyFacets `json:"facets"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(payload.Data.Companies) != 1 || len(payload.Data.Facets.Cities) != 1 {
+		t.Fatalf("unexpected payload: %+v", payload.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?facets=maybe", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid facets, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_FacetsUnavailable(t *testing.T) {
+	handler := newCompaniesHandler(&capturingCompaniesRepo{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?facets=true", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without facets repository, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_NotModified(t *testing.T) {
+	handler := newCompaniesHandler(&capturingCompaniesRepo{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag header")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for unchanged listing, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_Fields(t *testing.T) {
+	handler := newCompaniesHandler(&capturingCompaniesRepo{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/companies?fields=company,%20Phone", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var payload struct {
+		Data []map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	// phone is omitted because the stub company has none; raw and timestamps must be trimmed.
+	if len(payload.Data) != 1 || len(payload.Data[0]) != 1 || string(payload.Data[0]["company"]) != `"Acme"` {
+		t.Fatalf("expected only selected fields, got %v", payload.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies?fields=company,score", nil)
+	rec = httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown field, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_IncludeRaw(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+	e := echo.New()
+
+	cases := map[string]bool{
+		"/companies":                    false,
+		"/companies?include=raw":        true,
+		"/companies?fields=company,raw": true,
+	}
+	for target, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		if err := handler.List(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.lastFilter.IncludeRaw != want {
+			t.Fatalf("%s: expected IncludeRaw %v, got %v", target, want, repo.lastFilter.IncludeRaw)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/companies?include=everything", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.List(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("expected handler to write response")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown include, got %d", rec.Code)
+	}
+}
+
+func TestCompaniesHandler_List_IncludeEnrichmentSummary(t *testing.T) {
+	repo := &capturingCompaniesRepo{}
+	handler := newCompaniesHandler(repo)
+	e := echo.New()
+
+	cases := map[string]bool{
+		"/companies": false,
+		"/companies?include=raw,enrichment_summary":    true,
+		"/companies?fields=company,enrichment_summary": true,
+	}
+	for target, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		if err := handler.List(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusOK || repo.lastFilter.IncludeEnrichmentSummary != want {
+			t.Fatalf("%s: expected IncludeEnrichmentSummary %v, got %v (%d)", target, want, repo.lastFilter.IncludeEnrichmentSummary, rec.Code)
+		}
+	}
+}
+
+type stubRawRepo struct{}
+
+func (s *stubRawRepo) GetCompanyRaw(ctx context.Context, companyID uuid.UUID) (repository.CompanyRaw, error) {
+	switch companyID.String() {
+	case "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa":
+		return repository.CompanyRaw{Payload: json.RawMessage(`{"place_id":"abc"}`)}, nil
+	case "cccccccc-cccc-cccc-cccc-cccccccccccc":
+		return repository.CompanyRaw{Payload: json.RawMessage(`{}`), Ref: "raw/def/manual.json"}, nil
+	case "dddddddd-dddd-dddd-dddd-dddddddddddd":
+		return repository.CompanyRaw{Payload: json.RawMessage(`{}`), Ref: "raw/gone/manual.json"}, nil
+	}
+	return repository.CompanyRaw{}, repository.ErrCompanyNotFound
+}
+
+func (s *stubRawRepo) ListInlineRawPayloads(ctx context.Context, after uuid.UUID, limit int) ([]repository.InlineRawPayload, error) {
+	return nil, nil
+}
+
+func (s *stubRawRepo) MarkRawOffloaded(ctx context.Context, companyID uuid.UUID, ref string, payload json.RawMessage) (bool, error) {
+	return false, nil
+}
+
+func TestCompaniesHandler_Raw(t *testing.T) {
+	repo := testsupport.NewStubCompaniesRepository()
+	handler := NewCompaniesHandler(service.NewCompaniesService(repo, service.WithRawPayloads(&stubRawRepo{})))
+
+	get := func(id string) *httptest.ResponseRecorder {
+		c, rec := testsupport.NewContext(http.MethodGet, "/companies/"+id+"/raw")
+		if err := handler.Raw(testsupport.WithParams(c, "id", id)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := get("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var raw map[string]string
+	testsupport.DecodeData(t, rec, &raw)
+	if raw["place_id"] != "abc" {
+		t.Fatalf("unexpected raw payload: %v", raw)
+	}
+
+	testsupport.AssertStatus(t, get("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"), http.StatusNotFound)
+	testsupport.AssertStatus(t, get("nope"), http.StatusBadRequest)
+	// Offloaded payloads need the raw store.
+	testsupport.AssertStatus(t, get("cccccccc-cccc-cccc-cccc-cccccccccccc"), http.StatusNotImplemented)
+}
+
+func TestCompaniesHandler_RawOffloaded(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	if err := store.Upload(context.Background(), "raw/def/manual.json", "application/json", strings.NewReader(`{"place_id":"def"}`)); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	handler := NewCompaniesHandler(service.NewCompaniesService(testsupport.NewStubCompaniesRepository(),
+		service.WithRawPayloads(&stubRawRepo{}), service.WithRawStore(store, "raw")))
+
+	get := func(id string) *httptest.ResponseRecorder {
+		c, rec := testsupport.NewContext(http.MethodGet, "/companies/"+id+"/raw")
+		if err := handler.Raw(testsupport.WithParams(c, "id", id)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := get("cccccccc-cccc-cccc-cccc-cccccccccccc")
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var raw map[string]string
+	testsupport.DecodeData(t, rec, &raw)
+	if raw["place_id"] != "def" {
+		t.Fatalf("unexpected offloaded payload: %v", raw)
+	}
+	testsupport.AssertStatus(t, get("dddddddd-dddd-dddd-dddd-dddddddddddd"), http.StatusNotFound)
+}
+
+type stubSnapshotsRepo struct{}
+
+func (s *stubSnapshotsRepo) ListCompanySnapshots(ctx context.Context, companyID uuid.UUID) ([]entity.CompanySnapshot, error) {
+	if companyID.String() != "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa" {
+		return nil, repository.ErrCompanyNotFound
+	}
+	reviews := 42
+	return []entity.CompanySnapshot{{ScrapeRunID: uuid.New(), Reviews: &reviews}}, nil
+}
+
+func TestCompaniesHandler_History(t *testing.T) {
+	repo := testsupport.NewStubCompaniesRepository()
+	handler := NewCompaniesHandler(service.NewCompaniesService(repo, service.WithHistory(&stubSnapshotsRepo{})))
+
+	get := func(id string) *httptest.ResponseRecorder {
+		c, rec := testsupport.NewContext(http.MethodGet, "/companies/"+id+"/history")
+		if err := handler.History(testsupport.WithParams(c, "id", id)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := get("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var history entity.CompanyHistory
+	testsupport.DecodeData(t, rec, &history)
+	if len(history.Snapshots) != 1 || *history.Snapshots[0].Reviews != 42 {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	testsupport.AssertStatus(t, get("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"), http.StatusNotFound)
+	testsupport.AssertStatus(t, get("nope"), http.StatusBadRequest)
+}
+
 func TestCompaniesHandler_ListAdmin_AllData(t *testing.T) {
 	repo := &capturingCompaniesRepo{}
 	handler := newCompaniesHandler(repo)
@@ -213,6 +883,75 @@ func TestCompaniesHandler_ListAdmin_AllData(t *testing.T) {
 	}
 }
 
+type companyExportsStub struct {
+	matched    int64
+	lastFilter dto.ListFilter
+}
+
+func (s *companyExportsStub) CountCompanies(ctx context.Context, filter dto.ListFilter) (int64, error) {
+	s.lastFilter = filter
+	return s.matched, nil
+}
+
+func (s *companyExportsStub) ExportCompanies(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.Company) error) error {
+	return fn(entity.Company{ID: uuid.New(), Company: "Acme"})
+}
+
+func TestCompaniesHandler_Export(t *testing.T) {
+	exports := &companyExportsStub{matched: 1}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}, service.WithExports(exports, map[string]int64{"user": 10, "admin": 0})))
+	export := func(target string, user *entity.User) *httptest.ResponseRecorder {
+		c, rec := testsupport.NewContext(http.MethodGet, target)
+		if err := handler.Export(testsupport.WithUser(c, user)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	user := testsupport.NewUser().WithEmail("sales@example.com").Build()
+	rec := export("/exports/companies?city=Jakarta", user)
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	exportID := rec.Header().Get("X-Export-ID")
+	if exportID == "" || !strings.Contains(rec.Body.String(), "sales@example.com ("+user.ID.String()+") export "+exportID) {
+		t.Fatalf("expected stamped export, got %q (%s)", rec.Body.String(), exportID)
+	}
+	if !exports.lastFilter.LatestRunOnly || exports.lastFilter.City != "Jakarta" {
+		t.Fatalf("expected user exports to follow the public listing, got %+v", exports.lastFilter)
+	}
+
+	exports.matched = 25
+	rec = export("/exports/companies", user)
+	testsupport.AssertStatus(t, rec, http.StatusUnprocessableEntity)
+	if !strings.Contains(testsupport.DecodeEnvelope(t, rec).Message, "limited to 10 rows") {
+		t.Fatalf("expected cap message, got %s", rec.Body.String())
+	}
+
+	rec = export("/exports/companies", testsupport.NewUser().Admin().Build())
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	if exports.lastFilter.LatestRunOnly {
+		t.Fatalf("expected admin exports to include all data")
+	}
+	testsupport.AssertStatus(t, export("/exports/companies?updated_since=yesterday", user), http.StatusBadRequest)
+
+	exports.matched = 1
+	c, rec := testsupport.NewContext(http.MethodGet, "/exports/companies")
+	c.Request().Header.Set(echo.HeaderAccept, "application/json;q=0.5, application/x-ndjson")
+	if err := handler.Export(testsupport.WithUser(c, user)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	if rec.Header().Get(echo.HeaderContentType) != "application/x-ndjson" || !strings.HasSuffix(rec.Header().Get(echo.HeaderContentDisposition), `.ndjson"`) {
+		t.Fatalf("expected a JSON Lines attachment, got %v", rec.Header())
+	}
+	var line struct {
+		Company    string `json:"company"`
+		ExportedBy string `json:"exported_by"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &line); err != nil || line.Company != "Acme" || !strings.Contains(line.ExportedBy, "sales@example.com") {
+		t.Fatalf("unexpected JSON Lines export %q: %v", rec.Body.String(), err)
+	}
+}
+
 func TestCompaniesHandler_parseIntDefault(t *testing.T) {
 	if val := parseIntDefault("", 5); val != 5 {
 		t.Fatalf("expected fallback when empty")
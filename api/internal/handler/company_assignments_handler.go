@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// Assign handles PATCH /companies/:id/assign requests.
+func (h *CompaniesHandler) Assign(c echo.Context) error {
+	var req dto.AssignCompanyRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+
+	assignment, err := h.service.AssignCompany(c.Request().Context(), c.Param("id"), req, userID, role == "admin")
+	if err != nil {
+		return assignmentError(c, err, "failed to assign company")
+	}
+	if assignment == nil {
+		return Success(c, http.StatusOK, "company unassigned", nil)
+	}
+	return Success(c, http.StatusOK, "company assigned", assignment)
+}
+
+// BulkAssign handles POST /admin/companies/assign requests, assigning every company matching the
+// listing filter of the query.
+func (h *CompaniesHandler) BulkAssign(c echo.Context) error {
+	filter, err := parseListFilter(c, false)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	var req dto.BulkAssignRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+
+	changed, err := h.service.AssignCompanies(c.Request().Context(), filter, req, userID)
+	if err != nil {
+		return assignmentError(c, err, "failed to assign companies")
+	}
+	return Success(c, http.StatusOK, "companies assigned", map[string]int64{"changed": changed})
+}
+
+// assignmentError writes the response of a failed assignment.
+func assignmentError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCompanyID):
+		return Error(c, http.StatusBadRequest, "invalid company id")
+	case errors.Is(err, service.ErrInvalidAssignee), errors.Is(err, service.ErrAssigneeNotFound),
+		errors.Is(err, service.ErrAssignmentFilterRequired):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCompanyNotFound):
+		return Error(c, http.StatusNotFound, "company not found")
+	case errors.Is(err, service.ErrAssignmentForbidden):
+		return Error(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrCompanyAssigned):
+		return Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrAssignmentsUnavailable):
+		return Error(c, http.StatusNotImplemented, "company assignments are not enabled")
+	default:
+		return queryError(c, err, fallback)
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// Batch handles POST /companies/batch requests from data providers. The body is a JSON array of
+// companies stored as a CSV import would; the optional mode query value picks overwrite (default),
+// fill_missing or skip_existing for companies that already exist. The response reports a result per
+// record, in the order sent.
+func (h *CompaniesHandler) Batch(c echo.Context) error {
+	mode, err := service.ParseImportMode(c.QueryParam("mode"))
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "invalid mode (use overwrite, fill_missing or skip_existing)")
+	}
+	var records []dto.CompanyBatchRecord
+	if err := c.Bind(&records); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+
+	summary, err := h.service.UpsertCompaniesBatch(c.Request().Context(), records, mode)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCompanyBatchEmpty):
+			return Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrCompanyBatchTooLarge):
+			return Error(c, http.StatusRequestEntityTooLarge, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to store companies")
+	}
+
+	if key := middlewarepkg.APIKeyFromContext(c); key != nil {
+		log.Printf("request_id=%s api_key=%s stored company batch mode=%s inserted=%d updated=%d skipped=%d rejected=%d",
+			middlewarepkg.RequestIDFromContext(c), key.Prefix, summary.Mode, summary.Inserted, summary.Updated, summary.Skipped, summary.Rejected)
+	}
+	return Success(c, http.StatusOK, "companies batch processed", summary)
+}
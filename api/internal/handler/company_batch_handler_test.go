@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+func TestCompaniesHandler_Batch(t *testing.T) {
+	repo := testsupport.NewStubCompaniesRepository()
+	handler := NewCompaniesHandler(service.NewCompaniesService(repo))
+
+	c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/companies/batch?mode=skip-existing", []dto.CompanyBatchRecord{
+		{Company: "Kopi Kenangan", Address: "Jl. Sudirman 1", City: "Jakarta"},
+		{Address: "Jl. Thamrin 2"},
+	})
+	if err := handler.Batch(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var summary service.CompanyBatchSummary
+	testsupport.DecodeData(t, rec, &summary)
+	if summary.Mode != repository.ImportModeSkipExisting || summary.Inserted != 1 || summary.Rejected != 1 || len(summary.Results) != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if summary.Results[0].Status != repository.BulkUpsertInserted || summary.Results[0].CompanyID == nil || summary.Results[1].Error != "company is required" {
+		t.Fatalf("unexpected results: %+v", summary.Results)
+	}
+	if repo.BulkMode != repository.ImportModeSkipExisting || len(repo.BulkRecords) != 1 {
+		t.Fatalf("expected the valid record stored with the mode, got %q %+v", repo.BulkMode, repo.BulkRecords)
+	}
+
+	tests := map[string]struct {
+		target  string
+		payload any
+		status  int
+	}{
+		"unknown mode":     {"/companies/batch?mode=merge", []dto.CompanyBatchRecord{{Company: "A", Address: "B"}}, http.StatusBadRequest},
+		"object body":      {"/companies/batch", dto.CompanyBatchRecord{Company: "A", Address: "B"}, http.StatusBadRequest},
+		"empty batch":      {"/companies/batch", []dto.CompanyBatchRecord{}, http.StatusBadRequest},
+		"too many records": {"/companies/batch", make([]dto.CompanyBatchRecord, service.MaxCompanyBatchSize+1), http.StatusRequestEntityTooLarge},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, tt.target, tt.payload)
+			if err := handler.Batch(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testsupport.AssertStatus(t, rec, tt.status)
+			if !strings.Contains(rec.Body.String(), `"status":"error"`) {
+				t.Fatalf("expected an error envelope, got %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// SuggestQueryRules bound the limit of company suggestions.
+var SuggestQueryRules = []QueryRule{
+	IntParam("limit", 1, service.MaxSuggestLimit),
+}
+
+// Suggest handles GET /companies/suggest requests, returning the distinct values of a city, business
+// type or company name field that start with q, with their company counts, for typeahead inputs.
+func (h *CompaniesHandler) Suggest(c echo.Context) error {
+	limit := parseIntDefault(strings.TrimSpace(c.QueryParam("limit")), service.DefaultSuggestLimit)
+	suggestions, err := h.service.SuggestCompanyValues(c.Request().Context(), c.QueryParam("field"), c.QueryParam("q"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSuggestField), errors.Is(err, service.ErrSuggestQueryRequired):
+			return Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrSuggestionsUnavailable):
+			return Error(c, http.StatusNotImplemented, "company suggestions are not enabled")
+		default:
+			return queryError(c, err, "failed to suggest company values")
+		}
+	}
+	return Success(c, http.StatusOK, "company suggestions retrieved", suggestions)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type suggestRepoStub struct {
+	field, prefix string
+	limit         int
+}
+
+func (s *suggestRepoStub) Suggest(ctx context.Context, field, prefix string, limit int) ([]entity.StatBucket, error) {
+	s.field, s.prefix, s.limit = field, prefix, limit
+	return []entity.StatBucket{{Name: "Cafe", Companies: 9}}, nil
+}
+
+func TestCompaniesHandler_Suggest(t *testing.T) {
+	repo := &suggestRepoStub{}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}, service.WithSuggestions(repo)))
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/companies/suggest?field=type_business&q=Caf&limit=5", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.Suggest(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if repo.field != "type_business" || repo.prefix != "caf" || repo.limit != 5 {
+		t.Fatalf("unexpected suggest call %+v", repo)
+	}
+
+	for _, query := range []string{"field=phone&q=08", "field=city"} {
+		req = httptest.NewRequest(http.MethodGet, "/companies/suggest?"+query, nil)
+		rec = httptest.NewRecorder()
+		if err := handler.Suggest(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+
+	disabled := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}))
+	req = httptest.NewRequest(http.MethodGet, "/companies/suggest?field=city&q=ban", nil)
+	rec = httptest.NewRecorder()
+	if err := disabled.Suggest(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", rec.Code)
+	}
+}
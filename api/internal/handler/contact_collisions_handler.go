@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ContactCollisionsHandler exposes the report of contacts shared across companies.
+type ContactCollisionsHandler struct {
+	collisions *service.ContactCollisionService
+}
+
+// NewContactCollisionsHandler constructs a handler instance.
+func NewContactCollisionsHandler(collisions *service.ContactCollisionService) *ContactCollisionsHandler {
+	return &ContactCollisionsHandler{collisions: collisions}
+}
+
+// List handles GET /reports/contact-collisions requests.
+func (h *ContactCollisionsHandler) List(c echo.Context) error {
+	collisions, err := h.collisions.ListCollisions(
+		c.Request().Context(),
+		c.QueryParam("kind"),
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidContactKind) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to list contact collisions")
+	}
+	return Success(c, http.StatusOK, "contact collisions retrieved", collisions)
+}
+
+// Detect handles POST /admin/contacts/collisions/detect requests.
+func (h *ContactCollisionsHandler) Detect(c echo.Context) error {
+	minCompanies := parseIntDefault(c.QueryParam("min_companies"), service.DefaultContactCollisionMinCompanies)
+
+	result, err := h.collisions.DetectCollisions(c.Request().Context(), minCompanies)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCollisionThreshold) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to detect contact collisions")
+	}
+	return Success(c, http.StatusOK, "contact collisions detected", result)
+}
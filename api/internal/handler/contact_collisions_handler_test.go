@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type contactCollisionsRepoStub struct {
+	minOwners int
+	kind      string
+}
+
+func (s *contactCollisionsRepoStub) DetectContactCollisions(ctx context.Context, minOwners int) (repository.ContactCollisionDetectionResult, error) {
+	s.minOwners = minOwners
+	return repository.ContactCollisionDetectionResult{Collisions: 2, SharedCompanies: 30}, nil
+}
+
+func (s *contactCollisionsRepoStub) ListContactCollisions(ctx context.Context, kind string, limit, offset int) ([]entity.ContactCollision, error) {
+	s.kind = kind
+	return []entity.ContactCollision{{Kind: entity.ContactKindPhone, Value: "622155501234", CompanyCount: 15, OwnerCount: 15}}, nil
+}
+
+func TestContactCollisionsHandler_List(t *testing.T) {
+	e := echo.New()
+	repo := &contactCollisionsRepoStub{}
+	handler := NewContactCollisionsHandler(service.NewContactCollisionService(repo))
+
+	req := httptest.NewRequest(http.MethodGet, "/reports/contact-collisions?kind=Phone", nil)
+	rec := httptest.NewRecorder()
+	_ = handler.List(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if repo.kind != entity.ContactKindPhone {
+		t.Fatalf("expected kind forwarded, got %q", repo.kind)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/reports/contact-collisions?kind=fax", nil)
+	rec = httptest.NewRecorder()
+	_ = handler.List(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestContactCollisionsHandler_Detect(t *testing.T) {
+	e := echo.New()
+	repo := &contactCollisionsRepoStub{}
+	handler := NewContactCollisionsHandler(service.NewContactCollisionService(repo))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/contacts/collisions/detect", nil)
+	rec := httptest.NewRecorder()
+	_ = handler.Detect(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if repo.minOwners != service.DefaultContactCollisionMinCompanies {
+		t.Fatalf("expected default threshold, got %d", repo.minOwners)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/contacts/collisions/detect?min_companies=1", nil)
+	rec = httptest.NewRecorder()
+	_ = handler.Detect(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
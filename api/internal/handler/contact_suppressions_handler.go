@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// SuppressionsHandler exposes admin management of the do-not-contact list.
+type SuppressionsHandler struct {
+	suppressions *service.ContactSuppressionService
+}
+
+// NewSuppressionsHandler constructs a handler instance.
+func NewSuppressionsHandler(suppressions *service.ContactSuppressionService) *SuppressionsHandler {
+	return &SuppressionsHandler{suppressions: suppressions}
+}
+
+// List handles GET /admin/suppressions requests, optionally filtered by kind and a value search.
+func (h *SuppressionsHandler) List(c echo.Context) error {
+	suppressions, err := h.suppressions.List(
+		c.Request().Context(),
+		c.QueryParam("kind"),
+		c.QueryParam("q"),
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		return suppressionError(c, err, "failed to list suppressions")
+	}
+	return Success(c, http.StatusOK, "suppressions retrieved", suppressions)
+}
+
+// Create handles POST /admin/suppressions requests.
+func (h *SuppressionsHandler) Create(c echo.Context) error {
+	var req dto.SuppressionRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	suppression, err := h.suppressions.Create(c.Request().Context(), req, userID)
+	if err != nil {
+		return suppressionError(c, err, "failed to save suppression")
+	}
+	return Success(c, http.StatusCreated, "suppression created", suppression)
+}
+
+// Update handles PUT /admin/suppressions/:id requests.
+func (h *SuppressionsHandler) Update(c echo.Context) error {
+	var req dto.SuppressionRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	suppression, err := h.suppressions.Update(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return suppressionError(c, err, "failed to save suppression")
+	}
+	return Success(c, http.StatusOK, "suppression updated", suppression)
+}
+
+// Delete handles DELETE /admin/suppressions/:id requests.
+func (h *SuppressionsHandler) Delete(c echo.Context) error {
+	if err := h.suppressions.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return suppressionError(c, err, "failed to delete suppression")
+	}
+	return Success(c, http.StatusOK, "suppression deleted", nil)
+}
+
+func suppressionError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidSuppression):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidSuppressionID):
+		return Error(c, http.StatusBadRequest, "invalid suppression id")
+	case errors.Is(err, service.ErrSuppressionNotFound):
+		return Error(c, http.StatusNotFound, "suppression not found")
+	case errors.Is(err, service.ErrSuppressionExists):
+		return Error(c, http.StatusConflict, "suppression already exists")
+	default:
+		return Error(c, http.StatusInternalServerError, fallback)
+	}
+}
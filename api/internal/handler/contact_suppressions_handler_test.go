@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type suppressionsRepoStub struct {
+	created []entity.ContactSuppression
+}
+
+func (s *suppressionsRepoStub) ListSuppressions(ctx context.Context, kind, query string, limit, offset int) ([]entity.ContactSuppression, error) {
+	return s.created, nil
+}
+
+func (s *suppressionsRepoStub) CreateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error {
+	for _, existing := range s.created {
+		if existing.Kind == suppression.Kind && existing.Value == suppression.Value {
+			return repository.ErrSuppressionDuplicate
+		}
+	}
+	suppression.ID = uuid.New()
+	s.created = append(s.created, *suppression)
+	return nil
+}
+
+func (s *suppressionsRepoStub) UpdateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error {
+	return repository.ErrSuppressionNotFound
+}
+
+func (s *suppressionsRepoStub) DeleteSuppression(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrSuppressionNotFound
+}
+
+func (s *suppressionsRepoStub) MatchSuppressions(ctx context.Context, emails, domains, phones []string) ([]entity.ContactSuppression, error) {
+	return nil, nil
+}
+
+func TestSuppressionsHandler_Create(t *testing.T) {
+	e := echo.New()
+	handler := NewSuppressionsHandler(service.NewContactSuppressionService(&suppressionsRepoStub{}, nil))
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"created", `{"kind":"domain","value":"https://www.acme.com"}`, http.StatusCreated},
+		{"duplicate", `{"kind":"domain","value":"ACME.com"}`, http.StatusConflict},
+		{"invalid kind", `{"kind":"fax","value":"123"}`, http.StatusBadRequest},
+		{"invalid json", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/suppressions", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			_ = handler.Create(e.NewContext(req, rec))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSuppressionsHandler_ListInvalidKind(t *testing.T) {
+	e := echo.New()
+	handler := NewSuppressionsHandler(service.NewContactSuppressionService(&suppressionsRepoStub{}, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/suppressions?kind=fax", nil)
+	rec := httptest.NewRecorder()
+
+	_ = handler.List(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// DeadLettersHandler lets administrators inspect, fix and replay the worker callbacks the API could
+// not store.
+type DeadLettersHandler struct {
+	letters *service.DeadLetterService
+}
+
+// NewDeadLettersHandler wires a handler backed by the dead letter service.
+func NewDeadLettersHandler(letters *service.DeadLetterService) *DeadLettersHandler {
+	return &DeadLettersHandler{letters: letters}
+}
+
+// List handles GET /admin/dead-letters requests, optionally narrowed with status=pending or
+// status=replayed.
+func (h *DeadLettersHandler) List(c echo.Context) error {
+	limit := parseIntDefault(c.QueryParam("limit"), 50)
+	offset := parseIntDefault(c.QueryParam("offset"), 0)
+	letters, err := h.letters.List(c.Request().Context(), c.QueryParam("status"), limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDeadLetterStatus) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to list dead letters")
+	}
+	return Success(c, http.StatusOK, "dead letters retrieved", letters)
+}
+
+// Get handles GET /admin/dead-letters/:id requests.
+func (h *DeadLettersHandler) Get(c echo.Context) error {
+	letter, err := h.letters.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return deadLetterError(c, err, "failed to load dead letter")
+	}
+	return Success(c, http.StatusOK, "dead letter retrieved", letter)
+}
+
+// Update handles PUT /admin/dead-letters/:id requests, replacing the body of a pending dead letter
+// with the fixed JSON object in body.
+func (h *DeadLettersHandler) Update(c echo.Context) error {
+	var req dto.UpdateDeadLetterRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	letter, err := h.letters.Update(c.Request().Context(), c.Param("id"), req.Body)
+	if err != nil {
+		return deadLetterError(c, err, "failed to update dead letter")
+	}
+	return Success(c, http.StatusOK, "dead letter updated", letter)
+}
+
+// Replay handles POST /admin/dead-letters/:id/replay requests, storing the body of a pending dead
+// letter as its callback would have. A body rejected again is answered 422 with the reason, and
+// with the rejected values per field when it failed validation; the letter stays pending.
+func (h *DeadLettersHandler) Replay(c echo.Context) error {
+	letter, err := h.letters.Replay(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		var validationErr service.EnrichmentValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return c.JSON(http.StatusUnprocessableEntity, APIResponse{
+				Status:  "error",
+				Message: "invalid enrichment payload",
+				Data:    letter,
+				Errors:  validationErr.Fields,
+			})
+		case errors.Is(err, service.ErrInvalidDeadLetterBody), errors.Is(err, service.ErrInvalidCompanyID):
+			return c.JSON(http.StatusUnprocessableEntity, APIResponse{Status: "error", Message: err.Error(), Data: letter})
+		}
+		return deadLetterError(c, err, "failed to replay dead letter")
+	}
+	return Success(c, http.StatusOK, "dead letter replayed", letter)
+}
+
+func deadLetterError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidDeadLetterID):
+		return Error(c, http.StatusBadRequest, "invalid dead letter id")
+	case errors.Is(err, service.ErrInvalidDeadLetterBody):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrDeadLetterNotFound):
+		return Error(c, http.StatusNotFound, "dead letter not found")
+	case errors.Is(err, service.ErrDeadLetterReplayed):
+		return Error(c, http.StatusConflict, err.Error())
+	default:
+		return Error(c, http.StatusInternalServerError, msg)
+	}
+}
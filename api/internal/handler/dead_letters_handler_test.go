@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type deadLettersRepoStub struct {
+	letters []*entity.DeadLetter
+}
+
+func (s *deadLettersRepoStub) Create(ctx context.Context, letter *entity.DeadLetter) error {
+	letter.ID = uuid.New()
+	letter.Status = entity.DeadLetterPending
+	letter.CreatedAt = time.Now()
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *deadLettersRepoStub) List(ctx context.Context, status string, limit, offset int) ([]entity.DeadLetter, error) {
+	letters := make([]entity.DeadLetter, 0)
+	for _, letter := range s.letters {
+		if status == "" || letter.Status == status {
+			letters = append(letters, *letter)
+		}
+	}
+	return letters, nil
+}
+
+func (s *deadLettersRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	for _, letter := range s.letters {
+		if letter.ID == id {
+			copied := *letter
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrDeadLetterNotFound
+}
+
+func (s *deadLettersRepoStub) update(id uuid.UUID, change func(*entity.DeadLetter)) (*entity.DeadLetter, error) {
+	for _, letter := range s.letters {
+		if letter.ID == id {
+			change(letter)
+			copied := *letter
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrDeadLetterNotFound
+}
+
+func (s *deadLettersRepoStub) UpdateBody(ctx context.Context, id uuid.UUID, body string) (*entity.DeadLetter, error) {
+	return s.update(id, func(letter *entity.DeadLetter) { letter.Body = body })
+}
+
+func (s *deadLettersRepoStub) MarkReplayed(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	return s.update(id, func(letter *entity.DeadLetter) {
+		letter.Status = entity.DeadLetterReplayed
+		letter.Attempts++
+	})
+}
+
+func (s *deadLettersRepoStub) RecordReplayFailure(ctx context.Context, id uuid.UUID, reason string) (*entity.DeadLetter, error) {
+	return s.update(id, func(letter *entity.DeadLetter) {
+		letter.Attempts++
+		letter.LastError = &reason
+	})
+}
+
+func TestDeadLetters_RecordAndReplay(t *testing.T) {
+	e := echo.New()
+	companyID := "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	enrichments := &enrichmentRepoStub{err: errors.New("boom")}
+	companies := service.NewCompaniesService(enrichments)
+	letters := &deadLettersRepoStub{}
+	deadLetters := service.NewDeadLetterService(letters, companies)
+	enrich := NewEnrichHandlerWithDeadLetters(companies, deadLetters)
+	admin := NewDeadLettersHandler(deadLetters)
+
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/enrich-result", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := enrich.SaveResult(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code
+	}
+	if code := post(`{"company_id":"` + companyID + `","emails":["info@example.com"]}`); code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the write fails, got %d", code)
+	}
+	if code := post(`{"company_id":"` + companyID + `","emails":"info@example.com"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid payload, got %d", code)
+	}
+	if len(letters.letters) != 2 {
+		t.Fatalf("expected both rejected results kept, got %d", len(letters.letters))
+	}
+	written, malformed := letters.letters[0], letters.letters[1]
+	if written.CompanyID == nil || written.CompanyID.String() != companyID || !strings.Contains(written.Reason, "boom") || !strings.Contains(written.Body, "info@example.com") {
+		t.Fatalf("unexpected dead letter: %+v", written)
+	}
+
+	call := func(method, id, suffix, body string, handle func(echo.Context) error) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/dead-letters/"+id+suffix, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		if err := handle(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	if err := admin.List(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/dead-letters?status=pending", nil), rec)); err != nil || rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), malformed.ID.String()) {
+		t.Fatalf("expected the pending letters, got %d: %s (%v)", rec.Code, rec.Body.String(), err)
+	}
+
+	enrichments.err = nil
+	if rec := call(http.MethodPost, written.ID.String(), "/replay", "", admin.Replay); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"replayed"`) {
+		t.Fatalf("expected the letter replayed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if enrichments.saved == nil || enrichments.saved.CompanyID.String() != companyID {
+		t.Fatalf("expected the enrichment stored, got %+v", enrichments.saved)
+	}
+	if rec := call(http.MethodPost, written.ID.String(), "/replay", "", admin.Replay); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a replayed letter, got %d", rec.Code)
+	}
+
+	if rec := call(http.MethodPost, malformed.ID.String(), "/replay", "", admin.Replay); rec.Code != http.StatusUnprocessableEntity || malformed.Attempts != 1 || malformed.LastError == nil {
+		t.Fatalf("expected 422 with the attempt counted, got %d: %+v", rec.Code, malformed)
+	}
+	if rec := call(http.MethodPut, malformed.ID.String(), "", `{"body":"nope"}`, admin.Update); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a body that is no object, got %d", rec.Code)
+	}
+	fixed := `{"body":{"company_id":"` + companyID + `","emails":["info@example.com"]}}`
+	if rec := call(http.MethodPut, malformed.ID.String(), "", fixed, admin.Update); rec.Code != http.StatusOK {
+		t.Fatalf("expected the body fixed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodPost, malformed.ID.String(), "/replay", "", admin.Replay); rec.Code != http.StatusOK || malformed.Status != entity.DeadLetterReplayed {
+		t.Fatalf("expected the fixed letter replayed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodGet, uuid.NewString(), "", "", admin.Get); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown letter, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// DNSCacheHandler exposes the hit rate of the MX lookup cache shared by enrichment validation.
+type DNSCacheHandler struct {
+	cache *service.DNSCache
+}
+
+// NewDNSCacheHandler constructs a handler instance; a nil cache reports zeros.
+func NewDNSCacheHandler(cache *service.DNSCache) *DNSCacheHandler {
+	return &DNSCacheHandler{cache: cache}
+}
+
+// Stats handles GET /admin/metrics/dns-cache requests.
+func (h *DNSCacheHandler) Stats(c echo.Context) error {
+	return Success(c, http.StatusOK, "dns cache stats retrieved", h.cache.Stats())
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+func TestDNSCacheHandler_Stats(t *testing.T) {
+	cache := service.NewDNSCache(10, time.Hour, time.Minute)
+	cache.Store("gmail.com", true)
+	cache.Lookup("gmail.com")
+	cache.Lookup("yahoo.com")
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := NewDNSCacheHandler(cache).Stats(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/metrics/dns-cache", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data service.DNSCacheStats `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.Entries != 1 || body.Data.Hits != 1 || body.Data.Misses != 1 || body.Data.HitRate != 0.5 {
+		t.Fatalf("unexpected stats payload: %s", rec.Body.String())
+	}
+}
@@ -1,21 +1,40 @@
 package handler
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"net/http"
-	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 	"github.com/octobees/leads-generator/api/internal/service"
-	"github.com/octobees/leads-generator/api/internal/service/scoring"
 )
 
+// enrichResultFields are the names accepted by the fields param of GET /enrich-result: enrichment
+// keys plus "score".
+var enrichResultFields = func() map[string]bool {
+	fields := jsonFieldNames(entity.CompanyEnrichment{})
+	fields["score"] = true
+	return fields
+}()
+
+// emailClassNames are the values accepted by the exclude_emails param of GET /enrich-result.
+var emailClassNames = func() map[string]bool {
+	names := make(map[string]bool, len(service.EmailClasses))
+	for _, class := range service.EmailClasses {
+		names[class] = true
+	}
+	return names
+}()
+
 // EnrichHandler receives website enrichment payloads from the worker service.
 type EnrichHandler struct {
 	companiesService *service.CompaniesService
+	deadLetters      *service.DeadLetterService
 }
 
 // NewEnrichHandler wires a new EnrichHandler instance.
@@ -23,18 +42,50 @@ func NewEnrichHandler(companiesService *service.CompaniesService) *EnrichHandler
 	return &EnrichHandler{companiesService: companiesService}
 }
 
-// SaveResult persists the POSTed enrichment payload.
+// NewEnrichHandlerWithDeadLetters also keeps every result that cannot be stored as a dead letter,
+// so an operator can fix and replay it.
+func NewEnrichHandlerWithDeadLetters(companiesService *service.CompaniesService, deadLetters *service.DeadLetterService) *EnrichHandler {
+	return &EnrichHandler{companiesService: companiesService, deadLetters: deadLetters}
+}
+
+// SaveResult persists the POSTed enrichment payload. In strict validation mode a payload with
+// invalid values is answered 422 with the rejected values per field. Behind the worker callback
+// guard, payloads for companies outside the token's job are answered 403. With dead letters, a
+// payload rejected for any other reason is kept as received.
 func (h *EnrichHandler) SaveResult(c echo.Context) error {
+	var body []byte
+	if h.deadLetters != nil {
+		read, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return Error(c, http.StatusBadRequest, "invalid JSON payload")
+		}
+		body = read
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+	}
+
 	var payload dto.EnrichResultRequest
 	if err := c.Bind(&payload); err != nil {
+		h.deadLetter(c, body, errors.New("invalid JSON payload"))
 		return Error(c, http.StatusBadRequest, "invalid JSON payload")
 	}
 	if payload.CompanyID == "" {
+		h.deadLetter(c, body, errors.New("company_id is required"))
 		return Error(c, http.StatusBadRequest, "company_id is required")
 	}
+	if claims := middlewarepkg.CallbackFromContext(c); claims != nil && !claims.CoversCompany(payload.CompanyID) {
+		return Error(c, http.StatusForbidden, "callback token does not cover this company")
+	}
 
 	if err := h.companiesService.SaveEnrichment(c.Request().Context(), payload); err != nil {
+		h.deadLetter(c, body, err)
+		var validationErr service.EnrichmentValidationError
 		switch {
+		case errors.As(err, &validationErr):
+			return c.JSON(http.StatusUnprocessableEntity, APIResponse{
+				Status:  "error",
+				Message: "invalid enrichment payload",
+				Errors:  validationErr.Fields,
+			})
 		case errors.Is(err, service.ErrInvalidCompanyID):
 			return Error(c, http.StatusBadRequest, "invalid company_id")
 		default:
@@ -45,13 +96,35 @@ func (h *EnrichHandler) SaveResult(c echo.Context) error {
 	return Success(c, http.StatusOK, "enrichment stored", map[string]any{"success": true})
 }
 
-// GetResult retrieves the enrichment payload for a company.
+// deadLetter keeps a rejected result body when dead letters are enabled.
+func (h *EnrichHandler) deadLetter(c echo.Context, body []byte, cause error) {
+	if h.deadLetters == nil {
+		return
+	}
+	jobID := ""
+	if claims := middlewarepkg.CallbackFromContext(c); claims != nil {
+		jobID = claims.JobID()
+	}
+	h.deadLetters.RecordEnrichResult(c.Request().Context(), body, jobID, cause)
+}
+
+// GetResult retrieves the enrichment payload for a company. exclude_emails lists email classes
+// (disposable, free, role) to leave out; by default every stored address is returned.
 func (h *EnrichHandler) GetResult(c echo.Context) error {
 	companyID := c.Param("company_id")
 	if companyID == "" {
 		return Error(c, http.StatusBadRequest, "company_id is required")
 	}
 
+	fields, err := parseFields("fields", c.QueryParam("fields"), enrichResultFields)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	excludeEmails, err := parseFields("exclude_emails", c.QueryParam("exclude_emails"), emailClassNames)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+
 	result, err := h.companiesService.GetEnrichment(c.Request().Context(), companyID)
 	if err != nil {
 		switch {
@@ -64,120 +137,28 @@ func (h *EnrichHandler) GetResult(c echo.Context) error {
 		}
 	}
 
-	features := buildLeadFeatures(result)
-	score := scoring.ComputeScore(features)
-
-	payload := map[string]any{
-		"enrichment": result,
-		"score":      score,
-	}
-
-	return Success(c, http.StatusOK, "ok", payload)
-}
-
-func buildLeadFeatures(enrichment *entity.CompanyEnrichment) scoring.LeadFeatures {
-	if enrichment == nil {
-		return scoring.LeadFeatures{}
-	}
-
-	socials := flattenSocials(enrichment.Socials)
-	address := derefString(enrichment.Address)
-	website := metadataString(enrichment.Metadata, "website")
-
-	hasContactForm := hasPointerValue(enrichment.ContactFormURL)
-	hasContactPage := hasContactForm
-	if !hasContactPage {
-		if metadataString(enrichment.Metadata, "contact_page_url") != "" {
-			hasContactPage = true
-		} else if flag, ok := metadataBool(enrichment.Metadata, "has_contact_page"); ok {
-			hasContactPage = flag
-		}
-	}
-
-	hasHTTPS := metadataBoolDefault(enrichment.Metadata, "https_enabled")
-	if !hasHTTPS && website != "" {
-		hasHTTPS = strings.HasPrefix(strings.ToLower(website), "https://")
-	}
-
-	return scoring.LeadFeatures{
-		Emails:         enrichment.Emails,
-		Phones:         enrichment.Phones,
-		Socials:        socials,
-		HasHTTPS:       hasHTTPS,
-		HasContactPage: hasContactPage,
-		HasAboutPage:   hasPointerValue(enrichment.AboutSummary),
-		HasContactForm: hasContactForm,
-		Address:        address,
-		Website:        website,
-	}
-}
-
-func flattenSocials(values map[string][]string) map[string]string {
-	if len(values) == 0 {
-		return nil
+	payload := map[string]any{}
+	if fields == nil || fields["score"] {
+		payload["score"] = h.companiesService.ScoreEnrichment(c.Request().Context(), result)
 	}
-	result := make(map[string]string, len(values))
-	for platform, links := range values {
-		value := firstNonEmpty(links)
-		if value == "" {
-			continue
+	// The score reflects every stored address; exclusions only shape the returned list.
+	service.ExcludeEmailClasses(result, excludeEmails)
+	enrichmentFields := fields
+	if fields != nil {
+		enrichmentFields = make(map[string]bool, len(fields))
+		for name := range fields {
+			if name != "score" {
+				enrichmentFields[name] = true
+			}
 		}
-		result[platform] = value
-	}
-	if len(result) == 0 {
-		return nil
-	}
-	return result
-}
-
-func firstNonEmpty(values []string) string {
-	for _, value := range values {
-		trimmed := strings.TrimSpace(value)
-		if trimmed != "" {
-			return trimmed
-		}
-	}
-	return ""
-}
-
-func derefString(value *string) string {
-	if value == nil {
-		return ""
-	}
-	return strings.TrimSpace(*value)
-}
-
-func hasPointerValue(value *string) bool {
-	return derefString(value) != ""
-}
-
-func metadataString(meta map[string]any, key string) string {
-	if len(meta) == 0 {
-		return ""
 	}
-	if raw, ok := meta[key]; ok {
-		if str, ok := raw.(string); ok {
-			return strings.TrimSpace(str)
+	if enrichmentFields == nil || len(enrichmentFields) > 0 {
+		enrichment, err := selectFields(result, enrichmentFields)
+		if err != nil {
+			return Error(c, http.StatusInternalServerError, "failed to encode enrichment")
 		}
+		payload["enrichment"] = enrichment
 	}
-	return ""
-}
-
-func metadataBool(meta map[string]any, key string) (bool, bool) {
-	if len(meta) == 0 {
-		return false, false
-	}
-	raw, ok := meta[key]
-	if !ok {
-		return false, false
-	}
-	flag, valid := raw.(bool)
-	return flag, valid
-}
 
-func metadataBoolDefault(meta map[string]any, key string) bool {
-	if flag, ok := metadataBool(meta, key); ok {
-		return flag
-	}
-	return false
+	return SuccessWithETag(c, http.StatusOK, "ok", payload)
 }
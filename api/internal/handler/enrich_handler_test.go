@@ -38,7 +38,7 @@ func (s *enrichmentRepoStub) List(ctx context.Context, filter dto.ListFilter) ([
 	return nil, nil
 }
 
-func (s *enrichmentRepoStub) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+func (s *enrichmentRepoStub) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput, mode repository.ImportMode) (repository.BulkUpsertResult, error) {
 	return repository.BulkUpsertResult{}, nil
 }
 
@@ -119,6 +119,33 @@ func TestEnrichHandler_SaveResult_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestEnrichHandler_SaveResult_StrictValidation(t *testing.T) {
+	repo := &enrichmentRepoStub{}
+	// Without a resolver no email domain has an MX record, so every email is rejected.
+	processor := service.NewDataProcessor("US", service.WithDNSResolver(nil))
+	handler := NewEnrichHandler(service.NewCompaniesService(repo, service.WithEnrichmentValidation(processor, service.EnrichValidationStrict)))
+
+	e := echo.New()
+	body := `{"company_id":"aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa","emails":["info@example.com"]}`
+	req := httptest.NewRequest(http.MethodPost, "/enrich-result", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.SaveResult(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"emails"`) {
+		t.Fatalf("expected emails to be reported, got %s", rec.Body.String())
+	}
+	if repo.saved != nil {
+		t.Fatalf("expected nothing saved, got %+v", repo.saved)
+	}
+}
+
 func TestEnrichHandler_SaveResult_MissingCompanyID(t *testing.T) {
 	repo := &enrichmentRepoStub{}
 	handler := NewEnrichHandler(service.NewCompaniesService(repo))
@@ -218,6 +245,103 @@ func TestEnrichHandler_GetResult_Success(t *testing.T) {
 	}
 }
 
+func TestEnrichHandler_GetResult_Fields(t *testing.T) {
+	repo := &enrichmentRepoStub{
+		result: &entity.CompanyEnrichment{
+			CompanyID: uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"),
+			Emails:    []string{"info@example.com"},
+			Phones:    []string{"+6211111111"},
+			Metadata:  map[string]any{"website": "https://acme.com"},
+		},
+	}
+	handler := NewEnrichHandler(service.NewCompaniesService(repo))
+	e := echo.New()
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/enrich-result/aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("company_id")
+		c.SetParamValues("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+		if err := handler.GetResult(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := get("fields=emails,score")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp struct {
+		Data map[string]map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Data["enrichment"]) != 1 || resp.Data["enrichment"]["emails"] == nil {
+		t.Fatalf("expected only emails in enrichment, got %v", resp.Data["enrichment"])
+	}
+	if resp.Data["score"] == nil {
+		t.Fatalf("expected score to be selected")
+	}
+
+	rec = get("fields=score")
+	resp.Data = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := resp.Data["enrichment"]; ok || resp.Data["score"] == nil {
+		t.Fatalf("expected score only, got %v", resp.Data)
+	}
+
+	if rec := get("fields=emails,bogus"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown field, got %d", rec.Code)
+	}
+}
+
+func TestEnrichHandler_GetResult_ExcludeEmails(t *testing.T) {
+	get := func(query string) *httptest.ResponseRecorder {
+		repo := &enrichmentRepoStub{
+			result: &entity.CompanyEnrichment{
+				CompanyID: uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"),
+				Emails:    []string{"budi@acme.com", "info@acme.com"},
+				Metadata:  map[string]any{"email_classes": map[string]any{"info@acme.com": []any{"role"}}},
+			},
+		}
+		handler := NewEnrichHandler(service.NewCompaniesService(repo))
+		req := httptest.NewRequest(http.MethodGet, "/enrich-result/aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("company_id")
+		c.SetParamValues("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+		if err := handler.GetResult(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	var resp struct {
+		Data struct {
+			Enrichment struct {
+				Emails []string `json:"emails"`
+			} `json:"enrichment"`
+		} `json:"data"`
+	}
+	for query, want := range map[string]int{"": 2, "exclude_emails=role,disposable": 1} {
+		rec := get(query)
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Data.Enrichment.Emails) != want {
+			t.Fatalf("%q: expected %d emails, got %v", query, want, resp.Data.Enrichment.Emails)
+		}
+	}
+	if rec := get("exclude_emails=spam"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown email class, got %d", rec.Code)
+	}
+}
+
 func TestEnrichHandler_GetResult_MissingCompanyID(t *testing.T) {
 	handler := NewEnrichHandler(service.NewCompaniesService(&enrichmentRepoStub{}))
 	e := echo.New()
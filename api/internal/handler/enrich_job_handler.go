@@ -1,18 +1,27 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	"github.com/octobees/leads-generator/api/internal/auth"
 	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
 	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
 )
 
 // EnrichWorkerHandler forwards enrichment jobs to the worker service.
 type EnrichWorkerHandler struct {
-	worker WorkerPoster
+	worker    WorkerPoster
+	jobs      *service.EnrichJobService
+	companies *service.CompaniesService
+	callbacks *auth.CallbackSigner
 }
 
 // NewEnrichWorkerHandler constructs an enrichment job handler backed by HTTP client.
@@ -25,6 +34,13 @@ func NewEnrichWorkerHandlerWithWorker(worker WorkerPoster) *EnrichWorkerHandler
 	return &EnrichWorkerHandler{worker: worker}
 }
 
+// NewEnrichWorkerHandlerWithJobs injects a worker client, the job accounting service and,
+// optionally, the companies service used to serve results from the domain cache and the signer of
+// the callback token each job carries for /enrich-result.
+func NewEnrichWorkerHandlerWithJobs(worker WorkerPoster, jobs *service.EnrichJobService, companies *service.CompaniesService, callbacks *auth.CallbackSigner) *EnrichWorkerHandler {
+	return &EnrichWorkerHandler{worker: worker, jobs: jobs, companies: companies, callbacks: callbacks}
+}
+
 // Enqueue validates the request and forwards it to the worker enrichment endpoint.
 func (h *EnrichWorkerHandler) Enqueue(c echo.Context) error {
 	var req dto.EnrichJobRequest
@@ -38,16 +54,116 @@ func (h *EnrichWorkerHandler) Enqueue(c echo.Context) error {
 		return Error(c, http.StatusBadRequest, "company_id and website are required")
 	}
 
+	profile, err := service.ResolveEnrichDepth(req.Depth)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+
 	ctx := c.Request().Context()
-	data, err := h.worker.PostJSON(ctx, "/enrich", map[string]string{
-		"company_id": req.CompanyID,
-		"website":    req.Website,
-	}, middlewarepkg.RequestIDFromContext(c))
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+
+	if h.companies != nil && !req.ForceRefresh {
+		cached, err := h.companies.ReuseCachedEnrichment(ctx, req.CompanyID, req.Website, profile.Depth)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidCompanyID) {
+				return Error(c, http.StatusBadRequest, "invalid company_id")
+			}
+			log.Printf("request_id=%s domain cache lookup failed: %v", middlewarepkg.RequestIDFromContext(c), err)
+		} else if cached != nil {
+			return h.serveCached(c, userID, req, profile, cached)
+		}
+	}
+
+	// The job id goes out with the callback token, so the job is reserved under it.
+	jobID := uuid.New()
+	payload := dto.WorkerEnrichRequest{
+		CompanyID: req.CompanyID,
+		Website:   req.Website,
+		Depth:     profile.Depth,
+		MaxPages:  profile.MaxPages,
+	}
+	if h.callbacks != nil {
+		token, err := h.callbacks.Issue(auth.CallbackEnrich, jobID.String(), req.CompanyID)
+		if err != nil {
+			return Error(c, http.StatusInternalServerError, errCallbackToken)
+		}
+		payload.JobID = jobID.String()
+		payload.CallbackToken = token
+	}
+
+	// Reserving the cost before the worker is asked keeps concurrent requests from all passing
+	// the quota.
+	var quota *service.EnrichQuotaUsage
+	if h.jobs != nil {
+		_, quota, err = h.jobs.ReserveJob(ctx, jobID, userID, req.CompanyID, req.Website, profile)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrInvalidCompanyID):
+				return Error(c, http.StatusBadRequest, "invalid company_id")
+			case errors.Is(err, service.ErrEnrichQuotaExceeded):
+				return Error(c, http.StatusTooManyRequests, "enrichment quota exceeded")
+			}
+			return Error(c, http.StatusInternalServerError, "failed to reserve enrichment quota")
+		}
+	}
+
+	data, err := h.worker.PostJSON(ctx, "/enrich", payload, middlewarepkg.RequestIDFromContext(c))
 	if err != nil {
-		return Error(c, http.StatusBadGateway, err.Error())
+		h.releaseJob(c, jobID, err.Error())
+		return workerError(c, err)
 	}
 	if data == nil {
 		data = map[string]any{"status": "queued"}
 	}
+
+	if h.jobs != nil {
+		// A job left reserved stays charged, which errs on the side of the quota.
+		if err := h.jobs.AcceptJob(ctx, jobID); err != nil {
+			log.Printf("request_id=%s failed to mark enrichment job accepted: %v", middlewarepkg.RequestIDFromContext(c), err)
+		}
+		data["job_id"] = jobID.String()
+		if quota != nil {
+			data["quota"] = quota
+		}
+	}
+	data["depth"] = profile.Depth
+	data["cost"] = profile.Cost
+
 	return Success(c, http.StatusOK, "enrichment job queued", data)
 }
+
+// releaseJob gives back the reservation of a job the worker did not get, keeping it as failed with
+// the reason so an operator can retry it.
+func (h *EnrichWorkerHandler) releaseJob(c echo.Context, jobID uuid.UUID, reason string) {
+	if h.jobs == nil {
+		return
+	}
+	if err := h.jobs.ReleaseJob(c.Request().Context(), jobID, reason); err != nil {
+		log.Printf("request_id=%s failed to release enrichment job: %v", middlewarepkg.RequestIDFromContext(c), err)
+	}
+}
+
+func (h *EnrichWorkerHandler) serveCached(c echo.Context, userID string, req dto.EnrichJobRequest, profile service.EnrichDepthProfile, enrichment *entity.CompanyEnrichment) error {
+	data := map[string]any{
+		"status":     service.EnrichJobStatusCached,
+		"depth":      profile.Depth,
+		"cost":       0,
+		"enrichment": enrichment,
+	}
+	if domain, ok := enrichment.Metadata["cached_from_domain"]; ok {
+		data["domain"] = domain
+	}
+
+	if h.jobs != nil {
+		free := profile
+		free.Cost = 0
+		job, err := h.jobs.RecordJob(c.Request().Context(), uuid.Nil, userID, req.CompanyID, req.Website, free, service.EnrichJobStatusCached)
+		if err != nil {
+			log.Printf("request_id=%s failed to record cached enrichment job: %v", middlewarepkg.RequestIDFromContext(c), err)
+		} else {
+			data["job_id"] = job.ID.String()
+		}
+	}
+
+	return Success(c, http.StatusOK, "enrichment served from domain cache", data)
+}
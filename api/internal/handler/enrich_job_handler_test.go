@@ -1,13 +1,22 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
 )
 
 func newEnrichHandlerWithWorker(worker WorkerPoster) *EnrichWorkerHandler {
@@ -99,3 +108,227 @@ func TestEnrichWorkerHandler_WorkerResponses(t *testing.T) {
 		}
 	})
 }
+
+type enrichJobsRepoStub struct {
+	used    int
+	created int
+	last    *entity.EnrichmentJob
+}
+
+func (s *enrichJobsRepoStub) Create(ctx context.Context, job *entity.EnrichmentJob) error {
+	s.created++
+	s.last = job
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	return nil
+}
+
+func (s *enrichJobsRepoStub) Reserve(ctx context.Context, job *entity.EnrichmentJob, since time.Time, quota int) (int, error) {
+	if quota > 0 && s.used+job.Cost > quota {
+		return s.used, repository.ErrEnrichmentQuotaExceeded
+	}
+	s.used += job.Cost
+	return s.used - job.Cost, s.Create(ctx, job)
+}
+
+func (s *enrichJobsRepoStub) SetStatus(ctx context.Context, id uuid.UUID, status string, reason *string) error {
+	if s.last == nil || s.last.ID != id {
+		return repository.ErrRetryableJobNotFound
+	}
+	if status == service.EnrichJobStatusFailed {
+		s.used -= s.last.Cost
+	}
+	s.last.Status, s.last.Error = status, reason
+	return nil
+}
+
+type domainCacheStub struct {
+	record *entity.DomainEnrichment
+}
+
+func (s *domainCacheStub) UpsertDomainEnrichment(ctx context.Context, record *entity.DomainEnrichment) error {
+	return nil
+}
+
+func (s *domainCacheStub) GetDomainEnrichment(ctx context.Context, domain string) (*entity.DomainEnrichment, error) {
+	if s.record == nil || s.record.Domain != domain {
+		return nil, repository.ErrDomainEnrichmentNotFound
+	}
+	return s.record, nil
+}
+
+type capturingWorker struct {
+	payload any
+}
+
+func (w *capturingWorker) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+	w.payload = payload
+	return map[string]any{"status": "queued"}, nil
+}
+
+func TestEnrichWorkerHandler_Depth(t *testing.T) {
+	e := echo.New()
+	companyID := "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+
+	t.Run("invalid depth", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"abc","website":"https://example.com","depth":"extreme"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		_ = newEnrichHandlerWithWorker(&workerStub{}).Enqueue(c)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("forwards depth and records job", func(t *testing.T) {
+		worker := &capturingWorker{}
+		repo := &enrichJobsRepoStub{}
+		handler := NewEnrichWorkerHandlerWithJobs(worker, service.NewEnrichJobService(repo, 10), nil, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://example.com","depth":"deep"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+
+		_ = handler.Enqueue(c)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		payload, _ := worker.payload.(dto.WorkerEnrichRequest)
+		if payload.Depth != "deep" || payload.MaxPages != 20 {
+			t.Fatalf("expected depth forwarded to worker, got %+v", payload)
+		}
+		if repo.created != 1 || repo.last.Status != service.EnrichJobStatusAccepted {
+			t.Fatalf("expected an accepted job recorded, got %+v", repo.last)
+		}
+		if !strings.Contains(rec.Body.String(), `"cost":5`) {
+			t.Fatalf("expected cost in response, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("records the job the worker refused as failed", func(t *testing.T) {
+		repo := &enrichJobsRepoStub{}
+		handler := NewEnrichWorkerHandlerWithJobs(&workerStub{err: fmt.Errorf("boom")}, service.NewEnrichJobService(repo, 10), nil, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://example.com","depth":"deep"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+
+		_ = handler.Enqueue(c)
+		if rec.Code != http.StatusBadGateway {
+			t.Fatalf("expected 502, got %d", rec.Code)
+		}
+		if repo.created != 1 || repo.last.Status != service.EnrichJobStatusFailed || repo.last.Error == nil || *repo.last.Error != "boom" || repo.last.Depth != "deep" {
+			t.Fatalf("expected a failed job with the reason, got %+v", repo.last)
+		}
+		if repo.used != 0 {
+			t.Fatalf("expected the reservation released, still charged %d", repo.used)
+		}
+	})
+
+	t.Run("reserves the quota before calling the worker", func(t *testing.T) {
+		repo := &enrichJobsRepoStub{}
+		handler := NewEnrichWorkerHandlerWithJobs(&capturingWorker{}, service.NewEnrichJobService(repo, 6), nil, nil)
+
+		codes := make([]int, 0, 2)
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://example.com","depth":"deep"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+			_ = handler.Enqueue(c)
+			codes = append(codes, rec.Code)
+		}
+		if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests || repo.created != 1 {
+			t.Fatalf("expected the second deep job refused, got %v with %d jobs", codes, repo.created)
+		}
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		worker := &capturingWorker{}
+		handler := NewEnrichWorkerHandlerWithJobs(worker, service.NewEnrichJobService(&enrichJobsRepoStub{used: 9}, 10), nil, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://example.com","depth":"standard"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+
+		_ = handler.Enqueue(c)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", rec.Code)
+		}
+		if worker.payload != nil {
+			t.Fatalf("expected worker not to be called")
+		}
+	})
+}
+
+func TestEnrichWorkerHandler_DomainCache(t *testing.T) {
+	e := echo.New()
+	companyID := "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	cache := &domainCacheStub{record: &entity.DomainEnrichment{
+		Domain:    "example.com",
+		Depth:     service.EnrichDepthDeep,
+		Emails:    []string{"info@example.com"},
+		UpdatedAt: time.Now(),
+	}}
+
+	newHandler := func(worker WorkerPoster, repo *enrichJobsRepoStub) (*EnrichWorkerHandler, *enrichmentRepoStub) {
+		companiesRepo := &enrichmentRepoStub{}
+		companies := service.NewCompaniesService(companiesRepo, service.WithEnrichmentCache(cache, time.Hour))
+		return NewEnrichWorkerHandlerWithJobs(worker, service.NewEnrichJobService(repo, 10), companies, nil), companiesRepo
+	}
+
+	t.Run("serves cached result without calling worker", func(t *testing.T) {
+		worker := &capturingWorker{}
+		jobs := &enrichJobsRepoStub{used: 10}
+		handler, companiesRepo := newHandler(worker, jobs)
+
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://www.example.com/branch"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+
+		_ = handler.Enqueue(c)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if worker.payload != nil {
+			t.Fatalf("expected worker not to be called")
+		}
+		if companiesRepo.saved == nil || companiesRepo.saved.CompanyID.String() != companyID {
+			t.Fatalf("expected cached enrichment copied, got %+v", companiesRepo.saved)
+		}
+		if jobs.created != 1 || !strings.Contains(rec.Body.String(), `"status":"cached"`) || !strings.Contains(rec.Body.String(), `"cost":0`) {
+			t.Fatalf("expected zero-cost cached job, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("force refresh bypasses cache", func(t *testing.T) {
+		worker := &capturingWorker{}
+		handler, _ := newHandler(worker, &enrichJobsRepoStub{})
+
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://example.com","force_refresh":true}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+
+		_ = handler.Enqueue(c)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		if worker.payload == nil {
+			t.Fatalf("expected worker to be called")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ExportSchedulesHandler exposes the scheduled exports of the caller and their deliveries.
+type ExportSchedulesHandler struct {
+	schedules *service.ExportScheduleService
+}
+
+// NewExportSchedulesHandler constructs a handler instance.
+func NewExportSchedulesHandler(schedules *service.ExportScheduleService) *ExportSchedulesHandler {
+	return &ExportSchedulesHandler{schedules: schedules}
+}
+
+// List handles GET /export-schedules requests with the caller's schedules.
+func (h *ExportSchedulesHandler) List(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	schedules, err := h.schedules.List(c.Request().Context(), userID)
+	if err != nil {
+		return exportScheduleError(c, err, "failed to list export schedules")
+	}
+	return Success(c, http.StatusOK, "export schedules retrieved", schedules)
+}
+
+// Create handles POST /export-schedules requests.
+func (h *ExportSchedulesHandler) Create(c echo.Context) error {
+	var req dto.ExportScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	schedule, err := h.schedules.Create(c.Request().Context(), userID, req)
+	if err != nil {
+		return exportScheduleError(c, err, "failed to save export schedule")
+	}
+	return Success(c, http.StatusCreated, "export schedule created", schedule)
+}
+
+// Update handles PUT /export-schedules/:id requests.
+func (h *ExportSchedulesHandler) Update(c echo.Context) error {
+	var req dto.ExportScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	schedule, err := h.schedules.Update(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return exportScheduleError(c, err, "failed to save export schedule")
+	}
+	return Success(c, http.StatusOK, "export schedule updated", schedule)
+}
+
+// Delete handles DELETE /export-schedules/:id requests.
+func (h *ExportSchedulesHandler) Delete(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	if err := h.schedules.Delete(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return exportScheduleError(c, err, "failed to delete export schedule")
+	}
+	return Success(c, http.StatusOK, "export schedule deleted", nil)
+}
+
+// Deliveries handles GET /export-schedules/:id/deliveries requests with the latest runs of a
+// schedule, newest first.
+func (h *ExportSchedulesHandler) Deliveries(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	limit := parseIntDefault(c.QueryParam("limit"), service.DefaultExportDeliveryLimit)
+	deliveries, err := h.schedules.Deliveries(c.Request().Context(), userID, c.Param("id"), limit)
+	if err != nil {
+		return exportScheduleError(c, err, "failed to list export deliveries")
+	}
+	return Success(c, http.StatusOK, "export deliveries retrieved", deliveries)
+}
+
+// Run handles POST /export-schedules/:id/run requests, running a schedule at once. A failed
+// delivery is still a recorded run, so it is returned with its error rather than as one.
+func (h *ExportSchedulesHandler) Run(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	delivery, err := h.schedules.RunNow(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return exportScheduleError(c, err, "failed to run export schedule")
+	}
+	return Success(c, http.StatusOK, "export schedule ran", delivery)
+}
+
+func exportScheduleError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidExportSchedule):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidExportScheduleID):
+		return Error(c, http.StatusBadRequest, "invalid export schedule id")
+	case errors.Is(err, service.ErrExportScheduleNotFound):
+		return Error(c, http.StatusNotFound, "export schedule not found")
+	case errors.Is(err, service.ErrExportScheduleExists):
+		return Error(c, http.StatusConflict, "export schedule already exists")
+	case errors.Is(err, service.ErrExportDestinationUnavailable):
+		return Error(c, http.StatusNotImplemented, "export destination is not enabled")
+	default:
+		// Schedules name their export template, so its errors surface here too.
+		return exportTemplateError(c, err, fallback)
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/mailer"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type exportSchedulesRepoStub struct {
+	schedules []entity.ExportSchedule
+}
+
+func (s *exportSchedulesRepoStub) List(ctx context.Context, userID uuid.UUID) ([]entity.ExportSchedule, error) {
+	return s.schedules, nil
+}
+
+func (s *exportSchedulesRepoStub) Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportSchedule, error) {
+	return nil, repository.ErrExportScheduleNotFound
+}
+
+func (s *exportSchedulesRepoStub) Create(ctx context.Context, schedule *entity.ExportSchedule) error {
+	for _, existing := range s.schedules {
+		if existing.Name == schedule.Name {
+			return repository.ErrExportScheduleDuplicate
+		}
+	}
+	schedule.ID = uuid.New()
+	s.schedules = append(s.schedules, *schedule)
+	return nil
+}
+
+func (s *exportSchedulesRepoStub) Update(ctx context.Context, schedule *entity.ExportSchedule) error {
+	return repository.ErrExportScheduleNotFound
+}
+
+func (s *exportSchedulesRepoStub) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return repository.ErrExportScheduleNotFound
+}
+
+func (s *exportSchedulesRepoStub) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.ExportSchedule, error) {
+	return nil, nil
+}
+
+func (s *exportSchedulesRepoStub) Reschedule(ctx context.Context, id uuid.UUID, ranAt, next time.Time) error {
+	return nil
+}
+
+func (s *exportSchedulesRepoStub) RecordDelivery(ctx context.Context, delivery *entity.ExportDelivery) error {
+	return nil
+}
+
+func (s *exportSchedulesRepoStub) ListDeliveries(ctx context.Context, scheduleID uuid.UUID, limit int) ([]entity.ExportDelivery, error) {
+	return nil, nil
+}
+
+type discardSender struct{}
+
+func (discardSender) SendMessage(ctx context.Context, msg mailer.Message) (string, error) {
+	return "<id@example.com>", nil
+}
+
+func TestExportSchedulesHandler(t *testing.T) {
+	user := testsupport.NewUser().Build()
+	h := NewExportSchedulesHandler(service.NewExportScheduleService(
+		&exportSchedulesRepoStub{},
+		service.NewCompaniesService(testsupport.NewStubCompaniesRepository()),
+		testsupport.NewMemoryUsersRepository(user),
+		service.WithExportMailer(discardSender{}),
+	))
+	weekly := map[string]any{"name": "Weekly", "destination": "email", "target": "sales@example.com", "cron": "0 7 * * 1", "timezone": "Asia/Jakarta", "filter": map[string]any{"city": "Jakarta"}}
+
+	tests := []struct {
+		name     string
+		call     func() (*httptest.ResponseRecorder, error)
+		wantCode int
+	}{
+		{"created", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/export-schedules", weekly)
+			return rec, h.Create(testsupport.WithUser(c, user))
+		}, http.StatusCreated},
+		{"duplicate", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/export-schedules", weekly)
+			return rec, h.Create(testsupport.WithUser(c, user))
+		}, http.StatusConflict},
+		{"invalid cron", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/export-schedules", map[string]any{"name": "Daily", "destination": "email", "target": "sales@example.com", "cron": "daily"})
+			return rec, h.Create(testsupport.WithUser(c, user))
+		}, http.StatusBadRequest},
+		{"destination not enabled", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/export-schedules", map[string]any{"name": "Daily", "destination": "gcs", "target": "leads", "cron": "@daily"})
+			return rec, h.Create(testsupport.WithUser(c, user))
+		}, http.StatusNotImplemented},
+		{"templates not enabled", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/export-schedules", map[string]any{"name": "Daily", "destination": "email", "target": "sales@example.com", "cron": "@daily", "template_id": uuid.NewString()})
+			return rec, h.Create(testsupport.WithUser(c, user))
+		}, http.StatusNotImplemented},
+		{"invalid id", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewContext(http.MethodPost, "/export-schedules/nope/run")
+			return rec, h.Run(testsupport.WithUser(testsupport.WithParams(c, "id", "nope"), user))
+		}, http.StatusBadRequest},
+		{"unknown", func() (*httptest.ResponseRecorder, error) {
+			id := uuid.NewString()
+			c, rec := testsupport.NewContext(http.MethodGet, "/export-schedules/"+id+"/deliveries")
+			return rec, h.Deliveries(testsupport.WithUser(testsupport.WithParams(c, "id", id), user))
+		}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testsupport.AssertStatus(t, rec, tt.wantCode)
+		})
+	}
+
+	c, rec := testsupport.NewContext(http.MethodGet, "/export-schedules")
+	if err := h.List(testsupport.WithUser(c, user)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []entity.ExportSchedule
+	testsupport.DecodeData(t, rec, &got)
+	if len(got) != 1 || got[0].Filter.City != "Jakarta" || got[0].Timezone != "Asia/Jakarta" {
+		t.Fatalf("unexpected schedules: %+v", got)
+	}
+}
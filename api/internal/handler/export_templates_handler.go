@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ListExportTemplates handles GET /exports/templates requests with the caller's templates.
+func (h *CompaniesHandler) ListExportTemplates(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	templates, err := h.service.ListExportTemplates(c.Request().Context(), userID)
+	if err != nil {
+		return exportTemplateError(c, err, "failed to list export templates")
+	}
+	return Success(c, http.StatusOK, "export templates retrieved", templates)
+}
+
+// CreateExportTemplate handles POST /exports/templates requests.
+func (h *CompaniesHandler) CreateExportTemplate(c echo.Context) error {
+	var req dto.ExportTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	template, err := h.service.CreateExportTemplate(c.Request().Context(), userID, req)
+	if err != nil {
+		return exportTemplateError(c, err, "failed to save export template")
+	}
+	return Success(c, http.StatusCreated, "export template created", template)
+}
+
+// UpdateExportTemplate handles PUT /exports/templates/:id requests.
+func (h *CompaniesHandler) UpdateExportTemplate(c echo.Context) error {
+	var req dto.ExportTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	template, err := h.service.UpdateExportTemplate(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return exportTemplateError(c, err, "failed to save export template")
+	}
+	return Success(c, http.StatusOK, "export template updated", template)
+}
+
+// DeleteExportTemplate handles DELETE /exports/templates/:id requests.
+func (h *CompaniesHandler) DeleteExportTemplate(c echo.Context) error {
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	if err := h.service.DeleteExportTemplate(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return exportTemplateError(c, err, "failed to delete export template")
+	}
+	return Success(c, http.StatusOK, "export template deleted", nil)
+}
+
+// parseExportLocaleQuery reads the locale params of an export request.
+func parseExportLocaleQuery(c echo.Context) (dto.ExportLocaleQuery, error) {
+	query := dto.ExportLocaleQuery{
+		Template:    strings.TrimSpace(c.QueryParam("template")),
+		Delimiter:   c.QueryParam("delimiter"),
+		DecimalMark: c.QueryParam("decimal_mark"),
+		DateFormat:  strings.TrimSpace(c.QueryParam("date_format")),
+	}
+	if raw := strings.TrimSpace(c.QueryParam("bom")); raw != "" {
+		bom, err := strconv.ParseBool(raw)
+		if err != nil {
+			return dto.ExportLocaleQuery{}, fmt.Errorf("bom must be true or false")
+		}
+		query.BOM = &bom
+	}
+	return query, nil
+}
+
+func exportTemplateError(c echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidExportLocale), errors.Is(err, service.ErrInvalidExportTemplate):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidExportTemplateID):
+		return Error(c, http.StatusBadRequest, "invalid export template id")
+	case errors.Is(err, service.ErrExportTemplateNotFound):
+		return Error(c, http.StatusNotFound, "export template not found")
+	case errors.Is(err, service.ErrExportTemplateExists):
+		return Error(c, http.StatusConflict, "export template already exists")
+	case errors.Is(err, service.ErrExportTemplatesUnavailable):
+		return Error(c, http.StatusNotImplemented, "export templates are not enabled")
+	default:
+		return Error(c, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type exportTemplatesStub struct {
+	created *entity.ExportTemplate
+}
+
+func (s *exportTemplatesStub) List(ctx context.Context, userID uuid.UUID) ([]entity.ExportTemplate, error) {
+	return nil, nil
+}
+
+func (s *exportTemplatesStub) Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportTemplate, error) {
+	if s.created == nil || s.created.ID != id || s.created.UserID != userID {
+		return nil, repository.ErrExportTemplateNotFound
+	}
+	return s.created, nil
+}
+
+func (s *exportTemplatesStub) Create(ctx context.Context, template *entity.ExportTemplate) error {
+	template.ID = uuid.New()
+	s.created = template
+	return nil
+}
+
+func (s *exportTemplatesStub) Update(ctx context.Context, template *entity.ExportTemplate) error {
+	return repository.ErrExportTemplateNotFound
+}
+
+func (s *exportTemplatesStub) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return repository.ErrExportTemplateNotFound
+}
+
+func TestCompaniesHandler_ExportLocale(t *testing.T) {
+	templates := &exportTemplatesStub{}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{},
+		service.WithExports(&companyExportsStub{matched: 1}, nil),
+		service.WithExportTemplates(templates),
+	))
+	user := testsupport.NewUser().Build()
+
+	c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/exports/templates", dto.ExportTemplateRequest{Name: "Excel ID", Delimiter: ";", DecimalMark: ",", BOM: true})
+	if err := handler.CreateExportTemplate(testsupport.WithUser(c, user)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusCreated)
+	if templates.created == nil || templates.created.UserID != user.ID {
+		t.Fatalf("expected template owned by the caller, got %+v", templates.created)
+	}
+
+	export := func(target string) (int, string) {
+		c, rec := testsupport.NewContext(http.MethodGet, target)
+		if err := handler.Export(testsupport.WithUser(c, user)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code, rec.Body.String()
+	}
+	status, body := export("/exports/companies?template=" + templates.created.ID.String())
+	if status != http.StatusOK || !strings.HasPrefix(body, "\uFEFFid;company;") {
+		t.Fatalf("expected a BOM and semicolons, got %d %q", status, body)
+	}
+	if status, body = export("/exports/companies?delimiter=%7C&bom=false"); status != http.StatusOK || !strings.HasPrefix(body, "id|company|") {
+		t.Fatalf("expected pipe separated export, got %d %q", status, body)
+	}
+	for target, want := range map[string]int{
+		"/exports/companies?bom=maybe":                      http.StatusBadRequest,
+		"/exports/companies?date_format=yyyy":               http.StatusBadRequest,
+		"/exports/companies?template=" + uuid.NewString():   http.StatusNotFound,
+		"/exports/companies?template=not-a-uuid":            http.StatusBadRequest,
+		"/exports/companies?delimiter=%2C&decimal_mark=%2C": http.StatusBadRequest,
+	} {
+		if status, body := export(target); status != want {
+			t.Fatalf("%s: expected %d, got %d %s", target, want, status, body)
+		}
+	}
+
+	c, rec = testsupport.NewContext(http.MethodDelete, "/exports/templates/"+uuid.NewString())
+	if err := handler.DeleteExportTemplate(testsupport.WithParams(testsupport.WithUser(c, user), "id", uuid.NewString())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusNotFound)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// FeatureTogglesHandler lets admins switch worker-dependent features off and gates their routes.
+type FeatureTogglesHandler struct {
+	toggles *service.FeatureToggleService
+}
+
+// NewFeatureTogglesHandler constructs a handler instance.
+func NewFeatureTogglesHandler(toggles *service.FeatureToggleService) *FeatureTogglesHandler {
+	return &FeatureTogglesHandler{toggles: toggles}
+}
+
+// List handles GET /admin/features requests.
+func (h *FeatureTogglesHandler) List(c echo.Context) error {
+	toggles, err := h.toggles.List(c.Request().Context())
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list feature toggles")
+	}
+	return Success(c, http.StatusOK, "feature toggles retrieved", toggles)
+}
+
+// Update handles PUT /admin/features/:feature requests.
+func (h *FeatureTogglesHandler) Update(c echo.Context) error {
+	var req dto.FeatureToggleRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	toggle, err := h.toggles.Set(c.Request().Context(), c.Param("feature"), req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownFeature):
+			return Error(c, http.StatusNotFound, "unknown feature")
+		case errors.Is(err, service.ErrInvalidFeatureToggle):
+			return Error(c, http.StatusBadRequest, err.Error())
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to save feature toggle")
+		}
+	}
+	return Success(c, http.StatusOK, "feature toggle updated", toggle)
+}
+
+// Gate answers requests with 503 while admins have switched the feature off. The response carries
+// the toggle, and Retry-After when an ETA in the future was given.
+func (h *FeatureTogglesHandler) Gate(feature string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var disabled *service.FeatureDisabledError
+			if err := h.toggles.Check(c.Request().Context(), feature); !errors.As(err, &disabled) {
+				return next(c)
+			}
+			if eta := disabled.Toggle.ETA; eta != nil && eta.After(time.Now()) {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(*eta).Seconds()))))
+			}
+			return c.JSON(http.StatusServiceUnavailable, APIResponse{
+				Status:  "error",
+				Message: disabled.Error(),
+				Data:    disabled.Toggle,
+			})
+		}
+	}
+}
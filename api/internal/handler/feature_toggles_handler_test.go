@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type featureTogglesRepoStub struct {
+	toggles map[string]entity.FeatureToggle
+}
+
+func (s *featureTogglesRepoStub) List(ctx context.Context) ([]entity.FeatureToggle, error) {
+	toggles := make([]entity.FeatureToggle, 0, len(s.toggles))
+	for _, toggle := range s.toggles {
+		toggles = append(toggles, toggle)
+	}
+	return toggles, nil
+}
+
+func (s *featureTogglesRepoStub) Get(ctx context.Context, feature string) (*entity.FeatureToggle, error) {
+	toggle, ok := s.toggles[feature]
+	if !ok {
+		return nil, nil
+	}
+	return &toggle, nil
+}
+
+func (s *featureTogglesRepoStub) Upsert(ctx context.Context, toggle *entity.FeatureToggle) error {
+	s.toggles[toggle.Feature] = *toggle
+	return nil
+}
+
+func TestFeatureTogglesHandler_UpdateAndGate(t *testing.T) {
+	e := echo.New()
+	handler := NewFeatureTogglesHandler(service.NewFeatureToggleService(&featureTogglesRepoStub{toggles: map[string]entity.FeatureToggle{}}))
+
+	update := func(feature, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/features/"+feature, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("feature")
+		c.SetParamValues(feature)
+		_ = handler.Update(c)
+		return rec
+	}
+	enqueued := 0
+	scrape := handler.Gate(entity.FeatureScrape)(func(c echo.Context) error {
+		enqueued++
+		return c.NoContent(http.StatusOK)
+	})
+	call := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		_ = scrape(e.NewContext(httptest.NewRequest(http.MethodPost, "/scrape", nil), rec))
+		return rec
+	}
+
+	if rec := call(); rec.Code != http.StatusOK || enqueued != 1 {
+		t.Fatalf("expected the enabled feature to pass, got %d", rec.Code)
+	}
+	if rec := update("exports", `{"disabled":true}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown feature, got %d", rec.Code)
+	}
+	if rec := update(entity.FeatureScrape, `{"disabled":true,"message":"Places provider outage","eta":"2999-01-01T00:00:00Z"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := call()
+	if rec.Code != http.StatusServiceUnavailable || enqueued != 1 {
+		t.Fatalf("expected 503 while disabled, got %d", rec.Code)
+	}
+	var body struct {
+		Message string               `json:"message"`
+		Data    entity.FeatureToggle `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Message != "scraping is temporarily disabled: Places provider outage (expected back by 2999-01-01T00:00:00Z)" || body.Data.ETA == nil {
+		t.Fatalf("unexpected 503 body: %s", rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After for a future ETA")
+	}
+
+	if rec := update(entity.FeatureScrape, `{"disabled":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec := call(); rec.Code != http.StatusOK || enqueued != 2 {
+		t.Fatalf("expected the re-enabled feature to pass, got %d", rec.Code)
+	}
+}
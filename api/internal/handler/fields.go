@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// parseFields reads a comma-separated list query param (fields, include) and checks every name
+// against allowed. An empty param returns nil.
+func parseFields(param, raw string, allowed map[string]bool) (map[string]bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	fields := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !allowed[name] {
+			return nil, fmt.Errorf("invalid %s %q (use %s)", param, name, strings.Join(sortedKeys(allowed), ", "))
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// jsonFieldNames lists the JSON keys a struct value serialises to.
+func jsonFieldNames(value any) map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(value)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// selectFields serialises value and keeps only the selected top-level keys. A nil selection keeps
+// the value untouched.
+func selectFields(value any, fields map[string]bool) (any, error) {
+	if fields == nil {
+		return value, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return nil, err
+	}
+	for key := range object {
+		if !fields[key] {
+			delete(object, key)
+		}
+	}
+	return object, nil
+}
+
+// selectFieldsEach applies selectFields to every element of items.
+func selectFieldsEach[T any](items []T, fields map[string]bool) (any, error) {
+	if fields == nil {
+		return items, nil
+	}
+	selected := make([]any, 0, len(items))
+	for _, item := range items {
+		trimmed, err := selectFields(item, fields)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, trimmed)
+	}
+	return selected, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// FreshnessHandler exposes data freshness gauges.
+type FreshnessHandler struct {
+	freshness *service.FreshnessService
+}
+
+// NewFreshnessHandler constructs a handler instance.
+func NewFreshnessHandler(freshness *service.FreshnessService) *FreshnessHandler {
+	return &FreshnessHandler{freshness: freshness}
+}
+
+// Get handles GET /freshness requests.
+func (h *FreshnessHandler) Get(c echo.Context) error {
+	freshness, err := h.freshness.Freshness(
+		c.Request().Context(),
+		c.QueryParam("city"),
+		c.QueryParam("type_business"),
+		parseIntDefault(c.QueryParam("limit"), 0),
+	)
+	if err != nil {
+		return queryError(c, err, "failed to compute freshness")
+	}
+	return Success(c, http.StatusOK, "freshness retrieved", freshness)
+}
+
+// ScrapePreview handles POST /scrape/preview requests, which take the payload of POST /scrape and
+// tell whether the catalogue already holds fresh results for it.
+func (h *FreshnessHandler) ScrapePreview(c echo.Context) error {
+	var req dto.ScrapeRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	if msg := normalizeScrapeRequest(&req); msg != "" {
+		return Error(c, http.StatusBadRequest, msg)
+	}
+
+	preview, err := h.freshness.ScrapePreview(c.Request().Context(), req.TypeBusiness, req.City, req.Country)
+	if err != nil {
+		return queryError(c, err, "failed to preview scrape")
+	}
+	return Success(c, http.StatusOK, "scrape preview retrieved", preview)
+}
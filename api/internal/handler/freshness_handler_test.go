@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type freshnessRepoStub struct {
+	err      error
+	filter   repository.FreshnessFilter
+	coverage []string
+}
+
+func (s *freshnessRepoStub) FreshnessTotals(ctx context.Context, since time.Time) (*entity.DataFreshness, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &entity.DataFreshness{PendingEnrichments: 2}, nil
+}
+
+func (s *freshnessRepoStub) SegmentFreshness(ctx context.Context, filter repository.FreshnessFilter, since time.Time) ([]entity.SegmentFreshness, error) {
+	s.filter = filter
+	return []entity.SegmentFreshness{{City: "Jakarta", TypeBusiness: "cafe", Companies: 3}}, nil
+}
+
+func (s *freshnessRepoStub) ScrapeCoverage(ctx context.Context, typeBusiness, city, country string) (*entity.ScrapePreview, error) {
+	s.coverage = []string{typeBusiness, city, country}
+	return &entity.ScrapePreview{TypeBusiness: typeBusiness, City: city, Country: country}, nil
+}
+
+func TestFreshnessHandler_Get(t *testing.T) {
+	e := echo.New()
+	repo := &freshnessRepoStub{}
+	handler := NewFreshnessHandler(service.NewFreshnessService(repo, 0))
+
+	req := httptest.NewRequest(http.MethodGet, "/freshness?city=Jakarta&type_business=cafe&limit=5", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if repo.filter.City != "Jakarta" || repo.filter.TypeBusiness != "cafe" || repo.filter.Limit != 5 {
+		t.Fatalf("unexpected filter: %+v", repo.filter)
+	}
+
+	var body struct {
+		Data entity.DataFreshness `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.PendingEnrichments != 2 || len(body.Data.Segments) != 1 {
+		t.Fatalf("unexpected freshness payload: %s", rec.Body.String())
+	}
+
+	handler = NewFreshnessHandler(service.NewFreshnessService(&freshnessRepoStub{err: errors.New("boom")}, 0))
+	rec = httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(httptest.NewRequest(http.MethodGet, "/freshness", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestFreshnessHandler_ScrapePreview(t *testing.T) {
+	e := echo.New()
+	repo := &freshnessRepoStub{}
+	handler := NewFreshnessHandler(service.NewFreshnessService(repo, 0))
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"location", `{"type_business":" cafe ","location":"Bandung, Indonesia"}`, http.StatusOK},
+		{"missing type", `{"city":"Bandung","country":"Indonesia"}`, http.StatusBadRequest},
+		{"missing city", `{"type_business":"cafe","country":"Indonesia"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/scrape/preview", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			_ = handler.ScrapePreview(e.NewContext(req, rec))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+	if len(repo.coverage) != 3 || repo.coverage[0] != "cafe" || repo.coverage[1] != "Bandung" || repo.coverage[2] != "Indonesia" {
+		t.Fatalf("unexpected coverage arguments %v", repo.coverage)
+	}
+}
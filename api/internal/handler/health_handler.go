@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultProbeTimeout = 2 * time.Second
+
+// HealthCheck describes a single dependency probe used by the readiness endpoint.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// DependencyStatus reports the outcome of a single dependency probe.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthHandler exposes liveness and readiness endpoints.
+type HealthHandler struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	readOnly func() bool
+}
+
+// NewHealthHandler wires a handler that runs the supplied probes on readiness requests.
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks, timeout: defaultProbeTimeout}
+}
+
+// NewHealthHandlerWithReadOnly also reports whether the database currently rejects writes. A
+// read-only database keeps the service ready because reads are still served.
+func NewHealthHandlerWithReadOnly(readOnly func() bool, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks, timeout: defaultProbeTimeout, readOnly: readOnly}
+}
+
+// Live handles GET /healthz requests; it only reports that the process is serving.
+func (h *HealthHandler) Live(c echo.Context) error {
+	return Success(c, http.StatusOK, "service healthy", map[string]any{"status": "ok"})
+}
+
+// Ready handles GET /readyz requests and returns 503 when any dependency is down.
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), h.timeout)
+	defer cancel()
+
+	results := make(map[string]DependencyStatus, len(h.checks))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range h.checks {
+		if check.Check == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Check(ctx)
+			status := DependencyStatus{Status: "ok", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = "error"
+				status.Error = err.Error()
+			}
+			mu.Lock()
+			results[check.Name] = status
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, status := range results {
+		if status.Status != "ok" {
+			ready = false
+			break
+		}
+	}
+
+	payload := map[string]any{"checks": results}
+	readOnly := false
+	if h.readOnly != nil {
+		readOnly = h.readOnly()
+		payload["read_only"] = readOnly
+	}
+	if !ready {
+		payload["status"] = "unavailable"
+		return c.JSON(http.StatusServiceUnavailable, APIResponse{
+			Status:  "error",
+			Message: "service not ready",
+			Data:    payload,
+		})
+	}
+	payload["status"] = "ok"
+	if readOnly {
+		payload["status"] = "read_only"
+		return Success(c, http.StatusOK, "service ready (temporarily read-only)", payload)
+	}
+	return Success(c, http.StatusOK, "service ready", payload)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestHealthHandler_Ready_AllHealthy(t *testing.T) {
+	h := NewHealthHandler(
+		HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "worker", Check: func(ctx context.Context) error { return nil }},
+	)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.Ready(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Status string `json:"status"`
+		Data   struct {
+			Status string                      `json:"status"`
+			Checks map[string]DependencyStatus `json:"checks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Data.Status != "ok" || len(payload.Data.Checks) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if payload.Data.Checks["database"].Status != "ok" {
+		t.Fatalf("expected database ok, got %+v", payload.Data.Checks["database"])
+	}
+}
+
+func TestHealthHandler_Ready_DependencyDown(t *testing.T) {
+	h := NewHealthHandler(
+		HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "worker", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+	)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.Ready(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Status string `json:"status"`
+		Data   struct {
+			Checks map[string]DependencyStatus `json:"checks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	worker := payload.Data.Checks["worker"]
+	if payload.Status != "error" || worker.Status != "error" || worker.Error != "connection refused" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	h := NewHealthHandler(HealthCheck{Name: "database", Check: func(ctx context.Context) error {
+		return errors.New("down")
+	}})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.Live(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected liveness to ignore dependencies, got %d", rec.Code)
+	}
+}
+
+func TestHealthHandler_Ready_ReadOnly(t *testing.T) {
+	h := NewHealthHandlerWithReadOnly(func() bool { return true },
+		HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }},
+	)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := h.Ready(e.NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected read-only service to stay ready, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Data struct {
+			Status   string `json:"status"`
+			ReadOnly bool   `json:"read_only"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Data.Status != "read_only" || !payload.Data.ReadOnly {
+		t.Fatalf("unexpected payload: %+v", payload.Data)
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ImportJobsHandler exposes asynchronous company imports to administrators.
+type ImportJobsHandler struct {
+	imports *service.ImportJobService
+}
+
+// NewImportJobsHandler wires a handler backed by the import job service.
+func NewImportJobsHandler(imports *service.ImportJobService) *ImportJobsHandler {
+	return &ImportJobsHandler{imports: imports}
+}
+
+// Create handles POST /admin/imports requests. It accepts the same file, mode and mapping fields as
+// /admin/upload-csv, stores the file and answers 202 with the queued job; poll
+// GET /admin/imports/:id for progress.
+func (h *ImportJobsHandler) Create(c echo.Context) error {
+	mode, err := service.ParseImportMode(c.FormValue("mode"))
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "invalid mode (use overwrite, fill_missing or skip_existing)")
+	}
+
+	mapping, err := service.ParseCSVColumnMapping(c.FormValue("mapping"))
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "missing csv file")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "unable to open file")
+	}
+	defer file.Close()
+
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	job, err := h.imports.Create(c.Request().Context(), userID, fileHeader.Filename, mode, mapping, file)
+	if err != nil {
+		if errors.Is(err, service.ErrImportQueueFull) {
+			return Error(c, http.StatusServiceUnavailable, "import queue is full, try again later")
+		}
+		log.Printf("request_id=%s failed to queue import: %v", middlewarepkg.RequestIDFromContext(c), err)
+		return Error(c, http.StatusInternalServerError, "failed to queue import")
+	}
+	return Success(c, http.StatusAccepted, "import queued", job)
+}
+
+// List handles GET /admin/imports requests.
+func (h *ImportJobsHandler) List(c echo.Context) error {
+	limit := parseIntDefault(c.QueryParam("limit"), 50)
+	offset := parseIntDefault(c.QueryParam("offset"), 0)
+	jobs, err := h.imports.List(c.Request().Context(), limit, offset)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list imports")
+	}
+	return Success(c, http.StatusOK, "imports retrieved", jobs)
+}
+
+// Get handles GET /admin/imports/:id requests with the job progress, row errors and summary.
+func (h *ImportJobsHandler) Get(c echo.Context) error {
+	job, err := h.imports.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidImportJobID):
+			return Error(c, http.StatusBadRequest, "invalid import id")
+		case errors.Is(err, service.ErrImportJobNotFound):
+			return Error(c, http.StatusNotFound, "import not found")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to load import")
+		}
+	}
+	return Success(c, http.StatusOK, "import retrieved", job)
+}
+
+// CreateUserImport handles POST /admin/users/import requests. The file holds email, role and org
+// columns; every row is invited in the background and GET /admin/imports/:id reports each one with
+// its invitation token.
+func (h *ImportJobsHandler) CreateUserImport(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "missing csv file")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return Error(c, http.StatusBadRequest, "unable to open file")
+	}
+	defer file.Close()
+
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	job, err := h.imports.CreateUserImport(c.Request().Context(), userID, fileHeader.Filename, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserImportsUnavailable):
+			return Error(c, http.StatusNotImplemented, "user imports are not enabled")
+		case errors.Is(err, service.ErrImportQueueFull):
+			return Error(c, http.StatusServiceUnavailable, "import queue is full, try again later")
+		}
+		log.Printf("request_id=%s failed to queue user import: %v", middlewarepkg.RequestIDFromContext(c), err)
+		return Error(c, http.StatusInternalServerError, "failed to queue import")
+	}
+	return Success(c, http.StatusAccepted, "user import queued", job)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type importJobsRepoStub struct {
+	jobs map[uuid.UUID]entity.ImportJob
+}
+
+func (s *importJobsRepoStub) Create(ctx context.Context, job *entity.ImportJob) error {
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *importJobsRepoStub) Update(ctx context.Context, job *entity.ImportJob) error {
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *importJobsRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error) {
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, repository.ErrImportJobNotFound
+	}
+	return &job, nil
+}
+
+func (s *importJobsRepoStub) List(ctx context.Context, limit, offset int) ([]entity.ImportJob, error) {
+	jobs := make([]entity.ImportJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *importJobsRepoStub) ListUnfinished(ctx context.Context) ([]entity.ImportJob, error) {
+	return nil, nil
+}
+
+func TestImportJobsHandler(t *testing.T) {
+	scratch, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	companies := service.NewCompaniesService(&stubCompaniesRepository{})
+	imports := service.NewImportJobService(&importJobsRepoStub{jobs: map[uuid.UUID]entity.ImportJob{}}, companies, nil, scratch, 0, 1)
+	h := NewImportJobsHandler(imports)
+	e := echo.New()
+
+	req, rec := multipartRequest(t, "file", "test.csv", validCSV())
+	if err := h.Create(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data entity.ImportJob `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Data.Status != entity.ImportJobStatusQueued || created.Data.Filename != "test.csv" {
+		t.Fatalf("unexpected job: %+v", created.Data)
+	}
+
+	// Nothing runs the queue, so a second upload finds it full.
+	req, rec = multipartRequest(t, "file", "test.csv", validCSV())
+	if err := h.Create(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for a full queue, got %d", rec.Code)
+	}
+
+	tests := map[string]struct {
+		id     string
+		status int
+	}{
+		"existing": {created.Data.ID.String(), http.StatusOK},
+		"invalid":  {"nope", http.StatusBadRequest},
+		"unknown":  {uuid.NewString(), http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/imports/"+tt.id, nil), rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			if err := h.Get(c); err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+type invitationsRepoStub struct{}
+
+func (invitationsRepoStub) UpsertInvitation(ctx context.Context, invitation *entity.UserInvitation, tokenHash string) (bool, error) {
+	return true, nil
+}
+
+func (invitationsRepoStub) AcceptInvitation(ctx context.Context, tokenHash, passwordHash string) (*entity.User, error) {
+	return nil, repository.ErrInvitationNotFound
+}
+
+func TestImportJobsHandler_CreateUserImport(t *testing.T) {
+	scratch, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	repo := &importJobsRepoStub{jobs: map[uuid.UUID]entity.ImportJob{}}
+	e := echo.New()
+
+	disabled := NewImportJobsHandler(service.NewImportJobService(repo, nil, nil, scratch, 0, 1))
+	req, rec := multipartRequest(t, "file", "seats.csv", "email,role,org\nana@agency.test,user,Agency\n")
+	if err := disabled.CreateUserImport(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without user imports, got %d", rec.Code)
+	}
+
+	h := NewImportJobsHandler(service.NewImportJobService(repo, nil, nil, scratch, 0, 1,
+		service.WithUserImports(service.NewInvitationService(invitationsRepoStub{}))))
+	req, rec = multipartRequest(t, "file", "seats.csv", "email,role,org\nana@agency.test,user,Agency\n")
+	if err := h.CreateUserImport(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data entity.ImportJob `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Data.Kind != entity.ImportJobKindUsers || created.Data.Status != entity.ImportJobStatusQueued {
+		t.Fatalf("unexpected job: %+v", created.Data)
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// IngestRunsHandler receives scrapes the worker streams in numbered batches.
+type IngestRunsHandler struct {
+	runs *service.IngestRunsService
+}
+
+// NewIngestRunsHandler constructs a handler instance.
+func NewIngestRunsHandler(runs *service.IngestRunsService) *IngestRunsHandler {
+	return &IngestRunsHandler{runs: runs}
+}
+
+// Start handles POST /ingest/runs requests. Starting an open run_id again answers 200 with the run,
+// whose next_sequence tells the worker where to resume. Behind the worker callback guard the run is
+// the token's job: run_id defaults to it and any other id is answered 403.
+func (h *IngestRunsHandler) Start(c echo.Context) error {
+	var req dto.IngestRunStartRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid JSON payload")
+	}
+	if claims := middlewarepkg.CallbackFromContext(c); claims != nil && strings.TrimSpace(req.RunID) == "" {
+		req.RunID = claims.JobID()
+	}
+	if !callbackCoversRun(c, req.RunID) {
+		return Error(c, http.StatusForbidden, "callback token does not cover this run")
+	}
+	run, created, err := h.runs.Start(c.Request().Context(), req)
+	if err != nil {
+		return ingestRunError(c, err)
+	}
+	if !created {
+		return Success(c, http.StatusOK, "ingest run resumed", run)
+	}
+	return Success(c, http.StatusCreated, "ingest run started", run)
+}
+
+// Get handles GET /ingest/runs/:id requests.
+func (h *IngestRunsHandler) Get(c echo.Context) error {
+	if !callbackCoversRun(c, c.Param("id")) {
+		return Error(c, http.StatusForbidden, "callback token does not cover this run")
+	}
+	run, err := h.runs.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return ingestRunError(c, err)
+	}
+	return Success(c, http.StatusOK, "ingest run retrieved", run)
+}
+
+// AppendBatch handles POST /ingest/runs/:id/batches requests.
+func (h *IngestRunsHandler) AppendBatch(c echo.Context) error {
+	if !callbackCoversRun(c, c.Param("id")) {
+		return Error(c, http.StatusForbidden, "callback token does not cover this run")
+	}
+	var req dto.IngestBatchRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid JSON payload")
+	}
+	batch, err := h.runs.AppendBatch(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return ingestRunError(c, err)
+	}
+	if batch.Duplicate {
+		return Success(c, http.StatusOK, "ingest batch already received", batch)
+	}
+	return Success(c, http.StatusCreated, "ingest batch stored", batch)
+}
+
+// Finish handles POST /ingest/runs/:id/finish requests. A run missing batches is answered 409 with
+// the missing sequences, and stays open so the worker can send them.
+func (h *IngestRunsHandler) Finish(c echo.Context) error {
+	if !callbackCoversRun(c, c.Param("id")) {
+		return Error(c, http.StatusForbidden, "callback token does not cover this run")
+	}
+	var req dto.IngestRunFinishRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid JSON payload")
+	}
+	run, err := h.runs.Finish(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		var incomplete service.IngestRunIncompleteError
+		if errors.As(err, &incomplete) {
+			return c.JSON(http.StatusConflict, APIResponse{
+				Status:  "error",
+				Message: "ingest run is missing batches",
+				Data:    map[string]any{"missing_sequences": incomplete.Missing},
+			})
+		}
+		return ingestRunError(c, err)
+	}
+	return Success(c, http.StatusOK, "ingest run finished", run)
+}
+
+func ingestRunError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidIngestBatch):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidIngestRunID):
+		return Error(c, http.StatusBadRequest, "invalid ingest run id")
+	case errors.Is(err, service.ErrIngestRunNotFound):
+		return Error(c, http.StatusNotFound, "ingest run not found")
+	case errors.Is(err, service.ErrIngestRunFinished),
+		errors.Is(err, service.ErrIngestBatchConflict),
+		errors.Is(err, service.ErrIngestRunOverflow):
+		return Error(c, http.StatusConflict, err.Error())
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to process ingest run")
+	}
+}
+
+// callbackCoversRun reports whether the request may touch runID: always without the worker callback
+// guard, and only for the token's own run behind it.
+func callbackCoversRun(c echo.Context, runID string) bool {
+	claims := middlewarepkg.CallbackFromContext(c)
+	return claims == nil || strings.EqualFold(strings.TrimSpace(runID), claims.JobID())
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type ingestRunsRepoStub struct {
+	applied map[int]string
+	missing []int
+}
+
+func (s *ingestRunsRepoStub) Create(ctx context.Context, run *entity.IngestRun) error {
+	run.Status = entity.IngestRunOpen
+	return nil
+}
+
+func (s *ingestRunsRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.IngestRun, error) {
+	return &entity.IngestRun{ID: id, Status: entity.IngestRunFinished}, nil
+}
+
+func (s *ingestRunsRepoStub) AppendBatch(ctx context.Context, runID uuid.UUID, batch *entity.IngestBatch, companies []entity.Company) error {
+	if _, ok := s.applied[batch.Sequence]; ok {
+		batch.Duplicate = true
+		return nil
+	}
+	s.applied[batch.Sequence] = batch.Checksum
+	return nil
+}
+
+func (s *ingestRunsRepoStub) Finish(ctx context.Context, id uuid.UUID, totalBatches int) ([]int, error) {
+	return s.missing, nil
+}
+
+func TestIngestRunsHandler_AppendBatchAndFinish(t *testing.T) {
+	e := echo.New()
+	repo := &ingestRunsRepoStub{applied: map[int]string{}, missing: []int{1}}
+	h := NewIngestRunsHandler(service.NewIngestRunsService(repo))
+	runID := uuid.NewString()
+
+	post := func(path, body string, handle echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(runID)
+		if err := handle(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	batch := `{"sequence":0,"items":[{"place_id":"ChIJ1","name":"Kopi Kenangan"}]}`
+	if rec := post("/ingest/runs/"+runID+"/batches", batch, h.AppendBatch); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("/ingest/runs/"+runID+"/batches", batch, h.AppendBatch); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"duplicate":true`) {
+		t.Fatalf("expected the replay to be acknowledged, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("/ingest/runs/"+runID+"/batches", `{"sequence":1,"items":[{"name":"No place"}]}`, h.AppendBatch); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	rec := post("/ingest/runs/"+runID+"/finish", `{"total_batches":2}`, h.Finish)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+	var body struct {
+		Data struct {
+			Missing []int `json:"missing_sequences"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Data.Missing) != 1 || body.Data.Missing[0] != 1 {
+		t.Fatalf("expected the missing sequence, got %s (%v)", rec.Body.String(), err)
+	}
+
+	repo.missing = nil
+	if rec := post("/ingest/runs/"+runID+"/finish", `{"total_batches":2}`, h.Finish); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// InvitationsHandler lets invited users create their account.
+type InvitationsHandler struct {
+	invitations *service.InvitationService
+}
+
+// NewInvitationsHandler constructs a handler instance.
+func NewInvitationsHandler(invitations *service.InvitationService) *InvitationsHandler {
+	return &InvitationsHandler{invitations: invitations}
+}
+
+// Accept handles POST /auth/invitations/accept requests, creating the invited account with the
+// chosen password. The invitee then logs in as usual.
+func (h *InvitationsHandler) Accept(c echo.Context) error {
+	var req dto.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	if strings.TrimSpace(req.Token) == "" || strings.TrimSpace(req.Password) == "" {
+		return Error(c, http.StatusBadRequest, "token and password are required")
+	}
+
+	user, err := h.invitations.Accept(c.Request().Context(), req.Token, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInvitation):
+			return Error(c, http.StatusNotFound, "invitation is invalid or has expired")
+		case errors.Is(err, repository.ErrEmailDuplicate):
+			return Error(c, http.StatusConflict, "email already exists")
+		default:
+			return Error(c, http.StatusInternalServerError, "unable to accept invitation")
+		}
+	}
+	return Success(c, http.StatusCreated, "invitation accepted", user)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+func TestInvitationsHandler_Accept(t *testing.T) {
+	h := NewInvitationsHandler(service.NewInvitationService(invitationsRepoStub{}))
+	e := echo.New()
+
+	tests := map[string]struct {
+		body   string
+		status int
+	}{
+		"invalid payload": {`{`, http.StatusBadRequest},
+		"missing token":   {`{"password":"s3cret"}`, http.StatusBadRequest},
+		"unknown token":   {`{"token":"nope","password":"s3cret"}`, http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/invitations/accept", bytes.NewBufferString(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			if err := h.Accept(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("accept: %v", err)
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
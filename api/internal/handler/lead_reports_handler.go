@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// LeadReportsHandler serves PDF lead sheets to hand to sales people.
+type LeadReportsHandler struct {
+	reports *service.LeadReportService
+}
+
+// NewLeadReportsHandler constructs a handler instance.
+func NewLeadReportsHandler(reports *service.LeadReportService) *LeadReportsHandler {
+	return &LeadReportsHandler{reports: reports}
+}
+
+// Company handles GET /companies/:id/report.pdf requests with the one-page lead sheet of a
+// company. The sheet is stamped with the caller like any other export.
+func (h *LeadReportsHandler) Company(c echo.Context) error {
+	stamp := newExportStamp(c)
+	report, err := h.reports.CompanyReport(c.Request().Context(), c.Param("id"), stamp)
+	if err != nil {
+		return leadReportError(c, err)
+	}
+	return h.send(c, report)
+}
+
+// Batch handles POST /reports requests with a lead sheet per company matching the listing filter
+// of the query, up to limit companies. Admins report on all data while other users only see the
+// latest run; companies on the do-not-contact list are left out unless include_suppressed=true.
+func (h *LeadReportsHandler) Batch(c echo.Context) error {
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	filter, err := parseListFilter(c, role != "admin")
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	if filter.Limit < 0 || filter.Limit > service.MaxLeadReportLimit {
+		return Error(c, http.StatusBadRequest, fmt.Sprintf("invalid limit (use 1 to %d)", service.MaxLeadReportLimit))
+	}
+	if raw := strings.TrimSpace(c.QueryParam("include_suppressed")); raw != "" {
+		filter.IncludeSuppressed, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Error(c, http.StatusBadRequest, "invalid include_suppressed (use true or false)")
+		}
+	}
+
+	stamp := newExportStamp(c)
+	report, err := h.reports.BatchReport(c.Request().Context(), filter, stamp)
+	if err != nil {
+		return leadReportError(c, err)
+	}
+	return h.send(c, report)
+}
+
+func (h *LeadReportsHandler) send(c echo.Context, report *service.LeadReport) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/pdf")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", report.Name+".pdf"))
+	res.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure can only be logged.
+	if _, err := report.WriteTo(res); err != nil {
+		log.Printf("request_id=%s lead report %s failed: %v", middlewarepkg.RequestIDFromContext(c), report.Name, err)
+	}
+	return nil
+}
+
+func leadReportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCompanyID):
+		return Error(c, http.StatusBadRequest, "invalid company id")
+	case errors.Is(err, service.ErrCompanyNotFound):
+		return Error(c, http.StatusNotFound, "company not found")
+	case errors.Is(err, service.ErrNoLeadsToReport):
+		return Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrLeadReportsUnavailable):
+		return Error(c, http.StatusNotImplemented, "lead reports are not enabled")
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to generate lead report")
+	}
+}
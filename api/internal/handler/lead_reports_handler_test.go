@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type leadReportsRepoStub []entity.Company
+
+func (s leadReportsRepoStub) LeadReports(ctx context.Context, ids []uuid.UUID) ([]entity.LeadReport, error) {
+	var reports []entity.LeadReport
+	for _, id := range ids {
+		for _, company := range s {
+			if company.ID == id {
+				reports = append(reports, entity.LeadReport{Company: company})
+			}
+		}
+	}
+	return reports, nil
+}
+
+func TestLeadReportsHandler(t *testing.T) {
+	user := testsupport.NewUser().Build()
+	company := entity.Company{ID: uuid.New(), Company: "Kopi Kenangan"}
+	h := NewLeadReportsHandler(service.NewLeadReportService(
+		leadReportsRepoStub{company},
+		service.NewCompaniesService(testsupport.NewStubCompaniesRepository(company)),
+	))
+
+	tests := []struct {
+		name     string
+		call     func() (*httptest.ResponseRecorder, error)
+		wantCode int
+	}{
+		{"invalid id", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewContext(http.MethodGet, "/companies/nope/report.pdf")
+			return rec, h.Company(testsupport.WithUser(testsupport.WithParams(c, "id", "nope"), user))
+		}, http.StatusBadRequest},
+		{"unknown company", func() (*httptest.ResponseRecorder, error) {
+			id := uuid.NewString()
+			c, rec := testsupport.NewContext(http.MethodGet, "/companies/"+id+"/report.pdf")
+			return rec, h.Company(testsupport.WithUser(testsupport.WithParams(c, "id", id), user))
+		}, http.StatusNotFound},
+		{"limit too large", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewContext(http.MethodPost, "/reports?limit=500")
+			return rec, h.Batch(testsupport.WithUser(c, user))
+		}, http.StatusBadRequest},
+		{"invalid include_suppressed", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewContext(http.MethodPost, "/reports?include_suppressed=maybe")
+			return rec, h.Batch(testsupport.WithUser(c, user))
+		}, http.StatusBadRequest},
+		{"batch", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewContext(http.MethodPost, "/reports?city=Jakarta&limit=10")
+			return rec, h.Batch(testsupport.WithUser(c, user))
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testsupport.AssertStatus(t, rec, tt.wantCode)
+		})
+	}
+
+	id := company.ID.String()
+	c, rec := testsupport.NewContext(http.MethodGet, "/companies/"+id+"/report.pdf")
+	if err := h.Company(testsupport.WithUser(testsupport.WithParams(c, "id", id), user)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="lead-kopi-kenangan-`) {
+		t.Fatalf("unexpected content disposition %q", got)
+	}
+	if rec.Header().Get("X-Export-ID") == "" || !strings.HasPrefix(rec.Body.String(), "%PDF-1.4") {
+		t.Fatalf("expected a stamped PDF, got headers %v", rec.Header())
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// LocationsHandler exposes the location catalog: autocomplete for every user and management for
+// admins.
+type LocationsHandler struct {
+	locations *service.LocationService
+}
+
+// NewLocationsHandler constructs a handler instance.
+func NewLocationsHandler(locations *service.LocationService) *LocationsHandler {
+	return &LocationsHandler{locations: locations}
+}
+
+// Search handles GET /locations requests: q matches the start of a city or one of its aliases,
+// country keeps one country's cities.
+func (h *LocationsHandler) Search(c echo.Context) error {
+	locations, err := h.locations.Search(
+		c.Request().Context(),
+		c.QueryParam("q"),
+		c.QueryParam("country"),
+		parseIntDefault(c.QueryParam("limit"), 0),
+	)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to search locations")
+	}
+	return Success(c, http.StatusOK, "locations retrieved", locations)
+}
+
+// Create handles POST /admin/locations requests.
+func (h *LocationsHandler) Create(c echo.Context) error {
+	var req dto.LocationRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	location, err := h.locations.Create(c.Request().Context(), req)
+	if err != nil {
+		return locationError(c, err)
+	}
+	return Success(c, http.StatusCreated, "location created", location)
+}
+
+// Update handles PUT /admin/locations/:id requests.
+func (h *LocationsHandler) Update(c echo.Context) error {
+	var req dto.LocationRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	location, err := h.locations.Update(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return locationError(c, err)
+	}
+	return Success(c, http.StatusOK, "location updated", location)
+}
+
+// Delete handles DELETE /admin/locations/:id requests.
+func (h *LocationsHandler) Delete(c echo.Context) error {
+	if err := h.locations.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return locationError(c, err)
+	}
+	return Success(c, http.StatusOK, "location deleted", nil)
+}
+
+func locationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidLocation):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidLocationID):
+		return Error(c, http.StatusBadRequest, "invalid location id")
+	case errors.Is(err, service.ErrLocationNotFound):
+		return Error(c, http.StatusNotFound, "location not found")
+	case errors.Is(err, service.ErrLocationExists):
+		return Error(c, http.StatusConflict, "location already exists")
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to save location")
+	}
+}
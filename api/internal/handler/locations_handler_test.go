@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type locationsRepoStub struct {
+	locations []entity.Location
+	search    repository.LocationSearch
+}
+
+func (s *locationsRepoStub) List(ctx context.Context) ([]entity.Location, error) {
+	return s.locations, nil
+}
+
+func (s *locationsRepoStub) Search(ctx context.Context, filter repository.LocationSearch) ([]entity.Location, error) {
+	s.search = filter
+	var matches []entity.Location
+	for _, location := range s.locations {
+		if strings.HasPrefix(strings.ToLower(location.City), filter.Query) {
+			matches = append(matches, location)
+		}
+	}
+	return matches, nil
+}
+
+func (s *locationsRepoStub) Create(ctx context.Context, location *entity.Location) error {
+	for _, existing := range s.locations {
+		if strings.EqualFold(existing.City, location.City) && strings.EqualFold(existing.Country, location.Country) {
+			return repository.ErrLocationDuplicate
+		}
+	}
+	location.ID = uuid.New()
+	s.locations = append(s.locations, *location)
+	return nil
+}
+
+func (s *locationsRepoStub) Update(ctx context.Context, location *entity.Location) error {
+	return repository.ErrLocationNotFound
+}
+
+func (s *locationsRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrLocationNotFound
+}
+
+func TestLocationsHandler_Search(t *testing.T) {
+	repo := &locationsRepoStub{locations: []entity.Location{
+		{City: "Yogyakarta", Country: "Indonesia"},
+		{City: "Surabaya", Country: "Indonesia"},
+	}}
+	h := NewLocationsHandler(service.NewLocationService(repo))
+
+	c, rec := testsupport.NewContext(http.MethodGet, "/locations?q=Yog&country=Indonesia&limit=5")
+	if err := h.Search(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	var locations []entity.Location
+	testsupport.DecodeData(t, rec, &locations)
+	if len(locations) != 1 || locations[0].City != "Yogyakarta" {
+		t.Fatalf("unexpected locations: %+v", locations)
+	}
+	if repo.search != (repository.LocationSearch{Query: "yog", Country: "Indonesia", Limit: 5}) {
+		t.Fatalf("unexpected search filter: %+v", repo.search)
+	}
+}
+
+func TestLocationsHandler_Admin(t *testing.T) {
+	h := NewLocationsHandler(service.NewLocationService(&locationsRepoStub{}))
+
+	tests := []struct {
+		name     string
+		call     func() (*httptest.ResponseRecorder, error)
+		wantCode int
+	}{
+		{"created", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/locations", map[string]any{"city": "Bandung", "country": "Indonesia"})
+			return rec, h.Create(c)
+		}, http.StatusCreated},
+		{"duplicate", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/locations", map[string]any{"city": "BANDUNG", "country": "Indonesia"})
+			return rec, h.Create(c)
+		}, http.StatusConflict},
+		{"missing country", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPost, "/admin/locations", map[string]any{"city": "Bandung"})
+			return rec, h.Create(c)
+		}, http.StatusBadRequest},
+		{"invalid id", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewJSONContext(t, http.MethodPut, "/admin/locations/nope", map[string]any{"city": "Bandung", "country": "Indonesia"})
+			return rec, h.Update(testsupport.WithParams(c, "id", "nope"))
+		}, http.StatusBadRequest},
+		{"unknown", func() (*httptest.ResponseRecorder, error) {
+			c, rec := testsupport.NewContext(http.MethodDelete, "/admin/locations/"+uuid.NewString())
+			return rec, h.Delete(testsupport.WithParams(c, "id", uuid.NewString()))
+		}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := tt.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testsupport.AssertStatus(t, rec, tt.wantCode)
+		})
+	}
+}
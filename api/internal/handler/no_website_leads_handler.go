@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// NoWebsiteLeadsQueryRules bound the numeric filters of no-website lead listings and exports.
+var NoWebsiteLeadsQueryRules = append([]QueryRule{
+	IntParam("min_reviews", 0, 0),
+	FloatParam("max_rating", 0, 5),
+}, CompanyListQueryRules...)
+
+// NoWebsiteLeads handles GET /leads/no-website requests, listing companies without a website by
+// opportunity score. Like exports, only admins see beyond the latest run.
+func (h *CompaniesHandler) NoWebsiteLeads(c echo.Context) error {
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	filter, err := parseNoWebsiteLeadsFilter(c, role != "admin")
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	leads, err := h.service.NoWebsiteLeads(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrNoWebsiteLeadsUnavailable) {
+			return Error(c, http.StatusNotImplemented, "no-website leads are not enabled")
+		}
+		return queryError(c, err, "failed to list no-website leads")
+	}
+	return Success(c, http.StatusOK, "no-website leads retrieved", leads)
+}
+
+// ExportNoWebsiteLeads handles GET /exports/leads/no-website requests by streaming the ranked leads
+// as CSV, with the stamp, row caps, locale and include_suppressed params of company exports.
+func (h *CompaniesHandler) ExportNoWebsiteLeads(c echo.Context) error {
+	role, _ := c.Get(middlewarepkg.ContextKeyUserRole).(string)
+	filter, err := parseNoWebsiteLeadsFilter(c, role != "admin")
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	return h.streamExport(c, "no-website-leads", filter, h.service.PrepareNoWebsiteLeadsExport, csvExport(h.service.WriteNoWebsiteLeadsCSV))
+}
+
+// parseNoWebsiteLeadsFilter reads the listing filter plus the min_reviews, max_rating, has_phone
+// and has_socials params. The website param is ignored: these leads never have one.
+func parseNoWebsiteLeadsFilter(c echo.Context, latestOnly bool) (dto.ListFilter, error) {
+	filter, err := parseListFilter(c, latestOnly)
+	if err != nil {
+		return filter, err
+	}
+
+	if minReviewsStr := strings.TrimSpace(c.QueryParam("min_reviews")); minReviewsStr != "" {
+		minReviews, err := strconv.Atoi(minReviewsStr)
+		if err != nil || minReviews < 0 {
+			return filter, errors.New("invalid min_reviews")
+		}
+		filter.MinReviews = &minReviews
+	}
+	if maxRatingStr := strings.TrimSpace(c.QueryParam("max_rating")); maxRatingStr != "" {
+		maxRating, err := strconv.ParseFloat(maxRatingStr, 64)
+		if err != nil {
+			return filter, errors.New("invalid max_rating")
+		}
+		filter.MaxRating = &maxRating
+	}
+	if filter.MinRating != nil && filter.MaxRating != nil && *filter.MinRating > *filter.MaxRating {
+		return filter, errors.New("min_rating must not exceed max_rating")
+	}
+
+	if hasPhoneParam := strings.TrimSpace(c.QueryParam("has_phone")); hasPhoneParam != "" {
+		hasPhone, err := strconv.ParseBool(hasPhoneParam)
+		if err != nil {
+			return filter, errors.New("invalid has_phone (use true or false)")
+		}
+		filter.HasPhone = &hasPhone
+	}
+	if hasSocialsParam := strings.TrimSpace(c.QueryParam("has_socials")); hasSocialsParam != "" {
+		hasSocials, err := strconv.ParseBool(hasSocialsParam)
+		if err != nil {
+			return filter, errors.New("invalid has_socials (use true or false)")
+		}
+		filter.HasSocials = &hasSocials
+	}
+	return filter, nil
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type noWebsiteLeadsStub struct {
+	companyExportsStub
+	listFilter dto.ListFilter
+}
+
+func (s *noWebsiteLeadsStub) ListNoWebsiteLeads(ctx context.Context, filter dto.ListFilter) ([]entity.NoWebsiteLead, error) {
+	s.listFilter = filter
+	return []entity.NoWebsiteLead{{Company: entity.Company{Company: "Acme"}, HasSocials: true, OpportunityScore: 81.5}}, nil
+}
+
+func (s *noWebsiteLeadsStub) ExportNoWebsiteLeads(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.NoWebsiteLead) error) error {
+	return fn(entity.NoWebsiteLead{Company: entity.Company{ID: uuid.New(), Company: "Acme"}, OpportunityScore: 81.5})
+}
+
+func TestCompaniesHandler_NoWebsiteLeads(t *testing.T) {
+	leads := &noWebsiteLeadsStub{}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}, service.WithNoWebsiteLeads(leads)))
+	list := func(target string, user *entity.User) *httptest.ResponseRecorder {
+		c, rec := testsupport.NewContext(http.MethodGet, target)
+		if err := handler.NoWebsiteLeads(testsupport.WithUser(c, user)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+	user := testsupport.NewUser().Build()
+
+	rec := list("/leads/no-website?city=Jakarta&website=available&min_reviews=20&min_rating=3.5&max_rating=4.8&has_phone=true&has_socials=false", user)
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	if !strings.Contains(rec.Body.String(), `"opportunity_score":81.5`) || !strings.Contains(rec.Body.String(), `"has_socials":true`) {
+		t.Fatalf("expected ranked leads, got %s", rec.Body.String())
+	}
+	filter := leads.listFilter
+	if filter.WebsiteStatus != "missing" || !filter.LatestRunOnly || filter.City != "Jakarta" {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+	if *filter.MinReviews != 20 || *filter.MinRating != 3.5 || *filter.MaxRating != 4.8 || !*filter.HasPhone || *filter.HasSocials {
+		t.Fatalf("unexpected lead filters %+v", filter)
+	}
+
+	list("/leads/no-website", testsupport.NewUser().Admin().Build())
+	if leads.listFilter.LatestRunOnly {
+		t.Fatalf("expected admins to see all data")
+	}
+
+	for _, target := range []string{
+		"/leads/no-website?min_rating=4.5&max_rating=3",
+		"/leads/no-website?has_phone=maybe",
+		"/leads/no-website?min_reviews=-1",
+	} {
+		testsupport.AssertStatus(t, list(target, user), http.StatusBadRequest)
+	}
+
+	disabled := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}))
+	c, rec := testsupport.NewContext(http.MethodGet, "/leads/no-website")
+	if err := disabled.NoWebsiteLeads(testsupport.WithUser(c, user)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusNotImplemented)
+}
+
+func TestCompaniesHandler_ExportNoWebsiteLeads(t *testing.T) {
+	leads := &noWebsiteLeadsStub{companyExportsStub: companyExportsStub{matched: 1}}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{},
+		service.WithExports(leads, map[string]int64{"user": 10}),
+		service.WithNoWebsiteLeads(leads),
+	))
+
+	c, rec := testsupport.NewContext(http.MethodGet, "/exports/leads/no-website?has_socials=true")
+	if err := handler.ExportNoWebsiteLeads(testsupport.WithUser(c, testsupport.NewUser().Build())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	if !strings.HasPrefix(rec.Header().Get("Content-Disposition"), `attachment; filename="no-website-leads-`) {
+		t.Fatalf("unexpected disposition %q", rec.Header().Get("Content-Disposition"))
+	}
+	if !strings.Contains(rec.Body.String(), "has_socials,opportunity_score,exported_by") || !strings.Contains(rec.Body.String(), ",false,81.5,") {
+		t.Fatalf("unexpected export %q", rec.Body.String())
+	}
+	if leads.lastFilter.WebsiteStatus != "missing" || leads.lastFilter.HasSocials == nil || !*leads.lastFilter.HasSocials {
+		t.Fatalf("unexpected export filter %+v", leads.lastFilter)
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// PlaceRefreshHandler looks companies up again on Google Maps through the worker, for places
+// whose id Google retired.
+type PlaceRefreshHandler struct {
+	worker    WorkerPoster
+	companies *service.CompaniesService
+}
+
+// NewPlaceRefreshHandler wires a handler looking places up through worker.
+func NewPlaceRefreshHandler(worker WorkerPoster, companies *service.CompaniesService) *PlaceRefreshHandler {
+	return &PlaceRefreshHandler{worker: worker, companies: companies}
+}
+
+// Refresh handles POST /admin/companies/:id/refresh requests. The worker searches the company by
+// name and address; the place it finds replaces the stored place id, rating and reviews, and the
+// refresh is added to the company history.
+func (h *PlaceRefreshHandler) Refresh(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID, lookup, err := h.companies.PlaceLookup(ctx, c.Param("id"))
+	if err != nil {
+		return placeRefreshError(c, err)
+	}
+
+	data, err := h.worker.PostJSON(ctx, "/lookup", lookup, middlewarepkg.RequestIDFromContext(c))
+	if err != nil {
+		return workerError(c, err)
+	}
+	result, err := decodePlaceLookup(data)
+	if err != nil {
+		return workerError(c, err)
+	}
+
+	refresh, err := h.companies.RefreshPlace(ctx, companyID, result)
+	if err != nil {
+		return placeRefreshError(c, err)
+	}
+	return Success(c, http.StatusOK, "company place refreshed", refresh)
+}
+
+// decodePlaceLookup reads the data of a /lookup answer.
+func decodePlaceLookup(data map[string]any) (dto.WorkerPlaceLookupResult, error) {
+	var result dto.WorkerPlaceLookupResult
+	raw, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(raw, &result)
+	}
+	if err != nil {
+		return result, fmt.Errorf("could not decode worker response: %w", err)
+	}
+	return result, nil
+}
+
+func placeRefreshError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPlaceRefreshUnavailable):
+		return Error(c, http.StatusNotImplemented, "place refresh is not enabled")
+	case errors.Is(err, service.ErrInvalidCompanyID):
+		return Error(c, http.StatusBadRequest, "invalid company id")
+	case errors.Is(err, service.ErrCompanyNotFound):
+		return Error(c, http.StatusNotFound, "company not found")
+	case errors.Is(err, service.ErrPlaceNotFound):
+		return Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPlaceIDTaken):
+		return Error(c, http.StatusConflict, err.Error())
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to refresh company place")
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type placeRefreshRepoStub struct {
+	target     *repository.PlaceRefreshTarget
+	targetErr  error
+	refreshErr error
+
+	refreshed *entity.PlaceRefresh
+}
+
+func (s *placeRefreshRepoStub) GetPlaceRefreshTarget(ctx context.Context, id uuid.UUID) (*repository.PlaceRefreshTarget, error) {
+	if s.targetErr != nil {
+		return nil, s.targetErr
+	}
+	return s.target, nil
+}
+
+func (s *placeRefreshRepoStub) RefreshPlace(ctx context.Context, refresh *entity.PlaceRefresh) error {
+	if s.refreshErr != nil {
+		return s.refreshErr
+	}
+	s.refreshed = refresh
+	return nil
+}
+
+func TestPlaceRefreshHandler_Refresh(t *testing.T) {
+	companyID := uuid.New()
+	repo := &placeRefreshRepoStub{target: &repository.PlaceRefreshTarget{Company: "Kopi Kenangan", Address: "Jl. Sudirman 1", Country: testsupport.Ptr("ID")}}
+	worker := &workerStub{data: map[string]any{"found": true, "place_id": "ChIJnew", "rating": 4.6, "reviews": 210}}
+	companies := service.NewCompaniesService(testsupport.NewStubCompaniesRepository(), service.WithPlaceRefresh(repo))
+	handler := NewPlaceRefreshHandler(worker, companies)
+
+	c, rec := testsupport.NewContext(http.MethodPost, "/admin/companies/"+companyID.String()+"/refresh")
+	if err := handler.Refresh(testsupport.WithParams(c, "id", companyID.String())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testsupport.AssertStatus(t, rec, http.StatusOK)
+	lookup, ok := worker.payload.(dto.WorkerPlaceLookupRequest)
+	if worker.path != "/lookup" || !ok || lookup.Name != "Kopi Kenangan" || lookup.Address != "Jl. Sudirman 1" || lookup.Country != "ID" {
+		t.Fatalf("unexpected worker call %s %+v", worker.path, worker.payload)
+	}
+	if repo.refreshed == nil || repo.refreshed.CompanyID != companyID || repo.refreshed.PlaceID != "ChIJnew" || *repo.refreshed.Rating != 4.6 || *repo.refreshed.Reviews != 210 {
+		t.Fatalf("unexpected refresh %+v", repo.refreshed)
+	}
+
+	tests := map[string]struct {
+		id      string
+		data    map[string]any
+		repo    *placeRefreshRepoStub
+		enabled bool
+		status  int
+	}{
+		"not enabled":   {id: companyID.String(), status: http.StatusNotImplemented},
+		"invalid id":    {id: "abc", enabled: true, status: http.StatusBadRequest},
+		"unknown":       {id: companyID.String(), enabled: true, repo: &placeRefreshRepoStub{targetErr: repository.ErrCompanyNotFound}, status: http.StatusNotFound},
+		"no match":      {id: companyID.String(), enabled: true, data: map[string]any{"found": false}, status: http.StatusNotFound},
+		"place id used": {id: companyID.String(), enabled: true, repo: &placeRefreshRepoStub{refreshErr: repository.ErrPlaceIDTaken}, status: http.StatusConflict},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var opts []service.CompaniesServiceOption
+			if tt.enabled {
+				stub := tt.repo
+				if stub == nil {
+					stub = &placeRefreshRepoStub{}
+				}
+				if stub.target == nil {
+					stub.target = repo.target
+				}
+				opts = append(opts, service.WithPlaceRefresh(stub))
+			}
+			data := tt.data
+			if data == nil {
+				data = worker.data
+			}
+			handler := NewPlaceRefreshHandler(&workerStub{data: data}, service.NewCompaniesService(testsupport.NewStubCompaniesRepository(), opts...))
+
+			c, rec := testsupport.NewContext(http.MethodPost, "/admin/companies/"+tt.id+"/refresh")
+			if err := handler.Refresh(testsupport.WithParams(c, "id", tt.id)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testsupport.AssertStatus(t, rec, tt.status)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// PrivacyHandler serves data subject erasure and export requests and their audit trail.
+type PrivacyHandler struct {
+	privacy *service.PrivacyService
+}
+
+// NewPrivacyHandler constructs a handler instance.
+func NewPrivacyHandler(privacy *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacy: privacy}
+}
+
+// Erase handles POST /admin/privacy/erase requests.
+func (h *PrivacyHandler) Erase(c echo.Context) error {
+	var req dto.PrivacyRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	request, err := h.privacy.Erase(c.Request().Context(), req, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPrivacyRequest) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to erase contact data")
+	}
+	return Success(c, http.StatusOK, "contact data erased", request)
+}
+
+// Export handles POST /admin/privacy/export requests. Subjects travel in the body so emails stay
+// out of access logs.
+func (h *PrivacyHandler) Export(c echo.Context) error {
+	var req dto.PrivacyRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	export, err := h.privacy.Export(c.Request().Context(), req, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPrivacyRequest) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to export contact data")
+	}
+	return Success(c, http.StatusOK, "contact data exported", export)
+}
+
+// Requests handles GET /admin/privacy/requests requests.
+func (h *PrivacyHandler) Requests(c echo.Context) error {
+	requests, err := h.privacy.ListRequests(
+		c.Request().Context(),
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list privacy requests")
+	}
+	return Success(c, http.StatusOK, "privacy requests retrieved", requests)
+}
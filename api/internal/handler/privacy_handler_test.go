@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type privacyRepoStub struct {
+	erased []string
+}
+
+func (s *privacyRepoStub) FindCompaniesByEmail(ctx context.Context, emails []string) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (s *privacyRepoStub) ExportPrivacyData(ctx context.Context, companyIDs []uuid.UUID, emails []string) (entity.PrivacyExport, error) {
+	return entity.PrivacyExport{Emails: emails}, nil
+}
+
+func (s *privacyRepoStub) ErasePrivacyData(ctx context.Context, request *entity.PrivacyRequest, emails []string) ([]string, error) {
+	s.erased = emails
+	return nil, nil
+}
+
+func (s *privacyRepoStub) RecordPrivacyRequest(ctx context.Context, request *entity.PrivacyRequest) error {
+	return nil
+}
+
+func (s *privacyRepoStub) ListPrivacyRequests(ctx context.Context, limit, offset int) ([]entity.PrivacyRequest, error) {
+	return nil, nil
+}
+
+func TestPrivacyHandler_Erase(t *testing.T) {
+	e := echo.New()
+	repo := &privacyRepoStub{}
+	handler := NewPrivacyHandler(service.NewPrivacyService(repo, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/privacy/erase", strings.NewReader(`{"emails":["Jane@Example.com"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	_ = handler.Erase(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.erased) != 1 || repo.erased[0] != "jane@example.com" {
+		t.Fatalf("expected the normalised email erased, got %v", repo.erased)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/privacy/erase", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	_ = handler.Erase(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without subjects, got %d", rec.Code)
+	}
+}
+
+func TestPrivacyHandler_Export(t *testing.T) {
+	e := echo.New()
+	handler := NewPrivacyHandler(service.NewPrivacyService(&privacyRepoStub{}, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/privacy/export", strings.NewReader(`{"company_ids":["not-a-uuid"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	_ = handler.Export(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid company id, got %d", rec.Code)
+	}
+}
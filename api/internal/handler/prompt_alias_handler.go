@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// PromptAliasHandler exposes admin management of prompt parser aliases.
+type PromptAliasHandler struct {
+	aliases *service.PromptAliasService
+}
+
+// NewPromptAliasHandler constructs a handler instance.
+func NewPromptAliasHandler(aliases *service.PromptAliasService) *PromptAliasHandler {
+	return &PromptAliasHandler{aliases: aliases}
+}
+
+// List handles GET /admin/prompt-aliases requests.
+func (h *PromptAliasHandler) List(c echo.Context) error {
+	aliases, err := h.aliases.List(c.Request().Context())
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list prompt aliases")
+	}
+	return Success(c, http.StatusOK, "prompt aliases retrieved", aliases)
+}
+
+// Create handles POST /admin/prompt-aliases requests.
+func (h *PromptAliasHandler) Create(c echo.Context) error {
+	var req dto.PromptAliasRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	alias, err := h.aliases.Create(c.Request().Context(), req)
+	if err != nil {
+		return promptAliasError(c, err)
+	}
+	return Success(c, http.StatusCreated, "prompt alias created", alias)
+}
+
+// Update handles PUT /admin/prompt-aliases/:id requests.
+func (h *PromptAliasHandler) Update(c echo.Context) error {
+	var req dto.PromptAliasRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	alias, err := h.aliases.Update(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return promptAliasError(c, err)
+	}
+	return Success(c, http.StatusOK, "prompt alias updated", alias)
+}
+
+// Delete handles DELETE /admin/prompt-aliases/:id requests.
+func (h *PromptAliasHandler) Delete(c echo.Context) error {
+	if err := h.aliases.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return promptAliasError(c, err)
+	}
+	return Success(c, http.StatusOK, "prompt alias deleted", nil)
+}
+
+func promptAliasError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromptAlias):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidPromptAliasID):
+		return Error(c, http.StatusBadRequest, "invalid prompt alias id")
+	case errors.Is(err, service.ErrPromptAliasNotFound):
+		return Error(c, http.StatusNotFound, "prompt alias not found")
+	case errors.Is(err, service.ErrPromptAliasExists):
+		return Error(c, http.StatusConflict, "prompt alias already exists")
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to save prompt alias")
+	}
+}
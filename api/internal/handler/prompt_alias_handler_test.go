@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type promptAliasesRepoStub struct {
+	created []entity.PromptAlias
+}
+
+func (s *promptAliasesRepoStub) List(ctx context.Context) ([]entity.PromptAlias, error) {
+	return s.created, nil
+}
+
+func (s *promptAliasesRepoStub) Create(ctx context.Context, alias *entity.PromptAlias) error {
+	for _, existing := range s.created {
+		if existing.Kind == alias.Kind && existing.Alias == alias.Alias {
+			return repository.ErrPromptAliasDuplicate
+		}
+	}
+	alias.ID = uuid.New()
+	s.created = append(s.created, *alias)
+	return nil
+}
+
+func (s *promptAliasesRepoStub) Update(ctx context.Context, alias *entity.PromptAlias) error {
+	return repository.ErrPromptAliasNotFound
+}
+
+func (s *promptAliasesRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrPromptAliasNotFound
+}
+
+func TestPromptAliasHandler_Create(t *testing.T) {
+	e := echo.New()
+	handler := NewPromptAliasHandler(service.NewPromptAliasService(&promptAliasesRepoStub{}, service.NewPromptService("Indonesia")))
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"created", `{"kind":"city","alias":"jaksel","canonical":"Jakarta Selatan"}`, http.StatusCreated},
+		{"duplicate", `{"kind":"city","alias":"JAKSEL","canonical":"Jakarta Selatan"}`, http.StatusConflict},
+		{"invalid kind", `{"kind":"region","alias":"jabar","canonical":"Jawa Barat"}`, http.StatusBadRequest},
+		{"invalid json", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/prompt-aliases", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			_ = handler.Create(e.NewContext(req, rec))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPromptAliasHandler_DeleteNotFound(t *testing.T) {
+	e := echo.New()
+	handler := NewPromptAliasHandler(service.NewPromptAliasService(&promptAliasesRepoStub{}, service.NewPromptService("Indonesia")))
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/prompt-aliases/x", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(uuid.NewString())
+
+	_ = handler.Delete(c)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	"github.com/octobees/leads-generator/api/internal/auth"
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/service"
 	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
@@ -13,8 +15,9 @@ import (
 
 // PromptSearchHandler accepts free-form prompts and forwards derived jobs to the worker.
 type PromptSearchHandler struct {
-	worker  WorkerPoster
-	service *service.PromptService
+	worker    WorkerPoster
+	service   *service.PromptService
+	callbacks *auth.CallbackSigner
 }
 
 // NewPromptSearchHandler wires the handler.
@@ -22,6 +25,12 @@ func NewPromptSearchHandler(worker WorkerPoster, svc *service.PromptService) *Pr
 	return &PromptSearchHandler{worker: worker, service: svc}
 }
 
+// NewPromptSearchHandlerWithCallbacks also gives every job an ingest run and a callback token, like
+// NewScrapeHandlerWithCallbacks.
+func NewPromptSearchHandlerWithCallbacks(worker WorkerPoster, svc *service.PromptService, callbacks *auth.CallbackSigner) *PromptSearchHandler {
+	return &PromptSearchHandler{worker: worker, service: svc, callbacks: callbacks}
+}
+
 // Enqueue parses prompts and calls worker scrape endpoint.
 func (h *PromptSearchHandler) Enqueue(c echo.Context) error {
 	var req dto.PromptSearchRequest
@@ -38,23 +47,29 @@ func (h *PromptSearchHandler) Enqueue(c echo.Context) error {
 		return Error(c, http.StatusBadRequest, err.Error())
 	}
 
-	payload := map[string]any{
-		"type_business": result.TypeBusiness,
-		"city":          result.City,
-		"country":       result.Country,
+	payload := dto.WorkerScrapeRequest{
+		TypeBusiness: result.TypeBusiness,
+		City:         result.City,
+		Country:      result.Country,
 	}
 	if result.MinRating > 0 {
-		payload["min_rating"] = result.MinRating
+		payload.MinRating = result.MinRating
+	}
+	if err := assignScrapeRun(h.callbacks, &payload, uuid.Nil); err != nil {
+		return Error(c, http.StatusInternalServerError, errCallbackToken)
 	}
 
 	ctx := c.Request().Context()
 	data, err := h.worker.PostJSON(ctx, "/scrape", payload, middlewarepkg.RequestIDFromContext(c))
 	if err != nil {
-		return Error(c, http.StatusBadGateway, err.Error())
+		return workerError(c, err)
 	}
 	if data == nil {
 		data = map[string]any{"status": "queued"}
 	}
+	if payload.RunID != "" {
+		data["run_id"] = payload.RunID
+	}
 
 	resp := dto.PromptSearchResponse{
 		Prompt:          req.Prompt,
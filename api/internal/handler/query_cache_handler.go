@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/querycache"
+)
+
+// QueryCacheHandler exposes the hit rate of the cache of company listings and stats.
+type QueryCacheHandler struct {
+	cache *querycache.Cache
+}
+
+// NewQueryCacheHandler constructs a handler instance; a nil cache reports zeros.
+func NewQueryCacheHandler(cache *querycache.Cache) *QueryCacheHandler {
+	return &QueryCacheHandler{cache: cache}
+}
+
+// Stats handles GET /admin/metrics/query-cache requests.
+func (h *QueryCacheHandler) Stats(c echo.Context) error {
+	return Success(c, http.StatusOK, "query cache stats retrieved", h.cache.Stats(c.Request().Context()))
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/querycache"
+)
+
+func TestQueryCacheHandler_Stats(t *testing.T) {
+	cache := querycache.New(querycache.NewMemoryStore(10), querycache.StoreMemory, time.Minute)
+	load := func(ctx context.Context) (int, error) { return 42, nil }
+	querycache.Fetch(context.Background(), cache, "stats:10:20", load)
+	querycache.Fetch(context.Background(), cache, "stats:10:20", load)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := NewQueryCacheHandler(cache).Stats(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/metrics/query-cache", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data querycache.Stats `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.Store != querycache.StoreMemory || body.Data.Entries != 1 || body.Data.Hits != 1 || body.Data.Misses != 1 || body.Data.HitRate != 0.5 {
+		t.Fatalf("unexpected stats payload: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// QueryRule checks one query parameter, returning a message when its value is rejected.
+type QueryRule struct {
+	Param string
+	Check func(value string) string
+}
+
+// CompanyListQueryRules bound the numeric filters of company listings.
+var CompanyListQueryRules = []QueryRule{
+	IntParam("page", 1, 0),
+	IntParam("per_page", 1, 100),
+	IntParam("limit", 0, 0),
+	FloatParam("min_rating", 0, 5),
+	FloatParam("score_weight", 0, 1),
+}
+
+// IntParam accepts integers in [min, max]; a max of zero leaves the range open-ended.
+func IntParam(param string, min, max int) QueryRule {
+	message := fmt.Sprintf("must be an integer of at least %d", min)
+	if max > 0 {
+		message = fmt.Sprintf("must be an integer between %d and %d", min, max)
+	}
+	return QueryRule{Param: param, Check: func(value string) string {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < min || (max > 0 && parsed > max) {
+			return message
+		}
+		return ""
+	}}
+}
+
+// FloatParam accepts numbers in [min, max].
+func FloatParam(param string, min, max float64) QueryRule {
+	message := fmt.Sprintf("must be a number between %g and %g", min, max)
+	return QueryRule{Param: param, Check: func(value string) string {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(parsed) || parsed < min || parsed > max {
+			return message
+		}
+		return ""
+	}}
+}
+
+// ValidateQuery rejects requests with 422 when any present query parameter fails its rule, reporting
+// every failing parameter at once. Absent or blank parameters keep the handler's defaults.
+func ValidateQuery(rules ...QueryRule) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var errs map[string]string
+			for _, rule := range rules {
+				value := strings.TrimSpace(c.QueryParam(rule.Param))
+				if value == "" {
+					continue
+				}
+				if message := rule.Check(value); message != "" {
+					if errs == nil {
+						errs = make(map[string]string)
+					}
+					errs[rule.Param] = message
+				}
+			}
+			if errs != nil {
+				return ValidationError(c, errs)
+			}
+			return next(c)
+		}
+	}
+}
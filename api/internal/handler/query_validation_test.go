@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestValidateQuery(t *testing.T) {
+	e := echo.New()
+	e.GET("/companies", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, ValidateQuery(CompanyListQueryRules...))
+
+	cases := []struct {
+		name   string
+		query  string
+		status int
+		errors map[string]string
+	}{
+		{name: "defaults", query: "", status: http.StatusNoContent},
+		{name: "in range", query: "?page=2&per_page=100&limit=0&min_rating=4.5", status: http.StatusNoContent},
+		{name: "blank values", query: "?page=&min_rating=%20", status: http.StatusNoContent},
+		{
+			name:   "out of range",
+			query:  "?page=0&per_page=101&min_rating=5.5",
+			status: http.StatusUnprocessableEntity,
+			errors: map[string]string{
+				"page":       "must be an integer of at least 1",
+				"per_page":   "must be an integer between 1 and 100",
+				"min_rating": "must be a number between 0 and 5",
+			},
+		},
+		{
+			name:   "malformed",
+			query:  "?page=two&min_rating=NaN&limit=-1",
+			status: http.StatusUnprocessableEntity,
+			errors: map[string]string{
+				"page":       "must be an integer of at least 1",
+				"limit":      "must be an integer of at least 0",
+				"min_rating": "must be a number between 0 and 5",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/companies"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, rec.Code, rec.Body.String())
+			}
+			if tc.errors == nil {
+				return
+			}
+			var payload APIResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if payload.Status != "error" || len(payload.Errors) != len(tc.errors) {
+				t.Fatalf("unexpected payload: %+v", payload)
+			}
+			for param, message := range tc.errors {
+				if payload.Errors[param] != message {
+					t.Fatalf("expected %s error %q, got %q", param, message, payload.Errors[param])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// RawReprocessHandler exposes background reprocessing of raw payloads to administrators.
+type RawReprocessHandler struct {
+	jobs *service.RawReprocessJobService
+}
+
+// NewRawReprocessHandler wires a handler backed by the raw reprocess job service.
+func NewRawReprocessHandler(jobs *service.RawReprocessJobService) *RawReprocessHandler {
+	return &RawReprocessHandler{jobs: jobs}
+}
+
+// Create handles POST /admin/companies/reprocess-raw requests; the query holds the listing filter
+// of the companies to reprocess, every company without one. It answers 202 with the queued job;
+// poll GET /admin/companies/reprocess-raw/:id for progress. Only one job runs at a time.
+func (h *RawReprocessHandler) Create(c echo.Context) error {
+	filter, err := parseListFilter(c, false)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	job, err := h.jobs.Create(c.Request().Context(), filter, c.QueryString(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrRawReprocessInProgress) {
+			return Error(c, http.StatusConflict, err.Error())
+		}
+		log.Printf("request_id=%s failed to queue raw reprocess: %v", middlewarepkg.RequestIDFromContext(c), err)
+		return Error(c, http.StatusInternalServerError, "failed to queue raw reprocess")
+	}
+	return Success(c, http.StatusAccepted, "raw reprocess queued", job)
+}
+
+// List handles GET /admin/companies/reprocess-raw requests.
+func (h *RawReprocessHandler) List(c echo.Context) error {
+	limit := parseIntDefault(c.QueryParam("limit"), 50)
+	offset := parseIntDefault(c.QueryParam("offset"), 0)
+	jobs, err := h.jobs.List(c.Request().Context(), limit, offset)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list raw reprocess jobs")
+	}
+	return Success(c, http.StatusOK, "raw reprocess jobs retrieved", jobs)
+}
+
+// Get handles GET /admin/companies/reprocess-raw/:id requests with the job progress.
+func (h *RawReprocessHandler) Get(c echo.Context) error {
+	job, err := h.jobs.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRawReprocessJobID):
+			return Error(c, http.StatusBadRequest, "invalid raw reprocess job id")
+		case errors.Is(err, service.ErrRawReprocessJobNotFound):
+			return Error(c, http.StatusNotFound, "raw reprocess job not found")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to load raw reprocess job")
+		}
+	}
+	return Success(c, http.StatusOK, "raw reprocess job retrieved", job)
+}
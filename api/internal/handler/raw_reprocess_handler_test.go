@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type rawReprocessJobsRepoStub struct {
+	jobs map[uuid.UUID]entity.RawReprocessJob
+}
+
+func (s *rawReprocessJobsRepoStub) Create(ctx context.Context, job *entity.RawReprocessJob) error {
+	if len(s.jobs) > 0 {
+		return repository.ErrRawReprocessJobActive
+	}
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *rawReprocessJobsRepoStub) Update(ctx context.Context, job *entity.RawReprocessJob) error {
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *rawReprocessJobsRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.RawReprocessJob, error) {
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, repository.ErrRawReprocessJobNotFound
+	}
+	return &job, nil
+}
+
+func (s *rawReprocessJobsRepoStub) List(ctx context.Context, limit, offset int) ([]entity.RawReprocessJob, error) {
+	return []entity.RawReprocessJob{}, nil
+}
+
+func (s *rawReprocessJobsRepoStub) ListUnfinished(ctx context.Context) ([]entity.RawReprocessJob, error) {
+	return nil, nil
+}
+
+func TestRawReprocessHandler(t *testing.T) {
+	e := echo.New()
+	repo := &rawReprocessJobsRepoStub{jobs: map[uuid.UUID]entity.RawReprocessJob{}}
+	h := NewRawReprocessHandler(service.NewRawReprocessJobService(repo, nil, 0))
+
+	create := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		if err := h.Create(e.NewContext(httptest.NewRequest(http.MethodPost, "/admin/companies/reprocess-raw?"+query, nil), rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+	if rec := create("score_weight=2"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid filter, got %d", rec.Code)
+	}
+	if rec := create("city=Bandung"); rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"query":"city=Bandung"`) {
+		t.Fatalf("expected 202 with the queued job, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, job := range repo.jobs {
+		if !strings.Contains(string(job.Filter), `"City":"Bandung"`) {
+			t.Fatalf("expected the parsed filter stored, got %s", job.Filter)
+		}
+	}
+	if rec := create(""); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while a job is active, got %d", rec.Code)
+	}
+
+	get := func(id string) int {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/companies/reprocess-raw/"+id, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		if err := h.Get(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code
+	}
+	for id := range repo.jobs {
+		if code := get(id.String()); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+	if code := get("nope"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+	if code := get(uuid.NewString()); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ReportsHandler exposes catalogue reports.
+type ReportsHandler struct {
+	statuses *service.BusinessStatusService
+}
+
+// NewReportsHandler constructs a handler instance.
+func NewReportsHandler(statuses *service.BusinessStatusService) *ReportsHandler {
+	return &ReportsHandler{statuses: statuses}
+}
+
+// NewlyClosed handles GET /reports/closures requests.
+func (h *ReportsHandler) NewlyClosed(c echo.Context) error {
+	var since *time.Time
+	if sinceStr := strings.TrimSpace(c.QueryParam("since")); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			return Error(c, http.StatusBadRequest, "invalid since (use RFC3339)")
+		}
+		since = &parsed
+	} else if daysStr := strings.TrimSpace(c.QueryParam("days")); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			return Error(c, http.StatusBadRequest, "invalid days (use a positive integer)")
+		}
+		start := time.Now().AddDate(0, 0, -days)
+		since = &start
+	}
+
+	report, err := h.statuses.NewlyClosedReport(
+		c.Request().Context(),
+		since,
+		c.QueryParam("country"),
+		c.QueryParam("city"),
+		parseIntDefault(c.QueryParam("limit"), 0),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidClosureWindow) {
+			return Error(c, http.StatusBadRequest, err.Error())
+		}
+		return Error(c, http.StatusInternalServerError, "failed to build closure report")
+	}
+	return Success(c, http.StatusOK, "closure report generated", report)
+}
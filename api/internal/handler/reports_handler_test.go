@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type businessStatusRepoStub struct {
+	filter repository.ClosureReportFilter
+}
+
+func (s *businessStatusRepoStub) ListNewlyClosedAreas(ctx context.Context, filter repository.ClosureReportFilter) ([]entity.ClosureArea, error) {
+	s.filter = filter
+	return []entity.ClosureArea{{Country: "Indonesia", City: "Jakarta", NewlyClosed: 2}}, nil
+}
+
+func TestReportsHandler_NewlyClosed(t *testing.T) {
+	repo := &businessStatusRepoStub{}
+	handler := NewReportsHandler(service.NewBusinessStatusService(repo))
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/reports/closures?since=2024-05-01T00:00:00Z&city=Jakarta", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.NewlyClosed(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if repo.filter.City != "Jakarta" || repo.filter.Since.Format("2006-01-02") != "2024-05-01" {
+		t.Fatalf("unexpected filter: %+v", repo.filter)
+	}
+
+	for _, query := range []string{"since=yesterday", "days=-3", "since=2999-01-01T00:00:00Z"} {
+		req = httptest.NewRequest(http.MethodGet, "/reports/closures?"+query, nil)
+		rec = httptest.NewRecorder()
+		if err := handler.NewlyClosed(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
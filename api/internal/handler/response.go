@@ -1,16 +1,29 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/i18n"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/repository"
 )
 
 // APIResponse describes the standard envelope returned by the API.
 type APIResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message,omitempty"`
-	Data    any    `json:"data,omitempty"`
+	// Code identifies the kind of error for clients that branch on it.
+	Code string `json:"code,omitempty"`
+	Data any    `json:"data,omitempty"`
+	// Errors maps each rejected request parameter to the reason it was rejected.
+	Errors map[string]string `json:"errors,omitempty"`
 }
 
 // Success sends a successful response using the shared envelope format.
@@ -20,20 +33,98 @@ func Success(c echo.Context, status int, message string, data any) error {
 	}
 	payload := APIResponse{
 		Status:  "success",
-		Message: message,
+		Message: localize(c, message),
 		Data:    data,
 	}
 	return c.JSON(status, payload)
 }
 
+// SuccessWithETag sends a successful response tagged with a strong ETag derived from its body. When
+// the request's If-None-Match already names that ETag, it answers 304 Not Modified without a body so
+// polling clients do not download an unchanged payload again.
+func SuccessWithETag(c echo.Context, status int, message string, data any) error {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body, err := json.Marshal(APIResponse{
+		Status:  "success",
+		Message: localize(c, message),
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	// Clients may keep the payload but must revalidate it before reuse.
+	header.Set(echo.HeaderCacheControl, "no-cache")
+
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(status, body)
+}
+
+// etagMatches applies the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // Error sends an error response using the shared envelope format.
 func Error(c echo.Context, status int, message string) error {
+	return ErrorWithCode(c, status, "", message)
+}
+
+// ErrorWithCode sends an error response carrying a machine-readable code. Messages in the i18n
+// catalog are sent in the language of the request with their catalog code unless code is set.
+func ErrorWithCode(c echo.Context, status int, code, message string) error {
 	if status == 0 {
 		status = http.StatusInternalServerError
 	}
+	if status >= http.StatusInternalServerError {
+		middlewarepkg.CaptureServerError(c, nil, message)
+	}
+	code, message = i18n.Translate(code, message, middlewarepkg.LanguageFromContext(c))
 	payload := APIResponse{
 		Status:  "error",
 		Message: message,
+		Code:    code,
 	}
 	return c.JSON(status, payload)
 }
+
+// localize returns a success message in the language of the request.
+func localize(c echo.Context, message string) string {
+	_, text := i18n.Translate("", message, middlewarepkg.LanguageFromContext(c))
+	return text
+}
+
+// queryError answers a failed read: 504 when the query ran past the repository timeout, so clients
+// know to narrow the filters, and 500 with message otherwise.
+func queryError(c echo.Context, err error, message string) error {
+	if errors.Is(err, repository.ErrQueryTimeout) {
+		return Error(c, http.StatusGatewayTimeout, "query timed out; narrow the filters and retry")
+	}
+	middlewarepkg.CaptureServerError(c, err, message)
+	return Error(c, http.StatusInternalServerError, message)
+}
+
+// ValidationError sends a 422 response listing a message per rejected parameter.
+func ValidationError(c echo.Context, errs map[string]string) error {
+	code, message := i18n.Translate("", "invalid query parameters", middlewarepkg.LanguageFromContext(c))
+	return c.JSON(http.StatusUnprocessableEntity, APIResponse{
+		Status:  "error",
+		Message: message,
+		Code:    code,
+		Errors:  errs,
+	})
+}
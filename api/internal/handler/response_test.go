@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/labstack/echo/v4"
+
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 )
 
 func TestSuccess(t *testing.T) {
@@ -52,3 +54,78 @@ func TestError(t *testing.T) {
 		t.Fatalf("unexpected response: %+v", payload)
 	}
 }
+
+func TestError_Localized(t *testing.T) {
+	e := echo.New()
+	e.Use(middlewarepkg.Language())
+	e.GET("/companies/:id", func(c echo.Context) error {
+		return Error(c, http.StatusNotFound, "company not found")
+	})
+	e.GET("/worker", func(c echo.Context) error {
+		return ErrorWithCode(c, http.StatusGatewayTimeout, ErrCodeWorkerTimeout, "worker request failed: context deadline exceeded")
+	})
+
+	cases := []struct {
+		path, acceptLanguage, code, message string
+	}{
+		{"/companies/1", "", "company_not_found", "company not found"},
+		{"/companies/1", "id-ID,id;q=0.9,en;q=0.8", "company_not_found", "perusahaan tidak ditemukan"},
+		{"/companies/1", "fr-FR", "company_not_found", "company not found"},
+		{"/worker", "en", ErrCodeWorkerTimeout, "worker request failed: context deadline exceeded"},
+		{"/worker", "id", ErrCodeWorkerTimeout, "worker tidak merespons tepat waktu"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Accept-Language", tc.acceptLanguage)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var payload APIResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if payload.Code != tc.code || payload.Message != tc.message {
+			t.Fatalf("%s with %q: unexpected response %+v", tc.path, tc.acceptLanguage, payload)
+		}
+	}
+}
+
+func TestSuccessWithETag(t *testing.T) {
+	e := echo.New()
+	data := map[string]string{"foo": "bar"}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	if err := SuccessWithETag(e.NewContext(req, rec), http.StatusOK, "ok", data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.Len() == 0 {
+		t.Fatalf("expected tagged 200 response, got %d etag %q", rec.Code, etag)
+	}
+
+	cases := map[string]int{
+		etag:               http.StatusNotModified,
+		"W/" + etag:        http.StatusNotModified,
+		`"stale", ` + etag: http.StatusNotModified,
+		"*":                http.StatusNotModified,
+		`"stale"`:          http.StatusOK,
+	}
+	for ifNoneMatch, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		rec := httptest.NewRecorder()
+		if err := SuccessWithETag(e.NewContext(req, rec), http.StatusOK, "ok", data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != want {
+			t.Fatalf("If-None-Match %q: expected %d, got %d", ifNoneMatch, want, rec.Code)
+		}
+		if want == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Fatalf("expected empty body on 304, got %q", rec.Body.String())
+		}
+		if rec.Header().Get("ETag") != etag {
+			t.Fatalf("expected stable etag %q, got %q", etag, rec.Header().Get("ETag"))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ScoreRecomputeHandler exposes background lead score recomputation to administrators.
+type ScoreRecomputeHandler struct {
+	jobs *service.ScoreRecomputeJobService
+}
+
+// NewScoreRecomputeHandler wires a handler backed by the score recompute job service.
+func NewScoreRecomputeHandler(jobs *service.ScoreRecomputeJobService) *ScoreRecomputeHandler {
+	return &ScoreRecomputeHandler{jobs: jobs}
+}
+
+// Create handles POST /admin/scores/recompute requests. It answers 202 with the queued job; poll
+// GET /admin/scores/recompute/:id for progress. Only one job runs at a time.
+func (h *ScoreRecomputeHandler) Create(c echo.Context) error {
+	var req dto.ScoreRecomputeRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	userID, _ := c.Get(middlewarepkg.ContextKeyUserID).(string)
+	job, err := h.jobs.Create(c.Request().Context(), userID, req.StaleOnly)
+	if err != nil {
+		if errors.Is(err, service.ErrScoreRecomputeInProgress) {
+			return Error(c, http.StatusConflict, err.Error())
+		}
+		log.Printf("request_id=%s failed to queue score recompute: %v", middlewarepkg.RequestIDFromContext(c), err)
+		return Error(c, http.StatusInternalServerError, "failed to queue score recompute")
+	}
+	return Success(c, http.StatusAccepted, "score recompute queued", job)
+}
+
+// List handles GET /admin/scores/recompute requests.
+func (h *ScoreRecomputeHandler) List(c echo.Context) error {
+	limit := parseIntDefault(c.QueryParam("limit"), 50)
+	offset := parseIntDefault(c.QueryParam("offset"), 0)
+	jobs, err := h.jobs.List(c.Request().Context(), limit, offset)
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list score recompute jobs")
+	}
+	return Success(c, http.StatusOK, "score recompute jobs retrieved", jobs)
+}
+
+// Get handles GET /admin/scores/recompute/:id requests with the job progress.
+func (h *ScoreRecomputeHandler) Get(c echo.Context) error {
+	job, err := h.jobs.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidScoreRecomputeJobID):
+			return Error(c, http.StatusBadRequest, "invalid score recompute job id")
+		case errors.Is(err, service.ErrScoreRecomputeJobNotFound):
+			return Error(c, http.StatusNotFound, "score recompute job not found")
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to load score recompute job")
+		}
+	}
+	return Success(c, http.StatusOK, "score recompute job retrieved", job)
+}
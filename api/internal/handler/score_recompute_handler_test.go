@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type scoreRecomputeJobsRepoStub struct {
+	jobs map[uuid.UUID]entity.ScoreRecomputeJob
+}
+
+func (s *scoreRecomputeJobsRepoStub) Create(ctx context.Context, job *entity.ScoreRecomputeJob) error {
+	if len(s.jobs) > 0 {
+		return repository.ErrScoreRecomputeJobActive
+	}
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *scoreRecomputeJobsRepoStub) Update(ctx context.Context, job *entity.ScoreRecomputeJob) error {
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *scoreRecomputeJobsRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.ScoreRecomputeJob, error) {
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, repository.ErrScoreRecomputeJobNotFound
+	}
+	return &job, nil
+}
+
+func (s *scoreRecomputeJobsRepoStub) List(ctx context.Context, limit, offset int) ([]entity.ScoreRecomputeJob, error) {
+	return []entity.ScoreRecomputeJob{}, nil
+}
+
+func (s *scoreRecomputeJobsRepoStub) ListUnfinished(ctx context.Context) ([]entity.ScoreRecomputeJob, error) {
+	return nil, nil
+}
+
+func TestScoreRecomputeHandler(t *testing.T) {
+	e := echo.New()
+	repo := &scoreRecomputeJobsRepoStub{jobs: map[uuid.UUID]entity.ScoreRecomputeJob{}}
+	h := NewScoreRecomputeHandler(service.NewScoreRecomputeJobService(repo, nil, 0))
+
+	create := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/scores/recompute", strings.NewReader(`{"stale_only":true}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := h.Create(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+	if rec := create(); rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"stale_only":true`) {
+		t.Fatalf("expected 202 with the queued job, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := create(); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while a job is active, got %d", rec.Code)
+	}
+
+	get := func(id string) int {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/scores/recompute/"+id, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		if err := h.Get(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code
+	}
+	for id := range repo.jobs {
+		if code := get(id.String()); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+	if code := get("nope"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+	if code := get(uuid.NewString()); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// ScoringProfilesHandler exposes admin management of lead scoring profiles.
+type ScoringProfilesHandler struct {
+	profiles *service.ScoringProfilesService
+}
+
+// NewScoringProfilesHandler constructs a handler instance.
+func NewScoringProfilesHandler(profiles *service.ScoringProfilesService) *ScoringProfilesHandler {
+	return &ScoringProfilesHandler{profiles: profiles}
+}
+
+// List handles GET /admin/scoring-profiles requests.
+func (h *ScoringProfilesHandler) List(c echo.Context) error {
+	profiles, err := h.profiles.List(c.Request().Context())
+	if err != nil {
+		return Error(c, http.StatusInternalServerError, "failed to list scoring profiles")
+	}
+	return Success(c, http.StatusOK, "scoring profiles retrieved", profiles)
+}
+
+// Create handles POST /admin/scoring-profiles requests.
+func (h *ScoringProfilesHandler) Create(c echo.Context) error {
+	var req dto.ScoringProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	profile, err := h.profiles.Create(c.Request().Context(), req)
+	if err != nil {
+		return scoringProfileError(c, err)
+	}
+	return Success(c, http.StatusCreated, "scoring profile created", profile)
+}
+
+// Update handles PUT /admin/scoring-profiles/:id requests. Scores computed with an older revision
+// of the profile stay stored until recomputed.
+func (h *ScoringProfilesHandler) Update(c echo.Context) error {
+	var req dto.ScoringProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, http.StatusBadRequest, "invalid payload")
+	}
+	profile, err := h.profiles.Update(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return scoringProfileError(c, err)
+	}
+	message := "scoring profile updated"
+	if profile.Active {
+		message += "; run apiadmin recompute-scores --stale-only to rescore stored leads"
+	}
+	return Success(c, http.StatusOK, message, profile)
+}
+
+// Delete handles DELETE /admin/scoring-profiles/:id requests.
+func (h *ScoringProfilesHandler) Delete(c echo.Context) error {
+	if err := h.profiles.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return scoringProfileError(c, err)
+	}
+	return Success(c, http.StatusOK, "scoring profile deleted", nil)
+}
+
+// Activate handles POST /admin/scoring-profiles/:id/activate requests; the id "default" goes back
+// to the built-in weights.
+func (h *ScoringProfilesHandler) Activate(c echo.Context) error {
+	profile, err := h.profiles.Activate(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return scoringProfileError(c, err)
+	}
+	return Success(c, http.StatusOK, "scoring profile activated; run apiadmin recompute-scores --stale-only to rescore stored leads", profile)
+}
+
+func scoringProfileError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidScoringProfile):
+		return Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidScoringProfileID):
+		return Error(c, http.StatusBadRequest, "invalid scoring profile id")
+	case errors.Is(err, service.ErrScoringProfileNotFound):
+		return Error(c, http.StatusNotFound, "scoring profile not found")
+	case errors.Is(err, service.ErrScoringProfileExists):
+		return Error(c, http.StatusConflict, "scoring profile already exists")
+	default:
+		return Error(c, http.StatusInternalServerError, "failed to save scoring profile")
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+)
+
+type scoringProfilesRepoStub struct {
+	created []entity.ScoringProfile
+}
+
+func (s *scoringProfilesRepoStub) List(ctx context.Context) ([]entity.ScoringProfile, error) {
+	return s.created, nil
+}
+
+func (s *scoringProfilesRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.ScoringProfile, error) {
+	return nil, repository.ErrScoringProfileNotFound
+}
+
+func (s *scoringProfilesRepoStub) GetActive(ctx context.Context) (*entity.ScoringProfile, error) {
+	return nil, nil
+}
+
+func (s *scoringProfilesRepoStub) Create(ctx context.Context, profile *entity.ScoringProfile) error {
+	for _, existing := range s.created {
+		if existing.Name == profile.Name {
+			return repository.ErrScoringProfileDuplicate
+		}
+	}
+	profile.ID = uuid.New()
+	s.created = append(s.created, *profile)
+	return nil
+}
+
+func (s *scoringProfilesRepoStub) Update(ctx context.Context, profile *entity.ScoringProfile) error {
+	return repository.ErrScoringProfileNotFound
+}
+
+func (s *scoringProfilesRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrScoringProfileNotFound
+}
+
+func (s *scoringProfilesRepoStub) SetActive(ctx context.Context, id *uuid.UUID) error {
+	if id != nil {
+		return repository.ErrScoringProfileNotFound
+	}
+	return nil
+}
+
+func TestScoringProfilesHandler_Create(t *testing.T) {
+	e := echo.New()
+	handler := NewScoringProfilesHandler(service.NewScoringProfilesService(&scoringProfilesRepoStub{}, scoring.DefaultOptions()))
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{"created", `{"name":"Web agency","weights":{"website_quality":60,"contact_completeness":40},"inverted":["website_quality"]}`, http.StatusCreated},
+		{"duplicate", `{"name":"Web agency","weights":{"social_presence":20}}`, http.StatusConflict},
+		{"unknown category", `{"name":"Vibes","weights":{"vibes":20}}`, http.StatusBadRequest},
+		{"inverted thresholds", `{"name":"Strict","weights":{"social_presence":20},"hot_threshold":10,"warm_threshold":15}`, http.StatusBadRequest},
+		{"invalid json", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/scoring-profiles", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			_ = handler.Create(e.NewContext(req, rec))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestScoringProfilesHandler_Activate(t *testing.T) {
+	e := echo.New()
+	handler := NewScoringProfilesHandler(service.NewScoringProfilesService(&scoringProfilesRepoStub{}, scoring.DefaultOptions()))
+
+	for id, want := range map[string]int{"default": http.StatusOK, uuid.NewString(): http.StatusNotFound, "nope": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/scoring-profiles/x/activate", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+
+		_ = handler.Activate(c)
+		if rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d", id, want, rec.Code)
+		}
+	}
+}
@@ -2,19 +2,32 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	"github.com/octobees/leads-generator/api/internal/auth"
 	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
 	middleware "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
 )
 
+// scrapeEventsKeepAlive is how often an idle scrape event stream sends a comment.
+const scrapeEventsKeepAlive = 15 * time.Second
+
 // ScrapeHandler posts scrape requests to the worker service.
 type ScrapeHandler struct {
-	worker WorkerPoster
+	worker    WorkerPoster
+	callbacks *auth.CallbackSigner
+	jobs      *service.ScrapeJobService
 }
 
 // NewScrapeHandler constructs a scrape handler backed by an HTTP client.
@@ -28,12 +41,218 @@ func NewScrapeHandlerWithWorker(worker WorkerPoster) *ScrapeHandler {
 	return &ScrapeHandler{worker: worker}
 }
 
-// Enqueue handles POST /scrape requests and forwards them to the worker.
+// NewScrapeHandlerWithCallbacks also gives every job an ingest run and a callback token scoped to
+// it, so the worker can stream the results to the guarded /ingest/runs endpoints.
+func NewScrapeHandlerWithCallbacks(worker WorkerPoster, callbacks *auth.CallbackSigner) *ScrapeHandler {
+	return &ScrapeHandler{worker: worker, callbacks: callbacks}
+}
+
+// NewScrapeHandlerWithJobs also records every scrape as a job, so identical requests made while it
+// is queued join it instead of reaching the worker again.
+func NewScrapeHandlerWithJobs(worker WorkerPoster, callbacks *auth.CallbackSigner, jobs *service.ScrapeJobService) *ScrapeHandler {
+	return &ScrapeHandler{worker: worker, callbacks: callbacks, jobs: jobs}
+}
+
+// Enqueue handles POST /scrape requests and forwards them to the worker. With scrape jobs a request
+// identical to a queued one answers with that job and its run instead.
 func (h *ScrapeHandler) Enqueue(c echo.Context) error {
 	var req dto.ScrapeRequest
 	if err := c.Bind(&req); err != nil {
 		return Error(c, http.StatusBadRequest, "invalid payload")
 	}
+	if msg := normalizeScrapeRequest(&req); msg != "" {
+		return Error(c, http.StatusBadRequest, msg)
+	}
+
+	ctx := c.Request().Context()
+	var job *entity.ScrapeJob
+	if h.jobs != nil {
+		userID, _ := c.Get(middleware.ContextKeyUserID).(string)
+		var coalesced bool
+		var err error
+		job, coalesced, err = h.jobs.Claim(ctx, req, userID)
+		if err != nil {
+			return Error(c, http.StatusInternalServerError, "failed to record scrape job")
+		}
+		if coalesced {
+			return Success(c, http.StatusOK, "scrape job already queued", h.scrapeJobData(job, true))
+		}
+	}
+
+	payload := dto.WorkerScrapeRequest{
+		Source:       req.Source,
+		TypeBusiness: req.TypeBusiness,
+		City:         req.City,
+		Country:      req.Country,
+		MinRating:    req.MinRating,
+	}
+	runID := uuid.Nil
+	if job != nil {
+		runID = job.ID
+	}
+	if err := assignScrapeRun(h.callbacks, &payload, runID); err != nil {
+		h.failJob(c, job, errCallbackToken)
+		return Error(c, http.StatusInternalServerError, errCallbackToken)
+	}
+
+	data, err := h.worker.PostJSON(ctx, "/scrape", payload, middleware.RequestIDFromContext(c))
+	if err != nil {
+		h.failJob(c, job, err.Error())
+		return workerError(c, err)
+	}
+	if data == nil {
+		data = map[string]any{"status": "queued"}
+	}
+	if payload.RunID != "" {
+		data["run_id"] = payload.RunID
+	}
+	if job != nil {
+		for key, value := range h.scrapeJobData(job, false) {
+			if _, ok := data[key]; !ok {
+				data[key] = value
+			}
+		}
+	}
+	return Success(c, http.StatusOK, "scrape job queued", data)
+}
+
+// scrapeJobData describes a job in an enqueue response; the run is only named when the worker
+// streams results to it.
+func (h *ScrapeHandler) scrapeJobData(job *entity.ScrapeJob, coalesced bool) map[string]any {
+	data := map[string]any{
+		"status":     job.Status,
+		"job_id":     job.ID.String(),
+		"coalesced":  coalesced,
+		"requesters": job.Requesters,
+	}
+	if h.callbacks != nil {
+		data["run_id"] = job.ID.String()
+	}
+	return data
+}
+
+// failJob marks a job the worker never received, so the next identical request scrapes again.
+func (h *ScrapeHandler) failJob(c echo.Context, job *entity.ScrapeJob, reason string) {
+	if job == nil {
+		return
+	}
+	if err := h.jobs.Fail(c.Request().Context(), job.ID, reason); err != nil {
+		log.Printf("request_id=%s failed to mark scrape job %s failed: %v", middleware.RequestIDFromContext(c), job.ID, err)
+	}
+}
+
+// Jobs handles GET /scrape/jobs requests with the scrape jobs the caller asked for, including those
+// started by someone else, newest request first.
+func (h *ScrapeHandler) Jobs(c echo.Context) error {
+	if h.jobs == nil {
+		return Error(c, http.StatusNotImplemented, "scrape jobs are not enabled")
+	}
+	userID, _ := c.Get(middleware.ContextKeyUserID).(string)
+	jobs, err := h.jobs.ListRequested(
+		c.Request().Context(),
+		userID,
+		parseIntDefault(c.QueryParam("page"), 1),
+		parseIntDefault(c.QueryParam("per_page"), 20),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidScrapeRequester) {
+			return Error(c, http.StatusUnauthorized, "unauthorized")
+		}
+		return queryError(c, err, "failed to list scrape jobs")
+	}
+	return Success(c, http.StatusOK, "scrape jobs retrieved", jobs)
+}
+
+// JobEvents handles GET /scrape/jobs/:id/events, streaming a job the caller asked for as server-sent
+// events: the job as a status event, then a status event on every transition and a progress event
+// whenever the worker streams batches. The stream ends once the job is finished or failed.
+func (h *ScrapeHandler) JobEvents(c echo.Context) error {
+	if h.jobs == nil {
+		return Error(c, http.StatusNotImplemented, "scrape jobs are not enabled")
+	}
+	userID, _ := c.Get(middleware.ContextKeyUserID).(string)
+	events, err := h.jobs.WatchJob(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return scrapeJobError(c, err)
+	}
+	return streamScrapeJobEvents(c, events)
+}
+
+// Events handles GET /scrape/events, streaming the caller's latest scrape jobs like JobEvents: the
+// queued and running ones first, then every change, until the client disconnects.
+func (h *ScrapeHandler) Events(c echo.Context) error {
+	if h.jobs == nil {
+		return Error(c, http.StatusNotImplemented, "scrape jobs are not enabled")
+	}
+	userID, _ := c.Get(middleware.ContextKeyUserID).(string)
+	events, err := h.jobs.WatchRequested(c.Request().Context(), userID)
+	if err != nil {
+		return scrapeJobError(c, err)
+	}
+	return streamScrapeJobEvents(c, events)
+}
+
+func scrapeJobError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidScrapeRequester):
+		return Error(c, http.StatusUnauthorized, "unauthorized")
+	case errors.Is(err, service.ErrInvalidScrapeJobID):
+		return Error(c, http.StatusBadRequest, "invalid scrape job id")
+	case errors.Is(err, service.ErrScrapeJobNotFound):
+		return Error(c, http.StatusNotFound, "scrape job not found")
+	default:
+		return queryError(c, err, "failed to read scrape jobs")
+	}
+}
+
+// streamScrapeJobEvents writes events as server-sent events numbered from 1, with a comment every
+// scrapeEventsKeepAlive so proxies keep the idle connection open.
+func streamScrapeJobEvents(c echo.Context, events <-chan service.ScrapeJobEvent) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Tells nginx not to buffer the stream.
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(scrapeEventsKeepAlive)
+	defer keepAlive.Stop()
+	for id := 1; ; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event.Job)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", id, event.Type, data); err != nil {
+				return nil
+			}
+			id++
+		case <-keepAlive.C:
+			if _, err := io.WriteString(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case <-c.Request().Context().Done():
+			return nil
+		}
+		res.Flush()
+	}
+}
+
+// normalizeScrapeRequest trims req, resolves its source and fills the city and country from a
+// "city, country" location when they are missing, returning what is wrong with it or an empty
+// string.
+func normalizeScrapeRequest(req *dto.ScrapeRequest) string {
+	source, err := service.ResolveScrapeSource(req.Source)
+	if err != nil {
+		return err.Error()
+	}
+	req.Source = source.Name()
 
 	// Normalize fields
 	req.TypeBusiness = strings.TrimSpace(req.TypeBusiness)
@@ -44,7 +263,7 @@ func (h *ScrapeHandler) Enqueue(c echo.Context) error {
 	}
 
 	if req.TypeBusiness == "" {
-		return Error(c, http.StatusBadRequest, "type_business is required")
+		return "type_business is required"
 	}
 
 	if req.City == "" || req.Country == "" {
@@ -58,27 +277,9 @@ func (h *ScrapeHandler) Enqueue(c echo.Context) error {
 	}
 
 	if req.City == "" || req.Country == "" {
-		return Error(c, http.StatusBadRequest, "city and country are required")
-	}
-
-	payload := map[string]any{
-		"type_business": req.TypeBusiness,
-		"city":          req.City,
-		"country":       req.Country,
-	}
-	if req.MinRating > 0 {
-		payload["min_rating"] = req.MinRating
+		return "city and country are required"
 	}
-
-	ctx := c.Request().Context()
-	data, err := h.worker.PostJSON(ctx, "/scrape", payload, middleware.RequestIDFromContext(c))
-	if err != nil {
-		return Error(c, http.StatusBadGateway, err.Error())
-	}
-	if data == nil {
-		data = map[string]any{"status": "queued"}
-	}
-	return Success(c, http.StatusOK, "scrape job queued", data)
+	return ""
 }
 
 func extractWorkerError(body io.Reader) string {
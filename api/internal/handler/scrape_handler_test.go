@@ -2,13 +2,25 @@ package handler
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
 )
 
 func newTestScrapeHandler(worker WorkerPoster) *ScrapeHandler {
@@ -57,6 +69,19 @@ func TestScrapeHandler_ValidationErrors(t *testing.T) {
 		}
 	})
 
+	t.Run("unknown source", func(t *testing.T) {
+		body := `{"type_business":"plumber","city":"Gotham","country":"USA","source":"yelp"}`
+		req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		_ = handler.Enqueue(c)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "source must be one of google_maps") {
+			t.Fatalf("expected 400 for an unknown source, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
 	t.Run("worker base url missing", func(t *testing.T) {
 		defer func() {
 			if r := recover(); r == nil {
@@ -86,6 +111,24 @@ func TestScrapeHandler_WorkerInteraction(t *testing.T) {
 		}
 	})
 
+	t.Run("worker timeout", func(t *testing.T) {
+		body := `{"type_business":"plumber","city":"Gotham","country":"USA"}`
+		req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		handler := newTestScrapeHandler(&workerStub{err: fmt.Errorf("worker request failed: %w", context.DeadlineExceeded)})
+
+		_ = handler.Enqueue(c)
+		var resp APIResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if rec.Code != http.StatusGatewayTimeout || resp.Code != ErrCodeWorkerTimeout {
+			t.Fatalf("expected 504 %s, got %d %+v", ErrCodeWorkerTimeout, rec.Code, resp)
+		}
+	})
+
 	t.Run("worker returns error payload", func(t *testing.T) {
 		body := `{"type_business":"plumber","city":"Gotham","country":"USA"}`
 		req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(body))
@@ -147,3 +190,124 @@ func TestExtractWorkerError(t *testing.T) {
 		t.Fatalf("expected default message, got %s", msg)
 	}
 }
+
+type scrapeJobsRepoStub struct {
+	jobs   map[string]*entity.ScrapeJob
+	failed []uuid.UUID
+}
+
+func (s *scrapeJobsRepoStub) ClaimScrapeJob(ctx context.Context, job *entity.ScrapeJob, requester *uuid.UUID, window time.Duration) (bool, error) {
+	if existing, ok := s.jobs[job.Fingerprint]; ok && existing.Status == entity.ScrapeJobQueued {
+		existing.Requesters++
+		*job = *existing
+		return true, nil
+	}
+	job.Status = entity.ScrapeJobQueued
+	job.Requesters = 1
+	stored := *job
+	s.jobs[job.Fingerprint] = &stored
+	return false, nil
+}
+
+func (s *scrapeJobsRepoStub) FailScrapeJob(ctx context.Context, id uuid.UUID, reason string) error {
+	s.failed = append(s.failed, id)
+	for _, job := range s.jobs {
+		if job.ID == id {
+			job.Status = entity.ScrapeJobFailed
+		}
+	}
+	return nil
+}
+
+func (s *scrapeJobsRepoStub) ListRequestedScrapeJobs(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.ScrapeJob, error) {
+	return nil, nil
+}
+
+func (s *scrapeJobsRepoStub) GetRequestedScrapeJob(ctx context.Context, userID, id uuid.UUID) (*entity.ScrapeJob, error) {
+	for _, job := range s.jobs {
+		if job.ID == id {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrScrapeJobNotFound
+}
+
+func TestScrapeHandler_CoalescesIdenticalRequests(t *testing.T) {
+	e := echo.New()
+	repo := &scrapeJobsRepoStub{jobs: make(map[string]*entity.ScrapeJob)}
+	worker := &workerStub{data: map[string]any{"status": "queued"}}
+	signer := auth.NewCallbackSigner("callback-secret", time.Hour)
+	handler := NewScrapeHandlerWithJobs(worker, signer, service.NewScrapeJobService(repo, time.Hour))
+
+	enqueue := func(body string) map[string]any {
+		req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, uuid.NewString())
+		_ = handler.Enqueue(c)
+		var resp struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v (%s)", err, rec.Body.String())
+		}
+		return resp.Data
+	}
+
+	first := enqueue(`{"type_business":"plumber","city":"Jakarta","country":"Indonesia"}`)
+	sent, ok := worker.payload.(dto.WorkerScrapeRequest)
+	if !ok || first["job_id"] == nil || sent.RunID != first["job_id"] || first["coalesced"] != false {
+		t.Fatalf("expected the job to be the run sent to the worker, got %v and %+v", first, worker.payload)
+	}
+
+	worker.payload = nil
+	second := enqueue(`{"type_business":"Plumber","location":"jakarta, INDONESIA"}`)
+	if worker.payload != nil || second["job_id"] != first["job_id"] || second["run_id"] != first["job_id"] || second["coalesced"] != true || second["requesters"] != float64(2) {
+		t.Fatalf("expected the identical request to join the job, got %v", second)
+	}
+
+	worker.err = errors.New("worker down")
+	enqueue(`{"type_business":"plumber","city":"Bandung","country":"Indonesia"}`)
+	if len(repo.failed) != 1 {
+		t.Fatalf("expected the job the worker refused to be failed, got %v", repo.failed)
+	}
+}
+
+func TestScrapeHandler_JobEvents(t *testing.T) {
+	e := echo.New()
+	items := 25
+	job := &entity.ScrapeJob{ID: uuid.New(), Status: entity.ScrapeJobFinished, Items: &items}
+	repo := &scrapeJobsRepoStub{jobs: map[string]*entity.ScrapeJob{"fp": job}}
+	handler := NewScrapeHandlerWithJobs(&workerStub{}, nil, service.NewScrapeJobService(repo, time.Hour))
+
+	events := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/scrape/jobs/"+id+"/events", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set(middlewarepkg.ContextKeyUserID, uuid.NewString())
+		if err := handler.JobEvents(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	rec := events(job.ID.String())
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+	want := fmt.Sprintf("id: 1\nevent: status\ndata: {\"id\":\"%s\",", job.ID)
+	if !strings.HasPrefix(rec.Body.String(), want) || !strings.Contains(rec.Body.String(), `"status":"finished"`) || !strings.Contains(rec.Body.String(), `"items":25`) {
+		t.Fatalf("unexpected stream %q", rec.Body.String())
+	}
+
+	if rec := events(uuid.NewString()); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a job the caller did not ask for, got %d", rec.Code)
+	}
+	if rec := events("nope"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid id, got %d", rec.Code)
+	}
+}
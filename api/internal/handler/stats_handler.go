@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// StatsHandler exposes dashboard aggregates.
+type StatsHandler struct {
+	stats *service.StatsService
+}
+
+// NewStatsHandler constructs a handler instance.
+func NewStatsHandler(stats *service.StatsService) *StatsHandler {
+	return &StatsHandler{stats: stats}
+}
+
+// Get handles GET /stats requests.
+func (h *StatsHandler) Get(c echo.Context) error {
+	stats, err := h.stats.CatalogueStats(
+		c.Request().Context(),
+		parseIntDefault(c.QueryParam("top"), 0),
+		parseIntDefault(c.QueryParam("runs"), 0),
+	)
+	if err != nil {
+		return queryError(c, err, "failed to compute stats")
+	}
+	return Success(c, http.StatusOK, "stats retrieved", stats)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type statsRepoStub struct {
+	err error
+}
+
+func (s *statsRepoStub) CatalogueTotals(ctx context.Context) (*entity.CatalogueStats, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &entity.CatalogueStats{Companies: 3, Enriched: 1}, nil
+}
+
+func (s *statsRepoStub) TopCities(ctx context.Context, limit int) ([]entity.StatBucket, error) {
+	return []entity.StatBucket{{Name: "Jakarta", Companies: 3}}, nil
+}
+
+func (s *statsRepoStub) TopBusinessTypes(ctx context.Context, limit int) ([]entity.StatBucket, error) {
+	return []entity.StatBucket{}, nil
+}
+
+func (s *statsRepoStub) ScrapeRunTotals(ctx context.Context, limit int) ([]entity.ScrapeRunStats, error) {
+	return []entity.ScrapeRunStats{}, nil
+}
+
+func TestStatsHandler_Get(t *testing.T) {
+	e := echo.New()
+	handler := NewStatsHandler(service.NewStatsService(&statsRepoStub{}, 0))
+
+	req := httptest.NewRequest(http.MethodGet, "/stats?top=5", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data entity.CatalogueStats `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.Companies != 3 || len(body.Data.TopCities) != 1 {
+		t.Fatalf("unexpected stats payload: %s", rec.Body.String())
+	}
+
+	handler = NewStatsHandler(service.NewStatsService(&statsRepoStub{err: errors.New("boom")}, 0))
+	rec = httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(httptest.NewRequest(http.MethodGet, "/stats", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+
+	handler = NewStatsHandler(service.NewStatsService(&statsRepoStub{err: fmt.Errorf("%w: canceling statement", repository.ErrQueryTimeout)}, 0))
+	rec = httptest.NewRecorder()
+	if err := handler.Get(e.NewContext(httptest.NewRequest(http.MethodGet, "/stats", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d", rec.Code)
+	}
+}
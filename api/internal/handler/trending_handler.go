@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// TrendingQueryRules bound the numeric filters of trending companies.
+var TrendingQueryRules = append([]QueryRule{
+	IntParam("window_days", 1, service.MaxTrendingWindowDays),
+	IntParam("min_reviews", 0, 0),
+	IntParam("min_review_gain", 0, 0),
+	FloatParam("min_rating_gain", 0, 4),
+}, CompanyListQueryRules...)
+
+// Trending handles GET /companies/trending requests, listing the companies whose reviews or rating
+// grew the most between their last two scrape runs.
+func (h *CompaniesHandler) Trending(c echo.Context) error {
+	filter, err := parseTrendingFilter(c)
+	if err != nil {
+		return Error(c, http.StatusBadRequest, err.Error())
+	}
+	companies, err := h.service.TrendingCompanies(c.Request().Context(), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTrendingWindow), errors.Is(err, service.ErrInvalidTrendingRanking):
+			return Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrTrendingUnavailable):
+			return Error(c, http.StatusNotImplemented, "trending companies are not enabled")
+		default:
+			return queryError(c, err, "failed to list trending companies")
+		}
+	}
+	return Success(c, http.StatusOK, "trending companies retrieved", companies)
+}
+
+// parseTrendingFilter reads the listing filter plus the window_days, by, min_reviews,
+// min_review_gain and min_rating_gain params. Trends span runs, so the latest run is not enforced.
+func parseTrendingFilter(c echo.Context) (dto.TrendingFilter, error) {
+	listFilter, err := parseListFilter(c, false)
+	filter := dto.TrendingFilter{ListFilter: listFilter, By: c.QueryParam("by")}
+	if err != nil {
+		return filter, err
+	}
+
+	if windowStr := strings.TrimSpace(c.QueryParam("window_days")); windowStr != "" {
+		window, err := strconv.Atoi(windowStr)
+		if err != nil || window <= 0 {
+			return filter, errors.New("invalid window_days")
+		}
+		filter.WindowDays = window
+	}
+	if minReviewsStr := strings.TrimSpace(c.QueryParam("min_reviews")); minReviewsStr != "" {
+		minReviews, err := strconv.Atoi(minReviewsStr)
+		if err != nil || minReviews < 0 {
+			return filter, errors.New("invalid min_reviews")
+		}
+		filter.MinReviews = &minReviews
+	}
+	if gainStr := strings.TrimSpace(c.QueryParam("min_review_gain")); gainStr != "" {
+		gain, err := strconv.Atoi(gainStr)
+		if err != nil {
+			return filter, errors.New("invalid min_review_gain")
+		}
+		filter.MinReviewGain = &gain
+	}
+	if gainStr := strings.TrimSpace(c.QueryParam("min_rating_gain")); gainStr != "" {
+		gain, err := strconv.ParseFloat(gainStr, 64)
+		if err != nil {
+			return filter, errors.New("invalid min_rating_gain")
+		}
+		filter.MinRatingGain = &gain
+	}
+	return filter, nil
+}
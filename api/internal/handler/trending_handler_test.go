@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type trendingRepoStub struct {
+	filter dto.TrendingFilter
+}
+
+func (s *trendingRepoStub) ListTrendingCompanies(ctx context.Context, filter dto.TrendingFilter) ([]entity.TrendingCompany, error) {
+	s.filter = filter
+	delta := 40
+	return []entity.TrendingCompany{{Company: entity.Company{Company: "Acme"}, ReviewsDelta: &delta}}, nil
+}
+
+func TestCompaniesHandler_Trending(t *testing.T) {
+	repo := &trendingRepoStub{}
+	handler := NewCompaniesHandler(service.NewCompaniesService(&capturingCompaniesRepo{}, service.WithTrending(repo)))
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/companies/trending?city=Bandung&type_business=cafe&window_days=14&min_review_gain=10&min_reviews=50", nil)
+	rec := httptest.NewRecorder()
+	if err := handler.Trending(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	got := repo.filter
+	if got.City != "Bandung" || got.TypeBusiness != "cafe" || got.WindowDays != 14 || *got.MinReviewGain != 10 || *got.MinReviews != 50 {
+		t.Fatalf("unexpected filter %+v", got)
+	}
+	if got.LatestRunOnly {
+		t.Fatalf("expected trends to span runs")
+	}
+
+	for _, query := range []string{"by=stars", "min_rating_gain=lots", "window_days=400"} {
+		req = httptest.NewRequest(http.MethodGet, "/companies/trending?"+query, nil)
+		rec = httptest.NewRecorder()
+		if err := handler.Trending(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
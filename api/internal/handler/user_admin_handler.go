@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 
@@ -21,13 +22,34 @@ func NewUserAdminHandler(users *service.UserService) *UserAdminHandler {
 	return &UserAdminHandler{users: users}
 }
 
-// List returns all users.
+// List handles GET /admin/users requests: one page of users filtered by email substring and role,
+// sorted by created_at (newest first by default) or email.
 func (h *UserAdminHandler) List(c echo.Context) error {
-	records, err := h.users.ListUsers(c.Request().Context())
+	filter := dto.UserListFilter{
+		Email: c.QueryParam("email"),
+		Role:  c.QueryParam("role"),
+		Sort:  strings.TrimSpace(c.QueryParam("sort")),
+		Limit: parseIntDefault(c.QueryParam("limit"), 0),
+	}
+	switch order := strings.ToLower(strings.TrimSpace(c.QueryParam("order"))); order {
+	case "":
+		filter.Desc = filter.Sort == "" || filter.Sort == dto.UserSortCreatedAt
+	case "asc", "desc":
+		filter.Desc = order == "desc"
+	default:
+		return Error(c, http.StatusBadRequest, "order must be asc or desc")
+	}
+
+	page, err := h.users.ListUsers(c.Request().Context(), filter, c.QueryParam("cursor"))
 	if err != nil {
-		return Error(c, http.StatusInternalServerError, "failed to list users")
+		switch {
+		case errors.Is(err, service.ErrInvalidUserSort), errors.Is(err, service.ErrInvalidUserCursor):
+			return Error(c, http.StatusBadRequest, err.Error())
+		default:
+			return Error(c, http.StatusInternalServerError, "failed to list users")
+		}
 	}
-	return Success(c, http.StatusOK, "users retrieved", records)
+	return Success(c, http.StatusOK, "users retrieved", page)
 }
 
 // Create provisions a new user.
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -19,7 +20,7 @@ import (
 )
 
 type usersRepoForHandler struct {
-	list   func(ctx context.Context) ([]entity.User, error)
+	list   func(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error)
 	create func(ctx context.Context, email, passwordHash, role string) (*entity.User, error)
 	update func(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error)
 	delete func(ctx context.Context, id uuid.UUID) error
@@ -40,13 +41,18 @@ func (u *usersRepoForHandler) Create(ctx context.Context, email, passwordHash, r
 	return nil, errors.New("not implemented")
 }
 
-func (u *usersRepoForHandler) List(ctx context.Context) ([]entity.User, error) {
+func (u *usersRepoForHandler) List(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
 	if u.list != nil {
-		return u.list(ctx)
+		return u.list(ctx, filter)
 	}
 	return nil, errors.New("not implemented")
 }
 
+func (u *usersRepoForHandler) Count(ctx context.Context, filter dto.UserListFilter) (int64, error) {
+	users, err := u.List(ctx, filter)
+	return int64(len(users)), err
+}
+
 func (u *usersRepoForHandler) Update(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error) {
 	if u.update != nil {
 		return u.update(ctx, id, email, passwordHash, role)
@@ -68,14 +74,16 @@ func newUserAdminHandler(repo repository.UsersRepository) *UserAdminHandler {
 
 func TestUserAdminHandler_List(t *testing.T) {
 	e := echo.New()
+	var captured dto.UserListFilter
 	repo := &usersRepoForHandler{
-		list: func(ctx context.Context) ([]entity.User, error) {
-			return []entity.User{{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}}, nil
+		list: func(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
+			captured = filter
+			return []entity.UserSummary{{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}}, nil
 		},
 	}
 	handler := newUserAdminHandler(repo)
 
-	req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	req := httptest.NewRequest(http.MethodGet, "/admin/users?email=ADMIN&role=admin&sort=email&limit=10", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
@@ -85,8 +93,31 @@ func TestUserAdminHandler_List(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
+	if captured.Email != "ADMIN" || captured.Role != "admin" || captured.Sort != dto.UserSortEmail || captured.Desc || captured.Limit != 11 {
+		t.Fatalf("unexpected filter: %+v", captured)
+	}
+	if strings.Contains(rec.Body.String(), "password") {
+		t.Fatalf("expected no password fields, got %s", rec.Body.String())
+	}
+	var body struct {
+		Data service.UsersPage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.Total != 1 || len(body.Data.Users) != 1 || body.Data.HasMore {
+		t.Fatalf("unexpected page: %+v", body.Data)
+	}
+
+	for _, query := range []string{"sort=role", "order=sideways", "cursor=not-a-cursor"} {
+		rec = httptest.NewRecorder()
+		_ = handler.List(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/users?"+query, nil), rec))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
 
-	repo.list = func(ctx context.Context) ([]entity.User, error) {
+	repo.list = func(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
 		return nil, errors.New("boom")
 	}
 	rec = httptest.NewRecorder()
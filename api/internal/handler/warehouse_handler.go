@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// WarehouseHandler exposes bulk exports for the data warehouse.
+type WarehouseHandler struct {
+	warehouse *service.WarehouseService
+}
+
+// NewWarehouseHandler constructs a handler instance.
+func NewWarehouseHandler(warehouse *service.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{warehouse: warehouse}
+}
+
+// Export handles GET /admin/exports/companies requests by streaming a Parquet file stamped with the
+// exporting admin.
+func (h *WarehouseHandler) Export(c echo.Context) error {
+	format := strings.ToLower(strings.TrimSpace(c.QueryParam("format")))
+	if format == "" {
+		format = "parquet"
+	}
+	if format != "parquet" {
+		return Error(c, http.StatusBadRequest, "unsupported format (use parquet)")
+	}
+
+	stamp := newExportStamp(c)
+	filename := fmt.Sprintf("companies-%s.parquet", stamp.At.Format("20060102"))
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, service.ParquetContentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure can only be logged; the truncated file has no footer
+	// and will be rejected by Parquet readers.
+	if _, err := h.warehouse.WriteParquet(c.Request().Context(), res, stamp); err != nil {
+		log.Printf("parquet export failed: %v", err)
+	}
+	return nil
+}
+
+// Manifest handles GET /admin/exports/warehouse/manifest requests.
+func (h *WarehouseHandler) Manifest(c echo.Context) error {
+	export, err := h.warehouse.LatestExport(c.Request().Context())
+	if err != nil {
+		if errors.Is(err, service.ErrWarehouseExportNotFound) {
+			return Error(c, http.StatusNotFound, "no warehouse export yet")
+		}
+		return Error(c, http.StatusInternalServerError, "failed to load warehouse manifest")
+	}
+	return Success(c, http.StatusOK, "latest warehouse partition", export)
+}
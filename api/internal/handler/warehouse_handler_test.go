@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+type warehouseRepoStub struct {
+	latest *entity.WarehouseExport
+}
+
+func (s *warehouseRepoStub) ListExportRowsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.WarehouseRow, uuid.UUID, error) {
+	if after != uuid.Nil {
+		return nil, after, nil
+	}
+	id := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	return []entity.WarehouseRow{{ID: id.String(), Company: "Acme"}}, id, nil
+}
+
+func (s *warehouseRepoStub) RecordExport(ctx context.Context, export *entity.WarehouseExport) error {
+	return nil
+}
+
+func (s *warehouseRepoStub) LatestExport(ctx context.Context) (*entity.WarehouseExport, error) {
+	if s.latest == nil {
+		return nil, repository.ErrWarehouseExportNotFound
+	}
+	return s.latest, nil
+}
+
+func TestWarehouseHandler_Export(t *testing.T) {
+	e := echo.New()
+	handler := NewWarehouseHandler(service.NewWarehouseService(&warehouseRepoStub{}, nil, "", 0))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/exports/companies?format=parquet", nil)
+	if err := handler.Export(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != service.ParquetContentType {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+	// Parquet files start and end with the PAR1 magic bytes.
+	body := rec.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Fatalf("expected a parquet file, got %d bytes", len(body))
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/admin/exports/companies?format=csv", nil)
+	if err := handler.Export(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %d", rec.Code)
+	}
+}
+
+func TestWarehouseHandler_Manifest(t *testing.T) {
+	e := echo.New()
+	repo := &warehouseRepoStub{}
+	handler := NewWarehouseHandler(service.NewWarehouseService(repo, nil, "", 0))
+
+	rec := httptest.NewRecorder()
+	if err := handler.Manifest(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/exports/warehouse/manifest", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before the first export, got %d", rec.Code)
+	}
+
+	repo.latest = &entity.WarehouseExport{
+		PartitionDate: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		Location:      "gs://bucket/warehouse/companies/dt=2024-06-02/",
+		Files:         []string{"gs://bucket/warehouse/companies/dt=2024-06-02/part-00000.parquet"},
+		RowCount:      1,
+	}
+	rec = httptest.NewRecorder()
+	if err := handler.Manifest(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/exports/warehouse/manifest", nil), rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte("dt=2024-06-02")) {
+		t.Fatalf("unexpected manifest response %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+// errCallbackToken is answered when a job cannot be given its callback token.
+const errCallbackToken = "failed to issue worker callback token"
+
+// assignScrapeRun gives a scrape job the ingest run its results are streamed to, runID or a new one
+// when it is nil, and the token the worker presents to /ingest/runs. Without a signer the payload
+// is left as is.
+func assignScrapeRun(callbacks *auth.CallbackSigner, payload *dto.WorkerScrapeRequest, runID uuid.UUID) error {
+	if callbacks == nil {
+		return nil
+	}
+	if runID == uuid.Nil {
+		runID = uuid.New()
+	}
+	token, err := callbacks.Issue(auth.CallbackScrape, runID.String())
+	if err != nil {
+		return err
+	}
+	payload.RunID = runID.String()
+	payload.CallbackToken = token
+	return nil
+}
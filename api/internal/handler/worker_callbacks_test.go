@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+func TestWorkerCallbacks_Issue(t *testing.T) {
+	e := echo.New()
+	signer := auth.NewCallbackSigner("secret", time.Hour)
+	companyID := "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+
+	t.Run("scrape jobs get an ingest run", func(t *testing.T) {
+		worker := &workerStub{data: map[string]any{"status": "queued"}}
+		req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(`{"type_business":"plumber","city":"Gotham","country":"USA"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := NewScrapeHandlerWithCallbacks(worker, signer).Enqueue(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		payload := worker.payload.(dto.WorkerScrapeRequest)
+		claims, err := signer.Verify(payload.CallbackToken, auth.CallbackScrape)
+		if err != nil || claims.JobID() != payload.RunID {
+			t.Fatalf("expected a scrape token for run %s, got %+v (%v)", payload.RunID, claims, err)
+		}
+		if !strings.Contains(rec.Body.String(), `"run_id":"`+payload.RunID+`"`) || strings.Contains(rec.Body.String(), payload.CallbackToken) {
+			t.Fatalf("expected the run id but not the token in the response, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("enrich jobs share their id with the token", func(t *testing.T) {
+		worker := &capturingWorker{}
+		handler := NewEnrichWorkerHandlerWithJobs(worker, service.NewEnrichJobService(&enrichJobsRepoStub{}, 10), nil, signer)
+		req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(`{"company_id":"`+companyID+`","website":"https://example.com"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewarepkg.ContextKeyUserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+		if err := handler.Enqueue(c); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		payload := worker.payload.(dto.WorkerEnrichRequest)
+		claims, err := signer.Verify(payload.CallbackToken, auth.CallbackEnrich)
+		if err != nil || claims.JobID() != payload.JobID || !claims.CoversCompany(companyID) {
+			t.Fatalf("expected an enrich token for the company, got %+v (%v)", claims, err)
+		}
+		if !strings.Contains(rec.Body.String(), `"job_id":"`+payload.JobID+`"`) {
+			t.Fatalf("expected the recorded job to carry the token's id, got %s", rec.Body.String())
+		}
+	})
+}
+
+func TestWorkerCallbacks_EnrichResultScope(t *testing.T) {
+	e := echo.New()
+	signer := auth.NewCallbackSigner("secret", time.Hour)
+	token, _ := signer.Issue(auth.CallbackEnrich, uuid.NewString(), "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	scrapeToken, _ := signer.Issue(auth.CallbackScrape, uuid.NewString())
+	handler := NewEnrichHandler(service.NewCompaniesService(&enrichmentRepoStub{}))
+	guarded := middlewarepkg.WorkerCallback(signer, auth.CallbackEnrich)(handler.SaveResult)
+
+	post := func(companyID, token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/enrich-result", strings.NewReader(`{"company_id":"`+companyID+`","emails":["info@example.com"]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		if err := guarded(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+		return rec.Code
+	}
+
+	if code := post("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", code)
+	}
+	if code := post("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", scrapeToken); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a scrape token, got %d", code)
+	}
+	if code := post("cccccccc-cccc-cccc-cccc-cccccccccccc", token); code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another company, got %d", code)
+	}
+	if code := post("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", token); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+}
+
+func TestWorkerCallbacks_IngestRunScope(t *testing.T) {
+	e := echo.New()
+	signer := auth.NewCallbackSigner("secret", time.Hour)
+	runID := uuid.NewString()
+	token, _ := signer.Issue(auth.CallbackScrape, runID)
+	h := NewIngestRunsHandler(service.NewIngestRunsService(&ingestRunsRepoStub{applied: map[int]string{}}))
+	guard := middlewarepkg.WorkerCallback(signer, auth.CallbackScrape)
+
+	post := func(id, body string, handle echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/ingest/runs", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		if err := guard(handle)(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	if rec := post("", `{"source":"serpapi","query":"coffee"}`, h.Start); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), runID) {
+		t.Fatalf("expected the run to default to the token's, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("", `{"run_id":"`+uuid.NewString()+`"}`, h.Start); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another run, got %d", rec.Code)
+	}
+	batch := `{"sequence":0,"items":[{"place_id":"ChIJ1","name":"Kopi Kenangan"}]}`
+	if rec := post(uuid.NewString(), batch, h.AppendBatch); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a batch of another run, got %d", rec.Code)
+	}
+	if rec := post(runID, batch, h.AppendBatch); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -4,13 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/api/idtoken"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/outbound"
 )
 
 // WorkerClient posts JSON payloads to worker endpoints.
@@ -21,57 +31,187 @@ type WorkerPoster interface {
 type WorkerClient struct {
 	client  *http.Client
 	baseURL string
+	workerDeadlines
+}
+
+// workerDeadlines bound the calls of a worker client, whatever its transport.
+type workerDeadlines struct {
+	timeout time.Duration
+	// callTimeouts override timeout for the calls to some paths.
+	callTimeouts map[string]time.Duration
+	// margin is kept back from the caller's deadline, so the handler can still answer after the
+	// worker call gives up.
+	margin time.Duration
+}
+
+// WorkerTimeoutHeader tells the worker how many milliseconds it has to answer.
+const WorkerTimeoutHeader = "X-Request-Timeout-Ms"
+
+// WorkerClientOption tunes a WorkerClient.
+type WorkerClientOption func(*WorkerClient)
+
+// WithCallTimeout bounds the calls to path, such as /scrape, by timeout instead of the client
+// timeout.
+func WithCallTimeout(path string, timeout time.Duration) WorkerClientOption {
+	return func(c *WorkerClient) {
+		c.callTimeouts[path] = timeout
+	}
+}
+
+// WithDeadlineMargin ends each call margin before the deadline of its context.
+func WithDeadlineMargin(margin time.Duration) WorkerClientOption {
+	return func(c *WorkerClient) {
+		c.margin = margin
+	}
+}
+
+// workerUnavailableError marks failures where the worker never took the request, so it is safe to
+// send the request to another worker.
+type workerUnavailableError struct{ error }
+
+func (e workerUnavailableError) Unwrap() error { return e.error }
+
+// isWorkerUnavailable reports whether err shows the worker could not be reached.
+func isWorkerUnavailable(err error) bool {
+	var unavailable workerUnavailableError
+	return errors.As(err, &unavailable)
+}
+
+// isTimeout reports whether err is a timeout, after which the worker may still have accepted the
+// request.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
 }
 
 // NewWorkerClient builds a worker client, auto-configuring an ID token client when needed.
 func NewWorkerClient(client *http.Client, workerBaseURL string) *WorkerClient {
+	return NewWorkerClientWithPolicy(client, workerBaseURL, nil)
+}
+
+// NewWorkerClientWithPolicy builds a worker client whose requests are pinned to the worker host by
+// policy: redirects past the policy's cap or to any other host are refused, and the fallback client
+// used without ID tokens dials through the policy.
+func NewWorkerClientWithPolicy(client *http.Client, workerBaseURL string, policy *outbound.Policy) *WorkerClient {
+	return NewWorkerClientWithTimeout(client, workerBaseURL, policy, 0)
+}
+
+// NewWorkerClientWithTimeout builds a policy-pinned worker client whose calls are each bounded by
+// timeout; zero keeps the client's own timeout.
+func NewWorkerClientWithTimeout(client *http.Client, workerBaseURL string, policy *outbound.Policy, timeout time.Duration, opts ...WorkerClientOption) *WorkerClient {
 	if workerBaseURL == "" {
 		panic("workerBaseURL must not be empty")
 	}
 	workerBaseURL = strings.TrimRight(workerBaseURL, "/")
+	var pinned *outbound.Policy
+	if policy != nil {
+		if u, err := url.Parse(workerBaseURL); err == nil {
+			pinned = policy.Pin(u.Hostname())
+		}
+	}
 	if client == nil {
 		idc, err := idtoken.NewClient(context.Background(), workerBaseURL)
-		if err != nil {
-			client = &http.Client{Timeout: 10 * time.Second}
-		} else {
+		switch {
+		case err == nil:
 			client = idc
+		case pinned != nil:
+			client = pinned.Client(10 * time.Second)
+		default:
+			client = &http.Client{Timeout: 10 * time.Second}
+		}
+	}
+	// Worker calls carry the trace context, so the worker's callbacks join the request's trace.
+	traced := *client
+	traced.Transport = otelhttp.NewTransport(client.Transport)
+	if pinned != nil {
+		traced.CheckRedirect = pinned.CheckRedirect
+	}
+	client = &traced
+	return &WorkerClient{client: client, baseURL: workerBaseURL, workerDeadlines: newWorkerDeadlines(timeout, opts)}
+}
+
+// newWorkerDeadlines applies the deadline options of opts over timeout.
+func newWorkerDeadlines(timeout time.Duration, opts []WorkerClientOption) workerDeadlines {
+	c := &WorkerClient{workerDeadlines: workerDeadlines{timeout: timeout, callTimeouts: make(map[string]time.Duration)}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c.workerDeadlines
+}
+
+// withTimeout bounds ctx by the timeout of calls to path, if any, and by the deadline of ctx less
+// the margin. It fails when that leaves no time for the call.
+func (c *workerDeadlines) withTimeout(ctx context.Context, path string) (context.Context, context.CancelFunc, error) {
+	timeout := c.timeout
+	if t, ok := c.callTimeouts[path]; ok {
+		timeout = t
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline) - c.margin
+		if remaining <= 0 {
+			return nil, nil, context.DeadlineExceeded
+		}
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
 		}
 	}
-	return &WorkerClient{client: client, baseURL: workerBaseURL}
+	if timeout <= 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
 }
 
-// PostJSON posts the payload to the worker and returns the "data" object.
-func (c *WorkerClient) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+// PostJSON posts the payload to the worker and returns the "data" object. A panic while calling
+// the worker is returned as an error.
+func (c *WorkerClient) PostJSON(ctx context.Context, path string, payload any, requestID string) (data map[string]any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("worker request panicked: %v", r)
+		}
+	}()
+
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
+	ctx, cancel, err := c.withTimeout(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("worker request failed: %w", err)
+	}
+	defer cancel()
 	url := c.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create worker request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set(WorkerTimeoutHeader, strconv.FormatInt(time.Until(deadline).Milliseconds(), 10))
+	}
 	if requestID != "" {
 		req.Header.Set("X-Request-ID", requestID)
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
+		if !isTimeout(err) {
+			err = workerUnavailableError{err}
+		}
 		return nil, fmt.Errorf("worker request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		errMsg := extractWorkerError(resp.Body)
-		return nil, fmt.Errorf("worker error: %s", errMsg)
+		err := fmt.Errorf("worker error: %s", extractWorkerError(resp.Body))
+		if resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable {
+			err = workerUnavailableError{err}
+		}
+		return nil, err
 	}
 
-	var workerResp struct {
-		Data  map[string]any `json:"data"`
-		Error string         `json:"error"`
-	}
+	var workerResp dto.WorkerResponse
 	if err := json.NewDecoder(resp.Body).Decode(&workerResp); err != nil && err != io.EOF {
 		return nil, fmt.Errorf("could not decode worker response: %w", err)
 	}
@@ -81,4 +221,53 @@ func (c *WorkerClient) PostJSON(ctx context.Context, path string, payload any, r
 	return workerResp.Data, nil
 }
 
+// Ping issues a cheap HEAD request against the worker health endpoint.
+func (c *WorkerClient) Ping(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("worker ping panicked: %v", r)
+		}
+	}()
+
+	ctx, cancel, err := c.withTimeout(ctx, "/healthz")
+	if err != nil {
+		return fmt.Errorf("worker unreachable: %w", err)
+	}
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create worker ping: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("worker unreachable: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("worker unhealthy: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Error codes of failed worker calls.
+const (
+	ErrCodeWorkerTimeout     = "worker_timeout"
+	ErrCodeWorkerUnavailable = "worker_unavailable"
+	ErrCodeWorkerError       = "worker_error"
+)
+
+// workerError answers a failed worker call: 504 when it ran out of time, so clients can tell a
+// slow worker from a failing one, and 502 otherwise.
+func workerError(c echo.Context, err error) error {
+	middlewarepkg.CaptureServerError(c, err, err.Error())
+	switch {
+	case isTimeout(err):
+		return ErrorWithCode(c, http.StatusGatewayTimeout, ErrCodeWorkerTimeout, err.Error())
+	case isWorkerUnavailable(err):
+		return ErrorWithCode(c, http.StatusBadGateway, ErrCodeWorkerUnavailable, err.Error())
+	default:
+		return ErrorWithCode(c, http.StatusBadGateway, ErrCodeWorkerError, err.Error())
+	}
+}
+
 var _ WorkerPoster = (*WorkerClient)(nil)
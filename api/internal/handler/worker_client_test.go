@@ -5,7 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestWorkerClient_PostJSON(t *testing.T) {
@@ -27,3 +34,131 @@ func TestWorkerClient_PostJSON(t *testing.T) {
 		t.Fatalf("expected queued, got %v", data)
 	}
 }
+
+func TestWorkerClient_Ping(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewWorkerClient(server.Client(), server.URL)
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatalf("expected error for unhealthy worker")
+	}
+}
+
+func TestWorkerClient_ClassifiesFailures(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]any{"error": "busy"})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewWorkerClientWithTimeout(server.Client(), server.URL, nil, 50*time.Millisecond)
+	ctx := context.Background()
+	if _, err := client.PostJSON(ctx, "/scrape", nil, ""); !isWorkerUnavailable(err) {
+		t.Fatalf("expected a 503 to mark the worker unavailable, got %v", err)
+	}
+	status = http.StatusBadRequest
+	if _, err := client.PostJSON(ctx, "/scrape", nil, ""); err == nil || isWorkerUnavailable(err) {
+		t.Fatalf("expected a rejected request, got %v", err)
+	}
+	if _, err := client.PostJSON(ctx, "/slow", nil, ""); !isTimeout(err) || isWorkerUnavailable(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	closed := NewWorkerClient(server.Client(), "http://127.0.0.1:1")
+	if _, err := closed.PostJSON(ctx, "/scrape", nil, ""); !isWorkerUnavailable(err) {
+		t.Fatalf("expected a refused connection to mark the worker unavailable, got %v", err)
+	}
+}
+
+func TestWorkerClient_PropagatesTraceContext(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(prev)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"status": "queued"}})
+	}))
+	defer server.Close()
+
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "POST /scrape")
+	defer span.End()
+	if _, err := NewWorkerClient(server.Client(), server.URL).PostJSON(ctx, "/scrape", nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Fatalf("expected the worker call to carry trace %s, got %q", span.SpanContext().TraceID(), traceparent)
+	}
+}
+
+func TestWorkerClient_TimeoutBudget(t *testing.T) {
+	var budget string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget = r.Header.Get(WorkerTimeoutHeader)
+		if r.URL.Path == "/enrich" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"status": "queued"}})
+	}))
+	defer server.Close()
+
+	client := NewWorkerClientWithTimeout(server.Client(), server.URL, nil, 5*time.Second,
+		WithCallTimeout("/enrich", 20*time.Millisecond), WithDeadlineMargin(time.Second))
+
+	if _, err := client.PostJSON(context.Background(), "/enrich", nil, ""); !isTimeout(err) {
+		t.Fatalf("expected the enrich call timeout to apply, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if _, err := client.PostJSON(ctx, "/scrape", nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ms, err := strconv.Atoi(budget); err != nil || ms <= 1000 || ms > 2000 {
+		t.Fatalf("expected the worker budget to be the deadline less the margin, got %q", budget)
+	}
+
+	budget = ""
+	short, cancelShort := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancelShort()
+	if _, err := client.PostJSON(short, "/scrape", nil, ""); !isTimeout(err) || budget != "" {
+		t.Fatalf("expected a deadline inside the margin to fail before calling the worker, got %v", err)
+	}
+}
+
+type panickingTransport struct{}
+
+func (panickingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	panic("transport bug")
+}
+
+func TestWorkerClient_RecoversPanics(t *testing.T) {
+	client := NewWorkerClient(&http.Client{Transport: panickingTransport{}}, "http://worker:9000")
+	if _, err := client.PostJSON(context.Background(), "/scrape", nil, ""); err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Fatalf("expected the panic as an error, got %v", err)
+	}
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatalf("expected the panic as an error")
+	}
+}
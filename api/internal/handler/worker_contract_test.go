@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+)
+
+// Contract tests pin the JSON exchanged with the worker to the files under testdata/worker.
+// Requests the API sends are regenerated with `go test ./internal/handler -run Contract -update`;
+// payloads the worker sends mirror worker/src and must be edited by hand alongside it.
+var updateGolden = flag.Bool("update", false, "rewrite golden files for API to worker payloads")
+
+func goldenPath(name string) string {
+	return filepath.Join("testdata", "worker", name)
+}
+
+func assertGoldenJSON(t *testing.T, name string, value any) {
+	t.Helper()
+	got, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	got = append(got, '\n')
+	if *updateGolden {
+		if err := os.WriteFile(goldenPath(name), got, 0o644); err != nil {
+			t.Fatalf("update golden %s: %v", name, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenPath(name))
+	if err != nil {
+		t.Fatalf("read golden %s: %v", name, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("payload drifted from %s (rerun with -update if intended)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// decodeGoldenStrict decodes a worker payload, failing on fields the DTO does not know about.
+func decodeGoldenStrict(t *testing.T, name string, dest any) []byte {
+	t.Helper()
+	data, err := os.ReadFile(goldenPath(name))
+	if err != nil {
+		t.Fatalf("read golden %s: %v", name, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+	return data
+}
+
+func TestWorkerContract_ScrapeRequest(t *testing.T) {
+	worker := &workerStub{}
+	handler := NewScrapeHandlerWithWorker(worker)
+
+	e := echo.New()
+	body := `{"type_business":" coffee shop ","location":"Jakarta, Indonesia","min_rating":4.2}`
+	req := httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := handler.Enqueue(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || worker.path != "/scrape" {
+		t.Fatalf("expected scrape to be forwarded, got %d to %q", rec.Code, worker.path)
+	}
+	assertGoldenJSON(t, "scrape_request.json", worker.payload)
+}
+
+func TestWorkerContract_EnrichRequest(t *testing.T) {
+	worker := &workerStub{}
+	handler := NewEnrichWorkerHandlerWithWorker(worker)
+
+	e := echo.New()
+	body := `{"company_id":"aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa","website":"https://acme.example","depth":"deep"}`
+	req := httptest.NewRequest(http.MethodPost, "/enrich", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := handler.Enqueue(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || worker.path != "/enrich" {
+		t.Fatalf("expected enrichment to be forwarded, got %d to %q", rec.Code, worker.path)
+	}
+	assertGoldenJSON(t, "enrich_request.json", worker.payload)
+}
+
+func TestWorkerContract_EnrichResult(t *testing.T) {
+	for _, name := range []string{"enrich_result.json", "enrich_result_minimal.json"} {
+		t.Run(name, func(t *testing.T) {
+			var payload dto.EnrichResultRequest
+			body := decodeGoldenStrict(t, name, &payload)
+
+			repo := &enrichmentRepoStub{}
+			handler := NewEnrichHandler(service.NewCompaniesService(repo))
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/enrich-result", bytes.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			if err := handler.SaveResult(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if repo.saved == nil || repo.saved.CompanyID.String() != payload.CompanyID || len(repo.saved.Emails) != len(payload.Emails) {
+				t.Fatalf("expected worker payload to be stored, got %+v", repo.saved)
+			}
+		})
+	}
+}
+
+func TestWorkerContract_Responses(t *testing.T) {
+	var ok dto.WorkerResponse
+	okBody := decodeGoldenStrict(t, "scrape_response.json", &ok)
+	var failed dto.WorkerResponse
+	failedBody := decodeGoldenStrict(t, "error_response.json", &failed)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(failedBody)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(okBody)
+	}))
+	defer srv.Close()
+
+	client := NewWorkerClient(srv.Client(), srv.URL)
+	data, err := client.PostJSON(context.Background(), "/scrape", dto.WorkerScrapeRequest{}, "")
+	if err != nil || data["status"] != ok.Data["status"] {
+		t.Fatalf("expected queued response, got %v (%v)", data, err)
+	}
+	if _, err := client.PostJSON(context.Background(), "/fail", dto.WorkerScrapeRequest{}, ""); err == nil || !strings.Contains(err.Error(), failed.Error) {
+		t.Fatalf("expected worker error %q, got %v", failed.Error, err)
+	}
+}
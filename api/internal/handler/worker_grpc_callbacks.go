@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/workerpb"
+)
+
+// GRPCWorkerCallbacks serves the WorkerCallbacks gRPC service, the counterpart of POST
+// /enrich-result for workers on the gRPC transport. The callback token of the job travels as
+// "authorization: Bearer" metadata and is checked as the worker callback guard does.
+type GRPCWorkerCallbacks struct {
+	workerpb.UnimplementedWorkerCallbacksServer
+	companiesService *service.CompaniesService
+	callbacks        *auth.CallbackSigner
+	deadLetters      *service.DeadLetterService
+}
+
+// NewGRPCWorkerCallbacks wires the callback service storing results through companiesService.
+func NewGRPCWorkerCallbacks(companiesService *service.CompaniesService, callbacks *auth.CallbackSigner) *GRPCWorkerCallbacks {
+	return &GRPCWorkerCallbacks{companiesService: companiesService, callbacks: callbacks}
+}
+
+// NewGRPCWorkerCallbacksWithDeadLetters also keeps every result that cannot be stored as a dead
+// letter, encoded as the JSON body /enrich-result takes.
+func NewGRPCWorkerCallbacksWithDeadLetters(companiesService *service.CompaniesService, callbacks *auth.CallbackSigner, deadLetters *service.DeadLetterService) *GRPCWorkerCallbacks {
+	return &GRPCWorkerCallbacks{companiesService: companiesService, callbacks: callbacks, deadLetters: deadLetters}
+}
+
+// SubmitEnrichResult stores an enrichment result. Strict validation failures are answered
+// InvalidArgument with the rejected values as BadRequest field violations.
+func (s *GRPCWorkerCallbacks) SubmitEnrichResult(ctx context.Context, msg *workerpb.EnrichResult) (*workerpb.CallbackAck, error) {
+	claims, err := s.verify(ctx, auth.CallbackEnrich)
+	if err != nil {
+		return nil, err
+	}
+	result := enrichResultFromProto(msg)
+	if msg.GetCompanyId() == "" {
+		s.deadLetter(ctx, result, claims, errors.New("company_id is required"))
+		return nil, status.Error(codes.InvalidArgument, "company_id is required")
+	}
+	if !claims.CoversCompany(msg.GetCompanyId()) {
+		return nil, status.Error(codes.PermissionDenied, "callback token does not cover this company")
+	}
+
+	if err := s.companiesService.SaveEnrichment(ctx, result); err != nil {
+		s.deadLetter(ctx, result, claims, err)
+		var validationErr service.EnrichmentValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return nil, enrichmentValidationStatus(validationErr)
+		case errors.Is(err, service.ErrInvalidCompanyID):
+			return nil, status.Error(codes.InvalidArgument, "invalid company_id")
+		default:
+			return nil, status.Error(codes.Internal, "failed to persist enrichment")
+		}
+	}
+	return &workerpb.CallbackAck{Stored: true}, nil
+}
+
+// deadLetter keeps a rejected result when dead letters are enabled.
+func (s *GRPCWorkerCallbacks) deadLetter(ctx context.Context, result dto.EnrichResultRequest, claims *auth.CallbackClaims, cause error) {
+	if s.deadLetters == nil {
+		return
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	s.deadLetters.RecordEnrichResult(ctx, body, claims.JobID(), cause)
+}
+
+// verify reads and checks the callback token of kind from the incoming metadata.
+func (s *GRPCWorkerCallbacks) verify(ctx context.Context, kind string) (*auth.CallbackClaims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing callback token")
+	}
+	parts := strings.SplitN(values[0], " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+	claims, err := s.callbacks.Verify(parts[1], kind)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid callback token")
+	}
+	return claims, nil
+}
+
+func enrichmentValidationStatus(err service.EnrichmentValidationError) error {
+	st := status.New(codes.InvalidArgument, "invalid enrichment payload")
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(err.Fields))
+	for field, reason := range err.Fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: reason})
+	}
+	if detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/oauth"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/workerpb"
+)
+
+// workerServiceName is the service a gRPC worker reports the health of.
+const workerServiceName = "leadsgen.worker.v1.Worker"
+
+// GRPCWorkerClient sends jobs to a worker serving the Worker gRPC service, in place of the JSON
+// posts of WorkerClient. It takes the same paths and payloads, so handlers and the worker router
+// use either. Calls carry their deadline, the request id and the trace context.
+type GRPCWorkerClient struct {
+	worker workerpb.WorkerClient
+	health healthpb.HealthClient
+	workerDeadlines
+}
+
+// DialWorkerGRPC opens a connection to the worker gRPC service at addr, a host and port. With
+// useTLS the connection is encrypted and each call presents an ID token for the worker host when
+// default credentials can mint one, as Cloud Run requires; otherwise it is plaintext, for workers
+// on a private network.
+func DialWorkerGRPC(addr string, useTLS bool) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+	if useTLS {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})))
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("parse worker grpc address: %w", err)
+		}
+		if tokens, err := idtoken.NewTokenSource(context.Background(), "https://"+host); err == nil {
+			opts = append(opts, grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: tokens}))
+		}
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial worker grpc: %w", err)
+	}
+	return conn, nil
+}
+
+// NewGRPCWorkerClient builds a worker client calling the Worker service over conn, each call
+// bounded by timeout; zero leaves calls bounded by their context alone.
+func NewGRPCWorkerClient(conn grpc.ClientConnInterface, timeout time.Duration, opts ...WorkerClientOption) *GRPCWorkerClient {
+	return &GRPCWorkerClient{
+		worker:          workerpb.NewWorkerClient(conn),
+		health:          healthpb.NewHealthClient(conn),
+		workerDeadlines: newWorkerDeadlines(timeout, opts),
+	}
+}
+
+// PostJSON sends the job of a /scrape or /enrich payload to the worker and returns the data the
+// JSON endpoint would have answered with.
+func (c *GRPCWorkerClient) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+	ctx, cancel, err := c.withTimeout(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("worker request failed: %w", err)
+	}
+	defer cancel()
+	if requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID)
+	}
+
+	switch job := payload.(type) {
+	case dto.WorkerScrapeRequest:
+		if path != "/scrape" {
+			break
+		}
+		// The ScrapeJob message has no source; only Google Maps scrapes can go over gRPC.
+		if job.Source != "" && job.Source != entity.ScrapeSourceGoogleMaps {
+			return nil, fmt.Errorf("worker request failed: no grpc call for %s scrapes", job.Source)
+		}
+		accepted, err := c.worker.Scrape(ctx, scrapeJobProto(job))
+		if err != nil {
+			return nil, grpcWorkerError(err)
+		}
+		return map[string]any{"status": accepted.GetStatus()}, nil
+	case dto.WorkerEnrichRequest:
+		if path != "/enrich" {
+			break
+		}
+		result, err := c.worker.Enrich(ctx, enrichJobProto(job))
+		if err != nil {
+			return nil, grpcWorkerError(err)
+		}
+		return enrichResultData(enrichResultFromProto(result))
+	}
+	return nil, fmt.Errorf("worker request failed: no grpc call for %s with %T", path, payload)
+}
+
+// Ping checks the worker through the gRPC health service.
+func (c *GRPCWorkerClient) Ping(ctx context.Context) error {
+	ctx, cancel, err := c.withTimeout(ctx, "/healthz")
+	if err != nil {
+		return fmt.Errorf("worker unreachable: %w", err)
+	}
+	defer cancel()
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: workerServiceName})
+	if err != nil {
+		return fmt.Errorf("worker unreachable: %w", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("worker unhealthy: status %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// grpcWorkerError maps the status of a failed call onto the errors of WorkerClient, so workerError
+// and the router tell timeouts and unreachable workers from failing ones alike.
+func grpcWorkerError(err error) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("worker request failed: %s: %w", st.Message(), context.DeadlineExceeded)
+	case codes.Canceled:
+		return fmt.Errorf("worker request failed: %s: %w", st.Message(), context.Canceled)
+	case codes.Unavailable:
+		return fmt.Errorf("worker request failed: %w", workerUnavailableError{errors.New(st.Message())})
+	default:
+		return fmt.Errorf("worker error: %s", st.Message())
+	}
+}
+
+func scrapeJobProto(job dto.WorkerScrapeRequest) *workerpb.ScrapeJob {
+	msg := &workerpb.ScrapeJob{
+		TypeBusiness:  job.TypeBusiness,
+		City:          job.City,
+		Country:       job.Country,
+		RunId:         job.RunID,
+		CallbackToken: job.CallbackToken,
+	}
+	if job.MinRating != 0 {
+		msg.MinRating = &job.MinRating
+	}
+	return msg
+}
+
+func enrichJobProto(job dto.WorkerEnrichRequest) *workerpb.EnrichJob {
+	return &workerpb.EnrichJob{
+		CompanyId:     job.CompanyID,
+		Website:       job.Website,
+		Depth:         job.Depth,
+		MaxPages:      int32(job.MaxPages),
+		JobId:         job.JobID,
+		CallbackToken: job.CallbackToken,
+	}
+}
+
+// enrichResultFromProto reads an enrichment result the way /enrich-result decodes its JSON body.
+func enrichResultFromProto(msg *workerpb.EnrichResult) dto.EnrichResultRequest {
+	result := dto.EnrichResultRequest{
+		CompanyID:       msg.GetCompanyId(),
+		Emails:          msg.GetEmails(),
+		Phones:          msg.GetPhones(),
+		Address:         msg.Address,
+		ContactFormURL:  msg.ContactFormUrl,
+		AboutSummary:    msg.AboutSummary,
+		Website:         msg.GetWebsite(),
+		PagesCrawled:    int(msg.GetPagesCrawled()),
+		Depth:           msg.GetDepth(),
+		WebsiteLanguage: msg.WebsiteLanguage,
+		LogoURL:         msg.LogoUrl,
+		PhotoReference:  msg.PhotoReference,
+	}
+	if socials := msg.GetSocials(); len(socials) > 0 {
+		result.Socials = make(map[string][]string, len(socials))
+		for platform, links := range socials {
+			result.Socials[platform] = links.GetValues()
+		}
+	}
+	if msg.EmployeeMentions != nil {
+		mentions := int(msg.GetEmployeeMentions())
+		result.EmployeeMentions = &mentions
+	}
+	if followers := msg.GetSocialFollowers(); len(followers) > 0 {
+		result.SocialFollowers = make(map[string]int, len(followers))
+		for platform, count := range followers {
+			result.SocialFollowers[platform] = int(count)
+		}
+	}
+	if msg.GetTechnologiesChecked() {
+		result.Technologies = append([]string{}, msg.GetTechnologies()...)
+	}
+	return result
+}
+
+// enrichResultData renders an enrichment result as the data object of the JSON /enrich endpoint.
+func enrichResultData(result dto.EnrichResultRequest) (map[string]any, error) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encode enrichment result: %w", err)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decode enrichment result: %w", err)
+	}
+	return data, nil
+}
+
+var _ Worker = (*GRPCWorkerClient)(nil)
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/service"
+	"github.com/octobees/leads-generator/api/internal/workerpb"
+)
+
+type grpcWorkerStub struct {
+	workerpb.UnimplementedWorkerServer
+	scrape   func(ctx context.Context, job *workerpb.ScrapeJob) (*workerpb.ScrapeAccepted, error)
+	enrich   func(ctx context.Context, job *workerpb.EnrichJob) (*workerpb.EnrichResult, error)
+	deadline time.Time
+}
+
+func (s *grpcWorkerStub) Scrape(ctx context.Context, job *workerpb.ScrapeJob) (*workerpb.ScrapeAccepted, error) {
+	s.deadline, _ = ctx.Deadline()
+	return s.scrape(ctx, job)
+}
+
+func (s *grpcWorkerStub) Enrich(ctx context.Context, job *workerpb.EnrichJob) (*workerpb.EnrichResult, error) {
+	s.deadline, _ = ctx.Deadline()
+	return s.enrich(ctx, job)
+}
+
+// dialBufconn serves register on an in-memory listener and returns a connection to it.
+func dialBufconn(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCWorkerClient_Scrape(t *testing.T) {
+	stub := &grpcWorkerStub{scrape: func(ctx context.Context, job *workerpb.ScrapeJob) (*workerpb.ScrapeAccepted, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if got := md.Get("x-request-id"); len(got) != 1 || got[0] != "req-1" {
+			return nil, status.Error(codes.InvalidArgument, "missing request id")
+		}
+		if job.GetCity() != "Jakarta" || job.GetRunId() != "run-1" || job.MinRating == nil || job.GetMinRating() != 4.5 {
+			return nil, status.Errorf(codes.InvalidArgument, "unexpected job %v", job)
+		}
+		return &workerpb.ScrapeAccepted{Status: "queued"}, nil
+	}}
+	conn := dialBufconn(t, func(s *grpc.Server) { workerpb.RegisterWorkerServer(s, stub) })
+
+	client := NewGRPCWorkerClient(conn, 5*time.Second)
+	payload := dto.WorkerScrapeRequest{TypeBusiness: "cafe", City: "Jakarta", Country: "ID", MinRating: 4.5, RunID: "run-1"}
+	data, err := client.PostJSON(context.Background(), "/scrape", payload, "req-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data["status"] != "queued" {
+		t.Fatalf("expected queued, got %v", data)
+	}
+	if remaining := time.Until(stub.deadline); remaining <= 0 || remaining > 5*time.Second {
+		t.Fatalf("expected the client timeout propagated as the call deadline, got %v", remaining)
+	}
+
+	if _, err := client.PostJSON(context.Background(), "/enrich", payload, ""); err == nil {
+		t.Fatalf("expected an error for a scrape payload posted to /enrich")
+	}
+	payload.Source = "osm"
+	if _, err := client.PostJSON(context.Background(), "/scrape", payload, ""); err == nil {
+		t.Fatalf("expected an error for a scrape of another source than google maps")
+	}
+}
+
+func TestGRPCWorkerClient_Enrich(t *testing.T) {
+	stub := &grpcWorkerStub{enrich: func(ctx context.Context, job *workerpb.EnrichJob) (*workerpb.EnrichResult, error) {
+		mentions := int32(12)
+		return &workerpb.EnrichResult{
+			CompanyId:           job.GetCompanyId(),
+			Emails:              []string{"info@example.com"},
+			Socials:             map[string]*workerpb.StringList{"instagram": {Values: []string{"https://instagram.com/acme"}}},
+			Website:             job.GetWebsite(),
+			PagesCrawled:        3,
+			Depth:               job.GetDepth(),
+			EmployeeMentions:    &mentions,
+			TechnologiesChecked: true,
+		}, nil
+	}}
+	conn := dialBufconn(t, func(s *grpc.Server) { workerpb.RegisterWorkerServer(s, stub) })
+
+	client := NewGRPCWorkerClient(conn, 0, WithCallTimeout("/enrich", time.Minute))
+	payload := dto.WorkerEnrichRequest{CompanyID: "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", Website: "https://acme.com", Depth: "shallow", MaxPages: 5}
+	data, err := client.PostJSON(context.Background(), "/enrich", payload, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data["company_id"] != payload.CompanyID || data["pages_crawled"] != float64(3) || data["employee_mentions"] != float64(12) {
+		t.Fatalf("unexpected result %v", data)
+	}
+	if stub.deadline.IsZero() || time.Until(stub.deadline) > time.Minute {
+		t.Fatalf("expected the per-call timeout as deadline, got %v", stub.deadline)
+	}
+}
+
+func TestEnrichResultFromProto_Technologies(t *testing.T) {
+	if result := enrichResultFromProto(&workerpb.EnrichResult{}); result.Technologies != nil {
+		t.Fatalf("expected technologies left unset when not checked, got %v", result.Technologies)
+	}
+	result := enrichResultFromProto(&workerpb.EnrichResult{TechnologiesChecked: true})
+	if result.Technologies == nil || len(result.Technologies) != 0 {
+		t.Fatalf("expected an empty technology list once checked, got %v", result.Technologies)
+	}
+}
+
+func TestGRPCWorkerClient_ClassifiesFailures(t *testing.T) {
+	code := codes.Unavailable
+	stub := &grpcWorkerStub{scrape: func(ctx context.Context, job *workerpb.ScrapeJob) (*workerpb.ScrapeAccepted, error) {
+		return nil, status.Error(code, "boom")
+	}}
+	conn := dialBufconn(t, func(s *grpc.Server) { workerpb.RegisterWorkerServer(s, stub) })
+	client := NewGRPCWorkerClient(conn, time.Second)
+
+	_, err := client.PostJSON(context.Background(), "/scrape", dto.WorkerScrapeRequest{City: "Jakarta"}, "")
+	if !isWorkerUnavailable(err) {
+		t.Fatalf("expected an unavailable worker, got %v", err)
+	}
+
+	code = codes.DeadlineExceeded
+	_, err = client.PostJSON(context.Background(), "/scrape", dto.WorkerScrapeRequest{City: "Jakarta"}, "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	code = codes.Internal
+	_, err = client.PostJSON(context.Background(), "/scrape", dto.WorkerScrapeRequest{City: "Jakarta"}, "")
+	if err == nil || isWorkerUnavailable(err) || err.Error() != "worker error: boom" {
+		t.Fatalf("expected a worker error, got %v", err)
+	}
+}
+
+func TestGRPCWorkerClient_Ping(t *testing.T) {
+	healthServer := health.NewServer()
+	conn := dialBufconn(t, func(s *grpc.Server) { healthpb.RegisterHealthServer(s, healthServer) })
+	client := NewGRPCWorkerClient(conn, time.Second)
+
+	healthServer.SetServingStatus(workerServiceName, healthpb.HealthCheckResponse_SERVING)
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	healthServer.SetServingStatus(workerServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatalf("expected error for unhealthy worker")
+	}
+}
+
+func TestGRPCWorkerCallbacks_SubmitEnrichResult(t *testing.T) {
+	companyID := "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	signer := auth.NewCallbackSigner("secret", time.Hour)
+	jobID := uuid.New()
+	token, _ := signer.Issue(auth.CallbackEnrich, jobID.String(), companyID)
+	scrapeToken, _ := signer.Issue(auth.CallbackScrape, uuid.NewString())
+	repo := &enrichmentRepoStub{}
+	companies := service.NewCompaniesService(repo)
+	letters := &deadLettersRepoStub{}
+	callbacks := NewGRPCWorkerCallbacksWithDeadLetters(companies, signer, service.NewDeadLetterService(letters, companies))
+	conn := dialBufconn(t, func(s *grpc.Server) { workerpb.RegisterWorkerCallbacksServer(s, callbacks) })
+	client := workerpb.NewWorkerCallbacksClient(conn)
+
+	submit := func(companyID, token string) error {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		_, err := client.SubmitEnrichResult(ctx, &workerpb.EnrichResult{CompanyId: companyID, Emails: []string{"info@example.com"}})
+		return err
+	}
+
+	if code := status.Code(submit(companyID, "")); code != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without a token, got %v", code)
+	}
+	if code := status.Code(submit(companyID, scrapeToken)); code != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a scrape token, got %v", code)
+	}
+	if code := status.Code(submit("cccccccc-cccc-cccc-cccc-cccccccccccc", token)); code != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for another company, got %v", code)
+	}
+	if err := submit(companyID, token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.saved == nil || repo.saved.CompanyID.String() != companyID {
+		t.Fatalf("expected enrichment saved, got %+v", repo.saved)
+	}
+	if len(letters.letters) != 0 {
+		t.Fatalf("expected no dead letters for refused callers, got %+v", letters.letters)
+	}
+
+	repo.err = errors.New("boom")
+	if code := status.Code(submit(companyID, token)); code != codes.Internal {
+		t.Fatalf("expected Internal when the write fails, got %v", code)
+	}
+	if len(letters.letters) != 1 || letters.letters[0].JobID == nil || *letters.letters[0].JobID != jobID || !strings.Contains(letters.letters[0].Body, `"company_id":"`+companyID+`"`) {
+		t.Fatalf("expected the result kept as an /enrich-result body, got %+v", letters.letters)
+	}
+}
+
+func TestEnrichmentValidationStatus(t *testing.T) {
+	st := status.Convert(enrichmentValidationStatus(service.EnrichmentValidationError{Fields: map[string]string{"emails": "nobody@invalid"}}))
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("unexpected status %v", st)
+	}
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.GetFieldViolations()) != 1 || badRequest.GetFieldViolations()[0].GetField() != "emails" {
+		t.Fatalf("unexpected details %v", st.Details())
+	}
+}
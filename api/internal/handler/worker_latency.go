@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// DefaultWorkerLatencySamples is the number of recent calls per job type the percentiles cover.
+const DefaultWorkerLatencySamples = 512
+
+// WorkerLatencyRecorder times the jobs sent to a worker, failed ones included, for the admin
+// overview. Figures are per API instance and start over when it restarts.
+type WorkerLatencyRecorder struct {
+	Worker
+	samples int
+	now     func() time.Time
+
+	mu   sync.Mutex
+	jobs map[string]*latencySamples
+}
+
+// latencySamples keeps the latest durations of a job type in a ring.
+type latencySamples struct {
+	durations []time.Duration
+	next      int
+	calls     int64
+	errors    int64
+}
+
+// NewWorkerLatencyRecorder wraps worker, keeping the latest samples durations per job type; zero
+// or less keeps DefaultWorkerLatencySamples.
+func NewWorkerLatencyRecorder(worker Worker, samples int) *WorkerLatencyRecorder {
+	if samples <= 0 {
+		samples = DefaultWorkerLatencySamples
+	}
+	return &WorkerLatencyRecorder{Worker: worker, samples: samples, now: time.Now, jobs: make(map[string]*latencySamples)}
+}
+
+// PostJSON sends the job to the wrapped worker and records how long it took.
+func (r *WorkerLatencyRecorder) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+	start := r.now()
+	data, err := r.Worker.PostJSON(ctx, path, payload, requestID)
+	r.record(strings.Trim(path, "/"), r.now().Sub(start), err != nil)
+	return data, err
+}
+
+func (r *WorkerLatencyRecorder) record(job string, took time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	samples, ok := r.jobs[job]
+	if !ok {
+		samples = &latencySamples{durations: make([]time.Duration, 0, r.samples)}
+		r.jobs[job] = samples
+	}
+	samples.calls++
+	if failed {
+		samples.errors++
+	}
+	if len(samples.durations) < r.samples {
+		samples.durations = append(samples.durations, took)
+		return
+	}
+	samples.durations[samples.next] = took
+	samples.next = (samples.next + 1) % r.samples
+}
+
+// WorkerLatency returns the call counts and latency percentiles of each job type, by job name.
+func (r *WorkerLatencyRecorder) WorkerLatency() []entity.WorkerLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	latencies := make([]entity.WorkerLatency, 0, len(r.jobs))
+	for job, samples := range r.jobs {
+		sorted := slices.Clone(samples.durations)
+		slices.Sort(sorted)
+		latencies = append(latencies, entity.WorkerLatency{
+			Job:     job,
+			Calls:   samples.calls,
+			Errors:  samples.errors,
+			Samples: len(sorted),
+			P50MS:   latencyPercentile(sorted, 0.50),
+			P90MS:   latencyPercentile(sorted, 0.90),
+			P99MS:   latencyPercentile(sorted, 0.99),
+		})
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i].Job < latencies[j].Job })
+	return latencies
+}
+
+// latencyPercentile returns the nearest-rank percentile of sorted durations in milliseconds.
+func latencyPercentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return float64(sorted[rank].Microseconds()) / 1000
+}
+
+var _ Worker = (*WorkerLatencyRecorder)(nil)
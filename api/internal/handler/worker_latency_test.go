@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkerLatencyRecorder(t *testing.T) {
+	stub := &routedWorkerStub{}
+	recorder := NewWorkerLatencyRecorder(stub, 100)
+	clock := time.Unix(0, 0)
+	took := time.Duration(0)
+	started := false
+	recorder.now = func() time.Time {
+		if started {
+			clock = clock.Add(took)
+		}
+		started = !started
+		return clock
+	}
+
+	// 150 scrapes taking 1ms to 150ms: the ring keeps the latest 100, 51ms to 150ms.
+	for i := 1; i <= 150; i++ {
+		took = time.Duration(i) * time.Millisecond
+		if _, err := recorder.PostJSON(context.Background(), "/scrape", nil, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	stub.err = errors.New("boom")
+	took = 2 * time.Second
+	if _, err := recorder.PostJSON(context.Background(), "/enrich", nil, ""); err == nil {
+		t.Fatalf("expected the worker error to be returned")
+	}
+
+	latencies := recorder.WorkerLatency()
+	if len(latencies) != 2 || latencies[0].Job != "enrich" || latencies[1].Job != "scrape" {
+		t.Fatalf("unexpected jobs: %+v", latencies)
+	}
+	if enrich := latencies[0]; enrich.Calls != 1 || enrich.Errors != 1 || enrich.P99MS != 2000 {
+		t.Fatalf("unexpected enrich latency: %+v", enrich)
+	}
+	scrape := latencies[1]
+	if scrape.Calls != 150 || scrape.Errors != 0 || scrape.Samples != 100 {
+		t.Fatalf("unexpected scrape counts: %+v", scrape)
+	}
+	if scrape.P50MS != 100 || scrape.P90MS != 140 || scrape.P99MS != 149 {
+		t.Fatalf("unexpected scrape percentiles: %+v", scrape)
+	}
+	if stub.calls != 151 {
+		t.Fatalf("expected every call passed through, got %d", stub.calls)
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+// Worker is a worker deployment the router can send jobs to.
+type Worker interface {
+	WorkerPoster
+	Ping(ctx context.Context) error
+}
+
+// WorkerRoute sends the jobs matching it to Worker. Empty Countries or Jobs match every country or
+// job type.
+type WorkerRoute struct {
+	Name      string
+	Worker    Worker
+	Countries []string
+	Jobs      []string
+}
+
+// matches reports whether the route takes a job of the given type for the given country.
+func (r WorkerRoute) matches(job, country string) bool {
+	if len(r.Jobs) > 0 && !containsFold(r.Jobs, job) {
+		return false
+	}
+	return len(r.Countries) == 0 || containsFold(r.Countries, strings.TrimSpace(country))
+}
+
+// WorkerRouter sends each job to the first healthy worker whose route matches it, falling back to
+// the default worker. A worker that cannot be reached is skipped for the cooldown and the job
+// fails over to the next candidate; a timed-out job does not, since the worker may have taken it.
+type WorkerRouter struct {
+	routes   []WorkerRoute
+	fallback WorkerRoute
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time
+}
+
+// NewWorkerRouter builds a router over the routes, tried in order, and the default worker.
+func NewWorkerRouter(fallback Worker, cooldown time.Duration, routes ...WorkerRoute) *WorkerRouter {
+	return &WorkerRouter{
+		routes:    routes,
+		fallback:  WorkerRoute{Name: "default", Worker: fallback},
+		cooldown:  cooldown,
+		now:       time.Now,
+		downUntil: make(map[string]time.Time),
+	}
+}
+
+// PostJSON posts the payload to the worker routed for it, failing over while workers are
+// unavailable.
+func (r *WorkerRouter) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+	candidates := r.candidates(strings.Trim(path, "/"), payloadCountry(payload))
+	var err error
+	for i, route := range candidates {
+		var data map[string]any
+		data, err = route.Worker.PostJSON(ctx, path, payload, requestID)
+		if err == nil {
+			r.markUp(route.Name)
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if isTimeout(err) {
+			r.markDown(route.Name)
+			return nil, err
+		}
+		if !isWorkerUnavailable(err) {
+			return nil, err
+		}
+		r.markDown(route.Name)
+		if i+1 < len(candidates) {
+			log.Printf("request_id=%s worker %s unavailable, failing over to %s: %v", requestID, route.Name, candidates[i+1].Name, err)
+		}
+	}
+	return nil, err
+}
+
+// Ping checks every worker, recording their health, and fails only when none is healthy.
+func (r *WorkerRouter) Ping(ctx context.Context) error {
+	var errs []error
+	for _, route := range append(append([]WorkerRoute(nil), r.routes...), r.fallback) {
+		if err := route.Worker.Ping(ctx); err != nil {
+			r.markDown(route.Name)
+			errs = append(errs, fmt.Errorf("%s: %w", route.Name, err))
+			continue
+		}
+		r.markUp(route.Name)
+	}
+	if len(errs) == len(r.routes)+1 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// candidates lists the workers to try for a job: matching routes in order, then the default,
+// with workers still cooling down moved to the back so they are tried only as a last resort.
+func (r *WorkerRouter) candidates(job, country string) []WorkerRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	var healthy, down []WorkerRoute
+	for _, route := range append(append([]WorkerRoute(nil), r.routes...), r.fallback) {
+		if route.Name != r.fallback.Name && !route.matches(job, country) {
+			continue
+		}
+		if now.Before(r.downUntil[route.Name]) {
+			down = append(down, route)
+			continue
+		}
+		healthy = append(healthy, route)
+	}
+	return append(healthy, down...)
+}
+
+func (r *WorkerRouter) markDown(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downUntil[name] = r.now().Add(r.cooldown)
+}
+
+func (r *WorkerRouter) markUp(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.downUntil, name)
+}
+
+// payloadCountry returns the country of a scrape or place lookup payload, or "" for other jobs.
+func payloadCountry(payload any) string {
+	switch p := payload.(type) {
+	case dto.WorkerScrapeRequest:
+		return p.Country
+	case *dto.WorkerScrapeRequest:
+		if p != nil {
+			return p.Country
+		}
+	case dto.WorkerPlaceLookupRequest:
+		return p.Country
+	}
+	return ""
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ Worker = (*WorkerRouter)(nil)
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+type routedWorkerStub struct {
+	workerStub
+	calls   int
+	pingErr error
+}
+
+func (s *routedWorkerStub) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+	s.calls++
+	return s.workerStub.PostJSON(ctx, path, payload, requestID)
+}
+
+func (s *routedWorkerStub) Ping(ctx context.Context) error {
+	return s.pingErr
+}
+
+func TestWorkerRouter_RoutesByCountryAndJob(t *testing.T) {
+	eu := &routedWorkerStub{workerStub: workerStub{data: map[string]any{"worker": "eu"}}}
+	fallback := &routedWorkerStub{workerStub: workerStub{data: map[string]any{"worker": "default"}}}
+	router := NewWorkerRouter(fallback, time.Minute, WorkerRoute{Name: "eu", Worker: eu, Countries: []string{"DE", "FR"}, Jobs: []string{"scrape"}})
+	ctx := context.Background()
+
+	cases := []struct {
+		path    string
+		payload any
+		want    string
+	}{
+		{"/scrape", dto.WorkerScrapeRequest{Country: "de"}, "eu"},
+		{"/scrape", &dto.WorkerScrapeRequest{Country: " FR "}, "eu"},
+		{"/scrape", dto.WorkerScrapeRequest{Country: "Indonesia"}, "default"},
+		{"/enrich", dto.WorkerEnrichRequest{CompanyID: "c-1"}, "default"},
+	}
+	for _, tc := range cases {
+		data, err := router.PostJSON(ctx, tc.path, tc.payload, "req-1")
+		if err != nil {
+			t.Fatalf("%s %+v: unexpected error: %v", tc.path, tc.payload, err)
+		}
+		if data["worker"] != tc.want {
+			t.Fatalf("%s %+v: expected worker %s, got %v", tc.path, tc.payload, tc.want, data)
+		}
+	}
+}
+
+func TestWorkerRouter_FailsOverUnavailableWorkers(t *testing.T) {
+	eu := &routedWorkerStub{workerStub: workerStub{err: fmt.Errorf("worker request failed: %w", workerUnavailableError{errors.New("connection refused")})}}
+	fallback := &routedWorkerStub{workerStub: workerStub{data: map[string]any{"worker": "default"}}}
+	router := NewWorkerRouter(fallback, time.Minute, WorkerRoute{Name: "eu", Worker: eu})
+	now := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		data, err := router.PostJSON(ctx, "/scrape", dto.WorkerScrapeRequest{Country: "DE"}, "")
+		if err != nil || data["worker"] != "default" {
+			t.Fatalf("expected failover to the default worker, got %v, %v", data, err)
+		}
+	}
+	// The second job went to the default worker first, trying the cooling-down worker only as a
+	// last resort.
+	if eu.calls != 1 || fallback.calls != 2 {
+		t.Fatalf("unexpected calls eu=%d default=%d", eu.calls, fallback.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	eu.err = nil
+	eu.data = map[string]any{"worker": "eu"}
+	if data, err := router.PostJSON(ctx, "/scrape", dto.WorkerScrapeRequest{Country: "DE"}, ""); err != nil || data["worker"] != "eu" {
+		t.Fatalf("expected the recovered worker, got %v, %v", data, err)
+	}
+
+	// Rejections and timeouts are not retried elsewhere: the worker took the request, or may have.
+	for _, err := range []error{errors.New("worker error: invalid payload"), fmt.Errorf("worker request failed: %w", context.DeadlineExceeded)} {
+		eu.err = err
+		fallback.calls = 0
+		if _, got := router.PostJSON(ctx, "/scrape", dto.WorkerScrapeRequest{}, ""); !errors.Is(got, err) || fallback.calls != 0 {
+			t.Fatalf("expected %v without failover, got %v after %d default calls", err, got, fallback.calls)
+		}
+	}
+}
+
+func TestWorkerRouter_Ping(t *testing.T) {
+	eu := &routedWorkerStub{pingErr: errors.New("worker unreachable")}
+	fallback := &routedWorkerStub{}
+	router := NewWorkerRouter(fallback, time.Minute, WorkerRoute{Name: "eu", Worker: eu})
+
+	if err := router.Ping(context.Background()); err != nil {
+		t.Fatalf("expected a healthy router with one worker up, got %v", err)
+	}
+	if got := router.candidates("scrape", ""); len(got) != 2 || got[0].Name != "default" {
+		t.Fatalf("expected the unreachable worker tried last, got %+v", got)
+	}
+
+	fallback.pingErr = errors.New("worker unhealthy: status 503")
+	if err := router.Ping(context.Background()); err == nil {
+		t.Fatalf("expected an error with every worker down")
+	}
+}
@@ -5,9 +5,14 @@ import "context"
 type workerStub struct {
 	data map[string]any
 	err  error
+
+	path    string
+	payload any
 }
 
 func (s *workerStub) PostJSON(ctx context.Context, path string, payload any, requestID string) (map[string]any, error) {
+	s.path = path
+	s.payload = payload
 	if s.err != nil {
 		return nil, s.err
 	}
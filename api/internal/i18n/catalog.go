@@ -0,0 +1,90 @@
+package i18n
+
+// catalog lists the translated messages. Add a message here when it is shown to end users;
+// admin-only and operational messages may stay English-only.
+var catalog = []Message{
+	// Requests
+	{"invalid_payload", "invalid payload", "payload tidak valid"},
+	{"invalid_json_payload", "invalid JSON payload", "payload JSON tidak valid"},
+	{"invalid_query_parameters", "invalid query parameters", "parameter query tidak valid"},
+	{"query_timed_out", "query timed out; narrow the filters and retry", "query melewati batas waktu; persempit filter lalu coba lagi"},
+	{"unauthorized", "unauthorized", "tidak terautentikasi"},
+	{"too_many_requests", "too many requests", "terlalu banyak permintaan"},
+	{"temporarily_read_only", "temporarily read-only", "sementara hanya bisa dibaca"},
+	{"captcha_required", "captcha token is required", "token captcha wajib diisi"},
+	{"captcha_failed", "captcha verification failed", "verifikasi captcha gagal"},
+
+	// Accounts
+	{"login_successful", "login successful", "berhasil masuk"},
+	{"registration_successful", "registration successful", "pendaftaran berhasil"},
+	{"invalid_credentials", "invalid credentials", "email atau kata sandi salah"},
+	{"email_password_required", "email and password are required", "email dan kata sandi wajib diisi"},
+	{"email_exists", "email already exists", "email sudah terdaftar"},
+	{"unable_to_register", "unable to register user", "tidak dapat mendaftarkan pengguna"},
+	{"unable_to_authenticate", "unable to authenticate", "tidak dapat melakukan autentikasi"},
+	{"tokens_refreshed", "tokens refreshed", "token diperbarui"},
+	{"unable_to_refresh_tokens", "unable to refresh tokens", "tidak dapat memperbarui token"},
+	{"refresh_tokens_disabled", "refresh tokens are not enabled", "refresh token tidak diaktifkan"},
+	{"token_password_required", "token and password are required", "token dan kata sandi wajib diisi"},
+	{"invitation_accepted", "invitation accepted", "undangan diterima"},
+	{"invitation_invalid", "invitation is invalid or has expired", "undangan tidak valid atau sudah kedaluwarsa"},
+	{"unable_to_accept_invitation", "unable to accept invitation", "tidak dapat menerima undangan"},
+	{"unable_to_verify_email", "unable to verify email", "tidak dapat memverifikasi email"},
+	{"email_updated", "email updated", "email diperbarui"},
+	{"profile_retrieved", "profile retrieved", "profil berhasil diambil"},
+	{"failed_to_load_profile", "failed to load profile", "gagal memuat profil"},
+	{"failed_to_update_profile", "failed to update profile", "gagal memperbarui profil"},
+	{"sessions_retrieved", "sessions retrieved", "daftar sesi berhasil diambil"},
+	{"session_revoked", "session revoked", "sesi dicabut"},
+	{"session_not_found", "session not found", "sesi tidak ditemukan"},
+	{"failed_to_list_sessions", "failed to list sessions", "gagal mengambil daftar sesi"},
+	{"failed_to_revoke_session", "failed to revoke session", "gagal mencabut sesi"},
+	{"user_not_found", "user not found", "pengguna tidak ditemukan"},
+
+	// Companies
+	{"companies_retrieved", "companies retrieved", "data perusahaan berhasil diambil"},
+	{"failed_to_list_companies", "failed to list companies", "gagal mengambil data perusahaan"},
+	{"company_not_found", "company not found", "perusahaan tidak ditemukan"},
+	{"invalid_company_id", "invalid company id", "id perusahaan tidak valid"},
+	{"invalid_company_id_param", "invalid company_id", "company_id tidak valid"},
+	{"company_id_required", "company_id is required", "company_id wajib diisi"},
+	{"no_website_leads_retrieved", "no-website leads retrieved", "leads tanpa website berhasil diambil"},
+	{"failed_to_list_no_website_leads", "failed to list no-website leads", "gagal mengambil leads tanpa website"},
+	{"trending_companies_retrieved", "trending companies retrieved", "perusahaan yang sedang tren berhasil diambil"},
+	{"failed_to_list_trending_companies", "failed to list trending companies", "gagal mengambil perusahaan yang sedang tren"},
+	{"order_invalid", "order must be asc or desc", "order harus asc atau desc"},
+	{"invalid_days", "invalid days (use a positive integer)", "days tidak valid (gunakan bilangan bulat positif)"},
+	{"invalid_since", "invalid since (use RFC3339)", "since tidak valid (gunakan format RFC3339)"},
+	{"stats_retrieved", "stats retrieved", "statistik berhasil diambil"},
+	{"failed_to_compute_stats", "failed to compute stats", "gagal menghitung statistik"},
+	{"freshness_retrieved", "freshness retrieved", "data kebaruan berhasil diambil"},
+	{"failed_to_prepare_export", "failed to prepare export", "gagal menyiapkan ekspor"},
+	{"exports_disabled", "exports are not enabled", "ekspor tidak diaktifkan"},
+
+	// Scraping and enrichment
+	{"scrape_job_queued", "scrape job queued", "pekerjaan scrape masuk antrean"},
+	{"scrape_job_already_queued", "scrape job already queued", "pekerjaan scrape sudah ada di antrean"},
+	{"scrape_jobs_retrieved", "scrape jobs retrieved", "daftar pekerjaan scrape berhasil diambil"},
+	{"failed_to_list_scrape_jobs", "failed to list scrape jobs", "gagal mengambil daftar pekerjaan scrape"},
+	{"failed_to_record_scrape_job", "failed to record scrape job", "gagal mencatat pekerjaan scrape"},
+	{"scrape_preview_retrieved", "scrape preview retrieved", "pratinjau scrape berhasil diambil"},
+	{"failed_to_preview_scrape", "failed to preview scrape", "gagal membuat pratinjau scrape"},
+	{"prompt_required", "prompt is required", "prompt wajib diisi"},
+	{"prompt_not_recognized", "prompt not recognized; use a sentence like 'cari PT di Jakarta' to search for contact data", "prompt tidak dikenali. Gunakan kalimat seperti 'cari PT di Jakarta' untuk mencari data kontak"},
+	{"prompt_job_queued", "prompt job queued", "pekerjaan prompt masuk antrean"},
+	{"enrichment_job_queued", "enrichment job queued", "pekerjaan pengayaan data masuk antrean"},
+	{"enrichment_cached", "enrichment served from domain cache", "data pengayaan diambil dari cache domain"},
+	{"enrichment_quota_exceeded", "enrichment quota exceeded", "kuota pengayaan data habis"},
+	{"enrichment_not_found", "enrichment not found", "data pengayaan tidak ditemukan"},
+	{"company_website_required", "company_id and website are required", "company_id dan website wajib diisi"},
+	{"worker_timeout", "worker did not answer in time", "worker tidak merespons tepat waktu"},
+	{"worker_unavailable", "worker unavailable", "worker tidak tersedia"},
+	{"worker_error", "worker refused the job", "worker menolak pekerjaan"},
+
+	// Uploads and imports
+	{"missing_csv_file", "missing csv file", "file csv tidak ada"},
+	{"unable_to_open_file", "unable to open file", "tidak dapat membuka file"},
+	{"import_queued", "import queued", "impor masuk antrean"},
+	{"import_queue_full", "import queue is full, try again later", "antrean impor penuh, coba lagi nanti"},
+	{"import_not_found", "import not found", "impor tidak ditemukan"},
+}
@@ -0,0 +1,71 @@
+// Package i18n localizes the messages of API responses. Messages are written in English in the
+// code; the catalog gives each one a stable code and its translations.
+package i18n
+
+import (
+	"golang.org/x/text/language"
+)
+
+// Supported languages; English is the default.
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+
+// Negotiate picks the supported language that best matches an Accept-Language header, falling
+// back to English.
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return English
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No || index != 1 {
+		return English
+	}
+	return Indonesian
+}
+
+// Message is a catalog entry.
+type Message struct {
+	Code string
+	EN   string
+	ID   string
+}
+
+func (m Message) text(lang string) string {
+	if lang == Indonesian && m.ID != "" {
+		return m.ID
+	}
+	return m.EN
+}
+
+var (
+	byCode    = make(map[string]Message, len(catalog))
+	byMessage = make(map[string]Message, len(catalog))
+)
+
+func init() {
+	for _, m := range catalog {
+		byCode[m.Code] = m
+		byMessage[m.EN] = m
+	}
+}
+
+// Translate returns the code and the text in lang of an English message. A message missing from
+// the catalog keeps its text; when code names a catalog entry, its translation is used instead in
+// other languages, so detailed English messages still read in the caller's language.
+func Translate(code, message, lang string) (string, string) {
+	if m, ok := byMessage[message]; ok {
+		if code == "" {
+			code = m.Code
+		}
+		return code, m.text(lang)
+	}
+	if m, ok := byCode[code]; ok && lang != English {
+		return code, m.text(lang)
+	}
+	return code, message
+}
@@ -0,0 +1,49 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                          English,
+		"id":                        Indonesian,
+		"id-ID,id;q=0.9,en;q=0.8":   Indonesian,
+		"en-US,en;q=0.9,id;q=0.8":   English,
+		"fr-FR,id;q=0.5":            Indonesian,
+		"de-DE":                     English,
+		"not a language header;;q=": English,
+	}
+	for header, want := range cases {
+		if got := Negotiate(header); got != want {
+			t.Fatalf("Negotiate(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if code, text := Translate("", "invalid payload", Indonesian); code != "invalid_payload" || text != "payload tidak valid" {
+		t.Fatalf("unexpected translation %s %q", code, text)
+	}
+	if code, text := Translate("", "invalid payload", English); code != "invalid_payload" || text != "invalid payload" {
+		t.Fatalf("unexpected translation %s %q", code, text)
+	}
+	if code, text := Translate("", "failed to list feature toggles", Indonesian); code != "" || text != "failed to list feature toggles" {
+		t.Fatalf("expected a message missing from the catalog to stay as is, got %s %q", code, text)
+	}
+	if code, text := Translate("custom", "company not found", Indonesian); code != "custom" || text != "perusahaan tidak ditemukan" {
+		t.Fatalf("expected an explicit code to be kept, got %s %q", code, text)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	codes := map[string]bool{}
+	messages := map[string]bool{}
+	for _, m := range catalog {
+		if m.Code == "" || m.EN == "" || m.ID == "" {
+			t.Fatalf("incomplete catalog entry %+v", m)
+		}
+		if codes[m.Code] || messages[m.EN] {
+			t.Fatalf("duplicate catalog entry %+v", m)
+		}
+		codes[m.Code], messages[m.EN] = true, true
+	}
+}
@@ -0,0 +1,178 @@
+package mailer
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Delivery events reported by provider webhooks; other provider events are dropped.
+const (
+	EventOpen        = "open"
+	EventBounce      = "bounce"
+	EventComplaint   = "complaint"
+	EventUnsubscribe = "unsubscribe"
+)
+
+// ErrInvalidSignature is returned when a webhook request is not signed by the provider.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event is a delivery event of a message. Tags are the tags the message was sent with.
+type Event struct {
+	Type  string
+	Email string
+	Tags  map[string]string
+}
+
+// EventSource verifies and parses the delivery webhook requests of a provider. Providers retry
+// deliveries, so the same event may be parsed more than once.
+type EventSource interface {
+	ParseEvents(header http.Header, body []byte) ([]Event, error)
+}
+
+// SendGrid signed event webhook headers.
+const (
+	sendGridSignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	sendGridTimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+// SendGridWebhook reads the SendGrid signed event webhook.
+type SendGridWebhook struct {
+	key *ecdsa.PublicKey
+}
+
+// NewSendGridWebhook builds a webhook reader verifying signatures with the base64 verification key
+// shown in the SendGrid mail settings.
+func NewSendGridWebhook(publicKey string) (*SendGridWebhook, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode sendgrid verification key: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse sendgrid verification key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("sendgrid verification key is not an ECDSA key")
+	}
+	return &SendGridWebhook{key: key}, nil
+}
+
+// sendGridEvents maps SendGrid event names to delivery events.
+var sendGridEvents = map[string]string{
+	"open":              EventOpen,
+	"bounce":            EventBounce,
+	"dropped":           EventBounce,
+	"spamreport":        EventComplaint,
+	"unsubscribe":       EventUnsubscribe,
+	"group_unsubscribe": EventUnsubscribe,
+}
+
+// ParseEvents verifies the ECDSA signature over the timestamp and body, then returns the events
+// of the batch. SendGrid mixes custom args into the event, so every string field becomes a tag.
+// Blocked messages are temporary failures and are not reported as bounces.
+func (w *SendGridWebhook) ParseEvents(header http.Header, body []byte) ([]Event, error) {
+	signature, err := base64.StdEncoding.DecodeString(header.Get(sendGridSignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	digest := sha256.Sum256(append([]byte(header.Get(sendGridTimestampHeader)), body...))
+	if !ecdsa.VerifyASN1(w.key, digest[:], signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var raw []map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("decode sendgrid events: %w", err)
+	}
+	events := make([]Event, 0, len(raw))
+	for _, fields := range raw {
+		name, _ := fields["event"].(string)
+		kind, ok := sendGridEvents[name]
+		if !ok {
+			continue
+		}
+		if bounceType, _ := fields["type"].(string); name == "bounce" && bounceType == "blocked" {
+			continue
+		}
+		event := Event{Type: kind, Tags: make(map[string]string)}
+		for key, value := range fields {
+			if text, ok := value.(string); ok {
+				event.Tags[key] = text
+			}
+		}
+		event.Email, _ = fields["email"].(string)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// MailgunWebhook reads Mailgun webhooks.
+type MailgunWebhook struct {
+	signingKey []byte
+}
+
+// NewMailgunWebhook builds a webhook reader verifying signatures with the HTTP webhook signing key
+// of the Mailgun account.
+func NewMailgunWebhook(signingKey string) *MailgunWebhook {
+	return &MailgunWebhook{signingKey: []byte(signingKey)}
+}
+
+type mailgunPayload struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event         string         `json:"event"`
+		Severity      string         `json:"severity"`
+		Recipient     string         `json:"recipient"`
+		UserVariables map[string]any `json:"user-variables"`
+	} `json:"event-data"`
+}
+
+// ParseEvents verifies the HMAC of the timestamp and token carried in the body, then returns its
+// single event. Only permanent failures are reported as bounces.
+func (w *MailgunWebhook) ParseEvents(header http.Header, body []byte) ([]Event, error) {
+	var payload mailgunPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode mailgun event: %w", err)
+	}
+	mac := hmac.New(sha256.New, w.signingKey)
+	mac.Write([]byte(payload.Signature.Timestamp + payload.Signature.Token))
+	signature, err := hex.DecodeString(payload.Signature.Signature)
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var kind string
+	switch data := payload.EventData; {
+	case data.Event == "opened":
+		kind = EventOpen
+	case data.Event == "failed" && data.Severity == "permanent":
+		kind = EventBounce
+	case data.Event == "complained":
+		kind = EventComplaint
+	case data.Event == "unsubscribed":
+		kind = EventUnsubscribe
+	default:
+		return nil, nil
+	}
+	event := Event{Type: kind, Email: payload.EventData.Recipient, Tags: make(map[string]string)}
+	for key, value := range payload.EventData.UserVariables {
+		if text, ok := value.(string); ok {
+			event.Tags[key] = text
+		}
+	}
+	return []Event{event}, nil
+}
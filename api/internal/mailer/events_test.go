@@ -0,0 +1,99 @@
+package mailer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSendGridWebhook_ParseEvents(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	webhook, err := NewSendGridWebhook(base64.StdEncoding.EncodeToString(der))
+	if err != nil {
+		t.Fatalf("new webhook: %v", err)
+	}
+
+	body := []byte(`[
+		{"email":"a@example.com","event":"open","campaign_token":"t1"},
+		{"email":"b@example.com","event":"bounce","type":"bounce","campaign_token":"t2"},
+		{"email":"c@example.com","event":"bounce","type":"blocked","campaign_token":"t3"},
+		{"email":"d@example.com","event":"spamreport"},
+		{"email":"e@example.com","event":"delivered","campaign_token":"t5"}
+	]`)
+	timestamp := "1735689600"
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	header := http.Header{}
+	header.Set(sendGridTimestampHeader, timestamp)
+	header.Set(sendGridSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+
+	events, err := webhook.ParseEvents(header, body)
+	if err != nil {
+		t.Fatalf("parse events: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected open, bounce and complaint events, got %+v", events)
+	}
+	if events[0].Type != EventOpen || events[0].Tags["campaign_token"] != "t1" {
+		t.Fatalf("unexpected open event %+v", events[0])
+	}
+	if events[1].Type != EventBounce || events[1].Email != "b@example.com" || events[2].Type != EventComplaint {
+		t.Fatalf("unexpected events %+v", events[1:])
+	}
+
+	if _, err := webhook.ParseEvents(header, append(body, ' ')); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected a tampered body to be refused, got %v", err)
+	}
+}
+
+func TestMailgunWebhook_ParseEvents(t *testing.T) {
+	webhook := NewMailgunWebhook("signing-key")
+	sign := func(timestamp, token string) string {
+		mac := hmac.New(sha256.New, []byte("signing-key"))
+		mac.Write([]byte(timestamp + token))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	body := []byte(`{
+		"signature":{"timestamp":"1735689600","token":"abc","signature":"` + sign("1735689600", "abc") + `"},
+		"event-data":{"event":"failed","severity":"permanent","recipient":"lead@example.com","user-variables":{"campaign_token":"tok","attempt":2}}
+	}`)
+	events, err := webhook.ParseEvents(http.Header{}, body)
+	if err != nil {
+		t.Fatalf("parse events: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventBounce || events[0].Tags["campaign_token"] != "tok" || events[0].Email != "lead@example.com" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	temporary := []byte(`{
+		"signature":{"timestamp":"1735689600","token":"abc","signature":"` + sign("1735689600", "abc") + `"},
+		"event-data":{"event":"failed","severity":"temporary","recipient":"lead@example.com"}
+	}`)
+	if events, err := webhook.ParseEvents(http.Header{}, temporary); err != nil || len(events) != 0 {
+		t.Fatalf("expected temporary failures dropped, got %+v (%v)", events, err)
+	}
+
+	forged := []byte(`{"signature":{"timestamp":"1735689600","token":"abc","signature":"00"},"event-data":{"event":"complained"}}`)
+	if _, err := webhook.ParseEvents(http.Header{}, forged); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected a forged signature to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+// Package mailer sends e-mail through an SMTP relay or the SendGrid and Mailgun APIs, and reads the
+// delivery events those providers report back.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sender delivers a plain text message to one recipient.
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Message is an outreach e-mail with a plain text part and an optional HTML part.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers such as List-Unsubscribe.
+	Headers map[string]string
+	// Tags are echoed back by the delivery webhooks of SendGrid and Mailgun; SMTP drops them.
+	Tags map[string]string
+	// Attachments are sent as files alongside the text.
+	Attachments []Attachment
+}
+
+// Attachment is a file sent with a message. An empty ContentType sends application/octet-stream.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// contentType returns the media type of the attachment.
+func (a Attachment) contentType() string {
+	if a.ContentType == "" {
+		return "application/octet-stream"
+	}
+	return a.ContentType
+}
+
+// MessageSender delivers outreach messages and returns the message id assigned to them, if any.
+type MessageSender interface {
+	SendMessage(ctx context.Context, msg Message) (string, error)
+}
+
+// errHeaderBreak is returned for header values that would inject extra headers.
+var errHeaderBreak = errors.New("mail headers must not contain line breaks")
+
+// checkHeaders refuses header names and values holding line breaks.
+func checkHeaders(values ...string) error {
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return errHeaderBreak
+		}
+	}
+	return nil
+}
+
+// checkMessage refuses a message whose sender, recipient, subject, extra headers or attachment
+// names hold line breaks.
+func checkMessage(from string, msg Message) error {
+	if err := checkHeaders(from, msg.To, msg.Subject); err != nil {
+		return err
+	}
+	for name, value := range msg.Headers {
+		if err := checkHeaders(name, value); err != nil {
+			return err
+		}
+	}
+	for _, attachment := range msg.Attachments {
+		if err := checkHeaders(attachment.Filename, attachment.ContentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in order, so rendered messages and requests are stable.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SMTPSender sends mail through an SMTP relay, authenticating with PLAIN when a username is set.
+// Relays on port 587 are expected to offer STARTTLS, which net/smtp uses when available.
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	send     func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+}
+
+// NewSMTPSender builds a sender for the relay at host:port.
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+		send:     smtp.SendMail,
+		now:      time.Now,
+	}
+}
+
+// Send delivers the message. net/smtp has no context support, so ctx is only checked up front.
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg, err := s.message(to, subject, body)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	if err := s.send(s.addr, auth, s.from, []string{to}, msg); err != nil {
+		return fmt.Errorf("send mail to %s: %w", to, err)
+	}
+	return nil
+}
+
+// message renders an RFC 5322 message, refusing header values that would inject extra headers.
+func (s *SMTPSender) message(to, subject, body string) ([]byte, error) {
+	if err := checkHeaders(s.from, to, subject); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	s.writeHeaders(&b, to, subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(crlf(body))
+	return []byte(b.String()), nil
+}
+
+// writeHeaders writes the From, To, Subject and Date headers; non-ASCII subjects are Q-encoded.
+func (s *SMTPSender) writeHeaders(b *strings.Builder, to, subject string) {
+	fmt.Fprintf(b, "From: %s\r\n", s.from)
+	fmt.Fprintf(b, "To: %s\r\n", to)
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(b, "Date: %s\r\n", s.now().UTC().Format(time.RFC1123Z))
+}
+
+// SendMessage delivers an outreach message as multipart/alternative when it has an HTML part, and
+// as multipart/mixed when it has attachments, and returns the Message-ID it was given.
+func (s *SMTPSender) SendMessage(ctx context.Context, msg Message) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	raw, messageID, err := s.richMessage(msg)
+	if err != nil {
+		return "", err
+	}
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	if err := s.send(s.addr, auth, s.from, []string{msg.To}, raw); err != nil {
+		return "", fmt.Errorf("send mail to %s: %w", msg.To, err)
+	}
+	return messageID, nil
+}
+
+// richMessage renders msg with quoted-printable parts and a generated Message-ID.
+func (s *SMTPSender) richMessage(msg Message) ([]byte, string, error) {
+	if err := checkMessage(s.from, msg); err != nil {
+		return nil, "", err
+	}
+	messageID, err := s.messageID()
+	if err != nil {
+		return nil, "", err
+	}
+	entity, err := textEntity(msg)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(msg.Attachments) > 0 {
+		if entity, err = mixedEntity(entity, msg.Attachments); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var b strings.Builder
+	s.writeHeaders(&b, msg.To, msg.Subject)
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", messageID)
+	for _, name := range sortedKeys(msg.Headers) {
+		fmt.Fprintf(&b, "%s: %s\r\n", textproto.CanonicalMIMEHeaderKey(name), msg.Headers[name])
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: %s\r\n", entity.contentType)
+	if entity.encoding != "" {
+		fmt.Fprintf(&b, "Content-Transfer-Encoding: %s\r\n", entity.encoding)
+	}
+	b.WriteString("\r\n")
+	b.Write(entity.body)
+	return []byte(b.String()), messageID, nil
+}
+
+// mimeEntity is a rendered MIME entity: its content type, transfer encoding (empty for multipart
+// entities) and body.
+type mimeEntity struct {
+	contentType string
+	encoding    string
+	body        []byte
+}
+
+func (e mimeEntity) header() textproto.MIMEHeader {
+	header := textproto.MIMEHeader{"Content-Type": {e.contentType}}
+	if e.encoding != "" {
+		header.Set("Content-Transfer-Encoding", e.encoding)
+	}
+	return header
+}
+
+// textEntity renders the text of msg, with its HTML part as multipart/alternative when it has one.
+func textEntity(msg Message) (mimeEntity, error) {
+	if msg.HTML == "" {
+		text, err := quotedPrintable(msg.Text)
+		if err != nil {
+			return mimeEntity{}, err
+		}
+		return mimeEntity{contentType: "text/plain; charset=UTF-8", encoding: "quoted-printable", body: text}, nil
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return mimeEntity{}, err
+		}
+		encoded, err := quotedPrintable(part.content)
+		if err != nil {
+			return mimeEntity{}, err
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return mimeEntity{}, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return mimeEntity{}, err
+	}
+	return mimeEntity{contentType: fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()), body: body.Bytes()}, nil
+}
+
+// mixedEntity wraps text and the base64 encoded attachments in a multipart/mixed entity.
+func mixedEntity(text mimeEntity, attachments []Attachment) (mimeEntity, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	w, err := parts.CreatePart(text.header())
+	if err != nil {
+		return mimeEntity{}, err
+	}
+	if _, err := w.Write(text.body); err != nil {
+		return mimeEntity{}, err
+	}
+	for _, attachment := range attachments {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachment.contentType(), map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return mimeEntity{}, err
+		}
+		if _, err := w.Write(base64Lines(attachment.Content)); err != nil {
+			return mimeEntity{}, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return mimeEntity{}, err
+	}
+	return mimeEntity{contentType: fmt.Sprintf("multipart/mixed; boundary=%q", parts.Boundary()), body: body.Bytes()}, nil
+}
+
+// base64Lines encodes content as base64 in CRLF terminated lines of 76 characters, as RFC 2045
+// requires.
+func base64Lines(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	var b bytes.Buffer
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// messageID returns a random Message-ID on the domain of the sender address.
+func (s *SMTPSender) messageID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate message id: %w", err)
+	}
+	domain := s.host
+	if addr, err := mail.ParseAddress(s.from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	return "<" + hex.EncodeToString(buf) + "@" + domain + ">", nil
+}
+
+// crlf normalises line endings to CRLF.
+func crlf(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// quotedPrintable encodes text with CRLF line endings as quoted-printable.
+func quotedPrintable(text string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(crlf(text))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package mailer
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMTPSender_Send(t *testing.T) {
+	sender := NewSMTPSender("smtp.example.com", 587, "apikey", "secret", "noreply@example.com")
+	sender.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	var (
+		gotAddr string
+		gotAuth smtp.Auth
+		gotTo   []string
+		gotMsg  string
+	)
+	sender.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotTo, gotMsg = addr, a, to, string(msg)
+		return nil
+	}
+
+	if err := sender.Send(context.Background(), "user@example.com", "Confirm your email", "Hello\nBye"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAddr != "smtp.example.com:587" || gotAuth == nil || len(gotTo) != 1 || gotTo[0] != "user@example.com" {
+		t.Fatalf("unexpected envelope %s %v %v", gotAddr, gotAuth, gotTo)
+	}
+	for _, want := range []string{"From: noreply@example.com\r\n", "Subject: Confirm your email\r\n", "Date: Thu, 02 Jan 2025 03:04:05 +0000\r\n", "\r\n\r\nHello\r\nBye"} {
+		if !strings.Contains(gotMsg, want) {
+			t.Fatalf("expected %q in message:\n%s", want, gotMsg)
+		}
+	}
+
+	if err := sender.Send(context.Background(), "user@example.com\r\nBcc: victim@example.com", "hi", "body"); err == nil {
+		t.Fatal("expected header injection to be rejected")
+	}
+}
+
+func TestSMTPSender_SendMessage(t *testing.T) {
+	sender := NewSMTPSender("smtp.example.com", 587, "", "", "Sales <sales@example.com>")
+	var gotMsg string
+	sender.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotMsg = string(msg)
+		return nil
+	}
+
+	messageID, err := sender.SendMessage(context.Background(), Message{
+		To:      "lead@example.com",
+		Subject: "Halo Kopi Kenangan",
+		Text:    "Hello\nthere",
+		HTML:    "<p>Hello</p>",
+		Headers: map[string]string{"list-unsubscribe": "<https://api.example.com/campaigns/unsubscribe/t>"},
+		Tags:    map[string]string{"campaign_token": "t"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(messageID, "@example.com>") {
+		t.Fatalf("expected a message id on the sender domain, got %q", messageID)
+	}
+	for _, want := range []string{
+		"Message-ID: " + messageID + "\r\n",
+		"List-Unsubscribe: <https://api.example.com/campaigns/unsubscribe/t>\r\n",
+		"Content-Type: multipart/alternative; boundary=",
+		"Content-Type: text/html; charset=UTF-8",
+		"Hello\r\nthere",
+	} {
+		if !strings.Contains(gotMsg, want) {
+			t.Fatalf("expected %q in message:\n%s", want, gotMsg)
+		}
+	}
+	if strings.Contains(gotMsg, "campaign_token") {
+		t.Fatalf("expected tags to stay out of SMTP messages:\n%s", gotMsg)
+	}
+
+	_, err = sender.SendMessage(context.Background(), Message{To: "lead@example.com", Subject: "hi", Headers: map[string]string{"X-Note": "a\r\nBcc: victim@example.com"}})
+	if err == nil {
+		t.Fatal("expected header injection through extra headers to be rejected")
+	}
+}
+
+func TestSMTPSender_SendMessageWithAttachments(t *testing.T) {
+	sender := NewSMTPSender("smtp.example.com", 587, "", "", "exports@example.com")
+	var gotMsg string
+	sender.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotMsg = string(msg)
+		return nil
+	}
+
+	_, err := sender.SendMessage(context.Background(), Message{
+		To:          "ops@example.com",
+		Subject:     "Weekly leads",
+		Text:        "Attached.",
+		Attachments: []Attachment{{Filename: "companies-20261015.csv", ContentType: "text/csv", Content: []byte("id,company\n1,Acme\n")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Content-Type: multipart/mixed; boundary=",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Disposition: attachment; filename=companies-20261015.csv",
+		"Content-Transfer-Encoding: base64",
+		"aWQsY29tcGFueQoxLEFjbWUK",
+	} {
+		if !strings.Contains(gotMsg, want) {
+			t.Fatalf("expected %q in message:\n%s", want, gotMsg)
+		}
+	}
+
+	_, err = sender.SendMessage(context.Background(), Message{To: "ops@example.com", Subject: "hi", Attachments: []Attachment{{Filename: "a.csv\r\nBcc: victim@example.com"}}})
+	if err == nil {
+		t.Fatal("expected header injection through attachment names to be rejected")
+	}
+}
@@ -0,0 +1,140 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MailgunEndpoint is the Mailgun API of the US region; EU domains use https://api.eu.mailgun.net.
+const MailgunEndpoint = "https://api.mailgun.net"
+
+// MailgunSender sends outreach messages through the Mailgun messages API of one sending domain.
+type MailgunSender struct {
+	baseURL string
+	domain  string
+	apiKey  string
+	from    string
+	client  *http.Client
+}
+
+// NewMailgunSender builds a sender for domain on the API at baseURL, MailgunEndpoint when empty;
+// a nil client uses one with a timeout so a slow provider cannot stall the send loop.
+func NewMailgunSender(baseURL, domain, apiKey, from string, client *http.Client) *MailgunSender {
+	if baseURL == "" {
+		baseURL = MailgunEndpoint
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &MailgunSender{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		domain:  domain,
+		apiKey:  apiKey,
+		from:    from,
+		client:  client,
+	}
+}
+
+// SendMessage posts msg to Mailgun, sending its headers as h: fields and its tags as v: user
+// variables, and returns the message id Mailgun answers with. Messages with attachments are posted
+// as multipart/form-data, the others as a URL-encoded form.
+func (s *MailgunSender) SendMessage(ctx context.Context, msg Message) (string, error) {
+	if err := checkMessage(s.from, msg); err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"from":    {s.from},
+		"to":      {msg.To},
+		"subject": {msg.Subject},
+		"text":    {msg.Text},
+	}
+	if msg.HTML != "" {
+		form.Set("html", msg.HTML)
+	}
+	for name, value := range msg.Headers {
+		form.Set("h:"+name, value)
+	}
+	for name, value := range msg.Tags {
+		form.Set("v:"+name, value)
+	}
+
+	var body io.Reader = strings.NewReader(form.Encode())
+	contentType := "application/x-www-form-urlencoded"
+	if len(msg.Attachments) > 0 {
+		multipartBody, boundary, err := mailgunMultipart(form, msg.Attachments)
+		if err != nil {
+			return "", fmt.Errorf("encode mailgun message: %w", err)
+		}
+		body, contentType = multipartBody, "multipart/form-data; boundary="+boundary
+	}
+
+	endpoint := s.baseURL + "/v3/" + url.PathEscape(s.domain) + "/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("build mailgun request: %w", err)
+	}
+	req.SetBasicAuth("api", s.apiKey)
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send mail to %s: %w", msg.To, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", providerError("mailgun", resp)
+	}
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode mailgun response: %w", err)
+	}
+	return result.ID, nil
+}
+
+// mailgunMultipart encodes form and attachments as multipart/form-data, attachments as attachment
+// files, and returns the body with its boundary.
+func mailgunMultipart(form url.Values, attachments []Attachment) (*bytes.Buffer, string, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range form[name] {
+			if err := parts.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for _, attachment := range attachments {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {mime.FormatMediaType("form-data", map[string]string{"name": "attachment", "filename": attachment.Filename})},
+			"Content-Type":        {attachment.contentType()},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := w.Write(attachment.Content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, parts.Boundary(), nil
+}
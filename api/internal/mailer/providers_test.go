@@ -0,0 +1,111 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendGridSender_SendMessage(t *testing.T) {
+	var got sendGridMail
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sg-key" {
+			t.Fatalf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("X-Message-Id", "sg-123")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := NewSendGridSender("sg-key", "Sales <sales@example.com>", server.Client())
+	sender.endpoint = server.URL
+	messageID, err := sender.SendMessage(context.Background(), Message{
+		To: "lead@example.com", Subject: "Hi", Text: "text", HTML: "<p>html</p>",
+		Tags:        map[string]string{"campaign_token": "tok"},
+		Attachments: []Attachment{{Filename: "leads.csv", ContentType: "text/csv", Content: []byte("id\n")}},
+	})
+	if err != nil || messageID != "sg-123" {
+		t.Fatalf("expected message id sg-123, got %q (%v)", messageID, err)
+	}
+	if got.From.Email != "sales@example.com" || got.From.Name != "Sales" || len(got.Content) != 2 {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	if got.Personalizations[0].CustomArgs["campaign_token"] != "tok" {
+		t.Fatalf("expected the tags sent as custom args, got %+v", got.Personalizations)
+	}
+	if len(got.Attachments) != 1 || got.Attachments[0].Content != "aWQK" || got.Attachments[0].Filename != "leads.csv" {
+		t.Fatalf("expected the attachment base64 encoded, got %+v", got.Attachments)
+	}
+}
+
+func TestSendGridSender_ProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"message":"The from address does not match a verified Sender Identity"}]}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender := NewSendGridSender("sg-key", "sales@example.com", server.Client())
+	sender.endpoint = server.URL
+	_, err := sender.SendMessage(context.Background(), Message{To: "lead@example.com", Subject: "Hi", Text: "text"})
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "verified Sender Identity") {
+		t.Fatalf("expected the provider error, got %v", err)
+	}
+}
+
+func TestMailgunSender_SendMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/mg.example.com/messages" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if user, key, ok := r.BasicAuth(); !ok || user != "api" || key != "mg-key" {
+			t.Fatalf("unexpected basic auth %q %q", user, key)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.Form.Get("v:campaign_token") != "tok" || r.Form.Get("h:List-Unsubscribe") != "<https://u>" || r.Form.Get("html") != "<p>html</p>" {
+			t.Fatalf("unexpected form %v", r.Form)
+		}
+		_, _ = w.Write([]byte(`{"id":"<20250101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	sender := NewMailgunSender(server.URL+"/", "mg.example.com", "mg-key", "sales@example.com", server.Client())
+	messageID, err := sender.SendMessage(context.Background(), Message{
+		To: "lead@example.com", Subject: "Hi", Text: "text", HTML: "<p>html</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://u>"},
+		Tags:    map[string]string{"campaign_token": "tok"},
+	})
+	if err != nil || messageID != "<20250101.1@mg.example.com>" {
+		t.Fatalf("expected the mailgun message id, got %q (%v)", messageID, err)
+	}
+}
+
+func TestMailgunSender_SendMessageWithAttachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("expected a multipart form: %v", err)
+		}
+		files := r.MultipartForm.File["attachment"]
+		if r.FormValue("to") != "ops@example.com" || len(files) != 1 || files[0].Filename != "leads.csv" {
+			t.Fatalf("unexpected form %v %v", r.MultipartForm.Value, r.MultipartForm.File)
+		}
+		_, _ = w.Write([]byte(`{"id":"<1@mg.example.com>"}`))
+	}))
+	defer server.Close()
+
+	sender := NewMailgunSender(server.URL, "mg.example.com", "mg-key", "exports@example.com", server.Client())
+	_, err := sender.SendMessage(context.Background(), Message{
+		To: "ops@example.com", Subject: "Weekly leads", Text: "Attached.",
+		Attachments: []Attachment{{Filename: "leads.csv", ContentType: "text/csv", Content: []byte("id\n")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
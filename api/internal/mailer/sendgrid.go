@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"time"
+)
+
+// SendGridEndpoint is the SendGrid v3 mail send API.
+const SendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender sends outreach messages through the SendGrid v3 API.
+type SendGridSender struct {
+	endpoint string
+	apiKey   string
+	from     string
+	client   *http.Client
+}
+
+// NewSendGridSender builds a sender authenticating with apiKey; a nil client uses one with a
+// timeout so a slow provider cannot stall the send loop.
+func NewSendGridSender(apiKey, from string, client *http.Client) *SendGridSender {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &SendGridSender{endpoint: SendGridEndpoint, apiKey: apiKey, from: from, client: client}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+type sendGridPersonalization struct {
+	To         []sendGridAddress `json:"to"`
+	CustomArgs map[string]string `json:"custom_args,omitempty"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+// SendMessage posts msg to SendGrid, sending its tags as custom args, and returns the X-Message-Id
+// SendGrid answers with.
+func (s *SendGridSender) SendMessage(ctx context.Context, msg Message) (string, error) {
+	if err := checkMessage(s.from, msg); err != nil {
+		return "", err
+	}
+	payload := sendGridMail{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}, CustomArgs: msg.Tags}},
+		From:             sendGridAddress{Email: s.from},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Text}},
+		Headers:          msg.Headers,
+	}
+	if addr, err := mail.ParseAddress(s.from); err == nil {
+		payload.From = sendGridAddress{Email: addr.Address, Name: addr.Name}
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+	for _, attachment := range msg.Attachments {
+		payload.Attachments = append(payload.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Content),
+			Type:        attachment.contentType(),
+			Filename:    attachment.Filename,
+			Disposition: "attachment",
+		})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encode sendgrid message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("build sendgrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send mail to %s: %w", msg.To, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", providerError("sendgrid", resp)
+	}
+	return resp.Header.Get("X-Message-Id"), nil
+}
+
+// providerError describes a refused provider request with the start of the response body.
+func providerError(provider string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s: unexpected status %d: %s", provider, resp.StatusCode, bytes.TrimSpace(detail))
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// APIKeyHeader carries the API key of a data provider.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves the key sent in APIKeyHeader, returning nil when no unrevoked key
+// matches; service.APIKeyService implements it.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*entity.APIKey, error)
+}
+
+// APIKey only lets through requests carrying an unrevoked API key granted scope, and stores the
+// key for the handler and APIKeyRateLimiter. Missing and unknown keys are answered 401, keys
+// without the scope 403.
+func APIKey(keys APIKeyAuthenticator, scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			secret := strings.TrimSpace(c.Request().Header.Get(APIKeyHeader))
+			if secret == "" {
+				return c.JSON(http.StatusUnauthorized, errorBody(c, "missing api key"))
+			}
+
+			key, err := keys.Authenticate(c.Request().Context(), secret)
+			if err != nil {
+				log.Printf("request_id=%s failed to authenticate api key: %v", RequestIDFromContext(c), err)
+				return c.JSON(http.StatusInternalServerError, errorBody(c, "failed to authenticate api key"))
+			}
+			if key == nil {
+				return c.JSON(http.StatusUnauthorized, errorBody(c, "invalid api key"))
+			}
+			if !key.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, errorBody(c, "insufficient permissions"))
+			}
+
+			c.Set(ContextKeyAPIKey, key)
+			return next(c)
+		}
+	}
+}
+
+// APIKeyFromContext returns the key stored by APIKey, or nil when the route is not guarded by it.
+func APIKeyFromContext(c echo.Context) *entity.APIKey {
+	key, _ := c.Get(ContextKeyAPIKey).(*entity.APIKey)
+	return key
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	authpkg "github.com/octobees/leads-generator/api/internal/auth"
+)
+
+// WorkerCallback only lets through requests carrying a callback token of kind, sent as a bearer
+// token, and stores its claims for the handler to match against the job and companies it touches.
+func WorkerCallback(signer *authpkg.CallbackSigner, kind string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing callback token"})
+			}
+
+			parts := strings.SplitN(authHeader, " ", 2)
+			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid authorization header"})
+			}
+
+			claims, err := signer.Verify(parts[1], kind)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid callback token"})
+			}
+
+			c.Set(ContextKeyCallback, claims)
+			return next(c)
+		}
+	}
+}
+
+// CallbackFromContext returns the claims stored by WorkerCallback, or nil when the route is not
+// guarded by it.
+func CallbackFromContext(c echo.Context) *authpkg.CallbackClaims {
+	claims, _ := c.Get(ContextKeyCallback).(*authpkg.CallbackClaims)
+	return claims
+}
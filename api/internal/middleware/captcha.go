@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/captcha"
+)
+
+// CaptchaHeader carries the challenge token the client solved.
+const CaptchaHeader = "X-Captcha-Token"
+
+// Captcha requires a valid challenge token in CaptchaHeader. Missing tokens are answered 400 and
+// rejected ones 403. The check fails open when the provider cannot be reached, so an outage slows
+// abuse protection down to rate limiting instead of locking every user out.
+func Captcha(verifier captcha.Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := strings.TrimSpace(c.Request().Header.Get(CaptchaHeader))
+			if token == "" {
+				return c.JSON(http.StatusBadRequest, errorBody(c, "captcha token is required"))
+			}
+
+			err := verifier.Verify(c.Request().Context(), token, c.RealIP())
+			switch {
+			case err == nil:
+			case errors.Is(err, captcha.ErrRejected):
+				return c.JSON(http.StatusForbidden, errorBody(c, "captcha verification failed"))
+			default:
+				log.Printf("request_id=%s captcha verification unavailable, allowing request: %v", RequestIDFromContext(c), err)
+			}
+			return next(c)
+		}
+	}
+}
@@ -6,4 +6,14 @@ const (
 	ContextKeyUserEmail = "user_email"
 	ContextKeyUserRole  = "user_role"
 	ContextKeyRequestID = "request_id"
+	// ContextKeyLanguage holds the language of response messages, set by Language.
+	ContextKeyLanguage = "language"
+	// ContextKeySessionID holds the refresh token session of the access token, if any.
+	ContextKeySessionID = "session_id"
+	// ContextKeyCallback holds the *auth.CallbackClaims of a verified worker callback.
+	ContextKeyCallback = "worker_callback"
+	// ContextKeyServerError holds the errreport.Event of a 5xx response, for ReportErrors.
+	ContextKeyServerError = "server_error"
+	// ContextKeyAPIKey holds the *entity.APIKey of a request authenticated by APIKey.
+	ContextKeyAPIKey = "api_key"
 )
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+
+	"github.com/octobees/leads-generator/api/internal/errreport"
+)
+
+// CaptureServerError records the cause and stack of a 5xx response for ReportErrors. Only the
+// first capture of a request is kept, as later ones describe the same failure from further out.
+func CaptureServerError(c echo.Context, err error, message string) {
+	if _, ok := c.Get(ContextKeyServerError).(errreport.Event); ok {
+		return
+	}
+	c.Set(ContextKeyServerError, errreport.Event{Err: err, Message: message, Stack: errreport.Callers(1)})
+}
+
+// Recover turns panics into 500 responses, logging them and capturing the panic and its stack
+// for ReportErrors.
+func Recover() echo.MiddlewareFunc {
+	return echoMiddleware.RecoverWithConfig(echoMiddleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			log.Printf("request_id=%s [PANIC RECOVER] %v %s", RequestIDFromContext(c), err, stack)
+			// The deferred recover still runs on the panicking goroutine, so the stack reaches
+			// the panic.
+			c.Set(ContextKeyServerError, errreport.Event{Err: err, Message: "panic", Stack: errreport.Callers(1), Panic: true})
+			return err
+		},
+	})
+}
+
+// ReportErrors reports 5xx responses to reporter with the request id, user id and route, and the
+// cause and stack captured by the handler or Recover. 503 and 504 responses are left out: the API
+// answers them on purpose while read-only or when a query runs past its deadline.
+func ReportErrors(reporter errreport.Reporter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status < http.StatusInternalServerError || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout {
+				return err
+			}
+			event, ok := c.Get(ContextKeyServerError).(errreport.Event)
+			if !ok {
+				event = errreport.Event{Err: err, Message: http.StatusText(status)}
+				if err == nil {
+					event.Err = fmt.Errorf("%s %s answered %d", c.Request().Method, c.Path(), status)
+				}
+			}
+			event.RequestID = RequestIDFromContext(c)
+			event.UserID, _ = c.Get(ContextKeyUserID).(string)
+			event.Method = c.Request().Method
+			event.Route = c.Path()
+			event.Status = status
+			reporter.Report(c.Request().Context(), event)
+			return err
+		}
+	}
+}
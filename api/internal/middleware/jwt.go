@@ -31,8 +31,26 @@ func JWT(manager *authpkg.JWTManager) echo.MiddlewareFunc {
 			c.Set(ContextKeyUserID, claims.Subject)
 			c.Set(ContextKeyUserEmail, claims.Email)
 			c.Set(ContextKeyUserRole, claims.Role)
+			if claims.SessionID != "" {
+				c.Set(ContextKeySessionID, claims.SessionID)
+			}
 
 			return next(c)
 		}
 	}
 }
+
+// OptionalJWT authenticates requests carrying an Authorization header like JWT and lets anonymous
+// requests through, so public routes can tailor results to a signed-in caller.
+func OptionalJWT(manager *authpkg.JWTManager) echo.MiddlewareFunc {
+	authenticate := JWT(manager)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := authenticate(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			return authenticated(c)
+		}
+	}
+}
@@ -77,3 +77,31 @@ func TestJWTMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestOptionalJWTMiddleware(t *testing.T) {
+	e := echo.New()
+	manager := auth.NewJWTManager("secret", 0)
+	mw := OptionalJWT(manager)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	anonymous := false
+	_ = mw(func(c echo.Context) error {
+		anonymous = c.Get(ContextKeyUserID) == nil
+		return c.NoContent(http.StatusOK)
+	})(e.NewContext(req, rec))
+	if !anonymous || rec.Code != http.StatusOK {
+		t.Fatalf("expected anonymous requests through, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	rec = httptest.NewRecorder()
+	_ = mw(func(c echo.Context) error {
+		t.Fatalf("expected invalid tokens to be refused")
+		return nil
+	})(e.NewContext(req, rec))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an invalid token, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/i18n"
+)
+
+// Language picks the language of response messages from the Accept-Language header.
+func Language() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			lang := i18n.Negotiate(c.Request().Header.Get("Accept-Language"))
+			c.Set(ContextKeyLanguage, lang)
+			header := c.Response().Header()
+			header.Set("Content-Language", lang)
+			header.Add(echo.HeaderVary, "Accept-Language")
+			return next(c)
+		}
+	}
+}
+
+// LanguageFromContext returns the language picked for the request, English by default.
+func LanguageFromContext(c echo.Context) string {
+	if lang, ok := c.Get(ContextKeyLanguage).(string); ok {
+		return lang
+	}
+	return i18n.English
+}
+
+// errorBody is the envelope of an error response written by a middleware, in the language of the
+// request.
+func errorBody(c echo.Context, message string) map[string]string {
+	code, text := i18n.Translate("", message, LanguageFromContext(c))
+	body := map[string]string{"status": "error", "message": text}
+	if code != "" {
+		body["code"] = code
+	}
+	return body
+}
@@ -2,17 +2,33 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/octobees/leads-generator/api/internal/captcha"
 	"github.com/octobees/leads-generator/api/internal/config"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/errreport"
 )
 
 func TestLoggingMiddleware(t *testing.T) {
@@ -56,57 +72,261 @@ func TestLoggingMiddleware(t *testing.T) {
 	}
 }
 
-func TestScrapeRateLimiter(t *testing.T) {
-	cfg := config.RateLimitConfig{Requests: 1, Interval: time.Second}
-	mw := ScrapeRateLimiter(cfg)
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{
+		Requests: 10,
+		Interval: time.Minute,
+		Burst:    20,
+		Costs:    map[string]int{"scrape": 10, "enrich": 5},
+		Roles:    map[string]float64{"admin": 2},
+	}, CallerKey)
+	e := echo.New()
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	send := func(operation, userID, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/"+operation, nil)
+		req.RemoteAddr = "203.0.113.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if userID != "" {
+			c.Set(ContextKeyUserID, userID)
+			c.Set(ContextKeyUserRole, role)
+		}
+		_ = limiter.Limit(operation)(next)(c)
+		return rec
+	}
+
+	// The burst of 20 covers a scrape, an enrich and five listings, but not a sixth.
+	for _, operation := range []string{"scrape", "enrich", "list", "list", "list", "list", "list"} {
+		if rec := send(operation, "user-1", "user"); rec.Code != http.StatusOK {
+			t.Fatalf("expected %s within the burst to pass, got %d", operation, rec.Code)
+		}
+	}
+	if rec := send("list", "user-1", "user"); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "6" {
+		t.Fatalf("expected 429 with Retry-After of one token, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	// A refused request spends nothing, and an anonymous caller spends the budget of their IP.
+	if rec := send("scrape", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected an anonymous caller to have its own budget, got %d", rec.Code)
+	}
 
+	// Admins get twice the burst.
+	for i := 0; i < 4; i++ {
+		if rec := send("scrape", "admin-1", "admin"); rec.Code != http.StatusOK {
+			t.Fatalf("expected admin scrape %d to pass, got %d", i+1, rec.Code)
+		}
+	}
+	if rec := send("scrape", "admin-1", "admin"); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected the fifth admin scrape refused, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	disabled := NewRateLimiter(config.RateLimitConfig{}, CallerKey)
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		_ = disabled.Limit("scrape")(next)(e.NewContext(httptest.NewRequest(http.MethodPost, "/scrape", nil), rec))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected passthrough when limiter disabled, got %d", rec.Code)
+		}
+	}
+}
+
+func TestRateLimiterKeepsDrainedBucketsUntilFull(t *testing.T) {
+	// A burst of twice the rate takes two intervals to fill up again.
+	limiter := NewRateLimiter(config.RateLimitConfig{Requests: 1, Interval: time.Minute, Burst: 2, Costs: map[string]int{"scrape": 2}}, ClientIP)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
 	e := echo.New()
-	nextCalls := 0
-	next := func(c echo.Context) error {
-		nextCalls++
-		return c.NoContent(http.StatusOK)
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	send := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/scrape", nil)
+		req.RemoteAddr = "203.0.113.1:1234"
+		rec := httptest.NewRecorder()
+		_ = limiter.Limit("scrape")(next)(e.NewContext(req, rec))
+		return rec.Code
 	}
 
-	req := httptest.NewRequest(http.MethodPost, "/scrape", nil)
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-	c.SetPath("/scrape")
+	if code := send(); code != http.StatusOK {
+		t.Fatalf("expected the burst to cover a scrape, got %d", code)
+	}
+	now = now.Add(time.Minute + time.Second)
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Fatalf("expected a drained bucket to stay drained after one interval, got %d", code)
+	}
+	now = now.Add(2 * time.Minute)
+	if code := send(); code != http.StatusOK {
+		t.Fatalf("expected the bucket full again after the refill time, got %d", code)
+	}
+}
 
-	_ = mw(next)(c)
-	if rec.Code != http.StatusOK {
+func TestRateLimiterSharedKey(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{Requests: 2, Interval: time.Minute}, SharedKey)
+	e := echo.New()
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	send := func(remoteAddr, userID, role string) int {
+		req := httptest.NewRequest(http.MethodPost, "/scrape", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(ContextKeyUserID, userID)
+		c.Set(ContextKeyUserRole, role)
+		_ = limiter.Limit("scrape")(next)(c)
+		return rec.Code
+	}
+
+	// Callers on other hosts and in other roles still spend the one bucket.
+	if code := send("203.0.113.1:1234", "user-1", "user"); code != http.StatusOK {
+		t.Fatalf("expected the first call to pass, got %d", code)
+	}
+	if code := send("203.0.113.2:1234", "admin-1", "admin"); code != http.StatusOK {
+		t.Fatalf("expected the second call to pass, got %d", code)
+	}
+	if code := send("203.0.113.3:1234", "user-2", "user"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the shared bucket to be spent, got %d", code)
+	}
+}
+
+func TestIPRateLimiter(t *testing.T) {
+	mw := IPRateLimiter(config.RateLimitConfig{Requests: 1, Interval: time.Minute})
+	e := echo.New()
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		_ = mw(next)(e.NewContext(req, rec))
+		return rec
+	}
+
+	if rec := send("203.0.113.1"); rec.Code != http.StatusOK {
 		t.Fatalf("expected first request to pass, got %d", rec.Code)
 	}
+	rec := send("203.0.113.1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected 429 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := send("203.0.113.2"); rec.Code != http.StatusOK {
+		t.Fatalf("expected another client to have its own budget, got %d", rec.Code)
+	}
 
-	req2 := httptest.NewRequest(http.MethodPost, "/scrape", nil)
-	rec2 := httptest.NewRecorder()
-	c2 := e.NewContext(req2, rec2)
-	c2.SetPath("/scrape")
-	_ = mw(next)(c2)
-	if rec2.Code != http.StatusTooManyRequests {
-		t.Fatalf("expected second request rejected, got %d", rec2.Code)
+	disabled := IPRateLimiter(config.RateLimitConfig{})
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		_ = disabled(next)(e.NewContext(httptest.NewRequest(http.MethodGet, "/companies", nil), rec))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected passthrough when limiter disabled, got %d", rec.Code)
+		}
 	}
+}
+
+type captchaStub struct {
+	err error
+}
 
-	// Non-scrape path should bypass limiter.
-	req3 := httptest.NewRequest(http.MethodGet, "/healthz", nil)
-	rec3 := httptest.NewRecorder()
-	c3 := e.NewContext(req3, rec3)
-	_ = mw(next)(c3)
-	if rec3.Code != http.StatusOK {
-		t.Fatalf("expected non-scrape request to pass")
+func (s captchaStub) Verify(ctx context.Context, token, remoteIP string) error {
+	return s.err
+}
+
+func TestCaptcha(t *testing.T) {
+	tests := map[string]struct {
+		token  string
+		err    error
+		status int
+	}{
+		"missing token":        {"", nil, http.StatusBadRequest},
+		"valid token":          {"ok", nil, http.StatusOK},
+		"rejected token":       {"bad", fmt.Errorf("%w: invalid-input-response", captcha.ErrRejected), http.StatusForbidden},
+		"provider unavailable": {"ok", errors.New("timeout"), http.StatusOK},
 	}
 
-	// zero config should behave as passthrough
-	mw = ScrapeRateLimiter(config.RateLimitConfig{})
-	req4 := httptest.NewRequest(http.MethodPost, "/scrape", nil)
-	rec4 := httptest.NewRecorder()
-	c4 := e.NewContext(req4, rec4)
-	c4.SetPath("/scrape")
-	_ = mw(next)(c4)
-	if rec4.Code != http.StatusOK {
-		t.Fatalf("expected passthrough when limiter disabled")
+	e := echo.New()
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/register", nil)
+			if tt.token != "" {
+				req.Header.Set(CaptchaHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			_ = Captcha(captchaStub{err: tt.err})(next)(e.NewContext(req, rec))
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
 	}
-	if nextCalls == 0 {
-		t.Fatalf("expected next handler to be invoked")
+}
+
+type apiKeysStub map[string]*entity.APIKey
+
+func (s apiKeysStub) Authenticate(ctx context.Context, secret string) (*entity.APIKey, error) {
+	if secret == "broken" {
+		return nil, errors.New("database down")
+	}
+	return s[secret], nil
+}
+
+func TestAPIKey(t *testing.T) {
+	keys := apiKeysStub{
+		"writer": {ID: uuid.New(), Scopes: []string{entity.APIKeyScopeCompaniesWrite}},
+		"reader": {ID: uuid.New()},
+	}
+	tests := map[string]struct {
+		key    string
+		status int
+	}{
+		"missing key":       {"", http.StatusUnauthorized},
+		"unknown key":       {"nope", http.StatusUnauthorized},
+		"key without scope": {"reader", http.StatusForbidden},
+		"key with scope":    {"writer", http.StatusOK},
+		"store unavailable": {"broken", http.StatusInternalServerError},
+	}
+
+	e := echo.New()
+	next := func(c echo.Context) error {
+		if APIKeyFromContext(c) != keys["writer"] {
+			t.Fatalf("expected the key stored in the context")
+		}
+		return c.NoContent(http.StatusOK)
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/companies/batch", nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			_ = APIKey(keys, entity.APIKeyScopeCompaniesWrite)(next)(e.NewContext(req, rec))
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRateLimiter(t *testing.T) {
+	mw := APIKeyRateLimiter(config.RateLimitConfig{Requests: 1, Interval: time.Minute})
+	e := echo.New()
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	send := func(key *entity.APIKey, ip string) int {
+		req := httptest.NewRequest(http.MethodPost, "/companies/batch", nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(ContextKeyAPIKey, key)
+		_ = mw(next)(c)
+		return rec.Code
+	}
+
+	first, second := &entity.APIKey{ID: uuid.New()}, &entity.APIKey{ID: uuid.New()}
+	if code := send(first, "203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("expected first request to pass, got %d", code)
+	}
+	if code := send(first, "203.0.113.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the key limited across hosts, got %d", code)
+	}
+	if code := send(second, "203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("expected another key to have its own budget, got %d", code)
 	}
 }
 
@@ -200,3 +420,150 @@ func TestRequestIDMiddleware(t *testing.T) {
 		}
 	})
 }
+
+type readOnlyStateStub bool
+
+func (s readOnlyStateStub) ReadOnly() bool { return bool(s) }
+
+func TestReadOnlyGuard(t *testing.T) {
+	e := echo.New()
+	var dbReadOnlyErr bool
+	track := func(ctx context.Context) (context.Context, func() bool) {
+		return ctx, func() bool { return dbReadOnlyErr }
+	}
+	write := func(c echo.Context) error {
+		if dbReadOnlyErr {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"status": "error", "message": "failed to create user"})
+		}
+		return c.JSON(http.StatusCreated, map[string]string{"status": "success"})
+	}
+	serve := func(state bool, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		if err := ReadOnlyGuard(readOnlyStateStub(state), track, "/auth/login")(write)(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	if rec := serve(true, http.MethodPost, "/admin/users"); rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "temporarily read-only") || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected write rejected while read-only, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(true, http.MethodGet, "/companies"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected reads to pass while read-only, got %d", rec.Code)
+	}
+	if rec := serve(true, http.MethodPost, "/auth/login"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected read paths to pass while read-only, got %d", rec.Code)
+	}
+	if rec := serve(false, http.MethodPost, "/admin/users"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected writes to pass when writable, got %d", rec.Code)
+	}
+
+	// The database turned read-only while the write was running.
+	dbReadOnlyErr = true
+	rec := serve(false, http.MethodPost, "/admin/users")
+	if rec.Code != http.StatusServiceUnavailable || strings.Contains(rec.Body.String(), "failed to create user") || !strings.Contains(rec.Body.String(), "temporarily read-only") {
+		t.Fatalf("expected server error replaced by read-only 503, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	e := echo.New()
+	e.Use(RequestID(), Tracing())
+	var handlerSpan trace.SpanContext
+	e.POST("/ingest/runs/:id", func(c echo.Context) error {
+		handlerSpan = trace.SpanContextFromContext(c.Request().Context())
+		return c.String(http.StatusInternalServerError, "boom")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/ingest/runs/42", nil)
+	req.Header.Set("X-Request-ID", "rid-789")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "POST /ingest/runs/:id" || span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("expected a span continuing the caller's trace, got %s in %s", span.Name(), span.SpanContext().TraceID())
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Fatalf("expected the handler context to carry the span")
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["request_id"].AsString() != "rid-789" || attrs["http.response.status_code"].AsInt64() != http.StatusInternalServerError {
+		t.Fatalf("unexpected span attributes %v", span.Attributes())
+	}
+	if span.Status().Code != codes.Error {
+		t.Fatalf("expected a server error to mark the span failed, got %v", span.Status())
+	}
+}
+
+type recordingReporter struct{ events []errreport.Event }
+
+func (r *recordingReporter) Report(ctx context.Context, event errreport.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestReportErrors(t *testing.T) {
+	orig := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(orig)
+
+	reporter := &recordingReporter{}
+	e := echo.New()
+	e.Use(RequestID(), ReportErrors(reporter), Recover())
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(ContextKeyUserID, "user-1")
+			return next(c)
+		}
+	})
+	e.GET("/panic", func(c echo.Context) error { panic("boom") })
+	e.GET("/failed", func(c echo.Context) error {
+		CaptureServerError(c, errors.New("db down"), "failed to list")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to list"})
+	})
+	e.GET("/returned", func(c echo.Context) error { return errors.New("unhandled") })
+	e.GET("/timeout", func(c echo.Context) error { return c.NoContent(http.StatusGatewayTimeout) })
+	e.GET("/missing", func(c echo.Context) error { return echo.ErrNotFound })
+
+	for _, path := range []string{"/panic", "/failed", "/returned", "/timeout", "/missing"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Request-ID", "rid"+path)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(reporter.events) != 3 {
+		t.Fatalf("expected three reported errors, got %+v", reporter.events)
+	}
+	panicked, failed, returned := reporter.events[0], reporter.events[1], reporter.events[2]
+	if !panicked.Panic || panicked.Err.Error() != "boom" || panicked.Route != "/panic" || panicked.RequestID != "rid/panic" || panicked.Status != http.StatusInternalServerError {
+		t.Fatalf("unexpected panic event %+v", panicked)
+	}
+	if !slices.ContainsFunc(panicked.Stack, func(f runtime.Frame) bool { return strings.HasPrefix(f.Function, "runtime.gopanic") }) {
+		t.Fatalf("expected the stack to reach the panic, got %+v", panicked.Stack)
+	}
+	if failed.Err.Error() != "db down" || failed.Message != "failed to list" || failed.UserID != "user-1" || len(failed.Stack) == 0 {
+		t.Fatalf("unexpected handler event %+v", failed)
+	}
+	if returned.Err.Error() != "unhandled" || returned.Method != http.MethodGet {
+		t.Fatalf("unexpected returned error event %+v", returned)
+	}
+}
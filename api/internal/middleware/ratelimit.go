@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,39 +13,154 @@ import (
 	"github.com/octobees/leads-generator/api/internal/config"
 )
 
-// ScrapeRateLimiter applies a token bucket limiter for the /scrape endpoint.
-func ScrapeRateLimiter(cfg config.RateLimitConfig) echo.MiddlewareFunc {
-	if cfg.Requests <= 0 || cfg.Interval <= 0 {
-		return func(next echo.HandlerFunc) echo.HandlerFunc {
-			return func(c echo.Context) error {
-				return next(c)
-			}
+// Operations rate limited routes are charged as, besides the worker features of entity.Feature*.
+const (
+	OperationList   = "list"
+	OperationExport = "export"
+)
+
+// AnonymousRole is the role the budget of callers without a token is scaled by.
+const AnonymousRole = "anonymous"
+
+// RateLimiter spends a token bucket per client, as named by its client key. Each operation spends
+// the tokens its cost in the config says, one by default, and the bucket of a caller fills at the
+// configured rate up to the burst, both scaled by the multiplier of their role. Routes limited
+// through one RateLimiter share the buckets. Without role multipliers in the config every role of a
+// client spends the same bucket.
+type RateLimiter struct {
+	cfg       config.RateLimitConfig
+	clientKey func(echo.Context) string
+	every     time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*rateClient
+	lastSweep time.Time
+}
+
+type rateClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	// refill is how long the bucket takes to fill up from empty.
+	refill time.Duration
+}
+
+// NewRateLimiter builds a limiter with a bucket per client named by clientKey; a zero config
+// disables it.
+func NewRateLimiter(cfg config.RateLimitConfig, clientKey func(echo.Context) string) *RateLimiter {
+	every := time.Second
+	if cfg.Requests > 0 {
+		if perRequest := cfg.Interval / time.Duration(cfg.Requests); perRequest > 0 {
+			every = perRequest
 		}
 	}
-
-	perRequest := cfg.Interval / time.Duration(cfg.Requests)
-	if perRequest <= 0 {
-		perRequest = time.Second
+	return &RateLimiter{
+		cfg:       cfg,
+		clientKey: clientKey,
+		every:     every,
+		now:       time.Now,
+		clients:   make(map[string]*rateClient),
+		lastSweep: time.Now(),
 	}
+}
 
-	limiter := rate.NewLimiter(rate.Every(perRequest), cfg.Requests)
-	var mu sync.Mutex
+// Limit returns middleware spending the cost of operation from the bucket of each request's client.
+// Requests the bucket cannot cover yet are answered 429 with a Retry-After of when it will.
+func (l *RateLimiter) Limit(operation string) echo.MiddlewareFunc {
+	if l.cfg.Requests <= 0 || l.cfg.Interval <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	cost := l.cfg.Cost(operation)
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			if c.Path() != "/scrape" {
-				return next(c)
+			role, _ := c.Get(ContextKeyUserRole).(string)
+			if role == "" {
+				role = AnonymousRole
 			}
-
-			mu.Lock()
-			allowed := limiter.Allow()
-			mu.Unlock()
-
-			if !allowed {
-				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "scrape rate limit exceeded"})
+			now := l.now()
+			reservation := l.bucket(l.clientKey(c), role, now).ReserveN(now, cost)
+			if delay := reservation.DelayFrom(now); !reservation.OK() || delay > 0 {
+				reservation.CancelAt(now)
+				if reservation.OK() {
+					c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				}
+				return c.JSON(http.StatusTooManyRequests, errorBody(c, "too many requests"))
 			}
-
 			return next(c)
 		}
 	}
 }
+
+// bucket returns the bucket of a client in role. Buckets idle for as long as they take to fill up
+// are full again, so they are dropped to bound memory; with a burst above the rate, or a role
+// multiplier below one, that is longer than an interval.
+func (l *RateLimiter) bucket(key, role string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > l.cfg.Interval {
+		for id, idle := range l.clients {
+			if now.Sub(idle.lastSeen) > idle.refill {
+				delete(l.clients, id)
+			}
+		}
+		l.lastSweep = now
+	}
+	id := key
+	if len(l.cfg.Roles) > 0 {
+		id = role + "|" + key
+	}
+	cl, ok := l.clients[id]
+	if !ok {
+		multiplier := l.cfg.Multiplier(role)
+		burst := int(math.Ceil(float64(l.cfg.BurstSize()) * multiplier))
+		cl = &rateClient{
+			limiter: rate.NewLimiter(rate.Every(l.every)*rate.Limit(multiplier), burst),
+			refill:  time.Duration(math.Ceil(float64(l.every) * float64(burst) / multiplier)),
+		}
+		l.clients[id] = cl
+	}
+	cl.lastSeen = now
+	return cl.limiter
+}
+
+// SharedKey names every client the same, so they all spend one bucket; it suits limits guarding
+// something the callers share, such as the worker's Places quota.
+func SharedKey(echo.Context) string {
+	return "shared"
+}
+
+// ClientIP names clients by IP.
+func ClientIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// CallerKey names authenticated callers by user, so they keep one budget across hosts, and others
+// by IP. Routes must authenticate before limiting for the user to be known.
+func CallerKey(c echo.Context) string {
+	if userID, _ := c.Get(ContextKeyUserID).(string); userID != "" {
+		return "user:" + userID
+	}
+	return ClientIP(c)
+}
+
+// IPRateLimiter applies a token bucket per client IP, allowing cfg.Requests per cfg.Interval with
+// bursts of the configured size; a zero config disables it. Routes sharing one instance share the
+// buckets.
+func IPRateLimiter(cfg config.RateLimitConfig) echo.MiddlewareFunc {
+	return NewRateLimiter(cfg, ClientIP).Limit("")
+}
+
+// APIKeyRateLimiter applies a token bucket per API key, like IPRateLimiter does per IP, so a data
+// provider pushing from several hosts gets one budget. It must run after APIKey; requests without a
+// key share the bucket of their IP.
+func APIKeyRateLimiter(cfg config.RateLimitConfig) echo.MiddlewareFunc {
+	return NewRateLimiter(cfg, func(c echo.Context) string {
+		if key := APIKeyFromContext(c); key != nil {
+			return "key:" + key.ID.String()
+		}
+		return ClientIP(c)
+	}).Limit("")
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadOnlyRetryAfter is the Retry-After hint, in seconds, sent with read-only rejections.
+const ReadOnlyRetryAfter = 10
+
+const readOnlyMessage = "temporarily read-only"
+
+// ReadOnlyState reports whether the database currently rejects writes.
+type ReadOnlyState interface {
+	ReadOnly() bool
+}
+
+// ReadOnlyTracker returns a request context that records read-only database errors, and a
+// function reporting whether one occurred.
+type ReadOnlyTracker func(ctx context.Context) (context.Context, func() bool)
+
+// ReadOnlyGuard keeps the API useful while the database rejects writes. Reads always pass. Writes
+// are answered with 503 "temporarily read-only" while state reports read-only, and a write that
+// fails with a read-only error mid-request has its server error replaced by the same 503. Methods
+// on readPaths (route paths such as "/auth/login") are treated as reads.
+func ReadOnlyGuard(state ReadOnlyState, track ReadOnlyTracker, readPaths ...string) echo.MiddlewareFunc {
+	reads := make(map[string]bool, len(readPaths))
+	for _, p := range readPaths {
+		reads[p] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			if reads[c.Path()] {
+				return next(c)
+			}
+
+			res := c.Response()
+			if state.ReadOnly() {
+				res.Header().Set("Retry-After", strconv.Itoa(ReadOnlyRetryAfter))
+				return c.JSON(http.StatusServiceUnavailable, errorBody(c, readOnlyMessage))
+			}
+
+			ctx, hit := track(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+			writer := &readOnlyWriter{ResponseWriter: res.Writer}
+			res.Writer = writer
+			res.Before(func() {
+				if res.Status >= http.StatusInternalServerError && hit() {
+					res.Status = http.StatusServiceUnavailable
+					res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+					res.Header().Set("Retry-After", strconv.Itoa(ReadOnlyRetryAfter))
+					res.Header().Del(echo.HeaderContentLength)
+					writer.replace = true
+				}
+			})
+			return next(c)
+		}
+	}
+}
+
+// readOnlyWriter substitutes the read-only body for whatever error body the handler writes.
+type readOnlyWriter struct {
+	http.ResponseWriter
+	replace bool
+	written bool
+}
+
+func (w *readOnlyWriter) Write(b []byte) (int, error) {
+	if !w.replace {
+		return w.ResponseWriter.Write(b)
+	}
+	if !w.written {
+		w.written = true
+		if _, err := w.ResponseWriter.Write([]byte(`{"status":"error","message":"` + readOnlyMessage + `"}` + "\n")); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *readOnlyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,265 @@
+// Package outbound guards the HTTP requests the API makes to addresses it does not control, such
+// as the social links of an enrichment payload, against server-side request forgery.
+package outbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// ErrDenied is returned for requests the policy refuses to make.
+var ErrDenied = errors.New("outbound request denied")
+
+// Defaults for policies built by New.
+const (
+	DefaultMaxRedirects    = 3
+	DefaultMaxConnsPerHost = 4
+)
+
+// blockedRanges are never dialled unless allowed: the unspecified, loopback, private, shared,
+// link-local (where cloud metadata services answer), benchmarking, multicast and reserved ranges.
+var blockedRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// blockedHosts are refused by name before they are resolved.
+var blockedHosts = []string{"localhost", "metadata.google.internal"}
+
+// Resolver abstracts the address lookups of the policy to simplify testing.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Policy decides which hosts and addresses outbound requests may reach. Addresses in private,
+// loopback, link-local and other internal ranges are refused unless an allow entry covers them;
+// deny entries are refused in any case. Every address a host resolves to is checked when it is
+// dialled, so a name re-pointed at an internal address after a check is still refused.
+type Policy struct {
+	allowHosts      []string
+	allowNets       []netip.Prefix
+	denyHosts       []string
+	denyNets        []netip.Prefix
+	pinnedHost      string
+	maxRedirects    int
+	maxConnsPerHost int
+	resolver        Resolver
+	dialer          *net.Dialer
+}
+
+// Option configures a Policy.
+type Option func(*Policy)
+
+// WithAllow lets requests reach the given hosts, IPs or CIDR ranges even when they are internal. A
+// host entry also covers its subdomains.
+func WithAllow(entries ...string) Option {
+	return func(p *Policy) {
+		p.allowHosts, p.allowNets = appendEntries(p.allowHosts, p.allowNets, entries)
+	}
+}
+
+// WithDeny refuses requests to the given hosts, IPs or CIDR ranges, whatever the allow entries say.
+func WithDeny(entries ...string) Option {
+	return func(p *Policy) {
+		p.denyHosts, p.denyNets = appendEntries(p.denyHosts, p.denyNets, entries)
+	}
+}
+
+// WithMaxRedirects caps the redirects followed per request; zero follows none.
+func WithMaxRedirects(n int) Option {
+	return func(p *Policy) {
+		if n >= 0 {
+			p.maxRedirects = n
+		}
+	}
+}
+
+// WithMaxConnsPerHost bounds the connections open to one host at a time; zero removes the bound.
+func WithMaxConnsPerHost(n int) Option {
+	return func(p *Policy) {
+		if n >= 0 {
+			p.maxConnsPerHost = n
+		}
+	}
+}
+
+// WithResolver overrides the system resolver.
+func WithResolver(resolver Resolver) Option {
+	return func(p *Policy) {
+		if resolver != nil {
+			p.resolver = resolver
+		}
+	}
+}
+
+// New builds a policy refusing internal addresses, following DefaultMaxRedirects redirects and
+// opening at most DefaultMaxConnsPerHost connections per host.
+func New(opts ...Option) *Policy {
+	p := &Policy{
+		maxRedirects:    DefaultMaxRedirects,
+		maxConnsPerHost: DefaultMaxConnsPerHost,
+		resolver:        net.DefaultResolver,
+		dialer:          &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Pin returns a copy of the policy for a single trusted host, such as the worker: the host is
+// allowed even on an internal address, and redirects to any other host are refused.
+func (p *Policy) Pin(host string) *Policy {
+	pinned := *p
+	pinned.allowHosts = append(append([]string(nil), p.allowHosts...), normalizeHost(host))
+	pinned.pinnedHost = normalizeHost(host)
+	return &pinned
+}
+
+// Client returns an HTTP client with the given timeout whose requests follow the policy.
+func (p *Policy) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: p.Transport(), CheckRedirect: p.CheckRedirect}
+}
+
+// Transport returns a transport dialling only addresses the policy allows. It ignores proxy
+// settings, which would otherwise be dialled in place of the checked address.
+func (p *Policy) Transport() *http.Transport {
+	return &http.Transport{
+		DialContext:           p.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxConnsPerHost:       p.maxConnsPerHost,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// CheckRedirect is an http.Client CheckRedirect refusing redirects past the policy's cap, to schemes
+// other than http and https, and, on a pinned policy, to other hosts.
+func (p *Policy) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > p.maxRedirects {
+		return fmt.Errorf("%w: stopped after %d redirects", ErrDenied, p.maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("%w: redirect to %s scheme", ErrDenied, req.URL.Scheme)
+	}
+	if p.pinnedHost != "" && normalizeHost(req.URL.Hostname()) != p.pinnedHost {
+		return fmt.Errorf("%w: redirect away from %s", ErrDenied, p.pinnedHost)
+	}
+	return p.checkHost(req.URL.Hostname())
+}
+
+// DialContext resolves addr and dials the first of its addresses the policy allows.
+func (p *Policy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkHost(host); err != nil {
+		return nil, err
+	}
+	hostAllowed := matchHost(p.allowHosts, host)
+
+	var addrs []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{ip}
+	} else if addrs, err = p.resolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return nil, err
+	}
+
+	lastErr := fmt.Errorf("%w: %s has no addresses", ErrDenied, host)
+	for _, ip := range addrs {
+		ip = ip.Unmap()
+		if !p.addrAllowed(ip, hostAllowed) {
+			lastErr = fmt.Errorf("%w: %s resolves to %s", ErrDenied, host, ip)
+			continue
+		}
+		conn, err := p.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// checkHost refuses hosts denied by name.
+func (p *Policy) checkHost(host string) error {
+	if matchHost(p.denyHosts, host) || (matchHost(blockedHosts, host) && !matchHost(p.allowHosts, host)) {
+		return fmt.Errorf("%w: %s", ErrDenied, host)
+	}
+	return nil
+}
+
+// addrAllowed reports whether ip may be dialled; hostAllowed is set when an allow entry names the
+// host it was resolved from.
+func (p *Policy) addrAllowed(ip netip.Addr, hostAllowed bool) bool {
+	if matchNet(p.denyNets, ip) {
+		return false
+	}
+	if hostAllowed || matchNet(p.allowNets, ip) {
+		return true
+	}
+	return !matchNet(blockedRanges, ip)
+}
+
+func appendEntries(hosts []string, nets []netip.Prefix, entries []string) ([]string, []netip.Prefix) {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			nets = append(nets, prefix.Masked())
+		} else if ip, err := netip.ParseAddr(entry); err == nil {
+			ip = ip.Unmap()
+			nets = append(nets, netip.PrefixFrom(ip, ip.BitLen()))
+		} else if host := normalizeHost(entry); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nets
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "."), ".")
+}
+
+// matchHost reports whether host is one of hosts or a subdomain of one.
+func matchHost(hosts []string, host string) bool {
+	host = normalizeHost(host)
+	for _, candidate := range hosts {
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchNet(nets []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range nets {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
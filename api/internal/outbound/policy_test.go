@@ -0,0 +1,80 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubResolver map[string][]netip.Addr
+
+func (r stubResolver) LookupNetIP(_ context.Context, _ string, host string) ([]netip.Addr, error) {
+	return r[host], nil
+}
+
+func TestPolicy_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resolver := stubResolver{
+		"metadata.example.com": {netip.MustParseAddr("169.254.169.254")},
+		"mapped.example.com":   {netip.MustParseAddr("::ffff:10.0.0.1")},
+	}
+	client := New(WithResolver(resolver)).Client(time.Second)
+	for _, target := range []string{server.URL, "http://localhost/", "http://metadata.example.com/", "http://mapped.example.com/", "http://[::1]/"} {
+		if _, err := client.Get(target); !errors.Is(err, ErrDenied) {
+			t.Fatalf("expected %s to be denied, got %v", target, err)
+		}
+	}
+
+	allowed := New(WithAllow("127.0.0.0/8")).Client(time.Second)
+	resp, err := allowed.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected an allowed range to be reached: %v", err)
+	}
+	resp.Body.Close()
+
+	denied := New(WithAllow("127.0.0.1"), WithDeny("127.0.0.0/8")).Client(time.Second)
+	if _, err := denied.Get(server.URL); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected a deny entry to win over an allow entry, got %v", err)
+	}
+}
+
+func TestPolicy_DenyHostsCoverSubdomains(t *testing.T) {
+	policy := New(WithDeny("Example.com"))
+	if err := policy.checkHost("api.example.com"); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected a subdomain of a denied host to be refused, got %v", err)
+	}
+	if err := policy.checkHost("notexample.com"); err != nil {
+		t.Fatalf("expected an unrelated host to pass, got %v", err)
+	}
+}
+
+func TestPolicy_Redirects(t *testing.T) {
+	var hops int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/elsewhere":
+			http.Redirect(w, r, "http://localhost.example.com/", http.StatusFound)
+		case hops < 5:
+			hops++
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	client := New(WithAllow("127.0.0.1"), WithMaxRedirects(2)).Client(time.Second)
+	if _, err := client.Get(server.URL); err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+		t.Fatalf("expected the redirect cap to stop the request, got %v", err)
+	}
+
+	pinned := New(WithMaxRedirects(2)).Pin("127.0.0.1").Client(time.Second)
+	if _, err := pinned.Get(server.URL + "/elsewhere"); err == nil || !strings.Contains(err.Error(), "redirect away from 127.0.0.1") {
+		t.Fatalf("expected a pinned client to refuse other hosts, got %v", err)
+	}
+}
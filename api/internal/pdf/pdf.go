@@ -0,0 +1,370 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica fonts, lines, filled
+// rectangles and JPEG images, enough for generated reports without a layout engine.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A4 page size in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// ErrUnsupportedImage is returned for JPEG images in colour spaces other than RGB and grayscale.
+var ErrUnsupportedImage = errors.New("unsupported jpeg colour space")
+
+// Font selects one of the standard fonts every PDF reader ships.
+type Font int
+
+// Fonts available to Page.Text.
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = []string{"Helvetica", "Helvetica-Bold"}
+
+// Color is an RGB colour with components from 0 to 1.
+type Color struct {
+	R, G, B float64
+}
+
+// Common colours.
+var (
+	Black = Color{}
+	White = Color{1, 1, 1}
+)
+
+// Gray returns the gray of level from 0 (black) to 1 (white).
+func Gray(level float64) Color {
+	return Color{level, level, level}
+}
+
+// Image is a JPEG added to a document, drawn on its pages with Page.Image.
+type Image struct {
+	index int
+}
+
+type jpegImage struct {
+	data          []byte
+	width, height int
+	colorSpace    string
+}
+
+// Document is a PDF document built page by page and written with WriteTo.
+type Document struct {
+	// Title is shown by readers in place of the file name.
+	Title  string
+	pages  []*Page
+	images []jpegImage
+}
+
+// New returns an empty document.
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends an A4 portrait page.
+func (d *Document) AddPage() *Page {
+	page := &Page{images: make(map[int]bool)}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// AddJPEG adds a JPEG image, which pages then draw with Page.Image. The data is embedded as is.
+func (d *Document) AddJPEG(data []byte) (Image, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("read jpeg: %w", err)
+	}
+	var colorSpace string
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		colorSpace = "DeviceGray"
+	case color.YCbCrModel, color.RGBAModel:
+		colorSpace = "DeviceRGB"
+	default:
+		return Image{}, ErrUnsupportedImage
+	}
+	d.images = append(d.images, jpegImage{data: data, width: cfg.Width, height: cfg.Height, colorSpace: colorSpace})
+	return Image{index: len(d.images) - 1}, nil
+}
+
+// Page is one page of a document. Coordinates are in points from the top left corner, with y
+// growing downwards; Text places the baseline of the text at y.
+type Page struct {
+	content bytes.Buffer
+	images  map[int]bool
+}
+
+// SetColor sets the colour of the text and rectangles drawn next.
+func (p *Page) SetColor(c Color) {
+	fmt.Fprintf(&p.content, "%s %s %s rg\n", num(c.R), num(c.G), num(c.B))
+}
+
+// Text draws s with its baseline starting at x, y. Characters outside the Windows-1252 character
+// set are drawn as question marks.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(A4Height-y), escape(encode(s)))
+}
+
+// Line draws a line of width points in colour c.
+func (p *Page) Line(x1, y1, x2, y2, width float64, c Color) {
+	fmt.Fprintf(&p.content, "%s %s %s RG %s w %s %s m %s %s l S\n",
+		num(c.R), num(c.G), num(c.B), num(width), num(x1), num(A4Height-y1), num(x2), num(A4Height-y2))
+}
+
+// Rect fills the rectangle whose top left corner is at x, y with the current colour.
+func (p *Page) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", num(x), num(A4Height-y-height), num(width), num(height))
+}
+
+// Image draws img scaled into the rectangle whose top left corner is at x, y.
+func (p *Page) Image(img Image, x, y, width, height float64) {
+	p.images[img.index] = true
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", num(width), num(height), num(x), num(A4Height-y-height), img.index+1)
+}
+
+// WriteTo writes the document as PDF 1.4.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	pages := d.pages
+	if len(pages) == 0 {
+		// A document needs at least one page to open.
+		pages = []*Page{{images: map[int]bool{}}}
+	}
+
+	// Objects are numbered: catalog, page tree, info, fonts, images, then a page and its contents
+	// per page.
+	const catalogID, pagesID, infoID, fontsID = 1, 2, 3, 4
+	imagesID := fontsID + len(fontNames)
+	pagesStart := imagesID + len(d.images)
+
+	out := &countingWriter{w: w}
+	var offsets []int64
+	object := func(body func()) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n", len(offsets))
+		body()
+		fmt.Fprint(out, "\nendobj\n")
+	}
+
+	io.WriteString(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object(func() { fmt.Fprintf(out, "<< /Type /Catalog /Pages %d 0 R >>", pagesID) })
+	object(func() {
+		kids := make([]string, len(pages))
+		for i := range pages {
+			kids[i] = fmt.Sprintf("%d 0 R", pagesStart+2*i)
+		}
+		fmt.Fprintf(out, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	})
+	object(func() { fmt.Fprintf(out, "<< /Title (%s) /Producer (leads-generator) >>", escape(encode(d.Title))) })
+	for _, name := range fontNames {
+		object(func() {
+			fmt.Fprintf(out, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)
+		})
+	}
+	for _, img := range d.images {
+		object(func() {
+			fmt.Fprintf(out, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n",
+				img.width, img.height, img.colorSpace, len(img.data))
+			out.Write(img.data)
+			fmt.Fprint(out, "\nendstream")
+		})
+	}
+	for i, page := range pages {
+		contentsID := pagesStart + 2*i + 1
+		object(func() {
+			fonts := make([]string, len(fontNames))
+			for f := range fontNames {
+				fonts[f] = fmt.Sprintf("/F%d %d 0 R", f+1, fontsID+f)
+			}
+			var images []string
+			for index := range d.images {
+				if page.images[index] {
+					images = append(images, fmt.Sprintf("/Im%d %d 0 R", index+1, imagesID+index))
+				}
+			}
+			fmt.Fprintf(out, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> /XObject << %s >> >> /Contents %d 0 R >>",
+				pagesID, num(A4Width), num(A4Height), strings.Join(fonts, " "), strings.Join(images, " "), contentsID)
+		})
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(page.content.Bytes())
+		zw.Close()
+		object(func() {
+			fmt.Fprintf(out, "<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+			out.Write(compressed.Bytes())
+			fmt.Fprint(out, "\nendstream")
+		})
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, catalogID, infoID, xref)
+	return out.n, out.err
+}
+
+// countingWriter tracks the offset objects start at and keeps the first error, so WriteTo can
+// write without checking every call.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// num formats a coordinate to a hundredth of a point, well below what prints.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// escape escapes the delimiters of a PDF literal string.
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\r', '\n', '\t':
+			sb.WriteByte(' ')
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// winAnsi maps the characters of Windows-1252 outside Latin-1 onto their codes.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// encode converts s to Windows-1252, the encoding of the standard fonts.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			out = append(out, byte(r))
+		case winAnsi[r] != 0:
+			out = append(out, winAnsi[r])
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// Width returns the width of s in points.
+func Width(font Font, size float64, s string) float64 {
+	widths := helveticaWidths
+	if font == HelveticaBold {
+		widths = helveticaBoldWidths
+	}
+	var units int
+	for _, c := range encode(s) {
+		if c >= 32 && c <= 126 {
+			units += widths[c-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, between words where it can.
+func Wrap(font Font, size float64, s string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if Width(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// A word wider than a line is broken where it overflows.
+			for Width(font, size, word) > width && utf8.RuneCountInString(word) > 1 {
+				head := Truncate(font, size, word, width, "")
+				lines = append(lines, head)
+				word = word[len(head):]
+			}
+			line = word
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Truncate shortens s to fit width, ending it with suffix when it had to be cut.
+func Truncate(font Font, size float64, s string, width float64, suffix string) string {
+	if Width(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for n := len(runes) - 1; n > 0; n-- {
+		if candidate := string(runes[:n]) + suffix; Width(font, size, candidate) <= width {
+			if suffix == "" {
+				return candidate
+			}
+			return strings.TrimRight(string(runes[:n]), " ") + suffix
+		}
+	}
+	return string(runes[:1])
+}
+
+// Advance widths of the printable ASCII characters, in thousandths of the font size.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
@@ -0,0 +1,102 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/jpeg"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument_WriteTo(t *testing.T) {
+	var photo bytes.Buffer
+	if err := jpeg.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 8, 4)), nil); err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	doc := New()
+	doc.Title = "Lead (Acme)"
+	img, err := doc.AddJPEG(photo.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected image error: %v", err)
+	}
+	page := doc.AddPage()
+	page.SetColor(Gray(0.5))
+	page.Rect(40, 40, 100, 20)
+	page.Text(40, 100, HelveticaBold, 18, `Café "Kopi" (Jakarta) → 5★`)
+	page.Line(40, 110, 555, 110, 0.5, Black)
+	page.Image(img, 40, 120, 80, 40)
+	doc.AddPage().Text(40, 100, Helvetica, 10, "second")
+
+	var out bytes.Buffer
+	n, err := doc.WriteTo(&out)
+	if err != nil || n != int64(out.Len()) {
+		t.Fatalf("unexpected write result %d, %v", n, err)
+	}
+	pdf := out.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("expected a PDF header and trailer, got %q", pdf[:20])
+	}
+	for _, want := range []string{"/Count 2", "/Title (Lead \\(Acme\\))", "/BaseFont /Helvetica-Bold", "/Filter /DCTDecode", "/XObject << /Im1 6 0 R >>"} {
+		if !strings.Contains(pdf, want) {
+			t.Fatalf("expected %q in the document", want)
+		}
+	}
+
+	// Every xref entry points at the start of its object.
+	start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(pdf)[1])
+	if err != nil || !strings.HasPrefix(pdf[start:], "xref\n0 11\n") {
+		t.Fatalf("expected startxref to point at the xref table, got %d", start)
+	}
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf, -1) {
+		offset, _ := strconv.Atoi(entry[1])
+		if !strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj\n") {
+			t.Fatalf("expected object %d at offset %d", i+1, offset)
+		}
+	}
+
+	streams := regexp.MustCompile(`(?s)/FlateDecode >>\nstream\n(.*?)\nendstream`).FindAllStringSubmatch(pdf, -1)
+	if len(streams) != 2 {
+		t.Fatalf("expected a content stream per page, got %d", len(streams))
+	}
+	zr, err := zlib.NewReader(strings.NewReader(streams[0][1]))
+	if err != nil {
+		t.Fatalf("unexpected inflate error: %v", err)
+	}
+	content, _ := io.ReadAll(zr)
+	want := "BT /F2 18 Tf 40 741.89 Td (Caf\xe9 \"Kopi\" \\(Jakarta\\) ? 5?) Tj ET"
+	if !strings.Contains(string(content), want) {
+		t.Fatalf("expected text encoded in Windows-1252 with y from the top, got %q", content)
+	}
+	if !strings.Contains(string(content), "q 80 0 0 40 40 681.89 cm /Im1 Do Q") {
+		t.Fatalf("expected the image placed from the top, got %q", content)
+	}
+}
+
+func TestWrapAndTruncate(t *testing.T) {
+	if got := Width(Helvetica, 10, "Acme"); got != 25.56 {
+		t.Fatalf("unexpected width %v", got)
+	}
+	lines := Wrap(Helvetica, 10, "Jl. Kebon Sirih No. 12, Menteng, Jakarta Pusat\nIndonesia", 100)
+	if len(lines) != 4 || lines[3] != "Indonesia" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	for _, line := range lines {
+		if Width(Helvetica, 10, line) > 100 {
+			t.Fatalf("line %q overflows", line)
+		}
+	}
+	if got := Wrap(Helvetica, 10, "https://example.com/a/very/long/path/without/spaces", 60); len(got) < 2 || strings.Join(got, "") != "https://example.com/a/very/long/path/without/spaces" {
+		t.Fatalf("expected a long word broken across lines, got %q", got)
+	}
+	if got := Truncate(HelveticaBold, 12, "Sate Khas Senayan Kebon Sirih", 90, "…"); !strings.HasSuffix(got, "…") || Width(HelveticaBold, 12, got) > 90 {
+		t.Fatalf("unexpected truncation %q", got)
+	}
+	if got := Truncate(Helvetica, 12, "Acme", 90, "…"); got != "Acme" {
+		t.Fatalf("expected short text kept, got %q", got)
+	}
+}
@@ -0,0 +1,173 @@
+// Package querycache caches the results of hot read queries, such as company listings and
+// catalogue stats, in memory or in Redis. Entries expire after a TTL and are all dropped as soon as
+// the event bus reports that companies were written or a scrape run finished.
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/events"
+)
+
+// Store kinds, as Stats.Store reports them.
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Store keeps encoded entries. Implementations are safe for concurrent use.
+type Store interface {
+	// Get returns the entry of key; found is false when there is none or it expired.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Clear drops every entry.
+	Clear(ctx context.Context) error
+	// Len returns the number of entries held, expired ones not yet dropped included.
+	Len(ctx context.Context) (int64, error)
+}
+
+// Cache reads query results through a Store. A nil Cache caches nothing, so callers use it
+// unconditionally.
+type Cache struct {
+	store Store
+	kind  string
+	ttl   time.Duration
+	// generation changes with every invalidation, so results read before one are not stored after.
+	generation atomic.Uint64
+
+	hits, misses, invalidations, failures atomic.Int64
+}
+
+// Stats reports the effectiveness of a Cache since the process started. Failures counts the
+// store errors, each of which served the query from the database.
+type Stats struct {
+	Store         string  `json:"store"`
+	Entries       int64   `json:"entries"`
+	TTLSeconds    float64 `json:"ttl_seconds"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Invalidations int64   `json:"invalidations"`
+	Failures      int64   `json:"failures"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+// New builds a cache of entries living for ttl in store; kind names the store in Stats. A nil
+// store or a zero ttl return nil, which disables caching.
+func New(store Store, kind string, ttl time.Duration) *Cache {
+	if store == nil || ttl <= 0 {
+		return nil
+	}
+	return &Cache{store: store, kind: kind, ttl: ttl}
+}
+
+// Key derives the key of a query from its kind and its normalized parameters, e.g. a listing
+// filter, so equal parameters share an entry.
+func Key(kind string, params any) string {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		// Parameters are plain structs; should one not encode, its queries are still told apart.
+		encoded = []byte(err.Error())
+	}
+	sum := sha256.Sum256(encoded)
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// Fetch returns the cached result of key, or calls load and caches what it returns. Store errors
+// are logged and counted, and the result loaded instead; load errors are returned uncached.
+func Fetch[T any](ctx context.Context, c *Cache, key string, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	if value, found, err := c.store.Get(ctx, key); err != nil {
+		c.fail("get", err)
+	} else if found {
+		var result T
+		if err := json.Unmarshal(value, &result); err == nil {
+			c.hits.Add(1)
+			return result, nil
+		}
+	}
+	c.misses.Add(1)
+
+	generation := c.generation.Load()
+	result, err := load(ctx)
+	if err != nil {
+		return result, err
+	}
+	if c.generation.Load() != generation {
+		return result, nil
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		c.fail("encode", err)
+		return result, nil
+	}
+	if err := c.store.Set(ctx, key, encoded, c.ttl); err != nil {
+		c.fail("set", err)
+	}
+	return result, nil
+}
+
+// Invalidate drops every entry.
+func (c *Cache) Invalidate(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.generation.Add(1)
+	c.invalidations.Add(1)
+	if err := c.store.Clear(ctx); err != nil {
+		c.fail("clear", err)
+	}
+}
+
+// Run invalidates the cache whenever bus reports upserted companies, from the API, CSV imports or
+// scrapes, or a finished scrape run, until ctx is done. Events the subscription misses leave
+// entries to expire with their TTL.
+func (c *Cache) Run(ctx context.Context, bus *events.Bus) {
+	if c == nil || bus == nil {
+		return
+	}
+	sub := bus.Subscribe(events.TypeCompaniesUpserted, events.TypeIngestRunFinished)
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.C:
+			c.Invalidate(ctx)
+		}
+	}
+}
+
+// Stats returns the current counters; a nil cache reports zeros.
+func (c *Cache) Stats(ctx context.Context) Stats {
+	if c == nil {
+		return Stats{}
+	}
+	stats := Stats{
+		Store:         c.kind,
+		TTLSeconds:    c.ttl.Seconds(),
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Failures:      c.failures.Load(),
+	}
+	if entries, err := c.store.Len(ctx); err == nil {
+		stats.Entries = entries
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *Cache) fail(action string, err error) {
+	c.failures.Add(1)
+	log.Printf("query cache: %s: %v", action, err)
+}
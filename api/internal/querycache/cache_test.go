@@ -0,0 +1,114 @@
+package querycache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/events"
+)
+
+type failingStore struct{ *MemoryStore }
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func TestMemoryStore_ExpiresAndEvicts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(2)
+	store.now = func() time.Time { return now }
+
+	store.Set(ctx, "a", []byte("1"), time.Minute)
+	store.Set(ctx, "b", []byte("2"), time.Hour)
+	store.Get(ctx, "a")
+	store.Set(ctx, "c", []byte("3"), time.Hour)
+	if _, found, _ := store.Get(ctx, "b"); found {
+		t.Fatalf("expected the least recently used entry evicted")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, found, _ := store.Get(ctx, "a"); found {
+		t.Fatalf("expected the expired entry dropped")
+	}
+	if value, found, _ := store.Get(ctx, "c"); !found || string(value) != "3" {
+		t.Fatalf("expected the live entry, got %q (%v)", value, found)
+	}
+	if entries, _ := store.Len(ctx); entries != 1 {
+		t.Fatalf("expected 1 entry, got %d", entries)
+	}
+}
+
+func TestCache_FetchAndInvalidate(t *testing.T) {
+	ctx := context.Background()
+	cache := New(NewMemoryStore(10), StoreMemory, time.Minute)
+	calls := 0
+	load := func(ctx context.Context) ([]string, error) {
+		calls++
+		return []string{"Kopi Kenangan"}, nil
+	}
+	key := Key("companies", map[string]string{"city": "Jakarta"})
+	if key != Key("companies", map[string]string{"city": "Jakarta"}) || key == Key("companies", map[string]string{"city": "Bandung"}) {
+		t.Fatalf("expected keys to follow the parameters")
+	}
+
+	for i := 0; i < 2; i++ {
+		if got, err := Fetch(ctx, cache, key, load); err != nil || len(got) != 1 || got[0] != "Kopi Kenangan" {
+			t.Fatalf("unexpected result %v (%v)", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the second fetch served from cache, got %d loads", calls)
+	}
+
+	cache.Invalidate(ctx)
+	Fetch(ctx, cache, key, load)
+	stats := cache.Stats(ctx)
+	if calls != 2 || stats.Hits != 1 || stats.Misses != 2 || stats.Invalidations != 1 || stats.Entries != 1 {
+		t.Fatalf("expected a reload after invalidation, got %d loads and %+v", calls, stats)
+	}
+
+	// A result loaded across an invalidation may predate the change, so it is not kept.
+	Fetch(ctx, cache, "stale", func(ctx context.Context) (int, error) {
+		cache.Invalidate(ctx)
+		return 1, nil
+	})
+	if entries, _ := cache.store.Len(ctx); entries != 0 {
+		t.Fatalf("expected the result loaded during invalidation dropped, got %d entries", entries)
+	}
+}
+
+func TestCache_StoreFailuresFallBackToLoad(t *testing.T) {
+	cache := New(failingStore{NewMemoryStore(10)}, StoreRedis, time.Minute)
+	got, err := Fetch(context.Background(), cache, "key", func(ctx context.Context) (int, error) { return 7, nil })
+	if err != nil || got != 7 || cache.Stats(context.Background()).Failures != 1 {
+		t.Fatalf("expected the loaded result with the failure counted, got %d (%v) %+v", got, err, cache.Stats(context.Background()))
+	}
+
+	var disabled *Cache
+	if got, err := Fetch(context.Background(), disabled, "key", func(ctx context.Context) (int, error) { return 8, nil }); err != nil || got != 8 {
+		t.Fatalf("expected a nil cache to load, got %d (%v)", got, err)
+	}
+	if New(NewMemoryStore(10), StoreMemory, 0) != nil {
+		t.Fatalf("expected a zero ttl to disable the cache")
+	}
+}
+
+func TestCache_RunInvalidatesOnEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache := New(NewMemoryStore(10), StoreMemory, time.Minute)
+	bus := events.NewBus()
+	go cache.Run(ctx, bus)
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats(ctx).Invalidations == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected upserted companies to invalidate the cache")
+		}
+		bus.Publish(ctx, events.CompaniesUpserted{Source: "api", Companies: 1})
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,85 @@
+package querycache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps entries in the memory of the process; past its size the least recently used
+// entry is dropped. Every replica has its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStore builds a store of at most size entries.
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{size: size, order: list.New(), entries: make(map[string]*list.Element), now: time.Now}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !s.now().Before(entry.expires) {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set implements Store.
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires := s.now().Add(ttl)
+	if element, ok := s.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value, entry.expires = value, expires
+		s.order.MoveToFront(element)
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Clear implements Store.
+func (s *MemoryStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order.Init()
+	s.entries = make(map[string]*list.Element)
+	return nil
+}
+
+// Len implements Store.
+func (s *MemoryStore) Len(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(s.order.Len()), nil
+}
+
+var _ Store = (*MemoryStore)(nil)
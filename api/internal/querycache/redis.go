@@ -0,0 +1,75 @@
+package querycache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps entries in one Redis hash shared by every replica, so clearing it is a single
+// DEL. Each entry carries its own expiry; the hash expires once no entry was written for a TTL.
+type RedisStore struct {
+	client *redis.Client
+	key    string
+	now    func() time.Time
+}
+
+// NewRedisStore builds a store keeping its entries in the hash at key.
+func NewRedisStore(client *redis.Client, key string) *RedisStore {
+	return &RedisStore{client: client, key: key, now: time.Now}
+}
+
+// Get implements Store. An expired entry is dropped.
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	stored, err := s.client.HGet(ctx, s.key, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read cached query: %w", err)
+	}
+	if len(stored) < 8 || s.now().UnixNano() >= int64(binary.BigEndian.Uint64(stored)) {
+		s.client.HDel(ctx, s.key, key)
+		return nil, false, nil
+	}
+	return stored[8:], true, nil
+}
+
+// Set implements Store. The value is stored behind its expiry in Unix nanoseconds.
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	stored := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(stored, uint64(s.now().Add(ttl).UnixNano()))
+	stored = append(stored, value...)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.key, key, stored)
+		pipe.PExpire(ctx, s.key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cache query: %w", err)
+	}
+	return nil
+}
+
+// Clear implements Store.
+func (s *RedisStore) Clear(ctx context.Context) error {
+	if err := s.client.Del(ctx, s.key).Err(); err != nil {
+		return fmt.Errorf("clear cached queries: %w", err)
+	}
+	return nil
+}
+
+// Len implements Store.
+func (s *RedisStore) Len(ctx context.Context) (int64, error) {
+	count, err := s.client.HLen(ctx, s.key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("count cached queries: %w", err)
+	}
+	return count, nil
+}
+
+var _ Store = (*RedisStore)(nil)
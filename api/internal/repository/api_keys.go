@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// APIKeysRepository persists the API keys of data providers.
+type APIKeysRepository interface {
+	Create(ctx context.Context, key *entity.APIKey, keyHash string) error
+	// List returns every key, revoked ones included, newest first.
+	List(ctx context.Context) ([]entity.APIKey, error)
+	// GetActiveByHash returns the unrevoked key whose hash is keyHash.
+	GetActiveByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) (*entity.APIKey, error)
+	// TouchLastUsed records that the key was used, at most once a minute.
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+// ErrAPIKeyNotFound indicates no key, or no unrevoked key, matches.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// PGXAPIKeysRepository implements APIKeysRepository using pgx.
+type PGXAPIKeysRepository struct {
+	pool pgxPool
+}
+
+// NewPGXAPIKeysRepository wires a pgx backed API keys repository.
+func NewPGXAPIKeysRepository(pool *pgxpool.Pool) *PGXAPIKeysRepository {
+	return &PGXAPIKeysRepository{pool: pool}
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, created_by, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row pgx.Row) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedBy, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Create inserts a key and populates its identifier and creation time.
+func (r *PGXAPIKeysRepository) Create(ctx context.Context, key *entity.APIKey, keyHash string) error {
+	if key == nil {
+		return fmt.Errorf("api key payload is nil")
+	}
+	if err := r.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, key.Name, key.Prefix, keyHash, key.Scopes, key.CreatedBy).Scan(&key.ID, &key.CreatedAt); err != nil {
+		return fmt.Errorf("insert api key: %w", err)
+	}
+	return nil
+}
+
+// List returns every key, revoked ones included, newest first.
+func (r *PGXAPIKeysRepository) List(ctx context.Context) ([]entity.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]entity.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// GetActiveByHash returns the unrevoked key whose hash is keyHash or ErrAPIKeyNotFound.
+func (r *PGXAPIKeysRepository) GetActiveByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	return r.one(ctx, "get api key", `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL
+	`, keyHash)
+}
+
+// Revoke marks a key revoked, keeping the time of a first revocation, or returns ErrAPIKeyNotFound.
+func (r *PGXAPIKeysRepository) Revoke(ctx context.Context, id uuid.UUID) (*entity.APIKey, error) {
+	return r.one(ctx, "revoke api key", `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+		RETURNING `+apiKeyColumns, id)
+}
+
+// TouchLastUsed records that the key was used; within a minute of the last record it writes nothing.
+func (r *PGXAPIKeysRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+	return nil
+}
+
+func (r *PGXAPIKeysRepository) one(ctx context.Context, action, query string, args ...any) (*entity.APIKey, error) {
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+	return key, nil
+}
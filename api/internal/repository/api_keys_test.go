@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestPGXAPIKeysRepository_GetActiveByHash(t *testing.T) {
+	var query string
+	repo := &PGXAPIKeysRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+			query = sql
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}}
+
+	if _, err := repo.GetActiveByHash(context.Background(), "hash"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+	if !strings.Contains(query, "revoked_at IS NULL") {
+		t.Fatalf("expected revoked keys excluded, got %s", query)
+	}
+}
+
+func TestPGXAPIKeysRepository_TouchLastUsed(t *testing.T) {
+	var query string
+	repo := &PGXAPIKeysRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+			query = sql
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		},
+	}}
+
+	if err := repo.TouchLastUsed(context.Background(), uuid.New()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "last_used_at < NOW() - INTERVAL '1 minute'") {
+		t.Fatalf("expected writes throttled to one a minute, got %s", query)
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Business category repository errors.
+var (
+	ErrBusinessCategoryNotFound  = errors.New("business category not found")
+	ErrBusinessCategoryDuplicate = errors.New("business category already exists")
+	// ErrBusinessCategoryParent is returned when the parent of a category does not exist.
+	ErrBusinessCategoryParent = errors.New("business category parent not found")
+)
+
+// BusinessCategoriesRepository persists the business category taxonomy.
+type BusinessCategoriesRepository interface {
+	List(ctx context.Context) ([]entity.BusinessCategory, error)
+	Create(ctx context.Context, category *entity.BusinessCategory) error
+	Update(ctx context.Context, category *entity.BusinessCategory) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PGXBusinessCategoriesRepository implements BusinessCategoriesRepository using pgx.
+type PGXBusinessCategoriesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXBusinessCategoriesRepository wires a pgx backed business categories repository.
+func NewPGXBusinessCategoriesRepository(pool *pgxpool.Pool) *PGXBusinessCategoriesRepository {
+	return &PGXBusinessCategoriesRepository{pool: pool}
+}
+
+// List returns every category ordered by name.
+func (r *PGXBusinessCategoriesRepository) List(ctx context.Context) ([]entity.BusinessCategory, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, label, parent_id, synonyms, created_at, updated_at
+		FROM business_categories
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list business categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]entity.BusinessCategory, 0)
+	for rows.Next() {
+		var category entity.BusinessCategory
+		if err := rows.Scan(&category.ID, &category.Name, &category.Label, &category.ParentID, &category.Synonyms, &category.CreatedAt, &category.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan business category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate business categories: %w", err)
+	}
+	return categories, nil
+}
+
+// Create inserts a category and populates its identifier and timestamps.
+func (r *PGXBusinessCategoriesRepository) Create(ctx context.Context, category *entity.BusinessCategory) error {
+	if category == nil {
+		return fmt.Errorf("business category payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO business_categories (name, label, parent_id, synonyms)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, category.Name, category.Label, category.ParentID, synonymsOrEmpty(category.Synonyms)).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if mapped := mapBusinessCategoryError(err); mapped != nil {
+			return mapped
+		}
+		return fmt.Errorf("insert business category: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites a category by id and refreshes its timestamps.
+func (r *PGXBusinessCategoriesRepository) Update(ctx context.Context, category *entity.BusinessCategory) error {
+	if category == nil {
+		return fmt.Errorf("business category payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		UPDATE business_categories
+		SET name = $1, label = $2, parent_id = $3, synonyms = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING created_at, updated_at
+	`, category.Name, category.Label, category.ParentID, synonymsOrEmpty(category.Synonyms), category.ID).Scan(&category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrBusinessCategoryNotFound
+		}
+		if mapped := mapBusinessCategoryError(err); mapped != nil {
+			return mapped
+		}
+		return fmt.Errorf("update business category: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a category by id; its children become top-level categories.
+func (r *PGXBusinessCategoriesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM business_categories WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete business category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBusinessCategoryNotFound
+	}
+	return nil
+}
+
+func synonymsOrEmpty(synonyms []string) []string {
+	if synonyms == nil {
+		return []string{}
+	}
+	return synonyms
+}
+
+// mapBusinessCategoryError maps a duplicate name or a missing parent to its sentinel, or returns
+// nil for other errors.
+func mapBusinessCategoryError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch {
+	case pgErr.Code == "23505" && pgErr.ConstraintName == "business_categories_name_key":
+		return fmt.Errorf("%w: %v", ErrBusinessCategoryDuplicate, err)
+	case pgErr.Code == "23503":
+		return fmt.Errorf("%w: %v", ErrBusinessCategoryParent, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXBusinessCategoriesRepository_CreateErrors(t *testing.T) {
+	tests := map[string]struct {
+		pgErr *pgconn.PgError
+		want  error
+	}{
+		"duplicate name": {&pgconn.PgError{Code: "23505", ConstraintName: "business_categories_name_key"}, ErrBusinessCategoryDuplicate},
+		"unknown parent": {&pgconn.PgError{Code: "23503", ConstraintName: "business_categories_parent_id_fkey"}, ErrBusinessCategoryParent},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool := &stubPool{
+				queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+					if synonyms, ok := args[3].([]string); !ok || synonyms == nil {
+						t.Fatalf("expected synonyms bound as an empty array, got %#v", args[3])
+					}
+					return &stubRow{scan: func(dest ...any) error { return tt.pgErr }}
+				},
+			}
+			repo := &PGXBusinessCategoriesRepository{pool: pool}
+
+			err := repo.Create(context.Background(), &entity.BusinessCategory{Name: "restaurant", Label: "Restaurant"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPGXBusinessCategoriesRepository_NotFound(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		},
+	}
+	repo := &PGXBusinessCategoriesRepository{pool: pool}
+
+	if err := repo.Update(context.Background(), &entity.BusinessCategory{ID: uuid.New()}); !errors.Is(err, ErrBusinessCategoryNotFound) {
+		t.Fatalf("expected ErrBusinessCategoryNotFound on update, got %v", err)
+	}
+	if err := repo.Delete(context.Background(), uuid.New()); !errors.Is(err, ErrBusinessCategoryNotFound) {
+		t.Fatalf("expected ErrBusinessCategoryNotFound on delete, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ClosureReportFilter narrows the newly closed report to a time window and area.
+type ClosureReportFilter struct {
+	Since   time.Time
+	Country string
+	City    string
+	Limit   int
+}
+
+// BusinessStatusRepository reports on opening/closure status transitions.
+type BusinessStatusRepository interface {
+	ListNewlyClosedAreas(ctx context.Context, filter ClosureReportFilter) ([]entity.ClosureArea, error)
+}
+
+// PGXBusinessStatusRepository implements BusinessStatusRepository using pgx.
+type PGXBusinessStatusRepository struct {
+	pool pgxPool
+}
+
+// NewPGXBusinessStatusRepository wires a pgx backed business status repository.
+func NewPGXBusinessStatusRepository(pool *pgxpool.Pool) *PGXBusinessStatusRepository {
+	return &PGXBusinessStatusRepository{pool: pool}
+}
+
+// ListNewlyClosedAreas groups companies whose status changed to a closed value since filter.Since
+// by country and city. status_changed_at is maintained by the capture_business_status trigger.
+func (r *PGXBusinessStatusRepository) ListNewlyClosedAreas(ctx context.Context, filter ClosureReportFilter) ([]entity.ClosureArea, error) {
+	clauses := []string{
+		"business_status IN ('closed_temporarily', 'closed_permanently')",
+		"status_changed_at >= $1",
+	}
+	args := []any{filter.Since}
+	idx := 2
+
+	if filter.Country != "" {
+		clauses = append(clauses, fmt.Sprintf("LOWER(country) = LOWER($%d)", idx))
+		args = append(args, filter.Country)
+		idx++
+	}
+	if filter.City != "" {
+		clauses = append(clauses, fmt.Sprintf("LOWER(city) = LOWER($%d)", idx))
+		args = append(args, filter.City)
+		idx++
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(country, ''),
+			COALESCE(city, ''),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE business_status = 'closed_permanently'),
+			COUNT(*) FILTER (WHERE business_status = 'closed_temporarily'),
+			MAX(status_changed_at)
+		FROM companies
+		WHERE %s
+		GROUP BY COALESCE(country, ''), COALESCE(city, '')
+		ORDER BY COUNT(*) DESC, COALESCE(country, ''), COALESCE(city, '')
+		LIMIT $%d
+	`, strings.Join(clauses, " AND "), idx)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list newly closed areas: %w", err)
+	}
+	defer rows.Close()
+
+	areas := make([]entity.ClosureArea, 0)
+	for rows.Next() {
+		var area entity.ClosureArea
+		if err := rows.Scan(
+			&area.Country,
+			&area.City,
+			&area.NewlyClosed,
+			&area.ClosedPermanently,
+			&area.ClosedTemporarily,
+			&area.LastClosedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan closure area: %w", err)
+		}
+		areas = append(areas, area)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate closure areas: %w", err)
+	}
+	return areas, nil
+}
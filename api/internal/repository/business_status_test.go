@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXBusinessStatusRepository_ListNewlyClosedAreas(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	repo := &PGXBusinessStatusRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "LOWER(city) = LOWER($2)") || !strings.Contains(query, "LIMIT $3") {
+				t.Fatalf("unexpected query: %s", query)
+			}
+			if len(args) != 3 || args[0] != since || args[1] != "Jakarta" || args[2] != 10 {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "Indonesia"
+					*dest[1].(*string) = "Jakarta"
+					*dest[2].(*int) = 3
+					*dest[3].(*int) = 2
+					*dest[4].(*int) = 1
+					*dest[5].(*time.Time) = since.Add(time.Hour)
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	areas, err := repo.ListNewlyClosedAreas(context.Background(), ClosureReportFilter{Since: since, City: "Jakarta", Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(areas) != 1 || areas[0].NewlyClosed != 3 || areas[0].ClosedPermanently != 2 {
+		t.Fatalf("unexpected areas: %+v", areas)
+	}
+}
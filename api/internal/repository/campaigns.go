@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Campaign repository errors.
+var (
+	ErrCampaignNotFound       = errors.New("campaign not found")
+	ErrCampaignNotDraft       = errors.New("campaign has already been sent")
+	ErrCampaignFilterRequired = errors.New("campaign needs at least one filter")
+)
+
+// CampaignsRepository persists outreach campaigns, the delivery status of their recipients and the
+// suppression list.
+type CampaignsRepository interface {
+	CreateCampaign(ctx context.Context, campaign *entity.Campaign) error
+	GetCampaign(ctx context.Context, id uuid.UUID) (*entity.Campaign, error)
+	ListCampaigns(ctx context.Context, limit, offset int) ([]entity.Campaign, error)
+	QueueCampaign(ctx context.Context, id uuid.UUID, recipients []entity.CampaignRecipient) error
+	ListCampaignRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]entity.CampaignRecipient, error)
+	ClaimCampaignDeliveries(ctx context.Context, limit int) ([]entity.CampaignDelivery, error)
+	MarkRecipientSent(ctx context.Context, id uuid.UUID, tokenHash, messageID string) error
+	MarkRecipientFailed(ctx context.Context, id uuid.UUID, message string) error
+	FinishCampaigns(ctx context.Context) (int64, error)
+	MarkRecipientOpened(ctx context.Context, tokenHash string) (bool, error)
+	MarkRecipientUndeliverable(ctx context.Context, tokenHash, status, reason string) (bool, error)
+	SuppressEmail(ctx context.Context, suppression *entity.ContactSuppression) error
+}
+
+// CampaignLeadsRepository finds the companies a campaign mails.
+type CampaignLeadsRepository interface {
+	ListCampaignLeads(ctx context.Context, filter dto.ListFilter, limit int) ([]entity.CampaignLead, error)
+}
+
+// PGXCampaignsRepository implements CampaignsRepository using pgx.
+type PGXCampaignsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXCampaignsRepository wires a pgx backed campaigns repository.
+func NewPGXCampaignsRepository(pool *pgxpool.Pool) *PGXCampaignsRepository {
+	return &PGXCampaignsRepository{pool: pool}
+}
+
+// campaignColumns lists the columns scanCampaign expects; counts is the recipients per status.
+const campaignColumns = `
+	id, name, query, filter, subject, body, status, created_by, created_at, updated_at, started_at, finished_at,
+	(SELECT COALESCE(jsonb_object_agg(status, n), '{}'::jsonb)
+		FROM (SELECT status, COUNT(*) AS n FROM campaign_recipients r WHERE r.campaign_id = campaigns.id GROUP BY status) s
+	) AS counts`
+
+// CreateCampaign inserts a draft campaign and populates its id and timestamps.
+func (r *PGXCampaignsRepository) CreateCampaign(ctx context.Context, campaign *entity.Campaign) error {
+	if campaign == nil {
+		return errors.New("campaign is nil")
+	}
+	filter := campaign.Filter
+	if len(filter) == 0 {
+		filter = []byte("{}")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO campaigns (name, query, filter, subject, body, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`, campaign.Name, campaign.Query, filter, campaign.Subject, campaign.Body, entity.CampaignStatusDraft, campaign.CreatedBy).
+		Scan(&campaign.ID, &campaign.CreatedAt, &campaign.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create campaign: %w", err)
+	}
+	campaign.Status = entity.CampaignStatusDraft
+	campaign.Counts = map[string]int64{}
+	return nil
+}
+
+// GetCampaign returns a campaign with its recipient counts.
+func (r *PGXCampaignsRepository) GetCampaign(ctx context.Context, id uuid.UUID) (*entity.Campaign, error) {
+	campaign, err := scanCampaign(r.pool.QueryRow(ctx, `SELECT `+campaignColumns+` FROM campaigns WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, fmt.Errorf("get campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// ListCampaigns returns campaigns, newest first.
+func (r *PGXCampaignsRepository) ListCampaigns(ctx context.Context, limit, offset int) ([]entity.Campaign, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+campaignColumns+`
+		FROM campaigns
+		ORDER BY created_at DESC, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := make([]entity.Campaign, 0)
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan campaign: %w", err)
+		}
+		campaigns = append(campaigns, *campaign)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate campaigns: %w", err)
+	}
+	return campaigns, nil
+}
+
+func scanCampaign(row pgx.Row) (*entity.Campaign, error) {
+	var (
+		campaign entity.Campaign
+		counts   []byte
+	)
+	if err := row.Scan(
+		&campaign.ID, &campaign.Name, &campaign.Query, &campaign.Filter, &campaign.Subject, &campaign.Body,
+		&campaign.Status, &campaign.CreatedBy, &campaign.CreatedAt, &campaign.UpdatedAt,
+		&campaign.StartedAt, &campaign.FinishedAt, &counts,
+	); err != nil {
+		return nil, err
+	}
+	campaign.Counts = map[string]int64{}
+	if len(counts) > 0 {
+		if err := json.Unmarshal(counts, &campaign.Counts); err != nil {
+			return nil, fmt.Errorf("decode campaign counts: %w", err)
+		}
+	}
+	return &campaign, nil
+}
+
+// QueueCampaign adds the recipients of a draft campaign and starts sending it, in one transaction.
+// Recipients given as suppressed, or whose email or its domain is on the do-not-contact list, are
+// recorded as suppressed, and an email appearing twice is only mailed once.
+func (r *PGXCampaignsRepository) QueueCampaign(ctx context.Context, id uuid.UUID, recipients []entity.CampaignRecipient) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin queue campaign: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM campaigns WHERE id = $1 FOR UPDATE`, id).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCampaignNotFound
+		}
+		return fmt.Errorf("lock campaign: %w", err)
+	}
+	if status != entity.CampaignStatusDraft {
+		return ErrCampaignNotDraft
+	}
+
+	companyIDs := make([]*uuid.UUID, len(recipients))
+	emails := make([]string, len(recipients))
+	variables := make([]string, len(recipients))
+	suppressed := make([]bool, len(recipients))
+	for i, recipient := range recipients {
+		companyIDs[i] = recipient.CompanyID
+		emails[i] = recipient.Email
+		suppressed[i] = recipient.Status == entity.RecipientStatusSuppressed
+		encoded, err := json.Marshal(recipient.Variables)
+		if err != nil {
+			return fmt.Errorf("encode recipient variables: %w", err)
+		}
+		variables[i] = string(encoded)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO campaign_recipients (campaign_id, company_id, email, variables, status)
+		SELECT $1, l.company_id, l.email, l.variables::jsonb,
+			CASE WHEN l.suppressed OR `+suppressedEmailSQL("l.email")+` THEN $6 ELSE $7 END
+		FROM unnest($2::uuid[], $3::text[], $4::text[], $5::boolean[]) WITH ORDINALITY AS l(company_id, email, variables, suppressed, n)
+		ORDER BY l.n
+		ON CONFLICT (campaign_id, email) DO NOTHING
+	`, id, companyIDs, emails, variables, suppressed, entity.RecipientStatusSuppressed, entity.RecipientStatusQueued); err != nil {
+		return fmt.Errorf("queue campaign recipients: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE campaigns SET status = $2, started_at = NOW(), updated_at = NOW() WHERE id = $1
+	`, id, entity.CampaignStatusSending); err != nil {
+		return fmt.Errorf("start campaign: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit queue campaign: %w", err)
+	}
+	return nil
+}
+
+const campaignRecipientColumns = `id, campaign_id, company_id, email, variables, status, message_id, error,
+	sent_at, opened_at, bounced_at, created_at, updated_at`
+
+// ListCampaignRecipients returns the recipients of a campaign in queue order, only those with
+// status when it is set.
+func (r *PGXCampaignsRepository) ListCampaignRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]entity.CampaignRecipient, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+campaignRecipientColumns+`
+		FROM campaign_recipients
+		WHERE campaign_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`, campaignID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list campaign recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := make([]entity.CampaignRecipient, 0)
+	for rows.Next() {
+		var recipient entity.CampaignRecipient
+		if err := scanCampaignRecipient(rows, &recipient); err != nil {
+			return nil, fmt.Errorf("scan campaign recipient: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate campaign recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+func scanCampaignRecipient(row pgx.Row, recipient *entity.CampaignRecipient, extra ...any) error {
+	var variables []byte
+	dest := []any{
+		&recipient.ID, &recipient.CampaignID, &recipient.CompanyID, &recipient.Email, &variables,
+		&recipient.Status, &recipient.MessageID, &recipient.Error, &recipient.SentAt, &recipient.OpenedAt,
+		&recipient.BouncedAt, &recipient.CreatedAt, &recipient.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	recipient.Variables = map[string]string{}
+	if len(variables) > 0 {
+		if err := json.Unmarshal(variables, &recipient.Variables); err != nil {
+			return fmt.Errorf("decode recipient variables: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClaimCampaignDeliveries moves up to limit queued recipients of sending campaigns to sending and
+// returns them with the template of their campaign. Recipients suppressed since they were queued
+// are moved to suppressed instead and returned with that status, so they are skipped. Claims skip
+// rows another instance holds, so two API instances never send the same message.
+func (r *PGXCampaignsRepository) ClaimCampaignDeliveries(ctx context.Context, limit int) ([]entity.CampaignDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH claimed AS (
+			SELECT r.id
+			FROM campaign_recipients r
+			JOIN campaigns c ON c.id = r.campaign_id
+			WHERE r.status = $2 AND c.status = $3
+			ORDER BY r.created_at, r.id
+			LIMIT $1
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		UPDATE campaign_recipients r
+		SET status = CASE WHEN `+suppressedEmailSQL("r.email")+` THEN $4 ELSE $5 END,
+			updated_at = NOW()
+		FROM claimed, campaigns c
+		WHERE r.id = claimed.id AND c.id = r.campaign_id
+		RETURNING r.id, r.campaign_id, r.company_id, r.email, r.variables, r.status, r.message_id, r.error,
+			r.sent_at, r.opened_at, r.bounced_at, r.created_at, r.updated_at, c.subject, c.body
+	`, limit, entity.RecipientStatusQueued, entity.CampaignStatusSending, entity.RecipientStatusSuppressed, entity.RecipientStatusSending)
+	if err != nil {
+		return nil, fmt.Errorf("claim campaign deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.CampaignDelivery, 0)
+	for rows.Next() {
+		var delivery entity.CampaignDelivery
+		if err := scanCampaignRecipient(rows, &delivery.Recipient, &delivery.Subject, &delivery.Body); err != nil {
+			return nil, fmt.Errorf("scan campaign delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate campaign deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// MarkRecipientSent records that a message was handed to the provider, with the hash of the token
+// its tracking links carry.
+func (r *PGXCampaignsRepository) MarkRecipientSent(ctx context.Context, id uuid.UUID, tokenHash, messageID string) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE campaign_recipients
+		SET status = $2, token_hash = $3, message_id = NULLIF($4, ''), error = NULL, sent_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, entity.RecipientStatusSent, tokenHash, messageID); err != nil {
+		return fmt.Errorf("mark recipient sent: %w", err)
+	}
+	return nil
+}
+
+// MarkRecipientFailed records that the provider refused a message.
+func (r *PGXCampaignsRepository) MarkRecipientFailed(ctx context.Context, id uuid.UUID, message string) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE campaign_recipients SET status = $2, error = $3, updated_at = NOW() WHERE id = $1
+	`, id, entity.RecipientStatusFailed, message); err != nil {
+		return fmt.Errorf("mark recipient failed: %w", err)
+	}
+	return nil
+}
+
+// FinishCampaigns marks sending campaigns without queued recipients as sent and returns how many
+// were finished. A recipient whose send was interrupted stays sending rather than risk a second
+// message, and does not hold its campaign back.
+func (r *PGXCampaignsRepository) FinishCampaigns(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE campaigns c
+		SET status = $1, finished_at = NOW(), updated_at = NOW()
+		WHERE c.status = $2 AND NOT EXISTS (
+			SELECT 1 FROM campaign_recipients r WHERE r.campaign_id = c.id AND r.status = $3
+		)
+	`, entity.CampaignStatusSent, entity.CampaignStatusSending, entity.RecipientStatusQueued)
+	if err != nil {
+		return 0, fmt.Errorf("finish campaigns: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// MarkRecipientOpened records the first open of a message and reports whether the token matched.
+// Only sent messages move to opened; a later bounce or unsubscribe is kept.
+func (r *PGXCampaignsRepository) MarkRecipientOpened(ctx context.Context, tokenHash string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE campaign_recipients
+		SET opened_at = COALESCE(opened_at, NOW()),
+			status = CASE WHEN status = $2 THEN $3 ELSE status END,
+			updated_at = NOW()
+		WHERE token_hash = $1
+	`, tokenHash, entity.RecipientStatusSent, entity.RecipientStatusOpened)
+	if err != nil {
+		return false, fmt.Errorf("mark recipient opened: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkRecipientUndeliverable moves the recipient of a token to status, a bounce, complaint or
+// unsubscribe, and adds its email to the do-not-contact list with reason. It reports whether the
+// token matched.
+func (r *PGXCampaignsRepository) MarkRecipientUndeliverable(ctx context.Context, tokenHash, status, reason string) (bool, error) {
+	var matched int64
+	err := r.pool.QueryRow(ctx, `
+		WITH updated AS (
+			UPDATE campaign_recipients
+			SET status = $2,
+				bounced_at = CASE WHEN $2 = $4 THEN COALESCE(bounced_at, NOW()) ELSE bounced_at END,
+				updated_at = NOW()
+			WHERE token_hash = $1
+			RETURNING campaign_id, email
+		), suppressed AS (
+			INSERT INTO contact_suppressions (kind, value, reason, campaign_id)
+			SELECT 'email', email, $3, campaign_id FROM updated
+			ON CONFLICT (kind, value) DO NOTHING
+		)
+		SELECT COUNT(*) FROM updated
+	`, tokenHash, status, reason, entity.RecipientStatusBounced).Scan(&matched)
+	if err != nil {
+		return false, fmt.Errorf("mark recipient %s: %w", status, err)
+	}
+	return matched > 0, nil
+}
+
+// SuppressEmail adds an email to the do-not-contact list. An email already listed keeps its
+// original entry, which suppression is populated with.
+func (r *PGXCampaignsRepository) SuppressEmail(ctx context.Context, suppression *entity.ContactSuppression) error {
+	if suppression == nil {
+		return errors.New("email suppression is nil")
+	}
+	suppression.Kind = entity.SuppressionKindEmail
+	err := scanContactSuppression(r.pool.QueryRow(ctx, `
+		INSERT INTO contact_suppressions (kind, value, reason, note, campaign_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (kind, value) DO UPDATE SET value = EXCLUDED.value
+		RETURNING `+contactSuppressionColumns+`
+	`, suppression.Kind, suppression.Value, suppression.Reason, suppression.Note, suppression.CampaignID, suppression.CreatedBy), suppression)
+	if err != nil {
+		return fmt.Errorf("suppress email: %w", err)
+	}
+	return nil
+}
+
+// ListCampaignLeads returns up to limit companies matching filter that hold an enrichment or
+// website email, ignoring pagination, with their decrypted emails and the values of the campaign
+// template variables. A filter narrowing nothing is refused.
+func (r *PGXCompaniesRepository) ListCampaignLeads(ctx context.Context, filter dto.ListFilter, limit int) ([]entity.CampaignLead, error) {
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(where.clauses) == 0 {
+		return nil, ErrCampaignFilterRequired
+	}
+	// The clauses are concatenated, not formatted, as they may hold a literal %.
+	query := `
+		SELECT c.id, COALESCE(c.display_name, c.company), COALESCE(c.city, ''), COALESCE(c.country, ''),
+			COALESCE(c.type_business, ''), COALESCE(c.website, ''),
+			COALESCE(e.emails, ARRAY[]::TEXT[]), COALESCE(w.emails, ARRAY[]::TEXT[])
+		FROM companies c
+		LEFT JOIN company_enrichments e ON e.company_id = c.id
+		LEFT JOIN website_enriched_contacts w ON w.company_id = c.id
+		WHERE c.id IN (SELECT id FROM companies` + where.where() + `)
+			AND (cardinality(e.emails) > 0 OR cardinality(w.emails) > 0)
+		ORDER BY c.id
+		LIMIT ` + fmt.Sprintf("$%d", where.next)
+
+	rows, err := r.reader().Query(ctx, query, append(where.args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("list campaign leads: %w", err)
+	}
+	defer rows.Close()
+
+	leads := make([]entity.CampaignLead, 0)
+	for rows.Next() {
+		var (
+			lead                                      entity.CampaignLead
+			company, city, country, business, website string
+			enrichment, contacts                      []string
+		)
+		if err := rows.Scan(&lead.CompanyID, &company, &city, &country, &business, &website, &enrichment, &contacts); err != nil {
+			return nil, fmt.Errorf("scan campaign lead: %w", err)
+		}
+		decrypted, err := r.cipher.DecryptAll(append(enrichment, contacts...))
+		if err != nil {
+			return nil, fmt.Errorf("decrypt emails of %s: %w", lead.CompanyID, err)
+		}
+		lead.Emails = decrypted
+		lead.Variables = map[string]string{
+			"company":       company,
+			"city":          city,
+			"country":       country,
+			"type_business": business,
+			"website":       website,
+		}
+		leads = append(leads, lead)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate campaign leads: %w", err)
+	}
+	return leads, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+func TestPGXCampaignsRepository_QueueCampaign(t *testing.T) {
+	status := entity.CampaignStatusSending
+	var statements []string
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*string) = status
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			statements = append(statements, query)
+			return pgconn.CommandTag{}, nil
+		},
+	}
+	repo := &PGXCampaignsRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+	companyID := uuid.New()
+	recipients := []entity.CampaignRecipient{{CompanyID: &companyID, Email: "info@example.com", Variables: map[string]string{"company": "Kopi"}}}
+
+	if err := repo.QueueCampaign(context.Background(), uuid.New(), recipients); !errors.Is(err, ErrCampaignNotDraft) {
+		t.Fatalf("expected a started campaign to be refused, got %v", err)
+	}
+	if len(statements) != 0 || tx.committed {
+		t.Fatalf("expected nothing written, got %v", statements)
+	}
+
+	status = entity.CampaignStatusDraft
+	if err := repo.QueueCampaign(context.Background(), uuid.New(), recipients); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statements) != 2 || !strings.Contains(statements[0], "contact_suppressions") || !strings.Contains(statements[0], "ON CONFLICT (campaign_id, email) DO NOTHING") || !tx.committed {
+		t.Fatalf("unexpected statements %v", statements)
+	}
+}
+
+func TestPGXCompaniesRepository_ListCampaignLeads(t *testing.T) {
+	cipher, _ := fieldcrypt.New(bytes.Repeat([]byte{7}, fieldcrypt.KeySize))
+	encrypted, err := cipher.Encrypt("info@example.com")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	companyID := uuid.New()
+	var query string
+	var args []any
+	repo := &PGXCompaniesRepository{cipher: cipher, pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, a ...any) (pgx.Rows, error) {
+			query, args = q, a
+			return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = companyID
+				*dest[1].(*string) = "Kopi Kita"
+				*dest[2].(*string) = "Bandung"
+				*dest[7].(*[]string) = []string{"sales@example.com"}
+				*dest[6].(*[]string) = []string{encrypted}
+				return nil
+			}}}, nil
+		},
+	}}
+
+	if _, err := repo.ListCampaignLeads(context.Background(), dto.ListFilter{}, 10); !errors.Is(err, ErrCampaignFilterRequired) {
+		t.Fatalf("expected a campaign of every company to be refused, got %v", err)
+	}
+
+	leads, err := repo.ListCampaignLeads(context.Background(), dto.ListFilter{City: "Bandung"}, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(args) != 2 || args[0] != "Bandung" || args[1] != 10 || !strings.Contains(query, "LIMIT $2") {
+		t.Fatalf("unexpected query %s with args %v", query, args)
+	}
+	if len(leads) != 1 || leads[0].CompanyID != companyID || leads[0].Variables["company"] != "Kopi Kita" || leads[0].Variables["city"] != "Bandung" {
+		t.Fatalf("unexpected leads %+v", leads)
+	}
+	if got := leads[0].Emails; len(got) != 2 || got[0] != "info@example.com" || got[1] != "sales@example.com" {
+		t.Fatalf("expected decrypted enrichment emails before website emails, got %v", got)
+	}
+}
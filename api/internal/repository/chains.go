@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrChainNotFound indicates the requested chain does not exist.
+var ErrChainNotFound = errors.New("chain not found")
+
+// ChainDetectionResult summarises a chain detection pass.
+type ChainDetectionResult struct {
+	Chains           int   `json:"chains"`
+	CompaniesUpdated int64 `json:"companies_updated"`
+}
+
+// ChainsRepository detects franchise/chain groupings and serves their rollups.
+type ChainsRepository interface {
+	DetectChains(ctx context.Context, minCities int, sharedHosts []string) (ChainDetectionResult, error)
+	ListChains(ctx context.Context, limit, offset int) ([]entity.CompanyChain, error)
+	GetChainRollup(ctx context.Context, id uuid.UUID) (*entity.ChainRollup, error)
+}
+
+// PGXChainsRepository implements ChainsRepository using pgx.
+type PGXChainsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXChainsRepository wires a pgx backed chains repository.
+func NewPGXChainsRepository(pool *pgxpool.Pool) *PGXChainsRepository {
+	return &PGXChainsRepository{pool: pool}
+}
+
+// chainKeysCTE derives one grouping key per company: the website host when it is not a shared
+// host (social networks, link shorteners), otherwise the lowercase alphanumeric display name, so
+// "PT Maju Jaya" and "Maju Jaya" are one chain.
+const chainKeysCTE = `
+	WITH hosts AS (
+		SELECT
+			id,
+			company,
+			city,
+			NULLIF(substring(lower(btrim(website)) from '^(?:[a-z][a-z0-9+.-]*://)?(?:www\.)?([^/:?#]+)'), '') AS domain,
+			NULLIF(btrim(regexp_replace(lower(COALESCE(display_name, company)), '[^[:alnum:]]+', ' ', 'g')), '') AS name_key
+		FROM companies
+	), keyed AS (
+		SELECT
+			id,
+			company,
+			city,
+			CASE WHEN domain IS NOT NULL AND domain <> ALL($1::text[]) THEN domain END AS domain,
+			CASE
+				WHEN domain IS NOT NULL AND domain <> ALL($1::text[]) THEN 'domain:' || domain
+				WHEN name_key IS NOT NULL THEN 'name:' || name_key
+			END AS chain_key
+		FROM hosts
+	)
+`
+
+// DetectChains regroups every company into chains spanning at least minCities distinct cities.
+// Chains that no longer qualify are removed and their companies revert to independents.
+func (r *PGXChainsRepository) DetectChains(ctx context.Context, minCities int, sharedHosts []string) (ChainDetectionResult, error) {
+	var result ChainDetectionResult
+	if minCities < 2 {
+		return result, fmt.Errorf("min cities must be at least 2, got %d", minCities)
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return result, fmt.Errorf("start chain detection tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	upsertSQL := chainKeysCTE + `
+		INSERT INTO company_chains (chain_key, name, domain, location_count, city_count, updated_at)
+		SELECT
+			chain_key,
+			mode() WITHIN GROUP (ORDER BY company),
+			MAX(domain),
+			COUNT(*),
+			COUNT(DISTINCT LOWER(city)),
+			NOW()
+		FROM keyed
+		WHERE chain_key IS NOT NULL
+		GROUP BY chain_key
+		HAVING COUNT(DISTINCT LOWER(city)) >= $2
+		ON CONFLICT (chain_key) DO UPDATE SET
+			name = EXCLUDED.name,
+			domain = EXCLUDED.domain,
+			location_count = EXCLUDED.location_count,
+			city_count = EXCLUDED.city_count,
+			updated_at = NOW()
+	`
+	if _, err := tx.Exec(ctx, upsertSQL, stringSliceOrEmpty(sharedHosts), minCities); err != nil {
+		return result, fmt.Errorf("upsert chains: %w", err)
+	}
+
+	// NOW() is fixed for the transaction, so chains untouched by the upsert are stale.
+	if _, err := tx.Exec(ctx, `DELETE FROM company_chains WHERE updated_at < NOW()`); err != nil {
+		return result, fmt.Errorf("delete stale chains: %w", err)
+	}
+
+	assignSQL := chainKeysCTE + `
+		UPDATE companies c
+		SET chain_id = m.chain_id
+		FROM (
+			SELECT k.id, cc.id AS chain_id
+			FROM keyed k
+			LEFT JOIN company_chains cc ON cc.chain_key = k.chain_key
+		) m
+		WHERE c.id = m.id AND c.chain_id IS DISTINCT FROM m.chain_id
+	`
+	tag, err := tx.Exec(ctx, assignSQL, stringSliceOrEmpty(sharedHosts))
+	if err != nil {
+		return result, fmt.Errorf("assign companies to chains: %w", err)
+	}
+	result.CompaniesUpdated = tag.RowsAffected()
+
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM company_chains`).Scan(&result.Chains); err != nil {
+		return result, fmt.Errorf("count chains: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("commit chain detection: %w", err)
+	}
+	return result, nil
+}
+
+// ListChains returns chains ordered by their number of locations.
+func (r *PGXChainsRepository) ListChains(ctx context.Context, limit, offset int) ([]entity.CompanyChain, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, chain_key, name, domain, location_count, city_count, created_at, updated_at
+		FROM company_chains
+		ORDER BY location_count DESC, name ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list chains: %w", err)
+	}
+	defer rows.Close()
+
+	chains := make([]entity.CompanyChain, 0)
+	for rows.Next() {
+		chain, err := scanChain(rows)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, *chain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate chains: %w", err)
+	}
+	return chains, nil
+}
+
+// GetChainRollup returns a chain together with metrics aggregated over its locations.
+func (r *PGXChainsRepository) GetChainRollup(ctx context.Context, id uuid.UUID) (*entity.ChainRollup, error) {
+	chain, err := scanChain(r.pool.QueryRow(ctx, `
+		SELECT id, chain_key, name, domain, location_count, city_count, created_at, updated_at
+		FROM company_chains
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChainNotFound
+		}
+		return nil, err
+	}
+
+	rollup := &entity.ChainRollup{Chain: *chain, Countries: []string{}, Cities: []entity.ChainCityCount{}}
+
+	var (
+		avgRating sql.NullFloat64
+		countries []string
+	)
+	err = r.pool.QueryRow(ctx, `
+		SELECT
+			AVG(rating)::float8,
+			COALESCE(SUM(reviews), 0),
+			COALESCE(ARRAY_AGG(DISTINCT country) FILTER (WHERE country IS NOT NULL), ARRAY[]::TEXT[])
+		FROM companies
+		WHERE chain_id = $1
+	`, id).Scan(&avgRating, &rollup.TotalReviews, &countries)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate chain metrics: %w", err)
+	}
+	if avgRating.Valid {
+		val := avgRating.Float64
+		rollup.AverageRating = &val
+	}
+	if len(countries) > 0 {
+		rollup.Countries = countries
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT COALESCE(city, ''), COUNT(*)
+		FROM companies
+		WHERE chain_id = $1
+		GROUP BY COALESCE(city, '')
+		ORDER BY COUNT(*) DESC, COALESCE(city, '') ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate chain cities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var city entity.ChainCityCount
+		if err := rows.Scan(&city.City, &city.Locations); err != nil {
+			return nil, fmt.Errorf("scan chain city: %w", err)
+		}
+		rollup.Cities = append(rollup.Cities, city)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate chain cities: %w", err)
+	}
+
+	return rollup, nil
+}
+
+func scanChain(row pgx.Row) (*entity.CompanyChain, error) {
+	var (
+		chain  entity.CompanyChain
+		domain sql.NullString
+	)
+	err := row.Scan(
+		&chain.ID,
+		&chain.ChainKey,
+		&chain.Name,
+		&domain,
+		&chain.LocationCount,
+		&chain.CityCount,
+		&chain.CreatedAt,
+		&chain.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan chain: %w", err)
+	}
+	chain.Domain = nullStringToPtr(domain)
+	return &chain, nil
+}
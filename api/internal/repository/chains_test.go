@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPGXChainsRepository_DetectChainsValidation(t *testing.T) {
+	repo := &PGXChainsRepository{pool: &stubPool{}}
+	if _, err := repo.DetectChains(context.Background(), 1, nil); err == nil {
+		t.Fatalf("expected error for min cities below 2")
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ChangeEventsRepository reads the change history recorded by database triggers.
+type ChangeEventsRepository interface {
+	ListChangesAfter(ctx context.Context, after entity.ChangeCursor, limit int) ([]entity.ChangeEvent, error)
+}
+
+// PGXChangeEventsRepository implements ChangeEventsRepository using pgx.
+type PGXChangeEventsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXChangeEventsRepository wires a pgx backed change events repository.
+func NewPGXChangeEventsRepository(pool *pgxpool.Pool) *PGXChangeEventsRepository {
+	return &PGXChangeEventsRepository{pool: pool}
+}
+
+// listChangesAfterSQL reads events after a cursor in (tx_id, id) order. Events of transactions that
+// may still be followed by a lower-ordered commit (anything not older than the snapshot xmin) are
+// held back, so a cursor handed on never skips an event.
+const listChangesAfterSQL = `
+	SELECT tx_id::text, id, entity, entity_id, operation, data, occurred_at
+	FROM change_events
+	WHERE (tx_id, id) > ($1::text::xid8, $2)
+		AND tx_id < pg_snapshot_xmin(pg_current_snapshot())
+	ORDER BY tx_id, id
+	LIMIT $3
+`
+
+// ListChangesAfter returns events after the cursor, holding back those of transactions that may
+// still be followed by an earlier commit.
+func (r *PGXChangeEventsRepository) ListChangesAfter(ctx context.Context, after entity.ChangeCursor, limit int) ([]entity.ChangeEvent, error) {
+	rows, err := r.pool.Query(ctx, listChangesAfterSQL, strconv.FormatUint(after.TxID, 10), after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list change events: %w", err)
+	}
+	return scanChangeEvents(rows)
+}
+
+func scanChangeEvents(rows pgx.Rows) ([]entity.ChangeEvent, error) {
+	defer rows.Close()
+
+	events := make([]entity.ChangeEvent, 0)
+	for rows.Next() {
+		var (
+			event entity.ChangeEvent
+			txID  string
+			data  []byte
+			err   error
+		)
+		if err := rows.Scan(&txID, &event.Cursor.ID, &event.Entity, &event.EntityID, &event.Operation, &data, &event.OccurredAt); err != nil {
+			return nil, fmt.Errorf("scan change event: %w", err)
+		}
+		if event.Cursor.TxID, err = strconv.ParseUint(txID, 10, 64); err != nil {
+			return nil, fmt.Errorf("parse change event tx id: %w", err)
+		}
+		if len(data) > 0 {
+			event.Data = data
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate change events: %w", err)
+	}
+	return events, nil
+}
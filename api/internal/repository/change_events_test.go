@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXChangeEventsRepository_ListChangesAfter(t *testing.T) {
+	occurred := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &PGXChangeEventsRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "pg_snapshot_xmin") {
+				t.Fatalf("expected in-flight transactions to be held back, got %q", query)
+			}
+			if args[0] != "12" || args[1] != int64(3) || args[2] != 50 {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "13"
+					*dest[1].(*int64) = 4
+					*dest[2].(*string) = entity.ChangeEntityCompany
+					*dest[3].(*uuid.UUID) = uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+					*dest[4].(*string) = entity.ChangeOperationDelete
+					*dest[5].(*[]byte) = nil
+					*dest[6].(*time.Time) = occurred
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	events, err := repo.ListChangesAfter(context.Background(), entity.ChangeCursor{TxID: 12, ID: 3}, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Cursor != (entity.ChangeCursor{TxID: 13, ID: 4}) || event.Operation != "delete" || event.Data != nil {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
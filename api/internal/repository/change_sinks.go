@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrChangeSinkBusy indicates another API instance is exporting to the same sink.
+var ErrChangeSinkBusy = errors.New("change sink export already in progress")
+
+// ChangeSinksRepository hands change events to external sinks, keeping a cursor per sink.
+type ChangeSinksRepository interface {
+	ExportChanges(ctx context.Context, sink string, limit int, fn func([]entity.ChangeEvent) error) (int, error)
+}
+
+// PGXChangeSinksRepository implements ChangeSinksRepository using pgx.
+type PGXChangeSinksRepository struct {
+	pool pgxPool
+}
+
+// NewPGXChangeSinksRepository wires a pgx backed change sinks repository.
+func NewPGXChangeSinksRepository(pool *pgxpool.Pool) *PGXChangeSinksRepository {
+	return &PGXChangeSinksRepository{pool: pool}
+}
+
+// ExportChanges passes up to limit events after the cursor of sink to fn and moves the cursor past
+// them once fn succeeds, reporting how many it passed. The cursor only moves in the transaction that
+// read it, serialised per sink with a transaction-scoped advisory lock, so API instances exporting
+// together neither skip nor repeat a batch; ErrChangeSinkBusy reports that another one holds it.
+func (r *PGXChangeSinksRepository) ExportChanges(ctx context.Context, sink string, limit int, fn func([]entity.ChangeEvent) error) (int, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("begin change export: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('change_sink:' || $1))`, sink).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("lock change sink: %w", err)
+	}
+	if !locked {
+		return 0, ErrChangeSinkBusy
+	}
+
+	var (
+		after entity.ChangeCursor
+		txID  string
+	)
+	err = tx.QueryRow(ctx, `SELECT tx_id::text, event_id FROM change_sink_cursors WHERE sink = $1`, sink).Scan(&txID, &after.ID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return 0, fmt.Errorf("get change sink cursor: %w", err)
+	default:
+		if after.TxID, err = strconv.ParseUint(txID, 10, 64); err != nil {
+			return 0, fmt.Errorf("parse change sink cursor: %w", err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, listChangesAfterSQL, strconv.FormatUint(after.TxID, 10), after.ID, limit)
+	if err != nil {
+		return 0, fmt.Errorf("list change events: %w", err)
+	}
+	events, err := scanChangeEvents(rows)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	if err := fn(events); err != nil {
+		return 0, err
+	}
+
+	last := events[len(events)-1].Cursor
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO change_sink_cursors (sink, tx_id, event_id, updated_at)
+		VALUES ($1, $2::text::xid8, $3, NOW())
+		ON CONFLICT (sink) DO UPDATE
+		SET tx_id = EXCLUDED.tx_id, event_id = EXCLUDED.event_id, updated_at = EXCLUDED.updated_at
+	`, sink, strconv.FormatUint(last.TxID, 10), last.ID); err != nil {
+		return 0, fmt.Errorf("advance change sink cursor: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit change export: %w", err)
+	}
+	return len(events), nil
+}
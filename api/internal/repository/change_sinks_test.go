@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXChangeSinksRepository_ExportChanges(t *testing.T) {
+	locked := false
+	var advanced []any
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				if strings.Contains(query, "pg_try_advisory_xact_lock") {
+					*dest[0].(*bool) = locked
+					return nil
+				}
+				*dest[0].(*string) = "12"
+				*dest[1].(*int64) = 3
+				return nil
+			}}
+		},
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if args[0] != "12" || args[1] != int64(3) {
+				t.Fatalf("expected events after the stored cursor, got %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "13"
+					*dest[1].(*int64) = 4
+					*dest[2].(*string) = entity.ChangeEntityCompany
+					*dest[3].(*uuid.UUID) = uuid.New()
+					*dest[4].(*string) = entity.ChangeOperationUpdate
+					*dest[5].(*[]byte) = []byte(`{"name":"Acme"}`)
+					*dest[6].(*time.Time) = time.Now()
+					return nil
+				},
+			}}, nil
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			advanced = args
+			return pgconn.CommandTag{}, nil
+		},
+	}
+	repo := &PGXChangeSinksRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+	send := func([]entity.ChangeEvent) error { return nil }
+
+	if _, err := repo.ExportChanges(context.Background(), "bigquery:p.d.t", 10, send); !errors.Is(err, ErrChangeSinkBusy) {
+		t.Fatalf("expected ErrChangeSinkBusy while another instance exports, got %v", err)
+	}
+
+	locked = true
+	failed := errors.New("insert refused")
+	if _, err := repo.ExportChanges(context.Background(), "bigquery:p.d.t", 10, func([]entity.ChangeEvent) error { return failed }); !errors.Is(err, failed) || advanced != nil || tx.committed {
+		t.Fatalf("expected a refused batch to leave the cursor alone, got %v (advanced %v)", err, advanced)
+	}
+
+	exported, err := repo.ExportChanges(context.Background(), "bigquery:p.d.t", 10, send)
+	if err != nil || exported != 1 || !tx.committed {
+		t.Fatalf("expected one event exported, got %d, %v", exported, err)
+	}
+	if len(advanced) != 3 || advanced[1] != "13" || advanced[2] != int64(4) {
+		t.Fatalf("expected the cursor moved past the exported event, got %v", advanced)
+	}
+}
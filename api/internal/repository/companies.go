@@ -14,13 +14,14 @@ import (
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
 )
 
 // CompaniesRepository describes persistence operations for companies.
 type CompaniesRepository interface {
 	Upsert(ctx context.Context, company *entity.Company) error
 	List(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error)
-	BulkUpsertCompanies(ctx context.Context, records []BulkUpsertCompanyInput) (BulkUpsertResult, error)
+	BulkUpsertCompanies(ctx context.Context, records []BulkUpsertCompanyInput, mode ImportMode) (BulkUpsertResult, error)
 	UpsertEnrichment(ctx context.Context, enrichment *entity.CompanyEnrichment) error
 	GetEnrichment(ctx context.Context, companyID uuid.UUID) (*entity.CompanyEnrichment, error)
 	UpsertEnrichedContacts(ctx context.Context, contact *entity.WebsiteEnrichedContact) error
@@ -41,23 +42,82 @@ type BulkUpsertCompanyInput struct {
 	Address      string
 	City         *string
 	Country      *string
+
+	// DisplayName is the name without its legal form; empty means the name as given.
+	DisplayName string
+	LegalForm   *string
+	// Categories are place types such as cafe, the business type first.
+	Categories []string
 }
 
-// BulkUpsertResult summarises the number of rows inserted or updated.
+// ImportMode decides what a bulk upsert does with rows that already exist.
+type ImportMode string
+
+// Import modes accepted by BulkUpsertCompanies.
+const (
+	// ImportModeOverwrite replaces stored values with the imported ones.
+	ImportModeOverwrite ImportMode = "overwrite"
+	// ImportModeFillMissing only sets columns that are still NULL, keeping corrected data.
+	ImportModeFillMissing ImportMode = "fill_missing"
+	// ImportModeSkipExisting leaves existing rows untouched.
+	ImportModeSkipExisting ImportMode = "skip_existing"
+)
+
+// BulkUpsertResult summarises the number of rows inserted, updated or skipped.
 type BulkUpsertResult struct {
 	Inserted int
 	Updated  int
+	Skipped  int
 	Total    int
+	// Records holds the outcome of each record, in the order they were given.
+	Records []BulkUpsertRecordResult
+}
+
+// Outcomes of a bulk upserted record, as BulkUpsertRecordResult.Status reports them.
+const (
+	BulkUpsertInserted = "inserted"
+	BulkUpsertUpdated  = "updated"
+	BulkUpsertSkipped  = "skipped"
+)
+
+// BulkUpsertRecordResult is the outcome of one bulk upserted record. CompanyID is nil for records
+// the import mode left alone.
+type BulkUpsertRecordResult struct {
+	CompanyID *uuid.UUID
+	Status    string
 }
 
 // PGXCompaniesRepository implements CompaniesRepository using pgx.
 type PGXCompaniesRepository struct {
 	pool pgxPool
+	// reads serves listing, search and export queries; nil sends them to pool.
+	reads  ReadPool
+	limits queryLimits
+	// cipher encrypts enrichment emails and phone numbers; nil stores them in plaintext.
+	cipher *fieldcrypt.Cipher
+	// leadsView sends listings, counts and exports to leads_view.
+	leadsView bool
 }
 
 // NewPGXCompaniesRepository wires a pgx backed repository.
-func NewPGXCompaniesRepository(pool *pgxpool.Pool) *PGXCompaniesRepository {
-	return &PGXCompaniesRepository{pool: pool}
+func NewPGXCompaniesRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXCompaniesRepository {
+	options := newRepositoryOptions(opts)
+	return &PGXCompaniesRepository{pool: pool, limits: options.limits, cipher: options.cipher, leadsView: options.leadsView}
+}
+
+// NewPGXCompaniesRepositoryWithReplica wires a repository whose listing, search and export queries
+// go through reads while everything else uses the primary pool.
+func NewPGXCompaniesRepositoryWithReplica(pool *pgxpool.Pool, reads ReadPool, opts ...RepositoryOption) *PGXCompaniesRepository {
+	options := newRepositoryOptions(opts)
+	return &PGXCompaniesRepository{pool: pool, reads: reads, limits: options.limits, cipher: options.cipher, leadsView: options.leadsView}
+}
+
+// reader returns the pool serving heavy read-only queries.
+func (r *PGXCompaniesRepository) reader() ReadPool {
+	if r.reads != nil {
+		return r.reads
+	}
+	return r.pool
 }
 
 var _ pgxPool = (*pgxpool.Pool)(nil)
@@ -68,21 +128,15 @@ func (r *PGXCompaniesRepository) Upsert(ctx context.Context, company *entity.Com
 		return fmt.Errorf("company payload is nil")
 	}
 
-	raw := company.Raw
-	if len(raw) == 0 {
-		raw = json.RawMessage("{}")
+	if _, err := r.pool.Exec(ctx, upsertCompanySQL, upsertCompanyArgs(company)...); err != nil {
+		return fmt.Errorf("upsert company: %w", err)
 	}
 
-	var lng any
-	if company.Longitude != nil {
-		lng = *company.Longitude
-	}
-	var lat any
-	if company.Latitude != nil {
-		lat = *company.Latitude
-	}
+	return nil
+}
 
-	query := `
+// upsertCompanySQL inserts or updates a company keyed by place_id; upsertCompanyArgs builds its arguments.
+const upsertCompanySQL = `
         INSERT INTO companies (
             place_id,
             company,
@@ -98,6 +152,12 @@ func (r *PGXCompaniesRepository) Upsert(ctx context.Context, company *entity.Com
             raw,
             scrape_run_id,
             scraped_at,
+            display_name,
+            legal_form,
+            opening_hours,
+            utc_offset_minutes,
+            price_level,
+            categories,
             updated_at
         ) VALUES (
             $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
@@ -107,6 +167,12 @@ func (r *PGXCompaniesRepository) Upsert(ctx context.Context, company *entity.Com
             $13,
             $14,
             $15,
+            $16,
+            $17,
+            $18,
+            $19,
+            $20,
+            $21,
             NOW()
         )
         ON CONFLICT (place_id) DO UPDATE SET
@@ -121,12 +187,42 @@ func (r *PGXCompaniesRepository) Upsert(ctx context.Context, company *entity.Com
             country = EXCLUDED.country,
             location = EXCLUDED.location,
             raw = EXCLUDED.raw,
+            raw_ref = NULL,
             scrape_run_id = COALESCE(EXCLUDED.scrape_run_id, companies.scrape_run_id),
             scraped_at = COALESCE(EXCLUDED.scraped_at, companies.scraped_at),
+            display_name = EXCLUDED.display_name,
+            legal_form = EXCLUDED.legal_form,
+            opening_hours = EXCLUDED.opening_hours,
+            utc_offset_minutes = EXCLUDED.utc_offset_minutes,
+            price_level = EXCLUDED.price_level,
+            categories = EXCLUDED.categories,
+            ` + resetWebsiteCheckSQL + `,
             updated_at = NOW();
     `
 
-	_, err := r.pool.Exec(ctx, query,
+// resetWebsiteCheckSQL clears the website check of an upserted company whose website changed, so
+// the checker picks it up again instead of reporting the status of the old website.
+const resetWebsiteCheckSQL = `website_status = CASE WHEN companies.website IS DISTINCT FROM EXCLUDED.website THEN NULL ELSE companies.website_status END,
+            website_http_status = CASE WHEN companies.website IS DISTINCT FROM EXCLUDED.website THEN NULL ELSE companies.website_http_status END,
+            website_redirects_to = CASE WHEN companies.website IS DISTINCT FROM EXCLUDED.website THEN NULL ELSE companies.website_redirects_to END,
+            website_checked_at = CASE WHEN companies.website IS DISTINCT FROM EXCLUDED.website THEN NULL ELSE companies.website_checked_at END`
+
+func upsertCompanyArgs(company *entity.Company) []any {
+	raw := company.Raw
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+
+	var lng any
+	if company.Longitude != nil {
+		lng = *company.Longitude
+	}
+	var lat any
+	if company.Latitude != nil {
+		lat = *company.Latitude
+	}
+
+	return []any{
 		company.PlaceID,
 		company.Company,
 		company.Phone,
@@ -142,35 +238,92 @@ func (r *PGXCompaniesRepository) Upsert(ctx context.Context, company *entity.Com
 		raw,
 		company.ScrapeRunID,
 		company.ScrapedAt,
-	)
+		displayNameOrCompany(company.DisplayName, company.Company),
+		company.LegalForm,
+		openingHoursArg(company.OpeningHours),
+		company.UTCOffsetMinutes,
+		company.PriceLevel,
+		company.Categories,
+	}
+}
+
+// openingHoursArg encodes opening hours for the opening_hours column; none are stored as NULL.
+func openingHoursArg(periods []entity.OpeningPeriod) any {
+	if len(periods) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(periods)
 	if err != nil {
-		return fmt.Errorf("upsert company: %w", err)
+		return nil
 	}
+	return encoded
+}
 
-	return nil
+// displayNameOrCompany falls back to the full name for callers that did not extract a legal form.
+func displayNameOrCompany(displayName *string, company string) string {
+	if displayName != nil && *displayName != "" {
+		return *displayName
+	}
+	return company
 }
 
-const bulkUpsertSQL = `
-        INSERT INTO companies (company, phone, website, rating, reviews, type_business, address, city, country, raw, updated_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10::jsonb,NOW())
-        ON CONFLICT (company, address) WHERE place_id IS NULL DO UPDATE SET
-            phone = EXCLUDED.phone,
-            website = EXCLUDED.website,
-            rating = EXCLUDED.rating,
-            reviews = EXCLUDED.reviews,
-            type_business = EXCLUDED.type_business,
-            city = EXCLUDED.city,
-            country = EXCLUDED.country,
-            updated_at = NOW()
-        RETURNING xmax = 0;
+// bulkUpsertInsertSQL matches rows on their display name, so a re-import spelling out the legal form
+// differently updates the company instead of adding it again.
+const bulkUpsertInsertSQL = `
+        INSERT INTO companies (company, phone, website, rating, reviews, type_business, address, city, country, raw, display_name, legal_form, categories, updated_at)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10::jsonb,$11,$12,$13,NOW())
+        ON CONFLICT (display_name, address) WHERE place_id IS NULL
     `
 
-// BulkUpsertCompanies persists a batch of companies with idempotent semantics.
-func (r *PGXCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []BulkUpsertCompanyInput) (BulkUpsertResult, error) {
+// bulkUpsertColumns are the columns a CSV re-import may change on an existing row.
+var bulkUpsertColumns = []string{"phone", "website", "rating", "reviews", "type_business", "city", "country", "legal_form", "categories"}
+
+// bulkUpsertSQL builds the upsert statement for a mode. It returns the id of the row and whether it
+// was inserted, and no row at all when the mode left an existing company alone.
+func bulkUpsertSQL(mode ImportMode) (string, error) {
+	var conflict string
+	switch mode {
+	case ImportModeOverwrite, "":
+		sets := make([]string, 0, len(bulkUpsertColumns)+1)
+		for _, column := range bulkUpsertColumns {
+			if column == "legal_form" || column == "categories" {
+				// A name without a legal form, or a row without categories, does not mean the
+				// company lost them.
+				sets = append(sets, fmt.Sprintf("%s = COALESCE(EXCLUDED.%s, companies.%s)", column, column, column))
+				continue
+			}
+			sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+		conflict = "DO UPDATE SET " + strings.Join(append(sets, resetWebsiteCheckSQL, "updated_at = NOW()"), ", ")
+	case ImportModeFillMissing:
+		sets := make([]string, 0, len(bulkUpsertColumns)+1)
+		gaps := make([]string, 0, len(bulkUpsertColumns))
+		for _, column := range bulkUpsertColumns {
+			sets = append(sets, fmt.Sprintf("%s = COALESCE(companies.%s, EXCLUDED.%s)", column, column, column))
+			gaps = append(gaps, fmt.Sprintf("(companies.%s IS NULL AND EXCLUDED.%s IS NOT NULL)", column, column))
+		}
+		// Rows with nothing to fill are left alone so their updated_at does not move.
+		conflict = "DO UPDATE SET " + strings.Join(append(sets, "updated_at = NOW()"), ", ") +
+			" WHERE " + strings.Join(gaps, " OR ")
+	case ImportModeSkipExisting:
+		conflict = "DO NOTHING"
+	default:
+		return "", fmt.Errorf("unknown import mode %q", mode)
+	}
+	return bulkUpsertInsertSQL + conflict + " RETURNING id, xmax = 0", nil
+}
+
+// BulkUpsertCompanies persists a batch of companies with idempotent semantics; mode decides how rows
+// that already exist are treated.
+func (r *PGXCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []BulkUpsertCompanyInput, mode ImportMode) (BulkUpsertResult, error) {
 	var result BulkUpsertResult
 	if len(records) == 0 {
 		return result, nil
 	}
+	query, err := bulkUpsertSQL(mode)
+	if err != nil {
+		return result, err
+	}
 
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
@@ -179,7 +332,7 @@ func (r *PGXCompaniesRepository) BulkUpsertCompanies(ctx context.Context, record
 	defer tx.Rollback(ctx)
 
 	for _, record := range records {
-		rows, err := tx.Query(ctx, bulkUpsertSQL,
+		rows, err := tx.Query(ctx, query,
 			record.Company,
 			stringOrNil(record.Phone),
 			stringOrNil(record.Website),
@@ -190,14 +343,20 @@ func (r *PGXCompaniesRepository) BulkUpsertCompanies(ctx context.Context, record
 			stringOrNil(record.City),
 			stringOrNil(record.Country),
 			"{}",
+			displayNameOrCompany(&record.DisplayName, record.Company),
+			stringOrNil(record.LegalForm),
+			record.Categories,
 		)
 		if err != nil {
 			return result, fmt.Errorf("bulk upsert company %q: %w", record.Company, err)
 		}
 
-		var inserted bool
+		var (
+			id       uuid.UUID
+			inserted bool
+		)
 		if rows.Next() {
-			if scanErr := rows.Scan(&inserted); scanErr != nil {
+			if scanErr := rows.Scan(&id, &inserted); scanErr != nil {
 				rows.Close()
 				return result, fmt.Errorf("scan bulk upsert result: %w", scanErr)
 			}
@@ -207,14 +366,22 @@ func (r *PGXCompaniesRepository) BulkUpsertCompanies(ctx context.Context, record
 			if err != nil {
 				return result, fmt.Errorf("bulk upsert company %q: %w", record.Company, err)
 			}
-			return result, fmt.Errorf("bulk upsert company %q: no result returned", record.Company)
+			if mode == ImportModeOverwrite || mode == "" {
+				return result, fmt.Errorf("bulk upsert company %q: no result returned", record.Company)
+			}
+			result.Skipped++
+			result.Total++
+			result.Records = append(result.Records, BulkUpsertRecordResult{Status: BulkUpsertSkipped})
+			continue
 		}
 		rows.Close()
 
 		if inserted {
 			result.Inserted++
+			result.Records = append(result.Records, BulkUpsertRecordResult{CompanyID: &id, Status: BulkUpsertInserted})
 		} else {
 			result.Updated++
+			result.Records = append(result.Records, BulkUpsertRecordResult{CompanyID: &id, Status: BulkUpsertUpdated})
 		}
 		result.Total++
 	}
@@ -226,32 +393,189 @@ func (r *PGXCompaniesRepository) BulkUpsertCompanies(ctx context.Context, record
 	return result, nil
 }
 
+// companyColumns lists the columns scanCompanies expects, in order. rawColumn is the expression
+// selected for raw, so callers can skip the payload with NULL::jsonb AS raw.
+func companyColumns(rawColumn string) string {
+	return `
+        id,
+        place_id,
+        scrape_run_id,
+        company,
+        phone,
+        website,
+        rating,
+        reviews,
+        type_business,
+        address,
+        city,
+        country,
+        CASE WHEN location IS NOT NULL THEN ST_X(location::geometry) END AS longitude,
+        CASE WHEN location IS NOT NULL THEN ST_Y(location::geometry) END AS latitude,
+        ` + rawColumn + `,
+        scraped_at,
+        created_at,
+        updated_at,
+        chain_id,
+        size_bucket,
+        business_status,
+        status_changed_at,
+        preferred_outreach_language,
+        outreach_language_source,
+        legal_form,
+        display_name,
+        shared_contact,
+        (SELECT a.user_id FROM company_assignments a WHERE a.company_id = companies.id) AS assigned_to,
+        website_status,
+        website_redirects_to,
+        website_checked_at,
+        COALESCE(logo_mirror_url, logo_url) AS logo_url,
+        photo_mirror_url AS photo_url,
+        photo_reference,
+        opening_hours,
+        utc_offset_minutes,
+        price_level,
+        categories
+    `
+}
+
 // List retrieves companies matching the provided filter, sorted by rating then reviews.
-func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter) (_ []entity.Company, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	query, args, err := r.listQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list companies: %w", err)
+	}
+	defer rows.Close()
+
+	if filter.IncludeEnrichmentSummary {
+		return scanCompaniesWithSummary(rows)
+	}
+	return scanCompanies(rows)
+}
+
+// listQuery renders the statement and arguments List runs for filter.
+func (r *PGXCompaniesRepository) listQuery(ctx context.Context, filter dto.ListFilter) (string, []any, error) {
+	// raw dominates the row size, so it is only read when asked for.
+	rawColumn := "NULL::jsonb AS raw"
+	if filter.IncludeRaw {
+		rawColumn = "raw"
+	}
+
 	baseQuery := strings.Builder{}
-	baseQuery.WriteString(`
-        SELECT
-            id,
-            place_id,
-            scrape_run_id,
-            company,
-            phone,
-            website,
-            rating,
-            reviews,
-            type_business,
-            address,
-            city,
-            country,
-            CASE WHEN location IS NOT NULL THEN ST_X(location::geometry) END AS longitude,
-            CASE WHEN location IS NOT NULL THEN ST_Y(location::geometry) END AS latitude,
-            raw,
-            scraped_at,
-            created_at,
-            updated_at
-        FROM companies
-    `)
+	baseQuery.WriteString(r.listSource(filter, rawColumn).selectClause(filter.IncludeEnrichmentSummary))
 
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return "", nil, err
+	}
+	filter = where.filter
+	args, idx := where.args, where.next
+	baseQuery.WriteString(where.where())
+
+	order, orderArgs := listOrder(filter, idx)
+	baseQuery.WriteString(" ORDER BY ")
+	baseQuery.WriteString(order)
+	args = append(args, orderArgs...)
+	idx += len(orderArgs)
+
+	limit, limitArgs := listLimit(filter, idx)
+	baseQuery.WriteString(limit)
+	args = append(args, limitArgs...)
+	return baseQuery.String(), args, nil
+}
+
+// listLimit renders the LIMIT clause of a listing starting at positional argument idx: filter.Limit
+// when set, otherwise the requested page.
+func listLimit(filter dto.ListFilter, idx int) (string, []any) {
+	if filter.Limit > 0 {
+		return fmt.Sprintf(" LIMIT %d", filter.Limit), nil
+	}
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	perPage := filter.PerPage
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	offset := (page - 1) * perPage
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", idx, idx+1), []any{perPage, offset}
+}
+
+// SortRelevance ranks searches by a blend of text relevance and lead score.
+const SortRelevance = "relevance"
+
+// searchDocumentSQL weighs a company for text relevance: its name first, then its type, then its address.
+const searchDocumentSQL = `setweight(to_tsvector('simple', COALESCE(company, '')), 'A')
+	|| setweight(to_tsvector('simple', COALESCE(type_business, '')), 'B')
+	|| setweight(to_tsvector('simple', COALESCE(address, '')), 'C')`
+
+// listOrder returns the ORDER BY expression of company listings and the positional arguments it
+// uses, starting at idx. Searches without an explicit sort rank by relevance unless the listing
+// defaults to recent updates.
+func listOrder(filter dto.ListFilter, idx int) (string, []any) {
+	if strings.EqualFold(filter.Sort, "recent") || (filter.Sort == "" && filter.LatestRunOnly) {
+		return "updated_at DESC, rating DESC NULLS LAST, company ASC", nil
+	}
+	if filter.Q != "" && (strings.EqualFold(filter.Sort, SortRelevance) || filter.Sort == "") {
+		return relevanceOrder(filter, idx)
+	}
+	return "rating DESC NULLS LAST, reviews DESC NULLS LAST, company ASC", nil
+}
+
+// relevanceOrder blends the text rank of filter.Q, scaled to [0, 1), with the stored lead score over
+// 100. filter.ScoreWeight is the share of the lead score; nil ranks on text alone.
+func relevanceOrder(filter dto.ListFilter, idx int) (string, []any) {
+	weight := 0.0
+	if filter.ScoreWeight != nil {
+		weight = *filter.ScoreWeight
+	}
+	return fmt.Sprintf(`(1 - $%[2]d::float8) * ts_rank_cd(%[3]s, plainto_tsquery('simple', $%[1]d), 32)
+		+ $%[2]d::float8 * COALESCE((SELECT LEAST(ls.score, 100) FROM company_lead_scores ls WHERE ls.company_id = companies.id), 0) / 100.0 DESC,
+		rating DESC NULLS LAST, reviews DESC NULLS LAST, company ASC`, idx, idx+1, searchDocumentSQL), []any{filter.Q, weight}
+}
+
+// openNowSQL finds an opening period containing the current local time of the company, computed
+// from its UTC offset. Periods are split at midnight, so the day of week and time of day suffice.
+const openNowSQL = `(
+            SELECT 1
+            FROM jsonb_array_elements(opening_hours) p,
+                LATERAL (SELECT (now() AT TIME ZONE 'UTC') + make_interval(mins => utc_offset_minutes) AS at) l
+            WHERE (p->>'day')::int = EXTRACT(DOW FROM l.at)::int
+                AND to_char(l.at, 'HH24:MI') >= p->>'open'
+                AND to_char(l.at, 'HH24:MI') < p->>'close'
+        )`
+
+// listConditions holds the WHERE clauses and positional arguments derived from a ListFilter.
+type listConditions struct {
+	clauses []string
+	args    []any
+	// next is the index of the next free positional argument.
+	next int
+	// filter is the input with the latest scrape run/window resolved.
+	filter dto.ListFilter
+}
+
+// where renders the clauses as a WHERE fragment, or an empty string when nothing filters.
+func (c listConditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// buildListConditions translates a ListFilter into SQL conditions shared by List and Facets. When the
+// filter asks for the latest run only, it resolves that run (or update window) first.
+func (r *PGXCompaniesRepository) buildListConditions(ctx context.Context, filter dto.ListFilter) (listConditions, error) {
 	var (
 		clauses []string
 		args    []any
@@ -264,6 +588,16 @@ func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter
 		args = append(args, pattern, pattern)
 		idx += 2
 	}
+	if filter.QContact != "" {
+		// A search that reads as no email, phone number or handle matches nothing.
+		clause := "FALSE"
+		if terms := contactSearchTerms(r.cipher, filter.QContact); len(terms) > 0 {
+			clause = fmt.Sprintf("EXISTS (SELECT 1 FROM company_enrichments ce WHERE ce.company_id = companies.id AND ce.contact_terms && $%d::text[])", idx)
+			args = append(args, terms)
+			idx++
+		}
+		clauses = append(clauses, clause)
+	}
 	if filter.TypeBusiness != "" {
 		clauses = append(clauses, fmt.Sprintf("LOWER(type_business) = LOWER($%d)", idx))
 		args = append(args, filter.TypeBusiness)
@@ -284,12 +618,153 @@ func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter
 		args = append(args, *filter.MinRating)
 		idx++
 	}
+	if filter.MaxRating != nil {
+		clauses = append(clauses, fmt.Sprintf("rating <= $%d", idx))
+		args = append(args, *filter.MaxRating)
+		idx++
+	}
+	if filter.MinReviews != nil {
+		clauses = append(clauses, fmt.Sprintf("reviews >= $%d", idx))
+		args = append(args, *filter.MinReviews)
+		idx++
+	}
+	if filter.HasPhone != nil {
+		if *filter.HasPhone {
+			clauses = append(clauses, hasPhoneSQL)
+		} else {
+			clauses = append(clauses, "NOT "+hasPhoneSQL)
+		}
+	}
+	if filter.HasSocials != nil {
+		if *filter.HasSocials {
+			clauses = append(clauses, hasSocialsSQL)
+		} else {
+			clauses = append(clauses, "NOT "+hasSocialsSQL)
+		}
+	}
 	switch strings.ToLower(filter.WebsiteStatus) {
 	case "missing":
 		clauses = append(clauses, "website IS NULL")
 	case "available":
 		clauses = append(clauses, "website IS NOT NULL")
 	}
+	if len(filter.Technologies) > 0 {
+		clause := fmt.Sprintf("EXISTS (SELECT 1 FROM company_enrichments te WHERE te.company_id = companies.id AND te.metadata->'technologies' ?| $%d)", idx)
+		for _, technology := range filter.Technologies {
+			if technology == "none" {
+				clause = fmt.Sprintf("EXISTS (SELECT 1 FROM company_enrichments te WHERE te.company_id = companies.id AND (te.metadata->'technologies' ?| $%d OR te.metadata->'technologies' = '[]'::jsonb))", idx)
+				break
+			}
+		}
+		clauses = append(clauses, clause)
+		args = append(args, filter.Technologies)
+		idx++
+	}
+	if len(filter.WebsiteLiveness) > 0 {
+		clause := fmt.Sprintf("website_status = ANY($%d)", idx)
+		for _, status := range filter.WebsiteLiveness {
+			if status == "unchecked" {
+				clause = fmt.Sprintf("(website_status = ANY($%d) OR (website IS NOT NULL AND website_status IS NULL))", idx)
+				break
+			}
+		}
+		clauses = append(clauses, clause)
+		args = append(args, filter.WebsiteLiveness)
+		idx++
+	}
+	switch strings.ToLower(filter.BusinessStatus) {
+	case "operational":
+		// Companies without a reported status are assumed to be open.
+		clauses = append(clauses, "(business_status IS NULL OR business_status = 'operational')")
+	case "closed":
+		clauses = append(clauses, "business_status IN ('closed_temporarily', 'closed_permanently')")
+	case "closed_temporarily", "closed_permanently":
+		clauses = append(clauses, fmt.Sprintf("business_status = $%d", idx))
+		args = append(args, strings.ToLower(filter.BusinessStatus))
+		idx++
+	}
+	if len(filter.LegalForms) > 0 {
+		clause := fmt.Sprintf("legal_form = ANY($%d)", idx)
+		for _, form := range filter.LegalForms {
+			if form == "none" {
+				clause = fmt.Sprintf("(legal_form = ANY($%d) OR legal_form IS NULL)", idx)
+				break
+			}
+		}
+		clauses = append(clauses, clause)
+		args = append(args, filter.LegalForms)
+		idx++
+	}
+	if len(filter.PriceLevels) > 0 {
+		clauses = append(clauses, fmt.Sprintf("price_level = ANY($%d::int[])", idx))
+		args = append(args, filter.PriceLevels)
+		idx++
+	}
+	if len(filter.Categories) > 0 {
+		clauses = append(clauses, fmt.Sprintf("categories && $%d::text[]", idx))
+		args = append(args, filter.Categories)
+		idx++
+	}
+	if filter.OpenNow != nil {
+		// Companies without opening hours or a UTC offset match neither open_now=true nor false.
+		if *filter.OpenNow {
+			clauses = append(clauses, "utc_offset_minutes IS NOT NULL AND EXISTS "+openNowSQL)
+		} else {
+			clauses = append(clauses, "utc_offset_minutes IS NOT NULL AND opening_hours IS NOT NULL AND NOT EXISTS "+openNowSQL)
+		}
+	}
+	if filter.SharedContact != nil {
+		if *filter.SharedContact {
+			clauses = append(clauses, "shared_contact")
+		} else {
+			clauses = append(clauses, "NOT shared_contact")
+		}
+	}
+	if filter.AssignedTo != nil {
+		clauses = append(clauses, fmt.Sprintf("EXISTS (SELECT 1 FROM company_assignments a WHERE a.company_id = companies.id AND a.user_id = $%d)", idx))
+		args = append(args, *filter.AssignedTo)
+		idx++
+	}
+	if filter.Unassigned {
+		clauses = append(clauses, "NOT EXISTS (SELECT 1 FROM company_assignments a WHERE a.company_id = companies.id)")
+	}
+	if filter.IsChain != nil {
+		if *filter.IsChain {
+			clauses = append(clauses, "chain_id IS NOT NULL")
+		} else {
+			clauses = append(clauses, "chain_id IS NULL")
+		}
+	}
+	if filter.ChainID != nil {
+		clauses = append(clauses, fmt.Sprintf("chain_id = $%d", idx))
+		args = append(args, *filter.ChainID)
+		idx++
+	}
+	if len(filter.SizeBuckets) > 0 {
+		clause := fmt.Sprintf("size_bucket = ANY($%d)", idx)
+		for _, bucket := range filter.SizeBuckets {
+			if bucket == "unknown" {
+				// Companies that were never estimated count as unknown too.
+				clause = fmt.Sprintf("(size_bucket = ANY($%d) OR size_bucket IS NULL)", idx)
+				break
+			}
+		}
+		clauses = append(clauses, clause)
+		args = append(args, filter.SizeBuckets)
+		idx++
+	}
+	if len(filter.OutreachLanguages) > 0 {
+		clause := fmt.Sprintf("preferred_outreach_language = ANY($%d)", idx)
+		for _, language := range filter.OutreachLanguages {
+			if language == "unknown" {
+				clause = fmt.Sprintf("(preferred_outreach_language = ANY($%d) OR preferred_outreach_language IS NULL)", idx)
+				break
+			}
+		}
+		clauses = append(clauses, clause)
+		args = append(args, filter.OutreachLanguages)
+		idx++
+	}
 	if filter.LatestRunOnly && filter.UpdatedSince == nil && filter.ScrapeRunID == nil {
 		runClauses := append([]string{}, clauses...)
 		runClauses = append(runClauses, "scrape_run_id IS NOT NULL")
@@ -306,15 +781,15 @@ func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter
 			latestRunID   sql.NullString
 			latestScraped sql.NullTime
 		)
-		err := r.pool.QueryRow(ctx, runQuery.String(), args...).Scan(&latestRunID, &latestScraped)
+		err := r.reader().QueryRow(ctx, runQuery.String(), args...).Scan(&latestRunID, &latestScraped)
 		if err != nil {
 			if err != pgx.ErrNoRows {
-				return nil, fmt.Errorf("determine latest scrape run: %w", err)
+				return listConditions{}, fmt.Errorf("determine latest scrape run: %w", err)
 			}
 		} else if latestRunID.Valid {
 			parsed, parseErr := uuid.Parse(latestRunID.String)
 			if parseErr != nil {
-				return nil, fmt.Errorf("parse latest scrape run id: %w", parseErr)
+				return listConditions{}, fmt.Errorf("parse latest scrape run id: %w", parseErr)
 			}
 			filter.ScrapeRunID = &parsed
 		} else if latestScraped.Valid {
@@ -328,8 +803,8 @@ func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter
 			latestQuery += " WHERE " + strings.Join(clauses, " AND ")
 		}
 		var latest sql.NullTime
-		if err := r.pool.QueryRow(ctx, latestQuery, args...).Scan(&latest); err != nil {
-			return nil, fmt.Errorf("determine latest scrape window: %w", err)
+		if err := r.reader().QueryRow(ctx, latestQuery, args...).Scan(&latest); err != nil {
+			return listConditions{}, fmt.Errorf("determine latest scrape window: %w", err)
 		}
 		if latest.Valid {
 			ts := latest.Time
@@ -349,44 +824,7 @@ func (r *PGXCompaniesRepository) List(ctx context.Context, filter dto.ListFilter
 		idx++
 	}
 
-	if len(clauses) > 0 {
-		baseQuery.WriteString(" WHERE ")
-		baseQuery.WriteString(strings.Join(clauses, " AND "))
-	}
-
-	orderClause := "rating DESC NULLS LAST, reviews DESC NULLS LAST, company ASC"
-	if strings.EqualFold(filter.Sort, "recent") || (filter.Sort == "" && filter.LatestRunOnly) {
-		orderClause = "updated_at DESC, rating DESC NULLS LAST, company ASC"
-	}
-	baseQuery.WriteString(" ORDER BY ")
-	baseQuery.WriteString(orderClause)
-
-	if filter.Limit > 0 {
-		baseQuery.WriteString(fmt.Sprintf(" LIMIT %d", filter.Limit))
-	} else {
-		page := filter.Page
-		if page <= 0 {
-			page = 1
-		}
-		perPage := filter.PerPage
-		if perPage <= 0 {
-			perPage = 20
-		}
-		if perPage > 100 {
-			perPage = 100
-		}
-		offset := (page - 1) * perPage
-		baseQuery.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", idx, idx+1))
-		args = append(args, perPage, offset)
-	}
-
-	rows, err := r.pool.Query(ctx, baseQuery.String(), args...)
-	if err != nil {
-		return nil, fmt.Errorf("list companies: %w", err)
-	}
-	defer rows.Close()
-
-	return scanCompanies(rows)
+	return listConditions{clauses: clauses, args: args, next: idx, filter: filter}, nil
 }
 
 // UpsertEnrichment stores or updates contact enrichment metadata for a company.
@@ -413,6 +851,12 @@ func (r *PGXCompaniesRepository) UpsertEnrichment(ctx context.Context, enrichmen
 		return fmt.Errorf("marshal metadata: %w", err)
 	}
 
+	emails, phones, err := encryptContacts(r.cipher, enrichment)
+	if err != nil {
+		return err
+	}
+	terms := contactTerms(r.cipher, enrichment.Emails, enrichment.Phones, socials)
+
 	query := `
 		INSERT INTO company_enrichments (
 			company_id,
@@ -423,8 +867,9 @@ func (r *PGXCompaniesRepository) UpsertEnrichment(ctx context.Context, enrichmen
 			contact_form_url,
 			about_summary,
 			metadata,
+			contact_terms,
 			updated_at
-		) VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8::jsonb, NOW())
+		) VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8::jsonb, $9, NOW())
 		ON CONFLICT (company_id) DO UPDATE SET
 			emails = EXCLUDED.emails,
 			phones = EXCLUDED.phones,
@@ -433,18 +878,20 @@ func (r *PGXCompaniesRepository) UpsertEnrichment(ctx context.Context, enrichmen
 			contact_form_url = EXCLUDED.contact_form_url,
 			about_summary = EXCLUDED.about_summary,
 			metadata = EXCLUDED.metadata,
+			contact_terms = EXCLUDED.contact_terms,
 			updated_at = NOW();
 	`
 
 	_, err = r.pool.Exec(ctx, query,
 		enrichment.CompanyID,
-		stringSliceOrEmpty(enrichment.Emails),
-		stringSliceOrEmpty(enrichment.Phones),
+		emails,
+		phones,
 		string(socialsJSON),
 		enrichment.Address,
 		enrichment.ContactFormURL,
 		enrichment.AboutSummary,
 		string(metadataJSON),
+		terms,
 	)
 	if err != nil {
 		return fmt.Errorf("upsert enrichment: %w", err)
@@ -478,6 +925,20 @@ func (r *PGXCompaniesRepository) GetEnrichment(ctx context.Context, companyID uu
 		WHERE company_id = $1
 	`
 
+	record, err := scanEnrichment(r.pool.QueryRow(ctx, query, companyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEnrichmentNotFound
+		}
+		return nil, fmt.Errorf("fetch enrichment: %w", err)
+	}
+	if err := decryptContacts(r.cipher, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func scanEnrichment(row pgx.Row) (*entity.CompanyEnrichment, error) {
 	var (
 		record       entity.CompanyEnrichment
 		emails       []string
@@ -489,7 +950,7 @@ func (r *PGXCompaniesRepository) GetEnrichment(ctx context.Context, companyID uu
 		aboutSummary sql.NullString
 	)
 
-	err := r.pool.QueryRow(ctx, query, companyID).Scan(
+	err := row.Scan(
 		&record.CompanyID,
 		&emails,
 		&phones,
@@ -502,10 +963,7 @@ func (r *PGXCompaniesRepository) GetEnrichment(ctx context.Context, companyID uu
 		&record.UpdatedAt,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrEnrichmentNotFound
-		}
-		return nil, fmt.Errorf("fetch enrichment: %w", err)
+		return nil, err
 	}
 
 	if len(emails) > 0 {
@@ -531,7 +989,8 @@ func (r *PGXCompaniesRepository) GetEnrichment(ctx context.Context, companyID uu
 	return &record, nil
 }
 
-// UpsertEnrichedContacts saves normalized contact fields for a company.
+// UpsertEnrichedContacts saves normalized contact fields for a company, encrypting its emails and
+// phone numbers with the enrichment cipher.
 func (r *PGXCompaniesRepository) UpsertEnrichedContacts(ctx context.Context, contact *entity.WebsiteEnrichedContact) error {
 	if contact == nil {
 		return fmt.Errorf("enriched contact payload is nil")
@@ -547,10 +1006,13 @@ func (r *PGXCompaniesRepository) UpsertEnrichedContacts(ctx context.Context, con
 			instagram_url,
 			youtube_url,
 			tiktok_url,
+			twitter_url,
+			whatsapp_url,
+			social_handles,
 			address,
 			contact_form_url
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 		)
 		ON CONFLICT (company_id) DO UPDATE SET
 			emails = EXCLUDED.emails,
@@ -560,20 +1022,39 @@ func (r *PGXCompaniesRepository) UpsertEnrichedContacts(ctx context.Context, con
 			instagram_url = EXCLUDED.instagram_url,
 			youtube_url = EXCLUDED.youtube_url,
 			tiktok_url = EXCLUDED.tiktok_url,
+			twitter_url = EXCLUDED.twitter_url,
+			whatsapp_url = EXCLUDED.whatsapp_url,
+			social_handles = EXCLUDED.social_handles,
 			address = EXCLUDED.address,
 			contact_form_url = EXCLUDED.contact_form_url,
 			updated_at = NOW();
 	`
 
-	_, err := r.pool.Exec(ctx, query,
+	handles := contact.SocialHandles
+	if handles == nil {
+		handles = map[string]string{}
+	}
+	handlesJSON, err := json.Marshal(handles)
+	if err != nil {
+		return fmt.Errorf("marshal social handles: %w", err)
+	}
+	emails, phones, err := encryptContactLists(r.cipher, contact.Emails, contact.Phones)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, query,
 		contact.CompanyID,
-		stringSliceOrEmpty(contact.Emails),
-		stringSliceOrEmpty(contact.Phones),
+		emails,
+		phones,
 		stringOrNil(contact.LinkedInURL),
 		stringOrNil(contact.FacebookURL),
 		stringOrNil(contact.InstagramURL),
 		stringOrNil(contact.YouTubeURL),
 		stringOrNil(contact.TikTokURL),
+		stringOrNil(contact.TwitterURL),
+		stringOrNil(contact.WhatsAppURL),
+		string(handlesJSON),
 		stringOrNil(contact.Address),
 		stringOrNil(contact.ContactFormURL),
 	)
@@ -596,6 +1077,9 @@ func (r *PGXCompaniesRepository) GetByCompanyID(ctx context.Context, companyID u
 			instagram_url,
 			youtube_url,
 			tiktok_url,
+			twitter_url,
+			whatsapp_url,
+			social_handles,
 			address,
 			contact_form_url,
 			created_at,
@@ -613,6 +1097,9 @@ func (r *PGXCompaniesRepository) GetByCompanyID(ctx context.Context, companyID u
 		instagramURL   sql.NullString
 		youtubeURL     sql.NullString
 		tiktokURL      sql.NullString
+		twitterURL     sql.NullString
+		whatsappURL    sql.NullString
+		handlesJSON    []byte
 		address        sql.NullString
 		contactFormURL sql.NullString
 	)
@@ -627,6 +1114,9 @@ func (r *PGXCompaniesRepository) GetByCompanyID(ctx context.Context, companyID u
 		&instagramURL,
 		&youtubeURL,
 		&tiktokURL,
+		&twitterURL,
+		&whatsappURL,
+		&handlesJSON,
 		&address,
 		&contactFormURL,
 		&record.CreatedAt,
@@ -638,6 +1128,9 @@ func (r *PGXCompaniesRepository) GetByCompanyID(ctx context.Context, companyID u
 		}
 		return nil, fmt.Errorf("fetch website enriched contact: %w", err)
 	}
+	if emails, phones, err = decryptContactLists(r.cipher, record.CompanyID.String(), emails, phones); err != nil {
+		return nil, err
+	}
 
 	if len(emails) > 0 {
 		record.Emails = append([]string(nil), emails...)
@@ -650,6 +1143,13 @@ func (r *PGXCompaniesRepository) GetByCompanyID(ctx context.Context, companyID u
 	record.InstagramURL = nullStringToPtr(instagramURL)
 	record.YouTubeURL = nullStringToPtr(youtubeURL)
 	record.TikTokURL = nullStringToPtr(tiktokURL)
+	record.TwitterURL = nullStringToPtr(twitterURL)
+	record.WhatsAppURL = nullStringToPtr(whatsappURL)
+	if len(handlesJSON) > 0 {
+		if err := json.Unmarshal(handlesJSON, &record.SocialHandles); err != nil {
+			return nil, fmt.Errorf("unmarshal social handles: %w", err)
+		}
+	}
 	record.Address = nullStringToPtr(address)
 	record.ContactFormURL = nullStringToPtr(contactFormURL)
 
@@ -667,116 +1167,203 @@ func nullStringToPtr(value sql.NullString) *string {
 func scanCompanies(rows pgx.Rows) ([]entity.Company, error) {
 	var companies []entity.Company
 	for rows.Next() {
-		var (
-			c            entity.Company
-			placeID      sql.NullString
-			scrapeRunID  sql.NullString
-			phone        sql.NullString
-			website      sql.NullString
-			rating       sql.NullFloat64
-			reviews      sql.NullInt64
-			typeBusiness sql.NullString
-			address      sql.NullString
-			city         sql.NullString
-			country      sql.NullString
-			longitude    sql.NullFloat64
-			latitude     sql.NullFloat64
-			raw          []byte
-			scrapedAt    sql.NullTime
-		)
-
-		err := rows.Scan(
-			&c.ID,
-			&placeID,
-			&scrapeRunID,
-			&c.Company,
-			&phone,
-			&website,
-			&rating,
-			&reviews,
-			&typeBusiness,
-			&address,
-			&city,
-			&country,
-			&longitude,
-			&latitude,
-			&raw,
-			&scrapedAt,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		)
+		c, err := scanCompany(rows)
 		if err != nil {
-			return nil, fmt.Errorf("scan company: %w", err)
+			return nil, err
 		}
+		companies = append(companies, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate companies: %w", err)
+	}
+	return companies, nil
+}
 
-		if placeID.Valid {
-			val := placeID.String
-			c.PlaceID = &val
-		}
-		if scrapeRunID.Valid {
-			parsed, err := uuid.Parse(scrapeRunID.String)
-			if err != nil {
-				return nil, fmt.Errorf("parse scrape_run_id: %w", err)
-			}
-			c.ScrapeRunID = &parsed
-		}
-		if phone.Valid {
-			val := phone.String
-			c.Phone = &val
-		}
-		if website.Valid {
-			val := website.String
-			c.Website = &val
-		}
-		if rating.Valid {
-			val := rating.Float64
-			c.Rating = &val
-		}
-		if reviews.Valid {
-			cast := int(reviews.Int64)
-			c.Reviews = &cast
-		}
-		if typeBusiness.Valid {
-			val := typeBusiness.String
-			c.TypeBusiness = &val
-		}
-		if address.Valid {
-			val := address.String
-			c.Address = &val
-		}
-		if city.Valid {
-			val := city.String
-			c.City = &val
-		}
-		if country.Valid {
-			val := country.String
-			c.Country = &val
-		}
-		if longitude.Valid {
-			val := longitude.Float64
-			c.Longitude = &val
-		}
-		if latitude.Valid {
-			val := latitude.Float64
-			c.Latitude = &val
-		}
+// scanCompany reads the current row of a query selecting companyColumns, followed by the columns
+// scanned into extra.
+func scanCompany(rows pgx.Rows, extra ...any) (entity.Company, error) {
+	var (
+		c            entity.Company
+		placeID      sql.NullString
+		scrapeRunID  sql.NullString
+		phone        sql.NullString
+		website      sql.NullString
+		rating       sql.NullFloat64
+		reviews      sql.NullInt64
+		typeBusiness sql.NullString
+		address      sql.NullString
+		city         sql.NullString
+		country      sql.NullString
+		longitude    sql.NullFloat64
+		latitude     sql.NullFloat64
+		raw          []byte
+		scrapedAt    sql.NullTime
+		chainID      sql.NullString
+		sizeBucket   sql.NullString
+		status       sql.NullString
+		statusAt     sql.NullTime
+		language     sql.NullString
+		languageFrom sql.NullString
+		legalForm    sql.NullString
+		displayName  sql.NullString
+		siteStatus   sql.NullString
+		redirectsTo  sql.NullString
+		siteChecked  sql.NullTime
+		logoURL      sql.NullString
+		photoURL     sql.NullString
+		photoRef     sql.NullString
+		hours        []byte
+		utcOffset    sql.NullInt32
+		priceLevel   sql.NullInt16
+		categories   []string
+	)
 
-		if len(raw) > 0 {
-			c.Raw = json.RawMessage(raw)
-		} else {
-			c.Raw = json.RawMessage([]byte("{}"))
+	err := rows.Scan(append([]any{
+		&c.ID,
+		&placeID,
+		&scrapeRunID,
+		&c.Company,
+		&phone,
+		&website,
+		&rating,
+		&reviews,
+		&typeBusiness,
+		&address,
+		&city,
+		&country,
+		&longitude,
+		&latitude,
+		&raw,
+		&scrapedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&chainID,
+		&sizeBucket,
+		&status,
+		&statusAt,
+		&language,
+		&languageFrom,
+		&legalForm,
+		&displayName,
+		&c.SharedContact,
+		&c.AssignedTo,
+		&siteStatus,
+		&redirectsTo,
+		&siteChecked,
+		&logoURL,
+		&photoURL,
+		&photoRef,
+		&hours,
+		&utcOffset,
+		&priceLevel,
+		&categories,
+	}, extra...)...)
+	if err != nil {
+		return entity.Company{}, fmt.Errorf("scan company: %w", err)
+	}
+
+	if placeID.Valid {
+		val := placeID.String
+		c.PlaceID = &val
+	}
+	if scrapeRunID.Valid {
+		parsed, err := uuid.Parse(scrapeRunID.String)
+		if err != nil {
+			return entity.Company{}, fmt.Errorf("parse scrape_run_id: %w", err)
 		}
-		if scrapedAt.Valid {
-			ts := scrapedAt.Time
-			c.ScrapedAt = &ts
+		c.ScrapeRunID = &parsed
+	}
+	if chainID.Valid {
+		parsed, err := uuid.Parse(chainID.String)
+		if err != nil {
+			return entity.Company{}, fmt.Errorf("parse chain_id: %w", err)
 		}
+		c.ChainID = &parsed
+	}
+	c.SizeBucket = nullStringToPtr(sizeBucket)
+	c.BusinessStatus = nullStringToPtr(status)
+	c.PreferredOutreachLanguage = nullStringToPtr(language)
+	c.OutreachLanguageSource = nullStringToPtr(languageFrom)
+	c.LegalForm = nullStringToPtr(legalForm)
+	c.DisplayName = nullStringToPtr(displayName)
+	c.WebsiteStatus = nullStringToPtr(siteStatus)
+	c.WebsiteRedirectsTo = nullStringToPtr(redirectsTo)
+	c.LogoURL = nullStringToPtr(logoURL)
+	c.PhotoURL = nullStringToPtr(photoURL)
+	c.PhotoReference = nullStringToPtr(photoRef)
+	c.Categories = categories
+	if len(hours) > 0 {
+		if err := json.Unmarshal(hours, &c.OpeningHours); err != nil {
+			return entity.Company{}, fmt.Errorf("parse opening_hours: %w", err)
+		}
+	}
+	if utcOffset.Valid {
+		val := int(utcOffset.Int32)
+		c.UTCOffsetMinutes = &val
+	}
+	if priceLevel.Valid {
+		val := int(priceLevel.Int16)
+		c.PriceLevel = &val
+	}
+	if statusAt.Valid {
+		val := statusAt.Time
+		c.StatusChangedAt = &val
+	}
+	if siteChecked.Valid {
+		val := siteChecked.Time
+		c.WebsiteCheckedAt = &val
+	}
+	if phone.Valid {
+		val := phone.String
+		c.Phone = &val
+	}
+	if website.Valid {
+		val := website.String
+		c.Website = &val
+	}
+	if rating.Valid {
+		val := rating.Float64
+		c.Rating = &val
+	}
+	if reviews.Valid {
+		cast := int(reviews.Int64)
+		c.Reviews = &cast
+	}
+	if typeBusiness.Valid {
+		val := typeBusiness.String
+		c.TypeBusiness = &val
+	}
+	if address.Valid {
+		val := address.String
+		c.Address = &val
+	}
+	if city.Valid {
+		val := city.String
+		c.City = &val
+	}
+	if country.Valid {
+		val := country.String
+		c.Country = &val
+	}
+	if longitude.Valid {
+		val := longitude.Float64
+		c.Longitude = &val
+	}
+	if latitude.Valid {
+		val := latitude.Float64
+		c.Latitude = &val
+	}
 
-		companies = append(companies, c)
+	if len(raw) > 0 {
+		c.Raw = json.RawMessage(raw)
+	} else {
+		c.Raw = json.RawMessage([]byte("{}"))
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate companies: %w", err)
+	if scrapedAt.Valid {
+		ts := scrapedAt.Time
+		c.ScrapedAt = &ts
 	}
-	return companies, nil
+	return c, nil
 }
 
 func stringOrNil(value *string) any {
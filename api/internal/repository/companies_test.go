@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 
+	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
 )
 
@@ -52,6 +56,12 @@ func (s *stubCompanyRows) Scan(dest ...any) error {
 	lat := sql.NullFloat64{Float64: 20.0, Valid: true}
 	raw := []byte(`{"foo":"bar"}`)
 	scrapedAt := sql.NullTime{Time: created, Valid: true}
+	chainID := sql.NullString{String: "cccccccc-cccc-cccc-cccc-cccccccccccc", Valid: true}
+	sizeBucket := sql.NullString{String: "small", Valid: true}
+	status := sql.NullString{String: "closed_permanently", Valid: true}
+	statusAt := sql.NullTime{Time: updated, Valid: true}
+	language := sql.NullString{String: "en", Valid: true}
+	languageFrom := sql.NullString{String: "country", Valid: true}
 
 	*dest[0].(*uuid.UUID) = id
 	*dest[1].(*sql.NullString) = placeID
@@ -71,6 +81,12 @@ func (s *stubCompanyRows) Scan(dest ...any) error {
 	*dest[15].(*sql.NullTime) = scrapedAt
 	*dest[16].(*time.Time) = created
 	*dest[17].(*time.Time) = updated
+	*dest[18].(*sql.NullString) = chainID
+	*dest[19].(*sql.NullString) = sizeBucket
+	*dest[20].(*sql.NullString) = status
+	*dest[21].(*sql.NullTime) = statusAt
+	*dest[22].(*sql.NullString) = language
+	*dest[23].(*sql.NullString) = languageFrom
 	return nil
 }
 
@@ -87,7 +103,7 @@ func TestPGXCompaniesRepository_UpsertValidation(t *testing.T) {
 
 func TestPGXCompaniesRepository_BulkUpsertEmpty(t *testing.T) {
 	repo := &PGXCompaniesRepository{}
-	res, err := repo.BulkUpsertCompanies(context.Background(), nil)
+	res, err := repo.BulkUpsertCompanies(context.Background(), nil, ImportModeOverwrite)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -96,6 +112,92 @@ func TestPGXCompaniesRepository_BulkUpsertEmpty(t *testing.T) {
 	}
 }
 
+// stubTx serves bulk upsert statements; unimplemented pgx.Tx methods panic.
+type stubTx struct {
+	pgx.Tx
+	queryRowFunc func(ctx context.Context, query string, args ...any) pgx.Row
+	queryFunc    func(ctx context.Context, query string, args ...any) (pgx.Rows, error)
+	execFunc     func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	committed    bool
+}
+
+func (s *stubTx) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return s.queryRowFunc(ctx, query, args...)
+}
+
+func (s *stubTx) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	return s.queryFunc(ctx, query, args...)
+}
+
+func (s *stubTx) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return s.execFunc(ctx, query, args...)
+}
+
+func (s *stubTx) Commit(ctx context.Context) error {
+	s.committed = true
+	return nil
+}
+
+func (s *stubTx) Rollback(ctx context.Context) error { return nil }
+
+func TestBulkUpsertSQL(t *testing.T) {
+	overwrite, err := bulkUpsertSQL(ImportModeOverwrite)
+	if err != nil || !strings.Contains(overwrite, "phone = EXCLUDED.phone") {
+		t.Fatalf("expected overwrite to replace columns, got %q (%v)", overwrite, err)
+	}
+	fill, err := bulkUpsertSQL(ImportModeFillMissing)
+	if err != nil || !strings.Contains(fill, "phone = COALESCE(companies.phone, EXCLUDED.phone)") ||
+		!strings.Contains(fill, "WHERE (companies.phone IS NULL AND EXCLUDED.phone IS NOT NULL) OR") {
+		t.Fatalf("expected fill_missing to keep stored values, got %q (%v)", fill, err)
+	}
+	skip, err := bulkUpsertSQL(ImportModeSkipExisting)
+	if err != nil || !strings.Contains(skip, "DO NOTHING") {
+		t.Fatalf("expected skip_existing to do nothing on conflict, got %q (%v)", skip, err)
+	}
+	if _, err := bulkUpsertSQL("merge"); err == nil {
+		t.Fatalf("expected unknown mode to be rejected")
+	}
+}
+
+func TestPGXCompaniesRepository_BulkUpsertSkipExisting(t *testing.T) {
+	newID := uuid.New()
+	tx := &stubTx{queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+		if args[0] == "Existing" {
+			return &stubRows{}, nil
+		}
+		return &stubRows{scans: []func(dest ...any) error{
+			func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = newID
+				*dest[1].(*bool) = true
+				return nil
+			},
+		}}, nil
+	}}
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+
+	records := []BulkUpsertCompanyInput{
+		{Company: "Existing", Address: "Main St"},
+		{Company: "New", Address: "Side St"},
+	}
+	res, err := repo.BulkUpsertCompanies(context.Background(), records, ImportModeSkipExisting)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Inserted != 1 || res.Updated != 0 || res.Skipped != 1 || res.Total != 2 || !tx.committed {
+		t.Fatalf("unexpected result %+v (committed %v)", res, tx.committed)
+	}
+	if len(res.Records) != 2 || res.Records[0] != (BulkUpsertRecordResult{Status: BulkUpsertSkipped}) ||
+		res.Records[1].Status != BulkUpsertInserted || res.Records[1].CompanyID == nil || *res.Records[1].CompanyID != newID {
+		t.Fatalf("unexpected record results %+v", res.Records)
+	}
+
+	if _, err := repo.BulkUpsertCompanies(context.Background(), records[:1], ImportModeOverwrite); err == nil {
+		t.Fatalf("expected overwrite without a result row to fail")
+	}
+}
+
 func TestScanCompanies(t *testing.T) {
 	rows, err := scanCompanies(&stubCompanyRows{})
 	if err != nil {
@@ -114,6 +216,15 @@ func TestScanCompanies(t *testing.T) {
 	if company.ScrapedAt == nil {
 		t.Fatalf("expected scraped_at set")
 	}
+	if company.ChainID == nil || company.ChainID.String() != "cccccccc-cccc-cccc-cccc-cccccccccccc" {
+		t.Fatalf("expected chain_id set, got %+v", company.ChainID)
+	}
+	if company.SizeBucket == nil || *company.SizeBucket != "small" {
+		t.Fatalf("expected size_bucket set, got %+v", company.SizeBucket)
+	}
+	if company.BusinessStatus == nil || *company.BusinessStatus != "closed_permanently" || company.StatusChangedAt == nil {
+		t.Fatalf("expected business status set, got %+v at %v", company.BusinessStatus, company.StatusChangedAt)
+	}
 	if company.Longitude == nil || *company.Longitude != 10.0 {
 		t.Fatalf("expected longitude to be set")
 	}
@@ -122,6 +233,213 @@ func TestScanCompanies(t *testing.T) {
 	}
 }
 
+func TestPGXCompaniesRepository_ListExcludesRawByDefault(t *testing.T) {
+	var queries []string
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			queries = append(queries, query)
+			return &stubRows{}, nil
+		},
+	}}
+
+	if _, err := repo.List(context.Background(), dto.ListFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.List(context.Background(), dto.ListFilter{IncludeRaw: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(queries[0], "NULL::jsonb AS raw") {
+		t.Fatalf("expected raw to be skipped by default, got %q", queries[0])
+	}
+	if strings.Contains(queries[1], "NULL::jsonb AS raw") {
+		t.Fatalf("expected raw to be selected when included, got %q", queries[1])
+	}
+}
+
+func TestPGXCompaniesRepository_ReadsUseReplica(t *testing.T) {
+	var replicaQueries int
+	repo := &PGXCompaniesRepository{
+		pool: &stubPool{
+			queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+				t.Fatalf("expected listing reads to skip the primary, got %s", query)
+				return nil, nil
+			},
+			execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+				return pgconn.NewCommandTag("INSERT 0 1"), nil
+			},
+		},
+		reads: &stubPool{
+			queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+				replicaQueries++
+				return &stubRows{}, nil
+			},
+		},
+	}
+
+	if _, err := repo.List(context.Background(), dto.ListFilter{}); err != nil || replicaQueries != 1 {
+		t.Fatalf("expected the listing on the replica, got %d queries (%v)", replicaQueries, err)
+	}
+	if err := repo.Upsert(context.Background(), &entity.Company{Company: "Kopi"}); err != nil {
+		t.Fatalf("expected writes on the primary, got %v", err)
+	}
+}
+
+func TestPGXCompaniesRepository_ListRelevanceOrder(t *testing.T) {
+	var (
+		queries []string
+		args    [][]any
+	)
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, a ...any) (pgx.Rows, error) {
+			queries, args = append(queries, query), append(args, a)
+			return &stubRows{}, nil
+		},
+	}}
+	weight := 0.4
+
+	filters := []dto.ListFilter{
+		{Q: "kopi", ScoreWeight: &weight},
+		{Q: "kopi", Sort: "relevance", LatestRunOnly: true, ScoreWeight: &weight},
+		{Q: "kopi", Sort: "recent"},
+		{Sort: "relevance"},
+	}
+	for _, filter := range filters {
+		if _, err := repo.List(context.Background(), filter); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if !strings.Contains(queries[0], "ts_rank_cd(") || !strings.Contains(queries[0], "plainto_tsquery('simple', $3)") || !strings.Contains(queries[0], "LIMIT $5 OFFSET $6") {
+		t.Fatalf("expected searches to rank by relevance, got %q", queries[0])
+	}
+	if len(args[0]) != 6 || args[0][2] != "kopi" || args[0][3] != 0.4 {
+		t.Fatalf("unexpected relevance args %v", args[0])
+	}
+	if !strings.Contains(queries[1], "ts_rank_cd(") {
+		t.Fatalf("expected an explicit relevance sort to win over the latest run default, got %q", queries[1])
+	}
+	for i, query := range queries[2:] {
+		if strings.Contains(query, "ts_rank_cd(") {
+			t.Fatalf("query %d: expected no relevance ranking, got %q", i+2, query)
+		}
+	}
+}
+
+func TestPGXCompaniesRepository_CountAndExportCompanies(t *testing.T) {
+	var exportQuery string
+	var exportArgs []any
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.HasPrefix(query, "SELECT COUNT(*) FROM companies WHERE") || len(args) != 1 || args[0] != "Jakarta" {
+				t.Fatalf("unexpected count query %q %v", query, args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int64) = 42
+				return nil
+			}}
+		},
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			exportQuery, exportArgs = query, args
+			return &stubCompanyRows{}, nil
+		},
+	}}
+	filter := dto.ListFilter{City: "Jakarta"}
+
+	count, err := repo.CountCompanies(context.Background(), filter)
+	if err != nil || count != 42 {
+		t.Fatalf("unexpected count %d (%v)", count, err)
+	}
+
+	var exported []string
+	err = repo.ExportCompanies(context.Background(), filter, 10, func(company entity.Company) error {
+		exported = append(exported, company.Company)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exported) != 1 || exported[0] != "Acme" {
+		t.Fatalf("unexpected exported companies: %v", exported)
+	}
+	if !strings.Contains(exportQuery, "NULL::jsonb AS raw") || !strings.HasSuffix(exportQuery, "company ASC LIMIT $2") {
+		t.Fatalf("unexpected export query %q", exportQuery)
+	}
+	if len(exportArgs) != 2 || exportArgs[1] != int64(10) {
+		t.Fatalf("unexpected export args: %v", exportArgs)
+	}
+}
+
+func TestPGXCompaniesRepository_GetCompanyRaw(t *testing.T) {
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				if args[0] == uuid.Nil {
+					return pgx.ErrNoRows
+				}
+				*dest[0].(*[]byte) = []byte(`{"foo":"bar"}`)
+				return nil
+			}}
+		},
+	}}
+
+	raw, err := repo.GetCompanyRaw(context.Background(), uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"))
+	if err != nil || string(raw.Payload) != `{"foo":"bar"}` || raw.Ref != "" {
+		t.Fatalf("unexpected raw %+v (%v)", raw, err)
+	}
+	if _, err := repo.GetCompanyRaw(context.Background(), uuid.Nil); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+}
+
+func TestPGXCompaniesRepository_MarkRawOffloaded(t *testing.T) {
+	var gotQuery string
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			gotQuery = query
+			if args[2] != `{"a":1}` {
+				t.Fatalf("expected the listed payload as guard, got %v", args[2])
+			}
+			if args[1] == "raw/stale.json" {
+				return pgconn.NewCommandTag("UPDATE 0"), nil
+			}
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}}
+
+	ok, err := repo.MarkRawOffloaded(context.Background(), uuid.New(), "raw/abc.json", json.RawMessage(`{"a":1}`))
+	if err != nil || !ok {
+		t.Fatalf("expected the payload to be marked, got %v (%v)", ok, err)
+	}
+	if !strings.Contains(gotQuery, "raw_ref IS NULL AND raw = $3::jsonb") || !strings.Contains(gotQuery, "'business_status'") {
+		t.Fatalf("unexpected query %s", gotQuery)
+	}
+	if ok, err := repo.MarkRawOffloaded(context.Background(), uuid.New(), "raw/stale.json", json.RawMessage(`{"a":1}`)); err != nil || ok {
+		t.Fatalf("expected a rewritten payload to stay inline, got %v (%v)", ok, err)
+	}
+}
+
+func TestPGXCompaniesRepository_GetCompanyCountry(t *testing.T) {
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				if args[0] == uuid.Nil {
+					return pgx.ErrNoRows
+				}
+				*dest[0].(*string) = "United States"
+				return nil
+			}}
+		},
+	}}
+
+	country, err := repo.GetCompanyCountry(context.Background(), uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"))
+	if err != nil || country != "United States" {
+		t.Fatalf("unexpected country %q (%v)", country, err)
+	}
+	if _, err := repo.GetCompanyCountry(context.Background(), uuid.Nil); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+}
+
 func TestHelperConversions(t *testing.T) {
 	if stringOrNil(nil) != nil {
 		t.Fatalf("expected nil when pointer nil")
@@ -243,8 +561,8 @@ func TestPGXCompaniesRepository_UpsertEnrichment_Success(t *testing.T) {
 	repo := &PGXCompaniesRepository{pool: &stubPool{
 		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
 			called = true
-			if len(args) != 8 {
-				t.Fatalf("expected 8 args, got %d", len(args))
+			if len(args) != 9 {
+				t.Fatalf("expected 9 args, got %d", len(args))
 			}
 			if args[0] != companyID {
 				t.Fatalf("expected company id arg, got %v", args[0])
@@ -252,6 +570,9 @@ func TestPGXCompaniesRepository_UpsertEnrichment_Success(t *testing.T) {
 			if addr, _ := args[4].(*string); addr == nil || *addr != "Main St" {
 				t.Fatalf("expected address arg")
 			}
+			if terms, _ := args[8].([]string); !slices.Equal(terms, []string{"email:info@example.com", "social:acme"}) {
+				t.Fatalf("unexpected contact terms arg %v", args[8])
+			}
 			return pgconn.CommandTag{}, nil
 		},
 	}}
@@ -263,3 +584,70 @@ func TestPGXCompaniesRepository_UpsertEnrichment_Success(t *testing.T) {
 		t.Fatalf("expected exec to be called")
 	}
 }
+
+func TestPGXCompaniesRepository_ListTechnologyFilter(t *testing.T) {
+	var queries []string
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, a ...any) (pgx.Rows, error) {
+			queries = append(queries, query)
+			return &stubRows{}, nil
+		},
+	}}
+
+	for _, technologies := range [][]string{{"wordpress", "wix"}, {"wix", "none"}} {
+		if _, err := repo.List(context.Background(), dto.ListFilter{Technologies: technologies}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !strings.Contains(queries[0], "te.metadata->'technologies' ?| $1)") || strings.Contains(queries[0], "'[]'::jsonb") {
+		t.Fatalf("unexpected query %q", queries[0])
+	}
+	if !strings.Contains(queries[1], "(te.metadata->'technologies' ?| $1 OR te.metadata->'technologies' = '[]'::jsonb)") {
+		t.Fatalf("expected none to match websites without any technology, got %q", queries[1])
+	}
+}
+
+func TestPGXCompaniesRepository_ListPlaceAttributeFilters(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, a ...any) (pgx.Rows, error) {
+			query, args = q, a
+			return &stubRows{}, nil
+		},
+	}}
+	openNow := false
+	filter := dto.ListFilter{OpenNow: &openNow, PriceLevels: []int{1, 2}, Categories: []string{"cafe"}}
+	if _, err := repo.List(context.Background(), filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, fragment := range []string{"price_level = ANY($1::int[])", "categories && $2::text[]", "opening_hours IS NOT NULL AND NOT EXISTS", "make_interval(mins => utc_offset_minutes)"} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in query %q", fragment, query)
+		}
+	}
+	if levels, ok := args[0].([]int); !ok || len(levels) != 2 {
+		t.Fatalf("unexpected price level argument %v", args[0])
+	}
+}
+
+func TestUpsertCompanyArgs_PlaceAttributes(t *testing.T) {
+	level := 3
+	args := upsertCompanyArgs(&entity.Company{
+		Company:      "Kopi Kenangan",
+		OpeningHours: []entity.OpeningPeriod{{Day: 1, Open: "08:00", Close: "17:00"}},
+		PriceLevel:   &level,
+		Categories:   []string{"cafe"},
+	})
+	if got, ok := args[17].([]byte); !ok || string(got) != `[{"day":1,"open":"08:00","close":"17:00"}]` {
+		t.Fatalf("unexpected opening hours argument %v", args[17])
+	}
+	if args[18].(*int) != nil || *args[19].(*int) != 3 {
+		t.Fatalf("unexpected offset and price level arguments %v %v", args[18], args[19])
+	}
+	if none := upsertCompanyArgs(&entity.Company{Company: "Kopi"}); none[17] != nil {
+		t.Fatalf("expected NULL opening hours, got %v", none[17])
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+var (
+	// ErrCompanyAssigned is returned when a company is already assigned to someone else.
+	ErrCompanyAssigned = errors.New("company is assigned to another user")
+	// ErrAssigneeNotFound is returned when companies are assigned to a user that does not exist.
+	ErrAssigneeNotFound = errors.New("assignee not found")
+	// ErrAssignmentFilterRequired is returned when a bulk assignment would cover every company.
+	ErrAssignmentFilterRequired = errors.New("bulk assignment needs at least one filter")
+)
+
+// CompanyAssignmentsRepository assigns companies to the sales reps working them.
+type CompanyAssignmentsRepository interface {
+	AssignCompany(ctx context.Context, assignment *entity.CompanyAssignment, takeover bool) error
+	UnassignCompany(ctx context.Context, companyID uuid.UUID, onlyUser *uuid.UUID) (bool, error)
+	AssignCompanies(ctx context.Context, filter dto.ListFilter, userID *uuid.UUID, assignedBy uuid.UUID, onlyUnassigned bool) (int64, error)
+}
+
+// AssignCompany assigns a company and populates the assignment time. Without takeover, a company
+// assigned to someone else is left alone and ErrCompanyAssigned returned.
+func (r *PGXCompaniesRepository) AssignCompany(ctx context.Context, assignment *entity.CompanyAssignment, takeover bool) error {
+	if assignment == nil {
+		return errors.New("company assignment is nil")
+	}
+	conflict := `DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			assigned_by = EXCLUDED.assigned_by,
+			assigned_at = EXCLUDED.assigned_at`
+	if !takeover {
+		// Claiming a company one already holds keeps the original assignment.
+		conflict = `DO UPDATE SET user_id = company_assignments.user_id
+		WHERE company_assignments.user_id = EXCLUDED.user_id`
+	}
+	query := `
+		INSERT INTO company_assignments (company_id, user_id, assigned_by, assigned_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (company_id) ` + conflict + `
+		RETURNING assigned_by, assigned_at`
+
+	err := r.pool.QueryRow(ctx, query, assignment.CompanyID, assignment.UserID, assignment.AssignedBy).
+		Scan(&assignment.AssignedBy, &assignment.AssignedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCompanyAssigned
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			if pgErr.ConstraintName == "company_assignments_company_id_fkey" {
+				return ErrCompanyNotFound
+			}
+			return ErrAssigneeNotFound
+		}
+		return fmt.Errorf("assign company: %w", err)
+	}
+	return nil
+}
+
+// UnassignCompany returns a company to the pool. With onlyUser, only an assignment to that user is
+// removed. It reports whether an assignment was removed.
+func (r *PGXCompaniesRepository) UnassignCompany(ctx context.Context, companyID uuid.UUID, onlyUser *uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM company_assignments
+		WHERE company_id = $1 AND ($2::uuid IS NULL OR user_id = $2)
+	`, companyID, onlyUser)
+	if err != nil {
+		return false, fmt.Errorf("unassign company: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AssignCompanies assigns every company matching filter to userID, or returns them to the pool when
+// userID is nil, and reports how many assignments changed. onlyUnassigned leaves companies someone
+// already works alone. Pagination is ignored, and a filter narrowing nothing is refused.
+func (r *PGXCompaniesRepository) AssignCompanies(ctx context.Context, filter dto.ListFilter, userID *uuid.UUID, assignedBy uuid.UUID, onlyUnassigned bool) (int64, error) {
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(where.clauses) == 0 {
+		return 0, ErrAssignmentFilterRequired
+	}
+	matching := "SELECT id FROM companies" + where.where()
+
+	var (
+		query string
+		args  = where.args
+		// The clauses are concatenated, not formatted, as they may hold a literal %.
+		values = fmt.Sprintf("$%d, $%d", where.next, where.next+1)
+	)
+	switch {
+	case userID == nil:
+		query = `DELETE FROM company_assignments WHERE company_id IN (` + matching + `)`
+	case onlyUnassigned:
+		query = `
+			INSERT INTO company_assignments (company_id, user_id, assigned_by, assigned_at)
+			SELECT id, ` + values + `, NOW() FROM (` + matching + `) matching
+			ON CONFLICT (company_id) DO NOTHING`
+		args = append(args, *userID, assignedBy)
+	default:
+		query = `
+			INSERT INTO company_assignments (company_id, user_id, assigned_by, assigned_at)
+			SELECT id, ` + values + `, NOW() FROM (` + matching + `) matching
+			ON CONFLICT (company_id) DO UPDATE SET
+				user_id = EXCLUDED.user_id,
+				assigned_by = EXCLUDED.assigned_by,
+				assigned_at = EXCLUDED.assigned_at
+			WHERE company_assignments.user_id <> EXCLUDED.user_id`
+		args = append(args, *userID, assignedBy)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return 0, ErrAssigneeNotFound
+		}
+		return 0, fmt.Errorf("assign companies: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
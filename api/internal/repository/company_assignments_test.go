@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompaniesRepository_AssignCompanyHeldByAnother(t *testing.T) {
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "WHERE company_assignments.user_id = EXCLUDED.user_id") {
+				t.Fatalf("expected a claim that keeps other assignments, got %s", query)
+			}
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}}
+
+	err := repo.AssignCompany(context.Background(), &entity.CompanyAssignment{CompanyID: uuid.New(), UserID: uuid.New()}, false)
+	if !errors.Is(err, ErrCompanyAssigned) {
+		t.Fatalf("expected ErrCompanyAssigned, got %v", err)
+	}
+}
+
+func TestPGXCompaniesRepository_AssignCompanies(t *testing.T) {
+	var query string
+	var args []any
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, q string, a ...any) (pgconn.CommandTag, error) {
+			query, args = q, a
+			return pgconn.NewCommandTag("INSERT 0 12"), nil
+		},
+	}}
+
+	if _, err := repo.AssignCompanies(context.Background(), dto.ListFilter{}, nil, uuid.New(), false); !errors.Is(err, ErrAssignmentFilterRequired) {
+		t.Fatalf("expected an unfiltered bulk assignment to be refused, got %v", err)
+	}
+
+	rep, admin := uuid.New(), uuid.New()
+	changed, err := repo.AssignCompanies(context.Background(), dto.ListFilter{City: "Bandung"}, &rep, admin, true)
+	if err != nil || changed != 12 {
+		t.Fatalf("expected 12 assignments, got %d (%v)", changed, err)
+	}
+	if !strings.Contains(query, "SELECT id, $2, $3, NOW()") || !strings.Contains(query, "DO NOTHING") {
+		t.Fatalf("unexpected bulk assignment query %s", query)
+	}
+	if len(args) != 3 || args[0] != "Bandung" || args[1] != rep || args[2] != admin {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrAttachmentNotFound indicates the requested attachment does not exist.
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// CompanyAttachmentsRepository persists metadata of files attached to companies.
+type CompanyAttachmentsRepository interface {
+	Create(ctx context.Context, attachment *entity.CompanyAttachment) error
+	Get(ctx context.Context, id uuid.UUID) (*entity.CompanyAttachment, error)
+	ListByCompany(ctx context.Context, companyID uuid.UUID) ([]entity.CompanyAttachment, error)
+	MarkReady(ctx context.Context, id uuid.UUID, sizeBytes int64, uploadedAt time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// UsageByUser sums the sizes of a user's attachments, pending ones included until they are
+	// confirmed or swept.
+	UsageByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// UsageByOrg returns the org a user joined through an accepted invitation and the sizes summed
+	// over the attachments of every member of that org. Users without an org get an empty name.
+	UsageByOrg(ctx context.Context, userID uuid.UUID) (string, int64, error)
+	// ListExpiredPending returns up to limit pending attachments created before the given instant,
+	// oldest first.
+	ListExpiredPending(ctx context.Context, before time.Time, limit int) ([]entity.CompanyAttachment, error)
+}
+
+// PGXCompanyAttachmentsRepository implements CompanyAttachmentsRepository using pgx.
+type PGXCompanyAttachmentsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXCompanyAttachmentsRepository wires a pgx backed attachments repository.
+func NewPGXCompanyAttachmentsRepository(pool *pgxpool.Pool) *PGXCompanyAttachmentsRepository {
+	return &PGXCompanyAttachmentsRepository{pool: pool}
+}
+
+const companyAttachmentColumns = `
+	id, company_id, uploaded_by, filename, content_type, size_bytes, storage_key, status, created_at, uploaded_at
+`
+
+// Create inserts a pending attachment and populates its creation timestamp.
+func (r *PGXCompanyAttachmentsRepository) Create(ctx context.Context, attachment *entity.CompanyAttachment) error {
+	if attachment == nil {
+		return fmt.Errorf("attachment payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO company_attachments (id, company_id, uploaded_by, filename, content_type, size_bytes, storage_key, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`, attachment.ID, attachment.CompanyID, attachment.UploadedBy, attachment.Filename, attachment.ContentType,
+		attachment.SizeBytes, attachment.StorageKey, attachment.Status).Scan(&attachment.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "company_attachments_company_id_fkey" {
+			return ErrCompanyNotFound
+		}
+		return fmt.Errorf("insert attachment: %w", err)
+	}
+	return nil
+}
+
+// Get returns a single attachment.
+func (r *PGXCompanyAttachmentsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.CompanyAttachment, error) {
+	attachment, err := scanCompanyAttachment(r.pool.QueryRow(ctx, `SELECT `+companyAttachmentColumns+` FROM company_attachments WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// ListByCompany returns the uploaded attachments of a company, newest first.
+func (r *PGXCompanyAttachmentsRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]entity.CompanyAttachment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+companyAttachmentColumns+`
+		FROM company_attachments
+		WHERE company_id = $1 AND status = $2
+		ORDER BY created_at DESC, id
+	`, companyID, entity.AttachmentStatusReady)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]entity.CompanyAttachment, 0)
+	for rows.Next() {
+		attachment, err := scanCompanyAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// MarkReady records that the attachment file was uploaded, with its stored size.
+func (r *PGXCompanyAttachmentsRepository) MarkReady(ctx context.Context, id uuid.UUID, sizeBytes int64, uploadedAt time.Time) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE company_attachments
+		SET status = $2, size_bytes = $3, uploaded_at = $4
+		WHERE id = $1
+	`, id, entity.AttachmentStatusReady, sizeBytes, uploadedAt)
+	if err != nil {
+		return fmt.Errorf("update attachment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// Delete removes an attachment record.
+func (r *PGXCompanyAttachmentsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM company_attachments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// UsageByUser implements CompanyAttachmentsRepository.
+func (r *PGXCompanyAttachmentsRepository) UsageByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var used int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(size_bytes), 0)::bigint
+		FROM company_attachments
+		WHERE uploaded_by = $1
+	`, userID).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("sum attachment usage: %w", err)
+	}
+	return used, nil
+}
+
+// UsageByOrg implements CompanyAttachmentsRepository. Orgs are matched case-insensitively, as
+// administrators type them freely when inviting.
+func (r *PGXCompanyAttachmentsRepository) UsageByOrg(ctx context.Context, userID uuid.UUID) (string, int64, error) {
+	var (
+		org  string
+		used int64
+	)
+	err := r.pool.QueryRow(ctx, `
+		WITH member AS (
+			SELECT btrim(org) AS org
+			FROM user_invitations
+			WHERE user_id = $1 AND accepted_at IS NOT NULL AND btrim(COALESCE(org, '')) <> ''
+			ORDER BY accepted_at DESC
+			LIMIT 1
+		)
+		SELECT m.org, COALESCE((
+			SELECT SUM(a.size_bytes)
+			FROM company_attachments a
+			WHERE a.uploaded_by IN (
+				SELECT i.user_id
+				FROM user_invitations i
+				WHERE i.accepted_at IS NOT NULL AND lower(btrim(i.org)) = lower(m.org)
+			)
+		), 0)::bigint
+		FROM member m
+	`, userID).Scan(&org, &used)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", 0, nil
+		}
+		return "", 0, fmt.Errorf("sum org attachment usage: %w", err)
+	}
+	return org, used, nil
+}
+
+// ListExpiredPending implements CompanyAttachmentsRepository.
+func (r *PGXCompanyAttachmentsRepository) ListExpiredPending(ctx context.Context, before time.Time, limit int) ([]entity.CompanyAttachment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+companyAttachmentColumns+`
+		FROM company_attachments
+		WHERE status = $1 AND created_at < $2
+		ORDER BY created_at, id
+		LIMIT $3
+	`, entity.AttachmentStatusPending, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list expired attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]entity.CompanyAttachment, 0)
+	for rows.Next() {
+		attachment, err := scanCompanyAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate expired attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+func scanCompanyAttachment(row pgx.Row) (*entity.CompanyAttachment, error) {
+	var attachment entity.CompanyAttachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.CompanyID,
+		&attachment.UploadedBy,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.SizeBytes,
+		&attachment.StorageKey,
+		&attachment.Status,
+		&attachment.CreatedAt,
+		&attachment.UploadedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan attachment: %w", err)
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompanyAttachmentsRepository_CreateUnknownCompany(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23503", ConstraintName: "company_attachments_company_id_fkey"}
+			}}
+		},
+	}
+	repo := &PGXCompanyAttachmentsRepository{pool: pool}
+
+	err := repo.Create(context.Background(), &entity.CompanyAttachment{ID: uuid.New(), CompanyID: uuid.New()})
+	if !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+}
+
+func TestPGXCompanyAttachmentsRepository_NotFound(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		},
+	}
+	repo := &PGXCompanyAttachmentsRepository{pool: pool}
+
+	if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected ErrAttachmentNotFound on get, got %v", err)
+	}
+	if err := repo.Delete(context.Background(), uuid.New()); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected ErrAttachmentNotFound on delete, got %v", err)
+	}
+}
+
+func TestPGXCompanyAttachmentsRepository_UsageByOrgWithoutOrg(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}
+	repo := &PGXCompanyAttachmentsRepository{pool: pool}
+
+	org, used, err := repo.UsageByOrg(context.Background(), uuid.New())
+	if err != nil || org != "" || used != 0 {
+		t.Fatalf("expected no org for a user invited without one, got %q, %d, %v", org, used, err)
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// CompanyChangesRepository serves the updated_at/tombstone based company sync feed.
+type CompanyChangesRepository interface {
+	ListCompanyChanges(ctx context.Context, after entity.CompanyChangeCursor, settle time.Duration, limit int) ([]entity.CompanyChange, error)
+}
+
+// ListCompanyChanges returns companies updated and deleted after the cursor, ordered by change time
+// then id. Changes younger than settle are held back: updated_at is the writing transaction's start
+// time, so rows of a transaction still committing could otherwise land behind a cursor already
+// handed out.
+func (r *PGXCompaniesRepository) ListCompanyChanges(ctx context.Context, after entity.CompanyChangeCursor, settle time.Duration, limit int) ([]entity.CompanyChange, error) {
+	rows, err := r.pool.Query(ctx, `
+		(
+			SELECT FALSE AS deleted, id, updated_at AS changed_at
+			FROM companies
+			WHERE (updated_at, id) > ($1, $2)
+				AND updated_at < NOW() - make_interval(secs => $3)
+			ORDER BY updated_at, id
+			LIMIT $4
+		)
+		UNION ALL
+		(
+			SELECT TRUE, company_id, deleted_at
+			FROM company_tombstones
+			WHERE (deleted_at, company_id) > ($1, $2)
+				AND deleted_at < NOW() - make_interval(secs => $3)
+			ORDER BY deleted_at, company_id
+			LIMIT $4
+		)
+		ORDER BY changed_at, id
+		LIMIT $4
+	`, after.ChangedAt, after.ID, settle.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list company changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]entity.CompanyChange, 0)
+	var upserts []uuid.UUID
+	for rows.Next() {
+		var (
+			change  entity.CompanyChange
+			deleted bool
+		)
+		if err := rows.Scan(&deleted, &change.CompanyID, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan company change: %w", err)
+		}
+		change.Cursor = entity.CompanyChangeCursor{ChangedAt: change.ChangedAt, ID: change.CompanyID}
+		if deleted {
+			change.Operation = entity.ChangeOperationDelete
+		} else {
+			upserts = append(upserts, change.CompanyID)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company changes: %w", err)
+	}
+	if len(upserts) == 0 {
+		return changes, nil
+	}
+
+	companyRows, err := r.pool.Query(ctx, "SELECT "+companyColumns("NULL::jsonb AS raw")+" FROM companies WHERE id = ANY($1)", upserts)
+	if err != nil {
+		return nil, fmt.Errorf("load changed companies: %w", err)
+	}
+	defer companyRows.Close()
+	companies, err := scanCompanies(companyRows)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*entity.Company, len(companies))
+	for i := range companies {
+		byID[companies[i].ID] = &companies[i]
+	}
+
+	for i := range changes {
+		if changes[i].Operation == entity.ChangeOperationDelete {
+			continue
+		}
+		company, ok := byID[changes[i].CompanyID]
+		switch {
+		case !ok:
+			// Deleted since the first query; its tombstone follows later in the feed.
+			changes[i].Operation = entity.ChangeOperationDelete
+		case company.CreatedAt.After(after.ChangedAt):
+			changes[i].Operation = entity.ChangeOperationCreate
+			changes[i].Company = company
+		default:
+			changes[i].Operation = entity.ChangeOperationUpdate
+			changes[i].Company = company
+		}
+	}
+	return changes, nil
+}
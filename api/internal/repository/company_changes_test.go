@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompaniesRepository_ListCompanyChanges(t *testing.T) {
+	after := entity.CompanyChangeCursor{
+		ChangedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		ID:        uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+	}
+	kept := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	tombstoned := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	vanished := uuid.MustParse("cccccccc-cccc-cccc-cccc-cccccccccccc")
+	changed := after.ChangedAt.Add(time.Minute)
+
+	changeRow := func(deleted bool, id uuid.UUID) func(dest ...any) error {
+		return func(dest ...any) error {
+			*dest[0].(*bool) = deleted
+			*dest[1].(*uuid.UUID) = id
+			*dest[2].(*time.Time) = changed
+			return nil
+		}
+	}
+
+	calls := 0
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			calls++
+			if calls == 1 {
+				if !strings.Contains(query, "company_tombstones") || !strings.Contains(query, "make_interval") {
+					t.Fatalf("expected tombstones and settle window in query, got %q", query)
+				}
+				if args[0] != after.ChangedAt || args[1] != after.ID || args[2] != float64(30) || args[3] != 10 {
+					t.Fatalf("unexpected args: %v", args)
+				}
+				return &stubRows{scans: []func(dest ...any) error{
+					changeRow(false, kept),
+					changeRow(true, tombstoned),
+					changeRow(false, vanished),
+				}}, nil
+			}
+			ids, ok := args[0].([]uuid.UUID)
+			if !ok || len(ids) != 2 || ids[0] != kept || ids[1] != vanished {
+				t.Fatalf("unexpected company ids: %v", args[0])
+			}
+			if !strings.Contains(query, "NULL::jsonb AS raw") {
+				t.Fatalf("expected raw payload to be skipped, got %q", query)
+			}
+			return &stubCompanyRows{}, nil
+		},
+	}}
+
+	changes, err := repo.ListCompanyChanges(context.Background(), after, 30*time.Second, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d", len(changes))
+	}
+	if changes[0].Operation != entity.ChangeOperationCreate || changes[0].Company == nil || changes[0].Company.Company != "Acme" {
+		t.Fatalf("unexpected upsert change: %+v", changes[0])
+	}
+	if changes[0].Cursor != (entity.CompanyChangeCursor{ChangedAt: changed, ID: kept}) {
+		t.Fatalf("unexpected cursor: %+v", changes[0].Cursor)
+	}
+	for _, change := range changes[1:] {
+		if change.Operation != entity.ChangeOperationDelete || change.Company != nil {
+			t.Fatalf("expected delete without snapshot, got %+v", change)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CompanyCountryRepository looks up the country stored for a company.
+type CompanyCountryRepository interface {
+	GetCompanyCountry(ctx context.Context, companyID uuid.UUID) (string, error)
+}
+
+// GetCompanyCountry returns the country of a company, or an empty string when it is unknown.
+func (r *PGXCompaniesRepository) GetCompanyCountry(ctx context.Context, companyID uuid.UUID) (string, error) {
+	var country string
+	if err := r.pool.QueryRow(ctx, `SELECT COALESCE(country, '') FROM companies WHERE id = $1`, companyID).Scan(&country); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCompanyNotFound
+		}
+		return "", fmt.Errorf("get company country: %w", err)
+	}
+	return country, nil
+}
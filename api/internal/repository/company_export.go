@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// CompanyExportRepository counts and streams the companies of a listing filter for exports.
+type CompanyExportRepository interface {
+	CountCompanies(ctx context.Context, filter dto.ListFilter) (int64, error)
+	ExportCompanies(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.Company) error) error
+}
+
+// CountCompanies counts the companies matching filter, ignoring pagination.
+func (r *PGXCompaniesRepository) CountCompanies(ctx context.Context, filter dto.ListFilter) (_ int64, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	from := r.listSource(filter, "NULL::jsonb AS raw").from
+	if err := r.reader().QueryRow(ctx, "SELECT COUNT(*)"+from+where.where(), where.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count companies: %w", err)
+	}
+	return count, nil
+}
+
+// ExportCompanies calls fn for at most limit companies matching filter, in listing order, without
+// holding the whole result in memory. A limit of zero exports every match.
+func (r *PGXCompaniesRepository) ExportCompanies(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.Company) error) error {
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return err
+	}
+	order, orderArgs := listOrder(where.filter, where.next)
+	query := r.listSource(filter, "NULL::jsonb AS raw").selectClause(false) + where.where() + " ORDER BY " + order
+	args := append(where.args, orderArgs...)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", where.next+len(orderArgs))
+		args = append(args, limit)
+	}
+
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("export companies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		company, err := scanCompany(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(company); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate companies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Rating facet buckets, highest first. Each bucket is closed on its lower bound.
+const (
+	RatingBucketExcellent = "4.5+"
+	RatingBucketGood      = "4.0-4.5"
+	RatingBucketAverage   = "3.0-4.0"
+	RatingBucketLow       = "below_3.0"
+	RatingBucketUnrated   = "unrated"
+)
+
+// RatingBuckets lists the rating facet buckets in display order.
+var RatingBuckets = []string{RatingBucketExcellent, RatingBucketGood, RatingBucketAverage, RatingBucketLow, RatingBucketUnrated}
+
+// WebsiteStatusBuckets lists the website facet values; they match the website listing filter.
+var WebsiteStatusBuckets = []string{"available", "missing"}
+
+// CompanyFacetsRepository counts listing results per filterable dimension.
+type CompanyFacetsRepository interface {
+	Facets(ctx context.Context, filter dto.ListFilter, limit int) (*entity.CompanyFacets, error)
+}
+
+// Facets groups the companies matching filter by city, business type, category, website status and
+// rating bucket. City, business type and category facets keep the limit most common values; a
+// company counts once for each of its categories.
+func (r *PGXCompaniesRepository) Facets(ctx context.Context, filter dto.ListFilter, limit int) (_ *entity.CompanyFacets, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// The filtered set is materialised once and grouped per facet.
+	query := fmt.Sprintf(`
+		WITH filtered AS MATERIALIZED (
+			SELECT city, type_business, categories, website, rating
+			FROM companies%[1]s
+		)
+		(
+			SELECT 'city', city, COUNT(*)
+			FROM filtered
+			WHERE city IS NOT NULL AND city <> ''
+			GROUP BY city
+			ORDER BY COUNT(*) DESC, city ASC
+			LIMIT $%[2]d
+		)
+		UNION ALL
+		(
+			SELECT 'type_business', type_business, COUNT(*)
+			FROM filtered
+			WHERE type_business IS NOT NULL AND type_business <> ''
+			GROUP BY type_business
+			ORDER BY COUNT(*) DESC, type_business ASC
+			LIMIT $%[2]d
+		)
+		UNION ALL
+		(
+			SELECT 'category', category, COUNT(*)
+			FROM filtered, unnest(categories) AS category
+			GROUP BY category
+			ORDER BY COUNT(*) DESC, category ASC
+			LIMIT $%[2]d
+		)
+		UNION ALL
+		SELECT 'website_status', CASE WHEN website IS NULL THEN 'missing' ELSE 'available' END, COUNT(*)
+		FROM filtered
+		GROUP BY 2
+		UNION ALL
+		SELECT
+			'rating',
+			CASE
+				WHEN rating IS NULL THEN '%[3]s'
+				WHEN rating >= 4.5 THEN '%[4]s'
+				WHEN rating >= 4.0 THEN '%[5]s'
+				WHEN rating >= 3.0 THEN '%[6]s'
+				ELSE '%[7]s'
+			END,
+			COUNT(*)
+		FROM filtered
+		GROUP BY 2
+	`, where.where(), where.next, RatingBucketUnrated, RatingBucketExcellent, RatingBucketGood, RatingBucketAverage, RatingBucketLow)
+
+	args := append(where.args, limit)
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate company facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &entity.CompanyFacets{
+		Cities:        []entity.StatBucket{},
+		BusinessTypes: []entity.StatBucket{},
+		Categories:    []entity.StatBucket{},
+		WebsiteStatus: zeroBuckets(WebsiteStatusBuckets),
+		Ratings:       zeroBuckets(RatingBuckets),
+	}
+	for rows.Next() {
+		var (
+			facet  string
+			bucket entity.StatBucket
+		)
+		if err := rows.Scan(&facet, &bucket.Name, &bucket.Companies); err != nil {
+			return nil, fmt.Errorf("scan company facet: %w", err)
+		}
+		switch facet {
+		case "city":
+			facets.Cities = append(facets.Cities, bucket)
+		case "type_business":
+			facets.BusinessTypes = append(facets.BusinessTypes, bucket)
+		case "category":
+			facets.Categories = append(facets.Categories, bucket)
+		case "website_status":
+			setBucketCount(facets.WebsiteStatus, bucket)
+		case "rating":
+			setBucketCount(facets.Ratings, bucket)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company facets: %w", err)
+	}
+
+	// UNION ALL does not guarantee the branch order survives, so restore it here.
+	sortBuckets(facets.Cities)
+	sortBuckets(facets.BusinessTypes)
+	sortBuckets(facets.Categories)
+	return facets, nil
+}
+
+// zeroBuckets returns one empty bucket per name so fixed facets always list every value.
+func zeroBuckets(names []string) []entity.StatBucket {
+	buckets := make([]entity.StatBucket, len(names))
+	for i, name := range names {
+		buckets[i] = entity.StatBucket{Name: name}
+	}
+	return buckets
+}
+
+func setBucketCount(buckets []entity.StatBucket, counted entity.StatBucket) {
+	for i := range buckets {
+		if buckets[i].Name == counted.Name {
+			buckets[i].Companies = counted.Companies
+			return
+		}
+	}
+}
+
+func sortBuckets(buckets []entity.StatBucket) {
+	sort.SliceStable(buckets, func(i, j int) bool {
+		if buckets[i].Companies != buckets[j].Companies {
+			return buckets[i].Companies > buckets[j].Companies
+		}
+		return buckets[i].Name < buckets[j].Name
+	})
+}
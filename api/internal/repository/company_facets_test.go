@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+func facetRow(facet, name string, count int64) func(dest ...any) error {
+	return func(dest ...any) error {
+		*dest[0].(*string) = facet
+		*dest[1].(*string) = name
+		*dest[2].(*int64) = count
+		return nil
+	}
+}
+
+func TestPGXCompaniesRepository_Facets(t *testing.T) {
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "LOWER(city) = LOWER($1)") || !strings.Contains(query, "LIMIT $2") {
+				t.Fatalf("expected listing filter and facet limit in query, got %q", query)
+			}
+			if len(args) != 2 || args[0] != "Jakarta" || args[1] != 5 {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				facetRow("city", "Jakarta", 7),
+				facetRow("type_business", "cafe", 2),
+				facetRow("type_business", "bakery", 5),
+				facetRow("category", "cafe", 3),
+				facetRow("category", "bakery", 6),
+				facetRow("website_status", "missing", 3),
+				facetRow("rating", RatingBucketGood, 4),
+				facetRow("rating", RatingBucketUnrated, 1),
+			}}, nil
+		},
+	}}
+
+	facets, err := repo.Facets(context.Background(), dto.ListFilter{City: "Jakarta"}, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(facets.Cities) != 1 || facets.Cities[0].Companies != 7 {
+		t.Fatalf("unexpected city facet: %+v", facets.Cities)
+	}
+	if len(facets.BusinessTypes) != 2 || facets.BusinessTypes[0].Name != "bakery" {
+		t.Fatalf("expected business types ordered by count, got %+v", facets.BusinessTypes)
+	}
+	if len(facets.Categories) != 2 || facets.Categories[0].Name != "bakery" || facets.Categories[1].Companies != 3 {
+		t.Fatalf("expected categories ordered by count, got %+v", facets.Categories)
+	}
+	if len(facets.WebsiteStatus) != 2 || facets.WebsiteStatus[0].Name != "available" || facets.WebsiteStatus[0].Companies != 0 || facets.WebsiteStatus[1].Companies != 3 {
+		t.Fatalf("unexpected website facet: %+v", facets.WebsiteStatus)
+	}
+	if len(facets.Ratings) != len(RatingBuckets) || facets.Ratings[1].Companies != 4 || facets.Ratings[4].Companies != 1 {
+		t.Fatalf("unexpected rating facet: %+v", facets.Ratings)
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// CompanyImagesRepository stores the logo and Maps photo of companies and the URLs of their copies
+// mirrored to object storage.
+type CompanyImagesRepository interface {
+	SaveCompanyImages(ctx context.Context, companyID uuid.UUID, logoURL, photoReference *string) error
+	ListImagesToMirror(ctx context.Context, limit int) ([]entity.CompanyImages, error)
+	SaveImageMirror(ctx context.Context, images entity.CompanyImages, mirroredAt time.Time) error
+}
+
+// PGXCompanyImagesRepository implements CompanyImagesRepository using pgx.
+type PGXCompanyImagesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXCompanyImagesRepository wires a pgx backed company images repository.
+func NewPGXCompanyImagesRepository(pool *pgxpool.Pool) *PGXCompanyImagesRepository {
+	return &PGXCompanyImagesRepository{pool: pool}
+}
+
+// saveCompanyImagesSQL keeps the stored image a new enrichment did not find. An image that changes
+// loses its mirrored copy and is queued for mirroring again; every SET expression reads the row as
+// it was before the update.
+const saveCompanyImagesSQL = `
+	UPDATE companies SET
+		logo_mirror_url = CASE WHEN $2::text IS NOT NULL AND logo_url IS DISTINCT FROM $2 THEN NULL ELSE logo_mirror_url END,
+		photo_mirror_url = CASE WHEN $3::text IS NOT NULL AND photo_reference IS DISTINCT FROM $3 THEN NULL ELSE photo_mirror_url END,
+		images_mirrored_at = CASE
+			WHEN ($2::text IS NOT NULL AND logo_url IS DISTINCT FROM $2) OR ($3::text IS NOT NULL AND photo_reference IS DISTINCT FROM $3) THEN NULL
+			ELSE images_mirrored_at
+		END,
+		logo_url = COALESCE($2, logo_url),
+		photo_reference = COALESCE($3, photo_reference)
+	WHERE id = $1
+`
+
+// SaveCompanyImages stores the logo URL and Maps photo reference enrichment found for a company;
+// nil leaves the stored value alone.
+func (r *PGXCompanyImagesRepository) SaveCompanyImages(ctx context.Context, companyID uuid.UUID, logoURL, photoReference *string) error {
+	if logoURL == nil && photoReference == nil {
+		return nil
+	}
+	if _, err := r.pool.Exec(ctx, saveCompanyImagesSQL, companyID, logoURL, photoReference); err != nil {
+		return fmt.Errorf("save company images: %w", err)
+	}
+	return nil
+}
+
+// ListImagesToMirror returns companies with a logo or photo not mirrored since it was stored.
+func (r *PGXCompanyImagesRepository) ListImagesToMirror(ctx context.Context, limit int) ([]entity.CompanyImages, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, logo_url, photo_reference, logo_mirror_url, photo_mirror_url FROM companies
+		WHERE images_mirrored_at IS NULL AND (logo_url IS NOT NULL OR photo_reference IS NOT NULL)
+		ORDER BY id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list images to mirror: %w", err)
+	}
+	defer rows.Close()
+
+	var images []entity.CompanyImages
+	for rows.Next() {
+		var image entity.CompanyImages
+		if err := rows.Scan(&image.CompanyID, &image.LogoURL, &image.PhotoReference, &image.LogoMirrorURL, &image.PhotoMirrorURL); err != nil {
+			return nil, fmt.Errorf("scan images to mirror: %w", err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate images to mirror: %w", err)
+	}
+	return images, nil
+}
+
+// SaveImageMirror stores the mirrored copies of a company's images and marks the attempt. A
+// company whose images changed while they were mirrored is left alone, to be mirrored again.
+func (r *PGXCompanyImagesRepository) SaveImageMirror(ctx context.Context, images entity.CompanyImages, mirroredAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE companies
+		SET logo_mirror_url = $4, photo_mirror_url = $5, images_mirrored_at = $6
+		WHERE id = $1 AND logo_url IS NOT DISTINCT FROM $2 AND photo_reference IS NOT DISTINCT FROM $3
+	`, images.CompanyID, images.LogoURL, images.PhotoReference, images.LogoMirrorURL, images.PhotoMirrorURL, mirroredAt)
+	if err != nil {
+		return fmt.Errorf("save image mirror: %w", err)
+	}
+	return nil
+}
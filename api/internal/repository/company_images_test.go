@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompanyImagesRepository_SaveCompanyImages(t *testing.T) {
+	var queries []string
+	repo := &PGXCompanyImagesRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			queries = append(queries, query)
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}}
+
+	if err := repo.SaveCompanyImages(context.Background(), uuid.New(), nil, nil); err != nil || len(queries) != 0 {
+		t.Fatalf("expected an enrichment without images to leave the company alone, got %v %v", queries, err)
+	}
+	logo := "https://kopi.co.id/logo.png"
+	if err := repo.SaveCompanyImages(context.Background(), uuid.New(), &logo, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(queries[0], "logo_url = COALESCE($2, logo_url)") || !strings.Contains(queries[0], "THEN NULL ELSE logo_mirror_url END") {
+		t.Fatalf("expected a changed logo to lose its mirror, got %q", queries[0])
+	}
+}
+
+func TestPGXCompanyImagesRepository_SaveImageMirror(t *testing.T) {
+	var query string
+	var args []any
+	repo := &PGXCompanyImagesRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, q string, a ...any) (pgconn.CommandTag, error) {
+			query, args = q, a
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}}
+
+	logo, mirror := "https://kopi.co.id/logo.png", "https://cdn.example.com/logo.png"
+	images := entity.CompanyImages{CompanyID: uuid.New(), LogoURL: &logo, LogoMirrorURL: &mirror}
+	if err := repo.SaveImageMirror(context.Background(), images, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "logo_url IS NOT DISTINCT FROM $2 AND photo_reference IS NOT DISTINCT FROM $3") {
+		t.Fatalf("expected images changed meanwhile to be left alone, got %q", query)
+	}
+	if len(args) != 6 || args[3] != images.LogoMirrorURL {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
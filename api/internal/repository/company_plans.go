@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// CompanyPlansRepository explains the queries behind company listings, so administrators can
+// check which indexes they use.
+type CompanyPlansRepository interface {
+	// ExplainList returns the plan of the statement List runs for filter; with analyze the
+	// statement is run and the plan carries actual rows, timings and buffers.
+	ExplainList(ctx context.Context, filter dto.ListFilter, analyze bool) (*entity.QueryPlan, error)
+}
+
+// explainOutput is the part of EXPLAIN (FORMAT JSON) read into a QueryPlan.
+type explainOutput struct {
+	Plan          explainNode `json:"Plan"`
+	PlanningTime  *float64    `json:"Planning Time"`
+	ExecutionTime *float64    `json:"Execution Time"`
+}
+
+type explainNode struct {
+	NodeType     string        `json:"Node Type"`
+	RelationName string        `json:"Relation Name"`
+	IndexName    string        `json:"Index Name"`
+	TotalCost    float64       `json:"Total Cost"`
+	Plans        []explainNode `json:"Plans"`
+}
+
+// ExplainList explains the listing on the pool List reads from, so the plan is the one a replica
+// would pick when reads go to one.
+func (r *PGXCompaniesRepository) ExplainList(ctx context.Context, filter dto.ListFilter, analyze bool) (_ *entity.QueryPlan, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	query, args, err := r.listQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	options := "FORMAT JSON"
+	if analyze {
+		options = "ANALYZE, BUFFERS, FORMAT JSON"
+	}
+	var raw []byte
+	if err := r.reader().QueryRow(ctx, "EXPLAIN ("+options+") "+query, args...).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("explain companies listing: %w", err)
+	}
+	return newQueryPlan(query, args, analyze, raw)
+}
+
+// newQueryPlan summarises the EXPLAIN output raw of query.
+func newQueryPlan(query string, args []any, analyzed bool, raw []byte) (*entity.QueryPlan, error) {
+	var outputs []explainOutput
+	if err := json.Unmarshal(raw, &outputs); err != nil {
+		return nil, fmt.Errorf("decode query plan: %w", err)
+	}
+	if len(outputs) == 0 {
+		return nil, errors.New("decode query plan: empty output")
+	}
+	output := outputs[0]
+	plan := &entity.QueryPlan{
+		SQL:         query,
+		Args:        args,
+		Analyzed:    analyzed,
+		Indexes:     []string{},
+		SeqScans:    []string{},
+		TotalCost:   output.Plan.TotalCost,
+		PlanningMS:  output.PlanningTime,
+		ExecutionMS: output.ExecutionTime,
+		Plan:        raw,
+	}
+	indexes, seqScans := map[string]bool{}, map[string]bool{}
+	var walk func(node explainNode)
+	walk = func(node explainNode) {
+		if node.IndexName != "" {
+			indexes[node.IndexName] = true
+		}
+		if node.NodeType == "Seq Scan" && node.RelationName != "" {
+			seqScans[node.RelationName] = true
+		}
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+	walk(output.Plan)
+	for name := range indexes {
+		plan.Indexes = append(plan.Indexes, name)
+	}
+	for name := range seqScans {
+		plan.SeqScans = append(plan.SeqScans, name)
+	}
+	sort.Strings(plan.Indexes)
+	sort.Strings(plan.SeqScans)
+	return plan, nil
+}
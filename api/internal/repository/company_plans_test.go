@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+func TestPGXCompaniesRepository_ExplainList(t *testing.T) {
+	const output = `[{"Plan": {"Node Type": "Limit", "Total Cost": 12.5, "Plans": [
+		{"Node Type": "Bitmap Heap Scan", "Relation Name": "leads_view", "Plans": [
+			{"Node Type": "Bitmap Index Scan", "Index Name": "idx_leads_view_city_type_rating"}]},
+		{"Node Type": "Seq Scan", "Relation Name": "company_enrichments"}]},
+		"Planning Time": 0.2, "Execution Time": 1.5}]`
+	var explained string
+	repo := &PGXCompaniesRepository{leadsView: true, pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			explained = query
+			if len(args) != 4 || args[0] != "cafe" || args[1] != "Jakarta" {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*[]byte) = []byte(output)
+				return nil
+			}}
+		},
+	}}
+
+	plan, err := repo.ExplainList(context.Background(), dto.ListFilter{City: "Jakarta", TypeBusiness: "cafe", WebsiteStatus: "missing"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(explained, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) SELECT") || !strings.Contains(explained, "FROM leads_view companies") || !strings.Contains(explained, "website IS NULL") {
+		t.Fatalf("expected the listing explained, got %q", explained)
+	}
+	if plan.SQL != strings.TrimPrefix(explained, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) ") || !plan.Analyzed || plan.TotalCost != 12.5 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if len(plan.Indexes) != 1 || plan.Indexes[0] != "idx_leads_view_city_type_rating" || len(plan.SeqScans) != 1 || plan.SeqScans[0] != "company_enrichments" {
+		t.Fatalf("unexpected scans: indexes=%v seq_scans=%v", plan.Indexes, plan.SeqScans)
+	}
+	if plan.ExecutionMS == nil || *plan.ExecutionMS != 1.5 {
+		t.Fatalf("expected the execution time, got %v", plan.ExecutionMS)
+	}
+}
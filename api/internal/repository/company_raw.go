@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CompanyRawRepository serves the raw Places payload that listings leave out and moves payloads to
+// object storage.
+type CompanyRawRepository interface {
+	GetCompanyRaw(ctx context.Context, companyID uuid.UUID) (CompanyRaw, error)
+	ListInlineRawPayloads(ctx context.Context, after uuid.UUID, limit int) ([]InlineRawPayload, error)
+	MarkRawOffloaded(ctx context.Context, companyID uuid.UUID, ref string, payload json.RawMessage) (bool, error)
+}
+
+// CompanyRaw is the raw payload column of a company. Once offloaded, Ref is the object key of the
+// payload and Payload only keeps the fields SQL reads.
+type CompanyRaw struct {
+	Payload json.RawMessage
+	Ref     string
+}
+
+// InlineRawPayload is a raw payload still stored in Postgres.
+type InlineRawPayload struct {
+	CompanyID   uuid.UUID
+	PlaceID     string
+	ScrapeRunID *uuid.UUID
+	Payload     json.RawMessage
+}
+
+// rawResidueSQL keeps the payload fields read by triggers and size estimation.
+const rawResidueSQL = `jsonb_strip_nulls(jsonb_build_object(
+	'business_status', raw->'business_status',
+	'photos_count', CASE
+		WHEN raw->>'photos_count' ~ '^[0-9]+$' THEN raw->'photos_count'
+		WHEN jsonb_typeof(raw->'photos') = 'array' THEN to_jsonb(jsonb_array_length(raw->'photos'))
+	END
+))`
+
+// GetCompanyRaw returns the stored raw payload of a company, or JSON null when none was kept.
+func (r *PGXCompaniesRepository) GetCompanyRaw(ctx context.Context, companyID uuid.UUID) (CompanyRaw, error) {
+	var (
+		raw []byte
+		ref *string
+	)
+	if err := r.pool.QueryRow(ctx, `SELECT raw, raw_ref FROM companies WHERE id = $1`, companyID).Scan(&raw, &ref); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CompanyRaw{}, ErrCompanyNotFound
+		}
+		return CompanyRaw{}, fmt.Errorf("get company raw: %w", err)
+	}
+	result := CompanyRaw{Payload: json.RawMessage("null")}
+	if len(raw) > 0 {
+		result.Payload = json.RawMessage(raw)
+	}
+	if ref != nil {
+		result.Ref = *ref
+	}
+	return result, nil
+}
+
+// ListInlineRawPayloads returns up to limit scraped companies after the given id whose payload is
+// still inline, in id order.
+func (r *PGXCompaniesRepository) ListInlineRawPayloads(ctx context.Context, after uuid.UUID, limit int) ([]InlineRawPayload, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, place_id, scrape_run_id, raw
+		FROM companies
+		WHERE id > $1 AND raw_ref IS NULL AND place_id IS NOT NULL AND raw <> '{}'::jsonb
+		ORDER BY id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list inline raw payloads: %w", err)
+	}
+	defer rows.Close()
+
+	payloads := make([]InlineRawPayload, 0)
+	for rows.Next() {
+		var (
+			payload InlineRawPayload
+			raw     []byte
+		)
+		if err := rows.Scan(&payload.CompanyID, &payload.PlaceID, &payload.ScrapeRunID, &raw); err != nil {
+			return nil, fmt.Errorf("scan inline raw payload: %w", err)
+		}
+		payload.Payload = json.RawMessage(raw)
+		payloads = append(payloads, payload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate inline raw payloads: %w", err)
+	}
+	return payloads, nil
+}
+
+// MarkRawOffloaded points a company at its offloaded payload and trims the inline copy. It reports
+// false, leaving the row alone, when a writer replaced the payload since it was listed.
+func (r *PGXCompaniesRepository) MarkRawOffloaded(ctx context.Context, companyID uuid.UUID, ref string, payload json.RawMessage) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE companies
+		SET raw = `+rawResidueSQL+`, raw_ref = $2
+		WHERE id = $1 AND raw_ref IS NULL AND raw = $3::jsonb
+	`, companyID, ref, string(payload))
+	if err != nil {
+		return false, fmt.Errorf("mark raw payload offloaded: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrCompanyNotFound indicates the requested company does not exist.
+var ErrCompanyNotFound = errors.New("company not found")
+
+// CompanySizeRepository reads size signals and stores the estimated size bucket.
+type CompanySizeRepository interface {
+	GetSizeSignals(ctx context.Context, companyID uuid.UUID) (*entity.CompanySizeSignals, error)
+	ListSizeSignalsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanySizeSignals, error)
+	UpdateSize(ctx context.Context, companyID uuid.UUID, bucket string) error
+}
+
+// PGXCompanySizeRepository implements CompanySizeRepository using pgx.
+type PGXCompanySizeRepository struct {
+	pool pgxPool
+}
+
+// NewPGXCompanySizeRepository wires a pgx backed company size repository.
+func NewPGXCompanySizeRepository(pool *pgxpool.Pool) *PGXCompanySizeRepository {
+	return &PGXCompanySizeRepository{pool: pool}
+}
+
+// sizeSignalsSelect pulls reviews from the company, photo counts from the raw scrape payload
+// (SerpAPI photos_count or the Places photos array) and headcount/follower hints from enrichment.
+const sizeSignalsSelect = `
+	SELECT
+		c.id,
+		c.reviews,
+		CASE
+			WHEN c.raw->>'photos_count' ~ '^[0-9]+$' THEN (c.raw->>'photos_count')::int
+			WHEN jsonb_typeof(c.raw->'photos') = 'array' THEN jsonb_array_length(c.raw->'photos')
+		END AS photos,
+		CASE WHEN e.metadata->>'social_followers' ~ '^[0-9]+$' THEN (e.metadata->>'social_followers')::int END AS social_followers,
+		CASE WHEN e.metadata->>'employee_mentions' ~ '^[0-9]+$' THEN (e.metadata->>'employee_mentions')::int END AS employee_mentions,
+		c.size_bucket
+	FROM companies c
+	LEFT JOIN company_enrichments e ON e.company_id = c.id
+`
+
+// GetSizeSignals returns the size signals of a single company.
+func (r *PGXCompanySizeRepository) GetSizeSignals(ctx context.Context, companyID uuid.UUID) (*entity.CompanySizeSignals, error) {
+	signals, err := scanSizeSignals(r.pool.QueryRow(ctx, sizeSignalsSelect+` WHERE c.id = $1`, companyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, fmt.Errorf("fetch size signals: %w", err)
+	}
+	return signals, nil
+}
+
+// ListSizeSignalsAfter pages through company size signals ordered by company id.
+func (r *PGXCompanySizeRepository) ListSizeSignalsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanySizeSignals, error) {
+	rows, err := r.pool.Query(ctx, sizeSignalsSelect+` WHERE c.id > $1 ORDER BY c.id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list size signals: %w", err)
+	}
+	defer rows.Close()
+
+	var records []entity.CompanySizeSignals
+	for rows.Next() {
+		signals, err := scanSizeSignals(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan size signals: %w", err)
+		}
+		records = append(records, *signals)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate size signals: %w", err)
+	}
+	return records, nil
+}
+
+// UpdateSize stores the estimated size bucket of a company.
+func (r *PGXCompanySizeRepository) UpdateSize(ctx context.Context, companyID uuid.UUID, bucket string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE companies
+		SET size_bucket = $2, size_estimated_at = NOW()
+		WHERE id = $1
+	`, companyID, bucket)
+	if err != nil {
+		return fmt.Errorf("update company size: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
+
+func scanSizeSignals(row pgx.Row) (*entity.CompanySizeSignals, error) {
+	var (
+		signals          entity.CompanySizeSignals
+		reviews          sql.NullInt64
+		photos           sql.NullInt64
+		socialFollowers  sql.NullInt64
+		employeeMentions sql.NullInt64
+		sizeBucket       sql.NullString
+	)
+	if err := row.Scan(&signals.CompanyID, &reviews, &photos, &socialFollowers, &employeeMentions, &sizeBucket); err != nil {
+		return nil, err
+	}
+	signals.Reviews = nullIntToPtr(reviews)
+	signals.Photos = nullIntToPtr(photos)
+	signals.SocialFollowers = nullIntToPtr(socialFollowers)
+	signals.EmployeeMentions = nullIntToPtr(employeeMentions)
+	signals.SizeBucket = nullStringToPtr(sizeBucket)
+	return &signals, nil
+}
+
+func nullIntToPtr(value sql.NullInt64) *int {
+	if value.Valid {
+		val := int(value.Int64)
+		return &val
+	}
+	return nil
+}
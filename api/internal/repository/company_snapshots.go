@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// CompanySnapshotsRepository serves the per-run observations recorded by the
+// record_company_snapshot trigger, and the place refreshes.
+type CompanySnapshotsRepository interface {
+	ListCompanySnapshots(ctx context.Context, companyID uuid.UUID) ([]entity.CompanySnapshot, error)
+}
+
+// ListCompanySnapshots returns the snapshots of a company, oldest first. The company is joined in
+// so that a company without snapshots can be told apart from a missing one.
+func (r *PGXCompaniesRepository) ListCompanySnapshots(ctx context.Context, companyID uuid.UUID) ([]entity.CompanySnapshot, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.scrape_run_id, s.rating::float8, s.reviews, s.observed_at, s.source, s.place_id, s.previous_place_id
+		FROM companies c
+		LEFT JOIN company_snapshots s ON s.company_id = c.id
+		WHERE c.id = $1
+		ORDER BY s.observed_at, s.scrape_run_id
+	`, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("list company snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	snapshots := make([]entity.CompanySnapshot, 0)
+	for rows.Next() {
+		var (
+			snapshot entity.CompanySnapshot
+			runID    *uuid.UUID
+			observed *time.Time
+			source   *string
+		)
+		if err := rows.Scan(&runID, &snapshot.Rating, &snapshot.Reviews, &observed, &source, &snapshot.PlaceID, &snapshot.PreviousPlaceID); err != nil {
+			return nil, fmt.Errorf("scan company snapshot: %w", err)
+		}
+		found = true
+		if runID == nil {
+			continue
+		}
+		snapshot.ScrapeRunID = *runID
+		snapshot.ObservedAt = *observed
+		snapshot.Source = entity.SnapshotSourceScrape
+		if source != nil {
+			snapshot.Source = *source
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company snapshots: %w", err)
+	}
+	if !found {
+		return nil, ErrCompanyNotFound
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXCompaniesRepository_ListCompanySnapshots(t *testing.T) {
+	runID := uuid.New()
+	observed := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rowsByCompany := map[uuid.UUID][]func(dest ...any) error{
+		// A company with one snapshot.
+		uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"): {func(dest ...any) error {
+			*dest[0].(**uuid.UUID) = &runID
+			rating, reviews := 4.5, 120
+			*dest[1].(**float64) = &rating
+			*dest[2].(**int) = &reviews
+			*dest[3].(**time.Time) = &observed
+			return nil
+		}},
+		// A company never scraped: the join yields one row without a snapshot.
+		uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"): {func(dest ...any) error { return nil }},
+	}
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			return &stubRows{scans: rowsByCompany[args[0].(uuid.UUID)]}, nil
+		},
+	}}
+
+	snapshots, err := repo.ListCompanySnapshots(context.Background(), uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ScrapeRunID != runID || *snapshots[0].Reviews != 120 || !snapshots[0].ObservedAt.Equal(observed) {
+		t.Fatalf("unexpected snapshots %+v", snapshots)
+	}
+
+	snapshots, err = repo.ListCompanySnapshots(context.Background(), uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"))
+	if err != nil || snapshots == nil || len(snapshots) != 0 {
+		t.Fatalf("expected an empty history, got %+v (%v)", snapshots, err)
+	}
+
+	if _, err := repo.ListCompanySnapshots(context.Background(), uuid.Nil); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// suggestColumns maps the fields offered for autocomplete to their columns. Each column has a
+// LOWER(column) text_pattern_ops index (migration 0056) serving the prefix match.
+var suggestColumns = map[string]string{
+	"city":          "city",
+	"type_business": "type_business",
+	"company":       "company",
+}
+
+// CompanySuggestRepository lists the values of a company field for typeahead inputs.
+type CompanySuggestRepository interface {
+	Suggest(ctx context.Context, field, prefix string, limit int) ([]entity.StatBucket, error)
+}
+
+// Suggest returns up to limit distinct values of field starting with prefix, ignoring case, with
+// the number of companies holding each, the most common first. prefix must be lowercased.
+func (r *PGXCompaniesRepository) Suggest(ctx context.Context, field, prefix string, limit int) (_ []entity.StatBucket, err error) {
+	column, ok := suggestColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("suggest companies: unknown field %q", field)
+	}
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.reader().Query(ctx, fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*)
+		FROM companies
+		WHERE LOWER(%[1]s) LIKE $1 || '%%' ESCAPE '\' AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC, %[1]s ASC
+		LIMIT $2
+	`, column), escapeLike(prefix), limit)
+	if err != nil {
+		return nil, fmt.Errorf("suggest companies: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]entity.StatBucket, 0)
+	for rows.Next() {
+		var suggestion entity.StatBucket
+		if err := rows.Scan(&suggestion.Name, &suggestion.Companies); err != nil {
+			return nil, fmt.Errorf("scan company suggestion: %w", err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company suggestions: %w", err)
+	}
+	return suggestions, nil
+}
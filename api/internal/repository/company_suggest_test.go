@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXCompaniesRepository_Suggest(t *testing.T) {
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "LOWER(type_business) LIKE $1") || !strings.Contains(query, "ORDER BY COUNT(*) DESC") {
+				t.Fatalf("expected a prefix match ranked by frequency, got %q", query)
+			}
+			if len(args) != 2 || args[0] != `50\%` || args[1] != 5 {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "50% Off Store"
+					*dest[1].(*int64) = 3
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	suggestions, err := repo.Suggest(context.Background(), "type_business", "50%", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Name != "50% Off Store" || suggestions[0].Companies != 3 {
+		t.Fatalf("unexpected suggestions: %+v", suggestions)
+	}
+
+	if _, err := repo.Suggest(context.Background(), "phone; DROP TABLE companies", "0", 5); err == nil {
+		t.Fatalf("expected an unknown field to be rejected")
+	}
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// ContactCollisionDetectionResult summarises a contact collision detection pass.
+type ContactCollisionDetectionResult struct {
+	Collisions       int   `json:"collisions"`
+	SharedCompanies  int   `json:"shared_companies"`
+	CompaniesUpdated int64 `json:"companies_updated"`
+}
+
+// ContactCollisionsRepository detects emails and phone numbers shared across companies.
+type ContactCollisionsRepository interface {
+	DetectContactCollisions(ctx context.Context, minOwners int) (ContactCollisionDetectionResult, error)
+	ListContactCollisions(ctx context.Context, kind string, limit, offset int) ([]entity.ContactCollision, error)
+}
+
+// PGXContactCollisionsRepository implements ContactCollisionsRepository using pgx.
+type PGXContactCollisionsRepository struct {
+	pool   pgxPool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPGXContactCollisionsRepository wires a pgx backed contact collisions repository;
+// WithEnrichmentCipher makes detection compare the decrypted enrichment and website contacts.
+func NewPGXContactCollisionsRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXContactCollisionsRepository {
+	return &PGXContactCollisionsRepository{pool: pool, cipher: newRepositoryOptions(opts).cipher}
+}
+
+// contactOwnersCTE lists every contact of every company with its owner: the chain of the company,
+// or the company itself when independent. Emails are lowercased and phone numbers reduced to their
+// digits; numbers shorter than 7 digits are too ambiguous to compare. {enrichments} and
+// {website_contacts} are the tables holding the enrichment and website contacts in plaintext.
+const contactOwnersCTE = `
+	WITH contacts AS (
+		SELECT id AS company_id, 'phone' AS kind, regexp_replace(phone, '[^0-9]', '', 'g') AS value
+		FROM companies
+		WHERE phone IS NOT NULL
+		UNION
+		SELECT company_id, 'phone', regexp_replace(p, '[^0-9]', '', 'g')
+		FROM {enrichments}, unnest(phones) AS p
+		UNION
+		SELECT company_id, 'phone', regexp_replace(p, '[^0-9]', '', 'g')
+		FROM {website_contacts}, unnest(phones) AS p
+		UNION
+		SELECT company_id, 'email', lower(btrim(e))
+		FROM {enrichments}, unnest(emails) AS e
+		UNION
+		SELECT company_id, 'email', lower(btrim(e))
+		FROM {website_contacts}, unnest(emails) AS e
+	), owned AS (
+		SELECT ct.kind, ct.value, ct.company_id, COALESCE(c.chain_id, c.id) AS owner_id
+		FROM contacts ct
+		JOIN companies c ON c.id = ct.company_id
+		WHERE (ct.kind = 'phone' AND length(ct.value) >= 7)
+			OR (ct.kind = 'email' AND ct.value LIKE '_%@_%')
+	)
+`
+
+// DetectContactCollisions replaces the stored collisions with every contact shared by at least
+// minOwners owners, then flags the companies carrying one with shared_contact and clears the flag
+// of the others. Locations of one chain sharing a head office number count as a single owner.
+func (r *PGXContactCollisionsRepository) DetectContactCollisions(ctx context.Context, minOwners int) (ContactCollisionDetectionResult, error) {
+	var result ContactCollisionDetectionResult
+	if minOwners < 2 {
+		return result, fmt.Errorf("min owners must be at least 2, got %d", minOwners)
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return result, fmt.Errorf("start contact collision detection tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM contact_collisions`); err != nil {
+		return result, fmt.Errorf("clear contact collisions: %w", err)
+	}
+	enrichments, websiteContacts := EnrichmentContactTable.Name, WebsiteContactTable.Name
+	if r.cipher != nil {
+		if err := r.stageDecryptedContacts(ctx, tx, EnrichmentContactTable, decryptedContactsTable); err != nil {
+			return result, err
+		}
+		if err := r.stageDecryptedContacts(ctx, tx, WebsiteContactTable, decryptedWebsiteContactsTable); err != nil {
+			return result, err
+		}
+		enrichments, websiteContacts = decryptedContactsTable, decryptedWebsiteContactsTable
+	}
+	insertSQL := strings.NewReplacer("{enrichments}", enrichments, "{website_contacts}", websiteContacts).Replace(contactOwnersCTE) + `
+		INSERT INTO contact_collisions (kind, value, company_count, owner_count, company_ids, detected_at)
+		SELECT kind, value, COUNT(DISTINCT company_id), COUNT(DISTINCT owner_id), ARRAY_AGG(DISTINCT company_id), NOW()
+		FROM owned
+		GROUP BY kind, value
+		HAVING COUNT(DISTINCT owner_id) >= $1
+	`
+	tag, err := tx.Exec(ctx, insertSQL, minOwners)
+	if err != nil {
+		return result, fmt.Errorf("insert contact collisions: %w", err)
+	}
+	result.Collisions = int(tag.RowsAffected())
+
+	tag, err = tx.Exec(ctx, `
+		WITH shared AS (
+			SELECT DISTINCT unnest(company_ids) AS company_id FROM contact_collisions
+		)
+		UPDATE companies
+		SET shared_contact = NOT shared_contact
+		WHERE shared_contact <> (id IN (SELECT company_id FROM shared))
+	`)
+	if err != nil {
+		return result, fmt.Errorf("flag shared contacts: %w", err)
+	}
+	result.CompaniesUpdated = tag.RowsAffected()
+
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM companies WHERE shared_contact`).Scan(&result.SharedCompanies); err != nil {
+		return result, fmt.Errorf("count shared contact companies: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("commit contact collision detection: %w", err)
+	}
+	return result, nil
+}
+
+// decryptedContactsTable and decryptedWebsiteContactsTable hold the decrypted enrichment and website
+// contacts for the length of a detection.
+const (
+	decryptedContactsTable        = "decrypted_enrichment_contacts"
+	decryptedWebsiteContactsTable = "decrypted_website_contacts"
+)
+
+// decryptBatchSize is how many rows are decrypted and copied at a time.
+const decryptBatchSize = 1000
+
+// stageDecryptedContacts copies the contacts of source, decrypted, into the temporary table target
+// dropped when tx ends, for contactOwnersCTE to compare in place of the encrypted source.
+func (r *PGXContactCollisionsRepository) stageDecryptedContacts(ctx context.Context, tx pgx.Tx, source ContactTable, target string) error {
+	if _, err := tx.Exec(ctx, `
+		CREATE TEMP TABLE `+target+` (company_id UUID, emails TEXT[], phones TEXT[]) ON COMMIT DROP
+	`); err != nil {
+		return fmt.Errorf("create decrypted contacts table: %w", err)
+	}
+
+	var after uuid.UUID
+	for {
+		rows, err := tx.Query(ctx, `
+			SELECT company_id, emails, phones
+			FROM `+source.Name+`
+			WHERE company_id > $1
+			ORDER BY company_id
+			LIMIT $2
+		`, after, decryptBatchSize)
+		if err != nil {
+			return fmt.Errorf("list %s contacts: %w", source.Name, err)
+		}
+		var batch [][]any
+		for rows.Next() {
+			record := entity.CompanyEnrichment{}
+			if err := rows.Scan(&record.CompanyID, &record.Emails, &record.Phones); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan %s contacts: %w", source.Name, err)
+			}
+			if err := decryptContacts(r.cipher, &record); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, []any{record.CompanyID, stringSliceOrEmpty(record.Emails), stringSliceOrEmpty(record.Phones)})
+			after = record.CompanyID
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate %s contacts: %w", source.Name, err)
+		}
+		if len(batch) > 0 {
+			if _, err := tx.CopyFrom(ctx, pgx.Identifier{target}, []string{"company_id", "emails", "phones"}, pgx.CopyFromRows(batch)); err != nil {
+				return fmt.Errorf("copy decrypted contacts: %w", err)
+			}
+		}
+		if len(batch) < decryptBatchSize {
+			return nil
+		}
+	}
+}
+
+// ListContactCollisions returns the stored collisions shared by the most owners first, optionally
+// narrowed to one kind.
+func (r *PGXContactCollisionsRepository) ListContactCollisions(ctx context.Context, kind string, limit, offset int) ([]entity.ContactCollision, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT kind, value, company_count, owner_count, company_ids, detected_at
+		FROM contact_collisions
+		WHERE $1 = '' OR kind = $1
+		ORDER BY owner_count DESC, company_count DESC, kind, value
+		LIMIT $2 OFFSET $3
+	`, kind, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list contact collisions: %w", err)
+	}
+	defer rows.Close()
+
+	collisions := make([]entity.ContactCollision, 0)
+	for rows.Next() {
+		var collision entity.ContactCollision
+		if err := rows.Scan(&collision.Kind, &collision.Value, &collision.CompanyCount, &collision.OwnerCount, &collision.CompanyIDs, &collision.DetectedAt); err != nil {
+			return nil, fmt.Errorf("scan contact collision: %w", err)
+		}
+		collisions = append(collisions, collision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate contact collisions: %w", err)
+	}
+	return collisions, nil
+}
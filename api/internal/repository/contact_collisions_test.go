@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+func TestPGXContactCollisionsRepository_DetectContactCollisions(t *testing.T) {
+	var queries []string
+	tx := &stubTx{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			queries = append(queries, query)
+			switch {
+			case strings.Contains(query, "INSERT INTO contact_collisions"):
+				if args[0] != 3 {
+					t.Fatalf("expected threshold arg, got %v", args)
+				}
+				return pgconn.NewCommandTag("INSERT 0 4"), nil
+			case strings.Contains(query, "UPDATE companies"):
+				return pgconn.NewCommandTag("UPDATE 12"), nil
+			}
+			return pgconn.CommandTag{}, nil
+		},
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int) = 40
+				return nil
+			}}
+		},
+	}
+	repo := &PGXContactCollisionsRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+
+	result, err := repo.DetectContactCollisions(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Collisions != 4 || result.CompaniesUpdated != 12 || result.SharedCompanies != 40 || !tx.committed {
+		t.Fatalf("unexpected result %+v (committed %v)", result, tx.committed)
+	}
+	if len(queries) != 3 || !strings.Contains(queries[0], "DELETE FROM contact_collisions") {
+		t.Fatalf("expected stale collisions to be cleared first, got %v", queries)
+	}
+	if !strings.Contains(queries[1], "COUNT(DISTINCT owner_id) >= $1") || !strings.Contains(queries[1], "COALESCE(c.chain_id, c.id)") {
+		t.Fatalf("expected chains to count as one owner, got %q", queries[1])
+	}
+
+	if _, err := repo.DetectContactCollisions(context.Background(), 1); err == nil {
+		t.Fatalf("expected error for a threshold below 2")
+	}
+}
+
+// copyingTx records the rows copied through CopyFrom.
+type copyingTx struct {
+	*stubTx
+	copied [][]any
+	tables []string
+}
+
+func (tx *copyingTx) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	tx.tables = append(tx.tables, table.Sanitize())
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			return 0, err
+		}
+		tx.copied = append(tx.copied, values)
+	}
+	return int64(len(tx.copied)), nil
+}
+
+func TestPGXContactCollisionsRepository_DetectComparesDecryptedContacts(t *testing.T) {
+	cipher, _ := fieldcrypt.New(bytes.Repeat([]byte{3}, fieldcrypt.KeySize))
+	email, _ := cipher.Encrypt("info@example.com")
+	var insertSQL string
+	tx := &copyingTx{stubTx: &stubTx{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			if strings.Contains(query, "INSERT INTO contact_collisions") {
+				insertSQL = query
+			}
+			return pgconn.CommandTag{}, nil
+		},
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+				*dest[1].(*[]string) = []string{email}
+				return nil
+			}}}, nil
+		},
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return nil }}
+		},
+	}}
+	repo := &PGXContactCollisionsRepository{cipher: cipher, pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+
+	if _, err := repo.DetectContactCollisions(context.Background(), 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tx.copied) != 2 || tx.copied[0][1].([]string)[0] != "info@example.com" || tx.copied[1][1].([]string)[0] != "info@example.com" {
+		t.Fatalf("expected the decrypted enrichment and website contacts to be staged, got %v", tx.copied)
+	}
+	if strings.Join(tx.tables, ",") != `"decrypted_enrichment_contacts","decrypted_website_contacts"` {
+		t.Fatalf("unexpected staging tables %v", tx.tables)
+	}
+	if !strings.Contains(insertSQL, "FROM decrypted_enrichment_contacts, unnest(emails)") || !strings.Contains(insertSQL, "FROM decrypted_website_contacts, unnest(emails)") ||
+		strings.Contains(insertSQL, "FROM company_enrichments") || strings.Contains(insertSQL, "FROM website_enriched_contacts") {
+		t.Fatalf("expected detection to read the staged contacts, got %q", insertSQL)
+	}
+}
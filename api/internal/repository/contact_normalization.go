@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ContactNormalizationRepository reads company phones and websites and stores their normalized
+// form.
+type ContactNormalizationRepository interface {
+	ListCompanyContactFieldsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyContactFields, error)
+	UpdateCompanyContactFields(ctx context.Context, companyID uuid.UUID, phone, website *string) error
+}
+
+// PGXContactNormalizationRepository implements ContactNormalizationRepository using pgx.
+type PGXContactNormalizationRepository struct {
+	pool pgxPool
+}
+
+// NewPGXContactNormalizationRepository wires a pgx backed contact normalization repository.
+func NewPGXContactNormalizationRepository(pool *pgxpool.Pool) *PGXContactNormalizationRepository {
+	return &PGXContactNormalizationRepository{pool: pool}
+}
+
+// ListCompanyContactFieldsAfter pages through company phones and websites ordered by company id.
+func (r *PGXContactNormalizationRepository) ListCompanyContactFieldsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyContactFields, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, phone, website, country FROM companies WHERE id > $1 ORDER BY id LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list company contact fields: %w", err)
+	}
+	defer rows.Close()
+
+	var fields []entity.CompanyContactFields
+	for rows.Next() {
+		var f entity.CompanyContactFields
+		if err := rows.Scan(&f.CompanyID, &f.Phone, &f.Website, &f.Country); err != nil {
+			return nil, fmt.Errorf("scan company contact fields: %w", err)
+		}
+		fields = append(fields, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company contact fields: %w", err)
+	}
+	return fields, nil
+}
+
+// UpdateCompanyContactFields stores the phone and website of a company.
+func (r *PGXContactNormalizationRepository) UpdateCompanyContactFields(ctx context.Context, companyID uuid.UUID, phone, website *string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE companies SET phone = $2, website = $3, updated_at = NOW() WHERE id = $1
+	`, companyID, phone, website)
+	if err != nil {
+		return fmt.Errorf("update company contact fields: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestPGXContactNormalizationRepository_UpdateCompanyContactFields(t *testing.T) {
+	tag := pgconn.NewCommandTag("UPDATE 1")
+	repo := &PGXContactNormalizationRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return tag, nil
+		},
+	}}
+	phone := "+6281234567890"
+
+	if err := repo.UpdateCompanyContactFields(context.Background(), uuid.New(), &phone, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tag = pgconn.NewCommandTag("UPDATE 0")
+	if err := repo.UpdateCompanyContactFields(context.Background(), uuid.New(), &phone, nil); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// ContactTermsRepository rebuilds the contact_terms searched by q_contact, for enrichments stored
+// before the column existed or tokenised with a key since rotated. PGXCompaniesRepository
+// implements it with its enrichment cipher.
+type ContactTermsRepository interface {
+	RefreshContactTermsAfter(ctx context.Context, after uuid.UUID, limit int) (ContactTermsPage, error)
+}
+
+// ContactTermsPage reports one page of RefreshContactTermsAfter: the last company read, how many
+// enrichments were read and how many had their terms rewritten.
+type ContactTermsPage struct {
+	Last    uuid.UUID
+	Scanned int
+	Updated int
+}
+
+// minContactPhoneDigits is the shortest phone number indexed; shorter ones are too ambiguous.
+const minContactPhoneDigits = 7
+
+// contactTerms returns the contact_terms of an enrichment: every email lowercased, every phone
+// number reduced to its digits and every social handle, each prefixed with its kind. Email and
+// phone terms go through cipher.Token, so they are keyed hashes once contacts are encrypted;
+// handles are public and stay readable.
+func contactTerms(cipher *fieldcrypt.Cipher, emails, phones []string, socials map[string][]string) []string {
+	terms := make([]string, 0, len(emails)+len(phones))
+	add := func(term string) {
+		if !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	for _, email := range emails {
+		if value := contactEmail(email); value != "" {
+			add(cipher.Token("email:" + value))
+		}
+	}
+	for _, phone := range phones {
+		if value := contactPhone(phone); value != "" {
+			add(cipher.Token("phone:" + value))
+		}
+	}
+	for _, network := range slices.Sorted(maps.Keys(socials)) {
+		for _, profile := range socials[network] {
+			if handle := socialHandle(profile); handle != "" {
+				add("social:" + handle)
+			}
+		}
+	}
+	return terms
+}
+
+// contactSearchTerms returns the contact_terms a q_contact search matches: the email, phone
+// number or social handle it reads as, tokenised under every key of cipher.
+func contactSearchTerms(cipher *fieldcrypt.Cipher, query string) []string {
+	query = strings.TrimSpace(query)
+	var terms []string
+	if email := contactEmail(query); email != "" {
+		return cipher.Tokens("email:" + email)
+	}
+	if phone := contactPhone(query); phone != "" && !strings.ContainsFunc(query, unicode.IsLetter) {
+		terms = append(terms, cipher.Tokens("phone:"+phone)...)
+	}
+	if handle := socialHandle(query); handle != "" {
+		terms = append(terms, "social:"+handle)
+	}
+	return terms
+}
+
+// contactEmail lowercases an email address, or returns "" when value is not one.
+func contactEmail(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	local, domain, ok := strings.Cut(value, "@")
+	if !ok || local == "" || !strings.Contains(domain, ".") || strings.ContainsAny(value, " /") {
+		return ""
+	}
+	return value
+}
+
+// contactPhone reduces a phone number to its digits, or returns "" when it has too few.
+func contactPhone(value string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+	if len(digits) < minContactPhoneDigits {
+		return ""
+	}
+	return digits
+}
+
+// socialHandle returns the lowercased handle of a social profile given as a URL
+// (https://instagram.com/kopi.kenangan/), a host and path (instagram.com/kopi.kenangan) or a
+// handle (@kopi.kenangan): the last segment of its path, without a leading @.
+func socialHandle(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if strings.Contains(value, "/") {
+		if !strings.Contains(value, "://") {
+			value = "https://" + value
+		}
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return ""
+		}
+		segments := strings.FieldsFunc(parsed.Path, func(r rune) bool { return r == '/' })
+		if len(segments) == 0 {
+			return ""
+		}
+		value = segments[len(segments)-1]
+	}
+	value = strings.TrimPrefix(value, "@")
+	if value == "" || strings.ContainsFunc(value, unicode.IsSpace) || strings.Contains(value, "@") {
+		return ""
+	}
+	return value
+}
+
+// RefreshContactTermsAfter rebuilds the contact_terms of up to limit enrichments after the given
+// company id from their decrypted contacts. A row whose contacts changed meanwhile is left alone:
+// its writer stored its terms.
+func (r *PGXCompaniesRepository) RefreshContactTermsAfter(ctx context.Context, after uuid.UUID, limit int) (ContactTermsPage, error) {
+	page := ContactTermsPage{Last: after}
+	rows, err := r.pool.Query(ctx, `
+		SELECT company_id, emails, phones, socials, contact_terms
+		FROM company_enrichments
+		WHERE company_id > $1
+		ORDER BY company_id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return page, fmt.Errorf("list enrichment contacts: %w", err)
+	}
+	type stale struct {
+		stored  entity.CompanyEnrichment
+		socials []byte
+		terms   []string
+	}
+	var rewrites []stale
+	for rows.Next() {
+		var (
+			record  entity.CompanyEnrichment
+			socials []byte
+			stored  []string
+		)
+		if err := rows.Scan(&record.CompanyID, &record.Emails, &record.Phones, &socials, &stored); err != nil {
+			rows.Close()
+			return page, fmt.Errorf("scan enrichment contacts: %w", err)
+		}
+		page.Last = record.CompanyID
+		page.Scanned++
+		plain := record
+		if err := decryptContacts(r.cipher, &plain); err != nil {
+			rows.Close()
+			return page, err
+		}
+		if err := json.Unmarshal(socials, &plain.Socials); err != nil {
+			rows.Close()
+			return page, fmt.Errorf("decode socials of %s: %w", record.CompanyID, err)
+		}
+		if terms := contactTerms(r.cipher, plain.Emails, plain.Phones, plain.Socials); !slices.Equal(terms, stored) {
+			rewrites = append(rewrites, stale{stored: record, socials: socials, terms: terms})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return page, fmt.Errorf("iterate enrichment contacts: %w", err)
+	}
+
+	for _, rewrite := range rewrites {
+		tag, err := r.pool.Exec(ctx, `
+			UPDATE company_enrichments SET contact_terms = $2
+			WHERE company_id = $1 AND emails = $3 AND phones = $4 AND socials = $5::jsonb
+		`, rewrite.stored.CompanyID, rewrite.terms, stringSliceOrEmpty(rewrite.stored.Emails), stringSliceOrEmpty(rewrite.stored.Phones), string(rewrite.socials))
+		if err != nil {
+			return page, fmt.Errorf("update contact terms: %w", err)
+		}
+		page.Updated += int(tag.RowsAffected())
+	}
+	return page, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+func TestContactTerms(t *testing.T) {
+	terms := contactTerms(nil,
+		[]string{" Info@Kopi.co.id ", "info@kopi.co.id", "not an email"},
+		[]string{"+62 (21) 555-0101", "123"},
+		map[string][]string{"instagram": {"https://www.instagram.com/Kopi.Kenangan/"}, "facebook": {"facebook.com/kopikenangan"}},
+	)
+	want := []string{"email:info@kopi.co.id", "phone:62215550101", "social:kopikenangan", "social:kopi.kenangan"}
+	if !slices.Equal(terms, want) {
+		t.Fatalf("expected %v, got %v", want, terms)
+	}
+
+	cipher, err := fieldcrypt.New(bytes.Repeat([]byte{1}, fieldcrypt.KeySize))
+	if err != nil {
+		t.Fatalf("new cipher: %v", err)
+	}
+	tokenised := contactTerms(cipher, []string{"info@kopi.co.id"}, []string{"021 555 0101"}, map[string][]string{"instagram": {"@kopi.kenangan"}})
+	if len(tokenised) != 3 || tokenised[0] != cipher.Token("email:info@kopi.co.id") || strings.Contains(tokenised[0], "kopi") {
+		t.Fatalf("expected the email tokenised, got %v", tokenised)
+	}
+	if tokenised[2] != "social:kopi.kenangan" {
+		t.Fatalf("expected handles kept readable, got %v", tokenised)
+	}
+}
+
+func TestContactSearchTerms(t *testing.T) {
+	cases := map[string][]string{
+		"INFO@kopi.co.id":                     {"email:info@kopi.co.id"},
+		"+62 21-555-0101":                     {"phone:62215550101"},
+		"https://instagram.com/kopi.kenangan": {"social:kopi.kenangan"},
+		"@Kopi.Kenangan":                      {"social:kopi.kenangan"},
+		"kopi kenangan":                       nil,
+	}
+	for query, want := range cases {
+		if got := contactSearchTerms(nil, query); !slices.Equal(got, want) {
+			t.Errorf("contactSearchTerms(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestBuildListConditions_ContactSearch(t *testing.T) {
+	repo := &PGXCompaniesRepository{}
+	conditions, err := repo.buildListConditions(context.Background(), dto.ListFilter{QContact: "Info@Kopi.co.id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(conditions.where(), "ce.contact_terms && $1::text[]") {
+		t.Fatalf("expected a contact terms clause, got %q", conditions.where())
+	}
+	if terms, _ := conditions.args[0].([]string); !slices.Equal(terms, []string{"email:info@kopi.co.id"}) {
+		t.Fatalf("unexpected contact terms argument %v", conditions.args[0])
+	}
+
+	conditions, err = repo.buildListConditions(context.Background(), dto.ListFilter{QContact: "kopi kenangan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(conditions.where(), "FALSE") || len(conditions.args) != 0 {
+		t.Fatalf("expected a query matching no contact to match nothing, got %q %v", conditions.where(), conditions.args)
+	}
+}
+
+func TestPGXCompaniesRepository_RefreshContactTermsAfter(t *testing.T) {
+	current, stale := uuid.New(), uuid.New()
+	var updated []any
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if args[0] != uuid.Nil || args[1] != 2 {
+				t.Fatalf("unexpected page arguments %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = current
+					*dest[1].(*[]string) = []string{"info@kopi.co.id"}
+					*dest[3].(*[]byte) = []byte(`{}`)
+					*dest[4].(*[]string) = []string{"email:info@kopi.co.id"}
+					return nil
+				},
+				func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = stale
+					*dest[2].(*[]string) = []string{"0812 3456 789"}
+					*dest[3].(*[]byte) = []byte(`{"instagram":["@kopi"]}`)
+					return nil
+				},
+			}}, nil
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			if !strings.Contains(query, "socials = $5::jsonb") {
+				t.Fatalf("expected the update guarded by the contacts read, got %s", query)
+			}
+			updated = args
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}}
+
+	page, err := repo.RefreshContactTermsAfter(context.Background(), uuid.Nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Last != stale || page.Scanned != 2 || page.Updated != 1 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if updated[0] != stale || !slices.Equal(updated[1].([]string), []string{"phone:08123456789", "social:kopi"}) {
+		t.Fatalf("unexpected update %v", updated)
+	}
+}
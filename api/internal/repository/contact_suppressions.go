@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Contact suppression repository errors.
+var (
+	ErrSuppressionNotFound  = errors.New("contact suppression not found")
+	ErrSuppressionDuplicate = errors.New("contact already suppressed")
+)
+
+// ContactSuppressionsRepository persists the do-not-contact list.
+type ContactSuppressionsRepository interface {
+	ListSuppressions(ctx context.Context, kind, query string, limit, offset int) ([]entity.ContactSuppression, error)
+	CreateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error
+	UpdateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error
+	DeleteSuppression(ctx context.Context, id uuid.UUID) error
+	// MatchSuppressions returns the entries listing any of the given emails, domains or phone numbers.
+	MatchSuppressions(ctx context.Context, emails, domains, phones []string) ([]entity.ContactSuppression, error)
+}
+
+// CompanyContactsRepository reads the contacts of companies to check them against the
+// do-not-contact list.
+type CompanyContactsRepository interface {
+	ListCompanyContacts(ctx context.Context, ids []uuid.UUID) ([]entity.CompanyContacts, error)
+}
+
+// PGXContactSuppressionsRepository implements ContactSuppressionsRepository using pgx.
+type PGXContactSuppressionsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXContactSuppressionsRepository wires a pgx backed contact suppressions repository.
+func NewPGXContactSuppressionsRepository(pool *pgxpool.Pool) *PGXContactSuppressionsRepository {
+	return &PGXContactSuppressionsRepository{pool: pool}
+}
+
+const contactSuppressionColumns = `id, kind, value, reason, note, campaign_id, created_by, created_at`
+
+func scanContactSuppression(row pgx.Row, suppression *entity.ContactSuppression) error {
+	return row.Scan(
+		&suppression.ID, &suppression.Kind, &suppression.Value, &suppression.Reason, &suppression.Note,
+		&suppression.CampaignID, &suppression.CreatedBy, &suppression.CreatedAt,
+	)
+}
+
+// ListSuppressions returns the list newest first, only entries of kind when set and whose value
+// contains query when set.
+func (r *PGXContactSuppressionsRepository) ListSuppressions(ctx context.Context, kind, query string, limit, offset int) ([]entity.ContactSuppression, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+contactSuppressionColumns+`
+		FROM contact_suppressions
+		WHERE ($1 = '' OR kind = $1) AND ($2 = '' OR strpos(value, $2) > 0)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`, kind, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list contact suppressions: %w", err)
+	}
+	return collectContactSuppressions(rows)
+}
+
+func collectContactSuppressions(rows pgx.Rows) ([]entity.ContactSuppression, error) {
+	defer rows.Close()
+	suppressions := make([]entity.ContactSuppression, 0)
+	for rows.Next() {
+		var suppression entity.ContactSuppression
+		if err := scanContactSuppression(rows, &suppression); err != nil {
+			return nil, fmt.Errorf("scan contact suppression: %w", err)
+		}
+		suppressions = append(suppressions, suppression)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate contact suppressions: %w", err)
+	}
+	return suppressions, nil
+}
+
+// CreateSuppression inserts an entry and populates its identifier and timestamp.
+func (r *PGXContactSuppressionsRepository) CreateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error {
+	if suppression == nil {
+		return fmt.Errorf("contact suppression payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO contact_suppressions (kind, value, reason, note, campaign_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, suppression.Kind, suppression.Value, suppression.Reason, suppression.Note, suppression.CampaignID, suppression.CreatedBy).
+		Scan(&suppression.ID, &suppression.CreatedAt)
+	if err != nil {
+		if isSuppressionDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrSuppressionDuplicate, err)
+		}
+		return fmt.Errorf("insert contact suppression: %w", err)
+	}
+	return nil
+}
+
+// UpdateSuppression rewrites the kind, value and note of an entry by id and populates the rest.
+func (r *PGXContactSuppressionsRepository) UpdateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error {
+	if suppression == nil {
+		return fmt.Errorf("contact suppression payload is nil")
+	}
+	err := scanContactSuppression(r.pool.QueryRow(ctx, `
+		UPDATE contact_suppressions
+		SET kind = $2, value = $3, note = $4
+		WHERE id = $1
+		RETURNING `+contactSuppressionColumns+`
+	`, suppression.ID, suppression.Kind, suppression.Value, suppression.Note), suppression)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSuppressionNotFound
+		}
+		if isSuppressionDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrSuppressionDuplicate, err)
+		}
+		return fmt.Errorf("update contact suppression: %w", err)
+	}
+	return nil
+}
+
+// DeleteSuppression removes an entry by id.
+func (r *PGXContactSuppressionsRepository) DeleteSuppression(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM contact_suppressions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete contact suppression: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSuppressionNotFound
+	}
+	return nil
+}
+
+// MatchSuppressions returns the entries listing any of the given emails, domains or phone numbers.
+// Values are compared as they are, so callers normalise them first.
+func (r *PGXContactSuppressionsRepository) MatchSuppressions(ctx context.Context, emails, domains, phones []string) ([]entity.ContactSuppression, error) {
+	if len(emails) == 0 && len(domains) == 0 && len(phones) == 0 {
+		return []entity.ContactSuppression{}, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+contactSuppressionColumns+`
+		FROM contact_suppressions
+		WHERE (kind = $1 AND value = ANY($2))
+			OR (kind = $3 AND value = ANY($4))
+			OR (kind = $5 AND value = ANY($6))
+	`, entity.SuppressionKindEmail, stringSliceOrEmpty(emails),
+		entity.SuppressionKindDomain, stringSliceOrEmpty(domains),
+		entity.SuppressionKindPhone, stringSliceOrEmpty(phones))
+	if err != nil {
+		return nil, fmt.Errorf("match contact suppressions: %w", err)
+	}
+	return collectContactSuppressions(rows)
+}
+
+func isSuppressionDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "contact_suppressions_kind_value_key"
+}
+
+// suppressedEmailSQL is a condition holding when the email in column, lowercase, or its domain or
+// a parent domain is on the do-not-contact list.
+func suppressedEmailSQL(column string) string {
+	return `EXISTS (
+		SELECT 1 FROM contact_suppressions s
+		WHERE (s.kind = 'email' AND s.value = ` + column + `)
+			OR (s.kind = 'domain' AND (split_part(` + column + `, '@', 2) = s.value
+				OR split_part(` + column + `, '@', 2) LIKE '%.' || s.value))
+	)`
+}
+
+// ListCompanyContacts returns the phone number, website and enrichment contacts of the companies,
+// with the enrichment and website contacts decrypted. Companies that do not exist are left out.
+func (r *PGXCompaniesRepository) ListCompanyContacts(ctx context.Context, ids []uuid.UUID) ([]entity.CompanyContacts, error) {
+	contacts := make([]entity.CompanyContacts, 0, len(ids))
+	if len(ids) == 0 {
+		return contacts, nil
+	}
+	rows, err := r.reader().Query(ctx, `
+		SELECT c.id, COALESCE(c.phone, ''), COALESCE(c.website, ''),
+			COALESCE(e.emails, ARRAY[]::TEXT[]), COALESCE(e.phones, ARRAY[]::TEXT[]),
+			COALESCE(w.emails, ARRAY[]::TEXT[]), COALESCE(w.phones, ARRAY[]::TEXT[])
+		FROM companies c
+		LEFT JOIN company_enrichments e ON e.company_id = c.id
+		LEFT JOIN website_enriched_contacts w ON w.company_id = c.id
+		WHERE c.id = ANY($1)
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list company contacts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			company                      entity.CompanyContacts
+			phone                        string
+			enrichment                   entity.CompanyEnrichment
+			websiteEmails, websitePhones []string
+		)
+		if err := rows.Scan(&company.CompanyID, &phone, &company.Website, &enrichment.Emails, &enrichment.Phones, &websiteEmails, &websitePhones); err != nil {
+			return nil, fmt.Errorf("scan company contacts: %w", err)
+		}
+		enrichment.CompanyID = company.CompanyID
+		if err := decryptContacts(r.cipher, &enrichment); err != nil {
+			return nil, err
+		}
+		websiteEmails, websitePhones, err := decryptContactLists(r.cipher, company.CompanyID.String(), websiteEmails, websitePhones)
+		if err != nil {
+			return nil, err
+		}
+		company.Emails = append(enrichment.Emails, websiteEmails...)
+		company.Phones = append(enrichment.Phones, websitePhones...)
+		if phone != "" {
+			company.Phones = append(company.Phones, phone)
+		}
+		contacts = append(contacts, company)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company contacts: %w", err)
+	}
+	return contacts, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+func TestPGXContactSuppressionsRepository_CreateDuplicate(t *testing.T) {
+	repo := &PGXContactSuppressionsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23505", ConstraintName: "contact_suppressions_kind_value_key"}
+			}}
+		},
+	}}
+
+	err := repo.CreateSuppression(context.Background(), &entity.ContactSuppression{Kind: entity.SuppressionKindEmail, Value: "owner@example.com"})
+	if !errors.Is(err, ErrSuppressionDuplicate) {
+		t.Fatalf("expected ErrSuppressionDuplicate, got %v", err)
+	}
+}
+
+func TestPGXContactSuppressionsRepository_DeleteMissing(t *testing.T) {
+	repo := &PGXContactSuppressionsRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		},
+	}}
+
+	if err := repo.DeleteSuppression(context.Background(), uuid.New()); !errors.Is(err, ErrSuppressionNotFound) {
+		t.Fatalf("expected ErrSuppressionNotFound, got %v", err)
+	}
+}
+
+func TestPGXCompaniesRepository_ListCompanyContacts(t *testing.T) {
+	cipher, _ := fieldcrypt.New(bytes.Repeat([]byte{7}, fieldcrypt.KeySize))
+	encrypted, err := cipher.Encrypt("owner@example.com")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	companyID := uuid.New()
+	repo := &PGXCompaniesRepository{cipher: cipher, pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = companyID
+				*dest[1].(*string) = "+62 21 555 1234"
+				*dest[2].(*string) = "https://example.com"
+				*dest[3].(*[]string) = []string{encrypted}
+				*dest[5].(*[]string) = []string{"sales@example.com"}
+				return nil
+			}}}, nil
+		},
+	}}
+
+	contacts, err := repo.ListCompanyContacts(context.Background(), []uuid.UUID{companyID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(contacts) != 1 || contacts[0].Website != "https://example.com" {
+		t.Fatalf("unexpected contacts %+v", contacts)
+	}
+	if got := contacts[0].Emails; len(got) != 2 || got[0] != "owner@example.com" || got[1] != "sales@example.com" {
+		t.Fatalf("expected decrypted enrichment emails before website emails, got %v", got)
+	}
+	if got := contacts[0].Phones; len(got) != 1 || got[0] != "+62 21 555 1234" {
+		t.Fatalf("expected the company phone, got %v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrCSVUploadNotFound indicates the requested upload record does not exist.
+var ErrCSVUploadNotFound = errors.New("csv upload not found")
+
+// CSVUploadsRepository persists records of retained admin CSV uploads.
+type CSVUploadsRepository interface {
+	Create(ctx context.Context, upload *entity.CSVUpload) error
+	Complete(ctx context.Context, upload *entity.CSVUpload) error
+	List(ctx context.Context, limit, offset int) ([]entity.CSVUpload, error)
+	Get(ctx context.Context, id uuid.UUID) (*entity.CSVUpload, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.CSVUpload, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PGXCSVUploadsRepository implements CSVUploadsRepository using pgx.
+type PGXCSVUploadsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXCSVUploadsRepository wires a pgx backed CSV uploads repository.
+func NewPGXCSVUploadsRepository(pool *pgxpool.Pool) *PGXCSVUploadsRepository {
+	return &PGXCSVUploadsRepository{pool: pool}
+}
+
+const csvUploadColumns = `
+	id, uploaded_by, filename, sha256, size_bytes, storage_key, mode, status,
+	inserted, updated, skipped, total, error, created_at, completed_at, expires_at
+`
+
+// Create inserts a pending upload record and populates its creation timestamp.
+func (r *PGXCSVUploadsRepository) Create(ctx context.Context, upload *entity.CSVUpload) error {
+	if upload == nil {
+		return fmt.Errorf("csv upload payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO csv_uploads (id, uploaded_by, filename, sha256, size_bytes, storage_key, mode, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, upload.ID, upload.UploadedBy, upload.Filename, upload.SHA256, upload.SizeBytes, upload.StorageKey,
+		upload.Mode, upload.Status, upload.ExpiresAt).Scan(&upload.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert csv upload: %w", err)
+	}
+	return nil
+}
+
+// Complete stores the import outcome of an upload.
+func (r *PGXCSVUploadsRepository) Complete(ctx context.Context, upload *entity.CSVUpload) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE csv_uploads
+		SET status = $2, inserted = $3, updated = $4, skipped = $5, total = $6, error = $7, completed_at = $8
+		WHERE id = $1
+	`, upload.ID, upload.Status, upload.Inserted, upload.Updated, upload.Skipped, upload.Total, upload.Error, upload.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("complete csv upload: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCSVUploadNotFound
+	}
+	return nil
+}
+
+// List returns upload records, newest first.
+func (r *PGXCSVUploadsRepository) List(ctx context.Context, limit, offset int) ([]entity.CSVUpload, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+csvUploadColumns+`
+		FROM csv_uploads
+		ORDER BY created_at DESC, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list csv uploads: %w", err)
+	}
+	return collectCSVUploads(rows)
+}
+
+// Get returns a single upload record.
+func (r *PGXCSVUploadsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.CSVUpload, error) {
+	upload, err := scanCSVUpload(r.pool.QueryRow(ctx, `SELECT `+csvUploadColumns+` FROM csv_uploads WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCSVUploadNotFound
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// ListExpired returns up to limit uploads whose retention ended before now, oldest first.
+func (r *PGXCSVUploadsRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.CSVUpload, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+csvUploadColumns+`
+		FROM csv_uploads
+		WHERE expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list expired csv uploads: %w", err)
+	}
+	return collectCSVUploads(rows)
+}
+
+// Delete removes an upload record.
+func (r *PGXCSVUploadsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM csv_uploads WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete csv upload: %w", err)
+	}
+	return nil
+}
+
+func collectCSVUploads(rows pgx.Rows) ([]entity.CSVUpload, error) {
+	defer rows.Close()
+	uploads := make([]entity.CSVUpload, 0)
+	for rows.Next() {
+		upload, err := scanCSVUpload(rows)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, *upload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate csv uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+func scanCSVUpload(row pgx.Row) (*entity.CSVUpload, error) {
+	var upload entity.CSVUpload
+	err := row.Scan(
+		&upload.ID,
+		&upload.UploadedBy,
+		&upload.Filename,
+		&upload.SHA256,
+		&upload.SizeBytes,
+		&upload.StorageKey,
+		&upload.Mode,
+		&upload.Status,
+		&upload.Inserted,
+		&upload.Updated,
+		&upload.Skipped,
+		&upload.Total,
+		&upload.Error,
+		&upload.CreatedAt,
+		&upload.CompletedAt,
+		&upload.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan csv upload: %w", err)
+	}
+	return &upload, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// DeadLettersRepository persists worker callbacks the API could not store.
+type DeadLettersRepository interface {
+	Create(ctx context.Context, letter *entity.DeadLetter) error
+	// List returns the dead letters with status, every one when it is empty, newest first.
+	List(ctx context.Context, status string, limit, offset int) ([]entity.DeadLetter, error)
+	Get(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error)
+	UpdateBody(ctx context.Context, id uuid.UUID, body string) (*entity.DeadLetter, error)
+	// MarkReplayed records a successful replay; RecordReplayFailure a failed one, keeping the letter
+	// pending. Both count the attempt.
+	MarkReplayed(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error)
+	RecordReplayFailure(ctx context.Context, id uuid.UUID, reason string) (*entity.DeadLetter, error)
+}
+
+// ErrDeadLetterNotFound indicates the requested dead letter does not exist.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// PGXDeadLettersRepository implements DeadLettersRepository using pgx.
+type PGXDeadLettersRepository struct {
+	pool pgxPool
+}
+
+// NewPGXDeadLettersRepository wires a pgx backed dead letters repository.
+func NewPGXDeadLettersRepository(pool *pgxpool.Pool) *PGXDeadLettersRepository {
+	return &PGXDeadLettersRepository{pool: pool}
+}
+
+const deadLetterColumns = `id, kind, body, reason, details, company_id, job_id, status, attempts, last_error,
+	created_at, updated_at, replayed_at`
+
+func scanDeadLetter(row pgx.Row) (*entity.DeadLetter, error) {
+	var (
+		letter  entity.DeadLetter
+		details []byte
+	)
+	err := row.Scan(
+		&letter.ID, &letter.Kind, &letter.Body, &letter.Reason, &details, &letter.CompanyID, &letter.JobID,
+		&letter.Status, &letter.Attempts, &letter.LastError, &letter.CreatedAt, &letter.UpdatedAt, &letter.ReplayedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(details) > 0 {
+		if err := json.Unmarshal(details, &letter.Details); err != nil {
+			return nil, fmt.Errorf("decode dead letter details: %w", err)
+		}
+	}
+	return &letter, nil
+}
+
+// Create inserts a pending dead letter and populates its identifier and timestamps.
+func (r *PGXDeadLettersRepository) Create(ctx context.Context, letter *entity.DeadLetter) error {
+	if letter == nil {
+		return fmt.Errorf("dead letter payload is nil")
+	}
+	var details []byte
+	if len(letter.Details) > 0 {
+		encoded, err := json.Marshal(letter.Details)
+		if err != nil {
+			return fmt.Errorf("encode dead letter details: %w", err)
+		}
+		details = encoded
+	}
+	letter.Status = entity.DeadLetterPending
+	if err := r.pool.QueryRow(ctx, `
+		INSERT INTO dead_letters (kind, body, reason, details, company_id, job_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`, letter.Kind, letter.Body, letter.Reason, details, letter.CompanyID, letter.JobID, letter.Status).
+		Scan(&letter.ID, &letter.CreatedAt, &letter.UpdatedAt); err != nil {
+		return fmt.Errorf("insert dead letter: %w", err)
+	}
+	return nil
+}
+
+// List returns the dead letters with status, every one when it is empty, newest first.
+func (r *PGXDeadLettersRepository) List(ctx context.Context, status string, limit, offset int) ([]entity.DeadLetter, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deadLetterColumns+`
+		FROM dead_letters
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := make([]entity.DeadLetter, 0)
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan dead letter: %w", err)
+		}
+		letters = append(letters, *letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// Get returns a dead letter or ErrDeadLetterNotFound.
+func (r *PGXDeadLettersRepository) Get(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	return r.one(ctx, "get dead letter", `SELECT `+deadLetterColumns+` FROM dead_letters WHERE id = $1`, id)
+}
+
+// UpdateBody replaces the body of a dead letter, e.g. after fixing a rejected value.
+func (r *PGXDeadLettersRepository) UpdateBody(ctx context.Context, id uuid.UUID, body string) (*entity.DeadLetter, error) {
+	return r.one(ctx, "update dead letter", `
+		UPDATE dead_letters SET body = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+deadLetterColumns, id, body)
+}
+
+// MarkReplayed records that the body of a dead letter was stored.
+func (r *PGXDeadLettersRepository) MarkReplayed(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	return r.one(ctx, "mark dead letter replayed", `
+		UPDATE dead_letters
+		SET status = $2, attempts = attempts + 1, last_error = NULL, replayed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+deadLetterColumns, id, entity.DeadLetterReplayed)
+}
+
+// RecordReplayFailure records why a replay of a dead letter failed.
+func (r *PGXDeadLettersRepository) RecordReplayFailure(ctx context.Context, id uuid.UUID, reason string) (*entity.DeadLetter, error) {
+	return r.one(ctx, "record dead letter replay failure", `
+		UPDATE dead_letters
+		SET attempts = attempts + 1, last_error = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+deadLetterColumns, id, reason)
+}
+
+func (r *PGXDeadLettersRepository) one(ctx context.Context, action, query string, args ...any) (*entity.DeadLetter, error) {
+	letter, err := scanDeadLetter(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+	return letter, nil
+}
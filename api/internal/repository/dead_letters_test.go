@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXDeadLettersRepository_Create(t *testing.T) {
+	id := uuid.New()
+	repo := &PGXDeadLettersRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "INSERT INTO dead_letters") {
+				t.Fatalf("unexpected query %q", query)
+			}
+			if details, _ := args[3].([]byte); string(details) != `{"emails":"no MX record"}` || args[6] != entity.DeadLetterPending {
+				t.Fatalf("unexpected args %v", args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = id
+				*dest[1].(*time.Time) = time.Now()
+				return nil
+			}}
+		},
+	}}
+
+	letter := &entity.DeadLetter{Kind: entity.DeadLetterEnrichResult, Body: "{}", Reason: "invalid", Details: map[string]string{"emails": "no MX record"}}
+	if err := repo.Create(context.Background(), letter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if letter.ID != id || letter.Status != entity.DeadLetterPending {
+		t.Fatalf("unexpected letter: %+v", letter)
+	}
+}
+
+func TestPGXDeadLettersRepository_Get(t *testing.T) {
+	repo := &PGXDeadLettersRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[2].(*string) = `{"company_id":"x"}`
+				*dest[4].(*[]byte) = []byte(`{"phones":"invalid"}`)
+				return nil
+			}}
+		},
+	}}
+	letter, err := repo.Get(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if letter.Body != `{"company_id":"x"}` || letter.Details["phones"] != "invalid" {
+		t.Fatalf("unexpected letter: %+v", letter)
+	}
+
+	repo.pool = &stubPool{queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+		return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+	}}
+	if _, err := repo.MarkReplayed(context.Background(), uuid.New()); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Fatalf("expected ErrDeadLetterNotFound, got %v", err)
+	}
+}
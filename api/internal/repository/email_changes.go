@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrEmailChangeNotFound indicates no pending, unexpired email change matches the token.
+var ErrEmailChangeNotFound = errors.New("email change not found")
+
+// EmailChangesRepository persists email changes until their new address is confirmed.
+type EmailChangesRepository interface {
+	UpsertEmailChange(ctx context.Context, change *entity.EmailChange, tokenHash string) error
+	PendingEmailChange(ctx context.Context, userID uuid.UUID) (*entity.EmailChange, error)
+	ConfirmEmailChange(ctx context.Context, tokenHash string) (*entity.User, error)
+}
+
+// PGXEmailChangesRepository implements EmailChangesRepository using pgx.
+type PGXEmailChangesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXEmailChangesRepository wires a pgx backed email changes repository.
+func NewPGXEmailChangesRepository(pool *pgxpool.Pool) *PGXEmailChangesRepository {
+	return &PGXEmailChangesRepository{pool: pool}
+}
+
+// UpsertEmailChange stores the user's pending email change, replacing any earlier one. Addresses
+// that already belong to a user are reported with ErrEmailDuplicate.
+func (r *PGXEmailChangesRepository) UpsertEmailChange(ctx context.Context, change *entity.EmailChange, tokenHash string) error {
+	if change == nil {
+		return fmt.Errorf("email change payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO user_email_changes (user_id, email, token_hash, expires_at)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE lower(email) = lower($2))
+		ON CONFLICT (user_id) DO UPDATE
+		SET email = EXCLUDED.email, token_hash = EXCLUDED.token_hash,
+			expires_at = EXCLUDED.expires_at, created_at = NOW()
+		RETURNING created_at
+	`, change.UserID, change.Email, tokenHash, change.ExpiresAt).Scan(&change.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEmailDuplicate
+		}
+		return fmt.Errorf("upsert email change: %w", err)
+	}
+	return nil
+}
+
+// PendingEmailChange returns the user's unexpired email change, or nil when there is none.
+func (r *PGXEmailChangesRepository) PendingEmailChange(ctx context.Context, userID uuid.UUID) (*entity.EmailChange, error) {
+	change := entity.EmailChange{UserID: userID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT email, expires_at, created_at
+		FROM user_email_changes
+		WHERE user_id = $1 AND expires_at > NOW()
+	`, userID).Scan(&change.Email, &change.ExpiresAt, &change.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query email change: %w", err)
+	}
+	return &change, nil
+}
+
+// ConfirmEmailChange moves the user of the pending change matching tokenHash to its new address
+// and drops the change, in one statement. An address taken since the change was requested yields
+// ErrEmailDuplicate and leaves the change pending.
+func (r *PGXEmailChangesRepository) ConfirmEmailChange(ctx context.Context, tokenHash string) (*entity.User, error) {
+	var user entity.User
+	err := r.pool.QueryRow(ctx, `
+		WITH change AS (
+			DELETE FROM user_email_changes
+			WHERE token_hash = $1 AND expires_at > NOW()
+			RETURNING user_id, email
+		)
+		UPDATE users u
+		SET email = change.email, updated_at = NOW()
+		FROM change
+		WHERE u.id = change.user_id
+		RETURNING u.id, u.email, u.password_hash, u.role, u.created_at, u.updated_at
+	`, tokenHash).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEmailChangeNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && strings.Contains(pgErr.ConstraintName, "users_email") {
+			return nil, fmt.Errorf("%w: %v", ErrEmailDuplicate, pgErr)
+		}
+		return nil, fmt.Errorf("confirm email change: %w", err)
+	}
+	return &user, nil
+}
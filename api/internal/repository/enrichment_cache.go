@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// ErrDomainEnrichmentNotFound indicates there is no cached enrichment for the domain.
+var ErrDomainEnrichmentNotFound = errors.New("domain enrichment not found")
+
+// EnrichmentCacheRepository persists enrichment results keyed by canonical domain.
+type EnrichmentCacheRepository interface {
+	UpsertDomainEnrichment(ctx context.Context, record *entity.DomainEnrichment) error
+	GetDomainEnrichment(ctx context.Context, domain string) (*entity.DomainEnrichment, error)
+}
+
+// PGXEnrichmentCacheRepository implements EnrichmentCacheRepository using pgx.
+type PGXEnrichmentCacheRepository struct {
+	pool   pgxPool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPGXEnrichmentCacheRepository wires a pgx backed domain enrichment cache; WithEnrichmentCipher
+// encrypts the cached emails and phone numbers.
+func NewPGXEnrichmentCacheRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXEnrichmentCacheRepository {
+	return &PGXEnrichmentCacheRepository{pool: pool, cipher: newRepositoryOptions(opts).cipher}
+}
+
+// UpsertDomainEnrichment stores the latest enrichment for a domain, refreshing updated_at.
+func (r *PGXEnrichmentCacheRepository) UpsertDomainEnrichment(ctx context.Context, record *entity.DomainEnrichment) error {
+	if record == nil {
+		return fmt.Errorf("domain enrichment payload is nil")
+	}
+	if record.Domain == "" {
+		return fmt.Errorf("domain enrichment requires a domain")
+	}
+
+	socials := record.Socials
+	if socials == nil {
+		socials = map[string][]string{}
+	}
+	socialsJSON, err := json.Marshal(socials)
+	if err != nil {
+		return fmt.Errorf("marshal socials: %w", err)
+	}
+	metadata := record.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	emails, phones, err := encryptContactLists(r.cipher, record.Emails, record.Phones)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO domain_enrichments (
+			domain,
+			source_company_id,
+			depth,
+			emails,
+			phones,
+			socials,
+			address,
+			contact_form_url,
+			about_summary,
+			metadata,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, $9, $10::jsonb, NOW())
+		ON CONFLICT (domain) DO UPDATE SET
+			source_company_id = EXCLUDED.source_company_id,
+			depth = EXCLUDED.depth,
+			emails = EXCLUDED.emails,
+			phones = EXCLUDED.phones,
+			socials = EXCLUDED.socials,
+			address = EXCLUDED.address,
+			contact_form_url = EXCLUDED.contact_form_url,
+			about_summary = EXCLUDED.about_summary,
+			metadata = EXCLUDED.metadata,
+			updated_at = NOW();
+	`
+
+	_, err = r.pool.Exec(ctx, query,
+		record.Domain,
+		record.SourceCompanyID,
+		record.Depth,
+		emails,
+		phones,
+		string(socialsJSON),
+		record.Address,
+		record.ContactFormURL,
+		record.AboutSummary,
+		string(metadataJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert domain enrichment: %w", err)
+	}
+	return nil
+}
+
+// GetDomainEnrichment returns the cached enrichment for a domain.
+func (r *PGXEnrichmentCacheRepository) GetDomainEnrichment(ctx context.Context, domain string) (*entity.DomainEnrichment, error) {
+	query := `
+		SELECT
+			domain,
+			source_company_id,
+			depth,
+			emails,
+			phones,
+			socials,
+			address,
+			contact_form_url,
+			about_summary,
+			metadata,
+			created_at,
+			updated_at
+		FROM domain_enrichments
+		WHERE domain = $1
+	`
+
+	var (
+		record       entity.DomainEnrichment
+		sourceID     sql.NullString
+		emails       []string
+		phones       []string
+		socialsJSON  []byte
+		metadataJSON []byte
+		address      sql.NullString
+		contactForm  sql.NullString
+		aboutSummary sql.NullString
+	)
+
+	err := r.pool.QueryRow(ctx, query, domain).Scan(
+		&record.Domain,
+		&sourceID,
+		&record.Depth,
+		&emails,
+		&phones,
+		&socialsJSON,
+		&address,
+		&contactForm,
+		&aboutSummary,
+		&metadataJSON,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDomainEnrichmentNotFound
+		}
+		return nil, fmt.Errorf("fetch domain enrichment: %w", err)
+	}
+	if emails, phones, err = decryptContactLists(r.cipher, record.Domain, emails, phones); err != nil {
+		return nil, err
+	}
+
+	if sourceID.Valid {
+		parsed, err := uuid.Parse(sourceID.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse source_company_id: %w", err)
+		}
+		record.SourceCompanyID = &parsed
+	}
+	if len(emails) > 0 {
+		record.Emails = append([]string(nil), emails...)
+	}
+	if len(phones) > 0 {
+		record.Phones = append([]string(nil), phones...)
+	}
+	if len(socialsJSON) > 0 {
+		if err := json.Unmarshal(socialsJSON, &record.Socials); err != nil {
+			return nil, fmt.Errorf("unmarshal socials: %w", err)
+		}
+	}
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &record.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshal metadata: %w", err)
+		}
+	}
+	record.Address = nullStringToPtr(address)
+	record.ContactFormURL = nullStringToPtr(contactForm)
+	record.AboutSummary = nullStringToPtr(aboutSummary)
+
+	return &record, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// WithEnrichmentCipher encrypts the emails and phone numbers of company_enrichments, and of their
+// copies in website_enriched_contacts and domain_enrichments, before they are written and decrypts
+// them when read. Rows written before the cipher was configured are read
+// as they are until the backfill rewrites them; nil stores new rows in plaintext.
+func WithEnrichmentCipher(cipher *fieldcrypt.Cipher) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.cipher = cipher
+	}
+}
+
+// encryptContacts returns the emails and phone numbers of enrichment as they are stored.
+func encryptContacts(cipher *fieldcrypt.Cipher, enrichment *entity.CompanyEnrichment) ([]string, []string, error) {
+	return encryptContactLists(cipher, enrichment.Emails, enrichment.Phones)
+}
+
+// encryptContactLists returns emails and phones as they are stored.
+func encryptContactLists(cipher *fieldcrypt.Cipher, emails, phones []string) ([]string, []string, error) {
+	emails, err := cipher.EncryptAll(stringSliceOrEmpty(emails))
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypt emails: %w", err)
+	}
+	phones, err = cipher.EncryptAll(stringSliceOrEmpty(phones))
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypt phones: %w", err)
+	}
+	return emails, phones, nil
+}
+
+// decryptContacts replaces the stored emails and phone numbers of record with their plaintext.
+func decryptContacts(cipher *fieldcrypt.Cipher, record *entity.CompanyEnrichment) error {
+	emails, phones, err := decryptContactLists(cipher, record.CompanyID.String(), record.Emails, record.Phones)
+	if err != nil {
+		return err
+	}
+	record.Emails, record.Phones = emails, phones
+	return nil
+}
+
+// decryptContactLists returns the plaintext of the stored emails and phones of the row named by
+// owner.
+func decryptContactLists(cipher *fieldcrypt.Cipher, owner string, emails, phones []string) ([]string, []string, error) {
+	emails, err := cipher.DecryptAll(emails)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt emails of %s: %w", owner, err)
+	}
+	phones, err = cipher.DecryptAll(phones)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt phones of %s: %w", owner, err)
+	}
+	return emails, phones, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+func TestPGXCompaniesRepository_EnrichmentCipher(t *testing.T) {
+	cipher, _ := fieldcrypt.New(bytes.Repeat([]byte{7}, fieldcrypt.KeySize))
+	var stored []any
+	pool := &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			stored = args
+			return pgconn.CommandTag{}, nil
+		},
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = stored[0].(uuid.UUID)
+				*dest[1].(*[]string) = stored[1].([]string)
+				*dest[2].(*[]string) = stored[2].([]string)
+				return nil
+			}}
+		},
+	}
+	repo := &PGXCompaniesRepository{pool: pool, cipher: cipher}
+
+	enrichment := &entity.CompanyEnrichment{CompanyID: uuid.New(), Emails: []string{"info@example.com"}, Phones: []string{"+62215550100"}}
+	if err := repo.UpsertEnrichment(context.Background(), enrichment); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	emails, phones := stored[1].([]string), stored[2].([]string)
+	if !fieldcrypt.IsEncrypted(emails[0]) || !fieldcrypt.IsEncrypted(phones[0]) {
+		t.Fatalf("expected contacts to be stored encrypted, got %v %v", emails, phones)
+	}
+
+	got, err := repo.GetEnrichment(context.Background(), enrichment.CompanyID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Emails[0] != "info@example.com" || got.Phones[0] != "+62215550100" {
+		t.Fatalf("expected contacts to be read decrypted, got %v %v", got.Emails, got.Phones)
+	}
+
+	stored[1] = []string{"legacy@example.com"}
+	if got, err := repo.GetEnrichment(context.Background(), enrichment.CompanyID); err != nil || got.Emails[0] != "legacy@example.com" {
+		t.Fatalf("expected rows from before the key to be read as they are, got %v (%v)", got, err)
+	}
+}
+
+func TestEnrichmentCipher_EncryptsContactCopies(t *testing.T) {
+	cipher, _ := fieldcrypt.New(bytes.Repeat([]byte{7}, fieldcrypt.KeySize))
+	var stored []any
+	// column and arg are where the emails are read and written, phones following them.
+	var column, arg int
+	pool := &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			stored = args
+			return pgconn.CommandTag{}, nil
+		},
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[column].(*[]string) = stored[arg].([]string)
+				*dest[column+1].(*[]string) = stored[arg+1].([]string)
+				return nil
+			}}
+		},
+	}
+
+	companies := &PGXCompaniesRepository{pool: pool, cipher: cipher}
+	column, arg = 2, 1
+	contact := &entity.WebsiteEnrichedContact{CompanyID: uuid.New(), Emails: []string{"info@example.com"}, Phones: []string{"+62215550100"}}
+	if err := companies.UpsertEnrichedContacts(context.Background(), contact); err != nil {
+		t.Fatalf("upsert website contacts: %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(stored[1].([]string)[0]) || !fieldcrypt.IsEncrypted(stored[2].([]string)[0]) {
+		t.Fatalf("expected website contacts to be stored encrypted, got %v %v", stored[1], stored[2])
+	}
+	got, err := companies.GetByCompanyID(context.Background(), contact.CompanyID)
+	if err != nil || got.Emails[0] != "info@example.com" || got.Phones[0] != "+62215550100" {
+		t.Fatalf("expected website contacts to be read decrypted, got %+v (%v)", got, err)
+	}
+
+	cache := &PGXEnrichmentCacheRepository{pool: pool, cipher: cipher}
+	column, arg = 3, 3
+	record := &entity.DomainEnrichment{Domain: "example.com", Emails: []string{"info@example.com"}, Phones: []string{"+62215550100"}}
+	if err := cache.UpsertDomainEnrichment(context.Background(), record); err != nil {
+		t.Fatalf("upsert domain enrichment: %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(stored[3].([]string)[0]) || !fieldcrypt.IsEncrypted(stored[4].([]string)[0]) {
+		t.Fatalf("expected the domain cache to be stored encrypted, got %v %v", stored[3], stored[4])
+	}
+	cached, err := cache.GetDomainEnrichment(context.Background(), "example.com")
+	if err != nil || cached.Emails[0] != "info@example.com" || cached.Phones[0] != "+62215550100" {
+		t.Fatalf("expected the domain cache to be read decrypted, got %+v (%v)", cached, err)
+	}
+}
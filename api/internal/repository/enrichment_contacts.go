@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ContactTable is a table whose emails and phones columns hold enrichment contacts, encrypted by
+// WithEnrichmentCipher.
+type ContactTable struct {
+	Name string
+	// key is the column rows are paged and rewritten by, company the column naming their company.
+	key, company string
+	// first sorts before every key.
+	first string
+}
+
+// Tables holding enrichment contacts.
+var (
+	EnrichmentContactTable = ContactTable{Name: "company_enrichments", key: "company_id", company: "company_id", first: uuid.Nil.String()}
+	WebsiteContactTable    = ContactTable{Name: "website_enriched_contacts", key: "company_id", company: "company_id", first: uuid.Nil.String()}
+	DomainContactTable     = ContactTable{Name: "domain_enrichments", key: "domain", company: "source_company_id"}
+)
+
+// ContactTables lists every table holding enrichment contacts.
+var ContactTables = []ContactTable{EnrichmentContactTable, WebsiteContactTable, DomainContactTable}
+
+// StoredContacts are the emails and phone numbers of one row of a ContactTable, exactly as stored.
+type StoredContacts struct {
+	// Key is the value of the key column of the row, as text.
+	Key    string
+	Emails []string
+	Phones []string
+}
+
+// EnrichmentContactsRepository reads and rewrites the emails and phone numbers of the contact
+// tables exactly as stored, to encrypt the rows written before a key was configured or rotated.
+type EnrichmentContactsRepository interface {
+	// ListStoredContactsAfter pages through the rows of table ordered by key, starting after
+	// after; an empty after starts at the first row.
+	ListStoredContactsAfter(ctx context.Context, table ContactTable, after string, limit int) ([]StoredContacts, error)
+	// ReplaceStoredContacts swaps stored for replacement and reports false, changing nothing, when
+	// the row no longer holds stored.
+	ReplaceStoredContacts(ctx context.Context, table ContactTable, stored, replacement StoredContacts) (bool, error)
+}
+
+// PGXEnrichmentContactsRepository implements EnrichmentContactsRepository using pgx.
+type PGXEnrichmentContactsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXEnrichmentContactsRepository wires a pgx backed enrichment contacts repository.
+func NewPGXEnrichmentContactsRepository(pool *pgxpool.Pool) *PGXEnrichmentContactsRepository {
+	return &PGXEnrichmentContactsRepository{pool: pool}
+}
+
+// ListStoredContactsAfter pages through the stored contacts of table ordered by its key.
+func (r *PGXEnrichmentContactsRepository) ListStoredContactsAfter(ctx context.Context, table ContactTable, after string, limit int) ([]StoredContacts, error) {
+	if after == "" {
+		after = table.first
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+table.key+`::text, emails, phones
+		FROM `+table.Name+`
+		WHERE `+table.key+` > $1
+		ORDER BY `+table.key+`
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list %s contacts: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	var records []StoredContacts
+	for rows.Next() {
+		var record StoredContacts
+		if err := rows.Scan(&record.Key, &record.Emails, &record.Phones); err != nil {
+			return nil, fmt.Errorf("scan %s contacts: %w", table.Name, err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s contacts: %w", table.Name, err)
+	}
+	return records, nil
+}
+
+// ReplaceStoredContacts rewrites the contacts of one row. updated_at is left alone: the contacts
+// are the same, only stored differently.
+func (r *PGXEnrichmentContactsRepository) ReplaceStoredContacts(ctx context.Context, table ContactTable, stored, replacement StoredContacts) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE `+table.Name+`
+		SET emails = $2, phones = $3
+		WHERE `+table.key+` = $1 AND emails = $4 AND phones = $5
+	`, stored.Key,
+		stringSliceOrEmpty(replacement.Emails), stringSliceOrEmpty(replacement.Phones),
+		stringSliceOrEmpty(stored.Emails), stringSliceOrEmpty(stored.Phones))
+	if err != nil {
+		return false, fmt.Errorf("replace %s contacts: %w", table.Name, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
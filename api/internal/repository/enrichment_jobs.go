@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// EnrichmentJobsRepository persists enrichment job accounting records.
+type EnrichmentJobsRepository interface {
+	Create(ctx context.Context, job *entity.EnrichmentJob) error
+	// Reserve records job when its cost fits in what its requester has left of quota since the
+	// given instant and returns the cost they had used before it. A quota of zero or less, or a job
+	// without requester, records job unconditionally.
+	Reserve(ctx context.Context, job *entity.EnrichmentJob, since time.Time, quota int) (int, error)
+	SetStatus(ctx context.Context, id uuid.UUID, status string, reason *string) error
+}
+
+// ErrEnrichmentQuotaExceeded indicates a reservation would take its requester past the quota.
+var ErrEnrichmentQuotaExceeded = errors.New("enrichment quota exceeded")
+
+// PGXEnrichmentJobsRepository implements EnrichmentJobsRepository using pgx.
+type PGXEnrichmentJobsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXEnrichmentJobsRepository wires a pgx backed enrichment jobs repository.
+func NewPGXEnrichmentJobsRepository(pool *pgxpool.Pool) *PGXEnrichmentJobsRepository {
+	return &PGXEnrichmentJobsRepository{pool: pool}
+}
+
+const insertEnrichmentJobSQL = `
+	INSERT INTO enrichment_jobs (id, company_id, requested_by, website, depth, cost, status, error)
+	VALUES (COALESCE($1::uuid, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at
+`
+
+// Create inserts a job row and populates its timestamp and, unless the job carries one, its
+// generated identifier.
+func (r *PGXEnrichmentJobsRepository) Create(ctx context.Context, job *entity.EnrichmentJob) error {
+	if job == nil {
+		return fmt.Errorf("enrichment job payload is nil")
+	}
+	var id *uuid.UUID
+	if job.ID != uuid.Nil {
+		id = &job.ID
+	}
+
+	row := r.pool.QueryRow(ctx, insertEnrichmentJobSQL, id, job.CompanyID, job.RequestedBy, job.Website, job.Depth, job.Cost, job.Status, job.Error)
+
+	if err := row.Scan(&job.ID, &job.CreatedAt); err != nil {
+		return fmt.Errorf("insert enrichment job: %w", err)
+	}
+	return nil
+}
+
+// Reserve serialises the reservations of a requester with a transaction-scoped advisory lock, so
+// concurrent requests cannot all pass the quota check before any of them is recorded. Failed jobs
+// keep their cost for a retry but consume nothing until the worker accepts them.
+func (r *PGXEnrichmentJobsRepository) Reserve(ctx context.Context, job *entity.EnrichmentJob, since time.Time, quota int) (int, error) {
+	if job == nil {
+		return 0, fmt.Errorf("enrichment job payload is nil")
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("begin reserve enrichment job: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	used := 0
+	if quota > 0 && job.RequestedBy != nil {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('enrichment_quota:' || $1::text))`, *job.RequestedBy); err != nil {
+			return 0, fmt.Errorf("lock enrichment quota: %w", err)
+		}
+		if err := tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(cost), 0)
+			FROM enrichment_jobs
+			WHERE requested_by = $1 AND created_at >= $2 AND status <> 'failed'
+		`, *job.RequestedBy, since).Scan(&used); err != nil {
+			return 0, fmt.Errorf("sum enrichment job cost: %w", err)
+		}
+		if used+job.Cost > quota {
+			return used, ErrEnrichmentQuotaExceeded
+		}
+	}
+
+	var id *uuid.UUID
+	if job.ID != uuid.Nil {
+		id = &job.ID
+	}
+	if err := tx.QueryRow(ctx, insertEnrichmentJobSQL, id, job.CompanyID, job.RequestedBy, job.Website, job.Depth, job.Cost, job.Status, job.Error).Scan(&job.ID, &job.CreatedAt); err != nil {
+		return 0, fmt.Errorf("insert enrichment job: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit reserve enrichment job: %w", err)
+	}
+	return used, nil
+}
+
+// SetStatus moves a job to status, recording reason as its error.
+func (r *PGXEnrichmentJobsRepository) SetStatus(ctx context.Context, id uuid.UUID, status string, reason *string) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE enrichment_jobs SET status = $2, error = $3 WHERE id = $1
+	`, id, status, reason); err != nil {
+		return fmt.Errorf("update enrichment job status: %w", err)
+	}
+	return nil
+}
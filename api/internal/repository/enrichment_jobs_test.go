@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXEnrichmentJobsRepository_Reserve(t *testing.T) {
+	used := 6
+	var statements []string
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			statements = append(statements, query)
+			return &stubRow{scan: func(dest ...any) error {
+				switch {
+				case strings.Contains(query, "SUM(cost)"):
+					*dest[0].(*int) = used
+				case strings.Contains(query, "INSERT INTO enrichment_jobs"):
+					*dest[0].(*uuid.UUID) = *args[0].(*uuid.UUID)
+					*dest[1].(*time.Time) = time.Now()
+				}
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			statements = append(statements, query)
+			return pgconn.CommandTag{}, nil
+		},
+	}
+	repo := &PGXEnrichmentJobsRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+	requester := uuid.New()
+
+	job := &entity.EnrichmentJob{ID: uuid.New(), CompanyID: uuid.New(), RequestedBy: &requester, Cost: 5, Status: "reserved"}
+	if got, err := repo.Reserve(context.Background(), job, time.Now().Add(-24*time.Hour), 10); !errors.Is(err, ErrEnrichmentQuotaExceeded) || got != 6 || tx.committed {
+		t.Fatalf("expected the reservation refused, got %d, %v", got, err)
+	}
+	if len(statements) != 2 || !strings.Contains(statements[0], "pg_advisory_xact_lock") {
+		t.Fatalf("expected the requester locked before the quota is summed, got %v", statements)
+	}
+
+	used, statements = 4, nil
+	if got, err := repo.Reserve(context.Background(), job, time.Now().Add(-24*time.Hour), 10); err != nil || got != 4 || !tx.committed {
+		t.Fatalf("expected the job reserved, got %d, %v", got, err)
+	}
+	if len(statements) != 3 || !strings.Contains(statements[2], "INSERT INTO enrichment_jobs") || job.CreatedAt.IsZero() {
+		t.Fatalf("unexpected statements %v", statements)
+	}
+}
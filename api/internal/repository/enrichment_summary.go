@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// enrichmentSummaryJoin joins the enrichment of each listed company through a lateral subquery,
+// so its columns, named apart from those of companies, leave the unqualified listing clauses
+// unambiguous. Counting encrypted emails and phone numbers needs no key.
+const enrichmentSummaryJoin = ` LEFT JOIN LATERAL (
+	SELECT
+		cardinality(ce.emails) AS summary_email_count,
+		cardinality(ce.phones) AS summary_phone_count,
+		COALESCE(
+			ce.socials->'linkedin'->>0, ce.socials->'facebook'->>0, ce.socials->'instagram'->>0,
+			ce.socials->'youtube'->>0, ce.socials->'tiktok'->>0, ce.socials->'twitter'->>0,
+			ce.socials->'whatsapp'->>0
+		) AS summary_best_social
+	FROM company_enrichments ce
+	WHERE ce.company_id = companies.id
+) es ON TRUE`
+
+// enrichmentSummaryColumns are the columns enrichmentSummaryJoin adds to a listing. The best social
+// profile is the first found of LinkedIn, Facebook, Instagram, YouTube, TikTok, X and WhatsApp,
+// the order enrichment results report them in.
+const enrichmentSummaryColumns = `es.summary_email_count,
+	es.summary_phone_count,
+	es.summary_best_social,
+	(SELECT ls.score FROM company_lead_scores ls WHERE ls.company_id = companies.id) AS summary_score`
+
+// scanCompaniesWithSummary reads the rows of a listing selecting companyColumns followed by
+// enrichmentSummaryColumns.
+func scanCompaniesWithSummary(rows pgx.Rows) ([]entity.Company, error) {
+	var companies []entity.Company
+	for rows.Next() {
+		var emails, phones, score sql.NullInt64
+		var social sql.NullString
+		c, err := scanCompany(rows, &emails, &phones, &social, &score)
+		if err != nil {
+			return nil, err
+		}
+		summary := &entity.EnrichmentSummary{
+			Enriched:   emails.Valid,
+			EmailCount: int(emails.Int64),
+			PhoneCount: int(phones.Int64),
+			BestSocial: nullStringToPtr(social),
+		}
+		if score.Valid {
+			value := int(score.Int64)
+			summary.Score = &value
+		}
+		c.EnrichmentSummary = summary
+		companies = append(companies, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate companies: %w", err)
+	}
+	return companies, nil
+}
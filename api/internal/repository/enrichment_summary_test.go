@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+func TestPGXCompaniesRepository_ListEnrichmentSummary(t *testing.T) {
+	var query string
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, args ...any) (pgx.Rows, error) {
+			query = q
+			if !strings.Contains(q, "summary_") {
+				return &stubRows{}, nil
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					extra := dest[len(dest)-4:]
+					*extra[0].(*sql.NullInt64) = sql.NullInt64{Int64: 2, Valid: true}
+					*extra[1].(*sql.NullInt64) = sql.NullInt64{Int64: 0, Valid: true}
+					*extra[2].(*sql.NullString) = sql.NullString{String: "https://www.linkedin.com/company/kopi", Valid: true}
+					*extra[3].(*sql.NullInt64) = sql.NullInt64{Int64: 72, Valid: true}
+					return nil
+				},
+				func(dest ...any) error { return nil },
+			}}, nil
+		},
+	}}
+
+	companies, err := repo.List(context.Background(), dto.ListFilter{IncludeEnrichmentSummary: true, City: "Jakarta"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "LEFT JOIN LATERAL") || !strings.Contains(query, "es.summary_best_social") {
+		t.Fatalf("expected the enrichment summary joined, got %q", query)
+	}
+	if len(companies) != 2 {
+		t.Fatalf("expected 2 companies, got %d", len(companies))
+	}
+	summary := companies[0].EnrichmentSummary
+	if summary == nil || !summary.Enriched || summary.EmailCount != 2 || summary.PhoneCount != 0 ||
+		summary.BestSocial == nil || *summary.BestSocial != "https://www.linkedin.com/company/kopi" || summary.Score == nil || *summary.Score != 72 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if unenriched := companies[1].EnrichmentSummary; unenriched == nil || unenriched.Enriched || unenriched.BestSocial != nil || unenriched.Score != nil {
+		t.Fatalf("expected an empty summary for a company never enriched, got %+v", unenriched)
+	}
+
+	if _, err := repo.List(context.Background(), dto.ListFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(query, "summary_") {
+		t.Fatalf("expected no summary by default, got %q", query)
+	}
+}
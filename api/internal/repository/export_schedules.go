@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Export schedule repository errors.
+var (
+	ErrExportScheduleNotFound  = errors.New("export schedule not found")
+	ErrExportScheduleDuplicate = errors.New("export schedule already exists")
+)
+
+// ExportSchedulesRepository persists export schedules and their deliveries. Methods taking a user
+// id are scoped to the owning user, so a schedule id of another user is not found.
+type ExportSchedulesRepository interface {
+	List(ctx context.Context, userID uuid.UUID) ([]entity.ExportSchedule, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportSchedule, error)
+	Create(ctx context.Context, schedule *entity.ExportSchedule) error
+	Update(ctx context.Context, schedule *entity.ExportSchedule) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// ClaimDue returns up to limit enabled schedules due at now and postpones them to now+lease,
+	// so other instances skip them while they run and retry them should this one stop.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.ExportSchedule, error)
+	// Reschedule records a run of a schedule started at ranAt and sets when it runs next.
+	Reschedule(ctx context.Context, id uuid.UUID, ranAt, next time.Time) error
+	RecordDelivery(ctx context.Context, delivery *entity.ExportDelivery) error
+	ListDeliveries(ctx context.Context, scheduleID uuid.UUID, limit int) ([]entity.ExportDelivery, error)
+}
+
+// PGXExportSchedulesRepository implements ExportSchedulesRepository using pgx.
+type PGXExportSchedulesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXExportSchedulesRepository wires a pgx backed export schedules repository.
+func NewPGXExportSchedulesRepository(pool *pgxpool.Pool) *PGXExportSchedulesRepository {
+	return &PGXExportSchedulesRepository{pool: pool}
+}
+
+const exportScheduleColumns = `id, user_id, name, filter, template_id, destination, target, cron, timezone, enabled, next_run_at, last_run_at, created_at, updated_at`
+
+// List returns the schedules of a user ordered by name.
+func (r *PGXExportSchedulesRepository) List(ctx context.Context, userID uuid.UUID) ([]entity.ExportSchedule, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+exportScheduleColumns+`
+		FROM export_schedules
+		WHERE user_id = $1
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list export schedules: %w", err)
+	}
+	return scanExportSchedules(rows)
+}
+
+// Get returns a schedule of a user by id.
+func (r *PGXExportSchedulesRepository) Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportSchedule, error) {
+	var schedule entity.ExportSchedule
+	err := scanExportSchedule(r.pool.QueryRow(ctx, `
+		SELECT `+exportScheduleColumns+`
+		FROM export_schedules
+		WHERE id = $1 AND user_id = $2
+	`, id, userID), &schedule)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExportScheduleNotFound
+		}
+		return nil, fmt.Errorf("get export schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// Create inserts a schedule and populates its identifier and timestamps.
+func (r *PGXExportSchedulesRepository) Create(ctx context.Context, schedule *entity.ExportSchedule) error {
+	if schedule == nil {
+		return fmt.Errorf("export schedule payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO export_schedules (user_id, name, filter, template_id, destination, target, cron, timezone, enabled, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`, schedule.UserID, schedule.Name, schedule.Filter, schedule.TemplateID, schedule.Destination, schedule.Target,
+		schedule.Cron, schedule.Timezone, schedule.Enabled, schedule.NextRunAt,
+	).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		if isExportScheduleDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrExportScheduleDuplicate, err)
+		}
+		return fmt.Errorf("insert export schedule: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites a schedule of its user by id and refreshes its timestamps. The time of the last
+// run is kept.
+func (r *PGXExportSchedulesRepository) Update(ctx context.Context, schedule *entity.ExportSchedule) error {
+	if schedule == nil {
+		return fmt.Errorf("export schedule payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		UPDATE export_schedules
+		SET name = $3, filter = $4, template_id = $5, destination = $6, target = $7, cron = $8,
+			timezone = $9, enabled = $10, next_run_at = $11, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING last_run_at, created_at, updated_at
+	`, schedule.ID, schedule.UserID, schedule.Name, schedule.Filter, schedule.TemplateID, schedule.Destination,
+		schedule.Target, schedule.Cron, schedule.Timezone, schedule.Enabled, schedule.NextRunAt,
+	).Scan(&schedule.LastRunAt, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExportScheduleNotFound
+		}
+		if isExportScheduleDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrExportScheduleDuplicate, err)
+		}
+		return fmt.Errorf("update export schedule: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a schedule of a user by id, with its deliveries.
+func (r *PGXExportSchedulesRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM export_schedules WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete export schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrExportScheduleNotFound
+	}
+	return nil
+}
+
+// ClaimDue locks due schedules with SKIP LOCKED, so two API instances never claim the same run.
+func (r *PGXExportSchedulesRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.ExportSchedule, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH claimed AS (
+			SELECT id
+			FROM export_schedules
+			WHERE enabled AND next_run_at <= $1
+			ORDER BY next_run_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE export_schedules s
+		SET next_run_at = $2
+		FROM claimed
+		WHERE s.id = claimed.id
+		RETURNING s.id, s.user_id, s.name, s.filter, s.template_id, s.destination, s.target, s.cron, s.timezone,
+			s.enabled, s.next_run_at, s.last_run_at, s.created_at, s.updated_at
+	`, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim export schedules: %w", err)
+	}
+	return scanExportSchedules(rows)
+}
+
+// Reschedule records a run and sets the next one.
+func (r *PGXExportSchedulesRepository) Reschedule(ctx context.Context, id uuid.UUID, ranAt, next time.Time) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE export_schedules SET last_run_at = $2, next_run_at = $3 WHERE id = $1
+	`, id, ranAt, next); err != nil {
+		return fmt.Errorf("reschedule export schedule: %w", err)
+	}
+	return nil
+}
+
+// RecordDelivery inserts a delivery and populates its identifier.
+func (r *PGXExportSchedulesRepository) RecordDelivery(ctx context.Context, delivery *entity.ExportDelivery) error {
+	if delivery == nil {
+		return fmt.Errorf("export delivery payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO export_deliveries (schedule_id, status, row_count, file_name, location, error, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, delivery.ScheduleID, delivery.Status, delivery.RowCount, delivery.FileName, delivery.Location, delivery.Error,
+		delivery.StartedAt, delivery.FinishedAt,
+	).Scan(&delivery.ID)
+	if err != nil {
+		return fmt.Errorf("insert export delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the latest limit deliveries of a schedule, newest first.
+func (r *PGXExportSchedulesRepository) ListDeliveries(ctx context.Context, scheduleID uuid.UUID, limit int) ([]entity.ExportDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, schedule_id, status, row_count, file_name, location, error, started_at, finished_at
+		FROM export_deliveries
+		WHERE schedule_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list export deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.ExportDelivery, 0)
+	for rows.Next() {
+		var delivery entity.ExportDelivery
+		if err := rows.Scan(
+			&delivery.ID, &delivery.ScheduleID, &delivery.Status, &delivery.RowCount, &delivery.FileName,
+			&delivery.Location, &delivery.Error, &delivery.StartedAt, &delivery.FinishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan export delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate export deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func scanExportSchedules(rows pgx.Rows) ([]entity.ExportSchedule, error) {
+	defer rows.Close()
+
+	schedules := make([]entity.ExportSchedule, 0)
+	for rows.Next() {
+		var schedule entity.ExportSchedule
+		if err := scanExportSchedule(rows, &schedule); err != nil {
+			return nil, fmt.Errorf("scan export schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate export schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func scanExportSchedule(row pgx.Row, schedule *entity.ExportSchedule) error {
+	return row.Scan(
+		&schedule.ID, &schedule.UserID, &schedule.Name, &schedule.Filter, &schedule.TemplateID, &schedule.Destination,
+		&schedule.Target, &schedule.Cron, &schedule.Timezone, &schedule.Enabled, &schedule.NextRunAt, &schedule.LastRunAt,
+		&schedule.CreatedAt, &schedule.UpdatedAt,
+	)
+}
+
+func isExportScheduleDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "export_schedules_user_id_name_key"
+}
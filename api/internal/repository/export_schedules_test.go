@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXExportSchedulesRepository_ClaimDue(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	repo := &PGXExportSchedulesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "FOR UPDATE SKIP LOCKED") || !strings.Contains(query, "enabled AND next_run_at <= $1") {
+				t.Fatalf("expected due schedules locked for one instance, got %q", query)
+			}
+			if args[0] != now || args[1] != now.Add(30*time.Minute) || args[2] != 10 {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = id
+					*dest[5].(*string) = entity.ExportDestinationEmail
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	schedules, err := repo.ClaimDue(context.Background(), now, 30*time.Minute, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].ID != id || schedules[0].Destination != entity.ExportDestinationEmail {
+		t.Fatalf("unexpected schedules: %+v", schedules)
+	}
+}
+
+func TestPGXExportSchedulesRepository_ScopedToUser(t *testing.T) {
+	userID, id := uuid.New(), uuid.New()
+	var queries []string
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			queries = append(queries, query)
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			queries = append(queries, query)
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		},
+	}
+	repo := &PGXExportSchedulesRepository{pool: pool}
+	ctx := context.Background()
+
+	if _, err := repo.Get(ctx, userID, id); !errors.Is(err, ErrExportScheduleNotFound) {
+		t.Fatalf("expected ErrExportScheduleNotFound on get, got %v", err)
+	}
+	if err := repo.Update(ctx, &entity.ExportSchedule{ID: id, UserID: userID, Name: "Weekly"}); !errors.Is(err, ErrExportScheduleNotFound) {
+		t.Fatalf("expected ErrExportScheduleNotFound on update, got %v", err)
+	}
+	if err := repo.Delete(ctx, userID, id); !errors.Is(err, ErrExportScheduleNotFound) {
+		t.Fatalf("expected ErrExportScheduleNotFound on delete, got %v", err)
+	}
+	for _, query := range queries {
+		if !strings.Contains(query, "user_id = $2") {
+			t.Fatalf("expected query scoped to the user: %s", query)
+		}
+	}
+
+	pool.queryRowFunc = func(ctx context.Context, query string, args ...any) pgx.Row {
+		return &stubRow{scan: func(dest ...any) error {
+			return &pgconn.PgError{Code: "23505", ConstraintName: "export_schedules_user_id_name_key"}
+		}}
+	}
+	if err := repo.Create(ctx, &entity.ExportSchedule{UserID: userID, Name: "Weekly"}); !errors.Is(err, ErrExportScheduleDuplicate) {
+		t.Fatalf("expected ErrExportScheduleDuplicate, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Export template repository errors.
+var (
+	ErrExportTemplateNotFound  = errors.New("export template not found")
+	ErrExportTemplateDuplicate = errors.New("export template already exists")
+)
+
+// ExportTemplatesRepository persists the export templates of each user. Every method is scoped to
+// the owning user, so a template id of another user is not found.
+type ExportTemplatesRepository interface {
+	List(ctx context.Context, userID uuid.UUID) ([]entity.ExportTemplate, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportTemplate, error)
+	Create(ctx context.Context, template *entity.ExportTemplate) error
+	Update(ctx context.Context, template *entity.ExportTemplate) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// PGXExportTemplatesRepository implements ExportTemplatesRepository using pgx.
+type PGXExportTemplatesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXExportTemplatesRepository wires a pgx backed export templates repository.
+func NewPGXExportTemplatesRepository(pool *pgxpool.Pool) *PGXExportTemplatesRepository {
+	return &PGXExportTemplatesRepository{pool: pool}
+}
+
+const exportTemplateColumns = `id, user_id, name, delimiter, decimal_mark, date_format, bom, created_at, updated_at`
+
+// List returns the templates of a user ordered by name.
+func (r *PGXExportTemplatesRepository) List(ctx context.Context, userID uuid.UUID) ([]entity.ExportTemplate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+exportTemplateColumns+`
+		FROM export_templates
+		WHERE user_id = $1
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list export templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]entity.ExportTemplate, 0)
+	for rows.Next() {
+		var template entity.ExportTemplate
+		if err := scanExportTemplate(rows, &template); err != nil {
+			return nil, fmt.Errorf("scan export template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate export templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Get returns a template of a user by id.
+func (r *PGXExportTemplatesRepository) Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportTemplate, error) {
+	var template entity.ExportTemplate
+	err := scanExportTemplate(r.pool.QueryRow(ctx, `
+		SELECT `+exportTemplateColumns+`
+		FROM export_templates
+		WHERE id = $1 AND user_id = $2
+	`, id, userID), &template)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExportTemplateNotFound
+		}
+		return nil, fmt.Errorf("get export template: %w", err)
+	}
+	return &template, nil
+}
+
+// Create inserts a template and populates its identifier and timestamps.
+func (r *PGXExportTemplatesRepository) Create(ctx context.Context, template *entity.ExportTemplate) error {
+	if template == nil {
+		return fmt.Errorf("export template payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO export_templates (user_id, name, delimiter, decimal_mark, date_format, bom)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, template.UserID, template.Name, template.Delimiter, template.DecimalMark, template.DateFormat, template.BOM).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		if isExportTemplateDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrExportTemplateDuplicate, err)
+		}
+		return fmt.Errorf("insert export template: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites a template of its user by id and refreshes its timestamps.
+func (r *PGXExportTemplatesRepository) Update(ctx context.Context, template *entity.ExportTemplate) error {
+	if template == nil {
+		return fmt.Errorf("export template payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		UPDATE export_templates
+		SET name = $3, delimiter = $4, decimal_mark = $5, date_format = $6, bom = $7, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING created_at, updated_at
+	`, template.ID, template.UserID, template.Name, template.Delimiter, template.DecimalMark, template.DateFormat, template.BOM).Scan(&template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExportTemplateNotFound
+		}
+		if isExportTemplateDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrExportTemplateDuplicate, err)
+		}
+		return fmt.Errorf("update export template: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a template of a user by id.
+func (r *PGXExportTemplatesRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM export_templates WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete export template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrExportTemplateNotFound
+	}
+	return nil
+}
+
+func scanExportTemplate(row pgx.Row, template *entity.ExportTemplate) error {
+	return row.Scan(&template.ID, &template.UserID, &template.Name, &template.Delimiter, &template.DecimalMark, &template.DateFormat, &template.BOM, &template.CreatedAt, &template.UpdatedAt)
+}
+
+func isExportTemplateDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "export_templates_user_id_name_key"
+}
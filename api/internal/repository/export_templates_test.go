@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXExportTemplatesRepository_ScopedToUser(t *testing.T) {
+	userID, id := uuid.New(), uuid.New()
+	var queries []string
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			queries = append(queries, query)
+			if len(args) < 2 || args[0] != id || args[1] != userID {
+				t.Fatalf("expected id and user id args, got %v", args)
+			}
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			queries = append(queries, query)
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		},
+	}
+	repo := &PGXExportTemplatesRepository{pool: pool}
+	ctx := context.Background()
+
+	if _, err := repo.Get(ctx, userID, id); !errors.Is(err, ErrExportTemplateNotFound) {
+		t.Fatalf("expected ErrExportTemplateNotFound on get, got %v", err)
+	}
+	if err := repo.Update(ctx, &entity.ExportTemplate{ID: id, UserID: userID, Name: "Excel ID"}); !errors.Is(err, ErrExportTemplateNotFound) {
+		t.Fatalf("expected ErrExportTemplateNotFound on update, got %v", err)
+	}
+	if err := repo.Delete(ctx, userID, id); !errors.Is(err, ErrExportTemplateNotFound) {
+		t.Fatalf("expected ErrExportTemplateNotFound on delete, got %v", err)
+	}
+	for _, query := range queries {
+		if !strings.Contains(query, "user_id = $2") {
+			t.Fatalf("expected query scoped to the user: %s", query)
+		}
+	}
+}
+
+func TestPGXExportTemplatesRepository_CreateDuplicate(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23505", ConstraintName: "export_templates_user_id_name_key"}
+			}}
+		},
+	}
+	repo := &PGXExportTemplatesRepository{pool: pool}
+
+	err := repo.Create(context.Background(), &entity.ExportTemplate{UserID: uuid.New(), Name: "Excel ID"})
+	if !errors.Is(err, ErrExportTemplateDuplicate) {
+		t.Fatalf("expected ErrExportTemplateDuplicate, got %v", err)
+	}
+}
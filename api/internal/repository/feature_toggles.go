@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// FeatureTogglesRepository persists the admin kill switches of worker-dependent features.
+type FeatureTogglesRepository interface {
+	List(ctx context.Context) ([]entity.FeatureToggle, error)
+	Get(ctx context.Context, feature string) (*entity.FeatureToggle, error)
+	Upsert(ctx context.Context, toggle *entity.FeatureToggle) error
+}
+
+// PGXFeatureTogglesRepository implements FeatureTogglesRepository using pgx.
+type PGXFeatureTogglesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXFeatureTogglesRepository wires a pgx backed feature toggles repository.
+func NewPGXFeatureTogglesRepository(pool *pgxpool.Pool) *PGXFeatureTogglesRepository {
+	return &PGXFeatureTogglesRepository{pool: pool}
+}
+
+// List returns every stored toggle ordered by feature.
+func (r *PGXFeatureTogglesRepository) List(ctx context.Context) ([]entity.FeatureToggle, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT feature, disabled, message, eta, updated_by, updated_at
+		FROM feature_toggles
+		ORDER BY feature
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list feature toggles: %w", err)
+	}
+	defer rows.Close()
+
+	toggles := make([]entity.FeatureToggle, 0)
+	for rows.Next() {
+		var toggle entity.FeatureToggle
+		if err := rows.Scan(&toggle.Feature, &toggle.Disabled, &toggle.Message, &toggle.ETA, &toggle.UpdatedBy, &toggle.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan feature toggle: %w", err)
+		}
+		toggles = append(toggles, toggle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feature toggles: %w", err)
+	}
+	return toggles, nil
+}
+
+// Get returns the stored toggle of a feature, or nil when it was never toggled.
+func (r *PGXFeatureTogglesRepository) Get(ctx context.Context, feature string) (*entity.FeatureToggle, error) {
+	var toggle entity.FeatureToggle
+	err := r.pool.QueryRow(ctx, `
+		SELECT feature, disabled, message, eta, updated_by, updated_at
+		FROM feature_toggles
+		WHERE feature = $1
+	`, feature).Scan(&toggle.Feature, &toggle.Disabled, &toggle.Message, &toggle.ETA, &toggle.UpdatedBy, &toggle.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get feature toggle: %w", err)
+	}
+	return &toggle, nil
+}
+
+// Upsert stores the toggle of a feature and populates its update time.
+func (r *PGXFeatureTogglesRepository) Upsert(ctx context.Context, toggle *entity.FeatureToggle) error {
+	if toggle == nil {
+		return fmt.Errorf("feature toggle payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO feature_toggles (feature, disabled, message, eta, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (feature) DO UPDATE SET
+			disabled = EXCLUDED.disabled,
+			message = EXCLUDED.message,
+			eta = EXCLUDED.eta,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, toggle.Feature, toggle.Disabled, toggle.Message, toggle.ETA, toggle.UpdatedBy).Scan(&toggle.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert feature toggle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXFeatureTogglesRepository_GetUntoggled(t *testing.T) {
+	repo := &PGXFeatureTogglesRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}}
+
+	toggle, err := repo.Get(context.Background(), entity.FeatureScrape)
+	if err != nil || toggle != nil {
+		t.Fatalf("expected no toggle for an untouched feature, got %+v (%v)", toggle, err)
+	}
+}
+
+func TestPGXFeatureTogglesRepository_Upsert(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	repo := &PGXFeatureTogglesRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "ON CONFLICT (feature) DO UPDATE") {
+				t.Fatalf("expected an upsert, got %s", query)
+			}
+			if args[0] != entity.FeatureScrape || args[1] != true || args[2] != "provider outage" {
+				t.Fatalf("unexpected args %v", args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(**time.Time) = &updatedAt
+				return nil
+			}}
+		},
+	}}
+
+	toggle := &entity.FeatureToggle{Feature: entity.FeatureScrape, Disabled: true, Message: "provider outage"}
+	if err := repo.Upsert(context.Background(), toggle); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if toggle.UpdatedAt == nil || !toggle.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("expected the update time to be populated, got %+v", toggle)
+	}
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// FreshnessFilter narrows freshness segments to a city and/or business type; empty matches all.
+type FreshnessFilter struct {
+	City         string
+	TypeBusiness string
+	Limit        int
+}
+
+// FreshnessRepository aggregates scrape recency, enrichment lag and queue backlog.
+type FreshnessRepository interface {
+	FreshnessTotals(ctx context.Context, since time.Time) (*entity.DataFreshness, error)
+	SegmentFreshness(ctx context.Context, filter FreshnessFilter, since time.Time) ([]entity.SegmentFreshness, error)
+	// ScrapeCoverage counts the companies a scrape of the business type, city and country would find
+	// again, with the latest scrape of them.
+	ScrapeCoverage(ctx context.Context, typeBusiness, city, country string) (*entity.ScrapePreview, error)
+}
+
+// PGXFreshnessRepository implements FreshnessRepository using pgx.
+type PGXFreshnessRepository struct {
+	pool   ReadPool
+	limits queryLimits
+}
+
+// NewPGXFreshnessRepository wires a pgx backed freshness repository.
+func NewPGXFreshnessRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXFreshnessRepository {
+	return &PGXFreshnessRepository{pool: pool, limits: newQueryLimits(opts)}
+}
+
+// NewPGXFreshnessRepositoryWithReplica wires a freshness repository reading through reads, such as a
+// database.ReplicaRouter.
+func NewPGXFreshnessRepositoryWithReplica(reads ReadPool, opts ...RepositoryOption) *PGXFreshnessRepository {
+	return &PGXFreshnessRepository{pool: reads, limits: newQueryLimits(opts)}
+}
+
+// enrichmentJobProgress classifies accepted enrichment jobs created since $1: a job is pending
+// until the company's enrichment is written after it, and its lag is the time that took. Cached
+// jobs are answered immediately and say nothing about the queue.
+const enrichmentJobProgress = `
+	SELECT
+		j.company_id,
+		j.created_at,
+		e.updated_at IS NULL OR e.updated_at < j.created_at AS pending,
+		CASE WHEN e.updated_at >= j.created_at THEN EXTRACT(EPOCH FROM e.updated_at - j.created_at) END AS lag_seconds
+	FROM enrichment_jobs j
+	LEFT JOIN company_enrichments e ON e.company_id = j.company_id
+	WHERE j.status = 'accepted' AND j.created_at >= $1
+`
+
+// FreshnessTotals returns the catalogue-wide figures; segments are left empty.
+func (r *PGXFreshnessRepository) FreshnessTotals(ctx context.Context, since time.Time) (_ *entity.DataFreshness, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	var (
+		freshness     entity.DataFreshness
+		lastScraped   sql.NullTime
+		oldestPending sql.NullTime
+		avgLag        sql.NullFloat64
+	)
+	err = r.pool.QueryRow(ctx, `
+		WITH jobs AS (`+enrichmentJobProgress+`)
+		SELECT
+			(SELECT MAX(scraped_at) FROM companies),
+			COUNT(*) FILTER (WHERE pending),
+			MIN(created_at) FILTER (WHERE pending),
+			AVG(lag_seconds)::float8,
+			(SELECT COUNT(*) FROM import_jobs WHERE status IN ('queued', 'running'))
+		FROM jobs
+	`, since).Scan(&lastScraped, &freshness.PendingEnrichments, &oldestPending, &avgLag, &freshness.QueuedImports)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate freshness totals: %w", err)
+	}
+	if lastScraped.Valid {
+		val := lastScraped.Time
+		freshness.LastScrapedAt = &val
+	}
+	if oldestPending.Valid {
+		val := oldestPending.Time
+		freshness.OldestPendingAt = &val
+	}
+	if avgLag.Valid {
+		val := avgLag.Float64
+		freshness.AvgEnrichmentLagSeconds = &val
+	}
+	return &freshness, nil
+}
+
+// SegmentFreshness returns per city and business type figures, most recently scraped first.
+func (r *PGXFreshnessRepository) SegmentFreshness(ctx context.Context, filter FreshnessFilter, since time.Time) (_ []entity.SegmentFreshness, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.pool.Query(ctx, `
+		WITH jobs AS (`+enrichmentJobProgress+`),
+		segments AS (
+			SELECT
+				COALESCE(city, '') AS city,
+				COALESCE(type_business, '') AS type_business,
+				COUNT(*) AS companies,
+				(ARRAY_AGG(scrape_run_id ORDER BY scraped_at DESC NULLS LAST) FILTER (WHERE scrape_run_id IS NOT NULL))[1] AS last_run_id,
+				MAX(scraped_at) AS last_scraped_at
+			FROM companies
+			WHERE ($2 = '' OR LOWER(city) = LOWER($2))
+				AND ($3 = '' OR LOWER(type_business) = LOWER($3))
+			GROUP BY 1, 2
+		),
+		segment_jobs AS (
+			SELECT
+				COALESCE(c.city, '') AS city,
+				COALESCE(c.type_business, '') AS type_business,
+				COUNT(*) FILTER (WHERE jobs.pending) AS pending,
+				AVG(jobs.lag_seconds)::float8 AS lag_seconds
+			FROM jobs
+			JOIN companies c ON c.id = jobs.company_id
+			GROUP BY 1, 2
+		)
+		SELECT s.city, s.type_business, s.companies, s.last_run_id, s.last_scraped_at, COALESCE(j.pending, 0), j.lag_seconds
+		FROM segments s
+		LEFT JOIN segment_jobs j ON j.city = s.city AND j.type_business = s.type_business
+		ORDER BY s.last_scraped_at DESC NULLS LAST, s.city, s.type_business
+		LIMIT $4
+	`, since, filter.City, filter.TypeBusiness, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate segment freshness: %w", err)
+	}
+	defer rows.Close()
+
+	segments := make([]entity.SegmentFreshness, 0)
+	for rows.Next() {
+		var (
+			segment     entity.SegmentFreshness
+			lastRunID   *uuid.UUID
+			lastScraped sql.NullTime
+			avgLag      sql.NullFloat64
+		)
+		if err := rows.Scan(&segment.City, &segment.TypeBusiness, &segment.Companies, &lastRunID, &lastScraped, &segment.PendingEnrichments, &avgLag); err != nil {
+			return nil, fmt.Errorf("scan segment freshness: %w", err)
+		}
+		segment.LastScrapeRunID = lastRunID
+		if lastScraped.Valid {
+			val := lastScraped.Time
+			segment.LastScrapedAt = &val
+		}
+		if avgLag.Valid {
+			val := avgLag.Float64
+			segment.AvgEnrichmentLagSeconds = &val
+		}
+		segments = append(segments, segment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate segment freshness: %w", err)
+	}
+	return segments, nil
+}
+
+// ScrapeCoverage matches the business type, city and country case-insensitively; recommendation is
+// left to the caller.
+func (r *PGXFreshnessRepository) ScrapeCoverage(ctx context.Context, typeBusiness, city, country string) (_ *entity.ScrapePreview, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	preview := entity.ScrapePreview{TypeBusiness: typeBusiness, City: city, Country: country}
+	var lastScraped sql.NullTime
+	err = r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			(ARRAY_AGG(scrape_run_id ORDER BY scraped_at DESC NULLS LAST) FILTER (WHERE scrape_run_id IS NOT NULL))[1],
+			MAX(scraped_at)
+		FROM companies
+		WHERE LOWER(type_business) = LOWER($1)
+			AND LOWER(city) = LOWER($2)
+			AND LOWER(country) = LOWER($3)
+	`, typeBusiness, city, country).Scan(&preview.Companies, &preview.LastScrapeRunID, &lastScraped)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate scrape coverage: %w", err)
+	}
+	if lastScraped.Valid {
+		val := lastScraped.Time
+		preview.LastScrapedAt = &val
+	}
+	return &preview, nil
+}
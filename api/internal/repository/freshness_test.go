@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXFreshnessRepository_FreshnessTotals(t *testing.T) {
+	scraped := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	since := scraped.Add(-7 * 24 * time.Hour)
+	repo := &PGXFreshnessRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "j.status = 'accepted'") || args[0] != since {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*sql.NullTime) = sql.NullTime{Time: scraped, Valid: true}
+				*dest[1].(*int64) = 4
+				*dest[2].(*sql.NullTime) = sql.NullTime{}
+				*dest[3].(*sql.NullFloat64) = sql.NullFloat64{Float64: 95.5, Valid: true}
+				*dest[4].(*int64) = 2
+				return nil
+			}}
+		},
+	}}
+
+	freshness, err := repo.FreshnessTotals(context.Background(), since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if freshness.LastScrapedAt == nil || !freshness.LastScrapedAt.Equal(scraped) || freshness.OldestPendingAt != nil {
+		t.Fatalf("unexpected timestamps: %+v", freshness)
+	}
+	if freshness.PendingEnrichments != 4 || freshness.QueuedImports != 2 || freshness.AvgEnrichmentLagSeconds == nil || *freshness.AvgEnrichmentLagSeconds != 95.5 {
+		t.Fatalf("unexpected totals: %+v", freshness)
+	}
+}
+
+func TestPGXFreshnessRepository_SegmentFreshness(t *testing.T) {
+	runID := uuid.New()
+	repo := &PGXFreshnessRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "GROUP BY 1, 2") || args[1] != "Jakarta" || args[2] != "" || args[3] != 10 {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "Jakarta"
+					*dest[1].(*string) = "cafe"
+					*dest[2].(*int64) = 12
+					*dest[3].(**uuid.UUID) = &runID
+					*dest[4].(*sql.NullTime) = sql.NullTime{Time: time.Now(), Valid: true}
+					*dest[5].(*int64) = 1
+					*dest[6].(*sql.NullFloat64) = sql.NullFloat64{}
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	segments, err := repo.SegmentFreshness(context.Background(), FreshnessFilter{City: "Jakarta", Limit: 10}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) != 1 || segments[0].Companies != 12 || segments[0].LastScrapeRunID == nil || *segments[0].LastScrapeRunID != runID {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+	if segments[0].PendingEnrichments != 1 || segments[0].AvgEnrichmentLagSeconds != nil {
+		t.Fatalf("unexpected enrichment figures: %+v", segments[0])
+	}
+}
+
+func TestPGXFreshnessRepository_ScrapeCoverage(t *testing.T) {
+	scraped := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	runID := uuid.New()
+	repo := &PGXFreshnessRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "LOWER(country) = LOWER($3)") || args[0] != "cafe" || args[1] != "Bandung" || args[2] != "Indonesia" {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int64) = 12
+				*dest[1].(**uuid.UUID) = &runID
+				*dest[2].(*sql.NullTime) = sql.NullTime{Time: scraped, Valid: true}
+				return nil
+			}}
+		},
+	}}
+
+	preview, err := repo.ScrapeCoverage(context.Background(), "cafe", "Bandung", "Indonesia")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview.Companies != 12 || preview.LastScrapeRunID == nil || *preview.LastScrapeRunID != runID || preview.LastScrapedAt == nil || !preview.LastScrapedAt.Equal(scraped) || preview.City != "Bandung" {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+}
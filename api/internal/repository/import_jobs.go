@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrImportJobNotFound indicates the requested import job does not exist.
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// ImportJobsRepository persists asynchronous import jobs and their progress.
+type ImportJobsRepository interface {
+	Create(ctx context.Context, job *entity.ImportJob) error
+	Update(ctx context.Context, job *entity.ImportJob) error
+	Get(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error)
+	List(ctx context.Context, limit, offset int) ([]entity.ImportJob, error)
+	ListUnfinished(ctx context.Context) ([]entity.ImportJob, error)
+}
+
+// PGXImportJobsRepository implements ImportJobsRepository using pgx.
+type PGXImportJobsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXImportJobsRepository wires a pgx backed import jobs repository.
+func NewPGXImportJobsRepository(pool *pgxpool.Pool) *PGXImportJobsRepository {
+	return &PGXImportJobsRepository{pool: pool}
+}
+
+const importJobColumns = `
+	id, created_by, upload_id, filename, mode, mapping, storage_key, status, size_bytes, bytes_read,
+	processed_rows, inserted, updated, skipped, error_count, row_errors, error, created_at, started_at, finished_at,
+	kind, row_results
+`
+
+// Create inserts a queued job and populates its creation timestamp.
+func (r *PGXImportJobsRepository) Create(ctx context.Context, job *entity.ImportJob) error {
+	if job == nil {
+		return fmt.Errorf("import job payload is nil")
+	}
+	mapping := job.Mapping
+	if mapping == nil {
+		mapping = map[string]string{}
+	}
+	mappingJSON, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("encode import mapping: %w", err)
+	}
+	kind := job.Kind
+	if kind == "" {
+		kind = entity.ImportJobKindCompanies
+	}
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO import_jobs (id, created_by, upload_id, filename, mode, mapping, storage_key, status, size_bytes, kind)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`, job.ID, job.CreatedBy, job.UploadID, job.Filename, job.Mode, mappingJSON, job.StorageKey,
+		job.Status, job.SizeBytes, kind).Scan(&job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert import job: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status and progress of a job.
+func (r *PGXImportJobsRepository) Update(ctx context.Context, job *entity.ImportJob) error {
+	rowErrors := job.RowErrors
+	if rowErrors == nil {
+		rowErrors = []entity.ImportRowError{}
+	}
+	rowErrorsJSON, err := json.Marshal(rowErrors)
+	if err != nil {
+		return fmt.Errorf("encode import row errors: %w", err)
+	}
+	results := job.Results
+	if results == nil {
+		results = []entity.ImportRowResult{}
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("encode import row results: %w", err)
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE import_jobs
+		SET status = $2, bytes_read = $3, processed_rows = $4, inserted = $5, updated = $6, skipped = $7,
+			error_count = $8, row_errors = $9, error = $10, started_at = $11, finished_at = $12, row_results = $13
+		WHERE id = $1
+	`, job.ID, job.Status, job.BytesRead, job.ProcessedRows, job.Inserted, job.Updated, job.Skipped,
+		job.ErrorCount, rowErrorsJSON, job.Error, job.StartedAt, job.FinishedAt, resultsJSON)
+	if err != nil {
+		return fmt.Errorf("update import job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrImportJobNotFound
+	}
+	return nil
+}
+
+// Get returns a single import job.
+func (r *PGXImportJobsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error) {
+	job, err := scanImportJob(r.pool.QueryRow(ctx, `SELECT `+importJobColumns+` FROM import_jobs WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns import jobs, newest first.
+func (r *PGXImportJobsRepository) List(ctx context.Context, limit, offset int) ([]entity.ImportJob, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		ORDER BY created_at DESC, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list import jobs: %w", err)
+	}
+	return collectImportJobs(rows)
+}
+
+// ListUnfinished returns queued and running jobs, oldest first.
+func (r *PGXImportJobsRepository) ListUnfinished(ctx context.Context) ([]entity.ImportJob, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		WHERE status IN ($1, $2)
+		ORDER BY created_at, id
+	`, entity.ImportJobStatusQueued, entity.ImportJobStatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished import jobs: %w", err)
+	}
+	return collectImportJobs(rows)
+}
+
+func collectImportJobs(rows pgx.Rows) ([]entity.ImportJob, error) {
+	defer rows.Close()
+	jobs := make([]entity.ImportJob, 0)
+	for rows.Next() {
+		job, err := scanImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate import jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func scanImportJob(row pgx.Row) (*entity.ImportJob, error) {
+	var (
+		job           entity.ImportJob
+		mappingJSON   []byte
+		rowErrorsJSON []byte
+		resultsJSON   []byte
+	)
+	err := row.Scan(
+		&job.ID,
+		&job.CreatedBy,
+		&job.UploadID,
+		&job.Filename,
+		&job.Mode,
+		&mappingJSON,
+		&job.StorageKey,
+		&job.Status,
+		&job.SizeBytes,
+		&job.BytesRead,
+		&job.ProcessedRows,
+		&job.Inserted,
+		&job.Updated,
+		&job.Skipped,
+		&job.ErrorCount,
+		&rowErrorsJSON,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.Kind,
+		&resultsJSON,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan import job: %w", err)
+	}
+	if len(mappingJSON) > 0 {
+		if err := json.Unmarshal(mappingJSON, &job.Mapping); err != nil {
+			return nil, fmt.Errorf("decode import mapping: %w", err)
+		}
+	}
+	job.RowErrors = []entity.ImportRowError{}
+	if len(rowErrorsJSON) > 0 {
+		if err := json.Unmarshal(rowErrorsJSON, &job.RowErrors); err != nil {
+			return nil, fmt.Errorf("decode import row errors: %w", err)
+		}
+	}
+	if len(resultsJSON) > 0 {
+		if err := json.Unmarshal(resultsJSON, &job.Results); err != nil {
+			return nil, fmt.Errorf("decode import row results: %w", err)
+		}
+	}
+	return &job, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXImportJobsRepository_GetDecodesJSON(t *testing.T) {
+	id := uuid.New()
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = id
+				*dest[5].(*[]byte) = []byte(`{"name":"company"}`)
+				*dest[7].(*string) = entity.ImportJobStatusCompleted
+				*dest[15].(*[]byte) = []byte(`[{"row":3,"message":"invalid rating value on row 3"}]`)
+				return nil
+			}}
+		},
+	}
+	repo := &PGXImportJobsRepository{pool: pool}
+
+	job, err := repo.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Mapping["name"] != "company" {
+		t.Fatalf("unexpected mapping: %+v", job.Mapping)
+	}
+	if len(job.RowErrors) != 1 || job.RowErrors[0].Row != 3 {
+		t.Fatalf("unexpected row errors: %+v", job.RowErrors)
+	}
+}
+
+func TestPGXImportJobsRepository_NotFound(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		},
+	}
+	repo := &PGXImportJobsRepository{pool: pool}
+
+	if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, ErrImportJobNotFound) {
+		t.Fatalf("expected ErrImportJobNotFound on get, got %v", err)
+	}
+	if err := repo.Update(context.Background(), &entity.ImportJob{ID: uuid.New()}); !errors.Is(err, ErrImportJobNotFound) {
+		t.Fatalf("expected ErrImportJobNotFound on update, got %v", err)
+	}
+}
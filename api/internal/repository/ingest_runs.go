@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Ingest run repository errors.
+var (
+	ErrIngestRunNotFound  = errors.New("ingest run not found")
+	ErrIngestRunDuplicate = errors.New("ingest run already exists")
+	ErrIngestRunFinished  = errors.New("ingest run already finished")
+	// ErrIngestBatchConflict is returned when a sequence is sent again with different content.
+	ErrIngestBatchConflict = errors.New("ingest batch conflicts with the batch already received")
+	// ErrIngestRunOverflow is returned when a run holds batches beyond the total it is finished with.
+	ErrIngestRunOverflow = errors.New("ingest run has batches beyond total_batches")
+)
+
+// maxMissingSequences caps how many missing sequences Finish reports.
+const maxMissingSequences = 100
+
+// IngestRunsRepository persists chunked ingest runs and applies their batches.
+type IngestRunsRepository interface {
+	Create(ctx context.Context, run *entity.IngestRun) error
+	Get(ctx context.Context, id uuid.UUID) (*entity.IngestRun, error)
+	// AppendBatch upserts the companies of a batch unless its sequence was applied before, in which
+	// case batch.Duplicate is set when the checksum matches and ErrIngestBatchConflict returned otherwise.
+	AppendBatch(ctx context.Context, runID uuid.UUID, batch *entity.IngestBatch, companies []entity.Company) error
+	// Finish closes the run when batches 0 to totalBatches-1 were all received. Otherwise the run
+	// stays open and the first missing sequences are returned.
+	Finish(ctx context.Context, id uuid.UUID, totalBatches int) (missing []int, err error)
+}
+
+// PGXIngestRunsRepository implements IngestRunsRepository using pgx.
+type PGXIngestRunsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXIngestRunsRepository wires a pgx backed ingest runs repository.
+func NewPGXIngestRunsRepository(pool *pgxpool.Pool) *PGXIngestRunsRepository {
+	return &PGXIngestRunsRepository{pool: pool}
+}
+
+// ingestRunColumns selects a run from ingest_runs r; next_sequence is the first gap in its batches.
+const ingestRunColumns = `
+	r.id, r.source, r.query, r.status, r.batches, r.items, r.total_batches, r.started_at, r.finished_at, r.updated_at,
+	CASE
+		WHEN NOT EXISTS (SELECT 1 FROM ingest_run_batches WHERE run_id = r.id AND sequence = 0) THEN 0
+		ELSE (
+			SELECT MIN(b.sequence) + 1 FROM ingest_run_batches b
+			WHERE b.run_id = r.id AND NOT EXISTS (
+				SELECT 1 FROM ingest_run_batches n WHERE n.run_id = b.run_id AND n.sequence = b.sequence + 1
+			)
+		)
+	END`
+
+// Create inserts an open run, returning ErrIngestRunDuplicate when the id is taken.
+func (r *PGXIngestRunsRepository) Create(ctx context.Context, run *entity.IngestRun) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO ingest_runs (id, source, query)
+		VALUES ($1, $2, $3)
+		RETURNING status, started_at, updated_at
+	`, run.ID, run.Source, run.Query).Scan(&run.Status, &run.StartedAt, &run.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrIngestRunDuplicate
+		}
+		return fmt.Errorf("create ingest run: %w", err)
+	}
+	return nil
+}
+
+// Get returns a run by id.
+func (r *PGXIngestRunsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.IngestRun, error) {
+	var run entity.IngestRun
+	err := r.pool.QueryRow(ctx, `SELECT `+ingestRunColumns+` FROM ingest_runs r WHERE r.id = $1`, id).Scan(
+		&run.ID, &run.Source, &run.Query, &run.Status, &run.Batches, &run.Items, &run.TotalBatches,
+		&run.StartedAt, &run.FinishedAt, &run.UpdatedAt, &run.NextSequence,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIngestRunNotFound
+		}
+		return nil, fmt.Errorf("get ingest run: %w", err)
+	}
+	return &run, nil
+}
+
+// AppendBatch applies a batch in one transaction holding the run row, so a batch is either fully
+// applied and recorded or not at all, and concurrent retries of one sequence apply it once. Every
+// company is stamped with the run as scrape run and the run's start as scraped_at.
+func (r *PGXIngestRunsRepository) AppendBatch(ctx context.Context, runID uuid.UUID, batch *entity.IngestBatch, companies []entity.Company) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("start ingest batch tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		status    string
+		startedAt time.Time
+	)
+	if err := tx.QueryRow(ctx, `SELECT status, started_at FROM ingest_runs WHERE id = $1 FOR UPDATE`, runID).Scan(&status, &startedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrIngestRunNotFound
+		}
+		return fmt.Errorf("lock ingest run: %w", err)
+	}
+
+	// Replays are acknowledged even after the run finished: the worker may have missed the answer.
+	var checksum string
+	err = tx.QueryRow(ctx, `SELECT checksum FROM ingest_run_batches WHERE run_id = $1 AND sequence = $2`, runID, batch.Sequence).Scan(&checksum)
+	switch {
+	case err == nil:
+		if checksum != batch.Checksum {
+			return ErrIngestBatchConflict
+		}
+		batch.Duplicate = true
+		return nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("check ingest batch: %w", err)
+	}
+	if status != entity.IngestRunOpen {
+		return ErrIngestRunFinished
+	}
+
+	for i := range companies {
+		company := companies[i]
+		company.ScrapeRunID = &runID
+		company.ScrapedAt = &startedAt
+		if _, err := tx.Exec(ctx, upsertCompanySQL, upsertCompanyArgs(&company)...); err != nil {
+			return fmt.Errorf("upsert ingest batch company: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ingest_run_batches (run_id, sequence, checksum, items)
+		VALUES ($1, $2, $3, $4)
+	`, runID, batch.Sequence, batch.Checksum, batch.Items); err != nil {
+		return fmt.Errorf("record ingest batch: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE ingest_runs SET batches = batches + 1, items = items + $2, updated_at = NOW() WHERE id = $1
+	`, runID, batch.Items); err != nil {
+		return fmt.Errorf("count ingest batch: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit ingest batch: %w", err)
+	}
+	return nil
+}
+
+// Finish closes a run. Finishing a finished run again with the same total is a no-op.
+func (r *PGXIngestRunsRepository) Finish(ctx context.Context, id uuid.UUID, totalBatches int) ([]int, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("start ingest finish tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		status  string
+		batches int
+		total   *int
+	)
+	if err := tx.QueryRow(ctx, `SELECT status, batches, total_batches FROM ingest_runs WHERE id = $1 FOR UPDATE`, id).Scan(&status, &batches, &total); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIngestRunNotFound
+		}
+		return nil, fmt.Errorf("lock ingest run: %w", err)
+	}
+	if status != entity.IngestRunOpen {
+		if total != nil && *total == totalBatches {
+			return nil, nil
+		}
+		return nil, ErrIngestRunFinished
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT s FROM generate_series(0, $2::int - 1) AS s
+		WHERE NOT EXISTS (SELECT 1 FROM ingest_run_batches WHERE run_id = $1 AND sequence = s)
+		ORDER BY s
+		LIMIT $3
+	`, id, totalBatches, maxMissingSequences)
+	if err != nil {
+		return nil, fmt.Errorf("find missing ingest batches: %w", err)
+	}
+	missing := make([]int, 0)
+	for rows.Next() {
+		var sequence int
+		if err := rows.Scan(&sequence); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan missing ingest batch: %w", err)
+		}
+		missing = append(missing, sequence)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate missing ingest batches: %w", err)
+	}
+	if len(missing) > 0 {
+		return missing, nil
+	}
+	// With no gap below totalBatches, any further batch lies beyond it.
+	if batches > totalBatches {
+		return nil, ErrIngestRunOverflow
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE ingest_runs
+		SET status = $2, total_batches = $3, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, entity.IngestRunFinished, totalBatches); err != nil {
+		return nil, fmt.Errorf("finish ingest run: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit ingest finish: %w", err)
+	}
+	return nil, nil
+}
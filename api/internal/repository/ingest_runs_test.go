@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ingestTx answers the run lock with status and the stored batch lookup with checksum, if any.
+func ingestTx(status, checksum string, execs *[]string) *stubTx {
+	return &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if strings.Contains(query, "FOR UPDATE") {
+				return &stubRow{scan: func(dest ...any) error {
+					*dest[0].(*string) = status
+					*dest[1].(*time.Time) = time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+					return nil
+				}}
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				if checksum == "" {
+					return pgx.ErrNoRows
+				}
+				*dest[0].(*string) = checksum
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			*execs = append(*execs, query)
+			return pgconn.NewCommandTag("INSERT 0 1"), nil
+		},
+	}
+}
+
+func TestPGXIngestRunsRepository_AppendBatch(t *testing.T) {
+	runID := uuid.New()
+	placeID := "place-1"
+	companies := []entity.Company{{PlaceID: &placeID, Company: "Kopi Kenangan"}}
+
+	var execs []string
+	tx := ingestTx(entity.IngestRunOpen, "", &execs)
+	repo := &PGXIngestRunsRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+	batch := &entity.IngestBatch{Sequence: 0, Checksum: "abc", Items: 1}
+	if err := repo.AppendBatch(context.Background(), runID, batch, companies); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batch.Duplicate || !tx.committed || len(execs) != 3 || !strings.Contains(execs[0], "INSERT INTO companies") {
+		t.Fatalf("expected the company upsert, batch record and counters to commit, got %v", execs)
+	}
+	if companies[0].ScrapeRunID != nil {
+		t.Fatal("expected the caller's companies to be left untouched")
+	}
+
+	execs = nil
+	tx = ingestTx(entity.IngestRunFinished, "abc", &execs)
+	batch = &entity.IngestBatch{Sequence: 0, Checksum: "abc", Items: 1}
+	if err := repo.AppendBatch(context.Background(), runID, batch, companies); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !batch.Duplicate || len(execs) != 0 || tx.committed {
+		t.Fatalf("expected a replay to be acknowledged without writes, got %+v %v", batch, execs)
+	}
+
+	batch = &entity.IngestBatch{Sequence: 0, Checksum: "def", Items: 1}
+	if err := repo.AppendBatch(context.Background(), runID, batch, companies); !errors.Is(err, ErrIngestBatchConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+
+	tx = ingestTx(entity.IngestRunFinished, "", &execs)
+	if err := repo.AppendBatch(context.Background(), runID, &entity.IngestBatch{Sequence: 1, Checksum: "abc"}, companies); !errors.Is(err, ErrIngestRunFinished) {
+		t.Fatalf("expected a finished run to refuse new batches, got %v", err)
+	}
+}
+
+func TestPGXIngestRunsRepository_FinishReportsMissingBatches(t *testing.T) {
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*string) = entity.IngestRunOpen
+				*dest[1].(*int) = 2
+				return nil
+			}}
+		},
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "generate_series") || args[1] != 4 {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error { *dest[0].(*int) = 1; return nil },
+				func(dest ...any) error { *dest[0].(*int) = 3; return nil },
+			}}, nil
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			t.Fatalf("expected an incomplete run to stay open, got %q", query)
+			return pgconn.CommandTag{}, nil
+		},
+	}
+	repo := &PGXIngestRunsRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+
+	missing, err := repo.Finish(context.Background(), uuid.New(), 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(missing) != 2 || missing[0] != 1 || missing[1] != 3 || tx.committed {
+		t.Fatalf("unexpected missing sequences %v (committed %v)", missing, tx.committed)
+	}
+}
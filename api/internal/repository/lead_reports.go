@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// LeadReportsRepository loads what lead report sheets show of companies.
+type LeadReportsRepository interface {
+	// LeadReports returns the reports of the companies in ids that exist, in the order of ids.
+	LeadReports(ctx context.Context, ids []uuid.UUID) ([]entity.LeadReport, error)
+}
+
+// LeadReports reads companies with their enrichment and lead score in one query; enrichment
+// emails and phone numbers are decrypted like GetEnrichment does.
+func (r *PGXCompaniesRepository) LeadReports(ctx context.Context, ids []uuid.UUID) (_ []entity.LeadReport, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.reader().Query(ctx, `
+		WITH base AS (
+			SELECT `+companyColumns("NULL::jsonb AS raw")+`
+			FROM companies
+			WHERE id = ANY($1)
+		)
+		SELECT base.*, e.emails, e.phones, e.socials, e.contact_form_url, e.about_summary, e.updated_at,
+			s.score, s.breakdown, s.computed_at
+		FROM base
+		LEFT JOIN company_enrichments e ON e.company_id = base.id
+		LEFT JOIN company_lead_scores s ON s.company_id = base.id
+		ORDER BY array_position($1::uuid[], base.id)
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("load lead reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]entity.LeadReport, 0, len(ids))
+	for rows.Next() {
+		var (
+			report      entity.LeadReport
+			emails      []string
+			phones      []string
+			socials     []byte
+			contactForm sql.NullString
+			about       sql.NullString
+			enrichedAt  sql.NullTime
+			score       sql.NullInt32
+			breakdown   []byte
+			scoredAt    sql.NullTime
+		)
+		report.Company, err = scanCompany(rows, &emails, &phones, &socials, &contactForm, &about, &enrichedAt, &score, &breakdown, &scoredAt)
+		if err != nil {
+			return nil, err
+		}
+		if enrichedAt.Valid {
+			enrichment := entity.CompanyEnrichment{CompanyID: report.Company.ID, Emails: emails, Phones: phones}
+			if err := decryptContacts(r.cipher, &enrichment); err != nil {
+				return nil, err
+			}
+			report.Emails, report.Phones = enrichment.Emails, enrichment.Phones
+			if len(socials) > 0 {
+				if err := json.Unmarshal(socials, &report.Socials); err != nil {
+					return nil, fmt.Errorf("unmarshal socials: %w", err)
+				}
+			}
+			report.ContactFormURL = nullStringToPtr(contactForm)
+			report.AboutSummary = nullStringToPtr(about)
+			report.EnrichedAt = &enrichedAt.Time
+		}
+		if score.Valid {
+			value := int(score.Int32)
+			report.Score = &value
+			report.ScoredAt = &scoredAt.Time
+			if len(breakdown) > 0 {
+				if err := json.Unmarshal(breakdown, &report.ScoreBreakdown); err != nil {
+					return nil, fmt.Errorf("unmarshal score breakdown: %w", err)
+				}
+			}
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate lead reports: %w", err)
+	}
+	return reports, nil
+}
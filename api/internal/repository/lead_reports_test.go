@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXCompaniesRepository_LeadReports(t *testing.T) {
+	scored, bare := uuid.New(), uuid.New()
+	computed := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "LEFT JOIN company_lead_scores s") || !strings.Contains(query, "ORDER BY array_position($1::uuid[], base.id)") {
+				t.Fatalf("expected companies joined with their scores in the order asked, got %q", query)
+			}
+			if ids := args[0].([]uuid.UUID); len(ids) != 2 || ids[0] != scored {
+				t.Fatalf("unexpected args: %v", args)
+			}
+			// The enrichment and score columns follow the company columns.
+			row := func(id uuid.UUID, name string, extra func(tail []any)) func(dest ...any) error {
+				return func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = id
+					*dest[3].(*string) = name
+					extra(dest[len(dest)-9:])
+					return nil
+				}
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				row(scored, "Acme", func(tail []any) {
+					*tail[0].(*[]string) = []string{"sales@acme.test"}
+					*tail[2].(*[]byte) = []byte(`{"instagram":["https://instagram.com/acme"]}`)
+					*tail[5].(*sql.NullTime) = sql.NullTime{Time: computed, Valid: true}
+					*tail[6].(*sql.NullInt32) = sql.NullInt32{Int32: 72, Valid: true}
+					*tail[7].(*[]byte) = []byte(`{"email":30,"rating":42}`)
+					*tail[8].(*sql.NullTime) = sql.NullTime{Time: computed, Valid: true}
+				}),
+				row(bare, "Beta", func(tail []any) {}),
+			}}, nil
+		},
+	}}
+
+	reports, err := repo.LeadReports(context.Background(), []uuid.UUID{scored, bare})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Company.Company != "Acme" || reports[1].Company.ID != bare {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	acme := reports[0]
+	if *acme.Score != 72 || acme.ScoreBreakdown["rating"] != 42 || acme.Emails[0] != "sales@acme.test" || len(acme.Socials["instagram"]) != 1 {
+		t.Fatalf("unexpected enrichment and score: %+v", acme)
+	}
+	if beta := reports[1]; beta.Score != nil || beta.EnrichedAt != nil || beta.Emails != nil {
+		t.Fatalf("expected an unenriched company without contacts or score, got %+v", beta)
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import "github.com/octobees/leads-generator/api/internal/dto"
+
+// WithLeadsView sends company listings, counts and exports to leads_view, the denormalized copy of
+// the listing that triggers keep current, instead of joining the live tables per query.
+func WithLeadsView() RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.leadsView = true
+	}
+}
+
+// leadColumns lists the columns scanCompanies expects, in order, as leads_view stores them.
+const leadColumns = `
+        id,
+        place_id,
+        scrape_run_id,
+        company,
+        phone,
+        website,
+        rating,
+        reviews,
+        type_business,
+        address,
+        city,
+        country,
+        longitude,
+        latitude,
+        NULL::jsonb AS raw,
+        scraped_at,
+        created_at,
+        updated_at,
+        chain_id,
+        size_bucket,
+        business_status,
+        status_changed_at,
+        preferred_outreach_language,
+        outreach_language_source,
+        legal_form,
+        display_name,
+        shared_contact,
+        assigned_to,
+        website_status,
+        website_redirects_to,
+        website_checked_at,
+        logo_url,
+        photo_url,
+        photo_reference,
+        opening_hours,
+        utc_offset_minutes,
+        price_level,
+        categories
+    `
+
+// leadSummaryColumns are the stored counterparts of enrichmentSummaryColumns.
+const leadSummaryColumns = `email_count AS summary_email_count,
+	phone_count AS summary_phone_count,
+	best_social AS summary_best_social,
+	score AS summary_score`
+
+// listSource is where a listing, export or count reads companies from.
+type listSource struct {
+	columns        string
+	summaryColumns string
+	from           string
+	summaryJoin    string
+}
+
+// listSource picks the source of a listing. leads_view is read under the name of the live table,
+// so the listing conditions and their correlated subqueries apply unchanged; listings asking for
+// raw payloads, which only the live table keeps, read the live table.
+func (r *PGXCompaniesRepository) listSource(filter dto.ListFilter, rawColumn string) listSource {
+	if r.leadsView && !filter.IncludeRaw {
+		return listSource{columns: leadColumns, summaryColumns: leadSummaryColumns, from: " FROM leads_view companies"}
+	}
+	return listSource{
+		columns:        companyColumns(rawColumn),
+		summaryColumns: enrichmentSummaryColumns,
+		from:           " FROM companies",
+		summaryJoin:    enrichmentSummaryJoin,
+	}
+}
+
+// selectClause renders the SELECT and FROM clauses, with the enrichment summary when asked for.
+func (s listSource) selectClause(summary bool) string {
+	if summary {
+		return "SELECT " + s.columns + ", " + s.summaryColumns + s.from + s.summaryJoin
+	}
+	return "SELECT " + s.columns + s.from
+}
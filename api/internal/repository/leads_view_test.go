@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompaniesRepository_LeadsView(t *testing.T) {
+	var queries []string
+	repo := &PGXCompaniesRepository{leadsView: true, pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			queries = append(queries, query)
+			return &stubCompanyRows{}, nil
+		},
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			queries = append(queries, query)
+			return &stubRow{scan: func(dest ...any) error { return nil }}
+		},
+	}}
+	ctx := context.Background()
+
+	if _, err := repo.List(ctx, dto.ListFilter{IncludeEnrichmentSummary: true, City: "Jakarta"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(queries[0], "FROM leads_view companies WHERE") || !strings.Contains(queries[0], "score AS summary_score") ||
+		strings.Contains(queries[0], "LATERAL") || strings.Contains(queries[0], "company_assignments") {
+		t.Fatalf("expected the listing read from leads_view without joins, got %q", queries[0])
+	}
+	if _, err := repo.List(ctx, dto.ListFilter{IncludeRaw: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(queries[1], "FROM companies") || strings.Contains(queries[1], "leads_view") {
+		t.Fatalf("expected raw payloads read from the live table, got %q", queries[1])
+	}
+
+	if _, err := repo.CountCompanies(ctx, dto.ListFilter{City: "Jakarta"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.ExportCompanies(ctx, dto.ListFilter{City: "Jakarta"}, 10, func(entity.Company) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, query := range queries[2:] {
+		if !strings.Contains(query, "FROM leads_view companies WHERE") {
+			t.Fatalf("expected counts and exports read from leads_view, got %q", query)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrDisplayNameTaken is returned when another company without a place id already has the display
+// name at the same address.
+var ErrDisplayNameTaken = errors.New("display name already used at this address")
+
+// LegalFormRepository reads company names and stores the legal form extracted from them.
+type LegalFormRepository interface {
+	ListCompanyNamesAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyName, error)
+	UpdateLegalForm(ctx context.Context, companyID uuid.UUID, legalForm *string, displayName string) error
+}
+
+// PGXLegalFormRepository implements LegalFormRepository using pgx.
+type PGXLegalFormRepository struct {
+	pool pgxPool
+}
+
+// NewPGXLegalFormRepository wires a pgx backed legal form repository.
+func NewPGXLegalFormRepository(pool *pgxpool.Pool) *PGXLegalFormRepository {
+	return &PGXLegalFormRepository{pool: pool}
+}
+
+// ListCompanyNamesAfter pages through company names ordered by company id.
+func (r *PGXLegalFormRepository) ListCompanyNamesAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyName, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, company FROM companies WHERE id > $1 ORDER BY id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list company names: %w", err)
+	}
+	defer rows.Close()
+
+	var names []entity.CompanyName
+	for rows.Next() {
+		var name entity.CompanyName
+		if err := rows.Scan(&name.CompanyID, &name.Company); err != nil {
+			return nil, fmt.Errorf("scan company name: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate company names: %w", err)
+	}
+	return names, nil
+}
+
+// UpdateLegalForm stores the legal form and display name of a company. It returns
+// ErrDisplayNameTaken when that would make it a duplicate of another company.
+func (r *PGXLegalFormRepository) UpdateLegalForm(ctx context.Context, companyID uuid.UUID, legalForm *string, displayName string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE companies SET legal_form = $2, display_name = $3 WHERE id = $1
+	`, companyID, legalForm, displayName)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "unique_company_display_name_address" {
+			return ErrDisplayNameTaken
+		}
+		return fmt.Errorf("update legal form: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+func TestPGXLegalFormRepository_UpdateLegalForm(t *testing.T) {
+	var err error
+	tag := pgconn.NewCommandTag("UPDATE 1")
+	repo := &PGXLegalFormRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return tag, err
+		},
+	}}
+	form := "PT"
+
+	if got := repo.UpdateLegalForm(context.Background(), uuid.New(), &form, "Maju Jaya"); got != nil {
+		t.Fatalf("unexpected error: %v", got)
+	}
+	err = &pgconn.PgError{Code: "23505", ConstraintName: "unique_company_display_name_address"}
+	if got := repo.UpdateLegalForm(context.Background(), uuid.New(), &form, "Maju Jaya"); !errors.Is(got, ErrDisplayNameTaken) {
+		t.Fatalf("expected ErrDisplayNameTaken, got %v", got)
+	}
+	err, tag = nil, pgconn.NewCommandTag("UPDATE 0")
+	if got := repo.UpdateLegalForm(context.Background(), uuid.New(), nil, "Kopi"); !errors.Is(got, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", got)
+	}
+}
+
+func TestPGXCompaniesRepository_BulkUpsertMatchesDisplayName(t *testing.T) {
+	var args [][]any
+	tx := &stubTx{queryFunc: func(ctx context.Context, query string, a ...any) (pgx.Rows, error) {
+		if !strings.Contains(query, "ON CONFLICT (display_name, address) WHERE place_id IS NULL") {
+			t.Fatalf("expected rows to be matched on their display name, got %q", query)
+		}
+		args = append(args, a)
+		return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+			*dest[1].(*bool) = true
+			return nil
+		}}}, nil
+	}}
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+	form := "PT"
+
+	records := []BulkUpsertCompanyInput{
+		{Company: "PT Maju Jaya", DisplayName: "Maju Jaya", LegalForm: &form, Address: "Main St"},
+		{Company: "Kopi Kenangan", Address: "Side St"},
+	}
+	if _, err := repo.BulkUpsertCompanies(context.Background(), records, ImportModeOverwrite); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args[0][10] != "Maju Jaya" || args[0][11] != "PT" {
+		t.Fatalf("unexpected args %v", args[0])
+	}
+	if args[1][10] != "Kopi Kenangan" || args[1][11] != nil {
+		t.Fatalf("expected the full name without a display name, got %v", args[1])
+	}
+
+	overwrite, _ := bulkUpsertSQL(ImportModeOverwrite)
+	if !strings.Contains(overwrite, "legal_form = COALESCE(EXCLUDED.legal_form, companies.legal_form)") {
+		t.Fatalf("expected overwrite to keep a known legal form, got %q", overwrite)
+	}
+}
+
+func TestPGXCompaniesRepository_ListLegalFormFilter(t *testing.T) {
+	var queries []string
+	var args [][]any
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, a ...any) (pgx.Rows, error) {
+			queries, args = append(queries, query), append(args, a)
+			return &stubRows{}, nil
+		},
+	}}
+
+	for _, forms := range [][]string{{"PT", "CV"}, {"PT", "none"}} {
+		if _, err := repo.List(context.Background(), dto.ListFilter{LegalForms: forms}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !strings.Contains(queries[0], "WHERE legal_form = ANY($1)") || strings.Contains(queries[0], "IS NULL") {
+		t.Fatalf("unexpected query %q", queries[0])
+	}
+	if !strings.Contains(queries[1], "(legal_form = ANY($1) OR legal_form IS NULL)") {
+		t.Fatalf("expected none to match names without a form, got %q", queries[1])
+	}
+	if forms, ok := args[0][0].([]string); !ok || len(forms) != 2 {
+		t.Fatalf("unexpected args %v", args[0])
+	}
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Location repository errors.
+var (
+	ErrLocationNotFound  = errors.New("location not found")
+	ErrLocationDuplicate = errors.New("location already exists")
+)
+
+// LocationSearch filters the locations offered for autocomplete. Query matches the start of a city
+// name or of one of its aliases; Country, when set, keeps the locations of that country.
+type LocationSearch struct {
+	Query   string
+	Country string
+	Limit   int
+}
+
+// LocationsRepository persists the location catalog.
+type LocationsRepository interface {
+	List(ctx context.Context) ([]entity.Location, error)
+	Search(ctx context.Context, filter LocationSearch) ([]entity.Location, error)
+	Create(ctx context.Context, location *entity.Location) error
+	Update(ctx context.Context, location *entity.Location) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PGXLocationsRepository implements LocationsRepository using pgx.
+type PGXLocationsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXLocationsRepository wires a pgx backed locations repository.
+func NewPGXLocationsRepository(pool *pgxpool.Pool) *PGXLocationsRepository {
+	return &PGXLocationsRepository{pool: pool}
+}
+
+const locationColumns = `id, city, region, country, aliases, latitude, longitude, south, west, north, east, created_at, updated_at`
+
+// List returns every location ordered by country and city.
+func (r *PGXLocationsRepository) List(ctx context.Context) ([]entity.Location, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+locationColumns+` FROM locations ORDER BY country, city`)
+	if err != nil {
+		return nil, fmt.Errorf("list locations: %w", err)
+	}
+	return scanLocations(rows)
+}
+
+// Search returns up to filter.Limit locations matching the filter, ordered by city.
+func (r *PGXLocationsRepository) Search(ctx context.Context, filter LocationSearch) ([]entity.Location, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+locationColumns+`
+		FROM locations
+		WHERE ($1 = '' OR lower(city) LIKE $1 || '%' ESCAPE '\'
+				OR EXISTS (SELECT 1 FROM unnest(aliases) AS alias WHERE alias LIKE $1 || '%' ESCAPE '\'))
+			AND ($2 = '' OR lower(country) = lower($2))
+		ORDER BY city, country
+		LIMIT $3
+	`, escapeLike(filter.Query), filter.Country, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("search locations: %w", err)
+	}
+	return scanLocations(rows)
+}
+
+// Create inserts a location and populates its identifier and timestamps.
+func (r *PGXLocationsRepository) Create(ctx context.Context, location *entity.Location) error {
+	if location == nil {
+		return fmt.Errorf("location payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO locations (city, region, country, aliases, latitude, longitude, south, west, north, east)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`, locationArgs(location)...).Scan(&location.ID, &location.CreatedAt, &location.UpdatedAt)
+	if err != nil {
+		if isLocationDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrLocationDuplicate, err)
+		}
+		return fmt.Errorf("insert location: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites a location by id and refreshes its timestamps.
+func (r *PGXLocationsRepository) Update(ctx context.Context, location *entity.Location) error {
+	if location == nil {
+		return fmt.Errorf("location payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		UPDATE locations
+		SET city = $1, region = $2, country = $3, aliases = $4, latitude = $5, longitude = $6,
+			south = $7, west = $8, north = $9, east = $10, updated_at = NOW()
+		WHERE id = $11
+		RETURNING created_at, updated_at
+	`, append(locationArgs(location), location.ID)...).Scan(&location.CreatedAt, &location.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLocationNotFound
+		}
+		if isLocationDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrLocationDuplicate, err)
+		}
+		return fmt.Errorf("update location: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a location by id.
+func (r *PGXLocationsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM locations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete location: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLocationNotFound
+	}
+	return nil
+}
+
+// locationArgs binds the stored fields of a location as $1 to $10.
+func locationArgs(location *entity.Location) []any {
+	aliases := location.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	var south, west, north, east *float64
+	if box := location.BoundingBox; box != nil {
+		south, west, north, east = &box.South, &box.West, &box.North, &box.East
+	}
+	return []any{location.City, location.Region, location.Country, aliases, location.Latitude, location.Longitude, south, west, north, east}
+}
+
+func scanLocations(rows pgx.Rows) ([]entity.Location, error) {
+	defer rows.Close()
+
+	locations := make([]entity.Location, 0)
+	for rows.Next() {
+		var (
+			location                 entity.Location
+			south, west, north, east *float64
+		)
+		if err := rows.Scan(
+			&location.ID, &location.City, &location.Region, &location.Country, &location.Aliases,
+			&location.Latitude, &location.Longitude, &south, &west, &north, &east,
+			&location.CreatedAt, &location.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan location: %w", err)
+		}
+		if south != nil && west != nil && north != nil && east != nil {
+			location.BoundingBox = &entity.BoundingBox{South: *south, West: *west, North: *north, East: *east}
+		}
+		locations = append(locations, location)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate locations: %w", err)
+	}
+	return locations, nil
+}
+
+func isLocationDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_locations_city_country"
+}
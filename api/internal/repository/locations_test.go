@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXLocationsRepository_Search(t *testing.T) {
+	var gotQuery string
+	var gotArgs []any
+	pool := &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			gotQuery, gotArgs = query, args
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[1].(*string) = "Yogyakarta"
+					*dest[3].(*string) = "Indonesia"
+					*dest[4].(*[]string) = []string{"jogja"}
+					for i, value := range []float64{-7.84, 110.34, -7.76, 110.40} {
+						v := value
+						*dest[7+i].(**float64) = &v
+					}
+					return nil
+				},
+				func(dest ...any) error {
+					*dest[1].(*string) = "Yogya Baru"
+					*dest[3].(*string) = "Indonesia"
+					return nil
+				},
+			}}, nil
+		},
+	}
+	repo := &PGXLocationsRepository{pool: pool}
+
+	locations, err := repo.Search(context.Background(), LocationSearch{Query: "yog_", Country: "Indonesia", Limit: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(gotQuery, "unnest(aliases)") || gotArgs[0] != `yog\_` || gotArgs[1] != "Indonesia" || gotArgs[2] != 5 {
+		t.Fatalf("unexpected search query %q with args %v", gotQuery, gotArgs)
+	}
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 locations, got %d", len(locations))
+	}
+	want := entity.BoundingBox{South: -7.84, West: 110.34, North: -7.76, East: 110.40}
+	if box := locations[0].BoundingBox; box == nil || *box != want {
+		t.Fatalf("expected bounding box %+v, got %+v", want, box)
+	}
+	if locations[1].BoundingBox != nil {
+		t.Fatalf("expected no bounding box for an ungeocoded location, got %+v", locations[1].BoundingBox)
+	}
+}
+
+func TestPGXLocationsRepository_CreateDuplicate(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23505", ConstraintName: "idx_locations_city_country"}
+			}}
+		},
+	}
+	repo := &PGXLocationsRepository{pool: pool}
+
+	err := repo.Create(context.Background(), &entity.Location{City: "Bandung", Country: "Indonesia"})
+	if !errors.Is(err, ErrLocationDuplicate) {
+		t.Fatalf("expected ErrLocationDuplicate, got %v", err)
+	}
+}
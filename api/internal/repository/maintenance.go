@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// MaintenanceRepository groups operational queries used by the admin CLI.
+type MaintenanceRepository interface {
+	ReindexCompanies(ctx context.Context) error
+	RebuildLeadsView(ctx context.Context) (int64, error)
+	CountScrapeRunCompanies(ctx context.Context, runID uuid.UUID) (int64, error)
+	PurgeScrapeRun(ctx context.Context, runID uuid.UUID) (int64, error)
+	ListEnrichmentsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyEnrichment, error)
+	// ListStaleEnrichmentsAfter pages through enrichments without a score computed by the given
+	// scoring profile revision; a nil profile stands for the built-in weights at revision 0.
+	ListStaleEnrichmentsAfter(ctx context.Context, after uuid.UUID, limit int, profileID *uuid.UUID, revision int) ([]entity.CompanyEnrichment, error)
+	// CountEnrichmentsAfter counts what the list methods would page through after the given id.
+	CountEnrichmentsAfter(ctx context.Context, after uuid.UUID, staleOnly bool, profileID *uuid.UUID, revision int) (int, error)
+	UpsertLeadScore(ctx context.Context, companyID uuid.UUID, score int, breakdown map[string]int, profileID *uuid.UUID, revision int) error
+}
+
+// PGXMaintenanceRepository implements MaintenanceRepository using pgx.
+type PGXMaintenanceRepository struct {
+	pool   pgxPool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPGXMaintenanceRepository wires a pgx backed maintenance repository; WithEnrichmentCipher
+// decrypts the enrichments it lists.
+func NewPGXMaintenanceRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXMaintenanceRepository {
+	return &PGXMaintenanceRepository{pool: pool, cipher: newRepositoryOptions(opts).cipher}
+}
+
+// ReindexCompanies rebuilds the indexes backing company search and refreshes planner statistics.
+// REINDEX CONCURRENTLY cannot run inside a transaction, so each statement is issued separately.
+func (r *PGXMaintenanceRepository) ReindexCompanies(ctx context.Context) error {
+	if _, err := r.pool.Exec(ctx, `REINDEX TABLE CONCURRENTLY companies`); err != nil {
+		return fmt.Errorf("reindex companies: %w", err)
+	}
+	if _, err := r.pool.Exec(ctx, `ANALYZE companies`); err != nil {
+		return fmt.Errorf("analyze companies: %w", err)
+	}
+	return nil
+}
+
+// RebuildLeadsView recomputes every row of leads_view from the live tables and returns how many
+// there are. Readers keep seeing the previous rows until it commits, and a row a trigger rewrites
+// meanwhile is kept.
+func (r *PGXMaintenanceRepository) RebuildLeadsView(ctx context.Context) (int64, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("begin rebuild leads view: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM leads_view`); err != nil {
+		return 0, fmt.Errorf("clear leads view: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `INSERT INTO leads_view SELECT * FROM leads_view_source ON CONFLICT (id) DO NOTHING`)
+	if err != nil {
+		return 0, fmt.Errorf("fill leads view: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit rebuild leads view: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountScrapeRunCompanies reports how many companies belong to a scrape run.
+func (r *PGXMaintenanceRepository) CountScrapeRunCompanies(ctx context.Context, runID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM companies WHERE scrape_run_id = $1`, runID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count scrape run companies: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeScrapeRun deletes every company of a scrape run; enrichments cascade with them.
+func (r *PGXMaintenanceRepository) PurgeScrapeRun(ctx context.Context, runID uuid.UUID) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM companies WHERE scrape_run_id = $1`, runID)
+	if err != nil {
+		return 0, fmt.Errorf("purge scrape run: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListEnrichmentsAfter pages through company enrichments ordered by company id.
+func (r *PGXMaintenanceRepository) ListEnrichmentsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyEnrichment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			company_id,
+			emails,
+			phones,
+			socials,
+			address,
+			contact_form_url,
+			about_summary,
+			metadata,
+			created_at,
+			updated_at
+		FROM company_enrichments
+		WHERE company_id > $1
+		ORDER BY company_id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list enrichments: %w", err)
+	}
+	defer rows.Close()
+
+	var records []entity.CompanyEnrichment
+	for rows.Next() {
+		record, err := scanEnrichment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan enrichment: %w", err)
+		}
+		if err := decryptContacts(r.cipher, record); err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate enrichments: %w", err)
+	}
+	return records, nil
+}
+
+// ListStaleEnrichmentsAfter pages through enrichments whose score is missing or was computed with
+// another scoring profile or an older revision of it, ordered by company id.
+func (r *PGXMaintenanceRepository) ListStaleEnrichmentsAfter(ctx context.Context, after uuid.UUID, limit int, profileID *uuid.UUID, revision int) ([]entity.CompanyEnrichment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			e.company_id,
+			e.emails,
+			e.phones,
+			e.socials,
+			e.address,
+			e.contact_form_url,
+			e.about_summary,
+			e.metadata,
+			e.created_at,
+			e.updated_at
+		FROM company_enrichments e
+		LEFT JOIN company_lead_scores s ON s.company_id = e.company_id
+		WHERE e.company_id > $1
+			AND (s.company_id IS NULL OR s.profile_id IS DISTINCT FROM $3 OR s.profile_revision <> $4)
+		ORDER BY e.company_id
+		LIMIT $2
+	`, after, limit, profileID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("list stale enrichments: %w", err)
+	}
+	defer rows.Close()
+
+	var records []entity.CompanyEnrichment
+	for rows.Next() {
+		record, err := scanEnrichment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan enrichment: %w", err)
+		}
+		if err := decryptContacts(r.cipher, record); err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate enrichments: %w", err)
+	}
+	return records, nil
+}
+
+// CountEnrichmentsAfter counts the enrichments after the given company id; with staleOnly only those
+// ListStaleEnrichmentsAfter would return.
+func (r *PGXMaintenanceRepository) CountEnrichmentsAfter(ctx context.Context, after uuid.UUID, staleOnly bool, profileID *uuid.UUID, revision int) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM company_enrichments e
+		LEFT JOIN company_lead_scores s ON s.company_id = e.company_id
+		WHERE e.company_id > $1
+			AND (NOT $2 OR s.company_id IS NULL OR s.profile_id IS DISTINCT FROM $3 OR s.profile_revision <> $4)
+	`, after, staleOnly, profileID, revision).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count enrichments: %w", err)
+	}
+	return count, nil
+}
+
+// UpsertLeadScore stores the latest computed lead score for a company with the scoring profile
+// revision it was computed with.
+func (r *PGXMaintenanceRepository) UpsertLeadScore(ctx context.Context, companyID uuid.UUID, score int, breakdown map[string]int, profileID *uuid.UUID, revision int) error {
+	if breakdown == nil {
+		breakdown = map[string]int{}
+	}
+	breakdownJSON, err := json.Marshal(breakdown)
+	if err != nil {
+		return fmt.Errorf("marshal score breakdown: %w", err)
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO company_lead_scores (company_id, score, breakdown, profile_id, profile_revision, computed_at)
+		VALUES ($1, $2, $3::jsonb, $4, $5, NOW())
+		ON CONFLICT (company_id) DO UPDATE SET
+			score = EXCLUDED.score,
+			breakdown = EXCLUDED.breakdown,
+			profile_id = EXCLUDED.profile_id,
+			profile_revision = EXCLUDED.profile_revision,
+			computed_at = NOW()
+	`, companyID, score, string(breakdownJSON), profileID, revision)
+	if err != nil {
+		return fmt.Errorf("upsert lead score: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// hasPhoneSQL matches companies with a non-blank phone number.
+const hasPhoneSQL = "(phone IS NOT NULL AND BTRIM(phone) <> '')"
+
+// hasSocialsSQL matches companies for which enrichment found at least one social profile.
+const hasSocialsSQL = `(
+	EXISTS (SELECT 1 FROM company_enrichments ce WHERE ce.company_id = companies.id AND ce.socials <> '{}'::jsonb)
+	OR EXISTS (
+		SELECT 1 FROM website_enriched_contacts wc
+		WHERE wc.company_id = companies.id
+			AND COALESCE(wc.linkedin_url, wc.facebook_url, wc.instagram_url, wc.youtube_url, wc.tiktok_url, wc.twitter_url, wc.whatsapp_url) IS NOT NULL
+	)
+)`
+
+// opportunityScoreSQL rates a lead from 0 to 100: up to 40 points for review volume (log scaled,
+// full at 500 reviews), 30 for the rating, 15 for a phone number and 15 for a social profile. Busy,
+// well rated businesses that can be reached are the best prospects for a new website.
+const opportunityScoreSQL = `ROUND((
+	40 * LEAST(LN(1 + GREATEST(COALESCE(reviews, 0), 0)) / LN(501), 1)
+	+ 30 * GREATEST(COALESCE(rating, 0)::float8 - 1, 0) / 4
+	+ CASE WHEN ` + hasPhoneSQL + ` THEN 15 ELSE 0 END
+	+ CASE WHEN ` + hasSocialsSQL + ` THEN 15 ELSE 0 END
+)::numeric, 1)::float8`
+
+// noWebsiteLeadsOrder ranks leads by opportunity score, breaking ties by demand.
+const noWebsiteLeadsOrder = "opportunity_score DESC, reviews DESC NULLS LAST, company ASC, id"
+
+// NoWebsiteLeadsRepository ranks the companies of a listing filter by opportunity score. Callers set
+// filter.WebsiteStatus to "missing"; exports count their rows through CompanyExportRepository.
+type NoWebsiteLeadsRepository interface {
+	ListNoWebsiteLeads(ctx context.Context, filter dto.ListFilter) ([]entity.NoWebsiteLead, error)
+	ExportNoWebsiteLeads(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.NoWebsiteLead) error) error
+}
+
+func noWebsiteLeadsSelect(rawColumn string) string {
+	return "SELECT " + companyColumns(rawColumn) + ", " + hasSocialsSQL + " AS has_socials, " +
+		opportunityScoreSQL + " AS opportunity_score FROM companies"
+}
+
+// ListNoWebsiteLeads returns a page of leads matching filter, best opportunity first.
+func (r *PGXCompaniesRepository) ListNoWebsiteLeads(ctx context.Context, filter dto.ListFilter) (_ []entity.NoWebsiteLead, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	limit, limitArgs := listLimit(where.filter, where.next)
+	query := noWebsiteLeadsSelect("NULL::jsonb AS raw") + where.where() + " ORDER BY " + noWebsiteLeadsOrder + limit
+
+	leads := make([]entity.NoWebsiteLead, 0)
+	err = r.queryNoWebsiteLeads(ctx, query, append(where.args, limitArgs...), func(lead entity.NoWebsiteLead) error {
+		leads = append(leads, lead)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leads, nil
+}
+
+// ExportNoWebsiteLeads calls fn for at most limit leads matching filter, best opportunity first. A
+// limit of zero exports every match.
+func (r *PGXCompaniesRepository) ExportNoWebsiteLeads(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.NoWebsiteLead) error) error {
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return err
+	}
+	query := noWebsiteLeadsSelect("NULL::jsonb AS raw") + where.where() + " ORDER BY " + noWebsiteLeadsOrder
+	args := where.args
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", where.next)
+		args = append(args, limit)
+	}
+	return r.queryNoWebsiteLeads(ctx, query, args, fn)
+}
+
+func (r *PGXCompaniesRepository) queryNoWebsiteLeads(ctx context.Context, query string, args []any, fn func(entity.NoWebsiteLead) error) error {
+	rows, err := r.reader().Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("list no-website leads: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lead entity.NoWebsiteLead
+		company, err := scanCompany(rows, &lead.HasSocials, &lead.OpportunityScore)
+		if err != nil {
+			return err
+		}
+		lead.Company = company
+		if err := fn(lead); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate no-website leads: %w", err)
+	}
+	return nil
+}
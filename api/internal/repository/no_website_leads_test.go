@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// stubLeadRows returns the stubCompanyRows company followed by has_socials and opportunity_score.
+type stubLeadRows struct {
+	stubCompanyRows
+}
+
+func (s *stubLeadRows) Scan(dest ...any) error {
+	if err := s.stubCompanyRows.Scan(dest[:len(dest)-2]...); err != nil {
+		return err
+	}
+	*dest[len(dest)-2].(*bool) = true
+	*dest[len(dest)-1].(*float64) = 72.5
+	return nil
+}
+
+func TestPGXCompaniesRepository_ListNoWebsiteLeads(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, a ...any) (pgx.Rows, error) {
+			query, args = q, a
+			return &stubLeadRows{}, nil
+		},
+	}}
+	minRating, maxRating, minReviews, hasPhone, hasSocials := 3.5, 4.5, 20, true, false
+	filter := dto.ListFilter{
+		City:          "Jakarta",
+		WebsiteStatus: "missing",
+		MinRating:     &minRating,
+		MaxRating:     &maxRating,
+		MinReviews:    &minReviews,
+		HasPhone:      &hasPhone,
+		HasSocials:    &hasSocials,
+		Page:          2,
+		PerPage:       10,
+	}
+
+	leads, err := repo.ListNoWebsiteLeads(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(leads) != 1 || leads[0].Company.Company != "Acme" || !leads[0].HasSocials || leads[0].OpportunityScore != 72.5 {
+		t.Fatalf("unexpected leads: %+v", leads)
+	}
+	for _, fragment := range []string{
+		"website IS NULL",
+		"rating >= $2",
+		"rating <= $3",
+		"reviews >= $4",
+		"AND " + hasPhoneSQL,
+		"AND NOT " + hasSocialsSQL,
+		"AS opportunity_score",
+		"ORDER BY " + noWebsiteLeadsOrder + " LIMIT $5 OFFSET $6",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in query %q", fragment, query)
+		}
+	}
+	if len(args) != 6 || args[4] != 10 || args[5] != 10 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestPGXCompaniesRepository_ExportNoWebsiteLeads(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, a ...any) (pgx.Rows, error) {
+			query, args = q, a
+			return &stubLeadRows{}, nil
+		},
+	}}
+
+	var exported []entity.NoWebsiteLead
+	err := repo.ExportNoWebsiteLeads(context.Background(), dto.ListFilter{WebsiteStatus: "missing"}, 25, func(lead entity.NoWebsiteLead) error {
+		exported = append(exported, lead)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exported) != 1 || exported[0].OpportunityScore != 72.5 {
+		t.Fatalf("unexpected exported leads: %+v", exported)
+	}
+	if !strings.HasSuffix(query, noWebsiteLeadsOrder+" LIMIT $1") || len(args) != 1 || args[0] != int64(25) {
+		t.Fatalf("unexpected export query %q %v", query, args)
+	}
+}
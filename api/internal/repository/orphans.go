@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// OrphansRepository finds the rows left pointing at companies that no longer exist. The foreign keys
+// cascade deletes, so these only appear when rows bypass them, e.g. a restore with triggers off.
+type OrphansRepository interface {
+	// ReconcileOrphans moves orphaned rows to the company scraped again under the same place id,
+	// when it has no row of its own, and archives the rest under runID. A dry run rolls the changes
+	// back, so the report only says what a run would do.
+	ReconcileOrphans(ctx context.Context, runID uuid.UUID, dryRun bool) ([]entity.OrphanTableReport, error)
+}
+
+// ErrReconcileInProgress is returned while another reconciliation holds the lock.
+var ErrReconcileInProgress = errors.New("orphan reconciliation already in progress")
+
+// orphanTable is a table reconciled by ReconcileOrphans; keys are the columns that, with
+// company_id, identify one of its rows.
+type orphanTable struct {
+	name string
+	keys []string
+}
+
+var orphanTables = []orphanTable{
+	{name: "company_enrichments"},
+	{name: "website_enriched_contacts"},
+	{name: "company_lead_scores"},
+	{name: "company_snapshots", keys: []string{"scrape_run_id"}},
+}
+
+// relinkSQL moves the orphaned rows of a company deleted and scraped again under a new id. Every
+// target takes the row of the latest deleted company only, and none that has a row of its own.
+func (t orphanTable) relinkSQL() string {
+	var selected, conflict, match strings.Builder
+	for _, key := range t.keys {
+		fmt.Fprintf(&selected, ", t.%s", key)
+		fmt.Fprintf(&conflict, " AND x.%[1]s = t.%[1]s", key)
+		fmt.Fprintf(&match, " AND t.%[1]s = moves.%[1]s", key)
+	}
+	return fmt.Sprintf(`
+		WITH moves AS (
+			SELECT DISTINCT ON (c.id%[2]s) t.company_id AS old_id, c.id AS new_id%[2]s
+			FROM %[1]s t
+			JOIN company_tombstones ts ON ts.company_id = t.company_id
+			JOIN companies c ON c.place_id = ts.place_id
+			WHERE NOT EXISTS (SELECT 1 FROM companies live WHERE live.id = t.company_id)
+				AND NOT EXISTS (SELECT 1 FROM %[1]s x WHERE x.company_id = c.id%[3]s)
+			ORDER BY c.id%[2]s, ts.deleted_at DESC
+		)
+		UPDATE %[1]s t SET company_id = moves.new_id
+		FROM moves
+		WHERE t.company_id = moves.old_id%[4]s
+	`, t.name, selected.String(), conflict.String(), match.String())
+}
+
+// archiveSQL copies the orphaned rows left after relinking to orphan_archive and deletes them.
+func (t orphanTable) archiveSQL() string {
+	return fmt.Sprintf(`
+		WITH archived AS (
+			DELETE FROM %[1]s t
+			WHERE NOT EXISTS (SELECT 1 FROM companies c WHERE c.id = t.company_id)
+			RETURNING t.company_id, to_jsonb(t) AS data
+		)
+		INSERT INTO orphan_archive (run_id, source_table, company_id, data, reason)
+		SELECT $1, $2, a.company_id, a.data,
+			CASE
+				WHEN EXISTS (
+					SELECT 1 FROM company_tombstones ts JOIN companies c ON c.place_id = ts.place_id
+					WHERE ts.company_id = a.company_id
+				) THEN 'superseded'
+				WHEN EXISTS (SELECT 1 FROM company_tombstones ts WHERE ts.company_id = a.company_id) THEN 'deleted'
+				ELSE 'missing'
+			END
+		FROM archived a
+	`, t.name)
+}
+
+// PGXOrphansRepository implements OrphansRepository using pgx.
+type PGXOrphansRepository struct {
+	pool pgxPool
+}
+
+// NewPGXOrphansRepository wires a pgx backed orphans repository.
+func NewPGXOrphansRepository(pool *pgxpool.Pool) *PGXOrphansRepository {
+	return &PGXOrphansRepository{pool: pool}
+}
+
+// ReconcileOrphans relinks or archives the orphaned rows of every reconciled table in one
+// transaction, serialised with a transaction-scoped advisory lock so API instances running the
+// job together do not both report the same rows.
+func (r *PGXOrphansRepository) ReconcileOrphans(ctx context.Context, runID uuid.UUID, dryRun bool) ([]entity.OrphanTableReport, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin reconcile orphans: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('reconcile_orphans'))`).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("lock reconcile orphans: %w", err)
+	}
+	if !locked {
+		return nil, ErrReconcileInProgress
+	}
+
+	reports := make([]entity.OrphanTableReport, 0, len(orphanTables))
+	for _, table := range orphanTables {
+		relinked, err := tx.Exec(ctx, table.relinkSQL())
+		if err != nil {
+			return nil, fmt.Errorf("relink orphaned %s: %w", table.name, err)
+		}
+		archived, err := tx.Exec(ctx, table.archiveSQL(), runID, table.name)
+		if err != nil {
+			return nil, fmt.Errorf("archive orphaned %s: %w", table.name, err)
+		}
+		reports = append(reports, entity.OrphanTableReport{
+			Table:    table.name,
+			Orphans:  relinked.RowsAffected() + archived.RowsAffected(),
+			Relinked: relinked.RowsAffected(),
+			Archived: archived.RowsAffected(),
+		})
+	}
+	if dryRun {
+		return reports, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit reconcile orphans: %w", err)
+	}
+	return reports, nil
+}
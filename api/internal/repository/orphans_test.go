@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestPGXOrphansRepository_ReconcileOrphans(t *testing.T) {
+	locked := true
+	var statements []string
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*bool) = locked
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			statements = append(statements, query)
+			if strings.Contains(query, "INSERT INTO orphan_archive") {
+				return pgconn.NewCommandTag("INSERT 0 2"), nil
+			}
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}
+	repo := &PGXOrphansRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+
+	reports, err := repo.ReconcileOrphans(context.Background(), uuid.New(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.committed {
+		t.Fatalf("expected a dry run rolled back")
+	}
+	if len(reports) != len(orphanTables) || reports[0].Table != "company_enrichments" || reports[0].Orphans != 3 || reports[0].Relinked != 1 || reports[0].Archived != 2 {
+		t.Fatalf("unexpected reports %+v", reports)
+	}
+	snapshots := statements[len(statements)-2]
+	if !strings.Contains(snapshots, "DISTINCT ON (c.id, t.scrape_run_id)") || !strings.Contains(snapshots, "AND t.scrape_run_id = moves.scrape_run_id") {
+		t.Fatalf("expected snapshots relinked per scrape run, got %s", snapshots)
+	}
+
+	if _, err := repo.ReconcileOrphans(context.Background(), uuid.New(), false); err != nil || !tx.committed {
+		t.Fatalf("expected the run committed, got %v", err)
+	}
+
+	locked = false
+	if _, err := repo.ReconcileOrphans(context.Background(), uuid.New(), false); !errors.Is(err, ErrReconcileInProgress) {
+		t.Fatalf("expected ErrReconcileInProgress, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// OutreachLanguageRepository reads language signals and stores the detected outreach language.
+type OutreachLanguageRepository interface {
+	GetLanguageSignals(ctx context.Context, companyID uuid.UUID) (*entity.OutreachLanguageSignals, error)
+	ListLanguageSignalsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.OutreachLanguageSignals, error)
+	UpdateOutreachLanguage(ctx context.Context, companyID uuid.UUID, language, source string) error
+}
+
+// PGXOutreachLanguageRepository implements OutreachLanguageRepository using pgx.
+type PGXOutreachLanguageRepository struct {
+	pool pgxPool
+}
+
+// NewPGXOutreachLanguageRepository wires a pgx backed outreach language repository.
+func NewPGXOutreachLanguageRepository(pool *pgxpool.Pool) *PGXOutreachLanguageRepository {
+	return &PGXOutreachLanguageRepository{pool: pool}
+}
+
+// languageSignalsSelect pulls the website lang attribute and about text from enrichment and the
+// location from the company.
+const languageSignalsSelect = `
+	SELECT
+		c.id,
+		e.metadata->>'website_language' AS website_language,
+		e.about_summary,
+		c.city,
+		c.country,
+		c.preferred_outreach_language
+	FROM companies c
+	LEFT JOIN company_enrichments e ON e.company_id = c.id
+`
+
+// GetLanguageSignals returns the language signals of a single company.
+func (r *PGXOutreachLanguageRepository) GetLanguageSignals(ctx context.Context, companyID uuid.UUID) (*entity.OutreachLanguageSignals, error) {
+	signals, err := scanLanguageSignals(r.pool.QueryRow(ctx, languageSignalsSelect+` WHERE c.id = $1`, companyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, fmt.Errorf("fetch language signals: %w", err)
+	}
+	return signals, nil
+}
+
+// ListLanguageSignalsAfter pages through company language signals ordered by company id.
+func (r *PGXOutreachLanguageRepository) ListLanguageSignalsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.OutreachLanguageSignals, error) {
+	rows, err := r.pool.Query(ctx, languageSignalsSelect+` WHERE c.id > $1 ORDER BY c.id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list language signals: %w", err)
+	}
+	defer rows.Close()
+
+	var records []entity.OutreachLanguageSignals
+	for rows.Next() {
+		signals, err := scanLanguageSignals(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan language signals: %w", err)
+		}
+		records = append(records, *signals)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate language signals: %w", err)
+	}
+	return records, nil
+}
+
+// UpdateOutreachLanguage stores the detected outreach language of a company and the signal it
+// came from; an empty language clears both.
+func (r *PGXOutreachLanguageRepository) UpdateOutreachLanguage(ctx context.Context, companyID uuid.UUID, language, source string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE companies
+		SET preferred_outreach_language = NULLIF($2, ''), outreach_language_source = NULLIF($3, '')
+		WHERE id = $1
+	`, companyID, language, source)
+	if err != nil {
+		return fmt.Errorf("update outreach language: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
+
+func scanLanguageSignals(row pgx.Row) (*entity.OutreachLanguageSignals, error) {
+	var (
+		signals         entity.OutreachLanguageSignals
+		websiteLanguage sql.NullString
+		aboutSummary    sql.NullString
+		city            sql.NullString
+		country         sql.NullString
+		language        sql.NullString
+	)
+	if err := row.Scan(&signals.CompanyID, &websiteLanguage, &aboutSummary, &city, &country, &language); err != nil {
+		return nil, err
+	}
+	signals.WebsiteLanguage = nullStringToPtr(websiteLanguage)
+	signals.AboutSummary = nullStringToPtr(aboutSummary)
+	signals.City = nullStringToPtr(city)
+	signals.Country = nullStringToPtr(country)
+	signals.Language = nullStringToPtr(language)
+	return &signals, nil
+}
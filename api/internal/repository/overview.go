@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// OverviewRepository reads the job, import and database figures of the admin overview.
+type OverviewRepository interface {
+	JobCounts(ctx context.Context, since time.Time) ([]entity.JobStatusCounts, error)
+	LastCityScrapes(ctx context.Context, limit int) ([]entity.CityScrape, error)
+	ImportFailures(ctx context.Context, since time.Time, limit int) (*entity.ImportFailures, error)
+	DatabaseSize(ctx context.Context, tables int) (*entity.DatabaseSize, error)
+}
+
+// PGXOverviewRepository implements OverviewRepository using pgx. It reads the primary, so the
+// figures are current even while a replica lags.
+type PGXOverviewRepository struct {
+	pool   ReadPool
+	limits queryLimits
+}
+
+// NewPGXOverviewRepository wires a pgx backed overview repository.
+func NewPGXOverviewRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXOverviewRepository {
+	return &PGXOverviewRepository{pool: pool, limits: newQueryLimits(opts)}
+}
+
+// jobStatusCounts counts the jobs of each kind created since $1. A scrape job is running while its
+// ingest run is open and finished once the run is; an enrichment job the worker accepted is queued
+// until the company's enrichment is written after it.
+const jobStatusCounts = `
+	SELECT 'scrape',
+		COUNT(*) FILTER (WHERE j.status <> 'failed' AND run.id IS NULL),
+		COUNT(*) FILTER (WHERE j.status <> 'failed' AND run.status = 'open'),
+		COUNT(*) FILTER (WHERE j.status = 'failed'),
+		COUNT(*) FILTER (WHERE j.status <> 'failed' AND run.status = 'finished')
+	FROM scrape_jobs j
+	LEFT JOIN ingest_runs run ON run.id = j.id
+	WHERE j.created_at >= $1
+	UNION ALL
+	SELECT 'enrichment', COUNT(*) FILTER (WHERE pending), 0,
+		(SELECT COUNT(*) FROM enrichment_jobs WHERE status = 'failed' AND created_at >= $1),
+		COUNT(*) FILTER (WHERE NOT pending)
+	FROM (` + enrichmentJobProgress + `) jobs
+	UNION ALL
+	SELECT 'import',
+		COUNT(*) FILTER (WHERE status = 'queued'), COUNT(*) FILTER (WHERE status = 'running'),
+		COUNT(*) FILTER (WHERE status = 'failed'), COUNT(*) FILTER (WHERE status = 'completed')
+	FROM import_jobs WHERE created_at >= $1
+	UNION ALL
+	SELECT 'score_recompute',
+		COUNT(*) FILTER (WHERE status = 'queued'), COUNT(*) FILTER (WHERE status = 'running'),
+		COUNT(*) FILTER (WHERE status = 'failed'), COUNT(*) FILTER (WHERE status = 'completed')
+	FROM score_recompute_jobs WHERE created_at >= $1
+	UNION ALL
+	SELECT 'raw_reprocess',
+		COUNT(*) FILTER (WHERE status = 'queued'), COUNT(*) FILTER (WHERE status = 'running'),
+		COUNT(*) FILTER (WHERE status = 'failed'), COUNT(*) FILTER (WHERE status = 'completed')
+	FROM raw_reprocess_jobs WHERE created_at >= $1
+`
+
+// JobCounts returns the status counts of every job kind created since since.
+func (r *PGXOverviewRepository) JobCounts(ctx context.Context, since time.Time) (_ []entity.JobStatusCounts, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.pool.Query(ctx, jobStatusCounts, since)
+	if err != nil {
+		return nil, fmt.Errorf("count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]entity.JobStatusCounts, 0, 5)
+	for rows.Next() {
+		var c entity.JobStatusCounts
+		if err := rows.Scan(&c.Kind, &c.Queued, &c.Running, &c.Failed, &c.Finished); err != nil {
+			return nil, fmt.Errorf("scan job counts: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate job counts: %w", err)
+	}
+	return counts, nil
+}
+
+// LastCityScrapes returns the latest finished scrape of each city, most recent first.
+func (r *PGXOverviewRepository) LastCityScrapes(ctx context.Context, limit int) (_ []entity.CityScrape, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT city, country, type_business, id, items, finished_at
+		FROM (
+			SELECT DISTINCT ON (lower(j.city), lower(j.country))
+				j.city, j.country, j.type_business, j.id, run.items, run.finished_at
+			FROM scrape_jobs j
+			JOIN ingest_runs run ON run.id = j.id
+			WHERE run.status = 'finished' AND j.status <> 'failed'
+			ORDER BY lower(j.city), lower(j.country), run.finished_at DESC
+		) latest
+		ORDER BY finished_at DESC, city
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list last city scrapes: %w", err)
+	}
+	defer rows.Close()
+
+	scrapes := make([]entity.CityScrape, 0)
+	for rows.Next() {
+		var s entity.CityScrape
+		if err := rows.Scan(&s.City, &s.Country, &s.TypeBusiness, &s.JobID, &s.Items, &s.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan city scrape: %w", err)
+		}
+		scrapes = append(scrapes, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate city scrapes: %w", err)
+	}
+	return scrapes, nil
+}
+
+// ImportFailures counts the imports that failed since since and lists the latest limit of them.
+func (r *PGXOverviewRepository) ImportFailures(ctx context.Context, since time.Time, limit int) (_ *entity.ImportFailures, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	failures := entity.ImportFailures{Recent: make([]entity.ImportFailure, 0)}
+	err = r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM import_jobs WHERE status = 'failed' AND created_at >= $1`, since).
+		Scan(&failures.Total)
+	if err != nil {
+		return nil, fmt.Errorf("count import failures: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, filename, error, error_count, created_at, finished_at
+		FROM import_jobs
+		WHERE status = 'failed' AND created_at >= $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list import failures: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			failure  entity.ImportFailure
+			finished sql.NullTime
+		)
+		if err := rows.Scan(&failure.ID, &failure.Filename, &failure.Error, &failure.ErrorCount, &failure.CreatedAt, &finished); err != nil {
+			return nil, fmt.Errorf("scan import failure: %w", err)
+		}
+		if finished.Valid {
+			val := finished.Time
+			failure.FinishedAt = &val
+		}
+		failures.Recent = append(failures.Recent, failure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate import failures: %w", err)
+	}
+	return &failures, nil
+}
+
+// DatabaseSize returns the size of the database and of its largest tables, indexes and TOAST
+// included. Row counts are the planner's estimates, so reading them scans nothing.
+func (r *PGXOverviewRepository) DatabaseSize(ctx context.Context, tables int) (_ *entity.DatabaseSize, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	size := entity.DatabaseSize{Tables: make([]entity.TableSize, 0, tables)}
+	if err := r.pool.QueryRow(ctx, `SELECT pg_database_size(current_database())`).Scan(&size.Bytes); err != nil {
+		return nil, fmt.Errorf("read database size: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT relname, pg_total_relation_size(relid), n_live_tup
+		FROM pg_stat_user_tables
+		ORDER BY pg_total_relation_size(relid) DESC, relname
+		LIMIT $1
+	`, tables)
+	if err != nil {
+		return nil, fmt.Errorf("list table sizes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table entity.TableSize
+		if err := rows.Scan(&table.Table, &table.Bytes, &table.EstimatedRows); err != nil {
+			return nil, fmt.Errorf("scan table size: %w", err)
+		}
+		size.Tables = append(size.Tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate table sizes: %w", err)
+	}
+	return &size, nil
+}
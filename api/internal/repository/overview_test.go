@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXOverviewRepository_JobCounts(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &PGXOverviewRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "LEFT JOIN ingest_runs run") || !strings.Contains(query, "FROM raw_reprocess_jobs") || args[0] != since {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "scrape"
+					*dest[1].(*int64) = 2
+					*dest[2].(*int64) = 1
+					*dest[3].(*int64) = 3
+					*dest[4].(*int64) = 8
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	counts, err := repo.JobCounts(context.Background(), since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts) != 1 || counts[0].Kind != "scrape" || counts[0].Queued != 2 || counts[0].Running != 1 || counts[0].Failed != 3 || counts[0].Finished != 8 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+}
+
+func TestPGXOverviewRepository_ImportFailures(t *testing.T) {
+	id := uuid.New()
+	finished := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &PGXOverviewRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int64) = 4
+				return nil
+			}}
+		},
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if args[1] != 5 {
+				t.Fatalf("expected the limit, got %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					message := "bad header"
+					*dest[0].(*uuid.UUID) = id
+					*dest[1].(*string) = "leads.csv"
+					*dest[2].(**string) = &message
+					*dest[3].(*int) = 2
+					*dest[5].(*sql.NullTime) = sql.NullTime{Time: finished, Valid: true}
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	failures, err := repo.ImportFailures(context.Background(), time.Now(), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures.Total != 4 || len(failures.Recent) != 1 {
+		t.Fatalf("unexpected failures: %+v", failures)
+	}
+	recent := failures.Recent[0]
+	if recent.ID != id || *recent.Error != "bad header" || recent.FinishedAt == nil || !recent.FinishedAt.Equal(finished) {
+		t.Fatalf("unexpected failure: %+v", recent)
+	}
+}
+
+func TestPGXOverviewRepository_DatabaseSize(t *testing.T) {
+	repo := &PGXOverviewRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int64) = 1 << 30
+				return nil
+			}}
+		},
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "pg_stat_user_tables") || args[0] != 3 {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "companies"
+					*dest[1].(*int64) = 1 << 29
+					*dest[2].(*int64) = 120000
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	size, err := repo.DatabaseSize(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size.Bytes != 1<<30 || len(size.Tables) != 1 || size.Tables[0].Table != "companies" || size.Tables[0].EstimatedRows != 120000 {
+		t.Fatalf("unexpected size: %+v", size)
+	}
+}
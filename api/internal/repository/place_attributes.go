@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// PlaceAttributesRepository reads raw place payloads and stores the opening hours, price level
+// and categories extracted from them. PGXCompaniesRepository implements it, so the payloads can be
+// narrowed with the listing filters.
+type PlaceAttributesRepository interface {
+	CountPlacePayloadsAfter(ctx context.Context, filter dto.ListFilter, after uuid.UUID) (int, error)
+	ListPlacePayloadsAfter(ctx context.Context, filter dto.ListFilter, after uuid.UUID, limit int) ([]entity.CompanyPlacePayload, error)
+	UpdatePlaceAttributes(ctx context.Context, companyID uuid.UUID, attrs entity.PlaceAttributes) error
+}
+
+// placePayloadConditions extends the listing conditions of filter to the companies after the
+// given id, ignoring pagination.
+func (r *PGXCompaniesRepository) placePayloadConditions(ctx context.Context, filter dto.ListFilter, after uuid.UUID) (listConditions, error) {
+	filter.Page, filter.PerPage, filter.Limit = 0, 0, 0
+	where, err := r.buildListConditions(ctx, filter)
+	if err != nil {
+		return listConditions{}, err
+	}
+	where.clauses = append(where.clauses, fmt.Sprintf("id > $%d", where.next))
+	where.args = append(where.args, after)
+	where.next++
+	return where, nil
+}
+
+// CountPlacePayloadsAfter counts the companies matching filter after the given id.
+func (r *PGXCompaniesRepository) CountPlacePayloadsAfter(ctx context.Context, filter dto.ListFilter, after uuid.UUID) (int, error) {
+	where, err := r.placePayloadConditions(ctx, filter, after)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM companies"+where.where(), where.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count place payloads: %w", err)
+	}
+	return count, nil
+}
+
+// ListPlacePayloadsAfter pages through the raw place payloads of the companies matching filter,
+// ordered by company id. Offloaded payloads carry their object key instead of the payload.
+func (r *PGXCompaniesRepository) ListPlacePayloadsAfter(ctx context.Context, filter dto.ListFilter, after uuid.UUID, limit int) ([]entity.CompanyPlacePayload, error) {
+	where, err := r.placePayloadConditions(ctx, filter, after)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT id, type_business, CASE WHEN raw_ref IS NULL THEN raw END, raw_ref FROM companies" +
+		where.where() + fmt.Sprintf(" ORDER BY id LIMIT $%d", where.next)
+	rows, err := r.pool.Query(ctx, query, append(where.args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("list place payloads: %w", err)
+	}
+	defer rows.Close()
+
+	var payloads []entity.CompanyPlacePayload
+	for rows.Next() {
+		var (
+			payload entity.CompanyPlacePayload
+			raw     []byte
+		)
+		if err := rows.Scan(&payload.CompanyID, &payload.TypeBusiness, &raw, &payload.RawRef); err != nil {
+			return nil, fmt.Errorf("scan place payload: %w", err)
+		}
+		payload.Raw = json.RawMessage(raw)
+		payloads = append(payloads, payload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate place payloads: %w", err)
+	}
+	return payloads, nil
+}
+
+// UpdatePlaceAttributes stores the opening hours, price level and categories of a company.
+func (r *PGXCompaniesRepository) UpdatePlaceAttributes(ctx context.Context, companyID uuid.UUID, attrs entity.PlaceAttributes) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE companies SET opening_hours = $2, utc_offset_minutes = $3, price_level = $4, categories = $5 WHERE id = $1
+	`, companyID, openingHoursArg(attrs.OpeningHours), attrs.UTCOffsetMinutes, attrs.PriceLevel, attrs.Categories)
+	if err != nil {
+		return fmt.Errorf("update place attributes: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompaniesRepository_ListPlacePayloadsAfter_Filtered(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	companyID, ref := uuid.New(), "raw/abc.json"
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, a ...any) (pgx.Rows, error) {
+			query, args = q, a
+			return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = companyID
+				*dest[3].(**string) = &ref
+				return nil
+			}}}, nil
+		},
+	}}
+	after := uuid.New()
+
+	payloads, err := repo.ListPlacePayloadsAfter(context.Background(), dto.ListFilter{TypeBusiness: "cafe", Page: 3, PerPage: 20}, after, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "LOWER(type_business) = LOWER($1) AND id > $2") || !strings.Contains(query, "ORDER BY id LIMIT $3") {
+		t.Fatalf("unexpected query %q", query)
+	}
+	if len(args) != 3 || args[1] != after || args[2] != 100 {
+		t.Fatalf("unexpected args %v", args)
+	}
+	if len(payloads) != 1 || payloads[0].CompanyID != companyID || payloads[0].RawRef == nil || *payloads[0].RawRef != ref {
+		t.Fatalf("unexpected payloads %+v", payloads)
+	}
+}
+
+func TestPGXCompaniesRepository_UpdatePlaceAttributes(t *testing.T) {
+	var args []any
+	tag := pgconn.NewCommandTag("UPDATE 1")
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, query string, a ...any) (pgconn.CommandTag, error) {
+			args = a
+			return tag, nil
+		},
+	}}
+	level := 2
+
+	attrs := entity.PlaceAttributes{OpeningHours: []entity.OpeningPeriod{{Day: 0, Open: "10:00", Close: "14:00"}}, PriceLevel: &level}
+	if err := repo.UpdatePlaceAttributes(context.Background(), uuid.New(), attrs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hours, ok := args[1].([]byte); !ok || string(hours) != `[{"day":0,"open":"10:00","close":"14:00"}]` {
+		t.Fatalf("unexpected opening hours argument %v", args[1])
+	}
+	tag = pgconn.NewCommandTag("UPDATE 0")
+	if err := repo.UpdatePlaceAttributes(context.Background(), uuid.New(), entity.PlaceAttributes{}); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+	if args[1] != nil {
+		t.Fatalf("expected NULL opening hours, got %v", args[1])
+	}
+}
+
+func TestBulkUpsertSQL_KeepsCategories(t *testing.T) {
+	overwrite, _ := bulkUpsertSQL(ImportModeOverwrite)
+	if !strings.Contains(overwrite, "categories = COALESCE(EXCLUDED.categories, companies.categories)") {
+		t.Fatalf("expected overwrite to keep known categories, got %q", overwrite)
+	}
+	fill, _ := bulkUpsertSQL(ImportModeFillMissing)
+	if !strings.Contains(fill, "categories = COALESCE(companies.categories, EXCLUDED.categories)") {
+		t.Fatalf("expected fill-missing to fill categories, got %q", fill)
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// PlaceRefreshRepository stores the results of looking companies up again on Google Maps.
+type PlaceRefreshRepository interface {
+	// GetPlaceRefreshTarget returns what a company is looked up by.
+	GetPlaceRefreshTarget(ctx context.Context, companyID uuid.UUID) (*PlaceRefreshTarget, error)
+	// RefreshPlace replaces the place id, rating and reviews of refresh.CompanyID and records the
+	// refresh as a snapshot, filling in the previous place id, the stored rating and reviews and
+	// the time.
+	RefreshPlace(ctx context.Context, refresh *entity.PlaceRefresh) error
+}
+
+// PlaceRefreshTarget is the name and address a company is looked up by.
+type PlaceRefreshTarget struct {
+	Company string
+	Address string
+	Country *string
+	PlaceID *string
+}
+
+// ErrPlaceIDTaken indicates the refreshed place id already belongs to another company.
+var ErrPlaceIDTaken = errors.New("place id belongs to another company")
+
+// GetPlaceRefreshTarget returns what a company is looked up by or ErrCompanyNotFound.
+func (r *PGXCompaniesRepository) GetPlaceRefreshTarget(ctx context.Context, companyID uuid.UUID) (*PlaceRefreshTarget, error) {
+	var target PlaceRefreshTarget
+	if err := r.pool.QueryRow(ctx, `
+		SELECT company, address, country, place_id FROM companies WHERE id = $1
+	`, companyID).Scan(&target.Company, &target.Address, &target.Country, &target.PlaceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, fmt.Errorf("get place refresh target: %w", err)
+	}
+	return &target, nil
+}
+
+// RefreshPlace updates the company and records the refresh in one transaction. Ratings and reviews
+// the lookup did not find keep their stored values. The snapshot trigger is skipped for the
+// update, so the observation of the company's scrape run stays as that run saw it.
+func (r *PGXCompaniesRepository) RefreshPlace(ctx context.Context, refresh *entity.PlaceRefresh) error {
+	if refresh == nil {
+		return fmt.Errorf("place refresh payload is nil")
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin refresh place: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, `SELECT place_id FROM companies WHERE id = $1 FOR UPDATE`, refresh.CompanyID).Scan(&refresh.PreviousPlaceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCompanyNotFound
+		}
+		return fmt.Errorf("lock company for refresh: %w", err)
+	}
+	refresh.PlaceIDChanged = refresh.PreviousPlaceID == nil || *refresh.PreviousPlaceID != refresh.PlaceID
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('leadsgen.skip_snapshot', 'on', true)`); err != nil {
+		return fmt.Errorf("skip company snapshot: %w", err)
+	}
+	if err := tx.QueryRow(ctx, `
+		UPDATE companies SET
+			place_id = $2,
+			rating = COALESCE($3, rating),
+			reviews = COALESCE($4, reviews),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING rating::float8, reviews, updated_at
+	`, refresh.CompanyID, refresh.PlaceID, refresh.Rating, refresh.Reviews).Scan(&refresh.Rating, &refresh.Reviews, &refresh.RefreshedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "companies_place_id_key" {
+			return ErrPlaceIDTaken
+		}
+		return fmt.Errorf("refresh company place: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO company_snapshots (company_id, scrape_run_id, source, rating, reviews, place_id, previous_place_id, observed_at)
+		VALUES ($1, $2, 'refresh', $3, $4, $5, $6, $7)
+	`, refresh.CompanyID, refresh.ID, refresh.Rating, refresh.Reviews, refresh.PlaceID, refresh.PreviousPlaceID, refresh.RefreshedAt); err != nil {
+		return fmt.Errorf("record place refresh: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit refresh place: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXCompaniesRepository_RefreshPlace(t *testing.T) {
+	refreshedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	var (
+		statements []string
+		snapshot   []any
+		updateErr  error
+	)
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if strings.Contains(query, "FOR UPDATE") {
+				return &stubRow{scan: func(dest ...any) error {
+					old := "ChIJold"
+					*dest[0].(**string) = &old
+					return nil
+				}}
+			}
+			statements = append(statements, query)
+			return &stubRow{scan: func(dest ...any) error {
+				if updateErr != nil {
+					return updateErr
+				}
+				rating, reviews := 4.2, 80
+				*dest[0].(**float64) = &rating
+				*dest[1].(**int) = &reviews
+				*dest[2].(*time.Time) = refreshedAt
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			statements = append(statements, query)
+			if strings.Contains(query, "INSERT INTO company_snapshots") {
+				snapshot = args
+			}
+			return pgconn.NewCommandTag("INSERT 0 1"), nil
+		},
+	}
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+
+	reviews := 95
+	refresh := &entity.PlaceRefresh{ID: uuid.New(), CompanyID: uuid.New(), PlaceID: "ChIJnew", Reviews: &reviews}
+	if err := repo.RefreshPlace(context.Background(), refresh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tx.committed || !refresh.PlaceIDChanged || *refresh.PreviousPlaceID != "ChIJold" || *refresh.Rating != 4.2 || !refresh.RefreshedAt.Equal(refreshedAt) {
+		t.Fatalf("unexpected refresh %+v", refresh)
+	}
+	if len(statements) != 3 || !strings.Contains(statements[0], "leadsgen.skip_snapshot") || !strings.Contains(statements[1], "UPDATE companies") {
+		t.Fatalf("expected the snapshot trigger skipped for the update, got %v", statements)
+	}
+	if !strings.Contains(statements[2], "'refresh'") || snapshot[1] != refresh.ID || snapshot[4] != "ChIJnew" {
+		t.Fatalf("expected the refresh recorded as a snapshot, got %v", snapshot)
+	}
+
+	tx.committed = false
+	updateErr = &pgconn.PgError{Code: "23505", ConstraintName: "companies_place_id_key"}
+	if err := repo.RefreshPlace(context.Background(), refresh); !errors.Is(err, ErrPlaceIDTaken) || tx.committed {
+		t.Fatalf("expected ErrPlaceIDTaken without a commit, got %v", err)
+	}
+}
@@ -0,0 +1,497 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// PrivacyRepository erases and exports the contact data held about people and companies and keeps
+// the audit trail of those requests.
+type PrivacyRepository interface {
+	FindCompaniesByEmail(ctx context.Context, emails []string) ([]uuid.UUID, error)
+	ExportPrivacyData(ctx context.Context, companyIDs []uuid.UUID, emails []string) (entity.PrivacyExport, error)
+	ErasePrivacyData(ctx context.Context, request *entity.PrivacyRequest, emails []string) ([]string, error)
+	RecordPrivacyRequest(ctx context.Context, request *entity.PrivacyRequest) error
+	ListPrivacyRequests(ctx context.Context, limit, offset int) ([]entity.PrivacyRequest, error)
+}
+
+// PGXPrivacyRepository implements PrivacyRepository using pgx.
+type PGXPrivacyRepository struct {
+	pool   pgxPool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPGXPrivacyRepository wires a pgx backed privacy repository; WithEnrichmentCipher lets it match
+// and export encrypted enrichment contacts.
+func NewPGXPrivacyRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXPrivacyRepository {
+	return &PGXPrivacyRepository{pool: pool, cipher: newRepositoryOptions(opts).cipher}
+}
+
+// emailMatch is a row of a contact table holding some of the requested emails. Kept is the stored
+// emails column without them, still encrypted where it was.
+type emailMatch struct {
+	key       string
+	companyID *uuid.UUID
+	kept      []string
+	removed   int
+}
+
+// matchEmails finds the rows of table holding one of emails. Encrypted rows cannot be compared in
+// SQL, so every row holding an encrypted email is decrypted and compared here; lock holds the
+// matched rows until the transaction of q ends.
+func (r *PGXPrivacyRepository) matchEmails(ctx context.Context, q ReadPool, table ContactTable, emails []string, lock bool) ([]emailMatch, error) {
+	query := `
+		SELECT ` + table.key + `::text, ` + table.company + `, emails
+		FROM ` + table.Name + `
+		WHERE EXISTS (
+			SELECT 1 FROM unnest(emails) AS e
+			WHERE lower(btrim(e)) = ANY($1) OR e LIKE $2
+		)
+		ORDER BY ` + table.key
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	rows, err := q.Query(ctx, query, emails, fieldcrypt.LikePattern)
+	if err != nil {
+		return nil, fmt.Errorf("match %s emails: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	matches := make([]emailMatch, 0)
+	for rows.Next() {
+		var (
+			match  emailMatch
+			stored []string
+		)
+		if err := rows.Scan(&match.key, &match.companyID, &stored); err != nil {
+			return nil, fmt.Errorf("scan %s emails: %w", table.Name, err)
+		}
+		match.kept = make([]string, 0, len(stored))
+		for _, value := range stored {
+			plain, err := r.cipher.Decrypt(value)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt emails of %s: %w", match.key, err)
+			}
+			if slices.Contains(emails, strings.ToLower(strings.TrimSpace(plain))) {
+				match.removed++
+				continue
+			}
+			match.kept = append(match.kept, value)
+		}
+		if match.removed > 0 {
+			matches = append(matches, match)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s emails: %w", table.Name, err)
+	}
+	return matches, nil
+}
+
+// FindCompaniesByEmail returns the companies whose enrichment, website contacts or domain cache
+// entry holds one of the lowercased emails, in id order.
+func (r *PGXPrivacyRepository) FindCompaniesByEmail(ctx context.Context, emails []string) ([]uuid.UUID, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	var ids []uuid.UUID
+	for _, table := range ContactTables {
+		matches, err := r.matchEmails(ctx, r.pool, table, emails, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if match.companyID != nil {
+				ids = append(ids, *match.companyID)
+			}
+		}
+	}
+
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	return slices.Compact(ids), nil
+}
+
+// privacyExportQueries reads every row held about the companies in $1 or the lowercased emails in
+// $2, keyed by the table it comes from. Company locations are exported as longitude/latitude. The
+// emails of contact tables may be encrypted, so their queries get the keys of the rows holding the
+// emails as $2 instead.
+var privacyExportQueries = []struct {
+	table    string
+	query    string
+	contacts *ContactTable
+}{
+	{"companies", `
+		SELECT (to_jsonb(c) - 'location') || jsonb_build_object(
+			'longitude', ST_X(c.location::geometry),
+			'latitude', ST_Y(c.location::geometry)
+		)
+		FROM companies c
+		WHERE c.id = ANY($1)
+		ORDER BY c.id`, nil},
+	{"company_enrichments", `
+		SELECT to_jsonb(e) - 'contact_terms' FROM company_enrichments e
+		WHERE e.company_id = ANY($1)
+		ORDER BY e.company_id`, nil},
+	{"website_enriched_contacts", `
+		SELECT to_jsonb(w) FROM website_enriched_contacts w
+		WHERE w.company_id = ANY($1) OR w.company_id::text = ANY($2)
+		ORDER BY w.company_id`, &WebsiteContactTable},
+	{"domain_enrichments", `
+		SELECT to_jsonb(d) FROM domain_enrichments d
+		WHERE d.source_company_id = ANY($1) OR d.domain = ANY($2)
+		ORDER BY d.domain`, &DomainContactTable},
+	{"change_events", `
+		SELECT to_jsonb(ev) - 'tx_id' FROM change_events ev
+		WHERE ev.entity_id = ANY($1)
+		ORDER BY ev.id`, nil},
+	{"contact_collisions", `
+		SELECT to_jsonb(cc) FROM contact_collisions cc
+		WHERE cc.company_ids && $1 OR (cc.kind = 'email' AND cc.value = ANY($2))
+		ORDER BY cc.kind, cc.value`, nil},
+	{"campaign_recipients", `
+		SELECT to_jsonb(cr) - 'token_hash' FROM campaign_recipients cr
+		WHERE cr.company_id = ANY($1) OR cr.email = ANY($2)
+		ORDER BY cr.created_at, cr.id`, nil},
+	{"contact_suppressions", `
+		SELECT to_jsonb(cs) FROM contact_suppressions cs
+		WHERE cs.kind = 'email' AND cs.value = ANY($2)
+		ORDER BY cs.value`, nil},
+}
+
+// ExportPrivacyData returns every stored row about the companies and the lowercased emails, with
+// encrypted contacts decrypted.
+func (r *PGXPrivacyRepository) ExportPrivacyData(ctx context.Context, companyIDs []uuid.UUID, emails []string) (entity.PrivacyExport, error) {
+	export := entity.PrivacyExport{
+		Emails:     stringSliceOrEmpty(emails),
+		CompanyIDs: companyIDs,
+		Records:    make(map[string][]json.RawMessage, len(privacyExportQueries)),
+	}
+	if export.CompanyIDs == nil {
+		export.CompanyIDs = []uuid.UUID{}
+	}
+	for _, source := range privacyExportQueries {
+		var matched any = export.Emails
+		if source.contacts != nil {
+			keys := make([]string, 0)
+			if len(export.Emails) > 0 {
+				matches, err := r.matchEmails(ctx, r.pool, *source.contacts, export.Emails, false)
+				if err != nil {
+					return export, err
+				}
+				for _, match := range matches {
+					keys = append(keys, match.key)
+				}
+			}
+			matched = keys
+		}
+		rows, err := r.pool.Query(ctx, source.query, export.CompanyIDs, matched)
+		if err != nil {
+			return export, fmt.Errorf("export %s: %w", source.table, err)
+		}
+		records := make([]json.RawMessage, 0)
+		for rows.Next() {
+			var record []byte
+			if err := rows.Scan(&record); err != nil {
+				rows.Close()
+				return export, fmt.Errorf("scan %s: %w", source.table, err)
+			}
+			records = append(records, json.RawMessage(record))
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return export, fmt.Errorf("iterate %s: %w", source.table, err)
+		}
+		export.Records[source.table] = records
+	}
+
+	for _, table := range ContactTables {
+		for i, record := range export.Records[table.Name] {
+			decrypted, err := decryptContactsJSON(r.cipher, record)
+			if err != nil {
+				return export, err
+			}
+			export.Records[table.Name][i] = decrypted
+		}
+	}
+	for i, record := range export.Records["change_events"] {
+		decrypted, err := decryptEventContactsJSON(r.cipher, record)
+		if err != nil {
+			return export, err
+		}
+		export.Records["change_events"][i] = decrypted
+	}
+	return export, nil
+}
+
+// decryptContactsJSON decrypts the emails and phones arrays of a contact table row encoded as JSON.
+func decryptContactsJSON(cipher *fieldcrypt.Cipher, record json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return nil, fmt.Errorf("decode enrichment record: %w", err)
+	}
+	for _, key := range []string{"emails", "phones"} {
+		var values []string
+		if err := json.Unmarshal(fields[key], &values); err != nil || values == nil {
+			continue
+		}
+		plain, err := cipher.DecryptAll(values)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt enrichment %s: %w", key, err)
+		}
+		encoded, err := json.Marshal(plain)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = encoded
+	}
+	return json.Marshal(fields)
+}
+
+// decryptEventContactsJSON decrypts the enrichment snapshot of a change event encoded as JSON.
+func decryptEventContactsJSON(cipher *fieldcrypt.Cipher, record json.RawMessage) (json.RawMessage, error) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(record, &event); err != nil {
+		return nil, fmt.Errorf("decode change event: %w", err)
+	}
+	var kind string
+	_ = json.Unmarshal(event["entity"], &kind)
+	if kind != "enrichment" || len(event["data"]) == 0 || string(event["data"]) == "null" {
+		return record, nil
+	}
+	data, err := decryptContactsJSON(cipher, event["data"])
+	if err != nil {
+		return nil, err
+	}
+	event["data"] = data
+	return json.Marshal(event)
+}
+
+// ErasePrivacyData scrubs, in one transaction, every contact of the companies of request and every
+// occurrence of the lowercased emails, then records request with the number of rows touched per
+// table. Whole companies lose their phone number, raw payload, enrichment contacts, website
+// contacts, domain cache entries, collisions, campaign recipients and change event snapshots;
+// emails are removed from the contact lists holding them and from campaign recipients, and the
+// change event snapshots of enrichments that held them are cleared. It returns the object keys of the offloaded raw payloads the caller must delete.
+func (r *PGXPrivacyRepository) ErasePrivacyData(ctx context.Context, request *entity.PrivacyRequest, emails []string) ([]string, error) {
+	if request == nil {
+		return nil, errors.New("privacy request is nil")
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin privacy erasure: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	counts := map[string]int64{}
+	exec := func(table, query string, args ...any) error {
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("erase %s: %w", table, err)
+		}
+		counts[table] += tag.RowsAffected()
+		return nil
+	}
+
+	var refs []string
+	if ids := request.CompanyIDs; len(ids) > 0 {
+		// Snapshots are cleared first so the events recorded by the updates below, which only
+		// carry the scrubbed rows, are kept.
+		if err := exec("change_events", `UPDATE change_events SET data = NULL WHERE entity_id = ANY($1) AND data IS NOT NULL`, ids); err != nil {
+			return nil, err
+		}
+		if err := exec("company_enrichments", `
+			UPDATE company_enrichments
+			SET emails = ARRAY[]::TEXT[], phones = ARRAY[]::TEXT[], socials = '{}'::jsonb, contact_terms = ARRAY[]::TEXT[],
+				address = NULL, contact_form_url = NULL, metadata = '{}'::jsonb, updated_at = NOW()
+			WHERE company_id = ANY($1)
+		`, ids); err != nil {
+			return nil, err
+		}
+		if err := exec("website_enriched_contacts", `DELETE FROM website_enriched_contacts WHERE company_id = ANY($1)`, ids); err != nil {
+			return nil, err
+		}
+		if err := exec("domain_enrichments", `DELETE FROM domain_enrichments WHERE source_company_id = ANY($1)`, ids); err != nil {
+			return nil, err
+		}
+		if err := exec("contact_collisions", `DELETE FROM contact_collisions WHERE company_ids && $1`, ids); err != nil {
+			return nil, err
+		}
+		if err := exec("campaign_recipients", `DELETE FROM campaign_recipients WHERE company_id = ANY($1)`, ids); err != nil {
+			return nil, err
+		}
+
+		rows, err := tx.Query(ctx, `
+			WITH erased AS (
+				SELECT id, raw_ref FROM companies WHERE id = ANY($1) FOR UPDATE
+			)
+			UPDATE companies c
+			SET phone = NULL, raw = `+rawResidueSQL+`, raw_ref = NULL, updated_at = NOW()
+			FROM erased
+			WHERE c.id = erased.id
+			RETURNING erased.raw_ref
+		`, ids)
+		if err != nil {
+			return nil, fmt.Errorf("erase companies: %w", err)
+		}
+		for rows.Next() {
+			var ref *string
+			if err := rows.Scan(&ref); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan erased company: %w", err)
+			}
+			counts["companies"]++
+			if ref != nil {
+				refs = append(refs, *ref)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erase companies: %w", err)
+		}
+		counts["raw_payloads"] = int64(len(refs))
+	}
+
+	if len(emails) > 0 {
+		matches, err := r.matchEmails(ctx, tx, EnrichmentContactTable, emails, true)
+		if err != nil {
+			return nil, err
+		}
+		matched := make([]uuid.UUID, 0, len(matches))
+		for _, match := range matches {
+			matched = append(matched, *match.companyID)
+		}
+		if len(matched) > 0 {
+			if err := exec("change_events", `
+				UPDATE change_events SET data = NULL
+				WHERE entity = 'enrichment' AND entity_id = ANY($1) AND data IS NOT NULL
+			`, matched); err != nil {
+				return nil, err
+			}
+		}
+		// The search terms of the erased emails go with them, under every key.
+		var erasedTerms []string
+		for _, email := range emails {
+			if value := contactEmail(email); value != "" {
+				erasedTerms = append(erasedTerms, r.cipher.Tokens("email:"+value)...)
+			}
+		}
+		for _, match := range matches {
+			if err := exec("company_enrichments", `
+				UPDATE company_enrichments SET emails = $2, updated_at = NOW(),
+					contact_terms = array(SELECT t FROM unnest(contact_terms) AS t WHERE t <> ALL($3))
+				WHERE company_id = $1
+			`, match.key, match.kept, stringSliceOrEmpty(erasedTerms)); err != nil {
+				return nil, err
+			}
+		}
+		for _, table := range []ContactTable{WebsiteContactTable, DomainContactTable} {
+			matches, err := r.matchEmails(ctx, tx, table, emails, true)
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				if err := exec(table.Name, `
+					UPDATE `+table.Name+` SET emails = $2, updated_at = NOW()
+					WHERE `+table.key+` = $1
+				`, match.key, match.kept); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := exec("contact_collisions", `DELETE FROM contact_collisions WHERE kind = 'email' AND value = ANY($1)`, emails); err != nil {
+			return nil, err
+		}
+		// The do-not-contact list is kept: it is what stops the address being contacted again.
+		if err := exec("campaign_recipients", `DELETE FROM campaign_recipients WHERE email = ANY($1)`, emails); err != nil {
+			return nil, err
+		}
+	}
+
+	request.Counts = counts
+	if err := recordPrivacyRequest(ctx, tx, request); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit privacy erasure: %w", err)
+	}
+	return refs, nil
+}
+
+// RecordPrivacyRequest adds request to the audit trail and populates its id and creation time.
+func (r *PGXPrivacyRepository) RecordPrivacyRequest(ctx context.Context, request *entity.PrivacyRequest) error {
+	if request == nil {
+		return errors.New("privacy request is nil")
+	}
+	return recordPrivacyRequest(ctx, r.pool, request)
+}
+
+func recordPrivacyRequest(ctx context.Context, q ReadPool, request *entity.PrivacyRequest) error {
+	if request.Counts == nil {
+		request.Counts = map[string]int64{}
+	}
+	counts, err := json.Marshal(request.Counts)
+	if err != nil {
+		return fmt.Errorf("encode privacy request counts: %w", err)
+	}
+	err = q.QueryRow(ctx, `
+		INSERT INTO privacy_requests (action, subject_hashes, company_ids, counts, requested_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, request.Action, stringSliceOrEmpty(request.SubjectHashes), uuidSliceOrEmpty(request.CompanyIDs), counts, request.RequestedBy).
+		Scan(&request.ID, &request.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("record privacy request: %w", err)
+	}
+	return nil
+}
+
+// ListPrivacyRequests returns the audit trail, newest first.
+func (r *PGXPrivacyRepository) ListPrivacyRequests(ctx context.Context, limit, offset int) ([]entity.PrivacyRequest, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, action, subject_hashes, company_ids, counts, requested_by, created_at
+		FROM privacy_requests
+		ORDER BY created_at DESC, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list privacy requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := make([]entity.PrivacyRequest, 0)
+	for rows.Next() {
+		var (
+			request entity.PrivacyRequest
+			counts  []byte
+		)
+		if err := rows.Scan(&request.ID, &request.Action, &request.SubjectHashes, &request.CompanyIDs, &counts, &request.RequestedBy, &request.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan privacy request: %w", err)
+		}
+		if err := json.Unmarshal(counts, &request.Counts); err != nil {
+			return nil, fmt.Errorf("decode privacy request counts: %w", err)
+		}
+		requests = append(requests, request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate privacy requests: %w", err)
+	}
+	return requests, nil
+}
+
+func uuidSliceOrEmpty(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+func TestPGXPrivacyRepository_EraseEmailsKeepsOtherCiphertexts(t *testing.T) {
+	cipher, err := fieldcrypt.New([]byte(strings.Repeat("k", fieldcrypt.KeySize)))
+	if err != nil {
+		t.Fatalf("new cipher: %v", err)
+	}
+	erased, _ := cipher.Encrypt("Jane@Example.com")
+	kept, _ := cipher.Encrypt("sales@example.com")
+	companyID := uuid.New()
+
+	var statements []string
+	var keptArg, domainArgs []any
+	tx := &stubTx{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "FOR UPDATE") || args[1] != fieldcrypt.LikePattern {
+				t.Fatalf("expected locked match of encrypted rows, got %s %v", query, args)
+			}
+			switch {
+			case strings.Contains(query, "FROM company_enrichments"):
+				return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+					*dest[0].(*string) = companyID.String()
+					*dest[1].(**uuid.UUID) = &companyID
+					*dest[2].(*[]string) = []string{erased, kept}
+					return nil
+				}}}, nil
+			case strings.Contains(query, "FROM domain_enrichments"):
+				return &stubRows{scans: []func(dest ...any) error{func(dest ...any) error {
+					*dest[0].(*string) = "example.com"
+					*dest[2].(*[]string) = []string{erased}
+					return nil
+				}}}, nil
+			}
+			return &stubRows{}, nil
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			statements = append(statements, query)
+			if strings.Contains(query, "UPDATE company_enrichments SET emails = $2") {
+				keptArg = args
+			}
+			if strings.Contains(query, "UPDATE domain_enrichments SET emails = $2") {
+				domainArgs = args
+			}
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "INSERT INTO privacy_requests") {
+				t.Fatalf("expected the audit entry, got %s", query)
+			}
+			var counts map[string]int64
+			if err := json.Unmarshal(args[3].([]byte), &counts); err != nil || counts["company_enrichments"] != 1 || counts["domain_enrichments"] != 1 || counts["contact_collisions"] != 1 {
+				t.Fatalf("unexpected recorded counts %s (%v)", args[3], err)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = uuid.New()
+				*dest[1].(*time.Time) = time.Now()
+				return nil
+			}}
+		},
+	}
+	repo := &PGXPrivacyRepository{pool: &stubPool{beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+		return tx, nil
+	}}, cipher: cipher}
+
+	request := &entity.PrivacyRequest{Action: entity.PrivacyActionErase}
+	refs, err := repo.ErasePrivacyData(context.Background(), request, []string{"jane@example.com"})
+	if err != nil {
+		t.Fatalf("erase: %v", err)
+	}
+	if len(refs) != 0 || !tx.committed || request.ID == uuid.Nil {
+		t.Fatalf("expected a committed, recorded erasure, got refs %v committed %v id %s", refs, tx.committed, request.ID)
+	}
+	if keptArg[0] != companyID.String() || len(keptArg[1].([]string)) != 1 || keptArg[1].([]string)[0] != kept {
+		t.Fatalf("expected only the untouched ciphertext kept, got %v", keptArg)
+	}
+	if domainArgs[0] != "example.com" || len(domainArgs[1].([]string)) != 0 {
+		t.Fatalf("expected the encrypted email erased from the domain cache, got %v", domainArgs)
+	}
+	if !strings.Contains(statements[0], "UPDATE change_events SET data = NULL") {
+		t.Fatalf("expected snapshots cleared before the enrichment is rewritten, got %s", statements[0])
+	}
+}
+
+func TestDecryptEventContactsJSON(t *testing.T) {
+	cipher, err := fieldcrypt.New([]byte(strings.Repeat("k", fieldcrypt.KeySize)))
+	if err != nil {
+		t.Fatalf("new cipher: %v", err)
+	}
+	email, _ := cipher.Encrypt("jane@example.com")
+	event := json.RawMessage(`{"entity":"enrichment","data":{"emails":["` + email + `"],"phones":[]}}`)
+
+	decrypted, err := decryptEventContactsJSON(cipher, event)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !strings.Contains(string(decrypted), `"jane@example.com"`) || strings.Contains(string(decrypted), "enc:v1:") {
+		t.Fatalf("expected the snapshot decrypted, got %s", decrypted)
+	}
+
+	company := json.RawMessage(`{"entity":"company","data":{"phone":"+62"}}`)
+	if out, err := decryptEventContactsJSON(cipher, company); err != nil || string(out) != string(company) {
+		t.Fatalf("expected company events untouched, got %s (%v)", out, err)
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Prompt alias repository errors.
+var (
+	ErrPromptAliasNotFound  = errors.New("prompt alias not found")
+	ErrPromptAliasDuplicate = errors.New("prompt alias already exists")
+)
+
+// PromptAliasesRepository persists the aliases used by the prompt parser.
+type PromptAliasesRepository interface {
+	List(ctx context.Context) ([]entity.PromptAlias, error)
+	Create(ctx context.Context, alias *entity.PromptAlias) error
+	Update(ctx context.Context, alias *entity.PromptAlias) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PGXPromptAliasesRepository implements PromptAliasesRepository using pgx.
+type PGXPromptAliasesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXPromptAliasesRepository wires a pgx backed prompt aliases repository.
+func NewPGXPromptAliasesRepository(pool *pgxpool.Pool) *PGXPromptAliasesRepository {
+	return &PGXPromptAliasesRepository{pool: pool}
+}
+
+// List returns every alias ordered by kind and alias.
+func (r *PGXPromptAliasesRepository) List(ctx context.Context) ([]entity.PromptAlias, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, kind, alias, canonical, created_at, updated_at
+		FROM prompt_aliases
+		ORDER BY kind, alias
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make([]entity.PromptAlias, 0)
+	for rows.Next() {
+		var alias entity.PromptAlias
+		if err := rows.Scan(&alias.ID, &alias.Kind, &alias.Alias, &alias.Canonical, &alias.CreatedAt, &alias.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan prompt alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate prompt aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// Create inserts an alias and populates its identifier and timestamps.
+func (r *PGXPromptAliasesRepository) Create(ctx context.Context, alias *entity.PromptAlias) error {
+	if alias == nil {
+		return fmt.Errorf("prompt alias payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO prompt_aliases (kind, alias, canonical)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, alias.Kind, alias.Alias, alias.Canonical).Scan(&alias.ID, &alias.CreatedAt, &alias.UpdatedAt)
+	if err != nil {
+		if isPromptAliasDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrPromptAliasDuplicate, err)
+		}
+		return fmt.Errorf("insert prompt alias: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites an alias by id and refreshes its timestamps.
+func (r *PGXPromptAliasesRepository) Update(ctx context.Context, alias *entity.PromptAlias) error {
+	if alias == nil {
+		return fmt.Errorf("prompt alias payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		UPDATE prompt_aliases
+		SET kind = $2, alias = $3, canonical = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, alias.ID, alias.Kind, alias.Alias, alias.Canonical).Scan(&alias.CreatedAt, &alias.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPromptAliasNotFound
+		}
+		if isPromptAliasDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrPromptAliasDuplicate, err)
+		}
+		return fmt.Errorf("update prompt alias: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an alias by id.
+func (r *PGXPromptAliasesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM prompt_aliases WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete prompt alias: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPromptAliasNotFound
+	}
+	return nil
+}
+
+func isPromptAliasDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "prompt_aliases_kind_alias_key"
+}
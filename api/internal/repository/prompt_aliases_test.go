@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXPromptAliasesRepository_CreateDuplicate(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23505", ConstraintName: "prompt_aliases_kind_alias_key"}
+			}}
+		},
+	}
+	repo := &PGXPromptAliasesRepository{pool: pool}
+
+	err := repo.Create(context.Background(), &entity.PromptAlias{Kind: entity.PromptAliasKindCity, Alias: "jaksel", Canonical: "Jakarta Selatan"})
+	if !errors.Is(err, ErrPromptAliasDuplicate) {
+		t.Fatalf("expected ErrPromptAliasDuplicate, got %v", err)
+	}
+}
+
+func TestPGXPromptAliasesRepository_NotFound(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			return pgconn.NewCommandTag("DELETE 0"), nil
+		},
+	}
+	repo := &PGXPromptAliasesRepository{pool: pool}
+
+	if err := repo.Update(context.Background(), &entity.PromptAlias{ID: uuid.New()}); !errors.Is(err, ErrPromptAliasNotFound) {
+		t.Fatalf("expected ErrPromptAliasNotFound on update, got %v", err)
+	}
+	if err := repo.Delete(context.Background(), uuid.New()); !errors.Is(err, ErrPromptAliasNotFound) {
+		t.Fatalf("expected ErrPromptAliasNotFound on delete, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// ErrQueryTimeout is returned when a bounded query runs past the configured timeout.
+var ErrQueryTimeout = errors.New("query timed out")
+
+// RepositoryOption tunes the pgx repositories: the query timeout of the read-heavy ones
+// (companies, stats and freshness), the cipher of the enrichment contacts and whether company
+// listings read leads_view.
+type RepositoryOption func(*repositoryOptions)
+
+// repositoryOptions collects what the options set; each repository keeps the parts it uses.
+type repositoryOptions struct {
+	limits    queryLimits
+	cipher    *fieldcrypt.Cipher
+	leadsView bool
+}
+
+func newRepositoryOptions(opts []RepositoryOption) repositoryOptions {
+	var options repositoryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithQueryTimeout bounds each listing, search, facet, trend and stats query; zero leaves them
+// unbounded. Streaming exports are not bounded.
+func WithQueryTimeout(timeout time.Duration) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.limits.timeout = timeout
+	}
+}
+
+// queryLimits holds the deadline applied to read-heavy repository methods.
+type queryLimits struct {
+	timeout time.Duration
+}
+
+func newQueryLimits(opts []RepositoryOption) queryLimits {
+	return newRepositoryOptions(opts).limits
+}
+
+// bound derives a context carrying the query timeout. The returned function releases it and turns
+// an error caused by the timeout into ErrQueryTimeout; call it once the rows have been read:
+//
+//	ctx, done := r.limits.bound(ctx)
+//	defer func() { err = done(err) }()
+func (l queryLimits) bound(ctx context.Context) (context.Context, func(error) error) {
+	if l.timeout <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	return ctx, func(err error) error {
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil
+		cancel()
+		if err != nil && timedOut {
+			return fmt.Errorf("%w after %s: %v", ErrQueryTimeout, l.timeout, err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Raw reprocess job repository errors.
+var (
+	ErrRawReprocessJobNotFound = errors.New("raw reprocess job not found")
+	// ErrRawReprocessJobActive is returned when another job is still queued or running.
+	ErrRawReprocessJobActive = errors.New("raw reprocess job already active")
+)
+
+// RawReprocessJobsRepository persists background raw payload reprocessing jobs and their progress.
+type RawReprocessJobsRepository interface {
+	Create(ctx context.Context, job *entity.RawReprocessJob) error
+	Update(ctx context.Context, job *entity.RawReprocessJob) error
+	Get(ctx context.Context, id uuid.UUID) (*entity.RawReprocessJob, error)
+	List(ctx context.Context, limit, offset int) ([]entity.RawReprocessJob, error)
+	ListUnfinished(ctx context.Context) ([]entity.RawReprocessJob, error)
+}
+
+// PGXRawReprocessJobsRepository implements RawReprocessJobsRepository using pgx.
+type PGXRawReprocessJobsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXRawReprocessJobsRepository wires a pgx backed raw reprocess jobs repository.
+func NewPGXRawReprocessJobsRepository(pool *pgxpool.Pool) *PGXRawReprocessJobsRepository {
+	return &PGXRawReprocessJobsRepository{pool: pool}
+}
+
+const rawReprocessJobColumns = `
+	id, created_by, query, filter, status, total, processed, updated, skipped, resume_after, error, created_at, started_at,
+	finished_at, updated_at
+`
+
+// Create inserts a queued job, returning ErrRawReprocessJobActive while another job is active.
+func (r *PGXRawReprocessJobsRepository) Create(ctx context.Context, job *entity.RawReprocessJob) error {
+	if job == nil {
+		return fmt.Errorf("raw reprocess job payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO raw_reprocess_jobs (id, created_by, query, filter, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`, job.ID, job.CreatedBy, job.Query, job.Filter, job.Status).Scan(&job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_raw_reprocess_jobs_active" {
+			return ErrRawReprocessJobActive
+		}
+		return fmt.Errorf("insert raw reprocess job: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status and progress of a job.
+func (r *PGXRawReprocessJobsRepository) Update(ctx context.Context, job *entity.RawReprocessJob) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE raw_reprocess_jobs
+		SET status = $2, total = $3, processed = $4, updated = $5, skipped = $6, resume_after = $7, error = $8,
+			started_at = $9, finished_at = $10, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, job.ID, job.Status, job.Total, job.Processed, job.Updated, job.Skipped, job.ResumeAfter, job.Error, job.StartedAt, job.FinishedAt).Scan(&job.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRawReprocessJobNotFound
+		}
+		return fmt.Errorf("update raw reprocess job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a single job.
+func (r *PGXRawReprocessJobsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.RawReprocessJob, error) {
+	job, err := scanRawReprocessJob(r.pool.QueryRow(ctx, `SELECT `+rawReprocessJobColumns+` FROM raw_reprocess_jobs WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRawReprocessJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns jobs, newest first.
+func (r *PGXRawReprocessJobsRepository) List(ctx context.Context, limit, offset int) ([]entity.RawReprocessJob, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+rawReprocessJobColumns+`
+		FROM raw_reprocess_jobs
+		ORDER BY created_at DESC, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list raw reprocess jobs: %w", err)
+	}
+	return collectRawReprocessJobs(rows)
+}
+
+// ListUnfinished returns queued and running jobs, oldest first.
+func (r *PGXRawReprocessJobsRepository) ListUnfinished(ctx context.Context) ([]entity.RawReprocessJob, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+rawReprocessJobColumns+`
+		FROM raw_reprocess_jobs
+		WHERE status IN ($1, $2)
+		ORDER BY created_at, id
+	`, entity.RawReprocessQueued, entity.RawReprocessRunning)
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished raw reprocess jobs: %w", err)
+	}
+	return collectRawReprocessJobs(rows)
+}
+
+func collectRawReprocessJobs(rows pgx.Rows) ([]entity.RawReprocessJob, error) {
+	defer rows.Close()
+	jobs := make([]entity.RawReprocessJob, 0)
+	for rows.Next() {
+		job, err := scanRawReprocessJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate raw reprocess jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func scanRawReprocessJob(row pgx.Row) (*entity.RawReprocessJob, error) {
+	var job entity.RawReprocessJob
+	err := row.Scan(
+		&job.ID,
+		&job.CreatedBy,
+		&job.Query,
+		&job.Filter,
+		&job.Status,
+		&job.Total,
+		&job.Processed,
+		&job.Updated,
+		&job.Skipped,
+		&job.ResumeAfter,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan raw reprocess job: %w", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXRawReprocessJobsRepository_CreateRefusesSecondActiveJob(t *testing.T) {
+	repo := &PGXRawReprocessJobsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23505", ConstraintName: "idx_raw_reprocess_jobs_active"}
+			}}
+		},
+	}}
+
+	err := repo.Create(context.Background(), &entity.RawReprocessJob{ID: uuid.New(), Filter: []byte(`{}`), Status: entity.RawReprocessQueued})
+	if !errors.Is(err, ErrRawReprocessJobActive) {
+		t.Fatalf("expected ErrRawReprocessJobActive, got %v", err)
+	}
+}
+
+func TestPGXRawReprocessJobsRepository_NotFound(t *testing.T) {
+	repo := &PGXRawReprocessJobsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}}
+
+	if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, ErrRawReprocessJobNotFound) {
+		t.Fatalf("expected ErrRawReprocessJobNotFound from Get, got %v", err)
+	}
+	if err := repo.Update(context.Background(), &entity.RawReprocessJob{ID: uuid.New()}); !errors.Is(err, ErrRawReprocessJobNotFound) {
+		t.Fatalf("expected ErrRawReprocessJobNotFound from Update, got %v", err)
+	}
+}
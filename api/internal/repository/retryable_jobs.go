@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// RetryableJobsRepository lists the scrape and enrichment jobs the worker did not accept and puts
+// them back in the queue.
+type RetryableJobsRepository interface {
+	// ListFailedJobs returns the failed jobs of kind, every retryable kind when it is empty, newest
+	// first.
+	ListFailedJobs(ctx context.Context, kind string, limit, offset int) ([]entity.RetryableJob, error)
+	// RequeueFailedJob moves a failed job back to the status of a job the worker accepted and
+	// counts the retry.
+	RequeueFailedJob(ctx context.Context, id uuid.UUID) (*entity.RetryableJob, error)
+	FailRetryableJob(ctx context.Context, kind string, id uuid.UUID, reason string) error
+}
+
+var (
+	// ErrRetryableJobNotFound indicates there is no scrape or enrichment job with the requested id.
+	ErrRetryableJobNotFound = errors.New("job not found")
+	// ErrJobNotFailed is returned when a job to retry has not failed, e.g. because it was retried
+	// already.
+	ErrJobNotFailed = errors.New("job has not failed")
+)
+
+// PGXRetryableJobsRepository implements RetryableJobsRepository using pgx.
+type PGXRetryableJobsRepository struct {
+	pool   pgxPool
+	limits queryLimits
+}
+
+// NewPGXRetryableJobsRepository wires a pgx backed retryable jobs repository.
+func NewPGXRetryableJobsRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXRetryableJobsRepository {
+	return &PGXRetryableJobsRepository{pool: pool, limits: newQueryLimits(opts)}
+}
+
+// Both job tables are read as the same columns; the request columns of the other kind are blank.
+const (
+	retryableScrapeColumns = `'scrape', id, status, error, retries, created_by, created_at,
+		type_business, city, country, min_rating, NULL::uuid, ''::text, ''::text, source`
+	retryableEnrichmentColumns = `'enrichment', id, status, error, retries, requested_by, created_at,
+		''::text, ''::text, ''::text, 0::float8, company_id, website, depth, ''::text`
+)
+
+func scanRetryableJob(row pgx.Row) (entity.RetryableJob, error) {
+	var (
+		job        entity.RetryableJob
+		scrape     entity.RetryableScrape
+		enrichment entity.RetryableEnrichment
+		companyID  *uuid.UUID
+	)
+	err := row.Scan(
+		&job.Kind, &job.ID, &job.Status, &job.Error, &job.Retries, &job.RequestedBy, &job.CreatedAt,
+		&scrape.TypeBusiness, &scrape.City, &scrape.Country, &scrape.MinRating,
+		&companyID, &enrichment.Website, &enrichment.Depth, &scrape.Source,
+	)
+	if err != nil {
+		return job, err
+	}
+	switch job.Kind {
+	case entity.JobKindScrape:
+		job.Scrape = &scrape
+	case entity.JobKindEnrichment:
+		if companyID != nil {
+			enrichment.CompanyID = *companyID
+		}
+		job.Enrichment = &enrichment
+	}
+	return job, nil
+}
+
+// ListFailedJobs returns the failed jobs of kind, every retryable kind when it is empty, newest
+// first.
+func (r *PGXRetryableJobsRepository) ListFailedJobs(ctx context.Context, kind string, limit, offset int) (_ []entity.RetryableJob, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT * FROM (
+			SELECT `+retryableScrapeColumns+` FROM scrape_jobs WHERE status = 'failed'
+			UNION ALL
+			SELECT `+retryableEnrichmentColumns+` FROM enrichment_jobs WHERE status = 'failed'
+		) AS jobs (kind, id, status, error, retries, requested_by, created_at,
+			type_business, city, country, min_rating, company_id, website, depth, source)
+		WHERE $1 = '' OR kind = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`, kind, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list failed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.RetryableJob, 0)
+	for rows.Next() {
+		job, err := scanRetryableJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate failed jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// RequeueFailedJob puts a failed scrape job back in the queue, or a failed enrichment job back to
+// accepted. The job counts as created now, so a retried scrape coalesces identical requests and a
+// retried enrichment is charged to its requester's current quota window. It returns
+// ErrJobNotFailed when the job exists but has not failed, so two operators retrying the same job
+// send it once.
+func (r *PGXRetryableJobsRepository) RequeueFailedJob(ctx context.Context, id uuid.UUID) (*entity.RetryableJob, error) {
+	job, err := scanRetryableJob(r.pool.QueryRow(ctx, `
+		UPDATE scrape_jobs
+		SET status = $2, error = NULL, retries = retries + 1, created_at = NOW()
+		WHERE id = $1 AND status = $3
+		RETURNING `+retryableScrapeColumns,
+		id, entity.ScrapeJobQueued, entity.ScrapeJobFailed))
+	if err == nil {
+		return &job, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("requeue scrape job: %w", err)
+	}
+
+	job, err = scanRetryableJob(r.pool.QueryRow(ctx, `
+		UPDATE enrichment_jobs
+		SET status = 'accepted', error = NULL, retries = retries + 1, created_at = NOW()
+		WHERE id = $1 AND status = 'failed'
+		RETURNING `+retryableEnrichmentColumns,
+		id))
+	if err == nil {
+		return &job, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("requeue enrichment job: %w", err)
+	}
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM scrape_jobs WHERE id = $1)
+			OR EXISTS (SELECT 1 FROM enrichment_jobs WHERE id = $1)
+	`, id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("find job: %w", err)
+	}
+	if exists {
+		return nil, ErrJobNotFailed
+	}
+	return nil, ErrRetryableJobNotFound
+}
+
+// FailRetryableJob marks a job of kind failed again after the worker refused its retry.
+func (r *PGXRetryableJobsRepository) FailRetryableJob(ctx context.Context, kind string, id uuid.UUID, reason string) error {
+	var query string
+	switch kind {
+	case entity.JobKindScrape:
+		query = `UPDATE scrape_jobs SET status = 'failed', error = $2 WHERE id = $1`
+	case entity.JobKindEnrichment:
+		query = `UPDATE enrichment_jobs SET status = 'failed', error = $2 WHERE id = $1`
+	default:
+		return fmt.Errorf("fail job: unknown kind %q", kind)
+	}
+	if _, err := r.pool.Exec(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("fail %s job: %w", kind, err)
+	}
+	return nil
+}
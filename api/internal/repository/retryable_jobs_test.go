@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXRetryableJobsRepository_ListFailedJobs(t *testing.T) {
+	companyID := uuid.New()
+	repo := &PGXRetryableJobsRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "FROM scrape_jobs WHERE status = 'failed'") || !strings.Contains(query, "FROM enrichment_jobs WHERE status = 'failed'") {
+				t.Fatalf("unexpected query %q", query)
+			}
+			if args[0] != "enrichment" || args[1] != 20 || args[2] != 40 {
+				t.Fatalf("unexpected args %v", args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					reason := "worker unavailable"
+					*dest[0].(*string) = "enrichment"
+					*dest[2].(*string) = "failed"
+					*dest[3].(**string) = &reason
+					*dest[4].(*int) = 1
+					*dest[11].(**uuid.UUID) = &companyID
+					*dest[12].(*string) = "https://example.com"
+					*dest[13].(*string) = "deep"
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	jobs, err := repo.ListFailedJobs(context.Background(), "enrichment", 20, 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Scrape != nil || jobs[0].Enrichment == nil || jobs[0].Retries != 1 || *jobs[0].Error != "worker unavailable" {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+	if jobs[0].Enrichment.CompanyID != companyID || jobs[0].Enrichment.Depth != "deep" {
+		t.Fatalf("unexpected enrichment request: %+v", jobs[0].Enrichment)
+	}
+}
+
+func TestPGXRetryableJobsRepository_RequeueFailedJob(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("requeues a failed scrape job", func(t *testing.T) {
+		repo := &PGXRetryableJobsRepository{pool: &stubPool{
+			queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+				if !strings.Contains(query, "UPDATE scrape_jobs") || !strings.Contains(query, "retries = retries + 1") {
+					t.Fatalf("unexpected query %q", query)
+				}
+				return &stubRow{scan: func(dest ...any) error {
+					*dest[0].(*string) = "scrape"
+					*dest[1].(*uuid.UUID) = id
+					*dest[2].(*string) = "queued"
+					*dest[7].(*string) = "cafe"
+					*dest[8].(*string) = "Bandung"
+					return nil
+				}}
+			},
+		}}
+		job, err := repo.RequeueFailedJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if job.Scrape == nil || job.Scrape.City != "Bandung" || job.Status != "queued" {
+			t.Fatalf("unexpected job: %+v", job)
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		exists bool
+		want   error
+	}{
+		{name: "job not failed", exists: true, want: ErrJobNotFailed},
+		{name: "job missing", exists: false, want: ErrRetryableJobNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &PGXRetryableJobsRepository{pool: &stubPool{
+				queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+					if strings.Contains(query, "UPDATE") {
+						return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+					}
+					return &stubRow{scan: func(dest ...any) error {
+						*dest[0].(*bool) = tc.exists
+						return nil
+					}}
+				},
+			}}
+			if _, err := repo.RequeueFailedJob(context.Background(), id); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
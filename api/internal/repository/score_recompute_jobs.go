@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Score recompute job repository errors.
+var (
+	ErrScoreRecomputeJobNotFound = errors.New("score recompute job not found")
+	// ErrScoreRecomputeJobActive is returned when another job is still queued or running.
+	ErrScoreRecomputeJobActive = errors.New("score recompute job already active")
+)
+
+// ScoreRecomputeJobsRepository persists background score recompute jobs and their progress.
+type ScoreRecomputeJobsRepository interface {
+	Create(ctx context.Context, job *entity.ScoreRecomputeJob) error
+	Update(ctx context.Context, job *entity.ScoreRecomputeJob) error
+	Get(ctx context.Context, id uuid.UUID) (*entity.ScoreRecomputeJob, error)
+	List(ctx context.Context, limit, offset int) ([]entity.ScoreRecomputeJob, error)
+	ListUnfinished(ctx context.Context) ([]entity.ScoreRecomputeJob, error)
+}
+
+// PGXScoreRecomputeJobsRepository implements ScoreRecomputeJobsRepository using pgx.
+type PGXScoreRecomputeJobsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXScoreRecomputeJobsRepository wires a pgx backed score recompute jobs repository.
+func NewPGXScoreRecomputeJobsRepository(pool *pgxpool.Pool) *PGXScoreRecomputeJobsRepository {
+	return &PGXScoreRecomputeJobsRepository{pool: pool}
+}
+
+const scoreRecomputeJobColumns = `
+	id, created_by, stale_only, status, total, processed, resume_after, error, created_at, started_at, finished_at, updated_at
+`
+
+// Create inserts a queued job, returning ErrScoreRecomputeJobActive while another job is active.
+func (r *PGXScoreRecomputeJobsRepository) Create(ctx context.Context, job *entity.ScoreRecomputeJob) error {
+	if job == nil {
+		return fmt.Errorf("score recompute job payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO score_recompute_jobs (id, created_by, stale_only, status)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`, job.ID, job.CreatedBy, job.StaleOnly, job.Status).Scan(&job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_score_recompute_jobs_active" {
+			return ErrScoreRecomputeJobActive
+		}
+		return fmt.Errorf("insert score recompute job: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status and progress of a job.
+func (r *PGXScoreRecomputeJobsRepository) Update(ctx context.Context, job *entity.ScoreRecomputeJob) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE score_recompute_jobs
+		SET status = $2, total = $3, processed = $4, resume_after = $5, error = $6, started_at = $7,
+			finished_at = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, job.ID, job.Status, job.Total, job.Processed, job.ResumeAfter, job.Error, job.StartedAt, job.FinishedAt).Scan(&job.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrScoreRecomputeJobNotFound
+		}
+		return fmt.Errorf("update score recompute job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a single job.
+func (r *PGXScoreRecomputeJobsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.ScoreRecomputeJob, error) {
+	job, err := scanScoreRecomputeJob(r.pool.QueryRow(ctx, `SELECT `+scoreRecomputeJobColumns+` FROM score_recompute_jobs WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrScoreRecomputeJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns jobs, newest first.
+func (r *PGXScoreRecomputeJobsRepository) List(ctx context.Context, limit, offset int) ([]entity.ScoreRecomputeJob, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+scoreRecomputeJobColumns+`
+		FROM score_recompute_jobs
+		ORDER BY created_at DESC, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list score recompute jobs: %w", err)
+	}
+	return collectScoreRecomputeJobs(rows)
+}
+
+// ListUnfinished returns queued and running jobs, oldest first.
+func (r *PGXScoreRecomputeJobsRepository) ListUnfinished(ctx context.Context) ([]entity.ScoreRecomputeJob, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+scoreRecomputeJobColumns+`
+		FROM score_recompute_jobs
+		WHERE status IN ($1, $2)
+		ORDER BY created_at, id
+	`, entity.ScoreRecomputeQueued, entity.ScoreRecomputeRunning)
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished score recompute jobs: %w", err)
+	}
+	return collectScoreRecomputeJobs(rows)
+}
+
+func collectScoreRecomputeJobs(rows pgx.Rows) ([]entity.ScoreRecomputeJob, error) {
+	defer rows.Close()
+	jobs := make([]entity.ScoreRecomputeJob, 0)
+	for rows.Next() {
+		job, err := scanScoreRecomputeJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate score recompute jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func scanScoreRecomputeJob(row pgx.Row) (*entity.ScoreRecomputeJob, error) {
+	var job entity.ScoreRecomputeJob
+	err := row.Scan(
+		&job.ID,
+		&job.CreatedBy,
+		&job.StaleOnly,
+		&job.Status,
+		&job.Total,
+		&job.Processed,
+		&job.ResumeAfter,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan score recompute job: %w", err)
+	}
+	return &job, nil
+}
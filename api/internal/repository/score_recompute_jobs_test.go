@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXScoreRecomputeJobsRepository_CreateRefusesSecondActiveJob(t *testing.T) {
+	repo := &PGXScoreRecomputeJobsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				return &pgconn.PgError{Code: "23505", ConstraintName: "idx_score_recompute_jobs_active"}
+			}}
+		},
+	}}
+
+	err := repo.Create(context.Background(), &entity.ScoreRecomputeJob{ID: uuid.New(), Status: entity.ScoreRecomputeQueued})
+	if !errors.Is(err, ErrScoreRecomputeJobActive) {
+		t.Fatalf("expected ErrScoreRecomputeJobActive, got %v", err)
+	}
+}
+
+func TestPGXScoreRecomputeJobsRepository_NotFound(t *testing.T) {
+	repo := &PGXScoreRecomputeJobsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}}
+
+	if _, err := repo.Get(context.Background(), uuid.New()); !errors.Is(err, ErrScoreRecomputeJobNotFound) {
+		t.Fatalf("expected ErrScoreRecomputeJobNotFound from Get, got %v", err)
+	}
+	if err := repo.Update(context.Background(), &entity.ScoreRecomputeJob{ID: uuid.New()}); !errors.Is(err, ErrScoreRecomputeJobNotFound) {
+		t.Fatalf("expected ErrScoreRecomputeJobNotFound from Update, got %v", err)
+	}
+}
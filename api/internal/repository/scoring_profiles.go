@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Scoring profile repository errors.
+var (
+	ErrScoringProfileNotFound  = errors.New("scoring profile not found")
+	ErrScoringProfileDuplicate = errors.New("scoring profile already exists")
+)
+
+// ScoringProfilesRepository persists lead scoring profiles and which one is active.
+type ScoringProfilesRepository interface {
+	List(ctx context.Context) ([]entity.ScoringProfile, error)
+	Get(ctx context.Context, id uuid.UUID) (*entity.ScoringProfile, error)
+	// GetActive returns nil without error when no profile is active.
+	GetActive(ctx context.Context) (*entity.ScoringProfile, error)
+	Create(ctx context.Context, profile *entity.ScoringProfile) error
+	Update(ctx context.Context, profile *entity.ScoringProfile) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SetActive makes the profile the active one; nil deactivates every profile.
+	SetActive(ctx context.Context, id *uuid.UUID) error
+}
+
+// PGXScoringProfilesRepository implements ScoringProfilesRepository using pgx.
+type PGXScoringProfilesRepository struct {
+	pool pgxPool
+}
+
+// NewPGXScoringProfilesRepository wires a pgx backed scoring profiles repository.
+func NewPGXScoringProfilesRepository(pool *pgxpool.Pool) *PGXScoringProfilesRepository {
+	return &PGXScoringProfilesRepository{pool: pool}
+}
+
+const scoringProfileColumns = `id, name, weights, inverted, hot_threshold, warm_threshold, active, revision, created_at, updated_at`
+
+// List returns every profile ordered by name.
+func (r *PGXScoringProfilesRepository) List(ctx context.Context) ([]entity.ScoringProfile, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+scoringProfileColumns+` FROM scoring_profiles ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list scoring profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := make([]entity.ScoringProfile, 0)
+	for rows.Next() {
+		var profile entity.ScoringProfile
+		if err := scanScoringProfile(rows, &profile); err != nil {
+			return nil, fmt.Errorf("scan scoring profile: %w", err)
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scoring profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// Get returns a profile by id.
+func (r *PGXScoringProfilesRepository) Get(ctx context.Context, id uuid.UUID) (*entity.ScoringProfile, error) {
+	var profile entity.ScoringProfile
+	err := scanScoringProfile(r.pool.QueryRow(ctx, `SELECT `+scoringProfileColumns+` FROM scoring_profiles WHERE id = $1`, id), &profile)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrScoringProfileNotFound
+		}
+		return nil, fmt.Errorf("get scoring profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// GetActive returns the active profile, or nil when the built-in weights apply.
+func (r *PGXScoringProfilesRepository) GetActive(ctx context.Context) (*entity.ScoringProfile, error) {
+	var profile entity.ScoringProfile
+	err := scanScoringProfile(r.pool.QueryRow(ctx, `SELECT `+scoringProfileColumns+` FROM scoring_profiles WHERE active`), &profile)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get active scoring profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// Create inserts an inactive profile at revision 1 and populates its identifier and timestamps.
+func (r *PGXScoringProfilesRepository) Create(ctx context.Context, profile *entity.ScoringProfile) error {
+	if profile == nil {
+		return fmt.Errorf("scoring profile payload is nil")
+	}
+	weights, err := marshalScoringWeights(profile.Weights)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO scoring_profiles (name, weights, inverted, hot_threshold, warm_threshold)
+		VALUES ($1, $2::jsonb, $3, $4, $5)
+		RETURNING id, active, revision, created_at, updated_at
+	`, profile.Name, weights, profile.Inverted, profile.HotThreshold, profile.WarmThreshold).Scan(&profile.ID, &profile.Active, &profile.Revision, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		if isScoringProfileDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrScoringProfileDuplicate, err)
+		}
+		return fmt.Errorf("insert scoring profile: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites a profile by id and bumps its revision.
+func (r *PGXScoringProfilesRepository) Update(ctx context.Context, profile *entity.ScoringProfile) error {
+	if profile == nil {
+		return fmt.Errorf("scoring profile payload is nil")
+	}
+	weights, err := marshalScoringWeights(profile.Weights)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, `
+		UPDATE scoring_profiles
+		SET name = $2, weights = $3::jsonb, inverted = $4, hot_threshold = $5, warm_threshold = $6,
+			revision = revision + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING active, revision, created_at, updated_at
+	`, profile.ID, profile.Name, weights, profile.Inverted, profile.HotThreshold, profile.WarmThreshold).Scan(&profile.Active, &profile.Revision, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrScoringProfileNotFound
+		}
+		if isScoringProfileDuplicate(err) {
+			return fmt.Errorf("%w: %v", ErrScoringProfileDuplicate, err)
+		}
+		return fmt.Errorf("update scoring profile: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a profile by id; scores computed with it keep their values but lose the reference.
+func (r *PGXScoringProfilesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM scoring_profiles WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete scoring profile: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrScoringProfileNotFound
+	}
+	return nil
+}
+
+// SetActive switches the active profile in one transaction. The previous profile is deactivated
+// first because the unique index on active is checked row by row.
+func (r *PGXScoringProfilesRepository) SetActive(ctx context.Context, id *uuid.UUID) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("start scoring profile activation tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE scoring_profiles SET active = FALSE, updated_at = NOW() WHERE active`); err != nil {
+		return fmt.Errorf("deactivate scoring profiles: %w", err)
+	}
+	if id != nil {
+		tag, err := tx.Exec(ctx, `UPDATE scoring_profiles SET active = TRUE, updated_at = NOW() WHERE id = $1`, *id)
+		if err != nil {
+			return fmt.Errorf("activate scoring profile: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrScoringProfileNotFound
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit scoring profile activation: %w", err)
+	}
+	return nil
+}
+
+func scanScoringProfile(row pgx.Row, profile *entity.ScoringProfile) error {
+	var weights []byte
+	if err := row.Scan(&profile.ID, &profile.Name, &weights, &profile.Inverted, &profile.HotThreshold, &profile.WarmThreshold, &profile.Active, &profile.Revision, &profile.CreatedAt, &profile.UpdatedAt); err != nil {
+		return err
+	}
+	profile.Weights = map[string]int{}
+	if len(weights) > 0 {
+		if err := json.Unmarshal(weights, &profile.Weights); err != nil {
+			return fmt.Errorf("decode scoring weights: %w", err)
+		}
+	}
+	if profile.Inverted == nil {
+		profile.Inverted = []string{}
+	}
+	return nil
+}
+
+func marshalScoringWeights(weights map[string]int) (string, error) {
+	if weights == nil {
+		weights = map[string]int{}
+	}
+	raw, err := json.Marshal(weights)
+	if err != nil {
+		return "", fmt.Errorf("marshal scoring weights: %w", err)
+	}
+	return string(raw), nil
+}
+
+func isScoringProfileDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "scoring_profiles_name_key"
+}
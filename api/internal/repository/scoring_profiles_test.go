@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXScoringProfilesRepository_SetActive(t *testing.T) {
+	id := uuid.New()
+	var statements []string
+	tx := &stubTx{execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+		statements = append(statements, query)
+		if strings.Contains(query, "active = TRUE") {
+			if len(args) != 1 || args[0] != id {
+				t.Fatalf("expected the profile id, got %v", args)
+			}
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	}}
+	repo := &PGXScoringProfilesRepository{pool: &stubPool{beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+		return tx, nil
+	}}}
+
+	if err := repo.SetActive(context.Background(), &id); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Fatalf("expected ErrScoringProfileNotFound, got %v", err)
+	}
+	if tx.committed {
+		t.Fatal("expected a missing profile not to commit the deactivation")
+	}
+	if len(statements) != 2 || !strings.Contains(statements[0], "active = FALSE") {
+		t.Fatalf("expected the active profile deactivated first, got %v", statements)
+	}
+
+	statements = nil
+	if err := repo.SetActive(context.Background(), nil); err != nil || !tx.committed || len(statements) != 1 {
+		t.Fatalf("expected only the deactivation committed, got %v (%v)", statements, err)
+	}
+}
+
+func TestPGXScoringProfilesRepository_GetActiveAndDuplicates(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if strings.Contains(query, "INSERT") {
+				return &stubRow{scan: func(dest ...any) error {
+					return &pgconn.PgError{Code: "23505", ConstraintName: "scoring_profiles_name_key"}
+				}}
+			}
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}
+	repo := &PGXScoringProfilesRepository{pool: pool}
+
+	active, err := repo.GetActive(context.Background())
+	if err != nil || active != nil {
+		t.Fatalf("expected no active profile without error, got %+v (%v)", active, err)
+	}
+	err = repo.Create(context.Background(), &entity.ScoringProfile{Name: "Agency", Weights: map[string]int{"website_quality": 60}})
+	if !errors.Is(err, ErrScoringProfileDuplicate) {
+		t.Fatalf("expected ErrScoringProfileDuplicate, got %v", err)
+	}
+}
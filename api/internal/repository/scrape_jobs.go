@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ScrapeJobsRepository persists scrape jobs and the users who asked for them.
+type ScrapeJobsRepository interface {
+	// ClaimScrapeJob attaches requester to the queued job with the fingerprint of job created
+	// within window and returns true, populating job from it, or records job with requester as its
+	// first requester and returns false. A window of zero or less always records job.
+	ClaimScrapeJob(ctx context.Context, job *entity.ScrapeJob, requester *uuid.UUID, window time.Duration) (bool, error)
+	FailScrapeJob(ctx context.Context, id uuid.UUID, reason string) error
+	// ListRequestedScrapeJobs returns the jobs the user asked for, newest request first.
+	ListRequestedScrapeJobs(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.ScrapeJob, error)
+	GetRequestedScrapeJob(ctx context.Context, userID, id uuid.UUID) (*entity.ScrapeJob, error)
+}
+
+// ErrScrapeJobNotFound indicates there is no scrape job the user asked for with the requested id.
+var ErrScrapeJobNotFound = errors.New("scrape job not found")
+
+// PGXScrapeJobsRepository implements ScrapeJobsRepository using pgx.
+type PGXScrapeJobsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXScrapeJobsRepository wires a pgx backed scrape jobs repository.
+func NewPGXScrapeJobsRepository(pool *pgxpool.Pool) *PGXScrapeJobsRepository {
+	return &PGXScrapeJobsRepository{pool: pool}
+}
+
+// ClaimScrapeJob serialises claims of a fingerprint with a transaction-scoped advisory lock, so two
+// identical requests arriving together still share one job.
+func (r *PGXScrapeJobsRepository) ClaimScrapeJob(ctx context.Context, job *entity.ScrapeJob, requester *uuid.UUID, window time.Duration) (bool, error) {
+	if job == nil {
+		return false, fmt.Errorf("scrape job payload is nil")
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("begin claim scrape job: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, job.Fingerprint); err != nil {
+		return false, fmt.Errorf("lock scrape fingerprint: %w", err)
+	}
+
+	coalesced := false
+	if window > 0 {
+		err := tx.QueryRow(ctx, `
+			SELECT id, type_business, city, country, min_rating, status, created_by, created_at, source
+			FROM scrape_jobs
+			WHERE fingerprint = $1 AND status = $2 AND created_at >= NOW() - make_interval(secs => $3)
+			ORDER BY created_at DESC
+			LIMIT 1
+		`, job.Fingerprint, entity.ScrapeJobQueued, window.Seconds()).Scan(
+			&job.ID, &job.TypeBusiness, &job.City, &job.Country, &job.MinRating, &job.Status, &job.CreatedBy, &job.CreatedAt, &job.Source,
+		)
+		switch {
+		case err == nil:
+			coalesced = true
+		case !errors.Is(err, pgx.ErrNoRows):
+			return false, fmt.Errorf("find queued scrape job: %w", err)
+		}
+	}
+	if !coalesced {
+		job.Status = entity.ScrapeJobQueued
+		job.CreatedBy = requester
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO scrape_jobs (id, fingerprint, type_business, city, country, min_rating, status, created_by, source)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING created_at
+		`, job.ID, job.Fingerprint, job.TypeBusiness, job.City, job.Country, job.MinRating, job.Status, job.CreatedBy, job.Source).Scan(&job.CreatedAt); err != nil {
+			return false, fmt.Errorf("insert scrape job: %w", err)
+		}
+	}
+	if requester != nil {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO scrape_job_requesters (job_id, user_id) VALUES ($1, $2)
+			ON CONFLICT (job_id, user_id) DO NOTHING
+		`, job.ID, *requester); err != nil {
+			return false, fmt.Errorf("add scrape job requester: %w", err)
+		}
+	}
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM scrape_job_requesters WHERE job_id = $1`, job.ID).Scan(&job.Requesters); err != nil {
+		return false, fmt.Errorf("count scrape job requesters: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit claim scrape job: %w", err)
+	}
+	return coalesced, nil
+}
+
+// FailScrapeJob marks a job the worker did not accept, so identical requests start a new one.
+func (r *PGXScrapeJobsRepository) FailScrapeJob(ctx context.Context, id uuid.UUID, reason string) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE scrape_jobs SET status = $2, error = $3 WHERE id = $1
+	`, id, entity.ScrapeJobFailed, reason); err != nil {
+		return fmt.Errorf("fail scrape job: %w", err)
+	}
+	return nil
+}
+
+// requestedScrapeJobsSelect reads the jobs a user asked for, reporting a job as running while its
+// ingest run is open and finished once the run is. Callers append their conditions to $1, the
+// user, and bind the job statuses as $2 to $5.
+const requestedScrapeJobsSelect = `
+	SELECT j.id, j.type_business, j.city, j.country, j.min_rating,
+		CASE
+			WHEN j.status = $2 THEN j.status
+			WHEN run.status = $3 THEN $4
+			WHEN run.status IS NOT NULL THEN $5
+			ELSE j.status
+		END,
+		j.error,
+		(SELECT COUNT(*) FROM scrape_job_requesters other WHERE other.job_id = j.id),
+		run.batches, run.items, j.created_by, j.created_at, run.finished_at, j.source
+	FROM scrape_job_requesters req
+	JOIN scrape_jobs j ON j.id = req.job_id
+	LEFT JOIN ingest_runs run ON run.id = j.id
+	WHERE req.user_id = $1`
+
+func requestedScrapeJobsArgs(userID uuid.UUID, extra ...any) []any {
+	return append([]any{userID, entity.ScrapeJobFailed, entity.IngestRunFinished, entity.ScrapeJobFinished, entity.ScrapeJobRunning}, extra...)
+}
+
+func scanScrapeJob(row pgx.Row) (entity.ScrapeJob, error) {
+	var job entity.ScrapeJob
+	err := row.Scan(
+		&job.ID, &job.TypeBusiness, &job.City, &job.Country, &job.MinRating, &job.Status, &job.Error,
+		&job.Requesters, &job.Batches, &job.Items, &job.CreatedBy, &job.CreatedAt, &job.FinishedAt, &job.Source,
+	)
+	return job, err
+}
+
+// ListRequestedScrapeJobs returns the jobs the user asked for, newest request first.
+func (r *PGXScrapeJobsRepository) ListRequestedScrapeJobs(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.ScrapeJob, error) {
+	rows, err := r.pool.Query(ctx, requestedScrapeJobsSelect+`
+		ORDER BY req.requested_at DESC, j.id
+		LIMIT $6 OFFSET $7
+	`, requestedScrapeJobsArgs(userID, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("list scrape jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.ScrapeJob, 0)
+	for rows.Next() {
+		job, err := scanScrapeJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan scrape job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scrape jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetRequestedScrapeJob returns a job the user asked for, or ErrScrapeJobNotFound when there is no
+// such job or the user did not ask for it.
+func (r *PGXScrapeJobsRepository) GetRequestedScrapeJob(ctx context.Context, userID, id uuid.UUID) (*entity.ScrapeJob, error) {
+	job, err := scanScrapeJob(r.pool.QueryRow(ctx, requestedScrapeJobsSelect+` AND j.id = $6`, requestedScrapeJobsArgs(userID, id)...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrScrapeJobNotFound
+		}
+		return nil, fmt.Errorf("get scrape job: %w", err)
+	}
+	return &job, nil
+}
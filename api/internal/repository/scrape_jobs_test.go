@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXScrapeJobsRepository_ClaimScrapeJob(t *testing.T) {
+	existing := uuid.New()
+	found := true
+	var statements []string
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			statements = append(statements, query)
+			return &stubRow{scan: func(dest ...any) error {
+				switch {
+				case strings.Contains(query, "FROM scrape_jobs"):
+					if !found {
+						return pgx.ErrNoRows
+					}
+					*dest[0].(*uuid.UUID) = existing
+					*dest[5].(*string) = entity.ScrapeJobQueued
+				case strings.Contains(query, "COUNT(*)"):
+					*dest[0].(*int) = 2
+				case strings.Contains(query, "INSERT INTO scrape_jobs"):
+					*dest[0].(*time.Time) = time.Now()
+				}
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			statements = append(statements, query)
+			return pgconn.CommandTag{}, nil
+		},
+	}
+	repo := &PGXScrapeJobsRepository{pool: &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}}
+	requester := uuid.New()
+
+	job := &entity.ScrapeJob{ID: uuid.New(), Fingerprint: "plumber|jakarta|indonesia|0"}
+	coalesced, err := repo.ClaimScrapeJob(context.Background(), job, &requester, time.Hour)
+	if err != nil || !coalesced || job.ID != existing || job.Requesters != 2 || !tx.committed {
+		t.Fatalf("expected the queued job to be joined, got %+v, %v, %v", job, coalesced, err)
+	}
+	if !strings.Contains(statements[0], "pg_advisory_xact_lock") {
+		t.Fatalf("expected the fingerprint to be locked first, got %v", statements)
+	}
+
+	found, statements = false, nil
+	job = &entity.ScrapeJob{ID: uuid.New(), Fingerprint: "plumber|jakarta|indonesia|0"}
+	coalesced, err = repo.ClaimScrapeJob(context.Background(), job, &requester, time.Hour)
+	if err != nil || coalesced || job.Status != entity.ScrapeJobQueued || job.CreatedBy == nil || *job.CreatedBy != requester {
+		t.Fatalf("expected a new job, got %+v, %v, %v", job, coalesced, err)
+	}
+	if len(statements) != 5 || !strings.Contains(statements[2], "INSERT INTO scrape_jobs") {
+		t.Fatalf("unexpected statements %v", statements)
+	}
+}
+
+func TestPGXScrapeJobsRepository_GetRequestedScrapeJob(t *testing.T) {
+	userID, jobID := uuid.New(), uuid.New()
+	found := true
+	repo := &PGXScrapeJobsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if !strings.Contains(query, "req.user_id = $1") || !strings.Contains(query, "j.id = $6") || args[0] != userID || args[5] != jobID {
+				t.Fatalf("unexpected query %s with %v", query, args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				if !found {
+					return pgx.ErrNoRows
+				}
+				if len(dest) != 14 {
+					t.Fatalf("expected 14 columns, got %d", len(dest))
+				}
+				*dest[0].(*uuid.UUID) = jobID
+				*dest[5].(*string) = entity.ScrapeJobRunning
+				batches := 3
+				*dest[8].(**int) = &batches
+				*dest[13].(*string) = entity.ScrapeSourceGoogleMaps
+				return nil
+			}}
+		},
+	}}
+
+	job, err := repo.GetRequestedScrapeJob(context.Background(), userID, jobID)
+	if err != nil || job.ID != jobID || job.Status != entity.ScrapeJobRunning || job.Batches == nil || *job.Batches != 3 || job.Source != entity.ScrapeSourceGoogleMaps {
+		t.Fatalf("unexpected job %+v, %v", job, err)
+	}
+
+	found = false
+	if _, err := repo.GetRequestedScrapeJob(context.Background(), userID, jobID); err != ErrScrapeJobNotFound {
+		t.Fatalf("expected ErrScrapeJobNotFound, got %v", err)
+	}
+}
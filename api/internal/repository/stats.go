@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// StatsRepository computes catalogue-wide aggregates for the dashboard.
+type StatsRepository interface {
+	CatalogueTotals(ctx context.Context) (*entity.CatalogueStats, error)
+	TopCities(ctx context.Context, limit int) ([]entity.StatBucket, error)
+	TopBusinessTypes(ctx context.Context, limit int) ([]entity.StatBucket, error)
+	ScrapeRunTotals(ctx context.Context, limit int) ([]entity.ScrapeRunStats, error)
+}
+
+// PGXStatsRepository implements StatsRepository using pgx.
+type PGXStatsRepository struct {
+	pool   ReadPool
+	limits queryLimits
+}
+
+// NewPGXStatsRepository wires a pgx backed stats repository.
+func NewPGXStatsRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXStatsRepository {
+	return &PGXStatsRepository{pool: pool, limits: newQueryLimits(opts)}
+}
+
+// NewPGXStatsRepositoryWithReplica wires a stats repository reading through reads, such as a
+// database.ReplicaRouter.
+func NewPGXStatsRepositoryWithReplica(reads ReadPool, opts ...RepositoryOption) *PGXStatsRepository {
+	return &PGXStatsRepository{pool: reads, limits: newQueryLimits(opts)}
+}
+
+// CatalogueTotals counts companies, enrichments and missing websites in a single pass over companies.
+func (r *PGXStatsRepository) CatalogueTotals(ctx context.Context) (_ *entity.CatalogueStats, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	var (
+		stats     entity.CatalogueStats
+		avgRating sql.NullFloat64
+	)
+	err = r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE website IS NULL),
+			AVG(rating)::float8,
+			(SELECT COUNT(*) FROM company_enrichments)
+		FROM companies
+	`).Scan(&stats.Companies, &stats.WithoutWebsite, &avgRating, &stats.Enriched)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate catalogue totals: %w", err)
+	}
+	if avgRating.Valid {
+		val := avgRating.Float64
+		stats.AverageRating = &val
+	}
+	return &stats, nil
+}
+
+// TopCities returns the cities with the most companies.
+func (r *PGXStatsRepository) TopCities(ctx context.Context, limit int) ([]entity.StatBucket, error) {
+	return r.topBuckets(ctx, "city", limit)
+}
+
+// TopBusinessTypes returns the business types with the most companies.
+func (r *PGXStatsRepository) TopBusinessTypes(ctx context.Context, limit int) ([]entity.StatBucket, error) {
+	return r.topBuckets(ctx, "type_business", limit)
+}
+
+// topBuckets groups companies by a fixed column name; callers never pass user input as column.
+func (r *PGXStatsRepository) topBuckets(ctx context.Context, column string, limit int) (_ []entity.StatBucket, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*)
+		FROM companies
+		WHERE %[1]s IS NOT NULL AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC, %[1]s ASC
+		LIMIT $1
+	`, column), limit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate companies by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	buckets := make([]entity.StatBucket, 0)
+	for rows.Next() {
+		var bucket entity.StatBucket
+		if err := rows.Scan(&bucket.Name, &bucket.Companies); err != nil {
+			return nil, fmt.Errorf("scan %s bucket: %w", column, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s buckets: %w", column, err)
+	}
+	return buckets, nil
+}
+
+// ScrapeRunTotals returns per-run company counts, most recent run first.
+func (r *PGXStatsRepository) ScrapeRunTotals(ctx context.Context, limit int) (_ []entity.ScrapeRunStats, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT scrape_run_id, COUNT(*), MIN(scraped_at), MAX(scraped_at)
+		FROM companies
+		WHERE scrape_run_id IS NOT NULL
+		GROUP BY scrape_run_id
+		ORDER BY MAX(scraped_at) DESC NULLS LAST, scrape_run_id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]entity.ScrapeRunStats, 0)
+	for rows.Next() {
+		var (
+			run          entity.ScrapeRunStats
+			firstScraped sql.NullTime
+			lastScraped  sql.NullTime
+		)
+		if err := rows.Scan(&run.ScrapeRunID, &run.Companies, &firstScraped, &lastScraped); err != nil {
+			return nil, fmt.Errorf("scan scrape run totals: %w", err)
+		}
+		if firstScraped.Valid {
+			val := firstScraped.Time
+			run.FirstScrapedAt = &val
+		}
+		if lastScraped.Valid {
+			val := lastScraped.Time
+			run.LastScrapedAt = &val
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scrape runs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXStatsRepository_CatalogueTotals(t *testing.T) {
+	repo := &PGXStatsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int64) = 12
+				*dest[1].(*int64) = 5
+				*dest[2].(*sql.NullFloat64) = sql.NullFloat64{Float64: 4.2, Valid: true}
+				*dest[3].(*int64) = 7
+				return nil
+			}}
+		},
+	}}
+
+	stats, err := repo.CatalogueTotals(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Companies != 12 || stats.WithoutWebsite != 5 || stats.Enriched != 7 {
+		t.Fatalf("unexpected totals: %+v", stats)
+	}
+	if stats.AverageRating == nil || *stats.AverageRating != 4.2 {
+		t.Fatalf("expected average rating, got %v", stats.AverageRating)
+	}
+}
+
+func TestPGXStatsRepository_TopCities(t *testing.T) {
+	repo := &PGXStatsRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			if !strings.Contains(query, "GROUP BY city") || args[0] != 3 {
+				t.Fatalf("unexpected query %q args %v", query, args)
+			}
+			return &stubRows{scans: []func(dest ...any) error{
+				func(dest ...any) error {
+					*dest[0].(*string) = "Jakarta"
+					*dest[1].(*int64) = 9
+					return nil
+				},
+			}}, nil
+		},
+	}}
+
+	buckets, err := repo.TopCities(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Name != "Jakarta" || buckets[0].Companies != 9 {
+		t.Fatalf("unexpected buckets: %+v", buckets)
+	}
+}
+
+func TestPGXStatsRepository_QueryTimeout(t *testing.T) {
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}}
+		},
+	}
+	repo := NewPGXStatsRepositoryWithReplica(pool, WithQueryTimeout(time.Millisecond))
+
+	if _, err := repo.CatalogueTotals(context.Background()); !errors.Is(err, ErrQueryTimeout) {
+		t.Fatalf("expected ErrQueryTimeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.CatalogueTotals(ctx); errors.Is(err, ErrQueryTimeout) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled request to keep its own error, got %v", err)
+	}
+}
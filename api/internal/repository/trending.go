@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Trending rankings accepted by TrendingCompaniesRepository.
+const (
+	TrendingByReviews = "reviews"
+	TrendingByRating  = "rating"
+)
+
+// trendsSQL pairs the latest snapshot of every company with the one before it. Its columns are named
+// apart from those of companies so listing conditions stay unambiguous once joined.
+const trendsSQL = `
+	SELECT
+		company_id,
+		observed_at,
+		previous_observed_at,
+		previous_rating::float8 AS previous_rating,
+		previous_reviews,
+		reviews - previous_reviews AS reviews_delta,
+		(rating - previous_rating)::float8 AS rating_delta
+	FROM (
+		SELECT
+			company_id,
+			observed_at,
+			rating,
+			reviews,
+			LAG(observed_at) OVER runs AS previous_observed_at,
+			LAG(rating) OVER runs AS previous_rating,
+			LAG(reviews) OVER runs AS previous_reviews,
+			ROW_NUMBER() OVER (PARTITION BY company_id ORDER BY observed_at DESC, scrape_run_id DESC) AS recency
+		FROM company_snapshots
+		WINDOW runs AS (PARTITION BY company_id ORDER BY observed_at, scrape_run_id)
+	) s
+	WHERE recency = 1 AND previous_observed_at IS NOT NULL`
+
+// TrendingCompaniesRepository ranks companies by their growth between their last two snapshots.
+type TrendingCompaniesRepository interface {
+	ListTrendingCompanies(ctx context.Context, filter dto.TrendingFilter) ([]entity.TrendingCompany, error)
+}
+
+// ListTrendingCompanies returns a page of companies matching filter whose latest snapshot is not
+// older than filter.Since, with the most review (or rating) growth first.
+func (r *PGXCompaniesRepository) ListTrendingCompanies(ctx context.Context, filter dto.TrendingFilter) (_ []entity.TrendingCompany, err error) {
+	ctx, done := r.limits.bound(ctx)
+	defer func() { err = done(err) }()
+
+	where, err := r.buildListConditions(ctx, filter.ListFilter)
+	if err != nil {
+		return nil, err
+	}
+	clauses, args, idx := where.clauses, where.args, where.next
+
+	clauses = append(clauses, fmt.Sprintf("t.observed_at >= $%d", idx))
+	args = append(args, filter.Since)
+	idx++
+	if filter.MinReviewGain != nil {
+		clauses = append(clauses, fmt.Sprintf("t.reviews_delta >= $%d", idx))
+		args = append(args, *filter.MinReviewGain)
+		idx++
+	}
+	if filter.MinRatingGain != nil {
+		clauses = append(clauses, fmt.Sprintf("t.rating_delta >= $%d", idx))
+		args = append(args, *filter.MinRatingGain)
+		idx++
+	}
+
+	order := "t.reviews_delta DESC NULLS LAST, t.rating_delta DESC NULLS LAST"
+	if filter.By == TrendingByRating {
+		order = "t.rating_delta DESC NULLS LAST, t.reviews_delta DESC NULLS LAST"
+	}
+	limit, limitArgs := listLimit(where.filter, idx)
+	query := "SELECT " + companyColumns("NULL::jsonb AS raw") + `,
+			t.previous_rating, t.previous_reviews, t.previous_observed_at, t.observed_at, t.reviews_delta, t.rating_delta
+		FROM companies
+		JOIN (` + trendsSQL + `) t ON t.company_id = companies.id` +
+		listConditions{clauses: clauses}.where() +
+		" ORDER BY " + order + ", company ASC, id" + limit
+
+	rows, err := r.reader().Query(ctx, query, append(args, limitArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("list trending companies: %w", err)
+	}
+	defer rows.Close()
+
+	trending := make([]entity.TrendingCompany, 0)
+	for rows.Next() {
+		var company entity.TrendingCompany
+		scanned, err := scanCompany(rows,
+			&company.PreviousRating,
+			&company.PreviousReviews,
+			&company.PreviousObservedAt,
+			&company.ObservedAt,
+			&company.ReviewsDelta,
+			&company.RatingDelta,
+		)
+		if err != nil {
+			return nil, err
+		}
+		company.Company = scanned
+		trending = append(trending, company)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trending companies: %w", err)
+	}
+	return trending, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+func TestPGXCompaniesRepository_ListTrendingCompanies(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, q string, a ...any) (pgx.Rows, error) {
+			query, args = q, a
+			return &stubRows{}, nil
+		},
+	}}
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	gain := 5
+
+	_, err := repo.ListTrendingCompanies(context.Background(), dto.TrendingFilter{
+		ListFilter:    dto.ListFilter{City: "Jakarta", PerPage: 10},
+		Since:         since,
+		By:            TrendingByReviews,
+		MinReviewGain: &gain,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, fragment := range []string{
+		"JOIN (",
+		"LAG(reviews) OVER runs",
+		"t.observed_at >= $2",
+		"t.reviews_delta >= $3",
+		"ORDER BY t.reviews_delta DESC NULLS LAST",
+		"LIMIT $4 OFFSET $5",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in query %q", fragment, query)
+		}
+	}
+	if len(args) != 5 || args[1] != since || args[2] != 5 {
+		t.Fatalf("unexpected args %v", args)
+	}
+
+	ratingGain := 0.2
+	if _, err := repo.ListTrendingCompanies(context.Background(), dto.TrendingFilter{Since: since, By: TrendingByRating, MinRatingGain: &ratingGain}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "t.rating_delta >= $2") || !strings.Contains(query, "ORDER BY t.rating_delta DESC NULLS LAST") {
+		t.Fatalf("expected a rating ranking, got %q", query)
+	}
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrInvitationNotFound indicates no pending, unexpired invitation matches the token.
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// UserInvitationsRepository persists invitations and turns accepted ones into users.
+type UserInvitationsRepository interface {
+	UpsertInvitation(ctx context.Context, invitation *entity.UserInvitation, tokenHash string) (bool, error)
+	AcceptInvitation(ctx context.Context, tokenHash, passwordHash string) (*entity.User, error)
+}
+
+// PGXUserInvitationsRepository implements UserInvitationsRepository using pgx.
+type PGXUserInvitationsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXUserInvitationsRepository wires a pgx backed invitations repository.
+func NewPGXUserInvitationsRepository(pool *pgxpool.Pool) *PGXUserInvitationsRepository {
+	return &PGXUserInvitationsRepository{pool: pool}
+}
+
+// UpsertInvitation stores a pending invitation and reports whether it is new. A pending invitation
+// for the same address is refreshed with the new token, role, org and expiry instead. Addresses
+// that already belong to a user are left alone and reported with ErrEmailDuplicate.
+func (r *PGXUserInvitationsRepository) UpsertInvitation(ctx context.Context, invitation *entity.UserInvitation, tokenHash string) (bool, error) {
+	if invitation == nil {
+		return false, fmt.Errorf("invitation payload is nil")
+	}
+	var inserted bool
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO user_invitations (email, role, org, token_hash, invited_by, import_job_id, expires_at)
+		SELECT $1, $2::user_role, $3, $4, $5, $6, $7
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE lower(email) = lower($1))
+		ON CONFLICT ((lower(email))) WHERE accepted_at IS NULL DO UPDATE
+		SET role = EXCLUDED.role, org = EXCLUDED.org, token_hash = EXCLUDED.token_hash,
+			invited_by = EXCLUDED.invited_by, import_job_id = EXCLUDED.import_job_id,
+			expires_at = EXCLUDED.expires_at, created_at = NOW()
+		RETURNING id, created_at, (xmax = 0)
+	`, invitation.Email, invitation.Role, invitation.Org, tokenHash, invitation.InvitedBy, invitation.ImportJobID,
+		invitation.ExpiresAt).Scan(&invitation.ID, &invitation.CreatedAt, &inserted)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrEmailDuplicate
+		}
+		return false, fmt.Errorf("upsert invitation: %w", err)
+	}
+	return inserted, nil
+}
+
+// AcceptInvitation creates the user of the pending, unexpired invitation matching tokenHash and
+// marks the invitation accepted, in one transaction.
+func (r *PGXUserInvitationsRepository) AcceptInvitation(ctx context.Context, tokenHash, passwordHash string) (*entity.User, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("start invitation acceptance tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var invitation entity.UserInvitation
+	err = tx.QueryRow(ctx, `
+		SELECT id, email, role
+		FROM user_invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, tokenHash).Scan(&invitation.ID, &invitation.Email, &invitation.Role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("query invitation: %w", err)
+	}
+
+	var user entity.User
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email, password_hash, role)
+		VALUES ($1, $2, $3)
+		RETURNING id, email, password_hash, role, created_at, updated_at
+	`, invitation.Email, passwordHash, invitation.Role).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && strings.Contains(pgErr.Message, "users_email_key") {
+			return nil, fmt.Errorf("%w: %v", ErrEmailDuplicate, pgErr)
+		}
+		return nil, fmt.Errorf("insert invited user: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_invitations SET accepted_at = NOW(), user_id = $2 WHERE id = $1
+	`, invitation.ID, user.ID); err != nil {
+		return nil, fmt.Errorf("mark invitation accepted: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit invitation acceptance: %w", err)
+	}
+	return &user, nil
+}
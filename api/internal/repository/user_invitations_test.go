@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestPGXUserInvitationsRepository_Upsert(t *testing.T) {
+	id := uuid.New()
+	var gotQuery string
+	pool := &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			gotQuery = query
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = id
+				*dest[1].(*time.Time) = time.Now()
+				*dest[2].(*bool) = true
+				return nil
+			}}
+		},
+	}
+	repo := &PGXUserInvitationsRepository{pool: pool}
+
+	invitation := &entity.UserInvitation{Email: "ana@agency.test", Role: "user", ExpiresAt: time.Now().Add(time.Hour)}
+	created, err := repo.UpsertInvitation(context.Background(), invitation, "hash")
+	if err != nil || !created || invitation.ID != id {
+		t.Fatalf("unexpected upsert outcome: created=%v id=%s err=%v", created, invitation.ID, err)
+	}
+	if !strings.Contains(gotQuery, "ON CONFLICT ((lower(email))) WHERE accepted_at IS NULL") ||
+		!strings.Contains(gotQuery, "NOT EXISTS (SELECT 1 FROM users") {
+		t.Fatalf("unexpected upsert query: %s", gotQuery)
+	}
+
+	pool.queryRowFunc = func(ctx context.Context, query string, args ...any) pgx.Row {
+		return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+	}
+	if _, err := repo.UpsertInvitation(context.Background(), invitation, "hash"); !errors.Is(err, ErrEmailDuplicate) {
+		t.Fatalf("expected ErrEmailDuplicate for existing users, got %v", err)
+	}
+}
+
+func TestPGXUserInvitationsRepository_Accept(t *testing.T) {
+	userID := uuid.New()
+	var accepted []any
+	tx := &stubTx{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if strings.Contains(query, "FROM user_invitations") {
+				return &stubRow{scan: func(dest ...any) error {
+					*dest[1].(*string) = "ana@agency.test"
+					*dest[2].(*string) = "user"
+					return nil
+				}}
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*uuid.UUID) = userID
+				*dest[1].(*string) = args[0].(string)
+				*dest[3].(*string) = args[2].(string)
+				return nil
+			}}
+		},
+		execFunc: func(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+			accepted = args
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}
+	pool := &stubPool{
+		beginTxFunc: func(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) { return tx, nil },
+	}
+	repo := &PGXUserInvitationsRepository{pool: pool}
+
+	user, err := repo.AcceptInvitation(context.Background(), "hash", "bcrypt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != userID || user.Email != "ana@agency.test" || user.Role != "user" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if len(accepted) != 2 || accepted[1] != userID || !tx.committed {
+		t.Fatalf("expected invitation to be marked accepted, got %v (committed=%v)", accepted, tx.committed)
+	}
+
+	tx.committed = false
+	tx.queryRowFunc = func(ctx context.Context, query string, args ...any) pgx.Row {
+		return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+	}
+	if _, err := repo.AcceptInvitation(context.Background(), "hash", "bcrypt"); !errors.Is(err, ErrInvitationNotFound) || tx.committed {
+		t.Fatalf("expected ErrInvitationNotFound without commit, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// ErrSessionNotFound indicates no active session matches the refresh token or id.
+var ErrSessionNotFound = errors.New("session not found")
+
+// UserSessionsRepository persists refresh token sessions.
+type UserSessionsRepository interface {
+	CreateSession(ctx context.Context, session *entity.UserSession, tokenHash string) error
+	RotateSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (*entity.UserSession, error)
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]entity.UserSession, error)
+	RevokeSession(ctx context.Context, userID, id uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, keep *uuid.UUID) (int64, error)
+}
+
+// PGXUserSessionsRepository implements UserSessionsRepository using pgx.
+type PGXUserSessionsRepository struct {
+	pool pgxPool
+}
+
+// NewPGXUserSessionsRepository wires a pgx backed sessions repository.
+func NewPGXUserSessionsRepository(pool *pgxpool.Pool) *PGXUserSessionsRepository {
+	return &PGXUserSessionsRepository{pool: pool}
+}
+
+// CreateSession stores a new session under tokenHash and fills in its id and timestamps.
+func (r *PGXUserSessionsRepository) CreateSession(ctx context.Context, session *entity.UserSession, tokenHash string) error {
+	if session == nil {
+		return fmt.Errorf("session payload is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO user_sessions (user_id, token_hash, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, last_used_at
+	`, session.UserID, tokenHash, session.UserAgent, session.IP, session.ExpiresAt).Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt)
+	if err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+	return nil
+}
+
+// RotateSession swaps the refresh token of the active session matching tokenHash for newTokenHash
+// and extends it to expiresAt, so each refresh token works once. Unknown, revoked and expired
+// tokens yield ErrSessionNotFound.
+func (r *PGXUserSessionsRepository) RotateSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (*entity.UserSession, error) {
+	var session entity.UserSession
+	err := r.pool.QueryRow(ctx, `
+		UPDATE user_sessions
+		SET token_hash = $2, expires_at = $3, last_used_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+	`, tokenHash, newTokenHash, expiresAt).Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("rotate session: %w", err)
+	}
+	return &session, nil
+}
+
+// ListActiveSessions returns the user's unrevoked, unexpired sessions, most recently used first.
+func (r *PGXUserSessionsRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]entity.UserSession, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]entity.UserSession, 0)
+	for rows.Next() {
+		var session entity.UserSession
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt,
+			&session.LastUsedAt, &session.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's active sessions. Sessions of other users are reported
+// with ErrSessionNotFound like unknown ones.
+func (r *PGXUserSessionsRepository) RevokeSession(ctx context.Context, userID, id uuid.UUID) error {
+	cmd, err := r.pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes every active session of the user except keep, when set, and returns
+// how many were revoked.
+func (r *PGXUserSessionsRepository) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, keep *uuid.UUID) (int64, error) {
+	cmd, err := r.pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND ($2::uuid IS NULL OR id <> $2)
+	`, userID, keep)
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	return cmd.RowsAffected(), nil
+}
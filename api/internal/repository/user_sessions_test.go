@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestPGXUserSessionsRepository_RotateSession(t *testing.T) {
+	sessionID := uuid.New()
+	var captured []any
+	repo := &PGXUserSessionsRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			captured = args
+			return &stubRow{scan: func(dest ...any) error {
+				*(dest[0].(*uuid.UUID)) = sessionID
+				return nil
+			}}
+		},
+	}}
+
+	session, err := repo.RotateSession(context.Background(), "old", "new", time.Now())
+	if err != nil || session.ID != sessionID {
+		t.Fatalf("unexpected rotation %+v (%v)", session, err)
+	}
+	if captured[0] != "old" || captured[1] != "new" {
+		t.Fatalf("unexpected args %v", captured)
+	}
+
+	repo.pool = &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}
+	if _, err := repo.RotateSession(context.Background(), "used", "new", time.Now()); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestPGXUserSessionsRepository_Revoke(t *testing.T) {
+	var query string
+	repo := &PGXUserSessionsRepository{pool: &stubPool{
+		execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+			query = sql
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		},
+	}}
+	if err := repo.RevokeSession(context.Background(), uuid.New(), uuid.New()); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	if !strings.Contains(query, "user_id = $2") {
+		t.Fatalf("expected revocation to be scoped to the user: %s", query)
+	}
+
+	repo.pool = &stubPool{
+		execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+			return pgconn.NewCommandTag("UPDATE 2"), nil
+		},
+	}
+	keep := uuid.New()
+	if revoked, err := repo.RevokeOtherSessions(context.Background(), uuid.New(), &keep); err != nil || revoked != 2 {
+		t.Fatalf("expected two revoked sessions, got %d (%v)", revoked, err)
+	}
+}
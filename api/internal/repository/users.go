@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
 )
 
@@ -25,7 +26,8 @@ type UsersRepository interface {
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
 	Create(ctx context.Context, email, passwordHash, role string) (*entity.User, error)
-	List(ctx context.Context) ([]entity.User, error)
+	List(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error)
+	Count(ctx context.Context, filter dto.UserListFilter) (int64, error)
 	Update(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -90,18 +92,47 @@ func (r *PGXUsersRepository) Create(ctx context.Context, email, passwordHash, ro
 	return &user, nil
 }
 
-// List returns all users ordered by creation date (desc).
-func (r *PGXUsersRepository) List(ctx context.Context) ([]entity.User, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, email, password_hash, role, created_at, updated_at FROM users ORDER BY created_at DESC`)
+// List returns up to filter.Limit users matching the filter, after filter.After in the filter's
+// sort order. Rows are read into the summary projection; the password hash is never selected.
+func (r *PGXUsersRepository) List(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
+	column := "created_at"
+	if filter.Sort == dto.UserSortEmail {
+		column = "email"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	clauses, args := userListConditions(filter)
+	if filter.After != nil {
+		var key any = filter.After.CreatedAt
+		if column == "email" {
+			key = filter.After.Email
+		}
+		clauses = append(clauses, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, comparison, len(args)+1, len(args)+2))
+		args = append(args, key, filter.After.ID)
+	}
+	query := `SELECT id, email, role, created_at, updated_at FROM users`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []entity.User
+	users := make([]entity.UserSummary, 0)
 	for rows.Next() {
-		var user entity.User
-		if err := rows.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		var user entity.UserSummary
+		if err := rows.Scan(&user.ID, &user.Email, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan user row: %w", err)
 		}
 		users = append(users, user)
@@ -112,6 +143,42 @@ func (r *PGXUsersRepository) List(ctx context.Context) ([]entity.User, error) {
 	return users, nil
 }
 
+// Count returns how many users match the filter, ignoring its cursor and limit.
+func (r *PGXUsersRepository) Count(ctx context.Context, filter dto.UserListFilter) (int64, error) {
+	clauses, args := userListConditions(filter)
+	query := `SELECT COUNT(*) FROM users`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	var total int64
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count users: %w", err)
+	}
+	return total, nil
+}
+
+// userListConditions builds the WHERE clauses shared by List and Count.
+func userListConditions(filter dto.UserListFilter) ([]string, []any) {
+	var (
+		clauses []string
+		args    []any
+	)
+	if filter.Email != "" {
+		args = append(args, "%"+escapeLike(filter.Email)+"%")
+		clauses = append(clauses, fmt.Sprintf(`email ILIKE $%d ESCAPE '\'`, len(args)))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		clauses = append(clauses, fmt.Sprintf("role = $%d", len(args)))
+	}
+	return clauses, args
+}
+
+// escapeLike makes LIKE wildcards in s match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // Update patches user attributes.
 func (r *PGXUsersRepository) Update(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error) {
 	setClauses := make([]string, 0)
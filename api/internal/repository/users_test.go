@@ -3,12 +3,16 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
 )
 
 type stubPool struct {
@@ -163,20 +167,24 @@ func TestPGXUsersRepository_Create(t *testing.T) {
 }
 
 func TestPGXUsersRepository_List(t *testing.T) {
+	var (
+		capturedQuery string
+		capturedArgs  []any
+	)
 	repo := &PGXUsersRepository{pool: &stubPool{
 		queryFunc: func(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+			capturedQuery, capturedArgs = query, args
 			return &stubRows{
 				scans: []func(dest ...any) error{
 					func(dest ...any) error {
-						id := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
-						created := time.Now()
-						updated := created
-						*dest[0].(*uuid.UUID) = id
+						if len(dest) != 5 {
+							t.Fatalf("expected the summary projection, got %d columns", len(dest))
+						}
+						*dest[0].(*uuid.UUID) = uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
 						*dest[1].(*string) = "admin@example.com"
-						*dest[2].(*string) = "hash"
-						*dest[3].(*string) = "admin"
-						*dest[4].(*time.Time) = created
-						*dest[5].(*time.Time) = updated
+						*dest[2].(*string) = "admin"
+						*dest[3].(*time.Time) = time.Now()
+						*dest[4].(*time.Time) = time.Now()
 						return nil
 					},
 				},
@@ -184,13 +192,51 @@ func TestPGXUsersRepository_List(t *testing.T) {
 		},
 	}}
 
-	rows, err := repo.List(context.Background())
+	after := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	rows, err := repo.List(context.Background(), dto.UserListFilter{
+		Email: "50%_off",
+		Role:  "admin",
+		Sort:  dto.UserSortEmail,
+		Desc:  true,
+		After: &entity.UserCursor{Email: "m@example.com", ID: after},
+		Limit: 11,
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if len(rows) != 1 || rows[0].Email != "admin@example.com" {
 		t.Fatalf("unexpected rows: %+v", rows)
 	}
+	if strings.Contains(capturedQuery, "password_hash") {
+		t.Fatalf("expected the listing not to select password hashes: %s", capturedQuery)
+	}
+	for _, fragment := range []string{"email ILIKE $1", "role = $2", "(email, id) < ($3, $4)", "ORDER BY email DESC, id DESC LIMIT $5"} {
+		if !strings.Contains(capturedQuery, fragment) {
+			t.Fatalf("expected %q in query: %s", fragment, capturedQuery)
+		}
+	}
+	if capturedArgs[0] != `%50\%\_off%` || capturedArgs[2] != "m@example.com" || capturedArgs[3] != after || capturedArgs[4] != 11 {
+		t.Fatalf("unexpected args: %v", capturedArgs)
+	}
+}
+
+func TestPGXUsersRepository_Count(t *testing.T) {
+	repo := &PGXUsersRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			if query != "SELECT COUNT(*) FROM users WHERE role = $1" || args[0] != "user" {
+				t.Fatalf("unexpected count query %s %v", query, args)
+			}
+			return &stubRow{scan: func(dest ...any) error {
+				*dest[0].(*int64) = 42
+				return nil
+			}}
+		},
+	}}
+
+	total, err := repo.Count(context.Background(), dto.UserListFilter{Role: "user", After: &entity.UserCursor{ID: uuid.New()}, Limit: 5})
+	if err != nil || total != 42 {
+		t.Fatalf("unexpected count %d (%v)", total, err)
+	}
 }
 
 func TestPGXUsersRepository_Update(t *testing.T) {
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+)
+
+// ErrWarehouseExportNotFound indicates no warehouse export has completed yet.
+var ErrWarehouseExportNotFound = errors.New("warehouse export not found")
+
+// WarehouseRepository reads export rows and records completed warehouse partitions.
+type WarehouseRepository interface {
+	ListExportRowsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.WarehouseRow, uuid.UUID, error)
+	RecordExport(ctx context.Context, export *entity.WarehouseExport) error
+	LatestExport(ctx context.Context) (*entity.WarehouseExport, error)
+}
+
+// PGXWarehouseRepository implements WarehouseRepository using pgx.
+type PGXWarehouseRepository struct {
+	pool   pgxPool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPGXWarehouseRepository wires a pgx backed warehouse repository; WithEnrichmentCipher decrypts
+// the enrichment contacts it exports.
+func NewPGXWarehouseRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PGXWarehouseRepository {
+	return &PGXWarehouseRepository{pool: pool, cipher: newRepositoryOptions(opts).cipher}
+}
+
+// ListExportRowsAfter pages through companies joined with their enrichment and lead score,
+// ordered by company id. It returns the id of the last row so callers can resume after it.
+func (r *PGXWarehouseRepository) ListExportRowsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.WarehouseRow, uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			c.id,
+			c.place_id,
+			c.company,
+			c.phone,
+			c.website,
+			c.rating,
+			c.reviews,
+			c.type_business,
+			c.address,
+			c.city,
+			c.country,
+			CASE WHEN c.location IS NOT NULL THEN ST_X(c.location::geometry) END,
+			CASE WHEN c.location IS NOT NULL THEN ST_Y(c.location::geometry) END,
+			c.scrape_run_id::text,
+			c.scraped_at,
+			c.created_at,
+			c.updated_at,
+			c.chain_id::text,
+			c.size_bucket,
+			c.business_status,
+			e.emails,
+			e.phones,
+			e.socials::text,
+			e.contact_form_url,
+			e.about_summary,
+			e.updated_at,
+			s.score,
+			s.breakdown::text,
+			s.computed_at
+		FROM companies c
+		LEFT JOIN company_enrichments e ON e.company_id = c.id
+		LEFT JOIN company_lead_scores s ON s.company_id = c.id
+		WHERE c.id > $1
+		ORDER BY c.id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, after, fmt.Errorf("list export rows: %w", err)
+	}
+	defer rows.Close()
+
+	last := after
+	records := make([]entity.WarehouseRow, 0, limit)
+	for rows.Next() {
+		var (
+			record         entity.WarehouseRow
+			id             uuid.UUID
+			placeID        sql.NullString
+			phone          sql.NullString
+			website        sql.NullString
+			rating         sql.NullFloat64
+			reviews        sql.NullInt64
+			typeBusiness   sql.NullString
+			address        sql.NullString
+			city           sql.NullString
+			country        sql.NullString
+			longitude      sql.NullFloat64
+			latitude       sql.NullFloat64
+			scrapeRunID    sql.NullString
+			scrapedAt      sql.NullTime
+			chainID        sql.NullString
+			sizeBucket     sql.NullString
+			businessStatus sql.NullString
+			socials        sql.NullString
+			contactForm    sql.NullString
+			aboutSummary   sql.NullString
+			enrichedAt     sql.NullTime
+			score          sql.NullInt64
+			breakdown      sql.NullString
+			scoredAt       sql.NullTime
+		)
+		err := rows.Scan(
+			&id,
+			&placeID,
+			&record.Company,
+			&phone,
+			&website,
+			&rating,
+			&reviews,
+			&typeBusiness,
+			&address,
+			&city,
+			&country,
+			&longitude,
+			&latitude,
+			&scrapeRunID,
+			&scrapedAt,
+			&record.CreatedAt,
+			&record.UpdatedAt,
+			&chainID,
+			&sizeBucket,
+			&businessStatus,
+			&record.Emails,
+			&record.EnrichedPhones,
+			&socials,
+			&contactForm,
+			&aboutSummary,
+			&enrichedAt,
+			&score,
+			&breakdown,
+			&scoredAt,
+		)
+		if err != nil {
+			return nil, after, fmt.Errorf("scan export row: %w", err)
+		}
+		if record.Emails, err = r.cipher.DecryptAll(record.Emails); err != nil {
+			return nil, after, fmt.Errorf("decrypt emails of %s: %w", id, err)
+		}
+		if record.EnrichedPhones, err = r.cipher.DecryptAll(record.EnrichedPhones); err != nil {
+			return nil, after, fmt.Errorf("decrypt phones of %s: %w", id, err)
+		}
+
+		record.ID = id.String()
+		record.PlaceID = nullStringToPtr(placeID)
+		record.Phone = nullStringToPtr(phone)
+		record.Website = nullStringToPtr(website)
+		record.Rating = nullFloatToPtr(rating)
+		record.Reviews = nullInt64ToPtr(reviews)
+		record.TypeBusiness = nullStringToPtr(typeBusiness)
+		record.Address = nullStringToPtr(address)
+		record.City = nullStringToPtr(city)
+		record.Country = nullStringToPtr(country)
+		record.Longitude = nullFloatToPtr(longitude)
+		record.Latitude = nullFloatToPtr(latitude)
+		record.ScrapeRunID = nullStringToPtr(scrapeRunID)
+		record.ScrapedAt = scrapedAt.Time
+		record.ChainID = nullStringToPtr(chainID)
+		record.SizeBucket = nullStringToPtr(sizeBucket)
+		record.BusinessStatus = nullStringToPtr(businessStatus)
+		record.Socials = nullStringToPtr(socials)
+		record.ContactFormURL = nullStringToPtr(contactForm)
+		record.AboutSummary = nullStringToPtr(aboutSummary)
+		record.EnrichedAt = enrichedAt.Time
+		record.LeadScore = nullInt64ToPtr(score)
+		record.ScoreBreakdown = nullStringToPtr(breakdown)
+		record.ScoreComputedAt = scoredAt.Time
+
+		records = append(records, record)
+		last = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, after, fmt.Errorf("iterate export rows: %w", err)
+	}
+	return records, last, nil
+}
+
+// RecordExport stores a completed partition, replacing any earlier export of the same date.
+func (r *PGXWarehouseRepository) RecordExport(ctx context.Context, export *entity.WarehouseExport) error {
+	if export == nil {
+		return errors.New("warehouse export is nil")
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO warehouse_exports (partition_date, location, files, row_count, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (partition_date) DO UPDATE SET
+			location = EXCLUDED.location,
+			files = EXCLUDED.files,
+			row_count = EXCLUDED.row_count,
+			started_at = EXCLUDED.started_at,
+			completed_at = NOW()
+		RETURNING id, completed_at
+	`, export.PartitionDate, export.Location, stringSliceOrEmpty(export.Files), export.RowCount, export.StartedAt).Scan(&export.ID, &export.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("record warehouse export: %w", err)
+	}
+	return nil
+}
+
+// LatestExport returns the most recent completed partition.
+func (r *PGXWarehouseRepository) LatestExport(ctx context.Context) (*entity.WarehouseExport, error) {
+	var export entity.WarehouseExport
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, partition_date, location, files, row_count, started_at, completed_at
+		FROM warehouse_exports
+		ORDER BY partition_date DESC
+		LIMIT 1
+	`).Scan(
+		&export.ID,
+		&export.PartitionDate,
+		&export.Location,
+		&export.Files,
+		&export.RowCount,
+		&export.StartedAt,
+		&export.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWarehouseExportNotFound
+		}
+		return nil, fmt.Errorf("fetch latest warehouse export: %w", err)
+	}
+	return &export, nil
+}
+
+func nullFloatToPtr(value sql.NullFloat64) *float64 {
+	if value.Valid {
+		val := value.Float64
+		return &val
+	}
+	return nil
+}
+
+func nullInt64ToPtr(value sql.NullInt64) *int64 {
+	if value.Valid {
+		val := value.Int64
+		return &val
+	}
+	return nil
+}
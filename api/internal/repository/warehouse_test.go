@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPGXWarehouseRepository_LatestExport_NotFound(t *testing.T) {
+	repo := &PGXWarehouseRepository{pool: &stubPool{
+		queryRowFunc: func(ctx context.Context, query string, args ...any) pgx.Row {
+			return &stubRow{scan: func(dest ...any) error { return pgx.ErrNoRows }}
+		},
+	}}
+
+	if _, err := repo.LatestExport(context.Background()); !errors.Is(err, ErrWarehouseExportNotFound) {
+		t.Fatalf("expected ErrWarehouseExportNotFound, got %v", err)
+	}
+}
+
+func TestPGXWarehouseRepository_RecordExportValidation(t *testing.T) {
+	repo := &PGXWarehouseRepository{}
+	if err := repo.RecordExport(context.Background(), nil); err == nil {
+		t.Fatalf("expected error for nil export")
+	}
+}
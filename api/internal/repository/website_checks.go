@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// WebsiteCheckRepository reads the company websites due for a liveness check and stores the
+// outcome.
+type WebsiteCheckRepository interface {
+	ListWebsitesDue(ctx context.Context, checkedBefore time.Time, limit int) ([]entity.CompanyWebsite, error)
+	SaveWebsiteCheck(ctx context.Context, website entity.CompanyWebsite, check entity.WebsiteCheck) error
+}
+
+// PGXWebsiteCheckRepository implements WebsiteCheckRepository using pgx.
+type PGXWebsiteCheckRepository struct {
+	pool pgxPool
+}
+
+// NewPGXWebsiteCheckRepository wires a pgx backed website check repository.
+func NewPGXWebsiteCheckRepository(pool *pgxpool.Pool) *PGXWebsiteCheckRepository {
+	return &PGXWebsiteCheckRepository{pool: pool}
+}
+
+// ListWebsitesDue returns the websites never checked, then those last checked before
+// checkedBefore, oldest check first.
+func (r *PGXWebsiteCheckRepository) ListWebsitesDue(ctx context.Context, checkedBefore time.Time, limit int) ([]entity.CompanyWebsite, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, website FROM companies
+		WHERE website IS NOT NULL AND (website_checked_at IS NULL OR website_checked_at < $1)
+		ORDER BY website_checked_at NULLS FIRST, id
+		LIMIT $2
+	`, checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list websites due: %w", err)
+	}
+	defer rows.Close()
+
+	var websites []entity.CompanyWebsite
+	for rows.Next() {
+		var website entity.CompanyWebsite
+		if err := rows.Scan(&website.CompanyID, &website.Website); err != nil {
+			return nil, fmt.Errorf("scan website due: %w", err)
+		}
+		websites = append(websites, website)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate websites due: %w", err)
+	}
+	return websites, nil
+}
+
+// SaveWebsiteCheck stores the outcome of a check. A company deleted or given another website while
+// it was checked is left alone, since the outcome no longer describes it.
+func (r *PGXWebsiteCheckRepository) SaveWebsiteCheck(ctx context.Context, website entity.CompanyWebsite, check entity.WebsiteCheck) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE companies
+		SET website_status = $3, website_http_status = $4, website_redirects_to = $5, website_checked_at = $6
+		WHERE id = $1 AND website = $2
+	`, website.CompanyID, website.Website, check.Status, check.HTTPStatus, check.RedirectsTo, check.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("save website check: %w", err)
+	}
+	return nil
+}
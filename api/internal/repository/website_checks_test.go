@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+func TestPGXCompaniesRepository_ListWebsiteStatusFilter(t *testing.T) {
+	var queries []string
+	repo := &PGXCompaniesRepository{pool: &stubPool{
+		queryFunc: func(ctx context.Context, query string, a ...any) (pgx.Rows, error) {
+			queries = append(queries, query)
+			return &stubRows{}, nil
+		},
+	}}
+
+	for _, statuses := range [][]string{{"dead", "parked"}, {"dead", "unchecked"}} {
+		if _, err := repo.List(context.Background(), dto.ListFilter{WebsiteLiveness: statuses}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !strings.Contains(queries[0], "WHERE website_status = ANY($1)") || strings.Contains(queries[0], "website_status IS NULL") {
+		t.Fatalf("unexpected query %q", queries[0])
+	}
+	if !strings.Contains(queries[1], "(website_status = ANY($1) OR (website IS NOT NULL AND website_status IS NULL))") {
+		t.Fatalf("expected unchecked to match websites not checked yet, got %q", queries[1])
+	}
+}
+
+func TestUpsertResetsWebsiteCheckOfChangedWebsites(t *testing.T) {
+	overwrite, _ := bulkUpsertSQL(ImportModeOverwrite)
+	for _, query := range []string{upsertCompanySQL, overwrite} {
+		if !strings.Contains(query, "website_checked_at = CASE WHEN companies.website IS DISTINCT FROM EXCLUDED.website THEN NULL") {
+			t.Fatalf("expected a changed website to be checked again, got %q", query)
+		}
+	}
+}
@@ -6,54 +6,321 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/captcha"
 	"github.com/octobees/leads-generator/api/internal/config"
+	"github.com/octobees/leads-generator/api/internal/entity"
 	"github.com/octobees/leads-generator/api/internal/handler"
 	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
 )
 
 // Handlers aggregates HTTP handlers used by the router.
 type Handlers struct {
-	Auth        *handler.AuthHandler
-	Users       *handler.UserAdminHandler
-	Companies   *handler.CompaniesHandler
-	AdminUpload *handler.AdminUploadHandler
-	Scrape      *handler.ScrapeHandler
-	Enrich      *handler.EnrichHandler
-	EnrichJob   *handler.EnrichWorkerHandler
-	Prompt      *handler.PromptSearchHandler
+	Auth         *handler.AuthHandler
+	Users        *handler.UserAdminHandler
+	Companies    *handler.CompaniesHandler
+	AdminUpload  *handler.AdminUploadHandler
+	Scrape       *handler.ScrapeHandler
+	Enrich       *handler.EnrichHandler
+	EnrichJob    *handler.EnrichWorkerHandler
+	Prompt       *handler.PromptSearchHandler
+	Health       *handler.HealthHandler
+	Chains       *handler.ChainsHandler
+	Reports      *handler.ReportsHandler
+	Stats        *handler.StatsHandler
+	Warehouse    *handler.WarehouseHandler
+	Changes      *handler.ChangesHandler
+	PromptAlias  *handler.PromptAliasHandler
+	Locations    *handler.LocationsHandler
+	Categories   *handler.CategoriesHandler
+	Imports      *handler.ImportJobsHandler
+	Attachments  *handler.AttachmentsHandler
+	DNSCache     *handler.DNSCacheHandler
+	QueryCache   *handler.QueryCacheHandler
+	Scoring      *handler.ScoringProfilesHandler
+	Freshness    *handler.FreshnessHandler
+	Ingest       *handler.IngestRunsHandler
+	Recompute    *handler.ScoreRecomputeHandler
+	Collisions   *handler.ContactCollisionsHandler
+	Invites      *handler.InvitationsHandler
+	Features     *handler.FeatureTogglesHandler
+	Account      *handler.AccountHandler
+	Privacy      *handler.PrivacyHandler
+	Campaigns    *handler.CampaignsHandler
+	Suppressions *handler.SuppressionsHandler
+	Reprocess    *handler.RawReprocessHandler
+	Overview     *handler.AdminOverviewHandler
+	Jobs         *handler.AdminJobsHandler
+	DeadLetters  *handler.DeadLettersHandler
+	Maintenance  *handler.AdminMaintenanceHandler
+	APIKeys      *handler.APIKeysHandler
+	PlaceRefresh *handler.PlaceRefreshHandler
+	Schedules    *handler.ExportSchedulesHandler
+	LeadReports  *handler.LeadReportsHandler
 }
 
 // Register wires all HTTP routes for the API.
 func Register(e *echo.Echo, cfg *config.Config, jwtManager *auth.JWTManager, handlers Handlers) {
-	e.GET("/healthz", func(c echo.Context) error {
-		return handler.Success(c, http.StatusOK, "service healthy", map[string]any{"status": "ok"})
-	})
+	if handlers.Health != nil {
+		e.GET("/healthz", handlers.Health.Live)
+		e.GET("/readyz", handlers.Health.Ready)
+	} else {
+		e.GET("/healthz", func(c echo.Context) error {
+			return handler.Success(c, http.StatusOK, "service healthy", map[string]any{"status": "ok"})
+		})
+	}
 
-	e.POST("/auth/register", handlers.Auth.Register)
-	e.POST("/auth/login", handlers.Auth.Login)
-	e.GET("/companies", handlers.Companies.List)
+	// Login and register share one per-IP budget so attempts cannot be spread across both.
+	authGuards := []echo.MiddlewareFunc{middlewarepkg.IPRateLimiter(cfg.RateLimitAuth)}
+	if cfg.Captcha.Enabled() {
+		verifier := captcha.NewSiteVerifier(captcha.Endpoints[cfg.Captcha.Provider], cfg.Captcha.Secret, nil)
+		authGuards = append(authGuards, middlewarepkg.Captcha(verifier))
+	}
+	e.POST("/auth/register", handlers.Auth.Register, authGuards...)
+	e.POST("/auth/login", handlers.Auth.Login, authGuards...)
+	if handlers.Invites != nil {
+		e.POST("/auth/invitations/accept", handlers.Invites.Accept, authGuards...)
+	}
+	e.POST("/auth/refresh", handlers.Auth.Refresh, middlewarepkg.IPRateLimiter(cfg.RateLimitAuth))
+	if handlers.Account != nil {
+		e.POST("/auth/email/verify", handlers.Account.VerifyEmail, authGuards...)
+	}
+	// Listings, exports and worker jobs spend one weighted budget per caller; the public listings
+	// authenticate first so signed-in callers spend their own budget rather than their IP's.
+	budget := middlewarepkg.NewRateLimiter(cfg.RateLimitBudget, middlewarepkg.CallerKey)
+	e.GET("/companies", handlers.Companies.List, middlewarepkg.OptionalJWT(jwtManager), budget.Limit(middlewarepkg.OperationList), handler.ValidateQuery(handler.CompanyListQueryRules...))
+	e.GET("/companies/trending", handlers.Companies.Trending, middlewarepkg.OptionalJWT(jwtManager), budget.Limit(middlewarepkg.OperationList), handler.ValidateQuery(handler.TrendingQueryRules...))
+	e.GET("/companies/suggest", handlers.Companies.Suggest, middlewarepkg.OptionalJWT(jwtManager), budget.Limit(middlewarepkg.OperationList), handler.ValidateQuery(handler.SuggestQueryRules...))
+	e.GET("/companies/:id/raw", handlers.Companies.Raw)
+	e.GET("/companies/:id/history", handlers.Companies.History)
+	if handlers.Stats != nil {
+		e.GET("/stats", handlers.Stats.Get)
+	}
+	if handlers.Freshness != nil {
+		e.GET("/freshness", handlers.Freshness.Get)
+	}
+	// Data providers authenticate with an API key; each key has its own budget.
+	if handlers.APIKeys != nil {
+		e.POST("/companies/batch", handlers.Companies.Batch, handlers.APIKeys.Guard(entity.APIKeyScopeCompaniesWrite), middlewarepkg.APIKeyRateLimiter(cfg.RateLimitAPIKey))
+	}
+	if handlers.Chains != nil {
+		e.GET("/chains", handlers.Chains.List)
+		e.GET("/chains/:id", handlers.Chains.Rollup)
+	}
 
+	// Worker callbacks carry the token of the job they report on; the signer derives the same key
+	// as the one the handlers issue tokens with.
+	callbacks := auth.NewCallbackSigner(cfg.JWTSecret, cfg.CallbackTTL)
 	if handlers.Enrich != nil {
-		e.POST("/enrich-result", handlers.Enrich.SaveResult)
+		e.POST("/enrich-result", handlers.Enrich.SaveResult, middlewarepkg.WorkerCallback(callbacks, auth.CallbackEnrich))
 		e.GET("/enrich-result/:company_id", handlers.Enrich.GetResult)
 	}
+	if handlers.Ingest != nil {
+		ingest := e.Group("/ingest/runs", middlewarepkg.WorkerCallback(callbacks, auth.CallbackScrape))
+		ingest.POST("", handlers.Ingest.Start)
+		ingest.GET("/:id", handlers.Ingest.Get)
+		ingest.POST("/:id/batches", handlers.Ingest.AppendBatch)
+		ingest.POST("/:id/finish", handlers.Ingest.Finish)
+	}
+
+	// Campaign recipients follow these links without an account; the token identifies the message.
+	if handlers.Campaigns != nil {
+		e.GET("/campaigns/open/:token", handlers.Campaigns.Open)
+		e.GET("/campaigns/unsubscribe/:token", handlers.Campaigns.UnsubscribePage)
+		e.POST("/campaigns/unsubscribe/:token", handlers.Campaigns.Unsubscribe)
+		e.POST("/campaigns/webhooks/:provider", handlers.Campaigns.Webhook)
+	}
 
 	secured := e.Group("")
 	secured.Use(middlewarepkg.JWT(jwtManager))
 
+	secured.GET("/exports/companies", handlers.Companies.Export, budget.Limit(middlewarepkg.OperationExport), handler.ValidateQuery(handler.CompanyListQueryRules...))
+	secured.GET("/exports/templates", handlers.Companies.ListExportTemplates)
+	secured.POST("/exports/templates", handlers.Companies.CreateExportTemplate)
+	secured.PUT("/exports/templates/:id", handlers.Companies.UpdateExportTemplate)
+	secured.DELETE("/exports/templates/:id", handlers.Companies.DeleteExportTemplate)
+	if handlers.Schedules != nil {
+		secured.GET("/export-schedules", handlers.Schedules.List)
+		secured.POST("/export-schedules", handlers.Schedules.Create)
+		secured.PUT("/export-schedules/:id", handlers.Schedules.Update)
+		secured.DELETE("/export-schedules/:id", handlers.Schedules.Delete)
+		secured.GET("/export-schedules/:id/deliveries", handlers.Schedules.Deliveries)
+		secured.POST("/export-schedules/:id/run", handlers.Schedules.Run)
+	}
+	if handlers.LeadReports != nil {
+		secured.GET("/companies/:id/report.pdf", handlers.LeadReports.Company, budget.Limit(middlewarepkg.OperationExport))
+		secured.POST("/reports", handlers.LeadReports.Batch, budget.Limit(middlewarepkg.OperationExport))
+	}
+	if handlers.Account != nil {
+		secured.GET("/me", handlers.Account.Get)
+		secured.PATCH("/me", handlers.Account.Update)
+		secured.GET("/me/sessions", handlers.Account.Sessions)
+		secured.DELETE("/me/sessions/:id", handlers.Account.RevokeSession)
+	}
+	secured.PATCH("/companies/:id/assign", handlers.Companies.Assign)
+	secured.GET("/leads/no-website", handlers.Companies.NoWebsiteLeads, handler.ValidateQuery(handler.NoWebsiteLeadsQueryRules...))
+	secured.GET("/exports/leads/no-website", handlers.Companies.ExportNoWebsiteLeads, budget.Limit(middlewarepkg.OperationExport), handler.ValidateQuery(handler.NoWebsiteLeadsQueryRules...))
+	if handlers.Locations != nil {
+		secured.GET("/locations", handlers.Locations.Search)
+	}
+	if handlers.Categories != nil {
+		secured.GET("/categories", handlers.Categories.List)
+	}
+
 	admin := secured.Group("/admin", middlewarepkg.RequireRole("admin"))
-	admin.GET("/companies", handlers.Companies.ListAdmin)
+	admin.GET("/companies", handlers.Companies.ListAdmin, handler.ValidateQuery(handler.CompanyListQueryRules...))
+	admin.GET("/companies/explain", handlers.Companies.Explain, handler.ValidateQuery(handler.CompanyListQueryRules...))
+	admin.POST("/companies/assign", handlers.Companies.BulkAssign)
+	if handlers.PlaceRefresh != nil {
+		admin.POST("/companies/:id/refresh", handlers.PlaceRefresh.Refresh)
+	}
 	admin.POST("/upload-csv", handlers.AdminUpload.UploadCSV)
+	admin.GET("/uploads", handlers.AdminUpload.ListUploads)
+	admin.GET("/uploads/:id", handlers.AdminUpload.GetUpload)
+	admin.GET("/uploads/:id/download", handlers.AdminUpload.DownloadUpload)
 	admin.GET("/users", handlers.Users.List)
 	admin.POST("/users", handlers.Users.Create)
 	admin.PATCH("/users/:id", handlers.Users.Update)
 	admin.DELETE("/users/:id", handlers.Users.Delete)
+	if handlers.Chains != nil {
+		admin.POST("/chains/detect", handlers.Chains.Detect)
+	}
+	if handlers.Imports != nil {
+		admin.POST("/imports", handlers.Imports.Create)
+		admin.GET("/imports", handlers.Imports.List)
+		admin.GET("/imports/:id", handlers.Imports.Get)
+		admin.POST("/users/import", handlers.Imports.CreateUserImport)
+	}
+	if handlers.PromptAlias != nil {
+		admin.GET("/prompt-aliases", handlers.PromptAlias.List)
+		admin.POST("/prompt-aliases", handlers.PromptAlias.Create)
+		admin.PUT("/prompt-aliases/:id", handlers.PromptAlias.Update)
+		admin.DELETE("/prompt-aliases/:id", handlers.PromptAlias.Delete)
+	}
+	if handlers.Locations != nil {
+		admin.POST("/locations", handlers.Locations.Create)
+		admin.PUT("/locations/:id", handlers.Locations.Update)
+		admin.DELETE("/locations/:id", handlers.Locations.Delete)
+	}
+	if handlers.Categories != nil {
+		admin.POST("/categories", handlers.Categories.Create)
+		admin.PUT("/categories/:id", handlers.Categories.Update)
+		admin.DELETE("/categories/:id", handlers.Categories.Delete)
+	}
+	if handlers.Warehouse != nil {
+		admin.GET("/exports/companies", handlers.Warehouse.Export)
+		admin.GET("/exports/warehouse/manifest", handlers.Warehouse.Manifest)
+	}
+	if handlers.Scoring != nil {
+		admin.GET("/scoring-profiles", handlers.Scoring.List)
+		admin.POST("/scoring-profiles", handlers.Scoring.Create)
+		admin.PUT("/scoring-profiles/:id", handlers.Scoring.Update)
+		admin.DELETE("/scoring-profiles/:id", handlers.Scoring.Delete)
+		admin.POST("/scoring-profiles/:id/activate", handlers.Scoring.Activate)
+	}
+	if handlers.Recompute != nil {
+		admin.POST("/scores/recompute", handlers.Recompute.Create)
+		admin.GET("/scores/recompute", handlers.Recompute.List)
+		admin.GET("/scores/recompute/:id", handlers.Recompute.Get)
+	}
+	if handlers.Reprocess != nil {
+		admin.POST("/companies/reprocess-raw", handlers.Reprocess.Create)
+		admin.GET("/companies/reprocess-raw", handlers.Reprocess.List)
+		admin.GET("/companies/reprocess-raw/:id", handlers.Reprocess.Get)
+	}
+	if handlers.DNSCache != nil {
+		admin.GET("/metrics/dns-cache", handlers.DNSCache.Stats)
+	}
+	if handlers.QueryCache != nil {
+		admin.GET("/metrics/query-cache", handlers.QueryCache.Stats)
+	}
+	if handlers.Overview != nil {
+		admin.GET("/overview", handlers.Overview.Get)
+	}
+	if handlers.Jobs != nil {
+		admin.GET("/jobs", handlers.Jobs.List)
+		admin.POST("/jobs/:id/retry", handlers.Jobs.Retry)
+	}
+	if handlers.DeadLetters != nil {
+		admin.GET("/dead-letters", handlers.DeadLetters.List)
+		admin.GET("/dead-letters/:id", handlers.DeadLetters.Get)
+		admin.PUT("/dead-letters/:id", handlers.DeadLetters.Update)
+		admin.POST("/dead-letters/:id/replay", handlers.DeadLetters.Replay)
+	}
+	if handlers.Maintenance != nil {
+		admin.POST("/maintenance/reconcile", handlers.Maintenance.Reconcile)
+	}
+	if handlers.APIKeys != nil {
+		admin.GET("/api-keys", handlers.APIKeys.List)
+		admin.POST("/api-keys", handlers.APIKeys.Create)
+		admin.DELETE("/api-keys/:id", handlers.APIKeys.Revoke)
+	}
+	if handlers.Collisions != nil {
+		admin.POST("/contacts/collisions/detect", handlers.Collisions.Detect)
+	}
+	if handlers.Features != nil {
+		admin.GET("/features", handlers.Features.List)
+		admin.PUT("/features/:feature", handlers.Features.Update)
+	}
+	if handlers.Privacy != nil {
+		admin.POST("/privacy/erase", handlers.Privacy.Erase)
+		admin.POST("/privacy/export", handlers.Privacy.Export)
+		admin.GET("/privacy/requests", handlers.Privacy.Requests)
+	}
+	if handlers.Campaigns != nil {
+		admin.POST("/campaigns", handlers.Campaigns.Create, handler.ValidateQuery(handler.CompanyListQueryRules...))
+		admin.GET("/campaigns", handlers.Campaigns.List)
+		admin.GET("/campaigns/:id", handlers.Campaigns.Get)
+		admin.GET("/campaigns/:id/recipients", handlers.Campaigns.Recipients)
+		admin.POST("/campaigns/:id/send", handlers.Campaigns.Send)
+	}
+	if handlers.Suppressions != nil {
+		admin.GET("/suppressions", handlers.Suppressions.List)
+		admin.POST("/suppressions", handlers.Suppressions.Create)
+		admin.PUT("/suppressions/:id", handlers.Suppressions.Update)
+		admin.DELETE("/suppressions/:id", handlers.Suppressions.Delete)
+	}
 
-	secured.POST("/scrape", handlers.Scrape.Enqueue, middlewarepkg.ScrapeRateLimiter(cfg.RateLimitScrape))
+	// Kill switches run before the rate limiters so refused calls do not spend quota. Each feature is
+	// the operation its calls are charged as, first to the caller and then to the budget all callers
+	// share, so one caller running out cannot drain the shared one.
+	workers := middlewarepkg.NewRateLimiter(cfg.RateLimitWorkers, middlewarepkg.SharedKey)
+	workerGuards := func(feature string) []echo.MiddlewareFunc {
+		var guards []echo.MiddlewareFunc
+		if handlers.Features != nil {
+			guards = append(guards, handlers.Features.Gate(feature))
+		}
+		return append(guards, budget.Limit(feature), workers.Limit(feature))
+	}
+	secured.POST("/scrape", handlers.Scrape.Enqueue, workerGuards(entity.FeatureScrape)...)
+	secured.GET("/scrape/jobs", handlers.Scrape.Jobs)
+	secured.GET("/scrape/jobs/:id/events", handlers.Scrape.JobEvents)
+	secured.GET("/scrape/events", handlers.Scrape.Events)
+	if handlers.Freshness != nil {
+		// The preview only reads the catalogue, so it skips the worker guards and scrape quota.
+		secured.POST("/scrape/preview", handlers.Freshness.ScrapePreview)
+	}
 	if handlers.EnrichJob != nil {
-		secured.POST("/enrich", handlers.EnrichJob.Enqueue, middlewarepkg.ScrapeRateLimiter(cfg.RateLimitScrape))
+		secured.POST("/enrich", handlers.EnrichJob.Enqueue, workerGuards(entity.FeatureEnrich)...)
 	}
 	if handlers.Prompt != nil {
-		secured.POST("/prompt-search", handlers.Prompt.Enqueue, middlewarepkg.ScrapeRateLimiter(cfg.RateLimitScrape))
+		secured.POST("/prompt-search", handlers.Prompt.Enqueue, workerGuards(entity.FeaturePromptSearch)...)
+	}
+	if handlers.Reports != nil {
+		secured.GET("/reports/closures", handlers.Reports.NewlyClosed)
+	}
+	if handlers.Collisions != nil {
+		secured.GET("/reports/contact-collisions", handlers.Collisions.List)
+	}
+	if handlers.Changes != nil {
+		secured.GET("/changes", handlers.Changes.List)
+		secured.GET("/companies/changes", handlers.Changes.Companies)
+	}
+	if handlers.Attachments != nil {
+		secured.GET("/companies/:id/attachments", handlers.Attachments.List)
+		secured.POST("/companies/:id/attachments", handlers.Attachments.Create)
+		secured.GET("/attachments/usage", handlers.Attachments.Usage)
+		secured.POST("/attachments/:id/complete", handlers.Attachments.Complete)
+		secured.GET("/attachments/:id/download", handlers.Attachments.Download)
+		secured.DELETE("/attachments/:id", handlers.Attachments.Delete)
 	}
 }
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// DefaultEmailChangeTTL is how long a new email address can be confirmed.
+const DefaultEmailChangeTTL = 24 * time.Hour
+
+var (
+	// ErrCurrentPasswordMismatch is returned when a profile update carries a wrong current password.
+	ErrCurrentPasswordMismatch = errors.New("current password is incorrect")
+	// ErrInvalidProfileUpdate is returned for profile updates that change nothing or carry an
+	// invalid email or an empty password.
+	ErrInvalidProfileUpdate = errors.New("invalid profile update")
+	// ErrEmailChangesUnavailable is returned when no mailer is configured to confirm new addresses.
+	ErrEmailChangesUnavailable = errors.New("email changes are not enabled")
+	// ErrInvalidEmailChange is returned when an email change token is unknown, used or expired.
+	ErrInvalidEmailChange = errors.New("email change is invalid or has expired")
+	// ErrSessionsUnavailable is returned when refresh token sessions are not enabled.
+	ErrSessionsUnavailable = errors.New("sessions are not enabled")
+)
+
+// EmailSender delivers account e-mail; mailer.SMTPSender implements it.
+type EmailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// AccountService lets users manage their own account: profile, credentials and sessions.
+type AccountService struct {
+	users     repository.UsersRepository
+	sessions  repository.UserSessionsRepository
+	emails    repository.EmailChangesRepository
+	sender    EmailSender
+	verifyURL string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// AccountServiceOption customises an AccountService.
+type AccountServiceOption func(*AccountService)
+
+// WithAccountSessions lets users list and revoke their refresh token sessions, and revokes their
+// other sessions when they change their password.
+func WithAccountSessions(sessions repository.UserSessionsRepository) AccountServiceOption {
+	return func(s *AccountService) {
+		s.sessions = sessions
+	}
+}
+
+// WithEmailChanges lets users change their email address, confirmed by a token mailed to the new
+// address. When verifyURL is set the mail links to it with the token as the token query parameter.
+func WithEmailChanges(emails repository.EmailChangesRepository, sender EmailSender, verifyURL string) AccountServiceOption {
+	return func(s *AccountService) {
+		s.emails = emails
+		s.sender = sender
+		s.verifyURL = verifyURL
+	}
+}
+
+// NewAccountService builds an AccountService whose email changes last DefaultEmailChangeTTL.
+func NewAccountService(users repository.UsersRepository, opts ...AccountServiceOption) *AccountService {
+	s := &AccountService{users: users, ttl: DefaultEmailChangeTTL, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Profile returns the user's account with any pending email change.
+func (s *AccountService) Profile(ctx context.Context, userID string) (*dto.ProfileResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(ctx, user)
+}
+
+// UpdateProfile changes the user's password at once and requests a change of email address,
+// mailing a confirmation token to the new address. A new password revokes every session other
+// than currentSessionID.
+func (s *AccountService) UpdateProfile(ctx context.Context, userID, currentSessionID string, req dto.UpdateProfileRequest) (*dto.ProfileResponse, error) {
+	if req.Email == nil && req.Password == nil {
+		return nil, fmt.Errorf("%w: set email or password", ErrInvalidProfileUpdate)
+	}
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		return nil, ErrCurrentPasswordMismatch
+	}
+
+	var newEmail string
+	if req.Email != nil {
+		newEmail = strings.ToLower(strings.TrimSpace(*req.Email))
+		if !emailPattern.MatchString(newEmail) {
+			return nil, fmt.Errorf("%w: invalid email", ErrInvalidProfileUpdate)
+		}
+		if strings.EqualFold(newEmail, user.Email) {
+			newEmail = ""
+		} else if s.emails == nil || s.sender == nil {
+			return nil, ErrEmailChangesUnavailable
+		}
+	}
+	if req.Password != nil && strings.TrimSpace(*req.Password) == "" {
+		return nil, fmt.Errorf("%w: password cannot be empty", ErrInvalidProfileUpdate)
+	}
+
+	if req.Password != nil {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("hash password: %w", err)
+		}
+		pwd := string(hashed)
+		if _, err := s.users.Update(ctx, user.ID, nil, &pwd, nil); err != nil {
+			return nil, err
+		}
+		if s.sessions != nil {
+			var keep *uuid.UUID
+			if id, err := uuid.Parse(currentSessionID); err == nil {
+				keep = &id
+			}
+			if _, err := s.sessions.RevokeOtherSessions(ctx, user.ID, keep); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if newEmail != "" {
+		if err := s.requestEmailChange(ctx, user.ID, newEmail); err != nil {
+			return nil, err
+		}
+	}
+	return s.profile(ctx, user)
+}
+
+// VerifyEmail confirms the email change matching token, moving its user to the new address.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) (*dto.UserResponse, error) {
+	if s.emails == nil {
+		return nil, ErrEmailChangesUnavailable
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidEmailChange
+	}
+	user, err := s.emails.ConfirmEmailChange(ctx, hashSecretToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailChangeNotFound) {
+			return nil, ErrInvalidEmailChange
+		}
+		return nil, err
+	}
+	return &dto.UserResponse{ID: user.ID.String(), Email: user.Email, Role: user.Role}, nil
+}
+
+// Sessions lists the user's active sessions, marking the one of currentSessionID.
+func (s *AccountService) Sessions(ctx context.Context, userID, currentSessionID string) ([]entity.UserSession, error) {
+	if s.sessions == nil {
+		return nil, ErrSessionsUnavailable
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, repository.ErrUserNotFound
+	}
+	sessions, err := s.sessions.ListActiveSessions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's sessions out; its refresh token stops working at once and
+// its access tokens expire on their own.
+func (s *AccountService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if s.sessions == nil {
+		return ErrSessionsUnavailable
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return repository.ErrUserNotFound
+	}
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return repository.ErrSessionNotFound
+	}
+	return s.sessions.RevokeSession(ctx, uid, id)
+}
+
+func (s *AccountService) findUser(ctx context.Context, userID string) (*entity.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, repository.ErrUserNotFound
+	}
+	return s.users.FindByID(ctx, id)
+}
+
+func (s *AccountService) profile(ctx context.Context, user *entity.User) (*dto.ProfileResponse, error) {
+	profile := &dto.ProfileResponse{ID: user.ID.String(), Email: user.Email, Role: user.Role, CreatedAt: user.CreatedAt}
+	if s.emails != nil {
+		pending, err := s.emails.PendingEmailChange(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		profile.PendingEmailChange = pending
+	}
+	return profile, nil
+}
+
+// requestEmailChange records the change and mails its token to the new address. The old address
+// keeps working until the token is confirmed.
+func (s *AccountService) requestEmailChange(ctx context.Context, userID uuid.UUID, email string) error {
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	change := &entity.EmailChange{UserID: userID, Email: email, ExpiresAt: s.now().UTC().Add(s.ttl)}
+	if err := s.emails.UpsertEmailChange(ctx, change, hashSecretToken(token)); err != nil {
+		return err
+	}
+
+	confirm := "send this token to POST /auth/email/verify:\n\n" + token
+	if s.verifyURL != "" {
+		if link, err := url.Parse(s.verifyURL); err == nil {
+			query := link.Query()
+			query.Set("token", token)
+			link.RawQuery = query.Encode()
+			confirm = "open this link:\n\n" + link.String()
+		}
+	}
+	body := fmt.Sprintf("Someone asked to use this address for their Leads Generator account.\n\n"+
+		"To confirm, %s\n\nThe request expires at %s. If it was not you, ignore this message.\n",
+		confirm, change.ExpiresAt.Format(time.RFC1123))
+	return s.sender.Send(ctx, email, "Confirm your new email address", body)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+// memoryEmailChanges keeps one pending change per user, like the user_email_changes table.
+type memoryEmailChanges struct {
+	users   *testsupport.MemoryUsersRepository
+	changes map[uuid.UUID]entity.EmailChange
+	hashes  map[string]uuid.UUID
+}
+
+func newMemoryEmailChanges(users *testsupport.MemoryUsersRepository) *memoryEmailChanges {
+	return &memoryEmailChanges{users: users, changes: map[uuid.UUID]entity.EmailChange{}, hashes: map[string]uuid.UUID{}}
+}
+
+func (m *memoryEmailChanges) UpsertEmailChange(ctx context.Context, change *entity.EmailChange, tokenHash string) error {
+	for hash, userID := range m.hashes {
+		if userID == change.UserID {
+			delete(m.hashes, hash)
+		}
+	}
+	m.changes[change.UserID] = *change
+	m.hashes[tokenHash] = change.UserID
+	return nil
+}
+
+func (m *memoryEmailChanges) PendingEmailChange(ctx context.Context, userID uuid.UUID) (*entity.EmailChange, error) {
+	change, ok := m.changes[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &change, nil
+}
+
+func (m *memoryEmailChanges) ConfirmEmailChange(ctx context.Context, tokenHash string) (*entity.User, error) {
+	userID, ok := m.hashes[tokenHash]
+	if !ok {
+		return nil, repository.ErrEmailChangeNotFound
+	}
+	change := m.changes[userID]
+	delete(m.hashes, tokenHash)
+	delete(m.changes, userID)
+	return m.users.Update(ctx, userID, &change.Email, nil, nil)
+}
+
+type recordingSender struct {
+	to, body string
+}
+
+func (s *recordingSender) Send(ctx context.Context, to, subject, body string) error {
+	s.to, s.body = to, body
+	return nil
+}
+
+func newAccountFixture(t *testing.T) (*entity.User, *testsupport.MemoryUsersRepository) {
+	t.Helper()
+	hashed, err := bcrypt.GenerateFromPassword([]byte("current"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	user := testsupport.NewUser().WithEmail("old@example.com").WithPasswordHash(string(hashed)).Build()
+	return user, testsupport.NewMemoryUsersRepository(user)
+}
+
+func TestAccountService_UpdatePassword(t *testing.T) {
+	user, users := newAccountFixture(t)
+	sessions := testsupport.NewMemoryUserSessionsRepository()
+	service := NewAccountService(users, WithAccountSessions(sessions))
+	ctx := context.Background()
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		session := &entity.UserSession{UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		if err := sessions.CreateSession(ctx, session, uuid.NewString()); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		ids = append(ids, session.ID)
+	}
+
+	password := "rotated"
+	wrong := dto.UpdateProfileRequest{Password: &password, CurrentPassword: "guess"}
+	if _, err := service.UpdateProfile(ctx, user.ID.String(), ids[0].String(), wrong); !errors.Is(err, ErrCurrentPasswordMismatch) {
+		t.Fatalf("expected ErrCurrentPasswordMismatch, got %v", err)
+	}
+
+	update := dto.UpdateProfileRequest{Password: &password, CurrentPassword: "current"}
+	if _, err := service.UpdateProfile(ctx, user.ID.String(), ids[0].String(), update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	stored, _ := users.FindByID(ctx, user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("rotated")) != nil {
+		t.Fatal("expected the new password to be stored")
+	}
+
+	active, err := service.Sessions(ctx, user.ID.String(), ids[0].String())
+	if err != nil {
+		t.Fatalf("sessions: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != ids[0] || !active[0].Current {
+		t.Fatalf("expected only the current session to survive, got %+v", active)
+	}
+	if err := service.RevokeSession(ctx, user.ID.String(), ids[1].String()); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Fatalf("expected a revoked session to be gone, got %v", err)
+	}
+}
+
+func TestAccountService_EmailChange(t *testing.T) {
+	user, users := newAccountFixture(t)
+	emails := newMemoryEmailChanges(users)
+	sender := &recordingSender{}
+	service := NewAccountService(users, WithEmailChanges(emails, sender, "https://app.example.com/verify-email"))
+	ctx := context.Background()
+
+	email := " New@Example.com "
+	profile, err := service.UpdateProfile(ctx, user.ID.String(), "", dto.UpdateProfileRequest{Email: &email, CurrentPassword: "current"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if profile.Email != "old@example.com" || profile.PendingEmailChange == nil || profile.PendingEmailChange.Email != "new@example.com" {
+		t.Fatalf("expected the change to wait for confirmation, got %+v", profile)
+	}
+	if sender.to != "new@example.com" || !strings.Contains(sender.body, "https://app.example.com/verify-email?token=") {
+		t.Fatalf("unexpected confirmation mail to %s: %s", sender.to, sender.body)
+	}
+	token := sender.body[strings.Index(sender.body, "token=")+len("token="):]
+	token = token[:strings.IndexByte(token, '\n')]
+
+	if _, err := service.VerifyEmail(ctx, "unknown"); !errors.Is(err, ErrInvalidEmailChange) {
+		t.Fatalf("expected ErrInvalidEmailChange, got %v", err)
+	}
+	confirmed, err := service.VerifyEmail(ctx, token)
+	if err != nil || confirmed.Email != "new@example.com" {
+		t.Fatalf("expected the address to change, got %+v (%v)", confirmed, err)
+	}
+	if _, err := service.VerifyEmail(ctx, token); !errors.Is(err, ErrInvalidEmailChange) {
+		t.Fatalf("expected a used token to be rejected, got %v", err)
+	}
+}
+
+func TestAccountService_Unavailable(t *testing.T) {
+	user, users := newAccountFixture(t)
+	service := NewAccountService(users)
+	ctx := context.Background()
+
+	email := "new@example.com"
+	if _, err := service.UpdateProfile(ctx, user.ID.String(), "", dto.UpdateProfileRequest{Email: &email, CurrentPassword: "current"}); !errors.Is(err, ErrEmailChangesUnavailable) {
+		t.Fatalf("expected ErrEmailChangesUnavailable, got %v", err)
+	}
+	if _, err := service.UpdateProfile(ctx, user.ID.String(), "", dto.UpdateProfileRequest{CurrentPassword: "current"}); !errors.Is(err, ErrInvalidProfileUpdate) {
+		t.Fatalf("expected ErrInvalidProfileUpdate, got %v", err)
+	}
+	if _, err := service.Sessions(ctx, user.ID.String(), ""); !errors.Is(err, ErrSessionsUnavailable) {
+		t.Fatalf("expected ErrSessionsUnavailable, got %v", err)
+	}
+}
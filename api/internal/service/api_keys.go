@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// apiKeyPrefix starts every API key, so a leaked key is recognisable in logs and secret scanners.
+const apiKeyPrefix = "lgk_"
+
+// apiKeyShownPrefix is how much of a key is stored in the clear to tell keys apart.
+const apiKeyShownPrefix = len(apiKeyPrefix) + 8
+
+var (
+	// ErrInvalidAPIKeyName is returned when a key is created without a name.
+	ErrInvalidAPIKeyName = errors.New("name is required")
+	// ErrInvalidAPIKeyScope is returned when a key is created with a scope that does not exist.
+	ErrInvalidAPIKeyScope = errors.New("scopes must be among " + strings.Join(entity.APIKeyScopes, ", "))
+	// ErrInvalidAPIKeyID is returned when a key identifier cannot be parsed as UUID.
+	ErrInvalidAPIKeyID = errors.New("invalid api key id")
+	// ErrAPIKeyNotFound indicates the requested key does not exist.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// APIKeyService issues, lists and revokes the API keys data providers push companies with.
+type APIKeyService struct {
+	repo repository.APIKeysRepository
+}
+
+// NewAPIKeyService builds an APIKeyService.
+func NewAPIKeyService(repo repository.APIKeysRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create issues a key named name granting scopes, companies:write when none are given. The secret
+// is returned once; only its hash is stored.
+func (s *APIKeyService) Create(ctx context.Context, name string, scopes []string, createdBy *uuid.UUID) (*entity.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+	granted := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !slices.Contains(entity.APIKeyScopes, scope) {
+			return nil, "", ErrInvalidAPIKeyScope
+		}
+		if !slices.Contains(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		granted = append(granted, entity.APIKeyScopeCompaniesWrite)
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret := apiKeyPrefix + token
+	key := &entity.APIKey{Name: name, Prefix: secret[:apiKeyShownPrefix], Scopes: granted, CreatedBy: createdBy}
+	if err := s.repo.Create(ctx, key, hashSecretToken(secret)); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// List returns every key, revoked ones included, newest first.
+func (s *APIKeyService) List(ctx context.Context) ([]entity.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Revoke revokes the key with the given id; requests sent with it are refused from then on.
+func (s *APIKeyService) Revoke(ctx context.Context, idParam string) (*entity.APIKey, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idParam))
+	if err != nil {
+		return nil, ErrInvalidAPIKeyID
+	}
+	key, err := s.repo.Revoke(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// Authenticate returns the unrevoked key whose secret is secret, or nil when none matches, and
+// records that it was used.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*entity.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, nil
+	}
+	key, err := s.repo.GetActiveByHash(ctx, hashSecretToken(secret))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("failed to record use of api key %s: %v", key.ID, err)
+	}
+	return key, nil
+}
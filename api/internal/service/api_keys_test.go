@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockAPIKeysRepository struct {
+	keys    map[string]*entity.APIKey
+	touched []uuid.UUID
+}
+
+func (m *mockAPIKeysRepository) Create(ctx context.Context, key *entity.APIKey, keyHash string) error {
+	key.ID = uuid.New()
+	key.CreatedAt = time.Now()
+	m.keys[keyHash] = key
+	return nil
+}
+
+func (m *mockAPIKeysRepository) List(ctx context.Context) ([]entity.APIKey, error) {
+	keys := make([]entity.APIKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeysRepository) GetActiveByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	key, ok := m.keys[keyHash]
+	if !ok || key.RevokedAt != nil {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (m *mockAPIKeysRepository) Revoke(ctx context.Context, id uuid.UUID) (*entity.APIKey, error) {
+	for _, key := range m.keys {
+		if key.ID == id {
+			now := time.Now()
+			key.RevokedAt = &now
+			return key, nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeysRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+func TestAPIKeyService_Lifecycle(t *testing.T) {
+	repo := &mockAPIKeysRepository{keys: make(map[string]*entity.APIKey)}
+	svc := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	if _, _, err := svc.Create(ctx, " ", nil, nil); !errors.Is(err, ErrInvalidAPIKeyName) {
+		t.Fatalf("expected ErrInvalidAPIKeyName, got %v", err)
+	}
+	if _, _, err := svc.Create(ctx, "Acme data", []string{"companies:delete"}, nil); !errors.Is(err, ErrInvalidAPIKeyScope) {
+		t.Fatalf("expected ErrInvalidAPIKeyScope, got %v", err)
+	}
+
+	key, secret, err := svc.Create(ctx, " Acme data ", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.Name != "Acme data" || !key.HasScope(entity.APIKeyScopeCompaniesWrite) || !strings.HasPrefix(secret, key.Prefix) || len(secret) <= len(key.Prefix) {
+		t.Fatalf("unexpected key %+v for secret %q", key, secret)
+	}
+	if _, stored := repo.keys[secret]; stored {
+		t.Fatalf("expected only the hash of the secret stored")
+	}
+
+	found, err := svc.Authenticate(ctx, secret)
+	if err != nil || found == nil || found.ID != key.ID || len(repo.touched) != 1 {
+		t.Fatalf("expected the key authenticated and its use recorded, got %+v (%v)", found, err)
+	}
+	for _, wrong := range []string{"", secret + "x", strings.TrimPrefix(secret, apiKeyPrefix)} {
+		if found, err := svc.Authenticate(ctx, wrong); err != nil || found != nil {
+			t.Fatalf("expected %q refused, got %+v (%v)", wrong, found, err)
+		}
+	}
+
+	if _, err := svc.Revoke(ctx, "nope"); !errors.Is(err, ErrInvalidAPIKeyID) {
+		t.Fatalf("expected ErrInvalidAPIKeyID, got %v", err)
+	}
+	if _, err := svc.Revoke(ctx, uuid.NewString()); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+	if revoked, err := svc.Revoke(ctx, key.ID.String()); err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("expected the key revoked, got %+v (%v)", revoked, err)
+	}
+	if found, err := svc.Authenticate(ctx, secret); err != nil || found != nil {
+		t.Fatalf("expected a revoked key refused, got %+v (%v)", found, err)
+	}
+}
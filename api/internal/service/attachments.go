@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+// DefaultAttachmentURLTTL is how long signed upload and download URLs stay valid by default.
+const DefaultAttachmentURLTTL = 15 * time.Minute
+
+const (
+	// attachmentSweepGrace leaves uploads started just before their URL expired time to finish.
+	attachmentSweepGrace = time.Hour
+	// attachmentSweepBatch is how many expired uploads one sweep removes at most.
+	attachmentSweepBatch = 100
+)
+
+var (
+	// ErrInvalidAttachment is returned when an attachment request fails validation.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrInvalidAttachmentID is returned when an attachment identifier cannot be parsed as UUID.
+	ErrInvalidAttachmentID = errors.New("invalid attachment id")
+	// ErrAttachmentNotFound indicates the attachment record does not exist.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTooLarge is returned when a file exceeds the per-file size limit.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrAttachmentQuotaExceeded is returned when a file would push the uploader, or the org they were
+	// invited into, over its storage quota.
+	ErrAttachmentQuotaExceeded = errors.New("attachment storage quota exceeded")
+	// ErrAttachmentNotUploaded is returned when an upload is confirmed before the file was stored, or
+	// the stored file is larger than announced.
+	ErrAttachmentNotUploaded = errors.New("attachment file not uploaded")
+	// ErrAttachmentForbidden is returned when a user confirms or deletes an attachment of someone else.
+	ErrAttachmentForbidden = errors.New("attachment belongs to another user")
+)
+
+// AttachmentStore keeps attachment files and signs URLs clients use to transfer them directly.
+type AttachmentStore interface {
+	SignedURL(method, name string, headers map[string]string, ttl time.Duration) (string, error)
+	Stat(ctx context.Context, name string) (storage.ObjectInfo, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// AttachmentTransfer is a signed URL for uploading or downloading an attachment file.
+type AttachmentTransfer struct {
+	Attachment *entity.CompanyAttachment `json:"attachment"`
+	Method     string                    `json:"method"`
+	URL        string                    `json:"url"`
+	// Headers must be sent with the request for the signature to match.
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// AttachmentUsage reports the bytes a user stores in attachments.
+type AttachmentUsage struct {
+	Used      int64 `json:"used_bytes"`
+	Limit     int64 `json:"limit_bytes"`
+	Remaining int64 `json:"remaining_bytes"`
+	// Org reports the usage of the org the user was invited into, when there is one.
+	Org *AttachmentOrgUsage `json:"org,omitempty"`
+}
+
+// AttachmentOrgUsage reports the bytes all members of an org store in attachments.
+type AttachmentOrgUsage struct {
+	Name      string `json:"name"`
+	Used      int64  `json:"used_bytes"`
+	Limit     int64  `json:"limit_bytes"`
+	Remaining int64  `json:"remaining_bytes"`
+}
+
+// AttachmentService manages files attached to companies. File contents never pass through the API:
+// clients announce a file, upload it with a signed URL and confirm the upload.
+type AttachmentService struct {
+	repo     repository.CompanyAttachmentsRepository
+	store    AttachmentStore
+	prefix   string
+	maxSize  int64
+	quota    int64
+	orgQuota int64
+	urlTTL   time.Duration
+	now      func() time.Time
+}
+
+// AttachmentServiceOption customises an AttachmentService.
+type AttachmentServiceOption func(*AttachmentService)
+
+// WithOrgAttachmentQuota limits the total size stored by the members of an org, taken from the
+// invitation each user accepted. Users invited without an org only have the per-uploader quota.
+func WithOrgAttachmentQuota(quota int64) AttachmentServiceOption {
+	return func(s *AttachmentService) {
+		s.orgQuota = quota
+	}
+}
+
+// NewAttachmentService builds an AttachmentService. maxSize limits single files and quota the total
+// size stored per uploader; zero disables either limit.
+func NewAttachmentService(repo repository.CompanyAttachmentsRepository, store AttachmentStore, prefix string, maxSize, quota int64, urlTTL time.Duration, opts ...AttachmentServiceOption) *AttachmentService {
+	if urlTTL <= 0 {
+		urlTTL = DefaultAttachmentURLTTL
+	}
+	s := &AttachmentService{
+		repo:    repo,
+		store:   store,
+		prefix:  strings.Trim(prefix, "/"),
+		maxSize: maxSize,
+		quota:   quota,
+		urlTTL:  urlTTL,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateUpload records a pending attachment and returns the signed URL the client must PUT the file
+// to, followed by a call to Complete.
+func (s *AttachmentService) CreateUpload(ctx context.Context, userID, companyID string, req dto.CreateAttachmentRequest) (*AttachmentTransfer, error) {
+	companyUUID, err := uuid.Parse(companyID)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	uploader, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uploader id: %w", err)
+	}
+
+	filename := path.Base(strings.ReplaceAll(strings.TrimSpace(req.Filename), "\\", "/"))
+	if filename == "" || filename == "." || filename == "/" {
+		return nil, fmt.Errorf("%w: filename is required", ErrInvalidAttachment)
+	}
+	contentType := strings.TrimSpace(req.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		return nil, fmt.Errorf("%w: content_type is not a media type", ErrInvalidAttachment)
+	}
+	if req.SizeBytes <= 0 {
+		return nil, fmt.Errorf("%w: size_bytes must be positive", ErrInvalidAttachment)
+	}
+	if s.maxSize > 0 && req.SizeBytes > s.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	now := s.now().UTC()
+	if s.quota > 0 {
+		used, err := s.repo.UsageByUser(ctx, uploader)
+		if err != nil {
+			return nil, err
+		}
+		if used+req.SizeBytes > s.quota {
+			return nil, ErrAttachmentQuotaExceeded
+		}
+	}
+	if s.orgQuota > 0 {
+		org, used, err := s.repo.UsageByOrg(ctx, uploader)
+		if err != nil {
+			return nil, err
+		}
+		if org != "" && used+req.SizeBytes > s.orgQuota {
+			return nil, fmt.Errorf("%w for org %s", ErrAttachmentQuotaExceeded, org)
+		}
+	}
+
+	attachment := &entity.CompanyAttachment{
+		ID:          uuid.New(),
+		CompanyID:   companyUUID,
+		UploadedBy:  &uploader,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   req.SizeBytes,
+		Status:      entity.AttachmentStatusPending,
+	}
+	// The object keeps a readable name so downloaded files are not saved as bare UUIDs.
+	attachment.StorageKey = path.Join(s.prefix, companyUUID.String(), attachment.ID.String(), storageFilename(filename))
+
+	// The storage refuses bodies outside the signed length range, so the announced size the quota
+	// was checked against is also the most that can be uploaded.
+	headers := map[string]string{
+		"Content-Type":                contentType,
+		"x-goog-content-length-range": fmt.Sprintf("0,%d", req.SizeBytes),
+	}
+	signed, err := s.store.SignedURL(http.MethodPut, attachment.StorageKey, headers, s.urlTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	return &AttachmentTransfer{
+		Attachment: attachment,
+		Method:     http.MethodPut,
+		URL:        signed,
+		Headers:    headers,
+		ExpiresAt:  now.Add(s.urlTTL),
+	}, nil
+}
+
+// Complete confirms the upload of a pending attachment and records the stored size. Only the
+// uploader or an admin may confirm it; confirming an attachment twice is harmless.
+func (s *AttachmentService) Complete(ctx context.Context, userID string, isAdmin bool, id string) (*entity.CompanyAttachment, error) {
+	attachment, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canChangeAttachment(attachment, userID, isAdmin) {
+		return nil, ErrAttachmentForbidden
+	}
+	if attachment.Status == entity.AttachmentStatusReady {
+		return attachment, nil
+	}
+
+	info, err := s.store.Stat(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrAttachmentNotUploaded
+		}
+		return nil, err
+	}
+	// The quota was checked against the announced size, so a larger file is refused.
+	if info.Size > attachment.SizeBytes {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Printf("failed to delete oversized attachment %s: %v", attachment.ID, err)
+		}
+		return nil, fmt.Errorf("%w: stored file is larger than the announced %d bytes", ErrAttachmentNotUploaded, attachment.SizeBytes)
+	}
+
+	uploadedAt := s.now().UTC()
+	if err := s.repo.MarkReady(ctx, attachment.ID, info.Size, uploadedAt); err != nil {
+		return nil, err
+	}
+	attachment.Status = entity.AttachmentStatusReady
+	attachment.SizeBytes = info.Size
+	attachment.UploadedAt = &uploadedAt
+	return attachment, nil
+}
+
+// List returns the uploaded attachments of a company.
+func (s *AttachmentService) List(ctx context.Context, companyID string) ([]entity.CompanyAttachment, error) {
+	companyUUID, err := uuid.Parse(companyID)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	return s.repo.ListByCompany(ctx, companyUUID)
+}
+
+// Download returns a signed URL to fetch an uploaded attachment.
+func (s *AttachmentService) Download(ctx context.Context, id string) (*AttachmentTransfer, error) {
+	attachment, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.Status != entity.AttachmentStatusReady {
+		return nil, ErrAttachmentNotUploaded
+	}
+	signed, err := s.store.SignedURL(http.MethodGet, attachment.StorageKey, nil, s.urlTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &AttachmentTransfer{
+		Attachment: attachment,
+		Method:     http.MethodGet,
+		URL:        signed,
+		ExpiresAt:  s.now().UTC().Add(s.urlTTL),
+	}, nil
+}
+
+// Delete removes an attachment and its file. Only the uploader or an admin may delete it.
+func (s *AttachmentService) Delete(ctx context.Context, userID string, isAdmin bool, id string) error {
+	attachment, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !canChangeAttachment(attachment, userID, isAdmin) {
+		return ErrAttachmentForbidden
+	}
+	if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, attachment.ID); err != nil {
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Usage reports the attachment storage used by a user against the quota.
+func (s *AttachmentService) Usage(ctx context.Context, userID string) (*AttachmentUsage, error) {
+	uploader, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+	used, err := s.repo.UsageByUser(ctx, uploader)
+	if err != nil {
+		return nil, err
+	}
+	usage := &AttachmentUsage{Used: used, Limit: s.quota}
+	if s.quota > used {
+		usage.Remaining = s.quota - used
+	}
+	if s.orgQuota > 0 {
+		org, orgUsed, err := s.repo.UsageByOrg(ctx, uploader)
+		if err != nil {
+			return nil, err
+		}
+		if org != "" {
+			usage.Org = &AttachmentOrgUsage{Name: org, Used: orgUsed, Limit: s.orgQuota}
+			if s.orgQuota > orgUsed {
+				usage.Org.Remaining = s.orgQuota - orgUsed
+			}
+		}
+	}
+	return usage, nil
+}
+
+// SweepExpiredUploads deletes pending attachments whose upload URL expired, together with any file
+// uploaded under them, so abandoned uploads neither hold quota nor keep files in the bucket. It
+// returns how many it removed.
+func (s *AttachmentService) SweepExpiredUploads(ctx context.Context) (int, error) {
+	expired, err := s.repo.ListExpiredPending(ctx, s.now().UTC().Add(-s.urlTTL-attachmentSweepGrace), attachmentSweepBatch)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, attachment := range expired {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return removed, err
+		}
+		if err := s.repo.Delete(ctx, attachment.ID); err != nil && !errors.Is(err, repository.ErrAttachmentNotFound) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Run sweeps expired uploads every interval until ctx is cancelled.
+func (s *AttachmentService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.SweepExpiredUploads(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("failed to sweep expired attachment uploads: %v", err)
+			}
+			if removed > 0 {
+				log.Printf("swept %d expired attachment uploads", removed)
+			}
+		}
+	}
+}
+
+func canChangeAttachment(attachment *entity.CompanyAttachment, userID string, isAdmin bool) bool {
+	return isAdmin || (attachment.UploadedBy != nil && attachment.UploadedBy.String() == userID)
+}
+
+func (s *AttachmentService) get(ctx context.Context, id string) (*entity.CompanyAttachment, error) {
+	attachmentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidAttachmentID
+	}
+	attachment, err := s.repo.Get(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// storageFilename keeps letters, digits, dots, dashes and underscores of a filename.
+func storageFilename(filename string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, filename)
+	if strings.Trim(safe, "._") == "" {
+		return "file"
+	}
+	return safe
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type stubAttachmentsRepository struct {
+	attachments map[uuid.UUID]*entity.CompanyAttachment
+	used        int64
+	org         string
+	orgUsed     int64
+}
+
+func (r *stubAttachmentsRepository) Create(ctx context.Context, attachment *entity.CompanyAttachment) error {
+	r.attachments[attachment.ID] = attachment
+	return nil
+}
+
+func (r *stubAttachmentsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.CompanyAttachment, error) {
+	attachment, ok := r.attachments[id]
+	if !ok {
+		return nil, repository.ErrAttachmentNotFound
+	}
+	copied := *attachment
+	return &copied, nil
+}
+
+func (r *stubAttachmentsRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]entity.CompanyAttachment, error) {
+	return nil, nil
+}
+
+func (r *stubAttachmentsRepository) MarkReady(ctx context.Context, id uuid.UUID, sizeBytes int64, uploadedAt time.Time) error {
+	r.attachments[id].Status = entity.AttachmentStatusReady
+	r.attachments[id].SizeBytes = sizeBytes
+	return nil
+}
+
+func (r *stubAttachmentsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.attachments, id)
+	return nil
+}
+
+func (r *stubAttachmentsRepository) UsageByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return r.used, nil
+}
+
+func (r *stubAttachmentsRepository) UsageByOrg(ctx context.Context, userID uuid.UUID) (string, int64, error) {
+	return r.org, r.orgUsed, nil
+}
+
+func (r *stubAttachmentsRepository) ListExpiredPending(ctx context.Context, before time.Time, limit int) ([]entity.CompanyAttachment, error) {
+	expired := make([]entity.CompanyAttachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.Status == entity.AttachmentStatusPending && attachment.CreatedAt.Before(before) {
+			expired = append(expired, *attachment)
+		}
+	}
+	return expired, nil
+}
+
+type stubAttachmentStore struct {
+	objects map[string]int64
+	deleted []string
+	headers map[string]string
+}
+
+func (s *stubAttachmentStore) SignedURL(method, name string, headers map[string]string, ttl time.Duration) (string, error) {
+	s.headers = headers
+	return "https://storage.example/" + name + "?method=" + method, nil
+}
+
+func (s *stubAttachmentStore) Stat(ctx context.Context, name string) (storage.ObjectInfo, error) {
+	size, ok := s.objects[name]
+	if !ok {
+		return storage.ObjectInfo{}, storage.ErrObjectNotFound
+	}
+	return storage.ObjectInfo{Size: size}, nil
+}
+
+func (s *stubAttachmentStore) Delete(ctx context.Context, name string) error {
+	s.deleted = append(s.deleted, name)
+	delete(s.objects, name)
+	return nil
+}
+
+const (
+	attachmentCompanyID = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	attachmentUserID    = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+)
+
+func newTestAttachmentService(quota int64) (*AttachmentService, *stubAttachmentsRepository, *stubAttachmentStore) {
+	repo := &stubAttachmentsRepository{attachments: map[uuid.UUID]*entity.CompanyAttachment{}}
+	store := &stubAttachmentStore{objects: map[string]int64{}}
+	return NewAttachmentService(repo, store, "attachments", 1<<20, quota, 0), repo, store
+}
+
+func TestAttachmentService_UploadLifecycle(t *testing.T) {
+	svc, _, store := newTestAttachmentService(0)
+	ctx := context.Background()
+
+	upload, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, dto.CreateAttachmentRequest{
+		Filename:    `C:\deals\Q3 proposal.pdf`,
+		ContentType: "application/pdf",
+		SizeBytes:   2048,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	attachment := upload.Attachment
+	if attachment.Filename != "Q3 proposal.pdf" || attachment.Status != entity.AttachmentStatusPending {
+		t.Fatalf("unexpected attachment: %+v", attachment)
+	}
+	wantKey := "attachments/" + attachmentCompanyID + "/" + attachment.ID.String() + "/Q3_proposal.pdf"
+	if attachment.StorageKey != wantKey || !strings.Contains(upload.URL, "method=PUT") || upload.Headers["Content-Type"] != "application/pdf" {
+		t.Fatalf("unexpected upload: key=%s %+v", attachment.StorageKey, upload)
+	}
+	if upload.Headers["x-goog-content-length-range"] != "0,2048" || store.headers["x-goog-content-length-range"] != "0,2048" {
+		t.Fatalf("expected the announced size signed as the length range, got %v / %v", upload.Headers, store.headers)
+	}
+
+	if _, err := svc.Complete(ctx, uuid.NewString(), false, attachment.ID.String()); !errors.Is(err, ErrAttachmentForbidden) {
+		t.Fatalf("expected ErrAttachmentForbidden for another user, got %v", err)
+	}
+	if _, err := svc.Complete(ctx, attachmentUserID, false, attachment.ID.String()); !errors.Is(err, ErrAttachmentNotUploaded) {
+		t.Fatalf("expected ErrAttachmentNotUploaded before the upload, got %v", err)
+	}
+	if _, err := svc.Download(ctx, attachment.ID.String()); !errors.Is(err, ErrAttachmentNotUploaded) {
+		t.Fatalf("expected pending attachment not to be downloadable, got %v", err)
+	}
+
+	store.objects[wantKey] = 1500
+	completed, err := svc.Complete(ctx, attachmentUserID, false, attachment.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if completed.Status != entity.AttachmentStatusReady || completed.SizeBytes != 1500 {
+		t.Fatalf("unexpected completed attachment: %+v", completed)
+	}
+	download, err := svc.Download(ctx, attachment.ID.String())
+	if err != nil || !strings.Contains(download.URL, "method=GET") {
+		t.Fatalf("unexpected download: %+v, %v", download, err)
+	}
+}
+
+func TestAttachmentService_CompleteRejectsLargerFile(t *testing.T) {
+	svc, _, store := newTestAttachmentService(0)
+	ctx := context.Background()
+
+	upload, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, dto.CreateAttachmentRequest{Filename: "call.mp3", SizeBytes: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.objects[upload.Attachment.StorageKey] = 5000
+
+	if _, err := svc.Complete(ctx, attachmentUserID, false, upload.Attachment.ID.String()); !errors.Is(err, ErrAttachmentNotUploaded) {
+		t.Fatalf("expected ErrAttachmentNotUploaded, got %v", err)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != upload.Attachment.StorageKey {
+		t.Fatalf("expected oversized file to be deleted, got %v", store.deleted)
+	}
+}
+
+func TestAttachmentService_CreateUploadLimits(t *testing.T) {
+	svc, repo, _ := newTestAttachmentService(10_000)
+	repo.used = 9_000
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		req  dto.CreateAttachmentRequest
+		want error
+	}{
+		{"missing filename", dto.CreateAttachmentRequest{SizeBytes: 10}, ErrInvalidAttachment},
+		{"empty file", dto.CreateAttachmentRequest{Filename: "a.pdf"}, ErrInvalidAttachment},
+		{"bad content type", dto.CreateAttachmentRequest{Filename: "a.pdf", ContentType: "pdf file", SizeBytes: 10}, ErrInvalidAttachment},
+		{"over file limit", dto.CreateAttachmentRequest{Filename: "a.pdf", SizeBytes: 2 << 20}, ErrAttachmentTooLarge},
+		{"over quota", dto.CreateAttachmentRequest{Filename: "a.pdf", SizeBytes: 1_001}, ErrAttachmentQuotaExceeded},
+	}
+	for _, tc := range cases {
+		if _, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, tc.req); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if _, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, dto.CreateAttachmentRequest{Filename: "a.pdf", SizeBytes: 1_000}); err != nil {
+		t.Fatalf("expected upload within quota, got %v", err)
+	}
+}
+
+func TestAttachmentService_OrgQuota(t *testing.T) {
+	repo := &stubAttachmentsRepository{attachments: map[uuid.UUID]*entity.CompanyAttachment{}, orgUsed: 9_500}
+	svc := NewAttachmentService(repo, &stubAttachmentStore{objects: map[string]int64{}}, "attachments", 0, 10_000, 0, WithOrgAttachmentQuota(10_000))
+	ctx := context.Background()
+	req := dto.CreateAttachmentRequest{Filename: "a.pdf", SizeBytes: 1_000}
+
+	if _, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, req); err != nil {
+		t.Fatalf("expected users without an org to be held to their own quota only, got %v", err)
+	}
+	repo.org = "Acme Realty"
+	if _, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, req); !errors.Is(err, ErrAttachmentQuotaExceeded) {
+		t.Fatalf("expected the org quota to apply, got %v", err)
+	}
+	usage, err := svc.Usage(ctx, attachmentUserID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Org == nil || usage.Org.Name != "Acme Realty" || usage.Org.Used != 9_500 || usage.Org.Remaining != 500 {
+		t.Fatalf("unexpected org usage: %+v", usage.Org)
+	}
+}
+
+func TestAttachmentService_DeleteRequiresOwnerOrAdmin(t *testing.T) {
+	svc, repo, store := newTestAttachmentService(0)
+	ctx := context.Background()
+
+	upload, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, dto.CreateAttachmentRequest{Filename: "a.pdf", SizeBytes: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	id := upload.Attachment.ID.String()
+
+	if err := svc.Delete(ctx, uuid.NewString(), false, id); !errors.Is(err, ErrAttachmentForbidden) {
+		t.Fatalf("expected ErrAttachmentForbidden, got %v", err)
+	}
+	if err := svc.Delete(ctx, uuid.NewString(), true, id); err != nil {
+		t.Fatalf("expected admin delete to succeed, got %v", err)
+	}
+	if len(repo.attachments) != 0 || len(store.deleted) != 1 {
+		t.Fatalf("expected record and file to be deleted, got %v / %v", repo.attachments, store.deleted)
+	}
+}
+
+func TestAttachmentService_SweepExpiredUploads(t *testing.T) {
+	svc, repo, store := newTestAttachmentService(0)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	upload := func(name string, age time.Duration) *entity.CompanyAttachment {
+		created, err := svc.CreateUpload(ctx, attachmentUserID, attachmentCompanyID, dto.CreateAttachmentRequest{Filename: name, SizeBytes: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		attachment := repo.attachments[created.Attachment.ID]
+		attachment.CreatedAt = now.Add(-age)
+		return attachment
+	}
+	abandoned := upload("abandoned.pdf", 2*time.Hour)
+	store.objects[abandoned.StorageKey] = 10
+	recent := upload("recent.pdf", 30*time.Minute)
+	confirmed := upload("confirmed.pdf", 2*time.Hour)
+	store.objects[confirmed.StorageKey] = 10
+	if _, err := svc.Complete(ctx, attachmentUserID, false, confirmed.ID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	removed, err := svc.SweepExpiredUploads(ctx)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one expired upload swept, got %d, %v", removed, err)
+	}
+	if _, ok := repo.attachments[abandoned.ID]; ok || len(store.deleted) != 1 || store.deleted[0] != abandoned.StorageKey {
+		t.Fatalf("expected the abandoned upload and its file deleted, got %v", store.deleted)
+	}
+	if repo.attachments[recent.ID] == nil || repo.attachments[confirmed.ID] == nil {
+		t.Fatalf("expected recent and confirmed attachments kept")
+	}
+}
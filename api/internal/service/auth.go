@@ -4,77 +4,170 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
 	"github.com/octobees/leads-generator/api/internal/repository"
 )
 
+// maxUserAgentLength bounds the user agent stored with a session.
+const maxUserAgentLength = 256
+
 // AuthService coordinates credential validation and token issuance.
 type AuthService struct {
-	users repository.UsersRepository
-	jwt   *auth.JWTManager
+	users      repository.UsersRepository
+	jwt        *auth.JWTManager
+	sessions   repository.UserSessionsRepository
+	refreshTTL time.Duration
+	now        func() time.Time
 }
 
-// ErrEmailAlreadyExists indicates a duplicate registration attempt.
-var ErrEmailAlreadyExists = errors.New("email already exists")
+var (
+	// ErrEmailAlreadyExists indicates a duplicate registration attempt.
+	ErrEmailAlreadyExists = errors.New("email already exists")
+	// ErrInvalidRefreshToken is returned for unknown, reused, revoked and expired refresh tokens.
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or has expired")
+	// ErrRefreshTokensUnavailable is returned when refresh tokens are not enabled.
+	ErrRefreshTokensUnavailable = errors.New("refresh tokens are not enabled")
+)
+
+// AuthServiceOption customises an AuthService.
+type AuthServiceOption func(*AuthService)
+
+// WithRefreshTokens makes logins and registrations open a session whose refresh token can be
+// exchanged for new tokens until it has gone unused for ttl.
+func WithRefreshTokens(sessions repository.UserSessionsRepository, ttl time.Duration) AuthServiceOption {
+	return func(s *AuthService) {
+		s.sessions = sessions
+		s.refreshTTL = ttl
+	}
+}
 
 // NewAuthService constructs a new AuthService.
-func NewAuthService(users repository.UsersRepository, jwtManager *auth.JWTManager) *AuthService {
-	return &AuthService{users: users, jwt: jwtManager}
+func NewAuthService(users repository.UsersRepository, jwtManager *auth.JWTManager, opts ...AuthServiceOption) *AuthService {
+	s := &AuthService{users: users, jwt: jwtManager, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Login validates credentials and returns a JWT.
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
+// Login validates credentials and returns an access token, plus a refresh token when enabled.
+func (s *AuthService) Login(ctx context.Context, email, password string, client dto.ClientInfo) (*dto.LoginResponse, error) {
 	if email == "" || password == "" {
-		return "", errors.New("email and password must not be empty")
+		return nil, errors.New("email and password must not be empty")
 	}
 
 	user, err := s.users.FindByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return "", errors.New("invalid credentials")
+			return nil, errors.New("invalid credentials")
 		}
-		return "", err
+		return nil, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid credentials")
-	}
-
-	token, err := s.jwt.GenerateToken(user.ID.String(), user.Email, user.Role)
-	if err != nil {
-		return "", err
+		return nil, errors.New("invalid credentials")
 	}
 
-	return token, nil
+	return s.issue(ctx, user, client)
 }
 
-// Register creates a new user with the default role and returns an access token.
-func (s *AuthService) Register(ctx context.Context, email, password string) (string, error) {
+// Register creates a new user with the default role and signs them in like Login.
+func (s *AuthService) Register(ctx context.Context, email, password string, client dto.ClientInfo) (*dto.LoginResponse, error) {
 	email = strings.TrimSpace(email)
 	if email == "" || password == "" {
-		return "", errors.New("email and password must not be empty")
+		return nil, errors.New("email and password must not be empty")
 	}
 
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	user, err := s.users.Create(ctx, email, string(hashed), "user")
 	if err != nil {
 		if errors.Is(err, repository.ErrEmailDuplicate) {
-			return "", ErrEmailAlreadyExists
+			return nil, ErrEmailAlreadyExists
 		}
-		return "", err
+		return nil, err
 	}
 
-	token, err := s.jwt.GenerateToken(user.ID.String(), user.Email, user.Role)
+	return s.issue(ctx, user, client)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token; the old one
+// stops working. The access token carries the user's current email and role.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*dto.LoginResponse, error) {
+	if s.sessions == nil {
+		return nil, ErrRefreshTokensUnavailable
+	}
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	next, err := newSecretToken()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
+	session, err := s.sessions.RotateSession(ctx, hashSecretToken(refreshToken), hashSecretToken(next), s.now().UTC().Add(s.refreshTTL))
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	user, err := s.users.FindByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	token, err := s.jwt.GenerateSessionToken(user.ID.String(), user.Email, user.Role, session.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{AccessToken: token, RefreshToken: next}, nil
+}
+
+// issue signs user in, opening a session when refresh tokens are enabled.
+func (s *AuthService) issue(ctx context.Context, user *entity.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if s.sessions == nil {
+		token, err := s.jwt.GenerateToken(user.ID.String(), user.Email, user.Role)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{AccessToken: token}, nil
+	}
+
+	refreshToken, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	session := &entity.UserSession{
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IP:        client.IP,
+		ExpiresAt: s.now().UTC().Add(s.refreshTTL),
+	}
+	if err := s.sessions.CreateSession(ctx, session, hashSecretToken(refreshToken)); err != nil {
+		return nil, err
+	}
+
+	token, err := s.jwt.GenerateSessionToken(user.ID.String(), user.Email, user.Role, session.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{AccessToken: token, RefreshToken: refreshToken}, nil
 }
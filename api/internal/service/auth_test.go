@@ -4,20 +4,23 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/octobees/leads-generator/api/internal/auth"
+	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
 	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
 )
 
 type mockUsersRepository struct {
 	findByEmail func(ctx context.Context, email string) (*entity.User, error)
 	findByID    func(ctx context.Context, id uuid.UUID) (*entity.User, error)
 	create      func(ctx context.Context, email, passwordHash, role string) (*entity.User, error)
-	list        func(ctx context.Context) ([]entity.User, error)
+	list        func(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error)
 	update      func(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error)
 	delete      func(ctx context.Context, id uuid.UUID) error
 }
@@ -43,13 +46,18 @@ func (m *mockUsersRepository) Create(ctx context.Context, email, passwordHash, r
 	return nil, errors.New("create not implemented")
 }
 
-func (m *mockUsersRepository) List(ctx context.Context) ([]entity.User, error) {
+func (m *mockUsersRepository) List(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
 	if m.list != nil {
-		return m.list(ctx)
+		return m.list(ctx, filter)
 	}
 	return nil, errors.New("List not implemented")
 }
 
+func (m *mockUsersRepository) Count(ctx context.Context, filter dto.UserListFilter) (int64, error) {
+	users, err := m.List(ctx, filter)
+	return int64(len(users)), err
+}
+
 func (m *mockUsersRepository) Update(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error) {
 	if m.update != nil {
 		return m.update(ctx, id, email, passwordHash, role)
@@ -128,13 +136,13 @@ func TestAuthService_Login(t *testing.T) {
 			jwtManager := auth.NewJWTManager("test-secret", 0)
 			service := NewAuthService(tt.repo, jwtManager)
 
-			token, err := service.Login(context.Background(), tt.email, tt.password)
+			tokens, err := service.Login(context.Background(), tt.email, tt.password, dto.ClientInfo{})
 			if tt.expectError != "" {
 				if err == nil || err.Error() != tt.expectError {
 					t.Fatalf("expected error %q, got %v", tt.expectError, err)
 				}
-				if token != "" {
-					t.Fatalf("expected empty token on error, got %q", token)
+				if tokens != nil {
+					t.Fatalf("expected no tokens on error, got %+v", tokens)
 				}
 				return
 			}
@@ -142,8 +150,8 @@ func TestAuthService_Login(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if token == "" {
-				t.Fatalf("expected non-empty token")
+			if tokens.AccessToken == "" || tokens.RefreshToken != "" {
+				t.Fatalf("expected only an access token, got %+v", tokens)
 			}
 		})
 	}
@@ -191,13 +199,13 @@ func TestAuthService_Register(t *testing.T) {
 			jwtManager := auth.NewJWTManager("register-secret", 0)
 			service := NewAuthService(tt.repo, jwtManager)
 
-			token, err := service.Register(context.Background(), tt.email, tt.password)
+			tokens, err := service.Register(context.Background(), tt.email, tt.password, dto.ClientInfo{})
 			if tt.expectError != nil {
 				if err == nil || err.Error() != tt.expectError.Error() {
 					t.Fatalf("expected error %v, got %v", tt.expectError, err)
 				}
-				if token != "" {
-					t.Fatalf("expected empty token on error, got %q", token)
+				if tokens != nil {
+					t.Fatalf("expected no tokens on error, got %+v", tokens)
 				}
 				return
 			}
@@ -205,9 +213,51 @@ func TestAuthService_Register(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if token == "" {
+			if tokens.AccessToken == "" {
 				t.Fatalf("expected token to be returned")
 			}
 		})
 	}
 }
+
+func TestAuthService_RefreshTokens(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	user := testsupport.NewUser().WithPasswordHash(string(hashed)).Build()
+	users := testsupport.NewMemoryUsersRepository(user)
+	sessions := testsupport.NewMemoryUserSessionsRepository()
+	jwtManager := auth.NewJWTManager("refresh-secret", time.Hour)
+	service := NewAuthService(users, jwtManager, WithRefreshTokens(sessions, time.Hour))
+	ctx := context.Background()
+
+	tokens, err := service.Login(ctx, user.Email, "secret", dto.ClientInfo{UserAgent: "curl/8.0", IP: "203.0.113.7"})
+	if err != nil || tokens.RefreshToken == "" {
+		t.Fatalf("expected a refresh token, got %+v (%v)", tokens, err)
+	}
+	claims, err := jwtManager.ParseToken(tokens.AccessToken)
+	if err != nil || claims.SessionID == "" {
+		t.Fatalf("expected the access token to name its session (err=%v)", err)
+	}
+	active, _ := sessions.ListActiveSessions(ctx, user.ID)
+	if len(active) != 1 || active[0].ID.String() != claims.SessionID || active[0].UserAgent != "curl/8.0" {
+		t.Fatalf("unexpected sessions: %+v", active)
+	}
+
+	refreshed, err := service.Refresh(ctx, tokens.RefreshToken)
+	if err != nil || refreshed.RefreshToken == tokens.RefreshToken {
+		t.Fatalf("expected a rotated refresh token, got %+v (%v)", refreshed, err)
+	}
+	if _, err := service.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected a used refresh token to be rejected, got %v", err)
+	}
+
+	if err := sessions.RevokeSession(ctx, user.ID, active[0].ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := service.Refresh(ctx, refreshed.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected a revoked session to be rejected, got %v", err)
+	}
+
+	if _, err := NewAuthService(users, jwtManager).Refresh(ctx, "token"); !errors.Is(err, ErrRefreshTokensUnavailable) {
+		t.Fatalf("expected ErrRefreshTokensUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/placeattrs"
+)
+
+var (
+	// ErrInvalidBusinessCategory is returned when a category payload fails validation.
+	ErrInvalidBusinessCategory = errors.New("invalid business category")
+	// ErrInvalidBusinessCategoryID is returned when a category identifier cannot be parsed as UUID.
+	ErrInvalidBusinessCategoryID = errors.New("invalid business category id")
+	// ErrBusinessCategoryNotFound indicates the requested category does not exist.
+	ErrBusinessCategoryNotFound = errors.New("business category not found")
+	// ErrBusinessCategoryExists is returned when another category already has the name.
+	ErrBusinessCategoryExists = errors.New("business category already exists")
+)
+
+// categoryIndex is an immutable snapshot of the taxonomy. Terms are the names, labels and synonyms
+// of the categories in the form of Places types, so "Rumah Makan" and rumah_makan are one term.
+type categoryIndex struct {
+	categories []entity.BusinessCategory
+	byID       map[uuid.UUID]entity.BusinessCategory
+	byTerm     map[string][]uuid.UUID
+	children   map[uuid.UUID][]uuid.UUID
+}
+
+func newCategoryIndex(categories []entity.BusinessCategory) *categoryIndex {
+	index := &categoryIndex{
+		categories: categories,
+		byID:       make(map[uuid.UUID]entity.BusinessCategory, len(categories)),
+		byTerm:     make(map[string][]uuid.UUID),
+		children:   make(map[uuid.UUID][]uuid.UUID),
+	}
+	for _, category := range categories {
+		index.byID[category.ID] = category
+		if category.ParentID != nil {
+			index.children[*category.ParentID] = append(index.children[*category.ParentID], category.ID)
+		}
+		for _, term := range categoryTerms(category) {
+			if !slices.Contains(index.byTerm[term], category.ID) {
+				index.byTerm[term] = append(index.byTerm[term], category.ID)
+			}
+		}
+	}
+	return index
+}
+
+// lookup returns the category a term names, unless it names none or several.
+func (i *categoryIndex) lookup(raw string) (entity.BusinessCategory, bool) {
+	ids := i.byTerm[placeattrs.NormalizeCategory(raw)]
+	if len(ids) != 1 {
+		return entity.BusinessCategory{}, false
+	}
+	return i.byID[ids[0]], true
+}
+
+// descendants returns the ids of the categories below id, guarding against cycles.
+func (i *categoryIndex) descendants(id uuid.UUID) []uuid.UUID {
+	var found []uuid.UUID
+	queue := []uuid.UUID{id}
+	seen := map[uuid.UUID]bool{id: true}
+	for len(queue) > 0 {
+		for _, child := range i.children[queue[0]] {
+			if !seen[child] {
+				seen[child] = true
+				found = append(found, child)
+				queue = append(queue, child)
+			}
+		}
+		queue = queue[1:]
+	}
+	return found
+}
+
+// categoryTerms returns the terms a category is matched by.
+func categoryTerms(category entity.BusinessCategory) []string {
+	terms := make([]string, 0, len(category.Synonyms)+2)
+	for _, raw := range append([]string{category.Name, category.Label}, category.Synonyms...) {
+		if term := placeattrs.NormalizeCategory(raw); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// CategoryService manages the business category taxonomy and normalises business types against it.
+type CategoryService struct {
+	repo  repository.BusinessCategoriesRepository
+	index atomic.Pointer[categoryIndex]
+}
+
+// NewCategoryService builds a CategoryService with an empty taxonomy until the first Refresh.
+func NewCategoryService(repo repository.BusinessCategoriesRepository) *CategoryService {
+	svc := &CategoryService{repo: repo}
+	svc.index.Store(newCategoryIndex(nil))
+	return svc
+}
+
+// Refresh loads the taxonomy and swaps it into the normalisation index.
+func (s *CategoryService) Refresh(ctx context.Context) error {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.index.Store(newCategoryIndex(categories))
+	return nil
+}
+
+// Run refreshes the taxonomy every interval until ctx is cancelled. A failed refresh is logged and
+// the previous taxonomy stays in use.
+func (s *CategoryService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to refresh business categories: %v", err)
+			}
+		}
+	}
+}
+
+// List returns the categories of the taxonomy ordered by name. A non-empty query keeps those whose
+// name, label or a synonym starts with it.
+func (s *CategoryService) List(query string) []entity.BusinessCategory {
+	index := s.index.Load()
+	prefix := placeattrs.NormalizeCategory(query)
+	categories := make([]entity.BusinessCategory, 0, len(index.categories))
+	for _, category := range index.categories {
+		if prefix == "" || slices.ContainsFunc(categoryTerms(category), func(term string) bool {
+			return strings.HasPrefix(term, prefix)
+		}) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// NormalizeTypeBusiness returns the label of the category a business type names, or the business
+// type unchanged when it names no category or several.
+func (s *CategoryService) NormalizeTypeBusiness(typeBusiness string) string {
+	if category, ok := s.index.Load().lookup(typeBusiness); ok {
+		return category.Label
+	}
+	return typeBusiness
+}
+
+// normalizeCompany maps the business type of a company onto its category label and its categories
+// onto category names, keeping the business type first.
+func (s *CategoryService) normalizeCompany(typeBusiness *string, categories []string) (*string, []string) {
+	index := s.index.Load()
+	if typeBusiness != nil {
+		if category, ok := index.lookup(*typeBusiness); ok {
+			label := category.Label
+			typeBusiness = &label
+		}
+	}
+	mapped := make([]string, len(categories))
+	for i, raw := range categories {
+		mapped[i] = raw
+		if category, ok := index.lookup(raw); ok {
+			mapped[i] = category.Name
+		}
+	}
+	return typeBusiness, placeattrs.MergeCategories(mapped...)
+}
+
+// expandCategories widens category filters to what they mean in the taxonomy: a category matches
+// its own name, the synonyms companies stored before they were normalised, and its descendants.
+// Unknown categories are kept as they are.
+func (s *CategoryService) expandCategories(categories []string) []string {
+	index := s.index.Load()
+	expanded := make([]string, 0, len(categories))
+	add := func(term string) {
+		if term != "" && !slices.Contains(expanded, term) {
+			expanded = append(expanded, term)
+		}
+	}
+	for _, raw := range categories {
+		category, ok := index.lookup(raw)
+		if !ok {
+			add(placeattrs.NormalizeCategory(raw))
+			continue
+		}
+		for _, id := range append([]uuid.UUID{category.ID}, index.descendants(category.ID)...) {
+			for _, term := range categoryTerms(index.byID[id]) {
+				add(term)
+			}
+		}
+	}
+	return expanded
+}
+
+// Create stores a new category and refreshes the taxonomy so it applies immediately.
+func (s *CategoryService) Create(ctx context.Context, req dto.BusinessCategoryRequest) (*entity.BusinessCategory, error) {
+	category, err := buildBusinessCategory(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, mapBusinessCategoryError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return category, nil
+}
+
+// Update replaces a category and refreshes the taxonomy. A category cannot be moved below itself
+// or one of its descendants.
+func (s *CategoryService) Update(ctx context.Context, idRaw string, req dto.BusinessCategoryRequest) (*entity.BusinessCategory, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidBusinessCategoryID
+	}
+	category, err := buildBusinessCategory(req)
+	if err != nil {
+		return nil, err
+	}
+	category.ID = id
+	if parent := category.ParentID; parent != nil && (*parent == id || slices.Contains(s.index.Load().descendants(id), *parent)) {
+		return nil, fmt.Errorf("%w: parent_id must not be the category or one of its descendants", ErrInvalidBusinessCategory)
+	}
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, mapBusinessCategoryError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return category, nil
+}
+
+// Delete removes a category and refreshes the taxonomy; its children become top-level categories.
+func (s *CategoryService) Delete(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return ErrInvalidBusinessCategoryID
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return mapBusinessCategoryError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return nil
+}
+
+// refreshAfterChange applies a write on this instance right away; other instances pick it up on
+// their next periodic refresh.
+func (s *CategoryService) refreshAfterChange(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		log.Printf("failed to refresh business categories after change: %v", err)
+	}
+}
+
+func buildBusinessCategory(req dto.BusinessCategoryRequest) (*entity.BusinessCategory, error) {
+	label := strings.Join(strings.Fields(req.Label), " ")
+	if label == "" {
+		return nil, fmt.Errorf("%w: label is required", ErrInvalidBusinessCategory)
+	}
+	name := placeattrs.NormalizeCategory(req.Name)
+	if name == "" {
+		name = placeattrs.NormalizeCategory(label)
+	}
+
+	var parentID *uuid.UUID
+	if req.ParentID != nil && strings.TrimSpace(*req.ParentID) != "" {
+		parsed, err := uuid.Parse(strings.TrimSpace(*req.ParentID))
+		if err != nil {
+			return nil, fmt.Errorf("%w: parent_id must be a UUID", ErrInvalidBusinessCategory)
+		}
+		parentID = &parsed
+	}
+
+	// Synonyms are stored lowercased; those spelling the name or label again are dropped.
+	synonyms := make([]string, 0, len(req.Synonyms))
+	seen := map[string]bool{name: true, placeattrs.NormalizeCategory(label): true}
+	for _, raw := range req.Synonyms {
+		synonym := strings.ToLower(strings.Join(strings.Fields(raw), " "))
+		term := placeattrs.NormalizeCategory(synonym)
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		synonyms = append(synonyms, synonym)
+	}
+	return &entity.BusinessCategory{Name: name, Label: label, ParentID: parentID, Synonyms: synonyms}, nil
+}
+
+func mapBusinessCategoryError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrBusinessCategoryNotFound):
+		return ErrBusinessCategoryNotFound
+	case errors.Is(err, repository.ErrBusinessCategoryDuplicate):
+		return ErrBusinessCategoryExists
+	case errors.Is(err, repository.ErrBusinessCategoryParent):
+		return fmt.Errorf("%w: parent_id names no category", ErrInvalidBusinessCategory)
+	default:
+		return err
+	}
+}
+
+// WithCategories normalises the business type and categories of companies imported from files or
+// the batch API onto the taxonomy, and widens category filters of listings to synonyms and
+// subcategories.
+func WithCategories(categories *CategoryService) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.categories = categories
+	}
+}
+
+// withCategoryTaxonomy widens the category filter of a listing through the taxonomy.
+func (s *CompaniesService) withCategoryTaxonomy(filter dto.ListFilter) dto.ListFilter {
+	if s.categories != nil && len(filter.Categories) > 0 {
+		filter.Categories = s.categories.expandCategories(filter.Categories)
+	}
+	return filter
+}
+
+// WithIngestCategories normalises the business type and categories of streamed places onto the
+// taxonomy.
+func WithIngestCategories(categories *CategoryService) IngestRunsServiceOption {
+	return func(s *IngestRunsService) {
+		s.categories = categories
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockBusinessCategoriesRepository struct {
+	categories []entity.BusinessCategory
+}
+
+func (m *mockBusinessCategoriesRepository) List(ctx context.Context) ([]entity.BusinessCategory, error) {
+	return append([]entity.BusinessCategory(nil), m.categories...), nil
+}
+
+func (m *mockBusinessCategoriesRepository) Create(ctx context.Context, category *entity.BusinessCategory) error {
+	for _, existing := range m.categories {
+		if existing.Name == category.Name {
+			return repository.ErrBusinessCategoryDuplicate
+		}
+	}
+	category.ID = uuid.New()
+	m.categories = append(m.categories, *category)
+	return nil
+}
+
+func (m *mockBusinessCategoriesRepository) Update(ctx context.Context, category *entity.BusinessCategory) error {
+	for i, existing := range m.categories {
+		if existing.ID == category.ID {
+			m.categories[i] = *category
+			return nil
+		}
+	}
+	return repository.ErrBusinessCategoryNotFound
+}
+
+func (m *mockBusinessCategoriesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrBusinessCategoryNotFound
+}
+
+var (
+	testFoodCategoryID       = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testRestaurantCategoryID = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+)
+
+func newTestCategoryService(t *testing.T) (*CategoryService, *mockBusinessCategoriesRepository) {
+	t.Helper()
+	repo := &mockBusinessCategoriesRepository{categories: []entity.BusinessCategory{
+		{ID: testFoodCategoryID, Name: "food_and_drink", Label: "Food & Drink"},
+		{ID: testRestaurantCategoryID, Name: "restaurant", Label: "Restaurant", ParentID: &testFoodCategoryID, Synonyms: []string{"resto", "rumah makan"}},
+		{ID: uuid.New(), Name: "cafe", Label: "Cafe", ParentID: &testFoodCategoryID, Synonyms: []string{"kafe", "warung"}},
+		{ID: uuid.New(), Name: "grocery_store", Label: "Grocery Store", Synonyms: []string{"warung"}},
+	}}
+	svc := NewCategoryService(repo)
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+	return svc, repo
+}
+
+func TestCategoryService_Normalize(t *testing.T) {
+	svc, _ := newTestCategoryService(t)
+
+	tests := map[string]string{
+		"resto":       "Restaurant",
+		"Rumah Makan": "Restaurant",
+		"rumah-makan": "Restaurant",
+		"restaurant":  "Restaurant",
+		"KAFE":        "Cafe",
+		"warung":      "warung",
+		"bengkel":     "bengkel",
+	}
+	for raw, want := range tests {
+		if got := svc.NormalizeTypeBusiness(raw); got != want {
+			t.Fatalf("NormalizeTypeBusiness(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	typeBusiness, categories := svc.normalizeCompany(stringPtr("rumah makan"), []string{"rumah_makan", "resto", "bar", "warung"})
+	if *typeBusiness != "Restaurant" || strings.Join(categories, ",") != "restaurant,bar,warung" {
+		t.Fatalf("unexpected normalised company: %q %v", *typeBusiness, categories)
+	}
+}
+
+func TestCategoryService_ExpandCategories(t *testing.T) {
+	svc, _ := newTestCategoryService(t)
+
+	if got := strings.Join(svc.expandCategories([]string{"resto"}), ","); got != "restaurant,resto,rumah_makan" {
+		t.Fatalf("expected a category widened to its synonyms, got %s", got)
+	}
+	got := svc.expandCategories([]string{"food_and_drink", "bakery"})
+	for _, want := range []string{"food_and_drink", "restaurant", "rumah_makan", "cafe", "kafe", "warung", "bakery"} {
+		if !strings.Contains(","+strings.Join(got, ",")+",", ","+want+",") {
+			t.Fatalf("expected %s in the expansion of a parent category, got %v", want, got)
+		}
+	}
+	if got := svc.expandCategories([]string{"warung"}); len(got) != 1 || got[0] != "warung" {
+		t.Fatalf("expected an ambiguous synonym kept as is, got %v", got)
+	}
+}
+
+func TestCategoryService_List(t *testing.T) {
+	svc, _ := newTestCategoryService(t)
+
+	if got := svc.List(""); len(got) != 4 {
+		t.Fatalf("expected every category, got %d", len(got))
+	}
+	got := svc.List("Rumah")
+	if len(got) != 1 || got[0].Name != "restaurant" {
+		t.Fatalf("expected a synonym prefix to find its category, got %+v", got)
+	}
+}
+
+func TestCategoryService_Write(t *testing.T) {
+	svc, repo := newTestCategoryService(t)
+
+	category, err := svc.Create(context.Background(), dto.BusinessCategoryRequest{Label: " Dentist ", Synonyms: []string{"Dokter  Gigi", "dentist", "dokter gigi"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if category.Name != "dentist" || strings.Join(category.Synonyms, ",") != "dokter gigi" {
+		t.Fatalf("expected the name derived from the label and synonyms deduplicated, got %+v", category)
+	}
+	if got := svc.NormalizeTypeBusiness("dokter gigi"); got != "Dentist" {
+		t.Fatalf("expected the new category to apply at once, got %q", got)
+	}
+	if _, err := svc.Create(context.Background(), dto.BusinessCategoryRequest{Name: "Dentist", Label: "Dentists"}); !errors.Is(err, ErrBusinessCategoryExists) {
+		t.Fatalf("expected ErrBusinessCategoryExists, got %v", err)
+	}
+
+	invalid := []dto.BusinessCategoryRequest{
+		{Name: "bakery"},
+		{Label: "Bakery", ParentID: stringPtr("not-a-uuid")},
+	}
+	for _, req := range invalid {
+		if _, err := svc.Create(context.Background(), req); !errors.Is(err, ErrInvalidBusinessCategory) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+
+	cycle := dto.BusinessCategoryRequest{Label: "Food & Drink", ParentID: stringPtr(testRestaurantCategoryID.String())}
+	if _, err := svc.Update(context.Background(), testFoodCategoryID.String(), cycle); !errors.Is(err, ErrInvalidBusinessCategory) {
+		t.Fatalf("expected a category moved below its descendant to be rejected, got %v", err)
+	}
+	if _, err := svc.Update(context.Background(), "nope", cycle); !errors.Is(err, ErrInvalidBusinessCategoryID) {
+		t.Fatalf("expected ErrInvalidBusinessCategoryID, got %v", err)
+	}
+	if err := svc.Delete(context.Background(), uuid.NewString()); !errors.Is(err, ErrBusinessCategoryNotFound) {
+		t.Fatalf("expected ErrBusinessCategoryNotFound, got %v", err)
+	}
+	if len(repo.categories) != 5 {
+		t.Fatalf("expected only the valid category stored, got %d", len(repo.categories))
+	}
+}
+
+func TestCategoryService_NormalizesCompanies(t *testing.T) {
+	categories, _ := newTestCategoryService(t)
+
+	var received []repository.BulkUpsertCompanyInput
+	var listed dto.ListFilter
+	companies := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+		list: func(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+			listed = filter
+			return nil, nil
+		},
+	}
+	svc := NewCompaniesService(companies, WithCategories(categories))
+
+	csv := strings.Join(requiredCSVHeaders, ",") + "\n" + "Sate Khas Senayan,Jl. Kebon Sirih,,,,,resto,Jakarta,Indonesia\n"
+	if _, err := svc.ImportCompaniesCSV(context.Background(), strings.NewReader(csv), repository.ImportModeOverwrite, nil); err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+	if len(received) != 1 || *received[0].TypeBusiness != "Restaurant" || strings.Join(received[0].Categories, ",") != "restaurant" {
+		t.Fatalf("expected the imported business type normalised, got %+v", received)
+	}
+
+	if _, err := svc.ListCompanies(context.Background(), dto.ListFilter{Categories: []string{"rumah_makan"}}); err != nil {
+		t.Fatalf("unexpected list error: %v", err)
+	}
+	if strings.Join(listed.Categories, ",") != "restaurant,resto,rumah_makan" {
+		t.Fatalf("expected the category filter to match synonyms, got %v", listed.Categories)
+	}
+
+	runs := newMockIngestRunsRepository()
+	ingest := NewIngestRunsService(runs, WithIngestCategories(categories))
+	items := []dto.IngestItem{{Name: "Kopi Tuku", TypeBusiness: stringPtr("Kafe"), RawSnapshot: json.RawMessage(`{"place_id":"ChIJabc","types":["cafe","food"]}`)}}
+	if _, err := ingest.AppendBatch(context.Background(), uuid.NewString(), dto.IngestBatchRequest{Sequence: intPtr(0), Items: items}); err != nil {
+		t.Fatalf("unexpected ingest error: %v", err)
+	}
+	if company := runs.companies[0]; *company.TypeBusiness != "Cafe" || strings.Join(company.Categories, ",") != "cafe,food" {
+		t.Fatalf("expected the scraped business type normalised, got %q %v", *company.TypeBusiness, company.Categories)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// Business status filter values accepted by company listings.
+const (
+	BusinessStatusOperational       = "operational"
+	BusinessStatusClosed            = "closed"
+	BusinessStatusClosedTemporarily = "closed_temporarily"
+	BusinessStatusClosedPermanently = "closed_permanently"
+	BusinessStatusAll               = "all"
+)
+
+const (
+	// DefaultClosureWindow is how far back the newly closed report looks when no start is given.
+	DefaultClosureWindow = 30 * 24 * time.Hour
+	defaultClosureAreas  = 100
+	maxClosureAreas      = 1000
+)
+
+// ErrInvalidClosureWindow is returned when the report start lies in the future.
+var ErrInvalidClosureWindow = errors.New("since must not be in the future")
+
+// NormalizeBusinessStatus lowercases a status filter and reports whether it is supported.
+func NormalizeBusinessStatus(raw string) (string, bool) {
+	status := strings.ToLower(strings.TrimSpace(raw))
+	switch status {
+	case BusinessStatusOperational, BusinessStatusClosed, BusinessStatusClosedTemporarily, BusinessStatusClosedPermanently, BusinessStatusAll:
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+// BusinessStatusService reports on businesses that opened or closed.
+type BusinessStatusService struct {
+	repo repository.BusinessStatusRepository
+	now  func() time.Time
+}
+
+// NewBusinessStatusService builds a new BusinessStatusService instance.
+func NewBusinessStatusService(repo repository.BusinessStatusRepository) *BusinessStatusService {
+	return &BusinessStatusService{repo: repo, now: time.Now}
+}
+
+// NewlyClosedReport groups businesses that switched to a closed status since the given time per area.
+// A nil since defaults to DefaultClosureWindow ago; limit caps the number of areas returned.
+func (s *BusinessStatusService) NewlyClosedReport(ctx context.Context, since *time.Time, country, city string, limit int) (*entity.ClosureReport, error) {
+	now := s.now()
+	start := now.Add(-DefaultClosureWindow)
+	if since != nil {
+		if since.After(now) {
+			return nil, ErrInvalidClosureWindow
+		}
+		start = *since
+	}
+	if limit <= 0 {
+		limit = defaultClosureAreas
+	}
+	if limit > maxClosureAreas {
+		limit = maxClosureAreas
+	}
+
+	areas, err := s.repo.ListNewlyClosedAreas(ctx, repository.ClosureReportFilter{
+		Since:   start,
+		Country: strings.TrimSpace(country),
+		City:    strings.TrimSpace(city),
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.ClosureReport{Since: start, Areas: areas}
+	for _, area := range areas {
+		report.Total += area.NewlyClosed
+	}
+	return report, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockBusinessStatusRepository struct {
+	filter repository.ClosureReportFilter
+	areas  []entity.ClosureArea
+}
+
+func (m *mockBusinessStatusRepository) ListNewlyClosedAreas(ctx context.Context, filter repository.ClosureReportFilter) ([]entity.ClosureArea, error) {
+	m.filter = filter
+	return m.areas, nil
+}
+
+func TestNormalizeBusinessStatus(t *testing.T) {
+	if status, ok := NormalizeBusinessStatus(" Closed_Permanently "); !ok || status != BusinessStatusClosedPermanently {
+		t.Fatalf("expected closed_permanently, got %q %v", status, ok)
+	}
+	if _, ok := NormalizeBusinessStatus("open"); ok {
+		t.Fatalf("expected unsupported status to be rejected")
+	}
+}
+
+func TestBusinessStatusService_NewlyClosedReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockBusinessStatusRepository{areas: []entity.ClosureArea{
+		{Country: "Indonesia", City: "Jakarta", NewlyClosed: 4},
+		{Country: "Indonesia", City: "Bandung", NewlyClosed: 1},
+	}}
+	svc := NewBusinessStatusService(repo)
+	svc.now = func() time.Time { return now }
+
+	report, err := svc.NewlyClosedReport(context.Background(), nil, " Indonesia ", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Total != 5 || len(report.Areas) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if !repo.filter.Since.Equal(now.Add(-DefaultClosureWindow)) || repo.filter.Country != "Indonesia" || repo.filter.Limit != defaultClosureAreas {
+		t.Fatalf("unexpected filter: %+v", repo.filter)
+	}
+
+	future := now.Add(time.Hour)
+	if _, err := svc.NewlyClosedReport(context.Background(), &future, "", "", 0); !errors.Is(err, ErrInvalidClosureWindow) {
+		t.Fatalf("expected ErrInvalidClosureWindow, got %v", err)
+	}
+}
@@ -0,0 +1,532 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/mailer"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// maxCampaignRecipients bounds the companies one campaign may mail.
+	maxCampaignRecipients = 10000
+	// maxCampaignBody bounds the size of a body template, in bytes.
+	maxCampaignBody = 100 << 10
+	// DefaultCampaignBatchSize is how many messages a pass of the send loop delivers by default.
+	DefaultCampaignBatchSize = 50
+	// campaignTokenTag is the message tag carrying the tracking token back in provider webhooks.
+	campaignTokenTag = "campaign_token"
+)
+
+// campaignVariables lists the template variables; unsubscribe_url is filled in for each message.
+var campaignVariables = []string{"company", "city", "country", "type_business", "website", "unsubscribe_url"}
+
+// campaignVariablePattern matches a {{variable}} of a campaign template.
+var campaignVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_]+)\s*\}\}`)
+
+// campaignRecipientStatuses lists the statuses recipients can be filtered by.
+var campaignRecipientStatuses = []string{
+	entity.RecipientStatusQueued, entity.RecipientStatusSending, entity.RecipientStatusSent,
+	entity.RecipientStatusOpened, entity.RecipientStatusBounced, entity.RecipientStatusComplained,
+	entity.RecipientStatusUnsubscribed, entity.RecipientStatusFailed, entity.RecipientStatusSuppressed,
+}
+
+// undeliverableEvents maps provider events to the recipient status and suppression reason they set.
+var undeliverableEvents = map[string]struct{ status, reason string }{
+	mailer.EventBounce:      {entity.RecipientStatusBounced, entity.SuppressionBounce},
+	mailer.EventComplaint:   {entity.RecipientStatusComplained, entity.SuppressionComplaint},
+	mailer.EventUnsubscribe: {entity.RecipientStatusUnsubscribed, entity.SuppressionUnsubscribe},
+}
+
+var (
+	// ErrInvalidCampaign is returned when a campaign or a recipient filter fails validation.
+	ErrInvalidCampaign = errors.New("invalid campaign")
+	// ErrInvalidCampaignID is returned when a campaign id is not a UUID.
+	ErrInvalidCampaignID = errors.New("invalid campaign id")
+	// ErrCampaignNotFound is returned when a campaign does not exist.
+	ErrCampaignNotFound = errors.New("campaign not found")
+	// ErrCampaignNotDraft is returned when a campaign that already started is sent again.
+	ErrCampaignNotDraft = errors.New("campaign has already been sent")
+	// ErrCampaignFilterRequired is returned when a campaign would mail every company.
+	ErrCampaignFilterRequired = errors.New("campaign needs at least one filter")
+	// ErrCampaignNoRecipients is returned when no company matching a campaign has an email.
+	ErrCampaignNoRecipients = errors.New("no company matching the campaign filter has an email")
+	// ErrCampaignTooLarge is returned when a campaign matches more companies than it may mail.
+	ErrCampaignTooLarge = fmt.Errorf("campaign matches more than %d companies", maxCampaignRecipients)
+	// ErrCampaignSenderUnavailable is returned when campaigns are sent without an e-mail provider.
+	ErrCampaignSenderUnavailable = errors.New("campaign e-mail provider not configured")
+	// ErrCampaignTokenNotFound is returned for open and unsubscribe links of no sent message.
+	ErrCampaignTokenNotFound = errors.New("campaign link not found")
+	// ErrCampaignWebhookUnavailable is returned for events of a provider without a webhook key.
+	ErrCampaignWebhookUnavailable = errors.New("campaign webhook not configured")
+	// ErrInvalidWebhookSignature is returned when a webhook request is not signed by the provider.
+	ErrInvalidWebhookSignature = mailer.ErrInvalidSignature
+	// ErrInvalidWebhookPayload is returned when a signed webhook request cannot be read.
+	ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+)
+
+// CampaignService creates outreach campaigns, sends them through the configured e-mail provider and
+// tracks opens, bounces, complaints and unsubscribes against the do-not-contact list.
+type CampaignService struct {
+	repo  repository.CampaignsRepository
+	leads repository.CampaignLeadsRepository
+	// suppressions, when set, keeps companies with any contact on the do-not-contact list from
+	// being mailed; the repository only checks the recipient address.
+	suppressions *ContactSuppressionService
+	sender       mailer.MessageSender
+	publicURL    string
+	batchSize    int
+	webhooks     map[string]mailer.EventSource
+}
+
+// CampaignServiceOption configures optional CampaignService features.
+type CampaignServiceOption func(*CampaignService)
+
+// WithCampaignSender sends campaigns through sender, batchSize messages per pass of the send loop.
+// publicURL is where recipients reach the API for the open pixel and unsubscribe links.
+func WithCampaignSender(sender mailer.MessageSender, publicURL string, batchSize int) CampaignServiceOption {
+	return func(s *CampaignService) {
+		s.sender = sender
+		s.publicURL = strings.TrimRight(publicURL, "/")
+		if batchSize > 0 {
+			s.batchSize = batchSize
+		}
+	}
+}
+
+// WithCampaignWebhook reads the delivery events provider posts to its campaign webhook.
+func WithCampaignWebhook(provider string, source mailer.EventSource) CampaignServiceOption {
+	return func(s *CampaignService) {
+		s.webhooks[provider] = source
+	}
+}
+
+// WithCampaignSuppressions keeps campaigns from mailing companies with any contact on the
+// do-not-contact list, such as a suppressed phone number or website domain.
+func WithCampaignSuppressions(suppressions *ContactSuppressionService) CampaignServiceOption {
+	return func(s *CampaignService) {
+		s.suppressions = suppressions
+	}
+}
+
+// NewCampaignService builds a CampaignService; without WithCampaignSender campaigns can be created
+// but not sent.
+func NewCampaignService(repo repository.CampaignsRepository, leads repository.CampaignLeadsRepository, opts ...CampaignServiceOption) *CampaignService {
+	s := &CampaignService{
+		repo:      repo,
+		leads:     leads,
+		batchSize: DefaultCampaignBatchSize,
+		webhooks:  make(map[string]mailer.EventSource),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// checkCampaignTemplate refuses templates using unknown variables.
+func checkCampaignTemplate(field, template string) error {
+	for _, match := range campaignVariablePattern.FindAllStringSubmatch(template, -1) {
+		if name := strings.ToLower(match[1]); !slices.Contains(campaignVariables, name) {
+			return fmt.Errorf("%w: unknown %s variable {{%s}} (use %s)", ErrInvalidCampaign, field, match[1], strings.Join(campaignVariables, ", "))
+		}
+	}
+	return nil
+}
+
+// renderCampaignTemplate fills the variables of template with values passed through escape.
+func renderCampaignTemplate(template string, values map[string]string, escape func(string) string) string {
+	return campaignVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := strings.ToLower(campaignVariablePattern.FindStringSubmatch(match)[1])
+		return escape(values[name])
+	})
+}
+
+// usesCampaignVariable reports whether template uses the variable name.
+func usesCampaignVariable(template, name string) bool {
+	for _, match := range campaignVariablePattern.FindAllStringSubmatch(template, -1) {
+		if strings.EqualFold(match[1], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Create validates req and stores a draft campaign mailing the companies matching filter. query is
+// the listing query string filter was parsed from, kept to show what the campaign targets.
+func (s *CampaignService) Create(ctx context.Context, filter dto.ListFilter, query string, req dto.CampaignRequest, createdBy string) (*entity.Campaign, error) {
+	campaign := &entity.Campaign{
+		Name:    strings.TrimSpace(req.Name),
+		Query:   query,
+		Subject: strings.TrimSpace(req.Subject),
+		Body:    req.Body,
+	}
+	switch {
+	case campaign.Name == "" || utf8.RuneCountInString(campaign.Name) > 200:
+		return nil, fmt.Errorf("%w: name is required and must be at most 200 characters", ErrInvalidCampaign)
+	case campaign.Subject == "" || utf8.RuneCountInString(campaign.Subject) > 250:
+		return nil, fmt.Errorf("%w: subject is required and must be at most 250 characters", ErrInvalidCampaign)
+	case strings.ContainsAny(campaign.Subject, "\r\n"):
+		return nil, fmt.Errorf("%w: subject must be a single line", ErrInvalidCampaign)
+	case strings.TrimSpace(campaign.Body) == "" || len(campaign.Body) > maxCampaignBody:
+		return nil, fmt.Errorf("%w: body is required and must be at most %d KB", ErrInvalidCampaign, maxCampaignBody>>10)
+	}
+	if err := checkCampaignTemplate("subject", campaign.Subject); err != nil {
+		return nil, err
+	}
+	if err := checkCampaignTemplate("body", campaign.Body); err != nil {
+		return nil, err
+	}
+
+	// Recipients are resolved when sending starts, from every page of the filter.
+	filter.Page, filter.PerPage, filter.Limit = 0, 0, 0
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("encode campaign filter: %w", err)
+	}
+	campaign.Filter = encoded
+	if id, err := uuid.Parse(createdBy); err == nil {
+		campaign.CreatedBy = &id
+	}
+	if err := s.repo.CreateCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// Get returns a campaign with its recipient counts.
+func (s *CampaignService) Get(ctx context.Context, id string) (*entity.Campaign, error) {
+	campaignID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCampaignID
+	}
+	campaign, err := s.repo.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, campaignError(err)
+	}
+	return campaign, nil
+}
+
+// List returns a page of campaigns, newest first.
+func (s *CampaignService) List(ctx context.Context, page, perPage int) ([]entity.Campaign, error) {
+	limit, offset := pageBounds(page, perPage)
+	return s.repo.ListCampaigns(ctx, limit, offset)
+}
+
+// Recipients returns a page of the recipients of a campaign, only those with status when set.
+func (s *CampaignService) Recipients(ctx context.Context, id, status string, page, perPage int) ([]entity.CampaignRecipient, error) {
+	campaignID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCampaignID
+	}
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && !slices.Contains(campaignRecipientStatuses, status) {
+		return nil, fmt.Errorf("%w: unknown status %q (use %s)", ErrInvalidCampaign, status, strings.Join(campaignRecipientStatuses, ", "))
+	}
+	if _, err := s.repo.GetCampaign(ctx, campaignID); err != nil {
+		return nil, campaignError(err)
+	}
+	limit, offset := pageBounds(page, perPage)
+	return s.repo.ListCampaignRecipients(ctx, campaignID, status, limit, offset)
+}
+
+// pageBounds turns a page and page size into a limit and offset, 20 and at most 100 per page.
+func pageBounds(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return perPage, (page - 1) * perPage
+}
+
+// Send resolves the recipients of a draft campaign, one email per company matching its filter, and
+// queues them for the send loop. Suppressed contacts are recorded but never mailed.
+func (s *CampaignService) Send(ctx context.Context, id string) (*entity.Campaign, error) {
+	if s.sender == nil {
+		return nil, ErrCampaignSenderUnavailable
+	}
+	campaign, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != entity.CampaignStatusDraft {
+		return nil, ErrCampaignNotDraft
+	}
+	var filter dto.ListFilter
+	if err := json.Unmarshal(campaign.Filter, &filter); err != nil {
+		return nil, fmt.Errorf("decode campaign filter: %w", err)
+	}
+
+	leads, err := s.leads.ListCampaignLeads(ctx, filter, maxCampaignRecipients+1)
+	if err != nil {
+		return nil, campaignError(err)
+	}
+	if len(leads) > maxCampaignRecipients {
+		return nil, ErrCampaignTooLarge
+	}
+	recipients := campaignRecipients(leads)
+	if len(recipients) == 0 {
+		return nil, ErrCampaignNoRecipients
+	}
+	if s.suppressions != nil {
+		if err := s.markSuppressedRecipients(ctx, recipients); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.QueueCampaign(ctx, campaign.ID, recipients); err != nil {
+		return nil, campaignError(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// campaignRecipients picks the first valid email of each lead.
+func campaignRecipients(leads []entity.CampaignLead) []entity.CampaignRecipient {
+	recipients := make([]entity.CampaignRecipient, 0, len(leads))
+	for _, lead := range leads {
+		for _, email := range lead.Emails {
+			email = strings.ToLower(strings.TrimSpace(email))
+			if !emailPattern.MatchString(email) {
+				continue
+			}
+			companyID := lead.CompanyID
+			recipients = append(recipients, entity.CampaignRecipient{CompanyID: &companyID, Email: email, Variables: lead.Variables})
+			break
+		}
+	}
+	return recipients
+}
+
+// markSuppressedRecipients marks the recipients of companies on the do-not-contact list as
+// suppressed.
+func (s *CampaignService) markSuppressedRecipients(ctx context.Context, recipients []entity.CampaignRecipient) error {
+	ids := make([]uuid.UUID, 0, len(recipients))
+	for _, recipient := range recipients {
+		ids = append(ids, *recipient.CompanyID)
+	}
+	suppressed, err := s.suppressions.SuppressedCompanies(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range recipients {
+		if suppressed[*recipients[i].CompanyID] {
+			recipients[i].Status = entity.RecipientStatusSuppressed
+		}
+	}
+	return nil
+}
+
+// SendBatch delivers the next batch of queued messages, then finishes campaigns with none left, and
+// returns how many messages the provider accepted. A refused message is recorded as failed and
+// not retried.
+func (s *CampaignService) SendBatch(ctx context.Context) (int, error) {
+	if s.sender == nil {
+		return 0, ErrCampaignSenderUnavailable
+	}
+	deliveries, err := s.repo.ClaimCampaignDeliveries(ctx, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	// Outcomes are recorded even when shutdown cancels ctx mid-batch.
+	record := context.WithoutCancel(ctx)
+	sent := 0
+	for _, delivery := range deliveries {
+		if delivery.Recipient.Status != entity.RecipientStatusSending {
+			continue
+		}
+		id := delivery.Recipient.ID
+		token, err := newSecretToken()
+		if err != nil {
+			return sent, err
+		}
+		messageID, err := s.sender.SendMessage(ctx, s.campaignMessage(delivery, token))
+		if err != nil {
+			if markErr := s.repo.MarkRecipientFailed(record, id, err.Error()); markErr != nil {
+				log.Printf("failed to record campaign delivery failure of %s: %v", id, markErr)
+			}
+			continue
+		}
+		if err := s.repo.MarkRecipientSent(record, id, hashSecretToken(token), messageID); err != nil {
+			log.Printf("failed to record campaign delivery of %s: %v", id, err)
+		}
+		sent++
+	}
+	if _, err := s.repo.FinishCampaigns(record); err != nil {
+		return sent, err
+	}
+	return sent, nil
+}
+
+// RunSender sends a batch of campaign messages every interval until ctx is done, which paces
+// delivery at the batch size per interval.
+func (s *CampaignService) RunSender(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.SendBatch(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to send campaign messages: %v", err)
+			}
+		}
+	}
+}
+
+// campaignMessage renders the message of a delivery. The plain text part gets an unsubscribe line
+// unless the body places {{unsubscribe_url}} itself; the HTML part always ends with an unsubscribe
+// link and the open tracking pixel.
+func (s *CampaignService) campaignMessage(delivery entity.CampaignDelivery, token string) mailer.Message {
+	unsubscribeURL := s.publicURL + "/campaigns/unsubscribe/" + token
+	values := maps.Clone(delivery.Recipient.Variables)
+	if values == nil {
+		values = map[string]string{}
+	}
+	values["unsubscribe_url"] = unsubscribeURL
+	plain := func(value string) string { return value }
+
+	text := renderCampaignTemplate(delivery.Body, values, plain)
+	if !usesCampaignVariable(delivery.Body, "unsubscribe_url") {
+		text += "\n\n--\nUnsubscribe: " + unsubscribeURL
+	}
+	htmlBody := strings.ReplaceAll(renderCampaignTemplate(html.EscapeString(delivery.Body), values, html.EscapeString), "\n", "<br>\n")
+	htmlBody = "<html><body>" + htmlBody +
+		`<p style="font-size:12px;color:#888"><a href="` + html.EscapeString(unsubscribeURL) + `">Unsubscribe</a></p>` +
+		`<img src="` + html.EscapeString(s.publicURL+"/campaigns/open/"+token) + `" width="1" height="1" alt="">` +
+		"</body></html>"
+
+	return mailer.Message{
+		To:      delivery.Recipient.Email,
+		Subject: strings.Join(strings.Fields(renderCampaignTemplate(delivery.Subject, values, plain)), " "),
+		Text:    text,
+		HTML:    htmlBody,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+		Tags: map[string]string{campaignTokenTag: token},
+	}
+}
+
+// TrackOpen records that the message of token was opened.
+func (s *CampaignService) TrackOpen(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrCampaignTokenNotFound
+	}
+	matched, err := s.repo.MarkRecipientOpened(ctx, hashSecretToken(token))
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return ErrCampaignTokenNotFound
+	}
+	return nil
+}
+
+// Unsubscribe suppresses the address the message of token was sent to.
+func (s *CampaignService) Unsubscribe(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrCampaignTokenNotFound
+	}
+	matched, err := s.repo.MarkRecipientUndeliverable(ctx, hashSecretToken(token), entity.RecipientStatusUnsubscribed, entity.SuppressionUnsubscribe)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return ErrCampaignTokenNotFound
+	}
+	return nil
+}
+
+// HandleWebhook verifies and applies the delivery events provider posted, and returns how many
+// were applied. Bounces, complaints and unsubscribes suppress the address even when the message
+// is not one of ours, such as an unsubscribe from the provider's own preference page.
+func (s *CampaignService) HandleWebhook(ctx context.Context, provider string, header http.Header, body []byte) (int, error) {
+	source, ok := s.webhooks[provider]
+	if !ok {
+		return 0, ErrCampaignWebhookUnavailable
+	}
+	events, err := source.ParseEvents(header, body)
+	if err != nil {
+		if errors.Is(err, mailer.ErrInvalidSignature) {
+			return 0, ErrInvalidWebhookSignature
+		}
+		return 0, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	applied := 0
+	for _, event := range events {
+		ok, err := s.applyEvent(ctx, event)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// applyEvent records a delivery event and reports whether it matched a message or an address.
+func (s *CampaignService) applyEvent(ctx context.Context, event mailer.Event) (bool, error) {
+	token := event.Tags[campaignTokenTag]
+	if event.Type == mailer.EventOpen {
+		if token == "" {
+			return false, nil
+		}
+		return s.repo.MarkRecipientOpened(ctx, hashSecretToken(token))
+	}
+	outcome, ok := undeliverableEvents[event.Type]
+	if !ok {
+		return false, nil
+	}
+	if token != "" {
+		matched, err := s.repo.MarkRecipientUndeliverable(ctx, hashSecretToken(token), outcome.status, outcome.reason)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	email := strings.ToLower(strings.TrimSpace(event.Email))
+	if !emailPattern.MatchString(email) {
+		return false, nil
+	}
+	if err := s.repo.SuppressEmail(ctx, &entity.ContactSuppression{Value: email, Reason: outcome.reason}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// campaignError maps repository campaign errors to service errors.
+func campaignError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCampaignNotFound):
+		return ErrCampaignNotFound
+	case errors.Is(err, repository.ErrCampaignNotDraft):
+		return ErrCampaignNotDraft
+	case errors.Is(err, repository.ErrCampaignFilterRequired):
+		return ErrCampaignFilterRequired
+	default:
+		return err
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/mailer"
+)
+
+type stubCampaignsRepo struct {
+	campaign     *entity.Campaign
+	queued       []entity.CampaignRecipient
+	deliveries   []entity.CampaignDelivery
+	sent         map[uuid.UUID]string
+	failed       map[uuid.UUID]string
+	finished     bool
+	undelivered  map[string]string
+	suppressions []entity.ContactSuppression
+}
+
+func (s *stubCampaignsRepo) CreateCampaign(ctx context.Context, campaign *entity.Campaign) error {
+	campaign.ID, campaign.Status = uuid.New(), entity.CampaignStatusDraft
+	s.campaign = campaign
+	return nil
+}
+
+func (s *stubCampaignsRepo) GetCampaign(ctx context.Context, id uuid.UUID) (*entity.Campaign, error) {
+	if s.campaign == nil || s.campaign.ID != id {
+		return nil, ErrCampaignNotFound
+	}
+	copied := *s.campaign
+	return &copied, nil
+}
+
+func (s *stubCampaignsRepo) ListCampaigns(ctx context.Context, limit, offset int) ([]entity.Campaign, error) {
+	return nil, nil
+}
+
+func (s *stubCampaignsRepo) QueueCampaign(ctx context.Context, id uuid.UUID, recipients []entity.CampaignRecipient) error {
+	s.queued = recipients
+	s.campaign.Status = entity.CampaignStatusSending
+	return nil
+}
+
+func (s *stubCampaignsRepo) ListCampaignRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]entity.CampaignRecipient, error) {
+	return nil, nil
+}
+
+func (s *stubCampaignsRepo) ClaimCampaignDeliveries(ctx context.Context, limit int) ([]entity.CampaignDelivery, error) {
+	return s.deliveries, nil
+}
+
+func (s *stubCampaignsRepo) MarkRecipientSent(ctx context.Context, id uuid.UUID, tokenHash, messageID string) error {
+	s.sent[id] = tokenHash
+	return nil
+}
+
+func (s *stubCampaignsRepo) MarkRecipientFailed(ctx context.Context, id uuid.UUID, message string) error {
+	s.failed[id] = message
+	return nil
+}
+
+func (s *stubCampaignsRepo) FinishCampaigns(ctx context.Context) (int64, error) {
+	s.finished = true
+	return 1, nil
+}
+
+func (s *stubCampaignsRepo) MarkRecipientOpened(ctx context.Context, tokenHash string) (bool, error) {
+	return false, nil
+}
+
+func (s *stubCampaignsRepo) MarkRecipientUndeliverable(ctx context.Context, tokenHash, status, reason string) (bool, error) {
+	if _, ok := s.undelivered[tokenHash]; !ok {
+		return false, nil
+	}
+	s.undelivered[tokenHash] = status
+	return true, nil
+}
+
+func (s *stubCampaignsRepo) SuppressEmail(ctx context.Context, suppression *entity.ContactSuppression) error {
+	s.suppressions = append(s.suppressions, *suppression)
+	return nil
+}
+
+type stubCampaignLeads struct {
+	filter dto.ListFilter
+	leads  []entity.CampaignLead
+}
+
+func (s *stubCampaignLeads) ListCampaignLeads(ctx context.Context, filter dto.ListFilter, limit int) ([]entity.CampaignLead, error) {
+	s.filter = filter
+	return s.leads, nil
+}
+
+type stubMessageSender struct {
+	messages []mailer.Message
+	refuse   string
+}
+
+func (s *stubMessageSender) SendMessage(ctx context.Context, msg mailer.Message) (string, error) {
+	if msg.To == s.refuse {
+		return "", errors.New("mailbox unavailable")
+	}
+	s.messages = append(s.messages, msg)
+	return "<id@example.com>", nil
+}
+
+type stubEventSource struct {
+	events []mailer.Event
+}
+
+func (s stubEventSource) ParseEvents(header http.Header, body []byte) ([]mailer.Event, error) {
+	return s.events, nil
+}
+
+func TestCampaignService_Create(t *testing.T) {
+	repo := &stubCampaignsRepo{}
+	svc := NewCampaignService(repo, nil)
+	city := "Jakarta"
+	filter := dto.ListFilter{City: city, Page: 3, PerPage: 50}
+
+	_, err := svc.Create(context.Background(), filter, "city=Jakarta", dto.CampaignRequest{Name: "Launch", Subject: "Hi {{owner}}", Body: "Hello"}, "")
+	if !errors.Is(err, ErrInvalidCampaign) || !strings.Contains(err.Error(), "{{owner}}") {
+		t.Fatalf("expected an unknown variable to be refused, got %v", err)
+	}
+	if _, err := svc.Create(context.Background(), filter, "", dto.CampaignRequest{Name: "Launch", Subject: "Hi\r\nBcc: x@example.com", Body: "Hello"}, ""); !errors.Is(err, ErrInvalidCampaign) {
+		t.Fatalf("expected a multi-line subject to be refused, got %v", err)
+	}
+
+	campaign, err := svc.Create(context.Background(), filter, "city=Jakarta", dto.CampaignRequest{Name: " Launch ", Subject: "Hi {{ company }}", Body: "Hello from {{city}}"}, uuid.NewString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var stored dto.ListFilter
+	if err := json.Unmarshal(campaign.Filter, &stored); err != nil {
+		t.Fatalf("decode filter: %v", err)
+	}
+	if campaign.Name != "Launch" || campaign.CreatedBy == nil || stored.City != city || stored.Page != 0 || stored.PerPage != 0 {
+		t.Fatalf("unexpected campaign %+v with filter %+v", campaign, stored)
+	}
+}
+
+func TestCampaignService_Send(t *testing.T) {
+	repo := &stubCampaignsRepo{}
+	leads := &stubCampaignLeads{leads: []entity.CampaignLead{
+		{CompanyID: uuid.New(), Emails: []string{"not-an-email", " Info@Example.com "}},
+		{CompanyID: uuid.New(), Emails: nil},
+		{CompanyID: uuid.New(), Emails: []string{"sales@example.org", "ceo@example.org"}},
+	}}
+
+	unconfigured := NewCampaignService(repo, leads)
+	if _, err := unconfigured.Send(context.Background(), uuid.NewString()); !errors.Is(err, ErrCampaignSenderUnavailable) {
+		t.Fatalf("expected sending to need a provider, got %v", err)
+	}
+
+	svc := NewCampaignService(repo, leads, WithCampaignSender(&stubMessageSender{}, "https://api.example.com", 10))
+	campaign, err := svc.Create(context.Background(), dto.ListFilter{City: "Jakarta"}, "city=Jakarta", dto.CampaignRequest{Name: "Launch", Subject: "Hi", Body: "Hello"}, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	sent, err := svc.Send(context.Background(), campaign.ID.String())
+	if err != nil || sent.Status != entity.CampaignStatusSending {
+		t.Fatalf("expected the campaign queued, got %+v (%v)", sent, err)
+	}
+	if leads.filter.City != "Jakarta" {
+		t.Fatalf("expected the stored filter, got %+v", leads.filter)
+	}
+	if len(repo.queued) != 2 || repo.queued[0].Email != "info@example.com" || repo.queued[1].Email != "sales@example.org" {
+		t.Fatalf("expected the first valid email of each company, got %+v", repo.queued)
+	}
+
+	if _, err := svc.Send(context.Background(), campaign.ID.String()); !errors.Is(err, ErrCampaignNotDraft) {
+		t.Fatalf("expected a second send to be refused, got %v", err)
+	}
+}
+
+func TestCampaignService_SendBatch(t *testing.T) {
+	delivered, refused, suppressed := uuid.New(), uuid.New(), uuid.New()
+	repo := &stubCampaignsRepo{
+		sent:   map[uuid.UUID]string{},
+		failed: map[uuid.UUID]string{},
+		deliveries: []entity.CampaignDelivery{
+			{
+				Recipient: entity.CampaignRecipient{ID: delivered, Email: "info@example.com", Status: entity.RecipientStatusSending, Variables: map[string]string{"company": "Kopi <Kita>"}},
+				Subject:   "Hello {{company}}",
+				Body:      "Hi {{company}},\nsee you",
+			},
+			{
+				Recipient: entity.CampaignRecipient{ID: refused, Email: "gone@example.com", Status: entity.RecipientStatusSending},
+				Subject:   "Hello",
+				Body:      "Hi, unsubscribe at {{unsubscribe_url}}",
+			},
+			{Recipient: entity.CampaignRecipient{ID: suppressed, Email: "stop@example.com", Status: entity.RecipientStatusSuppressed}},
+		},
+	}
+	sender := &stubMessageSender{refuse: "gone@example.com"}
+	svc := NewCampaignService(repo, nil, WithCampaignSender(sender, "https://api.example.com", 10))
+
+	sent, err := svc.SendBatch(context.Background())
+	if err != nil || sent != 1 || !repo.finished {
+		t.Fatalf("expected one message sent and campaigns finished, got %d (%v)", sent, err)
+	}
+	if len(sender.messages) != 1 || repo.failed[refused] == "" {
+		t.Fatalf("expected the refused message recorded as failed, got %+v", repo.failed)
+	}
+
+	msg := sender.messages[0]
+	token := msg.Tags[campaignTokenTag]
+	if token == "" || repo.sent[delivered] != hashSecretToken(token) {
+		t.Fatalf("expected the hashed token stored, got %q for token %q", repo.sent[delivered], token)
+	}
+	unsubscribeURL := "https://api.example.com/campaigns/unsubscribe/" + token
+	if msg.Subject != "Hello Kopi <Kita>" || !strings.HasPrefix(msg.Text, "Hi Kopi <Kita>,\nsee you") || !strings.Contains(msg.Text, "Unsubscribe: "+unsubscribeURL) {
+		t.Fatalf("unexpected text message %q / %q", msg.Subject, msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "Hi Kopi &lt;Kita&gt;,<br>") || !strings.Contains(msg.HTML, "/campaigns/open/"+token) {
+		t.Fatalf("expected an escaped HTML part with the tracking pixel, got %q", msg.HTML)
+	}
+	if msg.Headers["List-Unsubscribe"] != "<"+unsubscribeURL+">" || msg.Headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Fatalf("unexpected headers %+v", msg.Headers)
+	}
+}
+
+func TestCampaignService_HandleWebhook(t *testing.T) {
+	repo := &stubCampaignsRepo{undelivered: map[string]string{hashSecretToken("known"): ""}}
+	source := stubEventSource{events: []mailer.Event{
+		{Type: mailer.EventBounce, Email: "info@example.com", Tags: map[string]string{campaignTokenTag: "known"}},
+		{Type: mailer.EventUnsubscribe, Email: " Owner@Example.org "},
+		{Type: mailer.EventOpen, Email: "someone@example.org"},
+	}}
+	svc := NewCampaignService(repo, nil, WithCampaignWebhook("sendgrid", source))
+
+	if _, err := svc.HandleWebhook(context.Background(), "mailgun", nil, nil); !errors.Is(err, ErrCampaignWebhookUnavailable) {
+		t.Fatalf("expected an unconfigured provider to be refused, got %v", err)
+	}
+	applied, err := svc.HandleWebhook(context.Background(), "sendgrid", nil, nil)
+	if err != nil || applied != 2 {
+		t.Fatalf("expected two events applied, got %d (%v)", applied, err)
+	}
+	if repo.undelivered[hashSecretToken("known")] != entity.RecipientStatusBounced {
+		t.Fatalf("expected the recipient bounced, got %+v", repo.undelivered)
+	}
+	if len(repo.suppressions) != 1 || repo.suppressions[0].Value != "owner@example.org" || repo.suppressions[0].Reason != entity.SuppressionUnsubscribe {
+		t.Fatalf("expected the tokenless unsubscribe to suppress the address, got %+v", repo.suppressions)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// DefaultChainMinCities is the number of distinct cities a name or domain must appear in to count as a chain.
+const DefaultChainMinCities = 3
+
+var (
+	// ErrInvalidChainID is returned when the chain identifier cannot be parsed as UUID.
+	ErrInvalidChainID = errors.New("invalid chain id")
+	// ErrChainNotFound indicates the requested chain does not exist.
+	ErrChainNotFound = errors.New("chain not found")
+)
+
+// sharedHostDomains are hosts used by many unrelated businesses, so they never identify a chain.
+var sharedHostDomains = []string{
+	"facebook.com",
+	"m.facebook.com",
+	"instagram.com",
+	"linktr.ee",
+	"wa.me",
+	"api.whatsapp.com",
+	"business.site",
+	"google.com",
+	"sites.google.com",
+	"tokopedia.com",
+	"shopee.co.id",
+	"tiktok.com",
+	"youtube.com",
+	"linkedin.com",
+	"twitter.com",
+	"x.com",
+	"bit.ly",
+	"blogspot.com",
+	"wordpress.com",
+}
+
+// ChainService detects franchise/chain groupings and exposes their rollups.
+type ChainService struct {
+	repo repository.ChainsRepository
+}
+
+// NewChainService builds a new ChainService instance.
+func NewChainService(repo repository.ChainsRepository) *ChainService {
+	return &ChainService{repo: repo}
+}
+
+// DetectChains regroups companies into chains; minCities <= 0 uses DefaultChainMinCities.
+func (s *ChainService) DetectChains(ctx context.Context, minCities int) (repository.ChainDetectionResult, error) {
+	if minCities <= 0 {
+		minCities = DefaultChainMinCities
+	}
+	if minCities < 2 {
+		return repository.ChainDetectionResult{}, fmt.Errorf("min_cities must be at least 2")
+	}
+	return s.repo.DetectChains(ctx, minCities, sharedHostDomains)
+}
+
+// ListChains returns chains largest first, applying the usual pagination defaults.
+func (s *ChainService) ListChains(ctx context.Context, page, perPage int) ([]entity.CompanyChain, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return s.repo.ListChains(ctx, perPage, (page-1)*perPage)
+}
+
+// GetChainRollup returns aggregated metrics for a chain.
+func (s *ChainService) GetChainRollup(ctx context.Context, idRaw string) (*entity.ChainRollup, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidChainID
+	}
+
+	rollup, err := s.repo.GetChainRollup(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrChainNotFound) {
+			return nil, ErrChainNotFound
+		}
+		return nil, err
+	}
+	return rollup, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockChainsRepository struct {
+	minCities   int
+	sharedHosts []string
+	limit       int
+	offset      int
+	rollup      *entity.ChainRollup
+	err         error
+}
+
+func (m *mockChainsRepository) DetectChains(ctx context.Context, minCities int, sharedHosts []string) (repository.ChainDetectionResult, error) {
+	m.minCities = minCities
+	m.sharedHosts = sharedHosts
+	return repository.ChainDetectionResult{Chains: 2, CompaniesUpdated: 7}, m.err
+}
+
+func (m *mockChainsRepository) ListChains(ctx context.Context, limit, offset int) ([]entity.CompanyChain, error) {
+	m.limit = limit
+	m.offset = offset
+	return []entity.CompanyChain{{Name: "Kopi Kenangan"}}, m.err
+}
+
+func (m *mockChainsRepository) GetChainRollup(ctx context.Context, id uuid.UUID) (*entity.ChainRollup, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.rollup, nil
+}
+
+func TestChainService_DetectChains_Defaults(t *testing.T) {
+	repo := &mockChainsRepository{}
+	svc := NewChainService(repo)
+
+	result, err := svc.DetectChains(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.minCities != DefaultChainMinCities {
+		t.Fatalf("expected default min cities, got %d", repo.minCities)
+	}
+	if len(repo.sharedHosts) == 0 {
+		t.Fatalf("expected shared hosts to be excluded")
+	}
+	if result.Chains != 2 || result.CompaniesUpdated != 7 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if _, err := svc.DetectChains(context.Background(), 1); err == nil {
+		t.Fatalf("expected error for min cities below 2")
+	}
+}
+
+func TestChainService_ListChains_Pagination(t *testing.T) {
+	repo := &mockChainsRepository{}
+	svc := NewChainService(repo)
+
+	if _, err := svc.ListChains(context.Background(), 3, 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.limit != 100 || repo.offset != 200 {
+		t.Fatalf("expected capped pagination, got limit=%d offset=%d", repo.limit, repo.offset)
+	}
+}
+
+func TestChainService_GetChainRollup(t *testing.T) {
+	svc := NewChainService(&mockChainsRepository{rollup: &entity.ChainRollup{TotalReviews: 42}})
+
+	rollup, err := svc.GetChainRollup(context.Background(), "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	if err != nil || rollup.TotalReviews != 42 {
+		t.Fatalf("unexpected rollup: %+v, %v", rollup, err)
+	}
+
+	if _, err := svc.GetChainRollup(context.Background(), "bad"); !errors.Is(err, ErrInvalidChainID) {
+		t.Fatalf("expected ErrInvalidChainID, got %v", err)
+	}
+
+	missing := NewChainService(&mockChainsRepository{err: repository.ErrChainNotFound})
+	if _, err := missing.GetChainRollup(context.Background(), "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"); !errors.Is(err, ErrChainNotFound) {
+		t.Fatalf("expected ErrChainNotFound, got %v", err)
+	}
+}
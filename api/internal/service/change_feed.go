@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	defaultChangeFeedLimit = 100
+	maxChangeFeedLimit     = 1000
+)
+
+// ErrInvalidChangeCursor is returned when a change feed cursor cannot be decoded.
+var ErrInvalidChangeCursor = errors.New("invalid change cursor")
+
+// ChangeFeedPage is one page of the change feed. Clients pass NextCursor back as since_cursor and
+// keep the previous cursor when a page comes back empty.
+type ChangeFeedPage struct {
+	Events     []entity.ChangeEvent `json:"events"`
+	NextCursor string               `json:"next_cursor"`
+	HasMore    bool                 `json:"has_more"`
+}
+
+// ChangeFeedService serves incremental company and enrichment changes to downstream systems.
+type ChangeFeedService struct {
+	repo repository.ChangeEventsRepository
+}
+
+// NewChangeFeedService builds a new ChangeFeedService.
+func NewChangeFeedService(repo repository.ChangeEventsRepository) *ChangeFeedService {
+	return &ChangeFeedService{repo: repo}
+}
+
+// Changes returns up to limit events after sinceCursor; an empty cursor starts from the beginning.
+func (s *ChangeFeedService) Changes(ctx context.Context, sinceCursor string, limit int) (*ChangeFeedPage, error) {
+	after, err := DecodeChangeCursor(sinceCursor)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampLimit(limit, defaultChangeFeedLimit, maxChangeFeedLimit)
+
+	// Fetch one extra event to learn whether another page follows.
+	events, err := s.repo.ListChangesAfter(ctx, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ChangeFeedPage{Events: events, NextCursor: EncodeChangeCursor(after)}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.HasMore = true
+	}
+	if len(page.Events) > 0 {
+		page.NextCursor = EncodeChangeCursor(page.Events[len(page.Events)-1].Cursor)
+	}
+	return page, nil
+}
+
+// EncodeChangeCursor renders a cursor as the opaque "<tx>.<id>" string handed to clients.
+func EncodeChangeCursor(cursor entity.ChangeCursor) string {
+	return fmt.Sprintf("%d.%d", cursor.TxID, cursor.ID)
+}
+
+// DecodeChangeCursor parses a cursor produced by EncodeChangeCursor; empty means the start of the feed.
+func DecodeChangeCursor(raw string) (entity.ChangeCursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return entity.ChangeCursor{}, nil
+	}
+	txPart, idPart, ok := strings.Cut(raw, ".")
+	if !ok {
+		return entity.ChangeCursor{}, ErrInvalidChangeCursor
+	}
+	txID, err := strconv.ParseUint(txPart, 10, 64)
+	if err != nil {
+		return entity.ChangeCursor{}, ErrInvalidChangeCursor
+	}
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || id < 0 {
+		return entity.ChangeCursor{}, ErrInvalidChangeCursor
+	}
+	return entity.ChangeCursor{TxID: txID, ID: id}, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type mockChangeEventsRepository struct {
+	events    []entity.ChangeEvent
+	lastAfter entity.ChangeCursor
+	lastLimit int
+}
+
+func (m *mockChangeEventsRepository) ListChangesAfter(ctx context.Context, after entity.ChangeCursor, limit int) ([]entity.ChangeEvent, error) {
+	m.lastAfter = after
+	m.lastLimit = limit
+	if len(m.events) > limit {
+		return m.events[:limit], nil
+	}
+	return m.events, nil
+}
+
+func changeEvent(txID uint64, id int64) entity.ChangeEvent {
+	return entity.ChangeEvent{
+		Cursor:    entity.ChangeCursor{TxID: txID, ID: id},
+		Entity:    entity.ChangeEntityCompany,
+		EntityID:  uuid.New(),
+		Operation: entity.ChangeOperationUpdate,
+	}
+}
+
+func TestChangeCursorRoundTrip(t *testing.T) {
+	cursor := entity.ChangeCursor{TxID: 7521, ID: 1044}
+	encoded := EncodeChangeCursor(cursor)
+	if encoded != "7521.1044" {
+		t.Fatalf("unexpected encoding %q", encoded)
+	}
+	decoded, err := DecodeChangeCursor(encoded)
+	if err != nil || decoded != cursor {
+		t.Fatalf("expected %+v, got %+v (%v)", cursor, decoded, err)
+	}
+	if decoded, err := DecodeChangeCursor(""); err != nil || decoded != (entity.ChangeCursor{}) {
+		t.Fatalf("expected empty cursor to start the feed, got %+v (%v)", decoded, err)
+	}
+	for _, raw := range []string{"abc", "1", "1.x", "-1.2", "1.-2"} {
+		if _, err := DecodeChangeCursor(raw); !errors.Is(err, ErrInvalidChangeCursor) {
+			t.Fatalf("expected %q to be rejected, got %v", raw, err)
+		}
+	}
+}
+
+func TestChangeFeedService_Changes(t *testing.T) {
+	repo := &mockChangeEventsRepository{events: []entity.ChangeEvent{
+		changeEvent(10, 1),
+		changeEvent(10, 2),
+		changeEvent(11, 3),
+	}}
+	svc := NewChangeFeedService(repo)
+
+	page, err := svc.Changes(context.Background(), "9.7", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastAfter != (entity.ChangeCursor{TxID: 9, ID: 7}) || repo.lastLimit != 3 {
+		t.Fatalf("unexpected repository call: after %+v limit %d", repo.lastAfter, repo.lastLimit)
+	}
+	if len(page.Events) != 2 || !page.HasMore || page.NextCursor != "10.2" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	repo.events = nil
+	page, err = svc.Changes(context.Background(), "10.2", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.HasMore || page.NextCursor != "10.2" || repo.lastLimit != defaultChangeFeedLimit+1 {
+		t.Fatalf("expected empty page to keep the cursor, got %+v (limit %d)", page, repo.lastLimit)
+	}
+
+	if _, err := svc.Changes(context.Background(), "bogus", 10); !errors.Is(err, ErrInvalidChangeCursor) {
+		t.Fatalf("expected ErrInvalidChangeCursor, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+// DefaultChangeSinkBatchSize is how many change events are streamed per insert.
+const DefaultChangeSinkBatchSize = 500
+
+// ChangeStreamTable receives change events as table rows. storage.BigQueryTable implements it.
+type ChangeStreamTable interface {
+	Insert(ctx context.Context, rows []storage.BigQueryRow) error
+}
+
+// ChangeSinkService streams the change feed into a table. It follows the trigger-backed
+// change_events table with a cursor of its own, so every company and enrichment change reaches the
+// table, whichever writer made it, including changes made while the API was down.
+type ChangeSinkService struct {
+	repo  repository.ChangeSinksRepository
+	sink  string
+	table ChangeStreamTable
+	batch int
+}
+
+// NewChangeSinkService builds a ChangeSinkService. sink names the cursor, so a sink renamed to point
+// at another table starts again from the first event.
+func NewChangeSinkService(repo repository.ChangeSinksRepository, sink string, table ChangeStreamTable, batch int) *ChangeSinkService {
+	if batch <= 0 {
+		batch = DefaultChangeSinkBatchSize
+	}
+	return &ChangeSinkService{repo: repo, sink: sink, table: table, batch: batch}
+}
+
+// Export streams every event after the cursor, a batch at a time, and reports how many it
+// streamed. Nothing is streamed while another API instance exports to the same sink.
+func (s *ChangeSinkService) Export(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		streamed, err := s.repo.ExportChanges(ctx, s.sink, s.batch, func(changes []entity.ChangeEvent) error {
+			return s.table.Insert(ctx, changeRows(changes))
+		})
+		total += streamed
+		if errors.Is(err, repository.ErrChangeSinkBusy) {
+			return total, nil
+		}
+		if err != nil || streamed < s.batch {
+			return total, err
+		}
+	}
+}
+
+// Run exports whenever the bus announces stored companies or enrichments, and every interval for
+// changes it did not announce, such as those the worker writes straight to Postgres, until ctx is
+// cancelled. A failed batch is retried on the next round from the same cursor.
+func (s *ChangeSinkService) Run(ctx context.Context, bus *events.Bus, interval time.Duration) {
+	var wake <-chan events.Event
+	if bus != nil {
+		sub := bus.Subscribe(events.TypeCompaniesUpserted, events.TypeEnrichmentSaved, events.TypeIngestBatchStored)
+		defer sub.Close()
+		wake = sub.C
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-ticker.C:
+		}
+		if _, err := s.Export(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("failed to stream changes to %s: %v", s.sink, err)
+		}
+	}
+}
+
+// changeRows renders events as rows of the sink table. The cursor is unique per event, so it doubles
+// as the insert id and lets queries drop the duplicates a retried batch can leave.
+func changeRows(changes []entity.ChangeEvent) []storage.BigQueryRow {
+	rows := make([]storage.BigQueryRow, 0, len(changes))
+	for _, change := range changes {
+		cursor := EncodeChangeCursor(change.Cursor)
+		values := map[string]any{
+			"cursor":      cursor,
+			"entity":      change.Entity,
+			"entity_id":   change.EntityID.String(),
+			"operation":   change.Operation,
+			"data":        nil,
+			"occurred_at": change.OccurredAt.UTC().Format(time.RFC3339Nano),
+		}
+		if len(change.Data) > 0 {
+			values["data"] = string(change.Data)
+		}
+		rows = append(rows, storage.BigQueryRow{InsertID: cursor, Values: values})
+	}
+	return rows
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type stubChangeSinksRepository struct {
+	pending []entity.ChangeEvent
+	busy    bool
+}
+
+func (r *stubChangeSinksRepository) ExportChanges(ctx context.Context, sink string, limit int, fn func([]entity.ChangeEvent) error) (int, error) {
+	if r.busy {
+		return 0, repository.ErrChangeSinkBusy
+	}
+	batch := r.pending[:min(limit, len(r.pending))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := fn(batch); err != nil {
+		return 0, err
+	}
+	r.pending = r.pending[len(batch):]
+	return len(batch), nil
+}
+
+type stubChangeStreamTable struct {
+	inserts [][]storage.BigQueryRow
+}
+
+func (t *stubChangeStreamTable) Insert(ctx context.Context, rows []storage.BigQueryRow) error {
+	t.inserts = append(t.inserts, rows)
+	return nil
+}
+
+func TestChangeSinkService_Export(t *testing.T) {
+	occurred := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubChangeSinksRepository{}
+	for i := int64(1); i <= 5; i++ {
+		repo.pending = append(repo.pending, entity.ChangeEvent{
+			Cursor:     entity.ChangeCursor{TxID: 7, ID: i},
+			Entity:     entity.ChangeEntityCompany,
+			EntityID:   uuid.New(),
+			Operation:  entity.ChangeOperationUpdate,
+			Data:       json.RawMessage(`{"name":"Acme"}`),
+			OccurredAt: occurred,
+		})
+	}
+	repo.pending[4].Operation, repo.pending[4].Data = entity.ChangeOperationDelete, nil
+	table := &stubChangeStreamTable{}
+	svc := NewChangeSinkService(repo, "bigquery:p.d.t", table, 2)
+
+	streamed, err := svc.Export(context.Background())
+	if err != nil || streamed != 5 || len(repo.pending) != 0 {
+		t.Fatalf("expected every pending event streamed, got %d, %v", streamed, err)
+	}
+	if len(table.inserts) != 3 || len(table.inserts[0]) != 2 || len(table.inserts[2]) != 1 {
+		t.Fatalf("expected batches of two, got %d inserts", len(table.inserts))
+	}
+	first := table.inserts[0][0]
+	if first.InsertID != "7.1" || first.Values["cursor"] != "7.1" || first.Values["data"] != `{"name":"Acme"}` || first.Values["occurred_at"] != "2024-05-01T12:00:00Z" {
+		t.Fatalf("unexpected row: %+v", first)
+	}
+	if deleted := table.inserts[2][0]; deleted.Values["operation"] != "delete" || deleted.Values["data"] != nil {
+		t.Fatalf("expected a delete without data, got %+v", deleted)
+	}
+
+	repo.busy = true
+	if streamed, err := svc.Export(context.Background()); err != nil || streamed != 0 {
+		t.Fatalf("expected nothing streamed while another instance exports, got %d, %v", streamed, err)
+	}
+}
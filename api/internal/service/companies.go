@@ -2,25 +2,71 @@ package service
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
-	"fmt"
 	"io"
+	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/querycache"
 	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+	"github.com/octobees/leads-generator/api/internal/service/techstack"
 )
 
 // CompaniesService exposes read/write operations for the company catalogue.
 type CompaniesService struct {
-	repo repository.CompaniesRepository
+	repo     repository.CompaniesRepository
+	cache    repository.EnrichmentCacheRepository
+	cacheTTL time.Duration
+	sizes    repository.CompanySizeRepository
+	facets   repository.CompanyFacetsRepository
+	suggest  repository.CompanySuggestRepository
+	plans    repository.CompanyPlansRepository
+	raw      repository.CompanyRawRepository
+	// rawStore keeps offloaded raw payloads under rawPrefix; nil keeps them in Postgres.
+	rawStore  BlobStore
+	rawPrefix string
+	scoring   scoring.Options
+	now       func() time.Time
+	// searchWeight is the lead score share of relevance sorting, unless a filter sets its own.
+	searchWeight float64
+	// processor cleans enrichment payloads; nil stores them as sent.
+	processor        *DataProcessor
+	strictEnrichment bool
+	countries        repository.CompanyCountryRepository
+	exports          repository.CompanyExportRepository
+	exportCaps       map[string]int64
+	exportTemplates  repository.ExportTemplatesRepository
+	languages        repository.OutreachLanguageRepository
+	scoringProfiles  repository.ScoringProfilesRepository
+	noWebsiteLeads   repository.NoWebsiteLeadsRepository
+	snapshots        repository.CompanySnapshotsRepository
+	refreshes        repository.PlaceRefreshRepository
+	trending         repository.TrendingCompaniesRepository
+	assignments      repository.CompanyAssignmentsRepository
+	suppressions     *ContactSuppressionService
+	images           repository.CompanyImagesRepository
+	// locations normalises imported city values onto the location catalog; nil stores them as sent.
+	locations *LocationService
+	// categories normalises imported business types and widens category filters; nil leaves both
+	// as sent.
+	categories *CategoryService
+	// publisher is told about stored companies and enrichments.
+	publisher events.Publisher
+	// queryCache serves repeated listings; nil reads every listing from the repository.
+	queryCache *querycache.Cache
 }
 
+// CompaniesServiceOption customises optional CompaniesService collaborators.
+type CompaniesServiceOption func(*CompaniesService)
+
 // ErrInvalidCompanyID is returned when the provided company identifier cannot be parsed as UUID.
 var (
 	ErrInvalidCompanyID   = errors.New("invalid company_id")
@@ -37,20 +83,113 @@ func (e CSVValidationError) Error() string {
 	return e.Message
 }
 
-// UploadSummary reports how many rows were inserted or updated during import.
+// UploadSummary reports how many rows were inserted, updated or skipped during import.
 type UploadSummary struct {
-	Inserted int `json:"inserted"`
-	Updated  int `json:"updated"`
-	Total    int `json:"total"`
+	// UploadID identifies the retained copy of the file when upload retention is enabled.
+	UploadID string                `json:"upload_id,omitempty"`
+	Mode     repository.ImportMode `json:"mode"`
+	Inserted int                   `json:"inserted"`
+	Updated  int                   `json:"updated"`
+	Skipped  int                   `json:"skipped"`
+	Total    int                   `json:"total"`
+}
+
+// ErrInvalidImportMode is returned when a CSV import mode is not recognised.
+var ErrInvalidImportMode = errors.New("invalid import mode")
+
+// ParseImportMode maps a raw mode value to an import mode, defaulting to overwrite. Hyphenated
+// spellings and fill_missing_only are accepted as aliases.
+func ParseImportMode(raw string) (repository.ImportMode, error) {
+	mode := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), "-", "_")
+	switch mode {
+	case "":
+		return repository.ImportModeOverwrite, nil
+	case "fill_missing_only":
+		return repository.ImportModeFillMissing, nil
+	}
+	switch repository.ImportMode(mode) {
+	case repository.ImportModeOverwrite, repository.ImportModeFillMissing, repository.ImportModeSkipExisting:
+		return repository.ImportMode(mode), nil
+	}
+	return "", ErrInvalidImportMode
 }
 
 // NewCompaniesService creates a new instance of CompaniesService.
-func NewCompaniesService(repo repository.CompaniesRepository) *CompaniesService {
-	return &CompaniesService{repo: repo}
+func NewCompaniesService(repo repository.CompaniesRepository, opts ...CompaniesServiceOption) *CompaniesService {
+	svc := &CompaniesService{repo: repo, scoring: scoring.DefaultOptions(), now: time.Now, searchWeight: DefaultSearchScoreWeight, publisher: events.Discard}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
 }
 
-// ListCompanies returns companies respecting pagination defaults.
+// ListCompanies returns companies respecting pagination defaults, flagging those on the
+// do-not-contact list.
 func (s *CompaniesService) ListCompanies(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+	companies, err := s.listCompanies(ctx, s.withScoreWeight(s.withCategoryTaxonomy(normalizeListFilter(filter))))
+	if err != nil {
+		return nil, err
+	}
+	flagged := make([]*entity.Company, len(companies))
+	for i := range companies {
+		flagged[i] = &companies[i]
+	}
+	if err := s.flagSuppressed(ctx, flagged); err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+// listCompanies reads a listing through the query cache. Listings with raw payloads, too large to
+// cache, and open-now listings, which change with the clock, always come from the repository.
+// Suppression is flagged after the cache, so the do-not-contact list applies at once.
+func (s *CompaniesService) listCompanies(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+	if s.queryCache == nil || filter.IncludeRaw || filter.OpenNow != nil {
+		return s.repo.List(ctx, filter)
+	}
+	return querycache.Fetch(ctx, s.queryCache, querycache.Key("companies", filter), func(ctx context.Context) ([]entity.Company, error) {
+		return s.repo.List(ctx, filter)
+	})
+}
+
+// DefaultSearchScoreWeight is the share of the lead score when searches rank by relevance.
+const DefaultSearchScoreWeight = 0.3
+
+// WithSearchScoreWeight sets the share, from 0 to 1, of the lead score when searches rank by
+// relevance; the rest is text relevance.
+func WithSearchScoreWeight(weight float64) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.searchWeight = weight
+	}
+}
+
+// WithCompanyEvents publishes company upserts and stored enrichments to publisher.
+func WithCompanyEvents(publisher events.Publisher) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
+}
+
+// WithQueryCache serves repeated company listings and suggestions from cache until it is invalidated.
+func WithQueryCache(cache *querycache.Cache) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.queryCache = cache
+	}
+}
+
+// withScoreWeight fills in the configured lead score share of relevance sorting.
+func (s *CompaniesService) withScoreWeight(filter dto.ListFilter) dto.ListFilter {
+	if filter.ScoreWeight == nil {
+		weight := s.searchWeight
+		filter.ScoreWeight = &weight
+	}
+	return filter
+}
+
+// normalizeListFilter applies the pagination and business status defaults of company listings.
+func normalizeListFilter(filter dto.ListFilter) dto.ListFilter {
 	if filter.Page <= 0 {
 		filter.Page = 1
 	}
@@ -63,96 +202,101 @@ func (s *CompaniesService) ListCompanies(ctx context.Context, filter dto.ListFil
 	if filter.Limit > 0 && filter.Limit < filter.PerPage {
 		filter.PerPage = filter.Limit
 	}
-	return s.repo.List(ctx, filter)
+	if filter.BusinessStatus == "" {
+		filter.BusinessStatus = BusinessStatusOperational
+	}
+	return filter
 }
 
-// ImportCompaniesCSV ingests companies data from a CSV reader.
-func (s *CompaniesService) ImportCompaniesCSV(ctx context.Context, r io.Reader) (UploadSummary, error) {
-	reader := csv.NewReader(r)
-	reader.TrimLeadingSpace = true
-
-	header, err := reader.Read()
+// ImportCompaniesCSV ingests companies data from a CSV or XLSX reader; mode decides how companies
+// that already exist are treated and mapping optionally renames columns onto the expected fields.
+func (s *CompaniesService) ImportCompaniesCSV(ctx context.Context, r io.Reader, mode repository.ImportMode, mapping CSVColumnMapping) (UploadSummary, error) {
+	reader, _, err := newCompanyCSVReader(r, mapping)
 	if err != nil {
-		if errors.Is(err, io.EOF) {
-			return UploadSummary{}, CSVValidationError{Message: "csv file is empty"}
-		}
-		return UploadSummary{}, fmt.Errorf("read csv header: %w", err)
-	}
-
-	indexMap, valErr := buildHeaderIndex(header)
-	if valErr != nil {
-		return UploadSummary{}, valErr
+		return UploadSummary{}, err
 	}
+	defer reader.close()
 
-	var (
-		records []repository.BulkUpsertCompanyInput
-		rowNum  = 1
-	)
-
+	var records []repository.BulkUpsertCompanyInput
 	for {
-		row, err := reader.Read()
+		record, err := reader.next()
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			return UploadSummary{}, fmt.Errorf("read csv row: %w", err)
+			return UploadSummary{}, err
 		}
-
-		rowNum++
-
-		company := strings.TrimSpace(row[indexMap["company"]])
-		address := strings.TrimSpace(row[indexMap["address"]])
-		if company == "" || address == "" {
-			continue
+		if record != nil {
+			s.normalizeImportedCompany(record)
+			records = append(records, *record)
 		}
-
-		rating, parseErr := parseOptionalFloat(row[indexMap["rating"]])
-		if parseErr != nil {
-			return UploadSummary{}, CSVValidationError{Message: fmt.Sprintf("invalid rating value on row %d", rowNum)}
-		}
-
-		reviews, parseReviewsErr := parseOptionalInt(row[indexMap["reviews"]])
-		if parseReviewsErr != nil {
-			return UploadSummary{}, CSVValidationError{Message: fmt.Sprintf("invalid reviews value on row %d", rowNum)}
-		}
-
-		records = append(records, repository.BulkUpsertCompanyInput{
-			Company:      company,
-			Address:      address,
-			Phone:        normalizeString(row[indexMap["phone"]]),
-			Website:      normalizeString(row[indexMap["website"]]),
-			Rating:       rating,
-			Reviews:      reviews,
-			TypeBusiness: normalizeString(row[indexMap["type_business"]]),
-			City:         normalizeString(row[indexMap["city"]]),
-			Country:      normalizeString(row[indexMap["country"]]),
-		})
 	}
 
-	result, err := s.repo.BulkUpsertCompanies(ctx, records)
+	result, err := s.repo.BulkUpsertCompanies(ctx, records, mode)
 	if err != nil {
 		return UploadSummary{}, err
 	}
+	s.publishCSVUpsert(ctx, result)
 
 	return UploadSummary{
+		Mode:     mode,
 		Inserted: result.Inserted,
 		Updated:  result.Updated,
+		Skipped:  result.Skipped,
 		Total:    result.Total,
 	}, nil
 }
 
-// UpsertCompany proxies to the repository to persist the record.
+// UpsertCompany persists the record with the legal form extracted from its name and its phone and
+// website normalized.
 func (s *CompaniesService) UpsertCompany(ctx context.Context, company *entity.Company) error {
-	return s.repo.Upsert(ctx, company)
+	applyLegalForm(company)
+	applyContactNormalization(company)
+	applyPlaceAttributes(company)
+	if err := s.repo.Upsert(ctx, company); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, events.CompaniesUpserted{Source: events.SourceAPI, Companies: 1})
+	return nil
+}
+
+// publishCSVUpsert reports the companies a CSV import batch inserted or updated, if any.
+func (s *CompaniesService) publishCSVUpsert(ctx context.Context, result repository.BulkUpsertResult) {
+	s.publishBulkUpsert(ctx, events.SourceCSV, result)
+}
+
+// publishBulkUpsert reports the companies a bulk upsert from source inserted or updated, if any.
+func (s *CompaniesService) publishBulkUpsert(ctx context.Context, source string, result repository.BulkUpsertResult) {
+	if result.Inserted+result.Updated == 0 {
+		return
+	}
+	s.publisher.Publish(ctx, events.CompaniesUpserted{
+		Source:    source,
+		Companies: result.Inserted + result.Updated,
+		Inserted:  result.Inserted,
+		Updated:   result.Updated,
+	})
 }
 
-// SaveEnrichment persists enrichment metadata for a company.
+// SaveEnrichment persists enrichment metadata for a company. When enrichment validation is enabled,
+// invalid values are dropped, or reported as EnrichmentValidationError in strict mode.
 func (s *CompaniesService) SaveEnrichment(ctx context.Context, payload dto.EnrichResultRequest) error {
 	companyID, err := uuid.Parse(strings.TrimSpace(payload.CompanyID))
 	if err != nil {
 		return ErrInvalidCompanyID
 	}
 
+	if s.processor != nil {
+		var rejected map[string]string
+		payload, rejected = s.processor.sanitizeEnrichment(ctx, payload, s.phoneRegion(ctx, companyID))
+		if len(rejected) > 0 {
+			if s.strictEnrichment {
+				return EnrichmentValidationError{Fields: rejected}
+			}
+			log.Printf("enrichment for company %s: dropped invalid values: %v", companyID, rejected)
+		}
+	}
+
 	enrichment := &entity.CompanyEnrichment{
 		CompanyID:      companyID,
 		Emails:         normalizeStringSlice(payload.Emails, strings.ToLower),
@@ -163,12 +307,26 @@ func (s *CompaniesService) SaveEnrichment(ctx context.Context, payload dto.Enric
 		AboutSummary:   trimPointer(payload.AboutSummary),
 		Metadata:       buildEnrichmentMetadata(payload),
 	}
+	if classes := s.processor.classifyEmails(enrichment.Emails); classes != nil {
+		if enrichment.Metadata == nil {
+			enrichment.Metadata = make(map[string]any)
+		}
+		enrichment.Metadata["email_classes"] = classes
+	}
 
 	if err := s.repo.UpsertEnrichedContacts(ctx, buildWebsiteEnrichedContact(companyID, payload)); err != nil {
 		return err
 	}
+	if err := s.repo.UpsertEnrichment(ctx, enrichment); err != nil {
+		return err
+	}
 
-	return s.repo.UpsertEnrichment(ctx, enrichment)
+	s.storeDomainEnrichment(ctx, companyID, payload, enrichment)
+	s.refreshSizeAfterEnrichment(ctx, companyID)
+	s.refreshOutreachLanguageAfterEnrichment(ctx, companyID)
+	s.storeCompanyImages(ctx, companyID, payload)
+	s.publisher.Publish(ctx, events.EnrichmentSaved{CompanyID: companyID})
+	return nil
 }
 
 // GetEnrichment fetches enrichment metadata for a company.
@@ -188,26 +346,6 @@ func (s *CompaniesService) GetEnrichment(ctx context.Context, companyIDRaw strin
 	return enrichment, nil
 }
 
-var requiredCSVHeaders = []string{"company", "address", "phone", "website", "rating", "reviews", "type_business", "city", "country"}
-
-func buildHeaderIndex(header []string) (map[string]int, error) {
-	index := make(map[string]int)
-	for i, col := range header {
-		index[strings.ToLower(strings.TrimSpace(col))] = i
-	}
-
-	missing := make([]string, 0)
-	for _, required := range requiredCSVHeaders {
-		if _, ok := index[required]; !ok {
-			missing = append(missing, required)
-		}
-	}
-	if len(missing) > 0 {
-		return nil, CSVValidationError{Message: fmt.Sprintf("missing required columns: %s", strings.Join(missing, ", "))}
-	}
-	return index, nil
-}
-
 func parseOptionalFloat(value string) (*float64, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -308,6 +446,30 @@ func buildEnrichmentMetadata(payload dto.EnrichResultRequest) map[string]any {
 	if payload.PagesCrawled > 0 {
 		meta["pages_crawled"] = payload.PagesCrawled
 	}
+	if depth := strings.ToLower(strings.TrimSpace(payload.Depth)); depth != "" {
+		meta["depth"] = depth
+	}
+	if payload.EmployeeMentions != nil && *payload.EmployeeMentions > 0 {
+		meta["employee_mentions"] = *payload.EmployeeMentions
+	}
+	if followers := totalFollowers(payload.SocialFollowers); followers > 0 {
+		meta["social_followers"] = followers
+	}
+	if handles := socialHandles(normalizeSocialLinks(payload.Socials)); handles != nil {
+		meta["social_handles"] = handles
+	}
+	if language := trimPointer(payload.WebsiteLanguage); language != nil {
+		meta["website_language"] = strings.ToLower(*language)
+	}
+	if technologies := techstack.NormalizeList(payload.Technologies); technologies != nil {
+		meta["technologies"] = technologies
+	}
+	if logo := companyLogoURL(payload.LogoURL); logo != nil {
+		meta["logo_url"] = *logo
+	}
+	if reference := companyPhotoReference(payload.PhotoReference); reference != nil {
+		meta["photo_reference"] = *reference
+	}
 	if len(meta) == 0 {
 		return nil
 	}
@@ -327,6 +489,20 @@ func buildWebsiteEnrichedContact(companyID uuid.UUID, payload dto.EnrichResultRe
 		InstagramURL:   selectSocialLink(socials, "instagram"),
 		YouTubeURL:     selectSocialLink(socials, "youtube"),
 		TikTokURL:      selectSocialLink(socials, "tiktok"),
+		TwitterURL:     selectSocialLink(socials, "twitter"),
+		WhatsAppURL:    selectSocialLink(socials, "whatsapp"),
+	}
+	for _, platform := range []string{"linkedin", "facebook", "instagram", "youtube", "tiktok", "twitter", "whatsapp"} {
+		link := selectSocialLink(socials, platform)
+		if link == nil {
+			continue
+		}
+		if handle := socialHandle(platform, *link); handle != "" {
+			if contact.SocialHandles == nil {
+				contact.SocialHandles = make(map[string]string)
+			}
+			contact.SocialHandles[platform] = handle
+		}
 	}
 	return contact
 }
@@ -346,3 +522,13 @@ func selectSocialLink(socials map[string][]string, platform string) *string {
 	}
 	return nil
 }
+
+func totalFollowers(followers map[string]int) int {
+	total := 0
+	for _, count := range followers {
+		if count > 0 {
+			total += count
+		}
+	}
+	return total
+}
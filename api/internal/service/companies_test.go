@@ -5,11 +5,13 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/querycache"
 	"github.com/octobees/leads-generator/api/internal/repository"
 )
 
@@ -21,6 +23,8 @@ type mockCompaniesRepository struct {
 	getEnrichment   func(ctx context.Context, companyID uuid.UUID) (*entity.CompanyEnrichment, error)
 	upsertContacts  func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error
 	getContactsByID func(ctx context.Context, companyID uuid.UUID) (*entity.WebsiteEnrichedContact, error)
+
+	bulkMode repository.ImportMode
 }
 
 func (m *mockCompaniesRepository) List(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
@@ -30,7 +34,8 @@ func (m *mockCompaniesRepository) List(ctx context.Context, filter dto.ListFilte
 	return nil, errors.New("list not implemented")
 }
 
-func (m *mockCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+func (m *mockCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput, mode repository.ImportMode) (repository.BulkUpsertResult, error) {
+	m.bulkMode = mode
 	if m.bulk != nil {
 		return m.bulk(ctx, records)
 	}
@@ -96,6 +101,9 @@ func TestCompaniesService_ListCompanies_AppliesDefaults(t *testing.T) {
 	if received.PerPage != 20 {
 		t.Fatalf("expected per_page default 20, got %d", received.PerPage)
 	}
+	if received.BusinessStatus != BusinessStatusOperational {
+		t.Fatalf("expected operational-only default, got %q", received.BusinessStatus)
+	}
 }
 
 func TestCompaniesService_ListCompanies_CapsPerPage(t *testing.T) {
@@ -111,6 +119,62 @@ func TestCompaniesService_ListCompanies_CapsPerPage(t *testing.T) {
 	service.ListCompanies(context.Background(), dto.ListFilter{PerPage: 500})
 }
 
+func TestCompaniesService_ListCompanies_QueryCache(t *testing.T) {
+	calls := 0
+	repo := &mockCompaniesRepository{
+		list: func(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+			calls++
+			return []entity.Company{{Company: "Acme"}}, nil
+		},
+	}
+	cache := querycache.New(querycache.NewMemoryStore(10), querycache.StoreMemory, time.Hour)
+	service := NewCompaniesService(repo, WithQueryCache(cache))
+
+	for i := 0; i < 2; i++ {
+		companies, err := service.ListCompanies(context.Background(), dto.ListFilter{City: "Jakarta"})
+		if err != nil || len(companies) != 1 || companies[0].Company != "Acme" {
+			t.Fatalf("unexpected companies %+v (%v)", companies, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the repeated listing cached, got %d repository calls", calls)
+	}
+
+	service.ListCompanies(context.Background(), dto.ListFilter{City: "Bandung"})
+	service.ListCompanies(context.Background(), dto.ListFilter{City: "Jakarta", IncludeRaw: true})
+	if calls != 3 {
+		t.Fatalf("expected other filters and raw listings read from the repository, got %d calls", calls)
+	}
+}
+
+func TestCompaniesService_ListCompanies_SearchScoreWeight(t *testing.T) {
+	var received dto.ListFilter
+	repo := &mockCompaniesRepository{
+		list: func(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+			received = filter
+			return nil, nil
+		},
+	}
+
+	service := NewCompaniesService(repo)
+	service.ListCompanies(context.Background(), dto.ListFilter{Q: "kopi"})
+	if received.ScoreWeight == nil || *received.ScoreWeight != DefaultSearchScoreWeight {
+		t.Fatalf("expected the default score weight, got %v", received.ScoreWeight)
+	}
+
+	service = NewCompaniesService(repo, WithSearchScoreWeight(0.8))
+	service.ListCompanies(context.Background(), dto.ListFilter{Q: "kopi"})
+	if *received.ScoreWeight != 0.8 {
+		t.Fatalf("expected the configured score weight, got %v", *received.ScoreWeight)
+	}
+
+	weight := 0.0
+	service.ListCompanies(context.Background(), dto.ListFilter{Q: "kopi", ScoreWeight: &weight})
+	if *received.ScoreWeight != 0 {
+		t.Fatalf("expected the requested score weight to win, got %v", *received.ScoreWeight)
+	}
+}
+
 func TestCompaniesService_ImportCompaniesCSV(t *testing.T) {
 	tests := map[string]struct {
 		csv         string
@@ -154,7 +218,7 @@ func TestCompaniesService_ImportCompaniesCSV(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			service := NewCompaniesService(tt.mock)
-			summary, err := service.ImportCompaniesCSV(context.Background(), strings.NewReader(tt.csv))
+			summary, err := service.ImportCompaniesCSV(context.Background(), strings.NewReader(tt.csv), repository.ImportModeFillMissing, nil)
 			if tt.expectError != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
 					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
@@ -167,13 +231,35 @@ func TestCompaniesService_ImportCompaniesCSV(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if summary.Inserted != 1 || summary.Total != 1 {
+			if summary.Inserted != 1 || summary.Total != 1 || summary.Mode != repository.ImportModeFillMissing {
 				t.Fatalf("unexpected summary: %+v", summary)
 			}
+			if tt.mock.bulkMode != repository.ImportModeFillMissing {
+				t.Fatalf("expected mode passed to repository, got %q", tt.mock.bulkMode)
+			}
 		})
 	}
 }
 
+func TestParseImportMode(t *testing.T) {
+	cases := map[string]repository.ImportMode{
+		"":                  repository.ImportModeOverwrite,
+		"overwrite":         repository.ImportModeOverwrite,
+		"Fill_Missing":      repository.ImportModeFillMissing,
+		"fill-missing-only": repository.ImportModeFillMissing,
+		" skip-existing ":   repository.ImportModeSkipExisting,
+	}
+	for raw, want := range cases {
+		got, err := ParseImportMode(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseImportMode(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseImportMode("merge"); !errors.Is(err, ErrInvalidImportMode) {
+		t.Fatalf("expected ErrInvalidImportMode, got %v", err)
+	}
+}
+
 func TestCompaniesService_UpsertCompany(t *testing.T) {
 	called := false
 	repo := &mockCompaniesRepository{
@@ -306,7 +392,7 @@ func TestCompaniesService_GetEnrichment_NotFound(t *testing.T) {
 
 func TestBuildHeaderIndex(t *testing.T) {
 	header := []string{"Company", "Address", "Phone", "Website", "Rating", "Reviews", "Type_Business", "City", "Country"}
-	index, err := buildHeaderIndex(header)
+	index, err := buildHeaderIndex(header, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -314,7 +400,7 @@ func TestBuildHeaderIndex(t *testing.T) {
 		t.Fatalf("header index not built correctly: %+v", index)
 	}
 
-	_, err = buildHeaderIndex([]string{"company", "address"})
+	_, err = buildHeaderIndex([]string{"company", "address"}, nil)
 	if err == nil {
 		t.Fatalf("expected error for missing headers")
 	}
@@ -408,4 +494,13 @@ func TestBuildEnrichmentMetadata(t *testing.T) {
 	if buildEnrichmentMetadata(dto.EnrichResultRequest{}) != nil {
 		t.Fatalf("expected nil metadata for empty payload")
 	}
+
+	meta = buildEnrichmentMetadata(dto.EnrichResultRequest{Technologies: []string{"WordPress", "jquery", "wp"}})
+	if technologies, ok := meta["technologies"].([]string); !ok || len(technologies) != 1 || technologies[0] != "wordpress" {
+		t.Fatalf("expected known technologies stored, got %+v", meta)
+	}
+	meta = buildEnrichmentMetadata(dto.EnrichResultRequest{Technologies: []string{}})
+	if technologies, ok := meta["technologies"].([]string); !ok || len(technologies) != 0 {
+		t.Fatalf("expected an empty list stored when none was detected, got %+v", meta)
+	}
 }
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// AssignToCaller is the user_id and assigned_to value naming the calling user.
+const AssignToCaller = "me"
+
+var (
+	// ErrAssignmentsUnavailable is returned when companies are assigned without an assignments repository.
+	ErrAssignmentsUnavailable = errors.New("company assignments unavailable")
+	// ErrInvalidAssignee is returned when user_id is neither "me" nor a user id.
+	ErrInvalidAssignee = errors.New("invalid user_id (use me or a user id)")
+	// ErrAssigneeNotFound is returned when companies are assigned to a user that does not exist.
+	ErrAssigneeNotFound = errors.New("assignee not found")
+	// ErrCompanyAssigned is returned when a user claims a company someone else works.
+	ErrCompanyAssigned = errors.New("company is assigned to another user")
+	// ErrAssignmentForbidden is returned when a user other than an admin assigns someone else.
+	ErrAssignmentForbidden = errors.New("only admins can assign companies to other users")
+	// ErrAssignmentFilterRequired is returned when a bulk assignment would cover every company.
+	ErrAssignmentFilterRequired = errors.New("bulk assignment needs at least one filter")
+)
+
+// WithAssignments enables assigning companies to sales reps.
+func WithAssignments(assignments repository.CompanyAssignmentsRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.assignments = assignments
+	}
+}
+
+// resolveAssignee returns the user named by userID, the caller for "me", or nil for nobody.
+func resolveAssignee(userID *string, caller uuid.UUID) (*uuid.UUID, error) {
+	if userID == nil || strings.TrimSpace(*userID) == "" {
+		return nil, nil
+	}
+	value := strings.TrimSpace(*userID)
+	if strings.EqualFold(value, AssignToCaller) {
+		return &caller, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, ErrInvalidAssignee
+	}
+	return &id, nil
+}
+
+// AssignCompany assigns a company, or returns it to the pool when req names nobody, and returns
+// the assignment, nil once unassigned. Admins assign anyone and take companies over; other users
+// may only claim companies nobody works and release their own.
+func (s *CompaniesService) AssignCompany(ctx context.Context, companyID string, req dto.AssignCompanyRequest, callerID string, isAdmin bool) (*entity.CompanyAssignment, error) {
+	if s.assignments == nil {
+		return nil, ErrAssignmentsUnavailable
+	}
+	id, err := uuid.Parse(companyID)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	caller, err := uuid.Parse(callerID)
+	if err != nil {
+		return nil, ErrAssignmentForbidden
+	}
+	assignee, err := resolveAssignee(req.UserID, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	if assignee == nil {
+		var onlyUser *uuid.UUID
+		if !isAdmin {
+			onlyUser = &caller
+		}
+		if _, err := s.assignments.UnassignCompany(ctx, id, onlyUser); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if !isAdmin && *assignee != caller {
+		return nil, ErrAssignmentForbidden
+	}
+
+	assignment := &entity.CompanyAssignment{CompanyID: id, UserID: *assignee, AssignedBy: &caller}
+	if err := s.assignments.AssignCompany(ctx, assignment, isAdmin); err != nil {
+		return nil, assignmentError(err)
+	}
+	return assignment, nil
+}
+
+// AssignCompanies assigns every company matching filter, ignoring pagination, and returns how many
+// assignments changed.
+func (s *CompaniesService) AssignCompanies(ctx context.Context, filter dto.ListFilter, req dto.BulkAssignRequest, callerID string) (int64, error) {
+	if s.assignments == nil {
+		return 0, ErrAssignmentsUnavailable
+	}
+	caller, err := uuid.Parse(callerID)
+	if err != nil {
+		return 0, ErrAssignmentForbidden
+	}
+	assignee, err := resolveAssignee(req.UserID, caller)
+	if err != nil {
+		return 0, err
+	}
+	changed, err := s.assignments.AssignCompanies(ctx, filter, assignee, caller, req.OnlyUnassigned)
+	if err != nil {
+		return 0, assignmentError(err)
+	}
+	return changed, nil
+}
+
+// assignmentError maps repository assignment errors to service errors.
+func assignmentError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCompanyNotFound):
+		return ErrCompanyNotFound
+	case errors.Is(err, repository.ErrAssigneeNotFound):
+		return ErrAssigneeNotFound
+	case errors.Is(err, repository.ErrCompanyAssigned):
+		return ErrCompanyAssigned
+	case errors.Is(err, repository.ErrAssignmentFilterRequired):
+		return ErrAssignmentFilterRequired
+	default:
+		return err
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubAssignmentsRepo struct {
+	assigned   *entity.CompanyAssignment
+	takeover   bool
+	unassigned *uuid.UUID
+	onlyUser   *uuid.UUID
+	err        error
+}
+
+func (s *stubAssignmentsRepo) AssignCompany(ctx context.Context, assignment *entity.CompanyAssignment, takeover bool) error {
+	s.assigned, s.takeover = assignment, takeover
+	return s.err
+}
+
+func (s *stubAssignmentsRepo) UnassignCompany(ctx context.Context, companyID uuid.UUID, onlyUser *uuid.UUID) (bool, error) {
+	s.unassigned, s.onlyUser = &companyID, onlyUser
+	return true, nil
+}
+
+func (s *stubAssignmentsRepo) AssignCompanies(ctx context.Context, filter dto.ListFilter, userID *uuid.UUID, assignedBy uuid.UUID, onlyUnassigned bool) (int64, error) {
+	return 0, s.err
+}
+
+func TestCompaniesService_AssignCompany(t *testing.T) {
+	companyID, caller, other := uuid.New(), uuid.New(), uuid.New()
+	me, someone := AssignToCaller, other.String()
+
+	repo := &stubAssignmentsRepo{}
+	svc := NewCompaniesService(nil, WithAssignments(repo))
+
+	assignment, err := svc.AssignCompany(context.Background(), companyID.String(), dto.AssignCompanyRequest{UserID: &me}, caller.String(), false)
+	if err != nil || assignment.UserID != caller || repo.takeover {
+		t.Fatalf("expected a claim by the caller, got %+v takeover %v (%v)", assignment, repo.takeover, err)
+	}
+
+	if _, err := svc.AssignCompany(context.Background(), companyID.String(), dto.AssignCompanyRequest{UserID: &someone}, caller.String(), false); !errors.Is(err, ErrAssignmentForbidden) {
+		t.Fatalf("expected users to be kept from assigning others, got %v", err)
+	}
+
+	assignment, err = svc.AssignCompany(context.Background(), companyID.String(), dto.AssignCompanyRequest{UserID: &someone}, caller.String(), true)
+	if err != nil || assignment.UserID != other || !repo.takeover {
+		t.Fatalf("expected admins to take over, got %+v (%v)", assignment, err)
+	}
+
+	if assignment, err := svc.AssignCompany(context.Background(), companyID.String(), dto.AssignCompanyRequest{}, caller.String(), false); err != nil || assignment != nil {
+		t.Fatalf("expected an unassignment, got %+v (%v)", assignment, err)
+	}
+	if repo.onlyUser == nil || *repo.onlyUser != caller {
+		t.Fatalf("expected users to release only their own companies, got %v", repo.onlyUser)
+	}
+
+	repo.err = repository.ErrCompanyAssigned
+	if _, err := svc.AssignCompany(context.Background(), companyID.String(), dto.AssignCompanyRequest{UserID: &me}, caller.String(), false); !errors.Is(err, ErrCompanyAssigned) {
+		t.Fatalf("expected ErrCompanyAssigned, got %v", err)
+	}
+
+	bad := "bob"
+	if _, err := svc.AssignCompany(context.Background(), companyID.String(), dto.AssignCompanyRequest{UserID: &bad}, caller.String(), true); !errors.Is(err, ErrInvalidAssignee) {
+		t.Fatalf("expected ErrInvalidAssignee, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// MaxCompanyBatchSize caps the companies of one batch, so a batch fits one short transaction.
+const MaxCompanyBatchSize = 1000
+
+// CompanyBatchRejected is the status of a batch record that failed validation and was not stored.
+const CompanyBatchRejected = "rejected"
+
+// Errors returned for batches that cannot be stored at all.
+var (
+	ErrCompanyBatchEmpty    = errors.New("batch holds no companies")
+	ErrCompanyBatchTooLarge = fmt.Errorf("batch holds more than %d companies", MaxCompanyBatchSize)
+)
+
+// CompanyBatchResult is the outcome of one record of a batch: inserted, updated, skipped by the
+// import mode, or rejected with the reason.
+type CompanyBatchResult struct {
+	Index     int        `json:"index"`
+	Status    string     `json:"status"`
+	CompanyID *uuid.UUID `json:"company_id,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// CompanyBatchSummary reports what a batch stored, with a result per record in the order sent.
+type CompanyBatchSummary struct {
+	Mode     repository.ImportMode `json:"mode"`
+	Inserted int                   `json:"inserted"`
+	Updated  int                   `json:"updated"`
+	Skipped  int                   `json:"skipped"`
+	Rejected int                   `json:"rejected"`
+	Total    int                   `json:"total"`
+	Results  []CompanyBatchResult  `json:"results"`
+}
+
+// UpsertCompaniesBatch stores a batch pushed by a data provider as a CSV import would, mode
+// deciding what happens to companies that already exist. Invalid records are rejected one by one;
+// the valid ones are written in a single transaction.
+func (s *CompaniesService) UpsertCompaniesBatch(ctx context.Context, records []dto.CompanyBatchRecord, mode repository.ImportMode) (*CompanyBatchSummary, error) {
+	if len(records) == 0 {
+		return nil, ErrCompanyBatchEmpty
+	}
+	if len(records) > MaxCompanyBatchSize {
+		return nil, ErrCompanyBatchTooLarge
+	}
+
+	summary := &CompanyBatchSummary{Mode: mode, Total: len(records), Results: make([]CompanyBatchResult, len(records))}
+	inputs := make([]repository.BulkUpsertCompanyInput, 0, len(records))
+	stored := make([]int, 0, len(records))
+	for i, record := range records {
+		summary.Results[i].Index = i
+		if reason := validateBatchRecord(record); reason != "" {
+			summary.Results[i].Status = CompanyBatchRejected
+			summary.Results[i].Error = reason
+			summary.Rejected++
+			continue
+		}
+		input := companyBulkInput(record)
+		s.normalizeImportedCompany(&input)
+		inputs = append(inputs, input)
+		stored = append(stored, i)
+	}
+	if len(inputs) == 0 {
+		return summary, nil
+	}
+
+	result, err := s.repo.BulkUpsertCompanies(ctx, inputs, mode)
+	if err != nil {
+		return nil, err
+	}
+	summary.Inserted, summary.Updated, summary.Skipped = result.Inserted, result.Updated, result.Skipped
+	for j, record := range result.Records {
+		if j < len(stored) {
+			summary.Results[stored[j]].Status = record.Status
+			summary.Results[stored[j]].CompanyID = record.CompanyID
+		}
+	}
+	s.publishBulkUpsert(ctx, events.SourceBatch, result)
+	return summary, nil
+}
+
+// validateBatchRecord returns why record cannot be stored, or an empty string.
+func validateBatchRecord(record dto.CompanyBatchRecord) string {
+	switch {
+	case strings.TrimSpace(record.Company) == "":
+		return "company is required"
+	case strings.TrimSpace(record.Address) == "":
+		return "address is required"
+	case record.Rating != nil && (*record.Rating < 0 || *record.Rating > 5):
+		return "rating must be between 0 and 5"
+	case record.Reviews != nil && *record.Reviews < 0:
+		return "reviews must not be negative"
+	}
+	return ""
+}
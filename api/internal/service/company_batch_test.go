@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+func TestCompaniesService_UpsertCompaniesBatch(t *testing.T) {
+	inserted, updated := uuid.New(), uuid.New()
+	var received []repository.BulkUpsertCompanyInput
+	repo := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: 1, Updated: 1, Skipped: 1, Total: 3, Records: []repository.BulkUpsertRecordResult{
+				{CompanyID: &inserted, Status: repository.BulkUpsertInserted},
+				{CompanyID: &updated, Status: repository.BulkUpsertUpdated},
+				{Status: repository.BulkUpsertSkipped},
+			}}, nil
+		},
+	}
+	bus := events.NewBus()
+	sub := bus.Subscribe(events.TypeCompaniesUpserted)
+	defer sub.Close()
+	svc := NewCompaniesService(repo, WithCompanyEvents(bus))
+
+	rating, reviews := 6.0, 12
+	records := []dto.CompanyBatchRecord{
+		{Company: "Kopi Kenangan", Address: "Jl. Sudirman 1", Reviews: &reviews, TypeBusiness: "Coffee Shop", City: "Jakarta", Country: "Indonesia"},
+		{Company: "Toko Roti", Address: "Jl. Thamrin 2"},
+		{Company: "  ", Address: "Jl. Thamrin 3"},
+		{Company: "Warung", Address: "Jl. Kebon 4", Rating: &rating},
+		{Company: "Bakmi", Address: "Jl. Gajah Mada 5"},
+	}
+	summary, err := svc.UpsertCompaniesBatch(context.Background(), records, repository.ImportModeFillMissing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.bulkMode != repository.ImportModeFillMissing || len(received) != 3 || received[0].Reviews == nil || *received[0].Reviews != 12 || received[0].Categories[0] != "coffee_shop" {
+		t.Fatalf("expected the valid records stored with the mode, got %q %+v", repo.bulkMode, received)
+	}
+	if summary.Total != 5 || summary.Inserted != 1 || summary.Updated != 1 || summary.Skipped != 1 || summary.Rejected != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	want := []struct {
+		status string
+		id     *uuid.UUID
+	}{
+		{repository.BulkUpsertInserted, &inserted},
+		{repository.BulkUpsertUpdated, &updated},
+		{CompanyBatchRejected, nil},
+		{CompanyBatchRejected, nil},
+		{repository.BulkUpsertSkipped, nil},
+	}
+	for i, result := range summary.Results {
+		if result.Index != i || result.Status != want[i].status || (want[i].id != nil) != (result.CompanyID != nil) || (result.CompanyID != nil && *result.CompanyID != *want[i].id) {
+			t.Fatalf("unexpected result %d: %+v", i, result)
+		}
+	}
+	if summary.Results[2].Error != "company is required" || summary.Results[3].Error != "rating must be between 0 and 5" {
+		t.Fatalf("expected rejection reasons, got %+v", summary.Results)
+	}
+	if event := <-sub.C; event.Payload != (events.CompaniesUpserted{Source: events.SourceBatch, Companies: 2, Inserted: 1, Updated: 1}) {
+		t.Fatalf("unexpected event %+v", event.Payload)
+	}
+}
+
+func TestCompaniesService_UpsertCompaniesBatch_Limits(t *testing.T) {
+	repo := &mockCompaniesRepository{}
+	svc := NewCompaniesService(repo)
+	if _, err := svc.UpsertCompaniesBatch(context.Background(), nil, repository.ImportModeOverwrite); !errors.Is(err, ErrCompanyBatchEmpty) {
+		t.Fatalf("expected ErrCompanyBatchEmpty, got %v", err)
+	}
+	records := make([]dto.CompanyBatchRecord, MaxCompanyBatchSize+1)
+	if _, err := svc.UpsertCompaniesBatch(context.Background(), records, repository.ImportModeOverwrite); !errors.Is(err, ErrCompanyBatchTooLarge) {
+		t.Fatalf("expected ErrCompanyBatchTooLarge, got %v", err)
+	}
+
+	summary, err := svc.UpsertCompaniesBatch(context.Background(), records[:2], repository.ImportModeOverwrite)
+	if err != nil || summary.Rejected != 2 || repo.bulkMode != "" {
+		t.Fatalf("expected invalid records rejected without a write, got %+v (%v)", summary, err)
+	}
+}
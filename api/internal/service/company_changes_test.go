@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type mockCompanyChangesRepository struct {
+	changes    []entity.CompanyChange
+	lastAfter  entity.CompanyChangeCursor
+	lastSettle time.Duration
+	lastLimit  int
+}
+
+func (m *mockCompanyChangesRepository) ListCompanyChanges(ctx context.Context, after entity.CompanyChangeCursor, settle time.Duration, limit int) ([]entity.CompanyChange, error) {
+	m.lastAfter = after
+	m.lastSettle = settle
+	m.lastLimit = limit
+	if len(m.changes) > limit {
+		return m.changes[:limit], nil
+	}
+	return m.changes, nil
+}
+
+func companyChange(at time.Time, id string) entity.CompanyChange {
+	cursor := entity.CompanyChangeCursor{ChangedAt: at, ID: uuid.MustParse(id)}
+	return entity.CompanyChange{
+		Cursor:    cursor,
+		Operation: entity.ChangeOperationUpdate,
+		CompanyID: cursor.ID,
+		ChangedAt: at,
+	}
+}
+
+func TestCompanyChangeCursorRoundTrip(t *testing.T) {
+	cursor := entity.CompanyChangeCursor{
+		ChangedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC),
+		ID:        uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"),
+	}
+	encoded := EncodeCompanyChangeCursor(cursor)
+	if encoded != "1714564800123456_aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa" {
+		t.Fatalf("unexpected encoding %q", encoded)
+	}
+	decoded, err := DecodeCompanyChangeCursor(encoded)
+	if err != nil || !decoded.ChangedAt.Equal(cursor.ChangedAt) || decoded.ID != cursor.ID {
+		t.Fatalf("expected %+v, got %+v (%v)", cursor, decoded, err)
+	}
+	if EncodeCompanyChangeCursor(entity.CompanyChangeCursor{}) != "" {
+		t.Fatalf("expected zero cursor to encode as empty string")
+	}
+	for _, raw := range []string{"abc", "12", "x_aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "12_nope"} {
+		if _, err := DecodeCompanyChangeCursor(raw); !errors.Is(err, ErrInvalidChangeCursor) {
+			t.Fatalf("expected %q to be rejected, got %v", raw, err)
+		}
+	}
+}
+
+func TestCompanyChangesService_Changes(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockCompanyChangesRepository{changes: []entity.CompanyChange{
+		companyChange(base, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"),
+		companyChange(base, "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"),
+		companyChange(base.Add(time.Second), "cccccccc-cccc-cccc-cccc-cccccccccccc"),
+	}}
+	svc := NewCompanyChangesService(repo)
+
+	page, err := svc.Changes(context.Background(), "", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastAfter != (entity.CompanyChangeCursor{}) || repo.lastLimit != 3 || repo.lastSettle != CompanyChangesSettleWindow {
+		t.Fatalf("unexpected repository call: after %+v settle %s limit %d", repo.lastAfter, repo.lastSettle, repo.lastLimit)
+	}
+	want := EncodeCompanyChangeCursor(repo.changes[1].Cursor)
+	if len(page.Changes) != 2 || !page.HasMore || page.NextCursor != want {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	repo.changes = nil
+	page, err = svc.Changes(context.Background(), want, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.HasMore || page.NextCursor != want || len(page.Changes) != 0 {
+		t.Fatalf("expected empty page to keep the cursor, got %+v", page)
+	}
+}
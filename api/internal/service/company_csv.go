@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/legalform"
+	"github.com/octobees/leads-generator/api/internal/service/placeattrs"
+)
+
+// DefaultImportBatchSize is how many rows a batched import writes per transaction by default.
+const DefaultImportBatchSize = 500
+
+// CSVPreviewRows is how many parsed rows a CSV preview returns.
+const CSVPreviewRows = 10
+
+// maxCSVPreviewIssues caps the issues reported by a preview so a broken file stays readable.
+const maxCSVPreviewIssues = 50
+
+var requiredCSVHeaders = []string{"company", "address", "phone", "website", "rating", "reviews", "type_business", "city", "country"}
+
+// optionalCSVHeaders are import fields a file may leave out. categories lists place types
+// separated by semicolons, pipes or commas.
+var optionalCSVHeaders = []string{"categories"}
+
+// CSVColumnMapping maps CSV header names onto the canonical import fields, e.g. {"Name": "company"}.
+type CSVColumnMapping map[string]string
+
+// ParseCSVColumnMapping decodes a JSON object of csv column -> canonical field. Column names are
+// matched case-insensitively; an empty value yields no mapping.
+func ParseCSVColumnMapping(raw string) (CSVColumnMapping, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return nil, CSVValidationError{Message: "mapping must be a JSON object of csv column to field"}
+	}
+
+	mapping := make(CSVColumnMapping, len(decoded))
+	targets := make(map[string]string, len(decoded))
+	for column, field := range decoded {
+		column = normalizeCSVHeader(column)
+		field = normalizeCSVHeader(field)
+		if column == "" {
+			return nil, CSVValidationError{Message: "mapping contains an empty column name"}
+		}
+		if !isCSVField(field) {
+			return nil, CSVValidationError{Message: fmt.Sprintf("mapping targets unknown field %q (use %s)", field, strings.Join(append(requiredCSVHeaders, optionalCSVHeaders...), ", "))}
+		}
+		if previous, ok := targets[field]; ok && previous != column {
+			return nil, CSVValidationError{Message: fmt.Sprintf("mapping assigns columns %q and %q to field %q", previous, column, field)}
+		}
+		targets[field] = column
+		mapping[column] = field
+	}
+	return mapping, nil
+}
+
+// CSVIssue describes a problem detected while parsing an import file. Row is the 1-based line of
+// the file (the header is row 1) and is omitted for header problems.
+type CSVIssue struct {
+	Row     int    `json:"row,omitempty"`
+	Message string `json:"message"`
+}
+
+// CSVPreviewRow is a parsed company row as it would be imported.
+type CSVPreviewRow struct {
+	Row          int      `json:"row"`
+	Company      string   `json:"company"`
+	Address      string   `json:"address"`
+	Phone        *string  `json:"phone"`
+	Website      *string  `json:"website"`
+	Rating       *float64 `json:"rating"`
+	Reviews      *int     `json:"reviews"`
+	TypeBusiness *string  `json:"type_business"`
+	Categories   []string `json:"categories"`
+	City         *string  `json:"city"`
+	Country      *string  `json:"country"`
+}
+
+// CSVPreview reports how an import file would be read without writing anything.
+type CSVPreview struct {
+	// Columns maps each canonical field to the CSV column it is read from.
+	Columns map[string]string `json:"columns"`
+	// Ignored lists CSV columns that do not feed any field.
+	Ignored []string        `json:"ignored_columns"`
+	Rows    []CSVPreviewRow `json:"rows"`
+	// Total counts data rows; Valid counts the rows that would be imported.
+	Total  int        `json:"total"`
+	Valid  int        `json:"valid"`
+	Issues []CSVIssue `json:"issues"`
+}
+
+// PreviewCompaniesCSV parses an import file with the given mapping and returns the first
+// CSVPreviewRows rows plus every issue found, so the mapping can be checked before importing.
+// Header problems are reported as issues rather than errors.
+func (s *CompaniesService) PreviewCompaniesCSV(r io.Reader, mapping CSVColumnMapping) (CSVPreview, error) {
+	preview := CSVPreview{Columns: map[string]string{}, Ignored: []string{}, Rows: []CSVPreviewRow{}, Issues: []CSVIssue{}}
+	addIssue := func(issue CSVIssue) {
+		if len(preview.Issues) < maxCSVPreviewIssues {
+			preview.Issues = append(preview.Issues, issue)
+		}
+	}
+
+	reader, header, err := newCompanyCSVReader(r, mapping)
+	if header != nil {
+		preview.Columns, preview.Ignored = describeCSVHeader(header, mapping)
+	}
+	if err != nil {
+		var validationErr CSVValidationError
+		if errors.As(err, &validationErr) {
+			addIssue(CSVIssue{Message: validationErr.Message})
+			return preview, nil
+		}
+		return CSVPreview{}, err
+	}
+	defer reader.close()
+
+	for {
+		record, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		preview.Total++
+		if err != nil {
+			issue, ok := rowIssue(err, reader.row)
+			if !ok {
+				return CSVPreview{}, err
+			}
+			addIssue(issue)
+			continue
+		}
+		if record == nil {
+			addIssue(skippedRowIssue(reader.row))
+			continue
+		}
+
+		preview.Valid++
+		s.normalizeImportedCompany(record)
+		if len(preview.Rows) < CSVPreviewRows {
+			preview.Rows = append(preview.Rows, CSVPreviewRow{
+				Row:          reader.row,
+				Company:      record.Company,
+				Address:      record.Address,
+				Phone:        record.Phone,
+				Website:      record.Website,
+				Rating:       record.Rating,
+				Reviews:      record.Reviews,
+				TypeBusiness: record.TypeBusiness,
+				Categories:   record.Categories,
+				City:         record.City,
+				Country:      record.Country,
+			})
+		}
+	}
+	return preview, nil
+}
+
+// ImportBatch reports one batch written by ImportCompaniesInBatches.
+type ImportBatch struct {
+	// Rows counts the data rows read for the batch, including rejected ones.
+	Rows     int
+	Inserted int
+	Updated  int
+	Skipped  int
+	// Issues lists the rows of the batch that were rejected.
+	Issues []CSVIssue
+}
+
+// ImportCompaniesInBatches imports a file batchSize rows at a time, each batch in its own
+// transaction, so large files neither hold one long transaction nor fail on their first bad row.
+// Rows that cannot be used are reported to onBatch as issues instead of stopping the import.
+// Header problems, storage errors and errors returned by onBatch stop it; batches already written
+// are kept.
+func (s *CompaniesService) ImportCompaniesInBatches(ctx context.Context, r io.Reader, mode repository.ImportMode, mapping CSVColumnMapping, batchSize int, onBatch func(ImportBatch) error) error {
+	reader, _, err := newCompanyCSVReader(r, mapping)
+	if err != nil {
+		return err
+	}
+	defer reader.close()
+
+	if batchSize <= 0 {
+		batchSize = DefaultImportBatchSize
+	}
+	records := make([]repository.BulkUpsertCompanyInput, 0, batchSize)
+	var batch ImportBatch
+	flush := func() error {
+		if batch.Rows == 0 {
+			return nil
+		}
+		if len(records) > 0 {
+			result, err := s.repo.BulkUpsertCompanies(ctx, records, mode)
+			if err != nil {
+				return err
+			}
+			batch.Inserted, batch.Updated, batch.Skipped = result.Inserted, result.Updated, result.Skipped
+			s.publishCSVUpsert(ctx, result)
+		}
+		err := onBatch(batch)
+		records, batch = records[:0], ImportBatch{}
+		return err
+	}
+
+	for {
+		record, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		batch.Rows++
+		switch {
+		case err != nil:
+			issue, ok := rowIssue(err, reader.row)
+			if !ok {
+				return err
+			}
+			batch.Issues = append(batch.Issues, issue)
+		case record == nil:
+			batch.Issues = append(batch.Issues, skippedRowIssue(reader.row))
+		default:
+			s.normalizeImportedCompany(record)
+			records = append(records, *record)
+		}
+		if batch.Rows >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// rowIssue turns a row-level parse error into an issue; other errors are reported as not ok.
+func rowIssue(err error, row int) (CSVIssue, bool) {
+	var validationErr CSVValidationError
+	if errors.As(err, &validationErr) {
+		return CSVIssue{Row: row, Message: validationErr.Message}, true
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return CSVIssue{Row: row, Message: parseErr.Err.Error()}, true
+	}
+	return CSVIssue{}, false
+}
+
+func skippedRowIssue(row int) CSVIssue {
+	return CSVIssue{Row: row, Message: "row skipped: company and address are required"}
+}
+
+// companyCSVReader reads company rows from an import file whose header was already resolved.
+type companyCSVReader struct {
+	rows  *importRows
+	index map[string]int
+	row   int
+}
+
+// newCompanyCSVReader opens an import file, reads the header and resolves it against the mapping.
+// The raw header is returned whenever it could be read, even if it fails validation. Callers must
+// close the reader once done.
+func newCompanyCSVReader(r io.Reader, mapping CSVColumnMapping) (*companyCSVReader, []string, error) {
+	rows, err := openImportRows(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header, err := rows.Read()
+	if err != nil {
+		rows.close()
+		if errors.Is(err, io.EOF) {
+			return nil, nil, CSVValidationError{Message: "csv file is empty"}
+		}
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	index, err := buildHeaderIndex(header, mapping)
+	if err != nil {
+		rows.close()
+		return nil, header, err
+	}
+	return &companyCSVReader{rows: rows, index: index, row: 1}, header, nil
+}
+
+// close releases the underlying file.
+func (c *companyCSVReader) close() error {
+	return c.rows.close()
+}
+
+// next parses the following data row. It returns io.EOF at the end of the file and a nil record
+// without error for rows skipped because company or address is blank.
+func (c *companyCSVReader) next() (*repository.BulkUpsertCompanyInput, error) {
+	row, err := c.rows.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	c.row++
+	if err != nil {
+		return nil, fmt.Errorf("read csv row: %w", err)
+	}
+
+	company := strings.TrimSpace(row[c.index["company"]])
+	address := strings.TrimSpace(row[c.index["address"]])
+	if company == "" || address == "" {
+		return nil, nil
+	}
+
+	ratingRaw, reviewsRaw := row[c.index["rating"]], row[c.index["reviews"]]
+	if c.rows.decimalComma {
+		ratingRaw = normalizeDecimalComma(ratingRaw)
+		reviewsRaw = strings.ReplaceAll(reviewsRaw, ".", "")
+	}
+
+	rating, err := parseOptionalFloat(ratingRaw)
+	if err != nil {
+		return nil, CSVValidationError{Message: fmt.Sprintf("invalid rating value on row %d", c.row)}
+	}
+
+	reviews, err := parseOptionalInt(reviewsRaw)
+	if err != nil {
+		return nil, CSVValidationError{Message: fmt.Sprintf("invalid reviews value on row %d", c.row)}
+	}
+
+	var categories []string
+	if i, ok := c.index["categories"]; ok {
+		categories = strings.FieldsFunc(row[i], func(r rune) bool {
+			return r == ';' || r == '|' || r == ','
+		})
+	}
+	input := companyBulkInput(dto.CompanyBatchRecord{
+		Company:      company,
+		Address:      address,
+		Phone:        row[c.index["phone"]],
+		Website:      row[c.index["website"]],
+		Rating:       rating,
+		Reviews:      reviews,
+		TypeBusiness: row[c.index["type_business"]],
+		City:         row[c.index["city"]],
+		Country:      row[c.index["country"]],
+		Categories:   categories,
+	})
+	return &input, nil
+}
+
+// companyBulkInput prepares a company imported from a file or pushed through the batch API: the
+// legal form is split from its name, phone and website are normalized, and its business type
+// leads its categories.
+func companyBulkInput(record dto.CompanyBatchRecord) repository.BulkUpsertCompanyInput {
+	company := strings.TrimSpace(record.Company)
+	extraction := legalform.Extract(company)
+	country := normalizeString(record.Country)
+	typeBusiness := normalizeString(record.TypeBusiness)
+	categories := append([]string{derefString(typeBusiness)}, record.Categories...)
+	return repository.BulkUpsertCompanyInput{
+		Company:      company,
+		DisplayName:  extraction.DisplayName,
+		LegalForm:    normalizeString(extraction.Form),
+		Address:      strings.TrimSpace(record.Address),
+		Phone:        normalizedCompanyPhone(normalizeString(record.Phone), country),
+		Website:      normalizedCompanyWebsite(normalizeString(record.Website)),
+		Rating:       record.Rating,
+		Reviews:      record.Reviews,
+		TypeBusiness: typeBusiness,
+		City:         normalizeString(record.City),
+		Country:      country,
+		Categories:   placeattrs.MergeCategories(categories...),
+	}
+}
+
+// normalizeImportedCompany maps the city of an imported company onto the location catalog and its
+// business type and categories onto the category taxonomy.
+func (s *CompaniesService) normalizeImportedCompany(input *repository.BulkUpsertCompanyInput) {
+	if s.locations != nil {
+		input.City = s.locations.normalizeCityPointer(input.City, input.Country)
+	}
+	if s.categories != nil {
+		input.TypeBusiness, input.Categories = s.categories.normalizeCompany(input.TypeBusiness, input.Categories)
+	}
+}
+
+// buildHeaderIndex resolves each canonical field to its column position. Mapped columns take
+// precedence over columns that already carry a canonical name.
+func buildHeaderIndex(header []string, mapping CSVColumnMapping) (map[string]int, error) {
+	index := make(map[string]int)
+	present := make(map[string]bool, len(header))
+	for i, col := range header {
+		name := normalizeCSVHeader(col)
+		present[name] = true
+		if _, mapped := mapping[name]; mapped {
+			continue
+		}
+		index[name] = i
+	}
+	for i, col := range header {
+		if field, ok := mapping[normalizeCSVHeader(col)]; ok {
+			index[field] = i
+		}
+	}
+
+	missingMapped := make([]string, 0)
+	for column := range mapping {
+		if !present[column] {
+			missingMapped = append(missingMapped, column)
+		}
+	}
+	if len(missingMapped) > 0 {
+		sort.Strings(missingMapped)
+		return nil, CSVValidationError{Message: fmt.Sprintf("mapped columns not found in csv header: %s", strings.Join(missingMapped, ", "))}
+	}
+
+	missing := make([]string, 0)
+	for _, required := range requiredCSVHeaders {
+		if _, ok := index[required]; !ok {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, CSVValidationError{Message: fmt.Sprintf("missing required columns: %s", strings.Join(missing, ", "))}
+	}
+	return index, nil
+}
+
+// describeCSVHeader reports which column feeds each field and which columns are ignored.
+func describeCSVHeader(header []string, mapping CSVColumnMapping) (map[string]string, []string) {
+	columns := make(map[string]string)
+	ignored := make([]string, 0)
+	for _, col := range header {
+		name := normalizeCSVHeader(col)
+		field, mapped := mapping[name]
+		if !mapped {
+			field = name
+		}
+		if !isCSVField(field) {
+			ignored = append(ignored, col)
+			continue
+		}
+		if _, taken := columns[field]; taken && !mapped {
+			ignored = append(ignored, col)
+			continue
+		}
+		columns[field] = col
+	}
+	return columns, ignored
+}
+
+func normalizeCSVHeader(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func isCSVField(field string) bool {
+	return slices.Contains(requiredCSVHeaders, field) || slices.Contains(optionalCSVHeaders, field)
+}
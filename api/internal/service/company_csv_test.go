@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const mappedCSVHeader = "Name,Street,Tel,URL,Stars,Review Count,Category,Town,Nation,Notes\n"
+
+var testCSVMapping = CSVColumnMapping{
+	"name":         "company",
+	"street":       "address",
+	"tel":          "phone",
+	"url":          "website",
+	"stars":        "rating",
+	"review count": "reviews",
+	"category":     "type_business",
+	"town":         "city",
+	"nation":       "country",
+}
+
+func TestParseCSVColumnMapping(t *testing.T) {
+	mapping, err := ParseCSVColumnMapping(`{" Name ":"Company","Street":"address"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapping["name"] != "company" || mapping["street"] != "address" {
+		t.Fatalf("unexpected mapping: %+v", mapping)
+	}
+
+	if mapping, err := ParseCSVColumnMapping("  "); err != nil || mapping != nil {
+		t.Fatalf("expected no mapping for empty input, got %+v (err=%v)", mapping, err)
+	}
+
+	for _, raw := range []string{`[1]`, `{"Name":"title"}`, `{"Name":"company","Label":"company"}`, `{"":"company"}`} {
+		if _, err := ParseCSVColumnMapping(raw); err == nil {
+			t.Fatalf("expected error for %s", raw)
+		}
+	}
+}
+
+func TestBuildHeaderIndex_Mapping(t *testing.T) {
+	header := strings.Split(strings.TrimSpace(mappedCSVHeader), ",")
+	index, err := buildHeaderIndex(header, testCSVMapping)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if index["company"] != 0 || index["reviews"] != 5 || index["country"] != 8 {
+		t.Fatalf("header index not built from mapping: %+v", index)
+	}
+
+	// A mapped column wins over a column already named after the field.
+	index, err = buildHeaderIndex([]string{"company", "address", "phone", "website", "rating", "reviews", "type_business", "city", "country", "Legal Name"}, CSVColumnMapping{"legal name": "company"})
+	if err != nil || index["company"] != 9 {
+		t.Fatalf("expected mapped column to take precedence, got %+v (err=%v)", index, err)
+	}
+
+	if _, err := buildHeaderIndex(header, CSVColumnMapping{"missing": "company"}); err == nil || !strings.Contains(err.Error(), "mapped columns not found") {
+		t.Fatalf("expected unknown mapped column error, got %v", err)
+	}
+}
+
+func TestCompaniesService_ImportCompaniesCSV_Mapping(t *testing.T) {
+	var received []repository.BulkUpsertCompanyInput
+	repo := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	}
+	csv := mappedCSVHeader + "Acme,Main St,123,https://acme.com,4.5,10,store,Gotham,USA,vip\n"
+
+	summary, err := NewCompaniesService(repo).ImportCompaniesCSV(context.Background(), strings.NewReader(csv), repository.ImportModeOverwrite, testCSVMapping)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Inserted != 1 || len(received) != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if rec := received[0]; rec.Company != "Acme" || rec.Address != "Main St" || rec.Reviews == nil || *rec.Reviews != 10 || rec.Country == nil || *rec.Country != "USA" {
+		t.Fatalf("unexpected record payload: %+v", rec)
+	}
+}
+
+func TestCompaniesService_ImportCompaniesCSV_Categories(t *testing.T) {
+	var received []repository.BulkUpsertCompanyInput
+	repo := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	}
+	csv := strings.Join(append(requiredCSVHeaders, "Categories"), ",") + "\n" +
+		"Kopi Kenangan,Jl. Sudirman 1,,,,,Coffee Shop,Jakarta,Indonesia,\"cafe; Bakery|coffee_shop\"\n" +
+		"Toko Roti,Jl. Thamrin 2,,,,,,Jakarta,Indonesia,\n"
+
+	if _, err := NewCompaniesService(repo).ImportCompaniesCSV(context.Background(), strings.NewReader(csv), repository.ImportModeOverwrite, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("unexpected records %+v", received)
+	}
+	if got := received[0].Categories; strings.Join(got, ",") != "coffee_shop,cafe,bakery" {
+		t.Fatalf("expected the business type first and categories deduplicated, got %v", got)
+	}
+	if got := received[1].Categories; got != nil {
+		t.Fatalf("expected no categories, got %v", got)
+	}
+}
+
+func TestCompaniesService_PreviewCompaniesCSV(t *testing.T) {
+	var rows strings.Builder
+	rows.WriteString(mappedCSVHeader)
+	rows.WriteString(",Nowhere,,,,,,,,\n")
+	rows.WriteString("Broken,Main St,,,five,,,,,\n")
+	for i := 0; i < 12; i++ {
+		rows.WriteString("Acme,Main St,,,4.5,10,store,Gotham,USA,\n")
+	}
+
+	repo := &mockCompaniesRepository{}
+	preview, err := NewCompaniesService(repo).PreviewCompaniesCSV(strings.NewReader(rows.String()), testCSVMapping)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview.Total != 14 || preview.Valid != 12 || len(preview.Rows) != CSVPreviewRows {
+		t.Fatalf("unexpected preview counts: total=%d valid=%d rows=%d", preview.Total, preview.Valid, len(preview.Rows))
+	}
+	if preview.Rows[0].Row != 4 || preview.Rows[0].Company != "Acme" {
+		t.Fatalf("unexpected first row: %+v", preview.Rows[0])
+	}
+	if len(preview.Issues) != 2 || preview.Issues[0].Row != 2 || preview.Issues[1].Row != 3 || !strings.Contains(preview.Issues[1].Message, "invalid rating") {
+		t.Fatalf("unexpected issues: %+v", preview.Issues)
+	}
+	if preview.Columns["company"] != "Name" || len(preview.Ignored) != 1 || preview.Ignored[0] != "Notes" {
+		t.Fatalf("unexpected column report: %+v ignored=%v", preview.Columns, preview.Ignored)
+	}
+	if repo.bulkMode != "" {
+		t.Fatalf("expected preview not to import")
+	}
+}
+
+func TestCompaniesService_PreviewCompaniesCSV_HeaderIssue(t *testing.T) {
+	preview, err := NewCompaniesService(&mockCompaniesRepository{}).PreviewCompaniesCSV(strings.NewReader(mappedCSVHeader), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(preview.Issues) != 1 || !strings.Contains(preview.Issues[0].Message, "missing required columns") {
+		t.Fatalf("expected missing columns issue, got %+v", preview.Issues)
+	}
+	if len(preview.Ignored) != 10 || len(preview.Rows) != 0 {
+		t.Fatalf("expected every column ignored and no rows, got %+v", preview)
+	}
+}
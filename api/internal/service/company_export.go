@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrExportsUnavailable is returned when a company export is requested without an export repository.
+var ErrExportsUnavailable = errors.New("company exports unavailable")
+
+// ExportCSVHeaders are the columns of company CSV exports. They start with the import columns so
+// an export can be imported again; the import ignores the extra ones.
+var ExportCSVHeaders = []string{"id", "company", "address", "phone", "website", "rating", "reviews", "type_business", "city", "country", "scraped_at", "updated_at", "outreach_language", "shared_contact", "do_not_contact", "exported_by"}
+
+// ExportCapError reports that a filter matches more rows than the caller's role may export.
+type ExportCapError struct {
+	Matched int64
+	Cap     int64
+}
+
+// Error implements the error interface.
+func (e ExportCapError) Error() string {
+	return fmt.Sprintf("filter matches %d companies but exports are limited to %d rows; narrow the filter or set limit", e.Matched, e.Cap)
+}
+
+// ExportStamp identifies who ran an export. It is written into the exported file so a copy found
+// elsewhere can be traced back to the account that downloaded it.
+type ExportStamp struct {
+	ID     uuid.UUID
+	UserID string
+	Email  string
+	At     time.Time
+}
+
+// NewExportStamp stamps a new export by the given user.
+func NewExportStamp(userID, email string, at time.Time) ExportStamp {
+	return ExportStamp{ID: uuid.New(), UserID: userID, Email: email, At: at.UTC()}
+}
+
+// String renders the stamp as "<email> (<user id>) export <id> at <time>".
+func (s ExportStamp) String() string {
+	return fmt.Sprintf("%s (%s) export %s at %s", s.Email, s.UserID, s.ID, s.At.Format(time.RFC3339))
+}
+
+// CompanyExport is a checked export ready to be written.
+type CompanyExport struct {
+	// Rows is how many companies the export holds.
+	Rows   int64
+	filter dto.ListFilter
+}
+
+// WithExports enables company exports. rowCaps maps a role onto the most rows it may export at
+// once; roles without an entry use the "user" cap and zero means unlimited.
+func WithExports(exports repository.CompanyExportRepository, rowCaps map[string]int64) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.exports = exports
+		s.exportCaps = rowCaps
+	}
+}
+
+// exportCap returns the row cap of role, zero meaning unlimited.
+func (s *CompaniesService) exportCap(role string) int64 {
+	if limit, ok := s.exportCaps[role]; ok {
+		return limit
+	}
+	return s.exportCaps["user"]
+}
+
+// PrepareCompanyExport counts the companies matching filter and checks them against the row cap
+// of role. Pagination is ignored; a positive filter.Limit exports only the first Limit companies.
+func (s *CompaniesService) PrepareCompanyExport(ctx context.Context, filter dto.ListFilter, role string) (*CompanyExport, error) {
+	if s.exports == nil {
+		return nil, ErrExportsUnavailable
+	}
+	if filter.BusinessStatus == "" {
+		filter.BusinessStatus = BusinessStatusOperational
+	}
+	filter = s.withScoreWeight(s.withCategoryTaxonomy(filter))
+
+	rows, err := s.exports.CountCompanies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 && int64(filter.Limit) < rows {
+		rows = int64(filter.Limit)
+	}
+	if limit := s.exportCap(role); limit > 0 && rows > limit {
+		return nil, ExportCapError{Matched: rows, Cap: limit}
+	}
+	return &CompanyExport{Rows: rows, filter: filter}, nil
+}
+
+// WriteCompanyExportCSV writes a prepared export as CSV in locale, stamping every row with the
+// exporter. Rows added since the export was prepared are left out so the cap still holds, and so
+// are companies on the do-not-contact list unless the filter includes them.
+func (s *CompaniesService) WriteCompanyExportCSV(ctx context.Context, export *CompanyExport, stamp ExportStamp, locale ExportLocale, w io.Writer) error {
+	if s.exports == nil {
+		return ErrExportsUnavailable
+	}
+	return writeExportCSV(w, locale, ExportCSVHeaders, func(writer *csv.Writer) error {
+		if export.Rows == 0 {
+			return nil
+		}
+		exportedBy := stamp.String()
+		rows := newSuppressionFilter(ctx, s, export.filter.IncludeSuppressed,
+			func(company *entity.Company) *entity.Company { return company },
+			func(company entity.Company) error {
+				return writer.Write(exportCSVRecord(company, locale, exportedBy))
+			})
+		if err := s.exports.ExportCompanies(ctx, export.filter, export.Rows, rows.add); err != nil {
+			return err
+		}
+		return rows.flush()
+	})
+}
+
+// exportFlushRows is how many JSON Lines export rows are buffered before they are sent on. Rows are
+// read from the database as they are written, so a slow reader slows the query down instead of
+// the rows piling up in memory.
+const exportFlushRows = 500
+
+// companyExportLine is a line of a JSON Lines company export.
+type companyExportLine struct {
+	entity.Company
+	ExportedBy string `json:"exported_by"`
+}
+
+// WriteCompanyExportNDJSON writes a prepared export as JSON Lines, one company object per line
+// stamped with the exporter in exported_by, for loading into data warehouses. The same rows as the
+// CSV export are written; when w is an http.Flusher it is flushed every exportFlushRows rows.
+func (s *CompaniesService) WriteCompanyExportNDJSON(ctx context.Context, export *CompanyExport, stamp ExportStamp, w io.Writer) error {
+	if s.exports == nil {
+		return ErrExportsUnavailable
+	}
+	if export.Rows == 0 {
+		return nil
+	}
+	buf := bufio.NewWriter(w)
+	flush := func() error {
+		if err := buf.Flush(); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	exportedBy := stamp.String()
+	written := 0
+	rows := newSuppressionFilter(ctx, s, export.filter.IncludeSuppressed,
+		func(company *entity.Company) *entity.Company { return company },
+		func(company entity.Company) error {
+			if err := encoder.Encode(companyExportLine{Company: company, ExportedBy: exportedBy}); err != nil {
+				return fmt.Errorf("write export: %w", err)
+			}
+			if written++; written%exportFlushRows == 0 {
+				return flush()
+			}
+			return nil
+		})
+	if err := s.exports.ExportCompanies(ctx, export.filter, export.Rows, rows.add); err != nil {
+		return err
+	}
+	if err := rows.flush(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// writeExportCSV writes headers and the records produced by rows as CSV in locale.
+func writeExportCSV(w io.Writer, locale ExportLocale, headers []string, rows func(*csv.Writer) error) error {
+	if locale.BOM {
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return fmt.Errorf("write export bom: %w", err)
+		}
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = locale.comma()
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("write export header: %w", err)
+	}
+	if err := rows(writer); err != nil {
+		return err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
+
+func exportCSVRecord(company entity.Company, locale ExportLocale, exportedBy string) []string {
+	record := []string{
+		company.ID.String(),
+		company.Company,
+		derefString(company.Address),
+		derefString(company.Phone),
+		derefString(company.Website),
+		"",
+		"",
+		derefString(company.TypeBusiness),
+		derefString(company.City),
+		derefString(company.Country),
+		locale.formatTime(company.ScrapedAt),
+		locale.formatTime(&company.UpdatedAt),
+		derefString(company.PreferredOutreachLanguage),
+		strconv.FormatBool(company.SharedContact),
+		strconv.FormatBool(company.DoNotContact),
+		exportedBy,
+	}
+	if company.Rating != nil {
+		record[5] = locale.formatFloat(*company.Rating)
+	}
+	if company.Reviews != nil {
+		record[6] = strconv.Itoa(*company.Reviews)
+	}
+	return record
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
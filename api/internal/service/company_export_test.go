@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type stubCompanyExports struct {
+	companies   []entity.Company
+	lastFilter  dto.ListFilter
+	exportLimit int64
+}
+
+func (s *stubCompanyExports) CountCompanies(ctx context.Context, filter dto.ListFilter) (int64, error) {
+	s.lastFilter = filter
+	return int64(len(s.companies)), nil
+}
+
+func (s *stubCompanyExports) ExportCompanies(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.Company) error) error {
+	s.exportLimit = limit
+	for i, company := range s.companies {
+		if limit > 0 && int64(i) >= limit {
+			break
+		}
+		if err := fn(company); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCompaniesService_PrepareCompanyExport_RowCaps(t *testing.T) {
+	exports := &stubCompanyExports{companies: make([]entity.Company, 5)}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExports(exports, map[string]int64{"user": 3, "admin": 0}))
+	ctx := context.Background()
+
+	_, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{City: "Jakarta"}, "user")
+	var capErr ExportCapError
+	if !errors.As(err, &capErr) || capErr.Matched != 5 || capErr.Cap != 3 {
+		t.Fatalf("expected cap error, got %v", err)
+	}
+	if exports.lastFilter.BusinessStatus != BusinessStatusOperational {
+		t.Fatalf("expected listing status default, got %+v", exports.lastFilter)
+	}
+	if _, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{}, "auditor"); !errors.As(err, &capErr) {
+		t.Fatalf("expected unknown roles to get the user cap, got %v", err)
+	}
+
+	export, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{Limit: 3}, "user")
+	if err != nil || export.Rows != 3 {
+		t.Fatalf("expected limit to fit the cap, got %+v, %v", export, err)
+	}
+	export, err = svc.PrepareCompanyExport(ctx, dto.ListFilter{}, "admin")
+	if err != nil || export.Rows != 5 {
+		t.Fatalf("expected admins to export everything, got %+v, %v", export, err)
+	}
+
+	if _, err := NewCompaniesService(&mockCompaniesRepository{}).PrepareCompanyExport(ctx, dto.ListFilter{}, "user"); !errors.Is(err, ErrExportsUnavailable) {
+		t.Fatalf("expected ErrExportsUnavailable, got %v", err)
+	}
+}
+
+func TestCompaniesService_WriteCompanyExportCSV_StampsRows(t *testing.T) {
+	rating, reviews, city, language := 4.5, 12, "Jakarta", "id"
+	exports := &stubCompanyExports{companies: []entity.Company{
+		{ID: uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"), Company: "Acme", Rating: &rating, Reviews: &reviews, City: &city, UpdatedAt: time.Date(2024, 5, 31, 17, 30, 0, 0, time.UTC), PreferredOutreachLanguage: &language},
+		{ID: uuid.New(), Company: "Beta"},
+	}}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExports(exports, nil))
+	ctx := context.Background()
+
+	export, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{}, "user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A company added after the count is left out of the export.
+	exports.companies = append(exports.companies, entity.Company{ID: uuid.New(), Company: "Late"})
+
+	stamp := NewExportStamp("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "sales@example.com", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	if err := svc.WriteCompanyExportCSV(ctx, export, stamp, DefaultExportLocale, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 3 || exports.exportLimit != 2 {
+		t.Fatalf("expected header and 2 rows, got %v (limit %d)", records, exports.exportLimit)
+	}
+	want := []string{"bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb", "Acme", "", "", "", "4.5", "12", "", "Jakarta", "", "", "2024-05-31T17:30:00Z", "id", "false", "false", stamp.String()}
+	for i, value := range want {
+		if records[1][i] != value {
+			t.Fatalf("column %s: expected %q, got %q", ExportCSVHeaders[i], value, records[1][i])
+		}
+	}
+	if records[2][len(ExportCSVHeaders)-1] != "sales@example.com (aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa) export "+stamp.ID.String()+" at 2024-06-02T08:00:00Z" {
+		t.Fatalf("unexpected stamp: %q", records[2][len(ExportCSVHeaders)-1])
+	}
+}
+
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (r *flushRecorder) Flush() { r.flushes++ }
+
+func TestCompaniesService_WriteCompanyExportNDJSON(t *testing.T) {
+	companies := make([]entity.Company, 1200)
+	for i := range companies {
+		companies[i] = entity.Company{ID: uuid.New(), Company: "Kopi <&> Co"}
+	}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExports(&stubCompanyExports{companies: companies}, nil))
+	ctx := context.Background()
+	export, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{}, "admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stamp := NewExportStamp(uuid.NewString(), "data@example.com", time.Now())
+	var out flushRecorder
+	if err := svc.WriteCompanyExportNDJSON(ctx, export, stamp, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Two full batches of exportFlushRows, then the rest.
+	if out.flushes != 3 {
+		t.Fatalf("expected 3 flushes, got %d", out.flushes)
+	}
+	if strings.Contains(out.String(), `\u003c`) {
+		t.Fatalf("expected HTML characters unescaped")
+	}
+	lines := 0
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %d is not JSON: %v", lines+1, err)
+		}
+		if line["company"] != "Kopi <&> Co" || line["exported_by"] != stamp.String() || line["id"] != companies[lines].ID.String() {
+			t.Fatalf("unexpected line %d: %s", lines+1, scanner.Text())
+		}
+		lines++
+	}
+	if lines != len(companies) {
+		t.Fatalf("expected %d lines, got %d", len(companies), lines)
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// DefaultFacetLimit caps how many cities and business types a facet lists.
+const DefaultFacetLimit = 20
+
+// ErrFacetsUnavailable is returned when facet counts are requested without a facets repository.
+var ErrFacetsUnavailable = errors.New("company facets unavailable")
+
+// WithFacets enables facet counts for company listings.
+func WithFacets(facets repository.CompanyFacetsRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.facets = facets
+	}
+}
+
+// CompanyFacets counts the companies matching filter per city, business type, website status and
+// rating bucket. The filter gets the same defaults as ListCompanies so counts match the listing.
+func (s *CompaniesService) CompanyFacets(ctx context.Context, filter dto.ListFilter) (*entity.CompanyFacets, error) {
+	if s.facets == nil {
+		return nil, ErrFacetsUnavailable
+	}
+	return s.facets.Facets(ctx, s.withCategoryTaxonomy(normalizeListFilter(filter)), DefaultFacetLimit)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrHistoryUnavailable is returned when company history is requested without a snapshot repository.
+var ErrHistoryUnavailable = errors.New("company history unavailable")
+
+// WithHistory enables GET /companies/:id/history lookups.
+func WithHistory(snapshots repository.CompanySnapshotsRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.snapshots = snapshots
+	}
+}
+
+// CompanyHistory returns the rating and reviews every scrape run observed for a company, with the
+// review growth between observations.
+func (s *CompaniesService) CompanyHistory(ctx context.Context, companyID string) (*entity.CompanyHistory, error) {
+	if s.snapshots == nil {
+		return nil, ErrHistoryUnavailable
+	}
+	id, err := uuid.Parse(companyID)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	snapshots, err := s.snapshots.ListCompanySnapshots(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+
+	history := &entity.CompanyHistory{CompanyID: id, Snapshots: snapshots}
+	var first, previous *int
+	for i := range snapshots {
+		reviews := snapshots[i].Reviews
+		if reviews == nil {
+			continue
+		}
+		if previous == nil {
+			first = reviews
+		} else {
+			delta := *reviews - *previous
+			snapshots[i].ReviewsDelta = &delta
+			growth := *reviews - *first
+			history.ReviewGrowth = &growth
+		}
+		previous = reviews
+	}
+	return history, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubSnapshotsRepo struct {
+	snapshots []entity.CompanySnapshot
+}
+
+func (s *stubSnapshotsRepo) ListCompanySnapshots(ctx context.Context, companyID uuid.UUID) ([]entity.CompanySnapshot, error) {
+	if companyID == uuid.Nil {
+		return nil, repository.ErrCompanyNotFound
+	}
+	return s.snapshots, nil
+}
+
+func TestCompaniesService_CompanyHistory(t *testing.T) {
+	reviews := func(n int) *int { return &n }
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &stubSnapshotsRepo{snapshots: []entity.CompanySnapshot{
+		{ScrapeRunID: uuid.New(), Reviews: reviews(100), ObservedAt: start},
+		{ScrapeRunID: uuid.New(), ObservedAt: start.AddDate(0, 1, 0)},
+		{ScrapeRunID: uuid.New(), Reviews: reviews(130), ObservedAt: start.AddDate(0, 2, 0)},
+		{ScrapeRunID: uuid.New(), Reviews: reviews(125), ObservedAt: start.AddDate(0, 3, 0)},
+	}}
+	svc := NewCompaniesService(nil, WithHistory(repo))
+	id := uuid.New()
+
+	history, err := svc.CompanyHistory(context.Background(), id.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if history.CompanyID != id || len(history.Snapshots) != 4 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	deltas := []*int{nil, nil, reviews(30), reviews(-5)}
+	for i, want := range deltas {
+		got := history.Snapshots[i].ReviewsDelta
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Fatalf("snapshot %d: unexpected reviews delta %v", i, got)
+		}
+	}
+	if history.ReviewGrowth == nil || *history.ReviewGrowth != 25 {
+		t.Fatalf("expected review growth of 25, got %v", history.ReviewGrowth)
+	}
+
+	repo.snapshots = repo.snapshots[:1]
+	if history, _ := svc.CompanyHistory(context.Background(), id.String()); history.ReviewGrowth != nil {
+		t.Fatalf("expected no growth from a single observation, got %d", *history.ReviewGrowth)
+	}
+
+	if _, err := svc.CompanyHistory(context.Background(), uuid.Nil.String()); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+	if _, err := svc.CompanyHistory(context.Background(), "nope"); !errors.Is(err, ErrInvalidCompanyID) {
+		t.Fatalf("expected ErrInvalidCompanyID, got %v", err)
+	}
+	if _, err := NewCompaniesService(nil).CompanyHistory(context.Background(), id.String()); !errors.Is(err, ErrHistoryUnavailable) {
+		t.Fatalf("expected ErrHistoryUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/outbound"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// Defaults of a CompanyImageMirrorService.
+const (
+	defaultImageMirrorBatch    = 50
+	defaultImageMirrorTimeout  = 15 * time.Second
+	defaultImageMaxBytes       = 2 << 20
+	defaultPlacesPhotoMaxWidth = 800
+	maxLogoURLLength           = 2048
+	maxPhotoReferenceLength    = 1024
+)
+
+// placesPhotoURL is the legacy Places photo endpoint; references of the Places API (New), named
+// places/{place}/photos/{photo}, are fetched from placesMediaURL instead.
+const (
+	placesPhotoURL = "https://maps.googleapis.com/maps/api/place/photo"
+	placesMediaURL = "https://places.googleapis.com/v1"
+)
+
+// photoReferencePattern accepts legacy photo references and Places API (New) photo names.
+var photoReferencePattern = regexp.MustCompile(`^[A-Za-z0-9_\-/]+$`)
+
+// imageExtensions maps the image types mirrored to the extension of their object. SVG is left out:
+// it may carry scripts, so SVG logos are only served from the website.
+var imageExtensions = map[string]string{
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/webp":               ".webp",
+	"image/avif":               ".avif",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+// WithCompanyImages stores the logo and Maps photo reference of enrichment results on the company,
+// where listings serve them.
+func WithCompanyImages(images repository.CompanyImagesRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.images = images
+	}
+}
+
+func (s *CompaniesService) storeCompanyImages(ctx context.Context, companyID uuid.UUID, payload dto.EnrichResultRequest) {
+	if s.images == nil {
+		return
+	}
+	if err := s.images.SaveCompanyImages(ctx, companyID, companyLogoURL(payload.LogoURL), companyPhotoReference(payload.PhotoReference)); err != nil {
+		log.Printf("failed to store images of company %s: %v", companyID, err)
+	}
+}
+
+// companyLogoURL returns the logo URL of an enrichment result when it is an absolute http(s) URL.
+func companyLogoURL(raw *string) *string {
+	value := trimPointer(raw)
+	if value == nil || len(*value) > maxLogoURLLength {
+		return nil
+	}
+	u, err := url.Parse(*value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	logo := u.String()
+	return &logo
+}
+
+// companyPhotoReference returns the Maps photo reference of an enrichment result when it looks like
+// one.
+func companyPhotoReference(raw *string) *string {
+	value := trimPointer(raw)
+	if value == nil || len(*value) > maxPhotoReferenceLength || !photoReferencePattern.MatchString(*value) {
+		return nil
+	}
+	return value
+}
+
+// ImageStore keeps mirrored company images; storage.GCSStore and storage.LocalStore implement it.
+type ImageStore interface {
+	Upload(ctx context.Context, name, contentType string, r io.Reader) error
+}
+
+// CompanyImageMirrorService copies company logos and Maps photos to object storage, so listings
+// serve them from a bucket the UI can rely on rather than from the website or Google.
+type CompanyImageMirrorService struct {
+	repo      repository.CompanyImagesRepository
+	store     ImageStore
+	prefix    string
+	publicURL string
+	client    *http.Client
+	batchSize int
+	maxBytes  int64
+	// placesKey resolves Maps photo references; without it photos are not mirrored.
+	placesKey      string
+	placesPhotoURL string
+	placesMediaURL string
+	now            func() time.Time
+}
+
+// CompanyImageMirrorOption customises a CompanyImageMirrorService.
+type CompanyImageMirrorOption func(*CompanyImageMirrorService)
+
+// WithImageMirrorPolicy downloads images through a client following policy, each request bounded by
+// timeout, in place of the default policy refusing internal addresses.
+func WithImageMirrorPolicy(policy *outbound.Policy, timeout time.Duration) CompanyImageMirrorOption {
+	return func(s *CompanyImageMirrorService) {
+		if policy != nil && timeout > 0 {
+			s.client = policy.Client(timeout)
+		}
+	}
+}
+
+// WithImageMirrorLimits sets how many companies each pass mirrors and the largest image copied.
+func WithImageMirrorLimits(batchSize int, maxBytes int64) CompanyImageMirrorOption {
+	return func(s *CompanyImageMirrorService) {
+		if batchSize > 0 {
+			s.batchSize = batchSize
+		}
+		if maxBytes > 0 {
+			s.maxBytes = maxBytes
+		}
+	}
+}
+
+// WithPlacesPhotoKey mirrors Maps photos, fetching their references with a Places API key.
+func WithPlacesPhotoKey(key string) CompanyImageMirrorOption {
+	return func(s *CompanyImageMirrorService) {
+		s.placesKey = strings.TrimSpace(key)
+	}
+}
+
+// NewCompanyImageMirrorService builds a CompanyImageMirrorService uploading images to store under
+// prefix, served from publicURL followed by the object name.
+func NewCompanyImageMirrorService(repo repository.CompanyImagesRepository, store ImageStore, prefix, publicURL string, opts ...CompanyImageMirrorOption) *CompanyImageMirrorService {
+	s := &CompanyImageMirrorService{
+		repo:           repo,
+		store:          store,
+		prefix:         strings.Trim(prefix, "/"),
+		publicURL:      strings.TrimRight(publicURL, "/"),
+		client:         outbound.New().Client(defaultImageMirrorTimeout),
+		batchSize:      defaultImageMirrorBatch,
+		maxBytes:       defaultImageMaxBytes,
+		placesPhotoURL: placesPhotoURL,
+		placesMediaURL: placesMediaURL,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run mirrors a batch of new images every interval until ctx is cancelled.
+func (s *CompanyImageMirrorService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.MirrorDue(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to mirror company images: %v", err)
+			}
+		}
+	}
+}
+
+// MirrorDue mirrors the images of one batch of companies and returns how many companies were
+// processed. An image that cannot be copied keeps being served from its source until it changes.
+func (s *CompanyImageMirrorService) MirrorDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ListImagesToMirror(ctx, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for _, images := range due {
+		if images.LogoURL != nil && images.LogoMirrorURL == nil {
+			images.LogoMirrorURL = s.mirror(ctx, images.CompanyID, "logo", *images.LogoURL)
+		}
+		if images.PhotoReference != nil && images.PhotoMirrorURL == nil && s.placesKey != "" {
+			images.PhotoMirrorURL = s.mirror(ctx, images.CompanyID, "photo", s.photoSource(*images.PhotoReference))
+		}
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+		if err := s.repo.SaveImageMirror(ctx, images, s.now()); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// photoSource returns the URL a photo reference is downloaded from.
+func (s *CompanyImageMirrorService) photoSource(reference string) string {
+	if strings.HasPrefix(reference, "places/") {
+		return fmt.Sprintf("%s/%s/media?maxWidthPx=%d&key=%s", s.placesMediaURL, reference, defaultPlacesPhotoMaxWidth, url.QueryEscape(s.placesKey))
+	}
+	return fmt.Sprintf("%s?maxwidth=%d&photo_reference=%s&key=%s", s.placesPhotoURL, defaultPlacesPhotoMaxWidth, url.QueryEscape(reference), url.QueryEscape(s.placesKey))
+}
+
+// mirror copies one image and returns the public URL of the copy, or nil when it was not copied.
+func (s *CompanyImageMirrorService) mirror(ctx context.Context, companyID uuid.UUID, kind, source string) *string {
+	body, contentType, err := s.download(ctx, source)
+	if err != nil {
+		// The source may carry the Places key, so only the kind of image is logged.
+		log.Printf("failed to download %s of company %s: %v", kind, companyID, err)
+		return nil
+	}
+	name := path.Join(s.prefix, companyID.String(), kind+imageExtensions[contentType])
+	if err := s.store.Upload(ctx, name, contentType, bytes.NewReader(body)); err != nil {
+		log.Printf("failed to upload %s of company %s: %v", kind, companyID, err)
+		return nil
+	}
+	mirrored := s.publicURL + "/" + name
+	return &mirrored
+}
+
+func (s *CompanyImageMirrorService) download(ctx context.Context, source string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", websiteCheckUserAgent)
+	req.Header.Set("Accept", "image/*")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// url.Error repeats the URL; drop it so the Places key stays out of the logs.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if _, ok := imageExtensions[contentType]; !ok {
+		return nil, "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read image: %w", err)
+	}
+	if int64(len(body)) > s.maxBytes {
+		return nil, "", fmt.Errorf("image larger than %d bytes", s.maxBytes)
+	}
+	return body, contentType, nil
+}
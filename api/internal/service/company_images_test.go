@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/outbound"
+)
+
+type stubCompanyImagesRepo struct {
+	due   []entity.CompanyImages
+	saved map[uuid.UUID]entity.CompanyImages
+	// stored holds the images enrichment stored, by company.
+	stored map[uuid.UUID][2]*string
+}
+
+func (s *stubCompanyImagesRepo) SaveCompanyImages(ctx context.Context, companyID uuid.UUID, logoURL, photoReference *string) error {
+	if s.stored == nil {
+		s.stored = make(map[uuid.UUID][2]*string)
+	}
+	s.stored[companyID] = [2]*string{logoURL, photoReference}
+	return nil
+}
+
+func (s *stubCompanyImagesRepo) ListImagesToMirror(ctx context.Context, limit int) ([]entity.CompanyImages, error) {
+	return s.due, nil
+}
+
+func (s *stubCompanyImagesRepo) SaveImageMirror(ctx context.Context, images entity.CompanyImages, mirroredAt time.Time) error {
+	if s.saved == nil {
+		s.saved = make(map[uuid.UUID]entity.CompanyImages)
+	}
+	s.saved[images.CompanyID] = images
+	return nil
+}
+
+type stubImageStore map[string]string
+
+func (s stubImageStore) Upload(ctx context.Context, name, contentType string, r io.Reader) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s[name] = contentType + ":" + string(body)
+	return nil
+}
+
+func TestCompanyImageInputs(t *testing.T) {
+	for raw, want := range map[string]string{
+		" https://kopi.co.id/logo.png ": "https://kopi.co.id/logo.png",
+		"http://kopi.co.id/a logo.png":  "http://kopi.co.id/a%20logo.png",
+		"/logo.png":                     "",
+		"javascript:alert(1)":           "",
+	} {
+		got := companyLogoURL(&raw)
+		if (want == "" && got != nil) || (want != "" && (got == nil || *got != want)) {
+			t.Fatalf("companyLogoURL(%q) = %v, want %q", raw, got, want)
+		}
+	}
+	for raw, ok := range map[string]bool{
+		"places/ChIJN1t_tDeuEmsRUsoyG83frY4/photos/AXCi2Q6":  true,
+		"Aap_uEA7vb0DDYVJWEaX3O-AtYp77AaswQKSGtDaimt3gt7QCN": true,
+		"https://lh3.googleusercontent.com/p/photo":          false,
+		"places/../../admin?key=1":                           false,
+	} {
+		if got := companyPhotoReference(&raw); (got != nil) != ok {
+			t.Fatalf("companyPhotoReference(%q) = %v, want accepted %v", raw, got, ok)
+		}
+	}
+}
+
+func TestCompaniesService_SaveEnrichmentStoresImages(t *testing.T) {
+	images := &stubCompanyImagesRepo{}
+	var metadata map[string]any
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			metadata = enrichment.Metadata
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithCompanyImages(images))
+	companyID := uuid.New()
+	logo, reference := "https://kopi.co.id/logo.png", " places/ChIJ/photos/AXCi "
+
+	if err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{CompanyID: companyID.String(), LogoURL: &logo, PhotoReference: &reference}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored := images.stored[companyID]
+	if stored[0] == nil || *stored[0] != logo || stored[1] == nil || *stored[1] != "places/ChIJ/photos/AXCi" {
+		t.Fatalf("unexpected stored images %v", stored)
+	}
+	if metadata["logo_url"] != logo || metadata["photo_reference"] != "places/ChIJ/photos/AXCi" {
+		t.Fatalf("expected the images in the enrichment metadata, got %+v", metadata)
+	}
+}
+
+func TestCompanyImageMirrorService_MirrorDue(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nlogo")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(png)
+		case "/logo.svg":
+			w.Header().Set("Content-Type", "image/svg+xml")
+			w.Write([]byte("<svg onload=alert(1)></svg>"))
+		case "/photo":
+			if r.URL.Query().Get("key") != "places-key" || r.URL.Query().Get("photo_reference") != "Aap_ref" {
+				http.Error(w, "denied", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "image/jpeg; charset=binary")
+			w.Write([]byte("jpeg"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	mirrored, svgLogo, mirroredLogo := uuid.New(), uuid.New(), uuid.New()
+	ptr := func(s string) *string { return &s }
+	repo := &stubCompanyImagesRepo{due: []entity.CompanyImages{
+		{CompanyID: mirrored, LogoURL: ptr(server.URL + "/logo.png"), PhotoReference: ptr("Aap_ref")},
+		{CompanyID: svgLogo, LogoURL: ptr(server.URL + "/logo.svg")},
+		{CompanyID: mirroredLogo, LogoURL: ptr(server.URL + "/gone.png"), LogoMirrorURL: ptr("https://cdn.example.com/kept.png")},
+	}}
+	store := stubImageStore{}
+	svc := NewCompanyImageMirrorService(repo, store, "/images/", "https://cdn.example.com/",
+		WithImageMirrorPolicy(outbound.New(outbound.WithAllow("127.0.0.1")), time.Second),
+		WithPlacesPhotoKey("places-key"),
+	)
+	svc.placesPhotoURL = server.URL + "/photo"
+
+	processed, err := svc.MirrorDue(context.Background())
+	if err != nil || processed != 3 {
+		t.Fatalf("expected 3 companies processed, got %d, %v", processed, err)
+	}
+	logoName := "images/" + mirrored.String() + "/logo.png"
+	if got := repo.saved[mirrored]; got.LogoMirrorURL == nil || *got.LogoMirrorURL != "https://cdn.example.com/"+logoName ||
+		got.PhotoMirrorURL == nil || *got.PhotoMirrorURL != "https://cdn.example.com/images/"+mirrored.String()+"/photo.jpg" {
+		t.Fatalf("unexpected mirror %+v", got)
+	}
+	if !bytes.Equal([]byte(store[logoName]), append([]byte("image/png:"), png...)) {
+		t.Fatalf("unexpected stored logo %q", store[logoName])
+	}
+	if got := repo.saved[svgLogo]; got.LogoMirrorURL != nil {
+		t.Fatalf("expected an svg logo not to be mirrored, got %s", *got.LogoMirrorURL)
+	}
+	if got := repo.saved[mirroredLogo]; got.LogoMirrorURL == nil || *got.LogoMirrorURL != "https://cdn.example.com/kept.png" {
+		t.Fatalf("expected a mirrored logo to be kept, got %+v", got)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrQueryPlansUnavailable is returned when a plan is requested without a plans repository.
+var ErrQueryPlansUnavailable = errors.New("query plans unavailable")
+
+// WithQueryPlans lets administrators explain company listings.
+func WithQueryPlans(plans repository.CompanyPlansRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.plans = plans
+	}
+}
+
+// ExplainCompanies returns the plan of the listing ListCompanies would run for filter, bypassing
+// the query cache; with analyze the listing is run to measure it.
+func (s *CompaniesService) ExplainCompanies(ctx context.Context, filter dto.ListFilter, analyze bool) (*entity.QueryPlan, error) {
+	if s.plans == nil {
+		return nil, ErrQueryPlansUnavailable
+	}
+	return s.plans.ExplainList(ctx, s.withScoreWeight(s.withCategoryTaxonomy(normalizeListFilter(filter))), analyze)
+}
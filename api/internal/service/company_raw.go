@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+const (
+	// DefaultRawOffloadBatchSize is how many payloads an offload pass moves per page.
+	DefaultRawOffloadBatchSize = 200
+	// rawContentType is the media type of offloaded payloads.
+	rawContentType = "application/json"
+)
+
+var (
+	// ErrCompanyNotFound is returned when the requested company does not exist.
+	ErrCompanyNotFound = errors.New("company not found")
+	// ErrRawPayloadUnavailable is returned when raw payloads are requested without a raw repository.
+	ErrRawPayloadUnavailable = errors.New("raw payloads unavailable")
+	// ErrRawPayloadMissing is returned when an offloaded payload is gone from object storage.
+	ErrRawPayloadMissing = errors.New("raw payload missing from object storage")
+	// ErrRawStoreNotConfigured is returned when offloading runs without object storage.
+	ErrRawStoreNotConfigured = errors.New("raw payload store not configured")
+)
+
+// BlobStore keeps offloaded raw payloads; storage.GCSStore, storage.S3Store and
+// storage.LocalStore implement it.
+type BlobStore interface {
+	Upload(ctx context.Context, name, contentType string, r io.Reader) error
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// WithRawPayloads enables GET /companies/:id/raw lookups.
+func WithRawPayloads(raw repository.CompanyRawRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.raw = raw
+	}
+}
+
+// WithRawStore moves raw payloads to store under prefix, keyed by place id and scrape run, and
+// reads offloaded payloads back from it.
+func WithRawStore(store BlobStore, prefix string) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.rawStore = store
+		s.rawPrefix = prefix
+	}
+}
+
+// CompanyRaw returns the full raw Places payload stored for a company, fetching it from object
+// storage once offloaded.
+func (s *CompaniesService) CompanyRaw(ctx context.Context, companyID string) (json.RawMessage, error) {
+	if s.raw == nil {
+		return nil, ErrRawPayloadUnavailable
+	}
+	id, err := uuid.Parse(companyID)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	raw, err := s.raw.GetCompanyRaw(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	if raw.Ref == "" {
+		return raw.Payload, nil
+	}
+	if s.rawStore == nil {
+		return nil, ErrRawStoreNotConfigured
+	}
+	return readRawObject(ctx, s.rawStore, raw.Ref)
+}
+
+// readRawObject reads an offloaded payload back from store.
+func readRawObject(ctx context.Context, store BlobStore, ref string) (json.RawMessage, error) {
+	object, err := store.Open(ctx, ref)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrRawPayloadMissing
+		}
+		return nil, err
+	}
+	defer object.Close()
+	payload, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("read raw payload %s: %w", ref, err)
+	}
+	return json.RawMessage(payload), nil
+}
+
+// OffloadRawPayloads moves every inline raw payload of a scraped company to object storage and
+// returns how many were moved. Payloads rewritten while being moved stay inline for the next pass.
+func (s *CompaniesService) OffloadRawPayloads(ctx context.Context, batchSize int) (int, error) {
+	if s.raw == nil {
+		return 0, ErrRawPayloadUnavailable
+	}
+	if s.rawStore == nil {
+		return 0, ErrRawStoreNotConfigured
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultRawOffloadBatchSize
+	}
+
+	moved := 0
+	after := uuid.Nil
+	for {
+		payloads, err := s.raw.ListInlineRawPayloads(ctx, after, batchSize)
+		if err != nil {
+			return moved, err
+		}
+		for _, payload := range payloads {
+			after = payload.CompanyID
+			ref := s.rawKey(payload.PlaceID, payload.ScrapeRunID)
+			if err := s.rawStore.Upload(ctx, ref, rawContentType, bytes.NewReader(payload.Payload)); err != nil {
+				return moved, err
+			}
+			ok, err := s.raw.MarkRawOffloaded(ctx, payload.CompanyID, ref, payload.Payload)
+			if err != nil {
+				return moved, err
+			}
+			if ok {
+				moved++
+			}
+		}
+		if len(payloads) < batchSize {
+			return moved, nil
+		}
+	}
+}
+
+// RunRawOffload offloads raw payloads every interval until ctx is cancelled.
+func (s *CompaniesService) RunRawOffload(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.OffloadRawPayloads(ctx, batchSize); err != nil && ctx.Err() == nil {
+				log.Printf("failed to offload raw payloads: %v", err)
+			}
+		}
+	}
+}
+
+// rawKey names the object of a payload: <prefix>/<place_id>/<scrape_run_id>.json, with "manual"
+// standing in for payloads written outside a scrape run.
+func (s *CompaniesService) rawKey(placeID string, runID *uuid.UUID) string {
+	run := "manual"
+	if runID != nil {
+		run = runID.String()
+	}
+	return path.Join(s.rawPrefix, placeID, run+".json")
+}
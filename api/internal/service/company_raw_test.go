@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+// memoryRawRepo keeps raw payloads in memory, trimming offloaded ones like the SQL residue does.
+type memoryRawRepo struct {
+	payloads map[uuid.UUID]repository.InlineRawPayload
+	refs     map[uuid.UUID]string
+	order    []uuid.UUID
+}
+
+func (m *memoryRawRepo) GetCompanyRaw(ctx context.Context, companyID uuid.UUID) (repository.CompanyRaw, error) {
+	payload, ok := m.payloads[companyID]
+	if !ok {
+		return repository.CompanyRaw{}, repository.ErrCompanyNotFound
+	}
+	return repository.CompanyRaw{Payload: payload.Payload, Ref: m.refs[companyID]}, nil
+}
+
+func (m *memoryRawRepo) ListInlineRawPayloads(ctx context.Context, after uuid.UUID, limit int) ([]repository.InlineRawPayload, error) {
+	var page []repository.InlineRawPayload
+	for _, id := range m.order {
+		if id.String() <= after.String() || m.refs[id] != "" {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, m.payloads[id])
+	}
+	return page, nil
+}
+
+func (m *memoryRawRepo) MarkRawOffloaded(ctx context.Context, companyID uuid.UUID, ref string, payload json.RawMessage) (bool, error) {
+	current := m.payloads[companyID]
+	if string(current.Payload) != string(payload) {
+		return false, nil
+	}
+	current.Payload = json.RawMessage(`{}`)
+	m.payloads[companyID] = current
+	m.refs[companyID] = ref
+	return true, nil
+}
+
+func TestCompaniesService_OffloadRawPayloads(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	run := uuid.MustParse("99999999-9999-9999-9999-999999999999")
+	repo := &memoryRawRepo{payloads: map[uuid.UUID]repository.InlineRawPayload{}, refs: map[uuid.UUID]string{}}
+	for i, placeID := range []string{"place-a", "place-b", "place-c"} {
+		id := uuid.MustParse(fmt.Sprintf("%08d-0000-0000-0000-000000000000", i+1))
+		payload := repository.InlineRawPayload{CompanyID: id, PlaceID: placeID, Payload: json.RawMessage(`{"place_id":"` + placeID + `"}`)}
+		if i == 0 {
+			payload.ScrapeRunID = &run
+		}
+		repo.payloads[id] = payload
+		repo.order = append(repo.order, id)
+	}
+	svc := NewCompaniesService(nil, WithRawPayloads(repo), WithRawStore(store, "raw"))
+	ctx := context.Background()
+
+	moved, err := svc.OffloadRawPayloads(ctx, 2)
+	if err != nil || moved != 3 {
+		t.Fatalf("expected 3 payloads moved, got %d (%v)", moved, err)
+	}
+	first := repo.order[0]
+	if repo.refs[first] != "raw/place-a/"+run.String()+".json" || repo.refs[repo.order[1]] != "raw/place-b/manual.json" {
+		t.Fatalf("unexpected object keys: %v", repo.refs)
+	}
+
+	raw, err := svc.CompanyRaw(ctx, first.String())
+	if err != nil || string(raw) != `{"place_id":"place-a"}` {
+		t.Fatalf("expected the payload back from the store, got %s (%v)", raw, err)
+	}
+	if moved, err := svc.OffloadRawPayloads(ctx, 2); err != nil || moved != 0 {
+		t.Fatalf("expected nothing left to move, got %d (%v)", moved, err)
+	}
+
+	if err := store.Delete(ctx, repo.refs[first]); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.CompanyRaw(ctx, first.String()); !errors.Is(err, ErrRawPayloadMissing) {
+		t.Fatalf("expected ErrRawPayloadMissing, got %v", err)
+	}
+	if _, err := NewCompaniesService(nil, WithRawPayloads(repo)).OffloadRawPayloads(ctx, 0); !errors.Is(err, ErrRawStoreNotConfigured) {
+		t.Fatalf("expected ErrRawStoreNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+	"github.com/octobees/leads-generator/api/internal/service/sizing"
+)
+
+// WithSizeEstimation re-estimates the company size bucket whenever enrichment is stored.
+func WithSizeEstimation(sizes repository.CompanySizeRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.sizes = sizes
+	}
+}
+
+// WithActiveScoringProfile scores enrichments with the scoring profile an admin activated.
+func WithActiveScoringProfile(profiles repository.ScoringProfilesRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.scoringProfiles = profiles
+	}
+}
+
+// WithScoringOptions overrides the default lead scoring options.
+func WithScoringOptions(opts scoring.Options) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.scoring = opts
+	}
+}
+
+// RefreshCompanySize estimates the size bucket of a company from its current signals and stores it.
+func (s *CompaniesService) RefreshCompanySize(ctx context.Context, companyID uuid.UUID) (sizing.Estimate, error) {
+	if s.sizes == nil {
+		return sizing.Estimate{Bucket: sizing.BucketUnknown}, nil
+	}
+	return refreshSize(ctx, s.sizes, companyID)
+}
+
+// ScoreEnrichment computes the lead score of an enrichment with the active scoring profile,
+// including the company size factor when the profile weighs it.
+func (s *CompaniesService) ScoreEnrichment(ctx context.Context, enrichment *entity.CompanyEnrichment) scoring.ScoreResult {
+	profile, _, err := resolveScoringProfile(ctx, s.scoringProfiles, s.scoring)
+	if err != nil {
+		log.Printf("failed to load scoring profile, using built-in weights: %v", err)
+	}
+	features := scoring.FeaturesFromEnrichment(enrichment)
+	if enrichment != nil && profile.UsesSize() {
+		features.SizeBucket = lookupSizeBucket(ctx, s.sizes, enrichment.CompanyID)
+	}
+	return scoring.ComputeScoreWithProfile(features, profile)
+}
+
+func (s *CompaniesService) refreshSizeAfterEnrichment(ctx context.Context, companyID uuid.UUID) {
+	if s.sizes == nil {
+		return
+	}
+	if _, err := refreshSize(ctx, s.sizes, companyID); err != nil {
+		log.Printf("failed to estimate size for company %s: %v", companyID, err)
+	}
+}
+
+func refreshSize(ctx context.Context, sizes repository.CompanySizeRepository, companyID uuid.UUID) (sizing.Estimate, error) {
+	signals, err := sizes.GetSizeSignals(ctx, companyID)
+	if err != nil {
+		return sizing.Estimate{}, err
+	}
+	estimate := sizing.EstimateSize(sizeSignals(signals))
+	if err := sizes.UpdateSize(ctx, companyID, estimate.Bucket); err != nil {
+		return sizing.Estimate{}, err
+	}
+	return estimate, nil
+}
+
+func sizeSignals(signals *entity.CompanySizeSignals) sizing.Signals {
+	return sizing.Signals{
+		Reviews:          signals.Reviews,
+		Photos:           signals.Photos,
+		SocialFollowers:  signals.SocialFollowers,
+		EmployeeMentions: signals.EmployeeMentions,
+	}
+}
+
+// lookupSizeBucket returns the stored bucket of a company, or an empty string when unavailable.
+func lookupSizeBucket(ctx context.Context, sizes repository.CompanySizeRepository, companyID uuid.UUID) string {
+	if sizes == nil {
+		return ""
+	}
+	signals, err := sizes.GetSizeSignals(ctx, companyID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrCompanyNotFound) {
+			log.Printf("failed to load size bucket for company %s: %v", companyID, err)
+		}
+		return ""
+	}
+	if signals.SizeBucket == nil {
+		return ""
+	}
+	return *signals.SizeBucket
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+	"github.com/octobees/leads-generator/api/internal/service/sizing"
+)
+
+type mockCompanySizeRepository struct {
+	signals map[uuid.UUID]entity.CompanySizeSignals
+	updated map[uuid.UUID]string
+	err     error
+}
+
+func (m *mockCompanySizeRepository) GetSizeSignals(ctx context.Context, companyID uuid.UUID) (*entity.CompanySizeSignals, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	signals, ok := m.signals[companyID]
+	if !ok {
+		return nil, repository.ErrCompanyNotFound
+	}
+	return &signals, nil
+}
+
+func (m *mockCompanySizeRepository) ListSizeSignalsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanySizeSignals, error) {
+	var page []entity.CompanySizeSignals
+	for _, id := range []string{
+		"11111111-1111-1111-1111-111111111111",
+		"22222222-2222-2222-2222-222222222222",
+		"33333333-3333-3333-3333-333333333333",
+	} {
+		companyID := uuid.MustParse(id)
+		record, ok := m.signals[companyID]
+		if ok && id > after.String() && len(page) < limit {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockCompanySizeRepository) UpdateSize(ctx context.Context, companyID uuid.UUID, bucket string) error {
+	if m.updated == nil {
+		m.updated = make(map[uuid.UUID]string)
+	}
+	m.updated[companyID] = bucket
+	return nil
+}
+
+func TestCompaniesService_SaveEnrichment_RefreshesSize(t *testing.T) {
+	companyID := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	var saved *entity.CompanyEnrichment
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			saved = enrichment
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	reviews := 120
+	sizes := &mockCompanySizeRepository{signals: map[uuid.UUID]entity.CompanySizeSignals{
+		companyID: {CompanyID: companyID, Reviews: &reviews},
+	}}
+	svc := NewCompaniesService(repo, WithSizeEstimation(sizes))
+
+	employees := 40
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID:        companyID.String(),
+		EmployeeMentions: &employees,
+		SocialFollowers:  map[string]int{"instagram": 800, "facebook": 400},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Metadata["employee_mentions"] != 40 || saved.Metadata["social_followers"] != 1200 {
+		t.Fatalf("expected size signals in metadata, got %+v", saved.Metadata)
+	}
+	if sizes.updated[companyID] != sizing.BucketSmall {
+		t.Fatalf("expected size refreshed to small, got %+v", sizes.updated)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_IgnoresSizeErrors(t *testing.T) {
+	repo := &mockCompaniesRepository{
+		enrich:         func(ctx context.Context, enrichment *entity.CompanyEnrichment) error { return nil },
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithSizeEstimation(&mockCompanySizeRepository{err: errors.New("down")}))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"})
+	if err != nil {
+		t.Fatalf("expected size failure to be ignored, got %v", err)
+	}
+}
+
+func TestCompaniesService_ScoreEnrichment_UsesSizeBucket(t *testing.T) {
+	companyID := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	bucket := sizing.BucketSmall
+	sizes := &mockCompanySizeRepository{signals: map[uuid.UUID]entity.CompanySizeSignals{
+		companyID: {CompanyID: companyID, SizeBucket: &bucket},
+	}}
+	enrichment := &entity.CompanyEnrichment{CompanyID: companyID, Emails: []string{"info@example.com"}}
+
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithSizeEstimation(sizes), WithScoringOptions(scoring.Options{SizeWeight: 20}))
+	score := svc.ScoreEnrichment(context.Background(), enrichment)
+	if score.Breakdown["business_size"] != 20 || score.Total != 30 {
+		t.Fatalf("expected size factor applied, got %+v", score)
+	}
+
+	svc = NewCompaniesService(&mockCompaniesRepository{}, WithSizeEstimation(sizes), WithScoringOptions(scoring.Options{}))
+	score = svc.ScoreEnrichment(context.Background(), enrichment)
+	if _, ok := score.Breakdown["business_size"]; ok {
+		t.Fatalf("expected size factor disabled, got %+v", score)
+	}
+}
+
+func TestMaintenanceService_RecomputeSizes(t *testing.T) {
+	reviews, photos := 900, 300
+	sizes := &mockCompanySizeRepository{signals: map[uuid.UUID]entity.CompanySizeSignals{
+		uuid.MustParse("11111111-1111-1111-1111-111111111111"): {CompanyID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Reviews: &reviews, Photos: &photos},
+		uuid.MustParse("22222222-2222-2222-2222-222222222222"): {CompanyID: uuid.MustParse("22222222-2222-2222-2222-222222222222")},
+		uuid.MustParse("33333333-3333-3333-3333-333333333333"): {CompanyID: uuid.MustParse("33333333-3333-3333-3333-333333333333"), Photos: &photos},
+	}}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).RecomputeSizes(context.Background(), 2); !errors.Is(err, ErrSizingUnavailable) {
+		t.Fatalf("expected ErrSizingUnavailable, got %v", err)
+	}
+
+	svc := NewMaintenanceService(&mockMaintenanceRepository{}, WithSizing(sizes, scoring.DefaultOptions()))
+	counts, err := svc.RecomputeSizes(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sizes.updated) != 3 || counts[sizing.BucketLarge] != 2 || counts[sizing.BucketUnknown] != 1 {
+		t.Fatalf("unexpected recompute result: %v (%v)", counts, sizes.updated)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/querycache"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// DefaultSuggestLimit is the number of values GET /companies/suggest returns by default.
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit caps the limit of GET /companies/suggest.
+	MaxSuggestLimit = 50
+)
+
+var (
+	// ErrSuggestionsUnavailable is returned when suggestions are requested without a repository.
+	ErrSuggestionsUnavailable = errors.New("company suggestions unavailable")
+	// ErrInvalidSuggestField is returned for fields other than city, type_business or company.
+	ErrInvalidSuggestField = errors.New("invalid field (use city, type_business or company)")
+	// ErrSuggestQueryRequired is returned when no prefix is given.
+	ErrSuggestQueryRequired = errors.New("q is required")
+)
+
+// suggestFields lists the company fields offered for typeahead filter inputs.
+var suggestFields = []string{"city", "type_business", "company"}
+
+// WithSuggestions enables GET /companies/suggest.
+func WithSuggestions(suggest repository.CompanySuggestRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.suggest = suggest
+	}
+}
+
+// SuggestCompanyValues returns the distinct values of field starting with query, ignoring case,
+// the most common first. Typeahead inputs repeat the same prefixes, so results go through the
+// query cache.
+func (s *CompaniesService) SuggestCompanyValues(ctx context.Context, field, query string, limit int) ([]entity.StatBucket, error) {
+	if s.suggest == nil {
+		return nil, ErrSuggestionsUnavailable
+	}
+	field = strings.ToLower(strings.TrimSpace(field))
+	if !slices.Contains(suggestFields, field) {
+		return nil, ErrInvalidSuggestField
+	}
+	prefix := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if prefix == "" {
+		return nil, ErrSuggestQueryRequired
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+
+	load := func(ctx context.Context) ([]entity.StatBucket, error) {
+		return s.suggest.Suggest(ctx, field, prefix, limit)
+	}
+	if s.queryCache == nil {
+		return load(ctx)
+	}
+	key := querycache.Key("suggest", struct {
+		Field  string `json:"field"`
+		Prefix string `json:"prefix"`
+		Limit  int    `json:"limit"`
+	}{field, prefix, limit})
+	return querycache.Fetch(ctx, s.queryCache, key, load)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/querycache"
+)
+
+type mockCompanySuggestRepository struct {
+	field, prefix string
+	limit, calls  int
+}
+
+func (m *mockCompanySuggestRepository) Suggest(ctx context.Context, field, prefix string, limit int) ([]entity.StatBucket, error) {
+	m.field, m.prefix, m.limit = field, prefix, limit
+	m.calls++
+	return []entity.StatBucket{{Name: "Bandung", Companies: 12}}, nil
+}
+
+func TestCompaniesService_SuggestCompanyValues(t *testing.T) {
+	repo := &mockCompanySuggestRepository{}
+	cache := querycache.New(querycache.NewMemoryStore(10), querycache.StoreMemory, time.Hour)
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithSuggestions(repo), WithQueryCache(cache))
+
+	suggestions, err := svc.SuggestCompanyValues(context.Background(), " City ", "  BAN  dung ", 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Companies != 12 {
+		t.Fatalf("unexpected suggestions: %+v", suggestions)
+	}
+	if repo.field != "city" || repo.prefix != "ban dung" || repo.limit != MaxSuggestLimit {
+		t.Fatalf("unexpected repository call: %+v", repo)
+	}
+	if _, err := svc.SuggestCompanyValues(context.Background(), "city", "ban dung", 500); err != nil || repo.calls != 1 {
+		t.Fatalf("expected a repeated prefix served from cache, got %d calls, %v", repo.calls, err)
+	}
+	if _, err := svc.SuggestCompanyValues(context.Background(), "company", "kopi", 0); err != nil || repo.limit != DefaultSuggestLimit {
+		t.Fatalf("expected the default limit, got %d, %v", repo.limit, err)
+	}
+
+	if _, err := svc.SuggestCompanyValues(context.Background(), "phone", "08", 0); !errors.Is(err, ErrInvalidSuggestField) {
+		t.Fatalf("expected ErrInvalidSuggestField, got %v", err)
+	}
+	if _, err := svc.SuggestCompanyValues(context.Background(), "city", "  ", 0); !errors.Is(err, ErrSuggestQueryRequired) {
+		t.Fatalf("expected ErrSuggestQueryRequired, got %v", err)
+	}
+	if _, err := NewCompaniesService(&mockCompaniesRepository{}).SuggestCompanyValues(context.Background(), "city", "ban", 0); !errors.Is(err, ErrSuggestionsUnavailable) {
+		t.Fatalf("expected ErrSuggestionsUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// DefaultContactCollisionMinCompanies is the number of unrelated companies that must share an email
+// or phone number before it counts as a collision. Two shops of one owner often share a number.
+const DefaultContactCollisionMinCompanies = 3
+
+var (
+	// ErrInvalidContactKind is returned when collisions are filtered on an unknown contact kind.
+	ErrInvalidContactKind = errors.New("invalid kind (use email or phone)")
+	// ErrInvalidCollisionThreshold is returned when fewer than two companies would make a collision.
+	ErrInvalidCollisionThreshold = errors.New("min_companies must be at least 2")
+)
+
+// ContactCollisionService finds emails and phone numbers shared across unrelated companies, which
+// usually belong to an agency or a directory, or are data errors.
+type ContactCollisionService struct {
+	repo repository.ContactCollisionsRepository
+}
+
+// NewContactCollisionService builds a new ContactCollisionService instance.
+func NewContactCollisionService(repo repository.ContactCollisionsRepository) *ContactCollisionService {
+	return &ContactCollisionService{repo: repo}
+}
+
+// DetectCollisions recomputes the collisions and the shared_contact flag of every company;
+// minCompanies <= 0 uses DefaultContactCollisionMinCompanies.
+func (s *ContactCollisionService) DetectCollisions(ctx context.Context, minCompanies int) (repository.ContactCollisionDetectionResult, error) {
+	if minCompanies <= 0 {
+		minCompanies = DefaultContactCollisionMinCompanies
+	}
+	if minCompanies < 2 {
+		return repository.ContactCollisionDetectionResult{}, ErrInvalidCollisionThreshold
+	}
+	return s.repo.DetectContactCollisions(ctx, minCompanies)
+}
+
+// ListCollisions returns the collisions found by the last detection, most shared first, optionally
+// narrowed to emails or phones, applying the usual pagination defaults.
+func (s *ContactCollisionService) ListCollisions(ctx context.Context, kind string, page, perPage int) ([]entity.ContactCollision, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "", entity.ContactKindEmail, entity.ContactKindPhone:
+	default:
+		return nil, ErrInvalidContactKind
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return s.repo.ListContactCollisions(ctx, kind, perPage, (page-1)*perPage)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubContactCollisionsRepo struct {
+	minOwners     int
+	kind          string
+	limit, offset int
+}
+
+func (s *stubContactCollisionsRepo) DetectContactCollisions(ctx context.Context, minOwners int) (repository.ContactCollisionDetectionResult, error) {
+	s.minOwners = minOwners
+	return repository.ContactCollisionDetectionResult{}, nil
+}
+
+func (s *stubContactCollisionsRepo) ListContactCollisions(ctx context.Context, kind string, limit, offset int) ([]entity.ContactCollision, error) {
+	s.kind, s.limit, s.offset = kind, limit, offset
+	return nil, nil
+}
+
+func TestContactCollisionService_DetectCollisions(t *testing.T) {
+	repo := &stubContactCollisionsRepo{}
+	svc := NewContactCollisionService(repo)
+
+	if _, err := svc.DetectCollisions(context.Background(), 0); err != nil || repo.minOwners != DefaultContactCollisionMinCompanies {
+		t.Fatalf("expected the default threshold, got %d (%v)", repo.minOwners, err)
+	}
+	if _, err := svc.DetectCollisions(context.Background(), 15); err != nil || repo.minOwners != 15 {
+		t.Fatalf("expected threshold forwarded, got %d (%v)", repo.minOwners, err)
+	}
+	if _, err := svc.DetectCollisions(context.Background(), 1); !errors.Is(err, ErrInvalidCollisionThreshold) {
+		t.Fatalf("expected ErrInvalidCollisionThreshold, got %v", err)
+	}
+}
+
+func TestContactCollisionService_ListCollisions(t *testing.T) {
+	repo := &stubContactCollisionsRepo{}
+	svc := NewContactCollisionService(repo)
+
+	if _, err := svc.ListCollisions(context.Background(), " EMAIL ", 3, 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.kind != entity.ContactKindEmail || repo.limit != 100 || repo.offset != 200 {
+		t.Fatalf("unexpected query %+v", repo)
+	}
+	if _, err := svc.ListCollisions(context.Background(), "", 0, 0); err != nil || repo.kind != "" || repo.limit != 20 || repo.offset != 0 {
+		t.Fatalf("expected defaults, got %+v (%v)", repo, err)
+	}
+	if _, err := svc.ListCollisions(context.Background(), "fax", 1, 20); !errors.Is(err, ErrInvalidContactKind) {
+		t.Fatalf("expected ErrInvalidContactKind, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockEnrichmentContactsRepository struct {
+	records  map[string][]repository.StoredContacts
+	replaced map[string]repository.StoredContacts
+	changed  map[string]bool
+}
+
+func (m *mockEnrichmentContactsRepository) ListStoredContactsAfter(ctx context.Context, table repository.ContactTable, after string, limit int) ([]repository.StoredContacts, error) {
+	var page []repository.StoredContacts
+	for _, record := range m.records[table.Name] {
+		if record.Key > after && len(page) < limit {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockEnrichmentContactsRepository) ReplaceStoredContacts(ctx context.Context, table repository.ContactTable, stored, replacement repository.StoredContacts) (bool, error) {
+	if m.changed[stored.Key] {
+		return false, nil
+	}
+	m.replaced[table.Name+"/"+stored.Key] = replacement
+	return true, nil
+}
+
+func TestMaintenanceService_EncryptEnrichmentContacts(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, fieldcrypt.KeySize), bytes.Repeat([]byte{2}, fieldcrypt.KeySize)
+	old, _ := fieldcrypt.New(oldKey)
+	cipher, _ := fieldcrypt.New(newKey, oldKey)
+	oldEmail, _ := old.Encrypt("old@example.com")
+	currentEmail, _ := cipher.Encrypt("current@example.com")
+
+	ids := []string{
+		"00000000-0000-0000-0000-000000000001",
+		"00000000-0000-0000-0000-000000000002",
+		"00000000-0000-0000-0000-000000000003",
+		"00000000-0000-0000-0000-000000000004",
+	}
+	enrichments := repository.EnrichmentContactTable.Name
+	repo := &mockEnrichmentContactsRepository{
+		records: map[string][]repository.StoredContacts{
+			enrichments: {
+				{Key: ids[0], Emails: []string{"plain@example.com"}, Phones: []string{"+62215550100"}},
+				{Key: ids[1], Emails: []string{oldEmail}},
+				{Key: ids[2], Emails: []string{currentEmail}},
+				{Key: ids[3], Phones: []string{"+62215550199"}},
+			},
+			repository.DomainContactTable.Name: {
+				{Key: "example.com", Emails: []string{"cache@example.com"}},
+			},
+		},
+		replaced: map[string]repository.StoredContacts{},
+		changed:  map[string]bool{ids[3]: true},
+	}
+	svc := NewMaintenanceService(&mockMaintenanceRepository{}, WithContactEncryption(repo, cipher))
+
+	result, err := svc.EncryptEnrichmentContacts(context.Background(), repository.EnrichmentContactTable, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (ContactEncryptionResult{Scanned: 4, Encrypted: 2, Changed: 1}) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if _, touched := repo.replaced[enrichments+"/"+ids[2]]; touched {
+		t.Fatal("expected current rows to be left alone")
+	}
+	for id, want := range map[string]string{ids[0]: "plain@example.com", ids[1]: "old@example.com"} {
+		email := repo.replaced[enrichments+"/"+id].Emails[0]
+		if !cipher.Current(email) {
+			t.Fatalf("expected %s to be encrypted with the active key, got %q", id, email)
+		}
+		if plain, _ := cipher.Decrypt(email); plain != want {
+			t.Fatalf("expected %q, got %q", want, plain)
+		}
+	}
+
+	result, err = svc.EncryptEnrichmentContacts(context.Background(), repository.DomainContactTable, 2)
+	if err != nil || result != (ContactEncryptionResult{Scanned: 1, Encrypted: 1}) {
+		t.Fatalf("unexpected domain cache result %+v (%v)", result, err)
+	}
+	if email := repo.replaced[repository.DomainContactTable.Name+"/example.com"].Emails[0]; !cipher.Current(email) {
+		t.Fatalf("expected the domain cache to be encrypted, got %q", email)
+	}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).EncryptEnrichmentContacts(context.Background(), repository.EnrichmentContactTable, 2); !errors.Is(err, ErrContactEncryptionUnavailable) {
+		t.Fatalf("expected ErrContactEncryptionUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrContactNormalizationUnavailable is returned when contact normalization runs without a
+// repository.
+var ErrContactNormalizationUnavailable = errors.New("contact normalization not configured")
+
+// ContactNormalization counts the companies whose stored contact fields changed when normalized
+// again, and how many of their phones and websites did.
+type ContactNormalization struct {
+	Companies int `json:"companies"`
+	Phones    int `json:"phones"`
+	Websites  int `json:"websites"`
+}
+
+// WithContactNormalization enables normalization of the stored company phones and websites.
+func WithContactNormalization(contacts repository.ContactNormalizationRepository) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.contactFields = contacts
+	}
+}
+
+// applyContactNormalization rewrites the phone and website of a scraped or imported company in
+// their canonical form.
+func applyContactNormalization(company *entity.Company) {
+	company.Phone = normalizedCompanyPhone(company.Phone, company.Country)
+	company.Website = normalizedCompanyWebsite(company.Website)
+}
+
+// normalizedCompanyPhone returns the phone in E.164, parsed in the region of the company's
+// country. A number that does not parse is kept as trimmed, so no scraped contact is lost.
+func normalizedCompanyPhone(phone, country *string) *string {
+	raw := strings.TrimSpace(derefString(phone))
+	if raw == "" {
+		return nil
+	}
+	region, _ := phoneRegionForCountry(derefString(country))
+	if e164 := normalizePhone(raw, region); e164 != "" {
+		return &e164
+	}
+	return &raw
+}
+
+// normalizedCompanyWebsite returns the website as an https URL with a lowercase host and without
+// default port, fragment or tracking parameters. A value that is not a URL is kept as trimmed.
+func normalizedCompanyWebsite(website *string) *string {
+	raw := strings.TrimSpace(derefString(website))
+	if raw == "" {
+		return nil
+	}
+	u, err := sanitizeURL(raw)
+	if err != nil {
+		return &raw
+	}
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == "" || port == "443" || port == "80" {
+		u.Host = host
+	} else {
+		u.Host = host + ":" + port
+	}
+	u.Fragment, u.RawFragment = "", ""
+	stripTracking(u)
+	if u.Path == "/" && u.RawQuery == "" {
+		u.Path, u.RawPath = "", ""
+	}
+	normalized := u.String()
+	return &normalized
+}
+
+// NormalizeContacts normalizes the phone and website of every company again, paging by company
+// id, and stores those that changed.
+func (s *MaintenanceService) NormalizeContacts(ctx context.Context, batchSize int) (ContactNormalization, error) {
+	var result ContactNormalization
+	if s.contactFields == nil {
+		return result, ErrContactNormalizationUnavailable
+	}
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+
+	var after uuid.UUID
+	for {
+		batch, err := s.contactFields.ListCompanyContactFieldsAfter(ctx, after, batchSize)
+		if err != nil {
+			return result, err
+		}
+		for _, fields := range batch {
+			phone := normalizedCompanyPhone(fields.Phone, fields.Country)
+			website := normalizedCompanyWebsite(fields.Website)
+			phoneChanged := derefString(phone) != derefString(fields.Phone)
+			websiteChanged := derefString(website) != derefString(fields.Website)
+			if !phoneChanged && !websiteChanged {
+				continue
+			}
+			if err := s.contactFields.UpdateCompanyContactFields(ctx, fields.CompanyID, phone, website); err != nil {
+				if errors.Is(err, repository.ErrCompanyNotFound) {
+					continue
+				}
+				return result, err
+			}
+			result.Companies++
+			if phoneChanged {
+				result.Phones++
+			}
+			if websiteChanged {
+				result.Websites++
+			}
+		}
+		if len(batch) < batchSize {
+			return result, nil
+		}
+		after = batch[len(batch)-1].CompanyID
+	}
+}
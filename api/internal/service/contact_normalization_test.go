@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type contactFieldsUpdate struct {
+	phone, website *string
+}
+
+type mockContactNormalizationRepository struct {
+	fields  []entity.CompanyContactFields
+	updated map[uuid.UUID]contactFieldsUpdate
+}
+
+func (m *mockContactNormalizationRepository) ListCompanyContactFieldsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyContactFields, error) {
+	var page []entity.CompanyContactFields
+	for _, f := range m.fields {
+		if strings.Compare(f.CompanyID.String(), after.String()) > 0 && len(page) < limit {
+			page = append(page, f)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockContactNormalizationRepository) UpdateCompanyContactFields(ctx context.Context, companyID uuid.UUID, phone, website *string) error {
+	if m.updated == nil {
+		m.updated = make(map[uuid.UUID]contactFieldsUpdate)
+	}
+	m.updated[companyID] = contactFieldsUpdate{phone: phone, website: website}
+	return nil
+}
+
+func TestNormalizedCompanyPhone(t *testing.T) {
+	cases := []struct {
+		phone, country string
+		want           string
+	}{
+		{"0812-3456-7890", "Indonesia", "+6281234567890"},
+		{" (021) 555-1234 ", "", "+62215551234"},
+		{"(415) 555-2671", "United States", "+14155552671"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"+65 6123 4567", "Indonesia", "+6561234567"},
+		{" call us ", "Indonesia", "call us"},
+		{"   ", "Indonesia", ""},
+	}
+	for _, tc := range cases {
+		got := derefString(normalizedCompanyPhone(&tc.phone, &tc.country))
+		if got != tc.want {
+			t.Fatalf("normalizedCompanyPhone(%q, %q) = %q, want %q", tc.phone, tc.country, got, tc.want)
+		}
+	}
+	if normalizedCompanyPhone(nil, nil) != nil {
+		t.Fatalf("expected a missing phone to stay missing")
+	}
+}
+
+func TestNormalizedCompanyWebsite(t *testing.T) {
+	cases := []struct {
+		website, want string
+	}{
+		{"WWW.Kopi.CO.ID", "https://www.kopi.co.id"},
+		{"http://Example.com/", "https://example.com"},
+		{"https://example.com:443/Menu?utm_source=maps&utm_medium=gbp", "https://example.com/Menu"},
+		{"https://example.com/?gclid=abc&fbclid=def#about", "https://example.com"},
+		{"https://shop.example.com:8443/p?id=7&utm_campaign=x", "https://shop.example.com:8443/p?id=7"},
+		{"not a url", "not a url"},
+		{"  ", ""},
+	}
+	for _, tc := range cases {
+		got := derefString(normalizedCompanyWebsite(&tc.website))
+		if got != tc.want {
+			t.Fatalf("normalizedCompanyWebsite(%q) = %q, want %q", tc.website, got, tc.want)
+		}
+	}
+}
+
+func TestCompaniesService_UpsertCompany_NormalizesContacts(t *testing.T) {
+	var saved *entity.Company
+	repo := &mockCompaniesRepository{upsert: func(ctx context.Context, company *entity.Company) error {
+		saved = company
+		return nil
+	}}
+	phone, website, country := "(415) 555-2671", "HTTP://Bakery.Example.com/?utm_source=maps", "USA"
+
+	company := &entity.Company{Company: "Bay Bakery", Phone: &phone, Website: &website, Country: &country}
+	if err := NewCompaniesService(repo).UpsertCompany(context.Background(), company); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if derefString(saved.Phone) != "+14155552671" || derefString(saved.Website) != "https://bakery.example.com" {
+		t.Fatalf("unexpected contact fields %q, %q", derefString(saved.Phone), derefString(saved.Website))
+	}
+}
+
+func TestCompaniesService_ImportCompaniesCSV_NormalizesContacts(t *testing.T) {
+	var received []repository.BulkUpsertCompanyInput
+	repo := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	}
+	csv := strings.Join(requiredCSVHeaders, ",") + "\nKopi Kenangan,Jl. Sudirman 1,0812-3456-7890,Kopi.co.id/?fbclid=x,,,,Jakarta,Malaysia\n"
+
+	if _, err := NewCompaniesService(repo).ImportCompaniesCSV(context.Background(), strings.NewReader(csv), repository.ImportModeOverwrite, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("unexpected records %+v", received)
+	}
+	// The number is read in the region of the row's country, where it is not valid, so it is kept.
+	if rec := received[0]; derefString(rec.Phone) != "0812-3456-7890" || derefString(rec.Website) != "https://kopi.co.id" {
+		t.Fatalf("unexpected contact fields %q, %q", derefString(rec.Phone), derefString(rec.Website))
+	}
+}
+
+func TestMaintenanceService_NormalizeContacts(t *testing.T) {
+	id := func(n int) uuid.UUID {
+		return uuid.MustParse(strings.Repeat(string(rune('0'+n)), 8) + "-1111-1111-1111-111111111111")
+	}
+	str := func(s string) *string { return &s }
+	contacts := &mockContactNormalizationRepository{fields: []entity.CompanyContactFields{
+		{CompanyID: id(1), Phone: str("0812 3456 7890"), Website: str("https://kopi.co.id"), Country: str("Indonesia")},
+		{CompanyID: id(2), Phone: str("+6281234567890"), Website: str("https://kopi.co.id")},
+		{CompanyID: id(3), Website: str("Example.com/?utm_source=maps")},
+		{CompanyID: id(4), Phone: str("(415) 555-2671"), Website: str("http://Bay.example"), Country: str("US")},
+	}}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).NormalizeContacts(context.Background(), 2); !errors.Is(err, ErrContactNormalizationUnavailable) {
+		t.Fatalf("expected ErrContactNormalizationUnavailable, got %v", err)
+	}
+
+	result, err := NewMaintenanceService(&mockMaintenanceRepository{}, WithContactNormalization(contacts)).NormalizeContacts(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (ContactNormalization{Companies: 3, Phones: 2, Websites: 2}) || len(contacts.updated) != 3 {
+		t.Fatalf("unexpected result %+v (%v)", result, contacts.updated)
+	}
+	if _, ok := contacts.updated[id(2)]; ok {
+		t.Fatalf("expected an already normalized company to be left alone")
+	}
+	if got := contacts.updated[id(3)]; got.phone != nil || derefString(got.website) != "https://example.com" {
+		t.Fatalf("unexpected update %q, %q", derefString(got.phone), derefString(got.website))
+	}
+	if got := contacts.updated[id(4)]; derefString(got.phone) != "+14155552671" || derefString(got.website) != "https://bay.example" {
+		t.Fatalf("unexpected update %q, %q", derefString(got.phone), derefString(got.website))
+	}
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrInvalidSuppression is returned when a do-not-contact entry fails validation.
+	ErrInvalidSuppression = errors.New("invalid contact suppression")
+	// ErrInvalidSuppressionID is returned when a do-not-contact entry id is not a UUID.
+	ErrInvalidSuppressionID = errors.New("invalid contact suppression id")
+	// ErrSuppressionNotFound indicates the requested do-not-contact entry does not exist.
+	ErrSuppressionNotFound = errors.New("contact suppression not found")
+	// ErrSuppressionExists is returned when a contact is already on the do-not-contact list.
+	ErrSuppressionExists = errors.New("contact already suppressed")
+)
+
+// suppressionKinds lists the kinds of contact the do-not-contact list holds.
+var suppressionKinds = []string{entity.SuppressionKindEmail, entity.SuppressionKindDomain, entity.SuppressionKindPhone}
+
+// maxSuppressionNote bounds the note of a do-not-contact entry, in characters.
+const maxSuppressionNote = 500
+
+// ContactSuppressionService manages the do-not-contact list and checks companies against it.
+type ContactSuppressionService struct {
+	repo     repository.ContactSuppressionsRepository
+	contacts repository.CompanyContactsRepository
+}
+
+// NewContactSuppressionService builds a ContactSuppressionService reading company contacts from
+// contacts.
+func NewContactSuppressionService(repo repository.ContactSuppressionsRepository, contacts repository.CompanyContactsRepository) *ContactSuppressionService {
+	return &ContactSuppressionService{repo: repo, contacts: contacts}
+}
+
+// List returns a page of the list, newest first, only entries of kind when set and whose value
+// contains query when set.
+func (s *ContactSuppressionService) List(ctx context.Context, kind, query string, page, perPage int) ([]entity.ContactSuppression, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind != "" && !slices.Contains(suppressionKinds, kind) {
+		return nil, fmt.Errorf("%w: kind must be %s", ErrInvalidSuppression, strings.Join(suppressionKinds, ", "))
+	}
+	limit, offset := pageBounds(page, perPage)
+	return s.repo.ListSuppressions(ctx, kind, strings.ToLower(strings.TrimSpace(query)), limit, offset)
+}
+
+// Create puts a contact on the list by hand.
+func (s *ContactSuppressionService) Create(ctx context.Context, req dto.SuppressionRequest, createdBy string) (*entity.ContactSuppression, error) {
+	suppression, err := buildContactSuppression(req)
+	if err != nil {
+		return nil, err
+	}
+	suppression.Reason = entity.SuppressionManual
+	if id, err := uuid.Parse(createdBy); err == nil {
+		suppression.CreatedBy = &id
+	}
+	if err := s.repo.CreateSuppression(ctx, suppression); err != nil {
+		return nil, mapSuppressionError(err)
+	}
+	return suppression, nil
+}
+
+// Update rewrites the contact and note of an entry; its reason and origin are kept.
+func (s *ContactSuppressionService) Update(ctx context.Context, idRaw string, req dto.SuppressionRequest) (*entity.ContactSuppression, error) {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return nil, ErrInvalidSuppressionID
+	}
+	suppression, err := buildContactSuppression(req)
+	if err != nil {
+		return nil, err
+	}
+	suppression.ID = id
+	if err := s.repo.UpdateSuppression(ctx, suppression); err != nil {
+		return nil, mapSuppressionError(err)
+	}
+	return suppression, nil
+}
+
+// Delete takes an entry off the list, letting the contact be reached again.
+func (s *ContactSuppressionService) Delete(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return ErrInvalidSuppressionID
+	}
+	if err := s.repo.DeleteSuppression(ctx, id); err != nil {
+		return mapSuppressionError(err)
+	}
+	return nil
+}
+
+func buildContactSuppression(req dto.SuppressionRequest) (*entity.ContactSuppression, error) {
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	if !slices.Contains(suppressionKinds, kind) {
+		return nil, fmt.Errorf("%w: kind must be %s", ErrInvalidSuppression, strings.Join(suppressionKinds, ", "))
+	}
+	value, ok := normalizeSuppressionValue(kind, req.Value)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a valid %s", ErrInvalidSuppression, strings.TrimSpace(req.Value), kind)
+	}
+	suppression := &entity.ContactSuppression{Kind: kind, Value: value}
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		if utf8.RuneCountInString(note) > maxSuppressionNote {
+			return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidSuppression, maxSuppressionNote)
+		}
+		if note != "" {
+			suppression.Note = &note
+		}
+	}
+	return suppression, nil
+}
+
+// normalizeSuppressionValue normalises a contact of kind the way listed contacts are compared:
+// lowercase emails, hosts without "www." (a URL or an email address gives its domain) and E.164
+// phone numbers, local numbers being read as Indonesian.
+func normalizeSuppressionValue(kind, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	switch kind {
+	case entity.SuppressionKindEmail:
+		email := strings.ToLower(raw)
+		return email, emailPattern.MatchString(email)
+	case entity.SuppressionKindDomain:
+		if at := strings.LastIndex(raw, "@"); at >= 0 && !strings.Contains(raw, "://") {
+			raw = raw[at+1:]
+		}
+		domain, ok := canonicalDomain(raw)
+		return domain, ok && isDomainValid(domain)
+	case entity.SuppressionKindPhone:
+		phone := normalizePhone(raw, defaultPhoneRegion)
+		return phone, phone != ""
+	}
+	return "", false
+}
+
+func mapSuppressionError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrSuppressionNotFound):
+		return ErrSuppressionNotFound
+	case errors.Is(err, repository.ErrSuppressionDuplicate):
+		return ErrSuppressionExists
+	default:
+		return err
+	}
+}
+
+// suppressionKey identifies a normalised contact of a kind.
+type suppressionKey struct{ kind, value string }
+
+// SuppressedCompanies returns the ids, among ids, of the companies with a contact on the list: an
+// email, phone number or website, or the domain of an email or website, or a parent domain of it.
+func (s *ContactSuppressionService) SuppressedCompanies(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	suppressed := make(map[uuid.UUID]bool)
+	if len(ids) == 0 {
+		return suppressed, nil
+	}
+	contacts, err := s.contacts.ListCompanyContacts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[suppressionKey][]uuid.UUID)
+	add := func(kind, value string, company uuid.UUID) {
+		key := suppressionKey{kind, value}
+		owners[key] = append(owners[key], company)
+	}
+	addDomain := func(domain string, company uuid.UUID) {
+		for _, parent := range parentDomains(domain) {
+			add(entity.SuppressionKindDomain, parent, company)
+		}
+	}
+	for _, company := range contacts {
+		if domain, ok := canonicalDomain(company.Website); ok {
+			addDomain(domain, company.CompanyID)
+		}
+		for _, raw := range company.Emails {
+			email := strings.ToLower(strings.TrimSpace(raw))
+			if !emailPattern.MatchString(email) {
+				continue
+			}
+			add(entity.SuppressionKindEmail, email, company.CompanyID)
+			addDomain(email[strings.LastIndex(email, "@")+1:], company.CompanyID)
+		}
+		for _, raw := range company.Phones {
+			if phone := normalizePhone(raw, defaultPhoneRegion); phone != "" {
+				add(entity.SuppressionKindPhone, phone, company.CompanyID)
+			}
+		}
+	}
+
+	values := make(map[string][]string, len(suppressionKinds))
+	for key := range owners {
+		values[key.kind] = append(values[key.kind], key.value)
+	}
+	matches, err := s.repo.MatchSuppressions(ctx, values[entity.SuppressionKindEmail], values[entity.SuppressionKindDomain], values[entity.SuppressionKindPhone])
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		for _, company := range owners[suppressionKey{match.Kind, match.Value}] {
+			suppressed[company] = true
+		}
+	}
+	return suppressed, nil
+}
+
+// parentDomains returns domain followed by each parent domain still holding a dot.
+func parentDomains(domain string) []string {
+	domains := []string{domain}
+	for {
+		dot := strings.Index(domain, ".")
+		if dot < 0 || !strings.Contains(domain[dot+1:], ".") {
+			return domains
+		}
+		domain = domain[dot+1:]
+		domains = append(domains, domain)
+	}
+}
+
+// FlagCompanies sets DoNotContact on the companies with a contact on the list.
+func (s *ContactSuppressionService) FlagCompanies(ctx context.Context, companies []*entity.Company) error {
+	ids := make([]uuid.UUID, len(companies))
+	for i, company := range companies {
+		ids[i] = company.ID
+	}
+	suppressed, err := s.SuppressedCompanies(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, company := range companies {
+		company.DoNotContact = suppressed[company.ID]
+	}
+	return nil
+}
+
+// WithContactSuppressions flags listed companies with a contact on the do-not-contact list and
+// leaves them out of exports unless the filter asks for them.
+func WithContactSuppressions(suppressions *ContactSuppressionService) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.suppressions = suppressions
+	}
+}
+
+// flagSuppressed sets DoNotContact on companies when the do-not-contact list is enabled.
+func (s *CompaniesService) flagSuppressed(ctx context.Context, companies []*entity.Company) error {
+	if s.suppressions == nil || len(companies) == 0 {
+		return nil
+	}
+	return s.suppressions.FlagCompanies(ctx, companies)
+}
+
+// suppressionCheckBatch is how many exported rows are checked against the do-not-contact list at
+// once.
+const suppressionCheckBatch = 500
+
+// suppressionFilter buffers exported rows to check them against the do-not-contact list in
+// batches, then writes the rows of companies off the list, or every row flagged when include is
+// set. Without the list rows are written straight away.
+type suppressionFilter[T any] struct {
+	ctx     context.Context
+	service *CompaniesService
+	include bool
+	company func(*T) *entity.Company
+	write   func(T) error
+	pending []T
+}
+
+func newSuppressionFilter[T any](ctx context.Context, s *CompaniesService, include bool, company func(*T) *entity.Company, write func(T) error) *suppressionFilter[T] {
+	return &suppressionFilter[T]{ctx: ctx, service: s, include: include, company: company, write: write}
+}
+
+func (f *suppressionFilter[T]) add(row T) error {
+	if f.service.suppressions == nil {
+		return f.write(row)
+	}
+	f.pending = append(f.pending, row)
+	if len(f.pending) < suppressionCheckBatch {
+		return nil
+	}
+	return f.flush()
+}
+
+func (f *suppressionFilter[T]) flush() error {
+	companies := make([]*entity.Company, len(f.pending))
+	for i := range f.pending {
+		companies[i] = f.company(&f.pending[i])
+	}
+	if err := f.service.flagSuppressed(f.ctx, companies); err != nil {
+		return err
+	}
+	for i, row := range f.pending {
+		if companies[i].DoNotContact && !f.include {
+			continue
+		}
+		if err := f.write(row); err != nil {
+			return err
+		}
+	}
+	f.pending = f.pending[:0]
+	return nil
+}
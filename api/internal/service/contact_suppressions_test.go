@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubSuppressionsRepo struct {
+	suppressions []entity.ContactSuppression
+	matched      [][]string
+}
+
+func (s *stubSuppressionsRepo) ListSuppressions(ctx context.Context, kind, query string, limit, offset int) ([]entity.ContactSuppression, error) {
+	return s.suppressions, nil
+}
+
+func (s *stubSuppressionsRepo) CreateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error {
+	for _, existing := range s.suppressions {
+		if existing.Kind == suppression.Kind && existing.Value == suppression.Value {
+			return repository.ErrSuppressionDuplicate
+		}
+	}
+	suppression.ID = uuid.New()
+	s.suppressions = append(s.suppressions, *suppression)
+	return nil
+}
+
+func (s *stubSuppressionsRepo) UpdateSuppression(ctx context.Context, suppression *entity.ContactSuppression) error {
+	return repository.ErrSuppressionNotFound
+}
+
+func (s *stubSuppressionsRepo) DeleteSuppression(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrSuppressionNotFound
+}
+
+func (s *stubSuppressionsRepo) MatchSuppressions(ctx context.Context, emails, domains, phones []string) ([]entity.ContactSuppression, error) {
+	s.matched = [][]string{emails, domains, phones}
+	var matches []entity.ContactSuppression
+	for _, suppression := range s.suppressions {
+		values := map[string][]string{
+			entity.SuppressionKindEmail:  emails,
+			entity.SuppressionKindDomain: domains,
+			entity.SuppressionKindPhone:  phones,
+		}[suppression.Kind]
+		if slices.Contains(values, suppression.Value) {
+			matches = append(matches, suppression)
+		}
+	}
+	return matches, nil
+}
+
+type stubCompanyContacts []entity.CompanyContacts
+
+func (s stubCompanyContacts) ListCompanyContacts(ctx context.Context, ids []uuid.UUID) ([]entity.CompanyContacts, error) {
+	var contacts []entity.CompanyContacts
+	for _, company := range s {
+		if slices.Contains(ids, company.CompanyID) {
+			contacts = append(contacts, company)
+		}
+	}
+	return contacts, nil
+}
+
+func TestContactSuppressionService_Create(t *testing.T) {
+	repo := &stubSuppressionsRepo{}
+	svc := NewContactSuppressionService(repo, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	cases := []struct {
+		req  dto.SuppressionRequest
+		want string
+	}{
+		{dto.SuppressionRequest{Kind: "email", Value: " Owner@Example.COM "}, "owner@example.com"},
+		{dto.SuppressionRequest{Kind: "Domain", Value: "https://www.Kopi.co.id/contact"}, "kopi.co.id"},
+		{dto.SuppressionRequest{Kind: "domain", Value: "sales@acme.com"}, "acme.com"},
+		{dto.SuppressionRequest{Kind: "phone", Value: "0812-3456-7890"}, "+6281234567890"},
+	}
+	for _, tc := range cases {
+		suppression, err := svc.Create(ctx, tc.req, userID.String())
+		if err != nil {
+			t.Fatalf("create %+v: %v", tc.req, err)
+		}
+		if suppression.Value != tc.want || suppression.Reason != entity.SuppressionManual || suppression.CreatedBy == nil || *suppression.CreatedBy != userID {
+			t.Fatalf("unexpected suppression %+v for %+v", suppression, tc.req)
+		}
+	}
+
+	if _, err := svc.Create(ctx, dto.SuppressionRequest{Kind: "email", Value: "OWNER@example.com"}, ""); !errors.Is(err, ErrSuppressionExists) {
+		t.Fatalf("expected ErrSuppressionExists, got %v", err)
+	}
+	for _, req := range []dto.SuppressionRequest{
+		{Kind: "fax", Value: "123"},
+		{Kind: "email", Value: "not-an-email"},
+		{Kind: "domain", Value: "localhost"},
+		{Kind: "phone", Value: "12"},
+	} {
+		if _, err := svc.Create(ctx, req, ""); !errors.Is(err, ErrInvalidSuppression) {
+			t.Fatalf("expected ErrInvalidSuppression for %+v, got %v", req, err)
+		}
+	}
+	if _, err := svc.Update(ctx, "nope", dto.SuppressionRequest{Kind: "email", Value: "a@b.co"}); !errors.Is(err, ErrInvalidSuppressionID) {
+		t.Fatalf("expected ErrInvalidSuppressionID, got %v", err)
+	}
+	if err := svc.Delete(ctx, uuid.NewString()); !errors.Is(err, ErrSuppressionNotFound) {
+		t.Fatalf("expected ErrSuppressionNotFound, got %v", err)
+	}
+}
+
+func TestContactSuppressionService_SuppressedCompanies(t *testing.T) {
+	byEmail, bySubdomain, byPhone, clean := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := &stubSuppressionsRepo{suppressions: []entity.ContactSuppression{
+		{Kind: entity.SuppressionKindEmail, Value: "owner@kopi.id"},
+		{Kind: entity.SuppressionKindDomain, Value: "acme.co.id"},
+		{Kind: entity.SuppressionKindPhone, Value: "+62215551234"},
+	}}
+	svc := NewContactSuppressionService(repo, stubCompanyContacts{
+		{CompanyID: byEmail, Emails: []string{"Owner@Kopi.ID"}},
+		{CompanyID: bySubdomain, Website: "https://shop.acme.co.id/"},
+		{CompanyID: byPhone, Phones: []string{"(021) 555-1234"}},
+		{CompanyID: clean, Website: "https://beta.com", Emails: []string{"hello@beta.com"}, Phones: []string{"0812 3456 7890"}},
+	})
+
+	suppressed, err := svc.SuppressedCompanies(context.Background(), []uuid.UUID{byEmail, bySubdomain, byPhone, clean})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !suppressed[byEmail] || !suppressed[bySubdomain] || !suppressed[byPhone] || suppressed[clean] {
+		t.Fatalf("unexpected suppressed companies %v", suppressed)
+	}
+	// Only parent domains still holding a dot are looked up, so a TLD never matches.
+	if slices.Contains(repo.matched[1], "id") || !slices.Contains(repo.matched[1], "co.id") {
+		t.Fatalf("unexpected domains looked up %v", repo.matched[1])
+	}
+}
+
+func TestCompaniesService_ExportsLeaveOutSuppressedCompanies(t *testing.T) {
+	listed, kept := uuid.New(), uuid.New()
+	suppressions := NewContactSuppressionService(
+		&stubSuppressionsRepo{suppressions: []entity.ContactSuppression{{Kind: entity.SuppressionKindDomain, Value: "acme.com"}}},
+		stubCompanyContacts{{CompanyID: listed, Website: "https://acme.com"}, {CompanyID: kept, Website: "https://beta.com"}},
+	)
+	exports := &stubCompanyExports{companies: []entity.Company{{ID: listed, Company: "Acme"}, {ID: kept, Company: "Beta"}}}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExports(exports, nil), WithContactSuppressions(suppressions))
+	ctx := context.Background()
+	stamp := NewExportStamp("", "", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC))
+
+	for _, include := range []bool{false, true} {
+		export, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{IncludeSuppressed: include}, "admin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := svc.WriteCompanyExportCSV(ctx, export, stamp, DefaultExportLocale, &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("read csv: %v", err)
+		}
+		doNotContact := slices.Index(ExportCSVHeaders, "do_not_contact")
+		switch {
+		case !include && (len(records) != 2 || records[1][1] != "Beta"):
+			t.Fatalf("expected the listed company left out, got %v", records)
+		case include && (len(records) != 3 || records[1][doNotContact] != "true" || records[2][doNotContact] != "false"):
+			t.Fatalf("expected every company with the flag, got %v", records)
+		}
+	}
+}
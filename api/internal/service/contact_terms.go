@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrContactTermsUnavailable is returned when contact search terms are rebuilt without a
+// repository.
+var ErrContactTermsUnavailable = errors.New("contact search terms not configured")
+
+// ContactTermsResult summarises an IndexEnrichmentContacts pass.
+type ContactTermsResult struct {
+	Scanned int `json:"scanned"`
+	Updated int `json:"updated"`
+}
+
+// WithContactTerms enables rebuilding the terms q_contact searches enrichment contacts by.
+func WithContactTerms(terms repository.ContactTermsRepository) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.contactTerms = terms
+	}
+}
+
+// IndexEnrichmentContacts rebuilds, paging by company id, the contact search terms of every
+// enrichment: after migration 0044 added them, and after encrypt-enrichments so emails and phone
+// numbers are searched by tokens of the active key. It can be interrupted and run again at any
+// time.
+func (s *MaintenanceService) IndexEnrichmentContacts(ctx context.Context, batchSize int) (ContactTermsResult, error) {
+	var result ContactTermsResult
+	if s.contactTerms == nil {
+		return result, ErrContactTermsUnavailable
+	}
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+
+	var after uuid.UUID
+	for {
+		page, err := s.contactTerms.RefreshContactTermsAfter(ctx, after, batchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Scanned += page.Scanned
+		result.Updated += page.Updated
+		if page.Scanned < batchSize {
+			return result, nil
+		}
+		after = page.Last
+	}
+}
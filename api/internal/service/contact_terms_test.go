@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubContactTermsRepo struct {
+	pages []repository.ContactTermsPage
+	// after holds the cursor of every call.
+	after []uuid.UUID
+}
+
+func (s *stubContactTermsRepo) RefreshContactTermsAfter(ctx context.Context, after uuid.UUID, limit int) (repository.ContactTermsPage, error) {
+	s.after = append(s.after, after)
+	if len(s.after) > len(s.pages) {
+		return repository.ContactTermsPage{Last: after}, nil
+	}
+	return s.pages[len(s.after)-1], nil
+}
+
+func TestMaintenanceService_IndexEnrichmentContacts(t *testing.T) {
+	first := uuid.New()
+	repo := &stubContactTermsRepo{pages: []repository.ContactTermsPage{
+		{Last: first, Scanned: 2, Updated: 2},
+		{Last: uuid.New(), Scanned: 1, Updated: 0},
+	}}
+	svc := NewMaintenanceService(&mockMaintenanceRepository{}, WithContactTerms(repo))
+
+	result, err := svc.IndexEnrichmentContacts(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Scanned != 3 || result.Updated != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(repo.after) != 2 || repo.after[0] != uuid.Nil || repo.after[1] != first {
+		t.Fatalf("expected paging by the last company, got %v", repo.after)
+	}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).IndexEnrichmentContacts(context.Background(), 2); !errors.Is(err, ErrContactTermsUnavailable) {
+		t.Fatalf("expected ErrContactTermsUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrInvalidDeadLetterID is returned when a dead letter identifier cannot be parsed as UUID.
+	ErrInvalidDeadLetterID = errors.New("invalid dead letter id")
+	// ErrDeadLetterNotFound indicates the requested dead letter does not exist.
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrDeadLetterReplayed is returned when a dead letter that was replayed already is changed or
+	// replayed again.
+	ErrDeadLetterReplayed = errors.New("dead letter was replayed already")
+	// ErrInvalidDeadLetterBody is returned when a dead letter body is not a JSON object.
+	ErrInvalidDeadLetterBody = errors.New("body must be a JSON object")
+	// ErrInvalidDeadLetterStatus is returned when dead letters are listed by an unknown status.
+	ErrInvalidDeadLetterStatus = errors.New("status must be one of pending, replayed")
+)
+
+// EnrichmentStore stores enrichment results; CompaniesService implements it.
+type EnrichmentStore interface {
+	SaveEnrichment(ctx context.Context, payload dto.EnrichResultRequest) error
+}
+
+// DeadLetterService keeps the worker callbacks the API could not store, so that an operator can
+// inspect them, fix the body and replay it, rather than the results being lost.
+type DeadLetterService struct {
+	repo        repository.DeadLettersRepository
+	enrichments EnrichmentStore
+}
+
+// NewDeadLetterService builds a DeadLetterService replaying enrichment results into enrichments.
+func NewDeadLetterService(repo repository.DeadLettersRepository, enrichments EnrichmentStore) *DeadLetterService {
+	return &DeadLetterService{repo: repo, enrichments: enrichments}
+}
+
+// RecordEnrichResult keeps the body of an /enrich-result callback rejected with cause. The company
+// is read from the body when it parses, the job from the callback token. A letter that cannot be
+// stored is logged; the worker is answered with cause either way.
+func (s *DeadLetterService) RecordEnrichResult(ctx context.Context, body []byte, jobID string, cause error) {
+	letter := &entity.DeadLetter{Kind: entity.DeadLetterEnrichResult, Body: string(body), Reason: cause.Error()}
+	var validationErr EnrichmentValidationError
+	if errors.As(cause, &validationErr) {
+		letter.Details = validationErr.Fields
+	}
+	var payload struct {
+		CompanyID string `json:"company_id"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		if id, err := uuid.Parse(strings.TrimSpace(payload.CompanyID)); err == nil {
+			letter.CompanyID = &id
+		}
+	}
+	if id, err := uuid.Parse(jobID); err == nil {
+		letter.JobID = &id
+	}
+	if err := s.repo.Create(ctx, letter); err != nil {
+		log.Printf("failed to record rejected %s callback (%v): %v", letter.Kind, cause, err)
+	}
+}
+
+// List returns the dead letters with status, every one when it is empty, newest first.
+func (s *DeadLetterService) List(ctx context.Context, status string, limit, offset int) ([]entity.DeadLetter, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && status != entity.DeadLetterPending && status != entity.DeadLetterReplayed {
+		return nil, ErrInvalidDeadLetterStatus
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+// Get returns a dead letter.
+func (s *DeadLetterService) Get(ctx context.Context, id string) (*entity.DeadLetter, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrInvalidDeadLetterID
+	}
+	letter, err := s.repo.Get(ctx, parsed)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeadLetterNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+	return letter, nil
+}
+
+// Update replaces the body of a pending dead letter with a fixed one.
+func (s *DeadLetterService) Update(ctx context.Context, id string, body json.RawMessage) (*entity.DeadLetter, error) {
+	trimmed := bytes.TrimSpace(body)
+	if !json.Valid(trimmed) || !bytes.HasPrefix(trimmed, []byte("{")) {
+		return nil, ErrInvalidDeadLetterBody
+	}
+	letter, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateBody(ctx, letter.ID, string(trimmed))
+}
+
+// Replay stores the body of a pending dead letter as its callback would have. The letter is
+// returned with the attempt counted either way; when the body is rejected again the error says why
+// and the letter stays pending.
+func (s *DeadLetterService) Replay(ctx context.Context, id string) (*entity.DeadLetter, error) {
+	letter, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var cause error
+	switch letter.Kind {
+	case entity.DeadLetterEnrichResult:
+		var payload dto.EnrichResultRequest
+		if err := json.Unmarshal([]byte(letter.Body), &payload); err != nil {
+			cause = fmt.Errorf("%w: %v", ErrInvalidDeadLetterBody, err)
+		} else if strings.TrimSpace(payload.CompanyID) == "" {
+			cause = ErrInvalidCompanyID
+		} else {
+			cause = s.enrichments.SaveEnrichment(ctx, payload)
+		}
+	default:
+		return nil, fmt.Errorf("replay dead letter: unknown kind %q", letter.Kind)
+	}
+
+	if cause != nil {
+		failed, err := s.repo.RecordReplayFailure(ctx, letter.ID, cause.Error())
+		if err != nil {
+			return nil, err
+		}
+		return failed, cause
+	}
+	return s.repo.MarkReplayed(ctx, letter.ID)
+}
+
+func (s *DeadLetterService) pending(ctx context.Context, id string) (*entity.DeadLetter, error) {
+	letter, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.Status != entity.DeadLetterPending {
+		return nil, ErrDeadLetterReplayed
+	}
+	return letter, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type deadLettersRepoStub struct {
+	created *entity.DeadLetter
+	letter  *entity.DeadLetter
+}
+
+func (s *deadLettersRepoStub) Create(ctx context.Context, letter *entity.DeadLetter) error {
+	s.created = letter
+	return nil
+}
+
+func (s *deadLettersRepoStub) List(ctx context.Context, status string, limit, offset int) ([]entity.DeadLetter, error) {
+	return []entity.DeadLetter{}, nil
+}
+
+func (s *deadLettersRepoStub) Get(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	return s.letter, nil
+}
+
+func (s *deadLettersRepoStub) UpdateBody(ctx context.Context, id uuid.UUID, body string) (*entity.DeadLetter, error) {
+	s.letter.Body = body
+	return s.letter, nil
+}
+
+func (s *deadLettersRepoStub) MarkReplayed(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	s.letter.Status = entity.DeadLetterReplayed
+	return s.letter, nil
+}
+
+func (s *deadLettersRepoStub) RecordReplayFailure(ctx context.Context, id uuid.UUID, reason string) (*entity.DeadLetter, error) {
+	s.letter.LastError = &reason
+	return s.letter, nil
+}
+
+func TestDeadLetterService_RecordEnrichResult(t *testing.T) {
+	repo := &deadLettersRepoStub{}
+	svc := NewDeadLetterService(repo, nil)
+	companyID, jobID := uuid.New(), uuid.New()
+
+	cause := EnrichmentValidationError{Fields: map[string]string{"emails": "no MX record"}}
+	svc.RecordEnrichResult(context.Background(), []byte(`{"company_id":"`+companyID.String()+`"}`), jobID.String(), cause)
+	letter := repo.created
+	if letter == nil || letter.Kind != entity.DeadLetterEnrichResult || letter.Details["emails"] != "no MX record" {
+		t.Fatalf("unexpected letter: %+v", letter)
+	}
+	if letter.CompanyID == nil || *letter.CompanyID != companyID || letter.JobID == nil || *letter.JobID != jobID {
+		t.Fatalf("expected the company and job kept, got %+v", letter)
+	}
+
+	svc.RecordEnrichResult(context.Background(), []byte(`not json`), "", errors.New("invalid JSON payload"))
+	if repo.created.Body != "not json" || repo.created.CompanyID != nil || repo.created.JobID != nil {
+		t.Fatalf("expected the raw body without ids, got %+v", repo.created)
+	}
+}
+
+func TestDeadLetterService_List(t *testing.T) {
+	svc := NewDeadLetterService(&deadLettersRepoStub{}, nil)
+	if _, err := svc.List(context.Background(), "lost", 0, 0); !errors.Is(err, ErrInvalidDeadLetterStatus) {
+		t.Fatalf("expected ErrInvalidDeadLetterStatus, got %v", err)
+	}
+	if _, err := svc.Get(context.Background(), "nope"); !errors.Is(err, ErrInvalidDeadLetterID) {
+		t.Fatalf("expected ErrInvalidDeadLetterID, got %v", err)
+	}
+}
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	repo := &deadLettersRepoStub{letter: &entity.DeadLetter{ID: uuid.New(), Kind: entity.DeadLetterEnrichResult, Status: entity.DeadLetterPending, Body: `{"emails":[]}`}}
+	svc := NewDeadLetterService(repo, nil)
+
+	letter, err := svc.Replay(context.Background(), repo.letter.ID.String())
+	if !errors.Is(err, ErrInvalidCompanyID) || letter == nil || letter.LastError == nil {
+		t.Fatalf("expected a failed replay without a company, got %+v (%v)", letter, err)
+	}
+
+	repo.letter.Status = entity.DeadLetterReplayed
+	if _, err := svc.Replay(context.Background(), repo.letter.ID.String()); !errors.Is(err, ErrDeadLetterReplayed) {
+		t.Fatalf("expected ErrDeadLetterReplayed, got %v", err)
+	}
+	if _, err := svc.Update(context.Background(), repo.letter.ID.String(), []byte(`[1]`)); !errors.Is(err, ErrInvalidDeadLetterBody) {
+		t.Fatalf("expected ErrInvalidDeadLetterBody, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the DNS cache built by NewDataProcessor.
+const (
+	defaultDNSCacheSize   = 10000
+	defaultDNSPositiveTTL = time.Hour
+	defaultDNSNegativeTTL = 10 * time.Minute
+)
+
+// DNSCache remembers MX lookup results per domain so the handful of domains most emails use
+// (gmail.com, yahoo.com, the company's own) are resolved once rather than on every payload. It is
+// safe for concurrent use and meant to be shared by every DataProcessor of the process. Domains
+// with mail servers are kept for the positive TTL and domains without for the usually shorter
+// negative TTL; past its size the least recently used domain is dropped.
+type DNSCache struct {
+	mu          sync.Mutex
+	size        int
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	order       *list.List
+	entries     map[string]*list.Element
+	now         func() time.Time
+
+	hits, misses, evictions int64
+}
+
+type dnsCacheEntry struct {
+	domain  string
+	hasMX   bool
+	expires time.Time
+}
+
+// DNSCacheStats reports the effectiveness of a DNSCache since the process started.
+type DNSCacheStats struct {
+	Entries   int     `json:"entries"`
+	Size      int     `json:"size"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// NewDNSCache builds a cache of at most size domains; a zero size or zero TTLs return nil, which
+// disables caching. A zero negative TTL alone only stops domains without mail servers being cached.
+func NewDNSCache(size int, positiveTTL, negativeTTL time.Duration) *DNSCache {
+	if size <= 0 || (positiveTTL <= 0 && negativeTTL <= 0) {
+		return nil
+	}
+	return &DNSCache{
+		size:        size,
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+		order:       list.New(),
+		entries:     make(map[string]*list.Element),
+		now:         time.Now,
+	}
+}
+
+// Lookup returns whether domain has mail servers; found is false when it is not cached or expired.
+func (c *DNSCache) Lookup(domain string) (hasMX, found bool) {
+	if c == nil {
+		return false, false
+	}
+	domain = strings.ToLower(domain)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[domain]
+	if !ok {
+		c.misses++
+		return false, false
+	}
+	entry := element.Value.(*dnsCacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, domain)
+		c.misses++
+		return false, false
+	}
+	c.order.MoveToFront(element)
+	c.hits++
+	return entry.hasMX, true
+}
+
+// Store records the lookup result of domain.
+func (c *DNSCache) Store(domain string, hasMX bool) {
+	if c == nil {
+		return
+	}
+	ttl := c.negativeTTL
+	if hasMX {
+		ttl = c.positiveTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	domain = strings.ToLower(domain)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := c.now().Add(ttl)
+	if element, ok := c.entries[domain]; ok {
+		entry := element.Value.(*dnsCacheEntry)
+		entry.hasMX, entry.expires = hasMX, expires
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[domain] = c.order.PushFront(&dnsCacheEntry{domain: domain, hasMX: hasMX, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*dnsCacheEntry).domain)
+		c.evictions++
+	}
+}
+
+// Stats returns the current counters; a nil cache reports zeros.
+func (c *DNSCache) Stats() DNSCacheStats {
+	if c == nil {
+		return DNSCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := DNSCacheStats{
+		Entries:   c.order.Len(),
+		Size:      c.size,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+	if total := c.hits + c.misses; total > 0 {
+		stats.HitRate = float64(c.hits) / float64(total)
+	}
+	return stats
+}
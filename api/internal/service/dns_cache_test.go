@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDNSCache_TTLsAndEviction(t *testing.T) {
+	now := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	cache := NewDNSCache(2, time.Hour, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Store("Gmail.com", true)
+	cache.Store("nomail.example", false)
+	if hasMX, found := cache.Lookup("gmail.com"); !hasMX || !found {
+		t.Fatalf("expected a cached positive, got %v, %v", hasMX, found)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, found := cache.Lookup("nomail.example"); found {
+		t.Fatal("expected the negative entry to expire first")
+	}
+	if _, found := cache.Lookup("gmail.com"); !found {
+		t.Fatal("expected the positive entry to outlive the negative TTL")
+	}
+
+	// gmail.com was used last, so yahoo.com evicts the older outlook.com.
+	cache.Store("outlook.com", true)
+	cache.Lookup("gmail.com")
+	cache.Store("yahoo.com", true)
+	if _, found := cache.Lookup("outlook.com"); found {
+		t.Fatal("expected the least recently used domain to be evicted")
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Hits != 3 || stats.Misses != 2 || stats.Evictions != 1 || stats.HitRate != 0.6 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if NewDNSCache(0, time.Hour, time.Hour) != nil {
+		t.Fatal("expected a zero size to disable the cache")
+	}
+}
+
+// flakyResolver fails every lookup with a temporary error.
+type flakyResolver struct{ calls int }
+
+func (r *flakyResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	r.calls++
+	return nil, &net.DNSError{Err: "server misbehaving", Name: domain, IsTemporary: true}
+}
+
+func TestHasMXRecord_SharesCacheAndSkipsTemporaryErrors(t *testing.T) {
+	cache := NewDNSCache(100, time.Hour, time.Minute)
+	resolver := &countingResolver{mx: map[string]bool{"gmail.com": true}}
+	first := NewDataProcessor("ID", WithDNSResolver(resolver), WithDNSCache(cache))
+	second := NewDataProcessor("ID", WithDNSResolver(resolver), WithDNSCache(cache))
+
+	if !first.hasMXRecord(context.Background(), "gmail.com") || first.hasMXRecord(context.Background(), "nomail.example") {
+		t.Fatal("unexpected MX results")
+	}
+	if !second.hasMXRecord(context.Background(), "gmail.com") || second.hasMXRecord(context.Background(), "nomail.example") {
+		t.Fatal("unexpected cached MX results")
+	}
+	if calls := resolver.calls.Load(); calls != 2 {
+		t.Fatalf("expected processors to share positive and negative results, got %d lookups", calls)
+	}
+
+	flaky := &flakyResolver{}
+	third := NewDataProcessor("ID", WithDNSResolver(flaky), WithDNSCache(cache))
+	third.hasMXRecord(context.Background(), "flaky.example")
+	third.hasMXRecord(context.Background(), "flaky.example")
+	if flaky.calls != 2 {
+		t.Fatalf("expected temporary failures not to be cached, got %d lookups", flaky.calls)
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// Email classes recorded in enrichment metadata under "email_classes".
+const (
+	// EmailClassDisposable marks addresses of throwaway inbox services.
+	EmailClassDisposable = "disposable"
+	// EmailClassFree marks addresses of free consumer mail providers rather than a company domain.
+	EmailClassFree = "free"
+	// EmailClassRole marks shared mailboxes such as info@ or sales@ rather than a person.
+	EmailClassRole = "role"
+)
+
+// EmailClasses lists every class in a stable order.
+var EmailClasses = []string{EmailClassDisposable, EmailClassFree, EmailClassRole}
+
+// maxDisposableListBytes caps the remote disposable domain list.
+const maxDisposableListBytes = 8 << 20
+
+//go:embed disposable_domains.txt
+var bundledDisposableDomains string
+
+var freeMailDomains = toSet(
+	"gmail.com", "googlemail.com", "yahoo.com", "yahoo.co.id", "yahoo.co.uk", "ymail.com", "rocketmail.com",
+	"hotmail.com", "hotmail.co.uk", "outlook.com", "outlook.co.id", "live.com", "msn.com", "icloud.com",
+	"me.com", "mac.com", "aol.com", "protonmail.com", "proton.me", "pm.me", "gmx.com", "gmx.de", "gmx.net",
+	"mail.com", "mail.ru", "yandex.com", "yandex.ru", "zoho.com", "zohomail.com", "qq.com", "163.com",
+	"126.com", "naver.com", "daum.net", "web.de", "t-online.de", "libero.it", "orange.fr", "free.fr",
+	"tutanota.com", "fastmail.com", "hey.com", "rediffmail.com",
+)
+
+var roleLocalParts = toSet(
+	"admin", "administrator", "billing", "booking", "bookings", "careers", "contact", "contactus", "cs",
+	"customercare", "customerservice", "enquiries", "enquiry", "finance", "hello", "help", "helpdesk", "hi",
+	"hr", "info", "information", "inquiries", "inquiry", "jobs", "legal", "mail", "marketing", "media",
+	"no-reply", "noreply", "office", "order", "orders", "postmaster", "press", "privacy", "reception",
+	"recruitment", "reservations", "sales", "security", "service", "support", "team", "webmaster",
+)
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// EmailClassifier tags addresses as disposable, free-mail or role accounts. The disposable list
+// starts from the bundled domains and can be extended from a remote list.
+type EmailClassifier struct {
+	mu         sync.RWMutex
+	disposable map[string]bool
+	httpClient HTTPClient
+}
+
+// NewEmailClassifier builds a classifier with the bundled disposable domains; a nil client uses a
+// client with the default validation timeout for remote refreshes.
+func NewEmailClassifier(client HTTPClient) *EmailClassifier {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	domains, _ := parseDomainList(strings.NewReader(bundledDisposableDomains))
+	return &EmailClassifier{disposable: domains, httpClient: client}
+}
+
+// Classify returns the classes of a normalised address, or nil for an ordinary one.
+func (c *EmailClassifier) Classify(email string) []string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return nil
+	}
+	var classes []string
+	c.mu.RLock()
+	disposable := c.disposable[domain]
+	c.mu.RUnlock()
+	if disposable {
+		classes = append(classes, EmailClassDisposable)
+	}
+	if freeMailDomains[domain] {
+		classes = append(classes, EmailClassFree)
+	}
+	// Sub-addresses such as sales+leads@ are still the sales mailbox.
+	local, _, _ = strings.Cut(local, "+")
+	if roleLocalParts[local] {
+		classes = append(classes, EmailClassRole)
+	}
+	return classes
+}
+
+// Refresh downloads a disposable domain list (one domain per line, # comments) and adds it to the
+// bundled domains.
+func (c *EmailClassifier) Refresh(ctx context.Context, listURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+	if err != nil {
+		return fmt.Errorf("build disposable list request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("download disposable list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download disposable list: unexpected status %d", resp.StatusCode)
+	}
+	remote, err := parseDomainList(io.LimitReader(resp.Body, maxDisposableListBytes))
+	if err != nil {
+		return fmt.Errorf("read disposable list: %w", err)
+	}
+
+	domains, _ := parseDomainList(strings.NewReader(bundledDisposableDomains))
+	for domain := range remote {
+		domains[domain] = true
+	}
+	c.mu.Lock()
+	c.disposable = domains
+	c.mu.Unlock()
+	return nil
+}
+
+// RunRefresh refreshes the disposable list from listURL every interval until ctx is cancelled. A
+// failed refresh keeps the previous list.
+func (c *EmailClassifier) RunRefresh(ctx context.Context, listURL string, interval time.Duration) {
+	refresh := func() {
+		if err := c.Refresh(ctx, listURL); err != nil {
+			log.Printf("failed to refresh disposable email domains: %v", err)
+		}
+	}
+	refresh()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
+
+func parseDomainList(r io.Reader) (map[string]bool, error) {
+	domains := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if domain := strings.ToLower(strings.TrimSpace(line)); domain != "" {
+			domains[domain] = true
+		}
+	}
+	return domains, scanner.Err()
+}
+
+// WithEmailClassifier records the classes of kept emails in enrichment metadata; addresses are kept
+// regardless of their class and can be filtered when read.
+func WithEmailClassifier(classifier *EmailClassifier) DataProcessorOption {
+	return func(p *DataProcessor) {
+		p.emailClassifier = classifier
+	}
+}
+
+// classifyEmails maps each classified address to its classes; nil without a classifier.
+func (p *DataProcessor) classifyEmails(emails []string) map[string][]string {
+	if p == nil || p.emailClassifier == nil {
+		return nil
+	}
+	classes := make(map[string][]string)
+	for _, email := range emails {
+		if tags := p.emailClassifier.Classify(email); len(tags) > 0 {
+			classes[email] = tags
+		}
+	}
+	if len(classes) == 0 {
+		return nil
+	}
+	return classes
+}
+
+// ExcludeEmailClasses removes emails of the given classes from an enrichment, using the classes
+// stored in its metadata at save time.
+func ExcludeEmailClasses(enrichment *entity.CompanyEnrichment, excluded map[string]bool) {
+	if enrichment == nil || len(excluded) == 0 {
+		return
+	}
+	stored := storedEmailClasses(enrichment.Metadata)
+	if len(stored) == 0 {
+		return
+	}
+	kept := make([]string, 0, len(enrichment.Emails))
+	for _, email := range enrichment.Emails {
+		drop := false
+		for _, class := range stored[email] {
+			if excluded[class] {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, email)
+		}
+	}
+	enrichment.Emails = kept
+}
+
+// storedEmailClasses reads metadata["email_classes"], which is a map[string][]string when built in
+// process and a map[string]any of []any once decoded from the database.
+func storedEmailClasses(metadata map[string]any) map[string][]string {
+	switch raw := metadata["email_classes"].(type) {
+	case map[string][]string:
+		return raw
+	case map[string]any:
+		classes := make(map[string][]string, len(raw))
+		for email, value := range raw {
+			tags, _ := value.([]any)
+			for _, tag := range tags {
+				if s, ok := tag.(string); ok {
+					classes[email] = append(classes[email], s)
+				}
+			}
+		}
+		return classes
+	default:
+		return nil
+	}
+}
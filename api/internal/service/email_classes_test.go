@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestEmailClassifier_Classify(t *testing.T) {
+	classifier := NewEmailClassifier(nil)
+	cases := map[string][]string{
+		"budi@acme.co.id":        nil,
+		"sales+leads@acme.co.id": {EmailClassRole},
+		"owner@gmail.com":        {EmailClassFree},
+		"info@gmail.com":         {EmailClassFree, EmailClassRole},
+		"x7@mailinator.com":      {EmailClassDisposable},
+	}
+	for email, want := range cases {
+		if got := classifier.Classify(email); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Classify(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
+
+func TestEmailClassifier_RefreshAddsRemoteDomains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# community list\nFreshTrash.example\n\n"))
+	}))
+	defer server.Close()
+
+	classifier := NewEmailClassifier(server.Client())
+	if err := classifier.Refresh(context.Background(), server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := classifier.Classify("a@freshtrash.example"); len(got) != 1 || got[0] != EmailClassDisposable {
+		t.Fatalf("expected remote domain to be disposable, got %v", got)
+	}
+	if got := classifier.Classify("a@yopmail.com"); len(got) != 1 {
+		t.Fatalf("expected bundled domains to be kept, got %v", got)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_StoresEmailClasses(t *testing.T) {
+	var stored *entity.CompanyEnrichment
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			stored = enrichment
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, c *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	processor := NewDataProcessor("ID",
+		WithDNSResolver(&stubDNSResolver{mx: map[string]bool{"example.com": true, "gmail.com": true}}),
+		WithEmailClassifier(NewEmailClassifier(nil)),
+	)
+	svc := NewCompaniesService(repo, WithEnrichmentValidation(processor, EnrichValidationLenient))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb",
+		Emails:    []string{"budi@example.com", "info@example.com", "owner@gmail.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stored.Emails) != 3 {
+		t.Fatalf("classified emails must be kept, got %v", stored.Emails)
+	}
+	want := map[string][]string{"info@example.com": {EmailClassRole}, "owner@gmail.com": {EmailClassFree}}
+	if got := stored.Metadata["email_classes"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected email classes: %v", got)
+	}
+
+	// Metadata read back from the database decodes into generic JSON values.
+	stored.Metadata["email_classes"] = map[string]any{"info@example.com": []any{"role"}, "owner@gmail.com": []any{"free"}}
+	ExcludeEmailClasses(stored, map[string]bool{EmailClassRole: true})
+	if !reflect.DeepEqual(stored.Emails, []string{"budi@example.com", "owner@gmail.com"}) {
+		t.Fatalf("unexpected emails after excluding role accounts: %v", stored.Emails)
+	}
+}
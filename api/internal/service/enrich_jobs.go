@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// Enrichment depth levels accepted by the enqueue endpoint.
+const (
+	EnrichDepthBasic    = "basic"
+	EnrichDepthStandard = "standard"
+	EnrichDepthDeep     = "deep"
+)
+
+// Enrichment job statuses recorded in enrichment_jobs.
+const (
+	EnrichJobStatusReserved = "reserved"
+	EnrichJobStatusAccepted = "accepted"
+	EnrichJobStatusCached   = "cached"
+	EnrichJobStatusFailed   = "failed"
+)
+
+const enrichQuotaWindow = 24 * time.Hour
+
+var (
+	// ErrInvalidEnrichDepth is returned when the requested depth is not one of basic/standard/deep.
+	ErrInvalidEnrichDepth = errors.New("depth must be one of basic, standard, deep")
+	// ErrEnrichQuotaExceeded indicates the caller has consumed its enrichment budget for the window.
+	ErrEnrichQuotaExceeded = errors.New("enrichment quota exceeded")
+)
+
+// EnrichDepthProfile describes how far the worker crawls and what the job costs.
+type EnrichDepthProfile struct {
+	Depth    string `json:"depth"`
+	MaxPages int    `json:"max_pages"`
+	Cost     int    `json:"cost"`
+}
+
+var enrichDepthProfiles = map[string]EnrichDepthProfile{
+	EnrichDepthBasic:    {Depth: EnrichDepthBasic, MaxPages: 2, Cost: 1},
+	EnrichDepthStandard: {Depth: EnrichDepthStandard, MaxPages: 5, Cost: 2},
+	EnrichDepthDeep:     {Depth: EnrichDepthDeep, MaxPages: 20, Cost: 5},
+}
+
+// ResolveEnrichDepth maps a raw depth value to its profile, defaulting to standard.
+func ResolveEnrichDepth(raw string) (EnrichDepthProfile, error) {
+	depth := strings.ToLower(strings.TrimSpace(raw))
+	if depth == "" {
+		depth = EnrichDepthStandard
+	}
+	profile, ok := enrichDepthProfiles[depth]
+	if !ok {
+		return EnrichDepthProfile{}, ErrInvalidEnrichDepth
+	}
+	return profile, nil
+}
+
+// EnrichQuotaUsage reports cost units consumed in the rolling quota window.
+type EnrichQuotaUsage struct {
+	Used      int `json:"used"`
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+}
+
+// EnrichJobService tracks enrichment jobs and enforces per-user cost quotas.
+type EnrichJobService struct {
+	repo       repository.EnrichmentJobsRepository
+	dailyQuota int
+	now        func() time.Time
+}
+
+// NewEnrichJobService builds the service; a dailyQuota of zero disables quota enforcement.
+func NewEnrichJobService(repo repository.EnrichmentJobsRepository, dailyQuota int) *EnrichJobService {
+	if dailyQuota < 0 {
+		dailyQuota = 0
+	}
+	return &EnrichJobService{repo: repo, dailyQuota: dailyQuota, now: time.Now}
+}
+
+// ReserveJob records a reserved job for the profile before the worker is asked, charging its cost
+// to the user's quota, and returns the job with the quota usage including it, nil when quotas are
+// off. Once the worker answers, AcceptJob or ReleaseJob settles the reservation.
+func (s *EnrichJobService) ReserveJob(ctx context.Context, jobID uuid.UUID, userID, companyID, website string, profile EnrichDepthProfile) (*entity.EnrichmentJob, *EnrichQuotaUsage, error) {
+	cid, err := uuid.Parse(strings.TrimSpace(companyID))
+	if err != nil {
+		return nil, nil, ErrInvalidCompanyID
+	}
+	job := &entity.EnrichmentJob{
+		ID:        jobID,
+		CompanyID: cid,
+		Website:   website,
+		Depth:     profile.Depth,
+		Cost:      profile.Cost,
+		Status:    EnrichJobStatusReserved,
+	}
+	uid, err := uuid.Parse(strings.TrimSpace(userID))
+	if err == nil {
+		job.RequestedBy = &uid
+	} else if s.dailyQuota > 0 {
+		return nil, nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	used, err := s.repo.Reserve(ctx, job, s.now().Add(-enrichQuotaWindow), s.dailyQuota)
+	switch {
+	case errors.Is(err, repository.ErrEnrichmentQuotaExceeded):
+		return nil, s.quotaUsage(used), ErrEnrichQuotaExceeded
+	case err != nil:
+		return nil, nil, err
+	case s.dailyQuota == 0:
+		return job, nil, nil
+	}
+	return job, s.quotaUsage(used + profile.Cost), nil
+}
+
+func (s *EnrichJobService) quotaUsage(used int) *EnrichQuotaUsage {
+	usage := &EnrichQuotaUsage{Used: used, Limit: s.dailyQuota, Remaining: s.dailyQuota - used}
+	if usage.Remaining < 0 {
+		usage.Remaining = 0
+	}
+	return usage
+}
+
+// AcceptJob settles a reservation the worker accepted; its cost stays charged.
+func (s *EnrichJobService) AcceptJob(ctx context.Context, jobID uuid.UUID) error {
+	return s.repo.SetStatus(ctx, jobID, EnrichJobStatusAccepted, nil)
+}
+
+// ReleaseJob marks a reservation the worker refused as failed with the reason, which gives its cost
+// back until an operator retries it.
+func (s *EnrichJobService) ReleaseJob(ctx context.Context, jobID uuid.UUID, reason string) error {
+	return s.repo.SetStatus(ctx, jobID, EnrichJobStatusFailed, &reason)
+}
+
+// RecordJob stores an enrichment job together with its depth, cost and status. A nil jobID lets the
+// database assign one; callers pass their own when the id already went out with the job.
+func (s *EnrichJobService) RecordJob(ctx context.Context, jobID uuid.UUID, userID, companyID, website string, profile EnrichDepthProfile, status string) (*entity.EnrichmentJob, error) {
+	cid, err := uuid.Parse(strings.TrimSpace(companyID))
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+
+	job := &entity.EnrichmentJob{
+		ID:        jobID,
+		CompanyID: cid,
+		Website:   website,
+		Depth:     profile.Depth,
+		Cost:      profile.Cost,
+		Status:    status,
+	}
+	if uid, err := uuid.Parse(strings.TrimSpace(userID)); err == nil {
+		job.RequestedBy = &uid
+	}
+
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockEnrichmentJobsRepository struct {
+	created []*entity.EnrichmentJob
+	used    int
+	since   time.Time
+	err     error
+}
+
+func (m *mockEnrichmentJobsRepository) Create(ctx context.Context, job *entity.EnrichmentJob) error {
+	if m.err != nil {
+		return m.err
+	}
+	job.ID = uuid.New()
+	m.created = append(m.created, job)
+	return nil
+}
+
+func (m *mockEnrichmentJobsRepository) Reserve(ctx context.Context, job *entity.EnrichmentJob, since time.Time, quota int) (int, error) {
+	m.since = since
+	if m.err != nil {
+		return 0, m.err
+	}
+	if quota > 0 && m.used+job.Cost > quota {
+		return m.used, repository.ErrEnrichmentQuotaExceeded
+	}
+	m.created = append(m.created, job)
+	return m.used, nil
+}
+
+func (m *mockEnrichmentJobsRepository) SetStatus(ctx context.Context, id uuid.UUID, status string, reason *string) error {
+	for _, job := range m.created {
+		if job.ID == id {
+			job.Status, job.Error = status, reason
+		}
+	}
+	return m.err
+}
+
+func TestResolveEnrichDepth(t *testing.T) {
+	profile, err := ResolveEnrichDepth("")
+	if err != nil || profile.Depth != EnrichDepthStandard {
+		t.Fatalf("expected standard default, got %+v (%v)", profile, err)
+	}
+	profile, err = ResolveEnrichDepth(" DEEP ")
+	if err != nil || profile.Depth != EnrichDepthDeep || profile.Cost <= enrichDepthProfiles[EnrichDepthStandard].Cost {
+		t.Fatalf("unexpected deep profile: %+v (%v)", profile, err)
+	}
+	if _, err := ResolveEnrichDepth("extreme"); !errors.Is(err, ErrInvalidEnrichDepth) {
+		t.Fatalf("expected ErrInvalidEnrichDepth, got %v", err)
+	}
+}
+
+func TestEnrichJobService_ReserveJob(t *testing.T) {
+	userID, companyID := "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	deep, _ := ResolveEnrichDepth(EnrichDepthDeep)
+
+	disabled := NewEnrichJobService(&mockEnrichmentJobsRepository{used: 1000}, 0)
+	if job, usage, err := disabled.ReserveJob(context.Background(), uuid.New(), "", companyID, "https://acme.test", deep); err != nil || usage != nil || job.Status != EnrichJobStatusReserved {
+		t.Fatalf("expected a reservation without quota, got %+v, %+v (%v)", job, usage, err)
+	}
+
+	repo := &mockEnrichmentJobsRepository{used: 6}
+	svc := NewEnrichJobService(repo, 10)
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	_, usage, err := svc.ReserveJob(context.Background(), uuid.New(), userID, companyID, "https://acme.test", deep)
+	if !errors.Is(err, ErrEnrichQuotaExceeded) {
+		t.Fatalf("expected quota exceeded, got %v", err)
+	}
+	if usage == nil || usage.Used != 6 || usage.Remaining != 4 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if !repo.since.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("expected 24h window, got %v", repo.since)
+	}
+
+	basic, _ := ResolveEnrichDepth(EnrichDepthBasic)
+	jobID := uuid.New()
+	job, usage, err := svc.ReserveJob(context.Background(), jobID, userID, companyID, "https://acme.test", basic)
+	if err != nil || job.ID != jobID || job.RequestedBy == nil || usage.Used != 7 || usage.Remaining != 3 {
+		t.Fatalf("expected basic job to fit in quota, got %+v, %+v (%v)", job, usage, err)
+	}
+
+	if err := svc.ReleaseJob(context.Background(), jobID, "worker down"); err != nil || job.Status != EnrichJobStatusFailed || *job.Error != "worker down" {
+		t.Fatalf("expected the reservation released, got %+v (%v)", job, err)
+	}
+	if err := svc.AcceptJob(context.Background(), jobID); err != nil || job.Status != EnrichJobStatusAccepted || job.Error != nil {
+		t.Fatalf("expected the job accepted, got %+v (%v)", job, err)
+	}
+
+	if _, _, err := svc.ReserveJob(context.Background(), uuid.New(), "not-a-uuid", companyID, "https://acme.test", basic); err == nil {
+		t.Fatalf("expected error for invalid user id")
+	}
+	if _, _, err := svc.ReserveJob(context.Background(), uuid.New(), userID, "bad", "https://acme.test", basic); !errors.Is(err, ErrInvalidCompanyID) {
+		t.Fatalf("expected ErrInvalidCompanyID, got %v", err)
+	}
+}
+
+func TestEnrichJobService_RecordJob(t *testing.T) {
+	repo := &mockEnrichmentJobsRepository{}
+	svc := NewEnrichJobService(repo, 10)
+	profile, _ := ResolveEnrichDepth(EnrichDepthBasic)
+
+	job, err := svc.RecordJob(context.Background(), uuid.Nil, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb", "https://acme.test", profile, EnrichJobStatusAccepted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Depth != EnrichDepthBasic || job.Cost != profile.Cost || job.RequestedBy == nil {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	if len(repo.created) != 1 {
+		t.Fatalf("expected job persisted")
+	}
+
+	if _, err := svc.RecordJob(context.Background(), uuid.Nil, "", "bad", "https://acme.test", profile, EnrichJobStatusAccepted); !errors.Is(err, ErrInvalidCompanyID) {
+		t.Fatalf("expected ErrInvalidCompanyID, got %v", err)
+	}
+}
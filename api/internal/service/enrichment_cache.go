@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// DefaultEnrichmentCacheTTL is how long a domain enrichment stays reusable when no TTL is configured.
+const DefaultEnrichmentCacheTTL = 7 * 24 * time.Hour
+
+var enrichDepthRank = map[string]int{
+	EnrichDepthBasic:    1,
+	EnrichDepthStandard: 2,
+	EnrichDepthDeep:     3,
+}
+
+// WithEnrichmentCache shares enrichment results between companies with the same canonical domain.
+// A non-positive ttl disables reuse while still recording results.
+func WithEnrichmentCache(cache repository.EnrichmentCacheRepository, ttl time.Duration) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.cache = cache
+		s.cacheTTL = ttl
+	}
+}
+
+// ReuseCachedEnrichment copies a fresh domain enrichment onto the company when one exists.
+// It returns nil without error when the cache is disabled, stale or shallower than depth.
+func (s *CompaniesService) ReuseCachedEnrichment(ctx context.Context, companyIDRaw, website, depth string) (*entity.CompanyEnrichment, error) {
+	if s.cache == nil || s.cacheTTL <= 0 {
+		return nil, nil
+	}
+	companyID, err := uuid.Parse(strings.TrimSpace(companyIDRaw))
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	domain, ok := cacheDomain(website)
+	if !ok {
+		return nil, nil
+	}
+
+	cached, err := s.cache.GetDomainEnrichment(ctx, domain)
+	if err != nil {
+		if errors.Is(err, repository.ErrDomainEnrichmentNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !s.cacheUsable(cached, depth) {
+		return nil, nil
+	}
+
+	payload := dto.EnrichResultRequest{
+		CompanyID:      companyID.String(),
+		Emails:         cached.Emails,
+		Phones:         cached.Phones,
+		Socials:        cached.Socials,
+		ContactFormURL: cached.ContactFormURL,
+		AboutSummary:   cached.AboutSummary,
+		Website:        strings.TrimSpace(website),
+		Depth:          cached.Depth,
+	}
+	// The logo belongs to the website; a Maps photo or street address belongs to the place, as
+	// branches of a chain share the site but not the address, so those are not reused.
+	if logo, ok := cached.Metadata["logo_url"].(string); ok {
+		payload.LogoURL = &logo
+	}
+	metadata := buildEnrichmentMetadata(payload)
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	if technologies, ok := cached.Metadata["technologies"]; ok {
+		metadata["technologies"] = technologies
+	}
+	metadata["cached_from_domain"] = domain
+	metadata["cached_at"] = cached.UpdatedAt.UTC().Format(time.RFC3339)
+	if cached.SourceCompanyID != nil {
+		metadata["source_company_id"] = cached.SourceCompanyID.String()
+	}
+
+	enrichment := &entity.CompanyEnrichment{
+		CompanyID:      companyID,
+		Emails:         normalizeStringSlice(payload.Emails, strings.ToLower),
+		Phones:         normalizeStringSlice(payload.Phones, nil),
+		Socials:        normalizeSocialLinks(payload.Socials),
+		Address:        trimPointer(payload.Address),
+		ContactFormURL: trimPointer(payload.ContactFormURL),
+		AboutSummary:   trimPointer(payload.AboutSummary),
+		Metadata:       metadata,
+	}
+
+	if err := s.repo.UpsertEnrichedContacts(ctx, buildWebsiteEnrichedContact(companyID, payload)); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertEnrichment(ctx, enrichment); err != nil {
+		return nil, err
+	}
+	s.storeCompanyImages(ctx, companyID, payload)
+	return enrichment, nil
+}
+
+func (s *CompaniesService) cacheUsable(cached *entity.DomainEnrichment, depth string) bool {
+	if cached == nil {
+		return false
+	}
+	if s.now().Sub(cached.UpdatedAt) > s.cacheTTL {
+		return false
+	}
+	return depthRank(cached.Depth) >= depthRank(depth)
+}
+
+// storeDomainEnrichment records a fresh worker result under its domain; failures are logged only
+// because the company enrichment itself has already been saved.
+func (s *CompaniesService) storeDomainEnrichment(ctx context.Context, companyID uuid.UUID, payload dto.EnrichResultRequest, enrichment *entity.CompanyEnrichment) {
+	if s.cache == nil {
+		return
+	}
+	domain, ok := cacheDomain(payload.Website)
+	if !ok {
+		return
+	}
+
+	depth := strings.ToLower(strings.TrimSpace(payload.Depth))
+	if _, known := enrichDepthRank[depth]; !known {
+		depth = EnrichDepthStandard
+	}
+	record := &entity.DomainEnrichment{
+		Domain:          domain,
+		SourceCompanyID: &companyID,
+		Depth:           depth,
+		Emails:          enrichment.Emails,
+		Phones:          enrichment.Phones,
+		Socials:         enrichment.Socials,
+		Address:         enrichment.Address,
+		ContactFormURL:  enrichment.ContactFormURL,
+		AboutSummary:    enrichment.AboutSummary,
+		Metadata:        enrichment.Metadata,
+	}
+	if err := s.cache.UpsertDomainEnrichment(ctx, record); err != nil {
+		log.Printf("failed to cache enrichment for domain %s: %v", domain, err)
+	}
+}
+
+func depthRank(depth string) int {
+	if rank, ok := enrichDepthRank[strings.ToLower(strings.TrimSpace(depth))]; ok {
+		return rank
+	}
+	return enrichDepthRank[EnrichDepthStandard]
+}
+
+// cacheDomain is the cache key of a website: its canonical domain, unless that is a shared host
+// such as facebook.com, whose pages belong to unrelated businesses.
+func cacheDomain(raw string) (string, bool) {
+	domain, ok := canonicalDomain(raw)
+	if !ok || slices.Contains(sharedHostDomains, domain) {
+		return "", false
+	}
+	return domain, true
+}
+
+// canonicalDomain reduces a website to the lowercase ASCII host without "www." or port,
+// so http://www.Example.com:8080/about and example.com share a cache entry.
+func canonicalDomain(raw string) (string, bool) {
+	u, err := sanitizeURL(raw)
+	if err != nil {
+		return "", false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if host == "" {
+		return "", false
+	}
+	ascii, err := idnaProfile.ToASCII(host)
+	if err != nil {
+		return "", false
+	}
+	return ascii, true
+}
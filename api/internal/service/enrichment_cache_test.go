@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockEnrichmentCache struct {
+	records map[string]*entity.DomainEnrichment
+	err     error
+}
+
+func (m *mockEnrichmentCache) UpsertDomainEnrichment(ctx context.Context, record *entity.DomainEnrichment) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.records == nil {
+		m.records = make(map[string]*entity.DomainEnrichment)
+	}
+	m.records[record.Domain] = record
+	return nil
+}
+
+func (m *mockEnrichmentCache) GetDomainEnrichment(ctx context.Context, domain string) (*entity.DomainEnrichment, error) {
+	if record, ok := m.records[domain]; ok {
+		return record, nil
+	}
+	return nil, repository.ErrDomainEnrichmentNotFound
+}
+
+func TestCanonicalDomain(t *testing.T) {
+	cases := map[string]string{
+		"https://www.Example.com/about": "example.com",
+		"example.com":                   "example.com",
+		"http://shop.example.com:8080":  "shop.example.com",
+		"https://bücher.de":             "xn--bcher-kva.de",
+	}
+	for input, want := range cases {
+		got, ok := canonicalDomain(input)
+		if !ok || got != want {
+			t.Fatalf("canonicalDomain(%q) = %q, %v; want %q", input, got, ok, want)
+		}
+	}
+	if _, ok := canonicalDomain("   "); ok {
+		t.Fatalf("expected empty input to be rejected")
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_StoresDomainCache(t *testing.T) {
+	cache := &mockEnrichmentCache{}
+	repo := &mockCompaniesRepository{
+		enrich:         func(ctx context.Context, enrichment *entity.CompanyEnrichment) error { return nil },
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithEnrichmentCache(cache, time.Hour))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb",
+		Emails:    []string{"Info@Example.com"},
+		Website:   "https://www.example.com/contact",
+		Depth:     "deep",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	record := cache.records["example.com"]
+	if record == nil {
+		t.Fatalf("expected domain cache entry, got %+v", cache.records)
+	}
+	if record.Depth != EnrichDepthDeep || len(record.Emails) != 1 || record.Emails[0] != "info@example.com" {
+		t.Fatalf("unexpected cache record: %+v", record)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_IgnoresCacheErrors(t *testing.T) {
+	repo := &mockCompaniesRepository{
+		enrich:         func(ctx context.Context, enrichment *entity.CompanyEnrichment) error { return nil },
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithEnrichmentCache(&mockEnrichmentCache{err: errors.New("down")}, time.Hour))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb",
+		Website:   "https://example.com",
+	})
+	if err != nil {
+		t.Fatalf("expected cache failure to be ignored, got %v", err)
+	}
+}
+
+func TestCompaniesService_ReuseCachedEnrichment(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	source := uuid.MustParse("cccccccc-cccc-cccc-cccc-cccccccccccc")
+	target := "dddddddd-dddd-dddd-dddd-dddddddddddd"
+
+	newService := func(record *entity.DomainEnrichment) (*CompaniesService, *entity.CompanyEnrichment) {
+		saved := &entity.CompanyEnrichment{}
+		repo := &mockCompaniesRepository{
+			enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+				*saved = *enrichment
+				return nil
+			},
+			upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+		}
+		cache := &mockEnrichmentCache{records: map[string]*entity.DomainEnrichment{}}
+		if record != nil {
+			cache.records[record.Domain] = record
+		}
+		svc := NewCompaniesService(repo, WithEnrichmentCache(cache, 24*time.Hour))
+		svc.now = func() time.Time { return now }
+		return svc, saved
+	}
+
+	address := "Jl. Sudirman 1"
+	record := &entity.DomainEnrichment{
+		Domain:          "example.com",
+		SourceCompanyID: &source,
+		Depth:           EnrichDepthStandard,
+		Emails:          []string{"info@example.com"},
+		Address:         &address,
+		UpdatedAt:       now.Add(-time.Hour),
+	}
+
+	t.Run("hit", func(t *testing.T) {
+		svc, saved := newService(record)
+		result, err := svc.ReuseCachedEnrichment(context.Background(), target, "http://www.example.com/branch-2", EnrichDepthBasic)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil {
+			t.Fatalf("expected cached enrichment")
+		}
+		if saved.CompanyID.String() != target || len(saved.Emails) != 1 {
+			t.Fatalf("expected enrichment copied to target, got %+v", saved)
+		}
+		if saved.Address != nil {
+			t.Fatalf("expected the source branch's address not to be copied, got %q", *saved.Address)
+		}
+		if saved.Metadata["cached_from_domain"] != "example.com" || saved.Metadata["source_company_id"] != source.String() {
+			t.Fatalf("unexpected metadata: %+v", saved.Metadata)
+		}
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		svc, _ := newService(nil)
+		result, err := svc.ReuseCachedEnrichment(context.Background(), target, "https://example.com", EnrichDepthBasic)
+		if err != nil || result != nil {
+			t.Fatalf("expected miss, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		stale := *record
+		stale.UpdatedAt = now.Add(-48 * time.Hour)
+		svc, _ := newService(&stale)
+		result, err := svc.ReuseCachedEnrichment(context.Background(), target, "https://example.com", EnrichDepthBasic)
+		if err != nil || result != nil {
+			t.Fatalf("expected stale entry to be skipped, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("shallower than requested", func(t *testing.T) {
+		svc, _ := newService(record)
+		result, err := svc.ReuseCachedEnrichment(context.Background(), target, "https://example.com", EnrichDepthDeep)
+		if err != nil || result != nil {
+			t.Fatalf("expected shallow entry to be skipped, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("invalid company id", func(t *testing.T) {
+		svc, _ := newService(record)
+		if _, err := svc.ReuseCachedEnrichment(context.Background(), "bad", "https://example.com", EnrichDepthBasic); !errors.Is(err, ErrInvalidCompanyID) {
+			t.Fatalf("expected ErrInvalidCompanyID, got %v", err)
+		}
+	})
+}
+
+func TestCompaniesService_EnrichmentCacheSkipsSharedHosts(t *testing.T) {
+	cache := &mockEnrichmentCache{}
+	var saved []*entity.CompanyEnrichment
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			saved = append(saved, enrichment)
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithEnrichmentCache(cache, time.Hour))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb",
+		Emails:    []string{"warung@example.com"},
+		Website:   "https://www.facebook.com/warungbudi",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cache.records) != 0 {
+		t.Fatalf("expected facebook.com not to be cached, got %+v", cache.records)
+	}
+
+	cache.records = map[string]*entity.DomainEnrichment{"facebook.com": {
+		Domain:    "facebook.com",
+		Depth:     EnrichDepthStandard,
+		Emails:    []string{"warung@example.com"},
+		UpdatedAt: time.Now(),
+	}}
+	result, err := svc.ReuseCachedEnrichment(context.Background(), "dddddddd-dddd-dddd-dddd-dddddddddddd", "https://facebook.com/bengkelsari", EnrichDepthBasic)
+	if err != nil || result != nil {
+		t.Fatalf("expected another facebook.com page not to reuse contacts, got %+v, %v", result, err)
+	}
+	if len(saved) != 1 {
+		t.Fatalf("expected only the first company enriched, got %d", len(saved))
+	}
+}
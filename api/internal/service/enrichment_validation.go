@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+)
+
+// Enrichment validation modes accepted by WithEnrichmentValidation.
+const (
+	// EnrichValidationLenient drops rejected values and stores the rest of the payload.
+	EnrichValidationLenient = "lenient"
+	// EnrichValidationStrict rejects the whole payload when any value fails validation.
+	EnrichValidationStrict = "strict"
+	// EnrichValidationOff stores payloads as sent, only trimmed and de-duplicated.
+	EnrichValidationOff = "off"
+)
+
+// EnrichmentValidationError lists the enrichment fields rejected in strict mode.
+type EnrichmentValidationError struct {
+	Fields map[string]string
+}
+
+// Error implements the error interface.
+func (e EnrichmentValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return "invalid enrichment payload: " + strings.Join(fields, ", ")
+}
+
+// WithEnrichmentValidation cleans worker payloads with processor before they are stored: emails
+// need an MX record, phones are normalised to E.164, social links must point at an allowed domain
+// and resolve, and URLs lose their tracking parameters. mode decides whether rejected values are
+// dropped (lenient) or fail the request (strict); EnrichValidationOff or a nil processor disables it.
+func WithEnrichmentValidation(processor *DataProcessor, mode string) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		if mode == EnrichValidationOff {
+			processor = nil
+		}
+		s.processor = processor
+		s.strictEnrichment = mode == EnrichValidationStrict
+	}
+}
+
+// sanitizeEnrichment returns payload with every value that failed validation removed, and the
+// rejected values per field. Phones without a country code are parsed in region, or in the
+// processor default when region is empty.
+func (p *DataProcessor) sanitizeEnrichment(ctx context.Context, payload dto.EnrichResultRequest, region string) (dto.EnrichResultRequest, map[string]string) {
+	if region == "" {
+		region = p.DefaultRegion
+	}
+	ctx, cancel := p.withCheckDeadline(ctx)
+	defer cancel()
+	rejected := make(map[string]string)
+	reject := func(field, reason string, values []string) {
+		if len(values) > 0 {
+			rejected[field] = fmt.Sprintf("%s: %s", reason, strings.Join(values, ", "))
+		}
+	}
+
+	emails := normalizeStringSlice(payload.Emails, strings.ToLower)
+	payload.Emails = p.cleanEmails(ctx, emails)
+	reject("emails", "invalid or without mail server", missingFrom(emails, payload.Emails))
+
+	var badPhones []string
+	phones := make([]string, 0, len(payload.Phones))
+	for _, raw := range normalizeStringSlice(payload.Phones, nil) {
+		if phone := normalizePhone(raw, region); phone != "" {
+			phones = append(phones, phone)
+		} else {
+			badPhones = append(badPhones, raw)
+		}
+	}
+	payload.Phones = normalizeStringSlice(phones, nil)
+	reject("phones", "not valid phone numbers", badPhones)
+
+	type socialCandidate struct{ key, platform, raw, link string }
+	var (
+		candidates []socialCandidate
+		links      []string
+	)
+	for key, raws := range normalizeSocialLinks(payload.Socials) {
+		platform := canonicalSocialKey(key)
+		if platform == "" {
+			reject("socials."+key, "unsupported platform", raws)
+			continue
+		}
+		for _, raw := range raws {
+			link, _, ok := canonicalSocialLink(platform, raw)
+			if ok {
+				links = append(links, link)
+			}
+			candidates = append(candidates, socialCandidate{key: key, platform: platform, raw: raw, link: link})
+		}
+	}
+	resolves := p.checkAll(ctx, links, p.urlResolves)
+	socials := make(map[string][]string)
+	bad := make(map[string][]string)
+	for _, c := range candidates {
+		if c.link != "" && resolves[c.link] {
+			socials[c.platform] = append(socials[c.platform], c.link)
+		} else {
+			bad[c.key] = append(bad[c.key], c.raw)
+		}
+	}
+	for key, raws := range bad {
+		reject("socials."+key, "not a reachable "+canonicalSocialKey(key)+" link", raws)
+	}
+	payload.Socials = socials
+
+	if form := trimPointer(payload.ContactFormURL); form != nil {
+		payload.ContactFormURL = nil
+		if cleaned := p.sanitizeContactForm(*form); cleaned != "" {
+			payload.ContactFormURL = &cleaned
+		} else {
+			reject("contact_form_url", "invalid url", []string{*form})
+		}
+	}
+
+	if website := strings.TrimSpace(payload.Website); website != "" {
+		if _, err := sanitizeURL(website); err != nil {
+			payload.Website = ""
+			reject("website", "invalid url", []string{website})
+		}
+	}
+	return payload, rejected
+}
+
+// missingFrom returns the values of all that kept does not contain.
+func missingFrom(all, kept []string) []string {
+	keep := make(map[string]bool, len(kept))
+	for _, value := range kept {
+		keep[value] = true
+	}
+	var missing []string
+	for _, value := range all {
+		if !keep[value] {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+func newValidationTestProcessor() *DataProcessor {
+	return NewDataProcessor("US",
+		WithDNSResolver(&stubDNSResolver{mx: map[string]bool{"example.com": true}}),
+		WithHTTPClient(&stubHTTPClient{responses: map[string]int{
+			"HEAD https://www.linkedin.com/company/test": http.StatusOK,
+		}}),
+	)
+}
+
+func dirtyEnrichmentPayload() dto.EnrichResultRequest {
+	form := "company.com/contact?utm_source=ads"
+	return dto.EnrichResultRequest{
+		CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb",
+		Emails:    []string{"Info@Example.com", "sales@nomail.invalid", "not-an-email"},
+		Phones:    []string{"(415) 555-1234", "12"},
+		Socials: map[string][]string{
+			"linkedin_url": {"https://www.linkedin.com/company/test?utm_medium=feed", "https://evil.example/linkedin"},
+			"myspace":      {"https://myspace.com/acme"},
+		},
+		ContactFormURL: &form,
+		Website:        "https://example.com",
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_Lenient(t *testing.T) {
+	var stored *entity.CompanyEnrichment
+	var contact *entity.WebsiteEnrichedContact
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			stored = enrichment
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, c *entity.WebsiteEnrichedContact) error {
+			contact = c
+			return nil
+		},
+	}
+	svc := NewCompaniesService(repo, WithEnrichmentValidation(newValidationTestProcessor(), EnrichValidationLenient))
+
+	if err := svc.SaveEnrichment(context.Background(), dirtyEnrichmentPayload()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stored.Emails) != 1 || stored.Emails[0] != "info@example.com" {
+		t.Fatalf("unexpected emails: %v", stored.Emails)
+	}
+	if len(stored.Phones) != 1 || stored.Phones[0] != "+14155551234" {
+		t.Fatalf("unexpected phones: %v", stored.Phones)
+	}
+	if len(stored.Socials) != 1 || len(stored.Socials["linkedin"]) != 1 || stored.Socials["linkedin"][0] != "https://www.linkedin.com/company/test" {
+		t.Fatalf("unexpected socials: %v", stored.Socials)
+	}
+	if stored.ContactFormURL == nil || *stored.ContactFormURL != "https://company.com/contact" {
+		t.Fatalf("unexpected contact form: %v", stored.ContactFormURL)
+	}
+	if contact.LinkedInURL == nil || *contact.LinkedInURL != "https://www.linkedin.com/company/test" {
+		t.Fatalf("unexpected contact linkedin: %v", contact.LinkedInURL)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_Strict(t *testing.T) {
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			t.Fatal("strict mode must not store an invalid payload")
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, c *entity.WebsiteEnrichedContact) error {
+			t.Fatal("strict mode must not store an invalid payload")
+			return nil
+		},
+	}
+	svc := NewCompaniesService(repo, WithEnrichmentValidation(newValidationTestProcessor(), EnrichValidationStrict))
+
+	err := svc.SaveEnrichment(context.Background(), dirtyEnrichmentPayload())
+	var validationErr EnrichmentValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected EnrichmentValidationError, got %v", err)
+	}
+	for _, field := range []string{"emails", "phones", "socials.linkedin_url", "socials.myspace"} {
+		if validationErr.Fields[field] == "" {
+			t.Fatalf("expected %s to be rejected, got %v", field, validationErr.Fields)
+		}
+	}
+	if _, ok := validationErr.Fields["contact_form_url"]; ok {
+		t.Fatalf("valid contact form should not be rejected: %v", validationErr.Fields)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_StrictAcceptsCleanPayload(t *testing.T) {
+	saved := false
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			saved = true
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, c *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithEnrichmentValidation(newValidationTestProcessor(), EnrichValidationStrict))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb",
+		Emails:    []string{"info@example.com"},
+		Phones:    []string{"+1 415 555 1234"},
+	})
+	if err != nil || !saved {
+		t.Fatalf("expected clean payload to be stored, got %v", err)
+	}
+}
+
+type stubCompanyCountries map[uuid.UUID]string
+
+func (s stubCompanyCountries) GetCompanyCountry(ctx context.Context, companyID uuid.UUID) (string, error) {
+	country, ok := s[companyID]
+	if !ok {
+		return "", repository.ErrCompanyNotFound
+	}
+	return country, nil
+}
+
+func TestCompaniesService_SaveEnrichment_RegionFromCountry(t *testing.T) {
+	var stored *entity.CompanyEnrichment
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			stored = enrichment
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, c *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	companyID := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	svc := NewCompaniesService(repo,
+		WithEnrichmentValidation(NewDataProcessor("ID"), EnrichValidationStrict),
+		WithCompanyCountries(stubCompanyCountries{companyID: "United States"}),
+	)
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: companyID.String(),
+		Phones:    []string{"(415) 555-1234"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stored.Phones) != 1 || stored.Phones[0] != "+14155551234" {
+		t.Fatalf("unexpected phones: %v", stored.Phones)
+	}
+}
+
+func TestPhoneRegionForCountry(t *testing.T) {
+	cases := map[string]string{
+		"Indonesia":        "ID",
+		" united  kingdom": "GB",
+		"USA":              "US",
+		"de":               "DE",
+		"Atlantis":         "",
+		"":                 "",
+	}
+	for country, want := range cases {
+		if got, _ := phoneRegionForCountry(country); got != want {
+			t.Fatalf("phoneRegionForCountry(%q) = %q, want %q", country, got, want)
+		}
+	}
+}
@@ -0,0 +1,551 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	// Schedules run in the timezone of their owner, which the container may have no zoneinfo for.
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/cron"
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/mailer"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/placeattrs"
+)
+
+var (
+	// ErrInvalidExportSchedule is returned when a schedule payload fails validation.
+	ErrInvalidExportSchedule = errors.New("invalid export schedule")
+	// ErrInvalidExportScheduleID is returned when a schedule identifier cannot be parsed as UUID.
+	ErrInvalidExportScheduleID = errors.New("invalid export schedule id")
+	// ErrExportScheduleNotFound indicates the caller has no schedule with the requested id.
+	ErrExportScheduleNotFound = errors.New("export schedule not found")
+	// ErrExportScheduleExists is returned when the caller already has a schedule of that name.
+	ErrExportScheduleExists = errors.New("export schedule already exists")
+	// ErrExportDestinationUnavailable is returned for destinations this instance has no mailer,
+	// bucket or webhook client for.
+	ErrExportDestinationUnavailable = errors.New("export destination not configured")
+)
+
+const (
+	// MaxExportAttachmentBytes caps exports delivered by email; mail relays refuse larger
+	// messages, so bigger exports go to gcs or a webhook.
+	MaxExportAttachmentBytes = 20 << 20
+	// DefaultExportDeliveryLimit is the number of deliveries listed per schedule by default.
+	DefaultExportDeliveryLimit = 20
+	// MaxExportDeliveryLimit caps the deliveries listed per schedule.
+	MaxExportDeliveryLimit = 100
+	// exportScheduleBatch is how many due schedules one poll claims.
+	exportScheduleBatch = 10
+	// exportScheduleLease postpones a claimed schedule while it runs; an instance stopping
+	// mid-run leaves it to be retried once the lease ends.
+	exportScheduleLease = 30 * time.Minute
+)
+
+// exportDestinations lists the accepted schedule destinations.
+var exportDestinations = []string{entity.ExportDestinationEmail, entity.ExportDestinationGCS, entity.ExportDestinationWebhook}
+
+// ExportScheduleService runs saved company exports on cron schedules and delivers the files.
+type ExportScheduleService struct {
+	repo      repository.ExportSchedulesRepository
+	companies *CompaniesService
+	users     repository.UsersRepository
+	// mailer sends email deliveries; nil refuses the email destination.
+	mailer mailer.MessageSender
+	// store receives gcs deliveries under prefix; nil refuses the gcs destination.
+	store  ObjectStore
+	prefix string
+	// webhooks posts webhook deliveries; nil refuses the webhook destination.
+	webhooks *http.Client
+	now      func() time.Time
+}
+
+// ExportScheduleServiceOption customises optional ExportScheduleService collaborators.
+type ExportScheduleServiceOption func(*ExportScheduleService)
+
+// WithExportMailer delivers scheduled exports by email as CSV attachments.
+func WithExportMailer(sender mailer.MessageSender) ExportScheduleServiceOption {
+	return func(s *ExportScheduleService) {
+		s.mailer = sender
+	}
+}
+
+// WithExportStore delivers scheduled exports to object storage under prefix, followed by the
+// target of the schedule.
+func WithExportStore(store ObjectStore, prefix string) ExportScheduleServiceOption {
+	return func(s *ExportScheduleService) {
+		s.store = store
+		s.prefix = strings.Trim(prefix, "/")
+	}
+}
+
+// WithExportWebhooks delivers scheduled exports by posting them to webhook URLs with client, which
+// should go through the outbound policy.
+func WithExportWebhooks(client *http.Client) ExportScheduleServiceOption {
+	return func(s *ExportScheduleService) {
+		s.webhooks = client
+	}
+}
+
+// NewExportScheduleService builds an ExportScheduleService exporting through companies. users
+// supplies the role and email of schedule owners, which set the row cap and stamp of each export.
+func NewExportScheduleService(repo repository.ExportSchedulesRepository, companies *CompaniesService, users repository.UsersRepository, opts ...ExportScheduleServiceOption) *ExportScheduleService {
+	svc := &ExportScheduleService{repo: repo, companies: companies, users: users, now: time.Now}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// List returns the schedules of a user.
+func (s *ExportScheduleService) List(ctx context.Context, userID string) ([]entity.ExportSchedule, error) {
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+	return s.repo.List(ctx, owner)
+}
+
+// Create saves a new schedule for a user; it first runs at the next time its cron expression fires.
+func (s *ExportScheduleService) Create(ctx context.Context, userID string, req dto.ExportScheduleRequest) (*entity.ExportSchedule, error) {
+	schedule, err := s.buildExportSchedule(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, schedule); err != nil {
+		return nil, mapExportScheduleError(err)
+	}
+	return schedule, nil
+}
+
+// Update replaces a schedule of a user and sets its next run from the new cron expression.
+func (s *ExportScheduleService) Update(ctx context.Context, userID, idRaw string, req dto.ExportScheduleRequest) (*entity.ExportSchedule, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidExportScheduleID
+	}
+	schedule, err := s.buildExportSchedule(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	schedule.ID = id
+	if err := s.repo.Update(ctx, schedule); err != nil {
+		return nil, mapExportScheduleError(err)
+	}
+	return schedule, nil
+}
+
+// Delete removes a schedule of a user with its deliveries.
+func (s *ExportScheduleService) Delete(ctx context.Context, userID, idRaw string) error {
+	owner, id, err := parseExportScheduleIDs(userID, idRaw)
+	if err != nil {
+		return err
+	}
+	return mapExportScheduleError(s.repo.Delete(ctx, owner, id))
+}
+
+// Deliveries returns the latest deliveries of a schedule of a user, newest first.
+func (s *ExportScheduleService) Deliveries(ctx context.Context, userID, idRaw string, limit int) ([]entity.ExportDelivery, error) {
+	schedule, err := s.get(ctx, userID, idRaw)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultExportDeliveryLimit
+	}
+	if limit > MaxExportDeliveryLimit {
+		limit = MaxExportDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, schedule.ID, limit)
+}
+
+// RunNow runs a schedule of a user at once and returns its delivery. The scheduled runs are kept.
+func (s *ExportScheduleService) RunNow(ctx context.Context, userID, idRaw string) (*entity.ExportDelivery, error) {
+	schedule, err := s.get(ctx, userID, idRaw)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, *schedule), nil
+}
+
+// RunDue claims the schedules that are due, runs them one after another and sets their next run.
+// It returns how many ran; failed deliveries are recorded and count as runs.
+func (s *ExportScheduleService) RunDue(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	schedules, err := s.repo.ClaimDue(ctx, now, exportScheduleLease, exportScheduleBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, schedule := range schedules {
+		s.run(ctx, schedule)
+		if err := s.repo.Reschedule(ctx, schedule.ID, now, nextExportRun(schedule, s.now())); err != nil {
+			log.Printf("failed to reschedule export schedule %s: %v", schedule.ID, err)
+		}
+	}
+	return len(schedules), nil
+}
+
+// Run runs due schedules every interval until ctx is cancelled.
+func (s *ExportScheduleService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to run export schedules: %v", err)
+			}
+		}
+	}
+}
+
+func (s *ExportScheduleService) get(ctx context.Context, userID, idRaw string) (*entity.ExportSchedule, error) {
+	owner, id, err := parseExportScheduleIDs(userID, idRaw)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.repo.Get(ctx, owner, id)
+	if err != nil {
+		return nil, mapExportScheduleError(err)
+	}
+	return schedule, nil
+}
+
+// run exports a schedule, delivers the file and records the delivery, failed or not.
+func (s *ExportScheduleService) run(ctx context.Context, schedule entity.ExportSchedule) *entity.ExportDelivery {
+	started := s.now().UTC()
+	delivery := &entity.ExportDelivery{
+		ScheduleID: schedule.ID,
+		FileName:   fmt.Sprintf("companies-%s.csv", started.Format("20060102-1504")),
+		StartedAt:  started,
+	}
+	rows, location, err := s.export(ctx, schedule, delivery.FileName, started)
+	delivery.RowCount = rows
+	delivery.FinishedAt = s.now().UTC()
+	if err != nil {
+		message := err.Error()
+		delivery.Status, delivery.Error = entity.ExportDeliveryFailed, &message
+		log.Printf("export schedule %s failed: %v", schedule.ID, err)
+	} else {
+		delivery.Status, delivery.Location = entity.ExportDeliveryDelivered, &location
+	}
+	if err := s.repo.RecordDelivery(ctx, delivery); err != nil {
+		log.Printf("failed to record delivery of export schedule %s: %v", schedule.ID, err)
+	}
+	return delivery
+}
+
+// export writes the companies matching the schedule to a temporary file as its owner would export
+// them, with the owner's row cap and stamp, and delivers the file. It returns the exported rows and
+// where the file went.
+func (s *ExportScheduleService) export(ctx context.Context, schedule entity.ExportSchedule, fileName string, at time.Time) (int64, string, error) {
+	owner, err := s.users.FindByID(ctx, schedule.UserID)
+	if err != nil {
+		return 0, "", fmt.Errorf("load schedule owner: %w", err)
+	}
+	userID := schedule.UserID.String()
+	var localeQuery dto.ExportLocaleQuery
+	if schedule.TemplateID != nil {
+		localeQuery.Template = schedule.TemplateID.String()
+	}
+	locale, err := s.companies.ResolveExportLocale(ctx, userID, localeQuery)
+	if err != nil {
+		return 0, "", err
+	}
+	export, err := s.companies.PrepareCompanyExport(ctx, exportScheduleListFilter(schedule.Filter, owner.Role), owner.Role)
+	if err != nil {
+		return 0, "", err
+	}
+
+	file, err := os.CreateTemp("", "export-schedule-*.csv")
+	if err != nil {
+		return 0, "", fmt.Errorf("create export file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	stamp := NewExportStamp(userID, owner.Email, at)
+	log.Printf("company data exported by %s for export schedule %s", stamp, schedule.ID)
+	if err := s.companies.WriteCompanyExportCSV(ctx, export, stamp, locale, file); err != nil {
+		return 0, "", err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, "", fmt.Errorf("size export file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, "", fmt.Errorf("rewind export file: %w", err)
+	}
+	location, err := s.deliver(ctx, schedule, fileName, file, size, export.Rows, stamp)
+	return export.Rows, location, err
+}
+
+// deliver sends the export file to the destination of the schedule and returns where it went.
+func (s *ExportScheduleService) deliver(ctx context.Context, schedule entity.ExportSchedule, fileName string, file io.Reader, size, rows int64, stamp ExportStamp) (string, error) {
+	switch schedule.Destination {
+	case entity.ExportDestinationEmail:
+		if s.mailer == nil {
+			return "", ErrExportDestinationUnavailable
+		}
+		if size > MaxExportAttachmentBytes {
+			return "", fmt.Errorf("export of %d bytes exceeds the %d byte email attachment limit; deliver it to gcs or a webhook", size, MaxExportAttachmentBytes)
+		}
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return "", fmt.Errorf("read export file: %w", err)
+		}
+		_, err = s.mailer.SendMessage(ctx, mailer.Message{
+			To:      schedule.Target,
+			Subject: "Scheduled export: " + schedule.Name,
+			Text: fmt.Sprintf("The scheduled export %q of %d companies is attached.\n\nExport %s at %s.",
+				schedule.Name, rows, stamp.ID, stamp.At.Format(time.RFC3339)),
+			Attachments: []mailer.Attachment{{Filename: fileName, ContentType: "text/csv", Content: content}},
+		})
+		return schedule.Target, err
+	case entity.ExportDestinationGCS:
+		if s.store == nil {
+			return "", ErrExportDestinationUnavailable
+		}
+		name := path.Join(s.prefix, schedule.Target, fileName)
+		if err := s.store.Upload(ctx, name, "text/csv", file); err != nil {
+			return "", err
+		}
+		return s.store.URI(name), nil
+	case entity.ExportDestinationWebhook:
+		if s.webhooks == nil {
+			return "", ErrExportDestinationUnavailable
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.Target, file)
+		if err != nil {
+			return "", fmt.Errorf("build export webhook request: %w", err)
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "text/csv")
+		req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+		req.Header.Set("X-Export-ID", stamp.ID.String())
+		req.Header.Set("X-Export-Schedule-ID", schedule.ID.String())
+		resp, err := s.webhooks.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("post export webhook: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return "", fmt.Errorf("export webhook answered with status %d", resp.StatusCode)
+		}
+		return schedule.Target, nil
+	default:
+		return "", fmt.Errorf("%w: unknown destination %q", ErrInvalidExportSchedule, schedule.Destination)
+	}
+}
+
+func (s *ExportScheduleService) buildExportSchedule(ctx context.Context, userID string, req dto.ExportScheduleRequest) (*entity.ExportSchedule, error) {
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidExportSchedule)
+	}
+
+	spec, err := cron.Parse(req.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExportSchedule, err)
+	}
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidExportSchedule, timezone)
+	}
+	next := spec.Next(s.now().In(location))
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: cron expression never fires", ErrInvalidExportSchedule)
+	}
+
+	destination := strings.ToLower(strings.TrimSpace(req.Destination))
+	if !slices.Contains(exportDestinations, destination) {
+		return nil, fmt.Errorf("%w: destination must be email, gcs or webhook", ErrInvalidExportSchedule)
+	}
+	target, err := s.exportTarget(destination, req.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := normalizeExportScheduleFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var templateID *uuid.UUID
+	if req.TemplateID != nil && strings.TrimSpace(*req.TemplateID) != "" {
+		// Resolving the locale checks the template belongs to the caller.
+		if _, err := s.companies.ResolveExportLocale(ctx, userID, dto.ExportLocaleQuery{Template: *req.TemplateID}); err != nil {
+			return nil, err
+		}
+		parsed := uuid.MustParse(strings.TrimSpace(*req.TemplateID))
+		templateID = &parsed
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return &entity.ExportSchedule{
+		UserID:      owner,
+		Name:        name,
+		Filter:      filter,
+		TemplateID:  templateID,
+		Destination: destination,
+		Target:      target,
+		Cron:        strings.Join(strings.Fields(req.Cron), " "),
+		Timezone:    location.String(),
+		Enabled:     enabled,
+		NextRunAt:   next.UTC(),
+	}, nil
+}
+
+// exportTarget checks the target of a destination this instance can deliver to: an email address,
+// an object prefix without .. segments or an http(s) URL.
+func (s *ExportScheduleService) exportTarget(destination, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch destination {
+	case entity.ExportDestinationEmail:
+		if s.mailer == nil {
+			return "", ErrExportDestinationUnavailable
+		}
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return "", fmt.Errorf("%w: target must be an email address", ErrInvalidExportSchedule)
+		}
+		return addr.Address, nil
+	case entity.ExportDestinationGCS:
+		if s.store == nil {
+			return "", ErrExportDestinationUnavailable
+		}
+		prefix := strings.Trim(raw, "/")
+		if slices.Contains(strings.Split(prefix, "/"), "..") {
+			return "", fmt.Errorf("%w: target must not contain .. segments", ErrInvalidExportSchedule)
+		}
+		return prefix, nil
+	default:
+		if s.webhooks == nil {
+			return "", ErrExportDestinationUnavailable
+		}
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return "", fmt.Errorf("%w: target must be an http or https URL", ErrInvalidExportSchedule)
+		}
+		return parsed.String(), nil
+	}
+}
+
+// normalizeExportScheduleFilter trims the saved filter and checks the values the listing would
+// otherwise ignore.
+func normalizeExportScheduleFilter(filter entity.ExportScheduleFilter) (entity.ExportScheduleFilter, error) {
+	filter.Q = strings.TrimSpace(filter.Q)
+	filter.TypeBusiness = strings.TrimSpace(filter.TypeBusiness)
+	filter.City = strings.TrimSpace(filter.City)
+	filter.Country = strings.TrimSpace(filter.Country)
+	filter.Sort = strings.TrimSpace(filter.Sort)
+	filter.Website = strings.ToLower(strings.TrimSpace(filter.Website))
+	if filter.Website != "" && !slices.Contains(repository.WebsiteStatusBuckets, filter.Website) {
+		return filter, fmt.Errorf("%w: filter.website must be available or missing", ErrInvalidExportSchedule)
+	}
+	if filter.Limit < 0 {
+		return filter, fmt.Errorf("%w: filter.limit must not be negative", ErrInvalidExportSchedule)
+	}
+	categories := make([]string, 0, len(filter.Categories))
+	for _, raw := range filter.Categories {
+		if category := placeattrs.NormalizeCategory(raw); category != "" {
+			categories = append(categories, category)
+		}
+	}
+	filter.Categories = categories
+	return filter, nil
+}
+
+// exportScheduleListFilter turns a saved filter into the listing filter of an export by role;
+// like GET /exports/companies, only admins export beyond the latest scrape run.
+func exportScheduleListFilter(filter entity.ExportScheduleFilter, role string) dto.ListFilter {
+	listFilter := dto.ListFilter{
+		Q:                 filter.Q,
+		TypeBusiness:      filter.TypeBusiness,
+		City:              filter.City,
+		Country:           filter.Country,
+		Categories:        filter.Categories,
+		MinRating:         filter.MinRating,
+		MaxRating:         filter.MaxRating,
+		MinReviews:        filter.MinReviews,
+		WebsiteStatus:     filter.Website,
+		HasPhone:          filter.HasPhone,
+		Sort:              filter.Sort,
+		Limit:             filter.Limit,
+		IncludeSuppressed: filter.IncludeSuppressed,
+	}
+	if role != "admin" {
+		listFilter.LatestRunOnly = true
+		if listFilter.Sort == "" {
+			listFilter.Sort = "recent"
+		}
+	}
+	return listFilter
+}
+
+// nextExportRun returns when a schedule runs next after now, in its timezone. Schedules are
+// checked when saved, so a timezone that no longer loads falls back to UTC.
+func nextExportRun(schedule entity.ExportSchedule, now time.Time) time.Time {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	spec, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return now.Add(24 * time.Hour).UTC()
+	}
+	next := spec.Next(now.In(location))
+	if next.IsZero() {
+		return now.Add(24 * time.Hour).UTC()
+	}
+	return next.UTC()
+}
+
+func parseExportScheduleIDs(userID, idRaw string) (uuid.UUID, uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidExportScheduleID
+	}
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid user id: %w", err)
+	}
+	return owner, id, nil
+}
+
+func mapExportScheduleError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrExportScheduleNotFound):
+		return ErrExportScheduleNotFound
+	case errors.Is(err, repository.ErrExportScheduleDuplicate):
+		return ErrExportScheduleExists
+	default:
+		return err
+	}
+}
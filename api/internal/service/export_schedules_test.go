@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubExportSchedules struct {
+	schedules   []entity.ExportSchedule
+	deliveries  []entity.ExportDelivery
+	rescheduled map[uuid.UUID]time.Time
+}
+
+func (s *stubExportSchedules) List(ctx context.Context, userID uuid.UUID) ([]entity.ExportSchedule, error) {
+	return s.schedules, nil
+}
+
+func (s *stubExportSchedules) Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportSchedule, error) {
+	for _, schedule := range s.schedules {
+		if schedule.ID == id && schedule.UserID == userID {
+			return &schedule, nil
+		}
+	}
+	return nil, repository.ErrExportScheduleNotFound
+}
+
+func (s *stubExportSchedules) Create(ctx context.Context, schedule *entity.ExportSchedule) error {
+	for _, existing := range s.schedules {
+		if existing.UserID == schedule.UserID && existing.Name == schedule.Name {
+			return repository.ErrExportScheduleDuplicate
+		}
+	}
+	schedule.ID = uuid.New()
+	s.schedules = append(s.schedules, *schedule)
+	return nil
+}
+
+func (s *stubExportSchedules) Update(ctx context.Context, schedule *entity.ExportSchedule) error {
+	return repository.ErrExportScheduleNotFound
+}
+
+func (s *stubExportSchedules) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return repository.ErrExportScheduleNotFound
+}
+
+func (s *stubExportSchedules) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.ExportSchedule, error) {
+	var due []entity.ExportSchedule
+	for i, schedule := range s.schedules {
+		if schedule.Enabled && !schedule.NextRunAt.After(now) {
+			s.schedules[i].NextRunAt = now.Add(lease)
+			due = append(due, schedule)
+		}
+	}
+	return due, nil
+}
+
+func (s *stubExportSchedules) Reschedule(ctx context.Context, id uuid.UUID, ranAt, next time.Time) error {
+	if s.rescheduled == nil {
+		s.rescheduled = make(map[uuid.UUID]time.Time)
+	}
+	s.rescheduled[id] = next
+	return nil
+}
+
+func (s *stubExportSchedules) RecordDelivery(ctx context.Context, delivery *entity.ExportDelivery) error {
+	delivery.ID = uuid.New()
+	s.deliveries = append(s.deliveries, *delivery)
+	return nil
+}
+
+func (s *stubExportSchedules) ListDeliveries(ctx context.Context, scheduleID uuid.UUID, limit int) ([]entity.ExportDelivery, error) {
+	return s.deliveries, nil
+}
+
+func newTestExportScheduleService(repo *stubExportSchedules, now time.Time, opts ...ExportScheduleServiceOption) *ExportScheduleService {
+	exports := &stubCompanyExports{companies: []entity.Company{{ID: uuid.New(), Company: "Acme"}, {ID: uuid.New(), Company: "Beta"}}}
+	companies := NewCompaniesService(&mockCompaniesRepository{}, WithExports(exports, nil))
+	users := &mockUsersRepository{findByID: func(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+		return &entity.User{ID: id, Email: "owner@example.com", Role: "user"}, nil
+	}}
+	svc := NewExportScheduleService(repo, companies, users, opts...)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestExportScheduleService_Create(t *testing.T) {
+	repo := &stubExportSchedules{}
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	svc := newTestExportScheduleService(repo, now, WithExportMailer(&stubMessageSender{}))
+	userID := uuid.NewString()
+
+	schedule, err := svc.Create(context.Background(), userID, dto.ExportScheduleRequest{
+		Name:        "  Weekly   Jakarta ",
+		Filter:      entity.ExportScheduleFilter{City: " Jakarta ", Website: "Missing"},
+		Destination: "Email",
+		Target:      "Sales <sales@example.com>",
+		Cron:        "0 7 * * 1",
+		Timezone:    "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedule.Name != "Weekly Jakarta" || schedule.Target != "sales@example.com" || schedule.Filter.City != "Jakarta" || schedule.Filter.Website != "missing" || !schedule.Enabled {
+		t.Fatalf("expected a normalised schedule, got %+v", schedule)
+	}
+	// Monday 07:00 in Jakarta is 00:00 UTC, already past on Monday 10:00 UTC.
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !schedule.NextRunAt.Equal(want) {
+		t.Fatalf("expected the next run at %s, got %s", want, schedule.NextRunAt)
+	}
+
+	if _, err := svc.Create(context.Background(), userID, dto.ExportScheduleRequest{Name: "weekly jakarta", Destination: "email", Target: "a@example.com", Cron: "@daily"}); err != nil {
+		t.Fatalf("expected names to differ by case, got %v", err)
+	}
+	if _, err := svc.Create(context.Background(), userID, dto.ExportScheduleRequest{Name: "Weekly Jakarta", Destination: "email", Target: "a@example.com", Cron: "@daily"}); !errors.Is(err, ErrExportScheduleExists) {
+		t.Fatalf("expected ErrExportScheduleExists, got %v", err)
+	}
+
+	invalid := []dto.ExportScheduleRequest{
+		{Destination: "email", Target: "a@example.com", Cron: "@daily"},
+		{Name: "x", Destination: "ftp", Target: "a@example.com", Cron: "@daily"},
+		{Name: "x", Destination: "email", Target: "not an address", Cron: "@daily"},
+		{Name: "x", Destination: "email", Target: "a@example.com", Cron: "every day"},
+		{Name: "x", Destination: "email", Target: "a@example.com", Cron: "0 0 30 2 *"},
+		{Name: "x", Destination: "email", Target: "a@example.com", Cron: "@daily", Timezone: "Mars/Olympus"},
+		{Name: "x", Destination: "email", Target: "a@example.com", Cron: "@daily", Filter: entity.ExportScheduleFilter{Website: "maybe"}},
+		{Name: "x", Destination: "email", Target: "a@example.com", Cron: "@daily", Filter: entity.ExportScheduleFilter{Limit: -1}},
+	}
+	for _, req := range invalid {
+		if _, err := svc.Create(context.Background(), userID, req); !errors.Is(err, ErrInvalidExportSchedule) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+	for _, destination := range []string{"gcs", "webhook"} {
+		req := dto.ExportScheduleRequest{Name: "x", Destination: destination, Target: "https://example.com/hook", Cron: "@daily"}
+		if _, err := svc.Create(context.Background(), userID, req); !errors.Is(err, ErrExportDestinationUnavailable) {
+			t.Fatalf("expected %s refused without a client, got %v", destination, err)
+		}
+	}
+	if _, err := svc.Update(context.Background(), userID, "nope", dto.ExportScheduleRequest{}); !errors.Is(err, ErrInvalidExportScheduleID) {
+		t.Fatalf("expected ErrInvalidExportScheduleID, got %v", err)
+	}
+	if err := svc.Delete(context.Background(), userID, uuid.NewString()); !errors.Is(err, ErrExportScheduleNotFound) {
+		t.Fatalf("expected ErrExportScheduleNotFound, got %v", err)
+	}
+	if len(repo.schedules) != 2 {
+		t.Fatalf("expected only the valid schedules stored, got %d", len(repo.schedules))
+	}
+}
+
+func TestExportScheduleService_RunDue(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	owner := uuid.New()
+	email := entity.ExportSchedule{ID: uuid.New(), UserID: owner, Name: "Leads", Destination: entity.ExportDestinationEmail, Target: "sales@example.com", Cron: "0 * * * *", Timezone: "UTC", Enabled: true, NextRunAt: now.Add(-time.Minute)}
+	webhook := entity.ExportSchedule{ID: uuid.New(), UserID: owner, Name: "Hook", Destination: entity.ExportDestinationWebhook, Cron: "@daily", Timezone: "Asia/Jakarta", Enabled: true, NextRunAt: now}
+	paused := entity.ExportSchedule{ID: uuid.New(), UserID: owner, Name: "Paused", Destination: entity.ExportDestinationEmail, Target: "sales@example.com", Cron: "@daily", Timezone: "UTC", NextRunAt: now.Add(-time.Hour)}
+
+	var posted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = string(body)
+		if r.Header.Get("X-Export-Schedule-ID") != webhook.ID.String() {
+			t.Errorf("expected the schedule id header, got %q", r.Header.Get("X-Export-Schedule-ID"))
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	webhook.Target = server.URL
+
+	repo := &stubExportSchedules{schedules: []entity.ExportSchedule{email, webhook, paused}}
+	sender := &stubMessageSender{}
+	svc := newTestExportScheduleService(repo, now, WithExportMailer(sender), WithExportWebhooks(server.Client()))
+
+	ran, err := svc.RunDue(context.Background())
+	if err != nil || ran != 2 {
+		t.Fatalf("expected the two due schedules to run, got %d, %v", ran, err)
+	}
+	if len(sender.messages) != 1 || len(sender.messages[0].Attachments) != 1 {
+		t.Fatalf("expected one email with the export attached, got %+v", sender.messages)
+	}
+	attachment := sender.messages[0].Attachments[0]
+	if attachment.Filename != "companies-20260302-1000.csv" || !strings.Contains(string(attachment.Content), "Acme") {
+		t.Fatalf("unexpected attachment %s: %s", attachment.Filename, attachment.Content)
+	}
+	if !strings.Contains(posted, "Beta") {
+		t.Fatalf("expected the export posted to the webhook, got %q", posted)
+	}
+
+	if len(repo.deliveries) != 2 {
+		t.Fatalf("expected a delivery per run, got %+v", repo.deliveries)
+	}
+	delivered, failed := repo.deliveries[0], repo.deliveries[1]
+	if delivered.Status != entity.ExportDeliveryDelivered || delivered.RowCount != 2 || *delivered.Location != "sales@example.com" {
+		t.Fatalf("unexpected email delivery %+v", delivered)
+	}
+	if failed.Status != entity.ExportDeliveryFailed || failed.Error == nil || !strings.Contains(*failed.Error, "503") {
+		t.Fatalf("expected the refused webhook recorded as failed, got %+v", failed)
+	}
+
+	if next := repo.rescheduled[email.ID]; !next.Equal(time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the hourly schedule at 11:00, got %s", next)
+	}
+	// Midnight in Jakarta is 17:00 UTC.
+	if next := repo.rescheduled[webhook.ID]; !next.Equal(time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the daily schedule at Jakarta midnight, got %s", next)
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrInvalidExportLocale is returned when export locale settings are not supported.
+	ErrInvalidExportLocale = errors.New("invalid export locale")
+	// ErrInvalidExportTemplate is returned when a template payload fails validation.
+	ErrInvalidExportTemplate = errors.New("invalid export template")
+	// ErrInvalidExportTemplateID is returned when a template identifier cannot be parsed as UUID.
+	ErrInvalidExportTemplateID = errors.New("invalid export template id")
+	// ErrExportTemplateNotFound indicates the caller has no template with the requested id.
+	ErrExportTemplateNotFound = errors.New("export template not found")
+	// ErrExportTemplateExists is returned when the caller already has a template of that name.
+	ErrExportTemplateExists = errors.New("export template already exists")
+	// ErrExportTemplatesUnavailable is returned when templates are used without a templates repository.
+	ErrExportTemplatesUnavailable = errors.New("export templates unavailable")
+)
+
+// exportDelimiters maps the accepted delimiter settings onto CSV separators.
+var exportDelimiters = map[string]rune{",": ',', ";": ';', "tab": '\t', "|": '|'}
+
+// exportDateFormats maps the accepted date format settings onto time layouts. Dates are in UTC.
+var exportDateFormats = map[string]string{
+	"iso":        time.RFC3339,
+	"yyyy-mm-dd": "2006-01-02",
+	"dd/mm/yyyy": "02/01/2006",
+	"mm/dd/yyyy": "01/02/2006",
+	"dd-mm-yyyy": "02-01-2006",
+	"dd.mm.yyyy": "02.01.2006",
+}
+
+// ExportLocale decides how a CSV export separates fields and renders numbers and dates, so the file
+// opens correctly in spreadsheets set to another locale, e.g. Indonesian Excel expects ";" and ",".
+type ExportLocale struct {
+	Delimiter   string `json:"delimiter"`
+	DecimalMark string `json:"decimal_mark"`
+	DateFormat  string `json:"date_format"`
+	// BOM prefixes the file with a UTF-8 byte order mark so Excel does not read it as ANSI.
+	BOM bool `json:"bom"`
+}
+
+// DefaultExportLocale is the locale of exports that set nothing: plain RFC 4180 CSV.
+var DefaultExportLocale = ExportLocale{Delimiter: ",", DecimalMark: ".", DateFormat: "iso"}
+
+// normalize fills empty settings from DefaultExportLocale and checks the rest.
+func (l ExportLocale) normalize() (ExportLocale, error) {
+	l.Delimiter = strings.ToLower(strings.TrimSpace(l.Delimiter))
+	if l.Delimiter == "\t" {
+		l.Delimiter = "tab"
+	}
+	l.DecimalMark = strings.TrimSpace(l.DecimalMark)
+	l.DateFormat = strings.ToLower(strings.TrimSpace(l.DateFormat))
+	if l.Delimiter == "" {
+		l.Delimiter = DefaultExportLocale.Delimiter
+	}
+	if l.DecimalMark == "" {
+		l.DecimalMark = DefaultExportLocale.DecimalMark
+	}
+	if l.DateFormat == "" {
+		l.DateFormat = DefaultExportLocale.DateFormat
+	}
+
+	if _, ok := exportDelimiters[l.Delimiter]; !ok {
+		return ExportLocale{}, fmt.Errorf("%w: delimiter must be one of , ; tab |", ErrInvalidExportLocale)
+	}
+	if l.DecimalMark != "." && l.DecimalMark != "," {
+		return ExportLocale{}, fmt.Errorf("%w: decimal_mark must be . or ,", ErrInvalidExportLocale)
+	}
+	if l.DecimalMark == l.Delimiter {
+		return ExportLocale{}, fmt.Errorf("%w: decimal_mark must differ from delimiter", ErrInvalidExportLocale)
+	}
+	if _, ok := exportDateFormats[l.DateFormat]; !ok {
+		return ExportLocale{}, fmt.Errorf("%w: date_format must be one of iso, yyyy-mm-dd, dd/mm/yyyy, mm/dd/yyyy, dd-mm-yyyy, dd.mm.yyyy", ErrInvalidExportLocale)
+	}
+	return l, nil
+}
+
+func (l ExportLocale) comma() rune {
+	if comma, ok := exportDelimiters[l.Delimiter]; ok {
+		return comma
+	}
+	return ','
+}
+
+func (l ExportLocale) formatFloat(value float64) string {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if l.DecimalMark == "," {
+		formatted = strings.Replace(formatted, ".", ",", 1)
+	}
+	return formatted
+}
+
+func (l ExportLocale) formatTime(value *time.Time) string {
+	if value == nil || value.IsZero() {
+		return ""
+	}
+	layout, ok := exportDateFormats[l.DateFormat]
+	if !ok {
+		layout = time.RFC3339
+	}
+	return value.UTC().Format(layout)
+}
+
+// WithExportTemplates lets users save export locale settings as named templates.
+func WithExportTemplates(templates repository.ExportTemplatesRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.exportTemplates = templates
+	}
+}
+
+// ListExportTemplates returns the templates saved by a user.
+func (s *CompaniesService) ListExportTemplates(ctx context.Context, userID string) ([]entity.ExportTemplate, error) {
+	if s.exportTemplates == nil {
+		return nil, ErrExportTemplatesUnavailable
+	}
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+	return s.exportTemplates.List(ctx, owner)
+}
+
+// CreateExportTemplate saves a new template for a user.
+func (s *CompaniesService) CreateExportTemplate(ctx context.Context, userID string, req dto.ExportTemplateRequest) (*entity.ExportTemplate, error) {
+	if s.exportTemplates == nil {
+		return nil, ErrExportTemplatesUnavailable
+	}
+	template, err := buildExportTemplate(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exportTemplates.Create(ctx, template); err != nil {
+		return nil, mapExportTemplateError(err)
+	}
+	return template, nil
+}
+
+// UpdateExportTemplate replaces a template of a user.
+func (s *CompaniesService) UpdateExportTemplate(ctx context.Context, userID, idRaw string, req dto.ExportTemplateRequest) (*entity.ExportTemplate, error) {
+	if s.exportTemplates == nil {
+		return nil, ErrExportTemplatesUnavailable
+	}
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidExportTemplateID
+	}
+	template, err := buildExportTemplate(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = id
+	if err := s.exportTemplates.Update(ctx, template); err != nil {
+		return nil, mapExportTemplateError(err)
+	}
+	return template, nil
+}
+
+// DeleteExportTemplate removes a template of a user.
+func (s *CompaniesService) DeleteExportTemplate(ctx context.Context, userID, idRaw string) error {
+	if s.exportTemplates == nil {
+		return ErrExportTemplatesUnavailable
+	}
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return ErrInvalidExportTemplateID
+	}
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+	return mapExportTemplateError(s.exportTemplates.Delete(ctx, owner, id))
+}
+
+// ResolveExportLocale returns the locale of an export by userID: the named template of the user, if
+// any, overridden by the settings given on the request.
+func (s *CompaniesService) ResolveExportLocale(ctx context.Context, userID string, query dto.ExportLocaleQuery) (ExportLocale, error) {
+	locale := DefaultExportLocale
+	if templateID := strings.TrimSpace(query.Template); templateID != "" {
+		if s.exportTemplates == nil {
+			return ExportLocale{}, ErrExportTemplatesUnavailable
+		}
+		id, err := uuid.Parse(templateID)
+		if err != nil {
+			return ExportLocale{}, ErrInvalidExportTemplateID
+		}
+		owner, err := uuid.Parse(userID)
+		if err != nil {
+			return ExportLocale{}, fmt.Errorf("invalid user id: %w", err)
+		}
+		template, err := s.exportTemplates.Get(ctx, owner, id)
+		if err != nil {
+			return ExportLocale{}, mapExportTemplateError(err)
+		}
+		locale = ExportLocale{Delimiter: template.Delimiter, DecimalMark: template.DecimalMark, DateFormat: template.DateFormat, BOM: template.BOM}
+	}
+
+	if query.Delimiter != "" {
+		locale.Delimiter = query.Delimiter
+	}
+	if query.DecimalMark != "" {
+		locale.DecimalMark = query.DecimalMark
+	}
+	if query.DateFormat != "" {
+		locale.DateFormat = query.DateFormat
+	}
+	if query.BOM != nil {
+		locale.BOM = *query.BOM
+	}
+	return locale.normalize()
+}
+
+func buildExportTemplate(userID string, req dto.ExportTemplateRequest) (*entity.ExportTemplate, error) {
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidExportTemplate)
+	}
+	locale, err := ExportLocale{Delimiter: req.Delimiter, DecimalMark: req.DecimalMark, DateFormat: req.DateFormat, BOM: req.BOM}.normalize()
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ExportTemplate{
+		UserID:      owner,
+		Name:        name,
+		Delimiter:   locale.Delimiter,
+		DecimalMark: locale.DecimalMark,
+		DateFormat:  locale.DateFormat,
+		BOM:         locale.BOM,
+	}, nil
+}
+
+func mapExportTemplateError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrExportTemplateNotFound):
+		return ErrExportTemplateNotFound
+	case errors.Is(err, repository.ErrExportTemplateDuplicate):
+		return ErrExportTemplateExists
+	default:
+		return err
+	}
+}
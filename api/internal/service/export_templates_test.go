@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubExportTemplates struct {
+	templates map[uuid.UUID]entity.ExportTemplate
+}
+
+func (s *stubExportTemplates) List(ctx context.Context, userID uuid.UUID) ([]entity.ExportTemplate, error) {
+	var templates []entity.ExportTemplate
+	for _, template := range s.templates {
+		if template.UserID == userID {
+			templates = append(templates, template)
+		}
+	}
+	return templates, nil
+}
+
+func (s *stubExportTemplates) Get(ctx context.Context, userID, id uuid.UUID) (*entity.ExportTemplate, error) {
+	template, ok := s.templates[id]
+	if !ok || template.UserID != userID {
+		return nil, repository.ErrExportTemplateNotFound
+	}
+	return &template, nil
+}
+
+func (s *stubExportTemplates) Create(ctx context.Context, template *entity.ExportTemplate) error {
+	for _, existing := range s.templates {
+		if existing.UserID == template.UserID && existing.Name == template.Name {
+			return repository.ErrExportTemplateDuplicate
+		}
+	}
+	template.ID = uuid.New()
+	s.templates[template.ID] = *template
+	return nil
+}
+
+func (s *stubExportTemplates) Update(ctx context.Context, template *entity.ExportTemplate) error {
+	if _, err := s.Get(ctx, template.UserID, template.ID); err != nil {
+		return err
+	}
+	s.templates[template.ID] = *template
+	return nil
+}
+
+func (s *stubExportTemplates) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	delete(s.templates, id)
+	return nil
+}
+
+func TestCompaniesService_ExportTemplates(t *testing.T) {
+	templates := &stubExportTemplates{templates: map[uuid.UUID]entity.ExportTemplate{}}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExportTemplates(templates))
+	ctx := context.Background()
+	owner, other := uuid.NewString(), uuid.NewString()
+
+	template, err := svc.CreateExportTemplate(ctx, owner, dto.ExportTemplateRequest{Name: "  Excel  ID ", Delimiter: ";", DecimalMark: ",", DateFormat: "DD/MM/YYYY", BOM: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if template.Name != "Excel ID" || template.DateFormat != "dd/mm/yyyy" {
+		t.Fatalf("expected normalised template, got %+v", template)
+	}
+	if _, err := svc.CreateExportTemplate(ctx, owner, dto.ExportTemplateRequest{Name: "Excel ID"}); !errors.Is(err, ErrExportTemplateExists) {
+		t.Fatalf("expected ErrExportTemplateExists, got %v", err)
+	}
+	if _, err := svc.CreateExportTemplate(ctx, owner, dto.ExportTemplateRequest{Name: "Broken", Delimiter: ",", DecimalMark: ","}); !errors.Is(err, ErrInvalidExportLocale) {
+		t.Fatalf("expected ErrInvalidExportLocale, got %v", err)
+	}
+	if _, err := svc.CreateExportTemplate(ctx, owner, dto.ExportTemplateRequest{DateFormat: "yyyy"}); !errors.Is(err, ErrInvalidExportTemplate) {
+		t.Fatalf("expected ErrInvalidExportTemplate, got %v", err)
+	}
+
+	locale, err := svc.ResolveExportLocale(ctx, owner, dto.ExportLocaleQuery{Template: template.ID.String(), DateFormat: "yyyy-mm-dd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ExportLocale{Delimiter: ";", DecimalMark: ",", DateFormat: "yyyy-mm-dd", BOM: true}
+	if locale != want {
+		t.Fatalf("expected template overridden by the query, got %+v", locale)
+	}
+	if _, err := svc.ResolveExportLocale(ctx, other, dto.ExportLocaleQuery{Template: template.ID.String()}); !errors.Is(err, ErrExportTemplateNotFound) {
+		t.Fatalf("expected templates of other users to be hidden, got %v", err)
+	}
+	if err := svc.DeleteExportTemplate(ctx, other, template.ID.String()); !errors.Is(err, ErrExportTemplateNotFound) {
+		t.Fatalf("expected ErrExportTemplateNotFound, got %v", err)
+	}
+	if err := svc.DeleteExportTemplate(ctx, owner, template.ID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	locale, err = NewCompaniesService(&mockCompaniesRepository{}).ResolveExportLocale(ctx, owner, dto.ExportLocaleQuery{Delimiter: "tab"})
+	if err != nil || locale != (ExportLocale{Delimiter: "tab", DecimalMark: ".", DateFormat: "iso"}) {
+		t.Fatalf("expected defaults with the query applied, got %+v, %v", locale, err)
+	}
+}
+
+func TestCompaniesService_WriteCompanyExportCSV_Locale(t *testing.T) {
+	rating, scraped := 4.5, time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	exports := &stubCompanyExports{companies: []entity.Company{
+		{ID: uuid.New(), Company: "Kopi; Kenangan", Rating: &rating, ScrapedAt: &scraped, UpdatedAt: time.Date(2024, 5, 31, 17, 30, 0, 0, time.UTC)},
+	}}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExports(exports, nil))
+	ctx := context.Background()
+	export, err := svc.PrepareCompanyExport(ctx, dto.ListFilter{}, "user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	locale := ExportLocale{Delimiter: ";", DecimalMark: ",", DateFormat: "dd/mm/yyyy", BOM: true}
+	if err := svc.WriteCompanyExportCSV(ctx, export, NewExportStamp(uuid.NewString(), "sales@example.com", time.Now()), locale, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, found := strings.CutPrefix(buf.String(), "\uFEFF")
+	if !found {
+		t.Fatalf("expected a UTF-8 BOM, got %q", buf.String())
+	}
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	row := records[1]
+	if row[1] != "Kopi; Kenangan" || row[5] != "4,5" || row[10] != "01/05/2024" || row[11] != "31/05/2024" {
+		t.Fatalf("unexpected localised row: %q", row)
+	}
+}
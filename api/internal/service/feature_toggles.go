@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// maxFeatureToggleMessage bounds the outage message shown to callers.
+const maxFeatureToggleMessage = 500
+
+var (
+	// ErrUnknownFeature is returned when a toggle targets a feature that cannot be switched off.
+	ErrUnknownFeature = errors.New("unknown feature")
+	// ErrInvalidFeatureToggle is returned when a toggle payload fails validation.
+	ErrInvalidFeatureToggle = errors.New("invalid feature toggle")
+)
+
+// featureLabels names features in the messages returned to callers.
+var featureLabels = map[string]string{
+	entity.FeatureScrape:       "scraping",
+	entity.FeaturePromptSearch: "prompt search",
+	entity.FeatureEnrich:       "enrichment",
+}
+
+// FeatureDisabledError reports that an admin switched the requested feature off.
+type FeatureDisabledError struct {
+	Toggle entity.FeatureToggle
+}
+
+func (e *FeatureDisabledError) Error() string {
+	msg := featureLabels[e.Toggle.Feature] + " is temporarily disabled"
+	if e.Toggle.Message != "" {
+		msg += ": " + e.Toggle.Message
+	}
+	if e.Toggle.ETA != nil {
+		msg += fmt.Sprintf(" (expected back by %s)", e.Toggle.ETA.UTC().Format(time.RFC3339))
+	}
+	return msg
+}
+
+// FeatureToggleService manages the admin kill switches of worker-dependent features.
+type FeatureToggleService struct {
+	repo repository.FeatureTogglesRepository
+}
+
+// NewFeatureToggleService builds a FeatureToggleService.
+func NewFeatureToggleService(repo repository.FeatureTogglesRepository) *FeatureToggleService {
+	return &FeatureToggleService{repo: repo}
+}
+
+// List returns the toggle of every feature; features never toggled are reported enabled.
+func (s *FeatureToggleService) List(ctx context.Context) ([]entity.FeatureToggle, error) {
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byFeature := make(map[string]entity.FeatureToggle, len(stored))
+	for _, toggle := range stored {
+		byFeature[toggle.Feature] = toggle
+	}
+	toggles := make([]entity.FeatureToggle, 0, len(entity.Features))
+	for _, feature := range entity.Features {
+		toggle, ok := byFeature[feature]
+		if !ok {
+			toggle = entity.FeatureToggle{Feature: feature}
+		}
+		toggles = append(toggles, toggle)
+	}
+	return toggles, nil
+}
+
+// Set switches a feature off or back on. Re-enabling clears the outage message and ETA.
+func (s *FeatureToggleService) Set(ctx context.Context, feature string, req dto.FeatureToggleRequest, userID string) (*entity.FeatureToggle, error) {
+	feature = strings.TrimSpace(feature)
+	if !slices.Contains(entity.Features, feature) {
+		return nil, ErrUnknownFeature
+	}
+	toggle := &entity.FeatureToggle{Feature: feature, Disabled: req.Disabled}
+	if req.Disabled {
+		toggle.Message = strings.TrimSpace(req.Message)
+		if len(toggle.Message) > maxFeatureToggleMessage {
+			return nil, fmt.Errorf("%w: message must be at most %d characters", ErrInvalidFeatureToggle, maxFeatureToggleMessage)
+		}
+		toggle.ETA = req.ETA
+	}
+	if id, err := uuid.Parse(userID); err == nil {
+		toggle.UpdatedBy = &id
+	}
+	if err := s.repo.Upsert(ctx, toggle); err != nil {
+		return nil, err
+	}
+	return toggle, nil
+}
+
+// Check returns a *FeatureDisabledError when the feature is switched off. A failed lookup is
+// logged and lets the request through so a database hiccup does not take the feature down.
+func (s *FeatureToggleService) Check(ctx context.Context, feature string) error {
+	toggle, err := s.repo.Get(ctx, feature)
+	if err != nil {
+		log.Printf("failed to check feature toggle %s: %v", feature, err)
+		return nil
+	}
+	if toggle == nil || !toggle.Disabled {
+		return nil
+	}
+	return &FeatureDisabledError{Toggle: *toggle}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type mockFeatureTogglesRepository struct {
+	toggles map[string]entity.FeatureToggle
+	err     error
+}
+
+func (m *mockFeatureTogglesRepository) List(ctx context.Context) ([]entity.FeatureToggle, error) {
+	toggles := make([]entity.FeatureToggle, 0, len(m.toggles))
+	for _, toggle := range m.toggles {
+		toggles = append(toggles, toggle)
+	}
+	return toggles, nil
+}
+
+func (m *mockFeatureTogglesRepository) Get(ctx context.Context, feature string) (*entity.FeatureToggle, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	toggle, ok := m.toggles[feature]
+	if !ok {
+		return nil, nil
+	}
+	return &toggle, nil
+}
+
+func (m *mockFeatureTogglesRepository) Upsert(ctx context.Context, toggle *entity.FeatureToggle) error {
+	m.toggles[toggle.Feature] = *toggle
+	return nil
+}
+
+func TestFeatureToggleService_SetAndCheck(t *testing.T) {
+	repo := &mockFeatureTogglesRepository{toggles: map[string]entity.FeatureToggle{}}
+	svc := NewFeatureToggleService(repo)
+	ctx := context.Background()
+	eta := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	admin := uuid.New()
+
+	toggle, err := svc.Set(ctx, entity.FeatureScrape, dto.FeatureToggleRequest{Disabled: true, Message: " Places provider outage ", ETA: &eta}, admin.String())
+	if err != nil || toggle.Message != "Places provider outage" || toggle.UpdatedBy == nil || *toggle.UpdatedBy != admin {
+		t.Fatalf("unexpected toggle %+v (%v)", toggle, err)
+	}
+
+	var disabled *FeatureDisabledError
+	if err := svc.Check(ctx, entity.FeatureScrape); !errors.As(err, &disabled) {
+		t.Fatalf("expected FeatureDisabledError, got %v", err)
+	}
+	if msg := disabled.Error(); msg != "scraping is temporarily disabled: Places provider outage (expected back by 2025-03-01T12:00:00Z)" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if err := svc.Check(ctx, entity.FeaturePromptSearch); err != nil {
+		t.Fatalf("expected prompt search to stay enabled, got %v", err)
+	}
+
+	toggles, err := svc.List(ctx)
+	if err != nil || len(toggles) != len(entity.Features) || !toggles[0].Disabled || toggles[1].Disabled {
+		t.Fatalf("unexpected toggles %+v (%v)", toggles, err)
+	}
+
+	toggle, err = svc.Set(ctx, entity.FeatureScrape, dto.FeatureToggleRequest{Message: "stale", ETA: &eta}, admin.String())
+	if err != nil || toggle.Message != "" || toggle.ETA != nil {
+		t.Fatalf("expected re-enabling to clear the outage details, got %+v (%v)", toggle, err)
+	}
+	if err := svc.Check(ctx, entity.FeatureScrape); err != nil {
+		t.Fatalf("expected scraping to be enabled again, got %v", err)
+	}
+}
+
+func TestFeatureToggleService_Validation(t *testing.T) {
+	svc := NewFeatureToggleService(&mockFeatureTogglesRepository{toggles: map[string]entity.FeatureToggle{}, err: errors.New("db down")})
+	ctx := context.Background()
+
+	if _, err := svc.Set(ctx, "exports", dto.FeatureToggleRequest{Disabled: true}, ""); !errors.Is(err, ErrUnknownFeature) {
+		t.Fatalf("expected ErrUnknownFeature, got %v", err)
+	}
+	long := dto.FeatureToggleRequest{Disabled: true, Message: strings.Repeat("x", maxFeatureToggleMessage+1)}
+	if _, err := svc.Set(ctx, entity.FeatureEnrich, long, ""); !errors.Is(err, ErrInvalidFeatureToggle) {
+		t.Fatalf("expected ErrInvalidFeatureToggle, got %v", err)
+	}
+	if err := svc.Check(ctx, entity.FeatureEnrich); err != nil {
+		t.Fatalf("expected a failed lookup to let requests through, got %v", err)
+	}
+}
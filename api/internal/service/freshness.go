@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// freshnessWindow is how far back enrichment jobs count towards lag and backlog; older jobs
+	// that never completed were abandoned rather than queued.
+	freshnessWindow         = 7 * 24 * time.Hour
+	defaultFreshnessSegment = 50
+	maxFreshnessSegments    = 200
+	// defaultScrapeFreshFor is how long scraped companies count as fresh for scrape previews.
+	defaultScrapeFreshFor = 7 * 24 * time.Hour
+)
+
+// FreshnessService reports how current the catalogue is, caching results so dashboards can poll it.
+type FreshnessService struct {
+	repo     repository.FreshnessRepository
+	ttl      time.Duration
+	freshFor time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*entity.DataFreshness
+}
+
+// FreshnessServiceOption customises a FreshnessService.
+type FreshnessServiceOption func(*FreshnessService)
+
+// WithScrapeFreshFor sets how long scraped companies count as fresh, so that previews advise
+// against scraping them again; zero or less keeps the default of a week.
+func WithScrapeFreshFor(freshFor time.Duration) FreshnessServiceOption {
+	return func(s *FreshnessService) {
+		if freshFor > 0 {
+			s.freshFor = freshFor
+		}
+	}
+}
+
+// NewFreshnessService builds a new FreshnessService; a ttl of zero disables caching.
+func NewFreshnessService(repo repository.FreshnessRepository, ttl time.Duration, opts ...FreshnessServiceOption) *FreshnessService {
+	s := &FreshnessService{
+		repo:     repo,
+		ttl:      ttl,
+		freshFor: defaultScrapeFreshFor,
+		now:      time.Now,
+		cache:    make(map[string]*entity.DataFreshness),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Freshness returns the last scrape, enrichment lag and queue backlog overall and per city and
+// business type, optionally narrowed to one city and/or business type.
+func (s *FreshnessService) Freshness(ctx context.Context, city, typeBusiness string, limit int) (*entity.DataFreshness, error) {
+	filter := repository.FreshnessFilter{
+		City:         strings.TrimSpace(city),
+		TypeBusiness: strings.TrimSpace(typeBusiness),
+		Limit:        clampLimit(limit, defaultFreshnessSegment, maxFreshnessSegments),
+	}
+	key := fmt.Sprintf("%s|%s|%d", strings.ToLower(filter.City), strings.ToLower(filter.TypeBusiness), filter.Limit)
+	if cached := s.cached(key); cached != nil {
+		return cached, nil
+	}
+
+	now := s.now().UTC()
+	since := now.Add(-freshnessWindow)
+	freshness, err := s.repo.FreshnessTotals(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	if freshness.Segments, err = s.repo.SegmentFreshness(ctx, filter, since); err != nil {
+		return nil, err
+	}
+	freshness.WindowHours = int(freshnessWindow / time.Hour)
+	freshness.GeneratedAt = now
+
+	if s.ttl > 0 {
+		s.mu.Lock()
+		// Filters are caller supplied, so expired entries are dropped to keep the cache bounded.
+		for k, entry := range s.cache {
+			if now.Sub(entry.GeneratedAt) >= s.ttl {
+				delete(s.cache, k)
+			}
+		}
+		s.cache[key] = freshness
+		s.mu.Unlock()
+	}
+	return freshness, nil
+}
+
+func (s *FreshnessService) cached(key string) *entity.DataFreshness {
+	if s.ttl <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	freshness, ok := s.cache[key]
+	if !ok || s.now().Sub(freshness.GeneratedAt) >= s.ttl {
+		return nil
+	}
+	return freshness
+}
+
+// ScrapePreview reports how many companies of the business type in the city and country are
+// already stored and when they were last scraped, recommending whether a scrape is worth it. It
+// is never cached: it is asked right before a scrape that changes the answer.
+func (s *FreshnessService) ScrapePreview(ctx context.Context, typeBusiness, city, country string) (*entity.ScrapePreview, error) {
+	preview, err := s.repo.ScrapeCoverage(ctx, typeBusiness, city, country)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case preview.Companies == 0:
+		preview.Recommendation = entity.ScrapeRecommendMissing
+	case preview.LastScrapedAt == nil:
+		// Imported companies were never scraped, so their age is unknown.
+		preview.Recommendation = entity.ScrapeRecommendStale
+	default:
+		freshUntil := preview.LastScrapedAt.Add(s.freshFor)
+		preview.FreshUntil = &freshUntil
+		preview.Recommendation = entity.ScrapeRecommendStale
+		if s.now().Before(freshUntil) {
+			preview.Recommendation = entity.ScrapeRecommendFresh
+		}
+	}
+	return preview, nil
+}
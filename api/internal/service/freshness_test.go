@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockFreshnessRepository struct {
+	calls    int
+	since    time.Time
+	filter   repository.FreshnessFilter
+	coverage entity.ScrapePreview
+}
+
+func (m *mockFreshnessRepository) FreshnessTotals(ctx context.Context, since time.Time) (*entity.DataFreshness, error) {
+	m.calls++
+	m.since = since
+	return &entity.DataFreshness{PendingEnrichments: 3, QueuedImports: 1}, nil
+}
+
+func (m *mockFreshnessRepository) SegmentFreshness(ctx context.Context, filter repository.FreshnessFilter, since time.Time) ([]entity.SegmentFreshness, error) {
+	m.filter = filter
+	return []entity.SegmentFreshness{{City: "Jakarta", TypeBusiness: "cafe", Companies: 4}}, nil
+}
+
+func (m *mockFreshnessRepository) ScrapeCoverage(ctx context.Context, typeBusiness, city, country string) (*entity.ScrapePreview, error) {
+	preview := m.coverage
+	return &preview, nil
+}
+
+func TestFreshnessService_Freshness(t *testing.T) {
+	now := time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC)
+	repo := &mockFreshnessRepository{}
+	svc := NewFreshnessService(repo, time.Minute)
+	svc.now = func() time.Time { return now }
+
+	freshness, err := svc.Freshness(context.Background(), " Jakarta ", "", 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if freshness.PendingEnrichments != 3 || len(freshness.Segments) != 1 || freshness.WindowHours != 168 || !freshness.GeneratedAt.Equal(now) {
+		t.Fatalf("unexpected freshness: %+v", freshness)
+	}
+	if repo.filter.City != "Jakarta" || repo.filter.Limit != maxFreshnessSegments || !repo.since.Equal(now.Add(-freshnessWindow)) {
+		t.Fatalf("unexpected repository arguments: %+v since %s", repo.filter, repo.since)
+	}
+
+	if _, err := svc.Freshness(context.Background(), "jakarta", "", 1000); err != nil || repo.calls != 1 {
+		t.Fatalf("expected cached freshness, got %d repository calls (%v)", repo.calls, err)
+	}
+	if _, err := svc.Freshness(context.Background(), "Bandung", "", 0); err != nil || repo.calls != 2 {
+		t.Fatalf("expected another filter to be computed, got %d repository calls (%v)", repo.calls, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := svc.Freshness(context.Background(), "Jakarta", "", 1000); err != nil || repo.calls != 3 {
+		t.Fatalf("expected expired cache to recompute, got %d repository calls (%v)", repo.calls, err)
+	}
+	if len(svc.cache) != 1 {
+		t.Fatalf("expected expired entries to be dropped, got %d", len(svc.cache))
+	}
+}
+
+func TestFreshnessService_ScrapePreview(t *testing.T) {
+	now := time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC)
+	yesterday, lastMonth := now.Add(-24*time.Hour), now.Add(-30*24*time.Hour)
+	repo := &mockFreshnessRepository{}
+	svc := NewFreshnessService(repo, time.Minute, WithScrapeFreshFor(72*time.Hour))
+	svc.now = func() time.Time { return now }
+
+	cases := []struct {
+		coverage entity.ScrapePreview
+		want     string
+	}{
+		{entity.ScrapePreview{}, entity.ScrapeRecommendMissing},
+		{entity.ScrapePreview{Companies: 40, LastScrapedAt: &yesterday}, entity.ScrapeRecommendFresh},
+		{entity.ScrapePreview{Companies: 40, LastScrapedAt: &lastMonth}, entity.ScrapeRecommendStale},
+		{entity.ScrapePreview{Companies: 40}, entity.ScrapeRecommendStale},
+	}
+	for _, tc := range cases {
+		repo.coverage = tc.coverage
+		preview, err := svc.ScrapePreview(context.Background(), "cafe", "Bandung", "Indonesia")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if preview.Recommendation != tc.want {
+			t.Fatalf("expected %s for %+v, got %+v", tc.want, tc.coverage, preview)
+		}
+	}
+	repo.coverage = entity.ScrapePreview{Companies: 40, LastScrapedAt: &yesterday}
+	preview, _ := svc.ScrapePreview(context.Background(), "cafe", "Bandung", "Indonesia")
+	if preview.FreshUntil == nil || !preview.FreshUntil.Equal(yesterday.Add(72*time.Hour)) {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+}
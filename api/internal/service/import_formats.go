@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXContentType is the media type of Excel workbooks accepted by the admin upload.
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// delimiterSniffBytes bounds how much of a CSV file is inspected to pick its delimiter.
+const delimiterSniffBytes = 64 << 10
+
+var (
+	utf8BOM  = []byte{0xEF, 0xBB, 0xBF}
+	zipMagic = []byte("PK\x03\x04")
+)
+
+// rowReader yields the rows of an import file, header first; *csv.Reader satisfies it.
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+// importRows is an opened import file.
+type importRows struct {
+	rowReader
+	// decimalComma is set for semicolon-delimited files, which write 4,5 for 4.5.
+	decimalComma bool
+	close        func() error
+}
+
+// isXLSX reports whether the leading bytes of a file belong to an XLSX workbook (a zip archive).
+func isXLSX(head []byte) bool {
+	return bytes.HasPrefix(head, zipMagic)
+}
+
+// openImportRows detects the format of an import file. XLSX workbooks are read from their first
+// sheet; anything else is treated as CSV with an optional UTF-8 BOM and a comma, semicolon or tab
+// delimiter picked from the header line.
+func openImportRows(r io.Reader) (*importRows, error) {
+	buffered := bufio.NewReaderSize(r, delimiterSniffBytes)
+	head, _ := buffered.Peek(len(zipMagic))
+	if isXLSX(head) {
+		return openXLSXRows(buffered)
+	}
+
+	if bom, _ := buffered.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		if _, err := buffered.Discard(len(utf8BOM)); err != nil {
+			return nil, fmt.Errorf("skip utf-8 bom: %w", err)
+		}
+	}
+	// Peek reports ErrBufferFull or EOF for short or long files; the bytes it returns are enough.
+	sample, _ := buffered.Peek(delimiterSniffBytes)
+	delimiter := detectDelimiter(sample)
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = delimiter
+	// Trimming would swallow empty fields of tab-separated files.
+	reader.TrimLeadingSpace = delimiter != '\t'
+	return &importRows{rowReader: reader, decimalComma: delimiter == ';', close: func() error { return nil }}, nil
+}
+
+// detectDelimiter picks the most frequent of comma, semicolon and tab on the first line, ignoring
+// quoted text; ties and lines without any of them fall back to comma.
+func detectDelimiter(sample []byte) rune {
+	counts := map[byte]int{}
+	inQuotes := false
+	for _, b := range sample {
+		if b == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if inQuotes {
+			continue
+		}
+		if b == '\n' || b == '\r' {
+			break
+		}
+		if b == ',' || b == ';' || b == '\t' {
+			counts[b]++
+		}
+	}
+
+	delimiter := byte(',')
+	for _, candidate := range []byte{';', '\t'} {
+		if counts[candidate] > counts[delimiter] {
+			delimiter = candidate
+		}
+	}
+	return rune(delimiter)
+}
+
+// xlsxRows reads the first sheet of a workbook. Rows are padded to the header width because
+// spreadsheets omit trailing empty cells.
+type xlsxRows struct {
+	rows  *excelize.Rows
+	width int
+}
+
+func openXLSXRows(r io.Reader) (*importRows, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, CSVValidationError{Message: "unable to read xlsx workbook"}
+	}
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		file.Close()
+		return nil, CSVValidationError{Message: "xlsx workbook has no sheets"}
+	}
+	rows, err := file.Rows(sheets[0])
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("read xlsx sheet: %w", err)
+	}
+
+	return &importRows{
+		rowReader: &xlsxRows{rows: rows},
+		close: func() error {
+			rows.Close()
+			return file.Close()
+		},
+	}, nil
+}
+
+// Read implements rowReader using raw cell values, so numbers are not reformatted by the
+// workbook's display format (phone numbers would otherwise turn into 6.28E+10).
+func (x *xlsxRows) Read() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, fmt.Errorf("read xlsx row: %w", err)
+		}
+		return nil, io.EOF
+	}
+	cols, err := x.rows.Columns(excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx row: %w", err)
+	}
+	if x.width == 0 {
+		x.width = len(cols)
+	}
+	for len(cols) < x.width {
+		cols = append(cols, "")
+	}
+	return cols, nil
+}
+
+// normalizeDecimalComma rewrites a European number such as "1.234,5" to "1234.5".
+func normalizeDecimalComma(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, ",") {
+		return value
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	cases := map[string]rune{
+		"company,address,phone\n":              ',',
+		"company;address;phone\n":              ';',
+		"company\taddress\tphone\n":            '\t',
+		`"a,b;c";"d,e";f` + "\nx,y,z,w,v\n":    ';',
+		"company\n":                            ',',
+		"company;address,phone,website\r\n;;;": ',',
+	}
+	for sample, want := range cases {
+		if got := detectDelimiter([]byte(sample)); got != want {
+			t.Fatalf("detectDelimiter(%q) = %q, want %q", sample, got, want)
+		}
+	}
+}
+
+func TestCompaniesService_ImportCompaniesCSV_Formats(t *testing.T) {
+	workbook := excelize.NewFile()
+	sheet := workbook.GetSheetName(0)
+	workbook.SetSheetRow(sheet, "A1", &[]any{"company", "address", "phone", "website", "rating", "reviews", "type_business", "city", "country"})
+	workbook.SetSheetRow(sheet, "A2", &[]any{"Acme", "Main St", 62812345678, "", 4.5, 1234, "store", "Gotham", "USA"})
+	// Trailing empty cells are omitted by spreadsheets.
+	workbook.SetSheetRow(sheet, "A3", &[]any{"Beta", "Side St"})
+	xlsx, err := workbook.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("write workbook: %v", err)
+	}
+
+	tests := map[string]struct {
+		input    []byte
+		expected int
+	}{
+		"utf-8 bom": {
+			input:    []byte("\xEF\xBB\xBFcompany,address,phone,website,rating,reviews,type_business,city,country\nAcme,Main St,62812345678,,4.5,1234,store,Gotham,USA\n"),
+			expected: 1,
+		},
+		"semicolon with decimal comma": {
+			input:    []byte("company;address;phone;website;rating;reviews;type_business;city;country\n\"Acme; Inc\";\"Main St, 1\";62812345678;;4,5;1.234;store;Gotham;USA\n"),
+			expected: 1,
+		},
+		"tab separated": {
+			input:    []byte("company\taddress\tphone\twebsite\trating\treviews\ttype_business\tcity\tcountry\nAcme\tMain St\t62812345678\t\t4.5\t1234\tstore\tGotham\tUSA\n"),
+			expected: 1,
+		},
+		"xlsx": {
+			input:    xlsx.Bytes(),
+			expected: 2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var received []repository.BulkUpsertCompanyInput
+			repo := &mockCompaniesRepository{
+				bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+					received = records
+					return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+				},
+			}
+			if _, err := NewCompaniesService(repo).ImportCompaniesCSV(context.Background(), bytes.NewReader(tt.input), repository.ImportModeOverwrite, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(received) != tt.expected {
+				t.Fatalf("expected %d records, got %d", tt.expected, len(received))
+			}
+			rec := received[0]
+			if !strings.HasPrefix(rec.Company, "Acme") || rec.Rating == nil || *rec.Rating != 4.5 || rec.Reviews == nil || *rec.Reviews != 1234 {
+				t.Fatalf("unexpected record payload: %+v", rec)
+			}
+			if rec.Phone == nil || *rec.Phone != "62812345678" || rec.Website != nil {
+				t.Fatalf("unexpected phone/website: %v %v", rec.Phone, rec.Website)
+			}
+		})
+	}
+}
+
+func TestCompaniesService_ImportCompaniesCSV_InvalidWorkbook(t *testing.T) {
+	_, err := NewCompaniesService(&mockCompaniesRepository{}).ImportCompaniesCSV(context.Background(), strings.NewReader("PK\x03\x04not a zip"), repository.ImportModeOverwrite, nil)
+	if err == nil || !strings.Contains(err.Error(), "unable to read xlsx") {
+		t.Fatalf("expected xlsx validation error, got %v", err)
+	}
+}
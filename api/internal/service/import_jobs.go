@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// maxImportRowErrors caps the row errors kept on a job; ErrorCount still counts every one.
+const maxImportRowErrors = 100
+
+var (
+	// ErrInvalidImportJobID is returned when a job identifier cannot be parsed as UUID.
+	ErrInvalidImportJobID = errors.New("invalid import job id")
+	// ErrImportJobNotFound indicates the requested import job does not exist.
+	ErrImportJobNotFound = errors.New("import job not found")
+	// ErrImportQueueFull is returned when every worker is busy and the queue has no room left.
+	ErrImportQueueFull = errors.New("import queue is full")
+)
+
+// ImportJobService runs company imports, and user imports when enabled, in the background. Files
+// are stored before the job is queued, in the upload archive when retention is enabled and in a
+// scratch store otherwise, and workers import them in batches while recording progress on the job.
+type ImportJobService struct {
+	repo        repository.ImportJobsRepository
+	companies   *CompaniesService
+	invitations *InvitationService
+	archive     *UploadArchiveService
+	scratch     UploadStore
+	batchSize   int
+	queue       chan uuid.UUID
+	now         func() time.Time
+}
+
+// ImportJobServiceOption customises an ImportJobService.
+type ImportJobServiceOption func(*ImportJobService)
+
+// WithUserImports enables user imports, which invite every row of the file.
+func WithUserImports(invitations *InvitationService) ImportJobServiceOption {
+	return func(s *ImportJobService) {
+		s.invitations = invitations
+	}
+}
+
+// NewImportJobService builds an ImportJobService. archive may be nil, in which case files are kept
+// in scratch until their job finishes. At most queueSize jobs wait for a worker.
+func NewImportJobService(repo repository.ImportJobsRepository, companies *CompaniesService, archive *UploadArchiveService, scratch UploadStore, batchSize, queueSize int, opts ...ImportJobServiceOption) *ImportJobService {
+	if batchSize <= 0 {
+		batchSize = DefaultImportBatchSize
+	}
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	svc := &ImportJobService{
+		repo:      repo,
+		companies: companies,
+		archive:   archive,
+		scratch:   scratch,
+		batchSize: batchSize,
+		queue:     make(chan uuid.UUID, queueSize),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Create stores file and queues a job importing it.
+func (s *ImportJobService) Create(ctx context.Context, createdBy, filename string, mode repository.ImportMode, mapping CSVColumnMapping, file io.ReadSeeker) (*entity.ImportJob, error) {
+	// Checked up front so a busy server does not store files it will not import.
+	if len(s.queue) == cap(s.queue) {
+		return nil, ErrImportQueueFull
+	}
+
+	job := newImportJob(createdBy, filename, entity.ImportJobKindCompanies)
+	job.Mode = string(mode)
+	job.Mapping = mapping
+
+	if s.archive != nil {
+		upload, err := s.archive.Archive(ctx, createdBy, filename, mode, file)
+		if err != nil {
+			return nil, err
+		}
+		job.UploadID = &upload.ID
+		job.StorageKey = upload.StorageKey
+		job.SizeBytes = upload.SizeBytes
+	} else if err := s.storeScratch(ctx, job, file); err != nil {
+		return nil, err
+	}
+	return s.queueJob(ctx, job)
+}
+
+// CreateUserImport stores a file of email, role and org columns and queues a job inviting every
+// row. The report of each row, with the invitation token to hand out, is kept on the job. These
+// files are never retained in the upload archive.
+func (s *ImportJobService) CreateUserImport(ctx context.Context, createdBy, filename string, file io.ReadSeeker) (*entity.ImportJob, error) {
+	if s.invitations == nil {
+		return nil, ErrUserImportsUnavailable
+	}
+	if len(s.queue) == cap(s.queue) {
+		return nil, ErrImportQueueFull
+	}
+
+	job := newImportJob(createdBy, filename, entity.ImportJobKindUsers)
+	job.Mode = "invite"
+	if err := s.storeScratch(ctx, job, file); err != nil {
+		return nil, err
+	}
+	return s.queueJob(ctx, job)
+}
+
+func newImportJob(createdBy, filename, kind string) *entity.ImportJob {
+	job := &entity.ImportJob{
+		ID:       uuid.New(),
+		Kind:     kind,
+		Filename: path.Base(strings.ReplaceAll(filename, "\\", "/")),
+		Status:   entity.ImportJobStatusQueued,
+	}
+	if parsed, err := uuid.Parse(createdBy); err == nil {
+		job.CreatedBy = &parsed
+	}
+	return job
+}
+
+// storeScratch keeps the file of job in the scratch store until the job finishes.
+func (s *ImportJobService) storeScratch(ctx context.Context, job *entity.ImportJob, file io.ReadSeeker) error {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("measure upload: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind upload: %w", err)
+	}
+	job.SizeBytes = size
+	job.StorageKey = job.ID.String()
+	if err := s.scratch.Upload(ctx, job.StorageKey, CSVContentType, file); err != nil {
+		return fmt.Errorf("store import file: %w", err)
+	}
+	return nil
+}
+
+// queueJob records a job whose file is stored and hands it to the workers.
+func (s *ImportJobService) queueJob(ctx context.Context, job *entity.ImportJob) (*entity.ImportJob, error) {
+	if err := s.repo.Create(ctx, job); err != nil {
+		s.discardFile(ctx, job)
+		return nil, err
+	}
+	if !s.enqueue(job.ID) {
+		s.fail(ctx, job, ErrImportQueueFull.Error())
+		return nil, ErrImportQueueFull
+	}
+	return job, nil
+}
+
+// Get returns an import job with its progress.
+func (s *ImportJobService) Get(ctx context.Context, id string) (*entity.ImportJob, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrInvalidImportJobID
+	}
+	job, err := s.repo.Get(ctx, parsed)
+	if err != nil {
+		if errors.Is(err, repository.ErrImportJobNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns import jobs, newest first.
+func (s *ImportJobService) List(ctx context.Context, limit, offset int) ([]entity.ImportJob, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+// Resume picks up jobs left behind by a previous process. Queued jobs are queued again; jobs that
+// were running are marked failed because their batches may have been partly written.
+func (s *ImportJobService) Resume(ctx context.Context) error {
+	jobs, err := s.repo.ListUnfinished(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		switch {
+		case job.Status == entity.ImportJobStatusRunning:
+			s.fail(ctx, job, "import interrupted by a restart; upload the file again")
+		case !s.enqueue(job.ID):
+			s.fail(ctx, job, ErrImportQueueFull.Error())
+		}
+	}
+	return nil
+}
+
+// Run processes queued jobs with the given number of workers until ctx is cancelled.
+func (s *ImportJobService) Run(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-s.queue:
+					s.process(ctx, id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *ImportJobService) enqueue(id uuid.UUID) bool {
+	select {
+	case s.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// process imports a single job and records its outcome.
+func (s *ImportJobService) process(ctx context.Context, id uuid.UUID) {
+	job, err := s.repo.Get(ctx, id)
+	if err != nil {
+		log.Printf("import job %s: load failed: %v", id, err)
+		return
+	}
+	started := s.now().UTC()
+	job.Status = entity.ImportJobStatusRunning
+	job.StartedAt = &started
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("import job %s: failed to mark running: %v", id, err)
+		return
+	}
+
+	importErr := s.runImport(ctx, job)
+
+	// The outcome is recorded even when shutdown cancelled the import.
+	ctx = context.WithoutCancel(ctx)
+	finished := s.now().UTC()
+	job.FinishedAt = &finished
+	job.Status = entity.ImportJobStatusCompleted
+	if importErr != nil {
+		message := importErr.Error()
+		var validationErr CSVValidationError
+		if !errors.As(importErr, &validationErr) {
+			log.Printf("import job %s failed: %v", id, importErr)
+			message = "import failed: " + message
+		}
+		job.Status = entity.ImportJobStatusFailed
+		job.Error = &message
+	} else {
+		job.BytesRead = job.SizeBytes
+	}
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("import job %s: failed to record outcome: %v", id, err)
+	}
+	s.completeUpload(ctx, job, importErr)
+	s.discardFile(ctx, job)
+}
+
+func (s *ImportJobService) runImport(ctx context.Context, job *entity.ImportJob) error {
+	store, err := s.storeFor(job)
+	if err != nil {
+		return err
+	}
+	file, err := store.Open(ctx, job.StorageKey)
+	if err != nil {
+		return fmt.Errorf("open import file: %w", err)
+	}
+	defer file.Close()
+
+	counter := &countingReader{r: file}
+	if job.Kind == entity.ImportJobKindUsers {
+		return s.runUserImport(ctx, job, counter)
+	}
+	return s.companies.ImportCompaniesInBatches(ctx, counter, repository.ImportMode(job.Mode), CSVColumnMapping(job.Mapping), s.batchSize, func(batch ImportBatch) error {
+		// Reads run ahead of parsing by a buffer, so the count is capped at the file size.
+		job.BytesRead = min(counter.n.Load(), job.SizeBytes)
+		job.ProcessedRows += batch.Rows
+		job.Inserted += batch.Inserted
+		job.Updated += batch.Updated
+		job.Skipped += batch.Skipped
+		job.ErrorCount += len(batch.Issues)
+		for _, issue := range batch.Issues {
+			if len(job.RowErrors) >= maxImportRowErrors {
+				break
+			}
+			job.RowErrors = append(job.RowErrors, entity.ImportRowError{Row: issue.Row, Message: issue.Message})
+		}
+		return s.repo.Update(ctx, job)
+	})
+}
+
+// runUserImport invites every row of a user import, reporting each one on the job. Rows whose
+// address already belongs to a user are skipped; refreshed pending invitations count as updated.
+func (s *ImportJobService) runUserImport(ctx context.Context, job *entity.ImportJob, counter *countingReader) error {
+	if s.invitations == nil {
+		return ErrUserImportsUnavailable
+	}
+	rows, err := readUserImportRows(counter)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]int, len(rows))
+	job.Results = make([]entity.ImportRowResult, 0, len(rows))
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result := entity.ImportRowResult{Row: row.row, Email: row.email}
+		email, _, err := normalizeInvite(row.email, row.role)
+		if err == nil {
+			if first, ok := seen[email]; ok {
+				err = fmt.Errorf("duplicate of row %d", first)
+			}
+			seen[email] = row.row
+		}
+		if err != nil {
+			result.Status = entity.ImportRowFailed
+			result.Message = err.Error()
+			job.ErrorCount++
+			if len(job.RowErrors) < maxImportRowErrors {
+				job.RowErrors = append(job.RowErrors, entity.ImportRowError{Row: row.row, Message: err.Error()})
+			}
+		} else {
+			invitation, token, created, err := s.invitations.Invite(ctx, UserInvite{
+				Email:       row.email,
+				Role:        row.role,
+				Org:         row.org,
+				InvitedBy:   job.CreatedBy,
+				ImportJobID: &job.ID,
+			})
+			switch {
+			case errors.Is(err, repository.ErrEmailDuplicate):
+				result.Status = entity.ImportRowSkipped
+				result.Message = "user already exists"
+				job.Skipped++
+			case err != nil:
+				return err
+			default:
+				result.Email = invitation.Email
+				result.InvitationID = &invitation.ID
+				result.Token = token
+				result.ExpiresAt = &invitation.ExpiresAt
+				if created {
+					result.Status = entity.ImportRowInvited
+					job.Inserted++
+				} else {
+					result.Status = entity.ImportRowReinvited
+					job.Updated++
+				}
+			}
+		}
+		job.Results = append(job.Results, result)
+		job.ProcessedRows++
+
+		if (i+1)%s.batchSize == 0 {
+			job.BytesRead = min(counter.n.Load(), job.SizeBytes)
+			if err := s.repo.Update(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storeFor returns where the file of job is kept.
+func (s *ImportJobService) storeFor(job *entity.ImportJob) (UploadStore, error) {
+	if job.UploadID == nil {
+		return s.scratch, nil
+	}
+	if s.archive == nil {
+		return nil, errors.New("upload retention is disabled; the import file is unavailable")
+	}
+	return s.archive.store, nil
+}
+
+// completeUpload records the job outcome on its retained upload.
+func (s *ImportJobService) completeUpload(ctx context.Context, job *entity.ImportJob, importErr error) {
+	if job.UploadID == nil || s.archive == nil {
+		return
+	}
+	upload, err := s.archive.Get(ctx, job.UploadID.String())
+	if err != nil {
+		log.Printf("import job %s: failed to load upload %s: %v", job.ID, job.UploadID, err)
+		return
+	}
+	summary := UploadSummary{
+		Mode:     repository.ImportMode(job.Mode),
+		Inserted: job.Inserted,
+		Updated:  job.Updated,
+		Skipped:  job.Skipped,
+		Total:    job.ProcessedRows - job.ErrorCount,
+	}
+	if err := s.archive.Complete(ctx, upload, summary, importErr); err != nil {
+		log.Printf("import job %s: failed to record upload %s outcome: %v", job.ID, upload.ID, err)
+	}
+}
+
+// discardFile removes scratch files; retained uploads are left to the archive.
+func (s *ImportJobService) discardFile(ctx context.Context, job *entity.ImportJob) {
+	if job.UploadID != nil {
+		return
+	}
+	if err := s.scratch.Delete(ctx, job.StorageKey); err != nil {
+		log.Printf("import job %s: failed to delete import file: %v", job.ID, err)
+	}
+}
+
+// fail marks a job that will not run as failed.
+func (s *ImportJobService) fail(ctx context.Context, job *entity.ImportJob, message string) {
+	finished := s.now().UTC()
+	job.Status = entity.ImportJobStatusFailed
+	job.Error = &message
+	job.FinishedAt = &finished
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("import job %s: failed to mark failed: %v", job.ID, err)
+	}
+	s.discardFile(ctx, job)
+}
+
+// countingReader counts the bytes read from an import file to report progress.
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
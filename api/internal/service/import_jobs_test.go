@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type mockImportJobsRepository struct {
+	mu      sync.Mutex
+	jobs    map[uuid.UUID]*entity.ImportJob
+	updates int
+}
+
+func newMockImportJobsRepository() *mockImportJobsRepository {
+	return &mockImportJobsRepository{jobs: make(map[uuid.UUID]*entity.ImportJob)}
+}
+
+func (m *mockImportJobsRepository) Create(ctx context.Context, job *entity.ImportJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *job
+	m.jobs[job.ID] = &copied
+	return nil
+}
+
+func (m *mockImportJobsRepository) Update(ctx context.Context, job *entity.ImportJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.jobs[job.ID]; !ok {
+		return repository.ErrImportJobNotFound
+	}
+	copied := *job
+	copied.RowErrors = append([]entity.ImportRowError(nil), job.RowErrors...)
+	m.jobs[job.ID] = &copied
+	m.updates++
+	return nil
+}
+
+func (m *mockImportJobsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, repository.ErrImportJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (m *mockImportJobsRepository) List(ctx context.Context, limit, offset int) ([]entity.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]entity.ImportJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+func (m *mockImportJobsRepository) ListUnfinished(ctx context.Context) ([]entity.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []entity.ImportJob
+	for _, job := range m.jobs {
+		if job.Status == entity.ImportJobStatusQueued || job.Status == entity.ImportJobStatusRunning {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+const importJobCSV = "company,address,phone,website,rating,reviews,type_business,city,country\n" +
+	"Acme,Main St,,,4.5,10,store,Gotham,USA\n" +
+	"Bolt,Second St,,,bad,3,store,Gotham,USA\n" +
+	",Third St,,,4.0,1,store,Gotham,USA\n" +
+	"Core,Fourth St,,,3.5,7,store,Gotham,USA\n" +
+	"Dash,Fifth St,,,,,store,Gotham,USA\n"
+
+func newTestImportJobService(t *testing.T, batches *[]int, queueSize int) (*ImportJobService, *mockImportJobsRepository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	scratch, err := storage.NewLocalStore(dir)
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	companies := NewCompaniesService(&mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			if batches != nil {
+				*batches = append(*batches, len(records))
+			}
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	})
+	repo := newMockImportJobsRepository()
+	return NewImportJobService(repo, companies, nil, scratch, 2, queueSize), repo, dir
+}
+
+func TestImportJobService_Process(t *testing.T) {
+	var batches []int
+	svc, repo, dir := newTestImportJobService(t, &batches, 5)
+	ctx := context.Background()
+
+	job, err := svc.Create(ctx, uuid.NewString(), "leads.csv", repository.ImportModeOverwrite, nil, strings.NewReader(importJobCSV))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if job.Status != entity.ImportJobStatusQueued || job.SizeBytes != int64(len(importJobCSV)) || job.CreatedBy == nil {
+		t.Fatalf("unexpected queued job: %+v", job)
+	}
+	if _, err := os.Stat(filepath.Join(dir, job.StorageKey)); err != nil {
+		t.Fatalf("expected stored import file: %v", err)
+	}
+
+	svc.process(ctx, <-svc.queue)
+
+	done, err := svc.Get(ctx, job.ID.String())
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if done.Status != entity.ImportJobStatusCompleted || done.StartedAt == nil || done.FinishedAt == nil {
+		t.Fatalf("unexpected job status: %+v", done)
+	}
+	if done.ProcessedRows != 5 || done.Inserted != 3 || done.ErrorCount != 2 || done.BytesRead != done.SizeBytes {
+		t.Fatalf("unexpected job progress: %+v", done)
+	}
+	if len(done.RowErrors) != 2 || done.RowErrors[0].Row != 3 || done.RowErrors[1].Row != 4 {
+		t.Fatalf("unexpected row errors: %+v", done.RowErrors)
+	}
+	// Rows are read two at a time and every batch holds one rejected or blank row except the last.
+	if len(batches) != 3 || batches[0] != 1 || batches[1] != 1 || batches[2] != 1 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	// running + three batches + outcome
+	if repo.updates != 5 {
+		t.Fatalf("expected 5 progress updates, got %d", repo.updates)
+	}
+	if _, err := os.Stat(filepath.Join(dir, job.StorageKey)); !os.IsNotExist(err) {
+		t.Fatalf("expected import file to be removed, got %v", err)
+	}
+}
+
+func TestImportJobService_ProcessInvalidHeader(t *testing.T) {
+	svc, _, _ := newTestImportJobService(t, nil, 5)
+	ctx := context.Background()
+
+	job, err := svc.Create(ctx, "", "leads.csv", repository.ImportModeOverwrite, nil, strings.NewReader("name,street\nAcme,Main St\n"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc.process(ctx, <-svc.queue)
+
+	done, _ := svc.Get(ctx, job.ID.String())
+	if done.Status != entity.ImportJobStatusFailed || done.Error == nil || !strings.Contains(*done.Error, "missing required columns") {
+		t.Fatalf("unexpected failed job: %+v", done)
+	}
+}
+
+func TestImportJobService_QueueFull(t *testing.T) {
+	svc, _, _ := newTestImportJobService(t, nil, 1)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "", "a.csv", repository.ImportModeOverwrite, nil, strings.NewReader(importJobCSV)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Create(ctx, "", "b.csv", repository.ImportModeOverwrite, nil, strings.NewReader(importJobCSV)); !errors.Is(err, ErrImportQueueFull) {
+		t.Fatalf("expected ErrImportQueueFull, got %v", err)
+	}
+}
+
+func TestImportJobService_Resume(t *testing.T) {
+	svc, repo, _ := newTestImportJobService(t, nil, 5)
+	ctx := context.Background()
+
+	queued := &entity.ImportJob{ID: uuid.New(), Status: entity.ImportJobStatusQueued, StorageKey: "queued"}
+	running := &entity.ImportJob{ID: uuid.New(), Status: entity.ImportJobStatusRunning, StorageKey: "running"}
+	_ = repo.Create(ctx, queued)
+	_ = repo.Create(ctx, running)
+
+	if err := svc.Resume(ctx); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if id := <-svc.queue; id != queued.ID {
+		t.Fatalf("expected queued job to be queued again, got %s", id)
+	}
+	interrupted, _ := repo.Get(ctx, running.ID)
+	if interrupted.Status != entity.ImportJobStatusFailed || interrupted.Error == nil {
+		t.Fatalf("expected running job to be failed, got %+v", interrupted)
+	}
+}
+
+func TestImportJobService_Run(t *testing.T) {
+	svc, _, _ := newTestImportJobService(t, nil, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx, 2)
+
+	job, err := svc.Create(ctx, "", "leads.csv", repository.ImportModeOverwrite, nil, strings.NewReader(importJobCSV))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, err := svc.Get(ctx, job.ID.String())
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if current.Status == entity.ImportJobStatusCompleted {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not complete: %+v", current)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// MaxIngestBatchItems caps the number of places in one ingest batch.
+const MaxIngestBatchItems = 500
+
+var (
+	// ErrInvalidIngestRunID is returned when a run identifier cannot be parsed as UUID.
+	ErrInvalidIngestRunID = errors.New("invalid ingest run id")
+	// ErrInvalidIngestBatch is returned when a batch or finish payload fails validation.
+	ErrInvalidIngestBatch = errors.New("invalid ingest batch")
+	// ErrIngestRunNotFound indicates there is no run with the requested id.
+	ErrIngestRunNotFound = errors.New("ingest run not found")
+	// ErrIngestRunFinished is returned when a finished run is appended to or restarted.
+	ErrIngestRunFinished = errors.New("ingest run already finished")
+	// ErrIngestBatchConflict is returned when a sequence is sent again with different items.
+	ErrIngestBatchConflict = errors.New("ingest batch conflicts with the batch already received")
+	// ErrIngestRunOverflow is returned when a run received batches beyond its total_batches.
+	ErrIngestRunOverflow = errors.New("ingest run has batches beyond total_batches")
+)
+
+// IngestRunIncompleteError lists the sequences a run is still missing when the worker finishes it.
+type IngestRunIncompleteError struct {
+	Missing []int
+}
+
+// Error implements the error interface.
+func (e IngestRunIncompleteError) Error() string {
+	sequences := make([]string, 0, len(e.Missing))
+	for _, sequence := range e.Missing {
+		sequences = append(sequences, strconv.Itoa(sequence))
+	}
+	return "ingest run is missing batches: " + strings.Join(sequences, ", ")
+}
+
+// IngestRunsService lets the worker stream a scrape as numbered batches: it starts a run, appends
+// batches in any order and finishes the run once all were sent. Every batch is applied once, so a
+// worker that failed mid-run resumes from the run's next_sequence and may safely resend batches.
+type IngestRunsService struct {
+	repo      repository.IngestRunsRepository
+	publisher events.Publisher
+	// locations normalises the city of streamed places; nil stores them as sent.
+	locations *LocationService
+	// categories normalises the business type and categories of streamed places; nil stores them
+	// as sent.
+	categories *CategoryService
+}
+
+// IngestRunsServiceOption customises optional IngestRunsService collaborators.
+type IngestRunsServiceOption func(*IngestRunsService)
+
+// WithIngestRunEvents publishes applied batches, the companies they upserted and finished runs to
+// publisher.
+func WithIngestRunEvents(publisher events.Publisher) IngestRunsServiceOption {
+	return func(s *IngestRunsService) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
+}
+
+// NewIngestRunsService builds a new IngestRunsService.
+func NewIngestRunsService(repo repository.IngestRunsRepository, opts ...IngestRunsServiceOption) *IngestRunsService {
+	svc := &IngestRunsService{repo: repo, publisher: events.Discard}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Start opens a run. Starting a run_id that is still open returns it with created false, so a
+// restarted worker can resume it.
+func (s *IngestRunsService) Start(ctx context.Context, req dto.IngestRunStartRequest) (*entity.IngestRun, bool, error) {
+	id := uuid.New()
+	if raw := strings.TrimSpace(req.RunID); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, false, ErrInvalidIngestRunID
+		}
+		id = parsed
+	}
+
+	run := &entity.IngestRun{ID: id, Source: strings.TrimSpace(req.Source), Query: strings.TrimSpace(req.Query)}
+	err := s.repo.Create(ctx, run)
+	if err == nil {
+		return run, true, nil
+	}
+	if !errors.Is(err, repository.ErrIngestRunDuplicate) {
+		return nil, false, err
+	}
+	existing, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, false, mapIngestRunError(err)
+	}
+	if existing.Status != entity.IngestRunOpen {
+		return nil, false, ErrIngestRunFinished
+	}
+	return existing, false, nil
+}
+
+// Get returns a run with the sequence a resumed worker continues from.
+func (s *IngestRunsService) Get(ctx context.Context, idRaw string) (*entity.IngestRun, error) {
+	id, err := parseIngestRunID(idRaw)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, mapIngestRunError(err)
+	}
+	return run, nil
+}
+
+// AppendBatch upserts the places of a batch into the run. A batch resent with the same items is
+// acknowledged as a duplicate without being applied again.
+func (s *IngestRunsService) AppendBatch(ctx context.Context, idRaw string, req dto.IngestBatchRequest) (*entity.IngestBatch, error) {
+	id, err := parseIngestRunID(idRaw)
+	if err != nil {
+		return nil, err
+	}
+	if req.Sequence == nil || *req.Sequence < 0 {
+		return nil, fmt.Errorf("%w: sequence must be 0 or greater", ErrInvalidIngestBatch)
+	}
+	if len(req.Items) == 0 || len(req.Items) > MaxIngestBatchItems {
+		return nil, fmt.Errorf("%w: items must hold 1 to %d places", ErrInvalidIngestBatch, MaxIngestBatchItems)
+	}
+
+	companies := make([]entity.Company, 0, len(req.Items))
+	for i, item := range req.Items {
+		company, err := ingestItemCompany(item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: items[%d]: %v", ErrInvalidIngestBatch, i, err)
+		}
+		if s.locations != nil {
+			company.City = s.locations.normalizeCityPointer(company.City, company.Country)
+		}
+		if s.categories != nil {
+			company.TypeBusiness, company.Categories = s.categories.normalizeCompany(company.TypeBusiness, company.Categories)
+		}
+		companies = append(companies, company)
+	}
+	checksum, err := ingestBatchChecksum(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &entity.IngestBatch{Sequence: *req.Sequence, Checksum: checksum, Items: len(companies)}
+	if err := s.repo.AppendBatch(ctx, id, batch, companies); err != nil {
+		return nil, mapIngestRunError(err)
+	}
+	if !batch.Duplicate {
+		s.publisher.Publish(ctx, events.CompaniesUpserted{Source: events.SourceIngest, Companies: batch.Items})
+		s.publisher.Publish(ctx, events.IngestBatchStored{RunID: id, Sequence: batch.Sequence, Items: batch.Items})
+	}
+	return batch, nil
+}
+
+// Finish closes a run once batches 0 to total_batches-1 were all received; otherwise it returns an
+// IngestRunIncompleteError and the run stays open for the missing batches.
+func (s *IngestRunsService) Finish(ctx context.Context, idRaw string, req dto.IngestRunFinishRequest) (*entity.IngestRun, error) {
+	id, err := parseIngestRunID(idRaw)
+	if err != nil {
+		return nil, err
+	}
+	if req.TotalBatches == nil || *req.TotalBatches < 0 {
+		return nil, fmt.Errorf("%w: total_batches must be 0 or greater", ErrInvalidIngestBatch)
+	}
+	missing, err := s.repo.Finish(ctx, id, *req.TotalBatches)
+	if err != nil {
+		return nil, mapIngestRunError(err)
+	}
+	if len(missing) > 0 {
+		return nil, IngestRunIncompleteError{Missing: missing}
+	}
+	run, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, mapIngestRunError(err)
+	}
+	s.publisher.Publish(ctx, events.IngestRunFinished{RunID: id, Batches: run.Batches})
+	return run, nil
+}
+
+func parseIngestRunID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return uuid.Nil, ErrInvalidIngestRunID
+	}
+	return id, nil
+}
+
+// ingestItemCompany maps a streamed place onto a company stored under the dedupe key of its source.
+// The key is required: it is what makes re-ingesting a place update it rather than add a duplicate.
+func ingestItemCompany(item dto.IngestItem) (entity.Company, error) {
+	name := strings.TrimSpace(item.Name)
+	if name == "" {
+		return entity.Company{}, errors.New("name is required")
+	}
+	source, err := ResolveScrapeSource(item.Source)
+	if err != nil {
+		return entity.Company{}, err
+	}
+	placeID := source.DedupeKey(item)
+	if placeID == "" {
+		return entity.Company{}, errors.New("place_id is required")
+	}
+	company := entity.Company{
+		PlaceID:      &placeID,
+		Company:      name,
+		Phone:        trimPointer(item.Phone),
+		Website:      trimPointer(item.Website),
+		Rating:       item.Rating,
+		Reviews:      item.ReviewCount,
+		TypeBusiness: trimPointer(item.TypeBusiness),
+		Address:      trimPointer(item.Address),
+		City:         trimPointer(item.City),
+		Country:      trimPointer(item.Country),
+		Latitude:     item.Latitude,
+		Longitude:    item.Longitude,
+		Raw:          item.RawSnapshot,
+	}
+	applyLegalForm(&company)
+	applyContactNormalization(&company)
+	applyPlaceAttributes(&company)
+	return company, nil
+}
+
+// ingestBatchChecksum fingerprints the items of a batch as decoded, so formatting differences of a
+// resent batch do not make it look like a conflicting one.
+func ingestBatchChecksum(items []dto.IngestItem) (string, error) {
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return "", fmt.Errorf("encode ingest batch: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func mapIngestRunError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrIngestRunNotFound):
+		return ErrIngestRunNotFound
+	case errors.Is(err, repository.ErrIngestRunFinished):
+		return ErrIngestRunFinished
+	case errors.Is(err, repository.ErrIngestBatchConflict):
+		return ErrIngestBatchConflict
+	case errors.Is(err, repository.ErrIngestRunOverflow):
+		return ErrIngestRunOverflow
+	default:
+		return err
+	}
+}
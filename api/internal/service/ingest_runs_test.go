@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockIngestRunsRepository struct {
+	runs      map[uuid.UUID]*entity.IngestRun
+	checksums map[int]string
+	companies []entity.Company
+	missing   []int
+}
+
+func newMockIngestRunsRepository() *mockIngestRunsRepository {
+	return &mockIngestRunsRepository{runs: map[uuid.UUID]*entity.IngestRun{}, checksums: map[int]string{}}
+}
+
+func (m *mockIngestRunsRepository) Create(ctx context.Context, run *entity.IngestRun) error {
+	if _, ok := m.runs[run.ID]; ok {
+		return repository.ErrIngestRunDuplicate
+	}
+	run.Status = entity.IngestRunOpen
+	m.runs[run.ID] = run
+	return nil
+}
+
+func (m *mockIngestRunsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.IngestRun, error) {
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, repository.ErrIngestRunNotFound
+	}
+	return run, nil
+}
+
+func (m *mockIngestRunsRepository) AppendBatch(ctx context.Context, runID uuid.UUID, batch *entity.IngestBatch, companies []entity.Company) error {
+	if checksum, ok := m.checksums[batch.Sequence]; ok {
+		if checksum != batch.Checksum {
+			return repository.ErrIngestBatchConflict
+		}
+		batch.Duplicate = true
+		return nil
+	}
+	m.checksums[batch.Sequence] = batch.Checksum
+	m.companies = append(m.companies, companies...)
+	return nil
+}
+
+func (m *mockIngestRunsRepository) Finish(ctx context.Context, id uuid.UUID, totalBatches int) ([]int, error) {
+	if len(m.missing) > 0 {
+		return m.missing, nil
+	}
+	m.runs[id].Status = entity.IngestRunFinished
+	return nil, nil
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestIngestRunsService_StartResumesOpenRun(t *testing.T) {
+	repo := newMockIngestRunsRepository()
+	svc := NewIngestRunsService(repo)
+
+	run, created, err := svc.Start(context.Background(), dto.IngestRunStartRequest{Source: " serpapi ", Query: "cafe in Bandung"})
+	if err != nil || !created || run.Source != "serpapi" {
+		t.Fatalf("unexpected start: %+v, %v, %v", run, created, err)
+	}
+	resumed, created, err := svc.Start(context.Background(), dto.IngestRunStartRequest{RunID: run.ID.String()})
+	if err != nil || created || resumed.ID != run.ID {
+		t.Fatalf("expected the open run to be resumed, got %+v, %v, %v", resumed, created, err)
+	}
+
+	run.Status = entity.IngestRunFinished
+	if _, _, err := svc.Start(context.Background(), dto.IngestRunStartRequest{RunID: run.ID.String()}); !errors.Is(err, ErrIngestRunFinished) {
+		t.Fatalf("expected a finished run not to restart, got %v", err)
+	}
+	if _, _, err := svc.Start(context.Background(), dto.IngestRunStartRequest{RunID: "nope"}); !errors.Is(err, ErrInvalidIngestRunID) {
+		t.Fatalf("expected an invalid run id error, got %v", err)
+	}
+}
+
+func TestIngestRunsService_AppendBatch(t *testing.T) {
+	repo := newMockIngestRunsRepository()
+	svc := NewIngestRunsService(repo)
+	runID := uuid.NewString()
+	items := []dto.IngestItem{{Name: " Kopi Kenangan ", RawSnapshot: json.RawMessage(`{"place_id":"ChIJ123"}`)}}
+
+	batch, err := svc.AppendBatch(context.Background(), runID, dto.IngestBatchRequest{Sequence: intPtr(0), Items: items})
+	if err != nil || batch.Duplicate || batch.Items != 1 {
+		t.Fatalf("unexpected batch: %+v, %v", batch, err)
+	}
+	if len(repo.companies) != 1 || *repo.companies[0].PlaceID != "ChIJ123" || repo.companies[0].Company != "Kopi Kenangan" {
+		t.Fatalf("expected the place id to come from the raw snapshot, got %+v", repo.companies)
+	}
+
+	batch, err = svc.AppendBatch(context.Background(), runID, dto.IngestBatchRequest{Sequence: intPtr(0), Items: items})
+	if err != nil || !batch.Duplicate || len(repo.companies) != 1 {
+		t.Fatalf("expected a resent batch to be a duplicate, got %+v, %v", batch, err)
+	}
+	legacy := []dto.IngestItem{{Name: "Kopi Kenangan", Source: "serpapi_google_maps", PlaceID: " ChIJ456 "}}
+	if _, err := svc.AppendBatch(context.Background(), runID, dto.IngestBatchRequest{Sequence: intPtr(1), Items: legacy}); err != nil || *repo.companies[len(repo.companies)-1].PlaceID != "ChIJ456" {
+		t.Fatalf("expected places of older workers keyed by their place id, got %+v, %v", repo.companies, err)
+	}
+
+	items[0].Name = "Kopi Janji Jiwa"
+	if _, err := svc.AppendBatch(context.Background(), runID, dto.IngestBatchRequest{Sequence: intPtr(0), Items: items}); !errors.Is(err, ErrIngestBatchConflict) {
+		t.Fatalf("expected different items under a sent sequence to conflict, got %v", err)
+	}
+
+	invalid := []dto.IngestBatchRequest{
+		{Items: items},
+		{Sequence: intPtr(-1), Items: items},
+		{Sequence: intPtr(1)},
+		{Sequence: intPtr(1), Items: []dto.IngestItem{{Name: "No place id"}}},
+		{Sequence: intPtr(1), Items: []dto.IngestItem{{Name: "Unknown source", Source: "yelp", PlaceID: "abc"}}},
+	}
+	for _, req := range invalid {
+		if _, err := svc.AppendBatch(context.Background(), runID, req); !errors.Is(err, ErrInvalidIngestBatch) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+}
+
+func TestIngestRunsService_FinishReportsMissingBatches(t *testing.T) {
+	repo := newMockIngestRunsRepository()
+	svc := NewIngestRunsService(repo)
+	run, _, err := svc.Start(context.Background(), dto.IngestRunStartRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo.missing = []int{2, 5}
+	var incomplete IngestRunIncompleteError
+	if _, err := svc.Finish(context.Background(), run.ID.String(), dto.IngestRunFinishRequest{TotalBatches: intPtr(6)}); !errors.As(err, &incomplete) || len(incomplete.Missing) != 2 {
+		t.Fatalf("expected the missing batches, got %v", err)
+	}
+	if incomplete.Error() != "ingest run is missing batches: 2, 5" {
+		t.Fatalf("unexpected message %q", incomplete.Error())
+	}
+
+	repo.missing = nil
+	finished, err := svc.Finish(context.Background(), run.ID.String(), dto.IngestRunFinishRequest{TotalBatches: intPtr(6)})
+	if err != nil || finished.Status != entity.IngestRunFinished {
+		t.Fatalf("unexpected finish: %+v, %v", finished, err)
+	}
+	if _, err := svc.Finish(context.Background(), run.ID.String(), dto.IngestRunFinishRequest{}); !errors.Is(err, ErrInvalidIngestBatch) {
+		t.Fatalf("expected total_batches to be required, got %v", err)
+	}
+}
+
+func TestIngestRunsService_PublishesEvents(t *testing.T) {
+	bus := events.NewBus()
+	sub := bus.Subscribe()
+	defer sub.Close()
+	repo := newMockIngestRunsRepository()
+	svc := NewIngestRunsService(repo, WithIngestRunEvents(bus))
+	run, _, err := svc.Start(context.Background(), dto.IngestRunStartRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := []dto.IngestItem{{Name: "Kopi Kenangan", PlaceID: "ChIJ123"}}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.AppendBatch(context.Background(), run.ID.String(), dto.IngestBatchRequest{Sequence: intPtr(0), Items: items}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	run.Batches = 1
+	if _, err := svc.Finish(context.Background(), run.ID.String(), dto.IngestRunFinishRequest{TotalBatches: intPtr(1)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []events.Payload{
+		events.CompaniesUpserted{Source: events.SourceIngest, Companies: 1},
+		events.IngestBatchStored{RunID: run.ID, Sequence: 0, Items: 1},
+		events.IngestRunFinished{RunID: run.ID, Batches: 1},
+	}
+	for _, payload := range want {
+		if event := <-sub.C; event.Payload != payload {
+			t.Fatalf("expected %+v, got %+v", payload, event.Payload)
+		}
+	}
+	if len(sub.C) != 0 {
+		t.Fatalf("expected the duplicate batch not to be published")
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// JobStatusFailed is the only status the admin job listing filters by so far.
+const JobStatusFailed = "failed"
+
+var (
+	// ErrInvalidJobID is returned when a job identifier cannot be parsed as UUID.
+	ErrInvalidJobID = errors.New("invalid job id")
+	// ErrJobNotFound indicates there is no scrape or enrichment job with the requested id.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotFailed is returned when a job to retry has not failed, or was retried already.
+	ErrJobNotFailed = errors.New("only failed jobs can be retried")
+	// ErrInvalidJobFilter is returned when a job listing asks for an unsupported status or kind.
+	ErrInvalidJobFilter = errors.New("status must be failed and kind one of scrape, enrichment")
+)
+
+// JobRetryService lists the scrape and enrichment jobs the worker did not accept and queues them
+// again with the request they were stored with. Sending the request is left to the caller, which
+// reports a refused retry with Fail.
+type JobRetryService struct {
+	repo repository.RetryableJobsRepository
+}
+
+// NewJobRetryService builds a JobRetryService.
+func NewJobRetryService(repo repository.RetryableJobsRepository) *JobRetryService {
+	return &JobRetryService{repo: repo}
+}
+
+// List returns the jobs with status, failed when it is empty, of kind, scrape and enrichment jobs
+// when it is empty, newest first.
+func (s *JobRetryService) List(ctx context.Context, status, kind string, limit, offset int) ([]entity.RetryableJob, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if status != "" && status != JobStatusFailed {
+		return nil, ErrInvalidJobFilter
+	}
+	if kind != "" && kind != entity.JobKindScrape && kind != entity.JobKindEnrichment {
+		return nil, ErrInvalidJobFilter
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListFailedJobs(ctx, kind, limit, offset)
+}
+
+// Requeue moves a failed job back to the queue, counting the retry, and returns it with the request
+// to send the worker. Only one of concurrent retries of a job succeeds; the others get
+// ErrJobNotFailed.
+func (s *JobRetryService) Requeue(ctx context.Context, id string) (*entity.RetryableJob, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrInvalidJobID
+	}
+	job, err := s.repo.RequeueFailedJob(ctx, parsed)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRetryableJobNotFound):
+			return nil, ErrJobNotFound
+		case errors.Is(err, repository.ErrJobNotFailed):
+			return nil, ErrJobNotFailed
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// Fail records that the worker refused the retry of job, so it can be retried again.
+func (s *JobRetryService) Fail(ctx context.Context, job *entity.RetryableJob, reason string) error {
+	return s.repo.FailRetryableJob(ctx, job.Kind, job.ID, reason)
+}
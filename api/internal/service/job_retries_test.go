@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type retryableJobsRepoStub struct {
+	kind          string
+	limit, offset int
+	requeueErr    error
+}
+
+func (s *retryableJobsRepoStub) ListFailedJobs(ctx context.Context, kind string, limit, offset int) ([]entity.RetryableJob, error) {
+	s.kind, s.limit, s.offset = kind, limit, offset
+	return []entity.RetryableJob{}, nil
+}
+
+func (s *retryableJobsRepoStub) RequeueFailedJob(ctx context.Context, id uuid.UUID) (*entity.RetryableJob, error) {
+	if s.requeueErr != nil {
+		return nil, s.requeueErr
+	}
+	return &entity.RetryableJob{ID: id, Kind: entity.JobKindScrape, Status: entity.ScrapeJobQueued}, nil
+}
+
+func (s *retryableJobsRepoStub) FailRetryableJob(ctx context.Context, kind string, id uuid.UUID, reason string) error {
+	return nil
+}
+
+func TestJobRetryService_List(t *testing.T) {
+	repo := &retryableJobsRepoStub{}
+	svc := NewJobRetryService(repo)
+
+	if _, err := svc.List(context.Background(), "Failed", " Scrape ", 500, -3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.kind != entity.JobKindScrape || repo.limit != 200 || repo.offset != 0 {
+		t.Fatalf("expected normalised kind and bounds, got %+v", repo)
+	}
+	if _, err := svc.List(context.Background(), "", "", 0, 0); err != nil || repo.kind != "" || repo.limit != 50 {
+		t.Fatalf("expected every failed job by default, got %+v (%v)", repo, err)
+	}
+	if _, err := svc.List(context.Background(), "queued", "", 0, 0); !errors.Is(err, ErrInvalidJobFilter) {
+		t.Fatalf("expected ErrInvalidJobFilter for a status, got %v", err)
+	}
+	if _, err := svc.List(context.Background(), "failed", "import", 0, 0); !errors.Is(err, ErrInvalidJobFilter) {
+		t.Fatalf("expected ErrInvalidJobFilter for a kind, got %v", err)
+	}
+}
+
+func TestJobRetryService_Requeue(t *testing.T) {
+	if _, err := NewJobRetryService(&retryableJobsRepoStub{}).Requeue(context.Background(), "nope"); !errors.Is(err, ErrInvalidJobID) {
+		t.Fatalf("expected ErrInvalidJobID, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		repoErr error
+		want    error
+	}{
+		{repoErr: repository.ErrRetryableJobNotFound, want: ErrJobNotFound},
+		{repoErr: repository.ErrJobNotFailed, want: ErrJobNotFailed},
+	} {
+		svc := NewJobRetryService(&retryableJobsRepoStub{requeueErr: tc.repoErr})
+		if _, err := svc.Requeue(context.Background(), uuid.NewString()); !errors.Is(err, tc.want) {
+			t.Fatalf("expected %v, got %v", tc.want, err)
+		}
+	}
+
+	id := uuid.New()
+	job, err := NewJobRetryService(&retryableJobsRepoStub{}).Requeue(context.Background(), id.String())
+	if err != nil || job.ID != id {
+		t.Fatalf("unexpected requeue: %+v (%v)", job, err)
+	}
+}
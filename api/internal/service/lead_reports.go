@@ -0,0 +1,516 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // static maps may come back as PNG
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/pdf"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrLeadReportsUnavailable is returned when reports are requested without a reports repository.
+	ErrLeadReportsUnavailable = errors.New("lead reports unavailable")
+	// ErrNoLeadsToReport is returned when a batch report filter matches no company to report on.
+	ErrNoLeadsToReport = errors.New("no companies match the report filter")
+)
+
+const (
+	// DefaultLeadReportLimit is how many companies a batch report covers by default.
+	DefaultLeadReportLimit = 25
+	// MaxLeadReportLimit caps the companies, one page each, of a batch report.
+	MaxLeadReportLimit = 100
+	// staticMapURL is the Maps Static API endpoint map thumbnails are fetched from.
+	staticMapURL = "https://maps.googleapis.com/maps/api/staticmap"
+	// maxStaticMapBytes caps a downloaded map thumbnail.
+	maxStaticMapBytes = 2 << 20
+)
+
+// Lead sheet layout, in points.
+const (
+	reportMargin    = 40.0
+	reportColumnGap = 20.0
+	reportMapWidth  = 200.0
+	reportMapHeight = 130.0
+	reportLeftWidth = pdf.A4Width - 2*reportMargin - reportColumnGap - reportMapWidth
+	reportFullWidth = pdf.A4Width - 2*reportMargin
+	reportFooterTop = pdf.A4Height - 50
+)
+
+var (
+	reportAccent   = pdf.Color{R: 0.11, G: 0.23, B: 0.42}
+	reportMuted    = pdf.Gray(0.4)
+	reportRule     = pdf.Gray(0.8)
+	reportBarTrack = pdf.Gray(0.9)
+	reportWarning  = pdf.Color{R: 0.75, G: 0.15, B: 0.15}
+)
+
+// LeadReportService renders one-page PDF lead sheets of companies to hand to sales people.
+type LeadReportService struct {
+	repo      repository.LeadReportsRepository
+	companies *CompaniesService
+	// maps fetches map thumbnails with mapsKey; nil leaves the map out.
+	maps         *http.Client
+	mapsKey      string
+	staticMapURL string
+	now          func() time.Time
+}
+
+// LeadReportServiceOption customises optional LeadReportService collaborators.
+type LeadReportServiceOption func(*LeadReportService)
+
+// WithReportMaps draws a map thumbnail of companies with coordinates, fetched from the Maps Static
+// API with key through client, which should go through the outbound policy.
+func WithReportMaps(client *http.Client, key string) LeadReportServiceOption {
+	return func(s *LeadReportService) {
+		s.maps = client
+		s.mapsKey = key
+	}
+}
+
+// NewLeadReportService builds a LeadReportService; companies selects the companies of batch reports
+// and flags those on the do-not-contact list.
+func NewLeadReportService(repo repository.LeadReportsRepository, companies *CompaniesService, opts ...LeadReportServiceOption) *LeadReportService {
+	svc := &LeadReportService{repo: repo, companies: companies, staticMapURL: staticMapURL, now: time.Now}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// LeadReport is a rendered report, ready to be written as PDF.
+type LeadReport struct {
+	// Name is the file name of the report, without extension.
+	Name string
+	// Companies is how many companies, one per page, the report covers.
+	Companies int
+	doc       *pdf.Document
+}
+
+// WriteTo writes the report as PDF.
+func (r *LeadReport) WriteTo(w io.Writer) (int64, error) {
+	return r.doc.WriteTo(w)
+}
+
+// CompanyReport renders the lead sheet of one company, stamped with who generated it.
+func (s *LeadReportService) CompanyReport(ctx context.Context, companyID string, stamp ExportStamp) (*LeadReport, error) {
+	if s.repo == nil {
+		return nil, ErrLeadReportsUnavailable
+	}
+	id, err := uuid.Parse(strings.TrimSpace(companyID))
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	reports, err := s.repo.LeadReports(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, ErrCompanyNotFound
+	}
+	if err := s.companies.flagSuppressed(ctx, []*entity.Company{&reports[0].Company}); err != nil {
+		return nil, err
+	}
+	name := reportSlug(reports[0].Company.Company)
+	return s.render(ctx, "Lead sheet: "+reports[0].Company.Company, "lead-"+name, reports, stamp), nil
+}
+
+// BatchReport renders a lead sheet per company matching filter, in the order of the listing, up to
+// filter.Limit companies (DefaultLeadReportLimit when unset, capped at MaxLeadReportLimit).
+// Companies on the do-not-contact list are left out unless the filter includes them.
+func (s *LeadReportService) BatchReport(ctx context.Context, filter dto.ListFilter, stamp ExportStamp) (*LeadReport, error) {
+	if s.repo == nil {
+		return nil, ErrLeadReportsUnavailable
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultLeadReportLimit
+	}
+	if filter.Limit > MaxLeadReportLimit {
+		filter.Limit = MaxLeadReportLimit
+	}
+	companies, err := s.companies.ListCompanies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(companies))
+	suppressed := make(map[uuid.UUID]bool)
+	for _, company := range companies {
+		if company.DoNotContact && !filter.IncludeSuppressed {
+			continue
+		}
+		ids = append(ids, company.ID)
+		suppressed[company.ID] = company.DoNotContact
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoLeadsToReport
+	}
+	reports, err := s.repo.LeadReports(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reports {
+		reports[i].Company.DoNotContact = suppressed[reports[i].Company.ID]
+	}
+	return s.render(ctx, fmt.Sprintf("Lead sheets: %d companies", len(reports)), "leads", reports, stamp), nil
+}
+
+// render lays out a page per report.
+func (s *LeadReportService) render(ctx context.Context, title, name string, reports []entity.LeadReport, stamp ExportStamp) *LeadReport {
+	doc := pdf.New()
+	doc.Title = title
+	for i := range reports {
+		s.renderPage(ctx, doc, &reports[i], stamp)
+	}
+	return &LeadReport{
+		Name:      fmt.Sprintf("%s-%s", name, stamp.At.UTC().Format("20060102")),
+		Companies: len(reports),
+		doc:       doc,
+	}
+}
+
+func (s *LeadReportService) renderPage(ctx context.Context, doc *pdf.Document, report *entity.LeadReport, stamp ExportStamp) {
+	company := report.Company
+	page := doc.AddPage()
+
+	// Header band: name, what and where the company is, and its score.
+	page.SetColor(reportAccent)
+	page.Rect(0, 0, pdf.A4Width, 78)
+	page.SetColor(pdf.White)
+	page.Text(reportMargin, 40, pdf.HelveticaBold, 20, pdf.Truncate(pdf.HelveticaBold, 20, company.Company, reportFullWidth-110, "…"))
+	subtitle := joinPresent(", ", company.TypeBusiness, company.City, company.Country)
+	page.Text(reportMargin, 60, pdf.Helvetica, 10, pdf.Truncate(pdf.Helvetica, 10, subtitle, reportFullWidth-110, "…"))
+	score := "—"
+	if report.Score != nil {
+		score = strconv.Itoa(*report.Score)
+	}
+	page.Text(pdf.A4Width-reportMargin-pdf.Width(pdf.HelveticaBold, 26, score), 44, pdf.HelveticaBold, 26, score)
+	page.Text(pdf.A4Width-reportMargin-pdf.Width(pdf.Helvetica, 9, "lead score"), 60, pdf.Helvetica, 9, "lead score")
+
+	y := 100.0
+	if company.DoNotContact {
+		page.SetColor(reportWarning)
+		page.Rect(reportMargin, y, reportFullWidth, 22)
+		page.SetColor(pdf.White)
+		page.Text(reportMargin+8, y+15, pdf.HelveticaBold, 10, "On the do-not-contact list: do not reach out to this company.")
+		y += 34
+	}
+
+	// Company details on the left, the map on the right.
+	top := y
+	left := newReportColumn(page, reportMargin, y, reportLeftWidth)
+	left.heading("Company")
+	left.field("Address", derefString(company.Address))
+	left.field("Phone", derefString(company.Phone))
+	left.field("Website", websiteLine(company))
+	left.field("Rating", ratingLine(company))
+	left.field("Status", derefString(company.BusinessStatus))
+	if company.PriceLevel != nil {
+		left.field("Price level", strings.Repeat("$", *company.PriceLevel)+fmt.Sprintf(" (%d of 4)", *company.PriceLevel))
+	}
+	left.field("Categories", strings.Join(company.Categories, ", "))
+	left.field("Legal form", derefString(company.LegalForm))
+	left.field("Language", derefString(company.PreferredOutreachLanguage))
+	if company.PlaceID != nil {
+		left.field("Maps", "https://www.google.com/maps/place/?q=place_id:"+*company.PlaceID)
+	}
+	s.drawMap(ctx, doc, page, company, pdf.A4Width-reportMargin-reportMapWidth, top+6)
+	y = max(left.y, top+6+reportMapHeight+14)
+
+	// Score breakdown, then contacts and socials in full width.
+	full := newReportColumn(page, reportMargin, y, reportFullWidth)
+	full.heading("Score breakdown")
+	full.scoreBars(report)
+	full.heading("Contacts")
+	if report.EnrichedAt == nil {
+		full.note("Not enriched yet: only the contacts from Google Maps are known.")
+	}
+	full.field("Emails", strings.Join(limitStrings(report.Emails, 6), ", "))
+	full.field("Phones", strings.Join(limitStrings(report.Phones, 6), ", "))
+	full.field("Contact form", derefString(report.ContactFormURL))
+	if len(report.Socials) > 0 {
+		full.heading("Socials")
+		platforms := make([]string, 0, len(report.Socials))
+		for platform := range report.Socials {
+			platforms = append(platforms, platform)
+		}
+		sort.Strings(platforms)
+		for _, platform := range platforms {
+			full.field(humanizeKey(platform), strings.Join(limitStrings(report.Socials[platform], 3), ", "))
+		}
+	}
+	if about := derefString(report.AboutSummary); about != "" {
+		full.heading("About")
+		full.paragraph(about)
+	}
+
+	page.Line(reportMargin, reportFooterTop, pdf.A4Width-reportMargin, reportFooterTop, 0.5, reportRule)
+	page.SetColor(reportMuted)
+	footer := fmt.Sprintf("Generated %s by %s · export %s", stamp.At.UTC().Format("2006-01-02 15:04 MST"), stamp.Email, stamp.ID)
+	if stamp.Email == "" {
+		footer = fmt.Sprintf("Generated %s · export %s", stamp.At.UTC().Format("2006-01-02 15:04 MST"), stamp.ID)
+	}
+	page.Text(reportMargin, reportFooterTop+16, pdf.Helvetica, 8, footer)
+	updated := "Data updated " + company.UpdatedAt.UTC().Format("2006-01-02")
+	page.Text(pdf.A4Width-reportMargin-pdf.Width(pdf.Helvetica, 8, updated), reportFooterTop+16, pdf.Helvetica, 8, updated)
+}
+
+// drawMap draws the map thumbnail of a company at x, y, or a placeholder saying why there is none.
+func (s *LeadReportService) drawMap(ctx context.Context, doc *pdf.Document, page *pdf.Page, company entity.Company, x, y float64) {
+	placeholder := "No map: location unknown"
+	if company.Latitude != nil && company.Longitude != nil {
+		placeholder = fmt.Sprintf("%.5f, %.5f", *company.Latitude, *company.Longitude)
+		if s.maps != nil {
+			data, err := s.fetchStaticMap(ctx, *company.Latitude, *company.Longitude)
+			if err == nil {
+				var img pdf.Image
+				if img, err = doc.AddJPEG(data); err == nil {
+					page.Image(img, x, y, reportMapWidth, reportMapHeight)
+					return
+				}
+			}
+			log.Printf("failed to fetch map of company %s: %v", company.ID, err)
+		}
+	}
+	page.SetColor(reportBarTrack)
+	page.Rect(x, y, reportMapWidth, reportMapHeight)
+	page.SetColor(reportMuted)
+	page.Text(x+(reportMapWidth-pdf.Width(pdf.Helvetica, 9, placeholder))/2, y+reportMapHeight/2+3, pdf.Helvetica, 9, placeholder)
+}
+
+// fetchStaticMap downloads a map centred on a marker and returns it as JPEG.
+func (s *LeadReportService) fetchStaticMap(ctx context.Context, lat, lng float64) ([]byte, error) {
+	center := fmt.Sprintf("%.6f,%.6f", lat, lng)
+	query := url.Values{
+		"center":  {center},
+		"zoom":    {"15"},
+		"size":    {fmt.Sprintf("%dx%d", int(reportMapWidth), int(reportMapHeight))},
+		"scale":   {"2"},
+		"markers": {center},
+		"format":  {"jpg"},
+		"key":     {s.mapsKey},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.staticMapURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.maps.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("static map answered with status %d", resp.StatusCode)
+	}
+	// Re-encoding accepts whatever format came back and drops colour spaces PDF cannot take.
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxStaticMapBytes))
+	if err != nil {
+		return nil, fmt.Errorf("decode static map: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("encode static map: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// reportColumn lays out labelled fields top down from y. Content past the footer is left out, so a
+// page never overflows.
+type reportColumn struct {
+	page     *pdf.Page
+	x, y     float64
+	width    float64
+	overflow bool
+}
+
+func newReportColumn(page *pdf.Page, x, y, width float64) *reportColumn {
+	return &reportColumn{page: page, x: x, y: y, width: width}
+}
+
+// room reports whether height more points fit above the footer.
+func (c *reportColumn) room(height float64) bool {
+	if c.y+height > reportFooterTop-8 {
+		c.overflow = true
+	}
+	return !c.overflow
+}
+
+func (c *reportColumn) heading(title string) {
+	if !c.room(30) {
+		return
+	}
+	c.y += 14
+	c.page.SetColor(reportAccent)
+	c.page.Text(c.x, c.y, pdf.HelveticaBold, 11, strings.ToUpper(title))
+	c.page.Line(c.x, c.y+4, c.x+c.width, c.y+4, 0.5, reportRule)
+	c.y += 18
+}
+
+// field draws a label and its value wrapped beside it; empty values are skipped.
+func (c *reportColumn) field(label, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	const labelWidth = 78.0
+	lines := pdf.Wrap(pdf.Helvetica, 9.5, value, c.width-labelWidth)
+	if len(lines) > 4 {
+		lines = append(lines[:3], pdf.Truncate(pdf.Helvetica, 9.5, strings.Join(lines[3:], " "), c.width-labelWidth, "…"))
+	}
+	if !c.room(float64(len(lines)) * 13) {
+		return
+	}
+	c.page.SetColor(reportMuted)
+	c.page.Text(c.x, c.y, pdf.HelveticaBold, 8.5, label)
+	c.page.SetColor(pdf.Black)
+	for _, line := range lines {
+		c.page.Text(c.x+labelWidth, c.y, pdf.Helvetica, 9.5, line)
+		c.y += 13
+	}
+	c.y += 2
+}
+
+func (c *reportColumn) note(text string) {
+	if !c.room(14) {
+		return
+	}
+	c.page.SetColor(reportMuted)
+	c.page.Text(c.x, c.y, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, text, c.width, "…"))
+	c.y += 15
+}
+
+func (c *reportColumn) paragraph(text string) {
+	c.page.SetColor(pdf.Black)
+	for _, line := range pdf.Wrap(pdf.Helvetica, 9.5, text, c.width) {
+		if !c.room(13) {
+			return
+		}
+		c.page.Text(c.x, c.y, pdf.Helvetica, 9.5, line)
+		c.y += 13
+	}
+}
+
+// scoreBars draws the points of each score category as a bar out of the 100 points of a score.
+func (c *reportColumn) scoreBars(report *entity.LeadReport) {
+	if report.Score == nil {
+		c.note("Not scored yet: the score is computed once the company is enriched.")
+		return
+	}
+	categories := make([]string, 0, len(report.ScoreBreakdown))
+	for category := range report.ScoreBreakdown {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	const labelWidth, valueWidth = 130.0, 30.0
+	barWidth := c.width - labelWidth - valueWidth
+	for _, category := range categories {
+		if !c.room(16) {
+			return
+		}
+		points := report.ScoreBreakdown[category]
+		if points < 0 {
+			points = 0
+		} else if points > 100 {
+			points = 100
+		}
+		c.page.SetColor(pdf.Black)
+		c.page.Text(c.x, c.y, pdf.Helvetica, 9.5, humanizeKey(category))
+		c.page.SetColor(reportBarTrack)
+		c.page.Rect(c.x+labelWidth, c.y-8, barWidth, 9)
+		c.page.SetColor(reportAccent)
+		c.page.Rect(c.x+labelWidth, c.y-8, barWidth*float64(points)/100, 9)
+		c.page.SetColor(pdf.Black)
+		c.page.Text(c.x+labelWidth+barWidth+8, c.y, pdf.HelveticaBold, 9.5, strconv.Itoa(report.ScoreBreakdown[category]))
+		c.y += 16
+	}
+	if report.ScoredAt != nil {
+		c.note(fmt.Sprintf("%d of 100 points, computed %s.", *report.Score, report.ScoredAt.UTC().Format("2006-01-02")))
+	}
+}
+
+func websiteLine(company entity.Company) string {
+	website := derefString(company.Website)
+	if website == "" {
+		return ""
+	}
+	if status := derefString(company.WebsiteStatus); status != "" {
+		website += " (" + status + ")"
+	}
+	return website
+}
+
+func ratingLine(company entity.Company) string {
+	if company.Rating == nil {
+		return ""
+	}
+	line := fmt.Sprintf("%.1f of 5", *company.Rating)
+	if company.Reviews != nil {
+		line += fmt.Sprintf(" from %d reviews", *company.Reviews)
+	}
+	return line
+}
+
+func joinPresent(sep string, values ...*string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if v := derefString(value); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func limitStrings(values []string, limit int) []string {
+	if len(values) <= limit {
+		return values
+	}
+	return append(append([]string(nil), values[:limit]...), fmt.Sprintf("+%d more", len(values)-limit))
+}
+
+// humanizeKey turns keys such as contact_completeness into labels.
+func humanizeKey(key string) string {
+	label := strings.ReplaceAll(key, "_", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// reportSlug turns a company name into a file name part.
+func reportSlug(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(sb.String(), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimSuffix(slug[:60], "-")
+	}
+	if slug == "" {
+		return "company"
+	}
+	return slug
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type stubLeadReports map[uuid.UUID]entity.LeadReport
+
+func (s stubLeadReports) LeadReports(ctx context.Context, ids []uuid.UUID) ([]entity.LeadReport, error) {
+	reports := make([]entity.LeadReport, 0, len(ids))
+	for _, id := range ids {
+		if report, ok := s[id]; ok {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+func TestLeadReportServiceCompanyReport(t *testing.T) {
+	var mapQuery string
+	maps := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mapQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "image/png")
+		_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 8, 5)))
+	}))
+	defer maps.Close()
+
+	lat, lng, score := -6.2, 106.8, 72
+	company := entity.Company{ID: uuid.New(), Company: "Kopi Kenangan (Senayan)", City: stringPtr("Jakarta"), Latitude: &lat, Longitude: &lng}
+	svc := NewLeadReportService(stubLeadReports{company.ID: {
+		Company:        company,
+		Emails:         []string{"halo@kopi.co.id"},
+		Socials:        map[string][]string{"instagram": {"https://instagram.com/kopi"}},
+		Score:          &score,
+		ScoreBreakdown: map[string]int{"contact_completeness": 30, "social_presence": 12},
+	}}, NewCompaniesService(&mockCompaniesRepository{}), WithReportMaps(maps.Client(), "maps-key"))
+	svc.staticMapURL = maps.URL
+
+	stamp := NewExportStamp(uuid.NewString(), "sales@example.com", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	if _, err := svc.CompanyReport(context.Background(), "nope", stamp); !errors.Is(err, ErrInvalidCompanyID) {
+		t.Fatalf("expected ErrInvalidCompanyID, got %v", err)
+	}
+	if _, err := svc.CompanyReport(context.Background(), uuid.NewString(), stamp); !errors.Is(err, ErrCompanyNotFound) {
+		t.Fatalf("expected ErrCompanyNotFound, got %v", err)
+	}
+
+	report, err := svc.CompanyReport(context.Background(), company.ID.String(), stamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Name != "lead-kopi-kenangan-senayan-20260302" || report.Companies != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if !strings.Contains(mapQuery, "center=-6.200000%2C106.800000") || !strings.Contains(mapQuery, "key=maps-key") {
+		t.Fatalf("unexpected map query: %s", mapQuery)
+	}
+	var buf bytes.Buffer
+	if _, err := report.WriteTo(&buf); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"%PDF-1.4", "/Count 1", "/Filter /DCTDecode", "/Title (Lead sheet: Kopi Kenangan \\(Senayan\\))"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report is missing %q", want)
+		}
+	}
+}
+
+func TestLeadReportServiceBatchReport(t *testing.T) {
+	kept, suppressed := entity.Company{ID: uuid.New(), Company: "Kept"}, entity.Company{ID: uuid.New(), Company: "Suppressed", DoNotContact: true}
+	var listed dto.ListFilter
+	repo := &mockCompaniesRepository{list: func(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+		listed = filter
+		return []entity.Company{suppressed, kept}, nil
+	}}
+	svc := NewLeadReportService(stubLeadReports{
+		kept.ID:       {Company: kept},
+		suppressed.ID: {Company: suppressed},
+	}, NewCompaniesService(repo))
+	stamp := NewExportStamp(uuid.NewString(), "", time.Now())
+
+	report, err := svc.BatchReport(context.Background(), dto.ListFilter{City: "Jakarta"}, stamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if listed.Limit != DefaultLeadReportLimit || report.Companies != 1 {
+		t.Fatalf("expected one page of the default limit, got %d pages of limit %d", report.Companies, listed.Limit)
+	}
+
+	report, err = svc.BatchReport(context.Background(), dto.ListFilter{Limit: 1000, IncludeSuppressed: true}, stamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if listed.Limit != MaxLeadReportLimit || report.Companies != 2 {
+		t.Fatalf("expected two pages of the max limit, got %d pages of limit %d", report.Companies, listed.Limit)
+	}
+
+	repo.list = func(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+		return []entity.Company{suppressed}, nil
+	}
+	if _, err := svc.BatchReport(context.Background(), dto.ListFilter{}, stamp); !errors.Is(err, ErrNoLeadsToReport) {
+		t.Fatalf("expected ErrNoLeadsToReport, got %v", err)
+	}
+	if _, err := NewLeadReportService(nil, NewCompaniesService(repo)).BatchReport(context.Background(), dto.ListFilter{}, stamp); !errors.Is(err, ErrLeadReportsUnavailable) {
+		t.Fatalf("expected ErrLeadReportsUnavailable, got %v", err)
+	}
+}
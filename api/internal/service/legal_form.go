@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/legalform"
+)
+
+// LegalFormConflict is the RecomputeLegalForms count of companies left with their full name because
+// their display name is already taken by another company at the same address.
+const LegalFormConflict = "conflict"
+
+// ErrLegalFormsUnavailable is returned when legal form recomputation runs without a repository.
+var ErrLegalFormsUnavailable = errors.New("legal form extraction not configured")
+
+// WithLegalForms enables recomputation of legal forms and display names.
+func WithLegalForms(legalForms repository.LegalFormRepository) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.legalForms = legalForms
+	}
+}
+
+// applyLegalForm sets the legal form carried by the company name and the name without it.
+func applyLegalForm(company *entity.Company) {
+	extraction := legalform.Extract(company.Company)
+	company.DisplayName = &extraction.DisplayName
+	company.LegalForm = nil
+	if extraction.Form != "" {
+		company.LegalForm = &extraction.Form
+	}
+}
+
+// RecomputeLegalForms extracts the legal form and display name of every company again, paging by
+// company id. Counts are keyed by legal form, legalform.None for none; companies whose display
+// name would duplicate another company are left alone and counted as LegalFormConflict.
+func (s *MaintenanceService) RecomputeLegalForms(ctx context.Context, batchSize int) (map[string]int, error) {
+	counts := make(map[string]int)
+	if s.legalForms == nil {
+		return counts, ErrLegalFormsUnavailable
+	}
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+
+	var after uuid.UUID
+	for {
+		batch, err := s.legalForms.ListCompanyNamesAfter(ctx, after, batchSize)
+		if err != nil {
+			return counts, err
+		}
+		for _, name := range batch {
+			extraction := legalform.Extract(name.Company)
+			var form *string
+			if extraction.Form != "" {
+				form = &extraction.Form
+			}
+			err := s.legalForms.UpdateLegalForm(ctx, name.CompanyID, form, extraction.DisplayName)
+			switch {
+			case errors.Is(err, repository.ErrDisplayNameTaken):
+				log.Printf("company %s: display name %q is taken at its address; merge the duplicates and run again", name.CompanyID, extraction.DisplayName)
+				counts[LegalFormConflict]++
+			case err != nil:
+				return counts, err
+			case form == nil:
+				counts[legalform.None]++
+			default:
+				counts[*form]++
+			}
+		}
+		if len(batch) < batchSize {
+			return counts, nil
+		}
+		after = batch[len(batch)-1].CompanyID
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/legalform"
+)
+
+type legalFormUpdate struct {
+	form        *string
+	displayName string
+}
+
+type mockLegalFormRepository struct {
+	names   []entity.CompanyName
+	taken   map[string]bool
+	updated map[uuid.UUID]legalFormUpdate
+}
+
+func (m *mockLegalFormRepository) ListCompanyNamesAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyName, error) {
+	var page []entity.CompanyName
+	for _, name := range m.names {
+		if strings.Compare(name.CompanyID.String(), after.String()) > 0 && len(page) < limit {
+			page = append(page, name)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockLegalFormRepository) UpdateLegalForm(ctx context.Context, companyID uuid.UUID, legalForm *string, displayName string) error {
+	if m.taken[displayName] {
+		return repository.ErrDisplayNameTaken
+	}
+	if m.updated == nil {
+		m.updated = make(map[uuid.UUID]legalFormUpdate)
+	}
+	m.updated[companyID] = legalFormUpdate{form: legalForm, displayName: displayName}
+	return nil
+}
+
+func TestMaintenanceService_RecomputeLegalForms(t *testing.T) {
+	legalForms := &mockLegalFormRepository{
+		names: []entity.CompanyName{
+			{CompanyID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Company: "PT Maju Jaya"},
+			{CompanyID: uuid.MustParse("22222222-2222-2222-2222-222222222222"), Company: "Kopi Kenangan"},
+			{CompanyID: uuid.MustParse("33333333-3333-3333-3333-333333333333"), Company: "CV. Sinar Abadi"},
+			{CompanyID: uuid.MustParse("44444444-4444-4444-4444-444444444444"), Company: "PT Sumber Rejeki"},
+		},
+		taken: map[string]bool{"Sumber Rejeki": true},
+	}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).RecomputeLegalForms(context.Background(), 2); !errors.Is(err, ErrLegalFormsUnavailable) {
+		t.Fatalf("expected ErrLegalFormsUnavailable, got %v", err)
+	}
+
+	counts, err := NewMaintenanceService(&mockMaintenanceRepository{}, WithLegalForms(legalForms)).RecomputeLegalForms(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts["PT"] != 1 || counts["CV"] != 1 || counts[legalform.None] != 1 || counts[LegalFormConflict] != 1 || len(legalForms.updated) != 3 {
+		t.Fatalf("unexpected recompute result: %v (%v)", counts, legalForms.updated)
+	}
+	got := legalForms.updated[uuid.MustParse("11111111-1111-1111-1111-111111111111")]
+	if got.form == nil || *got.form != "PT" || got.displayName != "Maju Jaya" {
+		t.Fatalf("unexpected update %+v", got)
+	}
+	if got := legalForms.updated[uuid.MustParse("22222222-2222-2222-2222-222222222222")]; got.form != nil || got.displayName != "Kopi Kenangan" {
+		t.Fatalf("expected names without a form to keep their name, got %+v", got)
+	}
+}
+
+func TestCompaniesService_ImportCompaniesCSV_ExtractsLegalForm(t *testing.T) {
+	var received []repository.BulkUpsertCompanyInput
+	repo := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	}
+	csv := strings.Join(requiredCSVHeaders, ",") + "\nPT Maju Jaya,Jl. Sudirman 1,,,,,,,\nWarung Bu Ani,Jl. Thamrin 2,,,,,,,\n"
+
+	if _, err := NewCompaniesService(repo).ImportCompaniesCSV(context.Background(), strings.NewReader(csv), repository.ImportModeOverwrite, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("unexpected records %+v", received)
+	}
+	if rec := received[0]; rec.Company != "PT Maju Jaya" || rec.DisplayName != "Maju Jaya" || rec.LegalForm == nil || *rec.LegalForm != "PT" {
+		t.Fatalf("expected the legal form extracted, got %+v", rec)
+	}
+	if rec := received[1]; rec.DisplayName != "Warung Bu Ani" || rec.LegalForm != nil {
+		t.Fatalf("expected no legal form, got %+v", rec)
+	}
+}
+
+func TestCompaniesService_UpsertCompany_ExtractsLegalForm(t *testing.T) {
+	var saved *entity.Company
+	repo := &mockCompaniesRepository{upsert: func(ctx context.Context, company *entity.Company) error {
+		saved = company
+		return nil
+	}}
+
+	if err := NewCompaniesService(repo).UpsertCompany(context.Background(), &entity.Company{Company: "Bintang Timur, CV"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.LegalForm == nil || *saved.LegalForm != "CV" || saved.DisplayName == nil || *saved.DisplayName != "Bintang Timur" {
+		t.Fatalf("unexpected company %+v", saved)
+	}
+}
@@ -0,0 +1,90 @@
+package legalform
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Forms lists the legal forms extracted from company names, as they are stored.
+var Forms = []string{"PT", "CV", "UD", "PD", "Firma", "Koperasi", "Yayasan", "Perum"}
+
+// None is the filter value matching companies whose name carries no legal form.
+const None = "none"
+
+// Extraction is a company name split into its legal form and the name to display.
+type Extraction struct {
+	// Form is one of Forms, empty when the name carries none.
+	Form string
+	// DisplayName is the name without the legal form and listing markers such as Tbk.
+	DisplayName string
+}
+
+// prefixPattern matches a legal form at the start of a name. Forms must be followed by a dot or a
+// space, so names such as "Ptolemy" or "Cvetko" are left alone; Fa. and Kop. need their dot, as
+// they double as words.
+var prefixPattern = regexp.MustCompile(`(?i)^(?:(p\.?\s?t|c\.?\s?v|u\.?\s?d|p\.?\s?d|firma|koperasi|yayasan|perum)(?:\.\s*|\s+)|(fa|kop)\.\s*)`)
+
+// suffixPattern matches a legal form appended after a comma, as in "Maju Jaya, PT".
+var suffixPattern = regexp.MustCompile(`(?i),\s*(p\.?\s?t|c\.?\s?v|u\.?\s?d|p\.?\s?d)\.?$`)
+
+// markerPattern matches the state ownership and listing markers trailing PT names.
+var markerPattern = regexp.MustCompile(`(?i)(\s*\(persero\)|\s*,?\s*\btbk\.?)+$`)
+
+// forms maps a matched legal form, lowercased and without dots or spaces, onto its stored form.
+var forms = map[string]string{
+	"pt": "PT", "cv": "CV", "ud": "UD", "pd": "PD",
+	"fa": "Firma", "firma": "Firma", "koperasi": "Koperasi", "kop": "Koperasi",
+	"yayasan": "Yayasan", "perum": "Perum",
+}
+
+// Extract splits name into its legal form and display name. A name that is nothing but a legal
+// form keeps it as display name.
+func Extract(name string) Extraction {
+	name = strings.Join(strings.Fields(name), " ")
+	result := Extraction{DisplayName: name}
+
+	rest := name
+	if match := prefixPattern.FindStringSubmatchIndex(rest); match != nil {
+		form := match[2:4]
+		if form[0] < 0 {
+			form = match[4:6]
+		}
+		result.Form = forms[key(rest[form[0]:form[1]])]
+		rest = rest[match[1]:]
+	} else if match := suffixPattern.FindStringSubmatchIndex(rest); match != nil {
+		result.Form = forms[key(rest[match[2]:match[3]])]
+		rest = rest[:match[0]]
+	}
+	if stripped := markerPattern.ReplaceAllString(rest, ""); stripped != rest {
+		if result.Form == "" {
+			// Only limited companies are listed or state owned.
+			result.Form = "PT"
+		}
+		rest = stripped
+	}
+
+	rest = strings.Trim(rest, " ,.-")
+	if rest == "" {
+		return Extraction{DisplayName: name}
+	}
+	result.DisplayName = rest
+	return result
+}
+
+// Normalize returns the stored form of raw, or an empty string when it is not one of Forms.
+func Normalize(raw string) string {
+	return forms[key(raw)]
+}
+
+// NormalizeFilter validates a legal_form filter value, accepting None for names without a form.
+func NormalizeFilter(raw string) (string, bool) {
+	if strings.EqualFold(strings.TrimSpace(raw), None) {
+		return None, true
+	}
+	form := Normalize(raw)
+	return form, form != ""
+}
+
+func key(raw string) string {
+	return strings.NewReplacer(".", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(raw)))
+}
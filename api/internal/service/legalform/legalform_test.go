@@ -0,0 +1,53 @@
+package legalform
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	cases := map[string]Extraction{
+		"PT Maju Jaya":                      {Form: "PT", DisplayName: "Maju Jaya"},
+		"pt. maju jaya":                     {Form: "PT", DisplayName: "maju jaya"},
+		"P.T. Sinar  Abadi":                 {Form: "PT", DisplayName: "Sinar Abadi"},
+		"PT.Sumber Rejeki":                  {Form: "PT", DisplayName: "Sumber Rejeki"},
+		"CV. Bintang Timur":                 {Form: "CV", DisplayName: "Bintang Timur"},
+		"UD Sumber Makmur":                  {Form: "UD", DisplayName: "Sumber Makmur"},
+		"Koperasi Karyawan Sejahtera":       {Form: "Koperasi", DisplayName: "Karyawan Sejahtera"},
+		"Yayasan Pendidikan Harapan":        {Form: "Yayasan", DisplayName: "Pendidikan Harapan"},
+		"Maju Jaya, PT":                     {Form: "PT", DisplayName: "Maju Jaya"},
+		"Bintang Timur, CV.":                {Form: "CV", DisplayName: "Bintang Timur"},
+		"PT Bank Central Asia Tbk":          {Form: "PT", DisplayName: "Bank Central Asia"},
+		"PT Telkom Indonesia (Persero) Tbk": {Form: "PT", DisplayName: "Telkom Indonesia"},
+		"Bank Rakyat Indonesia (Persero)":   {Form: "PT", DisplayName: "Bank Rakyat Indonesia"},
+		"Ptolemy Coffee":                    {DisplayName: "Ptolemy Coffee"},
+		"Kopi Kenangan":                     {DisplayName: "Kopi Kenangan"},
+		"Cvetko Bakery":                     {DisplayName: "Cvetko Bakery"},
+		"PT":                                {DisplayName: "PT"},
+		"  Warung   Bu Ani ":                {DisplayName: "Warung Bu Ani"},
+		"Kop. Unit Desa Makmur":             {Form: "Koperasi", DisplayName: "Unit Desa Makmur"},
+		"Kop Susu Bandung":                  {DisplayName: "Kop Susu Bandung"},
+		"Kopitbk":                           {DisplayName: "Kopitbk"},
+	}
+	for name, want := range cases {
+		if got := Extract(name); got != want {
+			t.Errorf("Extract(%q) = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestNormalizeFilter(t *testing.T) {
+	cases := map[string]struct {
+		want string
+		ok   bool
+	}{
+		"pt":     {"PT", true},
+		" C.V. ": {"CV", true},
+		"firma":  {"Firma", true},
+		"NONE":   {None, true},
+		"gmbh":   {"", false},
+		"":       {"", false},
+	}
+	for raw, want := range cases {
+		if got, ok := NormalizeFilter(raw); got != want.want || ok != want.ok {
+			t.Errorf("NormalizeFilter(%q) = %q, %v; want %q, %v", raw, got, ok, want.want, want.ok)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/geocode"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrInvalidLocation is returned when a location payload fails validation.
+	ErrInvalidLocation = errors.New("invalid location")
+	// ErrInvalidLocationID is returned when a location identifier cannot be parsed as UUID.
+	ErrInvalidLocationID = errors.New("invalid location id")
+	// ErrLocationNotFound indicates the requested location does not exist.
+	ErrLocationNotFound = errors.New("location not found")
+	// ErrLocationExists is returned when the city is already in the catalog for its country.
+	ErrLocationExists = errors.New("location already exists")
+)
+
+const (
+	// DefaultLocationSearchLimit is the number of locations GET /locations returns by default.
+	DefaultLocationSearchLimit = 10
+	// MaxLocationSearchLimit caps the limit of GET /locations.
+	MaxLocationSearchLimit = 50
+)
+
+// locationIndex is an immutable snapshot of the catalog keyed by lowercased city name and alias.
+// Refreshes build a new index and swap it in whole, like the prompt parser's aliases.
+type locationIndex struct {
+	byName map[string][]entity.Location
+}
+
+func newLocationIndex(locations []entity.Location) *locationIndex {
+	index := &locationIndex{byName: make(map[string][]entity.Location)}
+	for _, location := range locations {
+		names := append([]string{location.City}, location.Aliases...)
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			key := locationKey(name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			index.byName[key] = append(index.byName[key], location)
+		}
+	}
+	return index
+}
+
+// LocationService manages the location catalog and normalises city values against it.
+type LocationService struct {
+	repo repository.LocationsRepository
+	// geocoder locates cities stored without coordinates; nil stores them as sent.
+	geocoder geocode.Geocoder
+	index    atomic.Pointer[locationIndex]
+}
+
+// LocationServiceOption customises optional LocationService collaborators.
+type LocationServiceOption func(*LocationService)
+
+// WithGeocoder geocodes locations created or updated without a bounding box.
+func WithGeocoder(geocoder geocode.Geocoder) LocationServiceOption {
+	return func(s *LocationService) {
+		s.geocoder = geocoder
+	}
+}
+
+// NewLocationService builds a LocationService with an empty catalog until the first Refresh.
+func NewLocationService(repo repository.LocationsRepository, opts ...LocationServiceOption) *LocationService {
+	svc := &LocationService{repo: repo}
+	svc.index.Store(newLocationIndex(nil))
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// Refresh loads the catalog and swaps it into the normalisation index.
+func (s *LocationService) Refresh(ctx context.Context) error {
+	locations, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.index.Store(newLocationIndex(locations))
+	return nil
+}
+
+// Run refreshes the catalog every interval until ctx is cancelled. A failed refresh is logged and
+// the previous catalog stays in use.
+func (s *LocationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to refresh locations: %v", err)
+			}
+		}
+	}
+}
+
+// NormalizeCity returns the catalog spelling of city when it names exactly one catalog city,
+// matching its name or an alias case-insensitively; country, when set, keeps the cities of that
+// country. Unknown or ambiguous cities are returned unchanged.
+func (s *LocationService) NormalizeCity(city, country string) string {
+	candidates := s.index.Load().byName[locationKey(city)]
+	country = strings.TrimSpace(country)
+	canonical := ""
+	for _, location := range candidates {
+		if country != "" && !strings.EqualFold(location.Country, country) {
+			continue
+		}
+		if canonical != "" && canonical != location.City {
+			return city
+		}
+		canonical = location.City
+	}
+	if canonical == "" {
+		return city
+	}
+	return canonical
+}
+
+// normalizeCityPointer normalises an optional city value of a company.
+func (s *LocationService) normalizeCityPointer(city, country *string) *string {
+	if city == nil {
+		return nil
+	}
+	normalized := s.NormalizeCity(*city, derefString(country))
+	return &normalized
+}
+
+// Search returns the locations whose city or an alias starts with query, for autocomplete.
+func (s *LocationService) Search(ctx context.Context, query, country string, limit int) ([]entity.Location, error) {
+	if limit <= 0 {
+		limit = DefaultLocationSearchLimit
+	}
+	if limit > MaxLocationSearchLimit {
+		limit = MaxLocationSearchLimit
+	}
+	return s.repo.Search(ctx, repository.LocationSearch{
+		Query:   locationKey(query),
+		Country: strings.TrimSpace(country),
+		Limit:   limit,
+	})
+}
+
+// Create stores a new location, geocoding it when it comes without a bounding box, and refreshes
+// the catalog so it applies immediately.
+func (s *LocationService) Create(ctx context.Context, req dto.LocationRequest) (*entity.Location, error) {
+	location, err := buildLocation(req)
+	if err != nil {
+		return nil, err
+	}
+	s.geocode(ctx, location)
+	if err := s.repo.Create(ctx, location); err != nil {
+		return nil, mapLocationError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return location, nil
+}
+
+// Update replaces a location, geocoding it when it comes without a bounding box, and refreshes
+// the catalog.
+func (s *LocationService) Update(ctx context.Context, idRaw string, req dto.LocationRequest) (*entity.Location, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidLocationID
+	}
+	location, err := buildLocation(req)
+	if err != nil {
+		return nil, err
+	}
+	location.ID = id
+	s.geocode(ctx, location)
+	if err := s.repo.Update(ctx, location); err != nil {
+		return nil, mapLocationError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return location, nil
+}
+
+// Delete removes a location and refreshes the catalog.
+func (s *LocationService) Delete(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return ErrInvalidLocationID
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return mapLocationError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return nil
+}
+
+// geocode fills in the coordinates of a location sent without a bounding box. The geocoder is a
+// convenience: when it fails or knows no such city the location is stored without them.
+func (s *LocationService) geocode(ctx context.Context, location *entity.Location) {
+	if s.geocoder == nil || location.BoundingBox != nil {
+		return
+	}
+	result, err := s.geocoder.Geocode(ctx, location.City, location.Country)
+	if err != nil {
+		if !errors.Is(err, geocode.ErrNotFound) {
+			log.Printf("failed to geocode location %s, %s: %v", location.City, location.Country, err)
+		}
+		return
+	}
+	location.BoundingBox = &entity.BoundingBox{South: result.South, West: result.West, North: result.North, East: result.East}
+	if location.Latitude == nil || location.Longitude == nil {
+		location.Latitude, location.Longitude = &result.Latitude, &result.Longitude
+	}
+}
+
+// refreshAfterChange applies a write on this instance right away; other instances pick it up on
+// their next periodic refresh.
+func (s *LocationService) refreshAfterChange(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		log.Printf("failed to refresh locations after change: %v", err)
+	}
+}
+
+func buildLocation(req dto.LocationRequest) (*entity.Location, error) {
+	city := strings.Join(strings.Fields(req.City), " ")
+	country := strings.Join(strings.Fields(req.Country), " ")
+	if city == "" || country == "" {
+		return nil, fmt.Errorf("%w: city and country are required", ErrInvalidLocation)
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude must be set together", ErrInvalidLocation)
+	}
+	if box := req.BoundingBox; box != nil && (box.South > box.North || box.South < -90 || box.North > 90) {
+		return nil, fmt.Errorf("%w: bounding_box must have south <= north within -90 and 90", ErrInvalidLocation)
+	}
+
+	// Aliases are matched against lowercased city values, so they are stored lowercased.
+	aliases := make([]string, 0, len(req.Aliases))
+	seen := map[string]bool{locationKey(city): true}
+	for _, raw := range req.Aliases {
+		alias := locationKey(raw)
+		if alias == "" || seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	return &entity.Location{
+		City:        city,
+		Region:      normalizeString(derefString(req.Region)),
+		Country:     country,
+		Aliases:     aliases,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		BoundingBox: req.BoundingBox,
+	}, nil
+}
+
+// locationKey is the form city names and aliases are matched in: lowercased with whitespace
+// collapsed.
+func locationKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func mapLocationError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrLocationNotFound):
+		return ErrLocationNotFound
+	case errors.Is(err, repository.ErrLocationDuplicate):
+		return ErrLocationExists
+	default:
+		return err
+	}
+}
+
+// WithLocations normalises the city of companies imported from files or the batch API onto the
+// location catalog.
+func WithLocations(locations *LocationService) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.locations = locations
+	}
+}
+
+// WithIngestLocations normalises the city of streamed places onto the location catalog.
+func WithIngestLocations(locations *LocationService) IngestRunsServiceOption {
+	return func(s *IngestRunsService) {
+		s.locations = locations
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/geocode"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
+)
+
+type mockLocationsRepository struct {
+	locations []entity.Location
+	search    repository.LocationSearch
+}
+
+func (m *mockLocationsRepository) List(ctx context.Context) ([]entity.Location, error) {
+	return append([]entity.Location(nil), m.locations...), nil
+}
+
+func (m *mockLocationsRepository) Search(ctx context.Context, filter repository.LocationSearch) ([]entity.Location, error) {
+	m.search = filter
+	return m.locations, nil
+}
+
+func (m *mockLocationsRepository) Create(ctx context.Context, location *entity.Location) error {
+	for _, existing := range m.locations {
+		if strings.EqualFold(existing.City, location.City) && strings.EqualFold(existing.Country, location.Country) {
+			return repository.ErrLocationDuplicate
+		}
+	}
+	location.ID = uuid.New()
+	m.locations = append(m.locations, *location)
+	return nil
+}
+
+func (m *mockLocationsRepository) Update(ctx context.Context, location *entity.Location) error {
+	return repository.ErrLocationNotFound
+}
+
+func (m *mockLocationsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.ErrLocationNotFound
+}
+
+type stubGeocoder struct {
+	result *geocode.Result
+	err    error
+	calls  int
+}
+
+func (s *stubGeocoder) Geocode(ctx context.Context, city, country string) (*geocode.Result, error) {
+	s.calls++
+	return s.result, s.err
+}
+
+func newTestLocationService(t *testing.T) *LocationService {
+	t.Helper()
+	svc := NewLocationService(&mockLocationsRepository{locations: []entity.Location{
+		{City: "Yogyakarta", Country: "Indonesia", Aliases: []string{"jogja", "yogya"}},
+		{City: "Surakarta", Country: "Indonesia", Aliases: []string{"solo"}},
+		{City: "San Jose", Country: "United States"},
+		{City: "San Jose", Country: "Costa Rica"},
+		{City: "Santiago", Country: "Chile", Aliases: []string{"stgo"}},
+		{City: "Santiago de Compostela", Country: "Spain", Aliases: []string{"santiago"}},
+	}})
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected refresh error: %v", err)
+	}
+	return svc
+}
+
+func TestLocationService_NormalizeCity(t *testing.T) {
+	svc := newTestLocationService(t)
+
+	tests := []struct {
+		city, country, want string
+	}{
+		{"jogja", "Indonesia", "Yogyakarta"},
+		{"  JOGJA ", "", "Yogyakarta"},
+		{"Solo", "indonesia", "Surakarta"},
+		{"san jose", "", "San Jose"},
+		{"Santiago", "", "Santiago"},
+		{"santiago", "Spain", "Santiago de Compostela"},
+		{"santiago", "Chile", "Santiago"},
+		{"jogja", "Malaysia", "jogja"},
+		{"Atlantis", "Indonesia", "Atlantis"},
+		{"", "Indonesia", ""},
+	}
+	for _, tt := range tests {
+		if got := svc.NormalizeCity(tt.city, tt.country); got != tt.want {
+			t.Fatalf("NormalizeCity(%q, %q) = %q, want %q", tt.city, tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestLocationService_Search(t *testing.T) {
+	repo := &mockLocationsRepository{}
+	svc := NewLocationService(repo)
+
+	if _, err := svc.Search(context.Background(), "  Jog  ", " Indonesia ", 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.search != (repository.LocationSearch{Query: "jog", Country: "Indonesia", Limit: MaxLocationSearchLimit}) {
+		t.Fatalf("unexpected search filter: %+v", repo.search)
+	}
+	if _, err := svc.Search(context.Background(), "", "", 0); err != nil || repo.search.Limit != DefaultLocationSearchLimit {
+		t.Fatalf("expected the default limit, got %+v, %v", repo.search, err)
+	}
+}
+
+func TestLocationService_CreateGeocodes(t *testing.T) {
+	repo := &mockLocationsRepository{}
+	geocoder := &stubGeocoder{result: &geocode.Result{Latitude: -6.9, Longitude: 107.6, South: -7.0, West: 107.5, North: -6.8, East: 107.7}}
+	svc := NewLocationService(repo, WithGeocoder(geocoder))
+
+	location, err := svc.Create(context.Background(), dto.LocationRequest{City: " Bandung ", Country: "Indonesia", Aliases: []string{"Kota Kembang", "bandung", "kota  kembang"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.City != "Bandung" || strings.Join(location.Aliases, ",") != "kota kembang" {
+		t.Fatalf("expected trimmed city and deduplicated lowercased aliases, got %+v", location)
+	}
+	if location.BoundingBox == nil || location.BoundingBox.North != -6.8 || location.Latitude == nil || *location.Latitude != -6.9 {
+		t.Fatalf("expected the geocoded area, got %+v", location)
+	}
+	if got := svc.NormalizeCity("KOTA KEMBANG", ""); got != "Bandung" {
+		t.Fatalf("expected the new alias to apply at once, got %q", got)
+	}
+
+	box := &entity.BoundingBox{South: -8.7, West: 115.1, North: -8.6, East: 115.3}
+	if _, err := svc.Create(context.Background(), dto.LocationRequest{City: "Denpasar", Country: "Indonesia", BoundingBox: box}); err != nil || geocoder.calls != 1 {
+		t.Fatalf("expected a location sent with its area not to be geocoded, got %d calls, %v", geocoder.calls, err)
+	}
+
+	geocoder.err = geocode.ErrNotFound
+	location, err = svc.Create(context.Background(), dto.LocationRequest{City: "Atlantis", Country: "Indonesia"})
+	if err != nil || location.BoundingBox != nil {
+		t.Fatalf("expected an unknown city stored without an area, got %+v, %v", location, err)
+	}
+
+	if _, err := svc.Create(context.Background(), dto.LocationRequest{City: "bandung", Country: "indonesia"}); !errors.Is(err, ErrLocationExists) {
+		t.Fatalf("expected ErrLocationExists, got %v", err)
+	}
+	invalid := []dto.LocationRequest{
+		{City: "Bandung"},
+		{City: "Bandung", Country: "Indonesia", Latitude: testsupport.Ptr(-6.9)},
+		{City: "Bandung", Country: "Indonesia", BoundingBox: &entity.BoundingBox{South: 1, North: -1}},
+	}
+	for _, req := range invalid {
+		if _, err := svc.Create(context.Background(), req); !errors.Is(err, ErrInvalidLocation) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+	if _, err := svc.Update(context.Background(), "not-a-uuid", dto.LocationRequest{City: "Bandung", Country: "Indonesia"}); !errors.Is(err, ErrInvalidLocationID) {
+		t.Fatalf("expected ErrInvalidLocationID, got %v", err)
+	}
+	if err := svc.Delete(context.Background(), uuid.NewString()); !errors.Is(err, ErrLocationNotFound) {
+		t.Fatalf("expected ErrLocationNotFound, got %v", err)
+	}
+}
+
+func TestLocationService_NormalizesCities(t *testing.T) {
+	locations := newTestLocationService(t)
+
+	var received []repository.BulkUpsertCompanyInput
+	companies := &mockCompaniesRepository{
+		bulk: func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error) {
+			received = records
+			return repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}, nil
+		},
+	}
+	csv := strings.Join(requiredCSVHeaders, ",") + "\n" + "Gudeg Yu Djum,Jl. Wijilan,,,,,Restaurant,jogja,Indonesia\n"
+	if _, err := NewCompaniesService(companies, WithLocations(locations)).ImportCompaniesCSV(context.Background(), strings.NewReader(csv), repository.ImportModeOverwrite, nil); err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+	if len(received) != 1 || received[0].City == nil || *received[0].City != "Yogyakarta" {
+		t.Fatalf("expected the imported city normalised, got %+v", received)
+	}
+
+	runs := newMockIngestRunsRepository()
+	ingest := NewIngestRunsService(runs, WithIngestLocations(locations))
+	items := []dto.IngestItem{{Name: "Serabi Notosuman", City: testsupport.Ptr("Solo"), Country: testsupport.Ptr("Indonesia"), RawSnapshot: json.RawMessage(`{"place_id":"ChIJ789"}`)}}
+	if _, err := ingest.AppendBatch(context.Background(), uuid.NewString(), dto.IngestBatchRequest{Sequence: intPtr(0), Items: items}); err != nil {
+		t.Fatalf("unexpected ingest error: %v", err)
+	}
+	if city := runs.companies[0].City; city == nil || *city != "Surakarta" {
+		t.Fatalf("expected the scraped city normalised, got %v", city)
+	}
+
+	prompt := NewPromptService("Indonesia")
+	prompt.SetLocations(locations)
+	result, err := prompt.Parse(dto.PromptSearchRequest{Prompt: "cari cafe di solo"})
+	if err != nil || result.City != "Surakarta" {
+		t.Fatalf("expected the prompted city normalised, got %+v, %v", result, err)
+	}
+}
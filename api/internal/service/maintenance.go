@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/fieldcrypt"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/outreach"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+	"github.com/octobees/leads-generator/api/internal/service/sizing"
+)
+
+const defaultScoreBatchSize = 500
+
+var (
+	// ErrInvalidScrapeRunID is returned when the scrape run identifier cannot be parsed as UUID.
+	ErrInvalidScrapeRunID = errors.New("invalid scrape run id")
+	// ErrSizingUnavailable is returned when size recomputation runs without a size repository.
+	ErrSizingUnavailable = errors.New("size estimation not configured")
+)
+
+// MaintenanceService implements operational tasks run from the admin CLI.
+type MaintenanceService struct {
+	repo    repository.MaintenanceRepository
+	sizes   repository.CompanySizeRepository
+	scoring scoring.Options
+
+	languages     repository.OutreachLanguageRepository
+	profiles      repository.ScoringProfilesRepository
+	legalForms    repository.LegalFormRepository
+	contactFields repository.ContactNormalizationRepository
+	placeAttrs    repository.PlaceAttributesRepository
+	rawStore      BlobStore
+	contacts      repository.EnrichmentContactsRepository
+	contactTerms  repository.ContactTermsRepository
+	cipher        *fieldcrypt.Cipher
+}
+
+// MaintenanceServiceOption customises optional MaintenanceService collaborators.
+type MaintenanceServiceOption func(*MaintenanceService)
+
+// WithSizing enables size recomputation and the size factor when recomputing scores.
+func WithSizing(sizes repository.CompanySizeRepository, opts scoring.Options) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.sizes = sizes
+		s.scoring = opts
+	}
+}
+
+// WithOutreachLanguages enables recomputation of the preferred outreach language.
+func WithOutreachLanguages(languages repository.OutreachLanguageRepository) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.languages = languages
+	}
+}
+
+// WithScoringProfiles scores with the active scoring profile instead of the built-in weights.
+func WithScoringProfiles(profiles repository.ScoringProfilesRepository) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.profiles = profiles
+	}
+}
+
+// NewMaintenanceService builds a new MaintenanceService instance.
+func NewMaintenanceService(repo repository.MaintenanceRepository, opts ...MaintenanceServiceOption) *MaintenanceService {
+	svc := &MaintenanceService{repo: repo, scoring: scoring.DefaultOptions()}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// ReindexSearch rebuilds the company search indexes.
+func (s *MaintenanceService) ReindexSearch(ctx context.Context) error {
+	return s.repo.ReindexCompanies(ctx)
+}
+
+// RebuildLeadsView recomputes the listing rows of every company and returns how many there are.
+func (s *MaintenanceService) RebuildLeadsView(ctx context.Context) (int64, error) {
+	return s.repo.RebuildLeadsView(ctx)
+}
+
+// PurgeScrapeRun removes all companies of a scrape run. With dryRun it only reports the count.
+func (s *MaintenanceService) PurgeScrapeRun(ctx context.Context, runIDRaw string, dryRun bool) (int64, error) {
+	runID, err := uuid.Parse(strings.TrimSpace(runIDRaw))
+	if err != nil {
+		return 0, ErrInvalidScrapeRunID
+	}
+	if dryRun {
+		return s.repo.CountScrapeRunCompanies(ctx, runID)
+	}
+	return s.repo.PurgeScrapeRun(ctx, runID)
+}
+
+// RecomputeScores recalculates and stores the lead score of every enriched company with the
+// active scoring profile. It pages through enrichments by company id so memory stays bounded on
+// large catalogues.
+func (s *MaintenanceService) RecomputeScores(ctx context.Context, batchSize int) (int, error) {
+	return s.recomputeScores(ctx, batchSize, false)
+}
+
+// RecomputeStaleScores recalculates only the scores that are missing or were computed with another
+// scoring profile or an older revision of the active one, e.g. after an admin changed its weights.
+func (s *MaintenanceService) RecomputeStaleScores(ctx context.Context, batchSize int) (int, error) {
+	return s.recomputeScores(ctx, batchSize, true)
+}
+
+func (s *MaintenanceService) recomputeScores(ctx context.Context, batchSize int, staleOnly bool) (int, error) {
+	return s.RecomputeScoresFrom(ctx, ScoreRecomputeOptions{BatchSize: batchSize, StaleOnly: staleOnly})
+}
+
+// ScoreRecomputeOptions tunes RecomputeScoresFrom.
+type ScoreRecomputeOptions struct {
+	BatchSize int
+	StaleOnly bool
+	// After resumes a previous recomputation after the last company it scored.
+	After uuid.UUID
+	// OnStart, if set, receives the number of enrichments left to score before the first batch.
+	OnStart func(total int) error
+	// OnBatch, if set, runs after every stored batch with the last company scored and the batch size,
+	// so callers can record where to resume.
+	OnBatch func(last uuid.UUID, scored int) error
+}
+
+// RecomputeScoresFrom recalculates stored lead scores like RecomputeScores or RecomputeStaleScores,
+// starting after opts.After and reporting progress per batch. Scoring is idempotent, so resuming
+// after an interruption rescores at most the batch that was cut short.
+func (s *MaintenanceService) RecomputeScoresFrom(ctx context.Context, opts ScoreRecomputeOptions) (int, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+	profile, active, err := resolveScoringProfile(ctx, s.profiles, s.scoring)
+	if err != nil {
+		return 0, fmt.Errorf("load scoring profile: %w", err)
+	}
+	var (
+		profileID *uuid.UUID
+		revision  int
+	)
+	if active != nil {
+		profileID, revision = &active.ID, active.Revision
+	}
+
+	after := opts.After
+	if opts.OnStart != nil {
+		remaining, err := s.repo.CountEnrichmentsAfter(ctx, after, opts.StaleOnly, profileID, revision)
+		if err != nil {
+			return 0, err
+		}
+		if err := opts.OnStart(remaining); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int
+	for {
+		var batch []entity.CompanyEnrichment
+		if opts.StaleOnly {
+			batch, err = s.repo.ListStaleEnrichmentsAfter(ctx, after, batchSize, profileID, revision)
+		} else {
+			batch, err = s.repo.ListEnrichmentsAfter(ctx, after, batchSize)
+		}
+		if err != nil {
+			return total, err
+		}
+		for i := range batch {
+			features := scoring.FeaturesFromEnrichment(&batch[i])
+			if profile.UsesSize() {
+				features.SizeBucket = lookupSizeBucket(ctx, s.sizes, batch[i].CompanyID)
+			}
+			score := scoring.ComputeScoreWithProfile(features, profile)
+			if err := s.repo.UpsertLeadScore(ctx, batch[i].CompanyID, score.Total, score.Breakdown, profileID, revision); err != nil {
+				return total, err
+			}
+			total++
+		}
+		if len(batch) > 0 {
+			after = batch[len(batch)-1].CompanyID
+			if opts.OnBatch != nil {
+				if err := opts.OnBatch(after, len(batch)); err != nil {
+					return total, err
+				}
+			}
+		}
+		if len(batch) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// RecomputeSizes re-estimates and stores the size bucket of every company, paging by company id.
+func (s *MaintenanceService) RecomputeSizes(ctx context.Context, batchSize int) (map[string]int, error) {
+	counts := make(map[string]int)
+	if s.sizes == nil {
+		return counts, ErrSizingUnavailable
+	}
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+
+	var after uuid.UUID
+	for {
+		batch, err := s.sizes.ListSizeSignalsAfter(ctx, after, batchSize)
+		if err != nil {
+			return counts, err
+		}
+		for i := range batch {
+			estimate := sizing.EstimateSize(sizeSignals(&batch[i]))
+			if err := s.sizes.UpdateSize(ctx, batch[i].CompanyID, estimate.Bucket); err != nil {
+				return counts, err
+			}
+			counts[estimate.Bucket]++
+		}
+		if len(batch) < batchSize {
+			return counts, nil
+		}
+		after = batch[len(batch)-1].CompanyID
+	}
+}
+
+// RecomputeOutreachLanguages re-detects and stores the preferred outreach language of every
+// company, paging by company id. Counts are keyed by language, outreach.Unknown for none.
+func (s *MaintenanceService) RecomputeOutreachLanguages(ctx context.Context, batchSize int) (map[string]int, error) {
+	counts := make(map[string]int)
+	if s.languages == nil {
+		return counts, ErrOutreachLanguagesUnavailable
+	}
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+
+	var after uuid.UUID
+	for {
+		batch, err := s.languages.ListLanguageSignalsAfter(ctx, after, batchSize)
+		if err != nil {
+			return counts, err
+		}
+		for i := range batch {
+			detection := outreach.DetectLanguage(languageSignals(&batch[i]))
+			if err := s.languages.UpdateOutreachLanguage(ctx, batch[i].CompanyID, detection.Language, detection.Source); err != nil {
+				return counts, err
+			}
+			if detection.Language == "" {
+				counts[outreach.Unknown]++
+			} else {
+				counts[detection.Language]++
+			}
+		}
+		if len(batch) < batchSize {
+			return counts, nil
+		}
+		after = batch[len(batch)-1].CompanyID
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type mockMaintenanceRepository struct {
+	enrichments []entity.CompanyEnrichment
+	scores      map[uuid.UUID]int
+	revisions   map[uuid.UUID]int
+	counted     bool
+	purged      bool
+}
+
+func (m *mockMaintenanceRepository) ReindexCompanies(ctx context.Context) error { return nil }
+
+func (m *mockMaintenanceRepository) RebuildLeadsView(ctx context.Context) (int64, error) {
+	return int64(len(m.enrichments)), nil
+}
+
+func (m *mockMaintenanceRepository) CountScrapeRunCompanies(ctx context.Context, runID uuid.UUID) (int64, error) {
+	m.counted = true
+	return 3, nil
+}
+
+func (m *mockMaintenanceRepository) PurgeScrapeRun(ctx context.Context, runID uuid.UUID) (int64, error) {
+	m.purged = true
+	return 3, nil
+}
+
+func (m *mockMaintenanceRepository) ListEnrichmentsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.CompanyEnrichment, error) {
+	var page []entity.CompanyEnrichment
+	for _, record := range m.enrichments {
+		if record.CompanyID.String() > after.String() && len(page) < limit {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockMaintenanceRepository) ListStaleEnrichmentsAfter(ctx context.Context, after uuid.UUID, limit int, profileID *uuid.UUID, revision int) ([]entity.CompanyEnrichment, error) {
+	var page []entity.CompanyEnrichment
+	for _, record := range m.enrichments {
+		if record.CompanyID.String() > after.String() && len(page) < limit && m.revisions[record.CompanyID] != revision {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockMaintenanceRepository) CountEnrichmentsAfter(ctx context.Context, after uuid.UUID, staleOnly bool, profileID *uuid.UUID, revision int) (int, error) {
+	var count int
+	for _, record := range m.enrichments {
+		if record.CompanyID.String() > after.String() && (!staleOnly || m.revisions[record.CompanyID] != revision) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockMaintenanceRepository) UpsertLeadScore(ctx context.Context, companyID uuid.UUID, score int, breakdown map[string]int, profileID *uuid.UUID, revision int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.revisions == nil {
+		m.revisions = make(map[uuid.UUID]int)
+	}
+	m.revisions[companyID] = revision
+	if m.scores == nil {
+		m.scores = make(map[uuid.UUID]int)
+	}
+	m.scores[companyID] = score
+	return nil
+}
+
+func TestMaintenanceService_RecomputeScores(t *testing.T) {
+	repo := &mockMaintenanceRepository{enrichments: []entity.CompanyEnrichment{
+		{CompanyID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Emails: []string{"a@example.com"}},
+		{CompanyID: uuid.MustParse("22222222-2222-2222-2222-222222222222")},
+		{CompanyID: uuid.MustParse("33333333-3333-3333-3333-333333333333"), Phones: []string{"+62"}},
+	}}
+	svc := NewMaintenanceService(repo)
+
+	scored, err := svc.RecomputeScores(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scored != 3 || len(repo.scores) != 3 {
+		t.Fatalf("expected all enrichments scored across pages, got %d (%v)", scored, repo.scores)
+	}
+	if repo.scores[uuid.MustParse("11111111-1111-1111-1111-111111111111")] != 10 {
+		t.Fatalf("unexpected score: %v", repo.scores)
+	}
+}
+
+func TestMaintenanceService_PurgeScrapeRun(t *testing.T) {
+	repo := &mockMaintenanceRepository{}
+	svc := NewMaintenanceService(repo)
+
+	if _, err := svc.PurgeScrapeRun(context.Background(), "nope", false); !errors.Is(err, ErrInvalidScrapeRunID) {
+		t.Fatalf("expected ErrInvalidScrapeRunID, got %v", err)
+	}
+
+	count, err := svc.PurgeScrapeRun(context.Background(), "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", true)
+	if err != nil || count != 3 || !repo.counted || repo.purged {
+		t.Fatalf("expected dry run to only count, got %d %v", count, err)
+	}
+
+	if _, err := svc.PurgeScrapeRun(context.Background(), "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", false); err != nil || !repo.purged {
+		t.Fatalf("expected purge, got %v", err)
+	}
+}
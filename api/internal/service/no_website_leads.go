@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrNoWebsiteLeadsUnavailable is returned when no-website leads are requested without a repository.
+var ErrNoWebsiteLeadsUnavailable = errors.New("no-website leads unavailable")
+
+// NoWebsiteLeadsCSVHeaders are the columns of no-website lead exports: the company export columns
+// followed by the ranking, with the exporter stamp kept last.
+var NoWebsiteLeadsCSVHeaders = []string{"id", "company", "address", "phone", "website", "rating", "reviews", "type_business", "city", "country", "scraped_at", "updated_at", "outreach_language", "shared_contact", "do_not_contact", "has_socials", "opportunity_score", "exported_by"}
+
+// WithNoWebsiteLeads enables the ranked listing and export of companies without a website.
+func WithNoWebsiteLeads(leads repository.NoWebsiteLeadsRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.noWebsiteLeads = leads
+	}
+}
+
+// NoWebsiteLeads returns a page of the companies matching filter that have no website, ranked by
+// opportunity score, flagging those on the do-not-contact list.
+func (s *CompaniesService) NoWebsiteLeads(ctx context.Context, filter dto.ListFilter) ([]entity.NoWebsiteLead, error) {
+	if s.noWebsiteLeads == nil {
+		return nil, ErrNoWebsiteLeadsUnavailable
+	}
+	leads, err := s.noWebsiteLeads.ListNoWebsiteLeads(ctx, noWebsiteFilter(s.withCategoryTaxonomy(normalizeListFilter(filter))))
+	if err != nil {
+		return nil, err
+	}
+	flagged := make([]*entity.Company, len(leads))
+	for i := range leads {
+		flagged[i] = &leads[i].Company
+	}
+	if err := s.flagSuppressed(ctx, flagged); err != nil {
+		return nil, err
+	}
+	return leads, nil
+}
+
+// PrepareNoWebsiteLeadsExport checks an export of the no-website leads matching filter against the
+// row cap of role, like PrepareCompanyExport.
+func (s *CompaniesService) PrepareNoWebsiteLeadsExport(ctx context.Context, filter dto.ListFilter, role string) (*CompanyExport, error) {
+	if s.noWebsiteLeads == nil {
+		return nil, ErrNoWebsiteLeadsUnavailable
+	}
+	return s.PrepareCompanyExport(ctx, noWebsiteFilter(filter), role)
+}
+
+// WriteNoWebsiteLeadsCSV writes a prepared no-website leads export as CSV in locale, best
+// opportunity first, stamping every row with the exporter. Leads on the do-not-contact list are
+// left out unless the filter includes them.
+func (s *CompaniesService) WriteNoWebsiteLeadsCSV(ctx context.Context, export *CompanyExport, stamp ExportStamp, locale ExportLocale, w io.Writer) error {
+	if s.noWebsiteLeads == nil {
+		return ErrNoWebsiteLeadsUnavailable
+	}
+	return writeExportCSV(w, locale, NoWebsiteLeadsCSVHeaders, func(writer *csv.Writer) error {
+		if export.Rows == 0 {
+			return nil
+		}
+		exportedBy := stamp.String()
+		rows := newSuppressionFilter(ctx, s, export.filter.IncludeSuppressed,
+			func(lead *entity.NoWebsiteLead) *entity.Company { return &lead.Company },
+			func(lead entity.NoWebsiteLead) error {
+				record := exportCSVRecord(lead.Company, locale, "")
+				record = append(record[:len(record)-1], strconv.FormatBool(lead.HasSocials), locale.formatFloat(lead.OpportunityScore), exportedBy)
+				return writer.Write(record)
+			})
+		if err := s.noWebsiteLeads.ExportNoWebsiteLeads(ctx, export.filter, export.Rows, rows.add); err != nil {
+			return err
+		}
+		return rows.flush()
+	})
+}
+
+// noWebsiteFilter narrows a listing filter to companies without a website.
+func noWebsiteFilter(filter dto.ListFilter) dto.ListFilter {
+	filter.WebsiteStatus = "missing"
+	return filter
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type stubNoWebsiteLeads struct {
+	stubCompanyExports
+	leads      []entity.NoWebsiteLead
+	listFilter dto.ListFilter
+}
+
+func (s *stubNoWebsiteLeads) ListNoWebsiteLeads(ctx context.Context, filter dto.ListFilter) ([]entity.NoWebsiteLead, error) {
+	s.listFilter = filter
+	return s.leads, nil
+}
+
+func (s *stubNoWebsiteLeads) ExportNoWebsiteLeads(ctx context.Context, filter dto.ListFilter, limit int64, fn func(entity.NoWebsiteLead) error) error {
+	s.exportLimit = limit
+	for _, lead := range s.leads {
+		if err := fn(lead); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCompaniesService_NoWebsiteLeads(t *testing.T) {
+	leads := &stubNoWebsiteLeads{leads: []entity.NoWebsiteLead{{Company: entity.Company{Company: "Acme"}, OpportunityScore: 64}}}
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithNoWebsiteLeads(leads))
+
+	result, err := svc.NoWebsiteLeads(context.Background(), dto.ListFilter{WebsiteStatus: "available", PerPage: 500})
+	if err != nil || len(result) != 1 {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+	filter := leads.listFilter
+	if filter.WebsiteStatus != "missing" || filter.PerPage != 100 || filter.Page != 1 || filter.BusinessStatus != BusinessStatusOperational {
+		t.Fatalf("expected a normalized no-website filter, got %+v", filter)
+	}
+
+	if _, err := NewCompaniesService(&mockCompaniesRepository{}).NoWebsiteLeads(context.Background(), dto.ListFilter{}); !errors.Is(err, ErrNoWebsiteLeadsUnavailable) {
+		t.Fatalf("expected ErrNoWebsiteLeadsUnavailable, got %v", err)
+	}
+}
+
+func TestCompaniesService_WriteNoWebsiteLeadsCSV(t *testing.T) {
+	phone := "+62 21 555"
+	leads := &stubNoWebsiteLeads{leads: []entity.NoWebsiteLead{
+		{Company: entity.Company{ID: uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"), Company: "Acme", Phone: &phone}, HasSocials: true, OpportunityScore: 87.5},
+	}}
+	leads.companies = make([]entity.Company, 1)
+	svc := NewCompaniesService(&mockCompaniesRepository{}, WithExports(leads, map[string]int64{"user": 5}), WithNoWebsiteLeads(leads))
+	ctx := context.Background()
+
+	export, err := svc.PrepareNoWebsiteLeadsExport(ctx, dto.ListFilter{City: "Jakarta"}, "user")
+	if err != nil || export.Rows != 1 {
+		t.Fatalf("unexpected export %+v, %v", export, err)
+	}
+	if leads.lastFilter.WebsiteStatus != "missing" {
+		t.Fatalf("expected the count to cover companies without a website, got %+v", leads.lastFilter)
+	}
+
+	stamp := NewExportStamp("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "sales@example.com", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	if err := svc.WriteNoWebsiteLeadsCSV(ctx, export, stamp, ExportLocale{Delimiter: ";", DecimalMark: ","}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reader := csv.NewReader(&buf)
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 2 || len(records[1]) != len(NoWebsiteLeadsCSVHeaders) {
+		t.Fatalf("unexpected records %v", records)
+	}
+	row := records[1]
+	if row[1] != "Acme" || row[3] != phone || row[13] != "false" || row[14] != "false" || row[15] != "true" || row[16] != "87,5" || row[17] != stamp.String() {
+		t.Fatalf("unexpected row %v", row)
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// ErrReconcileInProgress is returned when a reconciliation is requested while another one runs.
+var ErrReconcileInProgress = errors.New("orphan reconciliation already in progress")
+
+// OrphanReconcileService cleans up enrichments, contacts, lead scores and snapshots left pointing at
+// companies that no longer exist, re-linking them to the company scraped again under the same place
+// id or archiving them.
+type OrphanReconcileService struct {
+	repo repository.OrphansRepository
+	now  func() time.Time
+}
+
+// NewOrphanReconcileService builds an OrphanReconcileService.
+func NewOrphanReconcileService(repo repository.OrphansRepository) *OrphanReconcileService {
+	return &OrphanReconcileService{repo: repo, now: time.Now}
+}
+
+// Reconcile runs a reconciliation and reports what it re-linked and archived; with dryRun nothing
+// is changed.
+func (s *OrphanReconcileService) Reconcile(ctx context.Context, dryRun bool) (*entity.OrphanReport, error) {
+	report := &entity.OrphanReport{RunID: uuid.New(), DryRun: dryRun, StartedAt: s.now().UTC()}
+	tables, err := s.repo.ReconcileOrphans(ctx, report.RunID, dryRun)
+	if err != nil {
+		if errors.Is(err, repository.ErrReconcileInProgress) {
+			return nil, ErrReconcileInProgress
+		}
+		return nil, err
+	}
+	report.Tables = tables
+	for _, table := range tables {
+		report.Relinked += table.Relinked
+		report.Archived += table.Archived
+	}
+	report.FinishedAt = s.now().UTC()
+	return report, nil
+}
+
+// Run reconciles orphans every interval until ctx is cancelled, logging runs that changed anything.
+func (s *OrphanReconcileService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.Reconcile(ctx, false)
+			switch {
+			case err != nil && !errors.Is(err, ErrReconcileInProgress) && ctx.Err() == nil:
+				log.Printf("failed to reconcile orphans: %v", err)
+			case err == nil && report.Relinked+report.Archived > 0:
+				log.Printf("reconciled orphans run_id=%s relinked=%d archived=%d", report.RunID, report.Relinked, report.Archived)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type orphansRepoStub struct {
+	runID  uuid.UUID
+	dryRun bool
+	err    error
+}
+
+func (s *orphansRepoStub) ReconcileOrphans(ctx context.Context, runID uuid.UUID, dryRun bool) ([]entity.OrphanTableReport, error) {
+	s.runID, s.dryRun = runID, dryRun
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []entity.OrphanTableReport{
+		{Table: "company_enrichments", Orphans: 3, Relinked: 1, Archived: 2},
+		{Table: "company_snapshots", Orphans: 4, Relinked: 4},
+	}, nil
+}
+
+func TestOrphanReconcileService_Reconcile(t *testing.T) {
+	repo := &orphansRepoStub{}
+	svc := NewOrphanReconcileService(repo)
+
+	report, err := svc.Reconcile(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.RunID != repo.runID || !repo.dryRun || !report.DryRun {
+		t.Fatalf("expected the run id and dry run passed on, got %+v", report)
+	}
+	if report.Relinked != 5 || report.Archived != 2 || len(report.Tables) != 2 || report.FinishedAt.Before(report.StartedAt) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	repo.err = repository.ErrReconcileInProgress
+	if _, err := svc.Reconcile(context.Background(), false); !errors.Is(err, ErrReconcileInProgress) {
+		t.Fatalf("expected ErrReconcileInProgress, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package outreach
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Languages lists every outreach language a lead can be assigned, as ISO 639-1 codes.
+var Languages = []string{"en", "id", "ms", "th", "vi", "ja", "ko", "zh", "de", "fr", "nl", "es", "pt", "it", "ar"}
+
+// Unknown is the filter value matching leads without a detected language.
+const Unknown = "unknown"
+
+// Sources name the signal a language was taken from.
+const (
+	SourceAbout   = "about"
+	SourceWebsite = "website"
+	SourceCity    = "city"
+	SourceCountry = "country"
+)
+
+// Signals holds what is known about a lead's language; empty means not observed.
+type Signals struct {
+	// WebsiteLanguage is the lang attribute of the company website, e.g. "id-ID".
+	WebsiteLanguage string
+	// AboutSummary is the text of the website's about section.
+	AboutSummary string
+	City         string
+	// Region is the ISO 3166-1 code of the company's country.
+	Region string
+}
+
+// Detection is the outcome of a language detection.
+type Detection struct {
+	// Language is empty when no signal pointed at a supported language.
+	Language string `json:"language"`
+	Source   string `json:"source,omitempty"`
+}
+
+// regionLanguages is the business language assumed for leads of a country.
+var regionLanguages = map[string]string{
+	"ID": "id", "MY": "ms", "BN": "ms", "SG": "en", "PH": "en", "TH": "th", "VN": "vi",
+	"US": "en", "GB": "en", "IE": "en", "CA": "en", "AU": "en", "NZ": "en", "IN": "en", "ZA": "en",
+	"JP": "ja", "KR": "ko", "CN": "zh", "HK": "zh", "TW": "zh",
+	"DE": "de", "AT": "de", "CH": "de", "FR": "fr", "BE": "nl", "NL": "nl",
+	"ES": "es", "MX": "es", "PT": "pt", "BR": "pt", "IT": "it", "AE": "ar", "SA": "ar",
+}
+
+// cityLanguages overrides the country language in cities whose business language differs from it.
+var cityLanguages = map[string]string{
+	"montreal": "fr", "montréal": "fr", "quebec": "fr", "québec": "fr", "quebec city": "fr",
+	"brussels": "fr", "bruxelles": "fr", "liege": "fr", "liège": "fr", "namur": "fr", "charleroi": "fr",
+	"geneva": "fr", "genève": "fr", "lausanne": "fr", "lugano": "it",
+	"dubai": "en", "abu dhabi": "en",
+}
+
+// stopwords are frequent function words that identify the language of a short text.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "for", "with", "our", "we", "are", "your", "you", "that"},
+	"id": {"dan", "yang", "di", "untuk", "dengan", "kami", "adalah", "ini", "dari", "ke", "anda", "dalam", "pada", "tidak", "bisa", "karena"},
+	"de": {"und", "der", "die", "das", "ist", "mit", "für", "wir", "sie", "ein", "eine", "zu", "von", "unsere"},
+	"fr": {"le", "la", "les", "et", "des", "est", "pour", "avec", "nous", "vous", "une", "dans", "du", "notre"},
+	"nl": {"de", "het", "en", "een", "van", "is", "voor", "met", "wij", "we", "onze", "zijn", "op", "uw"},
+	"es": {"el", "la", "los", "las", "y", "de", "es", "para", "con", "nuestro", "nuestra", "somos", "una", "del"},
+	"pt": {"o", "os", "as", "e", "de", "é", "para", "com", "nosso", "nossa", "somos", "uma", "do", "da"},
+	"it": {"il", "lo", "gli", "e", "di", "è", "per", "con", "siamo", "nostro", "nostra", "una", "del", "della"},
+}
+
+// scriptLanguages detects languages written in their own script by the share of its letters.
+var scriptLanguages = []struct {
+	language string
+	table    *unicode.RangeTable
+}{
+	{"th", unicode.Thai},
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"zh", unicode.Han},
+	{"ar", unicode.Arabic},
+}
+
+// minStopwordHits is how many stopwords a text needs before its language is trusted.
+const minStopwordHits = 3
+
+// DetectLanguage picks the language to reach a lead in. Text of the about section decides first
+// because it is what the business actually writes; the website lang attribute comes next since
+// themes often leave it at "en". The city and then the country fill in for leads without a site.
+// Indonesian and Malay share most function words, so text in either follows the country.
+func DetectLanguage(signals Signals) Detection {
+	region := strings.ToUpper(strings.TrimSpace(signals.Region))
+	if language := textLanguage(signals.AboutSummary); language != "" {
+		return Detection{Language: malayOrIndonesian(language, region), Source: SourceAbout}
+	}
+	if language := NormalizeLanguage(signals.WebsiteLanguage); language != "" {
+		return Detection{Language: malayOrIndonesian(language, region), Source: SourceWebsite}
+	}
+	if language, ok := cityLanguages[strings.ToLower(strings.Join(strings.Fields(signals.City), " "))]; ok {
+		return Detection{Language: language, Source: SourceCity}
+	}
+	if language, ok := regionLanguages[region]; ok {
+		return Detection{Language: language, Source: SourceCountry}
+	}
+	return Detection{}
+}
+
+// NormalizeLanguage reduces a language tag such as "id-ID", "en_US" or the legacy "in" to a
+// supported ISO 639-1 code, or returns an empty string.
+func NormalizeLanguage(raw string) string {
+	tag := strings.ToLower(strings.TrimSpace(raw))
+	if primary, _, ok := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-"); ok {
+		tag = primary
+	}
+	switch tag {
+	case "in":
+		tag = "id"
+	case "zsm", "may":
+		tag = "ms"
+	}
+	for _, language := range Languages {
+		if tag == language {
+			return tag
+		}
+	}
+	return ""
+}
+
+func malayOrIndonesian(language, region string) string {
+	if language != "id" && language != "ms" {
+		return language
+	}
+	if regionLanguages[region] == "ms" {
+		return "ms"
+	}
+	if regionLanguages[region] == "id" {
+		return "id"
+	}
+	return language
+}
+
+// textLanguage guesses the language of text from its script, or from its stopwords for Latin
+// scripts; it returns an empty string when the text is too short to tell.
+func textLanguage(text string) string {
+	var letters int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scriptLanguages {
+			if unicode.Is(script.table, r) {
+				scripts[script.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	// Japanese mixes kana with Han characters, so kana among them means Japanese rather than Chinese.
+	if scripts["ja"] > 0 && (scripts["ja"]+scripts["zh"])*2 > letters {
+		return "ja"
+	}
+	for _, script := range scriptLanguages {
+		if scripts[script.language]*2 > letters {
+			return script.language
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	hits := make(map[string]int, len(stopwords))
+	for _, word := range words {
+		for language, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					hits[language]++
+					break
+				}
+			}
+		}
+	}
+	best, bestHits := "", 0
+	for _, language := range Languages {
+		if hits[language] > bestHits {
+			best, bestHits = language, hits[language]
+		}
+	}
+	if bestHits < minStopwordHits {
+		return ""
+	}
+	return best
+}
+
+// NormalizeFilter validates a language given as a listing filter. Besides the supported languages
+// it accepts Unknown, which matches leads without a detected language.
+func NormalizeFilter(raw string) (string, bool) {
+	if strings.EqualFold(strings.TrimSpace(raw), Unknown) {
+		return Unknown, true
+	}
+	language := NormalizeLanguage(raw)
+	return language, language != ""
+}
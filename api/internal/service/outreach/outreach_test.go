@@ -0,0 +1,61 @@
+package outreach
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]struct {
+		signals Signals
+		want    Detection
+	}{
+		"about text beats an english theme": {
+			Signals{WebsiteLanguage: "en-US", AboutSummary: "Kami adalah kedai kopi yang menyajikan kopi terbaik dari seluruh Indonesia untuk anda.", Region: "ID"},
+			Detection{Language: "id", Source: SourceAbout},
+		},
+		"malay text in malaysia": {
+			Signals{AboutSummary: "Kami adalah syarikat yang menyediakan perkhidmatan untuk anda di dalam bandar.", Region: "MY"},
+			Detection{Language: "ms", Source: SourceAbout},
+		},
+		"english about text": {
+			Signals{AboutSummary: "We are a family bakery and we bake fresh bread for the people of Jakarta.", Region: "ID"},
+			Detection{Language: "en", Source: SourceAbout},
+		},
+		"japanese text": {
+			Signals{AboutSummary: "私たちは東京のコーヒーショップです。", Region: "JP"},
+			Detection{Language: "ja", Source: SourceAbout},
+		},
+		"thai text": {
+			Signals{AboutSummary: "ร้านกาแฟของเราตั้งอยู่ในกรุงเทพ", Region: "TH"},
+			Detection{Language: "th", Source: SourceAbout},
+		},
+		"short text falls back to the website": {
+			Signals{WebsiteLanguage: "in", AboutSummary: "Kopi", Region: "ID"},
+			Detection{Language: "id", Source: SourceWebsite},
+		},
+		"city overrides the country": {
+			Signals{City: " Montreal ", Region: "CA"},
+			Detection{Language: "fr", Source: SourceCity},
+		},
+		"country default": {
+			Signals{City: "Surabaya", Region: "id"},
+			Detection{Language: "id", Source: SourceCountry},
+		},
+		"nothing known": {
+			Signals{WebsiteLanguage: "xx", Region: "ZZ"},
+			Detection{},
+		},
+	}
+	for name, tc := range cases {
+		if got := DetectLanguage(tc.signals); got != tc.want {
+			t.Fatalf("%s: expected %+v, got %+v", name, tc.want, got)
+		}
+	}
+}
+
+func TestNormalizeFilter(t *testing.T) {
+	for raw, want := range map[string]string{"ID": "id", "en-GB": "en", "Unknown": Unknown, "klingon": ""} {
+		got, ok := NormalizeFilter(raw)
+		if got != want || ok != (want != "") {
+			t.Fatalf("NormalizeFilter(%q) = %q, %v", raw, got, ok)
+		}
+	}
+}
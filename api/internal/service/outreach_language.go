@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/outreach"
+)
+
+// ErrOutreachLanguagesUnavailable is returned when language recomputation runs without a repository.
+var ErrOutreachLanguagesUnavailable = errors.New("outreach language detection not configured")
+
+// WithOutreachLanguageDetection re-detects the preferred outreach language of a company whenever
+// its enrichment is stored.
+func WithOutreachLanguageDetection(languages repository.OutreachLanguageRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.languages = languages
+	}
+}
+
+func (s *CompaniesService) refreshOutreachLanguageAfterEnrichment(ctx context.Context, companyID uuid.UUID) {
+	if s.languages == nil {
+		return
+	}
+	if _, err := refreshOutreachLanguage(ctx, s.languages, companyID); err != nil {
+		log.Printf("failed to detect outreach language for company %s: %v", companyID, err)
+	}
+}
+
+func refreshOutreachLanguage(ctx context.Context, languages repository.OutreachLanguageRepository, companyID uuid.UUID) (outreach.Detection, error) {
+	signals, err := languages.GetLanguageSignals(ctx, companyID)
+	if err != nil {
+		return outreach.Detection{}, err
+	}
+	detection := outreach.DetectLanguage(languageSignals(signals))
+	if err := languages.UpdateOutreachLanguage(ctx, companyID, detection.Language, detection.Source); err != nil {
+		return outreach.Detection{}, err
+	}
+	return detection, nil
+}
+
+func languageSignals(signals *entity.OutreachLanguageSignals) outreach.Signals {
+	region, _ := phoneRegionForCountry(derefString(signals.Country))
+	return outreach.Signals{
+		WebsiteLanguage: derefString(signals.WebsiteLanguage),
+		AboutSummary:    derefString(signals.AboutSummary),
+		City:            derefString(signals.City),
+		Region:          region,
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/outreach"
+)
+
+type mockOutreachLanguageRepository struct {
+	signals map[uuid.UUID]entity.OutreachLanguageSignals
+	updated map[uuid.UUID]outreach.Detection
+	err     error
+}
+
+func (m *mockOutreachLanguageRepository) GetLanguageSignals(ctx context.Context, companyID uuid.UUID) (*entity.OutreachLanguageSignals, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	signals, ok := m.signals[companyID]
+	if !ok {
+		return nil, repository.ErrCompanyNotFound
+	}
+	return &signals, nil
+}
+
+func (m *mockOutreachLanguageRepository) ListLanguageSignalsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.OutreachLanguageSignals, error) {
+	var page []entity.OutreachLanguageSignals
+	for _, id := range []string{
+		"11111111-1111-1111-1111-111111111111",
+		"22222222-2222-2222-2222-222222222222",
+		"33333333-3333-3333-3333-333333333333",
+	} {
+		companyID := uuid.MustParse(id)
+		record, ok := m.signals[companyID]
+		if ok && id > after.String() && len(page) < limit {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockOutreachLanguageRepository) UpdateOutreachLanguage(ctx context.Context, companyID uuid.UUID, language, source string) error {
+	if m.updated == nil {
+		m.updated = make(map[uuid.UUID]outreach.Detection)
+	}
+	m.updated[companyID] = outreach.Detection{Language: language, Source: source}
+	return nil
+}
+
+func TestCompaniesService_SaveEnrichment_RefreshesOutreachLanguage(t *testing.T) {
+	companyID := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	var saved *entity.CompanyEnrichment
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			saved = enrichment
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	websiteLanguage, country := "ms-MY", "Malaysia"
+	languages := &mockOutreachLanguageRepository{signals: map[uuid.UUID]entity.OutreachLanguageSignals{
+		companyID: {CompanyID: companyID, WebsiteLanguage: &websiteLanguage, Country: &country},
+	}}
+	svc := NewCompaniesService(repo, WithOutreachLanguageDetection(languages))
+
+	raw := " MS-my "
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{CompanyID: companyID.String(), WebsiteLanguage: &raw})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Metadata["website_language"] != "ms-my" {
+		t.Fatalf("expected website language in metadata, got %+v", saved.Metadata)
+	}
+	if got := languages.updated[companyID]; got.Language != "ms" || got.Source != outreach.SourceWebsite {
+		t.Fatalf("expected outreach language refreshed from the website, got %+v", languages.updated)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_IgnoresOutreachLanguageErrors(t *testing.T) {
+	repo := &mockCompaniesRepository{
+		enrich:         func(ctx context.Context, enrichment *entity.CompanyEnrichment) error { return nil },
+		upsertContacts: func(ctx context.Context, contact *entity.WebsiteEnrichedContact) error { return nil },
+	}
+	svc := NewCompaniesService(repo, WithOutreachLanguageDetection(&mockOutreachLanguageRepository{err: errors.New("down")}))
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{CompanyID: "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"})
+	if err != nil {
+		t.Fatalf("expected outreach language failure to be ignored, got %v", err)
+	}
+}
+
+func TestMaintenanceService_RecomputeOutreachLanguages(t *testing.T) {
+	about, city, indonesia, canada := "Kami adalah toko kopi yang menyediakan biji kopi untuk kafe di Bandung dan sekitarnya.", "Montreal", "Indonesia", "Canada"
+	languages := &mockOutreachLanguageRepository{signals: map[uuid.UUID]entity.OutreachLanguageSignals{
+		uuid.MustParse("11111111-1111-1111-1111-111111111111"): {CompanyID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), AboutSummary: &about, Country: &indonesia},
+		uuid.MustParse("22222222-2222-2222-2222-222222222222"): {CompanyID: uuid.MustParse("22222222-2222-2222-2222-222222222222")},
+		uuid.MustParse("33333333-3333-3333-3333-333333333333"): {CompanyID: uuid.MustParse("33333333-3333-3333-3333-333333333333"), City: &city, Country: &canada},
+	}}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).RecomputeOutreachLanguages(context.Background(), 2); !errors.Is(err, ErrOutreachLanguagesUnavailable) {
+		t.Fatalf("expected ErrOutreachLanguagesUnavailable, got %v", err)
+	}
+
+	svc := NewMaintenanceService(&mockMaintenanceRepository{}, WithOutreachLanguages(languages))
+	counts, err := svc.RecomputeOutreachLanguages(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(languages.updated) != 3 || counts["id"] != 1 || counts["fr"] != 1 || counts[outreach.Unknown] != 1 {
+		t.Fatalf("unexpected recompute result: %v (%v)", counts, languages.updated)
+	}
+	if got := languages.updated[uuid.MustParse("33333333-3333-3333-3333-333333333333")]; got.Source != outreach.SourceCity {
+		t.Fatalf("expected the city to decide, got %+v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// DefaultOverviewWindow is how far back the admin overview counts jobs and import failures.
+const DefaultOverviewWindow = 24 * time.Hour
+
+const (
+	overviewCityScrapes    = 20
+	overviewImportFailures = 10
+	overviewTables         = 10
+)
+
+// WorkerLatencySource reports the latency of the recent worker calls of this API instance.
+type WorkerLatencySource interface {
+	WorkerLatency() []entity.WorkerLatency
+}
+
+// OverviewService gathers the figures of the ops dashboard in one call.
+type OverviewService struct {
+	repo    repository.OverviewRepository
+	latency WorkerLatencySource
+	window  time.Duration
+	now     func() time.Time
+}
+
+// OverviewServiceOption customises optional OverviewService collaborators.
+type OverviewServiceOption func(*OverviewService)
+
+// WithOverviewWorkerLatency reports the worker latency of source; without it the overview lists
+// none.
+func WithOverviewWorkerLatency(source WorkerLatencySource) OverviewServiceOption {
+	return func(s *OverviewService) {
+		s.latency = source
+	}
+}
+
+// NewOverviewService builds a new OverviewService counting jobs over DefaultOverviewWindow.
+func NewOverviewService(repo repository.OverviewRepository, opts ...OverviewServiceOption) *OverviewService {
+	s := &OverviewService{repo: repo, window: DefaultOverviewWindow, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Overview returns the job counts and import failures of the window, the latest scrape of each
+// city, the worker latency and the size of the database.
+func (s *OverviewService) Overview(ctx context.Context) (*entity.AdminOverview, error) {
+	now := s.now().UTC()
+	since := now.Add(-s.window)
+	overview := &entity.AdminOverview{
+		WindowHours:   int(s.window / time.Hour),
+		WorkerLatency: []entity.WorkerLatency{},
+		GeneratedAt:   now,
+	}
+
+	var err error
+	if overview.Jobs, err = s.repo.JobCounts(ctx, since); err != nil {
+		return nil, err
+	}
+	if overview.LastScrapes, err = s.repo.LastCityScrapes(ctx, overviewCityScrapes); err != nil {
+		return nil, err
+	}
+	failures, err := s.repo.ImportFailures(ctx, since, overviewImportFailures)
+	if err != nil {
+		return nil, err
+	}
+	overview.ImportFailures = *failures
+	size, err := s.repo.DatabaseSize(ctx, overviewTables)
+	if err != nil {
+		return nil, err
+	}
+	overview.Database = *size
+	if s.latency != nil {
+		overview.WorkerLatency = s.latency.WorkerLatency()
+	}
+	return overview, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type mockOverviewRepository struct {
+	since       time.Time
+	failedSince time.Time
+	scrapes     int
+	failures    int
+	tables      int
+}
+
+func (m *mockOverviewRepository) JobCounts(ctx context.Context, since time.Time) ([]entity.JobStatusCounts, error) {
+	m.since = since
+	return []entity.JobStatusCounts{{Kind: entity.JobKindImport, Running: 1}}, nil
+}
+
+func (m *mockOverviewRepository) LastCityScrapes(ctx context.Context, limit int) ([]entity.CityScrape, error) {
+	m.scrapes = limit
+	return []entity.CityScrape{}, nil
+}
+
+func (m *mockOverviewRepository) ImportFailures(ctx context.Context, since time.Time, limit int) (*entity.ImportFailures, error) {
+	m.failedSince, m.failures = since, limit
+	return &entity.ImportFailures{Total: 3, Recent: []entity.ImportFailure{}}, nil
+}
+
+func (m *mockOverviewRepository) DatabaseSize(ctx context.Context, tables int) (*entity.DatabaseSize, error) {
+	m.tables = tables
+	return &entity.DatabaseSize{Bytes: 1 << 20, Tables: []entity.TableSize{}}, nil
+}
+
+func TestOverviewService_Overview(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	repo := &mockOverviewRepository{}
+	svc := NewOverviewService(repo)
+	svc.now = func() time.Time { return now }
+
+	overview, err := svc.Overview(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := now.Add(-24 * time.Hour)
+	if !repo.since.Equal(want) || !repo.failedSince.Equal(want) {
+		t.Fatalf("expected the last 24 hours, got %v and %v", repo.since, repo.failedSince)
+	}
+	if repo.scrapes != overviewCityScrapes || repo.failures != overviewImportFailures || repo.tables != overviewTables {
+		t.Fatalf("unexpected limits: %+v", repo)
+	}
+	if overview.WindowHours != 24 || overview.ImportFailures.Total != 3 || overview.Database.Bytes != 1<<20 || !overview.GeneratedAt.Equal(now) {
+		t.Fatalf("unexpected overview: %+v", overview)
+	}
+	if overview.WorkerLatency == nil || len(overview.WorkerLatency) != 0 {
+		t.Fatalf("expected an empty worker latency list without a source, got %v", overview.WorkerLatency)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nyaruka/phonenumbers"
+
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// countryRegions maps normalised country names, as scraped from Places or typed into CSV imports,
+// to the ISO 3166-1 region used to parse local phone numbers. Two-letter codes are accepted as is.
+var countryRegions = map[string]string{
+	"indonesia":                "ID",
+	"malaysia":                 "MY",
+	"singapore":                "SG",
+	"thailand":                 "TH",
+	"philippines":              "PH",
+	"vietnam":                  "VN",
+	"viet nam":                 "VN",
+	"brunei":                   "BN",
+	"united states":            "US",
+	"united states of america": "US",
+	"usa":                      "US",
+	"america":                  "US",
+	"amerika serikat":          "US",
+	"united kingdom":           "GB",
+	"uk":                       "GB",
+	"great britain":            "GB",
+	"england":                  "GB",
+	"scotland":                 "GB",
+	"wales":                    "GB",
+	"northern ireland":         "GB",
+	"inggris":                  "GB",
+	"ireland":                  "IE",
+	"canada":                   "CA",
+	"australia":                "AU",
+	"new zealand":              "NZ",
+	"india":                    "IN",
+	"japan":                    "JP",
+	"south korea":              "KR",
+	"korea":                    "KR",
+	"china":                    "CN",
+	"hong kong":                "HK",
+	"taiwan":                   "TW",
+	"germany":                  "DE",
+	"france":                   "FR",
+	"netherlands":              "NL",
+	"the netherlands":          "NL",
+	"belgium":                  "BE",
+	"spain":                    "ES",
+	"portugal":                 "PT",
+	"italy":                    "IT",
+	"switzerland":              "CH",
+	"sweden":                   "SE",
+	"norway":                   "NO",
+	"denmark":                  "DK",
+	"poland":                   "PL",
+	"united arab emirates":     "AE",
+	"uae":                      "AE",
+	"saudi arabia":             "SA",
+	"brazil":                   "BR",
+	"mexico":                   "MX",
+	"south africa":             "ZA",
+}
+
+// phoneRegionForCountry returns the phone region of a country name or ISO code.
+func phoneRegionForCountry(country string) (string, bool) {
+	name := strings.ToLower(strings.Join(strings.Fields(strings.Trim(country, " .")), " "))
+	if name == "" {
+		return "", false
+	}
+	if region, ok := countryRegions[name]; ok {
+		return region, true
+	}
+	if code := strings.ToUpper(name); len(code) == 2 && phonenumbers.GetSupportedRegions()[code] {
+		return code, true
+	}
+	return "", false
+}
+
+// WithCompanyCountries parses enrichment phone numbers in the region of the company's country, so
+// local numbers of foreign leads are not rejected under the default region.
+func WithCompanyCountries(countries repository.CompanyCountryRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.countries = countries
+	}
+}
+
+// phoneRegion returns the region inferred from the company's country, or an empty string to use
+// the processor default.
+func (s *CompaniesService) phoneRegion(ctx context.Context, companyID uuid.UUID) string {
+	if s.countries == nil {
+		return ""
+	}
+	country, err := s.countries.GetCompanyCountry(ctx, companyID)
+	if err != nil {
+		log.Printf("failed to load country of company %s: %v", companyID, err)
+		return ""
+	}
+	region, _ := phoneRegionForCountry(country)
+	return region
+}
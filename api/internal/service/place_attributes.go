@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/placeattrs"
+)
+
+// ErrPlaceAttributesUnavailable is returned when place attribute extraction runs without a
+// repository.
+var ErrPlaceAttributesUnavailable = errors.New("place attribute extraction not configured")
+
+// PlaceAttributeExtraction counts the companies whose place attributes were extracted again, and
+// those skipped because their raw payload was offloaded and could not be read back.
+type PlaceAttributeExtraction struct {
+	Companies    int `json:"companies"`
+	OpeningHours int `json:"opening_hours"`
+	PriceLevels  int `json:"price_levels"`
+	Categories   int `json:"categories"`
+	Offloaded    int `json:"offloaded"`
+}
+
+// add sums the counts of two extractions.
+func (e *PlaceAttributeExtraction) add(other PlaceAttributeExtraction) {
+	e.Companies += other.Companies
+	e.OpeningHours += other.OpeningHours
+	e.PriceLevels += other.PriceLevels
+	e.Categories += other.Categories
+	e.Offloaded += other.Offloaded
+}
+
+// WithPlaceAttributes enables extraction of opening hours, price levels and categories from the
+// stored raw payloads. rawStore, when set, is where offloaded payloads are read back from;
+// without it they are skipped.
+func WithPlaceAttributes(attrs repository.PlaceAttributesRepository, rawStore BlobStore) MaintenanceServiceOption {
+	return func(s *MaintenanceService) {
+		s.placeAttrs = attrs
+		s.rawStore = rawStore
+	}
+}
+
+// applyPlaceAttributes sets the opening hours, price level and categories carried by the raw
+// payload of a scraped company. Its business type comes first among the categories.
+func applyPlaceAttributes(company *entity.Company) {
+	attrs := placeAttributes(company.TypeBusiness, company.Raw)
+	company.OpeningHours = attrs.OpeningHours
+	company.UTCOffsetMinutes = attrs.UTCOffsetMinutes
+	company.PriceLevel = attrs.PriceLevel
+	company.Categories = attrs.Categories
+}
+
+// placeAttributes extracts the attributes of a raw payload, with the business type as the
+// primary category.
+func placeAttributes(typeBusiness *string, raw json.RawMessage) entity.PlaceAttributes {
+	attrs := placeattrs.Extract(raw)
+	attrs.Categories = placeattrs.MergeCategories(append([]string{derefString(typeBusiness)}, attrs.Categories...)...)
+	return attrs
+}
+
+// PlaceAttributeOptions tunes ExtractPlaceAttributesFrom.
+type PlaceAttributeOptions struct {
+	// Filter narrows the companies with the listing filters; pagination is ignored.
+	Filter    dto.ListFilter
+	BatchSize int
+	// After resumes a previous extraction after the last company it reached.
+	After uuid.UUID
+	// OnStart, if set, receives the number of companies left to process before the first batch.
+	OnStart func(total int) error
+	// OnBatch, if set, runs after every batch with the last company reached and the counts of the
+	// batch, so callers can record where to resume.
+	OnBatch func(last uuid.UUID, batch PlaceAttributeExtraction) error
+}
+
+// ExtractPlaceAttributes extracts the opening hours, price level and categories of every company
+// from its raw payload again, paging by company id.
+func (s *MaintenanceService) ExtractPlaceAttributes(ctx context.Context, batchSize int) (PlaceAttributeExtraction, error) {
+	return s.ExtractPlaceAttributesFrom(ctx, PlaceAttributeOptions{BatchSize: batchSize})
+}
+
+// ExtractPlaceAttributesFrom extracts the place attributes of the companies matching opts.Filter
+// again, starting after opts.After and reporting progress per batch. Offloaded payloads are read
+// from the raw payload store; companies whose payload cannot be read keep their attributes and
+// are counted as Offloaded. Extraction is idempotent, so resuming after an interruption
+// processes at most the batch that was cut short again.
+func (s *MaintenanceService) ExtractPlaceAttributesFrom(ctx context.Context, opts PlaceAttributeOptions) (PlaceAttributeExtraction, error) {
+	var result PlaceAttributeExtraction
+	if s.placeAttrs == nil {
+		return result, ErrPlaceAttributesUnavailable
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+
+	after := opts.After
+	if opts.OnStart != nil {
+		remaining, err := s.placeAttrs.CountPlacePayloadsAfter(ctx, opts.Filter, after)
+		if err != nil {
+			return result, err
+		}
+		if err := opts.OnStart(remaining); err != nil {
+			return result, err
+		}
+	}
+
+	for {
+		batch, err := s.placeAttrs.ListPlacePayloadsAfter(ctx, opts.Filter, after, batchSize)
+		if err != nil {
+			return result, err
+		}
+		var counts PlaceAttributeExtraction
+		for _, payload := range batch {
+			raw := payload.Raw
+			if payload.RawRef != nil {
+				if raw, err = s.offloadedPlacePayload(ctx, *payload.RawRef); err != nil {
+					return result, err
+				}
+				if raw == nil {
+					counts.Offloaded++
+					continue
+				}
+			}
+			attrs := placeAttributes(payload.TypeBusiness, raw)
+			if err := s.placeAttrs.UpdatePlaceAttributes(ctx, payload.CompanyID, attrs); err != nil {
+				if errors.Is(err, repository.ErrCompanyNotFound) {
+					continue
+				}
+				return result, err
+			}
+			counts.Companies++
+			if len(attrs.OpeningHours) > 0 {
+				counts.OpeningHours++
+			}
+			if attrs.PriceLevel != nil {
+				counts.PriceLevels++
+			}
+			if len(attrs.Categories) > 0 {
+				counts.Categories++
+			}
+		}
+		result.add(counts)
+		if len(batch) > 0 {
+			after = batch[len(batch)-1].CompanyID
+			if opts.OnBatch != nil {
+				if err := opts.OnBatch(after, counts); err != nil {
+					return result, err
+				}
+			}
+		}
+		if len(batch) < batchSize {
+			return result, nil
+		}
+	}
+}
+
+// offloadedPlacePayload reads an offloaded payload back, or returns nil when there is no raw
+// payload store or the object is gone.
+func (s *MaintenanceService) offloadedPlacePayload(ctx context.Context, ref string) (json.RawMessage, error) {
+	if s.rawStore == nil {
+		return nil, nil
+	}
+	raw, err := readRawObject(ctx, s.rawStore, ref)
+	if errors.Is(err, ErrRawPayloadMissing) {
+		return nil, nil
+	}
+	return raw, err
+}
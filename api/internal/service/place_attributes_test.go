@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+func placePayloadID(n int) uuid.UUID {
+	return uuid.MustParse(strings.Repeat(string(rune('0'+n)), 8) + "-1111-1111-1111-111111111111")
+}
+
+type mockPlaceAttributesRepository struct {
+	payloads []entity.CompanyPlacePayload
+	updated  map[uuid.UUID]entity.PlaceAttributes
+	// filter is the filter of the last listing.
+	filter dto.ListFilter
+}
+
+func (m *mockPlaceAttributesRepository) CountPlacePayloadsAfter(ctx context.Context, filter dto.ListFilter, after uuid.UUID) (int, error) {
+	page, _ := m.ListPlacePayloadsAfter(ctx, filter, after, len(m.payloads))
+	return len(page), nil
+}
+
+func (m *mockPlaceAttributesRepository) ListPlacePayloadsAfter(ctx context.Context, filter dto.ListFilter, after uuid.UUID, limit int) ([]entity.CompanyPlacePayload, error) {
+	m.filter = filter
+	var page []entity.CompanyPlacePayload
+	for _, p := range m.payloads {
+		if strings.Compare(p.CompanyID.String(), after.String()) > 0 && len(page) < limit {
+			page = append(page, p)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockPlaceAttributesRepository) UpdatePlaceAttributes(ctx context.Context, companyID uuid.UUID, attrs entity.PlaceAttributes) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.updated == nil {
+		m.updated = make(map[uuid.UUID]entity.PlaceAttributes)
+	}
+	m.updated[companyID] = attrs
+	return nil
+}
+
+func TestCompaniesService_UpsertCompany_ExtractsPlaceAttributes(t *testing.T) {
+	var saved *entity.Company
+	repo := &mockCompaniesRepository{upsert: func(ctx context.Context, company *entity.Company) error {
+		saved = company
+		return nil
+	}}
+	raw := json.RawMessage(`{"price_level": 1, "utc_offset": 420, "types": ["cafe", "establishment"],
+		"opening_hours": {"periods": [{"open": {"day": 1, "time": "0700"}, "close": {"day": 1, "time": "2200"}}]}}`)
+
+	if err := NewCompaniesService(repo).UpsertCompany(context.Background(), &entity.Company{Company: "Kopi Kenangan", Raw: raw}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.PriceLevel == nil || *saved.PriceLevel != 1 || saved.UTCOffsetMinutes == nil || *saved.UTCOffsetMinutes != 420 {
+		t.Fatalf("unexpected price level %v and offset %v", saved.PriceLevel, saved.UTCOffsetMinutes)
+	}
+	if len(saved.OpeningHours) != 1 || saved.OpeningHours[0] != (entity.OpeningPeriod{Day: 1, Open: "07:00", Close: "22:00"}) {
+		t.Fatalf("unexpected opening hours %v", saved.OpeningHours)
+	}
+	if len(saved.Categories) != 1 || saved.Categories[0] != "cafe" {
+		t.Fatalf("unexpected categories %v", saved.Categories)
+	}
+}
+
+func TestMaintenanceService_ExtractPlaceAttributes(t *testing.T) {
+	id := placePayloadID
+	bakery, ref := "Bakery", "raw/2.json"
+	attrs := &mockPlaceAttributesRepository{payloads: []entity.CompanyPlacePayload{
+		{CompanyID: id(1), Raw: json.RawMessage(`{"price_level": 2, "types": ["restaurant"]}`)},
+		{CompanyID: id(2), RawRef: &ref},
+		{CompanyID: id(3), TypeBusiness: &bakery, Raw: json.RawMessage(`{"types": ["cafe"]}`)},
+	}}
+
+	if _, err := NewMaintenanceService(&mockMaintenanceRepository{}).ExtractPlaceAttributes(context.Background(), 2); !errors.Is(err, ErrPlaceAttributesUnavailable) {
+		t.Fatalf("expected ErrPlaceAttributesUnavailable, got %v", err)
+	}
+
+	result, err := NewMaintenanceService(&mockMaintenanceRepository{}, WithPlaceAttributes(attrs, nil)).ExtractPlaceAttributes(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (PlaceAttributeExtraction{Companies: 2, PriceLevels: 1, Categories: 2, Offloaded: 1}) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if _, ok := attrs.updated[id(2)]; ok {
+		t.Fatalf("expected an offloaded payload to be left alone without a raw payload store")
+	}
+	if got := attrs.updated[id(1)]; got.PriceLevel == nil || *got.PriceLevel != 2 {
+		t.Fatalf("unexpected attributes %+v", got)
+	}
+	if got := attrs.updated[id(3)].Categories; len(got) != 2 || got[0] != "bakery" || got[1] != "cafe" {
+		t.Fatalf("expected the business type as primary category, got %v", got)
+	}
+}
+
+func TestMaintenanceService_ExtractPlaceAttributesFrom_ReadsOffloadedPayloads(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	if err := store.Upload(context.Background(), "raw/2.json", rawContentType, strings.NewReader(`{"price_level": 3}`)); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	kept, gone := "raw/2.json", "raw/4.json"
+	attrs := &mockPlaceAttributesRepository{payloads: []entity.CompanyPlacePayload{
+		{CompanyID: placePayloadID(1), Raw: json.RawMessage(`{"types": ["cafe"]}`)},
+		{CompanyID: placePayloadID(2), RawRef: &kept},
+		{CompanyID: placePayloadID(3), Raw: json.RawMessage(`{"price_level": 1}`)},
+		{CompanyID: placePayloadID(4), RawRef: &gone},
+	}}
+	svc := NewMaintenanceService(&mockMaintenanceRepository{}, WithPlaceAttributes(attrs, store))
+
+	var (
+		total   int
+		batches []PlaceAttributeExtraction
+		last    uuid.UUID
+	)
+	result, err := svc.ExtractPlaceAttributesFrom(context.Background(), PlaceAttributeOptions{
+		Filter:    dto.ListFilter{City: "Jakarta"},
+		BatchSize: 2,
+		After:     placePayloadID(1),
+		OnStart:   func(remaining int) error { total = remaining; return nil },
+		OnBatch: func(after uuid.UUID, batch PlaceAttributeExtraction) error {
+			last = after
+			batches = append(batches, batch)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 3 || attrs.filter.City != "Jakarta" {
+		t.Fatalf("expected 3 companies left with the filter applied, got %d and %+v", total, attrs.filter)
+	}
+	if result != (PlaceAttributeExtraction{Companies: 2, PriceLevels: 2, Offloaded: 1}) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(batches) != 2 || batches[0].Companies != 2 || batches[1].Offloaded != 1 || last != placePayloadID(4) {
+		t.Fatalf("expected progress per batch, got %+v ending at %s", batches, last)
+	}
+	if got := attrs.updated[placePayloadID(2)]; got.PriceLevel == nil || *got.PriceLevel != 3 {
+		t.Fatalf("expected the offloaded payload read back, got %+v", got)
+	}
+	if _, ok := attrs.updated[placePayloadID(1)]; ok {
+		t.Fatalf("expected the companies before the cursor to be left alone")
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrPlaceRefreshUnavailable is returned when a refresh is requested without a refresh repository.
+	ErrPlaceRefreshUnavailable = errors.New("place refresh unavailable")
+	// ErrPlaceNotFound is returned when the lookup matched no place for the company.
+	ErrPlaceNotFound = errors.New("no place matches the company name and address")
+	// ErrPlaceIDTaken is returned when the place found already belongs to another company.
+	ErrPlaceIDTaken = errors.New("place id belongs to another company")
+)
+
+// WithPlaceRefresh enables POST /admin/companies/:id/refresh.
+func WithPlaceRefresh(refreshes repository.PlaceRefreshRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.refreshes = refreshes
+	}
+}
+
+// PlaceLookup returns the company id and the worker lookup that finds the place of a company
+// again, by its name and address.
+func (s *CompaniesService) PlaceLookup(ctx context.Context, companyID string) (uuid.UUID, dto.WorkerPlaceLookupRequest, error) {
+	if s.refreshes == nil {
+		return uuid.Nil, dto.WorkerPlaceLookupRequest{}, ErrPlaceRefreshUnavailable
+	}
+	id, err := uuid.Parse(strings.TrimSpace(companyID))
+	if err != nil {
+		return uuid.Nil, dto.WorkerPlaceLookupRequest{}, ErrInvalidCompanyID
+	}
+	target, err := s.refreshes.GetPlaceRefreshTarget(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return uuid.Nil, dto.WorkerPlaceLookupRequest{}, ErrCompanyNotFound
+		}
+		return uuid.Nil, dto.WorkerPlaceLookupRequest{}, err
+	}
+	return id, dto.WorkerPlaceLookupRequest{Name: target.Company, Address: target.Address, Country: derefString(target.Country)}, nil
+}
+
+// RefreshPlace stores what a lookup found for a company: the place id, which Google may have
+// changed, and the current rating and reviews. The refresh is recorded in the company history.
+func (s *CompaniesService) RefreshPlace(ctx context.Context, companyID uuid.UUID, result dto.WorkerPlaceLookupResult) (*entity.PlaceRefresh, error) {
+	if s.refreshes == nil {
+		return nil, ErrPlaceRefreshUnavailable
+	}
+	placeID := strings.TrimSpace(result.PlaceID)
+	if !result.Found || placeID == "" {
+		return nil, ErrPlaceNotFound
+	}
+	refresh := &entity.PlaceRefresh{
+		ID:        uuid.New(),
+		CompanyID: companyID,
+		PlaceID:   placeID,
+		Rating:    result.Rating,
+		Reviews:   result.Reviews,
+	}
+	if err := s.refreshes.RefreshPlace(ctx, refresh); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCompanyNotFound):
+			return nil, ErrCompanyNotFound
+		case errors.Is(err, repository.ErrPlaceIDTaken):
+			return nil, ErrPlaceIDTaken
+		}
+		return nil, err
+	}
+	s.publisher.Publish(ctx, events.CompaniesUpserted{Source: events.SourceRefresh, Companies: 1, Updated: 1})
+	return refresh, nil
+}
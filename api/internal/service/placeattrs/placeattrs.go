@@ -0,0 +1,234 @@
+package placeattrs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// MaxPriceLevel is the most expensive price level; levels run from 0 (free) to MaxPriceLevel.
+const MaxPriceLevel = 4
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// genericTypes are Places types carried by nearly every place, which say nothing of the business.
+var genericTypes = map[string]bool{
+	"point_of_interest": true, "establishment": true, "political": true, "premise": true,
+}
+
+// priceLevels maps the price levels of the Places API (New) and their short names onto levels.
+var priceLevels = map[string]int{
+	"price_level_free": 0, "free": 0,
+	"price_level_inexpensive": 1, "inexpensive": 1,
+	"price_level_moderate": 2, "moderate": 2,
+	"price_level_expensive": 3, "expensive": 3,
+	"price_level_very_expensive": 4, "very_expensive": 4,
+}
+
+// place holds the fields read from a raw payload, in the legacy Places shape (opening_hours,
+// utc_offset, price_level as a number), the Places API (New) shape (regularOpeningHours,
+// utcOffsetMinutes, priceLevel as a name, primaryType) or the SerpAPI one (price as dollar signs,
+// type as a label such as "Coffee shop").
+type place struct {
+	OpeningHours        *openingHours   `json:"opening_hours"`
+	RegularOpeningHours *openingHours   `json:"regularOpeningHours"`
+	UTCOffset           *int            `json:"utc_offset"`
+	UTCOffsetMinutes    *int            `json:"utc_offset_minutes"`
+	UTCOffsetMinutesNew *int            `json:"utcOffsetMinutes"`
+	PriceLevel          json.RawMessage `json:"price_level"`
+	PriceLevelNew       json.RawMessage `json:"priceLevel"`
+	Price               string          `json:"price"`
+	Types               []string        `json:"types"`
+	PrimaryType         string          `json:"primaryType"`
+	Type                string          `json:"type"`
+}
+
+type openingHours struct {
+	Periods []struct {
+		Open  *periodPoint `json:"open"`
+		Close *periodPoint `json:"close"`
+	} `json:"periods"`
+}
+
+// periodPoint is a time of the week: legacy payloads give "HHMM" in Time, the Places API (New)
+// gives Hour and Minute.
+type periodPoint struct {
+	Day    int    `json:"day"`
+	Time   string `json:"time"`
+	Hour   *int   `json:"hour"`
+	Minute *int   `json:"minute"`
+}
+
+// Extract reads the opening hours, price level and categories of a raw place payload. Fields that
+// are missing or malformed are left empty; categories leave out generic types such as
+// establishment.
+func Extract(raw json.RawMessage) entity.PlaceAttributes {
+	var p place
+	if len(raw) == 0 || json.Unmarshal(raw, &p) != nil {
+		return entity.PlaceAttributes{}
+	}
+
+	var attrs entity.PlaceAttributes
+	hours := p.OpeningHours
+	if hours == nil || len(hours.Periods) == 0 {
+		hours = p.RegularOpeningHours
+	}
+	if hours != nil {
+		attrs.OpeningHours = weeklyPeriods(hours)
+	}
+	for _, offset := range []*int{p.UTCOffsetMinutes, p.UTCOffsetMinutesNew, p.UTCOffset} {
+		if offset != nil && *offset >= -14*60 && *offset <= 14*60 {
+			attrs.UTCOffsetMinutes = offset
+			break
+		}
+	}
+	for _, level := range []json.RawMessage{p.PriceLevel, p.PriceLevelNew} {
+		if parsed, ok := parseRawPriceLevel(level); ok {
+			attrs.PriceLevel = &parsed
+			break
+		}
+	}
+	if attrs.PriceLevel == nil && p.Price != "" {
+		if parsed, ok := ParsePriceLevel(p.Price); ok {
+			attrs.PriceLevel = &parsed
+		}
+	}
+	attrs.Categories = MergeCategories(append([]string{p.PrimaryType, p.Type}, p.Types...)...)
+	return attrs
+}
+
+// weeklyPeriods splits the periods at midnight, so a bar open from 20:00 to 02:00 is open from
+// 20:00 to 24:00 and from 00:00 to 02:00 the next day. A period opening on Sunday at midnight
+// without closing is the Places way of saying always open.
+func weeklyPeriods(hours *openingHours) []entity.OpeningPeriod {
+	var periods []entity.OpeningPeriod
+	for _, period := range hours.Periods {
+		open, ok := minuteOfWeek(period.Open)
+		if !ok {
+			continue
+		}
+		var end int
+		if period.Close == nil {
+			if open != 0 {
+				continue
+			}
+			end = minutesPerWeek
+		} else {
+			closeAt, ok := minuteOfWeek(period.Close)
+			if !ok {
+				continue
+			}
+			end = closeAt
+			if end <= open {
+				end += minutesPerWeek
+			}
+		}
+		for start := open; start < end; {
+			dayStart := start / minutesPerDay * minutesPerDay
+			stop := min(end, dayStart+minutesPerDay)
+			periods = append(periods, entity.OpeningPeriod{
+				Day:   dayStart / minutesPerDay % 7,
+				Open:  clock(start - dayStart),
+				Close: clock(stop - dayStart),
+			})
+			start = stop
+		}
+	}
+	slices.SortFunc(periods, func(a, b entity.OpeningPeriod) int {
+		if a.Day != b.Day {
+			return a.Day - b.Day
+		}
+		return strings.Compare(a.Open, b.Open)
+	})
+	return periods
+}
+
+func minuteOfWeek(point *periodPoint) (int, bool) {
+	if point == nil || point.Day < 0 || point.Day > 6 {
+		return 0, false
+	}
+	var hour, minute int
+	switch {
+	case point.Hour != nil:
+		hour = *point.Hour
+		if point.Minute != nil {
+			minute = *point.Minute
+		}
+	case len(point.Time) == 4:
+		h, errH := strconv.Atoi(point.Time[:2])
+		m, errM := strconv.Atoi(point.Time[2:])
+		if errH != nil || errM != nil {
+			return 0, false
+		}
+		hour, minute = h, m
+	default:
+		return 0, false
+	}
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, false
+	}
+	return point.Day*minutesPerDay + hour*60 + minute, true
+}
+
+func clock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func parseRawPriceLevel(raw json.RawMessage) (int, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	var number int
+	if json.Unmarshal(raw, &number) == nil {
+		return number, number >= 0 && number <= MaxPriceLevel
+	}
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return ParsePriceLevel(name)
+	}
+	return 0, false
+}
+
+// ParsePriceLevel reads a price level given as a number from 0 to MaxPriceLevel, as dollar signs
+// ("$$" is 2) or by name ("moderate", "PRICE_LEVEL_MODERATE").
+func ParsePriceLevel(raw string) (int, bool) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, false
+	}
+	if level, err := strconv.Atoi(value); err == nil {
+		return level, level >= 0 && level <= MaxPriceLevel
+	}
+	if strings.Trim(value, "$") == "" && len(value) <= MaxPriceLevel {
+		return len(value), true
+	}
+	level, ok := priceLevels[strings.NewReplacer(" ", "_", "-", "_").Replace(value)]
+	return level, ok
+}
+
+// MergeCategories normalizes categories and drops empty, generic and repeated ones, keeping the
+// order they came in, so the primary category stays first. It returns nil when none is left.
+func MergeCategories(categories ...string) []string {
+	var merged []string
+	for _, raw := range categories {
+		if category := NormalizeCategory(raw); category != "" && !genericTypes[category] && !slices.Contains(merged, category) {
+			merged = append(merged, category)
+		}
+	}
+	return merged
+}
+
+// NormalizeCategory returns a category in the form of Places types, so "Coffee Shop" is
+// coffee_shop.
+func NormalizeCategory(raw string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(strings.TrimSpace(raw)), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "_")
+}
@@ -0,0 +1,108 @@
+package placeattrs
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestExtract_LegacyPlace(t *testing.T) {
+	raw := json.RawMessage(`{
+		"opening_hours": {"open_now": true, "periods": [
+			{"open": {"day": 1, "time": "0800"}, "close": {"day": 1, "time": "1700"}},
+			{"open": {"day": 6, "time": "2000"}, "close": {"day": 0, "time": "0200"}}
+		]},
+		"utc_offset": 420,
+		"price_level": 2,
+		"types": ["cafe", "food", "point_of_interest", "establishment", "Cafe"]
+	}`)
+	attrs := Extract(raw)
+
+	want := []entity.OpeningPeriod{
+		{Day: 0, Open: "00:00", Close: "02:00"},
+		{Day: 1, Open: "08:00", Close: "17:00"},
+		{Day: 6, Open: "20:00", Close: "24:00"},
+	}
+	if !slices.Equal(attrs.OpeningHours, want) {
+		t.Fatalf("expected %v, got %v", want, attrs.OpeningHours)
+	}
+	if attrs.UTCOffsetMinutes == nil || *attrs.UTCOffsetMinutes != 420 {
+		t.Fatalf("unexpected offset %v", attrs.UTCOffsetMinutes)
+	}
+	if attrs.PriceLevel == nil || *attrs.PriceLevel != 2 {
+		t.Fatalf("unexpected price level %v", attrs.PriceLevel)
+	}
+	if want := []string{"cafe", "food"}; !slices.Equal(attrs.Categories, want) {
+		t.Fatalf("expected %v, got %v", want, attrs.Categories)
+	}
+}
+
+func TestExtract_NewPlace(t *testing.T) {
+	raw := json.RawMessage(`{
+		"regularOpeningHours": {"periods": [{"open": {"day": 0, "hour": 0, "minute": 0}}]},
+		"utcOffsetMinutes": -300,
+		"priceLevel": "PRICE_LEVEL_VERY_EXPENSIVE",
+		"types": ["steak_house"]
+	}`)
+	attrs := Extract(raw)
+
+	if len(attrs.OpeningHours) != 7 || attrs.OpeningHours[3] != (entity.OpeningPeriod{Day: 3, Open: "00:00", Close: "24:00"}) {
+		t.Fatalf("expected a place always open, got %v", attrs.OpeningHours)
+	}
+	if attrs.UTCOffsetMinutes == nil || *attrs.UTCOffsetMinutes != -300 {
+		t.Fatalf("unexpected offset %v", attrs.UTCOffsetMinutes)
+	}
+	if attrs.PriceLevel == nil || *attrs.PriceLevel != 4 {
+		t.Fatalf("unexpected price level %v", attrs.PriceLevel)
+	}
+}
+
+func TestExtract_Malformed(t *testing.T) {
+	for _, raw := range []string{``, `[]`, `{"price_level": 9, "opening_hours": {"periods": [{"open": {"day": 8, "time": "0800"}}, {"open": {"day": 2, "time": "0800"}}]}}`} {
+		if attrs := Extract(json.RawMessage(raw)); attrs.OpeningHours != nil || attrs.PriceLevel != nil || attrs.Categories != nil {
+			t.Fatalf("expected nothing extracted from %q, got %+v", raw, attrs)
+		}
+	}
+	if attrs := Extract(json.RawMessage(`{"price": "$$$"}`)); attrs.PriceLevel == nil || *attrs.PriceLevel != 3 {
+		t.Fatalf("expected price level 3 from dollar signs, got %v", attrs.PriceLevel)
+	}
+}
+
+func TestParsePriceLevel(t *testing.T) {
+	cases := map[string]int{"0": 0, "$": 1, "Moderate": 2, "very expensive": 4, "PRICE_LEVEL_FREE": 0}
+	for raw, want := range cases {
+		if got, ok := ParsePriceLevel(raw); !ok || got != want {
+			t.Errorf("ParsePriceLevel(%q) = %d %v, want %d", raw, got, ok, want)
+		}
+	}
+	for _, raw := range []string{"", "5", "-1", "$$$$$", "cheap"} {
+		if _, ok := ParsePriceLevel(raw); ok {
+			t.Errorf("expected ParsePriceLevel(%q) to be rejected", raw)
+		}
+	}
+}
+
+func TestNormalizeCategory(t *testing.T) {
+	cases := map[string]string{" Coffee Shop ": "coffee_shop", "steak-house": "steak_house", "cafe": "cafe", "  ": ""}
+	for raw, want := range cases {
+		if got := NormalizeCategory(raw); got != want {
+			t.Errorf("NormalizeCategory(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestMergeCategories(t *testing.T) {
+	got := MergeCategories("Coffee Shop", "cafe", "", "establishment", "coffee_shop", "Bakery")
+	if want := []string{"coffee_shop", "cafe", "bakery"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := MergeCategories("point_of_interest", " "); got != nil {
+		t.Fatalf("expected no categories, got %v", got)
+	}
+	serp := Extract(json.RawMessage(`{"type": "Coffee shop", "types": ["Coffee shop", "Cafe"]}`))
+	if want := []string{"coffee_shop", "cafe"}; !slices.Equal(serp.Categories, want) {
+		t.Fatalf("expected %v, got %v", want, serp.Categories)
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+// maxPrivacySubjects bounds the emails plus companies one erasure or export may cover.
+const maxPrivacySubjects = 100
+
+// ErrInvalidPrivacyRequest is returned when an erasure or export names no valid subject.
+var ErrInvalidPrivacyRequest = errors.New("invalid privacy request")
+
+// PrivacyService erases and exports the contact data held about people and companies, recording
+// every request in the privacy audit trail.
+type PrivacyService struct {
+	repo     repository.PrivacyRepository
+	rawStore BlobStore
+}
+
+// NewPrivacyService builds a PrivacyService; rawStore, when set, is where offloaded raw payloads
+// are read from and deleted.
+func NewPrivacyService(repo repository.PrivacyRepository, rawStore BlobStore) *PrivacyService {
+	return &PrivacyService{repo: repo, rawStore: rawStore}
+}
+
+// privacySubjects is a validated privacy request.
+type privacySubjects struct {
+	emails     []string
+	companyIDs []uuid.UUID
+}
+
+// parsePrivacyRequest lowercases and deduplicates the emails and company ids of req.
+func parsePrivacyRequest(req dto.PrivacyRequest) (privacySubjects, error) {
+	var subjects privacySubjects
+	for _, email := range req.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !emailPattern.MatchString(email) {
+			return subjects, fmt.Errorf("%w: invalid email %q", ErrInvalidPrivacyRequest, email)
+		}
+		if !slices.Contains(subjects.emails, email) {
+			subjects.emails = append(subjects.emails, email)
+		}
+	}
+	for _, raw := range req.CompanyIDs {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return subjects, fmt.Errorf("%w: invalid company id %q", ErrInvalidPrivacyRequest, raw)
+		}
+		if !slices.Contains(subjects.companyIDs, id) {
+			subjects.companyIDs = append(subjects.companyIDs, id)
+		}
+	}
+	switch total := len(subjects.emails) + len(subjects.companyIDs); {
+	case total == 0:
+		return subjects, fmt.Errorf("%w: provide emails or company_ids", ErrInvalidPrivacyRequest)
+	case total > maxPrivacySubjects:
+		return subjects, fmt.Errorf("%w: at most %d emails and companies per request", ErrInvalidPrivacyRequest, maxPrivacySubjects)
+	}
+	return subjects, nil
+}
+
+// auditEntry starts the audit trail entry of a request; emails are only kept as hashes.
+func (p privacySubjects) auditEntry(action, userID string) *entity.PrivacyRequest {
+	request := &entity.PrivacyRequest{
+		Action:        action,
+		SubjectHashes: make([]string, 0, len(p.emails)),
+		CompanyIDs:    p.companyIDs,
+	}
+	for _, email := range p.emails {
+		sum := sha256.Sum256([]byte(email))
+		request.SubjectHashes = append(request.SubjectHashes, hex.EncodeToString(sum[:]))
+	}
+	if id, err := uuid.Parse(userID); err == nil {
+		request.RequestedBy = &id
+	}
+	return request
+}
+
+// Erase scrubs every contact of the requested companies and every occurrence of the requested
+// emails, then deletes the offloaded raw payloads of the companies. The returned audit entry
+// counts the rows touched per table; payloads that could not be deleted are logged.
+func (s *PrivacyService) Erase(ctx context.Context, req dto.PrivacyRequest, userID string) (*entity.PrivacyRequest, error) {
+	subjects, err := parsePrivacyRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	request := subjects.auditEntry(entity.PrivacyActionErase, userID)
+	refs, err := s.repo.ErasePrivacyData(ctx, request, subjects.emails)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if s.rawStore == nil {
+			log.Printf("privacy erasure %s: raw payload %s left in object storage, no store configured", request.ID, ref)
+			continue
+		}
+		if err := s.rawStore.Delete(ctx, ref); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			log.Printf("privacy erasure %s: delete raw payload %s: %v", request.ID, ref, err)
+		}
+	}
+	return request, nil
+}
+
+// Export returns everything held about the requested emails and companies, including the
+// companies whose contacts hold one of the emails, and records the export in the audit trail.
+func (s *PrivacyService) Export(ctx context.Context, req dto.PrivacyRequest, userID string) (*entity.PrivacyExport, error) {
+	subjects, err := parsePrivacyRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := s.repo.FindCompaniesByEmail(ctx, subjects.emails)
+	if err != nil {
+		return nil, err
+	}
+	companyIDs := slices.Clone(subjects.companyIDs)
+	for _, id := range matched {
+		if !slices.Contains(companyIDs, id) {
+			companyIDs = append(companyIDs, id)
+		}
+	}
+
+	export, err := s.repo.ExportPrivacyData(ctx, companyIDs, subjects.emails)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachRawPayloads(ctx, &export); err != nil {
+		return nil, err
+	}
+
+	request := subjects.auditEntry(entity.PrivacyActionExport, userID)
+	request.Counts = make(map[string]int64, len(export.Records))
+	for table, records := range export.Records {
+		request.Counts[table] = int64(len(records))
+	}
+	if err := s.repo.RecordPrivacyRequest(ctx, request); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// attachRawPayloads adds the offloaded raw payloads of the exported companies.
+func (s *PrivacyService) attachRawPayloads(ctx context.Context, export *entity.PrivacyExport) error {
+	for _, record := range export.Records["companies"] {
+		var company struct {
+			ID     string  `json:"id"`
+			RawRef *string `json:"raw_ref"`
+		}
+		if err := json.Unmarshal(record, &company); err != nil {
+			return fmt.Errorf("decode exported company: %w", err)
+		}
+		if company.RawRef == nil || *company.RawRef == "" {
+			continue
+		}
+		if s.rawStore == nil {
+			return ErrRawStoreNotConfigured
+		}
+		payload, err := readRawObject(ctx, s.rawStore, *company.RawRef)
+		if err != nil {
+			if errors.Is(err, ErrRawPayloadMissing) {
+				continue
+			}
+			return err
+		}
+		if export.RawPayloads == nil {
+			export.RawPayloads = make(map[string]json.RawMessage)
+		}
+		export.RawPayloads[company.ID] = payload
+	}
+	return nil
+}
+
+// ListRequests returns the privacy audit trail, newest first, applying the usual pagination
+// defaults.
+func (s *PrivacyService) ListRequests(ctx context.Context, page, perPage int) ([]entity.PrivacyRequest, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return s.repo.ListPrivacyRequests(ctx, perPage, (page-1)*perPage)
+}
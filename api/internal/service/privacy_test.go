@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+type stubPrivacyRepo struct {
+	matched  []uuid.UUID
+	export   entity.PrivacyExport
+	refs     []string
+	emails   []string
+	exported []uuid.UUID
+	recorded []*entity.PrivacyRequest
+}
+
+func (s *stubPrivacyRepo) FindCompaniesByEmail(ctx context.Context, emails []string) ([]uuid.UUID, error) {
+	return s.matched, nil
+}
+
+func (s *stubPrivacyRepo) ExportPrivacyData(ctx context.Context, companyIDs []uuid.UUID, emails []string) (entity.PrivacyExport, error) {
+	s.exported, s.emails = companyIDs, emails
+	return s.export, nil
+}
+
+func (s *stubPrivacyRepo) ErasePrivacyData(ctx context.Context, request *entity.PrivacyRequest, emails []string) ([]string, error) {
+	s.emails = emails
+	request.Counts = map[string]int64{"companies": int64(len(request.CompanyIDs))}
+	s.recorded = append(s.recorded, request)
+	return s.refs, nil
+}
+
+func (s *stubPrivacyRepo) RecordPrivacyRequest(ctx context.Context, request *entity.PrivacyRequest) error {
+	s.recorded = append(s.recorded, request)
+	return nil
+}
+
+func (s *stubPrivacyRepo) ListPrivacyRequests(ctx context.Context, limit, offset int) ([]entity.PrivacyRequest, error) {
+	return nil, nil
+}
+
+type stubPrivacyStore struct {
+	objects map[string]string
+	deleted []string
+}
+
+func (s *stubPrivacyStore) Upload(ctx context.Context, name, contentType string, r io.Reader) error {
+	return nil
+}
+
+func (s *stubPrivacyStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(s.objects[name])), nil
+}
+
+func (s *stubPrivacyStore) Delete(ctx context.Context, name string) error {
+	s.deleted = append(s.deleted, name)
+	return nil
+}
+
+func TestPrivacyService_RejectsInvalidRequests(t *testing.T) {
+	svc := NewPrivacyService(&stubPrivacyRepo{}, nil)
+
+	for name, req := range map[string]dto.PrivacyRequest{
+		"empty":      {},
+		"bad email":  {Emails: []string{"not-an-email"}},
+		"bad id":     {CompanyIDs: []string{"42"}},
+		"too many":   {Emails: make([]string, maxPrivacySubjects+1)},
+		"blank only": {Emails: []string{"  "}},
+	} {
+		if _, err := svc.Erase(context.Background(), req, ""); !errors.Is(err, ErrInvalidPrivacyRequest) {
+			t.Fatalf("%s: expected ErrInvalidPrivacyRequest, got %v", name, err)
+		}
+	}
+}
+
+func TestPrivacyService_EraseHashesSubjectsAndDeletesPayloads(t *testing.T) {
+	companyID := uuid.New()
+	userID := uuid.New()
+	repo := &stubPrivacyRepo{refs: []string{"raw/place.json"}}
+	store := &stubPrivacyStore{}
+	svc := NewPrivacyService(repo, store)
+
+	request, err := svc.Erase(context.Background(), dto.PrivacyRequest{
+		Emails:     []string{" Jane@Example.com", "jane@example.com"},
+		CompanyIDs: []string{companyID.String()},
+	}, userID.String())
+	if err != nil {
+		t.Fatalf("erase: %v", err)
+	}
+	if len(repo.emails) != 1 || repo.emails[0] != "jane@example.com" {
+		t.Fatalf("expected one normalised email, got %v", repo.emails)
+	}
+	if request.Action != entity.PrivacyActionErase || request.RequestedBy == nil || *request.RequestedBy != userID {
+		t.Fatalf("unexpected audit entry %+v", request)
+	}
+	if len(request.SubjectHashes) != 1 || strings.Contains(request.SubjectHashes[0], "@") || len(request.SubjectHashes[0]) != 64 {
+		t.Fatalf("expected the email to be recorded as a hash, got %v", request.SubjectHashes)
+	}
+	if len(request.CompanyIDs) != 1 || request.CompanyIDs[0] != companyID {
+		t.Fatalf("expected the company recorded, got %v", request.CompanyIDs)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "raw/place.json" {
+		t.Fatalf("expected the offloaded payload deleted, got %v", store.deleted)
+	}
+}
+
+func TestPrivacyService_ExportIncludesMatchedCompaniesAndRawPayloads(t *testing.T) {
+	requested, matched := uuid.New(), uuid.New()
+	repo := &stubPrivacyRepo{
+		matched: []uuid.UUID{matched, requested},
+		export: entity.PrivacyExport{Records: map[string][]json.RawMessage{
+			"companies": {
+				json.RawMessage(`{"id":"` + matched.String() + `","raw_ref":"raw/a.json"}`),
+				json.RawMessage(`{"id":"` + requested.String() + `","raw_ref":null}`),
+			},
+			"company_enrichments": {json.RawMessage(`{"emails":["jane@example.com"]}`)},
+		}},
+	}
+	store := &stubPrivacyStore{objects: map[string]string{"raw/a.json": `{"name":"Kopi"}`}}
+	svc := NewPrivacyService(repo, store)
+
+	export, err := svc.Export(context.Background(), dto.PrivacyRequest{
+		Emails:     []string{"jane@example.com"},
+		CompanyIDs: []string{requested.String()},
+	}, "")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(repo.exported) != 2 || repo.exported[0] != requested || repo.exported[1] != matched {
+		t.Fatalf("expected requested then matched companies, got %v", repo.exported)
+	}
+	if string(export.RawPayloads[matched.String()]) != `{"name":"Kopi"}` || len(export.RawPayloads) != 1 {
+		t.Fatalf("expected the offloaded payload attached, got %v", export.RawPayloads)
+	}
+	if len(repo.recorded) != 1 || repo.recorded[0].Action != entity.PrivacyActionExport {
+		t.Fatalf("expected the export audited, got %+v", repo.recorded)
+	}
+	if counts := repo.recorded[0].Counts; counts["companies"] != 2 || counts["company_enrichments"] != 1 {
+		t.Fatalf("unexpected audit counts %v", counts)
+	}
+}
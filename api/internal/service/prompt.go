@@ -3,10 +3,13 @@ package service
 import (
 	"errors"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
 )
 
 type cityAlias struct {
@@ -20,7 +23,8 @@ var (
 	numberPattern    = regexp.MustCompile(`(?i)\b(\d+)\b`)
 	nowebsitePattern = regexp.MustCompile(`(?i)(belum\s+(punya|memiliki)\s+website|tanpa\s+website|without\s+(a\s+)?website|no\s+website)`)
 	intentKeywords   = regexp.MustCompile(`(?i)\b(cari|search|find|scrape|look|looking|discover)\b`)
-	knownCities      = []cityAlias{
+	// knownCities are the built-in city aliases; aliases stored in the database are matched first.
+	knownCities = []cityAlias{
 		{"malioboro", "Malioboro"},
 		{"jakarta", "Jakarta"},
 		{"yogyakarta", "Yogyakarta"},
@@ -46,9 +50,48 @@ var (
 	}
 )
 
+// promptAliases is an immutable snapshot of the parser's alias tables. Refreshes build a new
+// snapshot and swap it in whole, so a concurrent Parse never sees a half-built table.
+type promptAliases struct {
+	cities        []cityAlias
+	businessTypes map[string]string
+}
+
+func newPromptAliases(aliases []entity.PromptAlias) *promptAliases {
+	snapshot := &promptAliases{businessTypes: make(map[string]string)}
+	overridden := make(map[string]bool)
+	for _, alias := range aliases {
+		match := strings.ToLower(strings.TrimSpace(alias.Alias))
+		canonical := strings.TrimSpace(alias.Canonical)
+		if match == "" || canonical == "" {
+			continue
+		}
+		switch alias.Kind {
+		case entity.PromptAliasKindCity:
+			snapshot.cities = append(snapshot.cities, cityAlias{match: match, canonical: canonical})
+			overridden[match] = true
+		case entity.PromptAliasKindBusinessType:
+			snapshot.businessTypes[match] = canonical
+		}
+	}
+	// Longer aliases first so "jakarta selatan" wins over "jakarta".
+	sort.SliceStable(snapshot.cities, func(i, j int) bool {
+		return len(snapshot.cities[i].match) > len(snapshot.cities[j].match)
+	})
+	for _, alias := range knownCities {
+		if !overridden[alias.match] {
+			snapshot.cities = append(snapshot.cities, alias)
+		}
+	}
+	return snapshot
+}
+
 // PromptService interprets free-form search prompts.
 type PromptService struct {
 	DefaultCountry string
+	aliases        atomic.Pointer[promptAliases]
+	// locations normalises parsed cities onto the location catalog; nil keeps them as parsed.
+	locations atomic.Pointer[LocationService]
 }
 
 // PromptResult contains structured parameters derived from a prompt.
@@ -61,12 +104,28 @@ type PromptResult struct {
 	RequireNoWebsite bool
 }
 
+// ErrPromptNotRecognized is returned for prompts that do not ask to search for businesses.
+var ErrPromptNotRecognized = errors.New("prompt not recognized; use a sentence like 'cari PT di Jakarta' to search for contact data")
+
 // NewPromptService creates a prompt parser with sensible defaults.
 func NewPromptService(defaultCountry string) *PromptService {
 	if strings.TrimSpace(defaultCountry) == "" {
 		defaultCountry = "Indonesia"
 	}
-	return &PromptService{DefaultCountry: defaultCountry}
+	svc := &PromptService{DefaultCountry: defaultCountry}
+	svc.aliases.Store(newPromptAliases(nil))
+	return svc
+}
+
+// SetAliases replaces the database-managed aliases; built-in city aliases stay available unless
+// an alias with the same spelling overrides them. Safe to call while prompts are being parsed.
+func (s *PromptService) SetAliases(aliases []entity.PromptAlias) {
+	s.aliases.Store(newPromptAliases(aliases))
+}
+
+// SetLocations normalises the cities of parsed prompts onto the catalog of locations.
+func (s *PromptService) SetLocations(locations *LocationService) {
+	s.locations.Store(locations)
 }
 
 // Parse converts a prompt request into a structured search query.
@@ -76,7 +135,7 @@ func (s *PromptService) Parse(req dto.PromptSearchRequest) (PromptResult, error)
 		return PromptResult{}, errors.New("prompt is required")
 	}
 	if !intentKeywords.MatchString(prompt) {
-		return PromptResult{}, errors.New("prompt tidak dikenali. Gunakan kalimat seperti 'cari PT di Jakarta' untuk mencari data kontak")
+		return PromptResult{}, ErrPromptNotRecognized
 	}
 
 	country := strings.TrimSpace(req.Country)
@@ -84,10 +143,17 @@ func (s *PromptService) Parse(req dto.PromptSearchRequest) (PromptResult, error)
 		country = s.DefaultCountry
 	}
 
-	city, typeBusiness := extractCityAndType(prompt)
+	aliases := s.aliases.Load()
+	city, typeBusiness := extractCityAndType(prompt, aliases.cities)
+	if canonical, ok := aliases.businessTypes[strings.ToLower(typeBusiness)]; ok {
+		typeBusiness = canonical
+	}
 	if city == "" {
 		city = "Jakarta"
 	}
+	if locations := s.locations.Load(); locations != nil {
+		city = locations.NormalizeCity(city, country)
+	}
 	if typeBusiness == "" {
 		typeBusiness = "business"
 	}
@@ -114,12 +180,12 @@ func (s *PromptService) Parse(req dto.PromptSearchRequest) (PromptResult, error)
 	}, nil
 }
 
-func extractCityAndType(prompt string) (string, string) {
+func extractCityAndType(prompt string, cities []cityAlias) (string, string) {
 	original := prompt
 	match := locationPattern.FindStringSubmatch(prompt)
 	city := ""
 	if len(match) > 1 {
-		city = deriveCityFromSegment(match[1])
+		city = deriveCityFromSegment(match[1], cities)
 	}
 
 	lower := strings.ToLower(original)
@@ -141,7 +207,7 @@ func extractCityAndType(prompt string) (string, string) {
 		}
 	}
 	if city == "" {
-		for _, alias := range knownCities {
+		for _, alias := range cities {
 			if idx := strings.Index(lower, alias.match); idx >= 0 {
 				city = alias.canonical
 				before := strings.TrimSpace(original[:idx])
@@ -221,13 +287,13 @@ func stripNumbers(value string) string {
 	return strings.TrimSpace(cleaned)
 }
 
-func deriveCityFromSegment(segment string) string {
+func deriveCityFromSegment(segment string, cities []cityAlias) string {
 	cleaned := stripTrailingKeywords(segment)
 	if cleaned == "" {
 		return ""
 	}
 	normalized := strings.ToLower(cleaned)
-	for _, alias := range knownCities {
+	for _, alias := range cities {
 		if strings.Contains(normalized, alias.match) {
 			return alias.canonical
 		}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrInvalidPromptAlias is returned when an alias payload fails validation.
+	ErrInvalidPromptAlias = errors.New("invalid prompt alias")
+	// ErrInvalidPromptAliasID is returned when an alias identifier cannot be parsed as UUID.
+	ErrInvalidPromptAliasID = errors.New("invalid prompt alias id")
+	// ErrPromptAliasNotFound indicates the requested alias does not exist.
+	ErrPromptAliasNotFound = errors.New("prompt alias not found")
+	// ErrPromptAliasExists is returned when the same alias is already defined for its kind.
+	ErrPromptAliasExists = errors.New("prompt alias already exists")
+)
+
+// PromptAliasService manages the database-backed aliases of the prompt parser and keeps the
+// parser in sync with them.
+type PromptAliasService struct {
+	repo   repository.PromptAliasesRepository
+	prompt *PromptService
+}
+
+// NewPromptAliasService builds a PromptAliasService feeding the given prompt parser.
+func NewPromptAliasService(repo repository.PromptAliasesRepository, prompt *PromptService) *PromptAliasService {
+	return &PromptAliasService{repo: repo, prompt: prompt}
+}
+
+// Refresh loads every alias and swaps them into the prompt parser.
+func (s *PromptAliasService) Refresh(ctx context.Context) error {
+	aliases, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.prompt.SetAliases(aliases)
+	return nil
+}
+
+// Run refreshes the aliases every interval until ctx is cancelled. A failed refresh is logged and
+// the parser keeps its previous aliases.
+func (s *PromptAliasService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to refresh prompt aliases: %v", err)
+			}
+		}
+	}
+}
+
+// List returns every stored alias.
+func (s *PromptAliasService) List(ctx context.Context) ([]entity.PromptAlias, error) {
+	return s.repo.List(ctx)
+}
+
+// Create stores a new alias and refreshes the parser so it applies immediately.
+func (s *PromptAliasService) Create(ctx context.Context, req dto.PromptAliasRequest) (*entity.PromptAlias, error) {
+	alias, err := buildPromptAlias(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, alias); err != nil {
+		return nil, mapPromptAliasError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return alias, nil
+}
+
+// Update replaces an alias and refreshes the parser.
+func (s *PromptAliasService) Update(ctx context.Context, idRaw string, req dto.PromptAliasRequest) (*entity.PromptAlias, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidPromptAliasID
+	}
+	alias, err := buildPromptAlias(req)
+	if err != nil {
+		return nil, err
+	}
+	alias.ID = id
+	if err := s.repo.Update(ctx, alias); err != nil {
+		return nil, mapPromptAliasError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return alias, nil
+}
+
+// Delete removes an alias and refreshes the parser.
+func (s *PromptAliasService) Delete(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return ErrInvalidPromptAliasID
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return mapPromptAliasError(err)
+	}
+	s.refreshAfterChange(ctx)
+	return nil
+}
+
+// refreshAfterChange applies a write on this instance right away; other instances pick it up on
+// their next periodic refresh.
+func (s *PromptAliasService) refreshAfterChange(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		log.Printf("failed to refresh prompt aliases after change: %v", err)
+	}
+}
+
+func buildPromptAlias(req dto.PromptAliasRequest) (*entity.PromptAlias, error) {
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	if kind != entity.PromptAliasKindCity && kind != entity.PromptAliasKindBusinessType {
+		return nil, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidPromptAlias, entity.PromptAliasKindCity, entity.PromptAliasKindBusinessType)
+	}
+	// Aliases are matched against lowercased prompts, so they are stored lowercased.
+	alias := strings.ToLower(strings.Join(strings.Fields(req.Alias), " "))
+	canonical := strings.Join(strings.Fields(req.Canonical), " ")
+	if alias == "" || canonical == "" {
+		return nil, fmt.Errorf("%w: alias and canonical are required", ErrInvalidPromptAlias)
+	}
+	return &entity.PromptAlias{Kind: kind, Alias: alias, Canonical: canonical}, nil
+}
+
+func mapPromptAliasError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrPromptAliasNotFound):
+		return ErrPromptAliasNotFound
+	case errors.Is(err, repository.ErrPromptAliasDuplicate):
+		return ErrPromptAliasExists
+	default:
+		return err
+	}
+}
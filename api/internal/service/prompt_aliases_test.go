@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockPromptAliasesRepository struct {
+	aliases []entity.PromptAlias
+	listErr error
+}
+
+func (m *mockPromptAliasesRepository) List(ctx context.Context) ([]entity.PromptAlias, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return append([]entity.PromptAlias(nil), m.aliases...), nil
+}
+
+func (m *mockPromptAliasesRepository) Create(ctx context.Context, alias *entity.PromptAlias) error {
+	for _, existing := range m.aliases {
+		if existing.Kind == alias.Kind && existing.Alias == alias.Alias {
+			return repository.ErrPromptAliasDuplicate
+		}
+	}
+	alias.ID = uuid.New()
+	m.aliases = append(m.aliases, *alias)
+	return nil
+}
+
+func (m *mockPromptAliasesRepository) Update(ctx context.Context, alias *entity.PromptAlias) error {
+	for i, existing := range m.aliases {
+		if existing.ID == alias.ID {
+			m.aliases[i] = *alias
+			return nil
+		}
+	}
+	return repository.ErrPromptAliasNotFound
+}
+
+func (m *mockPromptAliasesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, existing := range m.aliases {
+		if existing.ID == id {
+			m.aliases = append(m.aliases[:i], m.aliases[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrPromptAliasNotFound
+}
+
+func TestPromptService_SetAliases(t *testing.T) {
+	prompt := NewPromptService("Indonesia")
+	prompt.SetAliases([]entity.PromptAlias{
+		{Kind: entity.PromptAliasKindCity, Alias: "jaksel", Canonical: "Jakarta Selatan"},
+		{Kind: entity.PromptAliasKindCity, Alias: "jakarta selatan", Canonical: "Jakarta Selatan"},
+		{Kind: entity.PromptAliasKindCity, Alias: "jogja", Canonical: "Jogjakarta"},
+		{Kind: entity.PromptAliasKindBusinessType, Alias: "kafe", Canonical: "cafe"},
+	})
+
+	cases := []struct {
+		prompt string
+		city   string
+		typ    string
+	}{
+		{"cari kafe jaksel", "Jakarta Selatan", "cafe"},
+		{"cari kafe di jakarta selatan", "Jakarta Selatan", "cafe"},
+		{"cari bengkel jogja", "Jogjakarta", "bengkel"},
+		{"cari hotel bandung", "Bandung", "hotel"},
+	}
+	for _, tc := range cases {
+		result, err := prompt.Parse(dto.PromptSearchRequest{Prompt: tc.prompt})
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.prompt, err)
+		}
+		if result.City != tc.city || result.TypeBusiness != tc.typ {
+			t.Fatalf("%q: expected %s/%s, got %s/%s", tc.prompt, tc.typ, tc.city, result.TypeBusiness, result.City)
+		}
+	}
+}
+
+func TestPromptService_SetAliasesConcurrentParse(t *testing.T) {
+	prompt := NewPromptService("Indonesia")
+	aliases := []entity.PromptAlias{{Kind: entity.PromptAliasKindCity, Alias: "jaksel", Canonical: "Jakarta Selatan"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				prompt.SetAliases(aliases)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := prompt.Parse(dto.PromptSearchRequest{Prompt: "cari kafe jaksel"}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPromptAliasService(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockPromptAliasesRepository{}
+	prompt := NewPromptService("Indonesia")
+	svc := NewPromptAliasService(repo, prompt)
+
+	alias, err := svc.Create(ctx, dto.PromptAliasRequest{Kind: "City", Alias: "  JakSel ", Canonical: "Jakarta Selatan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alias.Kind != entity.PromptAliasKindCity || alias.Alias != "jaksel" {
+		t.Fatalf("expected normalised alias, got %+v", alias)
+	}
+	result, _ := prompt.Parse(dto.PromptSearchRequest{Prompt: "cari kafe jaksel"})
+	if result.City != "Jakarta Selatan" {
+		t.Fatalf("expected alias applied after create, got %q", result.City)
+	}
+
+	if _, err := svc.Create(ctx, dto.PromptAliasRequest{Kind: "city", Alias: "jaksel", Canonical: "South Jakarta"}); !errors.Is(err, ErrPromptAliasExists) {
+		t.Fatalf("expected ErrPromptAliasExists, got %v", err)
+	}
+	if _, err := svc.Create(ctx, dto.PromptAliasRequest{Kind: "country", Alias: "id", Canonical: "Indonesia"}); !errors.Is(err, ErrInvalidPromptAlias) {
+		t.Fatalf("expected ErrInvalidPromptAlias, got %v", err)
+	}
+	if _, err := svc.Update(ctx, "nope", dto.PromptAliasRequest{}); !errors.Is(err, ErrInvalidPromptAliasID) {
+		t.Fatalf("expected ErrInvalidPromptAliasID, got %v", err)
+	}
+
+	if err := svc.Delete(ctx, alias.ID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, _ = prompt.Parse(dto.PromptSearchRequest{Prompt: "cari kafe jaksel"})
+	if result.City == "Jakarta Selatan" {
+		t.Fatalf("expected alias removed after delete")
+	}
+	if err := svc.Delete(ctx, alias.ID.String()); !errors.Is(err, ErrPromptAliasNotFound) {
+		t.Fatalf("expected ErrPromptAliasNotFound, got %v", err)
+	}
+}
+
+func TestPromptAliasService_RefreshFailureKeepsAliases(t *testing.T) {
+	repo := &mockPromptAliasesRepository{aliases: []entity.PromptAlias{{Kind: entity.PromptAliasKindBusinessType, Alias: "kafe", Canonical: "cafe"}}}
+	prompt := NewPromptService("Indonesia")
+	svc := NewPromptAliasService(repo, prompt)
+	if err := svc.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo.listErr = errors.New("db down")
+	if err := svc.Refresh(context.Background()); err == nil {
+		t.Fatalf("expected refresh error")
+	}
+	result, _ := prompt.Parse(dto.PromptSearchRequest{Prompt: "cari kafe di Bandung"})
+	if result.TypeBusiness != "cafe" {
+		t.Fatalf("expected previous aliases kept, got %q", result.TypeBusiness)
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// rawReprocessQueueSize bounds the job ids waiting for the worker; the database allows one active job.
+const rawReprocessQueueSize = 4
+
+var (
+	// ErrInvalidRawReprocessJobID is returned when a job identifier cannot be parsed as UUID.
+	ErrInvalidRawReprocessJobID = errors.New("invalid raw reprocess job id")
+	// ErrRawReprocessJobNotFound indicates the requested job does not exist.
+	ErrRawReprocessJobNotFound = errors.New("raw reprocess job not found")
+	// ErrRawReprocessInProgress is returned when a job is requested while another one is active.
+	ErrRawReprocessInProgress = errors.New("a raw reprocess job is already queued or running")
+)
+
+// RawReprocessJobService extracts the place attributes of stored raw payloads again in the
+// background, e.g. after the extraction learned a new field. Like score recompute jobs, jobs record
+// after every batch which company they reached and continue from there after a restart.
+type RawReprocessJobService struct {
+	repo        repository.RawReprocessJobsRepository
+	maintenance *MaintenanceService
+	batchSize   int
+	queue       chan uuid.UUID
+	now         func() time.Time
+}
+
+// NewRawReprocessJobService builds a RawReprocessJobService reprocessing batchSize companies at a time.
+func NewRawReprocessJobService(repo repository.RawReprocessJobsRepository, maintenance *MaintenanceService, batchSize int) *RawReprocessJobService {
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+	return &RawReprocessJobService{
+		repo:        repo,
+		maintenance: maintenance,
+		batchSize:   batchSize,
+		queue:       make(chan uuid.UUID, rawReprocessQueueSize),
+		now:         time.Now,
+	}
+}
+
+// Create queues a job reprocessing the companies matching filter, every company when it narrows
+// nothing. query is the listing query string filter was parsed from, kept to show what the job
+// covers.
+func (s *RawReprocessJobService) Create(ctx context.Context, filter dto.ListFilter, query, createdBy string) (*entity.RawReprocessJob, error) {
+	filter.Page, filter.PerPage, filter.Limit = 0, 0, 0
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("encode raw reprocess filter: %w", err)
+	}
+	job := &entity.RawReprocessJob{ID: uuid.New(), Query: query, Filter: encoded, Status: entity.RawReprocessQueued}
+	if parsed, err := uuid.Parse(createdBy); err == nil {
+		job.CreatedBy = &parsed
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		if errors.Is(err, repository.ErrRawReprocessJobActive) {
+			return nil, ErrRawReprocessInProgress
+		}
+		return nil, err
+	}
+	if !s.enqueue(job.ID) {
+		s.fail(ctx, job, "raw reprocess queue is full")
+		return nil, ErrRawReprocessInProgress
+	}
+	return job, nil
+}
+
+// Get returns a job with its progress.
+func (s *RawReprocessJobService) Get(ctx context.Context, id string) (*entity.RawReprocessJob, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrInvalidRawReprocessJobID
+	}
+	job, err := s.repo.Get(ctx, parsed)
+	if err != nil {
+		if errors.Is(err, repository.ErrRawReprocessJobNotFound) {
+			return nil, ErrRawReprocessJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns jobs, newest first.
+func (s *RawReprocessJobService) List(ctx context.Context, limit, offset int) ([]entity.RawReprocessJob, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+// Resume queues the jobs left behind by a previous process, running ones included: they continue
+// after the last company they recorded.
+func (s *RawReprocessJobService) Resume(ctx context.Context) error {
+	jobs, err := s.repo.ListUnfinished(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if !s.enqueue(jobs[i].ID) {
+			s.fail(ctx, &jobs[i], "raw reprocess queue is full")
+		}
+	}
+	return nil
+}
+
+// Run processes queued jobs one at a time until ctx is cancelled.
+func (s *RawReprocessJobService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-s.queue:
+				s.process(ctx, id)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func (s *RawReprocessJobService) enqueue(id uuid.UUID) bool {
+	select {
+	case s.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// process runs a single job and records its outcome. A job cut short by shutdown stays running so
+// the next process resumes it.
+func (s *RawReprocessJobService) process(ctx context.Context, id uuid.UUID) {
+	job, err := s.repo.Get(ctx, id)
+	if err != nil {
+		log.Printf("raw reprocess job %s: load failed: %v", id, err)
+		return
+	}
+	var filter dto.ListFilter
+	if err := json.Unmarshal(job.Filter, &filter); err != nil {
+		s.fail(ctx, job, "decode filter: "+err.Error())
+		return
+	}
+	resuming := job.Status == entity.RawReprocessRunning
+	if job.StartedAt == nil {
+		started := s.now().UTC()
+		job.StartedAt = &started
+	}
+	job.Status = entity.RawReprocessRunning
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("raw reprocess job %s: failed to mark running: %v", id, err)
+		return
+	}
+
+	opts := PlaceAttributeOptions{
+		Filter:    filter,
+		BatchSize: s.batchSize,
+		OnStart: func(remaining int) error {
+			// A resumed job keeps its original total; what is left is already part of it.
+			if !resuming {
+				job.Total = remaining
+				return s.repo.Update(ctx, job)
+			}
+			return nil
+		},
+		OnBatch: func(last uuid.UUID, batch PlaceAttributeExtraction) error {
+			job.ResumeAfter = &last
+			job.Processed += batch.Companies + batch.Offloaded
+			job.Updated += batch.Companies
+			job.Skipped += batch.Offloaded
+			return s.repo.Update(ctx, job)
+		},
+	}
+	if job.ResumeAfter != nil {
+		opts.After = *job.ResumeAfter
+	}
+	_, extractErr := s.maintenance.ExtractPlaceAttributesFrom(ctx, opts)
+	if extractErr != nil && ctx.Err() != nil {
+		log.Printf("raw reprocess job %s interrupted after %d companies; it resumes on restart", id, job.Processed)
+		return
+	}
+
+	finished := s.now().UTC()
+	job.FinishedAt = &finished
+	job.Status = entity.RawReprocessCompleted
+	if extractErr != nil {
+		log.Printf("raw reprocess job %s failed: %v", id, extractErr)
+		message := "reprocess failed: " + extractErr.Error()
+		job.Status = entity.RawReprocessFailed
+		job.Error = &message
+	}
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("raw reprocess job %s: failed to record outcome: %v", id, err)
+	}
+}
+
+// fail marks a job that will not run as failed.
+func (s *RawReprocessJobService) fail(ctx context.Context, job *entity.RawReprocessJob, message string) {
+	finished := s.now().UTC()
+	job.Status = entity.RawReprocessFailed
+	job.Error = &message
+	job.FinishedAt = &finished
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("raw reprocess job %s: failed to mark failed: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockRawReprocessJobsRepository struct {
+	jobs     map[uuid.UUID]entity.RawReprocessJob
+	updates  []entity.RawReprocessJob
+	onUpdate func(job entity.RawReprocessJob)
+}
+
+func (m *mockRawReprocessJobsRepository) Create(ctx context.Context, job *entity.RawReprocessJob) error {
+	for _, existing := range m.jobs {
+		if existing.Status == entity.RawReprocessQueued || existing.Status == entity.RawReprocessRunning {
+			return repository.ErrRawReprocessJobActive
+		}
+	}
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockRawReprocessJobsRepository) Update(ctx context.Context, job *entity.RawReprocessJob) error {
+	m.jobs[job.ID] = *job
+	m.updates = append(m.updates, *job)
+	if m.onUpdate != nil {
+		m.onUpdate(*job)
+	}
+	return nil
+}
+
+func (m *mockRawReprocessJobsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.RawReprocessJob, error) {
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, repository.ErrRawReprocessJobNotFound
+	}
+	return &job, nil
+}
+
+func (m *mockRawReprocessJobsRepository) List(ctx context.Context, limit, offset int) ([]entity.RawReprocessJob, error) {
+	return nil, nil
+}
+
+func (m *mockRawReprocessJobsRepository) ListUnfinished(ctx context.Context) ([]entity.RawReprocessJob, error) {
+	var jobs []entity.RawReprocessJob
+	for _, job := range m.jobs {
+		if job.Status == entity.RawReprocessQueued || job.Status == entity.RawReprocessRunning {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func rawReprocessFixture() *mockPlaceAttributesRepository {
+	ref := "raw/2.json"
+	return &mockPlaceAttributesRepository{payloads: []entity.CompanyPlacePayload{
+		{CompanyID: placePayloadID(1), Raw: json.RawMessage(`{"price_level": 2}`)},
+		{CompanyID: placePayloadID(2), RawRef: &ref},
+		{CompanyID: placePayloadID(3), Raw: json.RawMessage(`{"types": ["cafe"]}`)},
+	}}
+}
+
+func TestRawReprocessJobService_ProcessRecordsProgress(t *testing.T) {
+	attrs := rawReprocessFixture()
+	repo := &mockRawReprocessJobsRepository{jobs: map[uuid.UUID]entity.RawReprocessJob{}}
+	svc := NewRawReprocessJobService(repo, NewMaintenanceService(&mockMaintenanceRepository{}, WithPlaceAttributes(attrs, nil)), 2)
+
+	job, err := svc.Create(context.Background(), dto.ListFilter{City: "Bandung", Page: 4}, "city=Bandung&page=4", uuid.NewString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Create(context.Background(), dto.ListFilter{}, "", ""); !errors.Is(err, ErrRawReprocessInProgress) {
+		t.Fatalf("expected a second job to be refused, got %v", err)
+	}
+
+	svc.process(context.Background(), <-svc.queue)
+	done := repo.jobs[job.ID]
+	if done.Status != entity.RawReprocessCompleted || done.Total != 3 || done.Processed != 3 || done.Updated != 2 || done.Skipped != 1 || done.FinishedAt == nil {
+		t.Fatalf("unexpected finished job: %+v", done)
+	}
+	if attrs.filter.City != "Bandung" || attrs.filter.Page != 0 || done.Query != "city=Bandung&page=4" {
+		t.Fatalf("expected the stored filter without pagination, got %+v", attrs.filter)
+	}
+	// running, total, first batch, second batch, outcome
+	if len(repo.updates) != 5 || repo.updates[2].Processed != 2 || *repo.updates[2].ResumeAfter != placePayloadID(2) {
+		t.Fatalf("expected progress recorded per batch, got %+v", repo.updates)
+	}
+}
+
+func TestRawReprocessJobService_ResumesInterruptedJob(t *testing.T) {
+	attrs := rawReprocessFixture()
+	after := placePayloadID(2)
+	job := entity.RawReprocessJob{ID: uuid.New(), Filter: []byte(`{}`), Status: entity.RawReprocessRunning, Total: 3, Processed: 2, Updated: 1, Skipped: 1, ResumeAfter: &after}
+	repo := &mockRawReprocessJobsRepository{jobs: map[uuid.UUID]entity.RawReprocessJob{job.ID: job}}
+	svc := NewRawReprocessJobService(repo, NewMaintenanceService(&mockMaintenanceRepository{}, WithPlaceAttributes(attrs, nil)), 2)
+
+	if err := svc.Resume(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.process(context.Background(), <-svc.queue)
+
+	done := repo.jobs[job.ID]
+	if done.Status != entity.RawReprocessCompleted || done.Total != 3 || done.Processed != 3 || done.Updated != 2 {
+		t.Fatalf("unexpected resumed job: %+v", done)
+	}
+	if len(attrs.updated) != 1 {
+		t.Fatalf("expected only the companies after the cursor to be reprocessed, got %v", attrs.updated)
+	}
+}
+
+func TestRawReprocessJobService_ShutdownLeavesJobRunning(t *testing.T) {
+	attrs := rawReprocessFixture()
+	repo := &mockRawReprocessJobsRepository{jobs: map[uuid.UUID]entity.RawReprocessJob{}}
+	svc := NewRawReprocessJobService(repo, NewMaintenanceService(&mockMaintenanceRepository{}, WithPlaceAttributes(attrs, nil)), 2)
+	job, err := svc.Create(context.Background(), dto.ListFilter{}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo.onUpdate = func(job entity.RawReprocessJob) {
+		if job.Processed > 0 {
+			cancel()
+		}
+	}
+	svc.process(ctx, <-svc.queue)
+
+	interrupted := repo.jobs[job.ID]
+	if interrupted.Status != entity.RawReprocessRunning || interrupted.Processed != 2 || interrupted.FinishedAt != nil {
+		t.Fatalf("expected the interrupted job to stay running for a resume, got %+v", interrupted)
+	}
+}
+
+func TestRawReprocessJobService_FailsWithoutExtraction(t *testing.T) {
+	repo := &mockRawReprocessJobsRepository{jobs: map[uuid.UUID]entity.RawReprocessJob{}}
+	svc := NewRawReprocessJobService(repo, NewMaintenanceService(&mockMaintenanceRepository{}), 0)
+	job, err := svc.Create(context.Background(), dto.ListFilter{}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.process(context.Background(), <-svc.queue)
+	if failed := repo.jobs[job.ID]; failed.Status != entity.RawReprocessFailed || failed.Error == nil {
+		t.Fatalf("expected the job to fail, got %+v", failed)
+	}
+}
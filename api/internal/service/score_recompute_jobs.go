@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// scoreRecomputeQueueSize bounds the job ids waiting for the worker; the database allows one active job.
+const scoreRecomputeQueueSize = 4
+
+var (
+	// ErrInvalidScoreRecomputeJobID is returned when a job identifier cannot be parsed as UUID.
+	ErrInvalidScoreRecomputeJobID = errors.New("invalid score recompute job id")
+	// ErrScoreRecomputeJobNotFound indicates the requested job does not exist.
+	ErrScoreRecomputeJobNotFound = errors.New("score recompute job not found")
+	// ErrScoreRecomputeInProgress is returned when a job is requested while another one is active.
+	ErrScoreRecomputeInProgress = errors.New("a score recompute job is already queued or running")
+)
+
+// ScoreRecomputeJobService recomputes stored lead scores in the background, e.g. after scoring rules
+// changed or a bulk import. Jobs record after every batch which company they reached, so a job
+// interrupted by a restart continues from there instead of starting over.
+type ScoreRecomputeJobService struct {
+	repo        repository.ScoreRecomputeJobsRepository
+	maintenance *MaintenanceService
+	batchSize   int
+	queue       chan uuid.UUID
+	now         func() time.Time
+}
+
+// NewScoreRecomputeJobService builds a ScoreRecomputeJobService scoring batchSize enrichments at a time.
+func NewScoreRecomputeJobService(repo repository.ScoreRecomputeJobsRepository, maintenance *MaintenanceService, batchSize int) *ScoreRecomputeJobService {
+	if batchSize <= 0 {
+		batchSize = defaultScoreBatchSize
+	}
+	return &ScoreRecomputeJobService{
+		repo:        repo,
+		maintenance: maintenance,
+		batchSize:   batchSize,
+		queue:       make(chan uuid.UUID, scoreRecomputeQueueSize),
+		now:         time.Now,
+	}
+}
+
+// Create queues a job recomputing every score, or with staleOnly only the stale ones.
+func (s *ScoreRecomputeJobService) Create(ctx context.Context, createdBy string, staleOnly bool) (*entity.ScoreRecomputeJob, error) {
+	job := &entity.ScoreRecomputeJob{ID: uuid.New(), StaleOnly: staleOnly, Status: entity.ScoreRecomputeQueued}
+	if parsed, err := uuid.Parse(createdBy); err == nil {
+		job.CreatedBy = &parsed
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		if errors.Is(err, repository.ErrScoreRecomputeJobActive) {
+			return nil, ErrScoreRecomputeInProgress
+		}
+		return nil, err
+	}
+	if !s.enqueue(job.ID) {
+		s.fail(ctx, job, "score recompute queue is full")
+		return nil, ErrScoreRecomputeInProgress
+	}
+	return job, nil
+}
+
+// Get returns a job with its progress.
+func (s *ScoreRecomputeJobService) Get(ctx context.Context, id string) (*entity.ScoreRecomputeJob, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrInvalidScoreRecomputeJobID
+	}
+	job, err := s.repo.Get(ctx, parsed)
+	if err != nil {
+		if errors.Is(err, repository.ErrScoreRecomputeJobNotFound) {
+			return nil, ErrScoreRecomputeJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns jobs, newest first.
+func (s *ScoreRecomputeJobService) List(ctx context.Context, limit, offset int) ([]entity.ScoreRecomputeJob, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+// Resume queues the jobs left behind by a previous process. Unlike imports, running jobs are
+// queued again too: they continue after the last company they recorded.
+func (s *ScoreRecomputeJobService) Resume(ctx context.Context) error {
+	jobs, err := s.repo.ListUnfinished(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if !s.enqueue(jobs[i].ID) {
+			s.fail(ctx, &jobs[i], "score recompute queue is full")
+		}
+	}
+	return nil
+}
+
+// Run processes queued jobs one at a time until ctx is cancelled.
+func (s *ScoreRecomputeJobService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-s.queue:
+				s.process(ctx, id)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func (s *ScoreRecomputeJobService) enqueue(id uuid.UUID) bool {
+	select {
+	case s.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// process runs a single job and records its outcome. A job cut short by shutdown stays running so
+// the next process resumes it.
+func (s *ScoreRecomputeJobService) process(ctx context.Context, id uuid.UUID) {
+	job, err := s.repo.Get(ctx, id)
+	if err != nil {
+		log.Printf("score recompute job %s: load failed: %v", id, err)
+		return
+	}
+	resuming := job.Status == entity.ScoreRecomputeRunning
+	if job.StartedAt == nil {
+		started := s.now().UTC()
+		job.StartedAt = &started
+	}
+	job.Status = entity.ScoreRecomputeRunning
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("score recompute job %s: failed to mark running: %v", id, err)
+		return
+	}
+
+	opts := ScoreRecomputeOptions{
+		BatchSize: s.batchSize,
+		StaleOnly: job.StaleOnly,
+		OnStart: func(remaining int) error {
+			// A resumed job keeps its original total; what is left is already part of it.
+			if !resuming {
+				job.Total = remaining
+				return s.repo.Update(ctx, job)
+			}
+			return nil
+		},
+		OnBatch: func(last uuid.UUID, scored int) error {
+			job.ResumeAfter = &last
+			job.Processed += scored
+			return s.repo.Update(ctx, job)
+		},
+	}
+	if job.ResumeAfter != nil {
+		opts.After = *job.ResumeAfter
+	}
+	_, recomputeErr := s.maintenance.RecomputeScoresFrom(ctx, opts)
+	if recomputeErr != nil && ctx.Err() != nil {
+		log.Printf("score recompute job %s interrupted after %d scores; it resumes on restart", id, job.Processed)
+		return
+	}
+
+	finished := s.now().UTC()
+	job.FinishedAt = &finished
+	job.Status = entity.ScoreRecomputeCompleted
+	if recomputeErr != nil {
+		log.Printf("score recompute job %s failed: %v", id, recomputeErr)
+		message := "recompute failed: " + recomputeErr.Error()
+		job.Status = entity.ScoreRecomputeFailed
+		job.Error = &message
+	}
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("score recompute job %s: failed to record outcome: %v", id, err)
+	}
+}
+
+// fail marks a job that will not run as failed.
+func (s *ScoreRecomputeJobService) fail(ctx context.Context, job *entity.ScoreRecomputeJob, message string) {
+	finished := s.now().UTC()
+	job.Status = entity.ScoreRecomputeFailed
+	job.Error = &message
+	job.FinishedAt = &finished
+	if err := s.repo.Update(ctx, job); err != nil {
+		log.Printf("score recompute job %s: failed to mark failed: %v", job.ID, err)
+	}
+}
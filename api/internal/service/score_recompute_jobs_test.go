@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockScoreRecomputeJobsRepository struct {
+	jobs     map[uuid.UUID]entity.ScoreRecomputeJob
+	updates  []entity.ScoreRecomputeJob
+	onUpdate func(job entity.ScoreRecomputeJob)
+}
+
+func (m *mockScoreRecomputeJobsRepository) Create(ctx context.Context, job *entity.ScoreRecomputeJob) error {
+	for _, existing := range m.jobs {
+		if existing.Status == entity.ScoreRecomputeQueued || existing.Status == entity.ScoreRecomputeRunning {
+			return repository.ErrScoreRecomputeJobActive
+		}
+	}
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockScoreRecomputeJobsRepository) Update(ctx context.Context, job *entity.ScoreRecomputeJob) error {
+	m.jobs[job.ID] = *job
+	m.updates = append(m.updates, *job)
+	if m.onUpdate != nil {
+		m.onUpdate(*job)
+	}
+	return nil
+}
+
+func (m *mockScoreRecomputeJobsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.ScoreRecomputeJob, error) {
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, repository.ErrScoreRecomputeJobNotFound
+	}
+	return &job, nil
+}
+
+func (m *mockScoreRecomputeJobsRepository) List(ctx context.Context, limit, offset int) ([]entity.ScoreRecomputeJob, error) {
+	return nil, nil
+}
+
+func (m *mockScoreRecomputeJobsRepository) ListUnfinished(ctx context.Context) ([]entity.ScoreRecomputeJob, error) {
+	var jobs []entity.ScoreRecomputeJob
+	for _, job := range m.jobs {
+		if job.Status == entity.ScoreRecomputeQueued || job.Status == entity.ScoreRecomputeRunning {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func scoreRecomputeFixture() *mockMaintenanceRepository {
+	return &mockMaintenanceRepository{enrichments: []entity.CompanyEnrichment{
+		{CompanyID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Emails: []string{"a@example.com"}},
+		{CompanyID: uuid.MustParse("22222222-2222-2222-2222-222222222222")},
+		{CompanyID: uuid.MustParse("33333333-3333-3333-3333-333333333333"), Phones: []string{"+62"}},
+	}}
+}
+
+func TestScoreRecomputeJobService_ProcessRecordsProgress(t *testing.T) {
+	maintenance := scoreRecomputeFixture()
+	repo := &mockScoreRecomputeJobsRepository{jobs: map[uuid.UUID]entity.ScoreRecomputeJob{}}
+	svc := NewScoreRecomputeJobService(repo, NewMaintenanceService(maintenance), 2)
+
+	job, err := svc.Create(context.Background(), uuid.NewString(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Create(context.Background(), "", true); !errors.Is(err, ErrScoreRecomputeInProgress) {
+		t.Fatalf("expected a second job to be refused, got %v", err)
+	}
+
+	svc.process(context.Background(), <-svc.queue)
+	done := repo.jobs[job.ID]
+	if done.Status != entity.ScoreRecomputeCompleted || done.Total != 3 || done.Processed != 3 || done.FinishedAt == nil {
+		t.Fatalf("unexpected finished job: %+v", done)
+	}
+	if len(maintenance.scores) != 3 {
+		t.Fatalf("expected every enrichment scored, got %v", maintenance.scores)
+	}
+	// running, total, first batch, second batch, outcome
+	if len(repo.updates) != 5 || repo.updates[2].Processed != 2 || *repo.updates[2].ResumeAfter != maintenance.enrichments[1].CompanyID {
+		t.Fatalf("expected progress recorded per batch, got %+v", repo.updates)
+	}
+}
+
+func TestScoreRecomputeJobService_ResumesInterruptedJob(t *testing.T) {
+	maintenance := scoreRecomputeFixture()
+	after := maintenance.enrichments[1].CompanyID
+	job := entity.ScoreRecomputeJob{ID: uuid.New(), Status: entity.ScoreRecomputeRunning, Total: 3, Processed: 2, ResumeAfter: &after}
+	repo := &mockScoreRecomputeJobsRepository{jobs: map[uuid.UUID]entity.ScoreRecomputeJob{job.ID: job}}
+	svc := NewScoreRecomputeJobService(repo, NewMaintenanceService(maintenance), 2)
+
+	if err := svc.Resume(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.process(context.Background(), <-svc.queue)
+
+	done := repo.jobs[job.ID]
+	if done.Status != entity.ScoreRecomputeCompleted || done.Total != 3 || done.Processed != 3 {
+		t.Fatalf("unexpected resumed job: %+v", done)
+	}
+	if len(maintenance.scores) != 1 {
+		t.Fatalf("expected only the companies after the cursor to be scored, got %v", maintenance.scores)
+	}
+}
+
+func TestScoreRecomputeJobService_ShutdownLeavesJobRunning(t *testing.T) {
+	maintenance := scoreRecomputeFixture()
+	repo := &mockScoreRecomputeJobsRepository{jobs: map[uuid.UUID]entity.ScoreRecomputeJob{}}
+	svc := NewScoreRecomputeJobService(repo, NewMaintenanceService(maintenance), 2)
+	job, err := svc.Create(context.Background(), "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo.onUpdate = func(job entity.ScoreRecomputeJob) {
+		if job.Processed > 0 {
+			cancel()
+		}
+	}
+	svc.process(ctx, <-svc.queue)
+
+	interrupted := repo.jobs[job.ID]
+	if interrupted.Status != entity.ScoreRecomputeRunning || interrupted.Processed != 2 || interrupted.FinishedAt != nil {
+		t.Fatalf("expected the interrupted job to stay running for a resume, got %+v", interrupted)
+	}
+}
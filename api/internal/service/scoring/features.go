@@ -0,0 +1,115 @@
+package scoring
+
+import (
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// FeaturesFromEnrichment derives scoring signals from a stored company enrichment.
+func FeaturesFromEnrichment(enrichment *entity.CompanyEnrichment) LeadFeatures {
+	if enrichment == nil {
+		return LeadFeatures{}
+	}
+
+	socials := flattenSocials(enrichment.Socials)
+	address := derefString(enrichment.Address)
+	website := metadataString(enrichment.Metadata, "website")
+
+	hasContactForm := hasPointerValue(enrichment.ContactFormURL)
+	hasContactPage := hasContactForm
+	if !hasContactPage {
+		if metadataString(enrichment.Metadata, "contact_page_url") != "" {
+			hasContactPage = true
+		} else if flag, ok := metadataBool(enrichment.Metadata, "has_contact_page"); ok {
+			hasContactPage = flag
+		}
+	}
+
+	hasHTTPS := metadataBoolDefault(enrichment.Metadata, "https_enabled")
+	if !hasHTTPS && website != "" {
+		hasHTTPS = strings.HasPrefix(strings.ToLower(website), "https://")
+	}
+
+	return LeadFeatures{
+		Emails:         enrichment.Emails,
+		Phones:         enrichment.Phones,
+		Socials:        socials,
+		HasHTTPS:       hasHTTPS,
+		HasContactPage: hasContactPage,
+		HasAboutPage:   hasPointerValue(enrichment.AboutSummary),
+		HasContactForm: hasContactForm,
+		Address:        address,
+		Website:        website,
+	}
+}
+
+func flattenSocials(values map[string][]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for platform, links := range values {
+		value := firstNonEmpty(links)
+		if value == "" {
+			continue
+		}
+		result[platform] = value
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func firstNonEmpty(values []string) string {
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		if trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+func hasPointerValue(value *string) bool {
+	return derefString(value) != ""
+}
+
+func metadataString(meta map[string]any, key string) string {
+	if len(meta) == 0 {
+		return ""
+	}
+	if raw, ok := meta[key]; ok {
+		if str, ok := raw.(string); ok {
+			return strings.TrimSpace(str)
+		}
+	}
+	return ""
+}
+
+func metadataBool(meta map[string]any, key string) (bool, bool) {
+	if len(meta) == 0 {
+		return false, false
+	}
+	raw, ok := meta[key]
+	if !ok {
+		return false, false
+	}
+	flag, valid := raw.(bool)
+	return flag, valid
+}
+
+func metadataBoolDefault(meta map[string]any, key string) bool {
+	if flag, ok := metadataBool(meta, key); ok {
+		return flag
+	}
+	return false
+}
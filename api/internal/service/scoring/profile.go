@@ -0,0 +1,154 @@
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidProfile is returned when a scoring profile has unknown categories or out of range values.
+var ErrInvalidProfile = errors.New("invalid scoring profile")
+
+// Categories lists the score categories a profile can weigh.
+var Categories = []string{categoryContact, categoryWebsite, categorySocial, categoryBusiness, categorySize}
+
+// Tiers a lead falls into by its total score.
+const (
+	TierHot  = "hot"
+	TierWarm = "warm"
+	TierCold = "cold"
+)
+
+// Default tier thresholds.
+const (
+	DefaultHotThreshold  = 70
+	DefaultWarmThreshold = 40
+)
+
+// MaxCategoryWeight caps the points a profile may give a single category.
+const MaxCategoryWeight = 100
+
+// categoryScorers compute the built-in points of each category; profile weights rescale them from
+// categoryMax. The size category is weighted directly by scoreBusinessSize.
+var categoryScorers = map[string]func(LeadFeatures) int{
+	categoryContact:  scoreContactCompleteness,
+	categoryWebsite:  scoreWebsiteQuality,
+	categorySocial:   scoreSocialPresence,
+	categoryBusiness: scoreBusinessProfile,
+}
+
+var categoryMax = map[string]int{
+	categoryContact:  30,
+	categoryWebsite:  30,
+	categorySocial:   20,
+	categoryBusiness: 20,
+}
+
+// Profile decides what a good lead looks like for the team using it.
+type Profile struct {
+	Name string
+	// Weights is the maximum number of points of each category; missing or zero leaves it out.
+	Weights map[string]int
+	// Inverted categories score the absence of their signals, e.g. website_quality for a team
+	// selling websites, to whom a lead without one is the best lead.
+	Inverted []string
+	// HotThreshold and WarmThreshold are the totals from which a lead is hot or warm; zero disables the tier.
+	HotThreshold  int
+	WarmThreshold int
+}
+
+// DefaultProfile is the built-in profile used when no profile is active.
+func DefaultProfile(opts Options) Profile {
+	weights := make(map[string]int, len(categoryMax)+1)
+	for category, points := range categoryMax {
+		weights[category] = points
+	}
+	if opts.SizeWeight > 0 {
+		weights[categorySize] = opts.SizeWeight
+	}
+	return Profile{Weights: weights, HotThreshold: DefaultHotThreshold, WarmThreshold: DefaultWarmThreshold}
+}
+
+// Validate reports the first problem with the profile, wrapping ErrInvalidProfile.
+func (p Profile) Validate() error {
+	for category, weight := range p.Weights {
+		if !isCategory(category) {
+			return fmt.Errorf("%w: unknown category %q (use %s)", ErrInvalidProfile, category, strings.Join(Categories, ", "))
+		}
+		if weight < 0 || weight > MaxCategoryWeight {
+			return fmt.Errorf("%w: weight of %s must be between 0 and %d", ErrInvalidProfile, category, MaxCategoryWeight)
+		}
+	}
+	for _, category := range p.Inverted {
+		if !isCategory(category) {
+			return fmt.Errorf("%w: unknown inverted category %q", ErrInvalidProfile, category)
+		}
+	}
+	if p.HotThreshold < 0 || p.WarmThreshold < 0 {
+		return fmt.Errorf("%w: thresholds must not be negative", ErrInvalidProfile)
+	}
+	if p.HotThreshold > 0 && p.WarmThreshold > p.HotThreshold {
+		return fmt.Errorf("%w: warm_threshold must not exceed hot_threshold", ErrInvalidProfile)
+	}
+	return nil
+}
+
+// ComputeScoreWithProfile evaluates the provided features with the weights of profile.
+func ComputeScoreWithProfile(input LeadFeatures, profile Profile) ScoreResult {
+	inverted := make(map[string]bool, len(profile.Inverted))
+	for _, category := range profile.Inverted {
+		inverted[category] = true
+	}
+
+	breakdown := make(map[string]int, len(profile.Weights))
+	total := 0
+	for _, category := range Categories {
+		weight := profile.Weights[category]
+		if weight <= 0 {
+			continue
+		}
+		var points int
+		if category == categorySize {
+			points = scoreBusinessSize(input, weight)
+		} else {
+			points = (categoryScorers[category](input)*weight + categoryMax[category]/2) / categoryMax[category]
+		}
+		if inverted[category] {
+			points = weight - points
+		}
+		breakdown[category] = points
+		total += points
+	}
+
+	return ScoreResult{
+		Total:     total,
+		Breakdown: breakdown,
+		Tier:      profile.tier(total),
+		Profile:   profile.Name,
+	}
+}
+
+func (p Profile) tier(total int) string {
+	switch {
+	case p.HotThreshold > 0 && total >= p.HotThreshold:
+		return TierHot
+	case p.WarmThreshold > 0 && total >= p.WarmThreshold:
+		return TierWarm
+	default:
+		return TierCold
+	}
+}
+
+func isCategory(category string) bool {
+	for _, known := range Categories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesSize reports whether the profile weighs the business size, which needs the size bucket.
+func (p Profile) UsesSize() bool {
+	return p.Weights[categorySize] > 0
+}
@@ -4,6 +4,8 @@ import (
 	"net/url"
 	"strings"
 	"unicode"
+
+	"github.com/octobees/leads-generator/api/internal/service/sizing"
 )
 
 const (
@@ -11,8 +13,31 @@ const (
 	categoryWebsite  = "website_quality"
 	categorySocial   = "social_presence"
 	categoryBusiness = "business_profile"
+	categorySize     = "business_size"
 )
 
+// DefaultSizeWeight is the maximum number of points the business size factor contributes.
+const DefaultSizeWeight = 10
+
+// sizeFit expresses, in percent, how well each size bucket matches the SMB lead profile.
+var sizeFit = map[string]int{
+	sizing.BucketMicro:  50,
+	sizing.BucketSmall:  100,
+	sizing.BucketMedium: 100,
+	sizing.BucketLarge:  30,
+}
+
+// Options tunes optional scoring factors.
+type Options struct {
+	// SizeWeight caps the business size factor; zero leaves it out of the breakdown.
+	SizeWeight int
+}
+
+// DefaultOptions returns the scoring options used when none are configured.
+func DefaultOptions() Options {
+	return Options{SizeWeight: DefaultSizeWeight}
+}
+
 var freeHostingDomains = []string{
 	"wordpress.com",
 	"blogspot.com",
@@ -37,32 +62,26 @@ type LeadFeatures struct {
 	HasContactForm bool
 	Address        string
 	Website        string
+	SizeBucket     string
 }
 
 // ScoreResult reports the aggregate score and the per-category breakdown.
 type ScoreResult struct {
 	Total     int
 	Breakdown map[string]int
+	// Tier is hot, warm or cold by the thresholds of the profile; Profile names it, empty for the default.
+	Tier    string
+	Profile string
 }
 
-// ComputeScore evaluates the provided features and returns the score breakdown.
+// ComputeScore evaluates the provided features with the default options.
 func ComputeScore(input LeadFeatures) ScoreResult {
-	breakdown := map[string]int{
-		categoryContact:  scoreContactCompleteness(input),
-		categoryWebsite:  scoreWebsiteQuality(input),
-		categorySocial:   scoreSocialPresence(input),
-		categoryBusiness: scoreBusinessProfile(input),
-	}
-
-	total := 0
-	for _, value := range breakdown {
-		total += value
-	}
+	return ComputeScoreWithOptions(input, DefaultOptions())
+}
 
-	return ScoreResult{
-		Total:     total,
-		Breakdown: breakdown,
-	}
+// ComputeScoreWithOptions evaluates the provided features with the default profile.
+func ComputeScoreWithOptions(input LeadFeatures, opts Options) ScoreResult {
+	return ComputeScoreWithProfile(input, DefaultProfile(opts))
 }
 
 func scoreContactCompleteness(input LeadFeatures) int {
@@ -139,6 +158,11 @@ func scoreBusinessProfile(input LeadFeatures) int {
 	return score
 }
 
+func scoreBusinessSize(input LeadFeatures, weight int) int {
+	fit := sizeFit[strings.ToLower(strings.TrimSpace(input.SizeBucket))]
+	return (weight*fit + 50) / 100
+}
+
 func hasValue(values []string) bool {
 	for _, value := range values {
 		if strings.TrimSpace(value) != "" {
@@ -1,6 +1,9 @@
 package scoring
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestComputeScore_FullCoverage(t *testing.T) {
 	input := LeadFeatures{
@@ -97,3 +100,70 @@ func TestHasCompleteAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestComputeScoreWithOptions_BusinessSize(t *testing.T) {
+	cases := map[string]int{
+		"small":   10,
+		"medium":  10,
+		"micro":   5,
+		"large":   3,
+		"unknown": 0,
+		"":        0,
+	}
+	for bucket, want := range cases {
+		score := ComputeScoreWithOptions(LeadFeatures{SizeBucket: bucket}, Options{SizeWeight: 10})
+		if score.Breakdown[categorySize] != want || score.Total != want {
+			t.Fatalf("bucket %q: expected size score %d, got %+v", bucket, want, score)
+		}
+	}
+
+	score := ComputeScoreWithOptions(LeadFeatures{SizeBucket: "small"}, Options{})
+	if _, ok := score.Breakdown[categorySize]; ok || score.Total != 0 {
+		t.Fatalf("expected size factor disabled with zero weight, got %+v", score)
+	}
+}
+
+func TestComputeScoreWithProfile_WeightsInversionAndTiers(t *testing.T) {
+	noWebsite := LeadFeatures{Phones: []string{"+62215550123"}, Address: "Jl. Merdeka No. 8, Jakarta"}
+	profile := Profile{
+		Name:          "web agency",
+		Weights:       map[string]int{categoryContact: 20, categoryWebsite: 60, categoryBusiness: 20},
+		Inverted:      []string{categoryWebsite},
+		HotThreshold:  75,
+		WarmThreshold: 50,
+	}
+	if err := profile.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	score := ComputeScoreWithProfile(noWebsite, profile)
+	// Contact 10 of 30 scales to 7 of 20, the missing website earns all 60 and the address 10 of 20.
+	want := map[string]int{categoryContact: 7, categoryWebsite: 60, categoryBusiness: 10}
+	for category, points := range want {
+		if score.Breakdown[category] != points {
+			t.Fatalf("%s: expected %d, got %+v", category, points, score)
+		}
+	}
+	if len(score.Breakdown) != 3 || score.Total != 77 || score.Tier != TierHot || score.Profile != "web agency" {
+		t.Fatalf("unexpected score: %+v", score)
+	}
+
+	if score := ComputeScore(noWebsite); score.Tier != TierCold || score.Total != 20 {
+		t.Fatalf("expected the default profile to rank the lead cold, got %+v", score)
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	cases := []Profile{
+		{Weights: map[string]int{"vibes": 10}},
+		{Weights: map[string]int{categoryWebsite: 101}},
+		{Inverted: []string{"vibes"}},
+		{HotThreshold: 40, WarmThreshold: 60},
+		{WarmThreshold: -1},
+	}
+	for _, profile := range cases {
+		if err := profile.Validate(); !errors.Is(err, ErrInvalidProfile) {
+			t.Fatalf("expected ErrInvalidProfile for %+v, got %v", profile, err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+)
+
+var (
+	// ErrInvalidScoringProfile is returned when a profile payload fails validation; it is the error
+	// scoring.Profile.Validate wraps.
+	ErrInvalidScoringProfile = scoring.ErrInvalidProfile
+	// ErrInvalidScoringProfileID is returned when a profile identifier cannot be parsed as UUID.
+	ErrInvalidScoringProfileID = errors.New("invalid scoring profile id")
+	// ErrScoringProfileNotFound indicates the requested profile does not exist.
+	ErrScoringProfileNotFound = errors.New("scoring profile not found")
+	// ErrScoringProfileExists is returned when another profile already has the name.
+	ErrScoringProfileExists = errors.New("scoring profile already exists")
+)
+
+// DefaultScoringProfileID is accepted by Activate to go back to the built-in weights.
+const DefaultScoringProfileID = "default"
+
+// ScoringProfilesService manages the lead scoring profiles admins use to decide what a good lead
+// is. There is no organisation model, so one profile is active for the whole deployment.
+type ScoringProfilesService struct {
+	repo     repository.ScoringProfilesRepository
+	defaults scoring.Options
+}
+
+// NewScoringProfilesService builds a ScoringProfilesService; defaults configure the built-in profile.
+func NewScoringProfilesService(repo repository.ScoringProfilesRepository, defaults scoring.Options) *ScoringProfilesService {
+	return &ScoringProfilesService{repo: repo, defaults: defaults}
+}
+
+// List returns every stored profile.
+func (s *ScoringProfilesService) List(ctx context.Context) ([]entity.ScoringProfile, error) {
+	return s.repo.List(ctx)
+}
+
+// Create stores a new, inactive profile.
+func (s *ScoringProfilesService) Create(ctx context.Context, req dto.ScoringProfileRequest) (*entity.ScoringProfile, error) {
+	profile, err := buildScoringProfile(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, profile); err != nil {
+		return nil, mapScoringProfileError(err)
+	}
+	return profile, nil
+}
+
+// Update replaces a profile and bumps its revision, which marks the scores it computed as stale.
+func (s *ScoringProfilesService) Update(ctx context.Context, idRaw string, req dto.ScoringProfileRequest) (*entity.ScoringProfile, error) {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidScoringProfileID
+	}
+	profile, err := buildScoringProfile(req)
+	if err != nil {
+		return nil, err
+	}
+	profile.ID = id
+	if err := s.repo.Update(ctx, profile); err != nil {
+		return nil, mapScoringProfileError(err)
+	}
+	return profile, nil
+}
+
+// Delete removes a profile. Deleting the active profile brings back the built-in weights.
+func (s *ScoringProfilesService) Delete(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return ErrInvalidScoringProfileID
+	}
+	return mapScoringProfileError(s.repo.Delete(ctx, id))
+}
+
+// Activate makes a profile the one new scores are computed with, or the built-in weights for
+// DefaultScoringProfileID, in which case it returns nil.
+func (s *ScoringProfilesService) Activate(ctx context.Context, idRaw string) (*entity.ScoringProfile, error) {
+	if strings.EqualFold(strings.TrimSpace(idRaw), DefaultScoringProfileID) {
+		return nil, mapScoringProfileError(s.repo.SetActive(ctx, nil))
+	}
+	id, err := uuid.Parse(strings.TrimSpace(idRaw))
+	if err != nil {
+		return nil, ErrInvalidScoringProfileID
+	}
+	if err := s.repo.SetActive(ctx, &id); err != nil {
+		return nil, mapScoringProfileError(err)
+	}
+	profile, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, mapScoringProfileError(err)
+	}
+	return profile, nil
+}
+
+// Active returns the profile scores are currently computed with and its stored record, which is
+// nil for the built-in profile.
+func (s *ScoringProfilesService) Active(ctx context.Context) (scoring.Profile, *entity.ScoringProfile, error) {
+	return resolveScoringProfile(ctx, s.repo, s.defaults)
+}
+
+// resolveScoringProfile loads the active profile, falling back to the built-in one when none is
+// active or no repository is configured.
+func resolveScoringProfile(ctx context.Context, repo repository.ScoringProfilesRepository, defaults scoring.Options) (scoring.Profile, *entity.ScoringProfile, error) {
+	if repo == nil {
+		return scoring.DefaultProfile(defaults), nil, nil
+	}
+	active, err := repo.GetActive(ctx)
+	if err != nil {
+		return scoring.DefaultProfile(defaults), nil, err
+	}
+	if active == nil {
+		return scoring.DefaultProfile(defaults), nil, nil
+	}
+	return scoring.Profile{
+		Name:          active.Name,
+		Weights:       active.Weights,
+		Inverted:      active.Inverted,
+		HotThreshold:  active.HotThreshold,
+		WarmThreshold: active.WarmThreshold,
+	}, active, nil
+}
+
+func buildScoringProfile(req dto.ScoringProfileRequest) (*entity.ScoringProfile, error) {
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidScoringProfile)
+	}
+	if strings.EqualFold(name, DefaultScoringProfileID) {
+		return nil, fmt.Errorf("%w: %q is reserved for the built-in profile", ErrInvalidScoringProfile, DefaultScoringProfileID)
+	}
+
+	weights := make(map[string]int, len(req.Weights))
+	for category, weight := range req.Weights {
+		weights[strings.ToLower(strings.TrimSpace(category))] = weight
+	}
+	inverted := make([]string, 0, len(req.Inverted))
+	seen := make(map[string]bool, len(req.Inverted))
+	for _, category := range req.Inverted {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category != "" && !seen[category] {
+			seen[category] = true
+			inverted = append(inverted, category)
+		}
+	}
+	profile := scoring.Profile{
+		Name:          name,
+		Weights:       weights,
+		Inverted:      inverted,
+		HotThreshold:  scoring.DefaultHotThreshold,
+		WarmThreshold: scoring.DefaultWarmThreshold,
+	}
+	if req.HotThreshold != nil {
+		profile.HotThreshold = *req.HotThreshold
+	}
+	if req.WarmThreshold != nil {
+		profile.WarmThreshold = *req.WarmThreshold
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: at least one category needs a weight (use %s)", ErrInvalidScoringProfile, strings.Join(scoring.Categories, ", "))
+	}
+
+	return &entity.ScoringProfile{
+		Name:          profile.Name,
+		Weights:       profile.Weights,
+		Inverted:      profile.Inverted,
+		HotThreshold:  profile.HotThreshold,
+		WarmThreshold: profile.WarmThreshold,
+	}, nil
+}
+
+func mapScoringProfileError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrScoringProfileNotFound):
+		return ErrScoringProfileNotFound
+	case errors.Is(err, repository.ErrScoringProfileDuplicate):
+		return ErrScoringProfileExists
+	default:
+		return err
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/service/scoring"
+)
+
+type mockScoringProfilesRepository struct {
+	profiles map[uuid.UUID]*entity.ScoringProfile
+	err      error
+}
+
+func (m *mockScoringProfilesRepository) List(ctx context.Context) ([]entity.ScoringProfile, error) {
+	profiles := make([]entity.ScoringProfile, 0, len(m.profiles))
+	for _, profile := range m.profiles {
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
+
+func (m *mockScoringProfilesRepository) Get(ctx context.Context, id uuid.UUID) (*entity.ScoringProfile, error) {
+	profile, ok := m.profiles[id]
+	if !ok {
+		return nil, repository.ErrScoringProfileNotFound
+	}
+	return profile, nil
+}
+
+func (m *mockScoringProfilesRepository) GetActive(ctx context.Context) (*entity.ScoringProfile, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, profile := range m.profiles {
+		if profile.Active {
+			return profile, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockScoringProfilesRepository) Create(ctx context.Context, profile *entity.ScoringProfile) error {
+	for _, existing := range m.profiles {
+		if existing.Name == profile.Name {
+			return repository.ErrScoringProfileDuplicate
+		}
+	}
+	if m.profiles == nil {
+		m.profiles = make(map[uuid.UUID]*entity.ScoringProfile)
+	}
+	profile.ID, profile.Revision = uuid.New(), 1
+	m.profiles[profile.ID] = profile
+	return nil
+}
+
+func (m *mockScoringProfilesRepository) Update(ctx context.Context, profile *entity.ScoringProfile) error {
+	existing, ok := m.profiles[profile.ID]
+	if !ok {
+		return repository.ErrScoringProfileNotFound
+	}
+	profile.Active, profile.Revision = existing.Active, existing.Revision+1
+	m.profiles[profile.ID] = profile
+	return nil
+}
+
+func (m *mockScoringProfilesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, ok := m.profiles[id]; !ok {
+		return repository.ErrScoringProfileNotFound
+	}
+	delete(m.profiles, id)
+	return nil
+}
+
+func (m *mockScoringProfilesRepository) SetActive(ctx context.Context, id *uuid.UUID) error {
+	if id != nil {
+		if _, ok := m.profiles[*id]; !ok {
+			return repository.ErrScoringProfileNotFound
+		}
+	}
+	for profileID, profile := range m.profiles {
+		profile.Active = id != nil && profileID == *id
+	}
+	return nil
+}
+
+func TestScoringProfilesService_CreateValidates(t *testing.T) {
+	svc := NewScoringProfilesService(&mockScoringProfilesRepository{}, scoring.DefaultOptions())
+	ctx := context.Background()
+
+	invalid := []dto.ScoringProfileRequest{
+		{Weights: map[string]int{"website_quality": 50}},
+		{Name: "Default", Weights: map[string]int{"website_quality": 50}},
+		{Name: "Agency", Weights: map[string]int{"vibes": 50}},
+		{Name: "Agency", Weights: map[string]int{"website_quality": 0}},
+		{Name: "Agency", Weights: map[string]int{"website_quality": 50}, Inverted: []string{"vibes"}},
+	}
+	for _, req := range invalid {
+		if _, err := svc.Create(ctx, req); !errors.Is(err, ErrInvalidScoringProfile) {
+			t.Fatalf("expected ErrInvalidScoringProfile for %+v, got %v", req, err)
+		}
+	}
+
+	profile, err := svc.Create(ctx, dto.ScoringProfileRequest{Name: " Web  agency ", Weights: map[string]int{"Website_Quality": 60}, Inverted: []string{"website_quality", "website_quality"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Name != "Web agency" || profile.Weights["website_quality"] != 60 || len(profile.Inverted) != 1 ||
+		profile.HotThreshold != scoring.DefaultHotThreshold || profile.WarmThreshold != scoring.DefaultWarmThreshold {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+	if _, err := svc.Create(ctx, dto.ScoringProfileRequest{Name: "Web agency", Weights: map[string]int{"social_presence": 20}}); !errors.Is(err, ErrScoringProfileExists) {
+		t.Fatalf("expected ErrScoringProfileExists, got %v", err)
+	}
+}
+
+func TestScoringProfilesService_Activate(t *testing.T) {
+	repo := &mockScoringProfilesRepository{}
+	svc := NewScoringProfilesService(repo, scoring.DefaultOptions())
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, dto.ScoringProfileRequest{Name: "Agency", Weights: map[string]int{"website_quality": 60}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Activate(ctx, "nope"); !errors.Is(err, ErrInvalidScoringProfileID) {
+		t.Fatalf("expected ErrInvalidScoringProfileID, got %v", err)
+	}
+	if _, err := svc.Activate(ctx, uuid.NewString()); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Fatalf("expected ErrScoringProfileNotFound, got %v", err)
+	}
+
+	activated, err := svc.Activate(ctx, created.ID.String())
+	if err != nil || !activated.Active {
+		t.Fatalf("expected profile activated, got %+v (%v)", activated, err)
+	}
+	profile, active, err := svc.Active(ctx)
+	if err != nil || active == nil || profile.Name != "Agency" || profile.Weights["website_quality"] != 60 {
+		t.Fatalf("unexpected active profile: %+v %+v (%v)", profile, active, err)
+	}
+
+	if _, err := svc.Activate(ctx, "default"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile, active, _ := svc.Active(ctx); active != nil || profile.Weights["contact_completeness"] != 30 {
+		t.Fatalf("expected the built-in profile, got %+v", profile)
+	}
+}
+
+func TestCompaniesService_ScoreEnrichment_UsesActiveProfile(t *testing.T) {
+	id := uuid.New()
+	profiles := &mockScoringProfilesRepository{profiles: map[uuid.UUID]*entity.ScoringProfile{
+		id: {ID: id, Name: "Agency", Weights: map[string]int{"website_quality": 60}, Inverted: []string{"website_quality"}, HotThreshold: 50, Active: true},
+	}}
+	enrichment := &entity.CompanyEnrichment{CompanyID: uuid.New(), Phones: []string{"+62215550123"}}
+
+	score := NewCompaniesService(&mockCompaniesRepository{}, WithActiveScoringProfile(profiles)).ScoreEnrichment(context.Background(), enrichment)
+	if score.Total != 60 || score.Tier != scoring.TierHot || score.Profile != "Agency" {
+		t.Fatalf("expected the missing website to score under the active profile, got %+v", score)
+	}
+
+	profiles.err = errors.New("down")
+	score = NewCompaniesService(&mockCompaniesRepository{}, WithActiveScoringProfile(profiles)).ScoreEnrichment(context.Background(), enrichment)
+	if score.Profile != "" || score.Breakdown["contact_completeness"] != 10 {
+		t.Fatalf("expected built-in weights when the profile cannot be loaded, got %+v", score)
+	}
+}
+
+func TestMaintenanceService_RecomputeStaleScores(t *testing.T) {
+	id := uuid.New()
+	profiles := &mockScoringProfilesRepository{profiles: map[uuid.UUID]*entity.ScoringProfile{
+		id: {ID: id, Name: "Agency", Weights: map[string]int{"website_quality": 60}, Inverted: []string{"website_quality"}, Active: true, Revision: 2},
+	}}
+	first, second := uuid.MustParse("11111111-1111-1111-1111-111111111111"), uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	repo := &mockMaintenanceRepository{
+		enrichments: []entity.CompanyEnrichment{{CompanyID: first}, {CompanyID: second}},
+		revisions:   map[uuid.UUID]int{first: 2, second: 1},
+	}
+	svc := NewMaintenanceService(repo, WithScoringProfiles(profiles))
+
+	scored, err := svc.RecomputeStaleScores(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scored != 1 || repo.scores[second] != 60 || repo.revisions[second] != 2 {
+		t.Fatalf("expected only the stale score recomputed, got %d (%v)", scored, repo.scores)
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	// ErrInvalidScrapeRequester is returned when the caller of a scrape is not a valid user id.
+	ErrInvalidScrapeRequester = errors.New("invalid scrape requester")
+	// ErrInvalidScrapeJobID is returned when a scrape job identifier cannot be parsed as UUID.
+	ErrInvalidScrapeJobID = errors.New("invalid scrape job id")
+	// ErrScrapeJobNotFound indicates the caller asked for no scrape job with the requested id.
+	ErrScrapeJobNotFound = errors.New("scrape job not found")
+)
+
+// DefaultScrapeJobEventsInterval is how often watched scrape jobs are read when no interval is set.
+const DefaultScrapeJobEventsInterval = 2 * time.Second
+
+// scrapeJobWatchLimit bounds the jobs WatchRequested follows: the most recent requests of the user.
+const scrapeJobWatchLimit = 100
+
+// Scrape job event types: a status transition, or batches received by the job's ingest run.
+const (
+	ScrapeJobEventStatus   = "status"
+	ScrapeJobEventProgress = "progress"
+)
+
+// ScrapeJobEvent is a change of a watched scrape job, carrying the job as it now is.
+type ScrapeJobEvent struct {
+	Type string
+	Job  entity.ScrapeJob
+}
+
+// ScrapeJobService records scrapes sent to the worker and coalesces identical requests: a request
+// for the business type, city, country and minimum rating of a job still queued within the window
+// joins that job instead of scraping again.
+type ScrapeJobService struct {
+	repo           repository.ScrapeJobsRepository
+	window         time.Duration
+	eventsInterval time.Duration
+	// wakeups, when set, has watchers read their jobs as soon as an ingest run moves.
+	wakeups *events.Bus
+}
+
+// ScrapeJobServiceOption configures a ScrapeJobService.
+type ScrapeJobServiceOption func(*ScrapeJobService)
+
+// WithScrapeJobEventsInterval sets how often watched jobs are read for changes; zero or less keeps
+// DefaultScrapeJobEventsInterval.
+func WithScrapeJobEventsInterval(interval time.Duration) ScrapeJobServiceOption {
+	return func(s *ScrapeJobService) {
+		if interval > 0 {
+			s.eventsInterval = interval
+		}
+	}
+}
+
+// WithScrapeJobWakeups has watched jobs read again as soon as bus reports an ingest batch or a
+// finished run, rather than at the next events interval alone.
+func WithScrapeJobWakeups(bus *events.Bus) ScrapeJobServiceOption {
+	return func(s *ScrapeJobService) {
+		s.wakeups = bus
+	}
+}
+
+// NewScrapeJobService builds a ScrapeJobService; a window of zero or less records every request as
+// its own job.
+func NewScrapeJobService(repo repository.ScrapeJobsRepository, window time.Duration, opts ...ScrapeJobServiceOption) *ScrapeJobService {
+	s := &ScrapeJobService{repo: repo, window: window, eventsInterval: DefaultScrapeJobEventsInterval}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Claim returns the job a normalised scrape request belongs to and whether it joined a job already
+// queued, in which case the worker must not be called again.
+func (s *ScrapeJobService) Claim(ctx context.Context, req dto.ScrapeRequest, requesterID string) (*entity.ScrapeJob, bool, error) {
+	var requester *uuid.UUID
+	if raw := strings.TrimSpace(requesterID); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, false, ErrInvalidScrapeRequester
+		}
+		requester = &parsed
+	}
+	job := &entity.ScrapeJob{
+		ID:           uuid.New(),
+		Source:       scrapeSourceOrDefault(req.Source),
+		TypeBusiness: req.TypeBusiness,
+		City:         req.City,
+		Country:      req.Country,
+		MinRating:    req.MinRating,
+		Fingerprint:  scrapeFingerprint(req),
+	}
+	coalesced, err := s.repo.ClaimScrapeJob(ctx, job, requester, s.window)
+	if err != nil {
+		return nil, false, err
+	}
+	return job, coalesced, nil
+}
+
+// Fail records that the worker did not accept a job.
+func (s *ScrapeJobService) Fail(ctx context.Context, id uuid.UUID, reason string) error {
+	return s.repo.FailScrapeJob(ctx, id, reason)
+}
+
+// ListRequested returns a page of the jobs the user asked for, newest request first.
+func (s *ScrapeJobService) ListRequested(ctx context.Context, userID string, page, perPage int) ([]entity.ScrapeJob, error) {
+	id, err := uuid.Parse(strings.TrimSpace(userID))
+	if err != nil {
+		return nil, ErrInvalidScrapeRequester
+	}
+	limit, offset := pageBounds(page, perPage)
+	return s.repo.ListRequestedScrapeJobs(ctx, id, limit, offset)
+}
+
+// GetRequested returns a job the user asked for.
+func (s *ScrapeJobService) GetRequested(ctx context.Context, userID, jobID string) (*entity.ScrapeJob, error) {
+	user, id, err := parseScrapeJobIDs(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRequested(ctx, user, id)
+}
+
+// WatchJob follows a job the user asked for. The returned channel first receives the job as a
+// status event, then an event whenever its status moves or its ingest run receives batches; it is
+// closed once the job is finished or failed, or when ctx is done. The job is read from the
+// database, so changes reported to any API instance are seen.
+func (s *ScrapeJobService) WatchJob(ctx context.Context, userID, jobID string) (<-chan ScrapeJobEvent, error) {
+	user, id, err := parseScrapeJobIDs(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.getRequested(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ScrapeJobEvent, 1)
+	events <- ScrapeJobEvent{Type: ScrapeJobEventStatus, Job: *job}
+	if scrapeJobDone(*job) {
+		close(events)
+		return events, nil
+	}
+	go func() {
+		defer close(events)
+		last := *job
+		s.poll(ctx, func() bool {
+			current, err := s.getRequested(ctx, user, id)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("watch scrape job %s: %v", id, err)
+				}
+				return true
+			}
+			if kind := scrapeJobChange(&last, *current); kind != "" {
+				last = *current
+				return sendScrapeJobEvent(ctx, events, ScrapeJobEvent{Type: kind, Job: last}) && !scrapeJobDone(last)
+			}
+			return true
+		})
+	}()
+	return events, nil
+}
+
+// WatchRequested follows the latest jobs the user asked for, as WatchJob does one: it first sends
+// the queued and running ones, then every change, including jobs requested since. The channel is
+// closed when ctx is done.
+func (s *ScrapeJobService) WatchRequested(ctx context.Context, userID string) (<-chan ScrapeJobEvent, error) {
+	user, err := uuid.Parse(strings.TrimSpace(userID))
+	if err != nil {
+		return nil, ErrInvalidScrapeRequester
+	}
+	jobs, err := s.repo.ListRequestedScrapeJobs(ctx, user, scrapeJobWatchLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ScrapeJobEvent, len(jobs)+1)
+	known := make(map[uuid.UUID]entity.ScrapeJob, len(jobs))
+	for _, job := range jobs {
+		known[job.ID] = job
+		if !scrapeJobDone(job) {
+			events <- ScrapeJobEvent{Type: ScrapeJobEventStatus, Job: job}
+		}
+	}
+	go func() {
+		defer close(events)
+		s.poll(ctx, func() bool {
+			jobs, err := s.repo.ListRequestedScrapeJobs(ctx, user, scrapeJobWatchLimit, 0)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("watch scrape jobs of %s: %v", user, err)
+				}
+				return true
+			}
+			// Oldest first, so a dashboard applies the changes in the order they happened.
+			for i := len(jobs) - 1; i >= 0; i-- {
+				job := jobs[i]
+				var previous *entity.ScrapeJob
+				if last, ok := known[job.ID]; ok {
+					previous = &last
+				}
+				kind := scrapeJobChange(previous, job)
+				if kind == "" {
+					continue
+				}
+				known[job.ID] = job
+				if !sendScrapeJobEvent(ctx, events, ScrapeJobEvent{Type: kind, Job: job}) {
+					return false
+				}
+			}
+			return true
+		})
+	}()
+	return events, nil
+}
+
+// poll calls check every events interval, and on every ingest event with wakeups, until it
+// returns false or ctx is done.
+func (s *ScrapeJobService) poll(ctx context.Context, check func() bool) {
+	ticker := time.NewTicker(s.eventsInterval)
+	defer ticker.Stop()
+	var wake <-chan events.Event
+	if s.wakeups != nil {
+		sub := s.wakeups.Subscribe(events.TypeIngestBatchStored, events.TypeIngestRunFinished)
+		defer sub.Close()
+		wake = sub.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+		if !check() {
+			return
+		}
+	}
+}
+
+func (s *ScrapeJobService) getRequested(ctx context.Context, user, id uuid.UUID) (*entity.ScrapeJob, error) {
+	job, err := s.repo.GetRequestedScrapeJob(ctx, user, id)
+	if errors.Is(err, repository.ErrScrapeJobNotFound) {
+		return nil, ErrScrapeJobNotFound
+	}
+	return job, err
+}
+
+func parseScrapeJobIDs(userID, jobID string) (uuid.UUID, uuid.UUID, error) {
+	user, err := uuid.Parse(strings.TrimSpace(userID))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidScrapeRequester
+	}
+	id, err := uuid.Parse(strings.TrimSpace(jobID))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidScrapeJobID
+	}
+	return user, id, nil
+}
+
+// scrapeJobChange reports how current differs from previous: a status event for a new job or a
+// status transition, a progress event when the ingest run received batches, or "" when it did not
+// change.
+func scrapeJobChange(previous *entity.ScrapeJob, current entity.ScrapeJob) string {
+	switch {
+	case previous == nil || previous.Status != current.Status:
+		return ScrapeJobEventStatus
+	case scrapeJobCount(previous.Batches) != scrapeJobCount(current.Batches), scrapeJobCount(previous.Items) != scrapeJobCount(current.Items):
+		return ScrapeJobEventProgress
+	default:
+		return ""
+	}
+}
+
+func scrapeJobCount(count *int) int {
+	if count == nil {
+		return 0
+	}
+	return *count
+}
+
+func scrapeJobDone(job entity.ScrapeJob) bool {
+	return job.Status == entity.ScrapeJobFinished || job.Status == entity.ScrapeJobFailed
+}
+
+func sendScrapeJobEvent(ctx context.Context, events chan<- ScrapeJobEvent, event ScrapeJobEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// scrapeFingerprint identifies a scrape request regardless of case and spacing. Google Maps scrapes
+// leave the source out, so they keep the fingerprint they had before other sources existed.
+func scrapeFingerprint(req dto.ScrapeRequest) string {
+	fields := []string{req.TypeBusiness, req.City, req.Country}
+	for i, field := range fields {
+		fields[i] = strings.ToLower(strings.Join(strings.Fields(field), " "))
+	}
+	fingerprint := strings.Join(append(fields, strconv.FormatFloat(req.MinRating, 'f', -1, 64)), "|")
+	if source := scrapeSourceOrDefault(req.Source); source != entity.ScrapeSourceGoogleMaps {
+		fingerprint = source + "|" + fingerprint
+	}
+	return fingerprint
+}
+
+// scrapeSourceOrDefault returns the source of a normalised request, Google Maps when it names none.
+func scrapeSourceOrDefault(source string) string {
+	if source == "" {
+		return entity.ScrapeSourceGoogleMaps
+	}
+	return source
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubScrapeJobsRepo struct {
+	job       entity.ScrapeJob
+	requester *uuid.UUID
+	window    time.Duration
+	// reads are returned by successive GetRequestedScrapeJob or ListRequestedScrapeJobs calls, the
+	// last one repeating.
+	reads [][]entity.ScrapeJob
+}
+
+func (s *stubScrapeJobsRepo) read() []entity.ScrapeJob {
+	jobs := s.reads[0]
+	if len(s.reads) > 1 {
+		s.reads = s.reads[1:]
+	}
+	return jobs
+}
+
+func (s *stubScrapeJobsRepo) ClaimScrapeJob(ctx context.Context, job *entity.ScrapeJob, requester *uuid.UUID, window time.Duration) (bool, error) {
+	s.job, s.requester, s.window = *job, requester, window
+	return false, nil
+}
+
+func (s *stubScrapeJobsRepo) FailScrapeJob(ctx context.Context, id uuid.UUID, reason string) error {
+	return nil
+}
+
+func (s *stubScrapeJobsRepo) ListRequestedScrapeJobs(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.ScrapeJob, error) {
+	if len(s.reads) == 0 {
+		return nil, nil
+	}
+	return s.read(), nil
+}
+
+func (s *stubScrapeJobsRepo) GetRequestedScrapeJob(ctx context.Context, userID, id uuid.UUID) (*entity.ScrapeJob, error) {
+	for _, job := range s.read() {
+		if job.ID == id {
+			return &job, nil
+		}
+	}
+	return nil, repository.ErrScrapeJobNotFound
+}
+
+func TestScrapeJobService_Claim(t *testing.T) {
+	repo := &stubScrapeJobsRepo{}
+	svc := NewScrapeJobService(repo, 6*time.Hour)
+	userID := uuid.New()
+
+	job, coalesced, err := svc.Claim(context.Background(), dto.ScrapeRequest{TypeBusiness: "Car  Wash", City: "Jakarta", Country: "Indonesia", MinRating: 4.5}, userID.String())
+	if err != nil || coalesced {
+		t.Fatalf("unexpected claim %v, %v", coalesced, err)
+	}
+	if job.ID == uuid.Nil || repo.job.Fingerprint != "car wash|jakarta|indonesia|4.5" || repo.window != 6*time.Hour || repo.requester == nil || *repo.requester != userID {
+		t.Fatalf("unexpected job %+v for requester %v", repo.job, repo.requester)
+	}
+	if job.Source != entity.ScrapeSourceGoogleMaps {
+		t.Fatalf("expected the job to default to google maps, got %q", job.Source)
+	}
+	if got := scrapeFingerprint(dto.ScrapeRequest{Source: "osm", TypeBusiness: "Car Wash", City: "Jakarta", Country: "Indonesia"}); got != "osm|car wash|jakarta|indonesia|0" {
+		t.Fatalf("expected other sources to keep their scrapes apart, got %q", got)
+	}
+
+	if _, _, err := svc.Claim(context.Background(), dto.ScrapeRequest{TypeBusiness: "cafe"}, "nope"); !errors.Is(err, ErrInvalidScrapeRequester) {
+		t.Fatalf("expected ErrInvalidScrapeRequester, got %v", err)
+	}
+}
+
+func TestScrapeJobService_WatchJob(t *testing.T) {
+	id := uuid.New()
+	one, two, items := 1, 2, 40
+	queued := entity.ScrapeJob{ID: id, Status: entity.ScrapeJobQueued}
+	running := entity.ScrapeJob{ID: id, Status: entity.ScrapeJobRunning, Batches: &one, Items: &items}
+	progressed := running
+	progressed.Batches = &two
+	finished := progressed
+	finished.Status = entity.ScrapeJobFinished
+	repo := &stubScrapeJobsRepo{reads: [][]entity.ScrapeJob{{queued}, {queued}, {running}, {running}, {progressed}, {finished}}}
+	svc := NewScrapeJobService(repo, 0, WithScrapeJobEventsInterval(time.Millisecond))
+
+	events, err := svc.WatchJob(context.Background(), uuid.NewString(), id.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for event := range events {
+		got = append(got, event.Type+":"+event.Job.Status)
+	}
+	want := []string{"status:queued", "status:running", "progress:running", "status:finished"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+
+	if _, err := svc.WatchJob(context.Background(), uuid.NewString(), uuid.NewString()); !errors.Is(err, ErrScrapeJobNotFound) {
+		t.Fatalf("expected ErrScrapeJobNotFound, got %v", err)
+	}
+	if _, err := svc.WatchJob(context.Background(), uuid.NewString(), "nope"); !errors.Is(err, ErrInvalidScrapeJobID) {
+		t.Fatalf("expected ErrInvalidScrapeJobID, got %v", err)
+	}
+}
+
+func TestScrapeJobService_WatchJobWakesOnIngestEvents(t *testing.T) {
+	id := uuid.New()
+	queued := entity.ScrapeJob{ID: id, Status: entity.ScrapeJobQueued}
+	running := entity.ScrapeJob{ID: id, Status: entity.ScrapeJobRunning}
+	repo := &stubScrapeJobsRepo{reads: [][]entity.ScrapeJob{{queued}, {running}}}
+	bus := events.NewBus()
+	svc := NewScrapeJobService(repo, 0, WithScrapeJobEventsInterval(time.Hour), WithScrapeJobWakeups(bus))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := svc.WatchJob(ctx, uuid.NewString(), id.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-updates
+	// The watcher subscribes once it starts, so publish until it has read the job again.
+	deadline := time.After(time.Second)
+	for {
+		bus.Publish(ctx, events.IngestBatchStored{RunID: id, Items: 20})
+		select {
+		case event := <-updates:
+			if event.Job.Status != entity.ScrapeJobRunning {
+				t.Fatalf("expected the running job, got %+v", event)
+			}
+			return
+		case <-deadline:
+			t.Fatalf("expected an ingest event to wake the watcher before its interval")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestScrapeJobService_WatchRequested(t *testing.T) {
+	done := entity.ScrapeJob{ID: uuid.New(), Status: entity.ScrapeJobFinished}
+	queued := entity.ScrapeJob{ID: uuid.New(), Status: entity.ScrapeJobQueued}
+	failed := queued
+	failed.Status = entity.ScrapeJobFailed
+	added := entity.ScrapeJob{ID: uuid.New(), Status: entity.ScrapeJobQueued}
+	repo := &stubScrapeJobsRepo{reads: [][]entity.ScrapeJob{{queued, done}, {queued, done}, {added, failed, done}}}
+	svc := NewScrapeJobService(repo, 0, WithScrapeJobEventsInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := svc.WatchRequested(ctx, uuid.NewString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ScrapeJobEvent{
+		{Type: ScrapeJobEventStatus, Job: queued},
+		{Type: ScrapeJobEventStatus, Job: failed},
+		{Type: ScrapeJobEventStatus, Job: added},
+	}
+	for i, expected := range want {
+		event := <-events
+		if event.Type != expected.Type || event.Job.ID != expected.Job.ID || event.Job.Status != expected.Job.Status {
+			t.Fatalf("event %d: expected %+v, got %+v", i, expected, event)
+		}
+	}
+	cancel()
+	for range events {
+	}
+}
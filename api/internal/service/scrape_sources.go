@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// legacyGoogleMapsSource is the source workers predating scrape sources send with every place.
+const legacyGoogleMapsSource = "serpapi_google_maps"
+
+// SourceAdapter is a place provider scrapes can run against. The worker searches the provider and
+// streams the places it finds; the API only needs to know which key a place is stored under.
+type SourceAdapter interface {
+	// Name is the source named by scrape requests and ingested places.
+	Name() string
+	// DedupeKey returns the key a place of the source is stored under, so scraping it again updates
+	// the company instead of adding a duplicate, or an empty string when the place carries none.
+	// Sources other than Google Maps prefix their keys with their name, so the ids of different
+	// providers never collide.
+	DedupeKey(item dto.IngestItem) string
+}
+
+// scrapeSources holds the registered sources by name; a provider is wired in by adding its
+// adapter here and to the worker.
+var scrapeSources = map[string]SourceAdapter{
+	entity.ScrapeSourceGoogleMaps: googleMapsSource{},
+}
+
+// ErrInvalidScrapeSource is returned when a scrape or an ingested place names an unknown source.
+var ErrInvalidScrapeSource = errors.New("source must be one of " + strings.Join(ScrapeSourceNames(), ", "))
+
+// ResolveScrapeSource maps a raw source name to its adapter, defaulting to Google Maps.
+func ResolveScrapeSource(raw string) (SourceAdapter, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if name == "" || name == legacyGoogleMapsSource {
+		name = entity.ScrapeSourceGoogleMaps
+	}
+	adapter, ok := scrapeSources[name]
+	if !ok {
+		return nil, ErrInvalidScrapeSource
+	}
+	return adapter, nil
+}
+
+// ScrapeSourceNames lists the registered sources in alphabetical order.
+func ScrapeSourceNames() []string {
+	names := make([]string, 0, len(scrapeSources))
+	for name := range scrapeSources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// googleMapsSource is Google Maps, whose places are keyed by their bare Google place id as they
+// were before other sources existed.
+type googleMapsSource struct{}
+
+func (googleMapsSource) Name() string { return entity.ScrapeSourceGoogleMaps }
+
+// DedupeKey returns the place id of the item, taken from raw_snapshot.place_id when empty.
+func (googleMapsSource) DedupeKey(item dto.IngestItem) string {
+	if placeID := strings.TrimSpace(item.PlaceID); placeID != "" {
+		return placeID
+	}
+	if len(item.RawSnapshot) == 0 {
+		return ""
+	}
+	var snapshot struct {
+		PlaceID string `json:"place_id"`
+	}
+	if err := json.Unmarshal(item.RawSnapshot, &snapshot); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(snapshot.PlaceID)
+}
@@ -0,0 +1,99 @@
+package sizing
+
+import "strings"
+
+// Size buckets assigned to companies.
+const (
+	BucketMicro   = "micro"
+	BucketSmall   = "small"
+	BucketMedium  = "medium"
+	BucketLarge   = "large"
+	BucketUnknown = "unknown"
+)
+
+// Buckets lists every bucket accepted by filters, smallest first.
+var Buckets = []string{BucketMicro, BucketSmall, BucketMedium, BucketLarge, BucketUnknown}
+
+// Signals holds the raw indicators used to estimate business size; nil means not observed.
+type Signals struct {
+	Reviews          *int
+	Photos           *int
+	SocialFollowers  *int
+	EmployeeMentions *int
+}
+
+// Estimate is the outcome of a size estimation.
+type Estimate struct {
+	Bucket string `json:"bucket"`
+	// Signals counts how many indicators contributed to the estimate.
+	Signals int `json:"signals"`
+}
+
+// signal maps one indicator onto a 0-3 level using ascending thresholds; weight reflects how
+// strongly the indicator correlates with headcount.
+type signal struct {
+	value      *int
+	thresholds [3]int
+	weight     int
+}
+
+// EstimateSize combines the observed signals into a size bucket. Employee mentions weigh the most
+// because they state headcount directly; reviews come next, followers and photos least.
+func EstimateSize(input Signals) Estimate {
+	signals := []signal{
+		{value: input.EmployeeMentions, thresholds: [3]int{10, 50, 250}, weight: 3},
+		{value: input.Reviews, thresholds: [3]int{25, 150, 750}, weight: 2},
+		{value: input.SocialFollowers, thresholds: [3]int{1000, 10000, 100000}, weight: 1},
+		{value: input.Photos, thresholds: [3]int{10, 50, 200}, weight: 1},
+	}
+
+	var (
+		weighted    int
+		totalWeight int
+		observed    int
+	)
+	for _, s := range signals {
+		if s.value == nil || *s.value < 0 {
+			continue
+		}
+		weighted += level(*s.value, s.thresholds) * s.weight
+		totalWeight += s.weight
+		observed++
+	}
+	if observed == 0 {
+		return Estimate{Bucket: BucketUnknown}
+	}
+
+	// Compare weighted/totalWeight against 0.75, 1.75 and 2.75 using integers (x4).
+	avg4 := weighted * 4
+	switch {
+	case avg4 < 3*totalWeight:
+		return Estimate{Bucket: BucketMicro, Signals: observed}
+	case avg4 < 7*totalWeight:
+		return Estimate{Bucket: BucketSmall, Signals: observed}
+	case avg4 < 11*totalWeight:
+		return Estimate{Bucket: BucketMedium, Signals: observed}
+	default:
+		return Estimate{Bucket: BucketLarge, Signals: observed}
+	}
+}
+
+func level(value int, thresholds [3]int) int {
+	for i, threshold := range thresholds {
+		if value < threshold {
+			return i
+		}
+	}
+	return len(thresholds)
+}
+
+// NormalizeBucket lowercases a bucket name and reports whether it is known.
+func NormalizeBucket(raw string) (string, bool) {
+	bucket := strings.ToLower(strings.TrimSpace(raw))
+	for _, known := range Buckets {
+		if bucket == known {
+			return bucket, true
+		}
+	}
+	return "", false
+}
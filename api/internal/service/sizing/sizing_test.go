@@ -0,0 +1,38 @@
+package sizing
+
+import "testing"
+
+func intPtr(v int) *int { return &v }
+
+func TestEstimateSize(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   Signals
+		bucket  string
+		signals int
+	}{
+		{name: "no signals", input: Signals{}, bucket: BucketUnknown},
+		{name: "few reviews", input: Signals{Reviews: intPtr(8), Photos: intPtr(3)}, bucket: BucketMicro, signals: 2},
+		{name: "neighbourhood shop", input: Signals{Reviews: intPtr(90), Photos: intPtr(20)}, bucket: BucketSmall, signals: 2},
+		{name: "employees outweigh reviews", input: Signals{Reviews: intPtr(30), EmployeeMentions: intPtr(300)}, bucket: BucketMedium, signals: 2},
+		{name: "large brand", input: Signals{Reviews: intPtr(2400), SocialFollowers: intPtr(250000), EmployeeMentions: intPtr(900), Photos: intPtr(240)}, bucket: BucketLarge, signals: 4},
+		{name: "negative ignored", input: Signals{Reviews: intPtr(-1)}, bucket: BucketUnknown},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := EstimateSize(tc.input)
+			if got.Bucket != tc.bucket || got.Signals != tc.signals {
+				t.Fatalf("expected %s/%d, got %+v", tc.bucket, tc.signals, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeBucket(t *testing.T) {
+	if bucket, ok := NormalizeBucket(" Medium "); !ok || bucket != BucketMedium {
+		t.Fatalf("expected medium, got %q %v", bucket, ok)
+	}
+	if _, ok := NormalizeBucket("huge"); ok {
+		t.Fatalf("expected unknown bucket name to be rejected")
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// canonicalSocialHosts is the host canonical profile URLs use when a link came from a mobile or
+// localised subdomain, or from a host the platform has moved away from.
+var canonicalSocialHosts = map[string]string{
+	"linkedin":  "www.linkedin.com",
+	"facebook":  "www.facebook.com",
+	"instagram": "www.instagram.com",
+	"youtube":   "www.youtube.com",
+	"tiktok":    "www.tiktok.com",
+	"twitter":   "x.com",
+	"whatsapp":  "wa.me",
+}
+
+// localeLabel matches locale subdomains such as "id", "en-gb" or "es-la".
+var localeLabel = regexp.MustCompile(`^[a-z]{2}(-[a-z]{2})?$`)
+
+// localePathPrefix matches locale path prefixes such as "en-us" or "pt_BR". Bare language codes are
+// not stripped from paths because they collide with names such as facebook.com/pg.
+var localePathPrefix = regexp.MustCompile(`^[a-zA-Z]{2}[-_][a-zA-Z]{2}$`)
+
+// localeQueryParams only switch the interface language of a page.
+var localeQueryParams = []string{"hl", "lang", "locale"}
+
+// mobileSubdomains are host labels of mobile sites serving the same profiles.
+var mobileSubdomains = map[string]bool{"m": true, "mobile": true, "touch": true, "mbasic": true}
+
+// reservedSocialPaths are first path segments that are features rather than profile names.
+var reservedSocialPaths = map[string]map[string]bool{
+	"facebook":  toSet("events", "groups", "hashtag", "permalink.php", "photo", "photo.php", "photos", "reel", "share", "sharer", "sharer.php", "story.php", "watch"),
+	"instagram": toSet("explore", "p", "reel", "reels", "stories", "tv"),
+	"twitter":   toSet("explore", "hashtag", "home", "i", "intent", "search", "share"),
+}
+
+// canonicalizeSocialURL rewrites u, a link on platform, into the platform's canonical form: mobile
+// and locale subdomains, locale path prefixes and params, fragments and trailing slashes are
+// dropped. It returns the profile handle, or an empty string when the link is
+// not a profile (a post, video or share link).
+func canonicalizeSocialURL(platform string, u *url.URL) string {
+	u.Host = canonicalSocialHost(platform, strings.ToLower(strings.TrimSuffix(u.Host, ".")))
+	u.Fragment = ""
+	u.RawFragment = ""
+	query := u.Query()
+	for _, key := range localeQueryParams {
+		query.Del(key)
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	if len(segments) > 1 && localePathPrefix.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+
+	var handle string
+	switch platform {
+	case "whatsapp":
+		// api.whatsapp.com/send?phone=... becomes wa.me/<number>.
+		if len(segments) <= 1 && query.Get("phone") != "" {
+			segments = []string{digitsOnly(query.Get("phone"))}
+		}
+		// Any prefilled message is dropped with the rest of the query.
+		query = url.Values{}
+		if len(segments) == 1 && segments[0] != "" && digitsOnly(segments[0]) == segments[0] {
+			handle = "+" + segments[0]
+		}
+	case "facebook":
+		switch {
+		case len(segments) == 1 && segments[0] == "profile.php":
+			handle = query.Get("id")
+		case len(segments) > 1 && segments[0] == "pg":
+			handle = segments[1]
+		case len(segments) > 2 && segments[0] == "pages":
+			// pages/<name>/<id> links name the page by id.
+			handle = segments[2]
+		case len(segments) > 0 && segments[0] != "pages" && !reservedSocialPaths[platform][segments[0]]:
+			handle = segments[0]
+		}
+	default:
+		handle = socialHandleFromPath(platform, u.Host, segments)
+	}
+
+	u.RawQuery = query.Encode()
+	u.Path = ""
+	if len(segments) > 0 {
+		u.Path = "/" + strings.Join(segments, "/")
+	}
+	u.RawPath = ""
+	// YouTube channel ids are case sensitive; every other handle is not.
+	if platform != "youtube" || !strings.HasPrefix(handle, "UC") {
+		handle = strings.ToLower(handle)
+	}
+	return handle
+}
+
+// canonicalSocialHost drops mobile and locale subdomains, e.g. m.facebook.com or id.linkedin.com,
+// and moves twitter.com links to x.com.
+func canonicalSocialHost(platform, host string) string {
+	if platform == "twitter" || platform == "whatsapp" {
+		if label, _, ok := strings.Cut(host, "."); ok && label != "chat" {
+			return canonicalSocialHosts[platform]
+		}
+		return host
+	}
+	label, rest, ok := strings.Cut(host, ".")
+	if !ok || !strings.Contains(rest, ".") {
+		return host
+	}
+	if mobileSubdomains[label] || localeLabel.MatchString(label) {
+		return canonicalSocialHosts[platform]
+	}
+	return host
+}
+
+func socialHandleFromPath(platform, host string, segments []string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+	first := segments[0]
+	switch platform {
+	case "linkedin":
+		if len(segments) > 1 && (first == "in" || first == "company" || first == "school" || first == "showcase") {
+			return segments[1]
+		}
+	case "youtube":
+		if host == "youtu.be" {
+			return ""
+		}
+		if strings.HasPrefix(first, "@") {
+			return strings.TrimPrefix(first, "@")
+		}
+		if len(segments) > 1 && (first == "c" || first == "user" || first == "channel") {
+			return segments[1]
+		}
+	case "tiktok":
+		if strings.HasPrefix(first, "@") {
+			return strings.TrimPrefix(first, "@")
+		}
+	case "instagram", "twitter":
+		if !reservedSocialPaths[platform][first] {
+			return strings.TrimPrefix(first, "@")
+		}
+	}
+	return ""
+}
+
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// socialHandle returns the profile handle of a stored link on platform, or an empty string when the
+// link is not a profile on an allowed domain of the platform.
+func socialHandle(platform, link string) string {
+	u, err := sanitizeURL(link)
+	if err != nil {
+		return ""
+	}
+	if hostPlatform, ok := hostMatchesAllowed(u.Hostname()); !ok || hostPlatform != platform {
+		return ""
+	}
+	return canonicalizeSocialURL(platform, u)
+}
+
+// socialHandles extracts the profile handles of the links, keyed by platform; nil when no link is a
+// profile.
+func socialHandles(socials map[string][]string) map[string][]string {
+	handles := make(map[string][]string)
+	for key, links := range socials {
+		platform := canonicalSocialKey(key)
+		if platform == "" {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, link := range links {
+			if handle := socialHandle(platform, link); handle != "" && !seen[handle] {
+				seen[handle] = true
+				handles[platform] = append(handles[platform], handle)
+			}
+		}
+	}
+	if len(handles) == 0 {
+		return nil
+	}
+	return handles
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+func TestCanonicalizeSocialURL(t *testing.T) {
+	cases := []struct {
+		platform, raw, url, handle string
+	}{
+		{"facebook", "https://m.facebook.com/AcmeCoffee/?ref=page_internal#about", "https://www.facebook.com/AcmeCoffee?ref=page_internal", "acmecoffee"},
+		{"facebook", "https://id-id.facebook.com/profile.php?id=1000123&hl=id", "https://www.facebook.com/profile.php?id=1000123", "1000123"},
+		{"facebook", "https://www.facebook.com/pages/Acme-Coffee/123456789/", "https://www.facebook.com/pages/Acme-Coffee/123456789", "123456789"},
+		{"facebook", "https://facebook.com/sharer/sharer.php?u=acme", "https://facebook.com/sharer/sharer.php?u=acme", ""},
+		{"linkedin", "https://id.linkedin.com/company/acme-indonesia/", "https://www.linkedin.com/company/acme-indonesia", "acme-indonesia"},
+		{"linkedin", "https://www.linkedin.com/in/Budi-Santoso?locale=en_US", "https://www.linkedin.com/in/Budi-Santoso", "budi-santoso"},
+		{"instagram", "https://www.instagram.com/acme.coffee/?hl=en", "https://www.instagram.com/acme.coffee", "acme.coffee"},
+		{"instagram", "https://www.instagram.com/p/Cx12AbC/", "https://www.instagram.com/p/Cx12AbC", ""},
+		{"youtube", "https://m.youtube.com/@AcmeCoffee/videos", "https://www.youtube.com/@AcmeCoffee/videos", "acmecoffee"},
+		{"youtube", "https://www.youtube.com/channel/UCabcDEF123", "https://www.youtube.com/channel/UCabcDEF123", "UCabcDEF123"},
+		{"youtube", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ""},
+		{"tiktok", "https://www.tiktok.com/en-US/@acme.coffee?lang=en", "https://www.tiktok.com/@acme.coffee", "acme.coffee"},
+		{"twitter", "https://mobile.twitter.com/AcmeCoffee/", "https://x.com/AcmeCoffee", "acmecoffee"},
+		{"twitter", "https://x.com/intent/tweet?text=hi", "https://x.com/intent/tweet?text=hi", ""},
+		{"whatsapp", "https://api.whatsapp.com/send?phone=+62 812-3456-7890&text=Halo", "https://wa.me/6281234567890", "+6281234567890"},
+		{"whatsapp", "https://wa.me/6281234567890/", "https://wa.me/6281234567890", "+6281234567890"},
+		{"whatsapp", "https://wa.me/message/ABCDEF123", "https://wa.me/message/ABCDEF123", ""},
+	}
+	for _, tc := range cases {
+		u, err := sanitizeURL(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.raw, err)
+		}
+		handle := canonicalizeSocialURL(tc.platform, u)
+		if u.String() != tc.url || handle != tc.handle {
+			t.Fatalf("%s: got %s (%q), want %s (%q)", tc.raw, u, handle, tc.url, tc.handle)
+		}
+	}
+}
+
+func TestHostMatchesAllowed_TwitterAndWhatsApp(t *testing.T) {
+	for host, want := range map[string]string{"x.com": "twitter", "twitter.com": "twitter", "wa.me": "whatsapp", "api.whatsapp.com": "whatsapp", "box.com": ""} {
+		if got, _ := hostMatchesAllowed(host); got != want {
+			t.Fatalf("hostMatchesAllowed(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestValidateSocialsStoresCanonicalURLAndHandle(t *testing.T) {
+	httpClient := &stubHTTPClient{
+		responses: map[string]int{
+			"HEAD https://x.com/AcmeCoffee":         http.StatusOK,
+			"HEAD https://wa.me/6281234567890":      http.StatusOK,
+			"HEAD https://www.facebook.com/acme.id": http.StatusOK,
+		},
+	}
+	p := NewDataProcessor("ID", WithDNSResolver(&stubDNSResolver{}), WithHTTPClient(httpClient))
+
+	result := p.validateSocials(context.Background(), map[string][]string{
+		"twitter":  {"https://twitter.com/AcmeCoffee/"},
+		"whatsapp": {"https://api.whatsapp.com/send?phone=6281234567890"},
+		"facebook": {"https://m.facebook.com/acme.id/"},
+	})
+
+	if result.Twitter != "https://x.com/AcmeCoffee" || result.WhatsApp != "https://wa.me/6281234567890" || result.Facebook != "https://www.facebook.com/acme.id" {
+		t.Fatalf("unexpected canonical links: %+v", result)
+	}
+	want := map[string]string{"twitter": "acmecoffee", "whatsapp": "+6281234567890", "facebook": "acme.id"}
+	if !reflect.DeepEqual(result.Handles, want) {
+		t.Fatalf("unexpected handles: %v", result.Handles)
+	}
+}
+
+func TestCompaniesService_SaveEnrichment_StoresSocialHandles(t *testing.T) {
+	var (
+		stored  *entity.CompanyEnrichment
+		contact *entity.WebsiteEnrichedContact
+	)
+	repo := &mockCompaniesRepository{
+		enrich: func(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+			stored = enrichment
+			return nil
+		},
+		upsertContacts: func(ctx context.Context, c *entity.WebsiteEnrichedContact) error {
+			contact = c
+			return nil
+		},
+	}
+	svc := NewCompaniesService(repo)
+
+	err := svc.SaveEnrichment(context.Background(), dto.EnrichResultRequest{
+		CompanyID: uuid.NewString(),
+		Socials: map[string][]string{
+			"instagram": {"https://www.instagram.com/acme.coffee", "https://www.instagram.com/p/Cx12AbC"},
+			"x":         {"https://x.com/AcmeCoffee"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string][]string{"instagram": {"acme.coffee"}, "twitter": {"acmecoffee"}}
+	if got := stored.Metadata["social_handles"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected enrichment handles: %v", got)
+	}
+	if contact.InstagramURL == nil || contact.SocialHandles["instagram"] != "acme.coffee" {
+		t.Fatalf("unexpected contact: %+v", contact)
+	}
+}
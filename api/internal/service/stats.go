@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/querycache"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// DefaultStatsCacheTTL is how long computed dashboard stats are served from memory.
+	DefaultStatsCacheTTL = time.Minute
+	defaultStatsTop      = 10
+	maxStatsTop          = 50
+	defaultStatsRuns     = 20
+	maxStatsRuns         = 100
+)
+
+// StatsService serves dashboard aggregates, caching results briefly because every call scans companies.
+type StatsService struct {
+	repo repository.StatsRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*entity.CatalogueStats
+	// queryCache replaces the in-memory cache, sharing its invalidation with company listings.
+	queryCache *querycache.Cache
+}
+
+// StatsServiceOption customises optional StatsService collaborators.
+type StatsServiceOption func(*StatsService)
+
+// WithStatsQueryCache caches stats in cache instead of process memory, so they are dropped as soon
+// as companies change rather than after the stats ttl.
+func WithStatsQueryCache(cache *querycache.Cache) StatsServiceOption {
+	return func(s *StatsService) {
+		s.queryCache = cache
+	}
+}
+
+// NewStatsService builds a new StatsService; a ttl of zero disables caching.
+func NewStatsService(repo repository.StatsRepository, ttl time.Duration, opts ...StatsServiceOption) *StatsService {
+	svc := &StatsService{
+		repo:  repo,
+		ttl:   ttl,
+		now:   time.Now,
+		cache: make(map[string]*entity.CatalogueStats),
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+// CatalogueStats returns catalogue totals with the top cities/business types and the latest scrape runs.
+func (s *StatsService) CatalogueStats(ctx context.Context, top, runs int) (*entity.CatalogueStats, error) {
+	top = clampLimit(top, defaultStatsTop, maxStatsTop)
+	runs = clampLimit(runs, defaultStatsRuns, maxStatsRuns)
+	key := fmt.Sprintf("%d:%d", top, runs)
+
+	if s.queryCache != nil {
+		return querycache.Fetch(ctx, s.queryCache, "stats:"+key, func(ctx context.Context) (*entity.CatalogueStats, error) {
+			return s.load(ctx, top, runs)
+		})
+	}
+	if cached := s.cached(key); cached != nil {
+		return cached, nil
+	}
+
+	stats, err := s.load(ctx, top, runs)
+	if err != nil {
+		return nil, err
+	}
+	if s.ttl > 0 {
+		s.mu.Lock()
+		s.cache[key] = stats
+		s.mu.Unlock()
+	}
+	return stats, nil
+}
+
+func (s *StatsService) load(ctx context.Context, top, runs int) (*entity.CatalogueStats, error) {
+	stats, err := s.repo.CatalogueTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TopCities, err = s.repo.TopCities(ctx, top); err != nil {
+		return nil, err
+	}
+	if stats.TopBusinessTypes, err = s.repo.TopBusinessTypes(ctx, top); err != nil {
+		return nil, err
+	}
+	if stats.ScrapeRuns, err = s.repo.ScrapeRunTotals(ctx, runs); err != nil {
+		return nil, err
+	}
+	stats.GeneratedAt = s.now().UTC()
+	return stats, nil
+}
+
+func (s *StatsService) cached(key string) *entity.CatalogueStats {
+	if s.ttl <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.cache[key]
+	if !ok || s.now().Sub(stats.GeneratedAt) >= s.ttl {
+		return nil
+	}
+	return stats
+}
+
+func clampLimit(value, fallback, max int) int {
+	if value <= 0 {
+		return fallback
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
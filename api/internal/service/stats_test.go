@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/querycache"
+)
+
+type mockStatsRepository struct {
+	calls    int
+	topLimit int
+	runLimit int
+}
+
+func (m *mockStatsRepository) CatalogueTotals(ctx context.Context) (*entity.CatalogueStats, error) {
+	m.calls++
+	return &entity.CatalogueStats{Companies: 10, Enriched: 4, WithoutWebsite: 3}, nil
+}
+
+func (m *mockStatsRepository) TopCities(ctx context.Context, limit int) ([]entity.StatBucket, error) {
+	m.topLimit = limit
+	return []entity.StatBucket{{Name: "Jakarta", Companies: 7}}, nil
+}
+
+func (m *mockStatsRepository) TopBusinessTypes(ctx context.Context, limit int) ([]entity.StatBucket, error) {
+	return []entity.StatBucket{{Name: "cafe", Companies: 5}}, nil
+}
+
+func (m *mockStatsRepository) ScrapeRunTotals(ctx context.Context, limit int) ([]entity.ScrapeRunStats, error) {
+	m.runLimit = limit
+	return []entity.ScrapeRunStats{{ScrapeRunID: uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"), Companies: 10}}, nil
+}
+
+func TestStatsService_CatalogueStats(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockStatsRepository{}
+	svc := NewStatsService(repo, time.Minute)
+	svc.now = func() time.Time { return now }
+
+	stats, err := svc.CatalogueStats(context.Background(), 0, 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Companies != 10 || len(stats.TopCities) != 1 || len(stats.TopBusinessTypes) != 1 || len(stats.ScrapeRuns) != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if repo.topLimit != defaultStatsTop || repo.runLimit != maxStatsRuns {
+		t.Fatalf("expected limits clamped, got top=%d runs=%d", repo.topLimit, repo.runLimit)
+	}
+
+	if _, err := svc.CatalogueStats(context.Background(), 0, 500); err != nil || repo.calls != 1 {
+		t.Fatalf("expected cached stats, got %d repository calls (%v)", repo.calls, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := svc.CatalogueStats(context.Background(), 0, 500); err != nil || repo.calls != 2 {
+		t.Fatalf("expected expired cache to recompute, got %d repository calls (%v)", repo.calls, err)
+	}
+}
+
+func TestStatsService_CatalogueStats_NoCache(t *testing.T) {
+	repo := &mockStatsRepository{}
+	svc := NewStatsService(repo, 0)
+	for i := 0; i < 2; i++ {
+		if _, err := svc.CatalogueStats(context.Background(), 5, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if repo.calls != 2 {
+		t.Fatalf("expected every call to hit the repository, got %d", repo.calls)
+	}
+}
+
+func TestStatsService_CatalogueStats_QueryCache(t *testing.T) {
+	repo := &mockStatsRepository{}
+	cache := querycache.New(querycache.NewMemoryStore(10), querycache.StoreMemory, time.Hour)
+	svc := NewStatsService(repo, time.Minute, WithStatsQueryCache(cache))
+
+	for i := 0; i < 2; i++ {
+		if stats, err := svc.CatalogueStats(context.Background(), 5, 5); err != nil || stats.Companies != 10 || len(stats.TopCities) != 1 {
+			t.Fatalf("unexpected stats %+v (%v)", stats, err)
+		}
+	}
+	if repo.calls != 1 {
+		t.Fatalf("expected cached stats, got %d repository calls", repo.calls)
+	}
+
+	cache.Invalidate(context.Background())
+	if _, err := svc.CatalogueStats(context.Background(), 5, 5); err != nil || repo.calls != 2 {
+		t.Fatalf("expected invalidated stats to recompute, got %d repository calls (%v)", repo.calls, err)
+	}
+}
@@ -0,0 +1,70 @@
+package techstack
+
+import (
+	"slices"
+	"strings"
+)
+
+// Technologies lists the website technologies enrichment detects, as they are stored. The worker
+// fingerprints the same names in src/core/site_enricher.py.
+var Technologies = []string{
+	"wordpress", "woocommerce", "elementor", "wix", "shopify", "squarespace", "webflow", "weebly",
+	"joomla", "drupal", "magento", "prestashop", "opencart", "blogger", "ghost",
+	"hubspot", "google-analytics", "google-tag-manager", "facebook-pixel", "cloudflare",
+}
+
+// None is the filter value matching websites enrichment analysed without detecting any technology.
+const None = "none"
+
+// aliases maps other spellings to the stored names.
+var aliases = map[string]string{
+	"wp":             "wordpress",
+	"woo":            "woocommerce",
+	"ga":             "google-analytics",
+	"ga4":            "google-analytics",
+	"gtm":            "google-tag-manager",
+	"meta-pixel":     "facebook-pixel",
+	"adobe-commerce": "magento",
+	"blogspot":       "blogger",
+}
+
+// Normalize returns the stored name of a technology, or an empty string when it is not one of
+// Technologies. Case, spaces and underscores do not matter, so "Google Tag Manager" is
+// google-tag-manager.
+func Normalize(raw string) string {
+	name := strings.Join(strings.FieldsFunc(strings.ToLower(strings.TrimSpace(raw)), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "-")
+	if alias, ok := aliases[name]; ok {
+		return alias
+	}
+	if slices.Contains(Technologies, name) {
+		return name
+	}
+	return ""
+}
+
+// NormalizeFilter validates a tech filter value, accepting None for websites without any.
+func NormalizeFilter(raw string) (string, bool) {
+	if strings.EqualFold(strings.TrimSpace(raw), None) {
+		return None, true
+	}
+	name := Normalize(raw)
+	return name, name != ""
+}
+
+// NormalizeList returns the known technologies of a detection result, sorted and without
+// duplicates; unknown names are dropped. A nil list stays nil, as the website was not analysed.
+func NormalizeList(raw []string) []string {
+	if raw == nil {
+		return nil
+	}
+	names := make([]string, 0, len(raw))
+	for _, value := range raw {
+		if name := Normalize(value); name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
@@ -0,0 +1,51 @@
+package techstack
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"WordPress":          "wordpress",
+		" wp ":               "wordpress",
+		"Google Tag Manager": "google-tag-manager",
+		"google_analytics":   "google-analytics",
+		"GA4":                "google-analytics",
+		"meta pixel":         "facebook-pixel",
+		"Adobe Commerce":     "magento",
+		"blogspot":           "blogger",
+		"react":              "",
+		"":                   "",
+	}
+	for raw, want := range cases {
+		if got := Normalize(raw); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestNormalizeFilter(t *testing.T) {
+	if got, ok := NormalizeFilter(" NONE "); !ok || got != None {
+		t.Fatalf("expected none, got %q %v", got, ok)
+	}
+	if got, ok := NormalizeFilter("Shopify"); !ok || got != "shopify" {
+		t.Fatalf("expected shopify, got %q %v", got, ok)
+	}
+	if _, ok := NormalizeFilter("frontpage"); ok {
+		t.Fatalf("expected an unknown technology to be rejected")
+	}
+}
+
+func TestNormalizeList(t *testing.T) {
+	if got := NormalizeList(nil); got != nil {
+		t.Fatalf("expected nil for a website not analysed, got %v", got)
+	}
+	if got := NormalizeList([]string{"react"}); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty list, got %v", got)
+	}
+	got := NormalizeList([]string{"WordPress", "gtm", "wp", "jquery", "Cloudflare"})
+	if want := []string{"cloudflare", "google-tag-manager", "wordpress"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// DefaultTrendingWindowDays is how recent the latest observation of a trending company must be
+	// when no window is given.
+	DefaultTrendingWindowDays = 30
+	// MaxTrendingWindowDays caps the window of trending companies.
+	MaxTrendingWindowDays = 365
+	// defaultTrendingRatingGain is the least rating growth of companies ranked by rating.
+	defaultTrendingRatingGain = 0.1
+)
+
+var (
+	// ErrTrendingUnavailable is returned when trending companies are requested without a repository.
+	ErrTrendingUnavailable = errors.New("trending companies unavailable")
+	// ErrInvalidTrendingWindow is returned when the window exceeds MaxTrendingWindowDays.
+	ErrInvalidTrendingWindow = errors.New("window_days must be between 1 and 365")
+	// ErrInvalidTrendingRanking is returned for rankings other than reviews or rating.
+	ErrInvalidTrendingRanking = errors.New("invalid by (use reviews or rating)")
+)
+
+// WithTrending enables GET /companies/trending over the snapshots recorded per scrape run.
+func WithTrending(trending repository.TrendingCompaniesRepository) CompaniesServiceOption {
+	return func(s *CompaniesService) {
+		s.trending = trending
+	}
+}
+
+// TrendingCompanies returns the companies matching filter that grew the most between their last
+// two observations, ranked by review or rating growth. Only growth counts: the ranked metric must
+// have grown by at least one review or 0.1 stars unless a minimum is given.
+func (s *CompaniesService) TrendingCompanies(ctx context.Context, filter dto.TrendingFilter) ([]entity.TrendingCompany, error) {
+	if s.trending == nil {
+		return nil, ErrTrendingUnavailable
+	}
+	filter.ListFilter = s.withCategoryTaxonomy(normalizeListFilter(filter.ListFilter))
+
+	if filter.WindowDays <= 0 {
+		filter.WindowDays = DefaultTrendingWindowDays
+	}
+	if filter.WindowDays > MaxTrendingWindowDays {
+		return nil, ErrInvalidTrendingWindow
+	}
+	filter.Since = s.now().AddDate(0, 0, -filter.WindowDays)
+
+	filter.By = strings.ToLower(strings.TrimSpace(filter.By))
+	switch filter.By {
+	case "", repository.TrendingByReviews:
+		filter.By = repository.TrendingByReviews
+		if filter.MinReviewGain == nil {
+			gain := 1
+			filter.MinReviewGain = &gain
+		}
+	case repository.TrendingByRating:
+		if filter.MinRatingGain == nil {
+			gain := defaultTrendingRatingGain
+			filter.MinRatingGain = &gain
+		}
+	default:
+		return nil, ErrInvalidTrendingRanking
+	}
+	return s.trending.ListTrendingCompanies(ctx, filter)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type stubTrendingRepo struct {
+	filter dto.TrendingFilter
+}
+
+func (s *stubTrendingRepo) ListTrendingCompanies(ctx context.Context, filter dto.TrendingFilter) ([]entity.TrendingCompany, error) {
+	s.filter = filter
+	return nil, nil
+}
+
+func TestCompaniesService_TrendingCompanies(t *testing.T) {
+	repo := &stubTrendingRepo{}
+	svc := NewCompaniesService(nil, WithTrending(repo))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := svc.TrendingCompanies(ctx, dto.TrendingFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := repo.filter
+	if got.By != repository.TrendingByReviews || got.MinReviewGain == nil || *got.MinReviewGain != 1 || got.MinRatingGain != nil {
+		t.Fatalf("expected growing reviews by default, got %+v", got)
+	}
+	if !got.Since.Equal(now.AddDate(0, 0, -DefaultTrendingWindowDays)) || got.PerPage != 20 {
+		t.Fatalf("unexpected defaults %+v", got)
+	}
+
+	if _, err := svc.TrendingCompanies(ctx, dto.TrendingFilter{By: "Rating", WindowDays: 7}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = repo.filter
+	if got.MinReviewGain != nil || got.MinRatingGain == nil || *got.MinRatingGain != 0.1 || !got.Since.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("expected growing ratings over 7 days, got %+v", got)
+	}
+
+	if _, err := svc.TrendingCompanies(ctx, dto.TrendingFilter{WindowDays: 400}); !errors.Is(err, ErrInvalidTrendingWindow) {
+		t.Fatalf("expected ErrInvalidTrendingWindow, got %v", err)
+	}
+	if _, err := svc.TrendingCompanies(ctx, dto.TrendingFilter{By: "stars"}); !errors.Is(err, ErrInvalidTrendingRanking) {
+		t.Fatalf("expected ErrInvalidTrendingRanking, got %v", err)
+	}
+	if _, err := NewCompaniesService(nil).TrendingCompanies(ctx, dto.TrendingFilter{}); !errors.Is(err, ErrTrendingUnavailable) {
+		t.Fatalf("expected ErrTrendingUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+const (
+	// DefaultUploadRetention is how long retained CSV uploads are kept when no retention is configured.
+	DefaultUploadRetention = 90 * 24 * time.Hour
+	// CSVContentType is the media type used for retained CSV uploads.
+	CSVContentType    = "text/csv"
+	uploadPurgeBatch  = 100
+	unknownUploadedBy = "unknown"
+)
+
+var (
+	// ErrInvalidUploadID is returned when an upload identifier cannot be parsed as UUID.
+	ErrInvalidUploadID = errors.New("invalid upload id")
+	// ErrUploadNotFound indicates the upload record or its retained file no longer exists.
+	ErrUploadNotFound = errors.New("upload not found")
+)
+
+// UploadStore keeps retained upload files.
+type UploadStore interface {
+	ObjectStore
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// UploadArchiveService keeps a copy of every admin CSV upload next to the outcome of its import, so
+// disputed imports can be inspected until the retention period ends.
+type UploadArchiveService struct {
+	repo      repository.CSVUploadsRepository
+	store     UploadStore
+	prefix    string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewUploadArchiveService builds an UploadArchiveService; a non-positive retention falls back to
+// DefaultUploadRetention.
+func NewUploadArchiveService(repo repository.CSVUploadsRepository, store UploadStore, prefix string, retention time.Duration) *UploadArchiveService {
+	if retention <= 0 {
+		retention = DefaultUploadRetention
+	}
+	return &UploadArchiveService{
+		repo:      repo,
+		store:     store,
+		prefix:    strings.Trim(prefix, "/"),
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Archive hashes and stores file under <prefix>/<uploader>/<upload id>.csv (.xlsx for workbooks)
+// and records a pending upload. file is rewound so the caller can import it afterwards.
+func (s *UploadArchiveService) Archive(ctx context.Context, uploadedBy, filename string, mode repository.ImportMode, file io.ReadSeeker) (*entity.CSVUpload, error) {
+	head := make([]byte, len(zipMagic))
+	n, _ := io.ReadFull(file, head)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewind upload: %w", err)
+	}
+	extension, contentType := ".csv", CSVContentType
+	if isXLSX(head[:n]) {
+		extension, contentType = ".xlsx", XLSXContentType
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, fmt.Errorf("hash upload: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewind upload: %w", err)
+	}
+
+	now := s.now().UTC()
+	upload := &entity.CSVUpload{
+		ID:        uuid.New(),
+		Filename:  path.Base(strings.ReplaceAll(filename, "\\", "/")),
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+		SizeBytes: size,
+		Mode:      string(mode),
+		Status:    entity.CSVUploadStatusPending,
+		ExpiresAt: now.Add(s.retention),
+	}
+	// There is no organisation model yet, so uploads are scoped by the admin who sent them.
+	scope := unknownUploadedBy
+	if parsed, err := uuid.Parse(uploadedBy); err == nil {
+		upload.UploadedBy = &parsed
+		scope = parsed.String()
+	}
+	upload.StorageKey = path.Join(s.prefix, scope, upload.ID.String()+extension)
+
+	if err := s.store.Upload(ctx, upload.StorageKey, contentType, file); err != nil {
+		return nil, fmt.Errorf("store upload: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewind upload: %w", err)
+	}
+	if err := s.repo.Create(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// UploadContentType returns the media type of a retained upload file.
+func UploadContentType(upload *entity.CSVUpload) string {
+	if path.Ext(upload.StorageKey) == ".xlsx" {
+		return XLSXContentType
+	}
+	return CSVContentType
+}
+
+// Complete records the outcome of importing an archived upload; importErr marks it failed.
+func (s *UploadArchiveService) Complete(ctx context.Context, upload *entity.CSVUpload, summary UploadSummary, importErr error) error {
+	completed := s.now().UTC()
+	upload.CompletedAt = &completed
+	upload.Inserted = summary.Inserted
+	upload.Updated = summary.Updated
+	upload.Skipped = summary.Skipped
+	upload.Total = summary.Total
+	upload.Status = entity.CSVUploadStatusCompleted
+	if importErr != nil {
+		message := importErr.Error()
+		upload.Status = entity.CSVUploadStatusFailed
+		upload.Error = &message
+	}
+	return s.repo.Complete(ctx, upload)
+}
+
+// List returns retained uploads, newest first.
+func (s *UploadArchiveService) List(ctx context.Context, limit, offset int) ([]entity.CSVUpload, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+// Get returns a retained upload record.
+func (s *UploadArchiveService) Get(ctx context.Context, id string) (*entity.CSVUpload, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return nil, ErrInvalidUploadID
+	}
+	upload, err := s.repo.Get(ctx, parsed)
+	if err != nil {
+		if errors.Is(err, repository.ErrCSVUploadNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Open returns an upload record together with its retained file; callers must close the reader.
+func (s *UploadArchiveService) Open(ctx context.Context, id string) (*entity.CSVUpload, io.ReadCloser, error) {
+	upload, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.store.Open(ctx, upload.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, ErrUploadNotFound
+		}
+		return nil, nil, err
+	}
+	return upload, file, nil
+}
+
+// PurgeExpired deletes uploads whose retention has ended, file first so a failure leaves the record
+// to retry on the next run. It returns how many uploads were removed.
+func (s *UploadArchiveService) PurgeExpired(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		expired, err := s.repo.ListExpired(ctx, s.now().UTC(), uploadPurgeBatch)
+		if err != nil {
+			return purged, err
+		}
+		for _, upload := range expired {
+			if err := s.store.Delete(ctx, upload.StorageKey); err != nil {
+				return purged, fmt.Errorf("delete upload %s: %w", upload.ID, err)
+			}
+			if err := s.repo.Delete(ctx, upload.ID); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(expired) < uploadPurgeBatch {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type mockCSVUploadsRepository struct {
+	uploads map[uuid.UUID]*entity.CSVUpload
+}
+
+func newMockCSVUploadsRepository() *mockCSVUploadsRepository {
+	return &mockCSVUploadsRepository{uploads: make(map[uuid.UUID]*entity.CSVUpload)}
+}
+
+func (m *mockCSVUploadsRepository) Create(ctx context.Context, upload *entity.CSVUpload) error {
+	copied := *upload
+	m.uploads[upload.ID] = &copied
+	return nil
+}
+
+func (m *mockCSVUploadsRepository) Complete(ctx context.Context, upload *entity.CSVUpload) error {
+	if _, ok := m.uploads[upload.ID]; !ok {
+		return repository.ErrCSVUploadNotFound
+	}
+	copied := *upload
+	m.uploads[upload.ID] = &copied
+	return nil
+}
+
+func (m *mockCSVUploadsRepository) List(ctx context.Context, limit, offset int) ([]entity.CSVUpload, error) {
+	uploads := make([]entity.CSVUpload, 0, len(m.uploads))
+	for _, upload := range m.uploads {
+		uploads = append(uploads, *upload)
+	}
+	return uploads, nil
+}
+
+func (m *mockCSVUploadsRepository) Get(ctx context.Context, id uuid.UUID) (*entity.CSVUpload, error) {
+	upload, ok := m.uploads[id]
+	if !ok {
+		return nil, repository.ErrCSVUploadNotFound
+	}
+	return upload, nil
+}
+
+func (m *mockCSVUploadsRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]entity.CSVUpload, error) {
+	var expired []entity.CSVUpload
+	for _, upload := range m.uploads {
+		if !upload.ExpiresAt.After(now) && len(expired) < limit {
+			expired = append(expired, *upload)
+		}
+	}
+	return expired, nil
+}
+
+func (m *mockCSVUploadsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(m.uploads, id)
+	return nil
+}
+
+func TestUploadArchiveService(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo := newMockCSVUploadsRepository()
+	svc := NewUploadArchiveService(repo, store, "/uploads/", 24*time.Hour)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	admin := "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	file := strings.NewReader("company,address\nAcme,Main St\n")
+	upload, err := svc.Archive(ctx, admin, `C:\exports\leads.csv`, repository.ImportModeFillMissing, file)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if offset, _ := file.Seek(0, io.SeekCurrent); offset != 0 {
+		t.Fatalf("expected file rewound for import, at %d", offset)
+	}
+	if upload.Filename != "leads.csv" || upload.SizeBytes != 29 || upload.Mode != "fill_missing" || upload.Status != entity.CSVUploadStatusPending {
+		t.Fatalf("unexpected upload: %+v", upload)
+	}
+	if upload.SHA256 != "615141f926e2f6d1a44aca69e9c261b5be7c44ff400bbe7c85b2146efe246baf" {
+		t.Fatalf("unexpected hash %q", upload.SHA256)
+	}
+	if upload.StorageKey != "uploads/"+admin+"/"+upload.ID.String()+".csv" || !upload.ExpiresAt.Equal(now.Add(24*time.Hour)) {
+		t.Fatalf("unexpected storage key or expiry: %+v", upload)
+	}
+
+	if err := svc.Complete(ctx, upload, UploadSummary{Inserted: 1, Total: 1}, nil); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	got, reader, err := svc.Open(ctx, upload.ID.String())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if got.Status != entity.CSVUploadStatusCompleted || got.Inserted != 1 || string(data) != "company,address\nAcme,Main St\n" {
+		t.Fatalf("unexpected retained upload %+v with %q", got, data)
+	}
+
+	if _, err := svc.Get(ctx, "nope"); !errors.Is(err, ErrInvalidUploadID) {
+		t.Fatalf("expected ErrInvalidUploadID, got %v", err)
+	}
+	if _, err := svc.Get(ctx, uuid.NewString()); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("expected ErrUploadNotFound, got %v", err)
+	}
+
+	if purged, err := svc.PurgeExpired(ctx); err != nil || purged != 0 {
+		t.Fatalf("expected nothing to purge yet, got %d (%v)", purged, err)
+	}
+	now = now.Add(25 * time.Hour)
+	if purged, err := svc.PurgeExpired(ctx); err != nil || purged != 1 {
+		t.Fatalf("expected 1 upload purged, got %d (%v)", purged, err)
+	}
+	if _, err := store.Open(ctx, upload.StorageKey); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Fatalf("expected retained file deleted, got %v", err)
+	}
+}
+
+func TestUploadArchiveService_CompleteFailure(t *testing.T) {
+	repo := newMockCSVUploadsRepository()
+	svc := NewUploadArchiveService(repo, nil, "uploads", 0)
+	upload := &entity.CSVUpload{ID: uuid.New(), Status: entity.CSVUploadStatusPending}
+	repo.uploads[upload.ID] = upload
+
+	if err := svc.Complete(context.Background(), upload, UploadSummary{}, CSVValidationError{Message: "invalid rating value on row 3"}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	stored := repo.uploads[upload.ID]
+	if stored.Status != entity.CSVUploadStatusFailed || stored.Error == nil || *stored.Error != "invalid rating value on row 3" {
+		t.Fatalf("expected failed upload with error, got %+v", stored)
+	}
+	if svc.retention != DefaultUploadRetention {
+		t.Fatalf("expected default retention, got %s", svc.retention)
+	}
+}
+
+func TestUploadArchiveService_ArchiveWorkbook(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc := NewUploadArchiveService(newMockCSVUploadsRepository(), store, "uploads", time.Hour)
+
+	upload, err := svc.Archive(context.Background(), "", "leads.xlsx", repository.ImportModeOverwrite, strings.NewReader("PK\x03\x04workbook"))
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if upload.StorageKey != "uploads/unknown/"+upload.ID.String()+".xlsx" || UploadContentType(upload) != XLSXContentType {
+		t.Fatalf("expected workbook key and content type, got %q", upload.StorageKey)
+	}
+}
@@ -2,43 +2,173 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
 	"github.com/octobees/leads-generator/api/internal/repository"
 )
 
+const (
+	defaultUserPageLimit = 50
+	maxUserPageLimit     = 200
+)
+
+var (
+	// ErrInvalidUserCursor is returned when a users listing cursor cannot be decoded or belongs to
+	// a different sort order.
+	ErrInvalidUserCursor = errors.New("invalid users cursor")
+	// ErrInvalidUserSort is returned for sort columns the users listing does not support.
+	ErrInvalidUserSort = errors.New("sort must be created_at or email")
+)
+
+// UsersPage is one page of the admin users listing. Clients pass NextCursor back as cursor with
+// the same filters and sort to fetch the next page.
+type UsersPage struct {
+	Users      []dto.UserResponse `json:"users"`
+	Total      int64              `json:"total"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	HasMore    bool               `json:"has_more"`
+}
+
 // UserService encapsulates administrative operations for users.
 type UserService struct {
-	repo repository.UsersRepository
+	repo      repository.UsersRepository
+	publisher events.Publisher
+}
+
+// UserServiceOption customises optional UserService collaborators.
+type UserServiceOption func(*UserService)
+
+// WithUserEvents publishes the user changes made by admins to publisher.
+func WithUserEvents(publisher events.Publisher) UserServiceOption {
+	return func(s *UserService) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
 }
 
 // NewUserService builds a new UserService instance.
-func NewUserService(repo repository.UsersRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo repository.UsersRepository, opts ...UserServiceOption) *UserService {
+	svc := &UserService{repo: repo, publisher: events.Discard}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
 }
 
-// ListUsers returns all users as DTOs.
-func (s *UserService) ListUsers(ctx context.Context) ([]dto.UserResponse, error) {
-	users, err := s.repo.List(ctx)
+// ListUsers returns one page of users matching filter, after cursor; an empty cursor starts from
+// the first user. Total counts every matching user, not just the page.
+func (s *UserService) ListUsers(ctx context.Context, filter dto.UserListFilter, cursor string) (*UsersPage, error) {
+	filter.Email = strings.TrimSpace(filter.Email)
+	filter.Role = strings.TrimSpace(filter.Role)
+	switch filter.Sort {
+	case "":
+		filter.Sort = dto.UserSortCreatedAt
+	case dto.UserSortCreatedAt, dto.UserSortEmail:
+	default:
+		return nil, ErrInvalidUserSort
+	}
+	after, err := DecodeUserCursor(cursor, filter.Sort, filter.Desc)
 	if err != nil {
 		return nil, err
 	}
+	filter.After = after
+	limit := clampLimit(filter.Limit, defaultUserPageLimit, maxUserPageLimit)
+	filter.Limit = limit + 1
 
-	responses := make([]dto.UserResponse, 0, len(users))
+	users, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &UsersPage{Users: make([]dto.UserResponse, 0, len(users)), Total: total}
+	if len(users) > limit {
+		users = users[:limit]
+		page.HasMore = true
+	}
 	for _, u := range users {
-		responses = append(responses, dto.UserResponse{
-			ID:    u.ID.String(),
-			Email: u.Email,
-			Role:  u.Role,
+		createdAt := u.CreatedAt
+		page.Users = append(page.Users, dto.UserResponse{
+			ID:        u.ID.String(),
+			Email:     u.Email,
+			Role:      u.Role,
+			CreatedAt: &createdAt,
 		})
 	}
-	return responses, nil
+	if page.HasMore {
+		last := users[len(users)-1]
+		page.NextCursor = EncodeUserCursor(filter.Sort, filter.Desc, entity.UserCursor{CreatedAt: last.CreatedAt, Email: last.Email, ID: last.ID})
+	}
+	return page, nil
+}
+
+// EncodeUserCursor renders a users listing position as base64url of
+// "<sort>.<asc|desc>|<sort value>|<user id>", with created_at as unix microseconds.
+func EncodeUserCursor(sort string, desc bool, cursor entity.UserCursor) string {
+	value := cursor.Email
+	if sort == dto.UserSortCreatedAt {
+		value = strconv.FormatInt(cursor.CreatedAt.UnixMicro(), 10)
+	}
+	raw := fmt.Sprintf("%s.%s|%s|%s", sort, userSortDirection(desc), value, cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeUserCursor parses a cursor produced by EncodeUserCursor for the same sort and direction; an
+// empty cursor decodes to nil.
+func DecodeUserCursor(raw, sort string, desc bool) (*entity.UserCursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidUserCursor
+	}
+	order, rest, ok := strings.Cut(string(decoded), "|")
+	if !ok || order != sort+"."+userSortDirection(desc) {
+		return nil, ErrInvalidUserCursor
+	}
+	sep := strings.LastIndex(rest, "|")
+	if sep < 0 {
+		return nil, ErrInvalidUserCursor
+	}
+	id, err := uuid.Parse(rest[sep+1:])
+	if err != nil {
+		return nil, ErrInvalidUserCursor
+	}
+	cursor := &entity.UserCursor{ID: id}
+	if sort == dto.UserSortCreatedAt {
+		micros, err := strconv.ParseInt(rest[:sep], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidUserCursor
+		}
+		cursor.CreatedAt = time.UnixMicro(micros).UTC()
+	} else {
+		cursor.Email = rest[:sep]
+	}
+	return cursor, nil
+}
+
+func userSortDirection(desc bool) string {
+	if desc {
+		return "desc"
+	}
+	return "asc"
 }
 
 // CreateUser creates a new user with the supplied role.
@@ -65,6 +195,7 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		}
 		return nil, err
 	}
+	s.publisher.Publish(ctx, events.UserChanged{UserID: user.ID, Change: events.UserCreated})
 
 	resp := &dto.UserResponse{ID: user.ID.String(), Email: user.Email, Role: user.Role}
 	return resp, nil
@@ -118,6 +249,7 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, req dto.UpdateU
 		}
 		return nil, err
 	}
+	s.publisher.Publish(ctx, events.UserChanged{UserID: user.ID, Change: events.UserUpdated})
 
 	resp := &dto.UserResponse{ID: user.ID.String(), Email: user.Email, Role: user.Role}
 	return resp, nil
@@ -132,5 +264,31 @@ func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	if err := s.repo.Delete(ctx, userID); err != nil {
 		return err
 	}
+	s.publisher.Publish(ctx, events.UserChanged{UserID: userID, Change: events.UserDeleted})
+	return nil
+}
+
+// ResetPassword replaces the password of the user identified by email.
+func (s *UserService) ResetPassword(ctx context.Context, email, password string) error {
+	email = strings.TrimSpace(email)
+	if email == "" || strings.TrimSpace(password) == "" {
+		return errors.New("email and password are required")
+	}
+
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	pwd := string(hashed)
+
+	if _, err := s.repo.Update(ctx, user.ID, nil, &pwd, nil); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, events.UserChanged{UserID: user.ID, Change: events.UserPasswordReset})
 	return nil
 }
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// DefaultInvitationTTL is how long an invitation can be accepted.
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+	// MaxUserImportRows caps the rows of a user import, whose report lists every row.
+	MaxUserImportRows = 1000
+)
+
+var (
+	// ErrInvalidInvitation is returned when an invitation token is unknown, used or expired.
+	ErrInvalidInvitation = errors.New("invitation is invalid or has expired")
+	// ErrUserImportsUnavailable is returned when user imports are not wired.
+	ErrUserImportsUnavailable = errors.New("user imports are not enabled")
+)
+
+// userRoles are the roles an invitation may grant.
+var userRoles = map[string]bool{"admin": true, "user": true}
+
+// InvitationService invites users, who pick their password when accepting.
+type InvitationService struct {
+	repo repository.UserInvitationsRepository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewInvitationService builds an InvitationService whose invitations last DefaultInvitationTTL.
+func NewInvitationService(repo repository.UserInvitationsRepository) *InvitationService {
+	return &InvitationService{repo: repo, ttl: DefaultInvitationTTL, now: time.Now}
+}
+
+// UserInvite is a request to invite one user.
+type UserInvite struct {
+	Email string
+	Role  string
+	Org   string
+	// InvitedBy and ImportJobID trace the invitation back to an administrator and an import.
+	InvitedBy   *uuid.UUID
+	ImportJobID *uuid.UUID
+}
+
+// Invite creates or refreshes the invitation for invite.Email and returns it with the token to
+// hand to the invitee, and whether it is new. An address that already belongs to a user yields
+// repository.ErrEmailDuplicate.
+func (s *InvitationService) Invite(ctx context.Context, invite UserInvite) (*entity.UserInvitation, string, bool, error) {
+	email, role, err := normalizeInvite(invite.Email, invite.Role)
+	if err != nil {
+		return nil, "", false, err
+	}
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	invitation := &entity.UserInvitation{
+		Email:       email,
+		Role:        role,
+		InvitedBy:   invite.InvitedBy,
+		ImportJobID: invite.ImportJobID,
+		ExpiresAt:   s.now().UTC().Add(s.ttl),
+	}
+	if org := strings.TrimSpace(invite.Org); org != "" {
+		invitation.Org = &org
+	}
+	created, err := s.repo.UpsertInvitation(ctx, invitation, hashSecretToken(token))
+	if err != nil {
+		return nil, "", false, err
+	}
+	return invitation, token, created, nil
+}
+
+// Accept creates the account of the invitation matching token with the given password.
+func (s *InvitationService) Accept(ctx context.Context, token, password string) (*dto.UserResponse, error) {
+	token = strings.TrimSpace(token)
+	if token == "" || strings.TrimSpace(password) == "" {
+		return nil, errors.New("token and password are required")
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+
+	user, err := s.repo.AcceptInvitation(ctx, hashSecretToken(token), string(hashed))
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		if errors.Is(err, repository.ErrEmailDuplicate) {
+			return nil, repository.ErrEmailDuplicate
+		}
+		return nil, err
+	}
+	return &dto.UserResponse{ID: user.ID.String(), Email: user.Email, Role: user.Role}, nil
+}
+
+// normalizeInvite lowercases the email and defaults the role to user, rejecting anything else.
+func normalizeInvite(email, role string) (string, string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || !emailPattern.MatchString(email) {
+		return "", "", errors.New("invalid email")
+	}
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = "user"
+	}
+	if !userRoles[role] {
+		return "", "", fmt.Errorf("invalid role %q (use admin or user)", role)
+	}
+	return email, role, nil
+}
+
+// newSecretToken returns a random token for invitations, refresh tokens and email changes.
+func newSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashSecretToken is what the database stores instead of a token, so a leaked table does not leak
+// usable tokens.
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// userImportRow is a data row of a user import file.
+type userImportRow struct {
+	row   int
+	email string
+	role  string
+	org   string
+}
+
+// readUserImportRows reads a user import file with an email column and optional role and org
+// columns. Files without an email column or with more than MaxUserImportRows rows are rejected.
+func readUserImportRows(r io.Reader) ([]userImportRow, error) {
+	rows, err := openImportRows(r)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.close()
+
+	header, err := rows.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, CSVValidationError{Message: "csv file is empty"}
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	index := map[string]int{}
+	for i, column := range header {
+		column = normalizeCSVHeader(column)
+		if column == "email" || column == "role" || column == "org" {
+			if _, seen := index[column]; !seen {
+				index[column] = i
+			}
+		}
+	}
+	if _, ok := index["email"]; !ok {
+		return nil, CSVValidationError{Message: "missing required columns: email"}
+	}
+	field := func(record []string, column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var result []userImportRow
+	for line := 2; ; line++ {
+		record, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv row: %w", err)
+		}
+		row := userImportRow{row: line, email: field(record, "email"), role: field(record, "role"), org: field(record, "org")}
+		if row.email == "" && row.role == "" && row.org == "" {
+			continue
+		}
+		if len(result) == MaxUserImportRows {
+			return nil, CSVValidationError{Message: fmt.Sprintf("user imports are limited to %d rows", MaxUserImportRows)}
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/storage"
+)
+
+type mockInvitationsRepository struct {
+	users   map[string]bool
+	pending map[string]string
+}
+
+func (m *mockInvitationsRepository) UpsertInvitation(ctx context.Context, invitation *entity.UserInvitation, tokenHash string) (bool, error) {
+	if m.users[invitation.Email] {
+		return false, repository.ErrEmailDuplicate
+	}
+	invitation.ID = uuid.New()
+	_, refreshed := m.pending[invitation.Email]
+	m.pending[invitation.Email] = tokenHash
+	return !refreshed, nil
+}
+
+func (m *mockInvitationsRepository) AcceptInvitation(ctx context.Context, tokenHash, passwordHash string) (*entity.User, error) {
+	for email, hash := range m.pending {
+		if hash == tokenHash {
+			delete(m.pending, email)
+			return &entity.User{ID: uuid.New(), Email: email, Role: "user"}, nil
+		}
+	}
+	return nil, repository.ErrInvitationNotFound
+}
+
+func TestImportJobService_ProcessUserImport(t *testing.T) {
+	scratch, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	invites := &mockInvitationsRepository{
+		users:   map[string]bool{"boss@agency.test": true},
+		pending: map[string]string{"late@agency.test": "old"},
+	}
+	repo := newMockImportJobsRepository()
+	svc := NewImportJobService(repo, nil, nil, scratch, 2, 5, WithUserImports(NewInvitationService(invites)))
+	ctx := context.Background()
+
+	file := "Email;Role;Org\n" +
+		"Ana@Agency.test;;Agency\n" +
+		"boss@agency.test;admin;Agency\n" +
+		"late@agency.test;user;\n" +
+		"not-an-email;user;Agency\n" +
+		"ana@agency.test;user;Agency\n" +
+		"eve@agency.test;owner;Agency\n"
+	job, err := svc.CreateUserImport(ctx, uuid.NewString(), "seats.csv", strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if job.Kind != entity.ImportJobKindUsers {
+		t.Fatalf("unexpected job kind: %+v", job)
+	}
+	svc.process(ctx, <-svc.queue)
+
+	done, _ := svc.Get(ctx, job.ID.String())
+	if done.Status != entity.ImportJobStatusCompleted {
+		t.Fatalf("unexpected job status: %+v", done)
+	}
+	if done.ProcessedRows != 6 || done.Inserted != 1 || done.Updated != 1 || done.Skipped != 1 || done.ErrorCount != 3 {
+		t.Fatalf("unexpected job counters: %+v", done)
+	}
+	want := []string{entity.ImportRowInvited, entity.ImportRowSkipped, entity.ImportRowReinvited, entity.ImportRowFailed, entity.ImportRowFailed, entity.ImportRowFailed}
+	if len(done.Results) != len(want) {
+		t.Fatalf("expected %d row results, got %+v", len(want), done.Results)
+	}
+	for i, status := range want {
+		if done.Results[i].Status != status || done.Results[i].Row != i+2 {
+			t.Fatalf("row result %d: expected %s, got %+v", i, status, done.Results[i])
+		}
+	}
+	first := done.Results[0]
+	if first.Email != "ana@agency.test" || first.Token == "" || first.InvitationID == nil || first.ExpiresAt == nil {
+		t.Fatalf("unexpected invitation result: %+v", first)
+	}
+	if done.Results[4].Message != "duplicate of row 2" || len(done.RowErrors) != 3 {
+		t.Fatalf("unexpected row errors: %+v %+v", done.Results[4], done.RowErrors)
+	}
+
+	user, err := NewInvitationService(invites).Accept(ctx, first.Token, "s3cret")
+	if err != nil || user.Email != "ana@agency.test" {
+		t.Fatalf("unexpected accept outcome: %+v, %v", user, err)
+	}
+	if _, err := NewInvitationService(invites).Accept(ctx, first.Token, "s3cret"); !errors.Is(err, ErrInvalidInvitation) {
+		t.Fatalf("expected a used token to be rejected, got %v", err)
+	}
+}
+
+func TestImportJobService_UserImportValidation(t *testing.T) {
+	scratch, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+	disabled := NewImportJobService(newMockImportJobsRepository(), nil, nil, scratch, 2, 5)
+	if _, err := disabled.CreateUserImport(context.Background(), "", "seats.csv", strings.NewReader("email\n")); !errors.Is(err, ErrUserImportsUnavailable) {
+		t.Fatalf("expected ErrUserImportsUnavailable, got %v", err)
+	}
+
+	if _, err := readUserImportRows(strings.NewReader("name,role\nAna,user\n")); err == nil || !strings.Contains(err.Error(), "missing required columns: email") {
+		t.Fatalf("expected a missing email column error, got %v", err)
+	}
+	tooMany := "email\n" + strings.Repeat("a@b.test\n", MaxUserImportRows+1)
+	var validationErr CSVValidationError
+	if _, err := readUserImportRows(strings.NewReader(tooMany)); !errors.As(err, &validationErr) {
+		t.Fatalf("expected oversized files to be rejected, got %v", err)
+	}
+}
@@ -3,33 +3,75 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/octobees/leads-generator/api/internal/dto"
 	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/events"
 	"github.com/octobees/leads-generator/api/internal/repository"
+	"github.com/octobees/leads-generator/api/internal/testsupport"
 )
 
 func TestUserService_ListUsers(t *testing.T) {
-	repo := &mockUsersRepository{
-		list: func(ctx context.Context) ([]entity.User, error) {
-			return []entity.User{
-				{ID: uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"), Email: "admin@example.com", Role: "admin"},
-				{ID: uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"), Email: "user@example.com", Role: "user"},
-			}, nil
-		},
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var users []*entity.User
+	for i, email := range []string{"carol@example.com", "alice@example.com", "bob@example.com", "dave@example.com"} {
+		user := testsupport.NewUser().WithEmail(email).Build()
+		user.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		users = append(users, user)
 	}
+	users[3].Role = "admin"
+	service := NewUserService(testsupport.NewMemoryUsersRepository(users...))
+	ctx := context.Background()
 
-	service := NewUserService(repo)
-	users, err := service.ListUsers(context.Background())
+	// Newest first by default, two per page, following the cursor to the end.
+	var seen []string
+	cursor := ""
+	for {
+		page, err := service.ListUsers(ctx, dto.UserListFilter{Desc: true, Limit: 2}, cursor)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.Total != 4 {
+			t.Fatalf("expected a total of 4, got %d", page.Total)
+		}
+		for _, user := range page.Users {
+			seen = append(seen, user.Email)
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Fatalf("expected no cursor on the last page, got %q", page.NextCursor)
+			}
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if strings.Join(seen, ",") != "dave@example.com,bob@example.com,alice@example.com,carol@example.com" {
+		t.Fatalf("unexpected order: %v", seen)
+	}
+
+	page, err := service.ListUsers(ctx, dto.UserListFilter{Sort: dto.UserSortEmail, Role: "user", Email: " EXAMPLE ", Limit: 1}, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(users) != 2 || users[0].Email != "admin@example.com" || users[1].Role != "user" {
-		t.Fatalf("unexpected response: %+v", users)
+	if page.Total != 3 || len(page.Users) != 1 || page.Users[0].Email != "alice@example.com" || !page.HasMore {
+		t.Fatalf("unexpected filtered page: %+v", page)
+	}
+	next, err := service.ListUsers(ctx, dto.UserListFilter{Sort: dto.UserSortEmail, Role: "user", Limit: 1}, page.NextCursor)
+	if err != nil || next.Users[0].Email != "bob@example.com" {
+		t.Fatalf("unexpected next page %+v (%v)", next, err)
+	}
+
+	if _, err := service.ListUsers(ctx, dto.UserListFilter{Limit: 1}, page.NextCursor); !errors.Is(err, ErrInvalidUserCursor) {
+		t.Fatalf("expected a cursor from another sort to be rejected, got %v", err)
+	}
+	if _, err := service.ListUsers(ctx, dto.UserListFilter{Sort: "password_hash"}, ""); !errors.Is(err, ErrInvalidUserSort) {
+		t.Fatalf("expected ErrInvalidUserSort, got %v", err)
 	}
 }
 
@@ -148,11 +190,18 @@ func TestUserService_DeleteUser(t *testing.T) {
 			return nil
 		},
 	}
-	service := NewUserService(repo)
+	bus := events.NewBus()
+	sub := bus.Subscribe(events.TypeUserChanged)
+	defer sub.Close()
+	service := NewUserService(repo, WithUserEvents(bus))
 
-	if err := service.DeleteUser(context.Background(), uuid.NewString()); err != nil {
+	userID := uuid.New()
+	if err := service.DeleteUser(context.Background(), userID.String()); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if event := <-sub.C; event.Payload != (events.UserChanged{UserID: userID, Change: events.UserDeleted}) {
+		t.Fatalf("unexpected event %+v", event.Payload)
+	}
 	if err := service.DeleteUser(context.Background(), "bad-uuid"); err == nil {
 		t.Fatalf("expected invalid uuid error")
 	}
@@ -163,8 +212,46 @@ func TestUserService_DeleteUser(t *testing.T) {
 	if err := service.DeleteUser(context.Background(), uuid.NewString()); !errors.Is(err, repository.ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
+	if len(sub.C) != 0 {
+		t.Fatalf("expected failed deletes not to be published")
+	}
 }
 
 func stringPtr(value string) *string {
 	return &value
 }
+
+func TestUserService_ResetPassword(t *testing.T) {
+	userID := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	var storedHash string
+	repo := &mockUsersRepository{
+		findByEmail: func(ctx context.Context, email string) (*entity.User, error) {
+			if email != "admin@example.com" {
+				return nil, repository.ErrUserNotFound
+			}
+			return &entity.User{ID: userID, Email: email, Role: "admin"}, nil
+		},
+		update: func(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error) {
+			if id != userID || email != nil || role != nil || passwordHash == nil {
+				t.Fatalf("unexpected update args")
+			}
+			storedHash = *passwordHash
+			return &entity.User{ID: id}, nil
+		},
+	}
+
+	service := NewUserService(repo)
+	if err := service.ResetPassword(context.Background(), " admin@example.com ", "new-secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(storedHash), []byte("new-secret")) != nil {
+		t.Fatalf("expected password to be hashed")
+	}
+
+	if err := service.ResetPassword(context.Background(), "missing@example.com", "x"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if err := service.ResetPassword(context.Background(), "admin@example.com", " "); err == nil {
+		t.Fatalf("expected validation error for blank password")
+	}
+}
@@ -12,6 +12,9 @@ import (
 
 	"github.com/nyaruka/phonenumbers"
 	"golang.org/x/net/idna"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/octobees/leads-generator/api/internal/outbound"
 )
 
 var (
@@ -19,6 +22,22 @@ var (
 	idnaProfile  = idna.Lookup
 )
 
+// trackingParams are the ad click and campaign identifiers stripped from links besides the utm_
+// parameters.
+var trackingParams = map[string]bool{
+	"gclid":   true,
+	"gbraid":  true,
+	"wbraid":  true,
+	"dclid":   true,
+	"fbclid":  true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+}
+
 const (
 	trackingPrefix     = "utm_"
 	defaultPhoneRegion = "ID"
@@ -32,6 +51,10 @@ var allowedSocialDomains = map[string]string{
 	"youtube.com":   "youtube",
 	"youtu.be":      "youtube",
 	"tiktok.com":    "tiktok",
+	"twitter.com":   "twitter",
+	"x.com":         "twitter",
+	"wa.me":         "whatsapp",
+	"whatsapp.com":  "whatsapp",
 }
 
 // CleanedData represents validated and normalized contact information.
@@ -42,6 +65,8 @@ type CleanedData struct {
 	Socials        SocialLinks `json:"socials"`
 	Address        string      `json:"address"`
 	ContactFormURL string      `json:"contact_form_url"`
+	// EmailClasses maps classified emails to their disposable, free or role classes.
+	EmailClasses map[string][]string `json:"email_classes,omitempty"`
 }
 
 // SocialLinks stores the canonical URL for each supported network.
@@ -51,6 +76,10 @@ type SocialLinks struct {
 	Instagram string `json:"instagram,omitempty"`
 	Youtube   string `json:"youtube,omitempty"`
 	Tiktok    string `json:"tiktok,omitempty"`
+	Twitter   string `json:"twitter,omitempty"`
+	WhatsApp  string `json:"whatsapp,omitempty"`
+	// Handles maps each platform to the profile handle of its link, when the link is a profile.
+	Handles map[string]string `json:"handles,omitempty"`
 }
 
 // RawEnrichedData is the unvalidated payload sent by the enrichment worker.
@@ -76,9 +105,15 @@ type HTTPClient interface {
 
 // DataProcessor encapsulates the data cleaning and validation rules.
 type DataProcessor struct {
-	DefaultRegion string
-	dnsResolver   DNSResolver
-	httpClient    HTTPClient
+	DefaultRegion   string
+	dnsResolver     DNSResolver
+	httpClient      HTTPClient
+	emailClassifier *EmailClassifier
+	// checks bounds concurrent MX lookups and URL checks; dnsCache and checkCache reuse their results.
+	checks        *semaphore.Weighted
+	checkDeadline time.Duration
+	checkCache    *checkCache
+	dnsCache      *DNSCache
 }
 
 // DataProcessorOption configures optional dependencies.
@@ -100,7 +135,19 @@ func WithHTTPClient(client HTTPClient) DataProcessorOption {
 	}
 }
 
-// NewDataProcessor builds a processor with sensible defaults.
+// WithOutboundPolicy makes URL checks through a client following policy in place of the default
+// policy, which refuses internal addresses.
+func WithOutboundPolicy(policy *outbound.Policy) DataProcessorOption {
+	return func(p *DataProcessor) {
+		if policy != nil {
+			p.httpClient = policy.Client(defaultHTTPTimeout)
+		}
+	}
+}
+
+// NewDataProcessor builds a processor with sensible defaults. URLs taken from payloads are only
+// checked through a client refusing internal addresses, so a payload cannot make the API probe
+// its own network.
 func NewDataProcessor(defaultRegion string, opts ...DataProcessorOption) *DataProcessor {
 	region := strings.ToUpper(strings.TrimSpace(defaultRegion))
 	if region == "" {
@@ -109,9 +156,11 @@ func NewDataProcessor(defaultRegion string, opts ...DataProcessorOption) *DataPr
 	p := &DataProcessor{
 		DefaultRegion: region,
 		dnsResolver:   systemDNSResolver{},
-		httpClient: &http.Client{
-			Timeout: defaultHTTPTimeout,
-		},
+		httpClient:    outbound.New().Client(defaultHTTPTimeout),
+		checks:        semaphore.NewWeighted(defaultCheckConcurrency),
+		checkDeadline: defaultCheckDeadline,
+		checkCache:    newCheckCache(defaultCheckCacheTTL),
+		dnsCache:      NewDNSCache(defaultDNSCacheSize, defaultDNSPositiveTTL, defaultDNSNegativeTTL),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -128,6 +177,8 @@ func (p *DataProcessor) Process(ctx context.Context, input RawEnrichedData) (Cle
 	if companyID == "" {
 		return CleanedData{}, errors.New("company_id is required")
 	}
+	ctx, cancel := p.withCheckDeadline(ctx)
+	defer cancel()
 
 	emails := p.cleanEmails(ctx, input.Emails)
 	phones := p.normalizePhones(input.PrimaryPhone, input.SecondaryPhones)
@@ -142,6 +193,7 @@ func (p *DataProcessor) Process(ctx context.Context, input RawEnrichedData) (Cle
 		Socials:        socials,
 		Address:        address,
 		ContactFormURL: contactForm,
+		EmailClasses:   p.classifyEmails(emails),
 	}, nil
 }
 
@@ -149,9 +201,10 @@ func (p *DataProcessor) cleanEmails(ctx context.Context, emails []string) []stri
 	if len(emails) == 0 {
 		return nil
 	}
+	type candidate struct{ email, domain string }
 	seen := make(map[string]struct{}, len(emails))
-	domainCache := make(map[string]bool)
-	valid := make([]string, 0, len(emails))
+	candidates := make([]candidate, 0, len(emails))
+	domains := make([]string, 0, len(emails))
 
 	for _, raw := range emails {
 		email := strings.ToLower(strings.TrimSpace(raw))
@@ -167,22 +220,20 @@ func (p *DataProcessor) cleanEmails(ctx context.Context, emails []string) []stri
 		if err != nil || asciiDomain == "" {
 			continue
 		}
-		if ok, cached := domainCache[asciiDomain]; cached {
-			if !ok {
-				continue
-			}
-		} else {
-			hasMX := p.hasMXRecord(ctx, asciiDomain)
-			domainCache[asciiDomain] = hasMX
-			if !hasMX {
-				continue
-			}
-		}
 		if _, dup := seen[email]; dup {
 			continue
 		}
 		seen[email] = struct{}{}
-		valid = append(valid, email)
+		candidates = append(candidates, candidate{email: email, domain: asciiDomain})
+		domains = append(domains, asciiDomain)
+	}
+
+	hasMX := p.checkAll(ctx, domains, p.hasMXRecord)
+	valid := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if hasMX[c.domain] {
+			valid = append(valid, c.email)
+		}
 	}
 	if len(valid) == 0 {
 		return nil
@@ -221,25 +272,35 @@ func (p *DataProcessor) validateSocials(ctx context.Context, socials map[string]
 	if len(socials) == 0 {
 		return SocialLinks{}
 	}
-	result := SocialLinks{}
-	used := make(map[string]struct{})
-
-	for key, candidates := range socials {
+	type candidate struct{ link, handle string }
+	byPlatform := make(map[string][]candidate)
+	var links []string
+	for key, raws := range socials {
 		platform := canonicalSocialKey(key)
-		if platform == "" || len(candidates) == 0 {
+		if platform == "" || len(raws) == 0 {
 			continue
 		}
-		if _, exists := used[platform]; exists {
+		if _, exists := byPlatform[platform]; exists {
 			continue
 		}
-		for _, raw := range candidates {
-			sanitized, ok := p.cleanSocialLink(ctx, platform, raw)
-			if !ok {
-				continue
+		byPlatform[platform] = nil
+		for _, raw := range raws {
+			if link, handle, ok := canonicalSocialLink(platform, raw); ok {
+				byPlatform[platform] = append(byPlatform[platform], candidate{link: link, handle: handle})
+				links = append(links, link)
+			}
+		}
+	}
+
+	// Every candidate is checked at once; each platform keeps its first link that resolves.
+	resolves := p.checkAll(ctx, links, p.urlResolves)
+	result := SocialLinks{}
+	for platform, candidates := range byPlatform {
+		for _, c := range candidates {
+			if resolves[c.link] {
+				result.set(platform, c.link, c.handle)
+				break
 			}
-			result.set(platform, sanitized)
-			used[platform] = struct{}{}
-			break
 		}
 	}
 	return result
@@ -254,36 +315,53 @@ func (p *DataProcessor) sanitizeContactForm(raw string) string {
 	return u.String()
 }
 
-func (p *DataProcessor) cleanSocialLink(ctx context.Context, platform, raw string) (string, bool) {
+// canonicalSocialLink returns the canonical URL of a link on platform and its profile handle, if
+// any. The link must be on an allowed domain of the platform; whether it resolves is checked
+// separately.
+func canonicalSocialLink(platform, raw string) (string, string, bool) {
 	u, err := sanitizeURL(raw)
 	if err != nil {
-		return "", false
+		return "", "", false
 	}
 	hostPlatform, ok := hostMatchesAllowed(u.Hostname())
 	if !ok || hostPlatform != platform {
-		return "", false
+		return "", "", false
 	}
 	stripTracking(u)
-	if !p.urlResolves(ctx, u.String()) {
-		return "", false
-	}
-	return u.String(), true
+	handle := canonicalizeSocialURL(platform, u)
+	return u.String(), handle, true
 }
 
 func (p *DataProcessor) hasMXRecord(ctx context.Context, domain string) bool {
 	if p.dnsResolver == nil {
 		return false
 	}
+	if hasMX, found := p.dnsCache.Lookup(domain); found {
+		return hasMX
+	}
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 	records, err := p.dnsResolver.LookupMX(ctx, domain)
-	return err == nil && len(records) > 0
+	hasMX := err == nil && len(records) > 0
+	// Only answers are cached: a timeout or SERVFAIL says nothing about the domain.
+	var dnsErr *net.DNSError
+	if err == nil || (errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		p.dnsCache.Store(domain, hasMX)
+	}
+	return hasMX
 }
 
 func (p *DataProcessor) urlResolves(ctx context.Context, target string) bool {
 	if p.httpClient == nil {
 		return false
 	}
+	return p.cachedCheck(ctx, "url:"+target, func(ctx context.Context) bool {
+		return p.fetchOK(ctx, target)
+	})
+}
+
+// fetchOK reports whether target answers 200 to HEAD, or to GET when HEAD is not allowed.
+func (p *DataProcessor) fetchOK(ctx context.Context, target string) bool {
 	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
 	if err != nil {
 		return false
@@ -311,7 +389,13 @@ func (p *DataProcessor) urlResolves(ctx context.Context, target string) bool {
 	return resp.StatusCode == http.StatusOK
 }
 
-func (links *SocialLinks) set(platform, value string) {
+func (links *SocialLinks) set(platform, value, handle string) {
+	if handle != "" {
+		if links.Handles == nil {
+			links.Handles = make(map[string]string)
+		}
+		links.Handles[platform] = handle
+	}
 	switch platform {
 	case "linkedin":
 		links.LinkedIn = value
@@ -323,6 +407,10 @@ func (links *SocialLinks) set(platform, value string) {
 		links.Youtube = value
 	case "tiktok":
 		links.Tiktok = value
+	case "twitter":
+		links.Twitter = value
+	case "whatsapp":
+		links.WhatsApp = value
 	}
 }
 
@@ -338,6 +426,10 @@ func canonicalSocialKey(key string) string {
 		return "youtube"
 	case "tiktok", "tiktok_url":
 		return "tiktok"
+	case "twitter", "twitter_url", "x", "x_url":
+		return "twitter"
+	case "whatsapp", "whatsapp_url", "wa", "whatsapp_business":
+		return "whatsapp"
 	default:
 		return ""
 	}
@@ -379,7 +471,7 @@ func stripTracking(u *url.URL) {
 	query := u.Query()
 	changed := false
 	for key := range query {
-		if strings.HasPrefix(strings.ToLower(key), trackingPrefix) {
+		if lower := strings.ToLower(key); strings.HasPrefix(lower, trackingPrefix) || trackingParams[lower] {
 			query.Del(key)
 			changed = true
 		}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// Defaults for the network checks of a DataProcessor.
+const (
+	defaultCheckConcurrency = 16
+	defaultCheckDeadline    = 10 * time.Second
+	defaultCheckCacheTTL    = time.Hour
+)
+
+// WithCheckConcurrency bounds how many MX lookups and URL checks run at once across all requests
+// sharing the processor.
+func WithCheckConcurrency(n int) DataProcessorOption {
+	return func(p *DataProcessor) {
+		if n > 0 {
+			p.checks = semaphore.NewWeighted(int64(n))
+		}
+	}
+}
+
+// WithCheckDeadline caps the time one payload may spend on MX lookups and URL checks; checks still
+// pending at the deadline count as failed.
+func WithCheckDeadline(d time.Duration) DataProcessorOption {
+	return func(p *DataProcessor) {
+		if d > 0 {
+			p.checkDeadline = d
+		}
+	}
+}
+
+// WithCheckCacheTTL sets how long URL check results are reused; zero disables the cache.
+func WithCheckCacheTTL(ttl time.Duration) DataProcessorOption {
+	return func(p *DataProcessor) {
+		p.checkCache = newCheckCache(ttl)
+	}
+}
+
+// WithDNSCache replaces the processor's own MX lookup cache, typically with one shared by every
+// processor so its stats cover the whole process; nil disables MX caching.
+func WithDNSCache(cache *DNSCache) DataProcessorOption {
+	return func(p *DataProcessor) {
+		p.dnsCache = cache
+	}
+}
+
+// withCheckDeadline returns ctx limited by the processor's per-payload check deadline.
+func (p *DataProcessor) withCheckDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.checkDeadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.checkDeadline)
+}
+
+// checkAll runs check for every distinct key, at most the processor's concurrency at a time, and
+// reports which keys passed. Keys not checked before ctx ends are reported as failed.
+func (p *DataProcessor) checkAll(ctx context.Context, keys []string, check func(context.Context, string) bool) map[string]bool {
+	var (
+		mu     sync.Mutex
+		passed = make(map[string]bool, len(keys))
+		seen   = make(map[string]bool, len(keys))
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		group.Go(func() error {
+			if p.checks != nil {
+				if err := p.checks.Acquire(groupCtx, 1); err != nil {
+					return nil
+				}
+				defer p.checks.Release(1)
+			}
+			ok := check(groupCtx, key)
+			mu.Lock()
+			passed[key] = ok
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return passed
+}
+
+// checkCache remembers URL check results for a while so repeated profiles of a busy enrichment run
+// are not fetched again. MX lookups have their own DNSCache.
+type checkCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]checkResult
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type checkResult struct {
+	ok      bool
+	expires time.Time
+}
+
+func newCheckCache(ttl time.Duration) *checkCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &checkCache{ttl: ttl, entries: make(map[string]checkResult), now: time.Now}
+}
+
+// lookup returns the cached result of key; found is false when it is missing or expired.
+func (c *checkCache) lookup(key string) (ok, found bool) {
+	if c == nil {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.entries[key]
+	if !found || c.now().After(entry.expires) {
+		return false, false
+	}
+	return entry.ok, true
+}
+
+// store records a result. Expired entries are swept once per TTL, so the cache holds at most the
+// keys seen within two TTLs.
+func (c *checkCache) store(key string, ok bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if now.After(c.nextSweep) {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+	c.entries[key] = checkResult{ok: ok, expires: now.Add(c.ttl)}
+}
+
+// cachedCheck answers from the cache or runs check, caching its result unless ctx ended first:
+// a check cut short by the deadline says nothing about the URL.
+func (p *DataProcessor) cachedCheck(ctx context.Context, key string, check func(context.Context) bool) bool {
+	if ok, found := p.checkCache.lookup(key); found {
+		return ok
+	}
+	ok := check(ctx)
+	if ctx.Err() == nil {
+		p.checkCache.store(key, ok)
+	}
+	return ok
+}
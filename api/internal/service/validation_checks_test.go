@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingResolver answers every domain in mx after delay and counts lookups and the most run at once.
+type countingResolver struct {
+	mx      map[string]bool
+	delay   time.Duration
+	calls   atomic.Int32
+	running atomic.Int32
+	mu      sync.Mutex
+	peak    int32
+}
+
+func (r *countingResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	r.calls.Add(1)
+	running := r.running.Add(1)
+	defer r.running.Add(-1)
+	r.mu.Lock()
+	if running > r.peak {
+		r.peak = running
+	}
+	r.mu.Unlock()
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.mx[domain] {
+		return []*net.MX{{Host: "mail." + domain}}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+}
+
+func TestCleanEmails_ChecksDomainsConcurrentlyAndCaches(t *testing.T) {
+	resolver := &countingResolver{mx: map[string]bool{"a.com": true, "b.com": true, "c.com": true}, delay: 20 * time.Millisecond}
+	p := NewDataProcessor("ID", WithDNSResolver(resolver), WithHTTPClient(&stubHTTPClient{}), WithCheckConcurrency(2))
+	emails := []string{"x@a.com", "y@b.com", "z@c.com", "w@d.com", "v@a.com"}
+
+	got := p.cleanEmails(context.Background(), emails)
+	want := []string{"x@a.com", "y@b.com", "z@c.com", "v@a.com"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v in input order, got %v", want, got)
+		}
+	}
+	if calls := resolver.calls.Load(); calls != 4 {
+		t.Fatalf("expected one lookup per domain, got %d", calls)
+	}
+	if resolver.peak != 2 {
+		t.Fatalf("expected lookups bounded at 2, peak was %d", resolver.peak)
+	}
+
+	p.cleanEmails(context.Background(), emails)
+	if calls := resolver.calls.Load(); calls != 4 {
+		t.Fatalf("expected cached results to be reused, got %d lookups", calls)
+	}
+}
+
+func TestProcess_CheckDeadlineRejectsPendingChecksWithoutCaching(t *testing.T) {
+	resolver := &countingResolver{mx: map[string]bool{"slow.com": true}, delay: time.Second}
+	p := NewDataProcessor("ID", WithDNSResolver(resolver), WithHTTPClient(&stubHTTPClient{}), WithCheckDeadline(20*time.Millisecond))
+
+	start := time.Now()
+	cleaned, err := p.Process(context.Background(), RawEnrichedData{CompanyID: "c-1", Emails: []string{"sales@slow.com"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the deadline to stop the check, took %s", elapsed)
+	}
+	if len(cleaned.Emails) != 0 {
+		t.Fatalf("expected the unchecked email to be dropped, got %v", cleaned.Emails)
+	}
+	if _, found := p.dnsCache.Lookup("slow.com"); found {
+		t.Fatal("expected a check cut short by the deadline not to be cached")
+	}
+}
+
+func TestCheckCache_ExpiresEntries(t *testing.T) {
+	now := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	cache := newCheckCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.store("url:https://x.com/acme", true)
+	if ok, found := cache.lookup("url:https://x.com/acme"); !ok || !found {
+		t.Fatalf("expected a cached pass, got %v, %v", ok, found)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, found := cache.lookup("url:https://x.com/acme"); found {
+		t.Fatal("expected the entry to expire")
+	}
+	cache.store("url:https://x.com/beta", false)
+	if len(cache.entries) != 1 {
+		t.Fatalf("expected expired entries to be swept, got %v", cache.entries)
+	}
+	if newCheckCache(0) != nil {
+		t.Fatal("expected a zero TTL to disable the cache")
+	}
+}
+
+func TestValidateSocials_FirstResolvingLinkWins(t *testing.T) {
+	httpClient := &stubHTTPClient{responses: map[string]int{
+		"HEAD https://www.instagram.com/acme.old": http.StatusNotFound,
+		"HEAD https://www.instagram.com/acme":     http.StatusOK,
+		"HEAD https://www.instagram.com/acme.bak": http.StatusOK,
+	}}
+	p := NewDataProcessor("ID", WithDNSResolver(&stubDNSResolver{}), WithHTTPClient(httpClient))
+
+	result := p.validateSocials(context.Background(), map[string][]string{
+		"instagram": {"https://www.instagram.com/acme.old", "https://www.instagram.com/acme", "https://www.instagram.com/acme.bak"},
+	})
+	if result.Instagram != "https://www.instagram.com/acme" {
+		t.Fatalf("expected the first reachable link, got %+v", result)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+const (
+	// DefaultWarehouseRowsPerFile caps the number of rows written to a single Parquet part.
+	DefaultWarehouseRowsPerFile = 250000
+	// ParquetContentType is the media type used for Parquet files.
+	ParquetContentType = "application/vnd.apache.parquet"
+	warehouseBatchSize = 1000
+)
+
+var (
+	// ErrWarehouseNotConfigured is returned when a partition export runs without object storage.
+	ErrWarehouseNotConfigured = errors.New("warehouse storage not configured")
+	// ErrWarehouseExportNotFound indicates no warehouse partition has been exported yet.
+	ErrWarehouseExportNotFound = errors.New("warehouse export not found")
+)
+
+// ObjectStore uploads export files to object storage.
+type ObjectStore interface {
+	Upload(ctx context.Context, name, contentType string, r io.Reader) error
+	URI(name string) string
+}
+
+// WarehouseService exports companies, enrichments and lead scores as Parquet for the data warehouse.
+type WarehouseService struct {
+	repo        repository.WarehouseRepository
+	store       ObjectStore
+	prefix      string
+	rowsPerFile int
+	now         func() time.Time
+}
+
+// NewWarehouseService builds a WarehouseService. store may be nil when only streaming exports are needed.
+func NewWarehouseService(repo repository.WarehouseRepository, store ObjectStore, prefix string, rowsPerFile int) *WarehouseService {
+	if rowsPerFile <= 0 {
+		rowsPerFile = DefaultWarehouseRowsPerFile
+	}
+	return &WarehouseService{
+		repo:        repo,
+		store:       store,
+		prefix:      strings.Trim(prefix, "/"),
+		rowsPerFile: rowsPerFile,
+		now:         time.Now,
+	}
+}
+
+// WriteParquet streams the whole catalogue to w as a single Parquet file and returns the row count.
+// The stamp is stored in the file's key-value metadata under "exported_by".
+func (s *WarehouseService) WriteParquet(ctx context.Context, w io.Writer, stamp ExportStamp) (int64, error) {
+	pager := &warehousePager{repo: s.repo}
+	return writeParquetPart(ctx, w, pager, 0, parquet.KeyValueMetadata("exported_by", stamp.String()))
+}
+
+// ExportPartition writes the catalogue to <prefix>/companies/dt=YYYY-MM-DD/part-NNNNN.parquet, a
+// Hive-style layout BigQuery external tables can read, and records the partition for the manifest.
+// Re-running a date overwrites its parts.
+func (s *WarehouseService) ExportPartition(ctx context.Context, date time.Time) (*entity.WarehouseExport, error) {
+	if s.store == nil {
+		return nil, ErrWarehouseNotConfigured
+	}
+
+	started := s.now().UTC()
+	if date.IsZero() {
+		date = started
+	}
+	partition := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	dir := path.Join(s.prefix, "companies", "dt="+partition.Format(time.DateOnly))
+
+	export := &entity.WarehouseExport{
+		PartitionDate: partition,
+		Location:      s.store.URI(dir + "/"),
+		StartedAt:     started,
+	}
+
+	pager := &warehousePager{repo: s.repo}
+	for part := 0; ; part++ {
+		name := path.Join(dir, fmt.Sprintf("part-%05d.parquet", part))
+		written, err := s.uploadPart(ctx, name, pager)
+		if err != nil {
+			return nil, err
+		}
+		export.Files = append(export.Files, s.store.URI(name))
+		export.RowCount += written
+
+		more, err := pager.more(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+	}
+
+	if err := s.repo.RecordExport(ctx, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// LatestExport returns the manifest of the most recent exported partition.
+func (s *WarehouseService) LatestExport(ctx context.Context) (*entity.WarehouseExport, error) {
+	export, err := s.repo.LatestExport(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrWarehouseExportNotFound) {
+			return nil, ErrWarehouseExportNotFound
+		}
+		return nil, err
+	}
+	return export, nil
+}
+
+// uploadPart pipes one Parquet part straight into the object store so parts never sit in memory.
+func (s *WarehouseService) uploadPart(ctx context.Context, name string, pager *warehousePager) (int64, error) {
+	pr, pw := io.Pipe()
+	uploaded := make(chan error, 1)
+	go func() {
+		err := s.store.Upload(ctx, name, ParquetContentType, pr)
+		// Unblock the writer if the upload stopped reading early.
+		pr.CloseWithError(err)
+		uploaded <- err
+	}()
+
+	written, writeErr := writeParquetPart(ctx, pw, pager, s.rowsPerFile)
+	pw.CloseWithError(writeErr)
+	uploadErr := <-uploaded
+	if writeErr != nil {
+		return 0, writeErr
+	}
+	if uploadErr != nil {
+		return 0, uploadErr
+	}
+	return written, nil
+}
+
+// writeParquetPart writes up to maxRows rows (all remaining rows when maxRows is zero) as one file.
+func writeParquetPart(ctx context.Context, w io.Writer, pager *warehousePager, maxRows int, options ...parquet.WriterOption) (int64, error) {
+	options = append([]parquet.WriterOption{parquet.Compression(&parquet.Snappy)}, options...)
+	writer := parquet.NewGenericWriter[entity.WarehouseRow](w, options...)
+
+	var written int64
+	for maxRows <= 0 || written < int64(maxRows) {
+		limit := warehouseBatchSize
+		if maxRows > 0 && int64(maxRows)-written < int64(limit) {
+			limit = int(int64(maxRows) - written)
+		}
+		batch, err := pager.take(ctx, limit)
+		if err != nil {
+			return written, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if _, err := writer.Write(batch); err != nil {
+			return written, fmt.Errorf("write parquet rows: %w", err)
+		}
+		written += int64(len(batch))
+	}
+
+	if err := writer.Close(); err != nil {
+		return written, fmt.Errorf("close parquet writer: %w", err)
+	}
+	return written, nil
+}
+
+// warehousePager buffers keyset pages so callers can ask whether rows remain before starting a new part.
+type warehousePager struct {
+	repo  repository.WarehouseRepository
+	after uuid.UUID
+	buf   []entity.WarehouseRow
+	done  bool
+}
+
+func (p *warehousePager) fill(ctx context.Context) error {
+	if len(p.buf) > 0 || p.done {
+		return nil
+	}
+	batch, last, err := p.repo.ListExportRowsAfter(ctx, p.after, warehouseBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(batch) < warehouseBatchSize {
+		p.done = true
+	}
+	p.buf = batch
+	p.after = last
+	return nil
+}
+
+func (p *warehousePager) more(ctx context.Context) (bool, error) {
+	if err := p.fill(ctx); err != nil {
+		return false, err
+	}
+	return len(p.buf) > 0, nil
+}
+
+func (p *warehousePager) take(ctx context.Context, max int) ([]entity.WarehouseRow, error) {
+	if err := p.fill(ctx); err != nil {
+		return nil, err
+	}
+	n := min(max, len(p.buf))
+	batch := p.buf[:n]
+	p.buf = p.buf[n:]
+	return batch, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+type mockWarehouseRepository struct {
+	rows     []entity.WarehouseRow
+	recorded *entity.WarehouseExport
+}
+
+func (m *mockWarehouseRepository) ListExportRowsAfter(ctx context.Context, after uuid.UUID, limit int) ([]entity.WarehouseRow, uuid.UUID, error) {
+	var page []entity.WarehouseRow
+	last := after
+	for _, row := range m.rows {
+		id := uuid.MustParse(row.ID)
+		if id.String() > after.String() && len(page) < limit {
+			page = append(page, row)
+			last = id
+		}
+	}
+	return page, last, nil
+}
+
+func (m *mockWarehouseRepository) RecordExport(ctx context.Context, export *entity.WarehouseExport) error {
+	m.recorded = export
+	return nil
+}
+
+func (m *mockWarehouseRepository) LatestExport(ctx context.Context) (*entity.WarehouseExport, error) {
+	if m.recorded == nil {
+		return nil, repository.ErrWarehouseExportNotFound
+	}
+	return m.recorded, nil
+}
+
+type memoryObjectStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (m *memoryObjectStore) Upload(ctx context.Context, name, contentType string, r io.Reader) error {
+	if m.err != nil {
+		return m.err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	m.objects[name] = data
+	return nil
+}
+
+func (m *memoryObjectStore) URI(name string) string {
+	return "gs://bucket/" + name
+}
+
+func warehouseRows(n int) []entity.WarehouseRow {
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]entity.WarehouseRow, 0, n)
+	for i := 1; i <= n; i++ {
+		city := "Jakarta"
+		score := int64(40 + i)
+		rows = append(rows, entity.WarehouseRow{
+			ID:        fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+			Company:   fmt.Sprintf("Company %d", i),
+			City:      &city,
+			CreatedAt: created,
+			UpdatedAt: created,
+			Emails:    []string{fmt.Sprintf("info%d@example.com", i)},
+			LeadScore: &score,
+		})
+	}
+	return rows
+}
+
+func readParquetRows(t *testing.T, data []byte) []entity.WarehouseRow {
+	t.Helper()
+	rows, err := parquet.Read[entity.WarehouseRow](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("read parquet: %v", err)
+	}
+	return rows
+}
+
+func TestWarehouseService_WriteParquet(t *testing.T) {
+	svc := NewWarehouseService(&mockWarehouseRepository{rows: warehouseRows(3)}, nil, "", 0)
+
+	var buf bytes.Buffer
+	stamp := NewExportStamp("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", "admin@example.com", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC))
+	written, err := svc.WriteParquet(context.Background(), &buf, stamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if written != 3 {
+		t.Fatalf("expected 3 rows written, got %d", written)
+	}
+
+	rows := readParquetRows(t, buf.Bytes())
+	if len(rows) != 3 || rows[1].Company != "Company 2" || *rows[1].City != "Jakarta" || *rows[2].LeadScore != 43 {
+		t.Fatalf("unexpected parquet rows: %+v", rows)
+	}
+	if rows[0].Phone != nil || len(rows[0].Emails) != 1 {
+		t.Fatalf("expected optional and list columns preserved, got %+v", rows[0])
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open parquet: %v", err)
+	}
+	if got, _ := file.Lookup("exported_by"); got != stamp.String() {
+		t.Fatalf("expected export stamp in metadata, got %q", got)
+	}
+}
+
+func TestWarehouseService_ExportPartition(t *testing.T) {
+	repo := &mockWarehouseRepository{rows: warehouseRows(5)}
+	store := &memoryObjectStore{}
+	svc := NewWarehouseService(repo, store, "/warehouse/", 2)
+
+	export, err := svc.ExportPartition(context.Background(), time.Date(2024, 6, 2, 15, 0, 0, 0, time.FixedZone("WIB", 7*3600)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.RowCount != 5 || len(export.Files) != 3 {
+		t.Fatalf("expected 5 rows across 3 parts, got %+v", export)
+	}
+	if export.Location != "gs://bucket/warehouse/companies/dt=2024-06-02/" {
+		t.Fatalf("unexpected location %q", export.Location)
+	}
+	last := store.objects["warehouse/companies/dt=2024-06-02/part-00002.parquet"]
+	if rows := readParquetRows(t, last); len(rows) != 1 || rows[0].Company != "Company 5" {
+		t.Fatalf("unexpected last part rows: %+v", rows)
+	}
+	if repo.recorded != export {
+		t.Fatalf("expected export recorded for the manifest")
+	}
+
+	latest, err := svc.LatestExport(context.Background())
+	if err != nil || latest.PartitionDate.Format(time.DateOnly) != "2024-06-02" {
+		t.Fatalf("unexpected latest export: %+v, %v", latest, err)
+	}
+}
+
+func TestWarehouseService_ExportPartition_EmptyCatalogue(t *testing.T) {
+	store := &memoryObjectStore{}
+	svc := NewWarehouseService(&mockWarehouseRepository{}, store, "warehouse", 2)
+
+	export, err := svc.ExportPartition(context.Background(), time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.RowCount != 0 || len(export.Files) != 1 || len(store.objects) != 1 {
+		t.Fatalf("expected a single empty part, got %+v", export)
+	}
+}
+
+func TestWarehouseService_ExportPartition_Errors(t *testing.T) {
+	svc := NewWarehouseService(&mockWarehouseRepository{}, nil, "warehouse", 0)
+	if _, err := svc.ExportPartition(context.Background(), time.Time{}); !errors.Is(err, ErrWarehouseNotConfigured) {
+		t.Fatalf("expected ErrWarehouseNotConfigured, got %v", err)
+	}
+
+	uploadErr := errors.New("bucket missing")
+	repo := &mockWarehouseRepository{rows: warehouseRows(3)}
+	svc = NewWarehouseService(repo, &memoryObjectStore{err: uploadErr}, "warehouse", 0)
+	if _, err := svc.ExportPartition(context.Background(), time.Time{}); !errors.Is(err, uploadErr) {
+		t.Fatalf("expected upload error, got %v", err)
+	}
+	if repo.recorded != nil {
+		t.Fatalf("expected failed export not to be recorded")
+	}
+
+	if _, err := NewWarehouseService(&mockWarehouseRepository{}, nil, "", 0).LatestExport(context.Background()); !errors.Is(err, ErrWarehouseExportNotFound) {
+		t.Fatalf("expected ErrWarehouseExportNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/outbound"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+// WebsiteStatusUnchecked is the website_status filter value matching websites not checked yet.
+const WebsiteStatusUnchecked = "unchecked"
+
+// Defaults of a WebsiteCheckService.
+const (
+	defaultWebsiteCheckBatch       = 100
+	defaultWebsiteCheckConcurrency = 8
+	defaultWebsiteCheckTimeout     = 10 * time.Second
+	defaultWebsiteRecheck          = 7 * 24 * time.Hour
+	// websiteCheckBodyLimit bounds how much of a page is read to recognise parking pages.
+	websiteCheckBodyLimit = 64 << 10
+	websiteCheckUserAgent = "Mozilla/5.0 (compatible; LeadsGeneratorBot/1.0; +website-check)"
+)
+
+// parkingHosts are domain parking and aftermarket services parked domains send visitors to.
+var parkingHosts = []string{
+	"sedo.com", "sedoparking.com", "dan.com", "afternic.com", "hugedomains.com", "bodis.com",
+	"parkingcrew.net", "above.com", "undeveloped.com", "domainmarket.com", "atom.com",
+}
+
+// parkingMarkers are phrases of parking and for-sale pages, matched in the lowercase page.
+var parkingMarkers = []string{
+	"this domain is for sale",
+	"this domain may be for sale",
+	"buy this domain",
+	"domain is parked",
+	"this domain is parked",
+	"parked free",
+	"domain has expired",
+	"this domain name has expired",
+	"sedoparking",
+	"parkingcrew",
+	"domain ini dijual",
+	"domain ini telah kedaluwarsa",
+}
+
+// NormalizeWebsiteStatus lowercases a website_status filter value and reports whether it is
+// supported.
+func NormalizeWebsiteStatus(raw string) (string, bool) {
+	status := strings.ToLower(strings.TrimSpace(raw))
+	switch status {
+	case entity.WebsiteLive, entity.WebsiteDead, entity.WebsiteParked, entity.WebsiteRedirected, WebsiteStatusUnchecked:
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+// WebsiteCheckService checks whether company websites are live, dead, parked or redirected to
+// another site, rechecking each one once its last check is old enough.
+type WebsiteCheckService struct {
+	repo        repository.WebsiteCheckRepository
+	client      *http.Client
+	batchSize   int
+	concurrency int
+	recheck     time.Duration
+	now         func() time.Time
+}
+
+// WebsiteCheckServiceOption customises a WebsiteCheckService.
+type WebsiteCheckServiceOption func(*WebsiteCheckService)
+
+// WithWebsiteCheckPolicy checks websites through a client following policy, each request bounded by
+// timeout, in place of the default policy refusing internal addresses.
+func WithWebsiteCheckPolicy(policy *outbound.Policy, timeout time.Duration) WebsiteCheckServiceOption {
+	return func(s *WebsiteCheckService) {
+		if policy != nil && timeout > 0 {
+			s.client = websiteCheckClient(policy, timeout)
+		}
+	}
+}
+
+// WithWebsiteCheckBatch sets how many websites each pass checks and how many at once.
+func WithWebsiteCheckBatch(batchSize, concurrency int) WebsiteCheckServiceOption {
+	return func(s *WebsiteCheckService) {
+		if batchSize > 0 {
+			s.batchSize = batchSize
+		}
+		if concurrency > 0 {
+			s.concurrency = concurrency
+		}
+	}
+}
+
+// WithWebsiteRecheck sets how old a check may get before the website is checked again.
+func WithWebsiteRecheck(recheck time.Duration) WebsiteCheckServiceOption {
+	return func(s *WebsiteCheckService) {
+		if recheck > 0 {
+			s.recheck = recheck
+		}
+	}
+}
+
+// NewWebsiteCheckService builds a new WebsiteCheckService instance. Websites are requested through a
+// client refusing internal addresses, so a scraped website cannot make the API probe its network.
+func NewWebsiteCheckService(repo repository.WebsiteCheckRepository, opts ...WebsiteCheckServiceOption) *WebsiteCheckService {
+	s := &WebsiteCheckService{
+		repo:        repo,
+		client:      websiteCheckClient(outbound.New(), defaultWebsiteCheckTimeout),
+		batchSize:   defaultWebsiteCheckBatch,
+		concurrency: defaultWebsiteCheckConcurrency,
+		recheck:     defaultWebsiteRecheck,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// websiteCheckClient follows redirects within the policy; past its cap, or towards an address it
+// refuses, the redirect response itself is classified.
+func websiteCheckClient(policy *outbound.Policy, timeout time.Duration) *http.Client {
+	client := policy.Client(timeout)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := policy.CheckRedirect(req, via); err != nil {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	return client
+}
+
+// Run checks a batch of due websites every interval until ctx is cancelled.
+func (s *WebsiteCheckService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CheckDue(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to check websites: %v", err)
+			}
+		}
+	}
+}
+
+// CheckDue checks the websites never checked or checked longer than the recheck period ago, one
+// batch at a time, and returns how many checks were stored.
+func (s *WebsiteCheckService) CheckDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ListWebsitesDue(ctx, s.now().Add(-s.recheck), s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var stored atomic.Int64
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(s.concurrency)
+	for _, website := range due {
+		group.Go(func() error {
+			check, err := s.Check(groupCtx, website.Website)
+			if err != nil {
+				return err
+			}
+			if err := s.repo.SaveWebsiteCheck(groupCtx, website, check); err != nil {
+				log.Printf("failed to save website check of company %s: %v", website.CompanyID, err)
+				return nil
+			}
+			stored.Add(1)
+			return nil
+		})
+	}
+	err = group.Wait()
+	return int(stored.Load()), err
+}
+
+// Check requests a website and classifies the answer. Websites that are not URLs, cannot be reached
+// or answer 404, 410 or a server error are dead. The only error returned is ctx's, when it ends
+// before the check does.
+func (s *WebsiteCheckService) Check(ctx context.Context, website string) (entity.WebsiteCheck, error) {
+	check := entity.WebsiteCheck{Status: entity.WebsiteDead, CheckedAt: s.now()}
+	target, err := sanitizeURL(website)
+	if err != nil {
+		return check, nil
+	}
+
+	resp, err := s.get(ctx, target)
+	if err != nil && ctx.Err() == nil {
+		// Websites are stored as https, but small sites often serve plain http only.
+		insecure := *target
+		insecure.Scheme = "http"
+		resp, err = s.get(ctx, &insecure)
+	}
+	if err != nil {
+		return check, ctx.Err()
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, websiteCheckBodyLimit))
+
+	status := resp.StatusCode
+	check.HTTPStatus = &status
+	final := resp.Request.URL
+	if isRedirectStatus(status) {
+		location, err := resp.Location()
+		if err != nil {
+			return check, nil
+		}
+		final = location
+	}
+	check.Status = classifyWebsite(target, final, status, body)
+	if check.Status == entity.WebsiteRedirected {
+		redirect := final.String()
+		check.RedirectsTo = normalizedCompanyWebsite(&redirect)
+	}
+	return check, nil
+}
+
+func (s *WebsiteCheckService) get(ctx context.Context, target *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", websiteCheckUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	return s.client.Do(req)
+}
+
+// classifyWebsite tells the status of a website requested at target from its last answer: the
+// status, the URL it came from, or the one a redirect left unfollowed points to, and the page.
+func classifyWebsite(target, final *url.URL, status int, body []byte) string {
+	switch {
+	case isRedirectStatus(status):
+		// Redirects past the cap are only followed off-site; a loop on the site itself is dead.
+		if isParkingHost(final.Hostname()) {
+			return entity.WebsiteParked
+		}
+		if siteOf(final) != siteOf(target) {
+			return entity.WebsiteRedirected
+		}
+		return entity.WebsiteDead
+	case status == http.StatusNotFound || status == http.StatusGone || status >= http.StatusInternalServerError:
+		return entity.WebsiteDead
+	case isParkingHost(final.Hostname()) || isParkingPage(body):
+		return entity.WebsiteParked
+	case siteOf(final) != siteOf(target):
+		return entity.WebsiteRedirected
+	default:
+		// Other client errors, such as 403 from bot protection, still come from a running site.
+		return entity.WebsiteLive
+	}
+}
+
+func isRedirectStatus(status int) bool {
+	return status >= 300 && status < 400 && status != http.StatusNotModified
+}
+
+// siteOf returns the registrable domain of a URL, so moving from www.example.com to
+// shop.example.com is not a redirect to another site.
+func siteOf(u *url.URL) string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if site, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return site
+	}
+	return host
+}
+
+func isParkingHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, parking := range parkingHosts {
+		if host == parking || strings.HasSuffix(host, "."+parking) {
+			return true
+		}
+	}
+	return false
+}
+
+func isParkingPage(body []byte) bool {
+	page := bytes.ToLower(body)
+	for _, marker := range parkingMarkers {
+		if bytes.Contains(page, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/outbound"
+)
+
+type stubWebsiteCheckRepo struct {
+	due []entity.CompanyWebsite
+
+	mu     sync.Mutex
+	before time.Time
+	saved  map[uuid.UUID]entity.WebsiteCheck
+}
+
+func (s *stubWebsiteCheckRepo) ListWebsitesDue(ctx context.Context, checkedBefore time.Time, limit int) ([]entity.CompanyWebsite, error) {
+	s.before = checkedBefore
+	if len(s.due) > limit {
+		return s.due[:limit], nil
+	}
+	return s.due, nil
+}
+
+func (s *stubWebsiteCheckRepo) SaveWebsiteCheck(ctx context.Context, website entity.CompanyWebsite, check entity.WebsiteCheck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saved == nil {
+		s.saved = make(map[uuid.UUID]entity.WebsiteCheck)
+	}
+	s.saved[website.CompanyID] = check
+	return nil
+}
+
+func TestClassifyWebsite(t *testing.T) {
+	parse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", raw, err)
+		}
+		return u
+	}
+	cases := []struct {
+		name   string
+		final  string
+		status int
+		body   string
+		want   string
+	}{
+		{"live", "https://www.kopi.co.id/", 200, "<h1>Kopi</h1>", entity.WebsiteLive},
+		{"bot protection", "https://www.kopi.co.id/", 403, "", entity.WebsiteLive},
+		{"same site", "https://shop.kopi.co.id/", 200, "", entity.WebsiteLive},
+		{"not found", "https://www.kopi.co.id/", 404, "", entity.WebsiteDead},
+		{"server error", "https://www.kopi.co.id/", 503, "", entity.WebsiteDead},
+		{"for sale", "https://www.kopi.co.id/", 200, "<p>This Domain Is For Sale!</p>", entity.WebsiteParked},
+		{"parking service", "https://sedo.com/search/details/?domain=kopi.co.id", 200, "", entity.WebsiteParked},
+		{"other site", "https://kopikenangan.com/", 200, "", entity.WebsiteRedirected},
+		{"redirect cap off-site", "https://kopikenangan.com/", 301, "", entity.WebsiteRedirected},
+		{"redirect loop", "https://www.kopi.co.id/again", 302, "", entity.WebsiteDead},
+	}
+	for _, tc := range cases {
+		got := classifyWebsite(parse("https://www.kopi.co.id"), parse(tc.final), tc.status, []byte(tc.body))
+		if got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestWebsiteCheckService_CheckDue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+		case "/gone":
+			http.Error(w, "gone", http.StatusGone)
+		case "/parked":
+			w.Write([]byte("<html><body>Buy this domain today</body></html>"))
+		default:
+			w.Write([]byte("<html><body>Welcome</body></html>"))
+		}
+	}))
+	defer server.Close()
+	// Websites are stored as https; the checker falls back to http for the plain test server.
+	site := "https://" + server.Listener.Addr().String()
+
+	live, moved, gone, parked, invalid := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := &stubWebsiteCheckRepo{due: []entity.CompanyWebsite{
+		{CompanyID: live, Website: site},
+		{CompanyID: moved, Website: site + "/moved"},
+		{CompanyID: gone, Website: site + "/gone"},
+		{CompanyID: parked, Website: site + "/parked"},
+		{CompanyID: invalid, Website: "http://"},
+	}}
+	now := time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)
+	svc := NewWebsiteCheckService(repo,
+		WithWebsiteCheckPolicy(outbound.New(outbound.WithAllow("127.0.0.1")), time.Second),
+		WithWebsiteCheckBatch(10, 2),
+		WithWebsiteRecheck(24*time.Hour),
+	)
+	svc.now = func() time.Time { return now }
+
+	stored, err := svc.CheckDue(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored != 5 || !repo.before.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("expected 5 checks of websites checked before %s, got %d before %s", now.Add(-24*time.Hour), stored, repo.before)
+	}
+	want := map[uuid.UUID]string{
+		live:    entity.WebsiteLive,
+		moved:   entity.WebsiteLive,
+		gone:    entity.WebsiteDead,
+		parked:  entity.WebsiteParked,
+		invalid: entity.WebsiteDead,
+	}
+	for id, status := range want {
+		check := repo.saved[id]
+		if check.Status != status || !check.CheckedAt.Equal(now) {
+			t.Fatalf("expected %s for %s, got %+v", status, id, check)
+		}
+	}
+	if code := repo.saved[gone].HTTPStatus; code == nil || *code != http.StatusGone {
+		t.Fatalf("expected the 410 to be stored, got %v", code)
+	}
+	if code := repo.saved[invalid].HTTPStatus; code != nil {
+		t.Fatalf("expected no status for a website that was not requested, got %d", *code)
+	}
+}
+
+func TestNormalizeWebsiteStatus(t *testing.T) {
+	if status, ok := NormalizeWebsiteStatus(" Parked "); !ok || status != entity.WebsiteParked {
+		t.Fatalf("expected parked, got %q %v", status, ok)
+	}
+	if _, ok := NormalizeWebsiteStatus("redirects_to"); ok {
+		t.Fatalf("expected an unknown status to be rejected")
+	}
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+// BigQueryRow is one row streamed into a table. InsertID lets BigQuery drop a row it already
+// received when a batch is retried shortly after.
+type BigQueryRow struct {
+	InsertID string
+	Values   map[string]any
+}
+
+// BigQueryTable streams rows into a BigQuery table using application default credentials.
+type BigQueryTable struct {
+	project   string
+	dataset   string
+	table     string
+	tabledata *bigquery.TabledataService
+}
+
+// NewBigQueryTable builds a streamer for project.dataset.table; the table must exist.
+func NewBigQueryTable(ctx context.Context, project, dataset, table string) (*BigQueryTable, error) {
+	svc, err := bigquery.NewService(ctx, option.WithScopes(bigquery.BigqueryInsertdataScope))
+	if err != nil {
+		return nil, fmt.Errorf("create bigquery client: %w", err)
+	}
+	return &BigQueryTable{project: project, dataset: dataset, table: table, tabledata: svc.Tabledata}, nil
+}
+
+// Insert streams rows with tabledata.insertAll. BigQuery accepts or refuses each row on its own, so
+// any refused row fails the whole call and the caller is expected to retry the batch.
+func (t *BigQueryTable) Insert(ctx context.Context, rows []BigQueryRow) error {
+	req := &bigquery.TableDataInsertAllRequest{Rows: make([]*bigquery.TableDataInsertAllRequestRows, 0, len(rows))}
+	for _, row := range rows {
+		values := make(map[string]bigquery.JsonValue, len(row.Values))
+		for name, value := range row.Values {
+			values[name] = value
+		}
+		req.Rows = append(req.Rows, &bigquery.TableDataInsertAllRequestRows{InsertId: row.InsertID, Json: values})
+	}
+	resp, err := t.tabledata.InsertAll(t.project, t.dataset, t.table, req).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("insert into %s: %w", t.name(), err)
+	}
+	for _, insertErr := range resp.InsertErrors {
+		for _, reason := range insertErr.Errors {
+			// Valid rows of a batch with an invalid one are refused as "stopped"; report the cause.
+			if reason.Reason != "stopped" {
+				return fmt.Errorf("insert into %s: row %d: %s: %s", t.name(), insertErr.Index, reason.Reason, reason.Message)
+			}
+		}
+	}
+	if len(resp.InsertErrors) > 0 {
+		return fmt.Errorf("insert into %s: %d rows refused", t.name(), len(resp.InsertErrors))
+	}
+	return nil
+}
+
+func (t *BigQueryTable) name() string {
+	return t.project + "." + t.dataset + "." + t.table
+}
@@ -0,0 +1,80 @@
+// Package storage uploads export artifacts and retained files to object storage.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	gcs "google.golang.org/api/storage/v1"
+)
+
+// GCSStore writes objects to a Google Cloud Storage bucket using application default credentials.
+type GCSStore struct {
+	bucket  string
+	objects *gcs.ObjectsService
+}
+
+// NewGCSStore builds a store for the given bucket.
+func NewGCSStore(ctx context.Context, bucket string) (*GCSStore, error) {
+	svc, err := gcs.NewService(ctx, option.WithScopes(gcs.DevstorageReadWriteScope))
+	if err != nil {
+		return nil, fmt.Errorf("create gcs client: %w", err)
+	}
+	return &GCSStore{bucket: bucket, objects: svc.Objects}, nil
+}
+
+// Upload streams r into the named object, replacing any existing object.
+func (s *GCSStore) Upload(ctx context.Context, name, contentType string, r io.Reader) error {
+	object := &gcs.Object{Name: name, ContentType: contentType}
+	if _, err := s.objects.Insert(s.bucket, object).Media(r, googleapi.ContentType(contentType)).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("upload gs://%s/%s: %w", s.bucket, name, err)
+	}
+	return nil
+}
+
+// Open streams the named object; callers must close the reader.
+func (s *GCSStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.objects.Get(s.bucket, name).Context(ctx).Download()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("download gs://%s/%s: %w", s.bucket, name, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the named object; a missing object is not an error.
+func (s *GCSStore) Delete(ctx context.Context, name string) error {
+	if err := s.objects.Delete(s.bucket, name).Context(ctx).Do(); err != nil && !isNotFound(err) {
+		return fmt.Errorf("delete gs://%s/%s: %w", s.bucket, name, err)
+	}
+	return nil
+}
+
+// Stat returns the size and content type of the named object.
+func (s *GCSStore) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	object, err := s.objects.Get(s.bucket, name).Context(ctx).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return ObjectInfo{}, ErrObjectNotFound
+		}
+		return ObjectInfo{}, fmt.Errorf("stat gs://%s/%s: %w", s.bucket, name, err)
+	}
+	return ObjectInfo{Size: int64(object.Size), ContentType: object.ContentType}, nil
+}
+
+// URI returns the gs:// location of an object name.
+func (s *GCSStore) URI(name string) string {
+	return fmt.Sprintf("gs://%s/%s", s.bucket, name)
+}
+
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrObjectNotFound is returned when a stored object does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// LocalStore keeps objects as files under a root directory, for development and single-node setups.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore builds a store rooted at dir, creating the directory when missing.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create storage dir: %w", err)
+	}
+	return &LocalStore{root: dir}, nil
+}
+
+// Upload writes r to the named file, replacing any existing file.
+func (s *LocalStore) Upload(ctx context.Context, name, contentType string, r io.Reader) error {
+	target := s.path(name)
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(target), err)
+	}
+	// Write to a temporary file first so readers never see a partial object.
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", target, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", target, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("store %s: %w", target, err)
+	}
+	return nil
+}
+
+// Open returns the named file; callers must close the reader.
+func (s *LocalStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// Delete removes the named file; a missing file is not an error.
+func (s *LocalStore) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(s.path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete %s: %w", name, err)
+	}
+	return nil
+}
+
+// URI returns the file:// location of an object name.
+func (s *LocalStore) URI(name string) string {
+	return "file://" + filepath.ToSlash(s.path(name))
+}
+
+// path maps an object name onto the root, so names cannot escape it with "..".
+func (s *LocalStore) path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+name)))
+}
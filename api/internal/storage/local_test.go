@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(root, "archive"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Upload(ctx, "uploads/admin/a.csv", "text/csv", strings.NewReader("company,address\n")); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	file, err := store.Open(ctx, "uploads/admin/a.csv")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "company,address\n" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	// Names cannot escape the root directory.
+	if err := store.Upload(ctx, "../../escape.csv", "text/csv", strings.NewReader("x")); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "archive", "escape.csv")); err != nil {
+		t.Fatalf("expected object kept under the root: %v", err)
+	}
+
+	if err := store.Delete(ctx, "uploads/admin/a.csv"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := store.Delete(ctx, "uploads/admin/a.csv"); err != nil {
+		t.Fatalf("expected deleting a missing object to succeed, got %v", err)
+	}
+	if _, err := store.Open(ctx, "uploads/admin/a.csv"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config locates an S3 bucket, or a bucket of an S3 compatible service such as MinIO.
+type S3Config struct {
+	Bucket string
+	Region string
+	// Endpoint overrides https://s3.<region>.amazonaws.com for S3 compatible services.
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// S3Store writes objects to an S3 bucket with path-style requests signed with AWS Signature V4.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store builds a store for the configured bucket.
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, fmt.Errorf("s3 bucket and region are required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 access key id and secret access key are required")
+	}
+	raw := cfg.Endpoint
+	if raw == "" {
+		raw = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", raw)
+	}
+	return &S3Store{cfg: cfg, endpoint: endpoint, client: &http.Client{Timeout: time.Minute}, now: time.Now}, nil
+}
+
+// Upload stores r as the named object, replacing any existing object. The body is buffered to
+// sign it, so this store suits payloads that fit in memory.
+func (s *S3Store) Upload(ctx context.Context, name, contentType string, r io.Reader) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read upload body: %w", err)
+	}
+	resp, err := s.do(ctx, http.MethodPut, name, body, contentType)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", s.URI(name), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upload %s: %s", s.URI(name), s3Error(resp))
+	}
+	return nil
+}
+
+// Open streams the named object; callers must close the reader.
+func (s *S3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", s.URI(name), err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("download %s: %s", s.URI(name), s3Error(resp))
+	}
+}
+
+// Delete removes the named object; a missing object is not an error.
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, name, nil, "")
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", s.URI(name), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("delete %s: %s", s.URI(name), s3Error(resp))
+	}
+	return nil
+}
+
+// Stat returns the size and content type of the named object.
+func (s *S3Store) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, name, nil, "")
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("stat %s: %w", s.URI(name), err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+		return ObjectInfo{Size: size, ContentType: resp.Header.Get("Content-Type")}, nil
+	case http.StatusNotFound:
+		return ObjectInfo{}, ErrObjectNotFound
+	default:
+		return ObjectInfo{}, fmt.Errorf("stat %s: %s", s.URI(name), resp.Status)
+	}
+}
+
+// URI returns the s3:// location of an object name.
+func (s *S3Store) URI(name string) string {
+	return fmt.Sprintf("s3://%s/%s", s.cfg.Bucket, name)
+}
+
+func (s *S3Store) do(ctx context.Context, method, name string, body []byte, contentType string) (*http.Response, error) {
+	target := *s.endpoint
+	target.Path = s.endpoint.Path + "/" + s.cfg.Bucket + "/" + strings.TrimLeft(name, "/")
+	target.RawPath = s.endpoint.Path + "/" + s3EscapePath(s.cfg.Bucket+"/"+strings.TrimLeft(name, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body)
+	return s.client.Do(req)
+}
+
+// sign adds the AWS Signature V4 headers to req.
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.cfg.SessionToken != "" {
+		signed = append(signed, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, header := range signed {
+		value := req.Header.Get(header)
+		if header == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(header + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), day)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3EscapePath escapes every byte outside the unreserved set, keeping the slashes between segments.
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func s3Error(resp *http.Response) string {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if len(detail) == 0 {
+		return resp.Status
+	}
+	return resp.Status + ": " + strings.TrimSpace(string(detail))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestS3Store(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = map[string]string{}
+		paths   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20250102/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+			http.Error(w, "bad authorization "+auth, http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) || r.Header.Get("X-Amz-Date") != "20250102T030405Z" {
+			http.Error(w, "bad payload hash", http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.EscapedPath())
+		key := r.URL.Path
+		switch r.Method {
+		case http.MethodPut:
+			objects[key] = string(body)
+		case http.MethodGet:
+			object, ok := objects[key]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			io.WriteString(w, object)
+		case http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Bucket: "leads", Region: "eu-west-1", Endpoint: server.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	store.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	ctx := context.Background()
+
+	if err := store.Upload(ctx, "raw/ChIJ+abc/run 1.json", "application/json", strings.NewReader(`{"a":1}`)); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	file, err := store.Open(ctx, "raw/ChIJ+abc/run 1.json")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != `{"a":1}` {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if paths[0] != "/leads/raw/ChIJ%2Babc/run%201.json" {
+		t.Fatalf("unexpected escaped path %q", paths[0])
+	}
+
+	if err := store.Delete(ctx, "raw/ChIJ+abc/run 1.json"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Open(ctx, "raw/ChIJ+abc/run 1.json"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound, got %v", err)
+	}
+	if got := store.URI("raw/x.json"); got != "s3://leads/raw/x.json" {
+		t.Fatalf("unexpected uri %q", got)
+	}
+}
+
+func TestNewS3StoreValidation(t *testing.T) {
+	if _, err := NewS3Store(S3Config{Bucket: "leads", Region: "eu-west-1"}); err == nil {
+		t.Fatal("expected missing credentials to be rejected")
+	}
+	if _, err := NewS3Store(S3Config{Bucket: "leads", Region: "eu-west-1", Endpoint: "minio:9000", AccessKeyID: "a", SecretAccessKey: "b"}); err == nil {
+		t.Fatal("expected an endpoint without scheme to be rejected")
+	}
+}
@@ -0,0 +1,193 @@
+// Package testsupport holds builders, stub repositories and echo helpers shared by handler and
+// service tests. It must only be imported from _test.go files.
+package testsupport
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+)
+
+// FixedTime is the timestamp builders use so assertions and ETags stay deterministic.
+var FixedTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// Ptr returns a pointer to value, handy for optional entity fields.
+func Ptr[T any](value T) *T {
+	return &value
+}
+
+// CompanyBuilder assembles entity.Company values with sensible defaults.
+type CompanyBuilder struct {
+	company entity.Company
+}
+
+// NewCompany starts a company named Acme in Jakarta, Indonesia.
+func NewCompany() *CompanyBuilder {
+	return &CompanyBuilder{company: entity.Company{
+		ID:        uuid.New(),
+		Company:   "Acme",
+		City:      Ptr("Jakarta"),
+		Country:   Ptr("Indonesia"),
+		CreatedAt: FixedTime,
+		UpdatedAt: FixedTime,
+	}}
+}
+
+// WithID sets the company id.
+func (b *CompanyBuilder) WithID(id uuid.UUID) *CompanyBuilder {
+	b.company.ID = id
+	return b
+}
+
+// WithName sets the company name.
+func (b *CompanyBuilder) WithName(name string) *CompanyBuilder {
+	b.company.Company = name
+	return b
+}
+
+// WithLocation sets the city and country.
+func (b *CompanyBuilder) WithLocation(city, country string) *CompanyBuilder {
+	b.company.City = Ptr(city)
+	b.company.Country = Ptr(country)
+	return b
+}
+
+// WithTypeBusiness sets the business type.
+func (b *CompanyBuilder) WithTypeBusiness(typeBusiness string) *CompanyBuilder {
+	b.company.TypeBusiness = Ptr(typeBusiness)
+	return b
+}
+
+// WithContact sets the phone and website; empty values stay unset.
+func (b *CompanyBuilder) WithContact(phone, website string) *CompanyBuilder {
+	b.company.Phone = nil
+	b.company.Website = nil
+	if phone != "" {
+		b.company.Phone = Ptr(phone)
+	}
+	if website != "" {
+		b.company.Website = Ptr(website)
+	}
+	return b
+}
+
+// WithRating sets the rating and review count.
+func (b *CompanyBuilder) WithRating(rating float64, reviews int) *CompanyBuilder {
+	b.company.Rating = Ptr(rating)
+	b.company.Reviews = Ptr(reviews)
+	return b
+}
+
+// WithRaw sets the raw Places payload.
+func (b *CompanyBuilder) WithRaw(raw string) *CompanyBuilder {
+	b.company.Raw = json.RawMessage(raw)
+	return b
+}
+
+// Build returns a copy of the assembled company.
+func (b *CompanyBuilder) Build() entity.Company {
+	return b.company
+}
+
+// BuildPtr returns a pointer to a copy of the assembled company.
+func (b *CompanyBuilder) BuildPtr() *entity.Company {
+	company := b.company
+	return &company
+}
+
+// EnrichmentBuilder assembles entity.CompanyEnrichment values with sensible defaults.
+type EnrichmentBuilder struct {
+	enrichment entity.CompanyEnrichment
+}
+
+// NewEnrichment starts an enrichment with one email and phone for the given company.
+func NewEnrichment(companyID uuid.UUID) *EnrichmentBuilder {
+	return &EnrichmentBuilder{enrichment: entity.CompanyEnrichment{
+		CompanyID: companyID,
+		Emails:    []string{"info@example.com"},
+		Phones:    []string{"+6221555000"},
+		Socials:   map[string][]string{},
+		Metadata:  map[string]any{},
+		CreatedAt: FixedTime,
+		UpdatedAt: FixedTime,
+	}}
+}
+
+// WithEmails replaces the emails.
+func (b *EnrichmentBuilder) WithEmails(emails ...string) *EnrichmentBuilder {
+	b.enrichment.Emails = emails
+	return b
+}
+
+// WithPhones replaces the phones.
+func (b *EnrichmentBuilder) WithPhones(phones ...string) *EnrichmentBuilder {
+	b.enrichment.Phones = phones
+	return b
+}
+
+// WithSocial adds profile URLs for a platform.
+func (b *EnrichmentBuilder) WithSocial(platform string, urls ...string) *EnrichmentBuilder {
+	b.enrichment.Socials[platform] = append(b.enrichment.Socials[platform], urls...)
+	return b
+}
+
+// WithMetadata sets one metadata key.
+func (b *EnrichmentBuilder) WithMetadata(key string, value any) *EnrichmentBuilder {
+	b.enrichment.Metadata[key] = value
+	return b
+}
+
+// Build returns a pointer to the assembled enrichment; repositories hand enrichments out as pointers.
+func (b *EnrichmentBuilder) Build() *entity.CompanyEnrichment {
+	enrichment := b.enrichment
+	return &enrichment
+}
+
+// UserBuilder assembles entity.User values with sensible defaults.
+type UserBuilder struct {
+	user entity.User
+}
+
+// NewUser starts a regular user.
+func NewUser() *UserBuilder {
+	return &UserBuilder{user: entity.User{
+		ID:           uuid.New(),
+		Email:        "user@example.com",
+		PasswordHash: "hash",
+		Role:         "user",
+		CreatedAt:    FixedTime,
+		UpdatedAt:    FixedTime,
+	}}
+}
+
+// WithEmail sets the email.
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+// WithRole sets the role.
+func (b *UserBuilder) WithRole(role string) *UserBuilder {
+	b.user.Role = role
+	return b
+}
+
+// WithPasswordHash sets the stored password hash.
+func (b *UserBuilder) WithPasswordHash(hash string) *UserBuilder {
+	b.user.PasswordHash = hash
+	return b
+}
+
+// Admin is shorthand for WithRole("admin").
+func (b *UserBuilder) Admin() *UserBuilder {
+	return b.WithRole("admin")
+}
+
+// Build returns a pointer to the assembled user.
+func (b *UserBuilder) Build() *entity.User {
+	user := b.user
+	return &user
+}
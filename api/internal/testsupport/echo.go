@@ -0,0 +1,97 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/octobees/leads-generator/api/internal/entity"
+	middlewarepkg "github.com/octobees/leads-generator/api/internal/middleware"
+)
+
+// Envelope mirrors the handler response envelope with the data left undecoded.
+type Envelope struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewContext builds an echo context for a request without a body.
+func NewContext(method, target string) (echo.Context, *httptest.ResponseRecorder) {
+	return NewContextWithBody(method, target, nil)
+}
+
+// NewContextWithBody builds an echo context for a request with the given body.
+func NewContextWithBody(method, target string, body io.Reader) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, body)
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+// NewJSONContext builds an echo context whose body is payload encoded as JSON.
+func NewJSONContext(t testing.TB, method, target string, payload any) (echo.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("encode request body: %v", err)
+	}
+	c, rec := NewContextWithBody(method, target, bytes.NewReader(body))
+	c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return c, rec
+}
+
+// WithParams sets route params given as name, value pairs and returns the context.
+func WithParams(c echo.Context, pairs ...string) echo.Context {
+	if len(pairs)%2 != 0 {
+		panic("testsupport: WithParams needs name/value pairs")
+	}
+	names := make([]string, 0, len(pairs)/2)
+	values := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		names = append(names, pairs[i])
+		values = append(values, pairs[i+1])
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	return c
+}
+
+// WithUser stores user's claims in the context the way the JWT middleware does.
+func WithUser(c echo.Context, user *entity.User) echo.Context {
+	c.Set(middlewarepkg.ContextKeyUserID, user.ID.String())
+	c.Set(middlewarepkg.ContextKeyUserEmail, user.Email)
+	c.Set(middlewarepkg.ContextKeyUserRole, user.Role)
+	return c
+}
+
+// DecodeEnvelope decodes the response envelope, failing the test when the body is not one.
+func DecodeEnvelope(t testing.TB, rec *httptest.ResponseRecorder) Envelope {
+	t.Helper()
+	var envelope Envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode response envelope: %v (body %q)", err, rec.Body.String())
+	}
+	return envelope
+}
+
+// DecodeData decodes the envelope's data into dst.
+func DecodeData(t testing.TB, rec *httptest.ResponseRecorder, dst any) Envelope {
+	t.Helper()
+	envelope := DecodeEnvelope(t, rec)
+	if err := json.Unmarshal(envelope.Data, dst); err != nil {
+		t.Fatalf("decode response data: %v (data %s)", err, envelope.Data)
+	}
+	return envelope
+}
+
+// AssertStatus fails the test when the recorded status differs from want.
+func AssertStatus(t testing.TB, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("expected status %d, got %d (body %q)", want, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,426 @@
+package testsupport
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/entity"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+var (
+	_ repository.CompaniesRepository    = (*StubCompaniesRepository)(nil)
+	_ repository.UsersRepository        = (*MemoryUsersRepository)(nil)
+	_ repository.UserSessionsRepository = (*MemoryUserSessionsRepository)(nil)
+)
+
+// StubCompaniesRepository is an in-memory repository.CompaniesRepository. It serves Companies and
+// Enrichments, records what it was asked, and lets tests override any method through the *Func
+// fields or force a failure through Err.
+type StubCompaniesRepository struct {
+	mu sync.Mutex
+
+	Companies   []entity.Company
+	Enrichments map[uuid.UUID]*entity.CompanyEnrichment
+	Contacts    map[uuid.UUID]*entity.WebsiteEnrichedContact
+	// Err, when set, is returned by every method that has no override.
+	Err error
+
+	ListFunc                func(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error)
+	BulkUpsertCompaniesFunc func(ctx context.Context, records []repository.BulkUpsertCompanyInput) (repository.BulkUpsertResult, error)
+
+	LastFilter   dto.ListFilter
+	ListCalls    int
+	BulkRecords  []repository.BulkUpsertCompanyInput
+	BulkMode     repository.ImportMode
+	UpsertedRows []entity.Company
+}
+
+// NewStubCompaniesRepository returns a stub serving the given companies.
+func NewStubCompaniesRepository(companies ...entity.Company) *StubCompaniesRepository {
+	return &StubCompaniesRepository{
+		Companies:   companies,
+		Enrichments: make(map[uuid.UUID]*entity.CompanyEnrichment),
+		Contacts:    make(map[uuid.UUID]*entity.WebsiteEnrichedContact),
+	}
+}
+
+// Upsert records the company.
+func (s *StubCompaniesRepository) Upsert(ctx context.Context, company *entity.Company) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.UpsertedRows = append(s.UpsertedRows, *company)
+	return nil
+}
+
+// List records the filter and returns Companies unfiltered.
+func (s *StubCompaniesRepository) List(ctx context.Context, filter dto.ListFilter) ([]entity.Company, error) {
+	s.mu.Lock()
+	s.LastFilter = filter
+	s.ListCalls++
+	s.mu.Unlock()
+	if s.ListFunc != nil {
+		return s.ListFunc(ctx, filter)
+	}
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return append([]entity.Company(nil), s.Companies...), nil
+}
+
+// BulkUpsertCompanies records the records and mode and reports them all as inserted.
+func (s *StubCompaniesRepository) BulkUpsertCompanies(ctx context.Context, records []repository.BulkUpsertCompanyInput, mode repository.ImportMode) (repository.BulkUpsertResult, error) {
+	s.mu.Lock()
+	s.BulkRecords = append(s.BulkRecords, records...)
+	s.BulkMode = mode
+	s.mu.Unlock()
+	if s.BulkUpsertCompaniesFunc != nil {
+		return s.BulkUpsertCompaniesFunc(ctx, records)
+	}
+	if s.Err != nil {
+		return repository.BulkUpsertResult{}, s.Err
+	}
+	result := repository.BulkUpsertResult{Inserted: len(records), Total: len(records)}
+	for range records {
+		id := uuid.New()
+		result.Records = append(result.Records, repository.BulkUpsertRecordResult{CompanyID: &id, Status: repository.BulkUpsertInserted})
+	}
+	return result, nil
+}
+
+// UpsertEnrichment stores the enrichment by company id.
+func (s *StubCompaniesRepository) UpsertEnrichment(ctx context.Context, enrichment *entity.CompanyEnrichment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if s.Enrichments == nil {
+		s.Enrichments = make(map[uuid.UUID]*entity.CompanyEnrichment)
+	}
+	stored := *enrichment
+	s.Enrichments[enrichment.CompanyID] = &stored
+	return nil
+}
+
+// GetEnrichment returns the stored enrichment or repository.ErrEnrichmentNotFound.
+func (s *StubCompaniesRepository) GetEnrichment(ctx context.Context, companyID uuid.UUID) (*entity.CompanyEnrichment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	enrichment, ok := s.Enrichments[companyID]
+	if !ok {
+		return nil, repository.ErrEnrichmentNotFound
+	}
+	return enrichment, nil
+}
+
+// UpsertEnrichedContacts stores the contacts by company id.
+func (s *StubCompaniesRepository) UpsertEnrichedContacts(ctx context.Context, contact *entity.WebsiteEnrichedContact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if s.Contacts == nil {
+		s.Contacts = make(map[uuid.UUID]*entity.WebsiteEnrichedContact)
+	}
+	stored := *contact
+	s.Contacts[contact.CompanyID] = &stored
+	return nil
+}
+
+// GetByCompanyID returns the stored contacts or repository.ErrEnrichmentNotFound.
+func (s *StubCompaniesRepository) GetByCompanyID(ctx context.Context, companyID uuid.UUID) (*entity.WebsiteEnrichedContact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	contact, ok := s.Contacts[companyID]
+	if !ok {
+		return nil, repository.ErrEnrichmentNotFound
+	}
+	return contact, nil
+}
+
+// MemoryUsersRepository is an in-memory repository.UsersRepository with the same not-found and
+// duplicate-email semantics as the pgx implementation.
+type MemoryUsersRepository struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*entity.User
+	// Err, when set, is returned by every method.
+	Err error
+}
+
+// NewMemoryUsersRepository returns a repository seeded with users.
+func NewMemoryUsersRepository(users ...*entity.User) *MemoryUsersRepository {
+	repo := &MemoryUsersRepository{users: make(map[uuid.UUID]*entity.User)}
+	for _, user := range users {
+		stored := *user
+		repo.users[user.ID] = &stored
+	}
+	return repo
+}
+
+// FindByEmail looks a user up case-insensitively.
+func (r *MemoryUsersRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+// FindByID looks a user up by id.
+func (r *MemoryUsersRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+// Create stores a new user, rejecting duplicate emails.
+func (r *MemoryUsersRepository) Create(ctx context.Context, email, passwordHash, role string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return nil, repository.ErrEmailDuplicate
+		}
+	}
+	user := &entity.User{
+		ID:           uuid.New(),
+		Email:        email,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	r.users[user.ID] = user
+	created := *user
+	return &created, nil
+}
+
+// List returns the users matching filter after its cursor, ordered like the pgx implementation.
+func (r *MemoryUsersRepository) List(ctx context.Context, filter dto.UserListFilter) ([]entity.UserSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	users := make([]entity.UserSummary, 0, len(r.users))
+	for _, user := range r.users {
+		summary := entity.UserSummary{ID: user.ID, Email: user.Email, Role: user.Role, CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt}
+		if matchesUserFilter(summary, filter) && (filter.After == nil || userAfter(summary, *filter.After, filter)) {
+			users = append(users, summary)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return userAfter(users[j], entity.UserCursor{CreatedAt: users[i].CreatedAt, Email: users[i].Email, ID: users[i].ID}, filter)
+	})
+	if filter.Limit > 0 && len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+	return users, nil
+}
+
+// Count returns how many users match filter, ignoring its cursor and limit.
+func (r *MemoryUsersRepository) Count(ctx context.Context, filter dto.UserListFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return 0, r.Err
+	}
+	var total int64
+	for _, user := range r.users {
+		if matchesUserFilter(entity.UserSummary{Email: user.Email, Role: user.Role}, filter) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// Update applies the non-nil fields to an existing user.
+func (r *MemoryUsersRepository) Update(ctx context.Context, id uuid.UUID, email, passwordHash, role *string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	if email != nil {
+		for otherID, other := range r.users {
+			if otherID != id && strings.EqualFold(other.Email, *email) {
+				return nil, repository.ErrEmailDuplicate
+			}
+		}
+		user.Email = *email
+	}
+	if passwordHash != nil {
+		user.PasswordHash = *passwordHash
+	}
+	if role != nil {
+		user.Role = *role
+	}
+	user.UpdatedAt = time.Now()
+	updated := *user
+	return &updated, nil
+}
+
+// Delete removes a user.
+func (r *MemoryUsersRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	if _, ok := r.users[id]; !ok {
+		return repository.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func matchesUserFilter(user entity.UserSummary, filter dto.UserListFilter) bool {
+	if filter.Email != "" && !strings.Contains(strings.ToLower(user.Email), strings.ToLower(filter.Email)) {
+		return false
+	}
+	return filter.Role == "" || user.Role == filter.Role
+}
+
+// userAfter reports whether user sorts after cursor in the filter's order.
+func userAfter(user entity.UserSummary, cursor entity.UserCursor, filter dto.UserListFilter) bool {
+	cmp := user.CreatedAt.Compare(cursor.CreatedAt)
+	if filter.Sort == dto.UserSortEmail {
+		cmp = strings.Compare(user.Email, cursor.Email)
+	}
+	if cmp == 0 {
+		cmp = strings.Compare(user.ID.String(), cursor.ID.String())
+	}
+	if filter.Desc {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+// MemoryUserSessionsRepository is an in-memory repository.UserSessionsRepository keyed by token
+// hash, with the same active-session semantics as the pgx implementation.
+type MemoryUserSessionsRepository struct {
+	mu       sync.Mutex
+	sessions map[string]*memorySession
+	now      func() time.Time
+}
+
+type memorySession struct {
+	session entity.UserSession
+	revoked bool
+}
+
+// NewMemoryUserSessionsRepository returns an empty sessions repository.
+func NewMemoryUserSessionsRepository() *MemoryUserSessionsRepository {
+	return &MemoryUserSessionsRepository{sessions: make(map[string]*memorySession), now: time.Now}
+}
+
+// CreateSession stores session under tokenHash.
+func (r *MemoryUserSessionsRepository) CreateSession(ctx context.Context, session *entity.UserSession, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.ID = uuid.New()
+	session.CreatedAt = r.now()
+	session.LastUsedAt = session.CreatedAt
+	r.sessions[tokenHash] = &memorySession{session: *session}
+	return nil
+}
+
+// RotateSession moves the active session of tokenHash to newTokenHash.
+func (r *MemoryUserSessionsRepository) RotateSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (*entity.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.sessions[tokenHash]
+	if !ok || !r.active(stored) {
+		return nil, repository.ErrSessionNotFound
+	}
+	delete(r.sessions, tokenHash)
+	stored.session.ExpiresAt = expiresAt
+	stored.session.LastUsedAt = r.now()
+	r.sessions[newTokenHash] = stored
+	session := stored.session
+	return &session, nil
+}
+
+// ListActiveSessions returns the user's active sessions, most recently used first.
+func (r *MemoryUserSessionsRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]entity.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]entity.UserSession, 0)
+	for _, stored := range r.sessions {
+		if stored.session.UserID == userID && r.active(stored) {
+			sessions = append(sessions, stored.session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's active sessions.
+func (r *MemoryUserSessionsRepository) RevokeSession(ctx context.Context, userID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.sessions {
+		if stored.session.ID == id && stored.session.UserID == userID && r.active(stored) {
+			stored.revoked = true
+			return nil
+		}
+	}
+	return repository.ErrSessionNotFound
+}
+
+// RevokeOtherSessions revokes the user's active sessions other than keep.
+func (r *MemoryUserSessionsRepository) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, keep *uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var revoked int64
+	for _, stored := range r.sessions {
+		if stored.session.UserID == userID && !stored.revoked && (keep == nil || stored.session.ID != *keep) {
+			stored.revoked = true
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *MemoryUserSessionsRepository) active(stored *memorySession) bool {
+	return !stored.revoked && stored.session.ExpiresAt.After(r.now())
+}
@@ -0,0 +1,99 @@
+package testsupport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/octobees/leads-generator/api/internal/dto"
+	"github.com/octobees/leads-generator/api/internal/middleware"
+	"github.com/octobees/leads-generator/api/internal/repository"
+)
+
+func TestCompanyBuilder(t *testing.T) {
+	company := NewCompany().WithName("Kopi Kenangan").WithContact("", "https://kopi.id").WithRating(4.6, 120).Build()
+	if company.Company != "Kopi Kenangan" || company.Phone != nil || *company.Website != "https://kopi.id" {
+		t.Fatalf("unexpected company: %+v", company)
+	}
+	if *company.City != "Jakarta" || *company.Reviews != 120 || !company.UpdatedAt.Equal(FixedTime) {
+		t.Fatalf("expected defaults to be kept, got %+v", company)
+	}
+}
+
+func TestStubCompaniesRepository(t *testing.T) {
+	company := NewCompany().Build()
+	repo := NewStubCompaniesRepository(company)
+	ctx := context.Background()
+
+	companies, err := repo.List(ctx, dto.ListFilter{City: "Jakarta"})
+	if err != nil || len(companies) != 1 || repo.LastFilter.City != "Jakarta" || repo.ListCalls != 1 {
+		t.Fatalf("unexpected list result %+v (%v), filter %+v", companies, err, repo.LastFilter)
+	}
+
+	if _, err := repo.GetEnrichment(ctx, company.ID); !errors.Is(err, repository.ErrEnrichmentNotFound) {
+		t.Fatalf("expected ErrEnrichmentNotFound, got %v", err)
+	}
+	if err := repo.UpsertEnrichment(ctx, NewEnrichment(company.ID).WithEmails("sales@acme.id").Build()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	enrichment, err := repo.GetEnrichment(ctx, company.ID)
+	if err != nil || enrichment.Emails[0] != "sales@acme.id" {
+		t.Fatalf("expected stored enrichment, got %+v (%v)", enrichment, err)
+	}
+
+	repo.Err = errors.New("down")
+	if _, err := repo.List(ctx, dto.ListFilter{}); err == nil {
+		t.Fatalf("expected forced error")
+	}
+}
+
+func TestMemoryUsersRepository(t *testing.T) {
+	admin := NewUser().WithEmail("admin@example.com").Admin().Build()
+	repo := NewMemoryUsersRepository(admin)
+	ctx := context.Background()
+
+	found, err := repo.FindByEmail(ctx, "ADMIN@example.com")
+	if err != nil || found.ID != admin.ID || found.Role != "admin" {
+		t.Fatalf("unexpected lookup %+v (%v)", found, err)
+	}
+	if _, err := repo.Create(ctx, "admin@example.com", "hash", "user"); !errors.Is(err, repository.ErrEmailDuplicate) {
+		t.Fatalf("expected ErrEmailDuplicate, got %v", err)
+	}
+	role := "user"
+	if updated, err := repo.Update(ctx, admin.ID, nil, nil, &role); err != nil || updated.Role != "user" {
+		t.Fatalf("unexpected update %+v (%v)", updated, err)
+	}
+	if err := repo.Delete(ctx, admin.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, admin.ID); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestEchoHelpers(t *testing.T) {
+	user := NewUser().Build()
+	c, rec := NewJSONContext(t, http.MethodPost, "/companies/abc/notes", map[string]string{"body": "call back"})
+	WithParams(c, "id", "abc")
+	WithUser(c, user)
+
+	if c.Param("id") != "abc" || c.Get(middleware.ContextKeyUserID) != user.ID.String() {
+		t.Fatalf("expected params and user to be set")
+	}
+	var body map[string]string
+	if err := c.Bind(&body); err != nil || body["body"] != "call back" {
+		t.Fatalf("expected JSON body to bind, got %v (%v)", body, err)
+	}
+
+	if err := c.JSON(http.StatusCreated, map[string]any{"status": "success", "data": map[string]int{"id": 7}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	AssertStatus(t, rec, http.StatusCreated)
+	var data struct {
+		ID int `json:"id"`
+	}
+	if envelope := DecodeData(t, rec, &data); envelope.Status != "success" || data.ID != 7 {
+		t.Fatalf("unexpected envelope %+v data %+v", envelope, data)
+	}
+}
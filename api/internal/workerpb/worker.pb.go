@@ -0,0 +1,638 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: leadsgen/worker/v1/worker.proto
+
+package workerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ScrapeJob struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	TypeBusiness string                 `protobuf:"bytes,1,opt,name=type_business,json=typeBusiness,proto3" json:"type_business,omitempty"`
+	City         string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	Country      string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	MinRating    *float64               `protobuf:"fixed64,4,opt,name=min_rating,json=minRating,proto3,oneof" json:"min_rating,omitempty"`
+	// run_id and callback_token let the worker stream its results to /ingest/runs.
+	RunId         string `protobuf:"bytes,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	CallbackToken string `protobuf:"bytes,6,opt,name=callback_token,json=callbackToken,proto3" json:"callback_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrapeJob) Reset() {
+	*x = ScrapeJob{}
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrapeJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeJob) ProtoMessage() {}
+
+func (x *ScrapeJob) ProtoReflect() protoreflect.Message {
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeJob.ProtoReflect.Descriptor instead.
+func (*ScrapeJob) Descriptor() ([]byte, []int) {
+	return file_leadsgen_worker_v1_worker_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ScrapeJob) GetTypeBusiness() string {
+	if x != nil {
+		return x.TypeBusiness
+	}
+	return ""
+}
+
+func (x *ScrapeJob) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *ScrapeJob) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *ScrapeJob) GetMinRating() float64 {
+	if x != nil && x.MinRating != nil {
+		return *x.MinRating
+	}
+	return 0
+}
+
+func (x *ScrapeJob) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *ScrapeJob) GetCallbackToken() string {
+	if x != nil {
+		return x.CallbackToken
+	}
+	return ""
+}
+
+type ScrapeAccepted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrapeAccepted) Reset() {
+	*x = ScrapeAccepted{}
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrapeAccepted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeAccepted) ProtoMessage() {}
+
+func (x *ScrapeAccepted) ProtoReflect() protoreflect.Message {
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeAccepted.ProtoReflect.Descriptor instead.
+func (*ScrapeAccepted) Descriptor() ([]byte, []int) {
+	return file_leadsgen_worker_v1_worker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScrapeAccepted) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type EnrichJob struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	CompanyId string                 `protobuf:"bytes,1,opt,name=company_id,json=companyId,proto3" json:"company_id,omitempty"`
+	Website   string                 `protobuf:"bytes,2,opt,name=website,proto3" json:"website,omitempty"`
+	// depth is quick, standard or deep; max_pages bounds the pages crawled.
+	Depth    string `protobuf:"bytes,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	MaxPages int32  `protobuf:"varint,4,opt,name=max_pages,json=maxPages,proto3" json:"max_pages,omitempty"`
+	// job_id and callback_token let the worker report the result.
+	JobId         string `protobuf:"bytes,5,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	CallbackToken string `protobuf:"bytes,6,opt,name=callback_token,json=callbackToken,proto3" json:"callback_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnrichJob) Reset() {
+	*x = EnrichJob{}
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrichJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrichJob) ProtoMessage() {}
+
+func (x *EnrichJob) ProtoReflect() protoreflect.Message {
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrichJob.ProtoReflect.Descriptor instead.
+func (*EnrichJob) Descriptor() ([]byte, []int) {
+	return file_leadsgen_worker_v1_worker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EnrichJob) GetCompanyId() string {
+	if x != nil {
+		return x.CompanyId
+	}
+	return ""
+}
+
+func (x *EnrichJob) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *EnrichJob) GetDepth() string {
+	if x != nil {
+		return x.Depth
+	}
+	return ""
+}
+
+func (x *EnrichJob) GetMaxPages() int32 {
+	if x != nil {
+		return x.MaxPages
+	}
+	return 0
+}
+
+func (x *EnrichJob) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *EnrichJob) GetCallbackToken() string {
+	if x != nil {
+		return x.CallbackToken
+	}
+	return ""
+}
+
+// StringList wraps the profile URLs found on one social platform.
+type StringList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StringList) Reset() {
+	*x = StringList{}
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StringList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StringList) ProtoMessage() {}
+
+func (x *StringList) ProtoReflect() protoreflect.Message {
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StringList.ProtoReflect.Descriptor instead.
+func (*StringList) Descriptor() ([]byte, []int) {
+	return file_leadsgen_worker_v1_worker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StringList) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type EnrichResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	CompanyId string                 `protobuf:"bytes,1,opt,name=company_id,json=companyId,proto3" json:"company_id,omitempty"`
+	Emails    []string               `protobuf:"bytes,2,rep,name=emails,proto3" json:"emails,omitempty"`
+	Phones    []string               `protobuf:"bytes,3,rep,name=phones,proto3" json:"phones,omitempty"`
+	// socials holds the profile URLs found per platform, such as linkedin or instagram.
+	Socials        map[string]*StringList `protobuf:"bytes,4,rep,name=socials,proto3" json:"socials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Address        *string                `protobuf:"bytes,5,opt,name=address,proto3,oneof" json:"address,omitempty"`
+	ContactFormUrl *string                `protobuf:"bytes,6,opt,name=contact_form_url,json=contactFormUrl,proto3,oneof" json:"contact_form_url,omitempty"`
+	AboutSummary   *string                `protobuf:"bytes,7,opt,name=about_summary,json=aboutSummary,proto3,oneof" json:"about_summary,omitempty"`
+	Website        string                 `protobuf:"bytes,8,opt,name=website,proto3" json:"website,omitempty"`
+	PagesCrawled   int32                  `protobuf:"varint,9,opt,name=pages_crawled,json=pagesCrawled,proto3" json:"pages_crawled,omitempty"`
+	Depth          string                 `protobuf:"bytes,10,opt,name=depth,proto3" json:"depth,omitempty"`
+	// employee_mentions is the largest headcount stated on the crawled pages.
+	EmployeeMentions *int32           `protobuf:"varint,11,opt,name=employee_mentions,json=employeeMentions,proto3,oneof" json:"employee_mentions,omitempty"`
+	SocialFollowers  map[string]int32 `protobuf:"bytes,12,rep,name=social_followers,json=socialFollowers,proto3" json:"social_followers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// website_language is the lang attribute of the website's html element, e.g. "id-ID".
+	WebsiteLanguage *string `protobuf:"bytes,13,opt,name=website_language,json=websiteLanguage,proto3,oneof" json:"website_language,omitempty"`
+	// technologies are the website technologies fingerprinted on the crawled pages; they are only
+	// stored when technologies_checked is set, so an empty list means none was detected.
+	Technologies        []string `protobuf:"bytes,14,rep,name=technologies,proto3" json:"technologies,omitempty"`
+	TechnologiesChecked bool     `protobuf:"varint,15,opt,name=technologies_checked,json=technologiesChecked,proto3" json:"technologies_checked,omitempty"`
+	LogoUrl             *string  `protobuf:"bytes,16,opt,name=logo_url,json=logoUrl,proto3,oneof" json:"logo_url,omitempty"`
+	PhotoReference      *string  `protobuf:"bytes,17,opt,name=photo_reference,json=photoReference,proto3,oneof" json:"photo_reference,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *EnrichResult) Reset() {
+	*x = EnrichResult{}
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrichResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrichResult) ProtoMessage() {}
+
+func (x *EnrichResult) ProtoReflect() protoreflect.Message {
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrichResult.ProtoReflect.Descriptor instead.
+func (*EnrichResult) Descriptor() ([]byte, []int) {
+	return file_leadsgen_worker_v1_worker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EnrichResult) GetCompanyId() string {
+	if x != nil {
+		return x.CompanyId
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetEmails() []string {
+	if x != nil {
+		return x.Emails
+	}
+	return nil
+}
+
+func (x *EnrichResult) GetPhones() []string {
+	if x != nil {
+		return x.Phones
+	}
+	return nil
+}
+
+func (x *EnrichResult) GetSocials() map[string]*StringList {
+	if x != nil {
+		return x.Socials
+	}
+	return nil
+}
+
+func (x *EnrichResult) GetAddress() string {
+	if x != nil && x.Address != nil {
+		return *x.Address
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetContactFormUrl() string {
+	if x != nil && x.ContactFormUrl != nil {
+		return *x.ContactFormUrl
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetAboutSummary() string {
+	if x != nil && x.AboutSummary != nil {
+		return *x.AboutSummary
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetPagesCrawled() int32 {
+	if x != nil {
+		return x.PagesCrawled
+	}
+	return 0
+}
+
+func (x *EnrichResult) GetDepth() string {
+	if x != nil {
+		return x.Depth
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetEmployeeMentions() int32 {
+	if x != nil && x.EmployeeMentions != nil {
+		return *x.EmployeeMentions
+	}
+	return 0
+}
+
+func (x *EnrichResult) GetSocialFollowers() map[string]int32 {
+	if x != nil {
+		return x.SocialFollowers
+	}
+	return nil
+}
+
+func (x *EnrichResult) GetWebsiteLanguage() string {
+	if x != nil && x.WebsiteLanguage != nil {
+		return *x.WebsiteLanguage
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetTechnologies() []string {
+	if x != nil {
+		return x.Technologies
+	}
+	return nil
+}
+
+func (x *EnrichResult) GetTechnologiesChecked() bool {
+	if x != nil {
+		return x.TechnologiesChecked
+	}
+	return false
+}
+
+func (x *EnrichResult) GetLogoUrl() string {
+	if x != nil && x.LogoUrl != nil {
+		return *x.LogoUrl
+	}
+	return ""
+}
+
+func (x *EnrichResult) GetPhotoReference() string {
+	if x != nil && x.PhotoReference != nil {
+		return *x.PhotoReference
+	}
+	return ""
+}
+
+type CallbackAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stored        bool                   `protobuf:"varint,1,opt,name=stored,proto3" json:"stored,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallbackAck) Reset() {
+	*x = CallbackAck{}
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallbackAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallbackAck) ProtoMessage() {}
+
+func (x *CallbackAck) ProtoReflect() protoreflect.Message {
+	mi := &file_leadsgen_worker_v1_worker_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallbackAck.ProtoReflect.Descriptor instead.
+func (*CallbackAck) Descriptor() ([]byte, []int) {
+	return file_leadsgen_worker_v1_worker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CallbackAck) GetStored() bool {
+	if x != nil {
+		return x.Stored
+	}
+	return false
+}
+
+var File_leadsgen_worker_v1_worker_proto protoreflect.FileDescriptor
+
+const file_leadsgen_worker_v1_worker_proto_rawDesc = "" +
+	"\n" +
+	"\x1fleadsgen/worker/v1/worker.proto\x12\x12leadsgen.worker.v1\"\xcf\x01\n" +
+	"\tScrapeJob\x12#\n" +
+	"\rtype_business\x18\x01 \x01(\tR\ftypeBusiness\x12\x12\n" +
+	"\x04city\x18\x02 \x01(\tR\x04city\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12\"\n" +
+	"\n" +
+	"min_rating\x18\x04 \x01(\x01H\x00R\tminRating\x88\x01\x01\x12\x15\n" +
+	"\x06run_id\x18\x05 \x01(\tR\x05runId\x12%\n" +
+	"\x0ecallback_token\x18\x06 \x01(\tR\rcallbackTokenB\r\n" +
+	"\v_min_rating\"(\n" +
+	"\x0eScrapeAccepted\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\xb5\x01\n" +
+	"\tEnrichJob\x12\x1d\n" +
+	"\n" +
+	"company_id\x18\x01 \x01(\tR\tcompanyId\x12\x18\n" +
+	"\awebsite\x18\x02 \x01(\tR\awebsite\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\tR\x05depth\x12\x1b\n" +
+	"\tmax_pages\x18\x04 \x01(\x05R\bmaxPages\x12\x15\n" +
+	"\x06job_id\x18\x05 \x01(\tR\x05jobId\x12%\n" +
+	"\x0ecallback_token\x18\x06 \x01(\tR\rcallbackToken\"$\n" +
+	"\n" +
+	"StringList\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"\xfb\a\n" +
+	"\fEnrichResult\x12\x1d\n" +
+	"\n" +
+	"company_id\x18\x01 \x01(\tR\tcompanyId\x12\x16\n" +
+	"\x06emails\x18\x02 \x03(\tR\x06emails\x12\x16\n" +
+	"\x06phones\x18\x03 \x03(\tR\x06phones\x12G\n" +
+	"\asocials\x18\x04 \x03(\v2-.leadsgen.worker.v1.EnrichResult.SocialsEntryR\asocials\x12\x1d\n" +
+	"\aaddress\x18\x05 \x01(\tH\x00R\aaddress\x88\x01\x01\x12-\n" +
+	"\x10contact_form_url\x18\x06 \x01(\tH\x01R\x0econtactFormUrl\x88\x01\x01\x12(\n" +
+	"\rabout_summary\x18\a \x01(\tH\x02R\faboutSummary\x88\x01\x01\x12\x18\n" +
+	"\awebsite\x18\b \x01(\tR\awebsite\x12#\n" +
+	"\rpages_crawled\x18\t \x01(\x05R\fpagesCrawled\x12\x14\n" +
+	"\x05depth\x18\n" +
+	" \x01(\tR\x05depth\x120\n" +
+	"\x11employee_mentions\x18\v \x01(\x05H\x03R\x10employeeMentions\x88\x01\x01\x12`\n" +
+	"\x10social_followers\x18\f \x03(\v25.leadsgen.worker.v1.EnrichResult.SocialFollowersEntryR\x0fsocialFollowers\x12.\n" +
+	"\x10website_language\x18\r \x01(\tH\x04R\x0fwebsiteLanguage\x88\x01\x01\x12\"\n" +
+	"\ftechnologies\x18\x0e \x03(\tR\ftechnologies\x121\n" +
+	"\x14technologies_checked\x18\x0f \x01(\bR\x13technologiesChecked\x12\x1e\n" +
+	"\blogo_url\x18\x10 \x01(\tH\x05R\alogoUrl\x88\x01\x01\x12,\n" +
+	"\x0fphoto_reference\x18\x11 \x01(\tH\x06R\x0ephotoReference\x88\x01\x01\x1aZ\n" +
+	"\fSocialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x124\n" +
+	"\x05value\x18\x02 \x01(\v2\x1e.leadsgen.worker.v1.StringListR\x05value:\x028\x01\x1aB\n" +
+	"\x14SocialFollowersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01B\n" +
+	"\n" +
+	"\b_addressB\x13\n" +
+	"\x11_contact_form_urlB\x10\n" +
+	"\x0e_about_summaryB\x14\n" +
+	"\x12_employee_mentionsB\x13\n" +
+	"\x11_website_languageB\v\n" +
+	"\t_logo_urlB\x12\n" +
+	"\x10_photo_reference\"%\n" +
+	"\vCallbackAck\x12\x16\n" +
+	"\x06stored\x18\x01 \x01(\bR\x06stored2\xa0\x01\n" +
+	"\x06Worker\x12K\n" +
+	"\x06Scrape\x12\x1d.leadsgen.worker.v1.ScrapeJob\x1a\".leadsgen.worker.v1.ScrapeAccepted\x12I\n" +
+	"\x06Enrich\x12\x1d.leadsgen.worker.v1.EnrichJob\x1a .leadsgen.worker.v1.EnrichResult2j\n" +
+	"\x0fWorkerCallbacks\x12W\n" +
+	"\x12SubmitEnrichResult\x12 .leadsgen.worker.v1.EnrichResult\x1a\x1f.leadsgen.worker.v1.CallbackAckB;Z9github.com/octobees/leads-generator/api/internal/workerpbb\x06proto3"
+
+var (
+	file_leadsgen_worker_v1_worker_proto_rawDescOnce sync.Once
+	file_leadsgen_worker_v1_worker_proto_rawDescData []byte
+)
+
+func file_leadsgen_worker_v1_worker_proto_rawDescGZIP() []byte {
+	file_leadsgen_worker_v1_worker_proto_rawDescOnce.Do(func() {
+		file_leadsgen_worker_v1_worker_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_leadsgen_worker_v1_worker_proto_rawDesc), len(file_leadsgen_worker_v1_worker_proto_rawDesc)))
+	})
+	return file_leadsgen_worker_v1_worker_proto_rawDescData
+}
+
+var file_leadsgen_worker_v1_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_leadsgen_worker_v1_worker_proto_goTypes = []any{
+	(*ScrapeJob)(nil),      // 0: leadsgen.worker.v1.ScrapeJob
+	(*ScrapeAccepted)(nil), // 1: leadsgen.worker.v1.ScrapeAccepted
+	(*EnrichJob)(nil),      // 2: leadsgen.worker.v1.EnrichJob
+	(*StringList)(nil),     // 3: leadsgen.worker.v1.StringList
+	(*EnrichResult)(nil),   // 4: leadsgen.worker.v1.EnrichResult
+	(*CallbackAck)(nil),    // 5: leadsgen.worker.v1.CallbackAck
+	nil,                    // 6: leadsgen.worker.v1.EnrichResult.SocialsEntry
+	nil,                    // 7: leadsgen.worker.v1.EnrichResult.SocialFollowersEntry
+}
+var file_leadsgen_worker_v1_worker_proto_depIdxs = []int32{
+	6, // 0: leadsgen.worker.v1.EnrichResult.socials:type_name -> leadsgen.worker.v1.EnrichResult.SocialsEntry
+	7, // 1: leadsgen.worker.v1.EnrichResult.social_followers:type_name -> leadsgen.worker.v1.EnrichResult.SocialFollowersEntry
+	3, // 2: leadsgen.worker.v1.EnrichResult.SocialsEntry.value:type_name -> leadsgen.worker.v1.StringList
+	0, // 3: leadsgen.worker.v1.Worker.Scrape:input_type -> leadsgen.worker.v1.ScrapeJob
+	2, // 4: leadsgen.worker.v1.Worker.Enrich:input_type -> leadsgen.worker.v1.EnrichJob
+	4, // 5: leadsgen.worker.v1.WorkerCallbacks.SubmitEnrichResult:input_type -> leadsgen.worker.v1.EnrichResult
+	1, // 6: leadsgen.worker.v1.Worker.Scrape:output_type -> leadsgen.worker.v1.ScrapeAccepted
+	4, // 7: leadsgen.worker.v1.Worker.Enrich:output_type -> leadsgen.worker.v1.EnrichResult
+	5, // 8: leadsgen.worker.v1.WorkerCallbacks.SubmitEnrichResult:output_type -> leadsgen.worker.v1.CallbackAck
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_leadsgen_worker_v1_worker_proto_init() }
+func file_leadsgen_worker_v1_worker_proto_init() {
+	if File_leadsgen_worker_v1_worker_proto != nil {
+		return
+	}
+	file_leadsgen_worker_v1_worker_proto_msgTypes[0].OneofWrappers = []any{}
+	file_leadsgen_worker_v1_worker_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_leadsgen_worker_v1_worker_proto_rawDesc), len(file_leadsgen_worker_v1_worker_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_leadsgen_worker_v1_worker_proto_goTypes,
+		DependencyIndexes: file_leadsgen_worker_v1_worker_proto_depIdxs,
+		MessageInfos:      file_leadsgen_worker_v1_worker_proto_msgTypes,
+	}.Build()
+	File_leadsgen_worker_v1_worker_proto = out.File
+	file_leadsgen_worker_v1_worker_proto_goTypes = nil
+	file_leadsgen_worker_v1_worker_proto_depIdxs = nil
+}
@@ -0,0 +1,2 @@
+-- Migration 0008 down: drop enrichment job tracking
+DROP TABLE IF EXISTS enrichment_jobs;
@@ -0,0 +1,16 @@
+-- Migration 0008: track enrichment jobs per depth for cost accounting and quotas
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    website TEXT NOT NULL,
+    depth TEXT NOT NULL DEFAULT 'standard',
+    cost INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'accepted',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_requested_by_created_at
+    ON enrichment_jobs (requested_by, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_company_id
+    ON enrichment_jobs (company_id);
@@ -0,0 +1,2 @@
+-- Migration 0009 down: drop the domain enrichment cache
+DROP TABLE IF EXISTS domain_enrichments;
@@ -0,0 +1,18 @@
+-- Migration 0009: cache enrichment results per canonical website domain
+CREATE TABLE IF NOT EXISTS domain_enrichments (
+    domain TEXT PRIMARY KEY,
+    source_company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    depth TEXT NOT NULL DEFAULT 'standard',
+    emails TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    phones TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    socials JSONB NOT NULL DEFAULT '{}'::jsonb,
+    address TEXT,
+    contact_form_url TEXT,
+    about_summary TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_enrichments_updated_at
+    ON domain_enrichments (updated_at DESC);
@@ -0,0 +1,4 @@
+-- Migration 0010 down: remove chain grouping
+DROP INDEX IF EXISTS idx_companies_chain_id;
+ALTER TABLE companies DROP COLUMN IF EXISTS chain_id;
+DROP TABLE IF EXISTS company_chains;
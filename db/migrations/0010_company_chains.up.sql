@@ -0,0 +1,20 @@
+-- Migration 0010: group franchise/chain locations under a parent chain entity
+CREATE TABLE IF NOT EXISTS company_chains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chain_key TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    domain TEXT,
+    location_count INT NOT NULL DEFAULT 0,
+    city_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS chain_id UUID REFERENCES company_chains(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_companies_chain_id
+    ON companies (chain_id);
+
+CREATE INDEX IF NOT EXISTS idx_company_chains_location_count
+    ON company_chains (location_count DESC);
@@ -0,0 +1,2 @@
+-- Migration 0011 down: drop materialised lead scores
+DROP TABLE IF EXISTS company_lead_scores;
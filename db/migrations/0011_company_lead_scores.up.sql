@@ -0,0 +1,10 @@
+-- Migration 0011: materialise lead scores computed from company enrichments
+CREATE TABLE IF NOT EXISTS company_lead_scores (
+    company_id UUID PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    score INT NOT NULL,
+    breakdown JSONB NOT NULL DEFAULT '{}'::jsonb,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_lead_scores_score
+    ON company_lead_scores (score DESC);
@@ -0,0 +1,5 @@
+-- Migration 0012 down: drop business size estimation columns
+DROP INDEX IF EXISTS idx_companies_size_bucket;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS size_estimated_at,
+    DROP COLUMN IF EXISTS size_bucket;
@@ -0,0 +1,7 @@
+-- Migration 0012: store the estimated business size bucket on companies
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS size_bucket TEXT,
+    ADD COLUMN IF NOT EXISTS size_estimated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_companies_size_bucket
+    ON companies (size_bucket);
@@ -0,0 +1,8 @@
+-- Migration 0013 down: remove business status tracking
+DROP TRIGGER IF EXISTS capture_business_status ON companies;
+DROP FUNCTION IF EXISTS trigger_capture_business_status();
+DROP INDEX IF EXISTS idx_companies_status_changed_at;
+DROP INDEX IF EXISTS idx_companies_business_status;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS business_status;
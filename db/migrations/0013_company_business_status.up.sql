@@ -0,0 +1,45 @@
+-- Migration 0013: track opening/closure status captured from the raw Places payload
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS business_status TEXT,
+    ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
+
+-- Backfill before the trigger exists so historical rows do not count as recent changes.
+UPDATE companies
+SET business_status = lower(btrim(raw->>'business_status'))
+WHERE NULLIF(btrim(raw->>'business_status'), '') IS NOT NULL;
+
+-- Places reports OPERATIONAL, CLOSED_TEMPORARILY or CLOSED_PERMANENTLY. Payloads without a
+-- status (CSV imports) keep the last known value; status_changed_at only moves on transitions.
+CREATE OR REPLACE FUNCTION trigger_capture_business_status()
+RETURNS TRIGGER AS $$
+DECLARE
+    captured TEXT;
+BEGIN
+    captured := NULLIF(lower(btrim(NEW.raw->>'business_status')), '');
+    IF captured IS NOT NULL THEN
+        NEW.business_status := captured;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.business_status IS DISTINCT FROM OLD.business_status THEN
+            NEW.status_changed_at := NOW();
+        ELSE
+            NEW.status_changed_at := OLD.status_changed_at;
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS capture_business_status ON companies;
+CREATE TRIGGER capture_business_status
+BEFORE INSERT OR UPDATE ON companies
+FOR EACH ROW
+EXECUTE FUNCTION trigger_capture_business_status();
+
+CREATE INDEX IF NOT EXISTS idx_companies_business_status
+    ON companies (business_status);
+
+CREATE INDEX IF NOT EXISTS idx_companies_status_changed_at
+    ON companies (status_changed_at)
+    WHERE status_changed_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Migration 0014 down: drop warehouse export bookkeeping
+DROP INDEX IF EXISTS idx_warehouse_exports_completed_at;
+DROP TABLE IF EXISTS warehouse_exports;
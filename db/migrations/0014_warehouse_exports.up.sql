@@ -0,0 +1,13 @@
+-- Migration 0014: record warehouse (Parquet/GCS) export partitions
+CREATE TABLE IF NOT EXISTS warehouse_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partition_date DATE NOT NULL UNIQUE,
+    location TEXT NOT NULL,
+    files TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    row_count BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_exports_completed_at
+    ON warehouse_exports (completed_at DESC);
@@ -0,0 +1,5 @@
+-- Migration 0015 down: remove change event history
+DROP TRIGGER IF EXISTS record_enrichment_change ON company_enrichments;
+DROP TRIGGER IF EXISTS record_company_change ON companies;
+DROP FUNCTION IF EXISTS trigger_record_change_event();
+DROP TABLE IF EXISTS change_events;
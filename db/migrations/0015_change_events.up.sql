@@ -0,0 +1,76 @@
+-- Migration 0015: change event history backing the GET /changes feed
+CREATE TABLE IF NOT EXISTS change_events (
+    id BIGSERIAL PRIMARY KEY,
+    -- Writing transaction; the feed orders by (tx_id, id) and only serves transactions older than
+    -- every in-flight one, so a cursor never skips a late commit.
+    tx_id XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    entity TEXT NOT NULL CHECK (entity IN ('company', 'enrichment')),
+    entity_id UUID NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('create', 'update', 'delete')),
+    data JSONB,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_events_cursor
+    ON change_events (tx_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_change_events_occurred_at
+    ON change_events (occurred_at);
+
+-- Records one event per row change from any writer (API, worker, CSV import). Updates that only
+-- bump updated_at are skipped. Company snapshots omit the bulky raw payload and expose the
+-- location as longitude/latitude.
+CREATE OR REPLACE FUNCTION trigger_record_change_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    kind TEXT;
+    key UUID;
+    snapshot JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' AND (to_jsonb(NEW) - 'updated_at') = (to_jsonb(OLD) - 'updated_at') THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_TABLE_NAME = 'companies' THEN
+        kind := 'company';
+        IF TG_OP = 'DELETE' THEN
+            key := OLD.id;
+        ELSE
+            key := NEW.id;
+            snapshot := (to_jsonb(NEW) - 'raw' - 'location') || jsonb_build_object(
+                'longitude', CASE WHEN NEW.location IS NOT NULL THEN ST_X(NEW.location::geometry) END,
+                'latitude', CASE WHEN NEW.location IS NOT NULL THEN ST_Y(NEW.location::geometry) END
+            );
+        END IF;
+    ELSE
+        kind := 'enrichment';
+        IF TG_OP = 'DELETE' THEN
+            key := OLD.company_id;
+        ELSE
+            key := NEW.company_id;
+            snapshot := to_jsonb(NEW);
+        END IF;
+    END IF;
+
+    INSERT INTO change_events (entity, entity_id, operation, data)
+    VALUES (
+        kind,
+        key,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        snapshot
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_company_change ON companies;
+CREATE TRIGGER record_company_change
+AFTER INSERT OR UPDATE OR DELETE ON companies
+FOR EACH ROW
+EXECUTE FUNCTION trigger_record_change_event();
+
+DROP TRIGGER IF EXISTS record_enrichment_change ON company_enrichments;
+CREATE TRIGGER record_enrichment_change
+AFTER INSERT OR UPDATE OR DELETE ON company_enrichments
+FOR EACH ROW
+EXECUTE FUNCTION trigger_record_change_event();
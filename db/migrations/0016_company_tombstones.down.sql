@@ -0,0 +1,5 @@
+-- Migration 0016 down: remove company tombstones
+DROP TRIGGER IF EXISTS record_company_tombstone ON companies;
+DROP FUNCTION IF EXISTS trigger_record_company_tombstone();
+DROP INDEX IF EXISTS idx_companies_updated_at_id;
+DROP TABLE IF EXISTS company_tombstones;
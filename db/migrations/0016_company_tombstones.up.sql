@@ -0,0 +1,29 @@
+-- Migration 0016: tombstones and updated_at keyset index for the /companies/changes sync feed
+CREATE TABLE IF NOT EXISTS company_tombstones (
+    company_id UUID PRIMARY KEY,
+    place_id TEXT,
+    company TEXT NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_tombstones_deleted_at
+    ON company_tombstones (deleted_at, company_id);
+
+CREATE INDEX IF NOT EXISTS idx_companies_updated_at_id
+    ON companies (updated_at, id);
+
+CREATE OR REPLACE FUNCTION trigger_record_company_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO company_tombstones (company_id, place_id, company)
+    VALUES (OLD.id, OLD.place_id, OLD.company)
+    ON CONFLICT (company_id) DO UPDATE SET deleted_at = NOW();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_company_tombstone ON companies;
+CREATE TRIGGER record_company_tombstone
+AFTER DELETE ON companies
+FOR EACH ROW
+EXECUTE FUNCTION trigger_record_company_tombstone();
@@ -0,0 +1,2 @@
+-- Migration 0017 down: drop retained CSV upload records
+DROP TABLE IF EXISTS csv_uploads;
@@ -0,0 +1,26 @@
+-- Migration 0017: retain a hashed copy of every admin CSV upload alongside its import outcome
+CREATE TABLE IF NOT EXISTS csv_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    inserted INT NOT NULL DEFAULT 0,
+    updated INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    total INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_csv_uploads_created_at
+    ON csv_uploads (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_csv_uploads_expires_at
+    ON csv_uploads (expires_at);
+CREATE INDEX IF NOT EXISTS idx_csv_uploads_sha256
+    ON csv_uploads (sha256);
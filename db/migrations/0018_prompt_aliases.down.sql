@@ -0,0 +1,2 @@
+-- Migration 0018 down: drop prompt parser aliases
+DROP TABLE IF EXISTS prompt_aliases;
@@ -0,0 +1,10 @@
+-- Migration 0018: city aliases and business-type synonyms used by the prompt parser
+CREATE TABLE IF NOT EXISTS prompt_aliases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL CHECK (kind IN ('city', 'business_type')),
+    alias TEXT NOT NULL,
+    canonical TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT prompt_aliases_kind_alias_key UNIQUE (kind, alias)
+);
@@ -0,0 +1,2 @@
+-- Migration 0019 down: drop import jobs
+DROP TABLE IF EXISTS import_jobs;
@@ -0,0 +1,28 @@
+-- Migration 0019: asynchronous CSV/XLSX import jobs with progress and per-row errors
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    upload_id UUID REFERENCES csv_uploads(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+    storage_key TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    bytes_read BIGINT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    inserted INT NOT NULL DEFAULT 0,
+    updated INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    error_count INT NOT NULL DEFAULT 0,
+    row_errors JSONB NOT NULL DEFAULT '[]'::jsonb,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_created_at
+    ON import_jobs (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status
+    ON import_jobs (status);
@@ -0,0 +1,2 @@
+-- Migration 0020 down: drop company attachments
+DROP TABLE IF EXISTS company_attachments;
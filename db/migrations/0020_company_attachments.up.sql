@@ -0,0 +1,18 @@
+-- Migration 0020: company attachments stored in object storage and uploaded through signed URLs
+CREATE TABLE IF NOT EXISTS company_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    uploaded_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_attachments_company_id
+    ON company_attachments (company_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_company_attachments_uploaded_by
+    ON company_attachments (uploaded_by);
@@ -0,0 +1,5 @@
+-- Migration 0021 down: remove Twitter/X and WhatsApp links and profile handles
+ALTER TABLE website_enriched_contacts
+    DROP COLUMN IF EXISTS social_handles,
+    DROP COLUMN IF EXISTS whatsapp_url,
+    DROP COLUMN IF EXISTS twitter_url;
@@ -0,0 +1,5 @@
+-- Migration 0021: Twitter/X and WhatsApp links plus profile handles for enriched contacts
+ALTER TABLE website_enriched_contacts
+    ADD COLUMN IF NOT EXISTS twitter_url TEXT,
+    ADD COLUMN IF NOT EXISTS whatsapp_url TEXT,
+    ADD COLUMN IF NOT EXISTS social_handles JSONB NOT NULL DEFAULT '{}'::jsonb;
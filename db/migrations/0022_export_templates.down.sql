@@ -0,0 +1,2 @@
+-- Migration 0022 down: drop export templates
+DROP TABLE IF EXISTS export_templates;
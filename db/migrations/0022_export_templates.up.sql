@@ -0,0 +1,13 @@
+-- Migration 0022: saved CSV export locale settings per user
+CREATE TABLE IF NOT EXISTS export_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    delimiter TEXT NOT NULL DEFAULT ',',
+    decimal_mark TEXT NOT NULL DEFAULT '.',
+    date_format TEXT NOT NULL DEFAULT 'iso',
+    bom BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT export_templates_user_id_name_key UNIQUE (user_id, name)
+);
@@ -0,0 +1,5 @@
+-- Migration 0023 down: drop the outreach language columns
+DROP INDEX IF EXISTS idx_companies_preferred_outreach_language;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS outreach_language_source,
+    DROP COLUMN IF EXISTS preferred_outreach_language;
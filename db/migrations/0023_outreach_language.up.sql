@@ -0,0 +1,7 @@
+-- Migration 0023: preferred outreach language detected per company
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS preferred_outreach_language TEXT,
+    ADD COLUMN IF NOT EXISTS outreach_language_source TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_companies_preferred_outreach_language
+    ON companies (preferred_outreach_language);
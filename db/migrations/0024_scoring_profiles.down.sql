@@ -0,0 +1,6 @@
+-- Migration 0024 down: drop scoring profiles
+ALTER TABLE company_lead_scores
+    DROP COLUMN IF EXISTS profile_revision,
+    DROP COLUMN IF EXISTS profile_id;
+
+DROP TABLE IF EXISTS scoring_profiles;
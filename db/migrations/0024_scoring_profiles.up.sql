@@ -0,0 +1,22 @@
+-- Migration 0024: admin managed lead scoring profiles and the profile each stored score used
+CREATE TABLE IF NOT EXISTS scoring_profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    weights JSONB NOT NULL DEFAULT '{}'::jsonb,
+    inverted TEXT[] NOT NULL DEFAULT '{}',
+    hot_threshold INT NOT NULL DEFAULT 70,
+    warm_threshold INT NOT NULL DEFAULT 40,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    revision INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT scoring_profiles_name_key UNIQUE (name)
+);
+
+-- At most one profile is active; without one the built-in weights apply.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_profiles_active
+    ON scoring_profiles (active) WHERE active;
+
+ALTER TABLE company_lead_scores
+    ADD COLUMN IF NOT EXISTS profile_id UUID REFERENCES scoring_profiles(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS profile_revision INT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Migration 0025 down: drop chunked ingestion runs
+DROP TABLE IF EXISTS ingest_run_batches;
+DROP TABLE IF EXISTS ingest_runs;
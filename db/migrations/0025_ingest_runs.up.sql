@@ -0,0 +1,26 @@
+-- Migration 0025: chunked worker ingestion runs and the batches received for each
+CREATE TABLE IF NOT EXISTS ingest_runs (
+    -- id doubles as the scrape_run_id of every company ingested by the run.
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source TEXT NOT NULL DEFAULT '',
+    query TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    batches INT NOT NULL DEFAULT 0,
+    items INT NOT NULL DEFAULT 0,
+    total_batches INT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT ingest_runs_status_check CHECK (status IN ('open', 'finished'))
+);
+
+-- One row per applied batch; the checksum tells a replayed batch from a conflicting one.
+CREATE TABLE IF NOT EXISTS ingest_run_batches (
+    run_id UUID NOT NULL REFERENCES ingest_runs(id) ON DELETE CASCADE,
+    sequence INT NOT NULL,
+    checksum TEXT NOT NULL,
+    items INT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (run_id, sequence),
+    CONSTRAINT ingest_run_batches_sequence_check CHECK (sequence >= 0)
+);
@@ -0,0 +1,2 @@
+-- Migration 0026 down: drop score recompute jobs
+DROP TABLE IF EXISTS score_recompute_jobs;
@@ -0,0 +1,23 @@
+-- Migration 0026: background lead score recompute jobs with resumable progress
+CREATE TABLE IF NOT EXISTS score_recompute_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    stale_only BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL DEFAULT 'queued',
+    total INT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    -- resume_after is the last company scored; a resumed job continues after it.
+    resume_after UUID,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_recompute_jobs_created_at
+    ON score_recompute_jobs (created_at DESC);
+
+-- At most one job is queued or running, so concurrent requests cannot rescore the catalogue twice.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_score_recompute_jobs_active
+    ON score_recompute_jobs ((TRUE)) WHERE status IN ('queued', 'running');
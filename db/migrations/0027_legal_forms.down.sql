@@ -0,0 +1,10 @@
+-- Migration 0027 down: match rows without a place id on the full name again
+CREATE UNIQUE INDEX IF NOT EXISTS unique_company_address
+    ON companies (company, address)
+    WHERE place_id IS NULL;
+
+DROP INDEX IF EXISTS unique_company_display_name_address;
+DROP INDEX IF EXISTS idx_companies_legal_form;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS legal_form;
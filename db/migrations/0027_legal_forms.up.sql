@@ -0,0 +1,18 @@
+-- Migration 0027: legal form (PT, CV...) and cleaned display name extracted from company names
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS legal_form TEXT,
+    ADD COLUMN IF NOT EXISTS display_name TEXT;
+
+-- Existing rows start with their full name; apiadmin recompute-legal-forms cleans them.
+UPDATE companies SET display_name = company WHERE display_name IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_companies_legal_form
+    ON companies (legal_form);
+
+-- Rows without a place id are matched on the cleaned name, so "PT Maju Jaya" and "Maju Jaya"
+-- at one address are the same company.
+CREATE UNIQUE INDEX IF NOT EXISTS unique_company_display_name_address
+    ON companies (display_name, address)
+    WHERE place_id IS NULL;
+
+DROP INDEX IF EXISTS unique_company_address;
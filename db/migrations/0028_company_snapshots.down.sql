@@ -0,0 +1,4 @@
+-- Migration 0028 down: remove company snapshots
+DROP TRIGGER IF EXISTS record_company_snapshot ON companies;
+DROP FUNCTION IF EXISTS trigger_record_company_snapshot();
+DROP TABLE IF EXISTS company_snapshots;
@@ -0,0 +1,49 @@
+-- Migration 0028: rating and review counts observed for a company by every scrape run
+CREATE TABLE IF NOT EXISTS company_snapshots (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    scrape_run_id UUID NOT NULL,
+    rating NUMERIC(2,1),
+    reviews INT,
+    observed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (company_id, scrape_run_id)
+);
+
+-- Seed the history with the last observation of every scraped company.
+INSERT INTO company_snapshots (company_id, scrape_run_id, rating, reviews, observed_at)
+SELECT id, scrape_run_id, rating, reviews, COALESCE(scraped_at, updated_at)
+FROM companies
+WHERE scrape_run_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Every write carrying a run id records what that run saw; a run seeing a company twice keeps its
+-- latest observation. Writes leaving the run, rating and reviews alone (enrichment, recomputes)
+-- are not observations.
+CREATE OR REPLACE FUNCTION trigger_record_company_snapshot()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.scrape_run_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE'
+        AND NEW.scrape_run_id IS NOT DISTINCT FROM OLD.scrape_run_id
+        AND NEW.rating IS NOT DISTINCT FROM OLD.rating
+        AND NEW.reviews IS NOT DISTINCT FROM OLD.reviews
+        AND NEW.scraped_at IS NOT DISTINCT FROM OLD.scraped_at THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO company_snapshots (company_id, scrape_run_id, rating, reviews, observed_at)
+    VALUES (NEW.id, NEW.scrape_run_id, NEW.rating, NEW.reviews, COALESCE(NEW.scraped_at, NOW()))
+    ON CONFLICT (company_id, scrape_run_id) DO UPDATE SET
+        rating = EXCLUDED.rating,
+        reviews = EXCLUDED.reviews,
+        observed_at = EXCLUDED.observed_at;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_company_snapshot ON companies;
+CREATE TRIGGER record_company_snapshot
+AFTER INSERT OR UPDATE ON companies
+FOR EACH ROW
+EXECUTE FUNCTION trigger_record_company_snapshot();
@@ -0,0 +1,5 @@
+-- Migration 0029 down: remove contact collisions
+DROP INDEX IF EXISTS idx_companies_shared_contact;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS shared_contact;
+DROP TABLE IF EXISTS contact_collisions;
@@ -0,0 +1,20 @@
+-- Migration 0029: emails and phone numbers shared by many unrelated companies
+CREATE TABLE IF NOT EXISTS contact_collisions (
+    kind TEXT NOT NULL,
+    value TEXT NOT NULL,
+    company_count INT NOT NULL,
+    owner_count INT NOT NULL,
+    company_ids UUID[] NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (kind, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_collisions_owner_count
+    ON contact_collisions (owner_count DESC);
+
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS shared_contact BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_companies_shared_contact
+    ON companies (shared_contact)
+    WHERE shared_contact;
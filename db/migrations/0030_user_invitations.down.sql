@@ -0,0 +1,5 @@
+-- Migration 0030 down: remove user invitations
+DROP TABLE IF EXISTS user_invitations;
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS row_results,
+    DROP COLUMN IF EXISTS kind;
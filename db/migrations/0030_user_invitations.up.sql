@@ -0,0 +1,25 @@
+-- Migration 0030: user invitations, provisioned one by one or in bulk through import jobs
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'companies',
+    ADD COLUMN IF NOT EXISTS row_results JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL,
+    role user_role NOT NULL DEFAULT 'user',
+    org TEXT,
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    import_job_id UUID REFERENCES import_jobs(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One pending invitation per address; inviting again refreshes it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_email
+    ON user_invitations (lower(email))
+    WHERE accepted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_invitations_import_job
+    ON user_invitations (import_job_id);
@@ -0,0 +1,47 @@
+-- Migration 0031 down: keep raw payloads inline again
+-- Offloaded payloads are not copied back; run before offloading or restore them from storage.
+CREATE OR REPLACE FUNCTION trigger_record_change_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    kind TEXT;
+    key UUID;
+    snapshot JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' AND (to_jsonb(NEW) - 'updated_at') = (to_jsonb(OLD) - 'updated_at') THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_TABLE_NAME = 'companies' THEN
+        kind := 'company';
+        IF TG_OP = 'DELETE' THEN
+            key := OLD.id;
+        ELSE
+            key := NEW.id;
+            snapshot := (to_jsonb(NEW) - 'raw' - 'location') || jsonb_build_object(
+                'longitude', CASE WHEN NEW.location IS NOT NULL THEN ST_X(NEW.location::geometry) END,
+                'latitude', CASE WHEN NEW.location IS NOT NULL THEN ST_Y(NEW.location::geometry) END
+            );
+        END IF;
+    ELSE
+        kind := 'enrichment';
+        IF TG_OP = 'DELETE' THEN
+            key := OLD.company_id;
+        ELSE
+            key := NEW.company_id;
+            snapshot := to_jsonb(NEW);
+        END IF;
+    END IF;
+
+    INSERT INTO change_events (entity, entity_id, operation, data)
+    VALUES (
+        kind,
+        key,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        snapshot
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS raw_ref;
@@ -0,0 +1,50 @@
+-- Migration 0031: raw Places payloads offloaded to object storage
+-- raw_ref is the object key of the payload once offloaded; raw then only keeps the fields SQL
+-- reads (business_status, photos_count). Writers storing a new payload clear raw_ref.
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS raw_ref TEXT;
+
+-- Offloading only moves the payload, so it is not reported as a change; snapshots leave raw_ref
+-- out like raw.
+CREATE OR REPLACE FUNCTION trigger_record_change_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    kind TEXT;
+    key UUID;
+    snapshot JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' AND (to_jsonb(NEW) - 'updated_at' - 'raw' - 'raw_ref') = (to_jsonb(OLD) - 'updated_at' - 'raw' - 'raw_ref') THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_TABLE_NAME = 'companies' THEN
+        kind := 'company';
+        IF TG_OP = 'DELETE' THEN
+            key := OLD.id;
+        ELSE
+            key := NEW.id;
+            snapshot := (to_jsonb(NEW) - 'raw' - 'raw_ref' - 'location') || jsonb_build_object(
+                'longitude', CASE WHEN NEW.location IS NOT NULL THEN ST_X(NEW.location::geometry) END,
+                'latitude', CASE WHEN NEW.location IS NOT NULL THEN ST_Y(NEW.location::geometry) END
+            );
+        END IF;
+    ELSE
+        kind := 'enrichment';
+        IF TG_OP = 'DELETE' THEN
+            key := OLD.company_id;
+        ELSE
+            key := NEW.company_id;
+            snapshot := to_jsonb(NEW);
+        END IF;
+    END IF;
+
+    INSERT INTO change_events (entity, entity_id, operation, data)
+    VALUES (
+        kind,
+        key,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        snapshot
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
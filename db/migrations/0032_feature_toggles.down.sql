@@ -0,0 +1,2 @@
+-- Migration 0032 down: drop feature toggles
+DROP TABLE IF EXISTS feature_toggles;
@@ -0,0 +1,9 @@
+-- Migration 0032: admin kill switches for worker-dependent features
+CREATE TABLE IF NOT EXISTS feature_toggles (
+    feature TEXT PRIMARY KEY,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    message TEXT NOT NULL DEFAULT '',
+    eta TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Migration 0033 down: drop refresh token sessions and pending email changes
+DROP TABLE IF EXISTS user_email_changes;
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,2 @@
+-- Migration 0034 down: drop the privacy audit trail
+DROP TABLE IF EXISTS privacy_requests;
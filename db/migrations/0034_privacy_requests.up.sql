@@ -0,0 +1,15 @@
+-- Migration 0034: audit trail of privacy erasures and exports
+-- Subjects are kept as SHA-256 hashes of the normalised email so a repeated request can be matched
+-- without the trail holding the addresses it erased.
+CREATE TABLE IF NOT EXISTS privacy_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    action TEXT NOT NULL CHECK (action IN ('erase', 'export')),
+    subject_hashes TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    company_ids UUID[] NOT NULL DEFAULT ARRAY[]::UUID[],
+    counts JSONB NOT NULL DEFAULT '{}'::jsonb,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_privacy_requests_created_at
+    ON privacy_requests (created_at DESC);
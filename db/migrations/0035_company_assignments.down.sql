@@ -0,0 +1,2 @@
+-- Migration 0035 down: drop company assignments
+DROP TABLE IF EXISTS company_assignments;
@@ -0,0 +1,12 @@
+-- Migration 0035: companies assigned to sales reps
+-- Assignments live outside companies so claiming a lead is not reported as a company change.
+-- Deleting a user returns their leads to the pool.
+CREATE TABLE IF NOT EXISTS company_assignments (
+    company_id UUID PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_assignments_user_id
+    ON company_assignments (user_id);
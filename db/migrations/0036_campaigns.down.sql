@@ -0,0 +1,4 @@
+-- Migration 0036 down: drop outreach campaigns
+DROP TABLE IF EXISTS email_suppressions;
+DROP TABLE IF EXISTS campaign_recipients;
+DROP TABLE IF EXISTS campaigns;
@@ -0,0 +1,54 @@
+-- Migration 0036: email outreach campaigns, their recipients and the suppression list
+-- A campaign keeps the listing filter it was created from; recipients are resolved from it when
+-- sending starts, one enriched email per company.
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sending', 'sent')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_created_at
+    ON campaigns (created_at DESC);
+
+-- Tracking tokens are stored hashed and only set once the message has been handed to the provider.
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    company_id UUID REFERENCES companies(id) ON DELETE SET NULL,
+    email TEXT NOT NULL,
+    variables JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN (
+        'queued', 'sending', 'sent', 'opened', 'bounced', 'complained', 'unsubscribed', 'failed', 'suppressed'
+    )),
+    token_hash TEXT UNIQUE,
+    message_id TEXT,
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+    opened_at TIMESTAMPTZ,
+    bounced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (campaign_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_queued
+    ON campaign_recipients (created_at)
+    WHERE status = 'queued';
+
+-- Addresses that must never be mailed again, whatever campaign they turn up in.
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    email TEXT PRIMARY KEY,
+    reason TEXT NOT NULL CHECK (reason IN ('unsubscribe', 'bounce', 'complaint', 'manual')),
+    campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Migration 0037 down: restore the campaign email suppression list; domain and phone entries are lost
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    email TEXT PRIMARY KEY,
+    reason TEXT NOT NULL CHECK (reason IN ('unsubscribe', 'bounce', 'complaint', 'manual')),
+    campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO email_suppressions (email, reason, campaign_id, created_by, created_at)
+SELECT value, reason, campaign_id, created_by, created_at
+FROM contact_suppressions
+WHERE kind = 'email'
+ON CONFLICT (email) DO NOTHING;
+
+DROP TABLE IF EXISTS contact_suppressions;
@@ -0,0 +1,24 @@
+-- Migration 0037: do-not-contact list of emails, domains and phone numbers
+-- Replaces the campaign email suppression list, whose entries are carried over as email entries.
+-- Values are normalised by the API: lowercase emails, hosts without "www." and E.164 numbers.
+CREATE TABLE IF NOT EXISTS contact_suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL CHECK (kind IN ('email', 'domain', 'phone')),
+    value TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('unsubscribe', 'bounce', 'complaint', 'manual')),
+    note TEXT,
+    campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_suppressions_created_at
+    ON contact_suppressions (created_at DESC);
+
+INSERT INTO contact_suppressions (kind, value, reason, campaign_id, created_by, created_at)
+SELECT 'email', email, reason, campaign_id, created_by, created_at
+FROM email_suppressions
+ON CONFLICT (kind, value) DO NOTHING;
+
+DROP TABLE IF EXISTS email_suppressions;
@@ -0,0 +1,3 @@
+-- Migration 0038 down: drop scrape jobs
+DROP TABLE IF EXISTS scrape_job_requesters;
+DROP TABLE IF EXISTS scrape_jobs;
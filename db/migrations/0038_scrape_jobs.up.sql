@@ -0,0 +1,29 @@
+-- Migration 0038: scrape jobs and their requesters
+-- A job is one scrape sent to the worker; its id is the ingest run the results stream to. Identical
+-- requests made while it is queued share it instead of scraping again, so each user asking for it
+-- is listed as a requester. The fingerprint is the normalised request used to find such a job.
+CREATE TABLE IF NOT EXISTS scrape_jobs (
+    id UUID PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    type_business TEXT NOT NULL,
+    city TEXT NOT NULL,
+    country TEXT NOT NULL,
+    min_rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'failed')),
+    error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_jobs_fingerprint_created_at
+    ON scrape_jobs (fingerprint, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS scrape_job_requesters (
+    job_id UUID NOT NULL REFERENCES scrape_jobs(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_job_requesters_user_id_requested_at
+    ON scrape_job_requesters (user_id, requested_at DESC);
@@ -0,0 +1,8 @@
+-- Migration 0039 down: drop website liveness
+DROP INDEX IF EXISTS idx_companies_website_checked_at;
+DROP INDEX IF EXISTS idx_companies_website_status;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS website_checked_at,
+    DROP COLUMN IF EXISTS website_redirects_to,
+    DROP COLUMN IF EXISTS website_http_status,
+    DROP COLUMN IF EXISTS website_status;
@@ -0,0 +1,16 @@
+-- Migration 0039: liveness of company websites
+-- The checker stores whether the website answers (live), fails or is gone (dead), shows a domain
+-- parking page (parked) or sends visitors to another domain (redirected, with redirects_to).
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS website_status TEXT CHECK (website_status IN ('live', 'dead', 'parked', 'redirected')),
+    ADD COLUMN IF NOT EXISTS website_http_status INT,
+    ADD COLUMN IF NOT EXISTS website_redirects_to TEXT,
+    ADD COLUMN IF NOT EXISTS website_checked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_companies_website_status
+    ON companies (website_status);
+
+-- The checker picks never checked websites first, then the oldest checks.
+CREATE INDEX IF NOT EXISTS idx_companies_website_checked_at
+    ON companies (website_checked_at NULLS FIRST, id)
+    WHERE website IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Migration 0040 down: drop the website technologies index
+DROP INDEX IF EXISTS idx_company_enrichments_technologies;
@@ -0,0 +1,5 @@
+-- Migration 0040: filter companies on website technologies
+-- Enrichment stores the technologies fingerprinted on the website in metadata.technologies; the
+-- GIN index serves the ?| lookups of the tech filter.
+CREATE INDEX IF NOT EXISTS idx_company_enrichments_technologies
+    ON company_enrichments USING GIN ((metadata->'technologies'));
@@ -0,0 +1,8 @@
+-- Migration 0041 down: drop company logos and photos
+DROP INDEX IF EXISTS idx_companies_images_unmirrored;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS images_mirrored_at,
+    DROP COLUMN IF EXISTS photo_mirror_url,
+    DROP COLUMN IF EXISTS logo_mirror_url,
+    DROP COLUMN IF EXISTS photo_reference,
+    DROP COLUMN IF EXISTS logo_url;
@@ -0,0 +1,14 @@
+-- Migration 0041: company logos and photos
+-- Enrichment stores the logo found on the website and a Maps photo reference. When mirroring is
+-- enabled the API copies them to object storage; images_mirrored_at records the last attempt and
+-- is cleared when either image changes, so it is mirrored again.
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS logo_url TEXT,
+    ADD COLUMN IF NOT EXISTS photo_reference TEXT,
+    ADD COLUMN IF NOT EXISTS logo_mirror_url TEXT,
+    ADD COLUMN IF NOT EXISTS photo_mirror_url TEXT,
+    ADD COLUMN IF NOT EXISTS images_mirrored_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_companies_images_unmirrored
+    ON companies (id)
+    WHERE images_mirrored_at IS NULL AND (logo_url IS NOT NULL OR photo_reference IS NOT NULL);
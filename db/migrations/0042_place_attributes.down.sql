@@ -0,0 +1,8 @@
+-- Migration 0042 down: drop opening hours, price level and categories
+DROP INDEX IF EXISTS idx_companies_categories;
+DROP INDEX IF EXISTS idx_companies_price_level;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS categories,
+    DROP COLUMN IF EXISTS price_level,
+    DROP COLUMN IF EXISTS utc_offset_minutes,
+    DROP COLUMN IF EXISTS opening_hours;
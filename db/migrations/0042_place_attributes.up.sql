@@ -0,0 +1,16 @@
+-- Migration 0042: opening hours, price level and categories
+-- Extracted from the raw place payload when a company is stored, so listings can filter on them
+-- without reading the payload. opening_hours holds {day, open, close} periods in local time,
+-- split at midnight; utc_offset_minutes turns the current time into that local time.
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS opening_hours JSONB,
+    ADD COLUMN IF NOT EXISTS utc_offset_minutes INT,
+    ADD COLUMN IF NOT EXISTS price_level SMALLINT CHECK (price_level BETWEEN 0 AND 4),
+    ADD COLUMN IF NOT EXISTS categories TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_companies_price_level
+    ON companies (price_level)
+    WHERE price_level IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_companies_categories
+    ON companies USING GIN (categories);
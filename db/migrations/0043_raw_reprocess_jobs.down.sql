@@ -0,0 +1,2 @@
+-- Migration 0043 down: drop raw reprocess jobs
+DROP TABLE IF EXISTS raw_reprocess_jobs;
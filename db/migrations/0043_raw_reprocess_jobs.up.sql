@@ -0,0 +1,27 @@
+-- Migration 0043: background reprocessing of raw payloads with resumable progress
+CREATE TABLE IF NOT EXISTS raw_reprocess_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- query is the listing query string the job was created with; filter is the parsed filter.
+    query TEXT NOT NULL DEFAULT '',
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status TEXT NOT NULL DEFAULT 'queued',
+    total INT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    updated INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    -- resume_after is the last company reprocessed; a resumed job continues after it.
+    resume_after UUID,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_raw_reprocess_jobs_created_at
+    ON raw_reprocess_jobs (created_at DESC);
+
+-- At most one job is queued or running, so concurrent requests cannot rewrite the catalogue twice.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_raw_reprocess_jobs_active
+    ON raw_reprocess_jobs ((TRUE)) WHERE status IN ('queued', 'running');
@@ -0,0 +1,3 @@
+-- Migration 0044 down: drop enrichment contact search terms
+DROP INDEX IF EXISTS idx_company_enrichments_contact_terms;
+ALTER TABLE company_enrichments DROP COLUMN IF EXISTS contact_terms;
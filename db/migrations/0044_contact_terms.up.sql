@@ -0,0 +1,8 @@
+-- Migration 0044: search terms of enrichment contacts for q_contact
+-- contact_terms holds kind-prefixed terms: email:<lowercased address>, phone:<digits> and
+-- social:<handle>. With enrichment encryption, email and phone terms are keyed hashes of these.
+ALTER TABLE company_enrichments
+    ADD COLUMN IF NOT EXISTS contact_terms TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_company_enrichments_contact_terms
+    ON company_enrichments USING GIN (contact_terms);
@@ -0,0 +1,2 @@
+-- Migration 0045 down: drop the scrape jobs creation time index
+DROP INDEX IF EXISTS idx_scrape_jobs_created_at;
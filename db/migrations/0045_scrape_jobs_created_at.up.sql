@@ -0,0 +1,3 @@
+-- Migration 0045: index scrape jobs by creation time for the admin overview
+CREATE INDEX IF NOT EXISTS idx_scrape_jobs_created_at
+    ON scrape_jobs (created_at DESC);
@@ -0,0 +1,7 @@
+-- Migration 0046 down: drop job retry counts and failed enrichment job errors
+DROP INDEX IF EXISTS idx_scrape_jobs_failed;
+DROP INDEX IF EXISTS idx_enrichment_jobs_failed;
+ALTER TABLE scrape_jobs DROP COLUMN IF EXISTS retries;
+ALTER TABLE enrichment_jobs
+    DROP COLUMN IF EXISTS retries,
+    DROP COLUMN IF EXISTS error;
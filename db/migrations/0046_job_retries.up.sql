@@ -0,0 +1,13 @@
+-- Migration 0046: failed enrichment jobs and retry counts for the admin jobs endpoints
+-- Enrichment jobs the worker never received are kept as failed, with the reason and no cost, so an
+-- operator can retry them. retries counts how often a failed job was sent to the worker again.
+ALTER TABLE enrichment_jobs
+    ADD COLUMN IF NOT EXISTS error TEXT,
+    ADD COLUMN IF NOT EXISTS retries INT NOT NULL DEFAULT 0;
+ALTER TABLE scrape_jobs
+    ADD COLUMN IF NOT EXISTS retries INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_failed
+    ON enrichment_jobs (created_at DESC) WHERE status = 'failed';
+CREATE INDEX IF NOT EXISTS idx_scrape_jobs_failed
+    ON scrape_jobs (created_at DESC) WHERE status = 'failed';
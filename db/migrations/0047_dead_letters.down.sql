@@ -0,0 +1,2 @@
+-- Migration 0047 down: drop dead letters
+DROP TABLE IF EXISTS dead_letters;
@@ -0,0 +1,22 @@
+-- Migration 0047: dead letters for worker callbacks the API could not store
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- kind is the callback the body was posted to, e.g. enrich_result.
+    kind TEXT NOT NULL,
+    -- body is the payload as received, kept as text since it may not even be valid JSON.
+    body TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    -- details holds the rejected values per field of a payload that failed validation.
+    details JSONB,
+    company_id UUID,
+    job_id UUID,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'replayed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    replayed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_status_created_at
+    ON dead_letters (status, created_at DESC);
@@ -0,0 +1,2 @@
+-- Migration 0048 down: drop the orphan archive
+DROP TABLE IF EXISTS orphan_archive;
@@ -0,0 +1,20 @@
+-- Migration 0048: archive for enrichment rows left pointing at companies that no longer exist
+CREATE TABLE IF NOT EXISTS orphan_archive (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- run_id groups the rows archived by one reconciliation run.
+    run_id UUID NOT NULL,
+    source_table TEXT NOT NULL,
+    company_id UUID NOT NULL,
+    -- data is the archived row as JSON, so it can be restored by hand.
+    data JSONB NOT NULL,
+    -- reason is superseded (the company was scraped again and already has its own row), deleted
+    -- (the company was deleted) or missing (no trace of the company is left).
+    reason TEXT NOT NULL CHECK (reason IN ('superseded', 'deleted', 'missing')),
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_orphan_archive_run_id
+    ON orphan_archive (run_id);
+
+CREATE INDEX IF NOT EXISTS idx_orphan_archive_company_id
+    ON orphan_archive (company_id);
@@ -0,0 +1,10 @@
+-- Migration 0049 down: drop leads_view and the triggers maintaining it
+DROP TRIGGER IF EXISTS refresh_lead ON company_assignments;
+DROP TRIGGER IF EXISTS refresh_lead ON company_lead_scores;
+DROP TRIGGER IF EXISTS refresh_lead ON company_enrichments;
+DROP TRIGGER IF EXISTS refresh_lead ON companies;
+DROP FUNCTION IF EXISTS trigger_refresh_lead_related();
+DROP FUNCTION IF EXISTS trigger_refresh_lead_company();
+DROP FUNCTION IF EXISTS refresh_lead(UUID);
+DROP TABLE IF EXISTS leads_view;
+DROP VIEW IF EXISTS leads_view_source;
@@ -0,0 +1,169 @@
+-- Migration 0049: leads_view, a denormalized copy of the company listing kept current by triggers
+-- leads_view_source computes the listing row of each company: its columns as listings return them,
+-- with the enrichment summary, lead score and assignee that would otherwise be joined per query.
+CREATE OR REPLACE VIEW leads_view_source AS
+SELECT
+    c.id,
+    c.place_id,
+    c.scrape_run_id,
+    c.company,
+    c.phone,
+    c.website,
+    c.rating,
+    c.reviews,
+    c.type_business,
+    c.address,
+    c.city,
+    c.country,
+    CASE WHEN c.location IS NOT NULL THEN ST_X(c.location::geometry) END AS longitude,
+    CASE WHEN c.location IS NOT NULL THEN ST_Y(c.location::geometry) END AS latitude,
+    c.scraped_at,
+    c.created_at,
+    c.updated_at,
+    c.chain_id,
+    c.size_bucket,
+    c.business_status,
+    c.status_changed_at,
+    c.preferred_outreach_language,
+    c.outreach_language_source,
+    c.legal_form,
+    c.display_name,
+    c.shared_contact,
+    a.user_id AS assigned_to,
+    c.website_status,
+    c.website_redirects_to,
+    c.website_checked_at,
+    COALESCE(c.logo_mirror_url, c.logo_url) AS logo_url,
+    c.photo_mirror_url AS photo_url,
+    c.photo_reference,
+    c.opening_hours,
+    c.utc_offset_minutes,
+    c.price_level,
+    c.categories,
+    cardinality(ce.emails) AS email_count,
+    cardinality(ce.phones) AS phone_count,
+    COALESCE(
+        ce.socials->'linkedin'->>0, ce.socials->'facebook'->>0, ce.socials->'instagram'->>0,
+        ce.socials->'youtube'->>0, ce.socials->'tiktok'->>0, ce.socials->'twitter'->>0,
+        ce.socials->'whatsapp'->>0
+    ) AS best_social,
+    ls.score
+FROM companies c
+LEFT JOIN company_enrichments ce ON ce.company_id = c.id
+LEFT JOIN company_lead_scores ls ON ls.company_id = c.id
+LEFT JOIN company_assignments a ON a.company_id = c.id;
+
+CREATE TABLE IF NOT EXISTS leads_view (
+    id UUID PRIMARY KEY,
+    place_id TEXT,
+    scrape_run_id UUID,
+    company TEXT NOT NULL,
+    phone TEXT,
+    website TEXT,
+    rating NUMERIC(2,1),
+    reviews INT,
+    type_business TEXT,
+    address TEXT,
+    city TEXT,
+    country TEXT,
+    longitude DOUBLE PRECISION,
+    latitude DOUBLE PRECISION,
+    scraped_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    chain_id UUID,
+    size_bucket TEXT,
+    business_status TEXT,
+    status_changed_at TIMESTAMPTZ,
+    preferred_outreach_language TEXT,
+    outreach_language_source TEXT,
+    legal_form TEXT,
+    display_name TEXT,
+    shared_contact BOOLEAN NOT NULL DEFAULT FALSE,
+    assigned_to UUID,
+    website_status TEXT,
+    website_redirects_to TEXT,
+    website_checked_at TIMESTAMPTZ,
+    logo_url TEXT,
+    photo_url TEXT,
+    photo_reference TEXT,
+    opening_hours JSONB,
+    utc_offset_minutes INT,
+    price_level SMALLINT,
+    categories TEXT[],
+    email_count INT,
+    phone_count INT,
+    best_social TEXT,
+    score INT
+);
+
+INSERT INTO leads_view SELECT * FROM leads_view_source ON CONFLICT (id) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_leads_view_rating_order
+    ON leads_view (rating DESC NULLS LAST, reviews DESC NULLS LAST, company);
+CREATE INDEX IF NOT EXISTS idx_leads_view_updated_at
+    ON leads_view (updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_leads_view_city ON leads_view (city);
+CREATE INDEX IF NOT EXISTS idx_leads_view_country ON leads_view (country);
+CREATE INDEX IF NOT EXISTS idx_leads_view_type_business ON leads_view (type_business);
+CREATE INDEX IF NOT EXISTS idx_leads_view_scrape_run_id ON leads_view (scrape_run_id);
+CREATE INDEX IF NOT EXISTS idx_leads_view_assigned_to ON leads_view (assigned_to);
+
+-- refresh_lead rewrites the row of one company; a company that no longer exists loses its row.
+CREATE OR REPLACE FUNCTION refresh_lead(p_company_id UUID)
+RETURNS VOID AS $$
+BEGIN
+    DELETE FROM leads_view WHERE id = p_company_id;
+    INSERT INTO leads_view SELECT * FROM leads_view_source WHERE id = p_company_id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION trigger_refresh_lead_company()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM leads_view WHERE id = OLD.id;
+    ELSE
+        PERFORM refresh_lead(NEW.id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Rows of the joined tables refresh their company, and both companies when one is moved.
+CREATE OR REPLACE FUNCTION trigger_refresh_lead_related()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM refresh_lead(OLD.company_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.company_id IS DISTINCT FROM OLD.company_id) THEN
+        PERFORM refresh_lead(NEW.company_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS refresh_lead ON companies;
+CREATE TRIGGER refresh_lead
+AFTER INSERT OR UPDATE OR DELETE ON companies
+FOR EACH ROW
+EXECUTE FUNCTION trigger_refresh_lead_company();
+
+DROP TRIGGER IF EXISTS refresh_lead ON company_enrichments;
+CREATE TRIGGER refresh_lead
+AFTER INSERT OR UPDATE OR DELETE ON company_enrichments
+FOR EACH ROW
+EXECUTE FUNCTION trigger_refresh_lead_related();
+
+DROP TRIGGER IF EXISTS refresh_lead ON company_lead_scores;
+CREATE TRIGGER refresh_lead
+AFTER INSERT OR UPDATE OR DELETE ON company_lead_scores
+FOR EACH ROW
+EXECUTE FUNCTION trigger_refresh_lead_related();
+
+DROP TRIGGER IF EXISTS refresh_lead ON company_assignments;
+CREATE TRIGGER refresh_lead
+AFTER INSERT OR UPDATE OR DELETE ON company_assignments
+FOR EACH ROW
+EXECUTE FUNCTION trigger_refresh_lead_related();
@@ -0,0 +1,14 @@
+-- Migration 0050 down: restore the listing indexes of 0049 and drop the composite ones
+CREATE INDEX IF NOT EXISTS idx_leads_view_city ON leads_view (city);
+CREATE INDEX IF NOT EXISTS idx_leads_view_country ON leads_view (country);
+CREATE INDEX IF NOT EXISTS idx_leads_view_type_business ON leads_view (type_business);
+CREATE INDEX IF NOT EXISTS idx_leads_view_scrape_run_id ON leads_view (scrape_run_id);
+
+DROP INDEX IF EXISTS idx_leads_view_scrape_run_rating;
+DROP INDEX IF EXISTS idx_leads_view_lower_country;
+DROP INDEX IF EXISTS idx_leads_view_no_website;
+DROP INDEX IF EXISTS idx_leads_view_city_type_rating;
+DROP INDEX IF EXISTS idx_companies_scrape_run_rating;
+DROP INDEX IF EXISTS idx_companies_lower_country;
+DROP INDEX IF EXISTS idx_companies_no_website;
+DROP INDEX IF EXISTS idx_companies_city_type_rating;
@@ -0,0 +1,33 @@
+-- Migration 0050: composite and partial indexes for the common company listing filters
+-- Listings filter on LOWER(city), LOWER(type_business) and LOWER(country), which the plain column
+-- indexes of 0003 and 0049 cannot serve, and sort by rating then reviews. The indexes are built
+-- concurrently so companies stay writable; scripts/migrate.sh runs each statement on its own, as
+-- CREATE INDEX CONCURRENTLY requires.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_city_type_rating
+    ON companies (LOWER(city), LOWER(type_business), rating DESC NULLS LAST, reviews DESC NULLS LAST);
+-- Leads without a website, the prospects of GET /leads/no-website, are a small share of companies.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_no_website
+    ON companies (LOWER(city), LOWER(type_business), rating DESC NULLS LAST, reviews DESC NULLS LAST)
+    WHERE website IS NULL;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_lower_country ON companies (LOWER(country));
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_scrape_run_rating
+    ON companies (scrape_run_id, rating DESC NULLS LAST, reviews DESC NULLS LAST);
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leads_view_city_type_rating
+    ON leads_view (LOWER(city), LOWER(type_business), rating DESC NULLS LAST, reviews DESC NULLS LAST);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leads_view_no_website
+    ON leads_view (LOWER(city), LOWER(type_business), rating DESC NULLS LAST, reviews DESC NULLS LAST)
+    WHERE website IS NULL;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leads_view_lower_country ON leads_view (LOWER(country));
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_leads_view_scrape_run_rating
+    ON leads_view (scrape_run_id, rating DESC NULLS LAST, reviews DESC NULLS LAST);
+
+-- Only listings read leads_view, so the plain column indexes it was created with go unused.
+DROP INDEX CONCURRENTLY IF EXISTS idx_leads_view_city;
+DROP INDEX CONCURRENTLY IF EXISTS idx_leads_view_type_business;
+DROP INDEX CONCURRENTLY IF EXISTS idx_leads_view_country;
+DROP INDEX CONCURRENTLY IF EXISTS idx_leads_view_scrape_run_id;
+
+-- Expression indexes get statistics of their own, which the planner needs to pick them.
+ANALYZE companies;
+ANALYZE leads_view;
@@ -0,0 +1,2 @@
+-- Migration 0051 down: drop API keys
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,14 @@
+-- Migration 0051: API keys for data providers pushing companies without a user account
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    -- prefix is the start of the key, listed so a key can be recognised without storing it.
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    -- scopes are the routes the key opens, e.g. companies:write.
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
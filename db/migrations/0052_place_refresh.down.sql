@@ -0,0 +1,30 @@
+-- Migration 0052 down: drop refreshes from the snapshot history
+CREATE OR REPLACE FUNCTION trigger_record_company_snapshot()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.scrape_run_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE'
+        AND NEW.scrape_run_id IS NOT DISTINCT FROM OLD.scrape_run_id
+        AND NEW.rating IS NOT DISTINCT FROM OLD.rating
+        AND NEW.reviews IS NOT DISTINCT FROM OLD.reviews
+        AND NEW.scraped_at IS NOT DISTINCT FROM OLD.scraped_at THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO company_snapshots (company_id, scrape_run_id, rating, reviews, observed_at)
+    VALUES (NEW.id, NEW.scrape_run_id, NEW.rating, NEW.reviews, COALESCE(NEW.scraped_at, NOW()))
+    ON CONFLICT (company_id, scrape_run_id) DO UPDATE SET
+        rating = EXCLUDED.rating,
+        reviews = EXCLUDED.reviews,
+        observed_at = EXCLUDED.observed_at;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DELETE FROM company_snapshots WHERE source = 'refresh';
+ALTER TABLE company_snapshots
+    DROP COLUMN IF EXISTS previous_place_id,
+    DROP COLUMN IF EXISTS place_id,
+    DROP COLUMN IF EXISTS source;
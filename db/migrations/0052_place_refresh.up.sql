@@ -0,0 +1,33 @@
+-- Migration 0052: place id refreshes recorded in the snapshot history
+ALTER TABLE company_snapshots
+    -- source is scrape for observations of a scrape run and refresh for a place looked up again;
+    -- the scrape_run_id of a refresh is the id of the refresh.
+    ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'scrape',
+    ADD COLUMN IF NOT EXISTS place_id TEXT,
+    ADD COLUMN IF NOT EXISTS previous_place_id TEXT;
+
+-- A refresh records its own snapshot, so its write must not overwrite the observation of the
+-- company's scrape run; it sets leadsgen.skip_snapshot for its transaction.
+CREATE OR REPLACE FUNCTION trigger_record_company_snapshot()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.scrape_run_id IS NULL OR current_setting('leadsgen.skip_snapshot', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE'
+        AND NEW.scrape_run_id IS NOT DISTINCT FROM OLD.scrape_run_id
+        AND NEW.rating IS NOT DISTINCT FROM OLD.rating
+        AND NEW.reviews IS NOT DISTINCT FROM OLD.reviews
+        AND NEW.scraped_at IS NOT DISTINCT FROM OLD.scraped_at THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO company_snapshots (company_id, scrape_run_id, rating, reviews, observed_at)
+    VALUES (NEW.id, NEW.scrape_run_id, NEW.rating, NEW.reviews, COALESCE(NEW.scraped_at, NOW()))
+    ON CONFLICT (company_id, scrape_run_id) DO UPDATE SET
+        rating = EXCLUDED.rating,
+        reviews = EXCLUDED.reviews,
+        observed_at = EXCLUDED.observed_at;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
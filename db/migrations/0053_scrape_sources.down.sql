@@ -0,0 +1,2 @@
+-- Migration 0053 down: drop the scrape job source
+ALTER TABLE scrape_jobs DROP COLUMN IF EXISTS source;
@@ -0,0 +1,2 @@
+-- Migration 0053: the source provider a scrape job runs against; jobs so far all scraped Google Maps
+ALTER TABLE scrape_jobs ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'google_maps';
@@ -0,0 +1,2 @@
+-- Migration 0054 down: drop the location catalog
+DROP TABLE IF EXISTS locations;
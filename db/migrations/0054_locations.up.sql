@@ -0,0 +1,31 @@
+-- Migration 0054: catalog of cities that free-text city values are normalised against
+CREATE TABLE IF NOT EXISTS locations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    city TEXT NOT NULL,
+    region TEXT,
+    country TEXT NOT NULL,
+    -- aliases are other spellings of the city, lowercased, e.g. jogja for Yogyakarta.
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    -- The centre and bounding box found by the geocoder; NULL until the city is geocoded.
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    south DOUBLE PRECISION,
+    west DOUBLE PRECISION,
+    north DOUBLE PRECISION,
+    east DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_city_country ON locations (lower(city), lower(country));
+CREATE INDEX IF NOT EXISTS idx_locations_aliases ON locations USING GIN (aliases);
+
+-- The cities the prompt parser already knew spellings of.
+INSERT INTO locations (city, region, country, aliases) VALUES
+    ('Jakarta', 'DKI Jakarta', 'Indonesia', ARRAY['dki jakarta', 'jkt']),
+    ('Yogyakarta', 'DI Yogyakarta', 'Indonesia', ARRAY['jogja', 'yogya', 'jogjakarta', 'djogja']),
+    ('Surakarta', 'Jawa Tengah', 'Indonesia', ARRAY['solo']),
+    ('Surabaya', 'Jawa Timur', 'Indonesia', ARRAY['sby']),
+    ('Bandung', 'Jawa Barat', 'Indonesia', ARRAY['bdg']),
+    ('Denpasar', 'Bali', 'Indonesia', ARRAY['dps'])
+ON CONFLICT DO NOTHING;
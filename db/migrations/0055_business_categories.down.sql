@@ -0,0 +1,2 @@
+-- Migration 0055 down: drop the business category taxonomy
+DROP TABLE IF EXISTS business_categories;
@@ -0,0 +1,40 @@
+-- Migration 0055: taxonomy of business categories that free-text business types are normalised against
+CREATE TABLE IF NOT EXISTS business_categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- name is the category in the form of Places types, e.g. restaurant; label is how it is shown.
+    name TEXT NOT NULL,
+    label TEXT NOT NULL,
+    -- parent_id groups categories, e.g. restaurant under food_and_drink; filtering by a parent
+    -- matches its descendants.
+    parent_id UUID REFERENCES business_categories(id) ON DELETE SET NULL,
+    -- synonyms are other words for the category, lowercased, e.g. resto and rumah makan.
+    synonyms TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT business_categories_name_key UNIQUE (name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_categories_parent ON business_categories (parent_id);
+
+INSERT INTO business_categories (name, label) VALUES
+    ('food_and_drink', 'Food & Drink'),
+    ('health', 'Health'),
+    ('lodging', 'Lodging'),
+    ('services', 'Services')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO business_categories (name, label, parent_id, synonyms)
+SELECT seed.name, seed.label, parent.id, seed.synonyms
+FROM (VALUES
+    ('restaurant', 'Restaurant', 'food_and_drink', ARRAY['resto', 'restoran', 'rumah makan', 'warung makan']),
+    ('cafe', 'Cafe', 'food_and_drink', ARRAY['kafe', 'coffee shop', 'kedai kopi', 'warkop']),
+    ('bakery', 'Bakery', 'food_and_drink', ARRAY['toko roti', 'toko kue']),
+    ('dentist', 'Dentist', 'health', ARRAY['dokter gigi', 'klinik gigi']),
+    ('pharmacy', 'Pharmacy', 'health', ARRAY['apotek', 'apotik']),
+    ('hotel', 'Hotel', 'lodging', ARRAY['penginapan', 'losmen']),
+    ('beauty_salon', 'Beauty Salon', 'services', ARRAY['salon', 'salon kecantikan']),
+    ('laundry', 'Laundry', 'services', ARRAY['binatu', 'laundry kiloan']),
+    ('car_repair', 'Car Repair', 'services', ARRAY['bengkel', 'bengkel mobil'])
+) AS seed(name, label, parent, synonyms)
+JOIN business_categories parent ON parent.name = seed.parent
+ON CONFLICT (name) DO NOTHING;
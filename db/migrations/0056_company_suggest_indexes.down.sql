@@ -0,0 +1,4 @@
+-- Migration 0056 down: drop the suggestion prefix indexes
+DROP INDEX IF EXISTS idx_companies_company_prefix;
+DROP INDEX IF EXISTS idx_companies_type_business_prefix;
+DROP INDEX IF EXISTS idx_companies_city_prefix;
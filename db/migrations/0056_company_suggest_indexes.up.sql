@@ -0,0 +1,10 @@
+-- Migration 0056: prefix indexes for the typeahead suggestions of GET /companies/suggest
+-- Suggestions match LOWER(column) LIKE 'prefix%'. Under a non-C collation a plain expression index
+-- cannot serve LIKE, so these use text_pattern_ops. They are built concurrently so companies stay
+-- writable; scripts/migrate.sh runs each statement on its own, as CREATE INDEX CONCURRENTLY requires.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_city_prefix ON companies (LOWER(city) text_pattern_ops);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_type_business_prefix ON companies (LOWER(type_business) text_pattern_ops);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_companies_company_prefix ON companies (LOWER(company) text_pattern_ops);
+
+-- Expression indexes get statistics of their own, which the planner needs to pick them.
+ANALYZE companies;
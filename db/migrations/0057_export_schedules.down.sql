@@ -0,0 +1,3 @@
+-- Migration 0057 down: drop scheduled exports
+DROP TABLE IF EXISTS export_deliveries;
+DROP TABLE IF EXISTS export_schedules;
@@ -0,0 +1,37 @@
+-- Migration 0057: scheduled exports and their deliveries
+-- A schedule exports the companies matching a saved listing filter, in the locale of an export
+-- template, on a cron schedule, and delivers the file by email, to object storage or to a webhook.
+CREATE TABLE IF NOT EXISTS export_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    template_id UUID REFERENCES export_templates(id) ON DELETE SET NULL,
+    destination TEXT NOT NULL CHECK (destination IN ('email', 'gcs', 'webhook')),
+    target TEXT NOT NULL,
+    cron TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT export_schedules_user_id_name_key UNIQUE (user_id, name)
+);
+
+-- The runner polls for enabled schedules that are due.
+CREATE INDEX IF NOT EXISTS idx_export_schedules_due ON export_schedules (next_run_at) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS export_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES export_schedules(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('delivered', 'failed')),
+    row_count BIGINT NOT NULL DEFAULT 0,
+    file_name TEXT NOT NULL,
+    location TEXT,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_deliveries_schedule ON export_deliveries (schedule_id, started_at DESC);
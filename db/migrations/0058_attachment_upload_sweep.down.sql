@@ -0,0 +1,2 @@
+-- Migration 0058 down: drop the pending upload index
+DROP INDEX IF EXISTS idx_company_attachments_pending;
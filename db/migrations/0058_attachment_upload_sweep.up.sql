@@ -0,0 +1,4 @@
+-- Migration 0058: find pending attachment uploads whose signed URL expired, for the sweeper
+CREATE INDEX IF NOT EXISTS idx_company_attachments_pending
+    ON company_attachments (created_at)
+    WHERE status = 'pending';
@@ -0,0 +1,2 @@
+-- Migration 0059 down: drop the change sink cursors
+DROP TABLE IF EXISTS change_sink_cursors;
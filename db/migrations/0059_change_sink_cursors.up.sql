@@ -0,0 +1,8 @@
+-- Migration 0059: per-sink cursors into change_events for the streaming exporters
+CREATE TABLE IF NOT EXISTS change_sink_cursors (
+    sink TEXT PRIMARY KEY,
+    -- Last exported event, in the (tx_id, id) order of the change feed.
+    tx_id XID8 NOT NULL,
+    event_id BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
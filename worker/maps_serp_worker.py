@@ -1,49 +1,113 @@
-"""Worker entrypoint that bridges SerpAPI Google Maps results with the Go ingest API."""
+"""Worker entrypoint that bridges SerpAPI Google Maps results with the Go ingest run API."""
 
 from __future__ import annotations
 
 import argparse
+import json
 import logging
+import time
 from dataclasses import asdict
+from pathlib import Path
 from typing import Dict, List, Optional
 
 import requests
 
 from config import ConfigError, get_settings
 from models import CompanyCandidate
-from serp_client import fetch_from_serpapi, parse_serpapi_maps
+from sources import DEFAULT_SOURCE, SourceAdapter, get_adapter
 
 logger = logging.getLogger(__name__)
 logging.basicConfig(level=logging.INFO, format="%(asctime)s %(levelname)s %(name)s - %(message)s")
 
 
-def to_ingest_payload(candidates: List[CompanyCandidate]) -> Dict[str, List[Dict[str, object]]]:
-    """Convert CompanyCandidate objects into the JSON payload accepted by the Go ingest API."""
+def to_ingest_payload(
+    candidates: List[CompanyCandidate], adapter: Optional[SourceAdapter] = None
+) -> Dict[str, object]:
+    """Convert CompanyCandidate objects into the JSON payload accepted by the Go ingest API.
+
+    Every item names the source it was scraped from and, as place_id, the key the source's
+    adapter gives it, which the API dedupes the place on.
+    """
+    adapter = adapter or get_adapter()
     items: List[Dict[str, object]] = []
     for candidate in candidates:
         entry = asdict(candidate)
         if entry.get("raw_snapshot") is None:
             entry["raw_snapshot"] = {}
+        entry["source"] = adapter.name
+        place_id = adapter.dedupe_key(candidate)
+        if place_id:
+            entry["place_id"] = place_id
         items.append(entry)
     return {"items": items}
 
 
-def send_to_ingest_api(payload: Dict[str, object]):
-    """POST normalized candidates to the Go ingest endpoint.
+# INGEST_BATCH_SIZE places go in each batch of an ingest run; the API takes up to 500.
+INGEST_BATCH_SIZE = 100
+
+# INGEST_RESUMES is how many times a failed run is resumed from its next_sequence before the
+# payload is saved for replay.
+INGEST_RESUMES = 3
+
+
+class IngestError(RuntimeError):
+    """Raised when the ingest API answers a run call with a non-2xx status."""
+
+    def __init__(self, response: requests.Response):
+        super().__init__(f"ingest API returned {response.status_code}: {response.text[:500]}")
+        self.response = response
+
+
+def _post_ingest(session: requests.Session, url: str, body: Dict[str, object], headers: Dict[str, str]) -> Dict[str, object]:
+    response = session.post(url, json=body, timeout=10, headers=headers)
+    if not (200 <= response.status_code < 300):
+        raise IngestError(response)
+    return response.json().get("data") or {}
+
+
+def _save_failed_payload(payload: Dict[str, object], error: Exception) -> None:
+    """Write payload to worker/data/failed/*.json for scripts/replay_failed.py."""
+    failed_dir = Path(__file__).resolve().parent.joinpath("data", "failed")
+    try:
+        failed_dir.mkdir(parents=True, exist_ok=True)
+        fname = failed_dir.joinpath(f"failed-{int(time.time())}.json")
+        with fname.open("w", encoding="utf-8") as fh:
+            json.dump({"error": str(error), "payload": payload}, fh, ensure_ascii=False, indent=2)
+        logger.info("Saved failed payload to %s", str(fname))
+    except Exception as e:
+        logger.error("Failed to save failed payload to disk: %s", e)
+
+
+def send_to_ingest_api(
+    payload: Dict[str, object],
+    callback_token: Optional[str] = None,
+    trace_headers: Optional[Dict[str, str]] = None,
+    save_failed: bool = True,
+) -> Optional[Dict[str, object]]:
+    """Stream normalized candidates to the Go ingest run endpoints under INGEST_API_URL.
+
+    payload holds the items and the run_id, source and query of the run. The run is started, or
+    resumed when run_id is already open, its items are appended in numbered batches from the
+    run's next_sequence, and it is finished with the number of batches sent. A failed call
+    resumes the run from the next_sequence the API reports, so batches it already stored are not
+    sent again.
+
+    callback_token is the token the API sent with the scrape job; it is presented as a bearer
+    token on every call so the API can tie the results to that job. trace_headers carry the W3C
+    trace context of the API call that queued the job and are forwarded on every call too.
 
     Defensive behavior:
     - Uses a requests.Session configured with retries for transient failures.
-    - On persistent failure writes payload to worker/data/failed/*.json for later replay.
-    - Returns the Response on success, Response on non-2xx, or None on network failure.
+    - After INGEST_RESUMES failed resumes writes payload, with the run_id it was streamed under,
+      to worker/data/failed/*.json for later replay, unless save_failed is False.
+    - Returns the finished run, or None when streaming failed.
     """
-    from pathlib import Path
     from requests.adapters import HTTPAdapter
     from urllib3.util.retry import Retry
-    import time, json
 
     settings = get_settings()
-    ingest_url = settings.ingest_api_url
-    logger.info("INGEST_API_URL=%s", ingest_url)
+    runs_url = settings.ingest_api_url.rstrip("/")
+    logger.info("INGEST_API_URL=%s", runs_url)
 
     # Configure session with retries for networking/5xx errors
     session = requests.Session()
@@ -56,41 +120,50 @@ def send_to_ingest_api(payload: Dict[str, object]):
     session.mount("http://", HTTPAdapter(max_retries=retries))
     session.mount("https://", HTTPAdapter(max_retries=retries))
 
-    try:
-        response = session.post(ingest_url, json=payload, timeout=10)
-    except requests.RequestException as exc:
-        logger.error("Failed to call ingest API: %s", exc)
-        # Persist payload for later replay
-        failed_dir = Path(__file__).resolve().parent.joinpath("data", "failed")
-        try:
-            failed_dir.mkdir(parents=True, exist_ok=True)
-            fname = failed_dir.joinpath(f"failed-{int(time.time())}.json")
-            with fname.open("w", encoding="utf-8") as fh:
-                json.dump(payload, fh, ensure_ascii=False, indent=2)
-            logger.info("Saved failed payload to %s", str(fname))
-        except Exception as e:
-            logger.error("Failed to save failed payload to disk: %s", e)
-        # Do not raise here so worker continues; caller can inspect return value
-        return None
-
-    # If remote returned non-2xx save payload too for inspection
-    if not (200 <= response.status_code < 300):
-        logger.error(
-            "Ingest API returned non-2xx status (%s): %s", response.status_code, response.text[:500]
-        )
-        try:
-            failed_dir = Path(__file__).resolve().parent.joinpath("data", "failed")
-            failed_dir.mkdir(parents=True, exist_ok=True)
-            fname = failed_dir.joinpath(f"failed-{int(time.time())}.json")
-            with fname.open("w", encoding="utf-8") as fh:
-                json.dump({"status": response.status_code, "text": response.text, "payload": payload}, fh, ensure_ascii=False, indent=2)
-            logger.info("Saved failed payload (non-2xx) to %s", str(fname))
-        except Exception as e:
-            logger.error("Failed to save non-2xx payload: %s", e)
-        # Return the response so caller can inspect status/text if needed
-        return response
+    headers = dict(trace_headers or {})
+    if callback_token:
+        headers["Authorization"] = f"Bearer {callback_token}"
 
-    return response
+    items = list(payload.get("items") or [])
+    batches = [items[i:i + INGEST_BATCH_SIZE] for i in range(0, len(items), INGEST_BATCH_SIZE)]
+    payload = dict(payload)
+    for attempt in range(INGEST_RESUMES + 1):
+        try:
+            try:
+                run = _post_ingest(session, runs_url, {
+                    "run_id": payload.get("run_id") or "",
+                    "source": payload.get("source") or "",
+                    "query": payload.get("query") or "",
+                }, headers)
+            except IngestError as exc:
+                # A finish whose answer was lost leaves nothing to send: the run is already finished.
+                if exc.response.status_code != 409 or not payload.get("run_id"):
+                    raise
+                response = session.get(f"{runs_url}/{payload['run_id']}", timeout=10, headers=headers)
+                if not (200 <= response.status_code < 300):
+                    raise IngestError(response) from exc
+                finished = response.json().get("data") or {}
+                if finished.get("status") != "finished":
+                    raise
+                return finished
+            # Later attempts and replays resume this run rather than open another one.
+            payload["run_id"] = run["id"]
+            for sequence in range(int(run.get("next_sequence") or 0), len(batches)):
+                _post_ingest(session, f"{runs_url}/{run['id']}/batches", {
+                    "sequence": sequence,
+                    "items": batches[sequence],
+                }, headers)
+            return _post_ingest(session, f"{runs_url}/{run['id']}/finish", {"total_batches": len(batches)}, headers)
+        except (requests.RequestException, IngestError, ValueError, KeyError) as exc:
+            # Resuming helps after lost calls and runs missing batches, not refused requests.
+            refused = isinstance(exc, IngestError) and 400 <= exc.response.status_code < 500 and exc.response.status_code != 409
+            if refused or attempt == INGEST_RESUMES:
+                logger.error("Failed to stream run %s to ingest API: %s", payload.get("run_id"), exc)
+                if save_failed:
+                    _save_failed_payload(payload, exc)
+                return None
+            logger.warning("Ingest run %s failed (%s); resuming from its next sequence", payload.get("run_id"), exc)
+    return None
 
 
 def run_scrape(
@@ -99,8 +172,12 @@ def run_scrape(
     min_rating: Optional[float] = None,
     limit: Optional[int] = None,
     require_no_website: bool = False,
+    run_id: Optional[str] = None,
+    callback_token: Optional[str] = None,
+    trace_headers: Optional[Dict[str, str]] = None,
+    source: str = DEFAULT_SOURCE,
 ) -> None:
-    """Full pipeline: fetch from SerpAPI, normalize candidates, and send to the ingest API.
+    """Full pipeline: search the source, normalize candidates, and send to the ingest API.
 
     Callers should dedupe or cache identical queries upstream to avoid burning through SerpAPI
     credits and to stay within SerpAPI's rate limits. We still log query-level stats here so
@@ -112,11 +189,15 @@ def run_scrape(
         min_rating: Optional minimum rating filter (e.g., 4.5)
         limit: Optional maximum number of results to return
         require_no_website: If True, filter out candidates with websites
+        run_id: Optional ingest run the API assigned to the job
+        callback_token: Optional token the API issued for the job's callbacks
+        trace_headers: Optional W3C trace context forwarded on the ingest run calls
+        source: Name of the source to search (see sources.SOURCES), Google Maps by default
     """
-    logger.info("Starting Stage 1 scrape for query=%s ll=%s", query, ll)
-    raw_data = fetch_from_serpapi(query, ll)
-    candidates = parse_serpapi_maps(raw_data)
-    logger.info("Parsed %s company candidates from SerpAPI response.", len(candidates))
+    adapter = get_adapter(source)
+    logger.info("Starting Stage 1 scrape for query=%s ll=%s source=%s", query, ll, adapter.name)
+    candidates = adapter.search(query, ll)
+    logger.info("Parsed %s company candidates from %s.", len(candidates), adapter.name)
 
     if not candidates:
         logger.warning("No candidates found for query=%s. Skipping ingest.", query)
@@ -143,12 +224,16 @@ def run_scrape(
         logger.warning("No candidates remaining after filters for query=%s. Skipping ingest.", query)
         return
 
-    payload = to_ingest_payload(candidates)
-    response = send_to_ingest_api(payload)
-    if response is not None:
-        logger.info("Posted %s candidates to ingest API. status=%s", len(candidates), response.status_code)
+    payload = to_ingest_payload(candidates, adapter)
+    payload["source"] = adapter.name
+    payload["query"] = query
+    if run_id:
+        payload["run_id"] = run_id
+    run = send_to_ingest_api(payload, callback_token=callback_token, trace_headers=trace_headers)
+    if run is not None:
+        logger.info("Streamed %s candidates to ingest run %s in %s batches.", len(candidates), run.get("id"), run.get("batches"))
     else:
-        logger.warning("Failed to post %s candidates to ingest API (see errors above).", len(candidates))
+        logger.warning("Failed to stream %s candidates to ingest API (see errors above).", len(candidates))
 
 
 def _parse_cli_args() -> argparse.Namespace:
@@ -159,13 +244,14 @@ def _parse_cli_args() -> argparse.Namespace:
         help="Optional SerpAPI ll parameter in the form '@lat,lng,zoom' (e.g. '@-7.7956,110.3695,13z')",
         default=None,
     )
+    parser.add_argument("--source", help="Source to search (default: google_maps)", default=DEFAULT_SOURCE)
     return parser.parse_args()
 
 
 if __name__ == "__main__":
     args = _parse_cli_args()
     try:
-        run_scrape(args.query, args.ll)
+        run_scrape(args.query, args.ll, source=args.source)
     except ConfigError as exc:
         logger.error("Configuration error: %s", exc)
         raise SystemExit(2) from exc
@@ -1,20 +1,35 @@
-import os, json, requests, time
-FOLDER = "worker/data/failed"
-URL = "http://localhost:8080/ingest/places"   # change to your ingestion URL if needed
+"""Replay the payloads the worker saved to worker/data/failed after an ingest run failed.
 
-if not os.path.isdir(FOLDER):
+Each payload is streamed again under the run_id it was saved with, so the run resumes from the
+batches the API already stored. Set INGEST_CALLBACK_TOKEN to a token from
+`apiadmin callback-token --kind scrape --job <run_id>` when the API guards /ingest/runs.
+"""
+
+import json
+import os
+import sys
+import time
+from pathlib import Path
+
+ROOT = Path(__file__).resolve().parents[1]
+sys.path.insert(0, str(ROOT))
+
+from maps_serp_worker import send_to_ingest_api  # noqa: E402
+
+FOLDER = ROOT.joinpath("data", "failed")
+
+if not FOLDER.is_dir():
     print("No failed folder:", FOLDER)
     raise SystemExit(0)
 
-for fname in sorted(os.listdir(FOLDER)):
-    path = os.path.join(FOLDER, fname)
-    try:
-        with open(path, "r", encoding="utf-8") as fh:
-            payload = json.load(fh)
-        r = requests.post(URL, json=payload, timeout=10)
-        r.raise_for_status()
-        print("Replayed", fname, "=>", r.status_code)
-        os.remove(path)
-    except Exception as e:
-        print("Failed to replay", fname, e)
+for path in sorted(FOLDER.glob("*.json")):
+    with path.open("r", encoding="utf-8") as fh:
+        saved = json.load(fh)
+    payload = saved.get("payload", saved)
+    run = send_to_ingest_api(payload, callback_token=os.getenv("INGEST_CALLBACK_TOKEN") or None, save_failed=False)
+    if run is None:
+        print("Failed to replay", path.name)
         time.sleep(1)
+        continue
+    print("Replayed", path.name, "=> run", run.get("id"))
+    path.unlink()
@@ -0,0 +1,66 @@
+"""Scrape sources: the place providers a scrape can be run against.
+
+Every provider is wired in as a SourceAdapter that searches it and names the key each place is
+stored under by the API. Google Maps (through SerpAPI) is the only source so far; another provider
+(Bing Places, OpenStreetMap/Overpass, Yelp...) is added by registering an adapter in SOURCES and
+the matching adapter on the API side.
+"""
+
+from __future__ import annotations
+
+from abc import ABC, abstractmethod
+from typing import Dict, List, Optional
+
+from models import CompanyCandidate
+from serp_client import fetch_from_serpapi, parse_serpapi_maps
+
+DEFAULT_SOURCE = "google_maps"
+
+
+class SourceAdapter(ABC):
+    """A place provider scrapes can be run against."""
+
+    #: Name of the source in scrape requests and ingested items.
+    name: str
+
+    @abstractmethod
+    def search(self, query: str, ll: Optional[str] = None) -> List[CompanyCandidate]:
+        """Return the places matching a free-text query such as 'cafe in Jakarta, Indonesia'."""
+
+    @abstractmethod
+    def dedupe_key(self, candidate: CompanyCandidate) -> Optional[str]:
+        """Return the id the source gives the place, which the API stores it under.
+
+        Scraping the place again must return the same id, so it updates the company instead of
+        adding a duplicate. None when the provider returned no id.
+        """
+
+
+class GoogleMapsAdapter(SourceAdapter):
+    """Google Maps results fetched through SerpAPI, keyed by their Google place id."""
+
+    name = "google_maps"
+
+    def search(self, query: str, ll: Optional[str] = None) -> List[CompanyCandidate]:
+        return parse_serpapi_maps(fetch_from_serpapi(query, ll))
+
+    def dedupe_key(self, candidate: CompanyCandidate) -> Optional[str]:
+        place_id = (candidate.raw_snapshot or {}).get("place_id")
+        if not place_id:
+            return None
+        return str(place_id).strip() or None
+
+
+SOURCES: Dict[str, SourceAdapter] = {adapter.name: adapter for adapter in (GoogleMapsAdapter(),)}
+
+
+def get_adapter(name: Optional[str] = None) -> SourceAdapter:
+    """Return the adapter of a source, the default one when name is empty.
+
+    Raises ValueError for a source that is not registered.
+    """
+    key = (name or DEFAULT_SOURCE).strip().lower()
+    try:
+        return SOURCES[key]
+    except KeyError:
+        raise ValueError(f"unknown source {name!r} (expected one of {', '.join(sorted(SOURCES))})") from None
@@ -18,6 +18,8 @@ class Settings:
     worker_port: int = 9000
     max_pages: int = 3
     enrich_callback_url: str = ""
+    enrich_callback_grpc_addr: str = ""
+    enrich_callback_grpc_tls: bool = False
     default_phone_region: Optional[str] = None
     enrich_use_js_renderer: bool = False
 
@@ -32,6 +34,10 @@ def get_settings() -> Settings:
     worker_port = int(os.getenv("WORKER_PORT", "9000"))
     max_pages = int(os.getenv("WORKER_MAX_PAGES", "3"))
     enrich_callback_url = os.getenv("ENRICH_CALLBACK_URL", "")
+    # With ENRICH_CALLBACK_GRPC_ADDR set, the gRPC server reports enrichment results over the
+    # WorkerCallbacks service of the API instead of POSTing them to ENRICH_CALLBACK_URL.
+    enrich_callback_grpc_addr = os.getenv("ENRICH_CALLBACK_GRPC_ADDR", "").strip()
+    enrich_callback_grpc_tls = os.getenv("ENRICH_CALLBACK_GRPC_TLS", "false").lower() in {"1", "true", "yes"}
     default_phone_region_raw = os.getenv("DEFAULT_PHONE_REGION")
     default_phone_region = default_phone_region_raw.strip().upper() if default_phone_region_raw else None
     enrich_use_js_renderer = os.getenv("ENRICH_USE_JS_RENDERER", "false").lower() in {"1", "true", "yes"}
@@ -40,7 +46,7 @@ def get_settings() -> Settings:
         logger.warning("DATABASE_URL is not set; database operations will fail.")
     if not google_api_key:
         logger.warning("GOOGLE_API_KEY is not configured; Google Places requests will fail.")
-    if not enrich_callback_url:
+    if not enrich_callback_url and not enrich_callback_grpc_addr:
         logger.warning("ENRICH_CALLBACK_URL is not configured; enrichment callbacks will be skipped.")
 
     return Settings(
@@ -49,6 +55,8 @@ def get_settings() -> Settings:
         worker_port=worker_port,
         max_pages=max_pages,
         enrich_callback_url=enrich_callback_url,
+        enrich_callback_grpc_addr=enrich_callback_grpc_addr,
+        enrich_callback_grpc_tls=enrich_callback_grpc_tls,
         default_phone_region=default_phone_region,
         enrich_use_js_renderer=enrich_use_js_renderer,
     )
@@ -8,6 +8,7 @@ import psycopg2
 from psycopg2 import extras, pool
 
 from src.core.config import get_settings
+from src.core.legal_form import extract
 
 logger = logging.getLogger(__name__)
 
@@ -47,6 +48,10 @@ def _prepare_params(row: Dict[str, Any]) -> Dict[str, Any]:
     if scrape_run_id is not None:
         scrape_run_id = str(scrape_run_id)
 
+    # Names are cleaned like the API cleans them, so rows without a place id
+    # keep matching on (display_name, address) after recompute-legal-forms.
+    extraction = extract(row["company"]) if row.get("company") else None
+
     return {
         "place_id": row.get("place_id"),
         "company": row.get("company"),
@@ -55,6 +60,7 @@ def _prepare_params(row: Dict[str, Any]) -> Dict[str, Any]:
         "rating": row.get("rating"),
         "reviews": row.get("reviews"),
         "type_business": row.get("type_business"),
+        "categories": row.get("categories") or None,
         "address": row.get("address"),
         "city": row.get("city"),
         "country": row.get("country"),
@@ -63,6 +69,8 @@ def _prepare_params(row: Dict[str, Any]) -> Dict[str, Any]:
         "raw": extras.Json(row.get("raw") or {}),
         "scrape_run_id": scrape_run_id,
         "scraped_at": row.get("scraped_at"),
+        "display_name": extraction.display_name if extraction else None,
+        "legal_form": extraction.form if extraction else None,
     }
 
 
@@ -75,6 +83,7 @@ INSERT INTO companies (
     rating,
     reviews,
     type_business,
+    categories,
     address,
     city,
     country,
@@ -82,6 +91,8 @@ INSERT INTO companies (
     raw,
     scrape_run_id,
     scraped_at,
+    display_name,
+    legal_form,
     updated_at
 ) VALUES (
     %(place_id)s,
@@ -91,6 +102,7 @@ INSERT INTO companies (
     %(rating)s,
     %(reviews)s,
     %(type_business)s,
+    %(categories)s::text[],
     %(address)s,
     %(city)s,
     %(country)s,
@@ -100,6 +112,8 @@ INSERT INTO companies (
     %(raw)s,
     %(scrape_run_id)s,
     %(scraped_at)s,
+    %(display_name)s,
+    %(legal_form)s,
     NOW()
 )
 ON CONFLICT (place_id) DO UPDATE SET
@@ -109,13 +123,19 @@ ON CONFLICT (place_id) DO UPDATE SET
     rating = EXCLUDED.rating,
     reviews = EXCLUDED.reviews,
     type_business = EXCLUDED.type_business,
+    categories = EXCLUDED.categories,
     address = EXCLUDED.address,
     city = EXCLUDED.city,
     country = EXCLUDED.country,
     location = EXCLUDED.location,
     raw = EXCLUDED.raw,
+    raw_ref = NULL,
     scrape_run_id = COALESCE(EXCLUDED.scrape_run_id, companies.scrape_run_id),
     scraped_at = COALESCE(EXCLUDED.scraped_at, companies.scraped_at),
+    display_name = CASE WHEN companies.company = EXCLUDED.company
+        THEN companies.display_name ELSE EXCLUDED.display_name END,
+    legal_form = CASE WHEN companies.company = EXCLUDED.company
+        THEN companies.legal_form ELSE EXCLUDED.legal_form END,
     updated_at = NOW();
 """
 
@@ -127,6 +147,7 @@ INSERT INTO companies (
     rating,
     reviews,
     type_business,
+    categories,
     address,
     city,
     country,
@@ -134,6 +155,8 @@ INSERT INTO companies (
     raw,
     scrape_run_id,
     scraped_at,
+    display_name,
+    legal_form,
     updated_at
 ) VALUES (
     %(company)s,
@@ -142,6 +165,7 @@ INSERT INTO companies (
     %(rating)s,
     %(reviews)s,
     %(type_business)s,
+    %(categories)s::text[],
     %(address)s,
     %(city)s,
     %(country)s,
@@ -151,20 +175,25 @@ INSERT INTO companies (
     %(raw)s,
     %(scrape_run_id)s,
     %(scraped_at)s,
+    %(display_name)s,
+    %(legal_form)s,
     NOW()
 )
-ON CONFLICT (company, address) WHERE place_id IS NULL DO UPDATE SET
+ON CONFLICT (display_name, address) WHERE place_id IS NULL DO UPDATE SET
     phone = EXCLUDED.phone,
     website = EXCLUDED.website,
     rating = EXCLUDED.rating,
     reviews = EXCLUDED.reviews,
     type_business = EXCLUDED.type_business,
+    categories = EXCLUDED.categories,
     city = EXCLUDED.city,
     country = EXCLUDED.country,
     location = EXCLUDED.location,
     raw = EXCLUDED.raw,
+    raw_ref = NULL,
     scrape_run_id = COALESCE(EXCLUDED.scrape_run_id, companies.scrape_run_id),
     scraped_at = COALESCE(EXCLUDED.scraped_at, companies.scraped_at),
+    legal_form = EXCLUDED.legal_form,
     updated_at = NOW();
 """
 
@@ -0,0 +1,69 @@
+"""Legal form extraction from company names, mirroring the API's legalform package.
+
+Rows without a place id are matched on (display_name, address), so the worker must clean names
+exactly like the API and apiadmin recompute-legal-forms do; keep both in step.
+"""
+
+import re
+from typing import NamedTuple, Optional
+
+# A legal form at the start of a name. Forms must be followed by a dot or a space, so names such
+# as "Ptolemy" or "Cvetko" are left alone; Fa. and Kop. need their dot, as they double as words.
+_PREFIX = re.compile(
+    r"^(?:(p\.?\s?t|c\.?\s?v|u\.?\s?d|p\.?\s?d|firma|koperasi|yayasan|perum)(?:\.\s*|\s+)|(fa|kop)\.\s*)",
+    re.IGNORECASE | re.ASCII,
+)
+
+# A legal form appended after a comma, as in "Maju Jaya, PT".
+_SUFFIX = re.compile(r",\s*(p\.?\s?t|c\.?\s?v|u\.?\s?d|p\.?\s?d)\.?$", re.IGNORECASE | re.ASCII)
+
+# The state ownership and listing markers trailing PT names.
+_MARKERS = re.compile(r"(\s*\(persero\)|\s*,?\s*\btbk\.?)+$", re.IGNORECASE | re.ASCII)
+
+_FORMS = {
+    "pt": "PT", "cv": "CV", "ud": "UD", "pd": "PD",
+    "fa": "Firma", "firma": "Firma", "koperasi": "Koperasi", "kop": "Koperasi",
+    "yayasan": "Yayasan", "perum": "Perum",
+}
+
+
+class Extraction(NamedTuple):
+    """A company name split into its legal form, None when it carries none, and display name."""
+
+    form: Optional[str]
+    display_name: str
+
+
+def _key(raw: str) -> str:
+    return raw.strip().lower().replace(".", "").replace(" ", "")
+
+
+def extract(name: str) -> Extraction:
+    """Split name into its legal form and display name.
+
+    A name that is nothing but a legal form keeps it as display name.
+    """
+    name = " ".join(name.split())
+    form = None
+
+    rest = name
+    match = _PREFIX.match(rest)
+    if match:
+        form = _FORMS.get(_key(match.group(1) or match.group(2)))
+        rest = rest[match.end():]
+    else:
+        match = _SUFFIX.search(rest)
+        if match:
+            form = _FORMS.get(_key(match.group(1)))
+            rest = rest[:match.start()]
+    stripped = _MARKERS.sub("", rest)
+    if stripped != rest:
+        if form is None:
+            # Only limited companies are listed or state owned.
+            form = "PT"
+        rest = stripped
+
+    rest = rest.strip(" ,.-")
+    if not rest:
+        return Extraction(None, name)
+    return Extraction(form, rest)
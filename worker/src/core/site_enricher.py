@@ -35,6 +35,8 @@ SOCIAL_HOSTS = {
     "instagram": ("instagram.com", "instagr.am"),
     "youtube": ("youtube.com", "youtu.be"),
     "tiktok": ("tiktok.com",),
+    "twitter": ("twitter.com", "x.com"),
+    "whatsapp": ("wa.me", "api.whatsapp.com"),
 }
 CONTACT_PAGE_CANDIDATES = (
     "/contact",
@@ -50,6 +52,48 @@ ABOUT_KEYWORDS = ("about", "tentang")
 
 EMAIL_REGEX = re.compile(r"[A-Z0-9._%+-]+@[A-Z0-9.-]+\.[A-Z]{2,}", re.IGNORECASE)
 PHONE_CANDIDATE_REGEX = re.compile(r"\+?\d[\d\s().\-]{6,}")
+EMPLOYEE_MENTION_REGEX = re.compile(
+    r"(\d{1,3}(?:[.,]\d{3})*|\d+)\s*\+?\s*(?:employees|staff|team members|karyawan|pegawai)\b",
+    re.IGNORECASE,
+)
+
+# Markup fingerprints of website technologies, matched in the lowercase page. The names are the
+# ones the API stores (api/internal/service/techstack).
+TECHNOLOGY_FINGERPRINTS = {
+    "wordpress": ("/wp-content/", "/wp-includes/", "wp-json"),
+    "woocommerce": ("woocommerce",),
+    "elementor": ("elementor-",),
+    "wix": ("static.wixstatic.com", "static.parastorage.com", "x-wix-"),
+    "shopify": ("cdn.shopify.com", "shopify.theme", "myshopify.com"),
+    "squarespace": ("static1.squarespace.com", "squarespace-cdn.com"),
+    "webflow": ("data-wf-page", "data-wf-site", "assets.website-files.com"),
+    "weebly": ("editmysite.com", "weebly.com"),
+    "joomla": ("/media/jui/", "/components/com_", "joomla!"),
+    "drupal": ("/sites/default/files/", "drupal-settings-json", "drupal.settings"),
+    "magento": ("mage/cookies", "/static/frontend/", "magento_"),
+    "prestashop": ("prestashop",),
+    "opencart": ("route=common/home", "catalog/view/theme"),
+    "blogger": ("blogger.com/static", "blogblog.com"),
+    "ghost": ("ghost-portal", "/ghost/api/"),
+    "hubspot": ("js.hs-scripts.com", "js.hsforms.net", "js.hs-analytics.net"),
+    "google-analytics": ("google-analytics.com/analytics.js", "googletagmanager.com/gtag/js", "ga('create'"),
+    "google-tag-manager": ("googletagmanager.com/gtm.js", "googletagmanager.com/ns.html"),
+    "facebook-pixel": ("connect.facebook.net", "fbq('init'", 'fbq("init"'),
+    "cloudflare": ("/cdn-cgi/", "cdnjs.cloudflare.com", "challenges.cloudflare.com"),
+}
+GENERATOR_TECHNOLOGIES = {
+    "wordpress": "wordpress",
+    "woocommerce": "woocommerce",
+    "elementor": "elementor",
+    "wix.com": "wix",
+    "squarespace": "squarespace",
+    "webflow": "webflow",
+    "joomla": "joomla",
+    "drupal": "drupal",
+    "prestashop": "prestashop",
+    "blogger": "blogger",
+    "ghost": "ghost",
+}
 
 
 class PlaywrightRenderer:
@@ -210,6 +254,23 @@ def extract_phones(text: str, default_region: Optional[str] = None) -> List[str]
     return sorted(normalized)
 
 
+def extract_employee_mentions(text: str) -> Optional[int]:
+    """Return the largest headcount mentioned in text (e.g. "120 employees", "50+ karyawan")."""
+
+    largest: Optional[int] = None
+    for match in EMPLOYEE_MENTION_REGEX.finditer(text or ""):
+        digits = re.sub(r"[.,]", "", match.group(1))
+        try:
+            value = int(digits)
+        except ValueError:
+            continue
+        if value <= 0 or value > 1_000_000:
+            continue
+        if largest is None or value > largest:
+            largest = value
+    return largest
+
+
 def _normalize_phone(raw: str, default_region: Optional[str], phone_lib: Optional[Any]) -> Optional[str]:
     if phone_lib:
         try:
@@ -263,13 +324,84 @@ def extract_social_links(soup: BeautifulSoup, base_url: str) -> Dict[str, List[s
 
         host = parsed.netloc.lower()
         for platform, allowed_hosts in SOCIAL_HOSTS.items():
-            if any(allowed in host for allowed in allowed_hosts):
-                normalized = urlunparse((parsed.scheme, parsed.netloc, parsed.path.rstrip("/"), "", "", ""))
+            # Exact or subdomain match: "x.com" must not match "dropbox.com".
+            if any(host == allowed or host.endswith("." + allowed) for allowed in allowed_hosts):
+                # WhatsApp click-to-chat links carry the number in ?phone=.
+                query = parsed.query if platform == "whatsapp" else ""
+                normalized = urlunparse((parsed.scheme, parsed.netloc, parsed.path.rstrip("/"), "", query, ""))
                 results[platform].add(normalized)
 
     return {platform: sorted(links) for platform, links in results.items() if links}
 
 
+def extract_html_language(soup: BeautifulSoup) -> Optional[str]:
+    """Return the lang attribute of the page, e.g. "id-ID", falling back to the content-language meta tag."""
+
+    html = soup.find("html")
+    lang = (html.get("lang") or html.get("xml:lang")) if html else None
+    if not lang:
+        meta = soup.find("meta", attrs={"http-equiv": lambda value: value and value.lower() == "content-language"})
+        lang = meta.get("content") if meta else None
+    if not isinstance(lang, str):
+        return None
+    # content-language may list several languages; the first one is the primary.
+    lang = lang.split(",")[0].strip()
+    return lang[:35] or None
+
+
+def detect_technologies(soup: BeautifulSoup) -> Set[str]:
+    """Fingerprint the website technologies of a page from its generator meta tags and markup."""
+
+    detected: Set[str] = set()
+    for meta in soup.find_all("meta", attrs={"name": lambda value: value and value.lower() == "generator"}):
+        generator = (meta.get("content") or "").lower()
+        for marker, technology in GENERATOR_TECHNOLOGIES.items():
+            if marker in generator:
+                detected.add(technology)
+
+    markup = str(soup).lower()
+    for technology, fingerprints in TECHNOLOGY_FINGERPRINTS.items():
+        if any(fingerprint in markup for fingerprint in fingerprints):
+            detected.add(technology)
+    return detected
+
+
+def extract_logo_url(soup: BeautifulSoup, base_url: str) -> Optional[str]:
+    """Return the absolute URL of the site logo: the schema.org logo, an image marked as a logo or the touch icon."""
+
+    candidates: List[str] = []
+    for meta in soup.find_all("meta", attrs={"itemprop": "logo"}):
+        candidates.append(meta.get("content") or "")
+    for tag in soup.find_all(attrs={"itemprop": "logo"}):
+        candidates.append(tag.get("src") or tag.get("href") or "")
+    for img in soup.find_all("img", src=True):
+        descriptor = " ".join(
+            [
+                img.get("id", ""),
+                " ".join(img.get("class", [])) if isinstance(img.get("class"), list) else img.get("class", ""),
+                img.get("alt", ""),
+                img.get("src", ""),
+            ]
+        ).lower()
+        if "logo" in descriptor:
+            candidates.append(img["src"])
+            break
+    for link in soup.find_all("link", href=True):
+        rel = link.get("rel") or []
+        rel = " ".join(rel) if isinstance(rel, list) else rel
+        if "apple-touch-icon" in rel.lower():
+            candidates.append(link["href"])
+
+    for candidate in candidates:
+        candidate = candidate.strip()
+        if not candidate or candidate.startswith("data:"):
+            continue
+        absolute = urljoin(base_url, candidate)
+        if urlparse(absolute).scheme in ("http", "https"):
+            return absolute[:2048]
+    return None
+
+
 def extract_address(soup: BeautifulSoup) -> Optional[str]:
     """Attempt to extract a postal address-like snippet from a page."""
 
@@ -507,6 +639,10 @@ class SiteEnricher:
         contact_form_url: Optional[str] = None
         address: Optional[str] = None
         about_summary: Optional[str] = None
+        employee_mentions: Optional[int] = None
+        website_language: Optional[str] = None
+        technologies: Set[str] = set()
+        logo_url: Optional[str] = None
 
         if not self._is_allowed_by_robots(self.root_url):
             logger.info("Robots disallows root path for %s; skipping enrichment", self.domain)
@@ -519,6 +655,10 @@ class SiteEnricher:
                 "address": None,
                 "contact_form_url": None,
                 "about_summary": None,
+                "employee_mentions": None,
+                "website_language": None,
+                "technologies": None,
+                "logo_url": None,
             }
 
         delay_needed = False
@@ -560,6 +700,10 @@ class SiteEnricher:
             aggregated_phones.update(extract_phones(text, self.settings.default_phone_region))
             aggregated_phones.update(self._extract_tel_links(soup))
 
+            mentioned = extract_employee_mentions(text)
+            if mentioned and (employee_mentions is None or mentioned > employee_mentions):
+                employee_mentions = mentioned
+
             social_links = extract_social_links(soup, final_url)
             for platform, links in social_links.items():
                 aggregated_socials[platform].update(links)
@@ -567,6 +711,13 @@ class SiteEnricher:
             if not address:
                 address = extract_address(soup)
 
+            if not website_language:
+                website_language = extract_html_language(soup)
+
+            technologies.update(detect_technologies(soup))
+            if not logo_url:
+                logo_url = extract_logo_url(soup, final_url)
+
             if not contact_form_url:
                 contact_form_url = self._find_contact_form(final_url, soup)
 
@@ -590,6 +741,11 @@ class SiteEnricher:
             "address": address,
             "contact_form_url": contact_form_url,
             "about_summary": about_summary,
+            "employee_mentions": employee_mentions,
+            "website_language": website_language,
+            # None when no page could be fetched, so the API does not record "no technology".
+            "technologies": sorted(technologies) if visited else None,
+            "logo_url": logo_url,
         }
 
     def _extract_about_section(self, soup: BeautifulSoup) -> str:
@@ -621,8 +777,18 @@ class SiteEnricher:
         self.close()
 
 
-def post_enrich_result(company_id: str, data: Dict[str, Any], settings: Optional[Settings] = None) -> None:
-    """POST enrichment payloads back to the Golang API callback."""
+def post_enrich_result(
+    company_id: str,
+    data: Dict[str, Any],
+    settings: Optional[Settings] = None,
+    callback_token: Optional[str] = None,
+    trace_headers: Optional[Dict[str, str]] = None,
+) -> None:
+    """POST enrichment payloads back to the Golang API callback.
+
+    callback_token is the token the API sent with the job; /enrich-result refuses results without it.
+    trace_headers carry the W3C trace context of the API call, so the callback joins its trace.
+    """
 
     settings = settings or get_settings()
     if not settings.enrich_callback_url:
@@ -639,14 +805,24 @@ def post_enrich_result(company_id: str, data: Dict[str, Any], settings: Optional
         "about_summary": data.get("about_summary"),
         "website": data.get("website"),
         "pages_crawled": data.get("pages_crawled"),
+        "depth": data.get("depth"),
+        "employee_mentions": data.get("employee_mentions"),
+        "website_language": data.get("website_language"),
+        "technologies": data.get("technologies"),
+        "logo_url": data.get("logo_url"),
+        "photo_reference": data.get("photo_reference"),
     }
 
+    headers = {"User-Agent": USER_AGENT, **(trace_headers or {})}
+    if callback_token:
+        headers["Authorization"] = f"Bearer {callback_token}"
+
     try:
         response = requests.post(
             settings.enrich_callback_url.rstrip("/") + "/enrich-result",
             json=payload,
             timeout=REQUEST_TIMEOUT,
-            headers={"User-Agent": USER_AGENT},
+            headers=headers,
         )
         response.raise_for_status()
     except requests.RequestException as exc:  # noqa: BLE001
@@ -1,7 +1,7 @@
 """Utilities for transforming Google Places responses into database rows."""
 
 import logging
-from typing import Any, Dict, Iterable, Optional, Tuple
+from typing import Any, Dict, Iterable, List, Optional, Tuple
 
 logger = logging.getLogger(__name__)
 
@@ -27,6 +27,16 @@ def _extract_primary_type(types: Iterable[str]) -> Optional[str]:
     return None
 
 
+def _extract_categories(types: Iterable[str]) -> Optional[List[str]]:
+    """Return every specific place type, primary first, as the API stores categories."""
+    categories: List[str] = []
+    for type_name in types or []:
+        category = "_".join(str(type_name).lower().replace("-", " ").replace("_", " ").split())
+        if category and category not in _IGNORE_TYPES and category not in categories:
+            categories.append(category)
+    return categories or None
+
+
 def to_company_row(result: Dict[str, Any], fallback_city: Optional[str], fallback_country: Optional[str]) -> Dict[str, Any]:
     geometry = result.get("geometry", {}).get("location", {})
     city, country = parse_city_country(result.get("address_components", []))
@@ -43,6 +53,7 @@ def to_company_row(result: Dict[str, Any], fallback_city: Optional[str], fallbac
         "rating": result.get("rating"),
         "reviews": result.get("user_ratings_total"),
         "type_business": _extract_primary_type(result.get("types", [])),
+        "categories": _extract_categories(result.get("types", [])),
         "address": result.get("formatted_address"),
         "city": city,
         "country": country,
@@ -0,0 +1,182 @@
+"""gRPC entrypoint serving the Worker service, the typed alternative to run_query_server."""
+
+from __future__ import annotations
+
+import logging
+import os
+from concurrent import futures
+from typing import Any, Dict, Optional
+
+import grpc
+from grpc_health.v1 import health, health_pb2, health_pb2_grpc
+
+from src.core.config import Settings, get_settings
+from src.core.site_enricher import SiteEnricher, post_enrich_result
+from src.rpc import worker_pb2, worker_pb2_grpc
+from maps_serp_worker import run_scrape
+
+# ---------- Logging ----------
+logging.basicConfig(
+    level=logging.INFO,
+    format="%(asctime)s %(levelname)s %(name)s - %(message)s",
+)
+logger = logging.getLogger(__name__)
+
+SERVICE_NAME = "leadsgen.worker.v1.Worker"
+
+_executor = futures.ThreadPoolExecutor(max_workers=4)
+
+
+class WorkerService(worker_pb2_grpc.WorkerServicer):
+    """Runs the jobs of the API, as the /scrape and /enrich endpoints of run_query_server do."""
+
+    def Scrape(self, request: worker_pb2.ScrapeJob, context: grpc.ServicerContext) -> worker_pb2.ScrapeAccepted:
+        missing = [f for f in ("type_business", "city", "country") if not getattr(request, f).strip()]
+        if missing:
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, f"missing fields: {', '.join(missing)}")
+
+        job_args = dict(
+            query=f"{request.type_business.strip()} in {request.city.strip()}, {request.country.strip()}",
+            min_rating=request.min_rating if request.HasField("min_rating") else None,
+            limit=None,
+            require_no_website=False,
+            run_id=request.run_id or None,
+            callback_token=request.callback_token or None,
+            trace_headers=_trace_headers(context),
+        )
+        logger.info(
+            "Queueing SERP scrape job: %s",
+            {k: v for k, v in job_args.items() if k not in ("callback_token", "trace_headers")},
+        )
+        _executor.submit(_run_job_safe, job_args)
+        return worker_pb2.ScrapeAccepted(status="queued")
+
+    def Enrich(self, request: worker_pb2.EnrichJob, context: grpc.ServicerContext) -> worker_pb2.EnrichResult:
+        if not request.company_id or not request.website:
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, "company_id and website are required")
+        if request.max_pages < 0:
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, "max_pages must be positive")
+
+        depth = (request.depth or "standard").strip().lower()
+        enricher_kwargs: Dict[str, Any] = {}
+        if request.max_pages > 0:
+            enricher_kwargs["max_pages"] = request.max_pages
+
+        try:
+            with SiteEnricher(request.website, **enricher_kwargs) as enricher:
+                enrichment = enricher.enrich()
+                enrichment["depth"] = depth
+        except ValueError as exc:
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, str(exc))
+        except Exception as exc:  # noqa: BLE001
+            logger.exception("Enrichment failed for %s: %s", request.website, exc)
+            context.abort(grpc.StatusCode.INTERNAL, "enrichment failed")
+
+        if not context.is_active():
+            # The API gave up waiting; the result is still reported below.
+            logger.warning("Enrichment for %s outlived its deadline", request.company_id)
+        submit_enrich_result(
+            request.company_id,
+            enrichment,
+            callback_token=request.callback_token or None,
+            trace_headers=_trace_headers(context),
+        )
+        return enrich_result_message(request.company_id, enrichment)
+
+
+def enrich_result_message(company_id: str, data: Dict[str, Any]) -> worker_pb2.EnrichResult:
+    """Build the EnrichResult of an enrichment, with the fields post_enrich_result sends."""
+
+    message = worker_pb2.EnrichResult(
+        company_id=company_id,
+        emails=data.get("emails") or [],
+        phones=data.get("phones") or [],
+        website=data.get("website") or "",
+        pages_crawled=int(data.get("pages_crawled") or 0),
+        depth=data.get("depth") or "",
+    )
+    for platform, links in (data.get("socials") or {}).items():
+        message.socials[platform].values.extend(links)
+    for platform, count in (data.get("social_followers") or {}).items():
+        message.social_followers[platform] = int(count)
+    for field in ("address", "contact_form_url", "about_summary", "website_language", "logo_url", "photo_reference"):
+        if data.get(field):
+            setattr(message, field, data[field])
+    if data.get("employee_mentions") is not None:
+        message.employee_mentions = int(data["employee_mentions"])
+    if data.get("technologies") is not None:
+        message.technologies.extend(data["technologies"])
+        message.technologies_checked = True
+    return message
+
+
+def submit_enrich_result(
+    company_id: str,
+    data: Dict[str, Any],
+    settings: Optional[Settings] = None,
+    callback_token: Optional[str] = None,
+    trace_headers: Optional[Dict[str, str]] = None,
+) -> None:
+    """Report an enrichment to the API over WorkerCallbacks when ENRICH_CALLBACK_GRPC_ADDR is set,
+    or through post_enrich_result otherwise. Failures are logged, as the HTTP callback does."""
+
+    settings = settings or get_settings()
+    if not settings.enrich_callback_grpc_addr:
+        post_enrich_result(company_id, data, settings, callback_token=callback_token, trace_headers=trace_headers)
+        return
+
+    metadata = list((trace_headers or {}).items())
+    if callback_token:
+        metadata.append(("authorization", f"Bearer {callback_token}"))
+    if settings.enrich_callback_grpc_tls:
+        channel = grpc.secure_channel(settings.enrich_callback_grpc_addr, grpc.ssl_channel_credentials())
+    else:
+        channel = grpc.insecure_channel(settings.enrich_callback_grpc_addr)
+    try:
+        with channel:
+            stub = worker_pb2_grpc.WorkerCallbacksStub(channel)
+            stub.SubmitEnrichResult(enrich_result_message(company_id, data), metadata=metadata, timeout=15)
+        logger.info("Enrichment callback stored for %s", company_id)
+    except grpc.RpcError as exc:
+        logger.error("Enrichment callback failed for %s: %s %s", company_id, exc.code(), exc.details())
+
+
+# ---------- Internals ----------
+
+
+def _trace_headers(context: grpc.ServicerContext) -> Dict[str, str]:
+    """W3C trace context the API sent as metadata, so the job's callbacks join its trace."""
+    return {key: value for key, value in context.invocation_metadata() if key in ("traceparent", "tracestate")}
+
+
+def _run_job_safe(job_args: Dict[str, Any]) -> None:
+    try:
+        run_scrape(**job_args)
+    except Exception as exc:  # noqa: BLE001
+        logger.exception("Scrape job failed: %s", exc)
+
+
+def build_server(port: int) -> grpc.Server:
+    """Build a server for the Worker service and its gRPC health status on port."""
+
+    server = grpc.server(futures.ThreadPoolExecutor(max_workers=8))
+    worker_pb2_grpc.add_WorkerServicer_to_server(WorkerService(), server)
+    health_servicer = health.HealthServicer()
+    for service in ("", SERVICE_NAME):
+        health_servicer.set(service, health_pb2.HealthCheckResponse.SERVING)
+    health_pb2_grpc.add_HealthServicer_to_server(health_servicer, server)
+    server.add_insecure_port(f"0.0.0.0:{port}")
+    return server
+
+
+def main() -> None:
+    """Serve on PORT, as Cloud Run injects it, or 9090 locally."""
+    port = int(os.getenv("PORT") or 9090)
+    logger.info("[BOOT] gRPC worker binding on 0.0.0.0:%d", port)
+    server = build_server(port)
+    server.start()
+    server.wait_for_termination()
+
+
+if __name__ == "__main__":
+    main()
@@ -12,6 +12,8 @@ from flask import Flask, jsonify, request
 from src.core.config import get_settings
 from src.core.site_enricher import SiteEnricher, post_enrich_result
 from maps_serp_worker import run_scrape
+from serp_client import fetch_from_serpapi, parse_serpapi_maps
+from sources import get_adapter
 
 # ---------- Logging ----------
 logging.basicConfig(
@@ -58,7 +60,9 @@ def enqueue_scrape() -> Any:
     """
     Enqueue a SERP API scraping job.
     Required JSON fields: type_business, city, country
-    Optional: min_rating (float), limit (int), require_no_website (bool)
+    Optional: source (google_maps by default), min_rating (float), limit (int),
+    require_no_website (bool), run_id and callback_token (issued by the API for the job's callbacks)
+    The traceparent/tracestate headers are forwarded on the ingest callback.
     """
     payload: Dict[str, Any] = request.get_json(silent=True) or {}
 
@@ -73,6 +77,11 @@ def enqueue_scrape() -> Any:
     country = str(payload["country"]).strip()
     query = f"{type_business} in {city}, {country}"
 
+    try:
+        adapter = get_adapter(payload.get("source"))
+    except ValueError as exc:
+        return jsonify({"error": str(exc)}), 400
+
     # min_rating (optional -> float)
     min_rating_raw = payload.get("min_rating")
     min_rating = None
@@ -101,9 +110,16 @@ def enqueue_scrape() -> Any:
         min_rating=min_rating,
         limit=limit,
         require_no_website=require_no_website,
+        run_id=payload.get("run_id"),
+        callback_token=payload.get("callback_token"),
+        trace_headers=_trace_headers(),
+        source=adapter.name,
     )
 
-    logger.info("Queueing SERP scrape job: %s", job_args)
+    logger.info(
+        "Queueing SERP scrape job: %s",
+        {k: v for k, v in job_args.items() if k not in ("callback_token", "trace_headers")},
+    )
     _executor.submit(_run_job_safe, job_args)
 
     # 202 Accepted lebih tepat untuk async enqueue
@@ -121,9 +137,26 @@ def enrich_website() -> Any:
     if not company_id or not website:
         return jsonify({"error": "company_id and website are required"}), 400
 
+    # depth/max_pages (optional) are chosen by the API per enrichment depth level
+    depth = str(payload.get("depth") or "standard").strip().lower()
+    enricher_kwargs: Dict[str, Any] = {}
+    max_pages_raw = payload.get("max_pages")
+    if max_pages_raw is not None:
+        try:
+            max_pages = int(max_pages_raw)
+        except (TypeError, ValueError):
+            return jsonify({"error": "max_pages must be numeric"}), 400
+        if max_pages <= 0:
+            return jsonify({"error": "max_pages must be positive"}), 400
+        enricher_kwargs["max_pages"] = max_pages
+
     try:
-        with SiteEnricher(website) as enricher:
+        with SiteEnricher(website, **enricher_kwargs) as enricher:
             enrichment = enricher.enrich()
+            enrichment["depth"] = depth
+            # A Maps photo reference the caller already holds is passed back with the result.
+            if payload.get("photo_reference"):
+                enrichment["photo_reference"] = str(payload["photo_reference"]).strip()
     except ValueError as exc:
         return jsonify({"error": str(exc)}), 400
     except Exception as exc:  # noqa: BLE001
@@ -133,14 +166,66 @@ def enrich_website() -> Any:
     response_payload = {"company_id": company_id, **enrichment}
 
     # Fire-and-forget callback to the Go API, failures are logged inside helper.
-    post_enrich_result(company_id, enrichment)
+    post_enrich_result(
+        company_id,
+        enrichment,
+        callback_token=payload.get("callback_token"),
+        trace_headers=_trace_headers(),
+    )
 
     return jsonify({"data": response_payload}), 200
 
 
+@app.post("/lookup")
+def lookup_place() -> Any:
+    """
+    Look a place up again by name and address, e.g. after Google retired its place ID.
+    Required JSON fields: name, address
+    Answers synchronously with the place_id, name, address, rating and reviews of the best match,
+    or found=false when the search matches no place.
+    """
+    payload: Dict[str, Any] = request.get_json(silent=True) or {}
+    name = str(payload.get("name") or "").strip()
+    address = str(payload.get("address") or "").strip()
+    if not name or not address:
+        return jsonify({"error": "name and address are required"}), 400
+
+    try:
+        candidates = parse_serpapi_maps(fetch_from_serpapi(f"{name}, {address}"))
+    except Exception as exc:  # noqa: BLE001
+        logger.exception("Place lookup failed for %s: %s", name, exc)
+        return jsonify({"error": "place lookup failed"}), 500
+
+    # The search ranks the place itself first; results without a place_id cannot replace one.
+    for candidate in candidates:
+        place_id = (candidate.raw_snapshot or {}).get("place_id")
+        if place_id:
+            return (
+                jsonify(
+                    {
+                        "data": {
+                            "found": True,
+                            "place_id": str(place_id).strip(),
+                            "name": candidate.name,
+                            "address": candidate.address,
+                            "rating": candidate.rating,
+                            "reviews": candidate.review_count,
+                        }
+                    }
+                ),
+                200,
+            )
+    return jsonify({"data": {"found": False}}), 200
+
+
 # ---------- Internals ----------
 
 
+def _trace_headers() -> Dict[str, str]:
+    """W3C trace context the API sent, so the job's callbacks join the API request's trace."""
+    return {name: request.headers[name] for name in ("traceparent", "tracestate") if name in request.headers}
+
+
 def _run_job_safe(job_args: Dict[str, Any]) -> None:
     try:
         run_scrape(**job_args)
@@ -29,7 +29,7 @@ def text_search(query: str, api_key: str, pagetoken: Optional[str] = None) -> Di
 
 
 def place_details(place_id: str, api_key: str) -> Dict[str, Any]:
-    fields = "place_id,name,formatted_address,formatted_phone_number,geometry,website,rating,user_ratings_total,types,address_components"
+    fields = "place_id,name,formatted_address,formatted_phone_number,geometry,website,rating,user_ratings_total,types,address_components,photos,business_status,opening_hours,price_level,utc_offset"
     params = {"place_id": place_id, "key": api_key, "fields": fields}
     response = _SESSION.get(f"{_BASE_URL}/details/json", params=params, timeout=10)
     response.raise_for_status()
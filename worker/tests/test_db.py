@@ -116,6 +116,7 @@ def test_prepare_params_json_wrapper():
 
     assert params["place_id"] == "pid"
     assert params["raw"].adapted == {"foo": "bar"}
+    assert params["categories"] is None
 
 
 def test_upsert_company_validations():
@@ -138,3 +139,21 @@ def test_upsert_company_executes_statements(monkeypatch):
     assert connection.commits == 2
     assert collector[0][0].startswith("INSERT INTO companies ( place_id")
     assert collector[1][0].startswith("INSERT INTO companies ( company")
+    assert "ON CONFLICT (display_name, address) WHERE place_id IS NULL" in collector[1][0]
+    assert collector[1][1]["display_name"] == "Acme"
+
+
+def test_upsert_company_rescrape_after_recompute_keeps_key(monkeypatch):
+    collector = []
+    connection = DummyConnection(collector)
+    db._connection_pool = DummyPool(connection)
+
+    # recompute-legal-forms stored "PT Maju Jaya" at this address as "Maju Jaya";
+    # a re-scrape must hit that row through the (display_name, address) index.
+    db.upsert_company({"company": "PT Maju Jaya", "address": "Jl. Sudirman 1"})
+
+    sql, params = collector[0]
+    assert "legal_form = EXCLUDED.legal_form" in sql
+    assert params["company"] == "PT Maju Jaya"
+    assert params["display_name"] == "Maju Jaya"
+    assert params["legal_form"] == "PT"
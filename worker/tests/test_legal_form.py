@@ -0,0 +1,33 @@
+import pytest
+
+from src.core.legal_form import Extraction, extract
+
+
+@pytest.mark.parametrize(
+    "name, want",
+    [
+        ("PT Maju Jaya", Extraction("PT", "Maju Jaya")),
+        ("pt. maju jaya", Extraction("PT", "maju jaya")),
+        ("P.T. Sinar  Abadi", Extraction("PT", "Sinar Abadi")),
+        ("PT.Sumber Rejeki", Extraction("PT", "Sumber Rejeki")),
+        ("CV. Bintang Timur", Extraction("CV", "Bintang Timur")),
+        ("UD Sumber Makmur", Extraction("UD", "Sumber Makmur")),
+        ("Koperasi Karyawan Sejahtera", Extraction("Koperasi", "Karyawan Sejahtera")),
+        ("Yayasan Pendidikan Harapan", Extraction("Yayasan", "Pendidikan Harapan")),
+        ("Maju Jaya, PT", Extraction("PT", "Maju Jaya")),
+        ("Bintang Timur, CV.", Extraction("CV", "Bintang Timur")),
+        ("PT Bank Central Asia Tbk", Extraction("PT", "Bank Central Asia")),
+        ("PT Telkom Indonesia (Persero) Tbk", Extraction("PT", "Telkom Indonesia")),
+        ("Bank Rakyat Indonesia (Persero)", Extraction("PT", "Bank Rakyat Indonesia")),
+        ("Ptolemy Coffee", Extraction(None, "Ptolemy Coffee")),
+        ("Kopi Kenangan", Extraction(None, "Kopi Kenangan")),
+        ("Cvetko Bakery", Extraction(None, "Cvetko Bakery")),
+        ("PT", Extraction(None, "PT")),
+        ("  Warung   Bu Ani ", Extraction(None, "Warung Bu Ani")),
+        ("Kop. Unit Desa Makmur", Extraction("Koperasi", "Unit Desa Makmur")),
+        ("Kop Susu Bandung", Extraction(None, "Kop Susu Bandung")),
+        ("Kopitbk", Extraction(None, "Kopitbk")),
+    ],
+)
+def test_extract_matches_api(name, want):
+    assert extract(name) == want
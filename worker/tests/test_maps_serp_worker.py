@@ -3,7 +3,7 @@
 import pytest
 from unittest.mock import Mock, patch
 
-from maps_serp_worker import run_scrape
+from maps_serp_worker import run_scrape, send_to_ingest_api
 from models import CompanyCandidate
 
 
@@ -45,8 +45,8 @@ def mock_candidates():
 
 
 @patch("maps_serp_worker.send_to_ingest_api")
-@patch("maps_serp_worker.parse_serpapi_maps")
-@patch("maps_serp_worker.fetch_from_serpapi")
+@patch("sources.parse_serpapi_maps")
+@patch("sources.fetch_from_serpapi")
 def test_run_scrape_filters_by_min_rating(mock_fetch, mock_parse, mock_send, mock_candidates):
     """Test that candidates below min_rating are filtered out."""
     mock_fetch.return_value = {"local_results": []}
@@ -64,8 +64,8 @@ def test_run_scrape_filters_by_min_rating(mock_fetch, mock_parse, mock_send, moc
 
 
 @patch("maps_serp_worker.send_to_ingest_api")
-@patch("maps_serp_worker.parse_serpapi_maps")
-@patch("maps_serp_worker.fetch_from_serpapi")
+@patch("sources.parse_serpapi_maps")
+@patch("sources.fetch_from_serpapi")
 def test_run_scrape_filters_by_require_no_website(mock_fetch, mock_parse, mock_send, mock_candidates):
     """Test that candidates with websites are filtered out when require_no_website=True."""
     mock_fetch.return_value = {"local_results": []}
@@ -83,8 +83,8 @@ def test_run_scrape_filters_by_require_no_website(mock_fetch, mock_parse, mock_s
 
 
 @patch("maps_serp_worker.send_to_ingest_api")
-@patch("maps_serp_worker.parse_serpapi_maps")
-@patch("maps_serp_worker.fetch_from_serpapi")
+@patch("sources.parse_serpapi_maps")
+@patch("sources.fetch_from_serpapi")
 def test_run_scrape_limits_results(mock_fetch, mock_parse, mock_send, mock_candidates):
     """Test that results are limited to the specified limit."""
     mock_fetch.return_value = {"local_results": []}
@@ -101,8 +101,8 @@ def test_run_scrape_limits_results(mock_fetch, mock_parse, mock_send, mock_candi
 
 
 @patch("maps_serp_worker.send_to_ingest_api")
-@patch("maps_serp_worker.parse_serpapi_maps")
-@patch("maps_serp_worker.fetch_from_serpapi")
+@patch("sources.parse_serpapi_maps")
+@patch("sources.fetch_from_serpapi")
 def test_run_scrape_combined_filters(mock_fetch, mock_parse, mock_send, mock_candidates):
     """Test that all filters work together correctly."""
     mock_fetch.return_value = {"local_results": []}
@@ -122,8 +122,8 @@ def test_run_scrape_combined_filters(mock_fetch, mock_parse, mock_send, mock_can
 
 
 @patch("maps_serp_worker.send_to_ingest_api")
-@patch("maps_serp_worker.parse_serpapi_maps")
-@patch("maps_serp_worker.fetch_from_serpapi")
+@patch("sources.parse_serpapi_maps")
+@patch("sources.fetch_from_serpapi")
 def test_run_scrape_skips_ingest_when_no_candidates_after_filter(mock_fetch, mock_parse, mock_send, mock_candidates):
     """Test that ingest is skipped when all candidates are filtered out."""
     mock_fetch.return_value = {"local_results": []}
@@ -135,3 +135,134 @@ def test_run_scrape_skips_ingest_when_no_candidates_after_filter(mock_fetch, moc
 
     # Should NOT call send_to_ingest_api
     assert not mock_send.called
+
+
+@patch("maps_serp_worker.send_to_ingest_api")
+@patch("sources.parse_serpapi_maps")
+@patch("sources.fetch_from_serpapi")
+def test_run_scrape_keys_items_by_source(mock_fetch, mock_parse, mock_send):
+    """Test that every item names its source and the place id it is deduped on."""
+    mock_fetch.return_value = {"local_results": []}
+    mock_parse.return_value = [
+        CompanyCandidate(name="Kopi Kenangan", raw_snapshot={"place_id": " ChIJabc "}),
+        CompanyCandidate(name="No Place Id"),
+    ]
+    mock_send.return_value = Mock(status_code=200)
+
+    run_scrape("coffee in Jakarta", source="google_maps")
+
+    items = mock_send.call_args[0][0]["items"]
+    assert [item["source"] for item in items] == ["google_maps", "google_maps"]
+    assert items[0]["place_id"] == "ChIJabc"
+    assert "place_id" not in items[1]
+
+
+def test_run_scrape_rejects_unknown_source():
+    """Test that a source without an adapter is refused before anything is fetched."""
+    with pytest.raises(ValueError):
+        run_scrape("coffee in Jakarta", source="yelp")
+
+
+class FakeIngestSession:
+    """Answers ingest run calls from a run whose stored batches are tracked, failing on demand."""
+
+    def __init__(self, fail_sequences=()):
+        self.calls = []
+        self.stored = set()
+        self.fail_sequences = list(fail_sequences)
+
+    def mount(self, prefix, adapter):
+        pass
+
+    def post(self, url, json, timeout, headers):
+        self.calls.append((url, json, headers))
+        if url.endswith("/ingest/runs"):
+            next_sequence = 0
+            while next_sequence in self.stored:
+                next_sequence += 1
+            return self._answer(201, {"id": json["run_id"] or "run-1", "next_sequence": next_sequence})
+        if url.endswith("/batches"):
+            if json["sequence"] in self.fail_sequences:
+                self.fail_sequences.remove(json["sequence"])
+                return self._answer(503, None)
+            self.stored.add(json["sequence"])
+            return self._answer(201, {"sequence": json["sequence"]})
+        return self._answer(200, {"id": "run-1", "status": "finished", "batches": json["total_batches"]})
+
+    @staticmethod
+    def _answer(status, data):
+        return Mock(status_code=status, text="", json=Mock(return_value={"data": data}))
+
+
+@pytest.fixture
+def ingest_session(monkeypatch):
+    def install(**kwargs):
+        session = FakeIngestSession(**kwargs)
+        monkeypatch.setattr("maps_serp_worker.requests.Session", lambda: session)
+        monkeypatch.setattr("maps_serp_worker.get_settings", lambda: Mock(ingest_api_url="http://api/ingest/runs/"))
+        return session
+    return install
+
+
+def test_send_to_ingest_api_streams_numbered_batches(ingest_session):
+    """Test that a run is started, its items appended in sequence and finished."""
+    session = ingest_session()
+    payload = {"items": [{"name": f"Place {i}"} for i in range(250)], "source": "google_maps", "query": "coffee"}
+
+    run = send_to_ingest_api(payload)
+
+    urls = [call[0] for call in session.calls]
+    assert urls == [
+        "http://api/ingest/runs",
+        "http://api/ingest/runs/run-1/batches",
+        "http://api/ingest/runs/run-1/batches",
+        "http://api/ingest/runs/run-1/batches",
+        "http://api/ingest/runs/run-1/finish",
+    ]
+    assert session.calls[0][1] == {"run_id": "", "source": "google_maps", "query": "coffee"}
+    assert [len(call[1]["items"]) for call in session.calls[1:4]] == [100, 100, 50]
+    assert [call[1]["sequence"] for call in session.calls[1:4]] == [0, 1, 2]
+    assert session.calls[4][1] == {"total_batches": 3}
+    assert run["status"] == "finished"
+
+
+def test_send_to_ingest_api_resumes_from_next_sequence(ingest_session, monkeypatch):
+    """Test that a failed batch resumes the same run without resending stored batches."""
+    session = ingest_session(fail_sequences=[1])
+    saved = []
+    monkeypatch.setattr("maps_serp_worker._save_failed_payload", lambda payload, error: saved.append(payload))
+
+    run = send_to_ingest_api({"items": [{"name": f"Place {i}"} for i in range(250)], "run_id": "job-1"})
+
+    sent = [(call[0].rsplit("/", 1)[-1], call[1].get("sequence")) for call in session.calls]
+    assert sent == [
+        ("runs", None), ("batches", 0), ("batches", 1),
+        ("runs", None), ("batches", 1), ("batches", 2),
+        ("finish", None),
+    ]
+    assert session.calls[3][1]["run_id"] == "job-1"
+    assert run["batches"] == 3
+    assert saved == []
+
+
+def test_send_to_ingest_api_saves_payload_after_resumes(ingest_session, monkeypatch):
+    """Test that a run still failing after every resume is saved with its run id for replay."""
+    ingest_session(fail_sequences=[0] * 4)
+    saved = []
+    monkeypatch.setattr("maps_serp_worker._save_failed_payload", lambda payload, error: saved.append(payload))
+
+    assert send_to_ingest_api({"items": [{"name": "Place"}]}) is None
+    assert len(saved) == 1 and saved[0]["run_id"] == "run-1"
+
+
+def test_send_to_ingest_api_forwards_token_and_trace_context(ingest_session):
+    """Test that every run call carries the job's callback token and the API's trace context."""
+    session = ingest_session()
+    trace = {"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+
+    send_to_ingest_api({"items": [{"name": "Place"}], "run_id": "job-1"}, callback_token="tok", trace_headers=trace)
+
+    assert len(session.calls) == 3
+    for _, _, headers in session.calls:
+        assert headers["Authorization"] == "Bearer tok"
+        assert headers["traceparent"] == trace["traceparent"]
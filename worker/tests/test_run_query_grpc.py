@@ -0,0 +1,64 @@
+import pytest
+
+grpc = pytest.importorskip("grpc")
+pytest.importorskip("grpc_health")
+
+from src.jobs import run_query_grpc  # noqa: E402
+from src.rpc import worker_pb2, worker_pb2_grpc  # noqa: E402
+
+
+@pytest.fixture
+def stub(monkeypatch):
+    submitted = {}
+
+    class DummyExecutor:
+        def submit(self, fn, args):
+            submitted["args"] = args
+
+    monkeypatch.setattr(run_query_grpc, "_executor", DummyExecutor())
+    server = run_query_grpc.build_server(0)
+    port = server.add_insecure_port("127.0.0.1:0")
+    server.start()
+    channel = grpc.insecure_channel(f"127.0.0.1:{port}")
+    yield worker_pb2_grpc.WorkerStub(channel), submitted
+    channel.close()
+    server.stop(None)
+
+
+def test_scrape_validates_and_queues(stub):
+    client, submitted = stub
+    with pytest.raises(grpc.RpcError) as exc:
+        client.Scrape(worker_pb2.ScrapeJob(type_business="store"))
+    assert exc.value.code() == grpc.StatusCode.INVALID_ARGUMENT
+
+    traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+    accepted = client.Scrape(
+        worker_pb2.ScrapeJob(type_business="restaurant", city="Yogyakarta", country="Indonesia", min_rating=4.5, run_id="run-1"),
+        metadata=[("traceparent", traceparent)],
+        timeout=5,
+    )
+    assert accepted.status == "queued"
+    job = submitted["args"]
+    assert job["query"] == "restaurant in Yogyakarta, Indonesia"
+    assert job["min_rating"] == 4.5
+    assert job["run_id"] == "run-1"
+    assert job["trace_headers"] == {"traceparent": traceparent}
+
+
+def test_enrich_result_message():
+    message = run_query_grpc.enrich_result_message(
+        "company-1",
+        {
+            "emails": ["info@example.com"],
+            "socials": {"instagram": ["https://instagram.com/acme"]},
+            "pages_crawled": 2,
+            "employee_mentions": 40,
+            "technologies": [],
+            "address": None,
+        },
+    )
+    assert message.company_id == "company-1"
+    assert list(message.socials["instagram"].values) == ["https://instagram.com/acme"]
+    assert message.employee_mentions == 40
+    assert message.technologies_checked
+    assert not message.HasField("address")
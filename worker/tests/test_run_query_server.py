@@ -51,8 +51,11 @@ def test_enqueue_scrape_builds_query_and_passes_params(reset_executor):
         "min_rating": 4.5,
         "limit": 10,
         "require_no_website": True,
+        "run_id": "0b7c1d2e-3f40-4a5b-8c6d-7e8f90a1b2c3",
+        "callback_token": "token",
     }
-    response = client.post("/scrape", json=payload)
+    traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+    response = client.post("/scrape", json=payload, headers={"traceparent": traceparent})
 
     assert response.status_code == 202
     assert reset_executor["called"] is True
@@ -61,6 +64,19 @@ def test_enqueue_scrape_builds_query_and_passes_params(reset_executor):
     assert args["min_rating"] == 4.5
     assert args["limit"] == 10
     assert args["require_no_website"] is True
+    assert args["run_id"] == "0b7c1d2e-3f40-4a5b-8c6d-7e8f90a1b2c3"
+    assert args["callback_token"] == "token"
+    assert args["trace_headers"] == {"traceparent": traceparent}
+    assert args["source"] == "google_maps"
+
+
+def test_enqueue_scrape_rejects_unknown_source(reset_executor):
+    client = run_query_server.app.test_client()
+    payload = {"type_business": "store", "city": "Jakarta", "country": "Indonesia", "source": "yelp"}
+    response = client.post("/scrape", json=payload)
+    assert response.status_code == 400
+    assert "unknown source" in response.get_json()["error"]
+    assert "called" not in reset_executor
 
 
 def test_enqueue_scrape_validates_limit(reset_executor):
@@ -73,3 +89,48 @@ def test_enqueue_scrape_validates_limit(reset_executor):
     # Invalid limit (negative)
     payload = {"type_business": "store", "city": "Jakarta", "country": "Indonesia", "limit": -5}
     assert client.post("/scrape", json=payload).status_code == 400
+
+
+def test_lookup_place_returns_best_match(monkeypatch):
+    queries = []
+
+    def fake_fetch(query, ll=None):
+        queries.append(query)
+        return {
+            "local_results": [
+                {"title": "Kopi Kenangan Sudirman"},
+                {
+                    "title": "Kopi Kenangan",
+                    "place_id": "ChIJnew",
+                    "address": "Jl. Sudirman 1",
+                    "rating": 4.6,
+                    "reviews": 120,
+                },
+            ]
+        }
+
+    monkeypatch.setattr(run_query_server, "fetch_from_serpapi", fake_fetch)
+    client = run_query_server.app.test_client()
+
+    assert client.post("/lookup", json={"name": "Kopi Kenangan"}).status_code == 400
+
+    response = client.post("/lookup", json={"name": "Kopi Kenangan", "address": "Jl. Sudirman 1"})
+    assert response.status_code == 200
+    assert queries == ["Kopi Kenangan, Jl. Sudirman 1"]
+    assert response.get_json()["data"] == {
+        "found": True,
+        "place_id": "ChIJnew",
+        "name": "Kopi Kenangan",
+        "address": "Jl. Sudirman 1",
+        "rating": 4.6,
+        "reviews": 120,
+    }
+
+
+def test_lookup_place_reports_no_match(monkeypatch):
+    monkeypatch.setattr(run_query_server, "fetch_from_serpapi", lambda query, ll=None: {"local_results": []})
+    client = run_query_server.app.test_client()
+
+    response = client.post("/lookup", json={"name": "Gone", "address": "Nowhere"})
+    assert response.status_code == 200
+    assert response.get_json()["data"] == {"found": False}
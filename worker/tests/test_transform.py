@@ -19,6 +19,12 @@ def test_extract_primary_type():
     assert transform._extract_primary_type([]) is None
 
 
+def test_extract_categories():
+    types = ["cafe", "point_of_interest", "food", "Coffee Shop", "cafe", "establishment"]
+    assert transform._extract_categories(types) == ["cafe", "food", "coffee_shop"]
+    assert transform._extract_categories(["establishment"]) is None
+
+
 def test_to_company_row_uses_fallbacks():
     result = {
         "name": "Acme",
@@ -38,3 +44,4 @@ def test_to_company_row_uses_fallbacks():
     assert row["country"] == "USA"
     assert row["lng"] == 10
     assert row["type_business"] == "store"
+    assert row["categories"] == ["store"]